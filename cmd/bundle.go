@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/packager"
 	"github.com/spf13/cobra"
 )
 
@@ -16,14 +19,43 @@ var bundleCmd = &cobra.Command{
 	Long: `Manage triage bundles including creation, extraction, and validation.
 Bundles contain all collected artifacts and findings in a compressed archive.`,
 	Args: cobra.NoArgs,
-	RunE: runBundle,
+	Example: `  RedTriage bundle --list --path ./evidence.zip
+  RedTriage bundle --validate --path ./evidence.zip
+  RedTriage bundle --extract --path ./evidence.zip --output ./case-42`,
+	Annotations: map[string]string{"category": "Data Management"},
+	RunE:        runBundle,
 }
 
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify a bundle against its manifest or an external one",
+	Long: `Verify every artifact in a bundle against a manifest. By default the
+manifest embedded in the bundle is used; --against checks the bundle against
+a manifest distributed separately, for example over another channel during
+evidence transfer. Files listed in the manifest but absent from the bundle,
+bundle artifacts the manifest does not list, and files whose checksum differs
+are reported separately. Artifact seals are checked when a seal key is given
+with --seal-key or saved beside the bundle.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage bundle verify --path ./evidence.zip
+  RedTriage bundle verify --path ./evidence.zip --against ./published-manifest.json
+  RedTriage bundle verify --path ./evidence.zip --seal-key ./case-42.sealkey`,
+	Annotations: map[string]string{"category": "Data Management"},
+	RunE:        runBundleVerify,
+}
+
+var (
+	bundleVerifyAgainst string
+	bundleSealKeyPath   string
+)
+
 var (
 	bundleExtract  bool
 	bundleValidate bool
 	bundleList     bool
 	bundlePath     string
+
+	bundleExtractTo string
 )
 
 func init() {
@@ -31,12 +63,67 @@ func init() {
 	bundleCmd.Flags().BoolVar(&bundleValidate, "validate", false, "Validate bundle integrity")
 	bundleCmd.Flags().BoolVar(&bundleList, "list", false, "List bundle contents")
 	bundleCmd.Flags().StringVar(&bundlePath, "path", "", "Path to bundle file")
+	bundleCmd.Flags().StringVarP(&bundleExtractTo, "output", "o", "./extracted-bundle", "Directory to extract the bundle into")
+	deprecatedAlias(bundleCmd.Flags(), "to", "output")
+
+	bundleVerifyCmd.Flags().StringVar(&bundlePath, "path", "", "Path to bundle file")
+	bundleVerifyCmd.Flags().StringVar(&bundleVerifyAgainst, "against", "", "Verify against this manifest instead of the one in the bundle")
+	bundleVerifyCmd.Flags().StringVar(&bundleSealKeyPath, "seal-key", "", "Key file to check artifact seals with (default: the bundle's .sealkey file when present)")
+	bundleCmd.AddCommand(bundleVerifyCmd)
+}
+
+func runBundleVerify(cmd *cobra.Command, args []string) error {
+	if bundlePath == "" {
+		return rterrors.Validationf("--path is required")
+	}
+	if err := validateBundleInputs(); err != nil {
+		return rterrors.Validationf("input validation failed: %w", err)
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		return rterrors.NotFoundf("bundle not found: %s", bundlePath)
+	}
+
+	fmt.Println("Bundle Verification")
+	fmt.Println("===================")
+	fmt.Printf("Bundle: %s\n", bundlePath)
+
+	key, err := bundleSealKey(bundlePath, bundleSealKeyPath)
+	if err != nil {
+		return err
+	}
+
+	var result *packager.VerifyResult
+	switch {
+	case bundleVerifyAgainst != "":
+		if _, err := os.Stat(bundleVerifyAgainst); err != nil {
+			return rterrors.NotFoundf("manifest not found: %s", bundleVerifyAgainst)
+		}
+		if key != nil {
+			result, err = packager.VerifyBundleSeals(bundlePath, bundleVerifyAgainst, key)
+		} else {
+			result, err = packager.VerifyBundleAgainst(bundlePath, bundleVerifyAgainst)
+		}
+	case key != nil:
+		result, err = packager.VerifyBundleSeals(bundlePath, "", key)
+	default:
+		result, err = packager.VerifyBundle(bundlePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to verify bundle: %w", err)
+	}
+
+	if err := reportBundleVerification(result); err != nil {
+		fmt.Println("❌ Bundle does not match the manifest")
+		return err
+	}
+	fmt.Println("✅ Bundle matches the manifest")
+	return nil
 }
 
 func runBundle(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateBundleInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("Bundle Management")
@@ -116,37 +203,10 @@ func runBundle(cmd *cobra.Command, args []string) error {
 	}
 
 	if bundleExtract {
-		fmt.Println("\n=== Extracting Bundle Contents (Simulated) ===")
-		fmt.Println("✓ Creating extraction directory...")
-		fmt.Println("✓ Reading bundle data...")
-		fmt.Println("✓ Extracting files...")
-		fmt.Println("✓ Verifying extracted files...")
-		
-		// Create sample extraction directory
-		extractDir := "./extracted-bundle"
-		if err := os.MkdirAll(extractDir, 0755); err == nil {
-			fmt.Printf("✓ Extraction directory created: %s\n", extractDir)
-			
-			// Create sample extracted files
-			sampleFiles := []string{
-				"artifacts/processes.json",
-				"artifacts/network.json",
-				"findings/detections.json",
-				"metadata/collection_info.json",
-			}
-			
-			for _, file := range sampleFiles {
-				fullPath := filepath.Join(extractDir, file)
-				dir := filepath.Dir(fullPath)
-				if err := os.MkdirAll(dir, 0755); err == nil {
-					content := fmt.Sprintf("Sample %s content\nGenerated: %s\n", file, time.Now().Format("2006-01-02 15:04:05"))
-					if err := os.WriteFile(fullPath, []byte(content), 0644); err == nil {
-						fmt.Printf("✓ Extracted: %s\n", file)
-					}
-				}
-			}
+		fmt.Println("\n=== Extracting Bundle Contents ===")
+		if err := extractBundle(bundlePath, bundleExtractTo); err != nil {
+			return err
 		}
-		
 		fmt.Println("✓ Bundle extraction completed")
 	}
 
@@ -155,7 +215,7 @@ func runBundle(cmd *cobra.Command, args []string) error {
 		fmt.Println("\nNo specific operation requested. Available operations:")
 		fmt.Println("  --list     : List bundle contents")
 		fmt.Println("  --validate : Validate bundle integrity")
-		fmt.Println("  --extract  : Extract bundle contents")
+		fmt.Println("  --extract  : Extract bundle contents (into --to)")
 		fmt.Println("  --path     : Specify bundle file path")
 	}
 
@@ -163,6 +223,34 @@ func runBundle(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// extractBundle unpacks a bundle into dir. Entries with absolute paths,
+// entries that climb out of dir and symlinks pointing outside it stop the
+// extraction, as do entries past the archive size limits.
+func extractBundle(path, dir string) error {
+	if _, err := os.Stat(path); err != nil {
+		return rterrors.NotFoundf("bundle not found: %s", path)
+	}
+
+	result, err := archive.Extract(path, dir, archive.DefaultLimits)
+	if result != nil {
+		for _, file := range result.Files {
+			fmt.Printf("✓ Extracted: %s\n", filepath.ToSlash(file))
+		}
+		for _, skipped := range result.Skipped {
+			fmt.Printf("⚠️  Skipped: %s\n", skipped)
+		}
+	}
+	if errors.Is(err, archive.ErrUnsafeEntry) {
+		return rterrors.Validationf("refusing to extract bundle: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract bundle: %w", err)
+	}
+
+	fmt.Printf("✓ %d files (%d bytes) extracted to %s\n", len(result.Files), result.Bytes, dir)
+	return nil
+}
+
 // validateBundleInputs validates all bundle command inputs
 func validateBundleInputs() error {
 	// Validate bundle path if specified
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CommandInfo describes a command in a machine-readable form. It is generated
+// from the cobra command tree so help, completion and docs never drift from
+// the real flags.
+type CommandInfo struct {
+	Name        string     `json:"name"`
+	Path        string     `json:"path"`
+	Description string     `json:"description"`
+	Long        string     `json:"long,omitempty"`
+	Category    string     `json:"category"`
+	Usage       string     `json:"usage"`
+	Examples    []string   `json:"examples,omitempty"`
+	Flags       []FlagInfo `json:"flags,omitempty"`
+	Hidden      bool       `json:"hidden,omitempty"`
+}
+
+// FlagInfo describes a single command flag
+type FlagInfo struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage"`
+	Inherited bool   `json:"inherited,omitempty"`
+}
+
+// Catalog returns metadata for every available command in the RedTriage
+// command tree
+func Catalog() []CommandInfo {
+	return BuildCatalog(NewRootCmd(), false)
+}
+
+// BuildCatalog walks the command tree below root and returns metadata for
+// each command. Hidden commands are only included when includeHidden is set.
+func BuildCatalog(root *cobra.Command, includeHidden bool) []CommandInfo {
+	var catalog []CommandInfo
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, child := range c.Commands() {
+			if child.Name() == "help" || child.Name() == "completion" {
+				continue
+			}
+			if child.Hidden && !includeHidden {
+				continue
+			}
+			catalog = append(catalog, NewCommandInfo(child))
+			walk(child)
+		}
+	}
+	walk(root)
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Path < catalog[j].Path
+	})
+
+	return catalog
+}
+
+// NewCommandInfo builds the metadata for a single cobra command
+func NewCommandInfo(c *cobra.Command) CommandInfo {
+	info := CommandInfo{
+		Name:        c.Name(),
+		Path:        strings.TrimSpace(strings.TrimPrefix(c.CommandPath(), c.Root().Name())),
+		Description: c.Short,
+		Long:        c.Long,
+		Category:    commandCategory(c),
+		Hidden:      c.Hidden,
+	}
+
+	for _, line := range strings.Split(c.Example, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			info.Examples = append(info.Examples, line)
+		}
+	}
+
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		info.Flags = append(info.Flags, newFlagInfo(f, false))
+	})
+	c.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		info.Flags = append(info.Flags, newFlagInfo(f, true))
+	})
+
+	info.Usage = commandUsage(info)
+
+	return info
+}
+
+// FindCommandInfo returns the metadata for the command at path name, or
+// else for a command named name
+func FindCommandInfo(catalog []CommandInfo, name string) (CommandInfo, bool) {
+	for _, info := range catalog {
+		if info.Path == name {
+			return info, true
+		}
+	}
+	for _, info := range catalog {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return CommandInfo{}, false
+}
+
+func newFlagInfo(f *pflag.Flag, inherited bool) FlagInfo {
+	info := FlagInfo{
+		Name:      f.Name,
+		Shorthand: f.Shorthand,
+		Type:      f.Value.Type(),
+		Usage:     f.Usage,
+		Inherited: inherited,
+	}
+	if f.DefValue != "" && f.DefValue != "[]" && f.DefValue != "false" {
+		info.Default = f.DefValue
+	}
+	return info
+}
+
+// commandCategory returns the category annotation, falling back to the
+// parent's category for subcommands
+func commandCategory(c *cobra.Command) string {
+	for cur := c; cur != nil; cur = cur.Parent() {
+		if category, ok := cur.Annotations["category"]; ok {
+			return category
+		}
+	}
+	return "General"
+}
+
+// commandUsage renders a compact usage line from the command's local flags
+func commandUsage(info CommandInfo) string {
+	parts := []string{info.Path}
+	for _, flag := range info.Flags {
+		if flag.Inherited {
+			continue
+		}
+		if flag.Type == "bool" {
+			parts = append(parts, "[--"+flag.Name+"]")
+		} else {
+			parts = append(parts, "[--"+flag.Name+" <"+flag.Type+">]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TestCatalogListsEveryCommand walks the command tree and checks each
+// command appears in the catalog with its own description and flags
+func TestCatalogListsEveryCommand(t *testing.T) {
+	root := NewRootCmd()
+	catalog := BuildCatalog(root, true)
+	visible := make(map[string]bool)
+	for _, info := range Catalog() {
+		visible[info.Path] = true
+	}
+
+	// A command below a hidden one is hidden with it
+	walked := 0
+	var walk func(c *cobra.Command, hidden bool)
+	walk = func(c *cobra.Command, hidden bool) {
+		for _, child := range c.Commands() {
+			if child.Name() == "help" || child.Name() == "completion" {
+				continue
+			}
+			walked++
+			childHidden := hidden || child.Hidden
+			path := strings.TrimSpace(strings.TrimPrefix(child.CommandPath(), root.Name()))
+			info, ok := FindCommandInfo(catalog, path)
+			if !ok {
+				t.Errorf("%s is missing from the catalog", path)
+			} else {
+				checkCommandInfo(t, child, info)
+			}
+			if visible[path] == childHidden {
+				t.Errorf("%s is hidden %v but listed in Catalog() %v", path, childHidden, visible[path])
+			}
+			walk(child, childHidden)
+		}
+	}
+	walk(root, false)
+
+	if walked == 0 {
+		t.Fatal("the root command has no subcommands")
+	}
+	if len(catalog) != walked {
+		t.Errorf("catalog lists %d commands, the command tree has %d", len(catalog), walked)
+	}
+}
+
+// checkCommandInfo compares catalog metadata with the command it describes
+func checkCommandInfo(t *testing.T, c *cobra.Command, info CommandInfo) {
+	t.Helper()
+	if info.Name != c.Name() || info.Description != c.Short {
+		t.Errorf("%s listed as %q: %q", c.CommandPath(), info.Name, info.Description)
+	}
+	flags := make(map[string]bool, len(info.Flags))
+	for _, flag := range info.Flags {
+		flags[flag.Name] = true
+	}
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && f.Name != "help" && !flags[f.Name] {
+			t.Errorf("%s is missing flag --%s in the catalog", c.CommandPath(), f.Name)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +18,11 @@ var checkCmd = &cobra.Command{
 	Long: `Run preflight checks to verify system readiness for RedTriage operations.
 Checks include system requirements, permissions, and available tools.`,
 	Args: cobra.NoArgs,
-	RunE: runCheck,
+	Example: `  RedTriage check
+  RedTriage check --verbose
+  RedTriage check --format json`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runCheck,
 }
 
 var (
@@ -27,9 +32,9 @@ var (
 )
 
 func init() {
-	checkCmd.Flags().StringVar(&checkOutput, "output", "", "Output directory for check results")
-	checkCmd.Flags().StringVar(&checkFormat, "format", "text", "Output format (text, json, yaml)")
-	checkCmd.Flags().BoolVar(&checkVerbose, "verbose", false, "Show detailed check information")
+	checkCmd.Flags().StringVarP(&checkOutput, "output", "o", "", "Output directory for check results")
+	checkCmd.Flags().StringVarP(&checkFormat, "format", "f", "text", "Output format (text, json, yaml)")
+	checkCmd.Flags().BoolVarP(&checkVerbose, "verbose", "v", false, "Show detailed check information")
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
@@ -44,12 +49,13 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize output manager: %w", err)
 	}
 	defer om.Close()
+	om.SetQuiet(quiet)
 
 	// Validate inputs
 	if err := validateCheckInputs(om); err != nil {
 		om.LogError(err, "Input validation failed")
 		om.PrintSummary()
-		return err
+		return rterrors.Wrap(rterrors.Validation, err)
 	}
 
 	om.LogInfo("Starting RedTriage preflight checks...")
@@ -1,15 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/redtriage/redtriage/collector"
 	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
 	"github.com/redtriage/redtriage/internal/output"
 
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/packager"
 	"github.com/redtriage/redtriage/reporter"
 	"github.com/spf13/cobra"
@@ -21,7 +30,22 @@ var collectCmd = &cobra.Command{
 	Long: `Collect system artifacts, run detections, and package everything into a triage bundle.
 This is the main command for incident response triage.`,
 	Args: cobra.NoArgs,
-	RunE: runCollect,
+	Example: `  RedTriage collect
+  RedTriage collect --output ./evidence
+  RedTriage collect --extended --timeout 600
+  RedTriage collect --network-capture 60s
+  RedTriage collect --profile-timing --skip event_logs
+  RedTriage collect --offline-root /mnt/evidence/C --extended
+  RedTriage collect --wsl-windows-host
+  RedTriage collect --name '{{.Host}}-{{.Date}}'
+  RedTriage collect --compress-artifacts
+  RedTriage collect --compress-artifacts=zstd
+  RedTriage collect --collectors ./site-collectors.yml
+  RedTriage collect --incident INC-001 --dry-run
+  RedTriage collect --incident INC-001 --override-scope 'Counsel approved mailbox collection, ticket LEG-42'
+  RedTriage collect --find --glob '*.hta;*.lnk' --paths 'C:\Users' --mtime-within 168h`,
+	Annotations: map[string]string{"category": "Collection"},
+	RunE:        runCollect,
 }
 
 var (
@@ -30,6 +54,29 @@ var (
 	excludeSpecific    []string
 	compressionType    string
 	createChecksums    bool
+	networkCapture     time.Duration
+	findFiles          bool
+	findGlobs          string
+	findPaths          string
+	findMTimeWithin    time.Duration
+	findMaxResults     int
+	findMaxDepth       int
+	findRate           int
+	profileTiming      bool
+	collectorsFile     string
+	collectSealKey     string
+	compressArtifacts  string
+	wslWindowsHost     bool
+	includeHidden      bool
+
+	cloudCredentialContent bool
+	carveImage             bool
+	carveSources           []string
+	collectYes             bool
+	collectIncident        string
+	overrideScope          string
+	allowDirs              []string
+	denyDirs               []string
 )
 
 func init() {
@@ -54,9 +101,34 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 	collectCmd.Flags().StringSliceVar(&excludeSpecific, "skip", nil, "Artifacts to skip")
 	collectCmd.Flags().StringVar(&compressionType, "compression", "zip", "Compression type (zip, tar.gz, none)")
 	collectCmd.Flags().BoolVar(&createChecksums, "checksums", true, "Create checksums for collected artifacts")
+	collectCmd.Flags().DurationVar(&networkCapture, "network-capture", 0, "Capture packets on the primary interface for the given duration (e.g. 60s)")
+	collectCmd.Flags().BoolVar(&findFiles, "find", false, "Run a targeted file sweep instead of a full collection")
+	collectCmd.Flags().StringVar(&findGlobs, "glob", "", "File name patterns for --find, separated by ';' (e.g. '*.hta;*.lnk')")
+	collectCmd.Flags().StringVar(&findPaths, "paths", "", "Root directories for --find, separated by ';'")
+	collectCmd.Flags().DurationVar(&findMTimeWithin, "mtime-within", 0, "Only match files modified within this window (e.g. 168h)")
+	collectCmd.Flags().IntVar(&findMaxResults, "max-results", collector.DefaultSweepMaxResults, "Maximum number of files --find records")
+	collectCmd.Flags().IntVar(&findMaxDepth, "max-depth", collector.DefaultSweepMaxDepth, "Maximum directory depth --find descends below each root, or file metadata collectors list (default: per artifact)")
+	collectCmd.Flags().StringSliceVar(&allowDirs, "allow-dir", nil, "Only walk into directories matching these globs in file metadata collectors (name or path below the walked directory)")
+	collectCmd.Flags().StringSliceVar(&denyDirs, "deny-dir", nil, "Never walk into directories matching these globs in file metadata collectors, on top of file_collection.deny_dirs")
+	collectCmd.Flags().BoolVar(&includeHidden, "include-hidden", false, "Also walk hidden (dot) files and directories in --find and file metadata collectors; known hidden persistence files are always read")
+	collectCmd.Flags().IntVar(&findRate, "find-rate", 5000, "Maximum entries per second --find examines (0 = unlimited)")
+	collectCmd.Flags().StringVar(&imageRoot, "offline-root", "", "Collect from a mounted forensic image or offline directory at this path (same as --root)")
+	collectCmd.Flags().BoolVar(&carveImage, "carve", false, "With --root, carve deleted prefetch files, event records and log lines from raw image data (slow)")
+	collectCmd.Flags().StringSliceVar(&carveSources, "carve-source", nil, "Raw partition image or unallocated space file for --carve (default: $MFT, $LogFile and page/swap files in the image)")
+	collectCmd.Flags().StringVar(&collectIncident, "incident", "", "Collect for this incident of the interactive session, enforcing its collection scope")
+	collectCmd.Flags().StringVar(&overrideScope, "override-scope", "", "Collect outside the incident's collection scope; the justification goes to the audit and custody logs")
+	collectCmd.Flags().BoolVarP(&collectYes, "yes", "y", false, "Start without the confirmation summary (for automation)")
+	collectCmd.Flags().BoolVar(&profileTiming, "profile-timing", false, "Print artifacts sorted by collection time when the collection finishes")
+	collectCmd.Flags().BoolVar(&wslWindowsHost, "wsl-windows-host", false, "Inside WSL, also collect the Windows side from "+collector.WSLWindowsRoot+" and through interop")
+	collectCmd.Flags().BoolVar(&cloudCredentialContent, "cloud-credential-content", false, "Copy the contents of cloud CLI credential files, not just their metadata")
+	collectCmd.Flags().StringVar(&compressArtifacts, "compress-artifacts", "", "Compress each text artifact inside the bundle: gzip (the default with no value) or zstd (needs the zstd tool)")
+	collectCmd.Flags().Lookup("compress-artifacts").NoOptDefVal = packager.CompressionGzip
+	collectCmd.Flags().StringVar(&bundleName, "name", "", "Bundle filename template for this collection, overriding filename_templates.bundle (e.g. '{{.Host}}-{{.Date}}')")
+	collectCmd.Flags().StringVar(&collectSealKey, "seal-key", "", "Seal artifacts with this key file (default: a new per-collection key, saved encrypted when REDTRIAGE_SEAL_PASSPHRASE is set)")
+	collectCmd.Flags().StringVar(&collectorsFile, "collectors", "", "YAML file of command-based collectors to run (default ./"+collector.DefaultCustomCollectorsFile+" when present)")
 }
 
-func runCollect(cmd *cobra.Command, args []string) error {
+func runCollect(cmd *cobra.Command, args []string) (err error) {
 	// Initialize output manager
 	outputDir := outputDir
 	if outputDir == "" {
@@ -68,15 +140,18 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize output manager: %w", err)
 	}
 	defer om.Close()
+	om.SetQuiet(quiet)
 
 	// Validate inputs
 	if err := validateCollectInputs(om); err != nil {
 		om.LogError(err, "Input validation failed")
 		om.PrintSummary()
-		return err
+		return rterrors.Wrap(rterrors.Validation, err)
 	}
 
-	om.LogInfo("Starting RedTriage collection...")
+	if findFiles {
+		return runFileSweep(om, outputDir)
+	}
 
 	// Initialize components
 	collectorInstance := collector.NewCollector()
@@ -102,6 +177,20 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		om.PrintSummary()
 		return err
 	}
+	sealKey, err := collectionSealKey()
+	if err != nil {
+		om.LogError(err, "Seal key unavailable")
+		om.PrintSummary()
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
+	packagerInstance.SetSealKey(sealKey)
+	codec, err := packager.ValidateCompression(compressArtifacts)
+	if err != nil {
+		om.LogError(err, "Artifact compression unavailable")
+		om.PrintSummary()
+		return err
+	}
+	packagerInstance.SetCompression(codec)
 
 	reporterInstance := reporter.NewReporter()
 	if reporterInstance == nil {
@@ -110,6 +199,13 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		om.PrintSummary()
 		return err
 	}
+	names, err := newCollectionNaming(om)
+	if err != nil {
+		om.LogError(err, "Filename templates are invalid")
+		om.PrintSummary()
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
+	names.apply(packagerInstance, reporterInstance)
 
 	// Set collection profile
 	profile := collector.CollectionProfile{
@@ -117,10 +213,122 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		Timeout:  time.Duration(timeout) * time.Second,
 		Include:  includeSpecific,
 		Exclude:  excludeSpecific,
+		ReadOnly: footprint.Current().IsMinimal(),
+		Root:     imageRoot,
+
+		WSLWindowsHost:         wslWindowsHost,
+		CloudCredentialContent: cloudCredentialContent,
+		Carve:                  carveImage,
+		CarveSources:           carveSources,
+	}
+
+	scope, err := fileWalkScope(cmd)
+	if err != nil {
+		om.LogError(err, "Input validation failed")
+		om.PrintSummary()
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
+	profile.Scope = scope
+
+	if imageRoot != "" {
+		om.LogInfo("Offline mode: reading artifacts from image mounted at %s (detected %s)", imageRoot, collector.DetectImageOS(imageRoot))
+		if carveImage {
+			om.LogInfo("Carving deleted artifacts from raw image data; this reads every source in full and can take a long time")
+		}
+	} else if env := collector.DetectEnvironment(); env.Isolated() {
+		if wslWindowsHost {
+			env.WindowsCollected = true
+			om.LogInfo("Running inside %s; collecting the Windows side from %s and through interop", env, env.WindowsRoot)
+		}
+		for _, warning := range env.Warnings() {
+			om.LogWarning("%s", warning)
+		}
+	}
+
+	custom, err := loadCustomCollectors(om)
+	if err != nil {
+		om.LogError(err, "Custom collectors could not be loaded")
+		om.PrintSummary()
+		return rterrors.Wrap(rterrors.Validation, err)
 	}
+	profile.Custom = custom
 
-	om.LogInfo("Collection profile: extended=%v, timeout=%s, include=%v, exclude=%v",
-		extendedCollection, profile.Timeout, includeSpecific, excludeSpecific)
+	if cmd.Flags().Changed("override-scope") && strings.TrimSpace(overrideScope) == "" {
+		err := rterrors.Validationf("--override-scope requires a justification")
+		om.LogError(err, "Input validation failed")
+		om.PrintSummary()
+		return err
+	}
+	if collectIncident != "" {
+		authorized, err := incidentScope(collectIncident)
+		if err != nil {
+			om.LogError(err, "Incident unavailable")
+			om.PrintSummary()
+			return err
+		}
+		if authorized == nil && overrideScope != "" {
+			err := rterrors.Validationf("--override-scope: incident %s has no collection scope", collectIncident)
+			om.LogError(err, "Input validation failed")
+			om.PrintSummary()
+			return err
+		}
+		profile.Authorized = authorized
+		profile.ScopeIncident = collectIncident
+		profile.ScopeOverride = overrideScope
+	}
+
+	if dryRun {
+		return printCollectionPlan(om, profile, outputDir)
+	}
+
+	// Ask before collecting, once everything that will run is known
+	if err := confirmCollection(om, profile, outputDir); err != nil {
+		om.PrintSummary()
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	om.LogInfo("Starting RedTriage collection...")
+	recordAudit(audit.CollectionStarted, collectIncident, outputDir, os.Args[1:], nil)
+	defer func() {
+		recordAudit(audit.CollectionFinished, collectIncident, outputDir, os.Args[1:], err)
+	}()
+
+	if profile.Authorized != nil {
+		om.LogInfo("Collection scope of %s: %s", collectIncident, profile.Authorized)
+		if overrideScope != "" {
+			om.LogWarning("Collection scope overridden; artifacts outside it are collected: %s", overrideScope)
+			footprint.Current().RecordOptIn("collection scope override", fmt.Sprintf("%s (%s): %s", collectIncident, profile.Authorized, overrideScope))
+			recordAudit(audit.ScopeOverridden, collectIncident, outputDir, map[string]string{
+				"scope":         profile.Authorized.String(),
+				"justification": overrideScope,
+			}, nil)
+		}
+	}
+
+	if cloudCredentialContent {
+		om.LogWarning("Cloud credential file contents will be copied into the bundle; handle it as a secret")
+		footprint.Current().RecordOptIn("cloud credential content", "--cloud-credential-content: AWS, Azure and gcloud credential files copied in full")
+		recordAudit(audit.CredentialsRead, "", outputDir, map[string]string{"scope": "cloud credential files"}, nil)
+	}
+
+	om.LogInfo("Collection profile: extended=%v, timeout=%s, include=%v, exclude=%v, footprint=%s",
+		extendedCollection, profile.Timeout, includeSpecific, excludeSpecific, footprint.Current().Mode)
+	depth := "per artifact"
+	if profile.Scope.MaxDepth > 0 {
+		depth = fmt.Sprint(profile.Scope.MaxDepth)
+	}
+	om.LogInfo("File walks: max depth %s, allow %v, deny %v, hidden %v", depth, profile.Scope.Allow, profile.Scope.Deny, profile.Scope.IncludeHidden)
+
+	// Start the packet capture so it runs alongside the connection snapshot
+	var captureDone chan captureOutcome
+	if networkCapture > 0 {
+		if ok, why := profile.Authorized.Permits("network_capture", "network"); ok || overrideScope != "" {
+			captureDone = startNetworkCapture(om, outputDir)
+		} else {
+			om.LogWarning("Skipping network capture: %s", why)
+		}
+	}
 
 	// Collect artifacts
 	om.LogInfo("Collecting artifacts...")
@@ -131,13 +339,55 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("collection failed: %w", err)
 	}
 
+	if captureDone != nil {
+		if capture := finishNetworkCapture(om, captureDone); capture != nil {
+			results = append(results, capture.ArtifactResult())
+		}
+	}
+
 	om.LogSuccess("Artifact collection completed successfully")
 	om.LogInfo("Collected %d artifacts", len(results))
 
+	// Flag earlier bundles that claim this hostname from another machine
+	identity, _ := collector.FindHostIdentity(results)
+	om.LogInfo("Host %s, fingerprint %s", identity.Hostname, identity.ShortFingerprint())
+	if conflicts := packager.HostConflicts(outputDir, identity); len(conflicts) > 0 {
+		for _, conflict := range conflicts {
+			om.LogWarning("Bundle %s also claims hostname %s but has fingerprint %s; it may come from a different machine",
+				conflict.Source, conflict.Hostname, conflict.Fingerprint)
+		}
+		collector.SetHostConflicts(results, conflicts)
+		identity.HostnameConflicts = conflicts
+	}
+
+	timings := collector.ArtifactTimings(results)
+	slowest := make([]string, 0, 3)
+	for _, timing := range timings {
+		if len(slowest) == cap(slowest) {
+			break
+		}
+		slowest = append(slowest, fmt.Sprintf("%s (%s)", timing.Name, timing.Duration.Round(time.Millisecond)))
+	}
+	if len(slowest) > 0 {
+		om.LogInfo("Slowest artifacts: %s", strings.Join(slowest, ", "))
+	}
+
 	// Count artifacts by category
 	artifactCounts := make(map[string]int)
 	errorCount := 0
+	unavailableCount := 0
+	outOfScopeCount := 0
 	for _, result := range results {
+		if errors.Is(result.Error, collector.ErrLiveOnly) {
+			unavailableCount++
+			om.LogInfo("Skipped live-only artifact %s: unavailable in offline mode", result.Artifact.Name)
+			continue
+		}
+		if result.Reason == collector.ReasonScopeDenied {
+			outOfScopeCount++
+			om.LogInfo("Skipped artifact %s: outside the collection scope", result.Artifact.Name)
+			continue
+		}
 		if result.Error != nil {
 			errorCount++
 			om.LogWarning("Failed to collect artifact %s: %v", result.Artifact.Name, result.Error)
@@ -153,6 +403,31 @@ func runCollect(cmd *cobra.Command, args []string) error {
 	if errorCount > 0 {
 		om.LogWarning("  Failed: %d artifacts", errorCount)
 	}
+	if unavailableCount > 0 {
+		om.LogInfo("  Unavailable offline: %d artifacts", unavailableCount)
+	}
+	if outOfScopeCount > 0 {
+		om.LogInfo("  Outside the collection scope: %d artifacts", outOfScopeCount)
+	}
+
+	// Reassemble PowerShell script blocks so complete scripts are packaged with hashes
+	if scripts := detector.ScriptBlockArtifacts(results); len(scripts) > 0 {
+		om.LogInfo("Reassembled %d PowerShell script blocks", len(scripts))
+		results = append(results, scripts...)
+	}
+
+	// Parse the effective security policy into structured records
+	if posture := detector.SecurityPostureArtifacts(results); len(posture) > 0 {
+		om.LogInfo("Parsed security posture from policy artifacts")
+		results = append(results, posture...)
+	}
+
+	// Parse processes, connections, scheduled tasks and event logs into
+	// structured records that do not depend on the host's display language
+	if records := detector.HostRecordArtifacts(results); len(records) > 0 {
+		om.LogInfo("Parsed %d structured record sets", len(records))
+		results = append(results, records...)
+	}
 
 	// Run detections
 	om.LogInfo("Running detections...")
@@ -165,10 +440,17 @@ func runCollect(cmd *cobra.Command, args []string) error {
 
 	om.LogSuccess("Detection analysis completed successfully")
 	om.LogInfo("Found %d findings", len(findings))
+	for _, warning := range detectorInstance.Warnings() {
+		om.LogWarning("%s", warning)
+	}
+	if gaps := detector.CoverageGaps(results); len(gaps) > 0 {
+		om.LogWarning("%d artifacts could not be examined (access denied, tool missing or timed out); no findings there is not a clean result", len(gaps))
+	}
 
 	// Package results
 	om.LogInfo("Packaging results...")
 	bundlePath, err := packagerInstance.CreateBundle(results, findings, outputDir)
+	recordAudit(audit.BundleCreated, "", bundlePath, os.Args[1:], err)
 	if err != nil {
 		om.LogError(err, "Packaging failed")
 		om.PrintSummary()
@@ -177,6 +459,7 @@ func runCollect(cmd *cobra.Command, args []string) error {
 
 	om.LogSuccess("Bundle creation completed successfully")
 	om.LogInfo("Bundle created at: %s", bundlePath)
+	sealKeyPath := finishSealing(om, bundlePath, sealKey)
 
 	// Generate reports
 	om.LogInfo("Generating reports...")
@@ -189,6 +472,7 @@ func runCollect(cmd *cobra.Command, args []string) error {
 
 	om.LogSuccess("Report generation completed successfully")
 	om.LogInfo("Reports generated: %v", reports)
+	names.record(om, packagerInstance, bundlePath)
 
 	// Add final results
 	om.AddResult(output.Result{
@@ -197,14 +481,20 @@ func runCollect(cmd *cobra.Command, args []string) error {
 		Message: "Triage collection completed successfully",
 		Data: map[string]interface{}{
 			"total_artifacts":      len(results),
-			"successful_artifacts": len(results) - errorCount,
+			"successful_artifacts": len(results) - errorCount - unavailableCount - outOfScopeCount,
 			"failed_artifacts":     errorCount,
+			"unavailable_offline":  unavailableCount,
+			"out_of_scope":         outOfScopeCount,
+			"image_root":           imageRoot,
+			"host":                 identity,
 			"findings_count":       len(findings),
 			"bundle_path":          bundlePath,
 			"reports":              reports,
 			"output_directory":     outputDir,
 			"extended_collection":  extendedCollection,
 			"timeout":              timeout,
+			"network_capture":      networkCapture.String(),
+			"artifact_timings":     timings,
 		},
 		Metadata: map[string]interface{}{
 			"collection_mode": "full_triage",
@@ -216,10 +506,281 @@ func runCollect(cmd *cobra.Command, args []string) error {
 	om.LogSuccess("Triage complete! Bundle created at: %s", bundlePath)
 	om.LogInfo("Reports generated: %v", reports)
 
+	// Document everything written during the run for chain of custody
+	policy := footprint.Current()
+	if policy.IsMinimal() {
+		policy.RecordWrite(outputDir, "collection log and output directory", false)
+		policy.RecordWrite(bundlePath, "triage bundle", false)
+		if sealKeyPath != "" {
+			policy.RecordWrite(sealKeyPath, "artifact seal key", false)
+		}
+		for _, report := range reports {
+			policy.RecordWrite(report.Path, fmt.Sprintf("triage report (%s)", report.Type), false)
+		}
+		custodyPath, err := policy.WriteCustodyLog()
+		if err != nil {
+			om.LogWarning("Failed to write custody log: %v", err)
+		} else {
+			om.LogInfo("Custody log written to: %s", custodyPath)
+		}
+	}
+
+	om.PrintSummary()
+	if profileTiming {
+		printArtifactTimings(timings)
+	}
+	return nil
+}
+
+// collectionSealKey returns the key a collection's artifacts are sealed
+// with: the analyst's --seal-key or, when REDTRIAGE_SEAL_PASSPHRASE is set
+// to encrypt it, a new key for this collection alone. With neither there is
+// no safe place to keep a key, so it returns nil and nothing is sealed.
+func collectionSealKey() (*packager.SealKey, error) {
+	if collectSealKey != "" {
+		key, err := packager.LoadSealKey(collectSealKey, os.Getenv(packager.SealPassphraseEnv))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load --seal-key: %w", err)
+		}
+		return key, nil
+	}
+	if os.Getenv(packager.SealPassphraseEnv) == "" {
+		return nil, nil
+	}
+	return packager.NewEphemeralSealKey()
+}
+
+// finishSealing records the sealing of a bundle in the audit log and, for
+// a per-collection key, saves the key beside the bundle encrypted with
+// REDTRIAGE_SEAL_PASSPHRASE. It returns the key file written, if any.
+func finishSealing(om *output.OutputManager, bundlePath string, key *packager.SealKey) string {
+	if key == nil {
+		om.LogWarning("Artifacts not sealed: pass --seal-key <file> or set %s to seal them", packager.SealPassphraseEnv)
+		return ""
+	}
+	if !key.Ephemeral {
+		recordAudit(audit.BundleSigned, "", bundlePath, map[string]string{"key_id": key.ID}, nil)
+		om.LogInfo("Artifacts sealed with key %s", key.ID)
+		return ""
+	}
+
+	keyPath := strings.TrimSuffix(bundlePath, filepath.Ext(bundlePath)) + ".sealkey"
+	err := packager.SaveSealKey(keyPath, key, os.Getenv(packager.SealPassphraseEnv))
+	recordAudit(audit.BundleSigned, "", bundlePath, map[string]string{"key_id": key.ID, "key_file": keyPath}, err)
+	if err != nil {
+		om.LogWarning("Failed to save the seal key; the artifact seals cannot be verified: %v", err)
+		return ""
+	}
+	om.LogInfo("Artifacts sealed with key %s, saved encrypted to %s", key.ID, keyPath)
+	return keyPath
+}
+
+// printArtifactTimings prints a table of artifacts, slowest first, with each
+// artifact's share of the total collection time
+func printArtifactTimings(timings []collector.ArtifactTiming) {
+	var total time.Duration
+	for _, timing := range timings {
+		total += timing.Duration
+	}
+
+	fmt.Println()
+	fmt.Println("Artifact collection timing (slowest first):")
+	fmt.Printf("%-32s %-14s %12s %7s  %s\n", "Artifact", "Category", "Duration", "Share", "Status")
+	for _, timing := range timings {
+		share := 0.0
+		if total > 0 {
+			share = float64(timing.Duration) / float64(total) * 100
+		}
+		status := "ok"
+		if timing.Failed {
+			status = "failed"
+		}
+		fmt.Printf("%-32s %-14s %12s %6.1f%%  %s\n", timing.Name, timing.Category, timing.Duration.Round(time.Microsecond), share, status)
+	}
+	fmt.Printf("%-32s %-14s %12s\n", "Total", "", total.Round(time.Microsecond))
+}
+
+// captureOutcome carries the result of a background network capture
+type captureOutcome struct {
+	capture *collector.NetworkCapture
+	err     error
+}
+
+// startNetworkCapture runs the packet capture in the background and delivers
+// the outcome on the returned channel
+func startNetworkCapture(om *output.OutputManager, outputDir string) chan captureOutcome {
+	opts := networkCaptureOptions(om, outputDir)
+
+	om.LogInfo("Starting network capture for %s...", networkCapture)
+
+	done := make(chan captureOutcome, 1)
+	go func() {
+		capture, err := collector.CaptureNetwork(context.Background(), opts)
+		done <- captureOutcome{capture: capture, err: err}
+	}()
+
+	return done
+}
+
+// networkCaptureOptions returns the --network-capture settings from the
+// plugin configuration
+func networkCaptureOptions(om *output.OutputManager, outputDir string) collector.CaptureOptions {
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		om.LogWarning("Failed to load configuration, using default capture settings: %v", err)
+		cfg = config.DefaultConfig()
+	}
+
+	return collector.CaptureOptions{
+		Duration:    networkCapture,
+		Tool:        cfg.Plugins.CaptureTool,
+		TcpdumpPath: cfg.Plugins.TcpdumpPath,
+		DumpcapPath: cfg.Plugins.DumpcapPath,
+		Interface:   cfg.Plugins.CaptureInterface,
+		Filter:      cfg.Plugins.CaptureFilter,
+		OutputDir:   outputDir,
+	}
+}
+
+// finishNetworkCapture waits for the capture and logs its outcome. Capture
+// problems are warnings so they never fail the collection.
+func finishNetworkCapture(om *output.OutputManager, done chan captureOutcome) *collector.NetworkCapture {
+	om.LogInfo("Waiting for network capture to finish...")
+	outcome := <-done
+
+	switch {
+	case outcome.err != nil:
+		om.LogWarning("Network capture failed: %v", outcome.err)
+		return nil
+	case outcome.capture.Skipped:
+		om.LogWarning("Network capture skipped: %s", outcome.capture.Reason)
+	default:
+		capture := outcome.capture
+		om.LogSuccess("Captured %d bytes on %s with %s (filter: %q)", capture.Size, capture.Interface, capture.Tool, capture.Filter)
+		om.LogInfo("Capture saved to %s (sha256: %s)", capture.Path, capture.Checksum)
+		footprint.Current().RecordWrite(capture.Path, "network packet capture", false)
+	}
+
+	return outcome.capture
+}
+
+// runFileSweep runs a targeted file sweep and saves it as a standalone
+// artifact. Ctrl+C stops the walk and keeps the matches found so far.
+func runFileSweep(om *output.OutputManager, outputDir string) error {
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		om.LogWarning("Failed to load configuration, using default size budget: %v", err)
+		cfg = config.DefaultConfig()
+	}
+
+	// In offline mode sweep paths are relative to the image root
+	roots := splitList(findPaths)
+	for i, root := range roots {
+		roots[i] = collector.ResolveRootPath(imageRoot, root)
+	}
+
+	opts := collector.SweepOptions{
+		Roots:          roots,
+		Globs:          splitList(findGlobs),
+		MTimeWithin:    findMTimeWithin,
+		MaxDepth:       findMaxDepth,
+		MaxResults:     findMaxResults,
+		MaxHashBytes:   cfg.GetMaxArtifactSize(),
+		FilesPerSecond: findRate,
+		IncludeHidden:  includeHidden || cfg.FileCollection.IncludeHidden,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	om.LogInfo("Sweeping %v for %v (max depth %d, max results %d)", opts.Roots, opts.Globs, opts.MaxDepth, opts.MaxResults)
+	sweep, err := collector.SweepFiles(ctx, opts)
+	if err != nil {
+		om.LogError(err, "File sweep failed")
+		om.PrintSummary()
+		return fmt.Errorf("file sweep failed: %w", err)
+	}
+
+	switch {
+	case sweep.Cancelled:
+		om.LogWarning("File sweep cancelled; keeping %d matches found so far", len(sweep.Matches))
+	case sweep.Truncated:
+		om.LogWarning("File sweep stopped early: %s", sweep.TruncatedReason)
+	}
+	om.LogSuccess("Scanned %d entries in %s, %d matches", sweep.Scanned, sweep.Duration, len(sweep.Matches))
+	if sweep.SkippedHidden > 0 {
+		om.LogInfo("Skipped %d hidden entries; use --include-hidden to walk them", sweep.SkippedHidden)
+	}
+
+	data, err := json.MarshalIndent(sweep, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal file sweep: %w", err)
+	}
+
+	sweepPath := filepath.Join(outputDir, fmt.Sprintf("file-sweep-%s.json", sweep.StartedAt.Format("20060102-150405")))
+	if err := output.WriteFileAtomic(sweepPath, data, 0644); err != nil {
+		om.LogError(err, "Failed to save file sweep")
+		om.PrintSummary()
+		return fmt.Errorf("failed to save file sweep: %w", err)
+	}
+	footprint.Current().RecordWrite(sweepPath, "file sweep results", false)
+
+	om.AddResult(output.Result{
+		Type:    "file_sweep",
+		Status:  "success",
+		Message: "File sweep completed",
+		Data: map[string]interface{}{
+			"roots":        sweep.Roots,
+			"globs":        sweep.Globs,
+			"mtime_within": sweep.MTimeWithin,
+			"scanned":      sweep.Scanned,
+			"matches":      len(sweep.Matches),
+			"truncated":    sweep.Truncated,
+			"cancelled":    sweep.Cancelled,
+			"output_file":  sweepPath,
+		},
+	})
+
+	om.LogSuccess("File sweep saved to: %s", sweepPath)
 	om.PrintSummary()
 	return nil
 }
 
+// splitList splits a ';' separated flag value, dropping empty entries
+// loadCustomCollectors loads the collectors file into the enhanced artifact
+// registry and returns the collectors for this platform. The default file is
+// optional; one named with --collectors must exist.
+func loadCustomCollectors(om *output.OutputManager) ([]collector.EnhancedArtifact, error) {
+	path := collectorsFile
+	if path == "" {
+		path = collector.DefaultCustomCollectorsFile
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+
+	definitions, err := collector.LoadCustomCollectors(path)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := collector.NewEnhancedArtifactRegistry()
+	registry.RegisterCustom(definitions)
+	custom := registry.CustomArtifacts()
+	om.LogInfo("Loaded %d custom collectors from %s (%d for this platform)", len(definitions), path, len(custom))
+	return custom, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func validateCollectInputs(om *output.OutputManager) error {
 	// Basic validation using simple approach
 
@@ -238,11 +799,88 @@ func validateCollectInputs(om *output.OutputManager) error {
 		}
 	}
 
+	if err := validateBundleName(); err != nil {
+		return err
+	}
+
 	// Validate timeout
 	if timeout <= 0 {
 		return fmt.Errorf("timeout must be positive, got %d", timeout)
 	}
 
+	// Validate network capture duration
+	if networkCapture < 0 {
+		return fmt.Errorf("network capture duration must be positive, got %s", networkCapture)
+	}
+	if networkCapture > 0 && networkCapture < time.Second {
+		return fmt.Errorf("network capture duration must be at least 1s, got %s", networkCapture)
+	}
+	if networkCapture > 0 && imageRoot != "" {
+		return fmt.Errorf("--network-capture requires a live host and cannot be used with --root")
+	}
+	if overrideScope != "" && collectIncident == "" {
+		return fmt.Errorf("--override-scope requires --incident")
+	}
+
+	// Validate carving, which reads raw image data
+	if carveImage && imageRoot == "" {
+		return fmt.Errorf("--carve recovers deleted artifacts from a disk image and requires --root")
+	}
+	if len(carveSources) > 0 && !carveImage {
+		return fmt.Errorf("--carve-source requires --carve")
+	}
+	for _, source := range carveSources {
+		if info, err := os.Stat(source); err != nil {
+			return fmt.Errorf("invalid carve source: %w", err)
+		} else if info.IsDir() {
+			return fmt.Errorf("carve source %s is a directory, not a raw image file", source)
+		}
+	}
+
+	// Validate WSL Windows-side collection
+	if wslWindowsHost {
+		if imageRoot != "" {
+			return fmt.Errorf("--wsl-windows-host collects the live Windows side and cannot be used with --root")
+		}
+		if env := collector.DetectEnvironment(); env.Kind != collector.EnvironmentWSL {
+			return fmt.Errorf("--wsl-windows-host requires running inside WSL, detected %s", env)
+		} else if env.WindowsRoot == "" {
+			return fmt.Errorf("--wsl-windows-host requires the Windows system drive mounted at %s", collector.WSLWindowsRoot)
+		}
+	}
+
+	// Validate file sweep options
+	if findFiles {
+		if len(splitList(findPaths)) == 0 {
+			return fmt.Errorf("--find requires --paths")
+		}
+		if len(splitList(findGlobs)) == 0 {
+			return fmt.Errorf("--find requires --glob")
+		}
+		for _, glob := range splitList(findGlobs) {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				return fmt.Errorf("invalid glob '%s': %w", glob, err)
+			}
+		}
+	}
+	if findMTimeWithin < 0 {
+		return fmt.Errorf("--mtime-within must be positive, got %s", findMTimeWithin)
+	}
+	if findMaxResults <= 0 {
+		return fmt.Errorf("--max-results must be positive, got %d", findMaxResults)
+	}
+	if findMaxDepth <= 0 {
+		return fmt.Errorf("--max-depth must be positive, got %d", findMaxDepth)
+	}
+	if findRate < 0 {
+		return fmt.Errorf("--find-rate cannot be negative, got %d", findRate)
+	}
+	for _, dir := range append(append([]string{}, allowDirs...), denyDirs...) {
+		if err := collector.ValidateDirPattern(dir); err != nil {
+			return fmt.Errorf("invalid --allow-dir or --deny-dir: %w", err)
+		}
+	}
+
 	// Validate compression type
 	allowedCompression := []string{"zip", "tar.gz", "none"}
 	compressionValid := false
@@ -284,3 +922,29 @@ func validateCollectInputs(om *output.OutputManager) error {
 
 	return nil
 }
+
+// fileWalkScope combines the file_collection settings with --max-depth,
+// --allow-dir, --deny-dir and --include-hidden into the override of every artifact's default
+// walk scope
+func fileWalkScope(cmd *cobra.Command) (collector.WalkScope, error) {
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	scope := collector.WalkScope{
+		MaxDepth:      cfg.FileCollection.MaxDepth,
+		Allow:         cfg.FileCollection.AllowDirs,
+		Deny:          append(append([]string{}, cfg.FileCollection.DenyDirs...), denyDirs...),
+		IncludeHidden: cfg.FileCollection.IncludeHidden || includeHidden,
+	}
+	if cmd.Flags().Changed("max-depth") {
+		scope.MaxDepth = findMaxDepth
+	}
+	if len(allowDirs) > 0 {
+		scope.Allow = allowDirs
+	}
+	if err := scope.Validate(); err != nil {
+		return scope, fmt.Errorf("invalid file collection scope: %w", err)
+	}
+	return scope, nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/spf13/cobra"
 	"strings"
 )
@@ -11,8 +12,12 @@ var configCmd = &cobra.Command{
 	Short: "Manage configuration settings",
 	Long: `Manage RedTriage configuration settings including viewing, editing, and validating config files.
 Supports both local and global configuration management.`,
-	Args:  cobra.NoArgs,
-	RunE:  runConfig,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage config --show
+  RedTriage config --validate
+  RedTriage config --reset`,
+	Annotations: map[string]string{"category": "Configuration"},
+	RunE:        runConfig,
 }
 
 var (
@@ -34,7 +39,7 @@ func init() {
 func runConfig(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateConfigInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("Configuration Management")
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// Answers to the collection confirmation, as the custody log records them
+const (
+	confirmAccepted       = "confirmed"
+	confirmHostnameTyped  = "confirmed by typing the hostname"
+	confirmDeclined       = "declined"
+	confirmSkipped        = "not asked: --yes"
+	confirmNotInteractive = "not asked: not running interactively"
+)
+
+// confirmCollection shows what the collection will do to the target host
+// and asks to go ahead. A host matching a sensitive_hosts pattern needs its
+// hostname typed. Without a terminal, or with --yes, it goes ahead without
+// asking. The summary and the answer go to the custody and audit logs.
+func confirmCollection(om *output.OutputManager, profile collector.CollectionProfile, outputDir string) error {
+	plan := collectionPlan(om, profile, outputDir)
+	summary := plan.Summary()
+
+	decision := confirmSkipped
+	switch {
+	case collectYes:
+	case !readline.IsTerminal(int(os.Stdin.Fd())):
+		decision = confirmNotInteractive
+	default:
+		decision = askCollection(plan, summary)
+	}
+
+	footprint.Current().RecordConfirmation(summary, decision)
+	params := map[string]interface{}{
+		"decision":  decision,
+		"host":      plan.Host,
+		"profile":   plan.Profile,
+		"artifacts": plan.Artifacts,
+		"commands":  len(plan.Commands),
+	}
+	if plan.Sensitive != "" {
+		params["sensitive"] = plan.Sensitive
+	}
+	if plan.Scope != nil {
+		params["scope"] = plan.Scope.String()
+		params["scope_incident"] = plan.ScopeIncident
+		if plan.ScopeOverride != "" {
+			params["scope_override"] = plan.ScopeOverride
+		}
+	}
+
+	if decision != confirmDeclined {
+		recordAudit(audit.CollectionConfirm, profile.ScopeIncident, outputDir, params, nil)
+		return nil
+	}
+
+	err := fmt.Errorf("collection cancelled: not confirmed")
+	recordAudit(audit.CollectionConfirm, profile.ScopeIncident, outputDir, params, err)
+	om.LogWarning("Collection cancelled before anything was collected")
+	if footprint.Current().IsMinimal() {
+		if custodyPath, writeErr := footprint.Current().WriteCustodyLog(); writeErr == nil {
+			om.LogInfo("Custody log written to: %s", custodyPath)
+		}
+	}
+	return err
+}
+
+// collectionPlan describes what a collection with the profile will do
+func collectionPlan(om *output.OutputManager, profile collector.CollectionProfile, outputDir string) collector.CollectionPlan {
+	destination, err := filepath.Abs(outputDir)
+	if err != nil {
+		destination = outputDir
+	}
+	var capture collector.CaptureOptions
+	if networkCapture > 0 {
+		capture = networkCaptureOptions(om, outputDir)
+	}
+	plan := collector.PlanCollection(profile, collector.PlanOptions{Destination: destination, Capture: capture})
+	if profile.Root == "" {
+		cfg, err := config.LoadReadOnly()
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		plan.Sensitive = collector.SensitiveHostMatch(cfg.SensitiveHosts, plan.Host, plan.HostRoles)
+	}
+	return plan
+}
+
+// printCollectionPlan prints what collect --dry-run would do and collects
+// nothing
+func printCollectionPlan(om *output.OutputManager, profile collector.CollectionProfile, outputDir string) error {
+	plan := collectionPlan(om, profile, outputDir)
+	color.New(color.FgCyan, color.Bold).Println("Collection plan (dry run, nothing is collected)")
+	for _, line := range plan.Summary() {
+		fmt.Println("  " + line)
+	}
+	return nil
+}
+
+// askCollection prints the summary and reads the answer: y or yes, or the
+// hostname for a sensitive host
+func askCollection(plan collector.CollectionPlan, summary []string) string {
+	fmt.Println()
+	color.New(color.FgCyan, color.Bold).Println("Collection summary")
+	for _, line := range summary {
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+	if plan.Sensitive != "" {
+		color.New(color.FgYellow).Printf("%s is a sensitive host (%s).\n", plan.Host, plan.Sensitive)
+		fmt.Printf("Type the hostname to start collecting: ")
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		short, _, _ := strings.Cut(plan.Host, ".")
+		if answer != "" && (strings.EqualFold(answer, plan.Host) || strings.EqualFold(answer, short)) {
+			return confirmHostnameTyped
+		}
+		return confirmDeclined
+	}
+
+	fmt.Printf("Start collecting? [y/N]: ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		return confirmAccepted
+	}
+	return confirmDeclined
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +14,11 @@ var diagCmd = &cobra.Command{
 	Long: `Run comprehensive system diagnostics to identify potential issues
 with RedTriage operation and system compatibility.`,
 	Args: cobra.NoArgs,
-	RunE: runDiag,
+	Example: `  RedTriage diag
+  RedTriage diag --quick
+  RedTriage diag --full --output ./diagnostics.log`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runDiag,
 }
 
 var (
@@ -26,14 +31,14 @@ var (
 func init() {
 	diagCmd.Flags().BoolVar(&diagQuick, "quick", false, "Run quick diagnostics only")
 	diagCmd.Flags().BoolVar(&diagFull, "full", false, "Run full diagnostic suite")
-	diagCmd.Flags().StringVar(&diagOutput, "output", "", "Output diagnostic results to file")
+	diagCmd.Flags().StringVarP(&diagOutput, "output", "o", "", "Output file for the diagnostic results (one file, not a directory)")
 	diagCmd.Flags().BoolVar(&diagFix, "fix", false, "Attempt to fix detected issues")
 }
 
 func runDiag(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateDiagInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("System Diagnostics")
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Documentation utilities",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+}
+
+var docsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate markdown reference pages for every command",
+	Long: `Generate one markdown reference page per command from the command tree,
+plus an index page. The pages use the same metadata as 'tools --format json'.`,
+	Args: cobra.NoArgs,
+	RunE: runDocsGenerate,
+}
+
+var docsOutputDir string
+
+func init() {
+	docsGenerateCmd.Flags().StringVarP(&docsOutputDir, "output", "o", "docs/reference", "Directory to write reference pages to")
+	deprecatedAlias(docsGenerateCmd.Flags(), "dir", "output")
+	docsCmd.AddCommand(docsGenerateCmd)
+}
+
+func runDocsGenerate(cmd *cobra.Command, args []string) error {
+	if strings.Contains(docsOutputDir, "..") {
+		return rterrors.Validationf("invalid docs directory path: %s", docsOutputDir)
+	}
+
+	if err := os.MkdirAll(docsOutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create docs directory: %w", err)
+	}
+
+	catalog := BuildCatalog(cmd.Root(), false)
+
+	var index strings.Builder
+	index.WriteString("# RedTriage Command Reference\n\n")
+	index.WriteString("This reference is generated by `RedTriage docs generate`. Do not edit by hand.\n\n")
+	index.WriteString("| Command | Category | Description |\n")
+	index.WriteString("|---------|----------|-------------|\n")
+
+	for _, info := range catalog {
+		filename := docsFilename(info)
+		if err := output.WriteFileAtomic(filepath.Join(docsOutputDir, filename), []byte(renderCommandMarkdown(info)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		index.WriteString(fmt.Sprintf("| [%s](%s) | %s | %s |\n", info.Path, filename, info.Category, info.Description))
+	}
+
+	if err := output.WriteFileAtomic(filepath.Join(docsOutputDir, "README.md"), []byte(index.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	fmt.Printf("✓ Generated %d reference pages in %s\n", len(catalog), docsOutputDir)
+	return nil
+}
+
+func docsFilename(info CommandInfo) string {
+	return strings.ReplaceAll(info.Path, " ", "_") + ".md"
+}
+
+// renderCommandMarkdown renders a reference page for a single command
+func renderCommandMarkdown(info CommandInfo) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("# %s\n\n", info.Path))
+	b.WriteString(fmt.Sprintf("%s\n\n", info.Description))
+	b.WriteString(fmt.Sprintf("**Category:** %s\n\n", info.Category))
+
+	if info.Long != "" {
+		b.WriteString("## Description\n\n")
+		b.WriteString(strings.TrimSpace(info.Long) + "\n\n")
+	}
+
+	b.WriteString("## Usage\n\n")
+	b.WriteString(fmt.Sprintf("```\nRedTriage %s\n```\n\n", info.Usage))
+
+	if len(info.Examples) > 0 {
+		b.WriteString("## Examples\n\n```\n")
+		for _, example := range info.Examples {
+			b.WriteString(example + "\n")
+		}
+		b.WriteString("```\n\n")
+	}
+
+	writeFlagTable := func(title string, inherited bool) {
+		var rows []FlagInfo
+		for _, flag := range info.Flags {
+			if flag.Inherited == inherited {
+				rows = append(rows, flag)
+			}
+		}
+		if len(rows) == 0 {
+			return
+		}
+
+		b.WriteString(fmt.Sprintf("## %s\n\n", title))
+		b.WriteString("| Flag | Type | Default | Description |\n")
+		b.WriteString("|------|------|---------|-------------|\n")
+		for _, flag := range rows {
+			name := "`--" + flag.Name + "`"
+			if flag.Shorthand != "" {
+				name = "`-" + flag.Shorthand + "`, " + name
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, flag.Type, flag.Default, flag.Usage))
+		}
+		b.WriteString("\n")
+	}
+	writeFlagTable("Flags", false)
+	writeFlagTable("Global Flags", true)
+
+	return b.String()
+}
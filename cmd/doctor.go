@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+// defaultRulePackURL is the starter Sigma rule pack offered by doctor: the
+// stable, high-confidence core rules of the SigmaHQ project
+const defaultRulePackURL = "https://github.com/SigmaHQ/sigma/releases/latest/download/sigma_core.zip"
+
+// maxRulePackSize bounds the rule pack download
+const maxRulePackSize = 64 << 20
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find and fix common setup issues",
+	Long: `Find and fix common setup issues: a missing configuration file, missing
+reports, output, log and Sigma rule directories, directories that cannot be
+written or that anyone can write to, and an empty Sigma rule directory.
+
+Each fix is confirmed before it is applied, unless --yes is given. Running
+doctor again after its fixes finds nothing to change. The starter rule pack
+is only offered when network access is allowed (--allow-network or
+allow_network in the configuration).`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage doctor
+  RedTriage doctor --yes
+  RedTriage doctor --yes --allow-network
+  RedTriage doctor --allow-network --rules-url https://example.org/rules.zip`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runDoctor,
+}
+
+var (
+	doctorYes      bool
+	doctorRulesURL string
+)
+
+func init() {
+	doctorCmd.Flags().BoolVarP(&doctorYes, "yes", "y", false, "Apply every fix without asking")
+	doctorCmd.Flags().StringVar(&doctorRulesURL, "rules-url", defaultRulePackURL, "Starter Sigma rule pack to download (.zip, .tar.gz or .tar)")
+}
+
+// doctorFix is a setup problem doctor can fix
+type doctorFix struct {
+	problem string
+	action  string
+	apply   func() (string, error)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if footprint.Current().IsMinimal() {
+		return rterrors.Validationf("doctor changes the local setup and cannot run with --footprint minimal")
+	}
+	if _, err := url.ParseRequestURI(doctorRulesURL); err != nil {
+		return rterrors.Validationf("invalid --rules-url: %w", err)
+	}
+
+	fmt.Println("RedTriage Doctor")
+	fmt.Println("================")
+
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		fmt.Printf("⚠️  Configuration could not be loaded, checking with defaults: %v\n", err)
+		cfg = config.DefaultConfig()
+	}
+
+	var fixes []doctorFix
+	fixes = append(fixes, doctorConfigFile(cmd)...)
+	for _, dir := range doctorDirectories(cfg) {
+		fixes = append(fixes, doctorDirectory(dir.path, dir.purpose)...)
+	}
+	fixes = append(fixes, doctorRulePack(cfg)...)
+
+	if len(fixes) == 0 {
+		fmt.Println("\n✅ No setup issues found")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var changed []string
+	skipped, failed := 0, 0
+	for _, fix := range fixes {
+		fmt.Printf("\n⚠️  %s\n", fix.problem)
+		if !doctorYes && !confirmFix(reader, fix.action) {
+			fmt.Println("   Skipped")
+			skipped++
+			continue
+		}
+		change, err := fix.apply()
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", fix.action, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ %s\n", change)
+		changed = append(changed, change)
+	}
+
+	fmt.Printf("\nSummary: %d changed, %d skipped, %d failed\n", len(changed), skipped, failed)
+	for _, change := range changed {
+		fmt.Printf("  - %s\n", change)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d fix(es) failed", failed)
+	}
+	return nil
+}
+
+// confirmFix asks whether to apply a fix; anything but yes declines
+func confirmFix(reader *bufio.Reader, action string) bool {
+	fmt.Printf("   %s? [y/N]: ", action)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// doctorConfigFile offers to write the default configuration when no config
+// file is found, and to make an unreadable one readable
+func doctorConfigFile(cmd *cobra.Command) []doctorFix {
+	target := "redtriage.yml"
+	if cmd.Flags().Changed("config") {
+		target = cfgFile
+	} else if found := config.FindFile(); found != "" {
+		target = found
+	}
+
+	info, err := os.Stat(target)
+	if os.IsNotExist(err) {
+		return []doctorFix{{
+			problem: fmt.Sprintf("No configuration file (%s)", target),
+			action:  "Write the default configuration to " + target,
+			apply: func() (string, error) {
+				if err := config.DefaultConfig().Save(target); err != nil {
+					return "", fmt.Errorf("failed to write configuration: %w", err)
+				}
+				return "Wrote default configuration to " + target, nil
+			},
+		}}
+	}
+	if err != nil {
+		fmt.Printf("❌ Configuration file %s: %v\n", target, err)
+		return nil
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0400 == 0 {
+		mode := info.Mode().Perm() | 0600
+		return []doctorFix{{
+			problem: fmt.Sprintf("Configuration file %s is not readable (%s)", target, info.Mode().Perm()),
+			action:  fmt.Sprintf("Change its mode to %s", mode),
+			apply:   chmodFix(target, mode),
+		}}
+	}
+	fmt.Printf("✅ Configuration file: %s\n", target)
+	return nil
+}
+
+// setupDirectory is a directory RedTriage writes to
+type setupDirectory struct {
+	path, purpose string
+}
+
+// doctorDirectories lists the directories the configuration points at
+func doctorDirectories(cfg *config.Config) []setupDirectory {
+	dirs := []setupDirectory{
+		{cfg.ReportsDir, "Reports"},
+		{cfg.DefaultOutputDir, "Output"},
+		{cfg.SessionLogPath, "Session log"},
+		{rules.DefaultDir, "Sigma rules"},
+	}
+	var result []setupDirectory
+	for _, dir := range dirs {
+		if dir.path != "" {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// doctorDirectory offers to create a missing directory, make one its owner
+// cannot use writable, and close one anyone can write to
+func doctorDirectory(dir, purpose string) []doctorFix {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return []doctorFix{{
+			problem: fmt.Sprintf("%s directory %s does not exist", purpose, dir),
+			action:  "Create " + dir,
+			apply: func() (string, error) {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return "", fmt.Errorf("failed to create directory: %w", err)
+				}
+				return "Created directory " + dir, nil
+			},
+		}}
+	}
+	if err != nil {
+		fmt.Printf("❌ %s directory %s: %v\n", purpose, dir, err)
+		return nil
+	}
+	if !info.IsDir() {
+		fmt.Printf("❌ %s directory %s is a file; move it away and run doctor again\n", purpose, dir)
+		return nil
+	}
+
+	mode := info.Mode().Perm()
+	if runtime.GOOS != "windows" {
+		if mode&0700 != 0700 {
+			return []doctorFix{{
+				problem: fmt.Sprintf("%s directory %s cannot be used by its owner (%s)", purpose, dir, mode),
+				action:  fmt.Sprintf("Change its mode to %s", mode|0700),
+				apply:   chmodFix(dir, mode|0700),
+			}}
+		}
+		if mode&0002 != 0 {
+			return []doctorFix{{
+				problem: fmt.Sprintf("%s directory %s can be written by anyone (%s)", purpose, dir, mode),
+				action:  fmt.Sprintf("Change its mode to %s", mode&^0022),
+				apply:   chmodFix(dir, mode&^0022),
+			}}
+		}
+	}
+
+	if err := probeWritable(dir); err != nil {
+		fmt.Printf("❌ %s directory %s is not writable: %v\n", purpose, dir, err)
+		return nil
+	}
+	fmt.Printf("✅ %s directory: %s\n", purpose, dir)
+	return nil
+}
+
+// chmodFix returns a fix that changes the mode of path
+func chmodFix(path string, mode os.FileMode) func() (string, error) {
+	return func() (string, error) {
+		if err := os.Chmod(path, mode); err != nil {
+			return "", fmt.Errorf("failed to change mode: %w", err)
+		}
+		return fmt.Sprintf("Changed mode of %s to %s", path, mode), nil
+	}
+}
+
+// probeWritable creates and removes a file in dir
+func probeWritable(dir string) error {
+	probe, err := os.CreateTemp(dir, ".redtriage-doctor-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// doctorRulePack offers to download a starter rule pack when the Sigma rule
+// directory holds no rules and network access is allowed
+func doctorRulePack(cfg *config.Config) []doctorFix {
+	dir := rules.DefaultDir
+	if count := countRuleFiles(dir); count > 0 {
+		fmt.Printf("✅ Sigma rules: %d in %s\n", count, dir)
+		return nil
+	}
+	if !allowNetwork && !cfg.AllowNetwork {
+		fmt.Printf("ℹ️  No Sigma rules in %s; run doctor with --allow-network to download a starter pack\n", dir)
+		return nil
+	}
+
+	return []doctorFix{{
+		problem: fmt.Sprintf("No Sigma rules in %s", dir),
+		action:  "Download the starter rule pack from " + doctorRulesURL,
+		apply: func() (string, error) {
+			count, err := downloadRulePack(doctorRulesURL, dir)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Installed %d Sigma rules in %s", count, dir), nil
+		},
+	}}
+}
+
+// countRuleFiles counts the rule files directly in dir, as the rule loaders
+// read them
+func countRuleFiles(dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && rules.IsRuleFile(entry.Name()) {
+			count++
+		}
+	}
+	return count
+}
+
+// downloadRulePack downloads a rule pack archive, unpacks it and copies its
+// rule files into dir. The rule loaders do not descend into directories, so
+// the rules are placed side by side; existing files are kept.
+func downloadRulePack(packURL, dir string) (int, error) {
+	parsed, err := url.Parse(packURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rule pack URL: %w", err)
+	}
+	name := path.Base(parsed.Path)
+	if !archive.IsArchive(name) {
+		return 0, fmt.Errorf("rule pack %s is not a .zip, .tar.gz or .tar archive", name)
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(packURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download rule pack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download rule pack: %s", resp.Status)
+	}
+
+	work, err := os.MkdirTemp("", "redtriage-rules-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(work)
+
+	packPath := filepath.Join(work, name)
+	pack, err := os.Create(packPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save rule pack: %w", err)
+	}
+	written, err := io.Copy(pack, io.LimitReader(resp.Body, maxRulePackSize+1))
+	pack.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to download rule pack: %w", err)
+	}
+	if written > maxRulePackSize {
+		return 0, fmt.Errorf("rule pack is larger than %d MB", maxRulePackSize>>20)
+	}
+
+	unpacked := filepath.Join(work, "unpacked")
+	limits := archive.Limits{MaxEntrySize: 4 << 20, MaxTotalSize: 4 * maxRulePackSize, MaxEntries: 50000}
+	if _, err := archive.Extract(packPath, unpacked, limits); err != nil {
+		return 0, fmt.Errorf("failed to unpack rule pack: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	installed := 0
+	err = filepath.WalkDir(unpacked, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !rules.IsRuleFile(entry.Name()) {
+			return err
+		}
+		target := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		installed++
+		return nil
+	})
+	if err != nil {
+		return installed, fmt.Errorf("failed to install rules: %w", err)
+	}
+	if installed == 0 {
+		return 0, fmt.Errorf("rule pack holds no rule files")
+	}
+	return installed, nil
+}
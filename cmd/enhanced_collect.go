@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -11,6 +15,7 @@ import (
 	"github.com/redtriage/redtriage/detector"
 	"github.com/redtriage/redtriage/internal/output"
 
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/packager"
 	"github.com/redtriage/redtriage/platform/windows"
 	"github.com/redtriage/redtriage/reporter"
@@ -29,7 +34,12 @@ This command includes:
 - Professional reporting in multiple formats
 - Timeline analysis and threat hunting capabilities`,
 	Args: cobra.NoArgs,
-	RunE: runEnhancedCollect,
+	Example: `  RedTriage enhanced-collect
+  RedTriage enhanced-collect --profile rapid
+  RedTriage enhanced-collect --report-formats html,json
+  RedTriage enhanced-collect --name 'INC-042-{{.Host}}'`,
+	Annotations: map[string]string{"category": "Collection"},
+	RunE:        runEnhancedCollect,
 }
 
 var (
@@ -53,6 +63,7 @@ func init() {
 	enhancedCollectCmd.Flags().BoolVar(&enableAnomalyDetection, "anomaly-detection", true, "Enable anomaly detection")
 	enhancedCollectCmd.Flags().StringSliceVar(&reportFormats, "report-formats", []string{"html", "json", "csv", "xml"}, "Report output formats")
 	enhancedCollectCmd.Flags().StringVar(&collectionPriority, "priority", "balanced", "Collection priority (volatile_first, balanced, comprehensive)")
+	enhancedCollectCmd.Flags().StringVar(&bundleName, "name", "", "Bundle filename template for this collection, overriding filename_templates.bundle")
 	enhancedCollectCmd.Flags().BoolVar(&enableVolatileCollection, "volatile", true, "Enable volatile data collection (memory, network, etc.)")
 }
 
@@ -68,12 +79,13 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize output manager: %w", err)
 	}
 	defer om.Close()
+	om.SetQuiet(quiet)
 
 	// Validate inputs
 	if err := validateEnhancedCollectInputs(om); err != nil {
 		om.LogError(err, "Input validation failed")
 		om.PrintSummary()
-		return err
+		return rterrors.Wrap(rterrors.Validation, err)
 	}
 
 	om.LogInfo("Starting RedTriage Enhanced Collection...")
@@ -119,8 +131,22 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 		om.PrintSummary()
 		return err
 	}
+	sealKey, err := collectionSealKey()
+	if err != nil {
+		om.LogError(err, "Seal key unavailable")
+		om.PrintSummary()
+		return err
+	}
+	packagerInstance.SetSealKey(sealKey)
 
 	enhancedReporter := reporter.NewEnhancedReporter()
+	names, err := newCollectionNaming(om)
+	if err != nil {
+		om.LogError(err, "Filename templates are invalid")
+		om.PrintSummary()
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
+	names.apply(packagerInstance, enhancedReporter.Reporter)
 	if enhancedReporter == nil {
 		err := fmt.Errorf("failed to initialize enhanced reporter")
 		om.LogError(err, "Enhanced reporter initialization failed")
@@ -134,6 +160,7 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 		Timeout:  time.Duration(timeout) * time.Second,
 		Include:  includeForensic,
 		Exclude:  excludeForensic,
+		Root:     imageRoot,
 	}
 
 	om.LogInfo("Enhanced collection profile: profile=%s, priority=%s, include=%v, exclude=%v",
@@ -196,6 +223,9 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 		om.PrintSummary()
 		return fmt.Errorf("enhanced detection failed: %w", err)
 	}
+	for _, warning := range detectorInstance.Warnings() {
+		om.LogWarning("%s", warning)
+	}
 
 	om.LogSuccess("Enhanced detection analysis completed successfully")
 	om.LogInfo("Found %d findings", len(findings))
@@ -211,18 +241,40 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 
 	om.LogSuccess("Enhanced bundle creation completed successfully")
 	om.LogInfo("Bundle created at: %s", bundlePath)
+	finishSealing(om, bundlePath, sealKey)
 
 	// Generate enhanced reports
 	om.LogInfo("Generating enhanced reports...")
-	reports, err := enhancedReporter.GenerateEnhancedReports(results, findings, bundlePath)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	reportResults, err := enhancedReporter.GenerateEnhancedReports(ctx, results, findings, bundlePath)
+	if errors.Is(err, context.Canceled) {
+		om.LogWarning("Report generation cancelled; the bundle at %s is complete", bundlePath)
+		om.PrintSummary()
+		return fmt.Errorf("report generation cancelled")
+	}
 	if err != nil {
 		om.LogError(err, "Enhanced report generation failed")
 		om.PrintSummary()
 		return fmt.Errorf("enhanced report generation failed: %w", err)
 	}
 
-	om.LogSuccess("Enhanced report generation completed successfully")
-	om.LogInfo("Reports generated: %v", reports)
+	reports := reporter.ProducedReports(reportResults)
+	failedReports := make(map[string]string)
+	for _, result := range reportResults {
+		if result.Err != nil {
+			failedReports[result.Name] = result.Err.Error()
+			om.LogWarning("Report %s failed: %v", result.Name, result.Err)
+			continue
+		}
+		om.LogInfo("Report %s: %s", result.Name, result.Report.Path)
+	}
+	if len(failedReports) == 0 {
+		om.LogSuccess("Enhanced report generation completed successfully")
+	} else {
+		om.LogWarning("Generated %d of %d enhanced reports", len(reports), len(reportResults))
+	}
+	names.record(om, packagerInstance, bundlePath)
 
 	// Add final results
 	om.AddResult(output.Result{
@@ -236,6 +288,7 @@ func runEnhancedCollect(cmd *cobra.Command, args []string) error {
 			"findings_count":       len(findings),
 			"bundle_path":          bundlePath,
 			"reports":              reports,
+			"failed_reports":       failedReports,
 			"output_directory":     outputDir,
 			"collection_profile":   enhancedCollectionProfile,
 			"collection_priority":  collectionPriority,
@@ -289,6 +342,9 @@ func validateEnhancedCollectInputs(om *output.OutputManager) error {
 			}
 		}
 	}
+	if err := validateBundleName(); err != nil {
+		return err
+	}
 
 	// Validate timeout
 	if timeout <= 0 {
@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/offline"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+	"github.com/redtriage/redtriage/reporter"
 	"github.com/spf13/cobra"
 )
 
@@ -15,7 +25,20 @@ var findingsCmd = &cobra.Command{
 	Long: `Manage and analyze detection findings from triage collections.
 View, filter, and export findings in various formats.`,
 	Args: cobra.NoArgs,
-	RunE: runFindings,
+	Example: `  RedTriage findings
+  RedTriage findings --severity high
+  RedTriage findings --format json
+  RedTriage findings --export findings.json
+  RedTriage findings --summary-only --top 5
+  RedTriage findings --baseline ./reports/findings-prior.json
+  RedTriage findings --elasticsearch https://es.example.com:9200 --index redtriage
+  RedTriage findings --notify-on critical
+  RedTriage findings --level critical,high --tag attack.persistence
+  RedTriage findings --rule-file ./drafts/new-rule.yml
+  RedTriage findings --explain 3f1d2a9c-6b7e-4c58-9a0d-1e2f3a4b5c61
+  RedTriage findings --input ./redtriage-CASE-001.zip`,
+	Annotations: map[string]string{"category": "Analysis"},
+	RunE:        runFindings,
 }
 
 var (
@@ -23,6 +46,19 @@ var (
 	findingsCategory string
 	findingsExport   string
 	findingsFilter   string
+	findingsESURL    string
+	findingsESIndex  string
+	findingsBaseline string
+	findingsInput    string
+	findingsNotifyOn string
+	findingsLevels   []string
+	findingsRuleIDs  []string
+	findingsRuleFile []string
+	findingsTags     []string
+	findingsFormat   string
+	findingsSummary  bool
+	findingsTop      int
+	findingsExplain  string
 )
 
 func init() {
@@ -30,22 +66,39 @@ func init() {
 	findingsCmd.Flags().StringVar(&findingsCategory, "category", "", "Filter by category (process, network, file, etc.)")
 	findingsCmd.Flags().StringVar(&findingsExport, "export", "", "Export findings to file (json, csv, html)")
 	findingsCmd.Flags().StringVar(&findingsFilter, "filter", "", "Custom filter expression")
+	findingsCmd.Flags().StringVarP(&findingsFormat, "format", "f", "table", "Output format (table, json, yaml)")
+	findingsCmd.Flags().BoolVar(&findingsSummary, "summary-only", false, "Print only the findings summary grouped by rule")
+	findingsCmd.Flags().IntVar(&findingsTop, "top", 10, "Number of rules shown in the findings summary")
+	findingsCmd.Flags().StringVar(&findingsESURL, "elasticsearch", "", "Also bulk-index findings into this Elasticsearch/OpenSearch URL")
+	findingsCmd.Flags().StringVar(&findingsESIndex, "index", reporter.DefaultElasticsearchIndex, "Elasticsearch index for --elasticsearch")
+	findingsCmd.Flags().StringVar(&findingsNotifyOn, "notify-on", "", "Alert the configured notification sinks about findings of this severity or higher")
+	findingsCmd.Flags().StringSliceVar(&findingsLevels, "level", nil, "Run only rules of these Sigma levels (critical, high, medium, low, informational)")
+	findingsCmd.Flags().StringSliceVar(&findingsRuleIDs, "rule-id", nil, "Run only the rules with these IDs")
+	findingsCmd.Flags().StringSliceVar(&findingsRuleFile, "rule-file", nil, "Run the Sigma rules in these files (only these, plus any --rule-id)")
+	findingsCmd.Flags().StringSliceVar(&findingsTags, "tag", nil, "Run only rules with one of these tags, e.g. attack.persistence")
+	findingsCmd.Flags().StringVar(&findingsExplain, "explain", "", "Run only this rule against the latest collection and show which selection fields matched (interactive session)")
+	findingsCmd.Flags().StringVar(&findingsBaseline, "baseline", "", "Suppress findings already present in this earlier findings report")
+	findingsCmd.Flags().StringVar(&findingsInput, "input", "", "Analyze this bundle ZIP or extracted bundle directory offline, writing next to it or to --output")
 }
 
 func runFindings(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateFindingsInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
+	if findingsExplain != "" {
+		return rterrors.Validationf("--explain runs the rule against the session's collections; run 'findings --explain %s' in the interactive session", findingsExplain)
+	}
+	info := infoWriter(findingsFormat)
 
-	fmt.Println("Findings Management")
-	fmt.Println("==================")
+	fmt.Fprintln(info, "Findings Management")
+	fmt.Fprintln(info, "==================")
 
 	// Process all flags and show what would be done
-	fmt.Println("Processing findings with the following parameters:")
+	fmt.Fprintln(info, "Processing findings with the following parameters:")
 
 	if findingsSeverity != "" {
-		fmt.Printf("✓ Severity filter: %s\n", findingsSeverity)
+		fmt.Fprintf(info, "✓ Severity filter: %s\n", findingsSeverity)
 		// Validate severity
 		validSeverities := []string{"low", "medium", "high", "critical"}
 		valid := false
@@ -56,12 +109,12 @@ func runFindings(cmd *cobra.Command, args []string) error {
 			}
 		}
 		if !valid {
-			fmt.Printf("⚠️  Warning: Invalid severity '%s'. Valid values: %v\n", findingsSeverity, validSeverities)
+			fmt.Fprintf(info, "⚠️  Warning: Invalid severity '%s'. Valid values: %v\n", findingsSeverity, validSeverities)
 		}
 	}
 
 	if findingsCategory != "" {
-		fmt.Printf("✓ Category filter: %s\n", findingsCategory)
+		fmt.Fprintf(info, "✓ Category filter: %s\n", findingsCategory)
 		// Validate category
 		validCategories := []string{"process", "network", "file", "registry", "memory", "system"}
 		valid := false
@@ -72,16 +125,16 @@ func runFindings(cmd *cobra.Command, args []string) error {
 			}
 		}
 		if !valid {
-			fmt.Printf("⚠️  Warning: Invalid category '%s'. Valid values: %v\n", findingsCategory, validCategories)
+			fmt.Fprintf(info, "⚠️  Warning: Invalid category '%s'. Valid values: %v\n", findingsCategory, validCategories)
 		}
 	}
 
 	if findingsFilter != "" {
-		fmt.Printf("✓ Custom filter: %s\n", findingsFilter)
+		fmt.Fprintf(info, "✓ Custom filter: %s\n", findingsFilter)
 	}
 
 	if findingsExport != "" {
-		fmt.Printf("✓ Export format: %s\n", findingsExport)
+		fmt.Fprintf(info, "✓ Export format: %s\n", findingsExport)
 		// Validate export format
 		validFormats := []string{"json", "csv", "html"}
 		valid := false
@@ -92,41 +145,436 @@ func runFindings(cmd *cobra.Command, args []string) error {
 			}
 		}
 		if !valid {
-			fmt.Printf("⚠️  Warning: Invalid export format '%s'. Valid values: %v\n", findingsExport, validFormats)
+			fmt.Fprintf(info, "⚠️  Warning: Invalid export format '%s'. Valid values: %v\n", findingsExport, validFormats)
+		}
+	}
+
+	if findingsESURL != "" {
+		fmt.Fprintf(info, "✓ Elasticsearch output: %s (index %s)\n", findingsESURL, findingsESIndex)
+	}
+
+	if findingsNotifyOn != "" {
+		fmt.Fprintf(info, "✓ Notify on: %s and higher\n", findingsNotifyOn)
+	}
+
+	selection, err := findingsSelection()
+	if err != nil {
+		return rterrors.Validationf("%w", err)
+	}
+	// The findings report holds Sigma matches, kept by the IDs of the
+	// selected rules; an offline analysis runs the built-in detector, whose
+	// findings are kept by their own ID, level and tags
+	var selectedIDs map[string]bool
+	switch {
+	case selection.Empty():
+	case findingsInput != "":
+		if len(selection.RuleFiles) > 0 {
+			return rterrors.Validationf("--rule-file selects Sigma rules; findings --input runs the built-in detector rules, select them with --rule-id, --level or --tag")
+		}
+		fmt.Fprintf(info, "✓ Rule selection: %s (applied to the built-in detector rules)\n", selection)
+	default:
+		loaded, _, _, _, err := rules.NewCache("").LoadWithDefaults(rules.DefaultDir)
+		if err != nil {
+			return rterrors.NotFoundf("could not load Sigma rules: %w", err)
+		}
+		selected, err := selection.Apply(loaded)
+		if err != nil {
+			return rterrors.Validationf("%w", err)
+		}
+		if len(selected) == 0 {
+			return rterrors.Validationf("no Sigma rules match the selection %s (%d rules loaded)", selection, len(loaded))
 		}
+		selectedIDs = make(map[string]bool, len(selected))
+		for _, rule := range selected {
+			selectedIDs[strings.ToLower(rule.ID)] = true
+		}
+		fmt.Fprintf(info, "✓ Rule selection: %s (%d of %d rules selected)\n", selection, len(selected), len(loaded))
 	}
 
-	fmt.Println("\nSimulating findings analysis...")
+	var baseline *reporter.Baseline
+	if findingsBaseline != "" {
+		if _, err := os.Stat(findingsBaseline); err != nil {
+			return rterrors.NotFoundf("baseline findings report not found: %s", findingsBaseline)
+		}
+		var err error
+		if baseline, err = reporter.LoadBaseline(findingsBaseline); err != nil {
+			return rterrors.Validationf("invalid baseline: %w", err)
+		}
+		fmt.Fprintf(info, "✓ Baseline: %s (%d known findings will be suppressed)\n", findingsBaseline, baseline.Len())
+	}
 
-	// Simulate loading findings
-	fmt.Println("✓ Loading findings database...")
-	fmt.Println("✓ Applying filters...")
-	fmt.Println("✓ Processing results...")
+	var sinks []reporter.NotificationSink
+	if findingsNotifyOn != "" {
+		if sinks = reporter.NotificationSinks(loadedConfig().Notifications); len(sinks) == 0 {
+			return rterrors.Validationf("--notify-on requires notifications.slack_webhook, notifications.teams_webhook or notifications.smtp in the configuration")
+		}
+	}
 
-	// Show what would be displayed
-	fmt.Println("\n=== Sample Findings (Simulated) ===")
-	fmt.Println("No actual findings found. Run 'collect' command first to gather data.")
-	fmt.Println("When findings exist, they would be displayed here based on your filters.")
+	var (
+		matches []map[string]interface{}
+		source  findingsSource
+	)
+	if findingsInput != "" {
+		if matches, source, err = analyzeBundleFindings(cmd, info); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(info, "\n✓ Loading the latest findings report...")
+		if matches, source, err = loadLatestFindings(); err != nil {
+			return err
+		}
+		if source.reportPath == "" {
+			fmt.Fprintln(info, "No findings report found. Run 'collect' and then 'findings' in the interactive session first.")
+		} else {
+			fmt.Fprintf(info, "✓ Findings report: %s\n", source.reportPath)
+		}
+	}
+	if !selection.Empty() {
+		matches = selectFindings(matches, selection, selectedIDs)
+	}
+	// Findings already in the baseline are kept, marked, but neither alert
+	// nor are indexed
+	if baseline != nil {
+		_, summary := baseline.Apply(matches)
+		fmt.Fprintf(info, "✓ Baseline %s: %d new, %d suppressed (already in the baseline), %d resolved (no longer seen)\n",
+			findingsBaseline, summary.New, summary.Suppressed, summary.Resolved)
+	}
+	matches = filterFindings(matches)
+	if err := printFindings(info, matches); err != nil {
+		return err
+	}
+	newMatches, _ := splitBaselineFindings(matches)
 
 	// Handle export if requested
 	if findingsExport != "" {
-		fmt.Printf("\n✓ Exporting findings to: %s\n", findingsExport)
+		fmt.Fprintf(info, "\n✓ Exporting findings to: %s\n", findingsExport)
 		// Create a sample export file
 		exportDir := "./redtriage-exports"
 		if err := os.MkdirAll(exportDir, 0755); err == nil {
 			exportFile := filepath.Join(exportDir, fmt.Sprintf("findings.%s", findingsExport))
 			if err := os.WriteFile(exportFile, []byte("Sample findings export\n"), 0644); err == nil {
-				fmt.Printf("✓ Sample export file created: %s\n", exportFile)
+				fmt.Fprintf(info, "✓ Sample export file created: %s\n", exportFile)
 			} else {
-				fmt.Printf("⚠️  Failed to create export file: %v\n", err)
+				fmt.Fprintf(info, "⚠️  Failed to create export file: %v\n", err)
 			}
 		}
 	}
 
-	fmt.Println("\n✓ Findings command completed successfully")
+	if findingsESURL != "" {
+		indexFindings(info, newMatches, source)
+	}
+	if findingsNotifyOn != "" {
+		notifyFindings(info, sinks, newMatches, source)
+	}
+
+	fmt.Fprintln(info, "\n✓ Findings command completed successfully")
 	return nil
 }
 
+// findingsSource is where the findings of a run came from, for the
+// documents indexed and the alerts sent about them
+type findingsSource struct {
+	collectionID string
+	reportPath   string
+	host         *collector.HostIdentity // nil when unknown
+}
+
+// loadLatestFindings reads the findings of the most recent report written by
+// the session's 'findings' command. The report path is empty when there is
+// none.
+func loadLatestFindings() ([]map[string]interface{}, findingsSource, error) {
+	dir := filepath.Join(reportsDirectory(), "tests")
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, findingsSource{}, fmt.Errorf("failed to list findings reports: %w", err)
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "findings-") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(dir, name), info.ModTime()
+		}
+	}
+	if latest == "" {
+		return nil, findingsSource{}, nil
+	}
+
+	data, err := os.ReadFile(latest)
+	if err != nil {
+		return nil, findingsSource{}, fmt.Errorf("failed to read findings report: %w", err)
+	}
+	var report struct {
+		CollectionID string                   `json:"collection_id"`
+		Findings     []map[string]interface{} `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, findingsSource{}, rterrors.Validationf("invalid findings report %s: %w", latest, err)
+	}
+	return report.Findings, findingsSource{collectionID: report.CollectionID, reportPath: latest}, nil
+}
+
+// analyzeBundleFindings runs the findings engine over the --input bundle and
+// writes its findings to the analysis directory, next to the bundle or in
+// --output
+func analyzeBundleFindings(cmd *cobra.Command, info io.Writer) ([]map[string]interface{}, findingsSource, error) {
+	fmt.Fprintf(info, "\n✓ Analyzing bundle offline: %s\n", findingsInput)
+	analysis, err := offline.Analyze(findingsInput)
+	if err != nil {
+		return nil, findingsSource{}, err
+	}
+	fmt.Fprintf(info, "✓ Case %s: %d artifacts, %d findings (%d recorded at collection)\n",
+		analysis.CaseID, len(analysis.Artifacts), len(analysis.Findings), len(analysis.BundleFindings))
+	for _, gap := range detector.CoverageGaps(analysis.Artifacts) {
+		fmt.Fprintf(info, "⚠ Not examined: %s\n", gap)
+	}
+	for _, warning := range analysis.Warnings {
+		fmt.Fprintf(info, "⚠ %s\n", warning)
+	}
+
+	path, err := analysis.WriteFindings(offline.Dir(findingsInput, analysisOutputDir(cmd)))
+	if err != nil {
+		return nil, findingsSource{}, err
+	}
+	fmt.Fprintf(info, "✓ Findings written to: %s\n", path)
+	return reporter.DetectorMatches(analysis.Findings), findingsSource{collectionID: analysis.CaseID, reportPath: path, host: analysis.Host}, nil
+}
+
+// indexFindings bulk-indexes the findings into --elasticsearch with the
+// credentials configured under elasticsearch. It runs after the findings are
+// printed and only warns on failure.
+func indexFindings(info io.Writer, matches []map[string]interface{}, source findingsSource) {
+	cfg := loadedConfig().Elasticsearch
+	target := reporter.ElasticsearchTarget{
+		URL:      findingsESURL,
+		Index:    findingsESIndex,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		APIKey:   cfg.APIKey,
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(info, "\nNo findings to index into Elasticsearch index %s\n", target.Index)
+		return
+	}
+
+	docs := make([]map[string]interface{}, 0, len(matches))
+	for _, match := range matches {
+		docs = append(docs, reporter.ECSFinding(match, source.collectionID, source.host))
+	}
+	fmt.Fprintf(info, "\nIndexing %d findings into %s (index %s)...\n", len(docs), target.URL, target.Index)
+	result, err := target.IndexFindings(docs)
+	if err != nil {
+		fmt.Fprintf(info, "Warning: %v\n", err)
+		return
+	}
+	fmt.Fprintf(info, "✓ Indexed %d findings into Elasticsearch index %s\n", result.Indexed, target.Index)
+	if result.Failed > 0 {
+		fmt.Fprintf(info, "Warning: %d findings were not indexed: %s\n", result.Failed, strings.Join(result.Errors, "; "))
+	}
+}
+
+// notifyFindings alerts the configured sinks about each rule whose findings
+// meet --notify-on, under the same per-run limit and cooldown as the
+// interactive session. It only warns on failure.
+func notifyFindings(info io.Writer, sinks []reporter.NotificationSink, matches []map[string]interface{}, source findingsSource) {
+	host, _ := os.Hostname()
+	if source.host != nil && source.host.Hostname != "" {
+		host = source.host.Hostname
+	}
+	reportPath := source.reportPath
+	if absolute, err := filepath.Abs(reportPath); err == nil && reportPath != "" {
+		reportPath = absolute
+	}
+
+	alerts := reporter.AlertsForGroups(reporter.GroupFindings(matches), findingsNotifyOn, host, source.collectionID, reportPath)
+	if len(alerts) == 0 {
+		fmt.Fprintf(info, "No %s+ findings to send alerts about\n", findingsNotifyOn)
+		return
+	}
+
+	cfg := loadedConfig()
+	statePath := filepath.Join(auditDirectory(), reporter.NotifyStateFile)
+	limiter, err := reporter.LoadNotifyLimiter(statePath)
+	if err != nil {
+		fmt.Fprintf(info, "Warning: %v\n", err)
+	}
+	limiter.MaxPerRun = cfg.Notifications.MaxPerRun
+	limiter.Cooldown = cfg.GetNotifyCooldown()
+
+	result := reporter.DispatchAlerts(sinks, alerts, limiter, time.Now())
+	err = os.MkdirAll(filepath.Dir(statePath), 0755)
+	if err == nil {
+		err = limiter.Save(statePath)
+	}
+	if err != nil {
+		fmt.Fprintf(info, "Warning: %v\n", err)
+	}
+
+	fmt.Fprintf(info, "✓ Sent %d of %d %s+ findings alerts\n", result.Sent, len(alerts), findingsNotifyOn)
+	if held := result.CoolingOff + result.OverLimit; held > 0 {
+		fmt.Fprintf(info, "  %d alerts held back by the cooldown of %s or the limit of %d per run\n", held, limiter.Cooldown, limiter.MaxPerRun)
+	}
+	for _, notifyErr := range result.Errors {
+		fmt.Fprintf(info, "Warning: failed to send findings alert via %s\n", notifyErr)
+	}
+}
+
+// loadedConfig returns the configuration, or the defaults when it cannot be
+// read
+func loadedConfig() *config.Config {
+	if cfg, err := config.LoadReadOnly(); err == nil {
+		return cfg
+	}
+	return config.DefaultConfig()
+}
+
+// analysisOutputDir is the --output directory for offline analysis, empty
+// unless it was given so the analysis is written next to the bundle
+func analysisOutputDir(cmd *cobra.Command) string {
+	if !cmd.Flags().Changed("output") {
+		return ""
+	}
+	return cmd.Flags().Lookup("output").Value.String()
+}
+
+// selectFindings keeps the matches of the selected rules: those of the
+// selectedIDs Sigma rules or, without them, the findings whose rule passes
+// the selection by ID, level and tags
+func selectFindings(matches []map[string]interface{}, selection rules.Selection, selectedIDs map[string]bool) []map[string]interface{} {
+	kept := make([]map[string]interface{}, 0, len(matches))
+	for _, match := range matches {
+		ruleID, _ := match["rule_id"].(string)
+		if selectedIDs != nil {
+			if !selectedIDs[strings.ToLower(ruleID)] {
+				continue
+			}
+		} else {
+			level, _ := match["level"].(string)
+			if !selection.KeepsFinding(ruleID, level, matchTags(match)) {
+				continue
+			}
+		}
+		kept = append(kept, match)
+	}
+	return kept
+}
+
+// matchTags returns the tags of a match, read back from JSON or converted
+// from a detector finding
+func matchTags(match map[string]interface{}) []string {
+	switch tags := match["tags"].(type) {
+	case []string:
+		return tags
+	case []interface{}:
+		values := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// filterFindings keeps the findings that match --severity and --category.
+// Sigma's informational level counts as low.
+func filterFindings(matches []map[string]interface{}) []map[string]interface{} {
+	filtered := make([]map[string]interface{}, 0, len(matches))
+	for _, match := range matches {
+		if level, _ := match["level"].(string); findingsSeverity != "" && detector.SeverityOf(level) != detector.SeverityOf(findingsSeverity) {
+			continue
+		}
+		if category, _ := match["category"].(string); findingsCategory != "" && category != findingsCategory {
+			continue
+		}
+		filtered = append(filtered, match)
+	}
+	return filtered
+}
+
+// printFindings prints the findings, or with --summary-only the --top rules
+// they group into, as a table or in the --format document. Findings already
+// in the baseline are left out of the summary and listed after the new ones
+// in the table; the --format document keeps them with their marker.
+func printFindings(info io.Writer, matches []map[string]interface{}) error {
+	if findingsFormat == "table" && len(matches) == 0 {
+		fmt.Println("\nNo findings match the filters")
+		return nil
+	}
+	newMatches, known := splitBaselineFindings(matches)
+	if findingsSummary {
+		groups := reporter.GroupFindings(newMatches)
+		shown := groups
+		if len(shown) > findingsTop {
+			shown = shown[:findingsTop]
+		}
+		if findingsFormat != "table" {
+			return printStructured(findingsFormat, shown)
+		}
+		fmt.Fprintf(info, "\nKey findings (%d rules):\n", len(groups))
+		if err := reporter.KeyFindingsTable(shown).Render(os.Stdout); err != nil {
+			return err
+		}
+		if hidden := len(groups) - len(shown); hidden > 0 {
+			fmt.Printf("... and %d more (use --top to show more)\n", hidden)
+		}
+		if len(known) > 0 {
+			fmt.Printf("Known from baseline: %d findings (not shown)\n", len(known))
+		}
+		return nil
+	}
+
+	if findingsFormat != "table" {
+		return printStructured(findingsFormat, matches)
+	}
+	if len(newMatches) == 0 {
+		fmt.Println("\nNo new findings since the baseline")
+	} else {
+		fmt.Printf("\nFindings (%d):\n", len(newMatches))
+		if err := reporter.FindingsTable(newMatches).Render(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if len(known) == 0 {
+		return nil
+	}
+	fmt.Printf("\nKnown from baseline (%d findings, suppressed):\n", len(known))
+	return reporter.FindingsTable(known).Render(os.Stdout)
+}
+
+// splitBaselineFindings separates the findings marked as already in the
+// baseline from the new ones
+func splitBaselineFindings(matches []map[string]interface{}) (newMatches, known []map[string]interface{}) {
+	for _, match := range matches {
+		if suppressed, _ := match[reporter.BaselineSuppressedField].(bool); suppressed {
+			known = append(known, match)
+		} else {
+			newMatches = append(newMatches, match)
+		}
+	}
+	return newMatches, known
+}
+
+// findingsSelection builds the rule selection from the selection flags,
+// validating the levels
+func findingsSelection() (rules.Selection, error) {
+	var args []string
+	for flag, values := range map[string][]string{
+		"--level": findingsLevels, "--rule-id": findingsRuleIDs, "--rule-file": findingsRuleFile, "--tag": findingsTags,
+	} {
+		if len(values) > 0 {
+			args = append(args, flag, strings.Join(values, ","))
+		}
+	}
+	selection, _, err := rules.TakeSelection(args)
+	return selection, err
+}
+
 // validateFindingsInputs validates all findings command inputs
 func validateFindingsInputs() error {
 	// Validate severity if specified
@@ -174,6 +622,26 @@ func validateFindingsInputs() error {
 		}
 	}
 
+	if err := validateListFormat(findingsFormat); err != nil {
+		return err
+	}
+
+	if findingsTop < 1 {
+		return fmt.Errorf("invalid --top value %d: must be a positive number", findingsTop)
+	}
+
+	// Validate Elasticsearch output if specified
+	if findingsESURL != "" {
+		if err := reporter.ValidateElasticsearchURL(findingsESURL); err != nil {
+			return err
+		}
+	}
+
+	// Validate notification threshold if specified
+	if findingsNotifyOn != "" && !reporter.ValidNotifySeverity(findingsNotifyOn) {
+		return fmt.Errorf("invalid --notify-on severity '%s'. Must be one of: low, medium, high, critical", findingsNotifyOn)
+	}
+
 	// Validate filter if specified
 	if findingsFilter != "" {
 		if strings.Contains(findingsFilter, "..") || strings.Contains(findingsFilter, "//") {
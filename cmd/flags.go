@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// standardShortFlags are the short flags every command uses with the same
+// meaning. --output is a directory unless the command inherently writes one
+// file, which its help says.
+var standardShortFlags = map[string]string{
+	"o": "output",
+	"f": "format",
+	"v": "verbose",
+	"q": "quiet",
+	"t": "timeout",
+	"y": "yes",
+	"h": "help",
+}
+
+// deprecatedAlias keeps an old flag spelling working for one more release:
+// it sets the same value as name, is hidden from help and prints a
+// deprecation notice when used
+func deprecatedAlias(flags *pflag.FlagSet, old, name string) {
+	flag := flags.Lookup(name)
+	flags.Var(flag.Value, old, flag.Usage)
+	flags.MarkDeprecated(old, fmt.Sprintf("use --%s; --%s will be removed in the next release", name, old))
+}
+
+// QuietRequested reports whether -q/--quiet is among args, alone or in a
+// group of boolean short flags such as -qy, so the banner can be left out
+// before the flags are parsed
+func QuietRequested(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return false
+		case arg == "--quiet" || arg == "--quiet=true":
+			return true
+		case len(arg) > 1 && arg[0] == '-' && arg[1] != '-' && strings.Trim(arg[1:], "qvy") == "" && strings.Contains(arg, "q"):
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFlagConsistency walks the command tree and reports every short flag
+// used for different long flags, and every standard short flag bound to a
+// flag other than its standard one
+func CheckFlagConsistency(root *cobra.Command) []string {
+	meanings := make(map[string]map[string][]string)
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		c.InitDefaultHelpFlag()
+		visit := func(f *pflag.Flag) {
+			if f.Shorthand == "" {
+				return
+			}
+			if meanings[f.Shorthand] == nil {
+				meanings[f.Shorthand] = make(map[string][]string)
+			}
+			path := c.CommandPath()
+			users := meanings[f.Shorthand][f.Name]
+			if len(users) == 0 || users[len(users)-1] != path {
+				meanings[f.Shorthand][f.Name] = append(users, path)
+			}
+		}
+		c.LocalFlags().VisitAll(visit)
+		c.InheritedFlags().VisitAll(visit)
+		for _, child := range c.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	var problems []string
+	for short, names := range meanings {
+		if standard, ok := standardShortFlags[short]; ok {
+			for name, commands := range names {
+				if name != standard {
+					problems = append(problems, fmt.Sprintf("-%s means --%s in %s, not --%s", short, name, strings.Join(commands, ", "), standard))
+				}
+			}
+			continue
+		}
+		if len(names) > 1 {
+			var uses []string
+			for name, commands := range names {
+				uses = append(uses, fmt.Sprintf("--%s in %s", name, strings.Join(commands, ", ")))
+			}
+			sort.Strings(uses)
+			problems = append(problems, fmt.Sprintf("-%s has different meanings: %s", short, strings.Join(uses, "; ")))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
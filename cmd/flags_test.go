@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestShortFlagsAreConsistent(t *testing.T) {
+	if problems := CheckFlagConsistency(NewRootCmd()); len(problems) > 0 {
+		t.Errorf("conflicting short flags:\n%s", strings.Join(problems, "\n"))
+	}
+}
+
+func TestCheckFlagConsistencyReportsConflicts(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	first := &cobra.Command{Use: "first"}
+	first.Flags().StringP("name", "n", "", "")
+	first.Flags().StringP("file", "f", "", "")
+	second := &cobra.Command{Use: "second"}
+	second.Flags().StringP("number", "n", "", "")
+	root.AddCommand(first, second)
+
+	problems := CheckFlagConsistency(root)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %q", problems)
+	}
+	if !strings.Contains(problems[0], "-f means --file in root first, not --format") {
+		t.Errorf("standard short flag misuse not reported: %q", problems[0])
+	}
+	if !strings.Contains(problems[1], "-n has different meanings") {
+		t.Errorf("short flag with two meanings not reported: %q", problems[1])
+	}
+}
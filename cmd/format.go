@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"gopkg.in/yaml.v3"
+)
+
+// listFormats are the --format values of the listing commands (findings,
+// incident list); table is the default
+var listFormats = []string{"table", "json", "yaml"}
+
+// validateListFormat validates the --format flag of a listing command
+func validateListFormat(format string) error {
+	for _, f := range listFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return rterrors.Validationf("invalid format '%s'. Must be one of: %s", format, strings.Join(listFormats, ", "))
+}
+
+// infoWriter is where a listing command prints its progress messages: stdout
+// for a table, stderr for JSON and YAML so stdout holds a single document
+func infoWriter(format string) io.Writer {
+	if format == "table" {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// printStructured prints v as JSON or YAML, YAML with the JSON field names
+func printStructured(format string, v interface{}) error {
+	if format == "json" {
+		return printJSON(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+	if data, err = yaml.Marshal(doc); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -13,7 +13,13 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/integrity"
 	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+	"github.com/redtriage/redtriage/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -84,11 +90,16 @@ This command will:
 - Run comprehensive test suites
 - Generate detailed health report
 - Identify any configuration errors or issues`,
-	RunE: runHealthCheck,
+	Example: `  RedTriage health
+  RedTriage health --verbose
+  RedTriage health --output ./health-report.json
+  RedTriage health --timeout 60`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runHealthCheck,
 }
 
 func init() {
-	healthCmd.Flags().StringVarP(&healthOutputFile, "output", "o", "", "output file for health check report (JSON format)")
+	healthCmd.Flags().StringVarP(&healthOutputFile, "output", "o", "", "output file for health check report (JSON format; one file, not a directory)")
 	healthCmd.Flags().BoolVarP(&healthVerbose, "verbose", "v", false, "enable verbose output")
 	healthCmd.Flags().IntVarP(&healthTimeout, "timeout", "t", 300, "timeout for health checks in seconds")
 	healthCmd.Flags().StringSliceVar(&healthSkipTests, "skip", nil, "skip specific health checks")
@@ -98,7 +109,7 @@ func init() {
 func runHealthCheck(cmd *cobra.Command, args []string) error {
 	// Input sanitization and validation
 	if err := validateHealthFlags(); err != nil {
-		return fmt.Errorf("validation error: %w", err)
+		return rterrors.Validationf("validation error: %w", err)
 	}
 
 	// Create health checker
@@ -197,14 +208,18 @@ func (hc *HealthChecker) RunHealthChecks() error {
 		{"config-validation", "Validate configuration files", hc.checkConfigValidation},
 		{"system-dependencies", "Check system dependencies", hc.checkSystemDependencies},
 		{"file-permissions", "Verify file permissions", hc.checkFilePermissions},
+		{"runtime-environment", "Detect container or WSL environment", hc.checkRuntimeEnvironment},
+		{"binary-integrity", "Verify the RedTriage binary", hc.checkBinaryIntegrity},
 		{"go-environment", "Check Go environment", hc.checkGoEnvironment},
 		{"build-system", "Verify build system", hc.checkBuildSystem},
 		{"test-suites", "Run comprehensive test suites", hc.runTestSuites},
 		{"artifact-collection", "Test artifact collection", hc.checkArtifactCollection},
 		{"detection-engine", "Test detection engine", hc.checkDetectionEngine},
+		{"validate-rules", "Load and validate Sigma rules", hc.checkRuleValidation},
 		{"packaging-system", "Test packaging system", hc.checkPackagingSystem},
 		{"output-management", "Test output management", hc.checkOutputManagement},
 		{"system-info", "Collect system information", hc.checkSystemInfo},
+		{"disk-space", "Cross-check free disk space", hc.checkDiskSpace},
 		// Enhanced feature health checks
 		{"enhanced-artifacts", "Test enhanced artifact registry", hc.checkEnhancedArtifacts},
 		{"enhanced-collector", "Test enhanced Windows collector", hc.checkEnhancedCollector},
@@ -438,6 +453,14 @@ func (hc *HealthChecker) checkFilePermissions() HealthCheckResult {
 		outputs = append(outputs, "Read access: OK")
 	}
 
+	// Writing a probe file would touch the target system in minimal footprint mode
+	if footprint.Current().IsMinimal() {
+		footprint.Current().RecordSkip("file-permissions write probe", "footprint minimal forbids writing to the target system")
+		outputs = append(outputs, "Write access: SKIPPED (footprint minimal)")
+		result.Output = strings.Join(outputs, "; ")
+		return result
+	}
+
 	// Check if we can write to current directory
 	testFile := ".health_test_write"
 	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
@@ -454,6 +477,60 @@ func (hc *HealthChecker) checkFilePermissions() HealthCheckResult {
 	return result
 }
 
+// checkRuntimeEnvironment warns when RedTriage runs inside WSL or a
+// container, where a live collection does not show the whole machine
+func (hc *HealthChecker) checkRuntimeEnvironment() HealthCheckResult {
+	result := HealthCheckResult{
+		Name:        "runtime-environment",
+		Description: "Detect container or WSL environment",
+		Status:      "PASS",
+	}
+
+	env := collector.DetectEnvironment()
+	outputs := []string{fmt.Sprintf("Environment: %s", env)}
+	if runtime.GOOS == "linux" {
+		outputs = append(outputs, fmt.Sprintf("systemd: %v", env.Systemd))
+	}
+	outputs = append(outputs, env.Evidence...)
+	result.Output = strings.Join(outputs, "; ")
+
+	if warnings := env.Warnings(); len(warnings) > 0 {
+		result.Status = "WARN"
+		result.Warning = strings.Join(warnings, "; ")
+		hc.report.Warnings = append(hc.report.Warnings, warnings...)
+	}
+
+	return result
+}
+
+func (hc *HealthChecker) checkBinaryIntegrity() HealthCheckResult {
+	result := HealthCheckResult{
+		Name:        "binary-integrity",
+		Description: "Verify the RedTriage binary",
+		Status:      "PASS",
+	}
+
+	report := integrity.Check()
+	result.Output = fmt.Sprintf("SHA-256: %s; %s", report.SHA256, report.Summary())
+	warnings := report.Warnings()
+	switch {
+	case report.Tampered():
+		result.Status = "FAIL"
+		result.Error = strings.Join(warnings, "; ")
+		hc.report.Errors = append(hc.report.Errors, warnings...)
+	case len(warnings) > 0:
+		result.Status = "WARN"
+		result.Warning = strings.Join(warnings, "; ")
+		hc.report.Warnings = append(hc.report.Warnings, warnings...)
+	case report.DevelopmentBuild():
+		result.Status = "WARN"
+		result.Warning = report.Summary()
+		hc.report.Warnings = append(hc.report.Warnings, result.Warning)
+	}
+
+	return result
+}
+
 func (hc *HealthChecker) checkGoEnvironment() HealthCheckResult {
 	result := HealthCheckResult{
 		Name:        "go-environment",
@@ -704,6 +781,49 @@ func (hc *HealthChecker) checkDetectionEngine() HealthCheckResult {
 	return result
 }
 
+// checkRuleValidation loads the Sigma rules the findings command uses and
+// checks that each parses and can be evaluated, so a broken rules directory
+// is caught before findings are needed on a live incident
+func (hc *HealthChecker) checkRuleValidation() HealthCheckResult {
+	result := HealthCheckResult{
+		Name:        "validate-rules",
+		Description: "Load and validate Sigma rules",
+		Status:      "PASS",
+	}
+
+	validation, err := rules.Validate(sigmaRules)
+	if err != nil {
+		result.Status = "WARN"
+		result.Warning = fmt.Sprintf("Sigma rules not loaded: %v", err)
+		hc.report.Warnings = append(hc.report.Warnings, result.Warning)
+		return result
+	}
+
+	outputs := []string{
+		fmt.Sprintf("Rules directory: %s", validation.Dir),
+		fmt.Sprintf("Rules loaded: %d of %d", validation.Loaded, validation.Files),
+	}
+	outputs = append(outputs, validation.Failures...)
+	result.Output = strings.Join(outputs, "; ")
+
+	switch {
+	case validation.Failed():
+		result.Status = "FAIL"
+		result.Error = fmt.Sprintf("none of the %d rules in %s could be loaded", validation.Files, validation.Dir)
+		hc.report.Errors = append(hc.report.Errors, result.Error)
+	case len(validation.Failures) > 0:
+		result.Status = "WARN"
+		result.Warning = fmt.Sprintf("%d rule problems in %s: %s", len(validation.Failures), validation.Dir, strings.Join(validation.Failures, "; "))
+		hc.report.Warnings = append(hc.report.Warnings, result.Warning)
+	case validation.Files == 0:
+		result.Status = "WARN"
+		result.Warning = fmt.Sprintf("No Sigma rules found in %s", validation.Dir)
+		hc.report.Warnings = append(hc.report.Warnings, result.Warning)
+	}
+
+	return result
+}
+
 func (hc *HealthChecker) checkPackagingSystem() HealthCheckResult {
 	result := HealthCheckResult{
 		Name:        "packaging-system",
@@ -818,13 +938,16 @@ func (hc *HealthChecker) checkSystemInfo() HealthCheckResult {
 		outputs = append(outputs, fmt.Sprintf("Home Directory: %s", home))
 	}
 
-	// Check available memory (basic check)
-	if runtime.GOOS == "windows" {
-		outputs = append(outputs, "Memory Info: Available on Windows")
-	} else if runtime.GOOS == "linux" {
-		outputs = append(outputs, "Memory Info: Available on Linux")
+	// Uptime and memory
+	stats := collector.GatherSystemStats()
+	if _, failed := stats.Errors["uptime"]; !failed {
+		outputs = append(outputs, fmt.Sprintf("Uptime: %s", collector.FormatUptime(stats.Uptime())))
+	}
+	if m := stats.Memory; m != nil {
+		outputs = append(outputs, fmt.Sprintf("Memory: %s total, %s available, %s used",
+			collector.FormatBytes(m.TotalBytes), collector.FormatBytes(m.AvailableBytes), collector.FormatBytes(m.UsedBytes)))
 	} else {
-		outputs = append(outputs, "Memory Info: Not available on this platform")
+		outputs = append(outputs, fmt.Sprintf("Memory: %s", stats.Errors["memory"]))
 	}
 
 	// Check CPU info
@@ -835,6 +958,73 @@ func (hc *HealthChecker) checkSystemInfo() HealthCheckResult {
 	return result
 }
 
+// freeSpaceTolerance is how far the free space of the reports volume may
+// drift between the disk listing and the reports directory guard reading
+// it, for files written in between
+const freeSpaceTolerance = 64 << 20
+
+// checkDiskSpace lists the fixed volumes and cross-checks the free space
+// listed for the volume of the reports directory with what the reports
+// directory guard measures there
+func (hc *HealthChecker) checkDiskSpace() HealthCheckResult {
+	result := HealthCheckResult{
+		Name:        "disk-space",
+		Description: "Cross-check free disk space",
+		Status:      "PASS",
+	}
+
+	disks, err := collector.FixedDisks()
+	if err != nil {
+		result.Status = "SKIP"
+		result.Output = fmt.Sprintf("Disk usage not available: %v", err)
+		return result
+	}
+	var outputs []string
+	for _, disk := range disks {
+		outputs = append(outputs, fmt.Sprintf("%s: %s free of %s", disk.Path, collector.FormatBytes(disk.FreeBytes), collector.FormatBytes(disk.SizeBytes)))
+	}
+
+	// The reports directory may not exist yet; its nearest existing parent
+	// is on the same volume
+	dir, err := filepath.Abs(reportsDirectory())
+	if err != nil {
+		result.Status = "FAIL"
+		result.Error = fmt.Sprintf("failed to resolve reports directory: %v", err)
+		hc.report.Errors = append(hc.report.Errors, result.Error)
+		return result
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	guard, err := utils.GetFreeDiskSpace(dir)
+	volume := collector.VolumeOf(disks, dir)
+	switch {
+	case err != nil:
+		result.Status = "FAIL"
+		result.Error = fmt.Sprintf("reports directory guard cannot measure free space: %v", err)
+		hc.report.Errors = append(hc.report.Errors, result.Error)
+	case volume == nil:
+		result.Status = "WARN"
+		result.Warning = fmt.Sprintf("reports directory %s is not on a fixed volume", dir)
+		hc.report.Warnings = append(hc.report.Warnings, result.Warning)
+	default:
+		outputs = append(outputs, fmt.Sprintf("Reports directory %s on %s: %s free", dir, volume.Path, collector.FormatBytes(uint64(guard))))
+		if diff := int64(volume.FreeBytes) - guard; diff > freeSpaceTolerance || diff < -freeSpaceTolerance {
+			result.Status = "FAIL"
+			result.Error = fmt.Sprintf("%s lists %s free but the reports directory guard measures %s",
+				volume.Path, collector.FormatBytes(volume.FreeBytes), collector.FormatBytes(uint64(guard)))
+			hc.report.Errors = append(hc.report.Errors, result.Error)
+		}
+	}
+
+	result.Output = strings.Join(outputs, "; ")
+	return result
+}
+
 // Enhanced feature health checks
 
 func (hc *HealthChecker) checkEnhancedArtifacts() HealthCheckResult {
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/schema"
+	"github.com/redtriage/redtriage/internal/snapshot"
+	"github.com/redtriage/redtriage/reporter"
+	"github.com/spf13/cobra"
+)
+
+var incidentCmd = &cobra.Command{
+	Use:   "incident",
+	Short: "List the incidents of the interactive session and their history",
+	Long: `Incidents are created and worked in the interactive session. The CLI lists
+the incidents stored in the reports directory for scripts and quick checks.
+
+Every saved incident is snapshotted, at most once per snapshot_interval, as a
+JSON Patch against the snapshot before it. 'incident history' lists the
+snapshots and 'incident diff' shows the fields that changed between two of
+them, or between a snapshot and the incident as it is now.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage incident list
+  RedTriage incident list --format json
+  RedTriage incident history --id INC-001
+  RedTriage incident diff --id INC-001 --from 1 --to 3
+  RedTriage incident diff --id INC-001 --from 2 --format json`,
+	Annotations: map[string]string{"category": "Analysis"},
+}
+
+var incidentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored incidents",
+	Args:  cobra.NoArgs,
+	RunE:  runIncidentList,
+}
+
+var incidentHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the snapshots of an incident",
+	Args:  cobra.NoArgs,
+	RunE:  runIncidentHistory,
+}
+
+var incidentDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the fields that changed between two snapshots of an incident",
+	Long: `Shows the fields that changed between two snapshots of an incident. --from
+defaults to the latest snapshot and --to to the incident as it is now
+("current"). With --format json or yaml the RFC 6902 JSON Patch is printed.`,
+	Args: cobra.NoArgs,
+	RunE: runIncidentDiff,
+}
+
+var (
+	incidentFormat    string
+	incidentHistoryID string
+	incidentDiffFrom  string
+	incidentDiffTo    string
+)
+
+func init() {
+	incidentListCmd.Flags().StringVarP(&incidentFormat, "format", "f", "table", "Output format (table, json, yaml)")
+	incidentHistoryCmd.Flags().StringVar(&incidentHistoryID, "id", "", "Incident ID (required)")
+	incidentHistoryCmd.Flags().StringVarP(&incidentFormat, "format", "f", "table", "Output format (table, json, yaml)")
+	incidentDiffCmd.Flags().StringVar(&incidentHistoryID, "id", "", "Incident ID (required)")
+	incidentDiffCmd.Flags().StringVar(&incidentDiffFrom, "from", "", "Snapshot number to compare from (default: the latest)")
+	incidentDiffCmd.Flags().StringVar(&incidentDiffTo, "to", snapshot.Current, "Snapshot number to compare to, or current")
+	incidentDiffCmd.Flags().StringVarP(&incidentFormat, "format", "f", "table", "Output format (table, json, yaml)")
+	incidentHistoryCmd.MarkFlagRequired("id")
+	incidentDiffCmd.MarkFlagRequired("id")
+	incidentCmd.AddCommand(incidentListCmd, incidentHistoryCmd, incidentDiffCmd)
+}
+
+// storedIncident holds the fields of a stored incident that 'incident list'
+// shows
+type storedIncident struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Severity  string    `json:"severity"`
+	Status    string    `json:"status"`
+	Analyst   string    `json:"analyst"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Findings  []struct {
+		TriageState string `json:"triage_state"`
+	} `json:"findings"`
+	Scope *collector.CollectionScope `json:"scope,omitempty"`
+	// Set on encrypted incidents, of which only the ID, title and status
+	// are readable
+	Encrypted json.RawMessage `json:"encrypted,omitempty"`
+}
+
+func runIncidentList(cmd *cobra.Command, args []string) error {
+	if err := validateListFormat(incidentFormat); err != nil {
+		return err
+	}
+
+	incidents, err := readIncidentSummaries(filepath.Join(reportsDirectory(), "incidents"))
+	if err != nil {
+		return err
+	}
+	if incidentFormat != "table" {
+		return printStructured(incidentFormat, incidents)
+	}
+
+	if len(incidents) == 0 {
+		fmt.Println("No incidents found")
+		return nil
+	}
+	return reporter.IncidentsTable(incidents).Render(os.Stdout)
+}
+
+func runIncidentHistory(cmd *cobra.Command, args []string) error {
+	if err := validateListFormat(incidentFormat); err != nil {
+		return err
+	}
+	incidentFile, historyFile, err := incidentFiles(incidentHistoryID)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	if _, err := os.Stat(incidentFile); err != nil {
+		return rterrors.NotFoundf("incident not found: %s", incidentHistoryID)
+	}
+
+	snapshots, err := snapshot.History(historyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the history of %s: %w", incidentHistoryID, err)
+	}
+	if incidentFormat != "table" {
+		if snapshots == nil {
+			snapshots = []snapshot.Snapshot{}
+		}
+		return printStructured(incidentFormat, snapshots)
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Printf("No snapshots of incident %s yet\n", incidentHistoryID)
+		return nil
+	}
+	return reporter.SnapshotsTable(snapshots).Render(os.Stdout)
+}
+
+func runIncidentDiff(cmd *cobra.Command, args []string) error {
+	if err := validateListFormat(incidentFormat); err != nil {
+		return err
+	}
+	incidentFile, historyFile, err := incidentFiles(incidentHistoryID)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	data, err := os.ReadFile(incidentFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rterrors.NotFoundf("incident not found: %s", incidentHistoryID)
+		}
+		return fmt.Errorf("failed to read incident file: %w", err)
+	}
+	current, err := snapshot.Decode(data)
+	if err != nil {
+		return err
+	}
+	// The stored clocks are recomputed on every save and not snapshotted
+	if fields, ok := current.(map[string]interface{}); ok {
+		delete(fields, "clocks")
+	}
+
+	before, patch, err := snapshot.Compare(historyFile, current, incidentDiffFrom, incidentDiffTo)
+	if err != nil {
+		return fmt.Errorf("failed to compare snapshots of %s: %w", incidentHistoryID, err)
+	}
+	if incidentFormat != "table" {
+		if patch == nil {
+			patch = []snapshot.Operation{}
+		}
+		return printStructured(incidentFormat, patch)
+	}
+
+	if len(patch) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	return reporter.ChangesTable(snapshot.Describe(before, patch)).Render(os.Stdout)
+}
+
+// incidentFiles returns the stored file and the history file of an incident
+func incidentFiles(id string) (string, string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", "", rterrors.Validationf("invalid incident ID: %q", id)
+	}
+	dir := filepath.Join(reportsDirectory(), "incidents")
+	return filepath.Join(dir, id+".json"), filepath.Join(dir, id, "history.jsonl"), nil
+}
+
+// readIncidentSummaries reads the incidents stored in dir. Incidents that
+// cannot be read are skipped with a warning.
+func readIncidentSummaries(dir string) ([]reporter.IncidentSummary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []reporter.IncidentSummary{}, nil
+		}
+		return nil, fmt.Errorf("failed to read incidents directory: %w", err)
+	}
+
+	incidents := []reporter.IncidentSummary{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		incident, err := readIncidentSummary(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to load incident %s: %v\n", entry.Name(), err)
+			continue
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, nil
+}
+
+// readStoredIncident reads one stored incident of any supported schema
+func readStoredIncident(path string) (storedIncident, error) {
+	var incident storedIncident
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return incident, err
+	}
+	raw, _, err := schema.ReadIncident(data)
+	if err != nil {
+		return incident, err
+	}
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return incident, fmt.Errorf("failed to upgrade incident data: %w", err)
+	}
+	if err := json.Unmarshal(upgraded, &incident); err != nil {
+		return incident, fmt.Errorf("failed to unmarshal incident data: %w", err)
+	}
+	return incident, nil
+}
+
+// incidentScope returns the collection scope set on a stored incident, nil
+// when it has none
+func incidentScope(id string) (*collector.CollectionScope, error) {
+	path, _, err := incidentFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	incident, err := readStoredIncident(path)
+	if os.IsNotExist(err) {
+		return nil, rterrors.NotFoundf("incident %s not found in %s", id, filepath.Dir(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incident %s: %w", id, err)
+	}
+	if incident.Encrypted != nil {
+		return nil, rterrors.Validationf("incident %s is encrypted; collect for it from a session where it is unlocked", id)
+	}
+	return incident.Scope, nil
+}
+
+// readIncidentSummary reads one stored incident of any supported schema
+func readIncidentSummary(path string) (reporter.IncidentSummary, error) {
+	incident, err := readStoredIncident(path)
+	if err != nil {
+		return reporter.IncidentSummary{}, err
+	}
+
+	summary := reporter.IncidentSummary{
+		ID:        incident.ID,
+		Title:     incident.Title,
+		Severity:  incident.Severity,
+		Status:    incident.Status,
+		Analyst:   incident.Analyst,
+		CreatedAt: incident.CreatedAt,
+		UpdatedAt: incident.UpdatedAt,
+		Findings:  len(incident.Findings),
+		Locked:    incident.Encrypted != nil,
+	}
+	for _, finding := range incident.Findings {
+		if finding.TriageState != "false-positive" {
+			summary.ActiveFindings++
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the effective configuration and where each value came from",
+	Long: `Show every configuration value in effect and its source. Values come from
+REDTRIAGE_* environment variables, then the redtriage.yml file, then the
+built-in defaults, in that order of precedence. Secrets are masked.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage info
+  REDTRIAGE_TIMEOUT=45m RedTriage info
+  RedTriage info --format json`,
+	Annotations: map[string]string{"category": "Configuration"},
+	RunE:        runInfo,
+}
+
+var infoFormat string
+
+func init() {
+	infoCmd.Flags().StringVarP(&infoFormat, "format", "f", "table", "Output format: table, json or yaml")
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	if err := validateListFormat(infoFormat); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		return rterrors.Validationf("failed to load configuration: %w", err)
+	}
+	effective := cfg.Effective()
+	if infoFormat != "table" {
+		return printStructured(infoFormat, effective)
+	}
+
+	if effective.File != "" {
+		fmt.Printf("Configuration file: %s\n", effective.File)
+	} else {
+		fmt.Println("Configuration file: none (defaults)")
+	}
+	fmt.Println("Precedence: environment (REDTRIAGE_*) > file > defaults")
+	if err := reporter.SettingsTable(effective.Settings).Render(os.Stdout); err != nil {
+		return err
+	}
+	if len(effective.UnknownEnv) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s set but not a configuration setting\n", strings.Join(effective.UnknownEnv, ", "))
+	}
+	return nil
+}
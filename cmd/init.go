@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write the default configuration and create the working directories",
+	Long: `Write the default configuration to redtriage.yml in the current directory, or
+to --config, and create the reports, output, session log and Sigma rule
+directories it points at. An existing configuration file is kept unless
+--force is given. Use 'doctor' to check and repair an existing setup.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage init
+  RedTriage init --config ./case/redtriage.yml
+  RedTriage init --force`,
+	Annotations: map[string]string{"category": "Configuration"},
+	RunE:        runInit,
+}
+
+var initForce bool
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing configuration file")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if footprint.Current().IsMinimal() {
+		return rterrors.Validationf("init changes the local setup and cannot run with --footprint minimal")
+	}
+	cmd.SilenceUsage = true
+
+	target := "redtriage.yml"
+	if cmd.Flags().Changed("config") {
+		target = cfgFile
+	}
+	if _, err := os.Stat(target); err == nil && !initForce {
+		return rterrors.Validationf("configuration file %s already exists (use --force to overwrite it)", target)
+	}
+
+	cfg := config.DefaultConfig()
+	if err := cfg.Save(target); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	fmt.Printf("✅ Wrote default configuration to %s\n", target)
+
+	for _, dir := range doctorDirectories(cfg) {
+		if err := os.MkdirAll(dir.path, 0755); err != nil {
+			return rterrors.Permissionf("failed to create %s directory %s: %w", dir.purpose, dir.path, err)
+		}
+		fmt.Printf("✅ %s directory: %s\n", dir.purpose, dir.path)
+	}
+	return nil
+}
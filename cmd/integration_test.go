@@ -0,0 +1,348 @@
+//go:build integration
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/selftest"
+)
+
+// integrationTimeout bounds each CLI command the integration tests run
+const integrationTimeout = 2 * time.Minute
+
+// cliSandbox runs the built CLI and interactive session in a scratch
+// directory with its own home directory, so the user's configuration,
+// reports and audit log are never touched
+type cliSandbox struct {
+	t       *testing.T
+	binary  string
+	session string
+	dir     string
+	home    string
+	env     []string
+}
+
+func newCLISandbox(t *testing.T) *cliSandbox {
+	t.Helper()
+	dir := t.TempDir()
+	binary := buildBinary(t, dir, "redtriage", "cmd/redtriage-cli")
+	session := buildBinary(t, dir, "redtriage-session", "cmd/redtriage")
+
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(home, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return &cliSandbox{
+		t:       t,
+		binary:  binary,
+		session: session,
+		dir:     dir,
+		home:    home,
+		env: append(os.Environ(),
+			"HOME="+home,
+			"USERPROFILE="+home,
+			"APPDATA="+filepath.Join(home, "AppData", "Roaming"),
+			"LOCALAPPDATA="+filepath.Join(home, "AppData", "Local"),
+			"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		),
+	}
+}
+
+// buildBinary builds the main package at pkg, relative to the module root,
+// into dir
+func buildBinary(t *testing.T, dir, name, pkg string) string {
+	t.Helper()
+	binary := filepath.Join(dir, name)
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", binary, "github.com/redtriage/redtriage/"+pkg)
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build %s: %v\n%s", pkg, err, output)
+	}
+	return binary
+}
+
+// run runs the CLI in the sandbox directory and fails the test unless it
+// exits with wantCode
+func (s *cliSandbox) run(wantCode int, args ...string) string {
+	s.t.Helper()
+	return s.runIn(s.dir, wantCode, args...)
+}
+
+// runIn runs the CLI in dir and fails the test unless it exits with wantCode
+func (s *cliSandbox) runIn(dir string, wantCode int, args ...string) string {
+	s.t.Helper()
+	return s.exec(dir, "", wantCode, s.binary, args...)
+}
+
+// runSession sends commands to an interactive session started in dir, one
+// per line, and fails the test unless the session exits cleanly. A command
+// that fails is reported in the output, labeled with its error category.
+func (s *cliSandbox) runSession(dir string, commands ...string) string {
+	s.t.Helper()
+	return s.exec(dir, strings.Join(commands, "\n")+"\n", 0, s.session)
+}
+
+func (s *cliSandbox) exec(dir, stdin string, wantCode int, binary string, args ...string) string {
+	s.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), integrationTimeout)
+	defer cancel()
+	command := exec.CommandContext(ctx, binary, args...)
+	command.Dir = dir
+	command.Env = s.env
+	command.Stdin = strings.NewReader(stdin)
+	var output bytes.Buffer
+	command.Stdout = &output
+	command.Stderr = &output
+
+	name := strings.Join(append([]string{filepath.Base(binary)}, args...), " ")
+	code := 0
+	if err := command.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			s.t.Fatalf("'%s' did not run: %v", name, err)
+		}
+		code = exitErr.ExitCode()
+	}
+	if code != wantCode {
+		s.t.Fatalf("'%s' exited %d, want %d:\n%s", name, code, wantCode, output.String())
+	}
+	return output.String()
+}
+
+// requireFiles fails the test unless dir holds an entry matching each
+// pattern
+func (s *cliSandbox) requireFiles(dir string, patterns ...string) {
+	s.t.Helper()
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) == 0 {
+			s.t.Fatalf("%s has no %s", dir, pattern)
+		}
+	}
+}
+
+// checkHomeUntouched fails the test if a command wrote into the sandbox's
+// home directory
+func (s *cliSandbox) checkHomeUntouched() {
+	s.t.Helper()
+	if entries, _ := os.ReadDir(s.home); len(entries) != 0 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		s.t.Errorf("commands wrote into the home directory: %v", names)
+	}
+}
+
+// copyTestImage copies the collector's fixture image into dir, so the
+// collection reads a fake host instead of the machine running the tests
+func copyTestImage(t *testing.T, dir string) string {
+	t.Helper()
+	source := filepath.Join("..", "collector", "testdata", "image")
+	dest := filepath.Join(dir, "image")
+	err := filepath.WalkDir(source, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(source, path)
+		target := filepath.Join(dest, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy the fixture image: %v", err)
+	}
+	return dest
+}
+
+func TestCollectVerifyAndExtract(t *testing.T) {
+	s := newCLISandbox(t)
+
+	s.run(0, "check", "--output", filepath.Join(s.dir, "check"))
+	s.requireFiles(filepath.Join(s.dir, "check"), "check-*.log")
+
+	out := filepath.Join(s.dir, "collection")
+	s.run(0, "collect", "--yes", "--offline-root", copyTestImage(t, s.dir), "--output", out)
+	bundles, _ := filepath.Glob(filepath.Join(out, "*.zip"))
+	if len(bundles) != 1 {
+		t.Fatalf("collect wrote %d bundles, want 1", len(bundles))
+	}
+	bundle := bundles[0]
+	s.requireFiles(out, "collect-*.log")
+	collected := strings.TrimSuffix(bundle, ".zip")
+	s.requireFiles(collected, "manifest.json", "checksums.txt", "artifacts")
+
+	var manifest struct {
+		Artifacts []json.RawMessage `json:"artifacts"`
+	}
+	data, err := os.ReadFile(filepath.Join(collected, "manifest.json"))
+	if err == nil {
+		err = json.Unmarshal(data, &manifest)
+	}
+	if err != nil {
+		t.Fatalf("failed to read the collection manifest: %v", err)
+	}
+	if len(manifest.Artifacts) == 0 {
+		t.Fatal("the collection manifest lists no artifacts")
+	}
+
+	s.run(0, "bundle", "verify", "--path", bundle)
+	extracted := filepath.Join(s.dir, "extracted")
+	s.run(0, "bundle", "--extract", "--path", bundle, "--output", extracted)
+	s.requireFiles(extracted, "manifest.json")
+
+	// A missing bundle is a not-found error with its own exit code
+	s.run(rterrors.NotFound.ExitCode(), "bundle", "verify", "--path", filepath.Join(s.dir, "missing.zip"))
+
+	s.checkHomeUntouched()
+}
+
+// TestAnalyzeSelftestBundle analyzes the bundle the self-test builds from
+// its embedded collection, so this suite and the selftest command check the
+// same fixture data
+func TestAnalyzeSelftestBundle(t *testing.T) {
+	s := newCLISandbox(t)
+
+	result, err := selftest.Run(selftest.Options{Keep: true})
+	if err != nil {
+		t.Fatalf("selftest.Run: %v", err)
+	}
+	defer os.RemoveAll(result.WorkDir)
+	if !result.Passed() {
+		t.Fatalf("self-test failed: %+v", result.Stages)
+	}
+	bundles, _ := filepath.Glob(filepath.Join(result.WorkDir, "*.zip"))
+	if len(bundles) != 1 {
+		t.Fatalf("self-test wrote %d bundles, want 1", len(bundles))
+	}
+
+	var expected struct {
+		Rules []string `json:"rules"`
+	}
+	data, err := os.ReadFile(filepath.Join("..", "internal", "selftest", "fixtures", "expected.json"))
+	if err == nil {
+		err = json.Unmarshal(data, &expected)
+	}
+	if err != nil {
+		t.Fatalf("failed to read the self-test expectations: %v", err)
+	}
+
+	analysis := filepath.Join(s.dir, "analysis")
+	s.run(0, "findings", "--input", bundles[0], "--output", analysis, "--format", "json")
+	reports := filepath.Join(s.dir, "reports")
+	s.run(0, "report", "--input", bundles[0], "--output", reports)
+
+	matches, _ := filepath.Glob(filepath.Join(analysis, "*", "findings.json"))
+	if len(matches) != 1 {
+		t.Fatalf("offline analysis wrote %d findings files, want 1", len(matches))
+	}
+	var findings []struct {
+		RuleID string `json:"rule_id"`
+	}
+	data, err = os.ReadFile(matches[0])
+	if err == nil {
+		err = json.Unmarshal(data, &findings)
+	}
+	if err != nil {
+		t.Fatalf("failed to read offline findings: %v", err)
+	}
+	fired := make(map[string]bool)
+	for _, finding := range findings {
+		fired[finding.RuleID] = true
+	}
+	rules := make([]string, 0, len(fired))
+	for rule := range fired {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	if strings.Join(rules, ",") != strings.Join(expected.Rules, ",") {
+		t.Errorf("offline analysis fired %v, expected %v", rules, expected.Rules)
+	}
+	if reportFiles, _ := filepath.Glob(filepath.Join(reports, "*", "reports", "*")); len(reportFiles) == 0 {
+		t.Error("report --input wrote no reports")
+	}
+
+	if listed := s.run(0, "incident", "list", "--format", "json"); !strings.Contains(listed, "[]") {
+		t.Errorf("incident list in a fresh home is not empty:\n%s", listed)
+	}
+
+	s.checkHomeUntouched()
+}
+
+// TestInitAndIncidentExportImport sets up a working directory with init,
+// creates and exports an incident in one session and imports it in a
+// session of a second working directory
+func TestInitAndIncidentExportImport(t *testing.T) {
+	s := newCLISandbox(t)
+
+	s.run(0, "init")
+	s.requireFiles(s.dir, "redtriage.yml", "redtriage-reports", "redtriage-output")
+	s.run(rterrors.Validation.ExitCode(), "init")
+	s.run(0, "init", "--force")
+
+	exported := filepath.Join(s.dir, "incident.json")
+	out := s.runSession(s.dir,
+		"incident create --title Integration --severity high",
+		"incident export "+exported,
+		"exit",
+	)
+	if !strings.Contains(out, "Exported incident context to") {
+		t.Fatalf("incident export did not report the export:\n%s", out)
+	}
+
+	var incident struct {
+		ID       string `json:"id"`
+		Title    string `json:"title"`
+		Severity string `json:"severity"`
+		Status   string `json:"status"`
+	}
+	data, err := os.ReadFile(exported)
+	if err == nil {
+		err = json.Unmarshal(data, &incident)
+	}
+	if err != nil {
+		t.Fatalf("failed to read the exported incident: %v", err)
+	}
+	if !strings.HasPrefix(incident.ID, "INC-") || incident.Title != "Integration" || incident.Severity != "high" || incident.Status != "open" {
+		t.Errorf("exported incident is %+v", incident)
+	}
+
+	other := filepath.Join(s.dir, "other")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	s.runIn(other, 0, "init")
+	out = s.runSession(other,
+		"incident import",
+		"incident import "+exported,
+		"exit",
+	)
+	if !strings.Contains(out, "Error [validation]: incident import requires an incident file") {
+		t.Errorf("incident import without a file was not a validation error:\n%s", out)
+	}
+	if listed := s.runIn(other, 0, "incident", "list", "--format", "json"); !strings.Contains(listed, incident.ID) {
+		t.Errorf("imported incident %s is not listed:\n%s", incident.ID, listed)
+	}
+
+	s.checkHomeUntouched()
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/naming"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/reporter"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// bundleName is the --name filename template of the bundle, overriding
+// filename_templates.bundle for one collection
+var bundleName string
+
+// validateBundleName checks the --name template
+func validateBundleName() error {
+	if bundleName == "" {
+		return nil
+	}
+	if err := naming.Validate(bundleName); err != nil {
+		return fmt.Errorf("invalid --name: %w", err)
+	}
+	return nil
+}
+
+// collectionNaming names the bundle and reports of one collection from the
+// configured filename templates
+type collectionNaming struct {
+	caseID  string
+	bundle  *naming.Namer
+	reports *naming.Namer
+}
+
+// newCollectionNaming prepares the names of a collection. The case ID is
+// chosen here so the report names can use it.
+func newCollectionNaming(om *output.OutputManager) (*collectionNaming, error) {
+	cfg, err := config.LoadReadOnly()
+	if err != nil {
+		om.LogWarning("Failed to load configuration, using default file names: %v", err)
+		cfg = config.DefaultConfig()
+	}
+	templates := cfg.FilenameTemplates
+	if bundleName != "" {
+		templates.Bundle = bundleName
+	}
+
+	caseID := utils.GenerateCaseID()
+	fields := naming.Fields{Collection: caseID, Timestamp: time.Now()}
+	bundle, err := naming.New(templates.Bundle, fields)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := naming.New(templates.Report, fields)
+	if err != nil {
+		return nil, err
+	}
+	return &collectionNaming{caseID: caseID, bundle: bundle, reports: reports}, nil
+}
+
+// apply makes the packager and reporter use the names
+func (n *collectionNaming) apply(p *packager.Packager, r *reporter.Reporter) {
+	p.SetCaseID(n.caseID)
+	p.SetNamer(n.bundle)
+	r.SetNaming(n.reports, nil)
+}
+
+// record echoes the templated file names and adds the report names to the
+// bundle manifest, keyed by their built-in names, so other tools can find
+// them
+func (n *collectionNaming) record(om *output.OutputManager, p *packager.Packager, bundlePath string) {
+	if n.bundle.Templated() {
+		om.LogSuccess("Bundle named %s", filepath.Base(bundlePath))
+	}
+	names := n.reports.Names()
+	if n.reports.Templated() {
+		var given []string
+		for _, name := range names {
+			given = append(given, name)
+		}
+		sort.Strings(given)
+		om.LogSuccess("Reports named %s", strings.Join(given, ", "))
+	}
+	if err := p.RecordFileNames(bundlePath, names); err != nil {
+		om.LogWarning("Failed to record report names in the manifest: %v", err)
+	}
+}
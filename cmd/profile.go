@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/footprint"
 	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+	"gopkg.in/yaml.v3"
 
 	"github.com/spf13/cobra"
 )
@@ -17,19 +23,26 @@ var profileCmd = &cobra.Command{
 	Long: `Collect basic host profile information without creating a full archive or running detections.
 Useful for quick system reconnaissance.`,
 	Args: cobra.NoArgs,
-	RunE: runProfile,
+	Example: `  RedTriage profile
+  RedTriage profile --detailed
+  RedTriage profile --format json
+  RedTriage profile --compare ./baseline/host-profile.json`,
+	Annotations: map[string]string{"category": "Collection"},
+	RunE:        runProfile,
 }
 
 var (
 	profileDetailed bool
 	profileOutput   string
 	profileFormat   string
+	profileCompare  string
 )
 
 func init() {
 	profileCmd.Flags().BoolVar(&profileDetailed, "detailed", false, "Show detailed profile information")
-	profileCmd.Flags().StringVar(&profileOutput, "output", "", "Output directory for profile data")
-	profileCmd.Flags().StringVar(&profileFormat, "format", "text", "Output format (text, json, yaml)")
+	profileCmd.Flags().StringVarP(&profileOutput, "output", "o", "", "Output directory for profile data")
+	profileCmd.Flags().StringVarP(&profileFormat, "format", "f", "text", "Output format (text, json, yaml)")
+	profileCmd.Flags().StringVar(&profileCompare, "compare", "", "Report drift against an earlier host-profile.json")
 }
 
 func runProfile(cmd *cobra.Command, args []string) error {
@@ -44,12 +57,24 @@ func runProfile(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize output manager: %w", err)
 	}
 	defer om.Close()
+	om.SetQuiet(quiet)
 
 	// Validate inputs
 	if err := validateProfileInputs(om); err != nil {
 		om.LogError(err, "Input validation failed")
 		om.PrintSummary()
-		return err
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
+
+	// The earlier profile is read first: it may be the host-profile.json
+	// this run replaces
+	var prior map[string]interface{}
+	if profileCompare != "" {
+		if prior, err = reporter.LoadProfile(profileCompare); err != nil {
+			om.LogError(err, "Failed to load profile to compare")
+			om.PrintSummary()
+			return rterrors.Wrap(rterrors.Validation, err)
+		}
 	}
 
 	om.LogInfo("Starting host profile collection...")
@@ -67,6 +92,7 @@ func runProfile(cmd *cobra.Command, args []string) error {
 	profile := collector.CollectionProfile{
 		Extended: profileDetailed,
 		Timeout:  0, // No timeout for profile
+		Root:     imageRoot,
 	}
 
 	om.LogInfo("Collecting host artifacts...")
@@ -141,6 +167,31 @@ func runProfile(cmd *cobra.Command, args []string) error {
 	om.LogInfo("Host artifacts: %d, System artifacts: %d, Network artifacts: %d, Process artifacts: %d",
 		hostArtifacts, systemArtifacts, networkArtifacts, processArtifacts)
 
+	// Save the profile so later runs can be compared with it
+	hostProfile := hostProfileDocument(results)
+	profilePath := filepath.Join(outputDir, "host-profile.json")
+	if data, err := json.MarshalIndent(hostProfile, "", "  "); err == nil {
+		if err := output.WriteFileAtomic(profilePath, data, 0644); err != nil {
+			om.LogWarning("Failed to save host profile: %v", err)
+		} else {
+			footprint.Current().RecordWrite(profilePath, "host profile", false)
+			fmt.Printf("Host profile saved to: %s\n", profilePath)
+		}
+	}
+
+	if prior != nil {
+		drift := reporter.CompareProfiles(profileCompare, prior, hostProfile)
+		om.AddResult(output.Result{
+			Type:    "profile_drift",
+			Status:  "success",
+			Message: fmt.Sprintf("%d change(s) since %s", len(drift.Changes), profileCompare),
+			Data:    drift,
+		})
+		if err := printProfileDrift(drift); err != nil {
+			return err
+		}
+	}
+
 	// Write output to file if requested
 	if err := om.WriteOutput(); err != nil {
 		om.LogWarning("Failed to write output file: %v", err)
@@ -150,6 +201,58 @@ func runProfile(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// hostProfileDocument gathers the collected artifacts into a host profile
+// keyed by artifact name, the form profile --compare reads back
+func hostProfileDocument(results []collector.ArtifactResult) map[string]interface{} {
+	profile := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		if identity, ok := result.Data.(collector.HostIdentity); ok {
+			profile["hostname"] = identity.Hostname
+		}
+		profile[result.Artifact.Name] = result.Data
+	}
+	return profile
+}
+
+// printProfileDrift prints the changes since an earlier profile, as JSON
+// or YAML when --format asks for it
+func printProfileDrift(drift reporter.ProfileDrift) error {
+	switch profileFormat {
+	case "json", "yaml", "yml":
+		data, err := json.MarshalIndent(drift, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal profile drift: %w", err)
+		}
+		if profileFormat != "json" {
+			// Decoded from JSON so YAML uses the same field names
+			var doc interface{}
+			json.Unmarshal(data, &doc)
+			if data, err = yaml.Marshal(doc); err != nil {
+				return fmt.Errorf("failed to marshal profile drift: %w", err)
+			}
+		}
+		fmt.Println(strings.TrimRight(string(data), "\n"))
+		return nil
+	}
+
+	fmt.Printf("\nDrift since %s:\n", drift.Prior)
+	if drift.HostMismatch {
+		fmt.Println("⚠️  Warning: the earlier profile is from a different hostname")
+	}
+	if len(drift.Changes) == 0 {
+		fmt.Println("  No changes")
+	}
+	for _, change := range drift.Changes {
+		fmt.Printf("  [%-6s] %s\n", change.Severity, change)
+	}
+	return nil
+}
+
 func validateProfileInputs(om *output.OutputManager) error {
 	// Basic validation using simple approach
 
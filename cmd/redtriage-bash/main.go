@@ -6,6 +6,7 @@ import (
 	"runtime"
 
 	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/version"
 )
@@ -20,22 +21,25 @@ func main() {
 		terminal.EnableUnixFeatures()
 	}
 
-	// Show Linux/Bash-optimized banner
-	showUnixBanner()
+	// Show Linux/Bash-optimized banner on stderr so command output stays machine-readable,
+	// unless --quiet was given
+	if !cmd.QuietRequested(os.Args[1:]) {
+		showUnixBanner()
+	}
 
 	// Create and execute the root command
 	rootCmd := cmd.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Unix Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(rterrors.ExitCode(err))
 	}
 }
 
 func showUnixBanner() {
-	fmt.Println("RedTriage Unix/Linux Interface")
-	fmt.Printf("Version: %s\n", version.GetShortVersion())
-	fmt.Println("Professional Incident Response Triage Tool")
-	fmt.Println("Optimized for Linux, macOS, and Bash environments")
-	fmt.Println("Enhanced Unix compatibility and terminal features")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, "RedTriage Unix/Linux Interface")
+	fmt.Fprintf(os.Stderr, "Version: %s\n", version.GetShortVersion())
+	fmt.Fprintln(os.Stderr, "Professional Incident Response Triage Tool")
+	fmt.Fprintln(os.Stderr, "Optimized for Linux, macOS, and Bash environments")
+	fmt.Fprintln(os.Stderr, "Enhanced Unix compatibility and terminal features")
+	fmt.Fprintln(os.Stderr)
 }
@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/version"
 )
@@ -13,21 +16,27 @@ func main() {
 	// Enable Windows virtual terminal sequences for better color support
 	terminal.EnableVirtualTerminal()
 
-	// Show banner
-	showBanner()
+	// Show banner on stderr so command output stays machine-readable,
+	// unless --quiet was given
+	if !cmd.QuietRequested(os.Args[1:]) {
+		showBanner()
+	}
 
 	// Create and execute the root command
 	rootCmd := cmd.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", output.Sanitize(err.Error()))
+		os.Exit(rterrors.ExitCode(err))
 	}
 }
 
 func showBanner() {
-	fmt.Println("RedTriage Command-Line Interface")
-	fmt.Printf("Version: %s\n", version.GetShortVersion())
-	fmt.Println("Professional Incident Response Triage Tool")
-	fmt.Println("Built for Windows-first forensics with Linux parity")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, "RedTriage Command-Line Interface")
+	fmt.Fprintf(os.Stderr, "Version: %s\n", version.GetShortVersion())
+	fmt.Fprintln(os.Stderr, "Professional Incident Response Triage Tool")
+	fmt.Fprintln(os.Stderr, "Built for Windows-first forensics with Linux parity")
+	if env := collector.DetectEnvironment(); env.Isolated() {
+		fmt.Fprintf(os.Stderr, "Environment: %s\n", env)
+	}
+	fmt.Fprintln(os.Stderr)
 }
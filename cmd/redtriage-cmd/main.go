@@ -6,6 +6,7 @@ import (
 	"runtime"
 
 	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/version"
 )
@@ -20,22 +21,25 @@ func main() {
 		terminal.EnableCmdFeatures()
 	}
 
-	// Show CMD-optimized banner
-	showCmdBanner()
+	// Show CMD-optimized banner on stderr so command output stays machine-readable,
+	// unless --quiet was given
+	if !cmd.QuietRequested(os.Args[1:]) {
+		showCmdBanner()
+	}
 
 	// Create and execute the root command
 	rootCmd := cmd.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "CMD Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(rterrors.ExitCode(err))
 	}
 }
 
 func showCmdBanner() {
-	fmt.Println("RedTriage Command Prompt Interface")
-	fmt.Printf("Version: %s\n", version.GetShortVersion())
-	fmt.Println("Professional Incident Response Triage Tool")
-	fmt.Println("Optimized for Windows Command Prompt")
-	fmt.Println("Enhanced compatibility with CMD environment")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, "RedTriage Command Prompt Interface")
+	fmt.Fprintf(os.Stderr, "Version: %s\n", version.GetShortVersion())
+	fmt.Fprintln(os.Stderr, "Professional Incident Response Triage Tool")
+	fmt.Fprintln(os.Stderr, "Optimized for Windows Command Prompt")
+	fmt.Fprintln(os.Stderr, "Enhanced compatibility with CMD environment")
+	fmt.Fprintln(os.Stderr)
 }
@@ -6,6 +6,7 @@ import (
 	"runtime"
 
 	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/version"
 )
@@ -20,22 +21,25 @@ func main() {
 		terminal.EnablePowerShellFeatures()
 	}
 
-	// Show PowerShell-optimized banner
-	showPowerShellBanner()
+	// Show PowerShell-optimized banner on stderr so command output stays machine-readable,
+	// unless --quiet was given
+	if !cmd.QuietRequested(os.Args[1:]) {
+		showPowerShellBanner()
+	}
 
 	// Create and execute the root command
 	rootCmd := cmd.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "PowerShell Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(rterrors.ExitCode(err))
 	}
 }
 
 func showPowerShellBanner() {
-	fmt.Println("RedTriage PowerShell Interface")
-	fmt.Printf("Version: %s\n", version.GetShortVersion())
-	fmt.Println("Professional Incident Response Triage Tool")
-	fmt.Println("Optimized for PowerShell and Windows Terminal")
-	fmt.Println("Enhanced color support and terminal features")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, "RedTriage PowerShell Interface")
+	fmt.Fprintf(os.Stderr, "Version: %s\n", version.GetShortVersion())
+	fmt.Fprintln(os.Stderr, "Professional Incident Response Triage Tool")
+	fmt.Fprintln(os.Stderr, "Optimized for PowerShell and Windows Terminal")
+	fmt.Fprintln(os.Stderr, "Enhanced color support and terminal features")
+	fmt.Fprintln(os.Stderr)
 }
@@ -8,15 +8,20 @@ import (
 
 	"github.com/redtriage/redtriage/cmd"
 	"github.com/redtriage/redtriage/internal/session"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var (
-	interactive = flag.Bool("interactive", false, "Start interactive RedTriage session")
-	versionFlag = flag.Bool("version", false, "Show version information")
-	helpFlag    = flag.Bool("help", false, "Show help information")
+	interactive   = flag.Bool("interactive", false, "Start interactive RedTriage session")
+	versionFlag   = flag.Bool("version", false, "Show version information")
+	verifyFlag    = flag.Bool("verify", false, "With -version, compute the binary's SHA-256 and verify it")
+	helpFlag      = flag.Bool("help", false, "Show help information")
+	footprintFlag = flag.String("footprint", "standard", "Footprint on the target system (standard, minimal)")
+	outputFlag    = flag.String("output", "", "Destination for all session output (required with -footprint minimal)")
 )
 
 func main() {
@@ -27,6 +32,15 @@ func main() {
 	flag.Parse()
 
 	// Show version if requested
+	if *versionFlag && *verifyFlag {
+		rootCmd := cmd.NewRootCmd()
+		rootCmd.SetArgs([]string{"version", "--verify"})
+		if err := rootCmd.Execute(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", output.Sanitize(err.Error()))
+			os.Exit(rterrors.ExitCode(err))
+		}
+		os.Exit(0)
+	}
 	if *versionFlag {
 		fmt.Printf("RedTriage %s\n", version.GetShortVersion())
 		fmt.Printf("Build Info: %s\n", version.GetBuildInfo())
@@ -50,8 +64,8 @@ func main() {
 		// Set help args and execute
 		rootCmd.SetArgs([]string{"--help"})
 		if err := rootCmd.Execute(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", output.Sanitize(err.Error()))
+			os.Exit(rterrors.ExitCode(err))
 		}
 		os.Exit(0)
 	}
@@ -69,9 +83,10 @@ func main() {
 	// Default to interactive mode if no non-flag arguments or if --interactive is specified
 	if *interactive || flag.NArg() == 0 {
 		fmt.Println("Starting RedTriage Interactive Session...")
-		if err := session.StartInteractive(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		opts := session.Options{Footprint: *footprintFlag, Destination: *outputFlag}
+		if err := session.StartInteractiveWithOptions(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", output.Sanitize(err.Error()))
+			os.Exit(rterrors.ExitCode(err))
 		}
 	}
 }
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/redtriage/redtriage/internal/offline"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +18,12 @@ var reportCmd = &cobra.Command{
 	Long: `Generate various types of triage reports from collected data.
 Supports executive summaries, technical details, and compliance reports.`,
 	Args: cobra.NoArgs,
-	RunE: runReport,
+	Example: `  RedTriage report
+  RedTriage report --type executive
+  RedTriage report --output report.md --evidence
+  RedTriage report --input ./redtriage-CASE-001.zip --output ./analysis`,
+	Annotations: map[string]string{"category": "Reporting"},
+	RunE:        runReport,
 }
 
 var (
@@ -24,24 +31,30 @@ var (
 	reportTemplate        string
 	reportOutput          string
 	reportIncludeEvidence bool
+	reportInput           string
 )
 
 func init() {
 	reportCmd.Flags().StringVar(&reportType, "type", "summary", "Report type (summary, technical, compliance, executive)")
 	reportCmd.Flags().StringVar(&reportTemplate, "template", "", "Custom report template file")
-	reportCmd.Flags().StringVar(&reportOutput, "output", "", "Output file for report")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output file for report (with --input, the directory the analysis is written to)")
 	reportCmd.Flags().BoolVar(&reportIncludeEvidence, "evidence", false, "Include evidence details in report")
+	reportCmd.Flags().StringVar(&reportInput, "input", "", "Generate the reports of this bundle ZIP or extracted bundle directory offline")
 }
 
 func runReport(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateReportInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("Report Generation")
 	fmt.Println("=================")
 
+	if reportInput != "" {
+		return runBundleReport()
+	}
+
 	// Process all flags and show what would be done
 	fmt.Println("Processing report generation with the following parameters:")
 
@@ -131,6 +144,37 @@ This is a simulated report. In production, this would contain actual triage data
 	return nil
 }
 
+// runBundleReport runs the findings engine over the --input bundle and writes
+// its findings and reports next to the bundle, or in the --output directory
+func runBundleReport() error {
+	fmt.Printf("Analyzing bundle offline: %s\n", reportInput)
+	analysis, err := offline.Analyze(reportInput)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Case %s: %d artifacts, %d findings\n", analysis.CaseID, len(analysis.Artifacts), len(analysis.Findings))
+	for _, warning := range analysis.Warnings {
+		fmt.Printf("⚠ %s\n", warning)
+	}
+
+	dir := offline.Dir(reportInput, reportOutput)
+	findingsPath, err := analysis.WriteFindings(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Findings: %s\n", findingsPath)
+	reports, err := analysis.WriteReports(dir)
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		fmt.Printf("✓ %s report: %s\n", report.Type, report.Path)
+	}
+
+	fmt.Println("\n✓ Report generation completed successfully")
+	return nil
+}
+
 // validateReportInputs validates all report command inputs
 func validateReportInputs() error {
 	// Validate report type
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/rterrors"
 	"github.com/spf13/cobra"
 )
 
@@ -19,8 +21,14 @@ var (
 	sigmaRules       string
 	dryRun           bool
 	verbose          bool
+	quiet            bool
 	jsonLogs         bool
 	allowNetwork     bool
+	footprintMode    string
+	imageRoot        string
+
+	// rootInitialized guards against registering flags and subcommands twice
+	rootInitialized bool
 )
 
 var RootCmd = &cobra.Command{
@@ -45,7 +53,17 @@ everything into a signed archive with a manifest and concise report.`,
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Validate all persistent flags before any command runs
-		return validatePersistentFlags()
+		if err := validatePersistentFlags(); err != nil {
+			return rterrors.Wrap(rterrors.Validation, err)
+		}
+		if err := setupFootprint(cmd); err != nil {
+			return err
+		}
+		// version --verify reports the check itself
+		if cmd != versionCmd {
+			checkBinaryIntegrity()
+		}
+		return nil
 	},
 }
 
@@ -80,6 +98,11 @@ func displayBanner() {
 }
 
 func NewRootCmd() *cobra.Command {
+	if rootInitialized {
+		return RootCmd
+	}
+	rootInitialized = true
+
 	// Set a simple, clean help template for consistent formatting
 	RootCmd.SetHelpTemplate(`{{with (or .Long .Short)}}{{. | trimTrailingWhitespaces}}
 
@@ -113,15 +136,18 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.redtriage.yml)")
 	RootCmd.PersistentFlags().StringVar(&platform, "platform", "", "override platform detection (windows/linux)")
-	RootCmd.PersistentFlags().StringVar(&outputDir, "output", "./redtriage-output", "output directory for triage results")
-	RootCmd.PersistentFlags().IntVar(&timeout, "timeout", 300, "collection timeout in seconds")
+	RootCmd.PersistentFlags().StringVarP(&outputDir, "output", "o", "./redtriage-output", "output directory for triage results")
+	RootCmd.PersistentFlags().IntVarP(&timeout, "timeout", "t", 300, "collection timeout in seconds")
 	RootCmd.PersistentFlags().StringSliceVar(&includeArtifacts, "include", nil, "only collect specific artifacts")
 	RootCmd.PersistentFlags().StringSliceVar(&excludeArtifacts, "exclude", nil, "exclude specific artifacts")
 	RootCmd.PersistentFlags().StringVar(&sigmaRules, "sigma-rules", "", "path to Sigma rules directory")
 	RootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would be collected without actually collecting")
-	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "enable verbose logging")
+	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose logging")
+	RootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print only results, warnings and errors (no banner or progress messages)")
 	RootCmd.PersistentFlags().BoolVar(&jsonLogs, "json-logs", false, "output logs in JSON format")
 	RootCmd.PersistentFlags().BoolVar(&allowNetwork, "allow-network", false, "allow network operations during collection")
+	RootCmd.PersistentFlags().StringVar(&footprintMode, "footprint", footprint.Standard, "footprint on the target system (standard, minimal); minimal writes only to --output")
+	RootCmd.PersistentFlags().StringVar(&imageRoot, "root", "", "analyze a mounted disk image at this path instead of the live host (offline mode)")
 
 	// Add subcommands
 	RootCmd.AddCommand(collectCmd)
@@ -134,8 +160,25 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 	RootCmd.AddCommand(bundleCmd)
 	RootCmd.AddCommand(verifyCmd)
 	RootCmd.AddCommand(configCmd)
+	RootCmd.AddCommand(initCmd)
+	RootCmd.AddCommand(infoCmd)
 	RootCmd.AddCommand(diagCmd)
 	RootCmd.AddCommand(healthCmd)
+	RootCmd.AddCommand(doctorCmd)
+	RootCmd.AddCommand(versionCmd)
+	RootCmd.AddCommand(selftestCmd)
+	RootCmd.AddCommand(toolsCmd)
+	RootCmd.AddCommand(docsCmd)
+	RootCmd.AddCommand(auditCmd)
+	RootCmd.AddCommand(incidentCmd)
+
+	// Flag parsing errors are usage errors
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return rterrors.Wrap(rterrors.Validation, err)
+	})
+
+	// Replace the default help command with one that supports --format json
+	RootCmd.SetHelpCommand(helpCmd)
 
 	return RootCmd
 }
@@ -163,7 +206,7 @@ func initConfig() {
 		cfgFile = home + "/.redtriage.yml"
 
 		// Check if config file exists
-		if _, err := os.Stat(cfgFile); os.IsNotExist(err) {
+		if _, err := os.Stat(cfgFile); os.IsNotExist(err) && !quiet {
 			fmt.Fprintf(os.Stderr, "Config file not found: %s\n", cfgFile)
 			fmt.Fprintf(os.Stderr, "Using default configuration...\n")
 		}
@@ -192,6 +235,10 @@ func validatePersistentFlags() error {
 		return fmt.Errorf("timeout must be positive, got %d", timeout)
 	}
 
+	if quiet && verbose {
+		return fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+
 	// Validate output directory
 	if outputDir != "" {
 		if strings.Contains(outputDir, "..") || strings.Contains(outputDir, "//") {
@@ -214,6 +261,37 @@ func validatePersistentFlags() error {
 		}
 	}
 
+	// Validate offline image root
+	if imageRoot != "" {
+		info, err := os.Stat(imageRoot)
+		if err != nil {
+			return fmt.Errorf("image root not accessible: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("image root must be a directory: %s", imageRoot)
+		}
+	}
+
+	return nil
+}
+
+// setupFootprint installs the footprint policy selected by --footprint
+func setupFootprint(cmd *cobra.Command) error {
+	destination := ""
+	if footprintMode == footprint.Minimal {
+		// The destination must be chosen deliberately, never defaulted onto the target
+		if !cmd.Flags().Changed("output") {
+			return fmt.Errorf("--footprint minimal requires --output pointing at a removable or remote destination")
+		}
+		destination = outputDir
+	}
+
+	policy, err := footprint.New(footprintMode, destination)
+	if err != nil {
+		return err
+	}
+	footprint.SetCurrent(policy)
+
 	return nil
 }
 
@@ -5,6 +5,11 @@ import (
 	"strings"
 
 	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
 	"github.com/spf13/cobra"
 )
 
@@ -14,30 +19,43 @@ var rulesCmd = &cobra.Command{
 	Long: `Manage detection rule packs including listing, updating, and testing rules.
 Supports both built-in heuristic rules and Sigma rules.`,
 	Args: cobra.NoArgs,
-	RunE: runRules,
+	Example: `  RedTriage rules
+  RedTriage rules --category process
+  RedTriage rules --test
+  RedTriage rules --update --source https://example.org/rule-pack`,
+	Annotations: map[string]string{"category": "Configuration"},
+	RunE:        runRules,
 }
 
 var (
 	rulesUpdate   bool
 	rulesTest     bool
 	rulesCategory string
+	rulesSource   string
+	rulesForce    bool
 )
 
 func init() {
-	rulesCmd.Flags().BoolVar(&rulesUpdate, "update", false, "Update rule packs from remote sources")
-	rulesCmd.Flags().BoolVar(&rulesTest, "test", false, "Test rules against sample data")
+	rulesCmd.Flags().BoolVar(&rulesUpdate, "update", false, "Update the Sigma rules from a remote rule pack, downloading only changed files")
+	rulesCmd.Flags().StringVar(&rulesSource, "source", "", "Rule pack URL for --update, the directory holding its index.json (default: rules_update_url from the config)")
+	rulesCmd.Flags().BoolVar(&rulesForce, "force", false, "With --update, fetch the pack index even if it did not change since the last sync")
+	rulesCmd.Flags().BoolVar(&rulesTest, "test", false, "Report which rules the field mappings can fully evaluate")
 	rulesCmd.Flags().StringVar(&rulesCategory, "category", "", "Filter rules by category")
 }
 
 func runRules(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateRulesInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("Detection Rules Management")
 	fmt.Println("=========================")
 
+	if rulesUpdate {
+		return runRulesUpdate(cmd)
+	}
+
 	// Initialize detector
 	detector := detector.NewDetector()
 
@@ -52,19 +70,143 @@ func runRules(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
-	// Check Sigma rules if path provided
-	if sigmaRules != "" {
-		fmt.Printf("\nSigma Rules Path: %s\n", sigmaRules)
-		fmt.Println("Note: Sigma rule integration is planned for future versions")
+	// List the Sigma rules findings would use
+	return listSigmaRules()
+}
+
+// listSigmaRules lists the Sigma rules in the --sigma-rules directory, or in
+// the default directory with the embedded rules as fallback, marking each
+// rule as embedded or external
+func listSigmaRules() error {
+	cache := rules.NewCache("")
+	dir := sigmaRules
+	var (
+		loaded   []rules.SigmaRule
+		warnings []string
+		source   = rules.SourceExternal
+		err      error
+	)
+	if dir == "" {
+		dir = rules.DefaultDir
+		loaded, warnings, _, source, err = cache.LoadWithDefaults(dir)
 	} else {
-		fmt.Println("\nNo Sigma rules path specified. Use --sigma-rules flag to specify a path.")
+		loaded, warnings, _, err = cache.Load(dir)
+		for i := range loaded {
+			loaded[i].Source = rules.SourceExternal
+		}
+	}
+	if err != nil {
+		return rterrors.NotFoundf("could not load Sigma rules: %w", err)
+	}
+
+	fmt.Printf("\nSigma Rules (%s):\n", rules.SourceLabel(source, dir))
+	fmt.Println("---------------")
+	for _, rule := range loaded {
+		fmt.Printf("[%s] %s (Level: %s)\n", rule.Source, output.SanitizeLine(rule.Title), output.SanitizeLine(rule.Level))
+	}
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	if source == rules.SourceEmbedded {
+		fmt.Printf("\nRule files placed in %s take precedence over the embedded defaults.\n", dir)
 	}
 
+	fieldMap, warnings := rules.LoadFieldMap(dir)
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	if rulesTest {
+		printMappingCoverage(fieldMap, loaded)
+		return nil
+	}
+	for _, rule := range loaded {
+		if coverage := fieldMap.RuleCoverage(rule); coverage.Status == rules.CoveragePartial || coverage.Status == rules.CoverageNone {
+			fmt.Printf("Warning: %s\n", output.SanitizeLine(coverage.String()))
+		}
+	}
+	return nil
+}
+
+// printMappingCoverage reports, for each rule, whether the field mappings
+// translate every field it selects on
+func printMappingCoverage(fieldMap *rules.FieldMap, loaded []rules.SigmaRule) {
+	fmt.Println("\nField Mapping Coverage:")
+	fmt.Println("---------------")
+	counts := make(map[string]int)
+	for _, rule := range loaded {
+		coverage := fieldMap.RuleCoverage(rule)
+		counts[coverage.Status]++
+		fmt.Printf("[%s] %s\n", coverage.Status, output.SanitizeLine(coverage.String()))
+	}
+	for _, path := range fieldMap.Files {
+		fmt.Printf("Mapping file: %s\n", path)
+	}
+	fmt.Printf("\n%d fully mapped, %d partially mapped, %d unmapped, %d native\n",
+		counts[rules.CoverageFull], counts[rules.CoveragePartial], counts[rules.CoverageNone], counts[rules.CoverageNative])
+}
+
+// runRulesUpdate syncs the Sigma rules directory with the configured rule
+// pack and reports what changed
+func runRulesUpdate(cmd *cobra.Command) error {
+	source := rulesSource
+	if source == "" {
+		if cfg, err := config.LoadReadOnly(); err == nil {
+			source = cfg.RulesUpdateURL
+		}
+	}
+	if source == "" {
+		return rterrors.Validationf("rules --update needs --source <url> or rules_update_url in the config")
+	}
+	dir := sigmaRules
+	if dir == "" {
+		dir = rules.DefaultDir
+	}
+
+	fmt.Printf("\nUpdating %s from %s\n", dir, source)
+	result, err := rules.Update(cmd.Context(), rules.UpdateOptions{Source: source, Dir: dir, Force: rulesForce})
+	params := map[string]interface{}{"source": source, "force": rulesForce}
+	if result != nil {
+		params["added"] = len(result.Added)
+		params["updated"] = len(result.Updated)
+		params["removed"] = len(result.Removed)
+	}
+	recordAudit(audit.RulesUpdated, "", dir, params, err)
+	if err != nil {
+		return err
+	}
+
+	if result.NotModified {
+		fmt.Println("✓ Rule pack unchanged since the last sync")
+	} else {
+		fmt.Printf("✓ %d added, %d updated, %d removed, %d unchanged\n", len(result.Added), len(result.Updated), len(result.Removed), result.Unchanged)
+		if result.Resumed > 0 {
+			fmt.Printf("  %d files reused from an interrupted update\n", result.Resumed)
+		}
+		for _, name := range result.Added {
+			fmt.Printf("  + added:   %s\n", name)
+		}
+		for _, name := range result.Updated {
+			fmt.Printf("  ~ updated: %s\n", name)
+		}
+		for _, name := range result.Removed {
+			fmt.Printf("  - removed: %s\n", name)
+		}
+		for _, name := range result.Kept {
+			fmt.Printf("Warning: %s is no longer in the pack but was edited locally; kept\n", name)
+		}
+	}
+	if !result.LastSync.IsZero() {
+		fmt.Printf("Last sync: %s\n", result.LastSync.Local().Format("2006-01-02 15:04:05"))
+	}
 	return nil
 }
 
 // validateRulesInputs validates all rules command inputs
 func validateRulesInputs() error {
+	if !rulesUpdate && (rulesSource != "" || rulesForce) {
+		return fmt.Errorf("--source and --force require --update")
+	}
+
 	// Validate category if specified
 	if rulesCategory != "" {
 		validCategories := []string{"process", "network", "file", "registry", "memory", "system", "malware", "persistence", "lateral_movement"}
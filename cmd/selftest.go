@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/redtriage/redtriage/internal/selftest"
+	"github.com/spf13/cobra"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run the full triage pipeline against built-in synthetic data",
+	Long: `Run a self-test that exercises the full pipeline on embedded synthetic data:
+load a synthetic collection, run the findings engine, check the results
+against embedded expectations, create a bundle, generate every report and
+export format, and verify the bundle checksums.
+
+Use it to confirm a build works on a new host before a real engagement.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage selftest
+  RedTriage selftest --keep`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runSelftest,
+}
+
+var selftestKeep bool
+
+func init() {
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "Keep the generated bundle and reports instead of removing them")
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	fmt.Println("RedTriage Self-Test")
+	fmt.Println("===================")
+
+	result, err := selftest.Run(selftest.Options{
+		Keep: selftestKeep,
+		OnStage: func(stage selftest.Stage) {
+			status := "PASS"
+			switch {
+			case stage.Passed:
+			case stage.Skipped:
+				status = "SKIP"
+			default:
+				status = "FAIL"
+			}
+			fmt.Printf("[%s] %-28s %s\n", status, stage.Name, stage.Detail)
+		},
+	})
+	if err != nil && result == nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	if result.Kept {
+		fmt.Printf("\nOutput kept in: %s\n", result.WorkDir)
+	}
+
+	if !result.Passed() {
+		return fmt.Errorf("self-test failed")
+	}
+
+	fmt.Println("\nAll self-test stages passed")
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/spf13/cobra"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List available tools and their metadata",
+	Long: `List every RedTriage tool with its category, description and flags.
+Use --format json to get the catalog in a machine-readable form for automation.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage tools
+  RedTriage tools --format json`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runTools,
+}
+
+var helpCmd = &cobra.Command{
+	Use:   "help [command]",
+	Short: "Help about any command",
+	Long: `Help provides help for any command in the application.
+Use --format json to get the command metadata in a machine-readable form.`,
+	Example: `  RedTriage help collect
+  RedTriage help collect --format json`,
+	RunE: runHelp,
+}
+
+var (
+	toolsFormat string
+	helpFormat  string
+)
+
+func init() {
+	toolsCmd.Flags().StringVarP(&toolsFormat, "format", "f", "text", "Output format (text, json)")
+	helpCmd.Flags().StringVarP(&helpFormat, "format", "f", "text", "Output format (text, json)")
+}
+
+func runTools(cmd *cobra.Command, args []string) error {
+	if err := validateCatalogFormat(toolsFormat); err != nil {
+		return err
+	}
+
+	catalog := BuildCatalog(cmd.Root(), false)
+
+	if toolsFormat == "json" {
+		return printJSON(catalog)
+	}
+
+	fmt.Printf("%-18s %-16s %s\n", "Tool", "Category", "Description")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, info := range catalog {
+		fmt.Printf("%-18s %-16s %s\n", info.Path, info.Category, info.Description)
+	}
+	fmt.Println()
+	fmt.Println("Use 'RedTriage help <tool>' for detailed information about a specific tool.")
+
+	return nil
+}
+
+func runHelp(cmd *cobra.Command, args []string) error {
+	if err := validateCatalogFormat(helpFormat); err != nil {
+		return err
+	}
+
+	target, _, err := cmd.Root().Find(args)
+	if err != nil || target == nil {
+		return rterrors.NotFoundf("unknown help topic %q", strings.Join(args, " "))
+	}
+
+	if helpFormat == "json" {
+		if target == cmd.Root() {
+			return printJSON(BuildCatalog(target, false))
+		}
+		return printJSON(NewCommandInfo(target))
+	}
+
+	return target.Help()
+}
+
+// validateCatalogFormat validates the --format flag of catalog commands
+func validateCatalogFormat(format string) error {
+	validFormats := []string{"text", "json"}
+	for _, f := range validFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return rterrors.Validationf("invalid format '%s'. Must be one of: %s", format, strings.Join(validFormats, ", "))
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return nil
+}
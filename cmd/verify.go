@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/packager"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +17,11 @@ var verifyCmd = &cobra.Command{
 	Long: `Verify the integrity and authenticity of triage data and bundles.
 Checks checksums, digital signatures, and data consistency.`,
 	Args: cobra.NoArgs,
-	RunE: runVerify,
+	Example: `  RedTriage verify --path ./evidence.zip
+  RedTriage verify --path ./evidence.zip --seal-key ./evidence.sealkey
+  RedTriage verify --path ./evidence.zip --signatures`,
+	Annotations: map[string]string{"category": "Data Management"},
+	RunE:        runVerify,
 }
 
 var (
@@ -21,6 +29,7 @@ var (
 	verifySignatures  bool
 	verifyConsistency bool
 	verifyPath        string
+	verifySealKey     string
 )
 
 func init() {
@@ -28,12 +37,13 @@ func init() {
 	verifyCmd.Flags().BoolVar(&verifySignatures, "signatures", false, "Verify digital signatures")
 	verifyCmd.Flags().BoolVar(&verifyConsistency, "consistency", true, "Verify data consistency")
 	verifyCmd.Flags().StringVar(&verifyPath, "path", "", "Path to verify (file, directory, or bundle)")
+	verifyCmd.Flags().StringVar(&verifySealKey, "seal-key", "", "Key file to check bundle artifact seals with (default: the bundle's .sealkey file when present)")
 }
 
 func runVerify(cmd *cobra.Command, args []string) error {
 	// Validate inputs first
 	if err := validateVerifyInputs(); err != nil {
-		return fmt.Errorf("input validation failed: %w", err)
+		return rterrors.Validationf("input validation failed: %w", err)
 	}
 
 	fmt.Println("Data Verification")
@@ -91,6 +101,11 @@ func validateVerifyInputs() error {
 
 // verifyChecksumsForPath verifies checksums for the specified path
 func verifyChecksumsForPath() error {
+	// Bundles are checked against their manifest, whatever schema version wrote it
+	if strings.EqualFold(filepath.Ext(verifyPath), ".zip") {
+		return verifyBundleChecksums(verifyPath)
+	}
+
 	// Simulate checksum verification
 	fmt.Println("  - Reading checksum files...")
 	fmt.Println("  - Calculating file hashes...")
@@ -99,6 +114,68 @@ func verifyChecksumsForPath() error {
 	return nil
 }
 
+// verifyBundleChecksums re-hashes a bundle's artifacts against its
+// manifest, and checks their seals when a seal key is available
+func verifyBundleChecksums(path string) error {
+	key, err := bundleSealKey(path, verifySealKey)
+	if err != nil {
+		return err
+	}
+	var result *packager.VerifyResult
+	if key != nil {
+		result, err = packager.VerifyBundleSeals(path, "", key)
+	} else {
+		result, err = packager.VerifyBundle(path)
+	}
+	if err != nil {
+		return err
+	}
+	return reportBundleVerification(result)
+}
+
+// bundleSealKey loads the key to check a bundle's artifact seals with: the
+// given key file, else the .sealkey file saved beside the bundle at
+// collection, else none
+func bundleSealKey(bundlePath, keyPath string) (*packager.SealKey, error) {
+	if keyPath == "" {
+		sibling := strings.TrimSuffix(bundlePath, filepath.Ext(bundlePath)) + ".sealkey"
+		if _, err := os.Stat(sibling); err != nil {
+			return nil, nil
+		}
+		keyPath = sibling
+	}
+	key, err := packager.LoadSealKey(keyPath, os.Getenv(packager.SealPassphraseEnv))
+	if err != nil {
+		return nil, rterrors.Wrap(rterrors.Validation, err)
+	}
+	fmt.Printf("  - Checking artifact seals with key %s (%s)\n", key.ID, keyPath)
+	return key, nil
+}
+
+// reportBundleVerification prints a bundle verification result and returns
+// an integrity error when the bundle and manifest disagree
+func reportBundleVerification(result *packager.VerifyResult) error {
+	fmt.Printf("  - Case %s, manifest schema v%d (%s)\n", result.CaseID, result.SchemaVersion, result.Manifest)
+	if result.Migration != nil {
+		fmt.Printf("  - Note: %s\n", result.Migration)
+	}
+	fmt.Printf("  - Checked %d entries\n", result.Checked)
+	switch {
+	case result.SealsChecked && len(result.BadSeals) == 0:
+		fmt.Printf("  - %d artifact seals match key %s\n", result.Sealed, result.SealKeyID)
+	case !result.SealsChecked && result.Sealed > 0:
+		fmt.Printf("  - %d artifacts are sealed with key %s; pass --seal-key to check the seals\n", result.Sealed, result.SealKeyID)
+	}
+	if !result.OK() {
+		for _, problem := range result.Problems() {
+			fmt.Printf("  - %s\n", problem)
+		}
+		return rterrors.Integrityf("bundle does not match the manifest: %d missing, %d extra, %d mismatched, %d bad seals",
+			len(result.Missing), len(result.Extra), len(result.Mismatches), len(result.BadSeals))
+	}
+	return nil
+}
+
 // verifyDigitalSignatures verifies digital signatures
 func verifyDigitalSignatures() error {
 	// Simulate digital signature verification
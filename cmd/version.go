@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/integrity"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version information and verify the binary",
+	Long: `Show version information. With --verify, compute the SHA-256 of the running
+binary and check it against the baseline embedded at release build time and
+against a signed SHA256SUMS file next to the binary, for inclusion in case
+notes. Development builds carry no baseline and say so.`,
+	Args: cobra.NoArgs,
+	Example: `  RedTriage version
+  RedTriage version --verify
+  RedTriage version --verify --format json`,
+	Annotations: map[string]string{"category": "System"},
+	RunE:        runVersion,
+}
+
+var (
+	versionVerify bool
+	versionFormat string
+)
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionVerify, "verify", false, "Compute the binary's SHA-256 and verify it")
+	versionCmd.Flags().StringVarP(&versionFormat, "format", "f", "table", "Output format: table, json or yaml")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	if err := validateListFormat(versionFormat); err != nil {
+		return err
+	}
+
+	info := map[string]interface{}{
+		"version":    version.GetShortVersion(),
+		"commit":     version.Commit,
+		"build_date": version.BuildDate,
+		"go_version": runtime.Version(),
+		"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	var report integrity.Report
+	if versionVerify {
+		report = checkBinaryIntegrity()
+		info["integrity"] = report
+	}
+	if versionFormat != "table" {
+		if err := printStructured(versionFormat, info); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("RedTriage %s (%s) built on %s\n", version.GetShortVersion(), version.Commit, version.BuildDate)
+		fmt.Printf("Build Info: %s\n", version.GetBuildInfo())
+		if versionVerify {
+			printIntegrityReport(report)
+		}
+	}
+
+	if versionVerify && report.Tampered() {
+		// The failure is not a usage mistake
+		cmd.SilenceUsage = true
+		return rterrors.Integrityf("binary integrity verification failed: %s", report.Summary())
+	}
+	return nil
+}
+
+// printIntegrityReport prints the verification result of the binary
+func printIntegrityReport(report integrity.Report) {
+	fmt.Printf("Binary: %s\n", report.Path)
+	fmt.Printf("SHA-256: %s\n", report.SHA256)
+	if report.BaselineHash != "" {
+		fmt.Printf("Embedded baseline: %s\n", report.BaselineHash)
+	}
+	if report.HashesFile != "" {
+		fmt.Printf("Hashes file: %s (%s)\n", report.HashesFile, report.Hashes)
+	}
+	switch {
+	case report.Tampered():
+		color.New(color.FgRed, color.Bold).Printf("Verification: %s\n", report.Summary())
+	case report.DevelopmentBuild():
+		color.New(color.FgYellow).Printf("Verification: %s\n", report.Summary())
+	default:
+		color.New(color.FgGreen).Printf("Verification: %s\n", report.Summary())
+	}
+}
+
+// checkBinaryIntegrity verifies the running binary and records it in the
+// custody log. When it cannot be trusted the warnings go to stderr and the
+// audit log.
+func checkBinaryIntegrity() integrity.Report {
+	report := integrity.Check()
+	footprint.Current().RecordBinary(report.Custody())
+
+	warnings := report.Warnings()
+	if len(warnings) == 0 {
+		return report
+	}
+	warn := color.New(color.FgRed, color.Bold)
+	for _, warning := range warnings {
+		warn.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+	if report.Tampered() {
+		warn.Fprintln(os.Stderr, "WARNING: do not trust results from this binary; use a verified copy from trusted media")
+	}
+	recordAudit(audit.BinaryUntrusted, "", report.Path, map[string]interface{}{
+		"sha256": report.SHA256, "baseline": report.Baseline, "hashes": report.Hashes,
+	}, errors.New(strings.Join(warnings, "; ")))
+	return report
+}
@@ -0,0 +1,466 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ASEPType is the artifact type of the autostart entries
+const ASEPType = "asep_json"
+
+// Autostart extension point groups
+const (
+	ASEPRun         = "run"          // Run and RunOnce keys
+	ASEPWinlogon    = "winlogon"     // Winlogon Shell and Userinit
+	ASEPIFEO        = "ifeo"         // Image File Execution Options debuggers
+	ASEPAppInit     = "appinit"      // AppInit_DLLs, loaded into every process using user32
+	ASEPService     = "service"      // services whose image is outside System32
+	ASEPLSA         = "lsa"          // LSA authentication, notification and security packages
+	ASEPCOM         = "com"          // per-user COM servers, which take precedence over the machine's
+	ASEPActiveSetup = "active_setup" // Active Setup StubPath commands run at logon
+)
+
+// Hives the autostart locations are read from
+const (
+	hiveSoftware = "SOFTWARE"
+	hiveSystem   = "SYSTEM"
+	hiveUser     = "NTUSER"   // a user's NTUSER.DAT, HKU\<SID>
+	hiveClasses  = "UsrClass" // a user's UsrClass.dat, HKU\<SID>_Classes
+)
+
+// currentControlSet in a location's path stands for the control set the
+// SYSTEM hive boots from
+const currentControlSet = "CurrentControlSet"
+
+// maxASEPHashSize bounds the images whose SHA-256 is computed
+const maxASEPHashSize = 256 << 20
+
+// asepLocations are the autostart extension points swept. With subkeys set,
+// the values of each subkey of the path are read, at sub below it. values
+// lists the values read, all of them when empty.
+var asepLocations = []struct {
+	group, hive, path string
+	subkeys           bool
+	sub               string
+	values            []string
+}{
+	{ASEPRun, hiveSoftware, `Microsoft\Windows\CurrentVersion\Run`, false, "", nil},
+	{ASEPRun, hiveSoftware, `Microsoft\Windows\CurrentVersion\RunOnce`, false, "", nil},
+	{ASEPRun, hiveSoftware, `Microsoft\Windows\CurrentVersion\Policies\Explorer\Run`, false, "", nil},
+	{ASEPRun, hiveSoftware, `WOW6432Node\Microsoft\Windows\CurrentVersion\Run`, false, "", nil},
+	{ASEPRun, hiveSoftware, `WOW6432Node\Microsoft\Windows\CurrentVersion\RunOnce`, false, "", nil},
+	{ASEPRun, hiveUser, `Software\Microsoft\Windows\CurrentVersion\Run`, false, "", nil},
+	{ASEPRun, hiveUser, `Software\Microsoft\Windows\CurrentVersion\RunOnce`, false, "", nil},
+	{ASEPRun, hiveUser, `Software\Microsoft\Windows\CurrentVersion\Policies\Explorer\Run`, false, "", nil},
+	{ASEPWinlogon, hiveSoftware, `Microsoft\Windows NT\CurrentVersion\Winlogon`, false, "", []string{"Shell", "Userinit"}},
+	{ASEPWinlogon, hiveUser, `Software\Microsoft\Windows NT\CurrentVersion\Winlogon`, false, "", []string{"Shell"}},
+	{ASEPIFEO, hiveSoftware, `Microsoft\Windows NT\CurrentVersion\Image File Execution Options`, true, "", []string{"Debugger"}},
+	{ASEPIFEO, hiveSoftware, `WOW6432Node\Microsoft\Windows NT\CurrentVersion\Image File Execution Options`, true, "", []string{"Debugger"}},
+	{ASEPAppInit, hiveSoftware, `Microsoft\Windows NT\CurrentVersion\Windows`, false, "", []string{"AppInit_DLLs"}},
+	{ASEPAppInit, hiveSoftware, `WOW6432Node\Microsoft\Windows NT\CurrentVersion\Windows`, false, "", []string{"AppInit_DLLs"}},
+	{ASEPService, hiveSystem, currentControlSet + `\Services`, true, "", []string{"ImagePath"}},
+	{ASEPLSA, hiveSystem, currentControlSet + `\Control\Lsa`, false, "", []string{"Authentication Packages", "Notification Packages", "Security Packages"}},
+	{ASEPLSA, hiveSystem, currentControlSet + `\Control\Lsa\OSConfig`, false, "", []string{"Security Packages"}},
+	{ASEPCOM, hiveClasses, `CLSID`, true, "InprocServer32", []string{""}},
+	{ASEPCOM, hiveClasses, `CLSID`, true, "LocalServer32", []string{""}},
+	{ASEPActiveSetup, hiveSoftware, `Microsoft\Active Setup\Installed Components`, true, "", []string{"StubPath"}},
+	{ASEPActiveSetup, hiveSoftware, `WOW6432Node\Microsoft\Active Setup\Installed Components`, true, "", []string{"StubPath"}},
+}
+
+// executableExtensions are the extensions that end the image path of an
+// unquoted command line
+var executableExtensions = map[string]bool{
+	".exe": true, ".dll": true, ".sys": true, ".com": true, ".bat": true, ".cmd": true, ".ps1": true,
+	".vbs": true, ".vbe": true, ".js": true, ".jse": true, ".wsf": true, ".hta": true, ".scr": true,
+	".cpl": true, ".ocx": true, ".drv": true, ".msi": true, ".lnk": true,
+}
+
+// ASEPEntry is one autostart entry: a registry value that makes Windows run
+// a program or load a library, with the binary it points at
+type ASEPEntry struct {
+	Group           string    `json:"group"`
+	Hive            string    `json:"hive"`
+	Key             string    `json:"key"`
+	ValueName       string    `json:"value_name"` // empty for the default value
+	Data            string    `json:"data"`
+	KeyModified     time.Time `json:"key_modified"` // last write of the key, shared by all its values
+	User            string    `json:"user,omitempty"`
+	Image           string    `json:"image,omitempty"` // Windows path of the binary run or loaded
+	ImageMissing    bool      `json:"image_missing,omitempty"`
+	SHA256          string    `json:"sha256,omitempty"`
+	SignatureStatus string    `json:"signature_status,omitempty"` // Get-AuthenticodeSignature status, empty when not checked
+	Signer          string    `json:"signer,omitempty"`
+}
+
+// Name is the entry as autoruns tools show it: its key and value
+func (e ASEPEntry) Name() string {
+	value := e.ValueName
+	if value == "" {
+		value = "(Default)"
+	}
+	return e.Key + `\` + value
+}
+
+// asepKey is a registry key as the sweep reads it, from the live registry
+// or from a hive file
+type asepKey interface {
+	open(path string) asepKey // nil when the subkey does not exist
+	subkeyNames() []string
+	values() []asepValue
+	modified() time.Time
+}
+
+// asepValue is a registry value as text; REG_MULTI_SZ values hold several
+type asepValue struct {
+	name string
+	data []string
+}
+
+// asepHive is a hive the sweep reads: which one it is, the name its keys are
+// shown under, such as HKLM\SOFTWARE, and the environment its data is
+// expanded with
+type asepHive struct {
+	kind       string
+	name       string
+	user       string
+	root       asepKey
+	controlSet string // SYSTEM only
+	env        map[string]string
+}
+
+// sweepASEPs reads the autostart locations of the hives
+func sweepASEPs(hives []asepHive) []ASEPEntry {
+	entries := []ASEPEntry{}
+	for _, hive := range hives {
+		for _, location := range asepLocations {
+			if location.hive != hive.kind {
+				continue
+			}
+			path := strings.Replace(location.path, currentControlSet, hive.controlSet, 1)
+			key := hive.root.open(path)
+			if key == nil {
+				continue
+			}
+			if !location.subkeys {
+				entries = append(entries, hive.entries(location.group, path, key, location.values)...)
+				continue
+			}
+			for _, name := range key.subkeyNames() {
+				subpath := name
+				if location.sub != "" {
+					subpath += `\` + location.sub
+				}
+				if sub := key.open(subpath); sub != nil {
+					entries = append(entries, hive.entries(location.group, path+`\`+subpath, sub, location.values)...)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// entries returns the autostart entries of one key
+func (h asepHive) entries(group, path string, key asepKey, names []string) []ASEPEntry {
+	var entries []ASEPEntry
+	for _, value := range key.values() {
+		if len(names) > 0 && !containsFold(names, value.name) {
+			continue
+		}
+		for _, data := range value.data {
+			if trimmed := strings.TrimSpace(data); trimmed == "" || trimmed == `""` {
+				continue
+			}
+			// Services in System32 ship with Windows; those elsewhere are listed
+			if group == ASEPService && strings.Contains(strings.ToLower(data), `system32\`) {
+				continue
+			}
+			entries = append(entries, ASEPEntry{
+				Group:       group,
+				Hive:        h.name,
+				Key:         h.name + `\` + path,
+				ValueName:   value.name,
+				Data:        data,
+				KeyModified: key.modified().UTC(),
+				User:        h.user,
+				Image:       asepImage(group, data, h.env),
+			})
+		}
+	}
+	return entries
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// asepEnvironment returns the variables autostart data is expanded with: the
+// system's, and the user's when profile, the Windows path of the user's
+// profile, is set
+func asepEnvironment(systemDrive, systemRoot, profile string) map[string]string {
+	env := map[string]string{
+		"SYSTEMDRIVE":        systemDrive,
+		"SYSTEMROOT":         systemRoot,
+		"WINDIR":             systemRoot,
+		"PROGRAMFILES":       systemDrive + `\Program Files`,
+		"PROGRAMFILES(X86)":  systemDrive + `\Program Files (x86)`,
+		"PROGRAMW6432":       systemDrive + `\Program Files`,
+		"COMMONPROGRAMFILES": systemDrive + `\Program Files\Common Files`,
+		"PROGRAMDATA":        systemDrive + `\ProgramData`,
+		"ALLUSERSPROFILE":    systemDrive + `\ProgramData`,
+		"PUBLIC":             systemDrive + `\Users\Public`,
+		"TEMP":               systemRoot + `\Temp`,
+		"TMP":                systemRoot + `\Temp`,
+	}
+	if profile != "" {
+		env["USERPROFILE"] = profile
+		env["APPDATA"] = profile + `\AppData\Roaming`
+		env["LOCALAPPDATA"] = profile + `\AppData\Local`
+		env["TEMP"] = profile + `\AppData\Local\Temp`
+		env["TMP"] = env["TEMP"]
+	}
+	return env
+}
+
+var windowsEnvVar = regexp.MustCompile(`%([^%\s]+)%`)
+
+// expandWindowsEnv expands %VARIABLE% references, leaving unknown ones
+func expandWindowsEnv(s string, env map[string]string) string {
+	return windowsEnvVar.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := env[strings.ToUpper(strings.Trim(ref, "%"))]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// asepImage returns the Windows path of the program or library an entry
+// starts. For rundll32 and regsvr32 it is the library they load. Bare file
+// names are returned as they are, for the enrichment to look up; LSA
+// packages are library names without their extension.
+func asepImage(group, data string, env map[string]string) string {
+	command := expandWindowsEnv(strings.TrimSpace(data), env)
+	if group == ASEPLSA && !strings.ContainsAny(command, `\.`) {
+		return command + ".dll"
+	}
+
+	image, rest := splitCommand(command)
+	switch strings.ToLower(strings.TrimSuffix(windowsBase(image), ".exe")) {
+	case "rundll32", "regsvr32":
+		for _, arg := range strings.Fields(rest) {
+			if !strings.HasPrefix(arg, "/") && !strings.HasPrefix(arg, "-") {
+				library, _ := splitCommand(strings.TrimSpace(rest[strings.Index(rest, arg):]))
+				image = library
+				break
+			}
+		}
+	}
+
+	if i := strings.Index(image, ","); i > 0 && executableExtensions[strings.ToLower(filepath.Ext(image[:i]))] {
+		image = image[:i]
+	}
+	image = strings.TrimRight(image, ", ")
+	image = strings.TrimPrefix(image, `\??\`)
+	if rest, ok := cutPrefixFold(image, `\SystemRoot\`); ok {
+		image = env["SYSTEMROOT"] + `\` + rest
+	}
+	return image
+}
+
+// splitCommand splits a command line into its image and arguments. An
+// unquoted image may contain spaces; it ends at the first word ending in an
+// executable extension.
+func splitCommand(command string) (string, string) {
+	if strings.HasPrefix(command, `"`) {
+		if end := strings.Index(command[1:], `"`); end >= 0 {
+			return command[1 : end+1], strings.TrimSpace(command[end+2:])
+		}
+		return strings.Trim(command, `"`), ""
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	for i := range fields {
+		candidate := strings.Join(fields[:i+1], " ")
+		if executableExtensions[strings.ToLower(filepath.Ext(strings.TrimRight(candidate, ",")))] {
+			return candidate, strings.Join(fields[i+1:], " ")
+		}
+	}
+	return fields[0], strings.Join(fields[1:], " ")
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// windowsBase returns the last element of a Windows path
+func windowsBase(path string) string {
+	return path[strings.LastIndexAny(path, `\/`)+1:]
+}
+
+// enrichASEPs looks up the image of each entry through locate, which maps a
+// Windows path to a readable file or returns "" when there is none, and
+// records its SHA-256. Bare names are looked up in System32 and the Windows
+// directory, as Windows searches them, and relative paths below the Windows
+// directory.
+func enrichASEPs(entries []ASEPEntry, systemRoot string, locate func(string) string) {
+	hashes := make(map[string]string)
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Image == "" {
+			continue
+		}
+		candidates := []string{entry.Image}
+		switch {
+		case !strings.ContainsAny(entry.Image, `\/`):
+			candidates = []string{systemRoot + `\System32\` + entry.Image, systemRoot + `\` + entry.Image}
+		case !(len(entry.Image) > 2 && entry.Image[1] == ':') && !strings.HasPrefix(entry.Image, `\\`):
+			candidates = []string{systemRoot + `\` + strings.TrimPrefix(entry.Image, `\`)}
+		}
+
+		entry.ImageMissing = true
+		for _, candidate := range candidates {
+			file := locate(candidate)
+			if file == "" {
+				continue
+			}
+			entry.Image, entry.ImageMissing = candidate, false
+			hash, ok := hashes[file]
+			if !ok {
+				if info, err := os.Stat(file); err == nil && info.Size() <= maxASEPHashSize {
+					hash, _ = hashFile(context.Background(), file)
+				}
+				hashes[file] = hash
+			}
+			entry.SHA256 = hash
+			break
+		}
+	}
+}
+
+// sortASEPs orders entries by group, key and value
+func sortASEPs(entries []ASEPEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Group != entries[j].Group {
+			return entries[i].Group < entries[j].Group
+		}
+		return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
+	})
+}
+
+// ASEPArtifact wraps autostart entries in an artifact result
+func ASEPArtifact(entries []ASEPEntry) ArtifactResult {
+	artifact := NewBaseArtifact("autostart_entries", "Autostart extension points in the registry: Run keys, Winlogon, IFEO debuggers, AppInit_DLLs, services, LSA packages, COM servers and Active Setup", "registry", ASEPType).Artifact
+	if entries == nil {
+		entries = []ASEPEntry{}
+	}
+	sortASEPs(entries)
+	data, _ := json.Marshal(entries)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     entries,
+		Size:     int64(len(data)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "asep",
+			Version:     "1.0.0",
+			Tags:        map[string]string{"entries": strconv.Itoa(len(entries))},
+		},
+	}
+}
+
+// ASEPEntries returns the entries of an autostart artifact, decoding them
+// when the artifact was read back from a bundle
+func ASEPEntries(result ArtifactResult) []ASEPEntry {
+	switch data := result.Data.(type) {
+	case []ASEPEntry:
+		return data
+	case nil:
+		return nil
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil
+		}
+		if text, ok := data.(string); ok {
+			raw = []byte(text)
+		}
+		var entries []ASEPEntry
+		if json.Unmarshal(raw, &entries) != nil {
+			return nil
+		}
+		return entries
+	}
+}
+
+// hiveASEPKey reads a key of a hive file
+type hiveASEPKey struct {
+	key *HiveKey
+}
+
+func (k hiveASEPKey) open(path string) asepKey {
+	key, _ := k.key.Path(path)
+	if key == nil {
+		return nil
+	}
+	return hiveASEPKey{key}
+}
+
+func (k hiveASEPKey) subkeyNames() []string {
+	keys, _ := k.key.Subkeys()
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+func (k hiveASEPKey) values() []asepValue {
+	values, _ := k.key.Values()
+	list := make([]asepValue, 0, len(values))
+	for _, value := range values {
+		if value.Type == RegSZ || value.Type == RegExpandSZ || value.Type == RegMultiSZ {
+			list = append(list, asepValue{name: value.Name, data: value.Strings()})
+		}
+	}
+	return list
+}
+
+func (k hiveASEPKey) modified() time.Time {
+	return k.key.LastWrite
+}
+
+// openASEPHive opens a hive file for the sweep
+func openASEPHive(kind, name, user, path string, env map[string]string) (asepHive, error) {
+	hive, err := OpenHive(path)
+	if err != nil {
+		return asepHive{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	root, err := hive.Root()
+	if err != nil {
+		return asepHive{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	h := asepHive{kind: kind, name: name, user: user, root: hiveASEPKey{root}, env: env}
+	if kind == hiveSystem {
+		h.controlSet = bootControlSet(root)
+	}
+	return h, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package collector
+
+import "context"
+
+// CollectLiveASEPs collects nothing: autostart extension points are
+// registry locations
+func CollectLiveASEPs(ctx context.Context) []ArtifactResult {
+	return nil
+}
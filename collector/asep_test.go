@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// asepUpdater is the program the test user Run key starts
+const asepUpdater = "MZ test updater"
+
+// TestSweepOfflineASEPs builds a Windows image whose SOFTWARE, SYSTEM and
+// NTUSER.DAT hives hold Run, Winlogon, IFEO, service and LSA entries, and
+// checks the offline sweep reads each with its image and hash and leaves out
+// services in System32
+func TestSweepOfflineASEPs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "windows-image")
+	recent := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	old := time.Date(2023, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	software := &hiveBuilder{}
+	run := software.key("Run", old, nil,
+		software.value("LegacyAgent", RegSZ, utf16z(`C:\Users\Public\agent.exe`)))
+	currentVersion := software.key("CurrentVersion", old, software.keys(run))
+	windows := software.key("Windows", old, software.keys(currentVersion))
+	winlogon := software.key("Winlogon", old, nil,
+		software.value("Shell", RegSZ, utf16z("explorer.exe")),
+		software.value("Userinit", RegSZ, utf16z(`C:\Windows\system32\userinit.exe,`)))
+	ifeo := software.key("Image File Execution Options", recent, software.keys(
+		software.key("sethc.exe", recent, nil, software.value("Debugger", RegSZ, utf16z(`C:\Windows\System32\cmd.exe`))),
+		software.key("notepad.exe", old, nil, software.value("GlobalFlag", RegSZ, utf16z("0x200")))))
+	windowsNT := software.key("Windows NT", old, software.keys(software.key("CurrentVersion", old, software.keys(winlogon, ifeo))))
+	microsoft := software.key("Microsoft", old, software.keys(windows, windowsNT))
+	software.write(software.key("ROOT", old, software.keys(microsoft)))
+
+	system := &hiveBuilder{}
+	services := system.key("Services", recent, system.keys(
+		system.key("SyncHost", recent, nil, system.value("ImagePath", RegExpandSZ, utf16z(`"C:\ProgramData\SyncHost\synchost.exe" -service`))),
+		system.key("W32Time", old, nil, system.value("ImagePath", RegExpandSZ, utf16z(`%SystemRoot%\system32\svchost.exe -k LocalService`)))))
+	lsa := system.key("Lsa", old, nil,
+		system.value("Security Packages", RegMultiSZ, append(append(utf16z("kerberos"), utf16z("msv1_0")...), 0, 0)))
+	control := system.key("Control", old, system.keys(lsa))
+	controlSet := system.key("ControlSet001", old, system.keys(control, services))
+	selectKey := system.key("Select", old, nil, system.value("Current", RegDWORD, []byte{1, 0, 0, 0}))
+	system.write(system.key("ROOT", old, system.keys(controlSet, selectKey)))
+
+	user := &hiveBuilder{}
+	userRun := user.key("Run", recent, nil,
+		user.value("Updater", RegSZ, utf16z(`"%LOCALAPPDATA%\Updater\updater.exe" --silent`)))
+	userVersion := user.key("CurrentVersion", recent, user.keys(userRun))
+	userWindows := user.key("Windows", recent, user.keys(userVersion))
+	userSoftware := user.key("Software", recent, user.keys(user.key("Microsoft", recent, user.keys(userWindows))))
+	user.write(user.key("ROOT", recent, user.keys(userSoftware)))
+
+	files := map[string][]byte{
+		"Windows/System32/config/SOFTWARE":              software.data,
+		"Windows/System32/config/SYSTEM":                system.data,
+		"Windows/System32/cmd.exe":                      []byte("MZ cmd"),
+		"Windows/System32/userinit.exe":                 []byte("MZ userinit"),
+		"Windows/explorer.exe":                          []byte("MZ explorer"),
+		"Users/alice/NTUSER.DAT":                        user.data,
+		"Users/alice/AppData/Local/Updater/updater.exe": []byte(asepUpdater),
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	autostart, ok := collectTestImage(t, root, WalkScope{})["autostart_entries"]
+	if !ok || autostart.Error != nil {
+		t.Fatalf("autostart entries not collected from the image: %v", autostart.Error)
+	}
+	entries := make(map[string]ASEPEntry)
+	for _, entry := range ASEPEntries(autostart) {
+		entries[entry.ValueName+":"+entry.Data] = entry
+	}
+
+	sum := sha256.Sum256([]byte(asepUpdater))
+	want := map[string]string{
+		`Updater:"%LOCALAPPDATA%\Updater\updater.exe" --silent`:     `C:\Users\alice\AppData\Local\Updater\updater.exe`,
+		`LegacyAgent:C:\Users\Public\agent.exe`:                     `C:\Users\Public\agent.exe`,
+		`Shell:explorer.exe`:                                        `C:\Windows\explorer.exe`,
+		`Userinit:C:\Windows\system32\userinit.exe,`:                `C:\Windows\system32\userinit.exe`,
+		`Debugger:C:\Windows\System32\cmd.exe`:                      `C:\Windows\System32\cmd.exe`,
+		`ImagePath:"C:\ProgramData\SyncHost\synchost.exe" -service`: `C:\ProgramData\SyncHost\synchost.exe`,
+		`Security Packages:kerberos`:                                `kerberos.dll`,
+		`Security Packages:msv1_0`:                                  `msv1_0.dll`,
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("swept %d autostart entries, want %d: %v", len(entries), len(want), entries)
+	}
+	for name, image := range want {
+		entry, ok := entries[name]
+		if !ok || entry.Image != image {
+			t.Errorf("autostart entry %s read as %+v, want image %s", name, entry, image)
+		}
+	}
+	updater := entries[`Updater:"%LOCALAPPDATA%\Updater\updater.exe" --silent`]
+	if updater.SHA256 != hex.EncodeToString(sum[:]) || updater.User != "alice" || !updater.KeyModified.Equal(recent) {
+		t.Errorf("user Run entry lacks its hash, user or key time: %+v", updater)
+	}
+	if synchost := entries[`ImagePath:"C:\ProgramData\SyncHost\synchost.exe" -service`]; !synchost.ImageMissing || !strings.HasSuffix(synchost.Key, `ControlSet001\Services\SyncHost`) {
+		t.Errorf("service entry is read wrongly: %+v", synchost)
+	}
+}
@@ -0,0 +1,203 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// CollectLiveASEPs sweeps the autostart locations of the running registry:
+// HKLM and the hive of every user loaded under HKU. The Authenticode
+// signature of each image found is checked with a single PowerShell run.
+func CollectLiveASEPs(ctx context.Context) []ArtifactResult {
+	started := time.Now()
+	systemDrive, systemRoot := liveSystemPaths()
+	machineEnv := asepEnvironment(systemDrive, systemRoot, "")
+
+	hives := []asepHive{
+		{kind: hiveSoftware, name: `HKLM\SOFTWARE`, root: liveASEPKey{registry.LOCAL_MACHINE, "SOFTWARE"}, env: machineEnv},
+		{kind: hiveSystem, name: `HKLM\SYSTEM`, root: liveASEPKey{registry.LOCAL_MACHINE, "SYSTEM"}, controlSet: currentControlSet, env: machineEnv},
+	}
+	for _, sid := range (liveASEPKey{registry.USERS, ""}).subkeyNames() {
+		if sid == ".DEFAULT" {
+			continue
+		}
+		kind, account := hiveUser, sid
+		if strings.HasSuffix(sid, "_Classes") {
+			kind, account = hiveClasses, strings.TrimSuffix(sid, "_Classes")
+		}
+		user, profile := liveProfile(account)
+		hives = append(hives, asepHive{
+			kind: kind,
+			name: `HKU\` + sid,
+			user: user,
+			root: liveASEPKey{registry.USERS, sid},
+			env:  asepEnvironment(systemDrive, systemRoot, profile),
+		})
+	}
+
+	entries := sweepASEPs(hives)
+	enrichASEPs(entries, systemRoot, func(path string) string {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+		return ""
+	})
+
+	var runs []CommandRun
+	if ctx.Err() == nil {
+		if run, ok := checkASEPSignatures(ctx, entries); ok {
+			runs = append(runs, run)
+		}
+	}
+
+	result := ASEPArtifact(entries)
+	result.Metadata.StartedAt = started
+	result.Metadata.Source = "live registry"
+	result.Metadata.Commands = runs
+	return []ArtifactResult{result}
+}
+
+// liveSystemPaths returns the system drive and Windows directory of the
+// running system
+func liveSystemPaths() (string, string) {
+	systemRoot := os.Getenv("SystemRoot")
+	systemDrive := os.Getenv("SystemDrive")
+	if systemRoot == "" {
+		systemRoot = `C:\Windows`
+	}
+	if systemDrive == "" {
+		systemDrive = systemRoot[:2]
+	}
+	return systemDrive, systemRoot
+}
+
+// liveProfile returns the account name and profile path of a SID from the
+// ProfileList
+func liveProfile(sid string) (string, string) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList\`+sid, registry.QUERY_VALUE)
+	if err != nil {
+		return sid, ""
+	}
+	defer key.Close()
+	profile, _, err := key.GetStringValue("ProfileImagePath")
+	if err != nil || profile == "" {
+		return sid, ""
+	}
+	profile, _ = registry.ExpandString(profile)
+	return windowsBase(profile), profile
+}
+
+// checkASEPSignatures records the Authenticode status and signer of the
+// images of the entries. It reports false when there was nothing to check.
+func checkASEPSignatures(ctx context.Context, entries []ASEPEntry) (CommandRun, bool) {
+	seen := make(map[string]bool)
+	var images []string
+	for _, entry := range entries {
+		if entry.Image != "" && !entry.ImageMissing && !seen[strings.ToLower(entry.Image)] {
+			seen[strings.ToLower(entry.Image)] = true
+			images = append(images, entry.Image)
+		}
+	}
+	if len(images) == 0 {
+		return CommandRun{}, false
+	}
+
+	const script = `$input | ForEach-Object { $s = Get-AuthenticodeSignature -LiteralPath $_; '{0}|{1}|{2}' -f $_, $s.Status, $s.SignerCertificate.Subject }`
+	cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.Stdin = strings.NewReader(strings.Join(images, "\r\n") + "\r\n")
+	output, run, err := RunCommand(cmd)
+	if err != nil {
+		return run, true
+	}
+
+	type signature struct{ status, signer string }
+	signatures := make(map[string]signature)
+	for _, line := range strings.Split(DecodeText(output).Text, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(fields) == 3 {
+			signatures[strings.ToLower(fields[0])] = signature{fields[1], fields[2]}
+		}
+	}
+	for i := range entries {
+		if sig, ok := signatures[strings.ToLower(entries[i].Image)]; ok {
+			entries[i].SignatureStatus, entries[i].Signer = sig.status, sig.signer
+		}
+	}
+	return run, true
+}
+
+// liveASEPKey reads a key of the running registry. Each call opens the key
+// afresh, so nothing has to be closed.
+type liveASEPKey struct {
+	root registry.Key
+	path string
+}
+
+const liveASEPAccess = registry.QUERY_VALUE | registry.ENUMERATE_SUB_KEYS | registry.WOW64_64KEY
+
+func (k liveASEPKey) join(path string) string {
+	if k.path == "" {
+		return path
+	}
+	return k.path + `\` + path
+}
+
+func (k liveASEPKey) open(path string) asepKey {
+	key, err := registry.OpenKey(k.root, k.join(path), liveASEPAccess)
+	if err != nil {
+		return nil
+	}
+	key.Close()
+	return liveASEPKey{k.root, k.join(path)}
+}
+
+func (k liveASEPKey) subkeyNames() []string {
+	key, err := registry.OpenKey(k.root, k.path, liveASEPAccess)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+	names, _ := key.ReadSubKeyNames(-1)
+	return names
+}
+
+func (k liveASEPKey) values() []asepValue {
+	key, err := registry.OpenKey(k.root, k.path, liveASEPAccess)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+	names, _ := key.ReadValueNames(-1)
+	var values []asepValue
+	for _, name := range names {
+		if data, _, err := key.GetStringValue(name); err == nil {
+			values = append(values, asepValue{name: name, data: []string{data}})
+		} else if errors.Is(err, registry.ErrUnexpectedType) {
+			if list, _, err := key.GetStringsValue(name); err == nil {
+				values = append(values, asepValue{name: name, data: list})
+			}
+		}
+	}
+	return values
+}
+
+func (k liveASEPKey) modified() time.Time {
+	key, err := registry.OpenKey(k.root, k.path, liveASEPAccess)
+	if err != nil {
+		return time.Time{}
+	}
+	defer key.Close()
+	info, err := key.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
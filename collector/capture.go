@@ -0,0 +1,253 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/utils"
+)
+
+// CaptureOptions configures a live packet capture
+type CaptureOptions struct {
+	Duration    time.Duration // How long to capture for
+	Tool        string        // tcpdump, dumpcap or auto
+	TcpdumpPath string        // Explicit tcpdump path (optional)
+	DumpcapPath string        // Explicit dumpcap path (optional)
+	Interface   string        // Interface to capture on (default: primary interface)
+	Filter      string        // BPF capture filter
+	OutputDir   string        // Directory the .pcap is written to
+}
+
+// NetworkCapture describes the outcome of a packet capture. A capture that
+// could not run is reported as skipped with a reason instead of an error.
+type NetworkCapture struct {
+	Path      string        `json:"path,omitempty"`
+	Tool      string        `json:"tool,omitempty"`
+	ToolPath  string        `json:"tool_path,omitempty"`
+	Interface string        `json:"interface,omitempty"`
+	Filter    string        `json:"filter"`
+	Duration  time.Duration `json:"duration"`
+	StartedAt time.Time     `json:"started_at"`
+	Size      int64         `json:"size"`
+	Checksum  string        `json:"checksum,omitempty"`
+	Skipped   bool          `json:"skipped,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+	// Code classifies Reason
+	Code ReasonCode `json:"reason_code,omitempty"`
+}
+
+// CaptureNetwork captures packets on the primary interface for the configured
+// duration using tcpdump or dumpcap. It degrades gracefully: a missing capture
+// tool or insufficient privileges yield a skipped capture, not an error.
+func CaptureNetwork(ctx context.Context, opts CaptureOptions) (*NetworkCapture, error) {
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("capture duration must be positive, got %s", opts.Duration)
+	}
+
+	capture := &NetworkCapture{
+		Filter:    opts.Filter,
+		Duration:  opts.Duration,
+		StartedAt: time.Now(),
+	}
+
+	tool, toolPath, err := findCaptureTool(opts)
+	if err != nil {
+		capture.Skipped = true
+		capture.Reason = err.Error()
+		capture.Code = ReasonToolMissing
+		return capture, nil
+	}
+	capture.Tool = tool
+	capture.ToolPath = toolPath
+
+	iface := opts.Interface
+	if iface == "" {
+		iface, err = PrimaryInterface()
+		if err != nil {
+			capture.Skipped = true
+			capture.Reason = fmt.Sprintf("failed to determine primary interface: %v", err)
+			return capture, nil
+		}
+	}
+	capture.Interface = iface
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	capture.Path = filepath.Join(opts.OutputDir, fmt.Sprintf("network-capture-%s.pcap", capture.StartedAt.Format("20060102-150405")))
+
+	var args []string
+	var runTimeout time.Duration
+	switch tool {
+	case "dumpcap":
+		// dumpcap stops itself once the duration is reached
+		args = []string{"-i", iface, "-a", "duration:" + strconv.Itoa(int(opts.Duration.Seconds())), "-F", "pcap", "-w", capture.Path, "-q"}
+		if opts.Filter != "" {
+			args = append(args, "-f", opts.Filter)
+		}
+		runTimeout = opts.Duration + 15*time.Second
+	default:
+		// tcpdump runs until interrupted; -U flushes each packet so nothing is lost
+		args = []string{"-i", iface, "-n", "-U", "-w", capture.Path}
+		if opts.Filter != "" {
+			args = append(args, opts.Filter)
+		}
+		runTimeout = opts.Duration
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, toolPath, args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 5 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil && runCtx.Err() == nil {
+		capture.Skipped = true
+		capture.Reason, capture.Code = captureFailureReason(stderr.String(), err)
+		os.Remove(capture.Path)
+		capture.Path = ""
+		return capture, nil
+	}
+
+	size, err := utils.GetFileSize(capture.Path)
+	if err != nil {
+		capture.Skipped = true
+		capture.Reason = fmt.Sprintf("%s produced no capture file: %s", tool, strings.TrimSpace(stderr.String()))
+		capture.Path = ""
+		return capture, nil
+	}
+	capture.Size = size
+
+	checksum, err := utils.GetFileHash(capture.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate capture checksum: %w", err)
+	}
+	capture.Checksum = checksum
+
+	return capture, nil
+}
+
+// ArtifactResult converts the capture into an artifact result so it is packaged
+// with the rest of the collection
+func (c *NetworkCapture) ArtifactResult() ArtifactResult {
+	artifact := NewBaseArtifact("network_capture", "Live packet capture", "network", "file").Artifact
+	artifact.Volatile = true
+	artifact.Timeout = c.Duration
+	artifact.Parameters["path"] = c.Path
+	artifact.Parameters["tool"] = c.Tool
+	artifact.Parameters["interface"] = c.Interface
+	artifact.Parameters["filter"] = c.Filter
+
+	result := ArtifactResult{
+		Artifact: artifact,
+		Data:     c,
+		Metadata: Metadata{
+			StartedAt:   c.StartedAt,
+			CollectedAt: c.StartedAt,
+			Collector:   c.Tool,
+			Source:      c.Interface,
+			Tags: map[string]string{
+				"interface": c.Interface,
+				"filter":    c.Filter,
+				"duration":  c.Duration.String(),
+			},
+		},
+		Size:     c.Size,
+		Checksum: c.Checksum,
+	}
+	if c.Skipped {
+		result.Fail(c.Code, fmt.Errorf("network capture skipped: %s", c.Reason))
+	} else {
+		result.Metadata.CollectedAt = c.StartedAt.Add(c.Duration)
+		result.Metadata.Duration = c.Duration
+	}
+
+	return result
+}
+
+// PrimaryInterface returns the name of the interface that carries the default
+// route. No packets are sent: connecting a UDP socket only selects a route.
+func PrimaryInterface() (string, error) {
+	conn, err := net.Dial("udp", "192.0.2.1:9")
+	if err != nil {
+		return "", fmt.Errorf("no default route: %w", err)
+	}
+	localIP := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to list interfaces: %w", err)
+	}
+
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(localIP) {
+				return iface.Name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no interface has address %s", localIP)
+}
+
+// findCaptureTool resolves the capture tool to run
+func findCaptureTool(opts CaptureOptions) (string, string, error) {
+	candidates := []string{"tcpdump", "dumpcap"}
+	switch opts.Tool {
+	case "tcpdump":
+		candidates = []string{"tcpdump"}
+	case "dumpcap":
+		candidates = []string{"dumpcap"}
+	}
+
+	for _, tool := range candidates {
+		path := opts.TcpdumpPath
+		if tool == "dumpcap" {
+			path = opts.DumpcapPath
+		}
+		if path != "" {
+			if utils.FileExists(path) {
+				return tool, path, nil
+			}
+			continue
+		}
+		if found, err := exec.LookPath(tool); err == nil {
+			return tool, found, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no capture tool available (looked for %s)", strings.Join(candidates, ", "))
+}
+
+// captureFailureReason explains why the capture tool failed
+func captureFailureReason(stderr string, err error) (string, ReasonCode) {
+	lower := strings.ToLower(stderr)
+	for _, marker := range []string{"permission", "not permitted", "privilege", "access is denied"} {
+		if strings.Contains(lower, marker) {
+			return "insufficient privileges to capture packets", ReasonAccessDenied
+		}
+	}
+
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Sprintf("capture failed: %s", msg), ReasonFor(err)
+	}
+	return fmt.Sprintf("capture failed: %v", err), ReasonFor(err)
+}
@@ -0,0 +1,483 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Data types of the carved artifacts
+const (
+	CarvedPrefetchType     = "carved_prefetch_json" // CarvedPrefetch entries
+	CarvedEventRecordsType = "carved_evtx_json"     // CarvedEventRecord entries
+	CarvedLogLinesType     = "carved_log_lines_json"
+)
+
+// RecoveredConfidence is the confidence tag of carved artifacts: a carved
+// record has no file system metadata and may be a fragment of something else
+const RecoveredConfidence = "low"
+
+const (
+	// carveBlockSize is how much of a source is scanned at a time
+	carveBlockSize = 4 << 20
+	// carveOverlap is read past each block so records that start in it are
+	// carved whole; it covers the largest event record
+	carveOverlap = 64 << 10
+	// maxCarvedRecords caps each carved artifact
+	maxCarvedRecords = 10000
+)
+
+// defaultCarveSources are image files scanned when no source is given, by
+// image OS: the NTFS metafiles keep resident and journaled copies of deleted
+// files, and page and swap files keep pages of files that were read
+var defaultCarveSources = map[string][]string{
+	"windows": {"$MFT", "$LogFile", "pagefile.sys", "swapfile.sys"},
+	"linux":   {"swapfile", "swap.img"},
+}
+
+// CarveOptions selects what is carved from an offline image
+type CarveOptions struct {
+	Root string
+	// Sources are raw files to scan, such as a partition image or the
+	// unallocated space extracted with blkls. Empty scans the default
+	// sources present in the image.
+	Sources []string
+}
+
+// CarvedPrefetch is a prefetch file found outside the file system
+type CarvedPrefetch struct {
+	Source     string     `json:"source"`
+	Offset     int64      `json:"offset"`
+	Version    int        `json:"version,omitempty"`
+	Executable string     `json:"executable,omitempty"`
+	Hash       string     `json:"hash,omitempty"`
+	RunCount   uint32     `json:"run_count,omitempty"`
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	// Compressed marks a Windows 10 compressed prefetch file, of which only
+	// the location and size are known
+	Compressed bool  `json:"compressed,omitempty"`
+	Size       int64 `json:"size,omitempty"`
+	// InImage is set when the image still has a prefetch file of that name
+	InImage bool `json:"in_image"`
+}
+
+// CarvedEventRecord is a Windows event log record found outside a log file
+type CarvedEventRecord struct {
+	Source   string    `json:"source"`
+	Offset   int64     `json:"offset"`
+	RecordID uint64    `json:"record_id"`
+	Written  time.Time `json:"written"`
+	// Strings are the readable strings of the record: provider, channel,
+	// computer and event data
+	Strings []string `json:"strings,omitempty"`
+}
+
+// CarvedLogLine is a syslog style line found outside a log file
+type CarvedLogLine struct {
+	Source string `json:"source"`
+	Offset int64  `json:"offset"`
+	Line   string `json:"line"`
+}
+
+// CarveImage scans raw sources of a mounted image for deleted prefetch
+// files, event log records and log lines. Results are tagged recovered, with
+// low confidence.
+func CarveImage(ctx context.Context, opts CarveOptions) []ArtifactResult {
+	imageOS := DetectImageOS(opts.Root)
+	sources := carveSourcePaths(opts, imageOS)
+
+	carver := &carver{seen: make(map[[16]byte]bool)}
+	if imageOS != "linux" {
+		carver.prefetch, carver.events = &[]CarvedPrefetch{}, &[]CarvedEventRecord{}
+	}
+	if imageOS != "windows" {
+		carver.lines = &[]CarvedLogLine{}
+	}
+
+	var scanned int64
+	var problems []string
+	for _, source := range sources {
+		carver.source = source
+		if rel, err := filepath.Rel(opts.Root, source); err == nil && !strings.HasPrefix(rel, "..") {
+			carver.source = "/" + filepath.ToSlash(rel)
+		}
+		n, err := carver.scanFile(ctx, source)
+		scanned += n
+		if err != nil {
+			if ctx.Err() != nil {
+				problems = append(problems, ctx.Err().Error())
+				break
+			}
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if carver.prefetch != nil {
+		markPrefetchInImage(opts.Root, *carver.prefetch)
+	}
+
+	var results []ArtifactResult
+	add := func(name, description, category, dataType string, entries interface{}, count int) {
+		artifact := NewBaseArtifact(name, description, category, dataType).Artifact
+		artifact.Platform = imageOS
+		artifact.Parameters["sources"] = strings.Join(sources, ",")
+
+		result := newCarvedResult(artifact, opts.Root, entries)
+		result.Metadata.Tags["scanned_bytes"] = fmt.Sprintf("%d", scanned)
+		result.Metadata.Tags["records"] = fmt.Sprintf("%d", count)
+		if count >= maxCarvedRecords {
+			result.Metadata.Tags["truncated"] = "true"
+			result.Reason = ReasonTruncated
+		}
+		if len(problems) > 0 {
+			result.Metadata.Tags["unreadable"] = strings.Join(problems, "; ")
+		}
+		if len(sources) == 0 {
+			result.Fail(ReasonNotPresent, errors.New("no carve sources in the image: pass a raw partition or unallocated space file with --carve-source"))
+		}
+		results = append(results, result)
+	}
+	if carver.prefetch != nil {
+		add("recovered_prefetch", "Prefetch files carved from raw image data", "trace", CarvedPrefetchType, *carver.prefetch, len(*carver.prefetch))
+		add("recovered_event_records", "Event log records carved from raw image data", "log", CarvedEventRecordsType, *carver.events, len(*carver.events))
+	}
+	if carver.lines != nil {
+		add("recovered_log_lines", "Syslog lines carved from raw image data", "log", CarvedLogLinesType, *carver.lines, len(*carver.lines))
+	}
+	return results
+}
+
+// carveSourcePaths returns the sources given, or the default sources present
+// in the image
+func carveSourcePaths(opts CarveOptions, imageOS string) []string {
+	if len(opts.Sources) > 0 {
+		return opts.Sources
+	}
+	var sources []string
+	for _, name := range defaultCarveSources[imageOS] {
+		sources = append(sources, imageGlob(opts.Root, name, imageOS == "windows")...)
+	}
+	return sources
+}
+
+// newCarvedResult stores carved entries as the JSON data of an artifact
+func newCarvedResult(artifact Artifact, root string, entries interface{}) ArtifactResult {
+	result := ArtifactResult{
+		Artifact: artifact,
+		Metadata: Metadata{
+			CollectedAt: time.Now(),
+			Collector:   "carve",
+			Source:      root,
+			Tags: map[string]string{
+				"mode":       "offline",
+				"recovered":  "true",
+				"confidence": RecoveredConfidence,
+			},
+		},
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		result.Error = fmt.Errorf("failed to marshal %s: %w", artifact.Name, err)
+		return result
+	}
+	result.SetText(data)
+	return result
+}
+
+// carver collects what the scans find. A nil list is not carved.
+type carver struct {
+	source   string
+	prefetch *[]CarvedPrefetch
+	events   *[]CarvedEventRecord
+	lines    *[]CarvedLogLine
+	// seen drops copies of the same record found in several places
+	seen map[[16]byte]bool
+}
+
+// scanFile scans a source block by block and returns the bytes scanned
+func (c *carver) scanFile(ctx context.Context, path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, carveBlockSize+carveOverlap)
+	var offset int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return offset, err
+		}
+		n, err := file.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return offset, fmt.Errorf("failed to read %s at %d: %w", path, offset, err)
+		}
+		if n == 0 {
+			return offset, nil
+		}
+		limit := n
+		if limit > carveBlockSize {
+			limit = carveBlockSize
+		}
+		c.scanBlock(buf[:n], offset, limit)
+		offset += int64(limit)
+		if n <= carveBlockSize {
+			return offset, nil
+		}
+	}
+}
+
+// scanBlock carves the records starting before limit in window, which is
+// read from base
+func (c *carver) scanBlock(window []byte, base int64, limit int) {
+	if c.prefetch != nil {
+		c.carvePrefetch(window, base, limit)
+		c.carveEventRecords(window, base, limit)
+	}
+	if c.lines != nil {
+		c.carveLogLines(window, base, limit)
+	}
+}
+
+// firstSeen reports whether a record with this content was not carved before
+func (c *carver) firstSeen(kind string, content []byte) bool {
+	sum := sha256.Sum256(append([]byte(kind), content...))
+	var key [16]byte
+	copy(key[:], sum[:])
+	if c.seen[key] {
+		return false
+	}
+	c.seen[key] = true
+	return true
+}
+
+// prefetchOffsets are the offsets of the last run time and run count in an
+// uncompressed prefetch file, by format version
+var prefetchOffsets = map[uint32]struct{ lastRun, runCount int }{
+	17: {0x78, 0x90}, // Windows XP and 2003
+	23: {0x80, 0x98}, // Windows Vista and 7
+	26: {0x80, 0xD0}, // Windows 8.1
+	30: {0x80, 0xD0}, // Windows 10 and 11, once decompressed
+}
+
+// carvePrefetch finds uncompressed prefetch files by their SCCA signature and
+// compressed ones by their MAM header at a sector boundary
+func (c *carver) carvePrefetch(window []byte, base int64, limit int) {
+	for i := 0; len(*c.prefetch) < maxCarvedRecords; i++ {
+		next := bytes.Index(window[i:], []byte("SCCA"))
+		if next < 0 {
+			break
+		}
+		i += next
+		start := i - 4
+		if start >= limit {
+			break
+		}
+		if start < 0 || start+0xD4 > len(window) {
+			continue
+		}
+		version := binary.LittleEndian.Uint32(window[start:])
+		offsets, ok := prefetchOffsets[version]
+		size := binary.LittleEndian.Uint32(window[start+12:])
+		if !ok || size < 0xD4 || size > 16<<20 {
+			continue
+		}
+		executable, ok := utf16String(window[start+16 : start+76])
+		if !ok || executable == "" {
+			continue
+		}
+		lastRun, ok := filetime(binary.LittleEndian.Uint64(window[start+offsets.lastRun:]))
+		if !ok {
+			continue
+		}
+		header := window[start : start+0xD4]
+		if !c.firstSeen("prefetch", header) {
+			continue
+		}
+		*c.prefetch = append(*c.prefetch, CarvedPrefetch{
+			Source:     c.source,
+			Offset:     base + int64(start),
+			Version:    int(version),
+			Executable: executable,
+			Hash:       fmt.Sprintf("%08X", binary.LittleEndian.Uint32(window[start+76:])),
+			RunCount:   binary.LittleEndian.Uint32(window[start+offsets.runCount:]),
+			LastRun:    &lastRun,
+			Size:       int64(size),
+		})
+	}
+
+	for start := int((512 - base%512) % 512); start < limit && start+8 <= len(window) && len(*c.prefetch) < maxCarvedRecords; start += 512 {
+		if !bytes.Equal(window[start:start+4], []byte("MAM\x04")) {
+			continue
+		}
+		size := binary.LittleEndian.Uint32(window[start+4:])
+		if size < 0xD4 || size > 16<<20 {
+			continue
+		}
+		*c.prefetch = append(*c.prefetch, CarvedPrefetch{
+			Source:     c.source,
+			Offset:     base + int64(start),
+			Compressed: true,
+			Size:       int64(size),
+		})
+	}
+}
+
+// carveEventRecords finds EVTX event records by their signature, with the
+// size repeated at their end and a binary XML body
+func (c *carver) carveEventRecords(window []byte, base int64, limit int) {
+	for i := 0; len(*c.events) < maxCarvedRecords; i++ {
+		next := bytes.Index(window[i:], []byte("**\x00\x00"))
+		if next < 0 {
+			break
+		}
+		i += next
+		if i >= limit {
+			break
+		}
+		if i+28 > len(window) {
+			continue
+		}
+		size := int(binary.LittleEndian.Uint32(window[i+4:]))
+		if size < 28 || size > carveOverlap-512 || i+size > len(window) {
+			continue
+		}
+		if int(binary.LittleEndian.Uint32(window[i+size-4:])) != size || window[i+24] != 0x0f || window[i+25] != 0x01 {
+			continue
+		}
+		recordID := binary.LittleEndian.Uint64(window[i+8:])
+		written, ok := filetime(binary.LittleEndian.Uint64(window[i+16:]))
+		if recordID == 0 || !ok {
+			continue
+		}
+		record := window[i : i+size]
+		if !c.firstSeen("evtx", record) {
+			continue
+		}
+		*c.events = append(*c.events, CarvedEventRecord{
+			Source:   c.source,
+			Offset:   base + int64(i),
+			RecordID: recordID,
+			Written:  written,
+			Strings:  utf16Strings(record[24:], 4, 16),
+		})
+	}
+}
+
+// syslogLine matches the start of a syslog line in the traditional or the
+// RFC 3339 timestamp format: timestamp, host and program
+var syslogLine = regexp.MustCompile(`^(?:(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [ 0-3]\d \d\d:\d\d:\d\d|\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?(?:Z|[+-]\d\d:\d\d)) \S+ [^\s:\[]+(?:\[\d+\])?: \S`)
+
+// maxLogLine is the longest carved log line
+const maxLogLine = 4096
+
+// carveLogLines finds syslog style lines that start after a newline or a
+// zeroed byte
+func (c *carver) carveLogLines(window []byte, base int64, limit int) {
+	for i := 0; i < limit && len(*c.lines) < maxCarvedRecords; i++ {
+		if i == 0 && base > 0 {
+			continue
+		}
+		if i > 0 && window[i-1] != '\n' && window[i-1] != 0 {
+			continue
+		}
+		if b := window[i]; !strings.ContainsRune("ADFJMNOS12", rune(b)) {
+			continue
+		}
+		end := i
+		for end < len(window) && end-i < maxLogLine && window[end] != '\n' && window[end] != 0 && (window[end] >= 0x20 || window[end] == '\t') {
+			end++
+		}
+		line := window[i:end]
+		if !syslogLine.Match(line) || !c.firstSeen("line", line) {
+			continue
+		}
+		*c.lines = append(*c.lines, CarvedLogLine{Source: c.source, Offset: base + int64(i), Line: string(line)})
+		i = end
+	}
+}
+
+// markPrefetchInImage marks carved prefetch files whose name is still in the
+// image's Prefetch directory
+func markPrefetchInImage(root string, carved []CarvedPrefetch) {
+	present := make(map[string]bool)
+	for _, dir := range imagePathFold(root, "Windows/Prefetch") {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			present[strings.ToUpper(entry.Name())] = true
+		}
+	}
+	for i, entry := range carved {
+		if entry.Executable != "" {
+			carved[i].InImage = present[strings.ToUpper(fmt.Sprintf("%s-%s.pf", entry.Executable, entry.Hash))]
+		}
+	}
+}
+
+// filetime converts a Windows FILETIME, accepting only times from 2000 to a
+// day from now so random bytes are not taken for one
+func filetime(ft uint64) (time.Time, bool) {
+	const epochDifference = 116444736000000000 // 1601-01-01 to 1970-01-01 in 100ns
+	if ft < epochDifference {
+		return time.Time{}, false
+	}
+	t := time.Unix(0, int64(ft-epochDifference)*100).UTC()
+	if t.Year() < 2000 || t.After(time.Now().Add(24*time.Hour)) {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// utf16String decodes a NUL terminated UTF-16LE string of printable ASCII,
+// reporting false for anything else
+func utf16String(data []byte) (string, bool) {
+	var units []uint16
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
+			break
+		}
+		if unit < 0x20 || unit > 0x7e {
+			return "", false
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// utf16Strings returns up to max distinct runs of at least min printable
+// ASCII UTF-16LE characters in data
+func utf16Strings(data []byte, min, max int) []string {
+	var strs []string
+	seen := make(map[string]bool)
+	for align := 0; align < 2; align++ {
+		var run []uint16
+		flush := func() {
+			if s := string(utf16.Decode(run)); len(run) >= min && !seen[s] && len(strs) < max {
+				seen[s] = true
+				strs = append(strs, s)
+			}
+			run = run[:0]
+		}
+		for i := align; i+1 < len(data); i += 2 {
+			if unit := binary.LittleEndian.Uint16(data[i:]); unit >= 0x20 && unit <= 0x7e {
+				run = append(run, unit)
+				continue
+			}
+			flush()
+		}
+		flush()
+	}
+	return strs
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// carveBlock is the block size the carver scans with; a prefetch file is
+// written across it to check records are not lost or carved twice there
+const carveBlock = 4 << 20
+
+// TestCarveImage writes an $MFT into the miniature image holding a
+// deleted prefetch file of a tool, one of a program the image still has,
+// a compressed prefetch header and an event record among filler bytes, and
+// checks the carver recovers each of them once
+func TestCarveImage(t *testing.T) {
+	root := testImage(t)
+
+	lastRun := time.Date(2024, 3, 9, 22, 14, 5, 0, time.UTC)
+	raw := make([]byte, carveBlock+64<<10)
+	for i := range raw {
+		raw[i] = byte(i*7 + i/251)
+	}
+	copy(raw[4096:], syntheticPrefetch("CMD.EXE", 0x4A81B364, 12, lastRun))
+	copy(raw[carveBlock-100:], syntheticPrefetch("MIMIKATZ.EXE", 0x1A2B3C4D, 3, lastRun))
+	copy(raw[16384:], "MAM\x04\x00\x40\x00\x00")
+	copy(raw[20000:], syntheticEventRecord(4625, lastRun, "Microsoft-Windows-Security-Auditing", "WS01", "administrator"))
+	if err := os.WriteFile(filepath.Join(root, "$MFT"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := CarveImage(context.Background(), CarveOptions{Root: root})
+	byType := make(map[string]ArtifactResult)
+	for _, result := range results {
+		if result.Error != nil {
+			t.Fatalf("%s: %v", result.Artifact.Name, result.Error)
+		}
+		if result.Metadata.Tags["recovered"] != "true" || result.Metadata.Tags["confidence"] != RecoveredConfidence {
+			t.Errorf("%s is not tagged recovered with low confidence: %v", result.Artifact.Name, result.Metadata.Tags)
+		}
+		byType[result.Artifact.Type] = result
+	}
+
+	var prefetch []CarvedPrefetch
+	decodeCarved(t, byType[CarvedPrefetchType], &prefetch)
+	if len(prefetch) != 3 {
+		t.Fatalf("carved %d prefetch files, want 3: %+v", len(prefetch), prefetch)
+	}
+	found := make(map[string]CarvedPrefetch)
+	for _, entry := range prefetch {
+		found[entry.Executable] = entry
+	}
+	mimikatz, cmd := found["MIMIKATZ.EXE"], found["CMD.EXE"]
+	if mimikatz.Offset != carveBlock-100 || mimikatz.RunCount != 3 || mimikatz.LastRun == nil || !mimikatz.LastRun.Equal(lastRun) {
+		t.Errorf("prefetch across a block boundary carved wrongly: %+v", mimikatz)
+	}
+	if mimikatz.InImage || !cmd.InImage {
+		t.Error("prefetch files still in the image not told apart from deleted ones")
+	}
+	if !found[""].Compressed {
+		t.Error("compressed prefetch header not carved")
+	}
+
+	var events []CarvedEventRecord
+	decodeCarved(t, byType[CarvedEventRecordsType], &events)
+	if len(events) != 1 || events[0].RecordID != 4625 || len(events[0].Strings) != 3 {
+		t.Errorf("event record carved wrongly: %+v", events)
+	}
+}
+
+func decodeCarved(t *testing.T, result ArtifactResult, v interface{}) {
+	t.Helper()
+	text, _ := result.Data.(string)
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		t.Fatalf("failed to decode %s: %v", result.Artifact.Name, err)
+	}
+}
+
+// syntheticPrefetch builds the header of an uncompressed Windows 10 prefetch
+// file
+func syntheticPrefetch(executable string, hash, runCount uint32, lastRun time.Time) []byte {
+	data := make([]byte, 0xD4)
+	binary.LittleEndian.PutUint32(data, 30)
+	copy(data[4:], "SCCA")
+	binary.LittleEndian.PutUint32(data[12:], 0x4000)
+	for i, unit := range utf16.Encode([]rune(executable)) {
+		binary.LittleEndian.PutUint16(data[16+2*i:], unit)
+	}
+	binary.LittleEndian.PutUint32(data[76:], hash)
+	binary.LittleEndian.PutUint64(data[0x80:], testFiletime(lastRun))
+	binary.LittleEndian.PutUint32(data[0xD0:], runCount)
+	return data
+}
+
+// syntheticEventRecord builds an EVTX event record whose binary XML body
+// holds strings
+func syntheticEventRecord(recordID uint64, written time.Time, strs ...string) []byte {
+	body := []byte{0x0f, 0x01, 0x01, 0x00}
+	for _, s := range strs {
+		for _, unit := range utf16.Encode([]rune(s)) {
+			body = binary.LittleEndian.AppendUint16(body, unit)
+		}
+		body = append(body, 0, 0, 0x05, 0x01)
+	}
+	size := 24 + len(body) + 4
+	data := make([]byte, 24, size)
+	copy(data, "**\x00\x00")
+	binary.LittleEndian.PutUint32(data[4:], uint32(size))
+	binary.LittleEndian.PutUint64(data[8:], recordID)
+	binary.LittleEndian.PutUint64(data[16:], testFiletime(written))
+	data = append(data, body...)
+	return binary.LittleEndian.AppendUint32(data, uint32(size))
+}
+
+func testFiletime(t time.Time) uint64 {
+	return uint64(t.UnixNano()/100) + 116444736000000000
+}
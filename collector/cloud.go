@@ -0,0 +1,510 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Artifact types for cloud identity. Join state, credential files and agents
+// are stored as JSON; klist output is kept as the tool printed it.
+const (
+	CloudJoinStateType   = "cloud_join_state_json"  // dsregcmd /status parsed into JoinState
+	CloudCredentialsType = "cloud_credentials_json" // CloudCredentialFile entries
+	CloudAgentsType      = "cloud_agents_json"      // CloudAgent entries
+	KerberosTicketsType  = "klist_text"             // klist of the current logon session
+)
+
+// CloudCategory is the artifact category of every cloud identity artifact
+const CloudCategory = "cloud"
+
+// Limits of the cloud identity collection
+const (
+	maxCredentialFiles   = 500     // credential files listed across all profiles
+	maxCredentialContent = 1 << 20 // bytes of a credential file kept with CredentialContent
+	agentLogTailLines    = 100     // lines kept from the end of an agent log
+	agentLogTailBytes    = 64 << 10
+)
+
+// CloudOptions controls the cloud identity collection
+type CloudOptions struct {
+	// CredentialContent also collects the content of cloud credential files.
+	// Off by default: only their existence and metadata are recorded.
+	CredentialContent bool
+	// Scope overrides the default scope of the credential directory walks
+	Scope WalkScope
+}
+
+// JoinState is the Azure AD (Entra ID) join state of a Windows device, from
+// dsregcmd /status. Fields holds every key the tool printed.
+type JoinState struct {
+	AzureADJoined       bool              `json:"azure_ad_joined"`
+	EnterpriseJoined    bool              `json:"enterprise_joined"`
+	DomainJoined        bool              `json:"domain_joined"`
+	WorkplaceJoined     bool              `json:"workplace_joined"`
+	DomainName          string            `json:"domain_name,omitempty"`
+	DeviceID            string            `json:"device_id,omitempty"`
+	TenantID            string            `json:"tenant_id,omitempty"`
+	TenantName          string            `json:"tenant_name,omitempty"`
+	WorkplaceTenantID   string            `json:"workplace_tenant_id,omitempty"`
+	WorkplaceTenantName string            `json:"workplace_tenant_name,omitempty"`
+	DeviceAuthStatus    string            `json:"device_auth_status,omitempty"`
+	KeySignTest         string            `json:"key_sign_test,omitempty"`
+	AzureADPrt          string            `json:"azure_ad_prt,omitempty"`
+	Fields              map[string]string `json:"fields"`
+}
+
+// CloudCredentialFile is a cached cloud credential or token file found in a
+// user profile. Content is only set when content collection was opted into.
+type CloudCredentialFile struct {
+	Provider         string    `json:"provider"`
+	Profile          string    `json:"profile"`
+	User             string    `json:"user"`
+	Path             string    `json:"path"`
+	Size             int64     `json:"size"`
+	Mode             string    `json:"mode"`
+	Modified         time.Time `json:"modified"`
+	SHA256           string    `json:"sha256,omitempty"`
+	Content          string    `json:"content_base64,omitempty"`
+	ContentTruncated bool      `json:"content_truncated,omitempty"`
+}
+
+// CloudAgent is an installed cloud provider agent and the end of its log
+type CloudAgent struct {
+	Name      string   `json:"name"`
+	Provider  string   `json:"provider"`
+	Service   string   `json:"service,omitempty"`
+	State     string   `json:"state"`
+	Installed bool     `json:"installed"`
+	LogPath   string   `json:"log_path,omitempty"`
+	LogTail   []string `json:"log_tail,omitempty"`
+}
+
+// cloudCredentialPaths are credential and token caches of the AWS, Azure and
+// Google Cloud CLIs, relative to a user profile. Directories are listed.
+var cloudCredentialPaths = []struct {
+	provider, path string
+}{
+	{"aws", ".aws/credentials"},
+	{"aws", ".aws/config"},
+	{"aws", ".aws/sso/cache"},
+	{"aws", ".aws/cli/cache"},
+	{"azure", ".azure/accessTokens.json"},
+	{"azure", ".azure/msal_token_cache.json"},
+	{"azure", ".azure/msal_token_cache.bin"},
+	{"azure", ".azure/service_principal_entries.json"},
+	{"azure", ".azure/azureProfile.json"},
+	{"gcloud", ".config/gcloud/credentials.db"},
+	{"gcloud", ".config/gcloud/access_tokens.db"},
+	{"gcloud", ".config/gcloud/application_default_credentials.json"},
+	{"gcloud", ".config/gcloud/legacy_credentials"},
+	{"gcloud", "AppData/Roaming/gcloud/credentials.db"},
+	{"gcloud", "AppData/Roaming/gcloud/access_tokens.db"},
+	{"gcloud", "AppData/Roaming/gcloud/application_default_credentials.json"},
+	{"gcloud", "AppData/Roaming/gcloud/legacy_credentials"},
+}
+
+// cloudAgents are the agents cloud providers install on their virtual
+// machines and on hybrid hosts they manage
+var cloudAgents = []struct {
+	name, provider              string
+	windowsServices, linuxUnits []string
+	windowsLogs, linuxLogs      []string
+}{
+	{"AWS Systems Manager Agent", "aws",
+		[]string{"AmazonSSMAgent"}, []string{"amazon-ssm-agent", "snap.amazon-ssm-agent.amazon-ssm-agent"},
+		[]string{`${ProgramData}\Amazon\SSM\Logs\amazon-ssm-agent.log`}, []string{"/var/log/amazon/ssm/amazon-ssm-agent.log"}},
+	{"Azure VM Agent", "azure",
+		[]string{"WindowsAzureGuestAgent", "RdAgent"}, []string{"walinuxagent", "waagent"},
+		[]string{`${SystemDrive}\WindowsAzure\Logs\WaAppAgent.log`}, []string{"/var/log/waagent.log"}},
+	{"Azure Connected Machine Agent", "azure",
+		[]string{"himds"}, []string{"himdsd"},
+		[]string{`${ProgramData}\AzureConnectedMachineAgent\Log\himds.log`}, []string{"/var/opt/azcmagent/log/himds.log"}},
+	{"Google Guest Agent", "gcp",
+		[]string{"GCEAgent", "GoogleGuestAgent"}, []string{"google-guest-agent"},
+		nil, nil},
+}
+
+// CollectCloudIdentity collects the device join state (Windows), cloud CLI
+// credential files in every user profile, installed cloud agents with the
+// end of their logs, and the Kerberos tickets of the current logon session.
+// Credential files are described by metadata only unless
+// opts.CredentialContent is set.
+func CollectCloudIdentity(ctx context.Context, opts CloudOptions) []ArtifactResult {
+	var results []ArtifactResult
+	if runtime.GOOS == "windows" {
+		results = append(results, collectJoinState(ctx))
+	}
+	results = append(results, collectCloudCredentials(UserProfiles(), opts), collectCloudAgents(ctx))
+	if tickets, ok := collectKerberosTickets(ctx); ok {
+		results = append(results, tickets)
+	}
+	return results
+}
+
+// collectJoinState runs dsregcmd /status and parses it into a JoinState
+func collectJoinState(ctx context.Context) ArtifactResult {
+	artifact := NewBaseArtifact("cloud_join_state", "Azure AD / Entra ID device join state (dsregcmd /status)", CloudCategory, CloudJoinStateType).Artifact
+
+	output, run, err := runCloudCommand(ctx, "dsregcmd", "/status")
+	if err != nil {
+		result := newCloudResult(artifact, "dsregcmd", nil, rterrors.Wrap(rterrors.ExternalTool, err))
+		result.Metadata.Commands = []CommandRun{run}
+		return result
+	}
+	result := newCloudResult(artifact, "dsregcmd", ParseJoinState(DecodeText([]byte(output)).Text), nil)
+	result.Metadata.Commands = []CommandRun{run}
+	return result
+}
+
+// ParseJoinState parses the "Key : Value" lines of dsregcmd /status
+func ParseJoinState(text string) JoinState {
+	state := JoinState{Fields: make(map[string]string)}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " : ")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" {
+			continue
+		}
+		state.Fields[key] = value
+	}
+
+	yes := func(key string) bool { return strings.EqualFold(state.Fields[key], "YES") }
+	state.AzureADJoined = yes("AzureAdJoined")
+	state.EnterpriseJoined = yes("EnterpriseJoined")
+	state.DomainJoined = yes("DomainJoined")
+	state.WorkplaceJoined = yes("WorkplaceJoined")
+	state.DomainName = state.Fields["DomainName"]
+	state.DeviceID = state.Fields["DeviceId"]
+	state.TenantID = state.Fields["TenantId"]
+	state.TenantName = state.Fields["TenantName"]
+	state.WorkplaceTenantID = state.Fields["WorkplaceTenantId"]
+	state.WorkplaceTenantName = state.Fields["WorkplaceTenantName"]
+	state.DeviceAuthStatus = state.Fields["DeviceAuthStatus"]
+	state.KeySignTest = state.Fields["KeySignTest"]
+	state.AzureADPrt = state.Fields["AzureAdPrt"]
+	return state
+}
+
+// UserProfiles returns the home directories of the host's users, including
+// the profiles of service accounts, mapped to the user name
+func UserProfiles() map[string]string {
+	profiles := make(map[string]string)
+	if runtime.GOOS == "windows" {
+		drive := os.Getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		users := filepath.Join(drive+`\`, "Users")
+		if entries, err := os.ReadDir(users); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					profiles[filepath.Join(users, entry.Name())] = entry.Name()
+				}
+			}
+		}
+		if root := os.Getenv("SystemRoot"); root != "" {
+			profiles[filepath.Join(root, "System32", "config", "systemprofile")] = "SYSTEM"
+			profiles[filepath.Join(root, "ServiceProfiles", "LocalService")] = "LocalService"
+			profiles[filepath.Join(root, "ServiceProfiles", "NetworkService")] = "NetworkService"
+		}
+		return profiles
+	}
+
+	// The home of every account, so service accounts are covered too
+	for _, line := range readLines("/etc/passwd") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 6 || fields[5] == "" || fields[5] == "/" {
+			continue
+		}
+		if _, seen := profiles[fields[5]]; !seen {
+			profiles[fields[5]] = fields[0]
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if entries, err := os.ReadDir("/Users"); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					profiles[filepath.Join("/Users", entry.Name())] = entry.Name()
+				}
+			}
+		}
+	}
+	return profiles
+}
+
+// collectCloudCredentials lists cloud CLI credential files in the given
+// profiles (home directory to user name)
+func collectCloudCredentials(profiles map[string]string, opts CloudOptions) ArtifactResult {
+	artifact := NewBaseArtifact("cloud_credentials", "Cloud CLI credential and token files (AWS, Azure, gcloud)", CloudCategory, CloudCredentialsType).Artifact
+	artifact.Parameters["content"] = "metadata"
+	if opts.CredentialContent {
+		artifact.Parameters["content"] = "included"
+	}
+
+	homes := make([]string, 0, len(profiles))
+	for home := range profiles {
+		homes = append(homes, home)
+	}
+	sort.Strings(homes)
+
+	scope := cloudCredentialScope.Override(opts.Scope)
+	files := []CloudCredentialFile{}
+	truncated := false
+	var counts walkCounts
+	for _, home := range homes {
+		for _, location := range cloudCredentialPaths {
+			root := filepath.Join(home, filepath.FromSlash(location.path))
+			skipped, _ := walkScoped(root, scope, func(path, rel string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return nil
+				}
+				if len(files) >= maxCredentialFiles {
+					truncated = true
+					return filepath.SkipAll
+				}
+				info, err := entry.Info()
+				if err != nil {
+					return nil
+				}
+				files = append(files, credentialFile(location.provider, home, profiles[home], path, info, opts))
+				return nil
+			})
+			counts.notEntered += skipped.notEntered
+			counts.hidden += skipped.hidden
+		}
+	}
+
+	result := newCloudResult(artifact, "filesystem", files, nil)
+	result.Metadata.Tags["files"] = fmt.Sprint(len(files))
+	result.Metadata.Tags["truncated"] = fmt.Sprint(truncated)
+	if truncated {
+		result.Reason = ReasonTruncated
+	}
+	scope.record(result.Artifact.Parameters, counts)
+	return result
+}
+
+// credentialFile describes one credential file, reading it only when its
+// content was opted into
+func credentialFile(provider, home, user, path string, info fs.FileInfo, opts CloudOptions) CloudCredentialFile {
+	file := CloudCredentialFile{
+		Provider: provider,
+		Profile:  home,
+		User:     user,
+		Path:     path,
+		Size:     info.Size(),
+		Mode:     info.Mode().String(),
+		Modified: info.ModTime().UTC(),
+	}
+	if !opts.CredentialContent {
+		return file
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return file
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxCredentialContent+1))
+	if err != nil {
+		return file
+	}
+	if len(data) > maxCredentialContent {
+		data = data[:maxCredentialContent]
+		file.ContentTruncated = true
+	}
+	hash := sha256.Sum256(data)
+	file.SHA256 = hex.EncodeToString(hash[:])
+	file.Content = base64.StdEncoding.EncodeToString(data)
+	return file
+}
+
+// collectCloudAgents reports the cloud agents whose service or log exists
+func collectCloudAgents(ctx context.Context) ArtifactResult {
+	artifact := NewBaseArtifact("cloud_agents", "Cloud provider agents (SSM, Azure VM and Arc agents, Google guest agent) and recent logs", CloudCategory, CloudAgentsType).Artifact
+
+	agents := []CloudAgent{}
+	for _, known := range cloudAgents {
+		services, logs := known.linuxUnits, known.linuxLogs
+		if runtime.GOOS == "windows" {
+			services, logs = known.windowsServices, known.windowsLogs
+		}
+
+		agent := CloudAgent{Name: known.name, Provider: known.provider, State: "not installed"}
+		for _, service := range services {
+			if state, installed := serviceState(ctx, service); installed {
+				agent.Service, agent.State, agent.Installed = service, state, true
+				break
+			}
+		}
+		for _, log := range logs {
+			path := os.ExpandEnv(log)
+			if tail, err := tailLines(path, agentLogTailLines); err == nil {
+				agent.LogPath, agent.LogTail = path, tail
+				break
+			}
+		}
+		if agent.Installed || agent.LogPath != "" {
+			agents = append(agents, agent)
+		}
+	}
+
+	return newCloudResult(artifact, "services", agents, nil)
+}
+
+// serviceState returns the state of a Windows service or systemd unit and
+// whether it is installed
+func serviceState(ctx context.Context, service string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		output, _, _ := runCloudCommand(ctx, "sc", "query", service)
+		for _, line := range strings.Split(output, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok || strings.TrimSpace(key) != "STATE" {
+				continue
+			}
+			if fields := strings.Fields(value); len(fields) > 0 {
+				return strings.ToLower(fields[len(fields)-1]), true
+			}
+			return "unknown", true
+		}
+		return "", false
+	}
+
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return "", false
+	}
+	output, _, _ := runCloudCommand(ctx, "systemctl", "show", "--property=LoadState,ActiveState", service)
+	properties := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			properties[key] = value
+		}
+	}
+	if properties["LoadState"] != "loaded" {
+		return "", false
+	}
+	return properties["ActiveState"], true
+}
+
+// tailLines returns the last lines of a text file, reading at most
+// agentLogTailBytes from its end
+func tailLines(path string, lines int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - agentLogTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ReplaceAll(DecodeText(data).Text, "\r\n", "\n")
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if offset > 0 && len(all) > 1 {
+		all = all[1:] // the first line was cut
+	}
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return all, nil
+}
+
+// collectKerberosTickets lists the Kerberos tickets of the current logon
+// session. It reports false where klist is not installed.
+func collectKerberosTickets(ctx context.Context) (ArtifactResult, bool) {
+	if _, err := exec.LookPath("klist"); err != nil && runtime.GOOS != "windows" {
+		return ArtifactResult{}, false
+	}
+	artifact := NewBaseArtifact("kerberos_tickets", "Kerberos tickets of the current logon session (klist)", CloudCategory, KerberosTicketsType).Artifact
+
+	result := ArtifactResult{
+		Artifact: artifact,
+		Metadata: Metadata{CollectedAt: time.Now(), Collector: "cloud", Source: "klist", Tags: map[string]string{}},
+	}
+	output, run, err := runCloudCommand(ctx, "klist")
+	result.Metadata.Commands = []CommandRun{run}
+	// MIT klist exits 1 when the cache is empty, which is a result too
+	if err != nil && output == "" {
+		result.Fail(ReasonFor(err), rterrors.Wrap(rterrors.ExternalTool, err))
+		return result, true
+	}
+	result.SetText([]byte(output))
+	return result, true
+}
+
+// runCloudCommand runs a read-only command and returns its output, also
+// when it exits with an error, and the run
+func runCloudCommand(ctx context.Context, name string, args ...string) (string, CommandRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, profileCommandTimeout)
+	defer cancel()
+	output, run, err := RunCommand(exec.CommandContext(ctx, name, args...))
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(output), run, fmt.Errorf("%s timed out: %w", name, ctx.Err())
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", run, fmt.Errorf("%s failed: %w", name, err)
+		}
+		if run.Stderr != "" {
+			return string(output), run, fmt.Errorf("%s failed: %s: %w", name, run.Stderr, err)
+		}
+		return string(output), run, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return string(output), run, nil
+}
+
+// newCloudResult stores v as the JSON data of a cloud artifact
+func newCloudResult(artifact Artifact, source string, v interface{}, err error) ArtifactResult {
+	result := ArtifactResult{
+		Artifact: artifact,
+		Metadata: Metadata{CollectedAt: time.Now(), Collector: "cloud", Source: source, Tags: map[string]string{}},
+		Error:    err,
+		Reason:   ReasonFor(err),
+	}
+	if err != nil {
+		return result
+	}
+
+	data, marshalErr := json.MarshalIndent(v, "", "  ")
+	if marshalErr != nil {
+		result.Error = fmt.Errorf("failed to marshal %s: %w", artifact.Name, marshalErr)
+		return result
+	}
+	result.SetText(data)
+	return result
+}
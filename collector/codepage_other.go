@@ -0,0 +1,9 @@
+//go:build !windows
+
+package collector
+
+// consoleCodePage returns DefaultCodePage: tools outside Windows write UTF-8,
+// so a code page only decodes Windows output read from an image or bundle
+func consoleCodePage() int {
+	return DefaultCodePage
+}
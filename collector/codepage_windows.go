@@ -0,0 +1,25 @@
+//go:build windows
+
+package collector
+
+import "golang.org/x/sys/windows"
+
+var (
+	getConsoleOutputCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetConsoleOutputCP")
+	getOEMCP           = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetOEMCP")
+)
+
+// consoleCodePage returns the code page console tools write their output
+// in: the console's output code page, or the OEM code page when the process
+// has no console
+func consoleCodePage() int {
+	for _, proc := range []*windows.LazyProc{getConsoleOutputCP, getOEMCP} {
+		if proc.Find() != nil {
+			continue
+		}
+		if cp, _, _ := proc.Call(); cp != 0 {
+			return int(cp)
+		}
+	}
+	return DefaultCodePage
+}
@@ -0,0 +1,14 @@
+package collector
+
+// Artifact types for container runtimes on Linux. Runtime output is kept as
+// the JSON the tool printed so it can be re-read without RedTriage.
+const (
+	ContainerListType      = "docker_ps_json"      // docker ps --all, one JSON object per line
+	ContainerInspectType   = "docker_inspect_json" // docker inspect of running containers
+	ContainerImagesType    = "docker_images_json"  // docker images, one JSON object per line
+	ContainerProcessesType = "container_processes" // host PIDs running inside containers, from /proc
+	PodListType            = "crictl_pods_json"    // crictl pods and containers
+)
+
+// ContainerCategory is the artifact category of every container artifact
+const ContainerCategory = "container"
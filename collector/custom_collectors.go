@@ -0,0 +1,380 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCustomCollectorsFile is the collector definition file collect loads
+// when it exists
+const DefaultCustomCollectorsFile = "collectors.yml"
+
+const (
+	// customForensicType marks enhanced artifacts defined in a collectors file
+	customForensicType = "custom"
+
+	// defaultCustomTimeout and maxCustomTimeout bound how long a custom
+	// collector command may run
+	defaultCustomTimeout = 30 * time.Second
+	maxCustomTimeout     = 10 * time.Minute
+
+	// maxCustomOutput is the most command output kept as artifact data
+	maxCustomOutput = 16 << 20
+)
+
+// customParsers turn command output into artifact data
+var customParsers = map[string]func([]byte) (interface{}, error){
+	"raw":   parseCustomRaw,
+	"lines": parseCustomLines,
+	"json":  parseCustomJSON,
+	"kv":    parseCustomKeyValues,
+	"csv":   parseCustomCSV,
+}
+
+// customNamePattern restricts collector names to what is safe in file names
+var customNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_]*$`)
+
+// CustomCollector is an artifact defined in a collectors file: a command
+// whose output, run through a parser, becomes the artifact data
+type CustomCollector struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description"`
+	Category    string      `yaml:"category"`
+	Platform    string      `yaml:"platform"`
+	Command     commandLine `yaml:"command"`
+	Parser      string      `yaml:"parser"`
+	Timeout     string      `yaml:"timeout"`
+}
+
+// commandLine is a command given either as a list of arguments or as one
+// string split on whitespace. It is never run through a shell.
+type commandLine []string
+
+// UnmarshalYAML accepts a command as a string or a list of arguments
+func (c *commandLine) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = strings.Fields(node.Value)
+		return nil
+	}
+	var args []string
+	if err := node.Decode(&args); err != nil {
+		return fmt.Errorf("command must be a string or a list of arguments: %w", err)
+	}
+	*c = args
+	return nil
+}
+
+// customCollectorsFile is the layout of a collectors file
+type customCollectorsFile struct {
+	Collectors []CustomCollector `yaml:"collectors"`
+}
+
+// LoadCustomCollectors reads and validates the collector definitions in
+// path. Commands are only looked up for collectors of this platform, so one
+// file can serve Windows and Linux hosts.
+func LoadCustomCollectors(path string) ([]CustomCollector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collectors file: %w", err)
+	}
+
+	var file customCollectorsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse collectors file %s: %w", path, err)
+	}
+
+	builtIn := NewEnhancedArtifactRegistry()
+	seen := make(map[string]bool)
+	var problems []string
+	for i := range file.Collectors {
+		def := &file.Collectors[i]
+		if def.Platform == "" {
+			def.Platform = "all"
+		}
+		if def.Parser == "" {
+			def.Parser = "raw"
+		}
+		if err := def.validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("collector %d (%s): %v", i+1, def.Name, err))
+			continue
+		}
+		if _, ok := builtIn.GetArtifact(def.Name); ok || seen[def.Name] {
+			problems = append(problems, fmt.Sprintf("collector %d (%s): name is already taken", i+1, def.Name))
+		}
+		seen[def.Name] = true
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid collectors file %s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+
+	return file.Collectors, nil
+}
+
+// validate checks a collector definition and that its command exists when it
+// targets this platform
+func (c CustomCollector) validate() error {
+	if !customNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("name must be lowercase letters, digits and underscores")
+	}
+	if c.Category == "" {
+		return fmt.Errorf("category is required")
+	}
+	switch c.Platform {
+	case "all", "windows", "linux", "darwin":
+	default:
+		return fmt.Errorf("unknown platform %q (expected windows, linux, darwin or all)", c.Platform)
+	}
+	if len(c.Command) == 0 {
+		return fmt.Errorf("command is required")
+	}
+	if _, ok := customParsers[c.Parser]; !ok {
+		return fmt.Errorf("unknown parser %q (expected raw, lines, json, kv or csv)", c.Parser)
+	}
+	if _, err := c.timeout(); err != nil {
+		return err
+	}
+	if c.runsHere() {
+		if _, err := exec.LookPath(c.Command[0]); err != nil {
+			return fmt.Errorf("command %s not found: %w", c.Command[0], err)
+		}
+	}
+	return nil
+}
+
+// timeout returns how long the command may run
+func (c CustomCollector) timeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return defaultCustomTimeout, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 || d > maxCustomTimeout {
+		return 0, fmt.Errorf("invalid timeout %q (expected a duration up to %s)", c.Timeout, maxCustomTimeout)
+	}
+	return d, nil
+}
+
+// runsHere reports whether the collector targets the running platform
+func (c CustomCollector) runsHere() bool {
+	return c.Platform == "all" || c.Platform == runtime.GOOS
+}
+
+// RegisterCustom adds collectors loaded from a collectors file to the
+// registry as command artifacts
+func (r *EnhancedArtifactRegistry) RegisterCustom(collectors []CustomCollector) {
+	for _, c := range collectors {
+		description := c.Description
+		if description == "" {
+			description = "Custom collector: " + strings.Join(c.Command, " ")
+		}
+		artifact := NewEnhancedArtifact(c.Name, description, c.Category, "command", customForensicType, 5)
+		artifact.Platform = c.Platform
+		artifact.Volatile = true
+		artifact.Timeout, _ = c.timeout()
+		artifact.Parameters["parser"] = c.Parser
+		artifact.Artifact.Parameters["parser"] = c.Parser
+		artifact.Command = append([]string(nil), c.Command...)
+		r.artifacts[c.Name] = artifact
+	}
+}
+
+// CustomArtifacts returns the registered custom collectors that target the
+// running platform, sorted by name
+func (r *EnhancedArtifactRegistry) CustomArtifacts() []EnhancedArtifact {
+	var artifacts []EnhancedArtifact
+	for _, artifact := range r.artifacts {
+		if artifact.ForensicType == customForensicType && (artifact.Platform == "all" || artifact.Platform == runtime.GOOS) {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Name < artifacts[j].Name
+	})
+	return artifacts
+}
+
+// errOutputLimit stops a custom command that writes more than its limit
+var errOutputLimit = errors.New("output limit exceeded")
+
+// limitedBuffer keeps up to limit bytes. A write past the limit fails and
+// calls stop, which kills the command.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	stop     func()
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		b.exceeded = true
+		if b.stop != nil {
+			b.stop()
+		}
+		return 0, errOutputLimit
+	}
+	return b.Buffer.Write(p)
+}
+
+// RunCustomArtifact runs a custom collector command and parses its output.
+// The command runs without a shell, with an empty stdin, a minimal
+// environment and the temp directory as working directory, and is killed
+// when its timeout passes or it writes more than 16MB.
+func RunCustomArtifact(ctx context.Context, artifact EnhancedArtifact) ArtifactResult {
+	result := ArtifactResult{
+		Artifact: artifact.Artifact,
+		Metadata: Metadata{
+			StartedAt: time.Now(),
+			Collector: "custom",
+			Source:    strings.Join(artifact.Command, " "),
+			Tags:      map[string]string{"parser": artifact.Parameters["parser"]},
+		},
+	}
+
+	timeout := artifact.Timeout
+	if timeout <= 0 {
+		timeout = defaultCustomTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxCustomOutput, stop: cancel}
+	stderr := &limitedBuffer{limit: 64 << 10, stop: cancel}
+	cmd := exec.CommandContext(runCtx, artifact.Command[0], artifact.Command[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Dir = os.TempDir()
+	cmd.Env = sandboxEnv()
+
+	err := cmd.Run()
+	result.Metadata.CollectedAt = time.Now()
+	result.Metadata.Duration = result.Metadata.CollectedAt.Sub(result.Metadata.StartedAt)
+	result.Metadata.Commands = []CommandRun{RecordCommand(cmd, result.Metadata.StartedAt, stdout.Len(), stderr.Bytes(), err)}
+
+	switch {
+	case stdout.exceeded || stderr.exceeded:
+		result.Fail(ReasonTruncated, fmt.Errorf("custom collector %s wrote more than %d bytes", artifact.Name, maxCustomOutput))
+		return result
+	case runCtx.Err() == context.DeadlineExceeded:
+		result.Fail(ReasonTimeout, fmt.Errorf("custom collector %s timed out after %s", artifact.Name, timeout))
+		return result
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		result.Fail(ReasonFor(err), fmt.Errorf("custom collector %s failed: %w", artifact.Name, err))
+		return result
+	}
+
+	// Parse the output as UTF-8 whatever code page the command wrote in
+	decoded := DecodeText(stdout.Bytes())
+	result.Metadata.Tags["encoding"] = decoded.Encoding
+	if decoded.Text != stdout.String() {
+		result.Raw = stdout.Bytes()
+	}
+	data, err := customParsers[artifact.Parameters["parser"]]([]byte(decoded.Text))
+	if err != nil {
+		result.Error = fmt.Errorf("custom collector %s: failed to parse output: %w", artifact.Name, err)
+		return result
+	}
+
+	hash := sha256.Sum256(stdout.Bytes())
+	result.Data = data
+	result.Size = int64(stdout.Len())
+	result.Checksum = hex.EncodeToString(hash[:])
+	return result
+}
+
+// sandboxEnv is the environment custom commands run with: only what is
+// needed to find programs and run them, and the WSL interop socket that
+// Windows programs started from WSL need
+func sandboxEnv() []string {
+	var env []string
+	for _, key := range []string{"PATH", "SystemRoot", "windir", "COMSPEC", "PATHEXT", "TEMP", "TMP", "WSL_INTEROP"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return append(env, "LANG=C", "LC_ALL=C")
+}
+
+func parseCustomRaw(output []byte) (interface{}, error) {
+	return string(output), nil
+}
+
+func parseCustomLines(output []byte) (interface{}, error) {
+	lines := []string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func parseCustomJSON(output []byte) (interface{}, error) {
+	var data interface{}
+	if err := json.Unmarshal(output, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseCustomKeyValues reads "key: value" or "key=value" lines; quotes
+// around a value are removed
+func parseCustomKeyValues(output []byte) (interface{}, error) {
+	values := make(map[string]interface{})
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		sep := strings.IndexAny(line, ":=")
+		if sep <= 0 {
+			continue
+		}
+		value := strings.TrimSpace(line[sep+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(line[:sep])] = value
+	}
+	return values, nil
+}
+
+// parseCustomCSV reads CSV with a header row into one record per row
+func parseCustomCSV(output []byte) (interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(output))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	records := []interface{}{}
+	if len(rows) == 0 {
+		return records, nil
+	}
+	header := rows[0]
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -12,6 +12,7 @@ type EnhancedArtifact struct {
 	Priority     int               // Collection priority (1=highest, 5=lowest)
 	Dependencies []string          // Other artifacts this depends on
 	Parameters  map[string]string // Collection parameters
+	Command      []string          // Command line of collectors defined in a collectors file
 }
 
 // NewEnhancedArtifact creates a new enhanced artifact
@@ -79,6 +80,15 @@ func (r *EnhancedArtifactRegistry) registerEnhancedArtifacts() {
 	registryHives.Parameters["backup"] = "true"
 	r.artifacts["registry_hives"] = registryHives
 	
+	r.artifacts["autostart_entries"] = NewEnhancedArtifact(
+		"autostart_entries",
+		"Registry autostart extension points with the hash and signature of each image",
+		"registry",
+		ASEPType,
+		"registry_analysis",
+		1,
+	)
+	
 	// File System Artifacts (Priority 2 - High)
 	fileMetadata := NewEnhancedArtifact(
 		"file_metadata",
@@ -152,6 +162,90 @@ func (r *EnhancedArtifactRegistry) registerEnhancedArtifacts() {
 	dnsCache.Volatile = true
 	r.artifacts["dns_cache"] = dnsCache
 	
+	r.artifacts["smb_shares"] = NewEnhancedArtifact(
+		"smb_shares",
+		"Shares exposed by the host with permissions and recent creation events",
+		"network",
+		SMBSharesType,
+		"network_analysis",
+		2,
+	)
+	
+	smbActivity := NewEnhancedArtifact(
+		"smb_activity",
+		"SMB sessions, open files and hidden share access",
+		"network",
+		SMBActivityType,
+		"network_analysis",
+		2,
+	)
+	smbActivity.Volatile = true
+	r.artifacts["smb_activity"] = smbActivity
+	
+	r.artifacts["mapped_drives"] = NewEnhancedArtifact(
+		"mapped_drives",
+		"Mapped network drives per user",
+		"network",
+		MappedDrivesType,
+		"network_analysis",
+		2,
+	)
+	
+	r.artifacts["unc_history"] = NewEnhancedArtifact(
+		"unc_history",
+		"UNC paths opened per user from MountPoints2 and recent shortcuts",
+		"network",
+		UNCHistoryType,
+		"network_analysis",
+		2,
+	)
+	
+	// Remote Access Artifacts (Priority 2 - High)
+	r.artifacts["rdp_history"] = NewEnhancedArtifact(
+		"rdp_history",
+		"Remote Desktop connections made by each user (Terminal Server Client keys, Default.rdp)",
+		"remote_access",
+		RDPHistoryType,
+		"user_activity",
+		2,
+	)
+	
+	r.artifacts["rdp_sessions"] = NewEnhancedArtifact(
+		"rdp_sessions",
+		"Inbound Remote Desktop logons and sessions within the lookback",
+		"remote_access",
+		RDPSessionsType,
+		"user_activity",
+		2,
+	)
+	
+	r.artifacts["rdp_cache"] = NewEnhancedArtifact(
+		"rdp_cache",
+		"Remote Desktop client bitmap cache files per user",
+		"remote_access",
+		RDPCacheType,
+		"user_activity",
+		2,
+	)
+	
+	r.artifacts["rdp_settings"] = NewEnhancedArtifact(
+		"rdp_settings",
+		"Remote Desktop and Network Level Authentication settings",
+		"remote_access",
+		RDPSettingsType,
+		"user_activity",
+		2,
+	)
+	
+	r.artifacts["remote_tools"] = NewEnhancedArtifact(
+		"remote_tools",
+		"Installed AnyDesk, TeamViewer and ScreenConnect with their log files",
+		"remote_access",
+		RemoteToolsType,
+		"user_activity",
+		2,
+	)
+	
 	// Execution Artifacts (Priority 2 - High)
 	r.artifacts["scheduled_tasks"] = NewEnhancedArtifact(
 		"scheduled_tasks",
@@ -196,7 +290,8 @@ func (r *EnhancedArtifactRegistry) registerEnhancedArtifacts() {
 		"log_analysis",
 		3,
 	)
-	r.artifacts["event_logs"].Parameters["logs"] = "Security,System,Application,Microsoft-Windows-Sysmon/Operational"
+	r.artifacts["event_logs"].Parameters["logs"] = "Security,System,Application,Microsoft-Windows-Sysmon/Operational," +
+		PowerShellOperationalChannel + "," + DefenderOperationalChannel
 	r.artifacts["event_logs"].Parameters["max_age"] = "7d"
 	r.artifacts["event_logs"].Parameters["include_evtx"] = "true"
 	
@@ -211,6 +306,30 @@ func (r *EnhancedArtifactRegistry) registerEnhancedArtifacts() {
 	r.artifacts["powershell_logs"].Parameters["include_transcript"] = "true"
 	r.artifacts["powershell_logs"].Parameters["include_modules"] = "true"
 	
+	r.artifacts["powershell_scriptblocks"] = NewEnhancedArtifact(
+		"powershell_scriptblocks",
+		"PowerShell script block logging events (4104) for script reassembly",
+		"logs",
+		EventXMLType,
+		"log_analysis",
+		3,
+	)
+	r.artifacts["powershell_scriptblocks"].Parameters["channel"] = PowerShellOperationalChannel
+	r.artifacts["powershell_scriptblocks"].Parameters["query"] = "*[System[(EventID=4104)]]"
+	r.artifacts["powershell_scriptblocks"].Parameters["max_events"] = "1000"
+	
+	r.artifacts["defender_logs"] = NewEnhancedArtifact(
+		"defender_logs",
+		"Windows Defender detection and action events (1116/1117)",
+		"logs",
+		EventXMLType,
+		"log_analysis",
+		3,
+	)
+	r.artifacts["defender_logs"].Parameters["channel"] = DefenderOperationalChannel
+	r.artifacts["defender_logs"].Parameters["query"] = "*[System[(EventID=1116 or EventID=1117)]]"
+	r.artifacts["defender_logs"].Parameters["max_events"] = "500"
+	
 	r.artifacts["sysmon_logs"] = NewEnhancedArtifact(
 		"sysmon_logs",
 		"Sysmon logs for advanced monitoring",
@@ -246,6 +365,60 @@ func (r *EnhancedArtifactRegistry) registerEnhancedArtifacts() {
 	r.artifacts["email_clients"].Parameters["clients"] = "outlook,thunderbird,mail_app"
 	r.artifacts["email_clients"].Parameters["include_attachments"] = "false"
 	
+	// Security Policy Artifacts (Priority 4 - Low)
+	r.artifacts["group_policy"] = NewEnhancedArtifact(
+		"group_policy",
+		"Resultant set of policy (gpresult /x)",
+		"policy",
+		GroupPolicyType,
+		"policy_analysis",
+		4,
+	)
+	r.artifacts["group_policy"].Parameters["scope"] = "computer,user"
+	
+	r.artifacts["security_policy"] = NewEnhancedArtifact(
+		"security_policy",
+		"Local security policy: password, lockout and user rights assignments (secedit /export)",
+		"policy",
+		SecurityPolicyType,
+		"policy_analysis",
+		4,
+	)
+	r.artifacts["security_policy"].Parameters["areas"] = "SECURITYPOLICY USER_RIGHTS"
+	
+	r.artifacts["audit_policy"] = NewEnhancedArtifact(
+		"audit_policy",
+		"Effective advanced audit policy (auditpol /get /category:*)",
+		"policy",
+		AuditPolicyType,
+		"policy_analysis",
+		4,
+	)
+	
+	r.artifacts["local_accounts"] = NewEnhancedArtifact(
+		"local_accounts",
+		"Local accounts with administrator membership and password expiry",
+		"policy",
+		LocalAccountsType,
+		"policy_analysis",
+		4,
+	)
+	
+	// Container Artifacts (Priority 2 - High)
+	for _, container := range []struct {
+		name, description, artifactType string
+	}{
+		{"docker_containers", "Docker containers, running and stopped (docker ps --all)", ContainerListType},
+		{"docker_inspect", "Configuration of running Docker containers (docker inspect)", ContainerInspectType},
+		{"docker_images", "Local Docker images (docker images)", ContainerImagesType},
+		{"container_processes", "Host processes running inside containers, with their namespaces", ContainerProcessesType},
+		{"kubernetes_pods", "Kubernetes pods and containers on this node (crictl)", PodListType},
+	} {
+		artifact := NewEnhancedArtifact(container.name, container.description, ContainerCategory, container.artifactType, "container_analysis", 2)
+		artifact.Platform = "linux"
+		r.artifacts[container.name] = artifact
+	}
+	
 	// Hardware and Device Artifacts (Priority 4 - Low)
 	r.artifacts["usb_devices"] = NewEnhancedArtifact(
 		"usb_devices",
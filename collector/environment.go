@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Kinds of environment RedTriage can run in
+const (
+	EnvironmentHost      = "host"
+	EnvironmentWSL       = "wsl"
+	EnvironmentContainer = "container"
+)
+
+// WSLWindowsRoot is where WSL mounts the Windows system drive
+const WSLWindowsRoot = "/mnt/c"
+
+// Environment describes where RedTriage runs. Inside WSL or a container
+// /proc lists the namespace's processes only and systemd may be absent, so
+// a live collection there does not show the whole machine.
+type Environment struct {
+	Kind string `json:"kind"`
+	// Runtime is the container runtime (docker, podman, kubernetes, lxc,
+	// containerd) or, for WSL, the WSL version ("wsl1", "wsl2")
+	Runtime string `json:"runtime,omitempty"`
+	// Distro is the WSL distribution name
+	Distro string `json:"distro,omitempty"`
+	// WindowsRoot is the Windows system drive reachable from WSL, and
+	// WindowsCollected is set once the Windows side has been collected
+	WindowsRoot      string `json:"windows_root,omitempty"`
+	WindowsCollected bool   `json:"windows_collected,omitempty"`
+	Systemd          bool   `json:"systemd"`
+	// Evidence lists what the detection was based on
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// EnvironmentProbe holds the inputs environment detection reads. Root is
+// the filesystem root ("/" on a live system); pointing it at a directory of
+// fake /proc and marker files, with a fake Getenv, exercises detection.
+type EnvironmentProbe struct {
+	GOOS   string
+	Root   string
+	Getenv func(string) string
+}
+
+// DetectEnvironment detects the environment of the running process
+func DetectEnvironment() Environment {
+	return EnvironmentProbe{GOOS: runtime.GOOS, Root: "/", Getenv: os.Getenv}.Detect()
+}
+
+// containerCgroupMarkers are cgroup path components written by container
+// runtimes, with the runtime each indicates
+var containerCgroupMarkers = []struct{ marker, runtime string }{
+	{"kubepods", "kubernetes"},
+	{"docker", "docker"},
+	{"libpod", "podman"},
+	{"containerd", "containerd"},
+	{"crio", "cri-o"},
+	{"lxc", "lxc"},
+}
+
+// Detect works out the environment from the probe's inputs: WSL from
+// /proc/version and the WSL_* variables, containers from /.dockerenv,
+// /run/.containerenv, the container and KUBERNETES_SERVICE_HOST variables
+// and the cgroup of PID 1, and systemd from /run/systemd/system
+func (p EnvironmentProbe) Detect() Environment {
+	env := Environment{Kind: EnvironmentHost}
+	if p.GOOS != "linux" {
+		return env
+	}
+	if p.Getenv == nil {
+		p.Getenv = func(string) string { return "" }
+	}
+
+	env.Systemd = p.exists("run/systemd/system")
+
+	procVersion := strings.ToLower(p.read("proc/version"))
+	wslInterop := p.Getenv("WSL_INTEROP")
+	wslDistro := p.Getenv("WSL_DISTRO_NAME")
+	if strings.Contains(procVersion, "microsoft") || wslInterop != "" || wslDistro != "" {
+		env.Kind = EnvironmentWSL
+		env.Distro = wslDistro
+		env.Runtime = "wsl1"
+		if strings.Contains(procVersion, "wsl2") || wslInterop != "" {
+			env.Runtime = "wsl2"
+		}
+		if strings.Contains(procVersion, "microsoft") {
+			env.Evidence = append(env.Evidence, "/proc/version names a Microsoft kernel")
+		}
+		if wslInterop != "" {
+			env.Evidence = append(env.Evidence, "WSL_INTEROP is set")
+		}
+		if wslDistro != "" {
+			env.Evidence = append(env.Evidence, "WSL_DISTRO_NAME is "+wslDistro)
+		}
+		if p.exists(strings.TrimPrefix(WSLWindowsRoot, "/")) {
+			env.WindowsRoot = WSLWindowsRoot
+		}
+	}
+
+	var runtimes []string
+	if p.exists(".dockerenv") {
+		runtimes = append(runtimes, "docker")
+		env.Evidence = append(env.Evidence, "/.dockerenv exists")
+	}
+	if p.exists("run/.containerenv") {
+		runtimes = append(runtimes, "podman")
+		env.Evidence = append(env.Evidence, "/run/.containerenv exists")
+	}
+	if value := p.Getenv("container"); value != "" {
+		runtimes = append(runtimes, value)
+		env.Evidence = append(env.Evidence, "container="+value+" is set")
+	}
+	if p.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		runtimes = append(runtimes, "kubernetes")
+		env.Evidence = append(env.Evidence, "KUBERNETES_SERVICE_HOST is set")
+	}
+	cgroup := p.read("proc/1/cgroup")
+	for _, m := range containerCgroupMarkers {
+		if strings.Contains(cgroup, m.marker) {
+			runtimes = append(runtimes, m.runtime)
+			env.Evidence = append(env.Evidence, "the cgroup of PID 1 is under "+m.marker)
+			break
+		}
+	}
+	// Docker Desktop's WSL integration sets none of these, so a WSL
+	// distribution is only a container when a marker says so
+	if len(runtimes) > 0 {
+		env.Kind = EnvironmentContainer
+		env.Runtime = runtimes[0]
+	}
+	return env
+}
+
+// exists reports whether a path below the probe root exists
+func (p EnvironmentProbe) exists(path string) bool {
+	_, err := os.Stat(filepath.Join(p.Root, path))
+	return err == nil
+}
+
+// read returns a file below the probe root, or "" when it cannot be read
+func (p EnvironmentProbe) read(path string) string {
+	data, err := os.ReadFile(filepath.Join(p.Root, path))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Isolated reports whether RedTriage runs inside WSL or a container
+func (e Environment) Isolated() bool {
+	return e.Kind == EnvironmentWSL || e.Kind == EnvironmentContainer
+}
+
+// String describes the environment in a few words
+func (e Environment) String() string {
+	switch e.Kind {
+	case EnvironmentWSL:
+		description := "WSL"
+		if e.Runtime == "wsl2" {
+			description = "WSL 2"
+		}
+		if e.Distro != "" {
+			description += " (" + e.Distro + ")"
+		}
+		return description
+	case EnvironmentContainer:
+		return fmt.Sprintf("%s container", e.Runtime)
+	default:
+		return "host"
+	}
+}
+
+// Warnings explains how the environment limits a live collection
+func (e Environment) Warnings() []string {
+	var warnings []string
+	switch e.Kind {
+	case EnvironmentContainer:
+		warnings = append(warnings, fmt.Sprintf("running inside a %s: processes, network connections, users and logs are the container's own, so this collection does not represent the host", e))
+	case EnvironmentWSL:
+		warning := fmt.Sprintf("running inside %s: /proc lists the Linux distribution's processes only and Windows artifacts are not collected", e)
+		switch {
+		case e.WindowsCollected:
+			warning = fmt.Sprintf("running inside %s: /proc lists the Linux distribution's processes only; the Windows side was collected from %s and through interop as %s* artifacts", e, e.WindowsRoot, WSLWindowsPrefix)
+		case e.WindowsRoot != "":
+			warning += "; pass --wsl-windows-host to also collect the Windows side from " + e.WindowsRoot
+		}
+		warnings = append(warnings, warning)
+	}
+	if e.Isolated() && !e.Systemd {
+		warnings = append(warnings, "systemd is not running; systemd services and journal artifacts are skipped")
+	}
+	return warnings
+}
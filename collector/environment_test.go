@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// environmentCases are fake roots and environment variables with the
+// environment each must be detected as
+var environmentCases = []struct {
+	name    string
+	files   map[string]string
+	env     map[string]string
+	kind    string
+	runtime string
+	systemd bool
+}{
+	{
+		name:    "bare-metal",
+		files:   map[string]string{"proc/version": "Linux version 6.8.0-45-generic", "proc/1/cgroup": "0::/init.scope\n", "run/systemd/system/.keep": ""},
+		kind:    EnvironmentHost,
+		systemd: true,
+	},
+	{
+		name:    "wsl2",
+		files:   map[string]string{"proc/version": "Linux version 5.15.153.1-microsoft-standard-WSL2", "mnt/c/Windows/.keep": ""},
+		env:     map[string]string{"WSL_INTEROP": "/run/WSL/1_interop", "WSL_DISTRO_NAME": "Ubuntu"},
+		kind:    EnvironmentWSL,
+		runtime: "wsl2",
+	},
+	{
+		name:    "wsl1",
+		files:   map[string]string{"proc/version": "Linux version 4.4.0-19041-Microsoft"},
+		kind:    EnvironmentWSL,
+		runtime: "wsl1",
+	},
+	{
+		name:    "docker",
+		files:   map[string]string{".dockerenv": "", "proc/version": "Linux version 6.8.0-45-generic"},
+		kind:    EnvironmentContainer,
+		runtime: "docker",
+	},
+	{
+		name:    "kubernetes",
+		files:   map[string]string{"proc/1/cgroup": "0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-1a2b.scope\n"},
+		env:     map[string]string{"KUBERNETES_SERVICE_HOST": "10.96.0.1"},
+		kind:    EnvironmentContainer,
+		runtime: "kubernetes",
+	},
+	{
+		name:    "podman-systemd",
+		files:   map[string]string{"run/.containerenv": "", "run/systemd/system/.keep": ""},
+		env:     map[string]string{"container": "podman"},
+		kind:    EnvironmentContainer,
+		runtime: "podman",
+		systemd: true,
+	},
+}
+
+func TestEnvironmentProbeDetect(t *testing.T) {
+	for _, c := range environmentCases {
+		t.Run(c.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range c.files {
+				path := filepath.Join(root, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			probe := EnvironmentProbe{GOOS: "linux", Root: root, Getenv: func(key string) string { return c.env[key] }}
+			env := probe.Detect()
+			if env.Kind != c.kind || env.Runtime != c.runtime || env.Systemd != c.systemd {
+				t.Fatalf("detected as %s/%s systemd=%v, want %s/%s systemd=%v",
+					env.Kind, env.Runtime, env.Systemd, c.kind, c.runtime, c.systemd)
+			}
+			if env.Isolated() != (len(env.Warnings()) > 0) {
+				t.Errorf("warnings %v do not match the environment", env.Warnings())
+			}
+			if c.kind == EnvironmentContainer && !strings.Contains(env.Warnings()[0], "does not represent the host") {
+				t.Errorf("container warning missing: %v", env.Warnings())
+			}
+		})
+	}
+}
+
+func TestCollectWSLWindowsHost(t *testing.T) {
+	// The miniature image stands in for /mnt/c
+	wsl := Environment{Kind: EnvironmentWSL, Runtime: "wsl2", WindowsRoot: testImage(t)}
+	results := CollectWSLWindowsHost(context.Background(), wsl, true, WalkScope{})
+
+	byName := make(map[string]ArtifactResult)
+	for _, result := range results {
+		if !strings.HasPrefix(result.Artifact.Name, WSLWindowsPrefix) {
+			t.Errorf("Windows-side artifact %s lacks the %s prefix", result.Artifact.Name, WSLWindowsPrefix)
+		}
+		byName[strings.TrimPrefix(result.Artifact.Name, WSLWindowsPrefix)] = result
+	}
+	for _, name := range testImageFiles {
+		if result, ok := byName[name]; !ok || result.Error != nil {
+			t.Errorf("Windows-side artifact %s not collected from the fake system drive", name)
+		}
+	}
+	if result, ok := byName["running_processes"]; !ok || result.Metadata.Collector != "wsl_interop" {
+		t.Error("Windows processes not collected through interop")
+	}
+}
@@ -0,0 +1,11 @@
+package collector
+
+// Event log channels collected as raw event XML for parsing by the detector
+const (
+	PowerShellOperationalChannel = "Microsoft-Windows-PowerShell/Operational"
+	DefenderOperationalChannel   = "Microsoft-Windows-Windows Defender/Operational"
+)
+
+// EventXMLType is the artifact type for event log entries exported as XML
+// (wevtutil /f:xml). The event channel is stored in the "channel" parameter.
+const EventXMLType = "event_xml"
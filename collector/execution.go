@@ -0,0 +1,479 @@
+package collector
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// ExecutionHistoryType is the artifact type of the Amcache and ShimCache
+// artifacts: ExecutionEntry records as JSON
+const ExecutionHistoryType = "execution_history_json"
+
+// Sources of execution evidence
+const (
+	SourceAmcache   = "amcache"
+	SourceShimCache = "shimcache"
+)
+
+// ExecutionEntry is a program Windows recorded as present or run. The
+// binary may have been deleted since.
+type ExecutionEntry struct {
+	Source string `json:"source"`
+	Path   string `json:"path"`
+	// SHA1 of the file's first 31 MB, from Amcache
+	SHA1 string `json:"sha1,omitempty"`
+	// FirstSeen is when Amcache first recorded the file: the last write
+	// of its key
+	FirstSeen time.Time `json:"first_seen"`
+	// LastModified is the file's modification time as recorded in the cache
+	LastModified time.Time `json:"last_modified"`
+	// Position is the ShimCache order, 0 being the most recently added
+	Position int `json:"position,omitempty"`
+	// Executed is the ShimCache insert flag of Windows 7 and 8, which only
+	// they record
+	Executed  *bool  `json:"executed,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	Product   string `json:"product,omitempty"`
+	Version   string `json:"version,omitempty"`
+	LinkDate  string `json:"link_date,omitempty"`
+}
+
+// Time returns the entry's best timestamp and what it means
+func (e ExecutionEntry) Time() (time.Time, string) {
+	if !e.FirstSeen.IsZero() {
+		return e.FirstSeen, "First Seen"
+	}
+	return e.LastModified, "File Modified"
+}
+
+// ShimCache formats, by the Windows versions that write them
+const (
+	ShimCacheWinXP    = "windows_xp"
+	ShimCacheWin2003  = "windows_2003_vista"
+	ShimCacheWin7     = "windows_7"
+	ShimCacheWin8     = "windows_8"
+	ShimCacheWin81    = "windows_8.1"
+	ShimCacheWin10    = "windows_10"
+	shimCacheMaxItems = 4096
+)
+
+// ParseAppCompatCache parses the AppCompatCache value of the SYSTEM hive,
+// detecting its format from the header, and returns the entries in cache
+// order with the format name
+func ParseAppCompatCache(data []byte) ([]ExecutionEntry, string, error) {
+	if len(data) < 8 {
+		return nil, "", fmt.Errorf("AppCompatCache value too short (%d bytes)", len(data))
+	}
+	magic := binary.LittleEndian.Uint32(data)
+	switch {
+	case magic == 0xdeadbeef:
+		entries, err := parseShimCacheXP(data)
+		return entries, ShimCacheWinXP, err
+	case magic == 0xbadc0ffe:
+		entries, err := parseShimCacheVista(data)
+		return entries, ShimCacheWin2003, err
+	case magic == 0xbadc0fee:
+		entries, err := parseShimCacheWin7(data)
+		return entries, ShimCacheWin7, err
+	case magic == 0x80 && len(data) >= 0x84 && string(data[0x80:0x84]) == "00ts":
+		entries, err := parseShimCacheWin8(data, 0x80, true)
+		return entries, ShimCacheWin8, err
+	case magic == 0x80 && len(data) >= 0x84 && string(data[0x80:0x84]) == "10ts":
+		entries, err := parseShimCacheWin8(data, 0x80, true)
+		return entries, ShimCacheWin81, err
+	case (magic == 0x30 || magic == 0x34) && len(data) >= int(magic)+4 && string(data[magic:magic+4]) == "10ts":
+		entries, err := parseShimCacheWin8(data, int(magic), false)
+		return entries, ShimCacheWin10, err
+	}
+	return nil, "", fmt.Errorf("unknown AppCompatCache format (header %#x)", magic)
+}
+
+// parseShimCacheXP reads the fixed-size entries of Windows XP
+func parseShimCacheXP(data []byte) ([]ExecutionEntry, error) {
+	const (
+		headerSize = 400
+		entrySize  = 552
+	)
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	var entries []ExecutionEntry
+	for i := 0; i < count && i < shimCacheMaxItems; i++ {
+		start := headerSize + i*entrySize
+		if start+entrySize > len(data) {
+			return entries, fmt.Errorf("AppCompatCache truncated at entry %d of %d", i, count)
+		}
+		entry := ExecutionEntry{Source: SourceShimCache, Path: decodeUTF16Z(data[start : start+520]), Position: i}
+		entry.LastModified, _ = filetime(binary.LittleEndian.Uint64(data[start+528:]))
+		entry.Size = int64(binary.LittleEndian.Uint64(data[start+536:]))
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseShimCacheVista reads the entries of Server 2003, Vista and Server
+// 2008, in their 32-bit or 64-bit layout
+func parseShimCacheVista(data []byte) ([]ExecutionEntry, error) {
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	wide := len(data) >= 16 && binary.LittleEndian.Uint32(data[12:]) == 0
+	entrySize, pathAt, timeAt := 0x18, 4, 8
+	if wide {
+		entrySize, pathAt, timeAt = 0x20, 8, 16
+	}
+	var entries []ExecutionEntry
+	for i := 0; i < count && i < shimCacheMaxItems; i++ {
+		start := 8 + i*entrySize
+		if start+entrySize > len(data) {
+			return entries, fmt.Errorf("AppCompatCache truncated at entry %d of %d", i, count)
+		}
+		entry, err := shimCachePathEntry(data, start, pathAt, i)
+		if err != nil {
+			return entries, err
+		}
+		entry.LastModified, _ = filetime(binary.LittleEndian.Uint64(data[start+timeAt:]))
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseShimCacheWin7 reads the entries of Windows 7 and Server 2008 R2, in
+// their 32-bit or 64-bit layout
+func parseShimCacheWin7(data []byte) ([]ExecutionEntry, error) {
+	const headerSize = 128
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	wide := len(data) >= headerSize+8 && binary.LittleEndian.Uint32(data[headerSize+4:]) == 0
+	entrySize, pathAt, timeAt, flagsAt := 0x20, 4, 8, 16
+	if wide {
+		entrySize, pathAt, timeAt, flagsAt = 0x30, 8, 16, 24
+	}
+	var entries []ExecutionEntry
+	for i := 0; i < count && i < shimCacheMaxItems; i++ {
+		start := headerSize + i*entrySize
+		if start+entrySize > len(data) {
+			return entries, fmt.Errorf("AppCompatCache truncated at entry %d of %d", i, count)
+		}
+		entry, err := shimCachePathEntry(data, start, pathAt, i)
+		if err != nil {
+			return entries, err
+		}
+		entry.LastModified, _ = filetime(binary.LittleEndian.Uint64(data[start+timeAt:]))
+		executed := binary.LittleEndian.Uint32(data[start+flagsAt:])&0x2 != 0
+		entry.Executed = &executed
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// shimCachePathEntry reads the path of a Vista or Windows 7 entry, stored
+// elsewhere in the value and referenced by length and offset
+func shimCachePathEntry(data []byte, start, pathAt, position int) (ExecutionEntry, error) {
+	length := int(binary.LittleEndian.Uint16(data[start:]))
+	var offset int
+	if pathAt == 8 {
+		offset = int(binary.LittleEndian.Uint64(data[start+pathAt:]))
+	} else {
+		offset = int(binary.LittleEndian.Uint32(data[start+pathAt:]))
+	}
+	if offset < 0 || offset+length > len(data) {
+		return ExecutionEntry{}, fmt.Errorf("AppCompatCache entry %d has its path outside the value", position)
+	}
+	return ExecutionEntry{
+		Source:   SourceShimCache,
+		Path:     decodeUTF16Z(data[offset : offset+length]),
+		Position: position,
+	}, nil
+}
+
+// parseShimCacheWin8 reads the signed entries of Windows 8, 8.1, 10 and 11.
+// Windows 8 and 8.1 entries also carry a package name and insert flags.
+func parseShimCacheWin8(data []byte, start int, flags bool) ([]ExecutionEntry, error) {
+	var entries []ExecutionEntry
+	for pos := start; pos+12 <= len(data) && len(entries) < shimCacheMaxItems; {
+		signature := string(data[pos : pos+4])
+		if signature != "00ts" && signature != "10ts" {
+			return entries, fmt.Errorf("AppCompatCache entry %d has signature %q", len(entries), signature)
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+8:]))
+		body := pos + 12
+		end := body + size
+		if end > len(data) || size < 2 {
+			return entries, fmt.Errorf("AppCompatCache truncated at entry %d", len(entries))
+		}
+		record := data[body:end]
+
+		pathLength := int(binary.LittleEndian.Uint16(record))
+		at := 2 + pathLength
+		if at > len(record) {
+			return entries, fmt.Errorf("AppCompatCache entry %d has a truncated path", len(entries))
+		}
+		entry := ExecutionEntry{Source: SourceShimCache, Path: decodeUTF16Z(record[2:at]), Position: len(entries)}
+		if flags {
+			if at+2 > len(record) {
+				return entries, fmt.Errorf("AppCompatCache entry %d is truncated", len(entries))
+			}
+			at += 2 + int(binary.LittleEndian.Uint16(record[at:]))
+			if at+8 > len(record) {
+				return entries, fmt.Errorf("AppCompatCache entry %d is truncated", len(entries))
+			}
+			executed := binary.LittleEndian.Uint32(record[at:])&0x2 != 0
+			entry.Executed = &executed
+			at += 8
+		}
+		if at+8 <= len(record) {
+			entry.LastModified, _ = filetime(binary.LittleEndian.Uint64(record[at:]))
+		}
+		entries = append(entries, entry)
+		pos = end
+	}
+	return entries, nil
+}
+
+// ReadShimCache reads the AppCompatCache value of the current control set
+// of a SYSTEM hive
+func ReadShimCache(system *Hive) ([]ExecutionEntry, string, error) {
+	root, err := system.Root()
+	if err != nil {
+		return nil, "", err
+	}
+	controlSet := bootControlSet(root)
+	for _, path := range []string{
+		`Control\Session Manager\AppCompatCache`,
+		`Control\Session Manager\AppCompatibility`, // Windows XP
+	} {
+		key, err := root.Path(controlSet + `\` + path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", controlSet, err)
+		}
+		if key == nil {
+			continue
+		}
+		if value, ok := key.Value("AppCompatCache"); ok {
+			return ParseAppCompatCache(value.Data)
+		}
+	}
+	return nil, "", rterrors.NotFoundf("no AppCompatCache value in %s", controlSet)
+}
+
+// bootControlSet returns the control set a SYSTEM hive boots from, as
+// its Select key records
+func bootControlSet(root *HiveKey) string {
+	if selectKey, _ := root.Subkey("Select"); selectKey != nil {
+		if current, ok := selectKey.Value("Current"); ok {
+			if n, ok := current.Uint(); ok {
+				return fmt.Sprintf("ControlSet%03d", n)
+			}
+		}
+	}
+	return "ControlSet001"
+}
+
+// ReadAmcache reads the files recorded in an Amcache.hve hive: the
+// InventoryApplicationFile keys of Windows 10 and 11, and the File keys of
+// Windows 8 and of Windows 7 with the compatibility updates
+func ReadAmcache(amcache *Hive) ([]ExecutionEntry, error) {
+	root, err := amcache.Root()
+	if err != nil {
+		return nil, err
+	}
+	var entries []ExecutionEntry
+
+	inventory, err := root.Path(`Root\InventoryApplicationFile`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read InventoryApplicationFile: %w", err)
+	}
+	if inventory != nil {
+		keys, err := inventory.Subkeys()
+		for _, key := range keys {
+			entry := ExecutionEntry{Source: SourceAmcache, FirstSeen: key.LastWrite}
+			for _, value := range mustValues(key) {
+				switch strings.ToLower(value.Name) {
+				case "lowercaselongpath":
+					entry.Path = value.String()
+				case "fileid":
+					entry.SHA1 = amcacheSHA1(value.String())
+				case "size":
+					entry.Size = amcacheSize(value)
+				case "publisher":
+					entry.Publisher = value.String()
+				case "productname":
+					entry.Product = value.String()
+				case "version":
+					entry.Version = value.String()
+				case "linkdate":
+					entry.LinkDate = value.String()
+				}
+			}
+			if entry.Path != "" {
+				entries = append(entries, entry)
+			}
+		}
+		if err != nil {
+			return entries, fmt.Errorf("failed to read InventoryApplicationFile: %w", err)
+		}
+	}
+
+	files, err := root.Path(`Root\File`)
+	if err != nil {
+		return entries, fmt.Errorf("failed to read File: %w", err)
+	}
+	if files != nil {
+		volumes, err := files.Subkeys()
+		for _, volume := range volumes {
+			keys, _ := volume.Subkeys()
+			for _, key := range keys {
+				entry := ExecutionEntry{Source: SourceAmcache, FirstSeen: key.LastWrite}
+				for _, value := range mustValues(key) {
+					switch value.Name {
+					case "15":
+						entry.Path = value.String()
+					case "101":
+						entry.SHA1 = amcacheSHA1(value.String())
+					case "6":
+						entry.Size = amcacheSize(value)
+					case "1":
+						entry.Publisher = value.String()
+					case "0":
+						entry.Product = value.String()
+					case "17":
+						if ft, ok := value.Uint(); ok {
+							entry.LastModified, _ = filetime(ft)
+						}
+					}
+				}
+				if entry.Path != "" {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		if err != nil {
+			return entries, fmt.Errorf("failed to read File: %w", err)
+		}
+	}
+
+	if inventory == nil && files == nil {
+		return nil, rterrors.NotFoundf("no InventoryApplicationFile or File key in the hive")
+	}
+	return entries, nil
+}
+
+// mustValues returns the values of a key that could be read
+func mustValues(key *HiveKey) []HiveValue {
+	values, _ := key.Values()
+	return values
+}
+
+// amcacheSHA1 strips the four zeros Amcache puts before the SHA-1
+func amcacheSHA1(fileID string) string {
+	fileID = strings.ToLower(strings.TrimSpace(fileID))
+	if len(fileID) == 44 && strings.HasPrefix(fileID, "0000") {
+		return fileID[4:]
+	}
+	if len(fileID) == 40 {
+		return fileID
+	}
+	return ""
+}
+
+// amcacheSize reads a file size stored as a number or as hexadecimal text
+func amcacheSize(value HiveValue) int64 {
+	if n, ok := value.Uint(); ok {
+		return int64(n)
+	}
+	text := strings.TrimPrefix(strings.ToLower(value.String()), "0x")
+	n, _ := strconv.ParseInt(text, 16, 64)
+	return n
+}
+
+// ExecutionHistoryArtifact wraps execution entries as an artifact
+func ExecutionHistoryArtifact(name, description, platform string, entries []ExecutionEntry) ArtifactResult {
+	artifact := NewBaseArtifact(name, description, "execution", ExecutionHistoryType).Artifact
+	artifact.Platform = platform
+	if entries == nil {
+		entries = []ExecutionEntry{}
+	}
+	data, _ := json.Marshal(entries)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     entries,
+		Size:     int64(len(data)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "execution",
+			Version:     "1.0.0",
+			Tags:        map[string]string{"entries": strconv.Itoa(len(entries))},
+		},
+	}
+}
+
+// ExecutionEntries returns the entries of an execution history artifact,
+// decoding them when the artifact was read back from a bundle
+func ExecutionEntries(result ArtifactResult) []ExecutionEntry {
+	switch data := result.Data.(type) {
+	case []ExecutionEntry:
+		return data
+	case nil:
+		return nil
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil
+		}
+		if text, ok := data.(string); ok {
+			raw = []byte(text)
+		}
+		var entries []ExecutionEntry
+		if json.Unmarshal(raw, &entries) != nil {
+			return nil
+		}
+		return entries
+	}
+}
+
+// CollectExecutionHistory reads the ShimCache from a SYSTEM hive and the
+// Amcache from an Amcache.hve, either of which may be missing
+func CollectExecutionHistory(systemHive, amcacheHive, platform string) []ArtifactResult {
+	shimcache := ExecutionHistoryArtifact("shimcache", "ShimCache (AppCompatCache) entries from the SYSTEM hive", platform, nil)
+	shimcache.Artifact.Parameters["path"] = systemHive
+	if hive, err := OpenHive(systemHive); err != nil {
+		shimcache.Fail(ReasonFor(err), fmt.Errorf("failed to read SYSTEM hive: %w", err))
+	} else {
+		entries, format, err := ReadShimCache(hive)
+		shimcache = ExecutionHistoryArtifact(shimcache.Artifact.Name, shimcache.Artifact.Description, platform, entries)
+		shimcache.Artifact.Parameters["path"] = systemHive
+		shimcache.Metadata.Tags["format"] = format
+		if err != nil && len(entries) == 0 {
+			shimcache.Fail(ReasonFor(err), err)
+		} else if err != nil {
+			shimcache.Metadata.Tags["parse_errors"] = err.Error()
+		}
+	}
+
+	return []ArtifactResult{shimcache, ReadAmcacheArtifact(amcacheHive, amcacheHive, platform)}
+}
+
+// ReadAmcacheArtifact reads an Amcache hive into the amcache artifact.
+// source is the path the hive was copied from, when it was read from a copy.
+func ReadAmcacheArtifact(path, source, platform string) ArtifactResult {
+	amcache := ExecutionHistoryArtifact("amcache", "Amcache.hve program inventory", platform, nil)
+	hive, err := OpenHive(path)
+	var entries []ExecutionEntry
+	if err == nil {
+		entries, err = ReadAmcache(hive)
+		amcache = ExecutionHistoryArtifact(amcache.Artifact.Name, amcache.Artifact.Description, platform, entries)
+	}
+	amcache.Artifact.Parameters["path"] = source
+	switch {
+	case err != nil && len(entries) == 0:
+		amcache.Fail(ReasonFor(err), fmt.Errorf("failed to read Amcache: %w", err))
+	case err != nil:
+		amcache.Metadata.Tags["parse_errors"] = err.Error()
+	}
+	return amcache
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package collector
+
+import "context"
+
+// CollectLiveExecutionHistory collects nothing: only Windows keeps an
+// Amcache and a ShimCache
+func CollectLiveExecutionHistory(ctx context.Context, readOnly bool) []ArtifactResult {
+	return nil
+}
@@ -0,0 +1,258 @@
+package collector
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	testShimCacheModified = time.Date(2024, 11, 2, 21, 14, 7, 0, time.UTC)
+	testShimCachePaths    = []string{`C:\Users\Public\mimikatz.exe`, `C:\Windows\System32\svchost.exe`}
+)
+
+func TestParseAppCompatCache(t *testing.T) {
+	formats := map[string][]byte{
+		ShimCacheWin7:  syntheticShimCacheWin7(testShimCachePaths, testShimCacheModified),
+		ShimCacheWin81: syntheticShimCacheWin8(0x80, testShimCachePaths, testShimCacheModified),
+		ShimCacheWin10: syntheticShimCacheWin8(0x34, testShimCachePaths, testShimCacheModified),
+	}
+	for want, value := range formats {
+		entries, format, err := ParseAppCompatCache(value)
+		if err != nil {
+			t.Errorf("%s ShimCache: %v", want, err)
+			continue
+		}
+		if format != want {
+			t.Errorf("%s ShimCache detected as %q", want, format)
+		}
+		checkShimCacheEntries(t, want, entries, want != ShimCacheWin10)
+	}
+}
+
+func TestCollectExecutionHistory(t *testing.T) {
+	modified := testShimCacheModified
+	dir := t.TempDir()
+
+	system := &hiveBuilder{}
+	appCompatCache := system.key("AppCompatCache", modified, nil,
+		system.value("AppCompatCache", RegBinary, syntheticShimCacheWin8(0x34, testShimCachePaths, modified)))
+	sessionManager := system.key("Session Manager", modified, system.keys(appCompatCache))
+	controlSet := system.key("ControlSet001", modified, system.keys(system.key("Control", modified, system.keys(sessionManager))))
+	selectKey := system.key("Select", modified, nil, system.value("Current", RegDWORD, []byte{1, 0, 0, 0}))
+	system.write(system.key("ROOT", modified, system.keys(selectKey, controlSet)))
+
+	firstSeen := time.Date(2024, 11, 2, 21, 16, 40, 0, time.UTC)
+	sha1 := "d241df7b9d2ec0b8194751cd5ce153e27cc40fa4"
+	amcache := &hiveBuilder{}
+	file := amcache.key("mimikatz.exe|5d1ae3a9f1ce2b57", firstSeen, nil,
+		amcache.value("LowerCaseLongPath", RegSZ, utf16z(strings.ToLower(testShimCachePaths[0]))),
+		amcache.value("FileId", RegSZ, utf16z("0000"+sha1)),
+		amcache.value("Size", RegQWORD, binary.LittleEndian.AppendUint64(nil, 1250056)))
+	inventory := amcache.key("InventoryApplicationFile", firstSeen, amcache.keys(file))
+	amcache.write(amcache.key("ROOT", firstSeen, amcache.keys(amcache.key("Root", firstSeen, amcache.keys(inventory)))))
+
+	systemPath, amcachePath := filepath.Join(dir, "SYSTEM"), filepath.Join(dir, "Amcache.hve")
+	if err := os.WriteFile(systemPath, system.data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(amcachePath, amcache.data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := CollectExecutionHistory(systemPath, amcachePath, "windows")
+	if len(results) != 2 {
+		t.Fatalf("collected %d execution history artifacts, want 2", len(results))
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			t.Fatalf("%s: %v", result.Artifact.Name, result.Error)
+		}
+	}
+	if format := results[0].Metadata.Tags["format"]; format != ShimCacheWin10 {
+		t.Errorf("ShimCache in the SYSTEM hive detected as %q", format)
+	}
+	checkShimCacheEntries(t, "SYSTEM hive", ExecutionEntries(results[0]), false)
+
+	amcacheEntries := ExecutionEntries(results[1])
+	if len(amcacheEntries) != 1 {
+		t.Fatalf("read %d Amcache entries, want 1", len(amcacheEntries))
+	}
+	entry := amcacheEntries[0]
+	if entry.Path != strings.ToLower(testShimCachePaths[0]) || entry.SHA1 != sha1 || entry.Size != 1250056 || !entry.FirstSeen.Equal(firstSeen) {
+		t.Errorf("Amcache entry read wrongly: %+v", entry)
+	}
+}
+
+// checkShimCacheEntries checks ShimCache entries against the paths they were
+// built from, the first marked executed when the format records it
+func checkShimCacheEntries(t *testing.T, source string, entries []ExecutionEntry, flags bool) {
+	t.Helper()
+	if len(entries) != len(testShimCachePaths) {
+		t.Errorf("%s: read %d entries, want %d", source, len(entries), len(testShimCachePaths))
+		return
+	}
+	for i, entry := range entries {
+		if entry.Path != testShimCachePaths[i] || entry.Position != i || !entry.LastModified.Equal(testShimCacheModified) {
+			t.Errorf("%s: entry %d read wrongly: %+v", source, i, entry)
+		}
+		if flags && (entry.Executed == nil || *entry.Executed != (i == 0)) {
+			t.Errorf("%s: entry %d has the wrong insert flag", source, i)
+		}
+		if !flags && entry.Executed != nil {
+			t.Errorf("%s: entry %d has an insert flag its format does not record", source, i)
+		}
+	}
+}
+
+// syntheticShimCacheWin7 builds a 64-bit Windows 7 AppCompatCache value
+// with the paths stored after the entries
+func syntheticShimCacheWin7(paths []string, modified time.Time) []byte {
+	const headerSize, entrySize = 128, 0x30
+	data := make([]byte, headerSize+len(paths)*entrySize)
+	binary.LittleEndian.PutUint32(data, 0xbadc0fee)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(paths)))
+	for i, path := range paths {
+		name := utf16z(path)
+		entry := data[headerSize+i*entrySize:]
+		binary.LittleEndian.PutUint16(entry, uint16(len(name)-2))
+		binary.LittleEndian.PutUint16(entry[2:], uint16(len(name)))
+		binary.LittleEndian.PutUint64(entry[8:], uint64(len(data)))
+		binary.LittleEndian.PutUint64(entry[16:], testFiletime(modified))
+		if i == 0 {
+			binary.LittleEndian.PutUint32(entry[24:], 0x2)
+		}
+		data = append(data, name...)
+	}
+	return data
+}
+
+// syntheticShimCacheWin8 builds a Windows 8.1 (header 0x80, with package
+// name and insert flags) or Windows 10 (header 0x34) AppCompatCache value
+func syntheticShimCacheWin8(header int, paths []string, modified time.Time) []byte {
+	data := make([]byte, header)
+	binary.LittleEndian.PutUint32(data, uint32(header))
+	for i, path := range paths {
+		name := utf16z(path)
+		name = name[:len(name)-2]
+		record := binary.LittleEndian.AppendUint16(nil, uint16(len(name)))
+		record = append(record, name...)
+		if header == 0x80 {
+			record = binary.LittleEndian.AppendUint16(record, 0)
+			flags := uint32(0)
+			if i == 0 {
+				flags = 0x2
+			}
+			record = binary.LittleEndian.AppendUint32(record, flags)
+			record = binary.LittleEndian.AppendUint32(record, 0)
+		}
+		record = binary.LittleEndian.AppendUint64(record, testFiletime(modified))
+		record = binary.LittleEndian.AppendUint32(record, 0)
+
+		data = append(data, "10ts"...)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(record)))
+		data = append(data, record...)
+	}
+	return data
+}
+
+// hiveBuilder writes a minimal registry hive: a base block and one hive bin
+// of cells, children written before their parents
+type hiveBuilder struct {
+	data  []byte
+	cells []byte
+}
+
+// cell appends an allocated cell and returns its offset
+func (b *hiveBuilder) cell(content []byte) uint32 {
+	if len(b.cells) == 0 {
+		b.cells = append([]byte("hbin"), make([]byte, 0x1c)...)
+	}
+	offset := uint32(len(b.cells))
+	size := (len(content) + 4 + 7) &^ 7
+	b.cells = binary.LittleEndian.AppendUint32(b.cells, uint32(-int32(size)))
+	b.cells = append(b.cells, content...)
+	b.cells = append(b.cells, make([]byte, size-4-len(content))...)
+	return offset
+}
+
+// value writes a vk cell with its data inline or in a cell of its own
+func (b *hiveBuilder) value(name string, kind uint32, data []byte) uint32 {
+	vk := make([]byte, 20, 20+len(name))
+	copy(vk, "vk")
+	binary.LittleEndian.PutUint16(vk[2:], uint16(len(name)))
+	if len(data) <= 4 {
+		binary.LittleEndian.PutUint32(vk[4:], uint32(len(data))|0x80000000)
+		copy(vk[8:12], data)
+	} else {
+		binary.LittleEndian.PutUint32(vk[4:], uint32(len(data)))
+		binary.LittleEndian.PutUint32(vk[8:], b.cell(data))
+	}
+	binary.LittleEndian.PutUint32(vk[12:], kind)
+	binary.LittleEndian.PutUint16(vk[16:], 1)
+	return b.cell(append(vk, name...))
+}
+
+// keys groups key offsets as the subkeys of a parent
+func (b *hiveBuilder) keys(offsets ...uint32) []uint32 {
+	return offsets
+}
+
+// key writes an nk cell with its subkey list and value list
+func (b *hiveBuilder) key(name string, lastWrite time.Time, subkeys []uint32, values ...uint32) uint32 {
+	nk := make([]byte, 76, 76+len(name))
+	copy(nk, "nk")
+	binary.LittleEndian.PutUint16(nk[2:], 0x20)
+	binary.LittleEndian.PutUint64(nk[4:], testFiletime(lastWrite))
+	binary.LittleEndian.PutUint32(nk[28:], 0xffffffff)
+	binary.LittleEndian.PutUint32(nk[40:], 0xffffffff)
+	if len(subkeys) > 0 {
+		list := []byte("lf")
+		list = binary.LittleEndian.AppendUint16(list, uint16(len(subkeys)))
+		for _, offset := range subkeys {
+			list = binary.LittleEndian.AppendUint32(list, offset)
+			list = binary.LittleEndian.AppendUint32(list, 0)
+		}
+		binary.LittleEndian.PutUint32(nk[20:], uint32(len(subkeys)))
+		binary.LittleEndian.PutUint32(nk[28:], b.cell(list))
+	}
+	if len(values) > 0 {
+		var list []byte
+		for _, offset := range values {
+			list = binary.LittleEndian.AppendUint32(list, offset)
+		}
+		binary.LittleEndian.PutUint32(nk[36:], uint32(len(values)))
+		binary.LittleEndian.PutUint32(nk[40:], b.cell(list))
+	}
+	binary.LittleEndian.PutUint16(nk[72:], uint16(len(name)))
+	return b.cell(append(nk, name...))
+}
+
+// write assembles the hive around its root key
+func (b *hiveBuilder) write(root uint32) {
+	for len(b.cells)%0x1000 != 0 {
+		b.cells = append(b.cells, 0)
+	}
+	binary.LittleEndian.PutUint32(b.cells[8:], uint32(len(b.cells)))
+	base := make([]byte, 0x1000)
+	copy(base, "regf")
+	binary.LittleEndian.PutUint32(base[0x14:], 1)
+	binary.LittleEndian.PutUint32(base[0x18:], 5)
+	binary.LittleEndian.PutUint32(base[0x24:], root)
+	binary.LittleEndian.PutUint32(base[0x28:], uint32(len(b.cells)))
+	b.data = append(base, b.cells...)
+}
+
+// utf16z encodes text as NUL-terminated UTF-16LE
+func utf16z(text string) []byte {
+	var data []byte
+	for _, unit := range utf16.Encode([]rune(text)) {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	return append(data, 0, 0)
+}
@@ -0,0 +1,72 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// CollectLiveExecutionHistory reads the ShimCache from the running
+// registry and the Amcache from a copy of Amcache.hve, which Windows keeps
+// locked. The copy is taken from a volume shadow copy with esentutl; with
+// readOnly nothing is written to the host and the hive is only read in
+// place, which fails while it is locked.
+func CollectLiveExecutionHistory(ctx context.Context, readOnly bool) []ArtifactResult {
+	shimcache := ExecutionHistoryArtifact("shimcache", "ShimCache (AppCompatCache) entries from the SYSTEM hive", "windows", nil)
+	const shimKey = `SYSTEM\CurrentControlSet\Control\Session Manager\AppCompatCache`
+	shimcache.Artifact.Parameters["path"] = `HKLM\` + shimKey
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, shimKey, registry.QUERY_VALUE)
+	if err == nil {
+		var value []byte
+		value, _, err = key.GetBinaryValue("AppCompatCache")
+		key.Close()
+		if err == nil {
+			entries, format, parseErr := ParseAppCompatCache(value)
+			shimcache = ExecutionHistoryArtifact(shimcache.Artifact.Name, shimcache.Artifact.Description, "windows", entries)
+			shimcache.Artifact.Parameters["path"] = `HKLM\` + shimKey
+			shimcache.Metadata.Tags["format"] = format
+			if parseErr != nil && len(entries) == 0 {
+				err = parseErr
+			} else if parseErr != nil {
+				shimcache.Metadata.Tags["parse_errors"] = parseErr.Error()
+			}
+		}
+	}
+	if err != nil {
+		shimcache.Fail(ReasonFor(err), fmt.Errorf("failed to read AppCompatCache: %w", err))
+	}
+
+	amcachePath := filepath.Join(os.Getenv("SystemRoot"), "AppCompat", "Programs", "Amcache.hve")
+	if readOnly {
+		return []ArtifactResult{shimcache, ReadAmcacheArtifact(amcachePath, amcachePath, "windows")}
+	}
+
+	dir, err := os.MkdirTemp("", "redtriage-amcache-*")
+	if err != nil {
+		amcache := ExecutionHistoryArtifact("amcache", "Amcache.hve program inventory", "windows", nil)
+		amcache.Fail(ReasonFor(err), fmt.Errorf("failed to create a directory for the Amcache copy: %w", err))
+		return []ArtifactResult{shimcache, amcache}
+	}
+	defer os.RemoveAll(dir)
+
+	copyPath := filepath.Join(dir, "Amcache.hve")
+	output, err := exec.CommandContext(ctx, "esentutl.exe", "/y", amcachePath, "/vss", "/d", copyPath).CombinedOutput()
+	if err != nil {
+		// Without a shadow copy the hive can only be read if it is not locked
+		amcache := ReadAmcacheArtifact(amcachePath, amcachePath, "windows")
+		if amcache.Error != nil {
+			amcache.Error = fmt.Errorf("%w; esentutl could not copy it: %s", amcache.Error, strings.TrimSpace(DecodeText(output).Text))
+		}
+		return []ArtifactResult{shimcache, amcache}
+	}
+	amcache := ReadAmcacheArtifact(copyPath, amcachePath, "windows")
+	amcache.Metadata.Source = "volume shadow copy"
+	return []ArtifactResult{shimcache, amcache}
+}
@@ -0,0 +1,319 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSweepType is the artifact type of a targeted file sweep
+const FileSweepType = "file_sweep"
+
+// Defaults applied when a sweep option is left unset
+const (
+	DefaultSweepMaxDepth   = 16
+	DefaultSweepMaxResults = 5000
+)
+
+// SweepOptions configures a targeted file sweep
+type SweepOptions struct {
+	Roots          []string      // Directories to walk
+	Globs          []string      // File name patterns, matched case-insensitively
+	MTimeWithin    time.Duration // Only match files modified within this window (0 = any time)
+	MaxDepth       int           // Maximum directory depth below each root
+	MaxResults     int           // Stop after this many matches
+	MaxHashBytes   int64         // Size budget for hashing matched files (0 = unlimited)
+	FilesPerSecond int           // Rate limit on entries examined (0 = unlimited)
+	IncludeHidden  bool          // Also walk hidden (dot) files and directories
+}
+
+// FileMatch is a file that matched a sweep
+type FileMatch struct {
+	Path       string     `json:"path"`
+	Size       int64      `json:"size"`
+	ModifiedAt time.Time  `json:"modified_at"`
+	AccessedAt *time.Time `json:"accessed_at,omitempty"`
+	ChangedAt  *time.Time `json:"changed_at,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	SHA256     string     `json:"sha256,omitempty"`
+	HashError  string     `json:"hash_error,omitempty"`
+}
+
+// FileSweep is the result of a targeted file sweep. A sweep that hit its
+// result limit or was cancelled keeps the matches found so far.
+type FileSweep struct {
+	Roots           []string    `json:"roots"`
+	Globs           []string    `json:"globs"`
+	MTimeWithin     string      `json:"mtime_within,omitempty"`
+	MaxDepth        int         `json:"max_depth"`
+	MaxResults      int         `json:"max_results"`
+	StartedAt       time.Time   `json:"started_at"`
+	Duration        string      `json:"duration"`
+	Scanned         int         `json:"scanned"`
+	SkippedLinks    int         `json:"skipped_links"`
+	SkippedHidden   int         `json:"skipped_hidden"`
+	IncludeHidden   bool        `json:"include_hidden,omitempty"`
+	Errors          int         `json:"errors"`
+	HashedBytes     int64       `json:"hashed_bytes"`
+	Truncated       bool        `json:"truncated,omitempty"`
+	TruncatedReason string      `json:"truncated_reason,omitempty"`
+	Cancelled       bool        `json:"cancelled,omitempty"`
+	Matches         []FileMatch `json:"matches"`
+}
+
+// errSweepLimit stops the walk once the result limit is reached
+var errSweepLimit = fmt.Errorf("sweep result limit reached")
+
+// SweepFiles walks the sweep roots looking for files whose names match the
+// globs. Symlinks and reparse points are never followed, hidden entries are
+// skipped unless opts.IncludeHidden is set, depth and result counts are
+// bounded, and cancelling ctx returns the partial result.
+func SweepFiles(ctx context.Context, opts SweepOptions) (*FileSweep, error) {
+	if len(opts.Roots) == 0 {
+		return nil, fmt.Errorf("at least one sweep path is required")
+	}
+	if len(opts.Globs) == 0 {
+		return nil, fmt.Errorf("at least one glob is required")
+	}
+
+	patterns := make([]string, 0, len(opts.Globs))
+	for _, glob := range opts.Globs {
+		pattern := strings.ToLower(glob)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultSweepMaxDepth
+	}
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultSweepMaxResults
+	}
+
+	sweep := &FileSweep{
+		Roots:         opts.Roots,
+		Globs:         opts.Globs,
+		MaxDepth:      opts.MaxDepth,
+		MaxResults:    opts.MaxResults,
+		IncludeHidden: opts.IncludeHidden,
+		StartedAt:     time.Now(),
+		Matches:       []FileMatch{},
+	}
+	if opts.MTimeWithin > 0 {
+		sweep.MTimeWithin = opts.MTimeWithin.String()
+	}
+
+	var modifiedAfter time.Time
+	if opts.MTimeWithin > 0 {
+		modifiedAfter = sweep.StartedAt.Add(-opts.MTimeWithin)
+	}
+
+	for _, root := range opts.Roots {
+		root = filepath.Clean(root)
+		rootDepth := strings.Count(root, string(filepath.Separator))
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				sweep.Errors++
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			sweep.Scanned++
+			throttleSweep(ctx, sweep, opts.FilesPerSecond)
+
+			// Links and reparse points are skipped so the walk can never loop
+			if d.Type()&(fs.ModeSymlink|fs.ModeIrregular) != 0 {
+				sweep.SkippedLinks++
+				return nil
+			}
+
+			if path != root && !opts.IncludeHidden && isHidden(d.Name()) {
+				sweep.SkippedHidden++
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			if d.IsDir() {
+				if path != root && strings.Count(path, string(filepath.Separator))-rootDepth >= opts.MaxDepth {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			if !d.Type().IsRegular() || !matchesAnyGlob(patterns, d.Name()) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				sweep.Errors++
+				return nil
+			}
+			if !modifiedAfter.IsZero() && info.ModTime().Before(modifiedAfter) {
+				return nil
+			}
+
+			sweep.Matches = append(sweep.Matches, newFileMatch(ctx, path, info, opts.MaxHashBytes, sweep))
+			if len(sweep.Matches) >= opts.MaxResults {
+				return errSweepLimit
+			}
+			return nil
+		})
+
+		switch {
+		case err == errSweepLimit:
+			sweep.Truncated = true
+			sweep.TruncatedReason = fmt.Sprintf("result limit of %d reached", opts.MaxResults)
+		case ctx.Err() != nil:
+			sweep.Cancelled = true
+		case err != nil:
+			sweep.Errors++
+		}
+		if sweep.Truncated || sweep.Cancelled {
+			break
+		}
+	}
+
+	sweep.Duration = time.Since(sweep.StartedAt).Round(time.Millisecond).String()
+	return sweep, nil
+}
+
+// newFileMatch records a matched file, hashing it while the size budget allows
+func newFileMatch(ctx context.Context, path string, info fs.FileInfo, maxHashBytes int64, sweep *FileSweep) FileMatch {
+	match := FileMatch{
+		Path:       path,
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime(),
+	}
+
+	details := statFileDetails(path, info)
+	match.Owner = details.owner
+	match.AccessedAt = optionalTime(details.accessedAt)
+	match.ChangedAt = optionalTime(details.changedAt)
+	match.CreatedAt = optionalTime(details.createdAt)
+
+	if maxHashBytes > 0 && sweep.HashedBytes+info.Size() > maxHashBytes {
+		match.HashError = "collection size budget exceeded"
+		return match
+	}
+
+	hash, err := hashFile(ctx, path)
+	if err != nil {
+		match.HashError = err.Error()
+		return match
+	}
+	match.SHA256 = hash
+	sweep.HashedBytes += info.Size()
+
+	return match
+}
+
+// fileDetails holds the platform-specific metadata of a matched file
+type fileDetails struct {
+	owner      string
+	accessedAt time.Time
+	changedAt  time.Time
+	createdAt  time.Time
+}
+
+// throttleSweep paces the walk to the configured entries per second
+func throttleSweep(ctx context.Context, sweep *FileSweep, filesPerSecond int) {
+	if filesPerSecond <= 0 {
+		return
+	}
+
+	due := sweep.StartedAt.Add(time.Duration(sweep.Scanned) * time.Second / time.Duration(filesPerSecond))
+	if wait := time.Until(due); wait > 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+	}
+}
+
+func matchesAnyGlob(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile returns the SHA256 of a file, stopping early if ctx is cancelled
+func hashFile(ctx context.Context, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, &contextReader{ctx: ctx, r: file}); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// contextReader fails reads once its context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ArtifactResult wraps the sweep as a standalone artifact
+func (sw *FileSweep) ArtifactResult() ArtifactResult {
+	artifact := NewBaseArtifact("file_sweep", "Targeted file sweep", "filesystem", FileSweepType).Artifact
+	artifact.Parameters["roots"] = strings.Join(sw.Roots, ";")
+	artifact.Parameters["globs"] = strings.Join(sw.Globs, ";")
+	artifact.Parameters["mtime_within"] = sw.MTimeWithin
+
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     sw,
+		Metadata: Metadata{
+			CollectedAt: sw.StartedAt,
+			Collector:   "file_sweep",
+			Source:      strings.Join(sw.Roots, ";"),
+			Tags: map[string]string{
+				"matches":   fmt.Sprintf("%d", len(sw.Matches)),
+				"truncated": fmt.Sprintf("%t", sw.Truncated),
+			},
+		},
+		Size: sw.HashedBytes,
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package collector
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// statFileDetails reads the owner and access/change times from the inode
+func statFileDetails(path string, info fs.FileInfo) fileDetails {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileDetails{}
+	}
+
+	details := fileDetails{
+		owner:      strconv.FormatUint(uint64(stat.Uid), 10),
+		accessedAt: time.Unix(stat.Atim.Unix()),
+		changedAt:  time.Unix(stat.Ctim.Unix()),
+	}
+	if u, err := user.LookupId(details.owner); err == nil {
+		details.owner = u.Username
+	}
+
+	return details
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package collector
+
+import "io/fs"
+
+// statFileDetails has no extra metadata on this platform beyond the mod time
+func statFileDetails(path string, info fs.FileInfo) fileDetails {
+	return fileDetails{}
+}
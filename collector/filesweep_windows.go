@@ -0,0 +1,41 @@
+//go:build windows
+
+package collector
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// statFileDetails reads the owner from the security descriptor and the
+// creation and access times from the file attributes
+func statFileDetails(path string, info fs.FileInfo) fileDetails {
+	var details fileDetails
+
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		details.createdAt = time.Unix(0, attrs.CreationTime.Nanoseconds())
+		details.accessedAt = time.Unix(0, attrs.LastAccessTime.Nanoseconds())
+	}
+
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return details
+	}
+	owner, _, err := sd.Owner()
+	if err != nil || owner == nil {
+		return details
+	}
+
+	details.owner = owner.String()
+	if account, domain, _, err := owner.LookupAccount(""); err == nil {
+		details.owner = account
+		if domain != "" {
+			details.owner = domain + `\` + account
+		}
+	}
+
+	return details
+}
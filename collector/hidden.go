@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// HiddenPersistenceType is the artifact type of the hidden persistence files
+const HiddenPersistenceType = "hidden_persistence_json"
+
+// Kinds of hidden persistence file
+const (
+	HiddenShellRC        = "shell_rc"        // shell startup files and ~/.ssh/rc
+	HiddenAuthorizedKeys = "authorized_keys" // SSH keys allowed to log in
+	HiddenAutostart      = "autostart"       // desktop session autostart entries
+	HiddenSystemdUser    = "systemd_user"    // per-user systemd units
+)
+
+const (
+	maxHiddenFiles       = 2000     // files recorded across all homes
+	maxHiddenFileContent = 64 << 10 // bytes of each file whose lines are kept
+)
+
+// hiddenPersistencePaths are the dotfiles, relative to a home directory,
+// where Unix persistence commonly hides. Directories are walked.
+var hiddenPersistencePaths = []struct {
+	kind, path string
+}{
+	{HiddenShellRC, ".bashrc"},
+	{HiddenShellRC, ".bash_profile"},
+	{HiddenShellRC, ".bash_login"},
+	{HiddenShellRC, ".bash_logout"},
+	{HiddenShellRC, ".profile"},
+	{HiddenShellRC, ".zshrc"},
+	{HiddenShellRC, ".zshenv"},
+	{HiddenShellRC, ".zprofile"},
+	{HiddenShellRC, ".zlogin"},
+	{HiddenShellRC, ".cshrc"},
+	{HiddenShellRC, ".tcshrc"},
+	{HiddenShellRC, ".xinitrc"},
+	{HiddenShellRC, ".xprofile"},
+	{HiddenShellRC, ".xsessionrc"},
+	{HiddenShellRC, ".ssh/rc"},
+	{HiddenAuthorizedKeys, ".ssh/authorized_keys"},
+	{HiddenAuthorizedKeys, ".ssh/authorized_keys2"},
+	{HiddenAutostart, ".config/autostart"},
+	{HiddenSystemdUser, ".config/systemd/user"},
+}
+
+// HiddenFile is a hidden persistence file found in a home directory, with
+// its lines
+type HiddenFile struct {
+	Kind      string    `json:"kind"`
+	User      string    `json:"user"`
+	Home      string    `json:"home"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Mode      string    `json:"mode"`
+	Modified  time.Time `json:"modified"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Lines     []string  `json:"lines"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// CollectHiddenPersistence reads the well-known hidden persistence files in
+// the given profiles (home directory to user name). These locations are
+// always read, hidden or not; scope only bounds the walk of the autostart
+// and systemd directories.
+func CollectHiddenPersistence(profiles map[string]string, scope WalkScope) ArtifactResult {
+	return collectHiddenPersistence(profiles, scope, true)
+}
+
+// collectHiddenPersistence reads hidden persistence files, following links
+// to regular files only when followLinks is set: in an image, a link
+// resolves on the analyst's machine instead
+func collectHiddenPersistence(profiles map[string]string, scope WalkScope, followLinks bool) ArtifactResult {
+	homes := make([]string, 0, len(profiles))
+	for home := range profiles {
+		homes = append(homes, home)
+	}
+	sort.Strings(homes)
+
+	scope = hiddenPersistenceScope.Override(scope)
+	files := []HiddenFile{}
+	truncated := false
+	var counts walkCounts
+	for _, home := range homes {
+		for _, location := range hiddenPersistencePaths {
+			root := filepath.Join(home, filepath.FromSlash(location.path))
+			walked, _ := walkScoped(root, scope, func(path, rel string, entry fs.DirEntry, err error) error {
+				if err != nil || entry.IsDir() {
+					return nil
+				}
+				// Dotfiles are often links into a dotfiles repository
+				stat := os.Lstat
+				if followLinks {
+					stat = os.Stat
+				}
+				info, err := stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					return nil
+				}
+				if len(files) >= maxHiddenFiles {
+					truncated = true
+					return filepath.SkipAll
+				}
+				files = append(files, hiddenFile(location.kind, home, profiles[home], path, info))
+				return nil
+			})
+			counts.notEntered += walked.notEntered
+		}
+	}
+
+	result := HiddenPersistenceArtifact(files)
+	result.Metadata.Tags["truncated"] = strconv.FormatBool(truncated)
+	if truncated {
+		result.Reason = ReasonTruncated
+	}
+	scope.record(result.Artifact.Parameters, counts)
+	return result
+}
+
+// hiddenFile describes one hidden persistence file and reads its lines, up
+// to maxHiddenFileContent bytes
+func hiddenFile(kind, home, user, path string, info fs.FileInfo) HiddenFile {
+	file := HiddenFile{
+		Kind:     kind,
+		User:     user,
+		Home:     home,
+		Path:     path,
+		Size:     info.Size(),
+		Mode:     info.Mode().String(),
+		Modified: info.ModTime().UTC(),
+		Lines:    []string{},
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return file
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxHiddenFileContent+1))
+	if err != nil {
+		return file
+	}
+	if len(data) > maxHiddenFileContent {
+		data = data[:maxHiddenFileContent]
+		file.Truncated = true
+	}
+	hash := sha256.Sum256(data)
+	file.SHA256 = hex.EncodeToString(hash[:])
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 4096), maxHiddenFileContent)
+	for scanner.Scan() {
+		file.Lines = append(file.Lines, scanner.Text())
+	}
+	return file
+}
+
+// HiddenPersistenceArtifact wraps hidden persistence files in an artifact
+// result
+func HiddenPersistenceArtifact(files []HiddenFile) ArtifactResult {
+	artifact := NewBaseArtifact("hidden_persistence", "Shell startup files, SSH authorized keys, autostart entries and systemd user units in home directories", "persistence", HiddenPersistenceType).Artifact
+	if files == nil {
+		files = []HiddenFile{}
+	}
+	data, _ := json.Marshal(files)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     files,
+		Size:     int64(len(data)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "hidden_persistence",
+			Version:     "1.0.0",
+			Tags:        map[string]string{"files": strconv.Itoa(len(files))},
+		},
+	}
+}
+
+// HiddenPersistenceFiles returns the files of a hidden persistence
+// artifact, decoding them when the artifact was read back from a bundle
+func HiddenPersistenceFiles(result ArtifactResult) []HiddenFile {
+	switch data := result.Data.(type) {
+	case []HiddenFile:
+		return data
+	case nil:
+		return nil
+	default:
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil
+		}
+		if text, ok := data.(string); ok {
+			raw = []byte(text)
+		}
+		var files []HiddenFile
+		if json.Unmarshal(raw, &files) != nil {
+			return nil
+		}
+		return files
+	}
+}
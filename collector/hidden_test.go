@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testLinuxImage is a miniature Linux image whose home directories hold a
+// startup file downloading to a shell, an authorized key setting the
+// environment and an autostart entry running from a hidden directory in /tmp
+var testLinuxImage = filepath.Join("testdata", "linux-image")
+
+// collectTestImage collects the basic and extended artifacts of an image
+// root as 'collect --root --extended' does, by name
+func collectTestImage(t *testing.T, root string, scope WalkScope) map[string]ArtifactResult {
+	t.Helper()
+	results, err := NewCollector().Collect(CollectionProfile{Root: root, Extended: true, Scope: scope})
+	if err != nil {
+		t.Fatalf("failed to collect from the image: %v", err)
+	}
+	byName := make(map[string]ArtifactResult)
+	for _, result := range results {
+		byName[result.Artifact.Name] = result
+	}
+	return byName
+}
+
+func TestCollectHiddenPersistenceFromImage(t *testing.T) {
+	root, err := filepath.Abs(testLinuxImage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hidden, ok := collectTestImage(t, root, WalkScope{})["hidden_persistence"]
+	if !ok || hidden.Error != nil {
+		t.Fatalf("hidden persistence not collected from the image: %v", hidden.Error)
+	}
+	files := HiddenPersistenceFiles(hidden)
+	kinds := make(map[string]int)
+	for _, file := range files {
+		if strings.HasPrefix(file.Path, root) {
+			t.Errorf("%s is not relative to the image", file.Path)
+		}
+		kinds[file.Kind]++
+	}
+	if len(files) != 4 || kinds[HiddenShellRC] != 2 || kinds[HiddenAuthorizedKeys] != 1 || kinds[HiddenAutostart] != 1 {
+		t.Errorf("collected %d hidden persistence files (%v), want .bashrc, .profile, authorized_keys and an autostart entry", len(files), kinds)
+	}
+}
+
+func TestListingsSkipHiddenEntries(t *testing.T) {
+	tmp := collectTestImage(t, testLinuxImage, WalkScope{})["tmp_files"]
+	listing, _ := tmp.Data.(string)
+	if tmp.Artifact.Parameters["hidden_skipped"] != "1" || strings.Contains(listing, "agent") {
+		t.Errorf("tmp listing did not skip its hidden directory:\n%s", listing)
+	}
+
+	tmp = collectTestImage(t, testLinuxImage, WalkScope{IncludeHidden: true})["tmp_files"]
+	listing, _ = tmp.Data.(string)
+	if tmp.Artifact.Parameters["hidden_skipped"] != "" || !strings.Contains(listing, "agent") {
+		t.Errorf("tmp listing with hidden entries included is wrong:\n%s", listing)
+	}
+}
+
+func TestSweepSkipsHiddenEntries(t *testing.T) {
+	for _, include := range []bool{false, true} {
+		sweep, err := SweepFiles(context.Background(), SweepOptions{
+			Roots:         []string{filepath.Join(testLinuxImage, "tmp")},
+			Globs:         []string{"*"},
+			IncludeHidden: include,
+		})
+		if err != nil {
+			t.Fatalf("SweepFiles: %v", err)
+		}
+		matches, skipped := 1, 1
+		if include {
+			matches, skipped = 3, 0
+		}
+		if len(sweep.Matches) != matches || sweep.SkippedHidden != skipped {
+			t.Errorf("sweep with hidden entries included %v matched %d and skipped %d, want %d and %d",
+				include, len(sweep.Matches), sweep.SkippedHidden, matches, skipped)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HostIdentityType is the artifact type of the host identity block
+const HostIdentityType = "host_identity"
+
+// HostIdentity identifies the machine a collection was taken from. The
+// fingerprint is derived from the durable identifiers, the machine ID and
+// the MAC addresses of physical adapters, so it tells apart hosts cloned
+// from one image that share a hostname.
+type HostIdentity struct {
+	Hostname          string         `json:"hostname"`
+	FQDN              string         `json:"fqdn,omitempty"`
+	Domain            string         `json:"domain,omitempty"`
+	MachineID         string         `json:"machine_id,omitempty"`
+	MACAddresses      []string       `json:"mac_addresses,omitempty"`
+	Platform          string         `json:"platform"`
+	OSBuild           string         `json:"os_build,omitempty"`
+	BootTime          string         `json:"boot_time,omitempty"`
+	ImageRoot         string         `json:"image_root,omitempty"`
+	Environment       *Environment   `json:"environment,omitempty"`
+	Fingerprint       string         `json:"fingerprint"`
+	FingerprintBasis  []string       `json:"fingerprint_basis"`
+	HostnameConflicts []HostConflict `json:"hostname_conflicts,omitempty"`
+}
+
+// HostConflict is an earlier collection that claims the same hostname as
+// this one with a different fingerprint
+type HostConflict struct {
+	Source      string `json:"source"`
+	Hostname    string `json:"hostname"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// GatherHostIdentity reads the identity of the live host, or of the image
+// mounted at root in offline mode. Images carry no network adapters, so
+// their fingerprint rests on the machine ID alone.
+func GatherHostIdentity(root string) HostIdentity {
+	if root != "" {
+		return imageHostIdentity(root)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	identity := HostIdentity{
+		Hostname:     hostname,
+		FQDN:         lookupFQDN(hostname),
+		Domain:       liveDomain(),
+		MachineID:    liveMachineID(),
+		MACAddresses: physicalMACAddresses(),
+		Platform:     runtime.GOOS,
+		OSBuild:      liveOSBuild(),
+		BootTime:     liveBootTime(),
+	}
+	if identity.Domain == "" {
+		if _, domain, ok := strings.Cut(identity.FQDN, "."); ok {
+			identity.Domain = domain
+		}
+	}
+	env := DetectEnvironment()
+	identity.Environment = &env
+	identity.fingerprint()
+	return identity
+}
+
+// imageHostIdentity reads the identifiers an offline image stores as plain
+// files. Windows images keep theirs in registry hives, which are not parsed
+// here, so only the platform is known for them.
+func imageHostIdentity(root string) HostIdentity {
+	identity := HostIdentity{Hostname: "unknown", Platform: DetectImageOS(root), ImageRoot: root}
+	if identity.Platform == "linux" {
+		if hostname := readTrimmed(filepath.Join(root, "etc", "hostname")); hostname != "" {
+			identity.Hostname = hostname
+		}
+		identity.MachineID = readTrimmed(filepath.Join(root, "etc", "machine-id"))
+		identity.OSBuild = osReleaseName(filepath.Join(root, "etc", "os-release"))
+	}
+	identity.fingerprint()
+	return identity
+}
+
+// fingerprint derives the fingerprint from the machine ID and MAC addresses.
+// A host with neither falls back to its hostname, which is recorded in the
+// basis so the weaker fingerprint is visible.
+func (h *HostIdentity) fingerprint() {
+	var parts []string
+	h.FingerprintBasis = nil
+	if h.MachineID != "" {
+		parts = append(parts, "machine_id="+strings.ToLower(h.MachineID))
+		h.FingerprintBasis = append(h.FingerprintBasis, "machine_id")
+	}
+	if len(h.MACAddresses) > 0 {
+		parts = append(parts, "mac="+strings.Join(h.MACAddresses, ","))
+		h.FingerprintBasis = append(h.FingerprintBasis, "mac_addresses")
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "hostname="+strings.ToLower(h.Hostname))
+		h.FingerprintBasis = append(h.FingerprintBasis, "hostname")
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	h.Fingerprint = hex.EncodeToString(sum[:16])
+}
+
+// ConflictsWith reports whether another identity claims the same hostname
+// with a different fingerprint
+func (h HostIdentity) ConflictsWith(other HostIdentity) bool {
+	return h.Fingerprint != "" && other.Fingerprint != "" &&
+		strings.EqualFold(h.Hostname, other.Hostname) && h.Fingerprint != other.Fingerprint
+}
+
+// Offline reports whether the identity was read from a mounted image rather
+// than the live host
+func (h HostIdentity) Offline() bool {
+	return h.ImageRoot != ""
+}
+
+// ShortFingerprint returns the first 12 characters of the fingerprint for
+// display
+func (h HostIdentity) ShortFingerprint() string {
+	if len(h.Fingerprint) > 12 {
+		return h.Fingerprint[:12]
+	}
+	return h.Fingerprint
+}
+
+// Map returns the identity as generic JSON data for reports and manifests
+func (h HostIdentity) Map() map[string]interface{} {
+	data, _ := json.Marshal(h)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// HostIdentityFromMap reads an identity stored in a report or manifest
+func HostIdentityFromMap(m map[string]interface{}) (HostIdentity, bool) {
+	var identity HostIdentity
+	data, err := json.Marshal(m)
+	if err != nil || json.Unmarshal(data, &identity) != nil || identity.Fingerprint == "" {
+		return HostIdentity{}, false
+	}
+	return identity, true
+}
+
+// HostIdentityArtifact wraps an identity as the host_identity artifact
+func HostIdentityArtifact(identity HostIdentity) ArtifactResult {
+	artifact := NewBaseArtifact("host_identity", "Host identity and collection fingerprint", "host", HostIdentityType)
+	artifact.Platform = identity.Platform
+	data, _ := json.Marshal(identity)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact.Artifact,
+		Data:     identity,
+		Size:     int64(len(data)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "identity",
+			Source:      "host",
+			Version:     "1.0.0",
+			Tags:        map[string]string{"fingerprint": identity.Fingerprint},
+		},
+	}
+}
+
+// FindHostIdentity returns the host identity recorded in a collection
+func FindHostIdentity(results []ArtifactResult) (HostIdentity, bool) {
+	for _, result := range results {
+		if result.Artifact.Type != HostIdentityType {
+			continue
+		}
+		switch data := result.Data.(type) {
+		case HostIdentity:
+			return data, true
+		case *HostIdentity:
+			return *data, data != nil
+		case map[string]interface{}:
+			return HostIdentityFromMap(data)
+		}
+	}
+	return HostIdentity{}, false
+}
+
+// SetHostConflicts records conflicting collections on the host_identity
+// artifact of a collection
+func SetHostConflicts(results []ArtifactResult, conflicts []HostConflict) {
+	for i := range results {
+		if identity, ok := results[i].Data.(HostIdentity); ok && results[i].Artifact.Type == HostIdentityType {
+			identity.HostnameConflicts = conflicts
+			results[i].Data = identity
+		}
+	}
+}
+
+// lookupFQDN resolves the fully qualified name of the host, giving up after
+// a short wait so an unreachable DNS server does not stall the collection
+func lookupFQDN(hostname string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cname, err := net.DefaultResolver.LookupCNAME(ctx, hostname)
+	fqdn := strings.TrimSuffix(cname, ".")
+	if err != nil || !strings.Contains(fqdn, ".") {
+		return ""
+	}
+	return fqdn
+}
+
+// physicalMACAddresses returns the sorted MAC addresses of non-loopback
+// adapters with a globally administered address. Virtual adapters such as
+// VPN, container and Hyper-V switches typically use locally administered
+// addresses that change between boots and are left out.
+func physicalMACAddresses() []string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var macs []string
+	for _, iface := range interfaces {
+		mac := iface.HardwareAddr
+		if iface.Flags&net.FlagLoopback != 0 || len(mac) != 6 || mac[0]&0x02 != 0 {
+			continue
+		}
+		address := mac.String()
+		if !seen[address] {
+			seen[address] = true
+			macs = append(macs, address)
+		}
+	}
+	sort.Strings(macs)
+	return macs
+}
+
+// osReleaseName returns PRETTY_NAME from an os-release file
+func osReleaseName(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
+
+// readTrimmed returns the trimmed contents of a small text file, or "" when
+// it cannot be read
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,81 @@
+//go:build linux
+
+package collector
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/utils"
+)
+
+// liveMachineID reads the systemd machine ID, or the D-Bus one on systems
+// without systemd
+func liveMachineID() string {
+	if id := readTrimmed("/etc/machine-id"); id != "" {
+		return id
+	}
+	return readTrimmed("/var/lib/dbus/machine-id")
+}
+
+// liveDomain returns the NIS domain when one is set; DNS domains are taken
+// from the FQDN
+func liveDomain() string {
+	domain := readTrimmed("/proc/sys/kernel/domainname")
+	if domain == "(none)" {
+		return ""
+	}
+	return domain
+}
+
+// liveOSBuild returns the distribution name and kernel release
+func liveOSBuild() string {
+	name := osReleaseName("/etc/os-release")
+	kernel := readTrimmed("/proc/sys/kernel/osrelease")
+	switch {
+	case name == "":
+		return kernel
+	case kernel == "":
+		return name
+	}
+	return name + " (kernel " + kernel + ")"
+}
+
+// liveBootTime reads the boot time from the btime line of /proc/stat
+func liveBootTime() string {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return ""
+			}
+			return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// liveHostRoles recognizes Samba and FreeIPA domain controllers and
+// Kubernetes control plane nodes by the state they keep on disk
+func liveHostRoles() []string {
+	var roles []string
+	ipaServers, _ := filepath.Glob("/etc/dirsrv/slapd-*")
+	if utils.FileExists("/var/lib/samba/private/sam.ldb") || len(ipaServers) > 0 {
+		roles = append(roles, RoleDomainController)
+	}
+	if utils.FileExists("/etc/kubernetes/manifests/kube-apiserver.yaml") {
+		roles = append(roles, RoleKubernetesControlPlane)
+	}
+	return roles
+}
@@ -0,0 +1,28 @@
+//go:build !linux && !windows
+
+package collector
+
+// liveMachineID has no durable machine ID source on this platform
+func liveMachineID() string {
+	return ""
+}
+
+// liveDomain leaves the domain to be taken from the FQDN
+func liveDomain() string {
+	return ""
+}
+
+// liveOSBuild has no OS build source on this platform
+func liveOSBuild() string {
+	return ""
+}
+
+// liveBootTime has no boot time source on this platform
+func liveBootTime() string {
+	return ""
+}
+
+// liveHostRoles has no role source on this platform
+func liveHostRoles() []string {
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build windows
+
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// getTickCount64 returns the milliseconds since boot without the 49-day
+// wraparound of GetTickCount
+var getTickCount64 = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetTickCount64")
+
+// liveMachineID reads the MachineGuid Windows generates at installation
+func liveMachineID() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Cryptography`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+
+	guid, _, err := key.GetStringValue("MachineGuid")
+	if err != nil {
+		return ""
+	}
+	return guid
+}
+
+// liveDomain returns the domain the host is joined to, or its workgroup
+func liveDomain() string {
+	var name *uint16
+	var joinType uint32
+	if err := windows.NetGetJoinInformation(nil, &name, &joinType); err != nil {
+		return ""
+	}
+	defer windows.NetApiBufferFree((*byte)(unsafe.Pointer(name)))
+
+	switch joinType {
+	case windows.NetSetupDomainName:
+		return windows.UTF16PtrToString(name)
+	case windows.NetSetupWorkgroupName:
+		return "WORKGROUP:" + windows.UTF16PtrToString(name)
+	}
+	return ""
+}
+
+// liveOSBuild returns the product name, release and full build number
+func liveOSBuild() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+
+	product, _, _ := key.GetStringValue("ProductName")
+	release, _, _ := key.GetStringValue("DisplayVersion")
+	build, _, _ := key.GetStringValue("CurrentBuild")
+	ubr, _, _ := key.GetIntegerValue("UBR")
+
+	name := product
+	if release != "" {
+		name += " " + release
+	}
+	if build != "" {
+		name += fmt.Sprintf(" (build %s.%d)", build, ubr)
+	}
+	return name
+}
+
+// liveBootTime derives the boot time from the system uptime
+func liveBootTime() string {
+	if getTickCount64.Find() != nil {
+		return ""
+	}
+	ticks, _, _ := getTickCount64.Call()
+	boot := time.Now().Add(-time.Duration(ticks) * time.Millisecond)
+	return boot.UTC().Truncate(time.Second).Format(time.RFC3339)
+}
+
+// liveHostRoles reads the product type: LanmanNt is a domain controller and
+// ServerNT any other server
+func liveHostRoles() []string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\ProductOptions`, registry.QUERY_VALUE)
+	if err != nil {
+		return nil
+	}
+	defer key.Close()
+
+	productType, _, err := key.GetStringValue("ProductType")
+	if err != nil {
+		return nil
+	}
+	switch strings.ToLower(productType) {
+	case "lanmannt":
+		return []string{RoleDomainController, RoleServer}
+	case "servernt":
+		return []string{RoleServer}
+	}
+	return nil
+}
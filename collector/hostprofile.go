@@ -0,0 +1,414 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Host profile sections, selectable with profile --include
+const (
+	ProfileOS          = "os"
+	ProfileSoftware    = "software"
+	ProfileUsers       = "users"
+	ProfileNetwork     = "network"
+	ProfileDisks       = "disks"
+	ProfileServices    = "services"
+	ProfilePersistence = "persistence"
+	ProfileSecurity    = "security"
+)
+
+// ProfileSections lists every host profile section in report order
+var ProfileSections = []string{
+	ProfileOS, ProfileSoftware, ProfileUsers, ProfileNetwork,
+	ProfileDisks, ProfileServices, ProfilePersistence, ProfileSecurity,
+}
+
+// HostProfile describes a live host in one read-only pass: what it runs,
+// who can log on, how it is connected and how it is defended. Sections
+// that were not requested are left empty; sections that could not be read
+// are named in Errors.
+type HostProfile struct {
+	Timestamp    string       `json:"timestamp"`
+	Hostname     string       `json:"hostname"`
+	Platform     string       `json:"platform"`
+	Architecture string       `json:"architecture"`
+	Sections     []string     `json:"sections"`
+	Host         HostIdentity `json:"host"`
+
+	OS             *OSProfile          `json:"os,omitempty"`
+	Software       []InstalledSoftware `json:"software,omitempty"`
+	Users          []LocalUser         `json:"users,omitempty"`
+	Groups         []LocalGroup        `json:"groups,omitempty"`
+	Network        *NetworkProfile     `json:"network,omitempty"`
+	Disks          []DiskVolume        `json:"disks,omitempty"`
+	Services       []ServiceEntry      `json:"services,omitempty"`
+	ServiceSummary *ServiceSummary     `json:"service_summary,omitempty"`
+	StartupItems   []StartupItem       `json:"startup_items,omitempty"`
+	ScheduledTasks []ScheduledTask     `json:"scheduled_tasks,omitempty"`
+	Security       *SecurityPosture    `json:"security,omitempty"`
+
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// OSProfile is the operating system version and patch level
+type OSProfile struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Build   string `json:"build,omitempty"`
+	Kernel  string `json:"kernel,omitempty"`
+	// PatchLevel is the update revision (Windows UBR) or the time the
+	// package database last changed (Linux)
+	PatchLevel    string        `json:"patch_level,omitempty"`
+	Hotfixes      []string      `json:"hotfixes,omitempty"`
+	UptimeSeconds uint64        `json:"uptime_seconds,omitempty"`
+	Memory        *MemoryStatus `json:"memory,omitempty"`
+}
+
+// InstalledSoftware is one entry of the software inventory
+type InstalledSoftware struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	InstallDate string `json:"install_date,omitempty"`
+	Source      string `json:"source"`
+}
+
+// LocalUser is a local account
+type LocalUser struct {
+	Username    string   `json:"username"`
+	UID         string   `json:"uid,omitempty"`
+	Home        string   `json:"home,omitempty"`
+	Shell       string   `json:"shell,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Interactive bool     `json:"interactive"`
+	Admin       bool     `json:"admin"`
+	Groups      []string `json:"groups,omitempty"`
+}
+
+// LocalGroup is a local group and its members
+type LocalGroup struct {
+	Name    string   `json:"name"`
+	GID     string   `json:"gid,omitempty"`
+	Members []string `json:"members,omitempty"`
+}
+
+// NetworkProfile is the network configuration of the host
+type NetworkProfile struct {
+	Interfaces      []NetworkInterface `json:"interfaces"`
+	DNSServers      []string           `json:"dns_servers,omitempty"`
+	DefaultGateways []string           `json:"default_gateways,omitempty"`
+}
+
+// NetworkInterface is one network adapter
+type NetworkInterface struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac,omitempty"`
+	MTU       int      `json:"mtu"`
+	Up        bool     `json:"up"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// DiskVolume is one mounted file system
+type DiskVolume struct {
+	Path       string `json:"path"`
+	Device     string `json:"device,omitempty"`
+	FileSystem string `json:"file_system,omitempty"`
+	DriveType  string `json:"drive_type,omitempty"`
+	SizeBytes  uint64 `json:"size_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+}
+
+// ServiceEntry is one installed service
+type ServiceEntry struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	StartType   string `json:"start_type,omitempty"`
+	State       string `json:"state"`
+	Path        string `json:"path,omitempty"`
+}
+
+// ServiceSummary counts the installed services by state
+type ServiceSummary struct {
+	Total     int      `json:"total"`
+	Running   int      `json:"running"`
+	AutoStart int      `json:"auto_start"`
+	Manager   string   `json:"manager"`
+	Names     []string `json:"running_services,omitempty"`
+}
+
+// StartupItem is a program started at boot or logon
+type StartupItem struct {
+	Name     string `json:"name"`
+	Command  string `json:"command,omitempty"`
+	Location string `json:"location"`
+}
+
+// ScheduledTask is a scheduled job: a Windows task or a cron entry
+type ScheduledTask struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule,omitempty"`
+	Command  string `json:"command,omitempty"`
+	RunAs    string `json:"run_as,omitempty"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// SecurityPosture summarizes the host's defenses
+type SecurityPosture struct {
+	Antivirus []SecurityProduct `json:"antivirus,omitempty"`
+	Firewall  []FirewallProfile `json:"firewall,omitempty"`
+	// UAC is enabled or disabled (Windows); SELinux is enforcing,
+	// permissive or disabled and AppArmor enabled or disabled (Linux)
+	UAC      string `json:"uac,omitempty"`
+	SELinux  string `json:"selinux,omitempty"`
+	AppArmor string `json:"apparmor,omitempty"`
+	// Highlights are the weaknesses worth a look, in a sentence each
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// SecurityProduct is an antivirus or EDR product
+type SecurityProduct struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	RealTime bool   `json:"real_time"`
+}
+
+// FirewallProfile is a firewall, or one profile of the Windows firewall
+type FirewallProfile struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ParseProfileSections reads a comma-separated --include list. An empty
+// list selects every section.
+func ParseProfileSections(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return ProfileSections, nil
+	}
+	known := make(map[string]bool, len(ProfileSections))
+	for _, section := range ProfileSections {
+		known[section] = true
+	}
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown profile section: %s (valid: %s)", name, strings.Join(ProfileSections, ", "))
+		}
+		selected[name] = true
+	}
+	var sections []string
+	for _, section := range ProfileSections {
+		if selected[section] {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// GatherHostProfile builds the profile of the live host from the selected
+// sections. Nothing is written to the host; commands are bounded by ctx.
+func GatherHostProfile(ctx context.Context, sections []string) HostProfile {
+	identity := GatherHostIdentity("")
+	profile := HostProfile{
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Hostname:     identity.Hostname,
+		Platform:     runtime.GOOS,
+		Architecture: runtime.GOARCH,
+		Sections:     sections,
+		Host:         identity,
+	}
+	fail := func(section string, err error) {
+		if err == nil {
+			return
+		}
+		if profile.Errors == nil {
+			profile.Errors = make(map[string]string)
+		}
+		profile.Errors[section] = err.Error()
+	}
+
+	for _, section := range sections {
+		if ctx.Err() != nil {
+			fail(section, ctx.Err())
+			continue
+		}
+		switch section {
+		case ProfileOS:
+			osProfile := liveOSProfile(ctx)
+			if uptime, err := liveUptime(); err == nil {
+				osProfile.UptimeSeconds = uint64(uptime / time.Second)
+			}
+			if memory, err := liveMemory(); err == nil {
+				osProfile.Memory = &memory
+			}
+			profile.OS = &osProfile
+		case ProfileSoftware:
+			software, err := liveSoftware(ctx)
+			sort.Slice(software, func(i, j int) bool { return strings.ToLower(software[i].Name) < strings.ToLower(software[j].Name) })
+			profile.Software = software
+			fail(section, err)
+		case ProfileUsers:
+			users, groups, err := liveAccounts(ctx)
+			profile.Users, profile.Groups = users, groups
+			fail(section, err)
+		case ProfileNetwork:
+			network, err := liveNetwork(ctx)
+			profile.Network = &network
+			fail(section, err)
+		case ProfileDisks:
+			disks, err := liveDisks()
+			profile.Disks = disks
+			fail(section, err)
+		case ProfileServices:
+			services, manager, err := liveServices(ctx)
+			profile.Services = services
+			profile.ServiceSummary = summarizeServices(services, manager)
+			fail(section, err)
+		case ProfilePersistence:
+			startup, tasks, err := livePersistence(ctx)
+			profile.StartupItems, profile.ScheduledTasks = startup, tasks
+			fail(section, err)
+		case ProfileSecurity:
+			security := liveSecurity(ctx)
+			security.Highlights = securityHighlights(security)
+			profile.Security = &security
+		}
+	}
+	return profile
+}
+
+// Map returns the profile as a generic map, the form profile --compare
+// and the structured session output use
+func (p HostProfile) Map() map[string]interface{} {
+	data, _ := json.Marshal(p)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// Includes reports whether a section was selected
+func (p HostProfile) Includes(section string) bool {
+	for _, s := range p.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceProfiles lists the network adapters and their addresses
+func interfaceProfiles() ([]NetworkInterface, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+	profiles := make([]NetworkInterface, 0, len(interfaces))
+	for _, iface := range interfaces {
+		profile := NetworkInterface{
+			Name: iface.Name,
+			MAC:  iface.HardwareAddr.String(),
+			MTU:  iface.MTU,
+			Up:   iface.Flags&net.FlagUp != 0,
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				profile.Addresses = append(profile.Addresses, addr.String())
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// summarizeServices counts services by state
+func summarizeServices(services []ServiceEntry, manager string) *ServiceSummary {
+	summary := &ServiceSummary{Total: len(services), Manager: manager}
+	for _, service := range services {
+		if service.State == "running" {
+			summary.Running++
+			summary.Names = append(summary.Names, service.Name)
+		}
+		switch strings.ToLower(service.StartType) {
+		case "auto", "automatic", "enabled":
+			summary.AutoStart++
+		}
+	}
+	sort.Strings(summary.Names)
+	return summary
+}
+
+// securityHighlights points out disabled or missing defenses
+func securityHighlights(security SecurityPosture) []string {
+	var highlights []string
+	enabledAV := 0
+	for _, product := range security.Antivirus {
+		if product.Enabled {
+			enabledAV++
+		} else {
+			highlights = append(highlights, fmt.Sprintf("antivirus %s is disabled", product.Name))
+		}
+		if product.Enabled && !product.RealTime {
+			highlights = append(highlights, fmt.Sprintf("real-time protection of %s is off", product.Name))
+		}
+	}
+	if enabledAV == 0 {
+		highlights = append(highlights, "no enabled antivirus or EDR product was found")
+	}
+	enabledFirewalls := 0
+	for _, firewall := range security.Firewall {
+		if firewall.Enabled {
+			enabledFirewalls++
+		} else if runtime.GOOS == "windows" {
+			highlights = append(highlights, fmt.Sprintf("firewall profile %s is disabled", firewall.Name))
+		}
+	}
+	if enabledFirewalls == 0 {
+		highlights = append(highlights, "no enabled host firewall was found")
+	}
+	if security.UAC == "disabled" {
+		highlights = append(highlights, "UAC is disabled")
+	}
+	if security.SELinux == "permissive" {
+		highlights = append(highlights, "SELinux is in permissive mode")
+	}
+	return highlights
+}
+
+// profileCommandTimeout bounds each command the host profile runs
+const profileCommandTimeout = 60 * time.Second
+
+// runProfileCommand runs a read-only command for the host profile and
+// returns its standard output. A failure carries what it wrote to standard
+// error.
+func runProfileCommand(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, profileCommandTimeout)
+	defer cancel()
+	output, run, err := RunCommand(exec.CommandContext(ctx, name, args...))
+	if err != nil {
+		if run.Stderr != "" {
+			return "", fmt.Errorf("%s failed: %s: %w", name, run.Stderr, err)
+		}
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return string(output), nil
+}
+
+// readLines returns the lines of a text file, or nil when it cannot be read
+func readLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+}
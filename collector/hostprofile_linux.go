@@ -0,0 +1,406 @@
+//go:build linux
+
+package collector
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// adminGroups are the groups whose members may become root
+var adminGroups = map[string]bool{"root": true, "sudo": true, "wheel": true, "admin": true}
+
+// securityAgents maps the process names of antivirus and EDR agents to
+// their products
+var securityAgents = map[string]string{
+	"clamd":         "ClamAV",
+	"falcon-sensor": "CrowdStrike Falcon",
+	"wdavdaemon":    "Microsoft Defender for Endpoint",
+	"s1-agent":      "SentinelOne",
+	"sentinelone":   "SentinelOne",
+	"cbagentd":      "Carbon Black",
+	"sophosav":      "Sophos",
+	"esets_daemon":  "ESET",
+	"ds_agent":      "Trend Micro Deep Security",
+	"osqueryd":      "osquery",
+	"auditd":        "Linux audit daemon",
+}
+
+// liveOSProfile reads the distribution from /etc/os-release and the kernel
+// release; the patch level is when the package database last changed
+func liveOSProfile(ctx context.Context) OSProfile {
+	profile := OSProfile{
+		Name:   osReleaseName("/etc/os-release"),
+		Kernel: readTrimmed("/proc/sys/kernel/osrelease"),
+		Build:  readTrimmed("/proc/sys/kernel/version"),
+	}
+	for _, line := range readLines("/etc/os-release") {
+		if value, ok := strings.CutPrefix(line, "VERSION_ID="); ok {
+			profile.Version = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	for _, db := range []string{"/var/lib/dpkg/status", "/var/lib/rpm/rpmdb.sqlite", "/var/lib/rpm/Packages", "/lib/apk/db/installed"} {
+		if info, err := os.Stat(db); err == nil {
+			profile.PatchLevel = "packages last changed " + info.ModTime().UTC().Format(time.RFC3339)
+			break
+		}
+	}
+	return profile
+}
+
+// liveSoftware lists installed packages from dpkg or rpm
+func liveSoftware(ctx context.Context) ([]InstalledSoftware, error) {
+	if _, err := exec.LookPath("dpkg-query"); err == nil {
+		output, err := runProfileCommand(ctx, "dpkg-query", "-W", "-f", "${Package}\t${Version}\t${Maintainer}\t${db:Status-Abbrev}\n")
+		if err != nil {
+			return nil, err
+		}
+		var software []InstalledSoftware
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) < 4 || !strings.HasPrefix(fields[3], "ii") {
+				continue
+			}
+			software = append(software, InstalledSoftware{Name: fields[0], Version: fields[1], Publisher: fields[2], Source: "dpkg"})
+		}
+		return software, nil
+	}
+	if _, err := exec.LookPath("rpm"); err == nil {
+		output, err := runProfileCommand(ctx, "rpm", "-qa", "--queryformat", "%{NAME}\t%{VERSION}-%{RELEASE}\t%{VENDOR}\t%{INSTALLTIME}\n")
+		if err != nil {
+			return nil, err
+		}
+		var software []InstalledSoftware
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) < 4 {
+				continue
+			}
+			entry := InstalledSoftware{Name: fields[0], Version: fields[1], Publisher: fields[2], Source: "rpm"}
+			var seconds int64
+			if _, err := fmt.Sscanf(fields[3], "%d", &seconds); err == nil {
+				entry.InstallDate = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+			}
+			software = append(software, entry)
+		}
+		return software, nil
+	}
+	return nil, fmt.Errorf("no supported package manager (dpkg, rpm) found")
+}
+
+// liveAccounts reads local users and groups from /etc/passwd and /etc/group
+func liveAccounts(ctx context.Context) ([]LocalUser, []LocalGroup, error) {
+	passwd := readLines("/etc/passwd")
+	if passwd == nil {
+		return nil, nil, fmt.Errorf("failed to read /etc/passwd")
+	}
+
+	primaryGroups := make(map[string]string)
+	var groups []LocalGroup
+	memberOf := make(map[string][]string)
+	for _, line := range readLines("/etc/group") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 4 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		group := LocalGroup{Name: fields[0], GID: fields[2]}
+		if fields[3] != "" {
+			group.Members = strings.Split(fields[3], ",")
+		}
+		for _, member := range group.Members {
+			memberOf[member] = append(memberOf[member], group.Name)
+		}
+		primaryGroups[group.GID] = group.Name
+		groups = append(groups, group)
+	}
+
+	var users []LocalUser
+	for _, line := range passwd {
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user := LocalUser{
+			Username: fields[0],
+			UID:      fields[2],
+			Home:     fields[5],
+			Shell:    fields[6],
+			Enabled:  true,
+			Groups:   memberOf[fields[0]],
+		}
+		if primary := primaryGroups[fields[3]]; primary != "" {
+			user.Groups = append([]string{primary}, user.Groups...)
+		}
+		base := filepath.Base(user.Shell)
+		user.Interactive = user.Shell != "" && base != "nologin" && base != "false" && base != "sync"
+		user.Admin = user.UID == "0"
+		for _, group := range user.Groups {
+			if adminGroups[group] {
+				user.Admin = true
+			}
+		}
+		users = append(users, user)
+	}
+	return users, groups, nil
+}
+
+// liveNetwork lists the adapters, the resolvers of /etc/resolv.conf and the
+// default routes of /proc/net/route
+func liveNetwork(ctx context.Context) (NetworkProfile, error) {
+	interfaces, err := interfaceProfiles()
+	profile := NetworkProfile{Interfaces: interfaces}
+	for _, line := range readLines("/etc/resolv.conf") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "nameserver" {
+			profile.DNSServers = append(profile.DNSServers, fields[1])
+		}
+	}
+	for _, line := range readLines("/proc/net/route") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gateway, err := hex.DecodeString(fields[2])
+		if err != nil || len(gateway) != 4 {
+			continue
+		}
+		// The kernel writes the address in host (little-endian) order
+		ip := net.IPv4(gateway[3], gateway[2], gateway[1], gateway[0])
+		profile.DefaultGateways = append(profile.DefaultGateways, fmt.Sprintf("%s via %s", ip, fields[0]))
+	}
+	return profile, err
+}
+
+// diskFileSystems are the file systems backed by storage; pseudo file
+// systems such as proc and tmpfs are left out of the disk layout
+var diskFileSystems = map[string]bool{
+	"ext2": true, "ext3": true, "ext4": true, "xfs": true, "btrfs": true, "zfs": true,
+	"vfat": true, "exfat": true, "ntfs": true, "ntfs3": true, "f2fs": true, "jfs": true,
+	"reiserfs": true, "overlay": true, "9p": true, "drvfs": true, "nfs": true, "nfs4": true, "cifs": true,
+}
+
+// remoteFileSystems are the disk file systems served over the network
+var remoteFileSystems = map[string]bool{"nfs": true, "nfs4": true, "cifs": true}
+
+// liveDisks lists the mounted storage file systems of /proc/mounts
+func liveDisks() ([]DiskVolume, error) {
+	mounts := readLines("/proc/mounts")
+	if mounts == nil {
+		return nil, fmt.Errorf("failed to read /proc/mounts")
+	}
+	var disks []DiskVolume
+	seen := make(map[string]bool)
+	for _, line := range mounts {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !diskFileSystems[fields[2]] || seen[fields[1]] {
+			continue
+		}
+		seen[fields[1]] = true
+		disk := DiskVolume{Path: fields[1], Device: fields[0], FileSystem: fields[2], DriveType: DriveFixed}
+		if remoteFileSystems[disk.FileSystem] {
+			disk.DriveType = DriveRemote
+		}
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(disk.Path, &stat); err == nil {
+			disk.SizeBytes = stat.Blocks * uint64(stat.Bsize)
+			disk.FreeBytes = stat.Bavail * uint64(stat.Bsize)
+		}
+		disks = append(disks, disk)
+	}
+	return disks, nil
+}
+
+// liveServices lists systemd services, or the SysV init scripts where
+// systemd is not running
+func liveServices(ctx context.Context) ([]ServiceEntry, string, error) {
+	if !DetectEnvironment().Systemd {
+		entries, err := os.ReadDir("/etc/init.d")
+		if err != nil {
+			return nil, "none", nil
+		}
+		var services []ServiceEntry
+		for _, entry := range entries {
+			services = append(services, ServiceEntry{Name: entry.Name(), State: "unknown", Path: filepath.Join("/etc/init.d", entry.Name())})
+		}
+		return services, "sysvinit", nil
+	}
+
+	files, err := runProfileCommand(ctx, "systemctl", "list-unit-files", "--type=service", "--no-legend", "--no-pager")
+	if err != nil {
+		return nil, "systemd", err
+	}
+	running := make(map[string]bool)
+	if units, err := runProfileCommand(ctx, "systemctl", "list-units", "--type=service", "--state=running", "--no-legend", "--no-pager", "--plain"); err == nil {
+		for _, line := range strings.Split(units, "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				running[fields[0]] = true
+			}
+		}
+	}
+
+	var services []ServiceEntry
+	for _, line := range strings.Split(files, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		service := ServiceEntry{Name: fields[0], StartType: fields[1], State: "stopped"}
+		if running[service.Name] {
+			service.State = "running"
+		}
+		services = append(services, service)
+	}
+	return services, "systemd", nil
+}
+
+// livePersistence lists units enabled at boot, autostart entries and
+// rc.local as startup items, and system and user crontabs as scheduled tasks
+func livePersistence(ctx context.Context) ([]StartupItem, []ScheduledTask, error) {
+	var startup []StartupItem
+	wants, _ := filepath.Glob("/etc/systemd/system/*.wants/*")
+	for _, path := range wants {
+		startup = append(startup, StartupItem{Name: filepath.Base(path), Command: resolveLink(path), Location: filepath.Dir(path)})
+	}
+	for _, dir := range []string{"/etc/xdg/autostart"} {
+		desktop, _ := filepath.Glob(filepath.Join(dir, "*.desktop"))
+		for _, path := range desktop {
+			item := StartupItem{Name: filepath.Base(path), Location: dir}
+			for _, line := range readLines(path) {
+				if value, ok := strings.CutPrefix(line, "Exec="); ok {
+					item.Command = value
+					break
+				}
+			}
+			startup = append(startup, item)
+		}
+	}
+	if info, err := os.Stat("/etc/rc.local"); err == nil && info.Mode()&0111 != 0 {
+		startup = append(startup, StartupItem{Name: "rc.local", Command: "/etc/rc.local", Location: "/etc"})
+	}
+
+	var tasks []ScheduledTask
+	tasks = append(tasks, cronTasks("/etc/crontab", "", true)...)
+	cronD, _ := filepath.Glob("/etc/cron.d/*")
+	for _, path := range cronD {
+		tasks = append(tasks, cronTasks(path, "", true)...)
+	}
+	for _, dir := range []string{"/var/spool/cron/crontabs", "/var/spool/cron"} {
+		userTabs, _ := filepath.Glob(filepath.Join(dir, "*"))
+		for _, path := range userTabs {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				tasks = append(tasks, cronTasks(path, filepath.Base(path), false)...)
+			}
+		}
+	}
+	for _, period := range []string{"hourly", "daily", "weekly", "monthly"} {
+		scripts, _ := filepath.Glob(filepath.Join("/etc/cron."+period, "*"))
+		for _, path := range scripts {
+			tasks = append(tasks, ScheduledTask{Name: path, Schedule: "@" + period, Command: path, RunAs: "root", Enabled: true})
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return startup, tasks, nil
+}
+
+// cronTasks parses the entries of a crontab. System crontabs name the user
+// in the sixth field; user crontabs run as their owner.
+func cronTasks(path, owner string, system bool) []ScheduledTask {
+	var tasks []ScheduledTask
+	for i, line := range readLines(path) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if strings.Contains(fields[0], "=") {
+			continue // environment assignment
+		}
+		scheduleFields := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFields = 1
+		}
+		userField := 0
+		if system {
+			userField = 1
+		}
+		if len(fields) <= scheduleFields+userField {
+			continue
+		}
+		task := ScheduledTask{
+			Name:     fmt.Sprintf("%s:%d", path, i+1),
+			Schedule: strings.Join(fields[:scheduleFields], " "),
+			RunAs:    owner,
+			Enabled:  true,
+		}
+		if system {
+			task.RunAs = fields[scheduleFields]
+		}
+		task.Command = strings.Join(fields[scheduleFields+userField:], " ")
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// resolveLink returns the target of a symbolic link, or the path itself
+func resolveLink(path string) string {
+	if target, err := os.Readlink(path); err == nil {
+		return target
+	}
+	return path
+}
+
+// liveSecurity looks for running security agents, enabled firewalls and
+// the SELinux and AppArmor modes
+func liveSecurity(ctx context.Context) SecurityPosture {
+	var posture SecurityPosture
+
+	running := make(map[string]bool)
+	comms, _ := filepath.Glob("/proc/[0-9]*/comm")
+	for _, path := range comms {
+		running[readTrimmed(path)] = true
+	}
+	seen := make(map[string]bool)
+	for process, product := range securityAgents {
+		if running[process] && !seen[product] {
+			seen[product] = true
+			posture.Antivirus = append(posture.Antivirus, SecurityProduct{Name: product, Enabled: true, RealTime: process != "auditd"})
+		}
+	}
+	sort.Slice(posture.Antivirus, func(i, j int) bool { return posture.Antivirus[i].Name < posture.Antivirus[j].Name })
+
+	for _, line := range readLines("/etc/ufw/ufw.conf") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "ENABLED="); ok {
+			posture.Firewall = append(posture.Firewall, FirewallProfile{Name: "ufw", Enabled: strings.EqualFold(strings.Trim(value, `"`), "yes")})
+		}
+	}
+	if running["firewalld"] {
+		posture.Firewall = append(posture.Firewall, FirewallProfile{Name: "firewalld", Enabled: true})
+	}
+	if tables := readTrimmed("/proc/net/ip_tables_names"); tables != "" {
+		posture.Firewall = append(posture.Firewall, FirewallProfile{Name: "iptables (" + strings.Join(strings.Fields(tables), ", ") + ")", Enabled: true})
+	}
+
+	switch enforce := readTrimmed("/sys/fs/selinux/enforce"); enforce {
+	case "1":
+		posture.SELinux = "enforcing"
+	case "0":
+		posture.SELinux = "permissive"
+	default:
+		posture.SELinux = "disabled"
+	}
+	switch readTrimmed("/sys/module/apparmor/parameters/enabled") {
+	case "Y":
+		posture.AppArmor = "enabled"
+	default:
+		posture.AppArmor = "disabled"
+	}
+	return posture
+}
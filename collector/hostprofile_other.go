@@ -0,0 +1,54 @@
+//go:build !linux && !windows
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// errProfileUnsupported is reported for host profile sections this
+// platform cannot read
+var errProfileUnsupported = fmt.Errorf("not supported on %s", runtime.GOOS)
+
+// liveOSProfile names the platform only
+func liveOSProfile(ctx context.Context) OSProfile {
+	return OSProfile{Name: runtime.GOOS}
+}
+
+// liveSoftware has no software inventory source on this platform
+func liveSoftware(ctx context.Context) ([]InstalledSoftware, error) {
+	return nil, errProfileUnsupported
+}
+
+// liveAccounts has no account source on this platform
+func liveAccounts(ctx context.Context) ([]LocalUser, []LocalGroup, error) {
+	return nil, nil, errProfileUnsupported
+}
+
+// liveNetwork lists the network adapters
+func liveNetwork(ctx context.Context) (NetworkProfile, error) {
+	interfaces, err := interfaceProfiles()
+	return NetworkProfile{Interfaces: interfaces}, err
+}
+
+// liveDisks has no disk layout source on this platform
+func liveDisks() ([]DiskVolume, error) {
+	return nil, errProfileUnsupported
+}
+
+// liveServices has no service manager source on this platform
+func liveServices(ctx context.Context) ([]ServiceEntry, string, error) {
+	return nil, "", errProfileUnsupported
+}
+
+// livePersistence has no startup item source on this platform
+func livePersistence(ctx context.Context) ([]StartupItem, []ScheduledTask, error) {
+	return nil, nil, errProfileUnsupported
+}
+
+// liveSecurity has no security posture source on this platform
+func liveSecurity(ctx context.Context) SecurityPosture {
+	return SecurityPosture{}
+}
@@ -0,0 +1,362 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// uninstallKeys hold the installed software of the machine, 32-bit
+// software on 64-bit Windows and the current user
+var uninstallKeys = []struct {
+	root registry.Key
+	path string
+	name string
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, "HKLM"},
+	{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`, "HKLM (32-bit)"},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, "HKCU"},
+}
+
+// runKeys start programs at boot or logon
+var runKeys = []struct {
+	root registry.Key
+	path string
+	name string
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, `HKLM\...\Run`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`, `HKLM\...\RunOnce`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Run`, `HKLM\WOW6432Node\...\Run`},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, `HKCU\...\Run`},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`, `HKCU\...\RunOnce`},
+}
+
+// profileAccountsScript lists local users and groups as JSON
+const profileAccountsScript = `$users = @(Get-LocalUser | ForEach-Object {
+  [pscustomobject]@{ Name = $_.Name; SID = $_.SID.Value; Enabled = $_.Enabled }
+})
+$groups = @(Get-LocalGroup | ForEach-Object {
+  $members = @(Get-LocalGroupMember -Group $_ -ErrorAction SilentlyContinue | ForEach-Object { $_.Name })
+  [pscustomobject]@{ Name = $_.Name; SID = $_.SID.Value; Members = $members }
+})
+ConvertTo-Json -InputObject @{ Users = $users; Groups = $groups } -Depth 4 -Compress`
+
+// profileServicesScript lists services from Win32_Service as JSON
+const profileServicesScript = `$services = @(Get-CimInstance Win32_Service | ForEach-Object {
+  [pscustomobject]@{ Name = $_.Name; DisplayName = $_.DisplayName; StartMode = $_.StartMode; State = $_.State; PathName = $_.PathName }
+})
+ConvertTo-Json -InputObject $services -Compress`
+
+// profileNetworkScript lists DNS servers and default gateways as JSON
+const profileNetworkScript = `$dns = @(Get-DnsClientServerAddress -ErrorAction SilentlyContinue | ForEach-Object { $_.ServerAddresses } | Select-Object -Unique)
+$gateways = @(Get-NetRoute -DestinationPrefix '0.0.0.0/0','::/0' -ErrorAction SilentlyContinue | ForEach-Object { "$($_.NextHop) via $($_.InterfaceAlias)" })
+ConvertTo-Json -InputObject @{ DNS = $dns; Gateways = $gateways } -Compress`
+
+// profileSecurityScript reads the antivirus products registered with
+// Security Center, Defender's state and the firewall profiles as JSON.
+// Bit 0x1000 of productState is set when a product is enabled.
+const profileSecurityScript = `$av = @(Get-CimInstance -Namespace root/SecurityCenter2 -ClassName AntiVirusProduct -ErrorAction SilentlyContinue | ForEach-Object {
+  [pscustomobject]@{ Name = $_.displayName; Enabled = (($_.productState -band 0x1000) -ne 0) }
+})
+$defender = Get-MpComputerStatus -ErrorAction SilentlyContinue
+$firewall = @(Get-NetFirewallProfile -ErrorAction SilentlyContinue | ForEach-Object {
+  [pscustomobject]@{ Name = $_.Name; Enabled = [bool]$_.Enabled }
+})
+ConvertTo-Json -InputObject @{
+  Antivirus = $av
+  DefenderEnabled = [bool]($defender -and $defender.AntivirusEnabled)
+  DefenderRealTime = [bool]($defender -and $defender.RealTimeProtectionEnabled)
+  Firewall = $firewall
+} -Depth 3 -Compress`
+
+// profileTasksScript lists scheduled tasks outside \Microsoft\ as JSON
+const profileTasksScript = `$tasks = @(Get-ScheduledTask | Where-Object { $_.TaskPath -notlike '\Microsoft\*' } | ForEach-Object {
+  [pscustomobject]@{
+    Name = $_.TaskPath + $_.TaskName
+    RunAs = $_.Principal.UserId
+    Enabled = $_.State.ToString() -ne 'Disabled'
+    Triggers = @($_.Triggers | ForEach-Object { $_.CimClass.CimClassName -replace '^MSFT_Task|Trigger$', '' }) -join ', '
+    Actions = @($_.Actions | ForEach-Object { (@($_.Execute, $_.Arguments) -join ' ').Trim() }) -join '; '
+  }
+})
+ConvertTo-Json -InputObject $tasks -Compress`
+
+// liveOSProfile reads the product, release and build from the registry and
+// the installed hotfixes from Win32_QuickFixEngineering
+func liveOSProfile(ctx context.Context) OSProfile {
+	var profile OSProfile
+	if key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE|registry.WOW64_64KEY); err == nil {
+		profile.Name, _, _ = key.GetStringValue("ProductName")
+		profile.Version, _, _ = key.GetStringValue("DisplayVersion")
+		profile.Build, _, _ = key.GetStringValue("CurrentBuild")
+		if ubr, _, err := key.GetIntegerValue("UBR"); err == nil {
+			profile.PatchLevel = fmt.Sprintf("%s.%d", profile.Build, ubr)
+		}
+		key.Close()
+	}
+	var hotfixes []string
+	if err := powerShellJSON(ctx, `ConvertTo-Json -InputObject @(Get-CimInstance Win32_QuickFixEngineering | ForEach-Object { $_.HotFixID }) -Compress`, &hotfixes); err == nil {
+		sort.Strings(hotfixes)
+		profile.Hotfixes = hotfixes
+	}
+	return profile
+}
+
+// liveSoftware reads the uninstall keys of the registry
+func liveSoftware(ctx context.Context) ([]InstalledSoftware, error) {
+	var software []InstalledSoftware
+	seen := make(map[string]bool)
+	for _, source := range uninstallKeys {
+		key, err := registry.OpenKey(source.root, source.path, registry.ENUMERATE_SUB_KEYS|registry.WOW64_64KEY)
+		if err != nil {
+			continue
+		}
+		names, _ := key.ReadSubKeyNames(-1)
+		key.Close()
+		for _, name := range names {
+			entry, err := registry.OpenKey(source.root, source.path+`\`+name, registry.QUERY_VALUE|registry.WOW64_64KEY)
+			if err != nil {
+				continue
+			}
+			display, _, _ := entry.GetStringValue("DisplayName")
+			version, _, _ := entry.GetStringValue("DisplayVersion")
+			publisher, _, _ := entry.GetStringValue("Publisher")
+			installed, _, _ := entry.GetStringValue("InstallDate")
+			entry.Close()
+			if display == "" || seen[display+"|"+version] {
+				continue
+			}
+			seen[display+"|"+version] = true
+			software = append(software, InstalledSoftware{Name: display, Version: version, Publisher: publisher, InstallDate: installed, Source: source.name})
+		}
+	}
+	if len(software) == 0 {
+		return nil, fmt.Errorf("no uninstall registry keys could be read")
+	}
+	return software, nil
+}
+
+// liveAccounts lists local users and groups; members of Administrators are
+// marked as admins
+func liveAccounts(ctx context.Context) ([]LocalUser, []LocalGroup, error) {
+	var accounts struct {
+		Users []struct {
+			Name    string
+			SID     string
+			Enabled bool
+		}
+		Groups []struct {
+			Name    string
+			SID     string
+			Members []string
+		}
+	}
+	if err := powerShellJSON(ctx, profileAccountsScript, &accounts); err != nil {
+		return nil, nil, err
+	}
+
+	memberOf := make(map[string][]string)
+	admins := make(map[string]bool)
+	var groups []LocalGroup
+	for _, g := range accounts.Groups {
+		groups = append(groups, LocalGroup{Name: g.Name, GID: g.SID, Members: g.Members})
+		for _, member := range g.Members {
+			// Members are DOMAIN\name; local users are matched by name
+			name := strings.ToLower(member[strings.LastIndex(member, `\`)+1:])
+			memberOf[name] = append(memberOf[name], g.Name)
+			// S-1-5-32-544 is BUILTIN\Administrators in every language
+			if g.SID == "S-1-5-32-544" {
+				admins[name] = true
+			}
+		}
+	}
+
+	var users []LocalUser
+	for _, u := range accounts.Users {
+		name := strings.ToLower(u.Name)
+		users = append(users, LocalUser{
+			Username:    u.Name,
+			UID:         u.SID,
+			Enabled:     u.Enabled,
+			Interactive: u.Enabled,
+			Admin:       admins[name],
+			Groups:      memberOf[name],
+		})
+	}
+	return users, groups, nil
+}
+
+// liveNetwork lists the adapters, DNS servers and default gateways
+func liveNetwork(ctx context.Context) (NetworkProfile, error) {
+	interfaces, err := interfaceProfiles()
+	profile := NetworkProfile{Interfaces: interfaces}
+	var config struct {
+		DNS      []string
+		Gateways []string
+	}
+	if powerShellJSON(ctx, profileNetworkScript, &config) == nil {
+		profile.DNSServers = config.DNS
+		profile.DefaultGateways = config.Gateways
+	}
+	return profile, err
+}
+
+// liveDisks lists the fixed, removable and network drives with their file
+// systems and free space
+func liveDisks() ([]DiskVolume, error) {
+	buffer := make([]uint16, 254)
+	n, err := windows.GetLogicalDriveStrings(uint32(len(buffer)), &buffer[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drives: %w", err)
+	}
+
+	var disks []DiskVolume
+	for _, drive := range strings.Split(windows.UTF16ToString(buffer[:n]), "\x00") {
+		if drive == "" {
+			continue
+		}
+		root, _ := windows.UTF16PtrFromString(drive)
+		disk := DiskVolume{Path: drive}
+		switch windows.GetDriveType(root) {
+		case windows.DRIVE_FIXED:
+			disk.DriveType = DriveFixed
+		case windows.DRIVE_REMOVABLE:
+			disk.DriveType = DriveRemovable
+		case windows.DRIVE_REMOTE:
+			disk.DriveType = DriveRemote
+		default:
+			continue
+		}
+		fsName := make([]uint16, windows.MAX_PATH+1)
+		if windows.GetVolumeInformation(root, nil, 0, nil, nil, nil, &fsName[0], uint32(len(fsName))) == nil {
+			disk.FileSystem = windows.UTF16ToString(fsName)
+		}
+		var free, total, totalFree uint64
+		if windows.GetDiskFreeSpaceEx(root, &free, &total, &totalFree) == nil {
+			disk.SizeBytes, disk.FreeBytes = total, free
+		}
+		disks = append(disks, disk)
+	}
+	return disks, nil
+}
+
+// liveServices lists the services of the Service Control Manager
+func liveServices(ctx context.Context) ([]ServiceEntry, string, error) {
+	var raw []struct {
+		Name        string
+		DisplayName string
+		StartMode   string
+		State       string
+		PathName    string
+	}
+	if err := powerShellJSON(ctx, profileServicesScript, &raw); err != nil {
+		return nil, "scm", err
+	}
+	services := make([]ServiceEntry, 0, len(raw))
+	for _, s := range raw {
+		services = append(services, ServiceEntry{
+			Name:        s.Name,
+			DisplayName: s.DisplayName,
+			StartType:   strings.ToLower(s.StartMode),
+			State:       strings.ToLower(s.State),
+			Path:        s.PathName,
+		})
+	}
+	return services, "scm", nil
+}
+
+// livePersistence lists the Run keys and the scheduled tasks outside the
+// \Microsoft\ folder
+func livePersistence(ctx context.Context) ([]StartupItem, []ScheduledTask, error) {
+	var startup []StartupItem
+	for _, source := range runKeys {
+		key, err := registry.OpenKey(source.root, source.path, registry.QUERY_VALUE|registry.WOW64_64KEY)
+		if err != nil {
+			continue
+		}
+		names, _ := key.ReadValueNames(-1)
+		for _, name := range names {
+			command, _, _ := key.GetStringValue(name)
+			startup = append(startup, StartupItem{Name: name, Command: command, Location: source.name})
+		}
+		key.Close()
+	}
+
+	var raw []struct {
+		Name     string
+		RunAs    string
+		Enabled  bool
+		Triggers string
+		Actions  string
+	}
+	err := powerShellJSON(ctx, profileTasksScript, &raw)
+	var tasks []ScheduledTask
+	for _, t := range raw {
+		tasks = append(tasks, ScheduledTask{Name: t.Name, Schedule: t.Triggers, Command: t.Actions, RunAs: t.RunAs, Enabled: t.Enabled})
+	}
+	return startup, tasks, err
+}
+
+// liveSecurity reads the antivirus products, Defender, the firewall
+// profiles and whether UAC is enabled
+func liveSecurity(ctx context.Context) SecurityPosture {
+	var posture SecurityPosture
+	var raw struct {
+		Antivirus []struct {
+			Name    string
+			Enabled bool
+		}
+		DefenderEnabled  bool
+		DefenderRealTime bool
+		Firewall         []FirewallProfile
+	}
+	if powerShellJSON(ctx, profileSecurityScript, &raw) == nil {
+		for _, av := range raw.Antivirus {
+			product := SecurityProduct{Name: av.Name, Enabled: av.Enabled, RealTime: av.Enabled}
+			if strings.Contains(av.Name, "Defender") {
+				product.Enabled, product.RealTime = raw.DefenderEnabled, raw.DefenderRealTime
+			}
+			posture.Antivirus = append(posture.Antivirus, product)
+		}
+		if len(raw.Antivirus) == 0 && raw.DefenderEnabled {
+			// Servers have no Security Center
+			posture.Antivirus = append(posture.Antivirus, SecurityProduct{Name: "Microsoft Defender Antivirus", Enabled: true, RealTime: raw.DefenderRealTime})
+		}
+		posture.Firewall = raw.Firewall
+	}
+
+	if key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Policies\System`, registry.QUERY_VALUE|registry.WOW64_64KEY); err == nil {
+		if lua, _, err := key.GetIntegerValue("EnableLUA"); err == nil {
+			posture.UAC = "disabled"
+			if lua != 0 {
+				posture.UAC = "enabled"
+			}
+		}
+		key.Close()
+	}
+	return posture
+}
+
+// powerShellJSON runs a PowerShell script printing JSON and decodes it.
+// PowerShell prints nothing for an empty result, which decodes as empty.
+func powerShellJSON(ctx context.Context, script string, v interface{}) error {
+	output, err := runProfileCommand(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	if err != nil {
+		return err
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		return fmt.Errorf("failed to parse PowerShell output: %w", err)
+	}
+	return nil
+}
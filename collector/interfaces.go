@@ -20,10 +20,31 @@ type ArtifactCollector interface {
 
 // CollectionProfile defines what artifacts to collect
 type CollectionProfile struct {
-	Extended bool          // Whether to collect extended artifacts
-	Timeout  time.Duration // Collection timeout
-	Include  []string      // Specific artifacts to include
-	Exclude  []string      // Specific artifacts to exclude
+	Extended bool               // Whether to collect extended artifacts
+	Timeout  time.Duration      // Collection timeout
+	Include  []string           // Specific artifacts to include
+	Exclude  []string           // Specific artifacts to exclude
+	ReadOnly bool               // Prefer read-only operations and skip artifacts that write to the target
+	Root     string             // Mounted image root for offline collection; empty collects from the live host
+	Custom   []EnhancedArtifact // Command artifacts from a collectors file, run after the built-ins
+	// WSLWindowsHost also collects the Windows side when running inside WSL
+	WSLWindowsHost bool
+	// CloudCredentialContent collects the content of cloud credential files,
+	// not just their metadata
+	CloudCredentialContent bool
+	// Carve scans raw image data for deleted artifacts in offline mode, from
+	// CarveSources or the image's own metafiles and page files
+	Carve        bool
+	CarveSources []string
+	// Scope overrides the default depth and directories of the file
+	// metadata collectors' walks
+	Scope WalkScope
+	// Authorized is the incident's collection scope, nil for none. With
+	// ScopeOverride, the justification given to collect outside it, every
+	// artifact is collected and the override recorded.
+	Authorized    *CollectionScope
+	ScopeIncident string
+	ScopeOverride string
 }
 
 // ArtifactResult represents the result of collecting a single artifact
@@ -34,6 +55,8 @@ type ArtifactResult struct {
 	Error      error         // Any error that occurred during collection
 	Size       int64         // Size of the collected data
 	Checksum   string        // SHA256 checksum of the data
+	Raw        []byte        // Original bytes of text data SetText re-encoded
+	Reason     ReasonCode    // Why the artifact was not collected, or not in full
 }
 
 // Artifact represents a collectable artifact
@@ -52,12 +75,14 @@ type Artifact struct {
 
 // Metadata contains information about the collection process
 type Metadata struct {
+	StartedAt   time.Time         // When collection of the artifact started
 	CollectedAt time.Time         // When the artifact was collected
 	Collector   string            // Which collector was used
-	Duration    time.Time         // How long collection took
+	Duration    time.Duration     // How long collection took
 	Source      string            // Source of the data
 	Version     string            // Version of the collector
 	Tags        map[string]string // Additional metadata tags
+	Commands    []CommandRun      // External commands run to collect the artifact
 }
 
 // BaseArtifact provides common functionality for artifacts
@@ -115,6 +140,14 @@ func (c *Collector) Collect(profile CollectionProfile) ([]ArtifactResult, error)
 	
 	var results []ArtifactResult
 	
+	// Offline mode reads from the mounted image instead of the live host
+	if profile.Root != "" {
+		c.platformCollector = NewPlatformFactory().CreateOfflineCollector(profile.Root)
+		if oc, ok := c.platformCollector.(*OfflineCollector); ok {
+			oc.scope = profile.Scope
+		}
+	}
+	
 	// Check if platform collector is available
 	if c.platformCollector == nil {
 		// Fallback to mock collector if factory failed
@@ -122,26 +155,118 @@ func (c *Collector) Collect(profile CollectionProfile) ([]ArtifactResult, error)
 		c.platformCollector = factory.CreateCollector()
 	}
 	
+	// Identify the host first so every output of the collection carries it
+	results = append(results, HostIdentityArtifact(GatherHostIdentity(profile.Root)))
+	
 	// Collect host profile
+	batchStart := time.Now()
 	if hostResult, err := c.platformCollector.CollectHostProfile(context.Background()); err == nil {
-		results = append(results, *hostResult)
+		results = append(results, recordTimings(batchStart, []ArtifactResult{*hostResult})...)
 	}
 	
 	// Collect basic artifacts
+	batchStart = time.Now()
 	if basicResults, err := c.platformCollector.CollectBasicArtifacts(context.Background()); err == nil {
-		results = append(results, basicResults...)
+		results = append(results, recordTimings(batchStart, basicResults)...)
 	}
 	
 	// Collect extended artifacts if requested
 	if profile.Extended {
+		batchStart = time.Now()
 		if extendedResults, err := c.platformCollector.CollectExtendedArtifacts(context.Background()); err == nil {
-			results = append(results, extendedResults...)
+			results = append(results, recordTimings(batchStart, extendedResults)...)
+		}
+	}
+	
+	// Carving recovers deleted artifacts from raw image data
+	if profile.Root != "" && profile.Carve {
+		batchStart = time.Now()
+		carved := CarveImage(context.Background(), CarveOptions{Root: profile.Root, Sources: profile.CarveSources})
+		results = append(results, recordTimings(batchStart, carved)...)
+	}
+	
+	// Execution evidence from the ShimCache and Amcache of a live Windows host
+	if profile.Root == "" && runtime.GOOS == "windows" && profile.permitsAny("user_activity") {
+		batchStart = time.Now()
+		execution := CollectLiveExecutionHistory(context.Background(), profile.ReadOnly)
+		results = append(results, recordTimings(batchStart, execution)...)
+	}
+	
+	// RDP history, sessions and settings, and remote access tools
+	if profile.Root == "" && runtime.GOOS == "windows" && profile.permitsAny("user_activity") {
+		batchStart = time.Now()
+		remote := CollectLiveRemoteAccess(context.Background())
+		results = append(results, recordTimings(batchStart, remote)...)
+	}
+	
+	// Autostart extension points of the registry
+	if profile.Root == "" && runtime.GOOS == "windows" && profile.permitsAny("persistence") {
+		batchStart = time.Now()
+		autostart := CollectLiveASEPs(context.Background())
+		results = append(results, recordTimings(batchStart, autostart)...)
+	}
+	
+	// Dotfiles in home directories, where Unix persistence often hides
+	if profile.Root == "" && runtime.GOOS != "windows" && profile.permitsAny("persistence") {
+		batchStart = time.Now()
+		hidden := CollectHiddenPersistence(UserProfiles(), profile.Scope)
+		results = append(results, recordTimings(batchStart, []ArtifactResult{hidden})...)
+	}
+	
+	// Cloud identity: join state, credential files, agents and Kerberos tickets
+	if profile.Root == "" && profile.permitsAny("cloud", "credentials") {
+		batchStart = time.Now()
+		cloud := CollectCloudIdentity(context.Background(), CloudOptions{CredentialContent: profile.CloudCredentialContent, Scope: profile.Scope})
+		results = append(results, recordTimings(batchStart, cloud)...)
+	}
+	
+	// Run the collectors defined in a collectors file; they need a live host
+	for _, artifact := range profile.Custom {
+		if profile.Root != "" {
+			results = append(results, ArtifactResult{Artifact: artifact.Artifact, Error: ErrLiveOnly})
+			continue
+		}
+		if ok, why := profile.Authorized.Permits(artifact.Name, artifact.Category); !ok && profile.ScopeOverride == "" {
+			results = append(results, ScopeDeniedResult(artifact.Artifact, why))
+			continue
+		}
+		results = append(results, RunCustomArtifact(context.Background(), artifact))
+	}
+	
+	// Inside WSL the Windows side is read from its system drive and
+	// through interop
+	if profile.WSLWindowsHost && profile.Root == "" {
+		if windows := CollectWSLWindowsHost(context.Background(), DetectEnvironment(), profile.Extended, profile.Scope); len(windows) > 0 {
+			results = append(results, windows...)
+			markWindowsCollected(results)
 		}
 	}
 	
+	// Batches gather several artifacts at once, so the ones the scope
+	// denies are dropped here, before they reach any output
+	if profile.Authorized != nil {
+		var record ScopeRecord
+		results, record = ApplyScope(results, profile.Authorized, profile.ScopeIncident, profile.ScopeOverride)
+		results = append(results, CollectionScopeArtifact(record))
+	}
+	
 	return results, nil
 }
 
+// permitsAny reports whether the collection scope authorizes any of the
+// scope categories, so a batch that only produces those is worth running
+func (p CollectionProfile) permitsAny(categories ...string) bool {
+	if p.Authorized == nil || p.ScopeOverride != "" {
+		return true
+	}
+	for _, category := range categories {
+		if p.Authorized.PermitsCategory(category) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetPlatformCollector sets the platform-specific collector
 func (c *Collector) SetPlatformCollector(collector ArtifactCollector) {
 	c.platformCollector = collector
@@ -0,0 +1,565 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrLiveOnly marks artifacts that only exist on a running host, such as
+// processes and network connections, when collecting from a disk image
+var ErrLiveOnly = errors.New("unavailable in offline mode: artifact requires a live host")
+
+// liveOnlyArtifacts are the artifacts that cannot be read from a disk image
+var liveOnlyArtifacts = []struct {
+	name, description, category string
+}{
+	{"running_processes", "Currently running processes", "process"},
+	{"network_connections", "Active network connections", "network"},
+	{"arp_cache", "ARP cache", "network"},
+	{"dns_cache", "DNS resolver cache", "network"},
+	{"logged_on_users", "Currently logged on users", "user"},
+}
+
+// offlineFileArtifacts are image files collected as-is, by image OS. Paths are
+// relative to the image root and may contain globs.
+var offlineFileArtifacts = map[string][]struct {
+	name, description, category, pattern string
+}{
+	"windows": {
+		{"registry_system", "SYSTEM registry hive", "registry", "Windows/System32/config/SYSTEM"},
+		{"registry_software", "SOFTWARE registry hive", "registry", "Windows/System32/config/SOFTWARE"},
+		{"registry_sam", "SAM registry hive", "registry", "Windows/System32/config/SAM"},
+		{"registry_security", "SECURITY registry hive", "registry", "Windows/System32/config/SECURITY"},
+		{"registry_ntuser", "User registry hive", "registry", "Users/*/NTUSER.DAT"},
+		{"eventlog_security", "Security event log", "log", "Windows/System32/winevt/Logs/Security.evtx"},
+		{"eventlog_system", "System event log", "log", "Windows/System32/winevt/Logs/System.evtx"},
+		{"eventlog_application", "Application event log", "log", "Windows/System32/winevt/Logs/Application.evtx"},
+		{"eventlog_powershell", "PowerShell operational event log", "log", "Windows/System32/winevt/Logs/Microsoft-Windows-PowerShell%4Operational.evtx"},
+		{"eventlog_defender", "Windows Defender operational event log", "log", "Windows/System32/winevt/Logs/Microsoft-Windows-Windows Defender%4Operational.evtx"},
+		{"firewall_log", "Windows Firewall log", "log", "Windows/System32/LogFiles/Firewall/pfirewall.log"},
+		{"setupapi_log", "Device installation log", "log", "Windows/INF/setupapi.dev.log"},
+		{"browser_chrome", "Chrome browsing history database", "browser", "Users/*/AppData/Local/Google/Chrome/User Data/Default/History"},
+		{"browser_edge", "Edge browsing history database", "browser", "Users/*/AppData/Local/Microsoft/Edge/User Data/Default/History"},
+		{"browser_firefox", "Firefox browsing history database", "browser", "Users/*/AppData/Roaming/Mozilla/Firefox/Profiles/*/places.sqlite"},
+	},
+	"linux": {
+		{"passwd", "Local accounts", "user", "etc/passwd"},
+		{"group", "Local groups", "user", "etc/group"},
+		{"crontab", "System crontab", "task", "etc/crontab"},
+		{"auth_log", "Authentication log", "log", "var/log/auth.log"},
+		{"secure_log", "Authentication log", "log", "var/log/secure"},
+		{"syslog", "System log", "log", "var/log/syslog"},
+		{"messages_log", "System log", "log", "var/log/messages"},
+		{"wtmp", "Login records", "log", "var/log/wtmp"},
+		{"bash_history", "User shell history", "user", "home/*/.bash_history"},
+		{"root_bash_history", "Root shell history", "user", "root/.bash_history"},
+		{"browser_chrome", "Chrome browsing history database", "browser", "home/*/.config/google-chrome/Default/History"},
+		{"browser_firefox", "Firefox browsing history database", "browser", "home/*/.mozilla/firefox/*/places.sqlite"},
+	},
+}
+
+// offlineListings are image directories whose file metadata is collected,
+// by image OS, with the default scope of the walk below each
+var offlineListings = map[string][]struct {
+	name, description, category, dir string
+	extended                         bool
+	scope                            WalkScope
+}{
+	"windows": {
+		{"user_profiles", "User profile directories", "user", "Users", false, WalkScope{MaxDepth: 1}},
+		{"prefetch", "Prefetch file metadata", "trace", "Windows/Prefetch", true, WalkScope{MaxDepth: 1}},
+		{"scheduled_tasks", "Scheduled task definitions", "task", "Windows/System32/Tasks", true, WalkScope{MaxDepth: 4}},
+		{"startup_folder", "All-users startup folder", "autorun", "ProgramData/Microsoft/Windows/Start Menu/Programs/StartUp", true, WalkScope{MaxDepth: 2}},
+	},
+	"linux": {
+		{"user_profiles", "User home directories", "user", "home", false, WalkScope{MaxDepth: 1}},
+		{"cron_jobs", "Cron job definitions", "task", "etc/cron.d", true, WalkScope{MaxDepth: 1}},
+		{"systemd_units", "Locally installed systemd units", "service", "etc/systemd/system", true, WalkScope{MaxDepth: 2}},
+		{"tmp_files", "Temporary file metadata", "file", "tmp", true, WalkScope{MaxDepth: 2, Deny: []string{".X11-unix", ".ICE-unix", "systemd-private-*"}}},
+		{"docker_containers", "Docker container state directories", ContainerCategory, "var/lib/docker/containers", true, WalkScope{MaxDepth: 2, Deny: []string{"*/mounts"}}},
+	},
+}
+
+// ResolveRootPath maps a live path onto a mounted image root. Windows drive
+// letters are dropped so C:\Windows\Prefetch becomes <root>/Windows/Prefetch.
+// When the path does not exist with that exact case, as on a Windows image
+// mounted on a case-sensitive filesystem, the image entry that differs only
+// in case is used. An empty root returns the live path unchanged.
+func ResolveRootPath(root, livePath string) string {
+	if root == "" {
+		return livePath
+	}
+
+	path := livePath
+	if len(path) >= 2 && path[1] == ':' {
+		path = path[2:]
+	}
+	path = strings.Trim(strings.ReplaceAll(path, `\`, "/"), "/")
+
+	exact := filepath.Join(root, filepath.FromSlash(path))
+	if _, err := os.Lstat(exact); err == nil || path == "" {
+		return exact
+	}
+	if matches := imagePathFold(root, path); len(matches) > 0 {
+		return matches[0]
+	}
+	return exact
+}
+
+// imageGlob returns the sorted paths below root matching a slash-separated
+// glob pattern. With foldCase each path component is matched without regard
+// to case: NTFS is case-insensitive, so a Windows image mounted on a
+// case-sensitive filesystem may store WINDOWS/system32/config/SYSTEM where
+// Windows/System32/config/SYSTEM is looked up.
+func imageGlob(root, pattern string, foldCase bool) []string {
+	if !foldCase {
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		sort.Strings(matches)
+		return matches
+	}
+	return walkImage(root, pattern, func(component, name string) bool {
+		ok, _ := filepath.Match(strings.ToLower(component), strings.ToLower(name))
+		return ok
+	})
+}
+
+// imagePathFold returns the paths below root that equal a slash-separated
+// literal path except for case
+func imagePathFold(root, path string) []string {
+	return walkImage(root, path, strings.EqualFold)
+}
+
+// walkImage descends from root one path component at a time, following
+// every directory entry that matches the component
+func walkImage(root, path string, match func(component, name string) bool) []string {
+	paths := []string{root}
+	for _, component := range strings.Split(path, "/") {
+		var next []string
+		for _, dir := range paths {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if match(component, entry.Name()) {
+					next = append(next, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		paths = next
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// DetectImageOS identifies the operating system of a mounted image
+func DetectImageOS(root string) string {
+	for _, path := range imageGlob(root, "Windows/System32", true) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return "windows"
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "etc", "os-release")); err == nil {
+		return "linux"
+	}
+	if info, err := os.Stat(filepath.Join(root, "etc")); err == nil && info.IsDir() {
+		return "linux"
+	}
+	return "unknown"
+}
+
+// OfflineCollector collects artifacts from a mounted disk image instead of
+// the live host. Live-only artifacts are reported as unavailable.
+type OfflineCollector struct {
+	root    string
+	imageOS string
+	version string
+	// scope overrides the default scope of every directory walk
+	scope WalkScope
+}
+
+// NewOfflineCollector creates a collector reading from the image mounted at root
+func NewOfflineCollector(root string) *OfflineCollector {
+	return &OfflineCollector{
+		root:    root,
+		imageOS: DetectImageOS(root),
+		version: "1.0.0",
+	}
+}
+
+// CollectHostProfile describes the image being analyzed
+func (oc *OfflineCollector) CollectHostProfile(ctx context.Context) (*ArtifactResult, error) {
+	artifact := NewBaseArtifact("host_profile", "Offline image profile", "host", "image")
+	artifact.Artifact.Platform = oc.imageOS
+
+	profileData := map[string]interface{}{
+		"hostname":        oc.imageHostname(),
+		"platform":        oc.imageOS,
+		"mode":            "offline",
+		"image_root":      oc.root,
+		"collection_time": time.Now().Format(time.RFC3339),
+	}
+
+	result := oc.newResult(artifact.Artifact, profileData, 0)
+	return &result, nil
+}
+
+// CollectBasicArtifacts reports live-only artifacts as unavailable and
+// collects registry hives, logs and user profiles from the image
+func (oc *OfflineCollector) CollectBasicArtifacts(ctx context.Context) ([]ArtifactResult, error) {
+	var results []ArtifactResult
+
+	for _, live := range liveOnlyArtifacts {
+		artifact := NewBaseArtifact(live.name, live.description, live.category, "command").Artifact
+		results = append(results, oc.unavailable(artifact))
+	}
+
+	for _, file := range offlineFileArtifacts[oc.imageOS] {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		results = append(results, oc.collectFiles(file.name, file.description, file.category, file.pattern)...)
+	}
+
+	if oc.imageOS == "windows" {
+		results = append(results, oc.collectExecutionHistory()...)
+		if autostart, ok := oc.collectASEPs(); ok {
+			results = append(results, autostart)
+		}
+	}
+	if oc.imageOS == "linux" {
+		results = append(results, oc.collectHiddenPersistence())
+	}
+
+	for _, listing := range offlineListings[oc.imageOS] {
+		if !listing.extended {
+			results = append(results, oc.collectListing(listing.name, listing.description, listing.category, listing.dir, listing.scope))
+		}
+	}
+
+	return results, nil
+}
+
+// CollectExtendedArtifacts collects file metadata for execution and
+// persistence locations in the image
+func (oc *OfflineCollector) CollectExtendedArtifacts(ctx context.Context) ([]ArtifactResult, error) {
+	var results []ArtifactResult
+
+	for _, listing := range offlineListings[oc.imageOS] {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		if listing.extended {
+			results = append(results, oc.collectListing(listing.name, listing.description, listing.category, listing.dir, listing.scope))
+		}
+	}
+
+	if oc.imageOS == "windows" {
+		if tasks, ok := oc.collectTaskDefinitions(); ok {
+			results = append(results, tasks)
+		}
+	}
+
+	return results, nil
+}
+
+// collectTaskDefinitions reads the scheduled task XML definitions from the
+// image. It reports false when the image has no task directory.
+func (oc *OfflineCollector) collectTaskDefinitions() (ArtifactResult, bool) {
+	dir := oc.imageDir("Windows/System32/Tasks")
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return ArtifactResult{}, false
+	}
+
+	artifact := NewBaseArtifact("scheduled_task_xml", "Scheduled task XML definitions", "task", ScheduledTaskXMLType).Artifact
+	artifact.Platform = oc.imageOS
+	artifact.Parameters["path"] = oc.imagePath(dir)
+
+	data, skipped, err := ReadTaskDefinitions(dir, oc.scope)
+	result := oc.newResult(artifact, data, int64(len(data)))
+	taskDefinitionScope.Override(oc.scope).record(result.Artifact.Parameters, walkCounts{})
+	if err != nil {
+		result.Fail(ReasonFor(err), err)
+	}
+	if skipped > 0 {
+		result.Metadata.Tags["unreadable"] = fmt.Sprintf("%d task files", skipped)
+	}
+	return result, true
+}
+
+// collectExecutionHistory parses the ShimCache of the image's SYSTEM hive
+// and its Amcache.hve. Neither is reported when the image lacks the file.
+func (oc *OfflineCollector) collectExecutionHistory() []ArtifactResult {
+	system := oc.imageDir("Windows/System32/config/SYSTEM")
+	amcache := oc.imageDir("Windows/AppCompat/Programs/Amcache.hve")
+
+	paths := []string{system, amcache}
+	var results []ArtifactResult
+	for i, result := range CollectExecutionHistory(system, amcache, oc.imageOS) {
+		if _, err := os.Stat(paths[i]); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		result.Artifact.Parameters["path"] = oc.imagePath(paths[i])
+		result.Metadata.Collector = "offline"
+		result.Metadata.Source = oc.root
+		result.Metadata.Tags["mode"] = "offline"
+		results = append(results, result)
+	}
+	return results
+}
+
+// collectASEPs sweeps the autostart locations of the image's SOFTWARE and
+// SYSTEM hives and of the NTUSER.DAT and UsrClass.dat of every profile. It
+// reports false when the image has neither machine hive.
+func (oc *OfflineCollector) collectASEPs() (ArtifactResult, bool) {
+	const systemRoot = `C:\Windows`
+	machineEnv := asepEnvironment("C:", systemRoot, "")
+	machine := []struct{ kind, path string }{
+		{hiveSoftware, oc.imageDir("Windows/System32/config/SOFTWARE")},
+		{hiveSystem, oc.imageDir("Windows/System32/config/SYSTEM")},
+	}
+
+	var hives []asepHive
+	var failures []string
+	found := false
+	for _, file := range machine {
+		if _, err := os.Stat(file.path); err != nil {
+			continue
+		}
+		found = true
+		hive, err := openASEPHive(file.kind, `HKLM\`+file.kind, "", file.path, machineEnv)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		hives = append(hives, hive)
+	}
+	if !found {
+		return ArtifactResult{}, false
+	}
+
+	for _, profile := range imageGlob(oc.root, "Users/*", true) {
+		user := filepath.Base(profile)
+		env := asepEnvironment("C:", systemRoot, `C:\Users\`+user)
+		users := []struct{ kind, name, pattern string }{
+			{hiveUser, `HKU\` + user, "Users/" + user + "/NTUSER.DAT"},
+			{hiveClasses, `HKU\` + user + "_Classes", "Users/" + user + "/AppData/Local/Microsoft/Windows/UsrClass.dat"},
+		}
+		for _, file := range users {
+			for _, path := range imageGlob(oc.root, file.pattern, true) {
+				hive, err := openASEPHive(file.kind, file.name, user, path, env)
+				if err != nil {
+					failures = append(failures, err.Error())
+					continue
+				}
+				hives = append(hives, hive)
+			}
+		}
+	}
+
+	entries := sweepASEPs(hives)
+	enrichASEPs(entries, systemRoot, func(path string) string {
+		file := ResolveRootPath(oc.root, path)
+		if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+			return file
+		}
+		return ""
+	})
+
+	result := ASEPArtifact(entries)
+	result.Artifact.Platform = oc.imageOS
+	result.Artifact.Parameters["path"] = oc.imagePath(oc.imageDir("Windows/System32/config"))
+	result.Metadata.Collector = "offline"
+	result.Metadata.Version = oc.version
+	result.Metadata.Source = oc.root
+	result.Metadata.Tags["mode"] = "offline"
+	if len(failures) > 0 && len(hives) == 0 {
+		err := fmt.Errorf("failed to sweep autostart entries: %s", strings.Join(failures, "; "))
+		result.Fail(ReasonFor(err), err)
+	} else if len(failures) > 0 {
+		result.Metadata.Tags["unreadable"] = strings.Join(failures, "; ")
+	}
+	return result, true
+}
+
+// collectHiddenPersistence reads the hidden persistence files in the home
+// directories the image's /etc/passwd lists and those under /home
+func (oc *OfflineCollector) collectHiddenPersistence() ArtifactResult {
+	profiles := make(map[string]string)
+	for _, line := range readLines(filepath.Join(oc.root, "etc", "passwd")) {
+		fields := strings.Split(line, ":")
+		if len(fields) < 6 || strings.Trim(fields[5], "/") == "" {
+			continue
+		}
+		profiles[oc.imageDir(strings.Trim(fields[5], "/"))] = fields[0]
+	}
+	homes, _ := filepath.Glob(filepath.Join(oc.root, "home", "*"))
+	for _, home := range homes {
+		if _, ok := profiles[home]; !ok {
+			profiles[home] = filepath.Base(home)
+		}
+	}
+
+	result := collectHiddenPersistence(profiles, oc.scope, false)
+	files := HiddenPersistenceFiles(result)
+	for i := range files {
+		files[i].Path = oc.imagePath(files[i].Path)
+		files[i].Home = oc.imagePath(files[i].Home)
+	}
+	result.Artifact.Platform = oc.imageOS
+	result.Metadata.Collector = "offline"
+	result.Metadata.Source = oc.root
+	result.Metadata.Tags["mode"] = "offline"
+	return result
+}
+
+// collectFiles returns a file artifact for every image file matching pattern.
+// Files are copied into the bundle as-is.
+func (oc *OfflineCollector) collectFiles(name, description, category, pattern string) []ArtifactResult {
+	matches := imageGlob(oc.root, pattern, oc.imageOS == "windows")
+
+	var results []ArtifactResult
+	used := make(map[string]bool)
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		artifactName := name
+		if len(matches) > 1 {
+			// One artifact per user profile, e.g. registry_ntuser_alice,
+			// numbered when a user has several, e.g. Firefox profiles
+			rel, _ := filepath.Rel(oc.root, path)
+			parts := strings.Split(filepath.ToSlash(rel), "/")
+			if len(parts) > 1 {
+				artifactName = fmt.Sprintf("%s_%s", name, parts[1])
+			}
+			for base, n := artifactName, 2; used[artifactName]; n++ {
+				artifactName = fmt.Sprintf("%s_%d", base, n)
+			}
+		}
+		used[artifactName] = true
+
+		artifact := NewBaseArtifact(artifactName, description, category, "file").Artifact
+		artifact.Platform = oc.imageOS
+		artifact.Parameters["path"] = path
+		artifact.Parameters["image_path"] = oc.imagePath(path)
+
+		result := oc.newResult(artifact, nil, info.Size())
+		result.Metadata.Tags["modified_at"] = info.ModTime().UTC().Format(time.RFC3339)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// collectListing records the metadata of the entries in an image directory
+// and the directories below it, as far as scope reaches
+func (oc *OfflineCollector) collectListing(name, description, category, dir string, scope WalkScope) ArtifactResult {
+	artifact := NewBaseArtifact(name, description, category, "listing").Artifact
+	artifact.Platform = oc.imageOS
+	artifact.Parameters["path"] = "/" + dir
+	scope = scope.Override(oc.scope)
+
+	var listing strings.Builder
+	fmt.Fprintf(&listing, "=== %s ===\n", "/"+dir)
+	count := 0
+	counts, err := walkScoped(oc.imageDir(dir), scope, func(path, rel string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(&listing, "%s\t%d\t%s\t%s\n",
+			info.Mode(), info.Size(), info.ModTime().UTC().Format(time.RFC3339), rel)
+		count++
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		result := oc.newResult(artifact, fmt.Sprintf("=== %s ===\nnot present in image\n", "/"+dir), 0)
+		result.Reason = ReasonNotPresent
+		return result
+	}
+	if err != nil {
+		result := oc.newResult(artifact, "", 0)
+		result.Fail(ReasonFor(err), fmt.Errorf("failed to read %s from image: %w", "/"+dir, err))
+		return result
+	}
+	fmt.Fprintf(&listing, "\nTotal entries: %d\n", count)
+
+	result := oc.newResult(artifact, listing.String(), int64(listing.Len()))
+	scope.record(result.Artifact.Parameters, counts)
+	return result
+}
+
+// unavailable marks a live-only artifact as not collectable from an image
+func (oc *OfflineCollector) unavailable(artifact Artifact) ArtifactResult {
+	artifact.Volatile = true
+	artifact.Platform = oc.imageOS
+
+	result := oc.newResult(artifact, ErrLiveOnly.Error(), 0)
+	result.Error = ErrLiveOnly
+	result.Metadata.Tags["availability"] = "unavailable_offline"
+	return result
+}
+
+// imageHostname reads the hostname recorded in the image when it is stored
+// as plain text
+func (oc *OfflineCollector) imageHostname() string {
+	if oc.imageOS == "linux" {
+		if data, err := os.ReadFile(filepath.Join(oc.root, "etc", "hostname")); err == nil {
+			if hostname := strings.TrimSpace(string(data)); hostname != "" {
+				return hostname
+			}
+		}
+	}
+	return "unknown"
+}
+
+// imageDir returns the image directory at a slash-separated path, matched
+// without regard to case on Windows images
+func (oc *OfflineCollector) imageDir(dir string) string {
+	if oc.imageOS == "windows" {
+		if matches := imagePathFold(oc.root, dir); len(matches) > 0 {
+			return matches[0]
+		}
+	}
+	return filepath.Join(oc.root, filepath.FromSlash(dir))
+}
+
+// imagePath returns a path as it appears inside the image
+func (oc *OfflineCollector) imagePath(path string) string {
+	rel, err := filepath.Rel(oc.root, path)
+	if err != nil {
+		return path
+	}
+	return "/" + filepath.ToSlash(rel)
+}
+
+func (oc *OfflineCollector) newResult(artifact Artifact, data interface{}, size int64) ArtifactResult {
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     data,
+		Metadata: Metadata{
+			CollectedAt: time.Now(),
+			Collector:   "offline",
+			Version:     oc.version,
+			Source:      oc.root,
+			Tags:        map[string]string{"mode": "offline"},
+		},
+		Size: size,
+	}
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testImageFiles are the artifacts the miniature Windows image in
+// testdata/image must yield. Its paths differ in case from the ones the
+// offline collector looks up, as on an NTFS image mounted on Linux.
+var testImageFiles = []string{
+	"registry_system",
+	"registry_software",
+	"registry_ntuser",
+	"eventlog_security",
+	"browser_chrome",
+	"browser_firefox_Alice",
+	"browser_firefox_Alice_2",
+}
+
+// testImage copies the miniature Windows image to a temporary directory,
+// so tests can add to it, and returns its root
+func testImage(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join("testdata", "image")
+	root := filepath.Join(t.TempDir(), "image")
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy the test image: %v", err)
+	}
+	return root
+}
+
+func TestOfflineCollectorCollectsImage(t *testing.T) {
+	root := testImage(t)
+	if imageOS := DetectImageOS(root); imageOS != "windows" {
+		t.Fatalf("image detected as %s, want windows", imageOS)
+	}
+
+	oc := NewOfflineCollector(root)
+	results, err := oc.CollectBasicArtifacts(context.Background())
+	if err != nil {
+		t.Fatalf("CollectBasicArtifacts: %v", err)
+	}
+	extended, err := oc.CollectExtendedArtifacts(context.Background())
+	if err != nil {
+		t.Fatalf("CollectExtendedArtifacts: %v", err)
+	}
+	results = append(results, extended...)
+
+	byName := make(map[string]ArtifactResult)
+	liveOnly := 0
+	for _, result := range results {
+		byName[result.Artifact.Name] = result
+		if errors.Is(result.Error, ErrLiveOnly) {
+			liveOnly++
+		}
+	}
+	for _, name := range testImageFiles {
+		if result, ok := byName[name]; !ok || result.Error != nil {
+			t.Errorf("artifact %s not collected from the image", name)
+		}
+	}
+	if _, ok := byName["running_processes"]; !ok || liveOnly == 0 {
+		t.Error("live-only artifacts not marked unavailable")
+	}
+	if prefetch, _ := byName["prefetch"].Data.(string); !strings.Contains(prefetch, "Total entries: 2") {
+		t.Errorf("prefetch listing of WINDOWS/prefetch is wrong:\n%s", prefetch)
+	}
+}
+
+func TestResolveRootPathIgnoresCase(t *testing.T) {
+	root := testImage(t)
+	want := filepath.Join(root, "WINDOWS", "prefetch")
+	if got := ResolveRootPath(root, `C:\Windows\Prefetch`); got != want {
+		t.Errorf(`C:\Windows\Prefetch resolved to %s, want %s`, got, want)
+	}
+}
+
+func TestHostIdentityOfImageIsOffline(t *testing.T) {
+	identity := GatherHostIdentity(testImage(t))
+	if !identity.Offline() || identity.Platform != "windows" {
+		t.Errorf("host identity of the image is not marked offline: %+v", identity)
+	}
+}
@@ -0,0 +1,350 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/utils"
+)
+
+// Host roles that make collecting from a live host sensitive
+const (
+	RoleDomainController       = "domain-controller"
+	RoleServer                 = "server"
+	RoleKubernetesControlPlane = "kubernetes-control-plane"
+)
+
+// Size and speed assumptions behind a collection plan's estimates
+const (
+	estimatedCommandBytes = 256 << 10 // output of one command artifact
+	estimatedListingBytes = 16 << 10  // one directory listing
+	estimatedCarveBytes   = 1 << 20   // carved records of one carve run
+	estimatedCaptureRate  = 1 << 20   // packet capture bytes per second
+	fastCopyRate          = 200 << 20 // bytes per second copied from local disk
+	slowCopyRate          = 20 << 20  // the same from a slow or busy disk
+	fastScanRate          = 500 << 20 // bytes per second scanned by --carve
+	slowScanRate          = 50 << 20
+	commandTimeBound      = 10 * time.Second // slowest expected built-in command
+)
+
+// DetectHostRoles returns the roles of the live host that a sensitive host
+// pattern can name
+func DetectHostRoles() []string {
+	return liveHostRoles()
+}
+
+// SensitiveHostMatch returns the pattern that marks a host sensitive, or ""
+// for none. A pattern is a hostname glob, matched against the full and the
+// short name without regard to case, or role:<role> for a detected role.
+func SensitiveHostMatch(patterns []string, hostname string, roles []string) string {
+	short, _, _ := strings.Cut(hostname, ".")
+	for _, pattern := range patterns {
+		if role, ok := strings.CutPrefix(pattern, "role:"); ok {
+			for _, r := range roles {
+				if strings.EqualFold(r, role) {
+					return pattern
+				}
+			}
+			continue
+		}
+		for _, name := range []string{hostname, short} {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+				return pattern
+			}
+		}
+	}
+	return ""
+}
+
+// CollectionPlan is what a collection will do to the target host, shown for
+// confirmation before it starts
+type CollectionPlan struct {
+	Profile        string        `json:"profile"`
+	Host           string        `json:"host"`
+	HostRoles      []string      `json:"host_roles,omitempty"`
+	Artifacts      int           `json:"artifacts"`
+	Commands       []string      `json:"commands"`
+	EstimatedBytes int64         `json:"estimated_bytes"`
+	Destination    string        `json:"destination"`
+	MinDuration    time.Duration `json:"min_duration"`
+	MaxDuration    time.Duration `json:"max_duration"`
+	Elevation      string        `json:"elevation"`
+	// Sensitive is the sensitive host pattern the host matched
+	Sensitive string `json:"sensitive,omitempty"`
+	// Scope is the incident's collection scope and ScopeOverride the
+	// justification for collecting outside it
+	Scope         *CollectionScope `json:"scope,omitempty"`
+	ScopeIncident string           `json:"scope_incident,omitempty"`
+	ScopeOverride string           `json:"scope_override,omitempty"`
+}
+
+// PlanOptions are the parts of a collection that are not in its profile
+type PlanOptions struct {
+	Destination string
+	// Capture is the packet capture to run alongside, when its duration is
+	// set
+	Capture CaptureOptions
+}
+
+// PlanCollection works out what collecting with profile will run and
+// produce, without touching the target beyond looking up file sizes and
+// tools. Sizes and durations are estimates.
+func PlanCollection(profile CollectionProfile, opts PlanOptions) CollectionPlan {
+	plan := CollectionPlan{
+		Profile:       "standard",
+		Destination:   opts.Destination,
+		Artifacts:     2, // host identity and host profile
+		Scope:         profile.Authorized,
+		ScopeIncident: profile.ScopeIncident,
+		ScopeOverride: profile.ScopeOverride,
+	}
+	if profile.Extended {
+		plan.Profile = "extended"
+	}
+
+	var copied, scanned int64
+	var commandTime time.Duration
+	if profile.Root != "" {
+		plan.Profile += " (offline image)"
+		plan.Host = GatherHostIdentity(profile.Root).Hostname
+		plan.Elevation = "none: reads the mounted image"
+
+		oc := NewOfflineCollector(profile.Root)
+		count, size := oc.plannedArtifacts(profile.Extended)
+		plan.Artifacts += count
+		copied += size
+		if profile.Carve {
+			plan.Profile += " with carving"
+			carve := CarveOptions{Root: profile.Root, Sources: profile.CarveSources}
+			for _, source := range carveSourcePaths(carve, oc.imageOS) {
+				if info, err := os.Stat(source); err == nil {
+					scanned += info.Size()
+				}
+			}
+			plan.Artifacts += 2
+			copied += estimatedCarveBytes
+		}
+	} else {
+		plan.Host, _ = os.Hostname()
+		plan.HostRoles = DetectHostRoles()
+		plan.Elevation = "none requested: running without administrator rights, so protected artifacts will fail"
+		if utils.HasAdminPrivileges() {
+			plan.Elevation = "none requested: already running with administrator rights"
+		}
+
+		count := 0
+		if planner, ok := NewPlatformFactory().CreateCollector().(artifactPlanner); ok {
+			count, _ = planner.plannedArtifacts(profile.Extended)
+		}
+		plan.Artifacts += count
+		copied += int64(count) * estimatedCommandBytes
+
+		var cloud []string
+		if profile.permitsAny("cloud", "credentials") {
+			cloud = cloudCommands()
+		}
+		plan.Commands = append(plan.Commands, cloud...)
+		cloudArtifacts := 0
+		if len(cloud) > 0 {
+			cloudArtifacts = 2 // credential files and agents
+		}
+		for _, command := range cloud {
+			if command == "dsregcmd /status" || command == "klist" {
+				cloudArtifacts++
+			}
+		}
+		plan.Artifacts += cloudArtifacts
+		copied += int64(cloudArtifacts) * estimatedCommandBytes
+		commandTime += time.Duration(len(cloud)) * commandTimeBound
+
+		for _, artifact := range profile.Custom {
+			plan.Commands = append(plan.Commands, strings.Join(artifact.Command, " "))
+			plan.Artifacts++
+			copied += estimatedCommandBytes
+			commandTime += artifact.Timeout
+		}
+
+		if profile.WSLWindowsHost {
+			if env := DetectEnvironment(); env.WindowsRoot != "" {
+				count, size := NewOfflineCollector(env.WindowsRoot).plannedArtifacts(profile.Extended)
+				plan.Artifacts += count + 1
+				copied += size
+			}
+			for _, c := range wslInteropCommands {
+				plan.Commands = append(plan.Commands, strings.Join(c.command, " ")+" (WSL interop)")
+				commandTime += commandTimeBound
+			}
+		}
+	}
+
+	var capture time.Duration
+	if opts.Capture.Duration > 0 {
+		capture = opts.Capture.Duration
+		tool, _, err := findCaptureTool(opts.Capture)
+		if err != nil {
+			tool = "no capture tool found"
+		}
+		plan.Commands = append(plan.Commands, fmt.Sprintf("%s packet capture for %s", tool, capture))
+		plan.Artifacts++
+		copied += int64(capture.Seconds()) * estimatedCaptureRate
+	}
+
+	plan.EstimatedBytes = copied
+	plan.MinDuration = (2*time.Second + capture + rateDuration(copied, fastCopyRate) + rateDuration(scanned, fastScanRate)).Round(time.Second)
+	plan.MaxDuration = (30*time.Second + capture + commandTime + rateDuration(copied, slowCopyRate) + rateDuration(scanned, slowScanRate)).Round(time.Second)
+	return plan
+}
+
+// Summary renders the plan as the lines shown for confirmation
+func (p CollectionPlan) Summary() []string {
+	host := p.Host
+	if len(p.HostRoles) > 0 {
+		host += " (" + strings.Join(p.HostRoles, ", ") + ")"
+	}
+	lines := []string{
+		"Host:               " + host,
+		"Profile:            " + p.Profile,
+		fmt.Sprintf("Artifacts:          %d", p.Artifacts),
+	}
+	if len(p.Commands) == 0 {
+		lines = append(lines, "External commands:  none")
+	} else {
+		lines = append(lines, fmt.Sprintf("External commands:  %d", len(p.Commands)))
+		for _, command := range p.Commands {
+			lines = append(lines, "  - "+command)
+		}
+	}
+	lines = append(lines,
+		"Estimated output:   about "+formatPlanBytes(p.EstimatedBytes)+" before compression",
+		"Destination:        "+p.Destination,
+		fmt.Sprintf("Expected duration:  %s to %s", p.MinDuration, p.MaxDuration),
+		"Elevation:          "+p.Elevation,
+	)
+	if p.Sensitive != "" {
+		lines = append(lines, "Sensitive host:     matches "+p.Sensitive)
+	}
+	if p.Scope != nil {
+		scope := p.Scope.String()
+		if p.ScopeIncident != "" {
+			scope += " (incident " + p.ScopeIncident + ")"
+		}
+		lines = append(lines, "Collection scope:   "+scope)
+		if p.Scope.Statement != "" {
+			lines = append(lines, "  Authorization:    "+p.Scope.Statement)
+		}
+		if p.ScopeOverride != "" {
+			lines = append(lines, "  OVERRIDDEN:       "+p.ScopeOverride)
+		} else {
+			lines = append(lines, "  Artifacts outside the scope are skipped and recorded")
+		}
+	}
+	return lines
+}
+
+// cloudCommands are the commands the cloud identity collection runs on this
+// platform
+func cloudCommands() []string {
+	var services []string
+	for _, agent := range cloudAgents {
+		if runtime.GOOS == "windows" {
+			services = append(services, agent.windowsServices...)
+		} else {
+			services = append(services, agent.linuxUnits...)
+		}
+	}
+	sort.Strings(services)
+
+	var commands []string
+	if runtime.GOOS == "windows" {
+		commands = append(commands, "dsregcmd /status")
+		for _, service := range services {
+			commands = append(commands, "sc query "+service)
+		}
+	} else {
+		for _, unit := range services {
+			commands = append(commands, "systemctl show --property=LoadState,ActiveState "+unit)
+		}
+	}
+	if utils.IsToolAvailable("klist") || runtime.GOOS == "windows" {
+		commands = append(commands, "klist")
+	}
+	return commands
+}
+
+// formatPlanBytes renders a byte count with a binary unit
+func formatPlanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// rateDuration is how long n bytes take at rate bytes per second
+func rateDuration(n, rate int64) time.Duration {
+	return time.Duration(float64(n) / float64(rate) * float64(time.Second))
+}
+
+// artifactPlanner is implemented by collectors that can count the artifacts
+// they would collect, and the bytes they would copy, without collecting
+type artifactPlanner interface {
+	plannedArtifacts(extended bool) (int, int64)
+}
+
+// plannedArtifacts counts the artifacts a collection from the image yields
+// and the size of the image files it copies
+func (oc *OfflineCollector) plannedArtifacts(extended bool) (int, int64) {
+	count := len(liveOnlyArtifacts)
+	var size int64
+	for _, file := range offlineFileArtifacts[oc.imageOS] {
+		for _, path := range imageGlob(oc.root, file.pattern, oc.imageOS == "windows") {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				count++
+				size += info.Size()
+			}
+		}
+	}
+	for _, listing := range offlineListings[oc.imageOS] {
+		if extended || !listing.extended {
+			count++
+			size += estimatedListingBytes
+		}
+	}
+	if extended && oc.imageOS == "windows" {
+		count++
+		size += estimatedListingBytes
+	}
+	if oc.imageOS == "windows" {
+		for _, hive := range []string{"SOFTWARE", "SYSTEM"} {
+			if _, err := os.Stat(oc.imageDir("Windows/System32/config/" + hive)); err == nil {
+				count++
+				size += estimatedListingBytes
+				break
+			}
+		}
+	}
+	if oc.imageOS == "linux" {
+		count++
+		size += estimatedListingBytes
+	}
+	return count, size
+}
+
+// plannedArtifacts counts the artifacts the built-in live collector returns
+func (mc *MockCollector) plannedArtifacts(extended bool) (int, int64) {
+	count := 2
+	if extended {
+		count++
+	}
+	return count, int64(count) * estimatedCommandBytes
+}
@@ -27,6 +27,11 @@ func (pf *PlatformFactory) CreateCollector() ArtifactCollector {
 	}
 }
 
+// CreateOfflineCollector creates a collector for a disk image mounted at root
+func (pf *PlatformFactory) CreateOfflineCollector(root string) ArtifactCollector {
+	return NewOfflineCollector(root)
+}
+
 // createWindowsCollector creates a Windows-specific collector
 func (pf *PlatformFactory) createWindowsCollector() ArtifactCollector {
 	// Create a Windows collector with basic functionality
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxRecordedStderr bounds the standard error kept with a command run
+const maxRecordedStderr = 4 << 10
+
+// CommandRun records an external command a collector ran: exactly what was
+// run, how it exited and what it wrote to standard error. Empty tells a run
+// that succeeded without output from one that failed.
+type CommandRun struct {
+	Args       []string  `json:"args"`
+	ExitCode   int       `json:"exit_code"` // -1 when the command did not start or was killed
+	Stderr     string    `json:"stderr,omitempty"`
+	Truncated  bool      `json:"stderr_truncated,omitempty"`
+	Empty      bool      `json:"empty,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// RecordCommand describes a finished run of cmd, started at started, that
+// wrote outputSize bytes to standard output and stderr to standard error
+func RecordCommand(cmd *exec.Cmd, started time.Time, outputSize int, stderr []byte, err error) CommandRun {
+	run := CommandRun{
+		Args:       append([]string(nil), cmd.Args...),
+		ExitCode:   -1,
+		StartedAt:  started.UTC(),
+		DurationMS: time.Since(started).Milliseconds(),
+		Empty:      err == nil && outputSize == 0,
+	}
+	if cmd.ProcessState != nil {
+		run.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if len(stderr) > maxRecordedStderr {
+		stderr = stderr[:maxRecordedStderr]
+		run.Truncated = true
+	}
+	run.Stderr = strings.TrimSpace(DecodeText(stderr).Text)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// RunCommand runs cmd and returns its standard output with the record of the
+// run. Standard error is captured apart from the output, so cmd must not
+// have Stdout or Stderr set.
+func RunCommand(cmd *exec.Cmd) ([]byte, CommandRun, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	started := time.Now()
+	err := cmd.Run()
+	return stdout.Bytes(), RecordCommand(cmd, started, stdout.Len(), stderr.Bytes(), err), err
+}
+
+// Message returns what the command wrote to explain a failure: its standard
+// error, or its output when it reports errors there
+func (r CommandRun) Message(output []byte) string {
+	if r.Stderr != "" {
+		return r.Stderr
+	}
+	return strings.TrimSpace(DecodeText(output).Text)
+}
+
+// DecodeCommands reads the command runs of an artifact back from a bundle
+// manifest
+func DecodeCommands(value interface{}) []CommandRun {
+	if value == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var runs []CommandRun
+	if json.Unmarshal(raw, &runs) != nil {
+		return nil
+	}
+	return runs
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// provenanceCommands are shell commands that print output, fail with a
+// message on standard error, and succeed without output
+func provenanceCommands() map[string]*exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return map[string]*exec.Cmd{
+			"prints":  exec.Command("cmd", "/c", "echo provenance"),
+			"fails":   exec.Command("cmd", "/c", "echo denied 1>&2 & exit /b 3"),
+			"empty":   exec.Command("cmd", "/c", "rem"),
+			"missing": exec.Command("redtriage-no-such-tool"),
+		}
+	}
+	return map[string]*exec.Cmd{
+		"prints":  exec.Command("sh", "-c", "echo provenance"),
+		"fails":   exec.Command("sh", "-c", "echo denied >&2; exit 3"),
+		"empty":   exec.Command("sh", "-c", "true"),
+		"missing": exec.Command("redtriage-no-such-tool"),
+	}
+}
+
+func TestRunCommandRecordsProvenance(t *testing.T) {
+	want := map[string]struct {
+		exitCode int
+		stderr   string
+		empty    bool
+	}{
+		"prints":  {0, "", false},
+		"fails":   {3, "denied", false},
+		"empty":   {0, "", true},
+		"missing": {-1, "", false},
+	}
+
+	for name, cmd := range provenanceCommands() {
+		_, run, err := RunCommand(cmd)
+		expect := want[name]
+		if run.ExitCode != expect.exitCode || run.Stderr != expect.stderr || run.Empty != expect.empty {
+			t.Errorf("%s recorded exit %d, stderr %q, empty %v; want exit %d, stderr %q, empty %v",
+				strings.Join(run.Args, " "), run.ExitCode, run.Stderr, run.Empty, expect.exitCode, expect.stderr, expect.empty)
+		}
+		if (err != nil) != (run.Error != "") || len(run.Args) == 0 || run.StartedAt.IsZero() {
+			t.Errorf("%s is recorded without its error, arguments or start", name)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// QuarantineRecord describes a file copied into quarantine: where it came
+// from, what it was and the archive holding it
+type QuarantineRecord struct {
+	ID            string     `json:"id"`
+	OriginalPath  string     `json:"original_path"`
+	Size          int64      `json:"size"`
+	ModifiedAt    time.Time  `json:"modified_at"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	Owner         string     `json:"owner,omitempty"`
+	ACL           string     `json:"acl,omitempty"` // SDDL on Windows, mode and owner elsewhere
+	MD5           string     `json:"md5"`
+	SHA1          string     `json:"sha1"`
+	SHA256        string     `json:"sha256"`
+	Archive       string     `json:"archive"`
+	ArchiveSHA256 string     `json:"archive_sha256"`
+	Reason        string     `json:"reason,omitempty"`
+	QuarantinedAt time.Time  `json:"quarantined_at"`
+}
+
+// QuarantineOptions control how a file is quarantined
+type QuarantineOptions struct {
+	ID       string // names the archive and its record
+	Dir      string // directory the archive is written to
+	Password string // password the archive is encrypted with
+	MaxSize  int64  // largest file accepted, 0 for no limit
+	Reason   string
+}
+
+// QuarantineFile copies a regular file into a password-protected ZIP
+// archive in opts.Dir, without executing or altering it, and writes its
+// record next to the archive. The file is read twice, once to hash it and
+// once to archive it, and is refused if it changed in between.
+func QuarantineFile(ctx context.Context, path string, opts QuarantineOptions) (*QuarantineRecord, error) {
+	if opts.Password == "" {
+		return nil, rterrors.Validationf("a quarantine password is required")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", abs, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, rterrors.Validationf("%s is not a regular file (%s); only files can be quarantined", abs, info.Mode().Type())
+	}
+	if opts.MaxSize > 0 && info.Size() > opts.MaxSize {
+		return nil, rterrors.Validationf("%s is %d bytes, over the quarantine limit of %d bytes (quarantine.max_file_size)", abs, info.Size(), opts.MaxSize)
+	}
+
+	sums, err := sumQuarantined(ctx, abs)
+	if err != nil {
+		return nil, err
+	}
+	if sums.size != info.Size() {
+		return nil, fmt.Errorf("%s changed while being read", abs)
+	}
+
+	details := statFileDetails(abs, info)
+	record := &QuarantineRecord{
+		ID:            opts.ID,
+		OriginalPath:  abs,
+		Size:          sums.size,
+		ModifiedAt:    info.ModTime().UTC(),
+		CreatedAt:     optionalTime(details.createdAt.UTC()),
+		Owner:         details.owner,
+		ACL:           fileACL(abs, info, details.owner),
+		MD5:           sums.md5,
+		SHA1:          sums.sha1,
+		SHA256:        sums.sha256,
+		Reason:        opts.Reason,
+		QuarantinedAt: time.Now().UTC(),
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	record.Archive = filepath.Join(opts.Dir, opts.ID+".zip")
+	if err := writeQuarantineArchive(ctx, abs, record, sums.crc, opts.Password); err != nil {
+		os.Remove(record.Archive)
+		return nil, err
+	}
+	if record.ArchiveSHA256, err = hashFile(ctx, record.Archive); err != nil {
+		return nil, fmt.Errorf("failed to hash quarantine archive: %w", err)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quarantine record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.Dir, opts.ID+".json"), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write quarantine record: %w", err)
+	}
+	return record, nil
+}
+
+// quarantineSums are the size and hashes of a file taken before it is
+// archived
+type quarantineSums struct {
+	size              int64
+	crc               uint32
+	md5, sha1, sha256 string
+}
+
+func sumQuarantined(ctx context.Context, path string) (quarantineSums, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return quarantineSums{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	crc, md5sum, sha1sum, sha256sum := crc32.NewIEEE(), md5.New(), sha1.New(), sha256.New()
+	size, err := io.Copy(io.MultiWriter(crc, md5sum, sha1sum, sha256sum), &contextReader{ctx: ctx, r: f})
+	if err != nil {
+		return quarantineSums{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return quarantineSums{
+		size:   size,
+		crc:    crc.Sum32(),
+		md5:    hex.EncodeToString(md5sum.Sum(nil)),
+		sha1:   hex.EncodeToString(sha1sum.Sum(nil)),
+		sha256: hex.EncodeToString(sha256sum.Sum(nil)),
+	}, nil
+}
+
+// writeQuarantineArchive writes the file encrypted into record.Archive,
+// checking it still has the SHA-256 it was hashed with
+func writeQuarantineArchive(ctx context.Context, path string, record *QuarantineRecord, crc uint32, password string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(record.Archive, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create quarantine archive: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	// The entry is named by its hash so the archive never holds a runnable name
+	w, err := archive.CreateEncrypted(zw, record.SHA256+".bin", record.ModifiedAt, record.Size, crc, password)
+	if err != nil {
+		return err
+	}
+	sum := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, sum), io.LimitReader(&contextReader{ctx: ctx, r: src}, record.Size+1))
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	if n != record.Size || hex.EncodeToString(sum.Sum(nil)) != record.SHA256 {
+		return fmt.Errorf("%s changed while being quarantined", path)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write quarantine archive: %w", err)
+	}
+	return out.Close()
+}
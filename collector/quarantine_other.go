@@ -0,0 +1,13 @@
+//go:build !windows
+
+package collector
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// fileACL returns the permission bits and owner of a file
+func fileACL(path string, info fs.FileInfo, owner string) string {
+	return strings.TrimSpace(info.Mode().String() + " " + owner)
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// quarantineSample stands in for a suspicious executable
+const quarantineSample = "MZ test dropper\x00\x01\x02"
+
+// TestQuarantineFile quarantines a sample file and checks the archive opens
+// only with the password, holds the exact bytes under a name that is not
+// runnable, and that the record keeps the original path, hashes and ACL.
+// Files over the size limit and directories must be refused.
+func TestQuarantineFile(t *testing.T) {
+	dir := t.TempDir()
+	sample := filepath.Join(dir, "invoice.exe")
+	if err := os.WriteFile(sample, []byte(quarantineSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(quarantineSample))
+	wantSHA := hex.EncodeToString(sum[:])
+
+	ctx := context.Background()
+	opts := QuarantineOptions{
+		ID:       "QUA-test",
+		Dir:      filepath.Join(t.TempDir(), "quarantine"),
+		Password: "infected",
+		MaxSize:  1 << 20,
+		Reason:   "test",
+	}
+	record, err := QuarantineFile(ctx, sample, opts)
+	if err != nil {
+		t.Fatalf("failed to quarantine sample: %v", err)
+	}
+	if record.SHA256 != wantSHA || record.Size != int64(len(quarantineSample)) || record.MD5 == "" || record.SHA1 == "" {
+		t.Fatalf("quarantine record has size %d and SHA-256 %s, want %d and %s with MD5 and SHA-1", record.Size, record.SHA256, len(quarantineSample), wantSHA)
+	}
+	if record.ACL == "" || record.ArchiveSHA256 == "" {
+		t.Fatal("quarantine record is missing the ACL or archive hash")
+	}
+	if _, err := os.Stat(sample); err != nil {
+		t.Fatalf("quarantine must leave the original in place: %v", err)
+	}
+
+	var saved QuarantineRecord
+	data, err := os.ReadFile(filepath.Join(opts.Dir, opts.ID+".json"))
+	if err != nil {
+		t.Fatalf("quarantine record was not written: %v", err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil || saved.OriginalPath != record.OriginalPath || saved.SHA256 != wantSHA {
+		t.Fatalf("saved quarantine record does not match: %s", data)
+	}
+
+	zr, err := zip.OpenReader(record.Archive)
+	if err != nil {
+		t.Fatalf("failed to open quarantine archive: %v", err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 1 || zr.File[0].Name != wantSHA+".bin" {
+		t.Fatalf("quarantine archive should hold only %s.bin", wantSHA)
+	}
+	entry := zr.File[0]
+	if content, err := readEncrypted(entry, "wrong"); err == nil || bytes.Equal(content, []byte(quarantineSample)) {
+		t.Fatal("quarantine archive opened with the wrong password")
+	}
+	content, err := readEncrypted(entry, opts.Password)
+	if err != nil {
+		t.Fatalf("failed to read quarantined file: %v", err)
+	}
+	if !bytes.Equal(content, []byte(quarantineSample)) {
+		t.Fatal("quarantined content differs from the original")
+	}
+	raw, err := os.ReadFile(record.Archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("MZ test")) {
+		t.Fatal("quarantine archive holds the file unencrypted")
+	}
+
+	opts.ID, opts.MaxSize = "QUA-test-large", 4
+	if _, err := QuarantineFile(ctx, sample, opts); rterrors.CategoryOf(err) != rterrors.Validation || !strings.Contains(err.Error(), "max_file_size") {
+		t.Errorf("a file over the size limit was not refused: %v", err)
+	}
+	opts.ID, opts.MaxSize = "QUA-test-dir", 0
+	if _, err := QuarantineFile(ctx, dir, opts); rterrors.CategoryOf(err) != rterrors.Validation {
+		t.Errorf("a directory was not refused: %v", err)
+	}
+}
+
+// readEncrypted reads the whole of an encrypted quarantine entry
+func readEncrypted(f *zip.File, password string) ([]byte, error) {
+	r, err := archive.OpenEncrypted(f, password)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package collector
+
+import (
+	"io/fs"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileACL returns the owner, group and discretionary ACL of a file as SDDL
+func fileACL(path string, info fs.FileInfo, owner string) string {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return ""
+	}
+	return sd.String()
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// ReasonCode says why an artifact was not collected, or not in full. Set
+// where the collection failed, it tells data that does not exist from data
+// that could not be read, which changes what its absence means.
+type ReasonCode string
+
+const (
+	ReasonNotPresent   ReasonCode = "not_present"   // the data does not exist on the host or image
+	ReasonAccessDenied ReasonCode = "access_denied" // the collector lacked the rights to read it
+	ReasonToolMissing  ReasonCode = "tool_missing"  // the external tool it needs is not installed
+	ReasonTimeout      ReasonCode = "timeout"       // the collection ran out of time
+	ReasonTruncated    ReasonCode = "truncated"     // collected, but cut at a limit
+	ReasonScopeDenied  ReasonCode = "scope_denied"  // outside the incident's collection scope, so not collected
+)
+
+// Blind reports whether an artifact with this reason was not looked at, so
+// that finding nothing in it says nothing about the host
+func (c ReasonCode) Blind() bool {
+	return c == ReasonAccessDenied || c == ReasonToolMissing || c == ReasonTimeout || c == ReasonScopeDenied
+}
+
+// Label is the reason as reports show it
+func (c ReasonCode) Label() string {
+	switch c {
+	case ReasonNotPresent:
+		return "Not present"
+	case ReasonAccessDenied:
+		return "Access denied"
+	case ReasonToolMissing:
+		return "Tool missing"
+	case ReasonTimeout:
+		return "Timed out"
+	case ReasonTruncated:
+		return "Truncated"
+	case ReasonScopeDenied:
+		return "Out of scope"
+	}
+	return string(c)
+}
+
+// ReasonFor classifies a collection error by its cause, or returns "" when
+// the cause is not one of the reason codes
+func ReasonFor(err error) ReasonCode {
+	if err == nil {
+		return ""
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReasonTimeout
+	case errors.Is(err, exec.ErrNotFound):
+		return ReasonToolMissing
+	}
+	switch rterrors.CategoryOf(err) {
+	case rterrors.Permission:
+		return ReasonAccessDenied
+	case rterrors.NotFound:
+		return ReasonNotPresent
+	}
+	// Windows tools report denied access in their output, not their status
+	if strings.Contains(strings.ToLower(err.Error()), "access is denied") {
+		return ReasonAccessDenied
+	}
+	return ""
+}
+
+// Fail records that the artifact could not be collected, and why
+func (r *ArtifactResult) Fail(reason ReasonCode, err error) {
+	r.Error = err
+	r.Reason = reason
+}
@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+func TestReasonFor(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want ReasonCode
+	}{
+		{"missing file", &fs.PathError{Op: "open", Path: "x", Err: syscall.ENOENT}, ReasonNotPresent},
+		{"unreadable file", fmt.Errorf("failed to read: %w", &fs.PathError{Op: "open", Path: "x", Err: syscall.EACCES}), ReasonAccessDenied},
+		{"elevation required", rterrors.Permissionf("auditpol requires elevation"), ReasonAccessDenied},
+		{"denied in tool output", fmt.Errorf("wevtutil failed: Access is denied."), ReasonAccessDenied},
+		{"deadline", fmt.Errorf("klist timed out: %w", context.DeadlineExceeded), ReasonTimeout},
+		{"unclassified", fmt.Errorf("exit status 2"), ""},
+	}
+	for _, c := range cases {
+		if got := ReasonFor(c.err); got != c.want {
+			t.Errorf("%s classified as %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRunCustomArtifactRecordsReason(t *testing.T) {
+	custom := NewEnhancedArtifact("probe", "Failure probe", "system", "command", "custom", 1)
+	custom.Parameters["parser"] = "raw"
+
+	custom.Command = []string{"redtriage-test-no-such-tool"}
+	if result := RunCustomArtifact(context.Background(), custom); result.Reason != ReasonToolMissing {
+		t.Errorf("missing command recorded as %q (%v), want %q", result.Reason, result.Error, ReasonToolMissing)
+	}
+
+	// The deadline has passed before the command starts, so it never runs
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom.Command = []string{self, "--version"}
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if result := RunCustomArtifact(expired, custom); result.Reason != ReasonTimeout {
+		t.Errorf("expired command recorded as %q (%v), want %q", result.Reason, result.Error, ReasonTimeout)
+	}
+}
+
+func TestOfflineArtifactsMissingFromImageAreNotPresent(t *testing.T) {
+	root := testImage(t)
+	extended, err := NewOfflineCollector(root).CollectExtendedArtifacts(context.Background())
+	if err != nil {
+		t.Fatalf("CollectExtendedArtifacts: %v", err)
+	}
+	for _, result := range extended {
+		if result.Artifact.Name == "startup_folder" && result.Reason != ReasonNotPresent {
+			t.Errorf("startup folder missing from the image recorded as %q, want %q", result.Reason, ReasonNotPresent)
+		}
+	}
+	for _, result := range CarveImage(context.Background(), CarveOptions{Root: root}) {
+		if result.Reason != ReasonNotPresent {
+			t.Errorf("carving an image without sources recorded as %q, want %q", result.Reason, ReasonNotPresent)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// RecordRef points at one record of an artifact, so a finding can name the
+// record it matched instead of carrying a copy of it. A record is the
+// Index-th entry of the artifact's List or, with no List, of the artifact's
+// only record list. Records are never reordered once a collection is saved,
+// so the reference stays valid for as long as the collection does.
+type RecordRef struct {
+	// ID is collection/artifact[/list]/index; the collection is left out
+	// for records of the artifacts being analyzed
+	ID         string `json:"id"`
+	Collection string `json:"collection,omitempty"`
+	Artifact   string `json:"artifact"`
+	List       string `json:"list,omitempty"`
+	Index      int    `json:"index"`
+}
+
+// NewRecordRef returns the reference of the index-th record of list in an
+// artifact of a collection
+func NewRecordRef(collection, artifact, list string, index int) *RecordRef {
+	var parts []string
+	for _, part := range []string{collection, artifact, list} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	parts = append(parts, strconv.Itoa(index))
+	return &RecordRef{ID: strings.Join(parts, "/"), Collection: collection, Artifact: artifact, List: list, Index: index}
+}
+
+// Anchor is the ID as an HTML fragment identifier
+func (r *RecordRef) Anchor() string {
+	var b strings.Builder
+	b.WriteString("record-")
+	for _, c := range r.ID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			b.WriteRune(c)
+		default:
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// DecodeRecordRef reads a reference back from a findings report, where it
+// has been decoded as a generic JSON object. It returns nil for findings
+// written before references were kept.
+func DecodeRecordRef(value interface{}) *RecordRef {
+	switch ref := value.(type) {
+	case *RecordRef:
+		return ref
+	case nil:
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var ref RecordRef
+	if json.Unmarshal(raw, &ref) != nil || ref.ID == "" {
+		return nil
+	}
+	return &ref
+}
+
+// RecordAt returns the record a reference points at in the data of its
+// artifact, either as collected or as read back from JSON. Without a List
+// the data must be a bare list.
+func RecordAt(data interface{}, ref *RecordRef) (map[string]interface{}, bool) {
+	if text, ok := data.(string); ok {
+		if json.Unmarshal([]byte(text), &data) != nil {
+			return nil, false
+		}
+	} else if _, ok := data.(map[string]interface{}); !ok {
+		if _, ok := data.([]interface{}); !ok {
+			raw, err := json.Marshal(data)
+			if err != nil || json.Unmarshal(raw, &data) != nil {
+				return nil, false
+			}
+		}
+	}
+
+	var list []interface{}
+	if ref.List == "" {
+		list, _ = data.([]interface{})
+	} else if fields, ok := data.(map[string]interface{}); ok {
+		list, _ = fields[ref.List].([]interface{})
+	}
+	if ref.Index < 0 || ref.Index >= len(list) {
+		return nil, false
+	}
+	record, ok := list[ref.Index].(map[string]interface{})
+	return record, ok
+}
@@ -0,0 +1,17 @@
+package collector
+
+// Artifact types for host state the detector parses into structured records.
+// PowerShell CIM objects serialized with ConvertTo-Json and wevtutil XML do
+// not depend on the display language and are preferred. The text types are
+// fallbacks for hosts where PowerShell is unavailable; they are parsed by
+// column position, never by their localized headers.
+const (
+	ProcessListType       = "process_list_json"    // Win32_Process via ConvertTo-Json
+	TasklistCSVType       = "tasklist_csv"         // tasklist /FO CSV /V
+	ConnectionListType    = "net_connections_json" // Get-NetTCPConnection and Get-NetUDPEndpoint via ConvertTo-Json
+	NetstatTextType       = "netstat_text"         // netstat -ano
+	ScheduledTaskListType = "scheduled_tasks_json" // Get-ScheduledTask via ConvertTo-Json
+	SchtasksCSVType       = "schtasks_csv"         // schtasks /query /fo csv /v
+	EventLogXMLType       = "event_log_xml"        // wevtutil /f:xml from the channels in the "channels" parameter
+	ScheduledTaskXMLType  = "scheduled_task_xml"   // task definitions in the form of schtasks /query /xml ONE
+)
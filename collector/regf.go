@@ -0,0 +1,399 @@
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Registry value types as stored in hive files
+const (
+	RegSZ       = 1
+	RegExpandSZ = 2
+	RegBinary   = 3
+	RegDWORD    = 4
+	RegMultiSZ  = 7
+	RegQWORD    = 11
+)
+
+const (
+	hiveBinsOffset  = 0x1000 // cell offsets are relative to the first hive bin
+	hiveBigDataSize = 16344  // larger values are split over a "db" segment list
+	hiveMaxDepth    = 512    // subkey lists nest at most this deep
+)
+
+// Hive is a registry hive file read into memory, such as SYSTEM or
+// Amcache.hve. It reads the primary file only; changes still in the
+// transaction logs of a dirty hive are not seen.
+type Hive struct {
+	data  []byte
+	minor uint32
+}
+
+// HiveKey is a key of a hive
+type HiveKey struct {
+	hive      *Hive
+	Name      string
+	LastWrite time.Time
+	subkeys   uint32
+	subList   uint32
+	values    uint32
+	valueList uint32
+}
+
+// HiveValue is a value of a hive key
+type HiveValue struct {
+	Name string
+	Type uint32
+	Data []byte
+}
+
+// OpenHive reads a hive file
+func OpenHive(path string) (*Hive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHive(data)
+}
+
+// ParseHive reads a hive from its file contents
+func ParseHive(data []byte) (*Hive, error) {
+	if len(data) < hiveBinsOffset+0x20 || string(data[:4]) != "regf" {
+		return nil, fmt.Errorf("not a registry hive: missing regf signature")
+	}
+	return &Hive{data: data, minor: binary.LittleEndian.Uint32(data[0x18:])}, nil
+}
+
+// Root returns the root key of the hive
+func (h *Hive) Root() (*HiveKey, error) {
+	return h.key(binary.LittleEndian.Uint32(h.data[0x24:]))
+}
+
+// cell returns the data of the cell at a hive bin offset
+func (h *Hive) cell(offset uint32) ([]byte, error) {
+	start := int64(hiveBinsOffset) + int64(offset)
+	if offset == 0xffffffff || start+4 > int64(len(h.data)) {
+		return nil, fmt.Errorf("cell offset %#x outside the hive", offset)
+	}
+	size := int64(int32(binary.LittleEndian.Uint32(h.data[start:])))
+	if size < 0 {
+		size = -size
+	}
+	if size < 4 || start+size > int64(len(h.data)) {
+		return nil, fmt.Errorf("cell at %#x has invalid size %d", offset, size)
+	}
+	return h.data[start+4 : start+size], nil
+}
+
+// key reads the nk cell at offset
+func (h *Hive) key(offset uint32) (*HiveKey, error) {
+	cell, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(cell) < 76 || string(cell[:2]) != "nk" {
+		return nil, fmt.Errorf("cell at %#x is not a key", offset)
+	}
+	flags := binary.LittleEndian.Uint16(cell[2:])
+	nameLength := int(binary.LittleEndian.Uint16(cell[72:]))
+	if 76+nameLength > len(cell) {
+		return nil, fmt.Errorf("key at %#x has a truncated name", offset)
+	}
+	lastWrite, _ := filetime(binary.LittleEndian.Uint64(cell[4:]))
+	return &HiveKey{
+		hive:      h,
+		Name:      hiveString(cell[76:76+nameLength], flags&0x20 != 0),
+		LastWrite: lastWrite,
+		subkeys:   binary.LittleEndian.Uint32(cell[20:]),
+		subList:   binary.LittleEndian.Uint32(cell[28:]),
+		values:    binary.LittleEndian.Uint32(cell[36:]),
+		valueList: binary.LittleEndian.Uint32(cell[40:]),
+	}, nil
+}
+
+// Subkeys returns the subkeys of the key
+func (k *HiveKey) Subkeys() ([]*HiveKey, error) {
+	if k.subkeys == 0 {
+		return nil, nil
+	}
+	offsets, err := k.hive.subkeyOffsets(k.subList, 0)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*HiveKey, 0, len(offsets))
+	for _, offset := range offsets {
+		key, err := k.hive.key(offset)
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// subkeyOffsets reads an lf, lh, li or ri subkey list
+func (h *Hive) subkeyOffsets(offset uint32, depth int) ([]uint32, error) {
+	if depth > hiveMaxDepth {
+		return nil, fmt.Errorf("subkey lists nested too deep")
+	}
+	cell, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(cell) < 4 {
+		return nil, fmt.Errorf("subkey list at %#x is truncated", offset)
+	}
+	count := int(binary.LittleEndian.Uint16(cell[2:]))
+	stride := 4
+	switch string(cell[:2]) {
+	case "lf", "lh":
+		stride = 8
+	case "li", "ri":
+	default:
+		return nil, fmt.Errorf("cell at %#x is not a subkey list", offset)
+	}
+	if 4+count*stride > len(cell) {
+		return nil, fmt.Errorf("subkey list at %#x is truncated", offset)
+	}
+
+	var offsets []uint32
+	for i := 0; i < count; i++ {
+		entry := binary.LittleEndian.Uint32(cell[4+i*stride:])
+		if string(cell[:2]) != "ri" {
+			offsets = append(offsets, entry)
+			continue
+		}
+		nested, err := h.subkeyOffsets(entry, depth+1)
+		if err != nil {
+			return offsets, err
+		}
+		offsets = append(offsets, nested...)
+	}
+	return offsets, nil
+}
+
+// Subkey returns the subkey with a name, compared without regard to case
+// as Windows does, or nil when there is none
+func (k *HiveKey) Subkey(name string) (*HiveKey, error) {
+	keys, err := k.Subkeys()
+	for _, key := range keys {
+		if strings.EqualFold(key.Name, name) {
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+// Path follows a backslash-separated path of subkeys, returning nil when a
+// key on the way does not exist
+func (k *HiveKey) Path(path string) (*HiveKey, error) {
+	key := k
+	for _, name := range strings.Split(path, `\`) {
+		if name == "" {
+			continue
+		}
+		next, err := key.Subkey(name)
+		if next == nil {
+			return nil, err
+		}
+		key = next
+	}
+	return key, nil
+}
+
+// Values returns the values of the key
+func (k *HiveKey) Values() ([]HiveValue, error) {
+	if k.values == 0 {
+		return nil, nil
+	}
+	list, err := k.hive.cell(k.valueList)
+	if err != nil {
+		return nil, err
+	}
+	if int(k.values)*4 > len(list) {
+		return nil, fmt.Errorf("value list of key %s is truncated", k.Name)
+	}
+	values := make([]HiveValue, 0, k.values)
+	for i := 0; i < int(k.values); i++ {
+		value, err := k.hive.value(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Value returns the value with a name, compared without regard to case,
+// and whether the key has it
+func (k *HiveKey) Value(name string) (HiveValue, bool) {
+	values, _ := k.Values()
+	for _, value := range values {
+		if strings.EqualFold(value.Name, name) {
+			return value, true
+		}
+	}
+	return HiveValue{}, false
+}
+
+// value reads the vk cell at offset and its data
+func (h *Hive) value(offset uint32) (HiveValue, error) {
+	cell, err := h.cell(offset)
+	if err != nil {
+		return HiveValue{}, err
+	}
+	if len(cell) < 20 || string(cell[:2]) != "vk" {
+		return HiveValue{}, fmt.Errorf("cell at %#x is not a value", offset)
+	}
+	nameLength := int(binary.LittleEndian.Uint16(cell[2:]))
+	size := binary.LittleEndian.Uint32(cell[4:])
+	dataOffset := binary.LittleEndian.Uint32(cell[8:])
+	if 20+nameLength > len(cell) {
+		return HiveValue{}, fmt.Errorf("value at %#x has a truncated name", offset)
+	}
+	value := HiveValue{
+		Name: hiveString(cell[20:20+nameLength], binary.LittleEndian.Uint16(cell[16:])&1 != 0),
+		Type: binary.LittleEndian.Uint32(cell[12:]),
+	}
+
+	switch {
+	case size&0x80000000 != 0:
+		// Up to four bytes are stored in the offset field itself
+		n := size &^ 0x80000000
+		if n > 4 {
+			n = 4
+		}
+		value.Data = append([]byte(nil), cell[8:8+n]...)
+	case size > hiveBigDataSize && h.minor > 3:
+		value.Data, err = h.bigData(dataOffset, int(size))
+	default:
+		var data []byte
+		data, err = h.cell(dataOffset)
+		if err == nil && int(size) > len(data) {
+			err = fmt.Errorf("data of value %s is truncated", value.Name)
+		}
+		if err == nil {
+			value.Data = data[:size]
+		}
+	}
+	return value, err
+}
+
+// bigData joins the segments of a value stored in a db cell
+func (h *Hive) bigData(offset uint32, size int) ([]byte, error) {
+	cell, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(cell) < 8 || string(cell[:2]) != "db" {
+		return nil, fmt.Errorf("cell at %#x is not a big data list", offset)
+	}
+	count := int(binary.LittleEndian.Uint16(cell[2:]))
+	list, err := h.cell(binary.LittleEndian.Uint32(cell[4:]))
+	if err != nil {
+		return nil, err
+	}
+	if count*4 > len(list) {
+		return nil, fmt.Errorf("big data list at %#x is truncated", offset)
+	}
+	data := make([]byte, 0, size)
+	for i := 0; i < count && len(data) < size; i++ {
+		segment, err := h.cell(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			return nil, err
+		}
+		if n := size - len(data); len(segment) > n {
+			segment = segment[:n]
+		} else if len(segment) > hiveBigDataSize {
+			segment = segment[:hiveBigDataSize]
+		}
+		data = append(data, segment...)
+	}
+	if len(data) < size {
+		return nil, fmt.Errorf("big data at %#x is truncated", offset)
+	}
+	return data, nil
+}
+
+// String returns the value as text: REG_SZ and REG_EXPAND_SZ decoded from
+// UTF-16, numbers in decimal
+func (v HiveValue) String() string {
+	switch v.Type {
+	case RegSZ, RegExpandSZ, RegMultiSZ:
+		return decodeUTF16Z(v.Data)
+	case RegDWORD:
+		if len(v.Data) >= 4 {
+			return fmt.Sprint(binary.LittleEndian.Uint32(v.Data))
+		}
+	case RegQWORD:
+		if len(v.Data) >= 8 {
+			return fmt.Sprint(binary.LittleEndian.Uint64(v.Data))
+		}
+	}
+	return ""
+}
+
+// Strings returns the strings of a REG_MULTI_SZ value, or the value as
+// text for other types
+func (v HiveValue) Strings() []string {
+	if v.Type != RegMultiSZ {
+		return []string{v.String()}
+	}
+	var values []string
+	for _, value := range strings.Split(string(utf16.Decode(utf16Units(v.Data))), "\x00") {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Uint returns a REG_DWORD or REG_QWORD value
+func (v HiveValue) Uint() (uint64, bool) {
+	switch {
+	case v.Type == RegDWORD && len(v.Data) >= 4:
+		return uint64(binary.LittleEndian.Uint32(v.Data)), true
+	case v.Type == RegQWORD && len(v.Data) >= 8:
+		return binary.LittleEndian.Uint64(v.Data), true
+	}
+	return 0, false
+}
+
+// hiveString decodes a key or value name, stored as Latin-1 when compressed
+// and UTF-16LE otherwise
+func hiveString(data []byte, compressed bool) string {
+	if !compressed {
+		return decodeUTF16Z(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// decodeUTF16Z decodes UTF-16LE text up to the first NUL
+func decodeUTF16Z(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.LittleEndian.Uint16(data[i:])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units))
+}
+
+// utf16Units reads UTF-16LE code units, NULs included
+func utf16Units(data []byte) []uint16 {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, binary.LittleEndian.Uint16(data[i:]))
+	}
+	return units
+}
@@ -0,0 +1,239 @@
+package collector
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Artifact types for RDP and remote access tooling on Windows
+const (
+	RDPHistoryType  = "rdp_history_json"  // RDPConnection entries
+	RDPSessionsType = "rdp_sessions_xml"  // wevtutil /f:xml from the channels in the "channels" parameter
+	RDPCacheType    = "rdp_cache_json"    // RDPCacheFile entries
+	RDPSettingsType = "rdp_settings_json" // RDPSettings of the host
+	RemoteToolsType = "remote_tools_json" // RemoteTool entries
+)
+
+// LocalSessionManagerChannel logs Remote Desktop session logons (21),
+// disconnects (24) and reconnects (25)
+const LocalSessionManagerChannel = "Microsoft-Windows-TerminalServices-LocalSessionManager/Operational"
+
+// RemoteAccessLookback is how far back RDP session events are read, and how
+// recently RDP must have been enabled or a remote access tool installed for
+// the detector to flag it
+const RemoteAccessLookback = 7 * 24 * time.Hour
+
+// Sources of an RDP connection a user made
+const (
+	RDPSourceMRU     = "mru"         // Terminal Server Client\Default MRU values
+	RDPSourceServers = "servers"     // Terminal Server Client\Servers subkeys
+	RDPSourceDefault = "default_rdp" // the user's Documents\Default.rdp
+)
+
+// RDPConnection is an outbound Remote Desktop connection a user made.
+// LastWrite is the time of the key or file it was read from; for the MRU
+// list it is the time of the most recent connection only.
+type RDPConnection struct {
+	Source       string    `json:"source"`
+	User         string    `json:"user"`
+	SID          string    `json:"sid,omitempty"`
+	Host         string    `json:"host"`
+	UsernameHint string    `json:"username_hint,omitempty"`
+	Path         string    `json:"path"`
+	LastWrite    time.Time `json:"last_write"`
+}
+
+// RDPCacheFile is a bitmap cache file of the Remote Desktop client, left
+// behind by the connections a user made
+type RDPCacheFile struct {
+	User     string    `json:"user"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+}
+
+// RDPSettings is whether the host accepts Remote Desktop connections and
+// requires Network Level Authentication for them. A policy value takes
+// precedence over the Terminal Server one; the sources name the key each
+// setting was read from, or "default" when neither sets it.
+type RDPSettings struct {
+	Enabled         bool      `json:"enabled"`
+	EnabledSource   string    `json:"enabled_source"`
+	EnabledModified time.Time `json:"enabled_modified"` // last write of the enabled source key
+	NLARequired     bool      `json:"nla_required"`
+	NLASource       string    `json:"nla_source"`
+	Port            int       `json:"port,omitempty"`
+}
+
+// Evidence of an installed remote access tool
+const (
+	RemoteToolService   = "service"   // a service whose name or image names the tool
+	RemoteToolInstall   = "install"   // an Uninstall entry
+	RemoteToolDirectory = "directory" // an install or configuration directory
+)
+
+// RemoteTool is evidence that a remote access tool is installed, with the
+// log files it keeps. InstalledAt is the install date of an Uninstall entry,
+// the last write of a service key or the creation of a directory.
+type RemoteTool struct {
+	Tool        string    `json:"tool"`
+	Evidence    string    `json:"evidence"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path,omitempty"`
+	State       string    `json:"state,omitempty"` // service start type
+	InstalledAt time.Time `json:"installed_at"`
+	Logs        []string  `json:"logs,omitempty"`
+}
+
+// remoteTools are the remote access tools looked for: the names their
+// services and installs go by, and their install directories and log files,
+// which may be globs. Paths under APPDATA are looked for in every profile.
+var remoteTools = []struct {
+	tool  string
+	names []string
+	dirs  []string
+	logs  []string
+}{
+	{
+		tool:  "AnyDesk",
+		names: []string{"anydesk"},
+		dirs:  []string{`%PROGRAMFILES%\AnyDesk`, `%PROGRAMFILES(X86)%\AnyDesk`, `%PROGRAMDATA%\AnyDesk`, `%APPDATA%\AnyDesk`},
+		logs:  []string{`%PROGRAMDATA%\AnyDesk\ad_svc.trace`, `%PROGRAMDATA%\AnyDesk\connection_trace.txt`, `%APPDATA%\AnyDesk\ad.trace`, `%APPDATA%\AnyDesk\connection_trace.txt`},
+	},
+	{
+		tool:  "TeamViewer",
+		names: []string{"teamviewer"},
+		dirs:  []string{`%PROGRAMFILES%\TeamViewer`, `%PROGRAMFILES(X86)%\TeamViewer`, `%APPDATA%\TeamViewer`},
+		logs: []string{
+			`%PROGRAMFILES%\TeamViewer\Connections_incoming.txt`, `%PROGRAMFILES%\TeamViewer\TeamViewer*_Logfile*.log`,
+			`%PROGRAMFILES(X86)%\TeamViewer\Connections_incoming.txt`, `%PROGRAMFILES(X86)%\TeamViewer\TeamViewer*_Logfile*.log`,
+			`%APPDATA%\TeamViewer\Connections.txt`, `%APPDATA%\TeamViewer\TeamViewer*_Logfile*.log`,
+		},
+	},
+	{
+		tool:  "ScreenConnect",
+		names: []string{"screenconnect", "connectwise control"},
+		dirs:  []string{`%PROGRAMFILES%\ScreenConnect Client*`, `%PROGRAMFILES(X86)%\ScreenConnect Client*`, `%PROGRAMDATA%\ScreenConnect Client*`},
+		logs:  []string{`%PROGRAMDATA%\ScreenConnect Client*\user.config`, `%WINDIR%\Temp\ScreenConnect\*`},
+	},
+}
+
+// RemoteToolOf returns the remote access tool a service, program or
+// directory name belongs to, or ""
+func RemoteToolOf(name string) string {
+	lower := strings.ToLower(name)
+	for _, tool := range remoteTools {
+		for _, n := range tool.names {
+			if strings.Contains(lower, n) {
+				return tool.tool
+			}
+		}
+	}
+	return ""
+}
+
+// ParseRDPFile returns the host and user name of a Remote Desktop
+// connection file, which mstsc writes as UTF-16
+func ParseRDPFile(data []byte) (host, user string) {
+	for _, line := range strings.Split(DecodeText(data).Text, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), ":s:")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "full address":
+			host = value
+		case "username":
+			user = value
+		}
+	}
+	return host, user
+}
+
+// RemoteAccessArtifact wraps remote access records of an artifact type in
+// an artifact result
+func RemoteAccessArtifact(name, description, artifactType string, data interface{}, records int) ArtifactResult {
+	artifact := NewBaseArtifact(name, description, "remote_access", artifactType).Artifact
+	artifact.Platform = "windows"
+	raw, _ := json.Marshal(data)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact,
+		Data:     data,
+		Size:     int64(len(raw)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "remote_access",
+			Version:     "1.0.0",
+			Tags:        map[string]string{"records": strconv.Itoa(records)},
+		},
+	}
+}
+
+// RDPConnections returns the connections of an RDP history artifact,
+// decoding them when the artifact was read back from a bundle
+func RDPConnections(result ArtifactResult) []RDPConnection {
+	if connections, ok := result.Data.([]RDPConnection); ok {
+		return connections
+	}
+	var connections []RDPConnection
+	decodeRemoteAccess(result.Data, &connections)
+	return connections
+}
+
+// RDPCacheFiles returns the files of an RDP cache artifact, decoding them
+// when the artifact was read back from a bundle
+func RDPCacheFiles(result ArtifactResult) []RDPCacheFile {
+	if files, ok := result.Data.([]RDPCacheFile); ok {
+		return files
+	}
+	var files []RDPCacheFile
+	decodeRemoteAccess(result.Data, &files)
+	return files
+}
+
+// RDPSettingsOf returns the settings of an RDP settings artifact, or nil
+func RDPSettingsOf(result ArtifactResult) *RDPSettings {
+	switch data := result.Data.(type) {
+	case RDPSettings:
+		return &data
+	case *RDPSettings:
+		return data
+	}
+	var settings RDPSettings
+	if !decodeRemoteAccess(result.Data, &settings) {
+		return nil
+	}
+	return &settings
+}
+
+// RemoteToolEntries returns the entries of a remote tools artifact,
+// decoding them when the artifact was read back from a bundle
+func RemoteToolEntries(result ArtifactResult) []RemoteTool {
+	if tools, ok := result.Data.([]RemoteTool); ok {
+		return tools
+	}
+	var tools []RemoteTool
+	decodeRemoteAccess(result.Data, &tools)
+	return tools
+}
+
+// decodeRemoteAccess decodes artifact data read back from a bundle, as
+// decoded JSON or as text, into v
+func decodeRemoteAccess(data interface{}, v interface{}) bool {
+	if data == nil {
+		return false
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	if text, ok := data.(string); ok {
+		raw = []byte(text)
+	}
+	return json.Unmarshal(raw, v) == nil
+}
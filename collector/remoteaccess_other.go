@@ -0,0 +1,11 @@
+//go:build !windows
+
+package collector
+
+import "context"
+
+// CollectLiveRemoteAccess collects nothing: Remote Desktop and the remote
+// access tools looked for are Windows artifacts
+func CollectLiveRemoteAccess(ctx context.Context) []ArtifactResult {
+	return nil
+}
@@ -0,0 +1,17 @@
+package collector
+
+import "testing"
+
+func TestParseRDPFile(t *testing.T) {
+	data := append([]byte{0xFF, 0xFE}, utf16z("screen mode id:i:2\r\nfull address:s:jump01.corp.example\r\nusername:s:CORP\\alice\r\n")...)
+	host, user := ParseRDPFile(data)
+	if host != "jump01.corp.example" || user != `CORP\alice` {
+		t.Errorf("Default.rdp parsed as host %q user %q", host, user)
+	}
+}
+
+func TestRemoteToolOf(t *testing.T) {
+	if tool := RemoteToolOf("ScreenConnect Client (4b5d7f2e1c3a)"); tool != "ScreenConnect" {
+		t.Errorf("ScreenConnect service identified as %q", tool)
+	}
+}
@@ -0,0 +1,381 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// maxRDPSessionEvents bounds the events read from each session channel
+const maxRDPSessionEvents = 2000
+
+// Registry keys of the Remote Desktop settings, below HKLM
+const (
+	terminalServerKey         = `SYSTEM\CurrentControlSet\Control\Terminal Server`
+	rdpTcpKey                 = terminalServerKey + `\WinStations\RDP-Tcp`
+	terminalServicesPolicyKey = `SOFTWARE\Policies\Microsoft\Windows NT\Terminal Services`
+)
+
+// terminalServerClientKey is where the Remote Desktop client keeps the
+// connections of a user, below the user's hive
+const terminalServerClientKey = `Software\Microsoft\Terminal Server Client`
+
+// CollectLiveRemoteAccess collects the Remote Desktop and remote access
+// tool artifacts of the running system: the connections each loaded user
+// made and their bitmap caches, the RDP sessions logged within
+// RemoteAccessLookback, whether RDP and NLA are enabled, and the AnyDesk,
+// TeamViewer and ScreenConnect installs with their logs
+func CollectLiveRemoteAccess(ctx context.Context) []ArtifactResult {
+	started := time.Now()
+	results := []ArtifactResult{
+		collectRDPHistory(),
+		collectRDPSessions(ctx),
+		collectRDPCache(),
+		collectRDPSettings(),
+		collectRemoteTools(),
+	}
+	for i := range results {
+		results[i].Metadata.StartedAt = started
+	}
+	return results
+}
+
+// loadedUserSIDs returns the SIDs of the user hives loaded under HKU
+func loadedUserSIDs() []string {
+	var sids []string
+	for _, sid := range (liveASEPKey{registry.USERS, ""}).subkeyNames() {
+		if strings.HasPrefix(sid, "S-1-5-21-") && !strings.HasSuffix(sid, "_Classes") {
+			sids = append(sids, sid)
+		}
+	}
+	return sids
+}
+
+// collectRDPHistory reads the connections of each user from the
+// Terminal Server Client key of the loaded hives and from Default.rdp
+func collectRDPHistory() ArtifactResult {
+	connections := []RDPConnection{}
+	for _, sid := range loadedUserSIDs() {
+		user, _ := liveProfile(sid)
+		client := liveASEPKey{registry.USERS, sid + `\` + terminalServerClientKey}
+		if key := client.open("Default"); key != nil {
+			values := make(map[string]string)
+			for _, value := range key.values() {
+				if len(value.data) > 0 {
+					values[strings.ToUpper(value.name)] = value.data[0]
+				}
+			}
+			for i := 0; i < 10; i++ {
+				name := fmt.Sprintf("MRU%d", i)
+				if values[name] == "" {
+					continue
+				}
+				connection := RDPConnection{Source: RDPSourceMRU, User: user, SID: sid, Host: values[name], Path: `HKU\` + client.path + `\Default\` + name}
+				if i == 0 {
+					connection.LastWrite = key.modified().UTC()
+				}
+				connections = append(connections, connection)
+			}
+		}
+		if servers := client.open("Servers"); servers != nil {
+			for _, host := range servers.subkeyNames() {
+				key := servers.open(host)
+				if key == nil {
+					continue
+				}
+				connection := RDPConnection{Source: RDPSourceServers, User: user, SID: sid, Host: host, Path: `HKU\` + client.path + `\Servers\` + host, LastWrite: key.modified().UTC()}
+				for _, value := range key.values() {
+					if strings.EqualFold(value.name, "UsernameHint") && len(value.data) > 0 {
+						connection.UsernameHint = value.data[0]
+					}
+				}
+				connections = append(connections, connection)
+			}
+		}
+	}
+
+	for profile, user := range UserProfiles() {
+		path := filepath.Join(profile, "Documents", "Default.rdp")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		host, hint := ParseRDPFile(data)
+		if host == "" {
+			continue
+		}
+		connection := RDPConnection{Source: RDPSourceDefault, User: user, Host: host, UsernameHint: hint, Path: path}
+		if info, err := os.Stat(path); err == nil {
+			connection.LastWrite = info.ModTime().UTC()
+		}
+		connections = append(connections, connection)
+	}
+
+	sort.SliceStable(connections, func(i, j int) bool {
+		if connections[i].User != connections[j].User {
+			return connections[i].User < connections[j].User
+		}
+		return connections[i].Path < connections[j].Path
+	})
+	result := RemoteAccessArtifact("rdp_history", "Remote Desktop connections made by each user (Terminal Server Client MRU and Servers keys, Default.rdp)", RDPHistoryType, connections, len(connections))
+	result.Metadata.Source = "live registry"
+	return result
+}
+
+// collectRDPSessions reads the Remote Desktop logons (4624 type 10) of
+// the Security log and the session logons, disconnects and reconnects of
+// the LocalSessionManager log within RemoteAccessLookback
+func collectRDPSessions(ctx context.Context) ArtifactResult {
+	lookback := RemoteAccessLookback.Milliseconds()
+	queries := []struct{ channel, query string }{
+		{"Security", fmt.Sprintf("*[System[(EventID=4624) and TimeCreated[timediff(@SystemTime) <= %d]]] and *[EventData[Data[@Name='LogonType']='10']]", lookback)},
+		{LocalSessionManagerChannel, fmt.Sprintf("*[System[(EventID=21 or EventID=24 or EventID=25) and TimeCreated[timediff(@SystemTime) <= %d]]]", lookback)},
+	}
+
+	var events strings.Builder
+	var failures, reasons []string
+	var reason ReasonCode
+	var runs []CommandRun
+	for _, query := range queries {
+		cmd := exec.CommandContext(ctx, "wevtutil", "qe", query.channel, "/rd:true", fmt.Sprintf("/c:%d", maxRDPSessionEvents), "/f:xml", "/q:"+query.query)
+		output, run, err := RunCommand(cmd)
+		runs = append(runs, run)
+		if err != nil {
+			err = fmt.Errorf("failed to query %s: %w: %s", query.channel, err, run.Stderr)
+			failures = append(failures, err.Error())
+			code := ReasonFor(err)
+			reasons = append(reasons, fmt.Sprintf("%s=%s", query.channel, code))
+			if reason == "" || code.Blind() {
+				reason = code
+			}
+			continue
+		}
+		events.WriteString(DecodeText(output).Text)
+	}
+
+	text := events.String()
+	result := RemoteAccessArtifact("rdp_sessions", "Remote Desktop logons (Security 4624 type 10) and sessions (LocalSessionManager 21, 24, 25)", RDPSessionsType, text, strings.Count(text, "<Event "))
+	result.Artifact.Parameters["channels"] = "Security," + LocalSessionManagerChannel
+	result.Artifact.Parameters["lookback"] = RemoteAccessLookback.String()
+	result.Metadata.Source = "wevtutil"
+	result.Metadata.Commands = runs
+	if len(reasons) > 0 {
+		result.Metadata.Tags["channel_reasons"] = strings.Join(reasons, ",")
+	}
+	if text == "" && len(failures) > 0 {
+		result.Fail(reason, fmt.Errorf("no session channel could be read: %s", strings.Join(failures, "; ")))
+	}
+	return result
+}
+
+// collectRDPCache lists the bitmap cache files of each user's Remote
+// Desktop client
+func collectRDPCache() ArtifactResult {
+	files := []RDPCacheFile{}
+	for profile, user := range UserProfiles() {
+		dir := filepath.Join(profile, "AppData", "Local", "Microsoft", "Terminal Server Client", "Cache")
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			files = append(files, RDPCacheFile{
+				User:     user,
+				Path:     filepath.Join(dir, entry.Name()),
+				Size:     info.Size(),
+				Created:  fileCreated(info),
+				Modified: info.ModTime().UTC(),
+			})
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return RemoteAccessArtifact("rdp_cache", "Remote Desktop client bitmap cache files per user", RDPCacheType, files, len(files))
+}
+
+// fileCreated returns the creation time of a file, or the zero time
+func fileCreated(info os.FileInfo) time.Time {
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, attrs.CreationTime.Nanoseconds()).UTC()
+	}
+	return time.Time{}
+}
+
+// collectRDPSettings reads whether RDP is enabled and requires NLA, from
+// the Terminal Services policy when it sets them and from the Terminal
+// Server key otherwise
+func collectRDPSettings() ArtifactResult {
+	settings := RDPSettings{EnabledSource: "default", NLASource: "default", NLARequired: true, Port: 3389}
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, terminalServerKey, registry.QUERY_VALUE)
+	if err != nil {
+		result := RemoteAccessArtifact("rdp_settings", "Remote Desktop and Network Level Authentication settings", RDPSettingsType, nil, 0)
+		result.Fail(ReasonFor(err), fmt.Errorf("failed to read %s: %w", terminalServerKey, err))
+		return result
+	}
+	key.Close()
+
+	// Remote Desktop is denied unless a value allows it
+	if deny, source, modified, ok := rdpSetting("fDenyTSConnections", terminalServicesPolicyKey, terminalServerKey); ok {
+		settings.Enabled, settings.EnabledSource, settings.EnabledModified = deny == 0, source, modified
+	}
+	if nla, source, _, ok := rdpSetting("UserAuthentication", terminalServicesPolicyKey, rdpTcpKey); ok {
+		settings.NLARequired, settings.NLASource = nla != 0, source
+	}
+	if port, _, _, ok := rdpSetting("PortNumber", rdpTcpKey); ok {
+		settings.Port = int(port)
+	}
+
+	result := RemoteAccessArtifact("rdp_settings", "Remote Desktop and Network Level Authentication settings", RDPSettingsType, settings, 1)
+	result.Metadata.Source = "live registry"
+	return result
+}
+
+// rdpSetting reads a DWORD from the first of the HKLM keys that has it,
+// returning the key and its last write
+func rdpSetting(name string, keys ...string) (uint64, string, time.Time, bool) {
+	for _, path := range keys {
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		value, _, err := key.GetIntegerValue(name)
+		var modified time.Time
+		if info, statErr := key.Stat(); statErr == nil {
+			modified = info.ModTime().UTC()
+		}
+		key.Close()
+		if err == nil {
+			return value, `HKLM\` + path, modified, true
+		}
+	}
+	return 0, "", time.Time{}, false
+}
+
+// serviceStartTypes names the Start values of a service key
+var serviceStartTypes = map[uint64]string{0: "boot", 1: "system", 2: "auto", 3: "manual", 4: "disabled"}
+
+// collectRemoteTools looks for AnyDesk, TeamViewer and ScreenConnect
+// services, Uninstall entries and directories, and lists their log files
+func collectRemoteTools() ArtifactResult {
+	systemDrive, systemRoot := liveSystemPaths()
+	machineEnv := asepEnvironment(systemDrive, systemRoot, "")
+	tools := []RemoteTool{}
+
+	services := liveASEPKey{registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services`}
+	for _, name := range services.subkeyNames() {
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, services.join(name), registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		display, _, _ := key.GetStringValue("DisplayName")
+		image, _, _ := key.GetStringValue("ImagePath")
+		tool := RemoteToolOf(name + " " + display + " " + image)
+		if tool != "" {
+			start, _, _ := key.GetIntegerValue("Start")
+			var modified time.Time
+			if info, err := key.Stat(); err == nil {
+				modified = info.ModTime().UTC()
+			}
+			tools = append(tools, RemoteTool{Tool: tool, Evidence: RemoteToolService, Name: name, Path: asepImage(ASEPService, image, machineEnv), State: serviceStartTypes[start], InstalledAt: modified})
+		}
+		key.Close()
+	}
+
+	uninstall := []liveASEPKey{
+		{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`},
+		{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
+	}
+	for _, sid := range loadedUserSIDs() {
+		uninstall = append(uninstall, liveASEPKey{registry.USERS, sid + `\Software\Microsoft\Windows\CurrentVersion\Uninstall`})
+	}
+	for _, programs := range uninstall {
+		for _, name := range programs.subkeyNames() {
+			key := programs.open(name)
+			if key == nil {
+				continue
+			}
+			values := make(map[string]string)
+			for _, value := range key.values() {
+				if len(value.data) > 0 {
+					values[value.name] = value.data[0]
+				}
+			}
+			tool := RemoteToolOf(name + " " + values["DisplayName"])
+			if tool == "" {
+				continue
+			}
+			installed := key.modified().UTC()
+			if date, err := time.Parse("20060102", values["InstallDate"]); err == nil {
+				installed = date
+			}
+			display := values["DisplayName"]
+			if display == "" {
+				display = name
+			}
+			tools = append(tools, RemoteTool{Tool: tool, Evidence: RemoteToolInstall, Name: display, Path: values["InstallLocation"], InstalledAt: installed})
+		}
+	}
+
+	// Directories and logs under APPDATA are looked for in every profile,
+	// the rest once
+	var userEnvs []map[string]string
+	for profile := range UserProfiles() {
+		userEnvs = append(userEnvs, asepEnvironment(systemDrive, systemRoot, profile))
+	}
+	glob := func(patterns []string) []string {
+		seen := make(map[string]bool)
+		var paths []string
+		for _, pattern := range patterns {
+			envs := []map[string]string{machineEnv}
+			if strings.Contains(pattern, "%APPDATA%") {
+				envs = userEnvs
+			}
+			for _, env := range envs {
+				matches, _ := filepath.Glob(expandWindowsEnv(pattern, env))
+				for _, match := range matches {
+					if !seen[strings.ToLower(match)] {
+						seen[strings.ToLower(match)] = true
+						paths = append(paths, match)
+					}
+				}
+			}
+		}
+		return paths
+	}
+	for _, known := range remoteTools {
+		for _, dir := range glob(known.dirs) {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				tools = append(tools, RemoteTool{Tool: known.tool, Evidence: RemoteToolDirectory, Name: filepath.Base(dir), Path: dir, InstalledAt: fileCreated(info)})
+			}
+		}
+		logs := glob(known.logs)
+		for i := range tools {
+			if tools[i].Tool == known.tool {
+				tools[i].Logs = logs
+			}
+		}
+	}
+
+	sort.SliceStable(tools, func(i, j int) bool {
+		if tools[i].Tool != tools[j].Tool {
+			return tools[i].Tool < tools[j].Tool
+		}
+		return tools[i].Evidence > tools[j].Evidence
+	})
+	result := RemoteAccessArtifact("remote_tools", "Installed remote access tools (AnyDesk, TeamViewer, ScreenConnect): services, Uninstall entries, directories and log files", RemoteToolsType, tools, len(tools))
+	result.Metadata.Source = "live registry"
+	return result
+}
@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// CollectionScopeType is the artifact type of the collection_scope artifact
+const CollectionScopeType = "collection_scope"
+
+// ScopeCategories are the categories a collection scope allows or denies.
+// Every artifact falls in exactly one; see ScopeCategoryOf.
+var ScopeCategories = []string{
+	"system", "processes", "persistence", "network", "filesystem", "registry",
+	"event_logs", "security", "memory", "user_activity", "email", "credentials",
+	"cloud", "containers", "other",
+}
+
+// artifactScopes are the artifacts whose scope category is narrower than
+// their artifact category says, e.g. browser history is user activity
+var artifactScopes = map[string]string{
+	"running_processes":  "processes",
+	"process_tree":       "processes",
+	"processes":          "processes",
+	"services":           "persistence",
+	"scheduled_tasks":    "persistence",
+	"startup_items":      "persistence",
+	"hidden_persistence": "persistence",
+	"autostart_entries":  "persistence",
+	"scheduled_task_xml": "persistence",
+	"browser_history":    "user_activity",
+	"prefetch_files":     "user_activity",
+	"amcache":            "user_activity",
+	"shimcache":          "user_activity",
+	"execution_history":  "user_activity",
+	"unc_history":        "user_activity",
+	"mapped_drives":      "user_activity",
+	"usb_devices":        "user_activity",
+	"email_clients":      "email",
+	"cloud_credentials":  "credentials",
+	"kerberos_tickets":   "credentials",
+	"local_accounts":     "credentials",
+}
+
+// categoryScopes map artifact categories to scope categories
+var categoryScopes = map[string]string{
+	"host":            "system",
+	"system":          "system",
+	"hardware":        "system",
+	"process":         "processes",
+	"execution":       "processes",
+	"task":            "persistence",
+	"network":         "network",
+	"filesystem":      "filesystem",
+	"storage":         "filesystem",
+	"timeline":        "filesystem",
+	"registry":        "registry",
+	"logs":            "event_logs",
+	"policy":          "security",
+	"memory":          "memory",
+	"application":     "user_activity",
+	"remote_access":   "user_activity",
+	CloudCategory:     "cloud",
+	ContainerCategory: "containers",
+}
+
+// ScopeCategoryOf returns the scope category of an artifact, by its name
+// and then its category; artifacts of unknown categories are "other"
+func ScopeCategoryOf(name, category string) string {
+	if scope, ok := artifactScopes[name]; ok {
+		return scope
+	}
+	if scope, ok := categoryScopes[category]; ok {
+		return scope
+	}
+	return "other"
+}
+
+// CollectionScope is what a responder is authorized to collect for an
+// incident. Denied categories are never collected; with an allow list, only
+// its categories are. The host identity and the scope record itself are
+// always collected, as every output needs them.
+type CollectionScope struct {
+	Allow     []string  `json:"allow,omitempty"`
+	Deny      []string  `json:"deny,omitempty"`
+	Statement string    `json:"statement,omitempty"`
+	SetBy     string    `json:"set_by,omitempty"`
+	SetAt     time.Time `json:"set_at"`
+}
+
+// ParseScopeCategories reads a comma-separated list of scope categories
+func ParseScopeCategories(value string) ([]string, error) {
+	var categories []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !containsString(ScopeCategories, name) {
+			return nil, rterrors.Validationf("unknown scope category %q (valid: %s)", name, strings.Join(ScopeCategories, ", "))
+		}
+		if !containsString(categories, name) {
+			categories = append(categories, name)
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// Validate checks that the scope allows or denies something and that no
+// category is both allowed and denied
+func (s *CollectionScope) Validate() error {
+	if len(s.Allow) == 0 && len(s.Deny) == 0 {
+		return rterrors.Validationf("a collection scope needs --allow or --deny categories")
+	}
+	for _, category := range s.Deny {
+		if containsString(s.Allow, category) {
+			return rterrors.Validationf("scope category %s is both allowed and denied", category)
+		}
+	}
+	return nil
+}
+
+// Permits reports whether the scope authorizes collecting an artifact, and
+// if not, why. A nil scope permits everything.
+func (s *CollectionScope) Permits(name, category string) (bool, string) {
+	if s == nil || name == "host_identity" || name == "collection_scope" {
+		return true, ""
+	}
+	scope := ScopeCategoryOf(name, category)
+	if containsString(s.Deny, scope) {
+		return false, fmt.Sprintf("category %s is denied by the incident's collection scope", scope)
+	}
+	if !s.PermitsCategory(scope) {
+		return false, fmt.Sprintf("category %s is not among the categories the incident's collection scope allows", scope)
+	}
+	return true, ""
+}
+
+// PermitsCategory reports whether the scope authorizes a scope category
+func (s *CollectionScope) PermitsCategory(scope string) bool {
+	if s == nil {
+		return true
+	}
+	return !containsString(s.Deny, scope) && (len(s.Allow) == 0 || containsString(s.Allow, scope))
+}
+
+// String describes the scope in one line
+func (s *CollectionScope) String() string {
+	if s == nil {
+		return "none"
+	}
+	var parts []string
+	if len(s.Allow) > 0 {
+		parts = append(parts, "allow "+strings.Join(s.Allow, ", "))
+	}
+	if len(s.Deny) > 0 {
+		parts = append(parts, "deny "+strings.Join(s.Deny, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ScopeRecord is the collection_scope artifact: the scope a collection ran
+// under, the artifacts it kept out and any override
+type ScopeRecord struct {
+	Incident  string          `json:"incident,omitempty"`
+	Scope     CollectionScope `json:"scope"`
+	Skipped   []ScopeSkip     `json:"skipped,omitempty"`
+	Override  string          `json:"override_justification,omitempty"`
+	Respected bool            `json:"respected"`
+}
+
+// ScopeSkip is an artifact the scope kept out of a collection
+type ScopeSkip struct {
+	Artifact string `json:"artifact"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+}
+
+// ApplyScope replaces the results the scope does not authorize with
+// ReasonScopeDenied results that hold no data, and returns the record of
+// what it kept out. With an override justification every result is kept
+// and the record says so.
+func ApplyScope(results []ArtifactResult, scope *CollectionScope, incident, override string) ([]ArtifactResult, ScopeRecord) {
+	record := ScopeRecord{Incident: incident, Scope: *scope, Override: override, Respected: override == ""}
+	for i, result := range results {
+		ok, why := scope.Permits(result.Artifact.Name, result.Artifact.Category)
+		if ok {
+			continue
+		}
+		record.Skipped = append(record.Skipped, ScopeSkip{
+			Artifact: result.Artifact.Name,
+			Category: ScopeCategoryOf(result.Artifact.Name, result.Artifact.Category),
+			Reason:   why,
+		})
+		if override != "" {
+			continue
+		}
+		results[i] = ScopeDeniedResult(result.Artifact, why)
+	}
+	return results, record
+}
+
+// ScopeDeniedResult is the result of an artifact the collection scope kept
+// out
+func ScopeDeniedResult(artifact Artifact, why string) ArtifactResult {
+	now := time.Now()
+	result := ArtifactResult{
+		Artifact: artifact,
+		Metadata: Metadata{StartedAt: now, CollectedAt: now, Collector: "scope", Version: "1.0.0"},
+	}
+	result.Fail(ReasonScopeDenied, fmt.Errorf("not collected: %s", why))
+	return result
+}
+
+// CollectionScopeArtifact wraps a scope record as the collection_scope
+// artifact
+func CollectionScopeArtifact(record ScopeRecord) ArtifactResult {
+	artifact := NewBaseArtifact("collection_scope", "Collection scope authorized for the incident", "host", CollectionScopeType)
+	data, _ := json.Marshal(record)
+	now := time.Now()
+	return ArtifactResult{
+		Artifact: artifact.Artifact,
+		Data:     record,
+		Size:     int64(len(data)),
+		Metadata: Metadata{
+			StartedAt:   now,
+			CollectedAt: now,
+			Collector:   "scope",
+			Source:      "incident",
+			Version:     "1.0.0",
+		},
+	}
+}
+
+// FindScopeRecord returns the scope record of a collection, if it ran
+// under one
+func FindScopeRecord(results []ArtifactResult) (ScopeRecord, bool) {
+	for _, result := range results {
+		if result.Artifact.Type != CollectionScopeType {
+			continue
+		}
+		switch data := result.Data.(type) {
+		case ScopeRecord:
+			return data, true
+		case map[string]interface{}:
+			var record ScopeRecord
+			raw, err := json.Marshal(data)
+			if err != nil || json.Unmarshal(raw, &record) != nil {
+				return ScopeRecord{}, false
+			}
+			return record, true
+		}
+	}
+	return ScopeRecord{}, false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
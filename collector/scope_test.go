@@ -0,0 +1,56 @@
+package collector
+
+import "testing"
+
+// scopeTestResults is one collected artifact from each of a few scope
+// categories
+func scopeTestResults() []ArtifactResult {
+	var results []ArtifactResult
+	for _, name := range []string{"process_list", "services", "cloud_credentials", "network_connections"} {
+		results = append(results, ArtifactResult{
+			Artifact: Artifact{Name: name, Category: "host", Type: "command"},
+			Data:     name + " output",
+		})
+	}
+	return results
+}
+
+func TestApplyScopeDeniesArtifactsOutsideTheScope(t *testing.T) {
+	scope := &CollectionScope{Deny: []string{"credentials", "persistence"}, Statement: "Test authorization"}
+	if err := scope.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	results, record := ApplyScope(scopeTestResults(), scope, "INC-TEST", "")
+	denied := 0
+	for _, result := range results {
+		category := ScopeCategoryOf(result.Artifact.Name, result.Artifact.Category)
+		out := category == "credentials" || category == "persistence"
+		switch {
+		case out && (result.Reason != ReasonScopeDenied || result.Data != nil):
+			t.Errorf("%s (%s) is outside the scope but was kept", result.Artifact.Name, category)
+		case !out && result.Reason == ReasonScopeDenied:
+			t.Errorf("%s (%s) is within the scope but was denied", result.Artifact.Name, category)
+		case out:
+			denied++
+		}
+	}
+	if denied != 2 || len(record.Skipped) != denied || !record.Respected {
+		t.Errorf("scope record lists %d skipped artifacts, want %d", len(record.Skipped), denied)
+	}
+
+	kept, overridden := ApplyScope(scopeTestResults(), scope, "INC-TEST", "test override")
+	for _, result := range kept {
+		if result.Reason == ReasonScopeDenied {
+			t.Errorf("%s was denied despite the override", result.Artifact.Name)
+		}
+	}
+	if overridden.Respected || len(overridden.Skipped) != denied {
+		t.Errorf("override not recorded: %+v", overridden)
+	}
+
+	found, ok := FindScopeRecord([]ArtifactResult{CollectionScopeArtifact(record)})
+	if !ok || found.Scope.Statement != scope.Statement || len(found.Skipped) != denied {
+		t.Errorf("scope record not read back from its artifact: %+v", found)
+	}
+}
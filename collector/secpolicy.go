@@ -0,0 +1,10 @@
+package collector
+
+// Artifact types for the effective security policy. Each artifact keeps the
+// raw tool output so the detector can parse it, as with event XML.
+const (
+	GroupPolicyType    = "gpresult_xml"       // gpresult /x resultant set of policy
+	SecurityPolicyType = "secedit_inf"        // secedit /export security template
+	AuditPolicyType    = "auditpol_csv"       // auditpol /get /category:* /r
+	LocalAccountsType  = "local_accounts_csv" // local users with admin membership and password expiry
+)
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"strings"
+	"time"
+)
+
+// Artifact types for SMB shares and network drive use on Windows, stored as
+// JSON records
+const (
+	SMBSharesType    = "smb_shares_json"    // SMBShare entries
+	SMBActivityType  = "smb_activity_json"  // SMBActivity of the SMB server
+	MappedDrivesType = "mapped_drives_json" // MappedDrive entries
+	UNCHistoryType   = "unc_history_json"   // UNCAccess entries
+)
+
+// SMBLookback is how far back the share creation (5142) and hidden share
+// access (5140) events of the Security log are read
+const SMBLookback = 7 * 24 * time.Hour
+
+// SMBShare is a share the host exposes with its permissions. CreatedAt and
+// CreatedBy are only set when a share creation event was logged within
+// SMBLookback.
+type SMBShare struct {
+	Name         string           `json:"name"`
+	Path         string           `json:"path"`
+	Description  string           `json:"description,omitempty"`
+	Special      bool             `json:"special"`
+	ShareType    string           `json:"share_type,omitempty"`
+	CurrentUsers int              `json:"current_users"`
+	Access       []SMBShareAccess `json:"access,omitempty"`
+	CreatedAt    string           `json:"created_at,omitempty"`
+	CreatedBy    string           `json:"created_by,omitempty"`
+}
+
+// SMBShareAccess is one entry of a share's permissions
+type SMBShareAccess struct {
+	Account string `json:"account"`
+	Right   string `json:"right"` // Full, Change, Read or Custom
+	Type    string `json:"type"`  // Allow or Deny
+}
+
+// SMBActivity is who uses the host's SMB server: current sessions, open
+// files, logged accesses to hidden shares within SMBLookback, and the direct
+// members of the local Administrators group to judge them by
+type SMBActivity struct {
+	Administrators    []string          `json:"administrators"`
+	Sessions          []SMBSession      `json:"sessions"`
+	OpenFiles         []SMBOpenFile     `json:"open_files"`
+	HiddenShareAccess []SMBAccessRecord `json:"hidden_share_access"`
+}
+
+// SMBSession is a client session to the host's SMB server
+type SMBSession struct {
+	SessionID      string `json:"session_id"`
+	ClientComputer string `json:"client_computer"`
+	ClientUser     string `json:"client_user"`
+	Dialect        string `json:"dialect,omitempty"`
+	OpenFiles      int    `json:"open_files"`
+	Seconds        int64  `json:"seconds"`
+	IdleSeconds    int64  `json:"idle_seconds"`
+}
+
+// SMBOpenFile is a file a client holds open on the host. Share is the
+// share whose path holds it, resolved after collection.
+type SMBOpenFile struct {
+	FileID            string `json:"file_id"`
+	SessionID         string `json:"session_id"`
+	ClientComputer    string `json:"client_computer"`
+	ClientUser        string `json:"client_user"`
+	Path              string `json:"path"`
+	ShareRelativePath string `json:"share_relative_path,omitempty"`
+	Share             string `json:"share,omitempty"`
+	Locks             int    `json:"locks"`
+}
+
+// SMBAccessRecord is a logged access to a share (Security event 5140)
+type SMBAccessRecord struct {
+	Time   string `json:"time"`
+	Share  string `json:"share"`
+	User   string `json:"user"`
+	SID    string `json:"sid,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// MappedDrive is a network drive of a user: a persistent mapping from the
+// Network key of a loaded user hive, or a current SMB mapping (net use)
+type MappedDrive struct {
+	Source     string `json:"source"` // registry or net_use
+	User       string `json:"user"`
+	SID        string `json:"sid,omitempty"`
+	Drive      string `json:"drive"`
+	RemotePath string `json:"remote_path"`
+	Server     string `json:"server"`
+	Share      string `json:"share"`
+	ConnectAs  string `json:"connect_as,omitempty"`
+	Status     string `json:"status,omitempty"`
+}
+
+// UNCAccess is evidence that a user opened a UNC path: a MountPoints2 key
+// or a recent shortcut pointing at it. LastUsed is the shortcut's last
+// write; MountPoints2 entries have none.
+type UNCAccess struct {
+	Source   string `json:"source"` // mountpoints2 or lnk
+	User     string `json:"user"`
+	Target   string `json:"target"`
+	Server   string `json:"server"`
+	Share    string `json:"share"`
+	Shortcut string `json:"shortcut,omitempty"`
+	LastUsed string `json:"last_used,omitempty"`
+}
+
+// ParseUNC splits \\server\share\path into its server and share
+func ParseUNC(path string) (server, share string) {
+	path = strings.ReplaceAll(path, "/", `\`)
+	if !strings.HasPrefix(path, `\\`) {
+		return "", ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, `\\`), `\`, 3)
+	server = parts[0]
+	if len(parts) > 1 {
+		share = parts[1]
+	}
+	return server, share
+}
+
+// HiddenShare reports whether a share name is hidden from browsing (ends in
+// $), as the administrative shares C$ and ADMIN$ are. IPC$ carries named
+// pipes, not files, and is left out.
+func HiddenShare(name string) bool {
+	return strings.HasSuffix(name, "$") && !strings.EqualFold(name, "IPC$")
+}
+
+// ShareOf returns the share whose path holds a local path, preferring the
+// most specific one, so that C:\Windows\x resolves to ADMIN$ rather than C$
+func ShareOf(shares []SMBShare, path string) string {
+	best, bestLen := "", 0
+	lower := strings.ToLower(path)
+	for _, share := range shares {
+		root := strings.ToLower(strings.TrimRight(share.Path, `\`))
+		if root == "" || len(root) <= bestLen {
+			continue
+		}
+		if lower == root || strings.HasPrefix(lower, root+`\`) {
+			best, bestLen = share.Name, len(root)
+		}
+	}
+	return best
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drive types of a DiskVolume
+const (
+	DriveFixed     = "fixed"
+	DriveRemovable = "removable"
+	DriveRemote    = "remote"
+)
+
+// SystemStats is the uptime, memory and fixed-disk usage of the live host.
+// Values are raw counts; they are only made readable when displayed.
+// Figures that could not be read are named in Errors.
+type SystemStats struct {
+	UptimeSeconds uint64            `json:"uptime_seconds"`
+	BootTime      string            `json:"boot_time,omitempty"`
+	Memory        *MemoryStatus     `json:"memory,omitempty"`
+	Disks         []DiskVolume      `json:"disks,omitempty"`
+	Errors        map[string]string `json:"errors,omitempty"`
+}
+
+// MemoryStatus is the physical memory of the host in bytes. Available
+// counts memory that can be given to programs without swapping, including
+// reclaimable caches; Free counts memory not used at all.
+type MemoryStatus struct {
+	TotalBytes     uint64 `json:"total_bytes"`
+	AvailableBytes uint64 `json:"available_bytes"`
+	FreeBytes      uint64 `json:"free_bytes"`
+	UsedBytes      uint64 `json:"used_bytes"`
+}
+
+// Uptime returns the time since boot
+func (s SystemStats) Uptime() time.Duration {
+	return time.Duration(s.UptimeSeconds) * time.Second
+}
+
+// GatherSystemStats reads the uptime, memory and fixed disks of the live
+// host
+func GatherSystemStats() SystemStats {
+	var stats SystemStats
+	fail := func(figure string, err error) {
+		if stats.Errors == nil {
+			stats.Errors = make(map[string]string)
+		}
+		stats.Errors[figure] = err.Error()
+	}
+
+	if uptime, err := liveUptime(); err != nil {
+		fail("uptime", err)
+	} else {
+		stats.UptimeSeconds = uint64(uptime / time.Second)
+		stats.BootTime = time.Now().Add(-uptime).UTC().Truncate(time.Second).Format(time.RFC3339)
+	}
+	if memory, err := liveMemory(); err != nil {
+		fail("memory", err)
+	} else {
+		stats.Memory = &memory
+	}
+	if disks, err := FixedDisks(); err != nil {
+		fail("disks", err)
+	} else {
+		stats.Disks = disks
+	}
+	return stats
+}
+
+// FixedDisks lists the mounted fixed volumes with their size and free
+// space
+func FixedDisks() ([]DiskVolume, error) {
+	disks, err := liveDisks()
+	if err != nil {
+		return nil, err
+	}
+	fixed := disks[:0]
+	for _, disk := range disks {
+		if disk.DriveType == DriveFixed {
+			fixed = append(fixed, disk)
+		}
+	}
+	return fixed, nil
+}
+
+// VolumeOf returns the volume an absolute path is stored on: the one with
+// the longest mount path containing it, or nil when none does
+func VolumeOf(disks []DiskVolume, path string) *DiskVolume {
+	var best *DiskVolume
+	for i, disk := range disks {
+		rel, err := filepath.Rel(disk.Path, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(disk.Path) > len(best.Path) {
+			best = &disks[i]
+		}
+	}
+	return best
+}
+
+// ParseProcUptime reads the uptime from the first field of /proc/uptime
+func ParseProcUptime(data string) (time.Duration, error) {
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty uptime")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("invalid uptime %q", fields[0])
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ParseMeminfo reads the memory status from the contents of /proc/meminfo.
+// Kernels older than 3.14 have no MemAvailable; free memory, buffers and
+// page cache stand in for it.
+func ParseMeminfo(data string) (MemoryStatus, error) {
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(data, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return MemoryStatus{}, fmt.Errorf("invalid %s value %q", name, fields[0])
+		}
+		if len(fields) > 1 && strings.EqualFold(fields[1], "kB") {
+			n *= 1024
+		}
+		values[name] = n
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok || total == 0 {
+		return MemoryStatus{}, fmt.Errorf("no MemTotal in meminfo")
+	}
+	memory := MemoryStatus{TotalBytes: total, FreeBytes: values["MemFree"]}
+	if available, ok := values["MemAvailable"]; ok {
+		memory.AvailableBytes = available
+	} else {
+		memory.AvailableBytes = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	memory.AvailableBytes = min(memory.AvailableBytes, total)
+	memory.UsedBytes = total - memory.AvailableBytes
+	return memory, nil
+}
+
+// FormatBytes renders a byte count with a binary unit
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatUptime renders an uptime as days, hours and minutes, or hours,
+// minutes and seconds when it is shorter than a day
+func FormatUptime(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	days, hours, minutes := seconds/86400, seconds/3600%24, seconds/60%60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds%60)
+}
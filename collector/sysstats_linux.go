@@ -0,0 +1,27 @@
+//go:build linux
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// liveUptime reads the time since boot from /proc/uptime
+func liveUptime() (time.Duration, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read /proc/uptime: %w", err)
+	}
+	return ParseProcUptime(string(data))
+}
+
+// liveMemory reads the physical memory from /proc/meminfo
+func liveMemory() (MemoryStatus, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return MemoryStatus{}, fmt.Errorf("failed to read /proc/meminfo: %w", err)
+	}
+	return ParseMeminfo(string(data))
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+
+package collector
+
+import "time"
+
+// liveUptime has no uptime source on this platform
+func liveUptime() (time.Duration, error) {
+	return 0, errProfileUnsupported
+}
+
+// liveMemory has no memory source on this platform
+func liveMemory() (MemoryStatus, error) {
+	return MemoryStatus{}, errProfileUnsupported
+}
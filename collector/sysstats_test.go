@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readProcFixture reads a /proc file from testdata/proc
+func readProcFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "proc", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseProcUptime(t *testing.T) {
+	uptime, err := ParseProcUptime(readProcFixture(t, "uptime"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uptime.Truncate(time.Second) != 24*time.Hour+15*time.Minute+32*time.Second {
+		t.Errorf("uptime parsed as %s", uptime)
+	}
+	if _, err := ParseProcUptime("garbage"); err == nil {
+		t.Error("invalid /proc/uptime parsed without error")
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	memory, err := ParseMeminfo(readProcFixture(t, "meminfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MemoryStatus{TotalBytes: 16384000 << 10, AvailableBytes: 9216000 << 10, FreeBytes: 2048000 << 10, UsedBytes: 7168000 << 10}
+	if memory != want {
+		t.Errorf("meminfo parsed as %+v, want %+v", memory, want)
+	}
+
+	// Kernels before 3.14 have no MemAvailable, so it is estimated from
+	// free memory, buffers and page cache
+	legacy, err := ParseMeminfo(readProcFixture(t, "meminfo-legacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.AvailableBytes != 448000<<10 || legacy.UsedBytes != 576000<<10 {
+		t.Errorf("meminfo without MemAvailable parsed as %+v", legacy)
+	}
+
+	if _, err := ParseMeminfo("MemFree: 10 kB\n"); err == nil {
+		t.Error("meminfo without MemTotal parsed without error")
+	}
+}
+
+func TestVolumeOf(t *testing.T) {
+	root, data := filepath.FromSlash("/"), filepath.FromSlash("/srv/data")
+	disks := []DiskVolume{{Path: root}, {Path: data}, {Path: filepath.FromSlash("/srv/database")}}
+	for path, want := range map[string]string{
+		filepath.FromSlash("/srv/data/reports"): data,
+		filepath.FromSlash("/srv/database2"):    root,
+		data:                                    data,
+	} {
+		if volume := VolumeOf(disks, path); volume == nil || volume.Path != want {
+			t.Errorf("%s matched to volume %v, want %s", path, volume, want)
+		}
+	}
+}
+
+func TestFormatSystemStats(t *testing.T) {
+	for _, format := range [][2]string{
+		{FormatBytes(16384000 << 10), "15.6 GiB"},
+		{FormatBytes(512), "512 B"},
+		{FormatUptime(24*time.Hour + 15*time.Minute + 32*time.Second), "1d 0h 15m"},
+		{FormatUptime(90 * time.Second), "0h 1m 30s"},
+	} {
+		if format[0] != format[1] {
+			t.Errorf("formatted as %q, want %q", format[0], format[1])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package collector
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// globalMemoryStatusEx reports the physical and virtual memory of the host
+var globalMemoryStatusEx = windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalMemoryStatusEx")
+
+// memoryStatusEx is the MEMORYSTATUSEX structure
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// liveUptime reads the time since boot from GetTickCount64
+func liveUptime() (time.Duration, error) {
+	if err := getTickCount64.Find(); err != nil {
+		return 0, fmt.Errorf("failed to find GetTickCount64: %w", err)
+	}
+	ticks, _, _ := getTickCount64.Call()
+	return time.Duration(ticks) * time.Millisecond, nil
+}
+
+// liveMemory reads the physical memory from GlobalMemoryStatusEx. Windows
+// counts the standby cache as available and has no separate free figure.
+func liveMemory() (MemoryStatus, error) {
+	if err := globalMemoryStatusEx.Find(); err != nil {
+		return MemoryStatus{}, fmt.Errorf("failed to find GlobalMemoryStatusEx: %w", err)
+	}
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+	if ok, _, err := globalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return MemoryStatus{}, fmt.Errorf("failed to read memory status: %w", err)
+	}
+	return MemoryStatus{
+		TotalBytes:     status.TotalPhys,
+		AvailableBytes: status.AvailPhys,
+		FreeBytes:      status.AvailPhys,
+		UsedBytes:      status.TotalPhys - status.AvailPhys,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// ReadTaskDefinitions reads the scheduled task definition files under dir,
+// such as C:\Windows\System32\Tasks, into one document in the form written
+// by 'schtasks /query /xml ONE': a Tasks element holding every task, each
+// preceded by a comment with its task path. Definitions are converted from
+// UTF-16 and their XML declarations dropped. Files that cannot be read are
+// skipped and counted; reading fails only when no definition could be read.
+// The walk stays within scope; an empty scope uses the default depth.
+func ReadTaskDefinitions(dir string, scope WalkScope) (string, int, error) {
+	var tasks strings.Builder
+	read, skipped := 0, 0
+
+	_, err := walkScoped(dir, taskDefinitionScope.Override(scope), func(path, rel string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			skipped++
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			skipped++
+			return nil
+		}
+		definition := stripXMLDeclaration(DecodeText(data).Text)
+		if !strings.Contains(definition, "<Task") {
+			return nil
+		}
+
+		taskPath := `\` + strings.ReplaceAll(rel, "/", `\`)
+		// XML comments may not contain "--"
+		fmt.Fprintf(&tasks, "<!-- %s -->\n%s\n", strings.ReplaceAll(taskPath, "--", "- -"), strings.TrimSpace(definition))
+		read++
+		return nil
+	})
+	if err != nil {
+		return "", skipped, fmt.Errorf("failed to read task definitions: %w", err)
+	}
+	if read == 0 && skipped > 0 {
+		return "", skipped, fmt.Errorf("none of the %d task definitions in %s could be read", skipped, dir)
+	}
+
+	return "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Tasks>\n" + tasks.String() + "</Tasks>\n", skipped, nil
+}
+
+// stripXMLDeclaration drops the <?xml ...?> declaration, which would name
+// the original encoding
+func stripXMLDeclaration(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "<?xml") {
+		if end := strings.Index(text, "?>"); end >= 0 {
+			return text[end+2:]
+		}
+	}
+	return text
+}
@@ -0,0 +1,262 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Encodings DecodeText reports besides single-byte code pages, which are
+// named cpNNN
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+)
+
+// DefaultCodePage decodes text that is neither UTF-8 nor UTF-16 when the
+// console code page is unknown or not one of codePages
+const DefaultCodePage = 1252
+
+// codePages are the single-byte Windows code pages text can be decoded
+// from: the OEM console code pages and the ANSI code pages
+var codePages = map[int]*charmap.Charmap{
+	437:  charmap.CodePage437,
+	850:  charmap.CodePage850,
+	852:  charmap.CodePage852,
+	855:  charmap.CodePage855,
+	858:  charmap.CodePage858,
+	860:  charmap.CodePage860,
+	862:  charmap.CodePage862,
+	863:  charmap.CodePage863,
+	865:  charmap.CodePage865,
+	866:  charmap.CodePage866,
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1255: charmap.Windows1255,
+	1256: charmap.Windows1256,
+	1257: charmap.Windows1257,
+	1258: charmap.Windows1258,
+}
+
+// DecodedText is tool or file output converted to UTF-8
+type DecodedText struct {
+	Text     string
+	Encoding string // utf-8, utf-16le, utf-16be or cpNNN
+	// Invalid counts the bytes or UTF-16 units that could not be decoded
+	// and were replaced by a marker
+	Invalid int
+}
+
+// DecodeText converts tool or file output to UTF-8, decoding text that is
+// not Unicode with the console code page
+func DecodeText(data []byte) DecodedText {
+	return DecodeTextCodePage(data, consoleCodePage())
+}
+
+// DecodeTextCodePage converts data to UTF-8. UTF-16 is recognized by its
+// byte order mark or, as wevtutil and PowerShell redirects write it, by its
+// zero bytes; text that is valid UTF-8 is kept; anything else is decoded
+// with the single-byte code page, DefaultCodePage when it is not known.
+// Undecodable sequences and control characters other than whitespace are
+// replaced by a visible \xNN (or \uNNNN for UTF-16) marker instead of U+FFFD,
+// so a stray binary byte does not turn the text into replacement characters.
+func DecodeTextCodePage(data []byte, codePage int) DecodedText {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], binary.LittleEndian, EncodingUTF16LE)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], binary.BigEndian, EncodingUTF16BE)
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return decodeUTF8(data[3:])
+	}
+	if order, encoding := guessUTF16(data); order != nil {
+		return decodeUTF16(data, order, encoding)
+	}
+	if utf8.Valid(data) || mostlyUTF8(data) {
+		return decodeUTF8(data)
+	}
+
+	table, ok := codePages[codePage]
+	if !ok {
+		codePage, table = DefaultCodePage, codePages[DefaultCodePage]
+	}
+	decoded := DecodedText{Encoding: fmt.Sprintf("cp%d", codePage)}
+	var text strings.Builder
+	text.Grow(len(data))
+	for _, b := range data {
+		r := table.DecodeByte(b)
+		if r == utf8.RuneError || !isText(r) {
+			decoded.Invalid++
+			fmt.Fprintf(&text, `\x%02X`, b)
+			continue
+		}
+		text.WriteRune(r)
+	}
+	decoded.Text = text.String()
+	return decoded
+}
+
+// decodeUTF8 keeps UTF-8 text, marking invalid bytes and control characters
+func decodeUTF8(data []byte) DecodedText {
+	decoded := DecodedText{Encoding: EncodingUTF8}
+	if utf8.Valid(data) && bytes.IndexFunc(data, func(r rune) bool { return !isText(r) }) < 0 {
+		decoded.Text = string(data)
+		return decoded
+	}
+
+	var text strings.Builder
+	text.Grow(len(data))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if (r == utf8.RuneError && size == 1) || !isText(r) {
+			decoded.Invalid++
+			fmt.Fprintf(&text, `\x%02X`, data[0])
+			data = data[1:]
+			continue
+		}
+		text.Write(data[:size])
+		data = data[size:]
+	}
+	decoded.Text = text.String()
+	return decoded
+}
+
+// decodeUTF16 decodes UTF-16 in the given byte order. Unpaired surrogates
+// and control characters are marked; trailing NUL padding is dropped.
+func decodeUTF16(data []byte, order binary.ByteOrder, encoding string) DecodedText {
+	decoded := DecodedText{Encoding: encoding}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	for len(units) > 0 && units[len(units)-1] == 0 {
+		units = units[:len(units)-1]
+	}
+
+	var text strings.Builder
+	text.Grow(len(units))
+	for i := 0; i < len(units); i++ {
+		unit := units[i]
+		r := rune(unit)
+		switch {
+		case utf16.IsSurrogate(r) && i+1 < len(units):
+			if paired := utf16.DecodeRune(r, rune(units[i+1])); paired != utf8.RuneError {
+				text.WriteRune(paired)
+				i++
+				continue
+			}
+			fallthrough
+		case utf16.IsSurrogate(r) || !isText(r):
+			decoded.Invalid++
+			fmt.Fprintf(&text, `\u%04X`, unit)
+		default:
+			text.WriteRune(r)
+		}
+	}
+	if len(data)%2 == 1 {
+		decoded.Invalid++
+		fmt.Fprintf(&text, `\x%02X`, data[len(data)-1])
+	}
+	decoded.Text = text.String()
+	return decoded
+}
+
+// guessUTF16 recognizes UTF-16 without a byte order mark by the zero high
+// bytes of its ASCII characters in the first kilobyte
+func guessUTF16(data []byte) (binary.ByteOrder, string) {
+	sample := data
+	if len(sample) > 1024 {
+		sample = sample[:1024]
+	}
+	pairs := len(sample) / 2
+	if pairs < 2 {
+		return nil, ""
+	}
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*10 < pairs:
+		return binary.LittleEndian, EncodingUTF16LE
+	case evenZeros*10 >= pairs*4 && oddZeros*10 < pairs:
+		return binary.BigEndian, EncodingUTF16BE
+	}
+	return nil, ""
+}
+
+// mostlyUTF8 reports whether text that is not valid UTF-8 is still UTF-8
+// with some binary bytes rather than a single-byte code page: code page text
+// practically never holds a well-formed multi-byte sequence, and random
+// bytes hold about one per 16 invalid bytes
+func mostlyUTF8(data []byte) bool {
+	multiByte, invalid := 0, 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			invalid++
+		case size > 1:
+			multiByte++
+		}
+		data = data[size:]
+	}
+	return multiByte > 0 && invalid <= 4*multiByte
+}
+
+// isText reports whether r belongs in text: anything but C0 and C1 control
+// characters other than tab, line feed, form feed and carriage return
+func isText(r rune) bool {
+	switch {
+	case r == '\t', r == '\n', r == '\f', r == '\r':
+		return true
+	case r < 0x20, r >= 0x7F && r < 0xA0:
+		return false
+	}
+	return true
+}
+
+// SetText stores tool or file output as the result's data converted to
+// UTF-8 with DecodeText. The detected encoding is recorded in the
+// "encoding" tag and replaced sequences in "invalid_bytes"; when the
+// conversion changed the output, Raw keeps the original bytes.
+func (r *ArtifactResult) SetText(data []byte) {
+	r.SetTextCodePage(data, consoleCodePage())
+}
+
+// SetTextCodePage is SetText for output known to use a single-byte code page
+func (r *ArtifactResult) SetTextCodePage(data []byte, codePage int) {
+	decoded := DecodeTextCodePage(data, codePage)
+	r.Data = decoded.Text
+	r.Size = int64(len(decoded.Text))
+	hash := sha256.Sum256([]byte(decoded.Text))
+	r.Checksum = hex.EncodeToString(hash[:])
+	r.Raw = nil
+	if decoded.Text != string(data) {
+		r.Raw = data
+	}
+
+	if r.Metadata.Tags == nil {
+		r.Metadata.Tags = make(map[string]string)
+	}
+	r.Metadata.Tags["encoding"] = decoded.Encoding
+	if decoded.Invalid > 0 {
+		r.Metadata.Tags["invalid_bytes"] = fmt.Sprint(decoded.Invalid)
+	}
+}
@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encodingFixtures are Windows tool output in testdata/encoding in the
+// encodings the tools write: UTF-16LE with and without a byte order mark,
+// and the CP850 console code page of a German or French installation. Each
+// must decode to the text listed, without replacement characters.
+var encodingFixtures = []struct {
+	file     string
+	encoding string
+	contains []string
+}{
+	{"wmic_product.csv", EncodingUTF16LE, []string{"Agent de sécurité,Société Générale", "Überwachungsdienst,Müller GmbH"}},
+	{"wevtutil_events.txt", EncodingUTF16LE, []string{"Keywords: Überwachung fehlgeschlagen", "Kontoname: jürgen"}},
+	{"netstat_de.txt", "cp850", []string{"ABHÖREN"}},
+	{"tasklist_de.csv", "cp850", []string{"ausgeführt"}},
+	{"schtasks_fr.csv", "cp850", []string{"Prêt"}},
+}
+
+func TestSetTextCodePage(t *testing.T) {
+	for _, fixture := range encodingFixtures {
+		t.Run(fixture.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "encoding", fixture.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result ArtifactResult
+			result.SetTextCodePage(data, 850)
+
+			text := result.Data.(string)
+			if got := result.Metadata.Tags["encoding"]; got != fixture.encoding {
+				t.Errorf("detected as %s, want %s", got, fixture.encoding)
+			}
+			if strings.ContainsRune(text, '�') || result.Metadata.Tags["invalid_bytes"] != "" {
+				t.Error("undecodable text after conversion")
+			}
+			for _, want := range fixture.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("decodes without %q", want)
+				}
+			}
+			if !bytes.Equal(result.Raw, data) {
+				t.Error("original bytes not kept")
+			}
+		})
+	}
+}
+
+func TestSetTextCodePageMarksInvalidBytes(t *testing.T) {
+	var result ArtifactResult
+	result.SetTextCodePage([]byte("Dienst gestartet: Überwachung\x00\xff\xfe\x1b[31m aktiv\r\n"), 850)
+	if text := result.Data.(string); text != `Dienst gestartet: Überwachung\x00\xFF\xFE\x1B[31m aktiv`+"\r\n" {
+		t.Errorf("binary bytes in UTF-8 text are not marked: %q", text)
+	}
+	if got := result.Metadata.Tags["invalid_bytes"]; got != "4" {
+		t.Errorf("%s invalid bytes recorded, want 4", got)
+	}
+}
+
+func TestSetTextKeepsUTF8(t *testing.T) {
+	var result ArtifactResult
+	result.SetTextCodePage([]byte("Überwachung aktiv\n"), 850)
+	if result.Data.(string) != "Überwachung aktiv\n" || result.Raw != nil {
+		t.Errorf("UTF-8 output changed: %q (raw %q)", result.Data, result.Raw)
+	}
+}
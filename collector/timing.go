@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"sort"
+	"time"
+)
+
+// ArtifactTiming is how long one artifact took to collect
+type ArtifactTiming struct {
+	Name       string        `json:"name"`
+	Category   string        `json:"category"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration"`
+	Failed     bool          `json:"failed"`
+}
+
+// recordTimings fills in the start time and duration of each artifact in a
+// batch returned by a platform collector. Collectors gather a batch one
+// artifact at a time and stamp CollectedAt when an artifact is finished, so
+// an artifact that carries no start time of its own started when the
+// previous one finished.
+func recordTimings(batchStart time.Time, results []ArtifactResult) []ArtifactResult {
+	previous := batchStart
+	for i := range results {
+		metadata := &results[i].Metadata
+		if metadata.StartedAt.IsZero() {
+			metadata.StartedAt = previous
+		}
+		if metadata.CollectedAt.Before(metadata.StartedAt) {
+			metadata.CollectedAt = metadata.StartedAt
+		}
+		if metadata.Duration == 0 {
+			metadata.Duration = metadata.CollectedAt.Sub(metadata.StartedAt)
+		}
+		if metadata.CollectedAt.After(previous) {
+			previous = metadata.CollectedAt
+		}
+	}
+	return results
+}
+
+// ArtifactTimings returns the collection time of every artifact, slowest
+// first
+func ArtifactTimings(results []ArtifactResult) []ArtifactTiming {
+	timings := make([]ArtifactTiming, 0, len(results))
+	for _, result := range results {
+		timings = append(timings, ArtifactTiming{
+			Name:       result.Artifact.Name,
+			Category:   result.Artifact.Category,
+			StartedAt:  result.Metadata.StartedAt,
+			FinishedAt: result.Metadata.CollectedAt,
+			Duration:   result.Metadata.Duration,
+			Failed:     result.Error != nil,
+		})
+	}
+
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Duration > timings[j].Duration
+	})
+	return timings
+}
@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WalkScope bounds the directory walk of a file metadata collector. MaxDepth
+// counts the directory levels listed: 1 lists the entries of the walk root,
+// 2 also those of its subdirectories. Allow and Deny are globs matched,
+// without regard to case, against a directory's name or its slash-separated
+// path below the walk root. With Allow set only matching directories, and
+// what lies below them, are entered; Deny always wins. Hidden entries, whose
+// names start with a dot, are skipped and counted unless IncludeHidden is
+// set.
+type WalkScope struct {
+	MaxDepth      int      `json:"max_depth"`
+	Allow         []string `json:"allow_dirs,omitempty"`
+	Deny          []string `json:"deny_dirs,omitempty"`
+	IncludeHidden bool     `json:"include_hidden,omitempty"`
+}
+
+// Default scopes of the walks that are not offline listings. They look in
+// specific locations where a hidden file matters as much as any other.
+var (
+	cloudCredentialScope   = WalkScope{MaxDepth: 3, IncludeHidden: true}
+	taskDefinitionScope    = WalkScope{MaxDepth: 8, IncludeHidden: true}
+	hiddenPersistenceScope = WalkScope{MaxDepth: 3, IncludeHidden: true}
+)
+
+// Override returns the scope with the settings of o applied: a depth
+// replaces the default, allowed directories replace the default ones,
+// denied directories are added to them and hidden entries are included when
+// either scope includes them
+func (s WalkScope) Override(o WalkScope) WalkScope {
+	if o.MaxDepth > 0 {
+		s.MaxDepth = o.MaxDepth
+	}
+	s.IncludeHidden = s.IncludeHidden || o.IncludeHidden
+	if len(o.Allow) > 0 {
+		s.Allow = o.Allow
+	}
+	deny := append([]string{}, s.Deny...)
+	for _, pattern := range o.Deny {
+		seen := false
+		for _, have := range deny {
+			seen = seen || strings.EqualFold(have, pattern)
+		}
+		if !seen {
+			deny = append(deny, pattern)
+		}
+	}
+	s.Deny = deny
+	return s
+}
+
+// Validate checks the depth and that every pattern stays below the walk root
+func (s WalkScope) Validate() error {
+	if s.MaxDepth < 0 {
+		return fmt.Errorf("directory depth cannot be negative, got %d", s.MaxDepth)
+	}
+	for _, pattern := range append(append([]string{}, s.Allow...), s.Deny...) {
+		if err := ValidateDirPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateDirPattern rejects directory patterns that are empty, absolute,
+// climb out of the walk root or are not valid globs
+func ValidateDirPattern(pattern string) error {
+	p := strings.ReplaceAll(pattern, `\`, "/")
+	switch {
+	case strings.TrimSpace(p) == "":
+		return fmt.Errorf("directory pattern cannot be empty")
+	case strings.HasPrefix(p, "/") || filepath.IsAbs(pattern) || (len(p) >= 2 && p[1] == ':'):
+		return fmt.Errorf("directory pattern %q must be relative to the walk root", pattern)
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return fmt.Errorf("directory pattern %q cannot leave the walk root", pattern)
+		}
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("invalid directory pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// enters reports whether the walk descends into the directory at rel, a
+// slash-separated path below the walk root
+func (s WalkScope) enters(rel string) bool {
+	if strings.Count(rel, "/")+1 >= s.MaxDepth {
+		return false
+	}
+	for _, pattern := range s.Deny {
+		if matchDir(pattern, rel) {
+			return false
+		}
+	}
+	if len(s.Allow) == 0 {
+		return true
+	}
+	for dir := rel; dir != "."; dir = path.Dir(dir) {
+		for _, pattern := range s.Allow {
+			if matchDir(pattern, dir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchDir matches a directory pattern against the name and the path of dir
+func matchDir(pattern, dir string) bool {
+	pattern = strings.ToLower(strings.Trim(strings.ReplaceAll(pattern, `\`, "/"), "/"))
+	dir = strings.ToLower(dir)
+	if ok, _ := path.Match(pattern, dir); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, path.Base(dir))
+	return ok
+}
+
+// walkCounts are the entries a scoped walk left out
+type walkCounts struct {
+	notEntered int // directories the scope kept the walk out of
+	hidden     int // hidden entries skipped
+}
+
+// record puts the effective scope, and what it left out of the walk, in
+// the parameters of an artifact, which the manifest keeps
+func (s WalkScope) record(params map[string]string, counts walkCounts) {
+	params["max_depth"] = fmt.Sprint(s.MaxDepth)
+	if len(s.Allow) > 0 {
+		params["allow_dirs"] = strings.Join(s.Allow, ",")
+	}
+	if len(s.Deny) > 0 {
+		params["deny_dirs"] = strings.Join(s.Deny, ",")
+	}
+	if s.IncludeHidden {
+		params["include_hidden"] = "true"
+	}
+	if counts.notEntered > 0 {
+		params["dirs_not_entered"] = fmt.Sprint(counts.notEntered)
+	}
+	if counts.hidden > 0 {
+		params["hidden_skipped"] = fmt.Sprint(counts.hidden)
+	}
+}
+
+// isHidden reports whether a file name is a Unix hidden entry
+func isHidden(name string) bool {
+	return len(name) > 1 && name[0] == '.' && name != ".."
+}
+
+// walkScoped walks root within scope, calling fn for every entry below it,
+// directories not entered included, or for root itself when it is a file.
+// Hidden entries below root are not passed to fn unless the scope includes
+// them. Links are not followed. It returns what the scope left out.
+func walkScoped(root string, scope WalkScope, fn func(path, rel string, entry fs.DirEntry, err error) error) (walkCounts, error) {
+	var counts walkCounts
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if path == root {
+			if err == nil && !entry.IsDir() {
+				return fn(path, entry.Name(), entry, nil)
+			}
+			return err
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+		if !scope.IncludeHidden && entry != nil && isHidden(entry.Name()) {
+			counts.hidden++
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if fnErr := fn(path, rel, entry, err); fnErr != nil {
+			return fnErr
+		}
+		if err == nil && entry.IsDir() && !scope.enters(rel) {
+			counts.notEntered++
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return counts, err
+}
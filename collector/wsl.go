@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WSLWindowsPrefix starts the name of every artifact collected from the
+// Windows side of a WSL host, keeping them apart from the Linux artifacts
+const WSLWindowsPrefix = "windows_host_"
+
+// wslInteropCommands collect the live Windows state that files on the
+// system drive do not hold, run through WSL interop. Paths are relative to
+// the Windows root.
+var wslInteropCommands = []struct {
+	name, description, category, parser string
+	command                             []string
+}{
+	{"running_processes", "Windows processes, via tasklist.exe over WSL interop", "process", "csv",
+		[]string{"Windows/System32/tasklist.exe", "/v", "/fo", "csv"}},
+	{"network_connections", "Windows network connections, via netstat.exe over WSL interop", "network", "lines",
+		[]string{"Windows/System32/NETSTAT.EXE", "-ano"}},
+	{"logged_on_users", "Windows logged on users, via query.exe over WSL interop", "user", "lines",
+		[]string{"Windows/System32/query.exe", "user"}},
+}
+
+// CollectWSLWindowsHost collects the Windows side of a WSL host: files and
+// listings from the system drive, as from an offline image, and processes,
+// connections and sessions through WSL interop. Artifact names carry the
+// windows_host_ prefix. Files Windows holds locked, such as the registry
+// hives of the running system, are reported as errors. scope overrides the
+// default scope of the directory walks.
+func CollectWSLWindowsHost(ctx context.Context, env Environment, extended bool, scope WalkScope) []ArtifactResult {
+	if env.Kind != EnvironmentWSL || env.WindowsRoot == "" {
+		return nil
+	}
+
+	offline := NewOfflineCollector(env.WindowsRoot)
+	offline.scope = scope
+	var results []ArtifactResult
+	if profile, err := offline.CollectHostProfile(ctx); err == nil {
+		results = append(results, *profile)
+	}
+	if basic, err := offline.CollectBasicArtifacts(ctx); err == nil {
+		results = append(results, basic...)
+	}
+	if extended {
+		if more, err := offline.CollectExtendedArtifacts(ctx); err == nil {
+			results = append(results, more...)
+		}
+	}
+
+	var windows []ArtifactResult
+	for _, result := range results {
+		// Interop replaces what an image cannot provide
+		if result.Error == ErrLiveOnly {
+			continue
+		}
+		if path := result.Artifact.Parameters["path"]; result.Artifact.Type == "file" && path != "" {
+			if err := checkReadable(path); err != nil {
+				delete(result.Artifact.Parameters, "path")
+				result.Data = err.Error()
+				result.Fail(ReasonFor(err), err)
+			}
+		}
+		windows = append(windows, result)
+	}
+
+	for _, c := range wslInteropCommands {
+		artifact := NewEnhancedArtifact(c.name, c.description, c.category, "command", customForensicType, 1)
+		artifact.Platform = "windows"
+		artifact.Timeout = 60 * time.Second
+		artifact.Command = append([]string{filepath.Join(env.WindowsRoot, filepath.FromSlash(c.command[0]))}, c.command[1:]...)
+		artifact.Parameters["parser"] = c.parser
+		result := RunCustomArtifact(ctx, artifact)
+		result.Metadata.Collector = "wsl_interop"
+		windows = append(windows, result)
+	}
+
+	for i := range windows {
+		windows[i].Artifact.Name = WSLWindowsPrefix + windows[i].Artifact.Name
+		if windows[i].Metadata.Tags == nil {
+			windows[i].Metadata.Tags = map[string]string{}
+		}
+		windows[i].Metadata.Tags["wsl_windows_root"] = env.WindowsRoot
+	}
+	return windows
+}
+
+// markWindowsCollected records on the host identity of a collection that
+// the Windows side of its WSL host was collected
+func markWindowsCollected(results []ArtifactResult) {
+	for i := range results {
+		if identity, ok := results[i].Data.(HostIdentity); ok && results[i].Artifact.Type == HostIdentityType && identity.Environment != nil {
+			env := *identity.Environment
+			env.WindowsCollected = true
+			identity.Environment = &env
+			results[i].Data = identity
+		}
+	}
+}
+
+// checkReadable reports why a file cannot be read, if it cannot
+func checkReadable(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s from the running Windows system: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := file.Read(make([]byte, 1)); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot read %s from the running Windows system: %w", path, err)
+	}
+	return nil
+}
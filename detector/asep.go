@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// recentAutostartWindow is how long before collection an autostart key must
+// have been written for its entries to count as recently added
+const recentAutostartWindow = 30 * 24 * time.Hour
+
+// evaluateAutostartRule flags autostart entries whose key was written in the
+// 30 days before collection and whose image lies in a user-writable
+// location, one finding per entry. Validly signed images are left out.
+// Unsigned images in temp or AppData style locations are high severity;
+// the rest, including images whose signature was not checked, are medium.
+func (d *Detector) evaluateAutostartRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.ASEPType {
+			continue
+		}
+		collectedAt := artifact.Metadata.CollectedAt
+		if collectedAt.IsZero() {
+			collectedAt = time.Now()
+		}
+		for i, entry := range collector.ASEPEntries(artifact) {
+			if entry.KeyModified.IsZero() || collectedAt.Sub(entry.KeyModified) > recentAutostartWindow {
+				continue
+			}
+			risk := ExecutableLocationRisk(entry.Image)
+			if risk != LocationRiskHigh && risk != LocationRiskMedium {
+				continue
+			}
+			valid := signatureValid(entry.SignatureStatus)
+			if valid != nil && *valid {
+				continue
+			}
+			severity := "medium"
+			if risk == LocationRiskHigh && valid != nil {
+				severity = "high"
+			}
+			ref := collector.NewRecordRef("", artifact.Artifact.Name, "", i)
+			findings = append(findings, autostartFinding(rule, ref, entry, risk, severity))
+		}
+	}
+	return findings
+}
+
+// autostartFinding builds the finding for the autostart entry ref points at
+func autostartFinding(rule Rule, ref *collector.RecordRef, entry collector.ASEPEntry, risk, severity string) Finding {
+	signature := entry.SignatureStatus
+	if signature == "" {
+		signature = "not checked"
+	}
+	return Finding{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Severity:    severity,
+		Category:    rule.Category,
+		Description: fmt.Sprintf("%s (%s) starts %s from a %s risk location; the key was written %s, signature %s", entry.Name(), strings.ReplaceAll(entry.Group, "_", " "), entry.Image, risk, entry.KeyModified.UTC().Format(time.RFC3339), signature),
+		Evidence: []Evidence{{
+			Type:        "asep_entry",
+			Source:      ref.Artifact,
+			Value:       entry.Data,
+			Description: fmt.Sprintf("%s = %s", entry.Name(), entry.Data),
+			Confidence:  0.7,
+			Metadata:    map[string]interface{}{"group": entry.Group, "location_risk": risk, "image_missing": entry.ImageMissing},
+			Ref:         ref,
+		}},
+		Tags:      rule.Tags,
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"key":              entry.Key,
+			"value_name":       entry.ValueName,
+			"image":            entry.Image,
+			"sha256":           entry.SHA256,
+			"signature_status": entry.SignatureStatus,
+			"signer":           entry.Signer,
+			"user":             entry.User,
+			"key_modified":     entry.KeyModified.UTC().Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package detector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+func TestAutostartRuleFlagsRecentUserWritableEntries(t *testing.T) {
+	recent := time.Now().Add(-48 * time.Hour).UTC()
+	old := time.Date(2023, 3, 14, 9, 30, 0, 0, time.UTC)
+	updater := collector.ASEPEntry{
+		Group:       "run_keys",
+		Key:         `HKU\alice\Software\Microsoft\Windows\CurrentVersion\Run`,
+		ValueName:   "Updater",
+		Data:        `"%LOCALAPPDATA%\Updater\updater.exe" --silent`,
+		KeyModified: recent,
+		User:        "alice",
+		Image:       `C:\Users\alice\AppData\Local\Updater\updater.exe`,
+	}
+	signed, unsigned := updater, updater
+	signed.ValueName, signed.SignatureStatus = "Signed", "Valid"
+	unsigned.ValueName, unsigned.SignatureStatus = "Unsigned", "NotSigned"
+	entries := []collector.ASEPEntry{
+		updater, signed, unsigned,
+		{Group: "services", Key: `HKLM\SYSTEM\ControlSet001\Services\SyncHost`, ValueName: "ImagePath", Data: `"C:\ProgramData\SyncHost\synchost.exe" -service`, KeyModified: recent, Image: `C:\ProgramData\SyncHost\synchost.exe`, ImageMissing: true},
+		{Group: "run_keys", Key: `HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`, ValueName: "LegacyAgent", Data: `C:\Users\Public\agent.exe`, KeyModified: old, Image: `C:\Users\Public\agent.exe`},
+		{Group: "ifeo", Key: `HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion\Image File Execution Options\sethc.exe`, ValueName: "Debugger", Data: `C:\Windows\System32\cmd.exe`, KeyModified: recent, Image: `C:\Windows\System32\cmd.exe`},
+	}
+
+	findings, err := NewDetector().Evaluate([]collector.ArtifactResult{collector.ASEPArtifact(entries)})
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	severities := make(map[string]string)
+	for _, finding := range findings {
+		if finding.RuleID == "RT017" {
+			severities[fmt.Sprint(finding.Metadata["value_name"])] = finding.Severity
+		}
+	}
+	want := map[string]string{"ImagePath": "medium", "Updater": "medium", "Unsigned": "high"}
+	if fmt.Sprint(severities) != fmt.Sprint(want) {
+		t.Errorf("autostart findings %v, want %v", severities, want)
+	}
+}
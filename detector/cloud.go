@@ -0,0 +1,207 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// unusualProfileNames are profiles no person signs in to: shared, template
+// and service account profiles. Cloud credentials there were planted or left
+// by a service and are usable by anyone who can read the profile.
+var unusualProfileNames = map[string]bool{
+	"public":             true,
+	"default":            true,
+	"default user":       true,
+	"all users":          true,
+	"guest":              true,
+	"defaultaccount":     true,
+	"wdagutilityaccount": true,
+	"systemprofile":      true,
+	"localservice":       true,
+	"networkservice":     true,
+}
+
+// serviceAccountNames are Unix service accounts that should not hold cloud
+// credentials
+var serviceAccountNames = map[string]bool{
+	"www-data": true, "nobody": true, "apache": true, "nginx": true,
+	"httpd": true, "daemon": true, "mysql": true, "postgres": true,
+}
+
+// unusualProfileRoots are directories that hold the homes of web and
+// service accounts rather than people
+var unusualProfileRoots = []string{"/var/www", "/srv", "/dev/shm", "/nonexistent"}
+
+// UnusualCredentialProfile says why cloud credentials in a profile are
+// unusual, or returns "" when they are not
+func UnusualCredentialProfile(profile, user string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(profile, `\`, "/"))
+	base := path.Base(normalized)
+	switch {
+	case unusualProfileNames[base]:
+		return "shared or service profile " + base
+	case serviceAccountNames[strings.ToLower(user)]:
+		return "service account " + user
+	case strings.Contains(normalized+"/", "/temp/"), strings.Contains(normalized+"/", "/tmp/"):
+		return "profile in a temporary directory"
+	}
+	for _, root := range unusualProfileRoots {
+		if normalized == root || strings.HasPrefix(normalized, root+"/") {
+			return "home under " + root
+		}
+	}
+	return ""
+}
+
+// evaluateJoinStateRule flags device join states that suggest the device was
+// registered to an attacker's tenant or its device identity is broken: a
+// workplace registration to a tenant other than the one the device is joined
+// to, a failing device key signing test, and failing device authentication.
+func (d *Detector) evaluateJoinStateRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.CloudJoinStateType {
+			continue
+		}
+		var state collector.JoinState
+		if err := json.Unmarshal([]byte(artifactText(artifact)), &state); err != nil {
+			continue
+		}
+
+		var anomalies []struct{ severity, description string }
+		add := func(severity, format string, args ...interface{}) {
+			anomalies = append(anomalies, struct{ severity, description string }{severity, fmt.Sprintf(format, args...)})
+		}
+		if state.WorkplaceJoined && state.WorkplaceTenantID != "" && state.TenantID != "" && !strings.EqualFold(state.WorkplaceTenantID, state.TenantID) {
+			add(rule.Severity, "Device is workplace registered to tenant %s (%s), not the tenant it is joined to, %s (%s)",
+				state.WorkplaceTenantID, state.WorkplaceTenantName, state.TenantID, state.TenantName)
+		}
+		if strings.Contains(strings.ToUpper(state.KeySignTest), "FAIL") {
+			add(rule.Severity, "Device key signing test failed (%s); the device key may have been removed or replaced", state.KeySignTest)
+		}
+		if state.AzureADJoined && state.DeviceAuthStatus != "" && !strings.EqualFold(state.DeviceAuthStatus, "SUCCESS") {
+			add("medium", "Device authentication to tenant %s is failing (%s); the device object may be disabled or deleted", state.TenantID, state.DeviceAuthStatus)
+		}
+
+		for _, anomaly := range anomalies {
+			metadata := map[string]interface{}{
+				"device_id":           state.DeviceID,
+				"tenant_id":           state.TenantID,
+				"tenant_name":         state.TenantName,
+				"workplace_tenant_id": state.WorkplaceTenantID,
+				"azure_ad_joined":     state.AzureADJoined,
+				"domain_joined":       state.DomainJoined,
+				"workplace_joined":    state.WorkplaceJoined,
+				"device_auth_status":  state.DeviceAuthStatus,
+				"key_sign_test":       state.KeySignTest,
+			}
+			findings = append(findings, Finding{
+				RuleID:      rule.ID,
+				RuleName:    rule.Name,
+				Severity:    anomaly.severity,
+				Category:    rule.Category,
+				Description: anomaly.description,
+				Evidence: []Evidence{
+					{
+						Type:        "join_state",
+						Source:      artifact.Artifact.Name,
+						Value:       state.DeviceID,
+						Description: "dsregcmd /status",
+						Confidence:  0.7,
+						Metadata:    metadata,
+					},
+				},
+				Tags:      rule.Tags,
+				Timestamp: time.Now(),
+				Metadata:  metadata,
+			})
+		}
+	}
+	return findings
+}
+
+// evaluateCloudCredentialRule flags cloud CLI credentials in shared, service
+// account and temporary profiles, one finding per profile
+func (d *Detector) evaluateCloudCredentialRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	type profileFiles struct {
+		reason, user, source string
+		paths, providers     []string
+	}
+	profiles := make(map[string]*profileFiles)
+	var order []string
+
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.CloudCredentialsType {
+			continue
+		}
+		var files []collector.CloudCredentialFile
+		if err := json.Unmarshal([]byte(artifactText(artifact)), &files); err != nil {
+			continue
+		}
+		for _, file := range files {
+			reason := UnusualCredentialProfile(file.Profile, file.User)
+			if reason == "" {
+				continue
+			}
+			entry, ok := profiles[file.Profile]
+			if !ok {
+				entry = &profileFiles{reason: reason, user: file.User, source: artifact.Artifact.Name}
+				profiles[file.Profile] = entry
+				order = append(order, file.Profile)
+			}
+			entry.paths = append(entry.paths, file.Path)
+			if !containsString(entry.providers, file.Provider) {
+				entry.providers = append(entry.providers, file.Provider)
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, profile := range order {
+		entry := profiles[profile]
+		sort.Strings(entry.providers)
+		metadata := map[string]interface{}{
+			"profile":   profile,
+			"user":      entry.user,
+			"reason":    entry.reason,
+			"providers": entry.providers,
+			"paths":     entry.paths,
+		}
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    rule.Severity,
+			Category:    rule.Category,
+			Description: fmt.Sprintf("%s credentials in %s (%s)", strings.Join(entry.providers, ", "), profile, entry.reason),
+			Evidence: []Evidence{
+				{
+					Type:        "credential_file",
+					Source:      entry.source,
+					Value:       entry.paths[0],
+					Description: fmt.Sprintf("%d cloud credential file(s)", len(entry.paths)),
+					Confidence:  0.8,
+					Metadata:    metadata,
+				},
+			},
+			Tags:      rule.Tags,
+			Timestamp: time.Now(),
+			Metadata:  metadata,
+		})
+	}
+	return findings
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
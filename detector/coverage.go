@@ -0,0 +1,70 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// CoverageGap is an artifact, or one event channel of it, that the
+// detections could not look at
+type CoverageGap struct {
+	Artifact string               `json:"artifact"`
+	Channel  string               `json:"channel,omitempty"`
+	Category string               `json:"category"`
+	Reason   collector.ReasonCode `json:"reason"`
+}
+
+// String names the gap and its reason, e.g. "security_logs
+// (Security: access denied)"
+func (g CoverageGap) String() string {
+	if g.Channel != "" {
+		return fmt.Sprintf("%s (%s: %s)", g.Artifact, g.Channel, strings.ToLower(g.Reason.Label()))
+	}
+	return fmt.Sprintf("%s (%s)", g.Artifact, strings.ToLower(g.Reason.Label()))
+}
+
+// CoverageGaps lists the artifacts and event channels that were denied,
+// lacked their tool or timed out. No findings from them says nothing about
+// the host, unlike data that is not present at all.
+func CoverageGaps(artifacts []collector.ArtifactResult) []CoverageGap {
+	var gaps []CoverageGap
+	for _, artifact := range artifacts {
+		channelGaps := 0
+		for _, entry := range strings.Split(artifact.Metadata.Tags["channel_reasons"], ",") {
+			channel, reason, ok := strings.Cut(entry, "=")
+			if ok && collector.ReasonCode(reason).Blind() {
+				gaps = append(gaps, CoverageGap{
+					Artifact: artifact.Artifact.Name,
+					Channel:  channel,
+					Category: artifact.Artifact.Category,
+					Reason:   collector.ReasonCode(reason),
+				})
+				channelGaps++
+			}
+		}
+		if channelGaps == 0 && artifact.Reason.Blind() {
+			gaps = append(gaps, CoverageGap{
+				Artifact: artifact.Artifact.Name,
+				Category: artifact.Artifact.Category,
+				Reason:   artifact.Reason,
+			})
+		}
+	}
+	return gaps
+}
+
+// evaluableArtifacts drops the artifacts that failed for a known reason:
+// they hold an error message or partial output, not data to match rules
+// against. Truncated artifacts are kept.
+func evaluableArtifacts(artifacts []collector.ArtifactResult) []collector.ArtifactResult {
+	evaluable := make([]collector.ArtifactResult, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if artifact.Error != nil && artifact.Reason != "" && artifact.Reason != collector.ReasonTruncated {
+			continue
+		}
+		evaluable = append(evaluable, artifact)
+	}
+	return evaluable
+}
@@ -0,0 +1,43 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+func TestDeniedArtifactsAreCoverageGaps(t *testing.T) {
+	// A denied log whose error text would match a rule, and an event log
+	// artifact with Sysmon missing and Security denied
+	denied := collector.ArtifactResult{
+		Artifact: collector.NewBaseArtifact("system_logs", "System logs", "log", "command").Artifact,
+		Data:     "suspicious: access denied",
+		Metadata: collector.Metadata{Tags: map[string]string{}},
+	}
+	denied.Fail(collector.ReasonAccessDenied, rterrors.Permissionf("suspicious: access denied"))
+	channels := collector.ArtifactResult{
+		Artifact: collector.NewBaseArtifact("event_logs", "Event logs", "log", collector.EventLogXMLType).Artifact,
+		Metadata: collector.Metadata{Tags: map[string]string{
+			"channel_reasons": "Microsoft-Windows-Sysmon/Operational=not_present,Security=access_denied",
+		}},
+	}
+	artifacts := []collector.ArtifactResult{denied, channels}
+
+	findings, err := NewDetector().Evaluate(artifacts)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(findings) > 0 {
+		t.Errorf("denied artifact was evaluated: %s", findings[0].RuleID)
+	}
+	gaps := CoverageGaps(artifacts)
+	if len(gaps) != 2 || gaps[0].Artifact != "system_logs" || gaps[1].Channel != "Security" {
+		t.Errorf("coverage gaps %v, want system_logs and the Security channel only", gaps)
+	}
+	if strings.Contains(fmt.Sprint(gaps), "Sysmon") {
+		t.Error("channel that is not present reported as a coverage gap")
+	}
+}
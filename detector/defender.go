@@ -0,0 +1,154 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// Windows Defender operational events that become findings
+const (
+	defenderDetectionEventID = 1116 // Malware or potentially unwanted software detected
+	defenderActionEventID    = 1117 // Action taken to protect the system
+)
+
+// defenderDetection combines a 1116 detection with its 1117 action event
+type defenderDetection struct {
+	detectionID string
+	threatName  string
+	severity    string
+	category    string
+	path        string
+	process     string
+	user        string
+	action      string
+	actionTaken bool
+	computer    string
+	detectedAt  time.Time
+}
+
+// evaluateDefenderRule turns Defender detection and action events into
+// findings carrying the threat name and the action taken
+func (d *Detector) evaluateDefenderRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var events []WinEvent
+	for _, artifact := range eventArtifacts(artifacts, collector.DefenderOperationalChannel) {
+		parsed, _ := ParseEventXML(artifactText(artifact))
+		events = append(events, parsed...)
+	}
+
+	detections := make(map[string]*defenderDetection)
+	var order []string
+
+	for _, event := range events {
+		if event.EventID != defenderDetectionEventID && event.EventID != defenderActionEventID {
+			continue
+		}
+
+		// Events without a detection ID are keyed by record so none are lost
+		key := event.Data["Detection ID"]
+		if key == "" {
+			key = fmt.Sprintf("record-%d", event.RecordID)
+		}
+
+		detection, ok := detections[key]
+		if !ok {
+			detection = &defenderDetection{detectionID: event.Data["Detection ID"]}
+			detections[key] = detection
+			order = append(order, key)
+		}
+
+		detection.merge(event)
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		detection := detections[key]
+
+		action := detection.action
+		if action == "" {
+			action = "none recorded"
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    defenderSeverity(detection.severity),
+			Category:    rule.Category,
+			Description: fmt.Sprintf("Windows Defender detected %s (action: %s)", detection.threatName, action),
+			Evidence: []Evidence{
+				{
+					Type:        "defender_detection",
+					Source:      collector.DefenderOperationalChannel,
+					Value:       detection.threatName,
+					Description: fmt.Sprintf("Threat detected at %s", detection.path),
+					Confidence:  0.95,
+					Metadata: map[string]interface{}{
+						"detection_id": detection.detectionID,
+						"process":      detection.process,
+						"user":         detection.user,
+					},
+				},
+			},
+			Tags:      rule.Tags,
+			Timestamp: time.Now(),
+			Metadata: map[string]interface{}{
+				"threat_name":     detection.threatName,
+				"threat_category": detection.category,
+				"action":          action,
+				"action_taken":    detection.actionTaken,
+				"path":            detection.path,
+				"detection_id":    detection.detectionID,
+				"computer":        detection.computer,
+				"detected_at":     detection.detectedAt,
+			},
+		})
+	}
+
+	return findings
+}
+
+// merge fills in the detection from a 1116 or 1117 event
+func (dd *defenderDetection) merge(event WinEvent) {
+	setIfEmpty := func(field *string, value string) {
+		if *field == "" {
+			*field = strings.TrimSpace(value)
+		}
+	}
+
+	setIfEmpty(&dd.threatName, event.Data["Threat Name"])
+	setIfEmpty(&dd.severity, event.Data["Severity Name"])
+	setIfEmpty(&dd.category, event.Data["Category Name"])
+	setIfEmpty(&dd.path, event.Data["Path"])
+	setIfEmpty(&dd.process, event.Data["Process Name"])
+	setIfEmpty(&dd.user, event.Data["Detection User"])
+	setIfEmpty(&dd.computer, event.Computer)
+
+	if event.EventID == defenderDetectionEventID && (dd.detectedAt.IsZero() || event.TimeCreated.Before(dd.detectedAt)) {
+		dd.detectedAt = event.TimeCreated
+	}
+	if event.EventID == defenderActionEventID {
+		dd.action = strings.TrimSpace(event.Data["Action Name"])
+		dd.actionTaken = true
+	}
+	if dd.detectedAt.IsZero() {
+		dd.detectedAt = event.TimeCreated
+	}
+}
+
+// defenderSeverity maps Defender severity names onto RedTriage severities
+func defenderSeverity(name string) string {
+	switch strings.ToLower(name) {
+	case "severe":
+		return "critical"
+	case "high":
+		return "high"
+	case "moderate", "medium":
+		return "medium"
+	case "low":
+		return "low"
+	default:
+		return "high"
+	}
+}
@@ -0,0 +1,30 @@
+package detector
+
+import "github.com/redtriage/redtriage/collector"
+
+// derivedTypes are the artifact types DerivedArtifacts produces
+var derivedTypes = map[string]bool{
+	"script":                     true,
+	"security_posture":           true,
+	"process_records":            true,
+	"network_connection_records": true,
+	"scheduled_task_records":     true,
+	"scheduled_task_definitions": true,
+	"event_records":              true,
+}
+
+// DerivedArtifacts builds the artifacts that 'collect' adds to the collected
+// ones before detection: the reassembled script blocks, the parsed security
+// posture and the structured host records
+func DerivedArtifacts(artifacts []collector.ArtifactResult) []collector.ArtifactResult {
+	derived := ScriptBlockArtifacts(artifacts)
+	derived = append(derived, SecurityPostureArtifacts(artifacts)...)
+	return append(derived, HostRecordArtifacts(artifacts)...)
+}
+
+// IsDerivedArtifact reports whether an artifact of this type was built by
+// DerivedArtifacts rather than collected. Offline analysis of a bundle skips
+// them and derives them again from the collected artifacts.
+func IsDerivedArtifact(artifactType string) bool {
+	return derivedTypes[artifactType]
+}
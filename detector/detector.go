@@ -11,6 +11,8 @@ import (
 // Detector represents the detection engine
 type Detector struct {
 	rules []Rule
+	// warnings are the problems the last Evaluate worked around
+	warnings []string
 }
 
 // Rule represents a detection rule
@@ -46,6 +48,8 @@ type Evidence struct {
 	Description string                 `json:"description"`
 	Confidence  float64                `json:"confidence"`
 	Metadata    map[string]interface{} `json:"metadata"`
+	// Ref points at the record the evidence was read from, when it is one
+	Ref *collector.RecordRef `json:"ref,omitempty"`
 }
 
 // NewDetector creates a new detector instance
@@ -113,14 +117,167 @@ func (d *Detector) loadBuiltInRules() {
 			Logic:       "Event log entries matching suspicious patterns",
 			Enabled:     true,
 		},
+		{
+			ID:          "RT006",
+			Name:        "Obfuscated PowerShell Script Block",
+			Description: "Detects obfuscation and AMSI bypass markers in reassembled PowerShell 4104 script blocks",
+			Severity:    "high",
+			Category:    "powershell",
+			Tags:        []string{"powershell", "obfuscation", "attack.t1059.001", "attack.t1027"},
+			Logic:       "FromBase64String with IEX, encoded commands, download cradles or AMSI bypass strings",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT007",
+			Name:        "Windows Defender Threat Detection",
+			Description: "Reports Windows Defender malware detections and the action taken",
+			Severity:    "high",
+			Category:    "defender",
+			Tags:        []string{"defender", "malware", "antivirus"},
+			Logic:       "Defender operational events 1116 (detection) and 1117 (action taken)",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT008",
+			Name:        "Risky Security Policy Configuration",
+			Description: "Detects commonly abused local security policy settings",
+			Severity:    "high",
+			Category:    "policy",
+			Tags:        []string{"policy", "posture", "attack.t1134", "attack.t1562.002", "attack.t1078"},
+			Logic:       "SeDebugPrivilege, SeTcbPrivilege or SeLoadDriverPrivilege granted beyond Administrators, logon auditing disabled, or enabled admin accounts whose passwords never expire",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT009",
+			Name:        "Suspicious Scheduled Task Action",
+			Description: "Detects scheduled task definitions whose actions run from user-writable paths or use commonly abused commands",
+			Severity:    "high",
+			Category:    "task_definition",
+			Tags:        []string{"persistence", "scheduled_task", "attack.t1053.005"},
+			Logic:       "Exec actions in task XML running from temp, AppData, ProgramData or other user paths, or using encoded or hidden PowerShell, download cradles, mshta, rundll32 or regsvr32 script loading, certutil or bitsadmin",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT010",
+			Name:        "Unsigned Executable in User-Writable Path",
+			Description: "Detects running executables without a valid Authenticode signature in user-writable locations",
+			Severity:    "high",
+			Category:    "process_signature",
+			Tags:        []string{"process", "signature", "attack.t1204.002", "attack.t1036"},
+			Logic:       "Processes running from temp, AppData, Downloads, Public or other user paths whose executable is unsigned or has an invalid signature",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT011",
+			Name:        "Cloud Device Join Anomaly",
+			Description: "Detects Azure AD / Entra ID device join states that suggest a rogue tenant registration or a broken device identity",
+			Severity:    "high",
+			Category:    "cloud_join",
+			Tags:        []string{"cloud", "identity", "azure_ad", "attack.t1098.005"},
+			Logic:       "Workplace registration to a tenant other than the joined tenant, a failed device key signing test, or failing device authentication in dsregcmd /status",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT012",
+			Name:        "Cloud Credentials in Unusual Profile",
+			Description: "Detects AWS, Azure or gcloud CLI credentials in shared, service account or temporary profiles",
+			Severity:    "high",
+			Category:    "cloud_credentials",
+			Tags:        []string{"cloud", "credentials", "attack.t1552.001"},
+			Logic:       "Cloud CLI credential or token files under Public, Default, systemprofile, LocalService or NetworkService, web and service account homes, or temporary directories",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT013",
+			Name:        "Hidden Administrative Share Access by Non-Administrator",
+			Description: "Detects access to hidden shares such as C$ and ADMIN$ by accounts outside the local Administrators group",
+			Severity:    "high",
+			Category:    "smb_admin_share",
+			Tags:        []string{"smb", "lateral_movement", "attack.t1021.002"},
+			Logic:       "Logged hidden share accesses (5140) and open files on a hidden share by an account that is not a direct member of the local Administrators group",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT014",
+			Name:        "Network Share Created Recently",
+			Description: "Detects shares created within the collection lookback, as used for data staging",
+			Severity:    "medium",
+			Category:    "smb_share_created",
+			Tags:        []string{"smb", "collection", "attack.t1074.002"},
+			Logic:       "Share creation events (5142) for shares that still exist; high when Everyone, Authenticated Users or Users can write to the share",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT015",
+			Name:        "Offensive Tool in Execution History",
+			Description: "Detects known offensive tools and files in staging locations recorded by the ShimCache or Amcache",
+			Severity:    "high",
+			Category:    "execution_history",
+			Tags:        []string{"execution", "amcache", "shimcache", "attack.t1204"},
+			Logic:       "ShimCache and Amcache entries naming a known offensive tool (high) or lying in Users\\Public, Windows\\Temp, a Temp, Downloads, PerfLogs or recycle bin directory (medium), merged by path",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT016",
+			Name:        "Suspicious Hidden Persistence Entry",
+			Description: "Detects persistence hidden in shell startup files, SSH authorized keys, autostart entries and systemd user units",
+			Severity:    "high",
+			Category:    "hidden_persistence",
+			Tags:        []string{"persistence", "linux", "dotfiles", "attack.t1546.004", "attack.t1098.004"},
+			Logic:       "Startup file lines that download to a shell, open a reverse shell, decode a payload, set LD_PRELOAD or wrap sudo (high), or run from /tmp or set PROMPT_COMMAND (medium); authorized_keys entries with a forced command or environment, or without a key (medium)",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT017",
+			Name:        "Recently Modified Autostart Entry",
+			Description: "Detects registry autostart entries written shortly before collection that start programs from user-writable locations",
+			Severity:    "high",
+			Category:    "autostart",
+			Tags:        []string{"persistence", "registry", "autoruns", "attack.t1547.001", "attack.t1546"},
+			Logic:       "Autostart entries whose key was written within 30 days of collection and whose image is not validly signed: unsigned images in temp, AppData, Downloads or Public locations (high), other profile or ProgramData images, or images whose signature was not checked (medium)",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT018",
+			Name:        "Remote Desktop Enabled Recently",
+			Description: "Detects Remote Desktop enabled shortly before collection, as attackers do to keep interactive access",
+			Severity:    "medium",
+			Category:    "rdp_enabled",
+			Tags:        []string{"rdp", "remote_access", "attack.t1021.001", "attack.t1112"},
+			Logic:       "RDP settings allowing connections (fDenyTSConnections = 0) where the policy or Terminal Server key holding the value was written within 7 days of collection",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT019",
+			Name:        "Remote Desktop Without Network Level Authentication",
+			Description: "Detects Remote Desktop accepting connections without Network Level Authentication",
+			Severity:    "high",
+			Category:    "rdp_nla",
+			Tags:        []string{"rdp", "remote_access", "attack.t1021.001"},
+			Logic:       "RDP settings allowing connections while UserAuthentication is 0 in the Terminal Services policy or the RDP-Tcp listener",
+			Enabled:     true,
+		},
+		{
+			ID:          "RT020",
+			Name:        "Remote Access Tool Installed Recently",
+			Description: "Detects AnyDesk, TeamViewer or ScreenConnect installed shortly before collection",
+			Severity:    "medium",
+			Category:    "remote_tool",
+			Tags:        []string{"remote_access", "command_and_control", "attack.t1219"},
+			Logic:       "Remote access tool services (key last write), Uninstall entries (install date) or directories (creation time) within 7 days of collection, one finding per tool",
+			Enabled:     true,
+		},
 	}
 	
 	d.rules = append(d.rules, builtInRules...)
 }
 
-// Evaluate runs detections against collected artifacts
+// Evaluate runs detections against collected artifacts. Artifacts that
+// could not be collected are left out; see CoverageGaps.
 func (d *Detector) Evaluate(artifacts []collector.ArtifactResult) ([]Finding, error) {
 	var findings []Finding
+	artifacts = evaluableArtifacts(artifacts)
+	d.warnings = nil
 	
 	for _, rule := range d.rules {
 		if !rule.Enabled {
@@ -128,6 +285,7 @@ func (d *Detector) Evaluate(artifacts []collector.ArtifactResult) ([]Finding, er
 		}
 		
 		// Apply rule logic based on category
+		start := len(findings)
 		switch rule.Category {
 		case "process":
 			if finding := d.evaluateProcessRule(rule, artifacts); finding != nil {
@@ -149,19 +307,63 @@ func (d *Detector) Evaluate(artifacts []collector.ArtifactResult) ([]Finding, er
 			if finding := d.evaluateLogRule(rule, artifacts); finding != nil {
 				findings = append(findings, *finding)
 			}
+		case "powershell":
+			findings = append(findings, d.evaluateScriptBlockRule(rule, artifacts)...)
+		case "defender":
+			findings = append(findings, d.evaluateDefenderRule(rule, artifacts)...)
+		case "policy":
+			findings = append(findings, d.evaluatePolicyRule(rule, artifacts)...)
+		case "task_definition":
+			findings = append(findings, d.evaluateTaskDefinitionRule(rule, artifacts)...)
+		case "process_signature":
+			findings = append(findings, d.evaluateSignatureRule(rule, artifacts)...)
+		case "cloud_join":
+			findings = append(findings, d.evaluateJoinStateRule(rule, artifacts)...)
+		case "cloud_credentials":
+			findings = append(findings, d.evaluateCloudCredentialRule(rule, artifacts)...)
+		case "smb_admin_share":
+			findings = append(findings, d.evaluateAdminShareRule(rule, artifacts)...)
+		case "smb_share_created":
+			findings = append(findings, d.evaluateNewShareRule(rule, artifacts)...)
+		case "execution_history":
+			findings = append(findings, d.evaluateExecutionRule(rule, artifacts)...)
+		case "hidden_persistence":
+			findings = append(findings, d.evaluateHiddenPersistenceRule(rule, artifacts)...)
+		case "autostart":
+			findings = append(findings, d.evaluateAutostartRule(rule, artifacts)...)
+		case "rdp_enabled":
+			findings = append(findings, d.evaluateRDPEnabledRule(rule, artifacts)...)
+		case "rdp_nla":
+			findings = append(findings, d.evaluateRDPNLARule(rule, artifacts)...)
+		case "remote_tool":
+			findings = append(findings, d.evaluateRemoteToolRule(rule, artifacts)...)
 		}
+		findings = append(findings[:start], d.normalizeSeverities(rule, findings[start:])...)
 	}
 	
 	return findings, nil
 }
 
+// Warnings returns the problems the last Evaluate worked around, such as
+// findings naming an unknown severity
+func (d *Detector) Warnings() []string {
+	return d.warnings
+}
+
+// artifactText returns the artifact data as text. Structured and binary
+// artifacts such as packet captures have no text and never match.
+func artifactText(artifact collector.ArtifactResult) string {
+	text, _ := artifact.Data.(string)
+	return text
+}
+
 // evaluateProcessRule evaluates process-related rules
 func (d *Detector) evaluateProcessRule(rule Rule, artifacts []collector.ArtifactResult) *Finding {
 	// Look for process artifacts
 	for _, artifact := range artifacts {
 		if artifact.Artifact.Category == "process" {
 			// Check for suspicious process names
-			if strings.Contains(strings.ToLower(artifact.Data.(string)), "suspicious") {
+			if strings.Contains(strings.ToLower(artifactText(artifact)), "suspicious") {
 				return &Finding{
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
@@ -193,7 +395,7 @@ func (d *Detector) evaluateNetworkRule(rule Rule, artifacts []collector.Artifact
 	for _, artifact := range artifacts {
 		if artifact.Artifact.Category == "network" {
 			// Check for suspicious network connections
-			if strings.Contains(strings.ToLower(artifact.Data.(string)), "suspicious") {
+			if strings.Contains(strings.ToLower(artifactText(artifact)), "suspicious") {
 				return &Finding{
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
@@ -225,7 +427,7 @@ func (d *Detector) evaluatePersistenceRule(rule Rule, artifacts []collector.Arti
 	for _, artifact := range artifacts {
 		if artifact.Artifact.Category == "task" {
 			// Check for suspicious scheduled tasks
-			if strings.Contains(strings.ToLower(artifact.Data.(string)), "suspicious") {
+			if strings.Contains(strings.ToLower(artifactText(artifact)), "suspicious") {
 				return &Finding{
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
@@ -257,7 +459,7 @@ func (d *Detector) evaluateServiceRule(rule Rule, artifacts []collector.Artifact
 	for _, artifact := range artifacts {
 		if artifact.Artifact.Category == "service" {
 			// Check for suspicious service names
-			if strings.Contains(strings.ToLower(artifact.Data.(string)), "suspicious") {
+			if strings.Contains(strings.ToLower(artifactText(artifact)), "suspicious") {
 				return &Finding{
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
@@ -289,7 +491,7 @@ func (d *Detector) evaluateLogRule(rule Rule, artifacts []collector.ArtifactResu
 	for _, artifact := range artifacts {
 		if artifact.Artifact.Category == "log" {
 			// Check for suspicious log patterns
-			if strings.Contains(strings.ToLower(artifact.Data.(string)), "suspicious") {
+			if strings.Contains(strings.ToLower(artifactText(artifact)), "suspicious") {
 				return &Finding{
 					RuleID:      rule.ID,
 					RuleName:    rule.Name,
@@ -320,9 +522,16 @@ func (d *Detector) GetBuiltInRules() []Rule {
 	return d.rules
 }
 
-// AddRule adds a custom detection rule
-func (d *Detector) AddRule(rule Rule) {
+// AddRule adds a custom detection rule. Its severity may be a Sigma level
+// and is stored in its canonical form.
+func (d *Detector) AddRule(rule Rule) error {
+	severity, err := ParseSeverity(rule.Severity)
+	if err != nil {
+		return fmt.Errorf("rule %s has an %w", rule.ID, err)
+	}
+	rule.Severity = severity.String()
 	d.rules = append(d.rules, rule)
+	return nil
 }
 
 // EnableRule enables a specific rule by ID
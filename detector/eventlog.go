@@ -0,0 +1,108 @@
+package detector
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WinEvent is a Windows event log record parsed from event XML
+type WinEvent struct {
+	EventID     int               `json:"event_id"`
+	Provider    string            `json:"provider"`
+	Channel     string            `json:"channel"`
+	Computer    string            `json:"computer"`
+	RecordID    int64             `json:"record_id"`
+	TimeCreated time.Time         `json:"time_created"`
+	UserID      string            `json:"user_id,omitempty"`
+	Data        map[string]string `json:"data"`
+}
+
+// eventXML mirrors the parts of the Windows event schema RedTriage uses
+type eventXML struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID     int `xml:"EventID"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		EventRecordID int64  `xml:"EventRecordID"`
+		Channel       string `xml:"Channel"`
+		Computer      string `xml:"Computer"`
+		Security      struct {
+			UserID string `xml:"UserID,attr"`
+		} `xml:"Security"`
+	} `xml:"System"`
+	EventData struct {
+		Data []struct {
+			Name  string `xml:"Name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Data"`
+	} `xml:"EventData"`
+	// UserData holds one provider-defined element whose children are the
+	// fields, as the TerminalServices channels log them
+	UserData struct {
+		Fields struct {
+			Field []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:",any"`
+	} `xml:"UserData"`
+}
+
+// ParseEventXML parses the output of 'wevtutil qe <channel> /f:xml', which is
+// a sequence of <Event> elements without a root element
+func ParseEventXML(data string) ([]WinEvent, error) {
+	var events []WinEvent
+
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return events, fmt.Errorf("failed to parse event XML: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Event" {
+			continue
+		}
+
+		var raw eventXML
+		if err := decoder.DecodeElement(&raw, &start); err != nil {
+			return events, fmt.Errorf("failed to decode event: %w", err)
+		}
+
+		event := WinEvent{
+			EventID:  raw.System.EventID,
+			Provider: raw.System.Provider.Name,
+			Channel:  raw.System.Channel,
+			Computer: raw.System.Computer,
+			RecordID: raw.System.EventRecordID,
+			UserID:   raw.System.Security.UserID,
+			Data:     make(map[string]string),
+		}
+		if t, err := time.Parse(time.RFC3339Nano, raw.System.TimeCreated.SystemTime); err == nil {
+			event.TimeCreated = t
+		}
+		for _, field := range raw.EventData.Data {
+			event.Data[field.Name] = field.Value
+		}
+		for _, field := range raw.UserData.Fields.Field {
+			event.Data[field.XMLName.Local] = field.Value
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,156 @@
+package detector
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// offensiveTools are file names, lower case, of tools commonly run for
+// credential theft, lateral movement and reconnaissance
+var offensiveTools = map[string]bool{
+	"mimikatz.exe": true, "mimilib.dll": true, "procdump.exe": true, "procdump64.exe": true,
+	"psexec.exe": true, "psexec64.exe": true, "paexec.exe": true, "rubeus.exe": true,
+	"sharphound.exe": true, "seatbelt.exe": true, "lazagne.exe": true, "wce.exe": true,
+	"pwdump.exe": true, "gsecdump.exe": true, "nanodump.exe": true, "safetykatz.exe": true,
+	"certify.exe": true, "adfind.exe": true, "rclone.exe": true, "plink.exe": true,
+	"chisel.exe": true, "ngrok.exe": true, "advanced_ip_scanner.exe": true, "netscan.exe": true,
+}
+
+// stagingPaths are path fragments, lower case with backslashes, of
+// locations attackers commonly drop and run tools from
+var stagingPaths = []string{
+	`\users\public\`, `\windows\temp\`, `\appdata\local\temp\`, `\$recycle.bin\`, `\perflogs\`, `\downloads\`,
+}
+
+// executedFile is a file seen in the execution history of one or more
+// sources, merged by path
+type executedFile struct {
+	path    string
+	sources []string
+	entries []collector.ExecutionEntry
+}
+
+// evaluateExecutionRule flags files in the ShimCache and Amcache that are
+// known offensive tools or lie in staging locations, one finding per path.
+// Known tools are high severity; other files in staging locations medium.
+// Both sources record files whether or not they still exist, so a finding
+// may point at a tool already deleted.
+func (d *Detector) evaluateExecutionRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	files := make(map[string]*executedFile)
+	var order []string
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.ExecutionHistoryType {
+			continue
+		}
+		for _, entry := range collector.ExecutionEntries(artifact) {
+			if entry.Path == "" {
+				continue
+			}
+			key := strings.ToLower(entry.Path)
+			file, ok := files[key]
+			if !ok {
+				file = &executedFile{path: entry.Path}
+				files[key] = file
+				order = append(order, key)
+			}
+			file.entries = append(file.entries, entry)
+			if !containsString(file.sources, artifact.Artifact.Name) {
+				file.sources = append(file.sources, artifact.Artifact.Name)
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		file := files[key]
+		name := path.Base(strings.ReplaceAll(key, `\`, "/"))
+
+		var reasons []string
+		severity := "medium"
+		if offensiveTools[name] {
+			reasons = append(reasons, fmt.Sprintf("known offensive tool (%s)", name))
+			severity = rule.Severity
+		}
+		for _, fragment := range stagingPaths {
+			if strings.Contains(key, fragment) {
+				reasons = append(reasons, fmt.Sprintf("in staging location (%s)", fragment))
+				break
+			}
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		metadata := map[string]interface{}{
+			"path":    file.path,
+			"sources": file.sources,
+			"reasons": reasons,
+		}
+		var evidence []Evidence
+		for _, entry := range file.entries {
+			entryMetadata := map[string]interface{}{"source": entry.Source}
+			if when, label := entry.Time(); !when.IsZero() {
+				entryMetadata[strings.ToLower(strings.ReplaceAll(label, " ", "_"))] = when.UTC().Format(time.RFC3339)
+			}
+			if entry.SHA1 != "" {
+				entryMetadata["sha1"] = entry.SHA1
+				metadata["sha1"] = entry.SHA1
+			}
+			if entry.Executed != nil {
+				entryMetadata["executed"] = *entry.Executed
+			}
+			evidence = append(evidence, Evidence{
+				Type:        "execution_entry",
+				Source:      entry.Source,
+				Value:       entry.Path,
+				Description: fmt.Sprintf("%s entry", executionSourceName(entry.Source)),
+				Confidence:  0.8,
+				Metadata:    entryMetadata,
+			})
+		}
+		if first := firstSeen(file.entries); !first.IsZero() {
+			metadata["first_seen"] = first.UTC().Format(time.RFC3339)
+		}
+
+		sort.Strings(file.sources)
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    severity,
+			Category:    rule.Category,
+			Description: fmt.Sprintf("%s in %s: %s", file.path, strings.Join(file.sources, " and "), strings.Join(reasons, "; ")),
+			Evidence:    evidence,
+			Tags:        rule.Tags,
+			Timestamp:   time.Now(),
+			Metadata:    metadata,
+		})
+	}
+	return findings
+}
+
+// executionSourceName returns the display name of an execution source
+func executionSourceName(source string) string {
+	switch source {
+	case collector.SourceAmcache:
+		return "Amcache"
+	case collector.SourceShimCache:
+		return "ShimCache"
+	}
+	return source
+}
+
+// firstSeen returns the earliest time any entry records
+func firstSeen(entries []collector.ExecutionEntry) time.Time {
+	var first time.Time
+	for _, entry := range entries {
+		if when, _ := entry.Time(); !when.IsZero() && (first.IsZero() || when.Before(first)) {
+			first = when
+		}
+	}
+	return first
+}
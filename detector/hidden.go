@@ -0,0 +1,185 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// dotfileIndicator is a marker of persistence in a shell startup file,
+// autostart entry or systemd user unit
+type dotfileIndicator struct {
+	Name        string
+	Description string
+	Severity    string
+	Pattern     *regexp.Regexp
+}
+
+// dotfileIndicators are checked against every line of the startup files
+var dotfileIndicators = []dotfileIndicator{
+	{"download_to_shell", "Downloads content and pipes it to a shell", "high",
+		regexp.MustCompile(`(?i)\b(curl|wget|fetch)\b[^;&#]*\|\s*(sudo\s+)?(ba|da|z|k)?sh\b`)},
+	{"reverse_shell", "Opens a reverse shell", "high",
+		regexp.MustCompile(`(?i)(/dev/(tcp|udp)/|\bn(c|cat)\b[^;#]*\s-(e|c)\s|\bsocat\b[^;#]*exec:|\bbash\s+-i\s*[>&])`)},
+	{"scripted_socket", "Runs an inline script that opens a network socket", "high",
+		regexp.MustCompile(`(?i)\b(python[23]?|perl|ruby|php)\b[^;#]*\s-(c|e|r)\s[^#]*socket`)},
+	{"decoded_payload", "Decodes Base64 content and executes it", "high",
+		regexp.MustCompile(`(?i)base64\s+(-d|--decode)[^#]*\|\s*(ba|da|z)?sh\b|\beval\b[^#]*base64\s+(-d|--decode)`)},
+	{"preload_hijack", "Sets LD_PRELOAD so a library is injected into every program started", "high",
+		regexp.MustCompile(`(?i)^\s*(export\s+)?LD_PRELOAD=`)},
+	{"credential_alias", "Redefines sudo or su, as done to capture passwords", "high",
+		regexp.MustCompile(`^\s*(alias\s+(sudo|su)=|(function\s+)?(sudo|su)\s*\(\))`)},
+	{"temp_execution", "Starts a program from a world-writable temporary directory", "medium",
+		regexp.MustCompile(`(^\s*|[;&|]\s*|\b(nohup|exec|setsid|Exec|ExecStart|ExecStartPre)[\s=]+)(/tmp|/var/tmp|/dev/shm)/\S+`)},
+	{"prompt_command", "Sets PROMPT_COMMAND, which runs before every prompt", "medium",
+		regexp.MustCompile(`^\s*(export\s+)?PROMPT_COMMAND=`)},
+}
+
+// authorizedKeyTypes are the key types an authorized_keys entry may hold
+var authorizedKeyTypes = []string{
+	"ssh-rsa", "ssh-dss", "ssh-ed25519", "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+	"sk-ssh-ed25519@openssh.com", "sk-ecdsa-sha2-nistp256@openssh.com",
+}
+
+// dotfileMatch is one suspicious line of a hidden persistence file
+type dotfileMatch struct {
+	line      int
+	text      string
+	indicator string
+	reason    string
+	severity  string
+}
+
+// evaluateHiddenPersistenceRule flags hidden persistence files holding
+// suspicious entries, one finding per file: startup files, autostart
+// entries and systemd user units that download to a shell, open reverse
+// shells, decode payloads, preload libraries, wrap sudo or run from
+// temporary directories, and authorized_keys entries that force a command
+// or set the environment. Comment lines are ignored.
+func (d *Detector) evaluateHiddenPersistenceRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.HiddenPersistenceType {
+			continue
+		}
+		for _, file := range collector.HiddenPersistenceFiles(artifact) {
+			var matches []dotfileMatch
+			if file.Kind == collector.HiddenAuthorizedKeys {
+				matches = authorizedKeyMatches(file.Lines)
+			} else {
+				matches = dotfileMatches(file.Lines)
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			findings = append(findings, hiddenPersistenceFinding(rule, artifact.Artifact.Name, file, matches))
+		}
+	}
+	return findings
+}
+
+// dotfileMatches checks the lines of a startup file against the indicators
+func dotfileMatches(lines []string) []dotfileMatch {
+	var matches []dotfileMatch
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		for _, indicator := range dotfileIndicators {
+			if indicator.Pattern.MatchString(line) {
+				matches = append(matches, dotfileMatch{line: i + 1, text: line, indicator: indicator.Name, reason: indicator.Description, severity: indicator.Severity})
+			}
+		}
+	}
+	return matches
+}
+
+// authorizedKeyMatches checks authorized_keys entries for options that run
+// a command or change the environment on login, and for entries that are
+// not keys at all
+func authorizedKeyMatches(lines []string) []dotfileMatch {
+	var matches []dotfileMatch
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		options, ok := authorizedKeyOptions(trimmed)
+		if !ok {
+			matches = append(matches, dotfileMatch{line: i + 1, text: line, indicator: "not_a_key", reason: "Entry holds no recognized public key", severity: "medium"})
+			continue
+		}
+		lower := strings.ToLower(options)
+		switch {
+		case strings.Contains(lower, "command="):
+			severity := "medium"
+			if dotfileMatches([]string{options}) != nil {
+				severity = "high"
+			}
+			matches = append(matches, dotfileMatch{line: i + 1, text: line, indicator: "forced_command", reason: "Key runs a forced command on every login", severity: severity})
+		case strings.Contains(lower, "environment="):
+			matches = append(matches, dotfileMatch{line: i + 1, text: line, indicator: "key_environment", reason: "Key sets environment variables on login", severity: "medium"})
+		}
+	}
+	return matches
+}
+
+// authorizedKeyOptions returns the options before the key type of an
+// authorized_keys entry, and false when the entry holds no known key type.
+// Options may contain quoted spaces, so the key type is searched for.
+func authorizedKeyOptions(entry string) (string, bool) {
+	fields := strings.Fields(entry)
+	for i, field := range fields {
+		for _, keyType := range authorizedKeyTypes {
+			if field == keyType && i+1 < len(fields) {
+				return strings.Join(fields[:i], " "), true
+			}
+		}
+	}
+	return "", false
+}
+
+// hiddenPersistenceFinding builds the finding for one file, at the highest
+// severity of its matches
+func hiddenPersistenceFinding(rule Rule, source string, file collector.HiddenFile, matches []dotfileMatch) Finding {
+	severity := "low"
+	var reasons []string
+	var evidence []Evidence
+	for _, match := range matches {
+		if SeverityOf(match.severity) > SeverityOf(severity) {
+			severity = match.severity
+		}
+		if !containsString(reasons, match.reason) {
+			reasons = append(reasons, match.reason)
+		}
+		evidence = append(evidence, Evidence{
+			Type:        "dotfile_line",
+			Source:      source,
+			Value:       strings.TrimSpace(match.text),
+			Description: fmt.Sprintf("%s line %d: %s", file.Path, match.line, match.reason),
+			Confidence:  0.7,
+			Metadata:    map[string]interface{}{"line": match.line, "indicator": match.indicator, "severity": match.severity},
+		})
+	}
+
+	return Finding{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Severity:    severity,
+		Category:    rule.Category,
+		Description: fmt.Sprintf("%s (%s of %s): %s", file.Path, strings.ReplaceAll(file.Kind, "_", " "), file.User, strings.Join(reasons, "; ")),
+		Evidence:    evidence,
+		Tags:        rule.Tags,
+		Timestamp:   time.Now(),
+		Metadata: map[string]interface{}{
+			"path":     file.Path,
+			"kind":     file.Kind,
+			"user":     file.User,
+			"modified": file.Modified.UTC().Format(time.RFC3339),
+			"sha256":   file.SHA256,
+		},
+	}
+}
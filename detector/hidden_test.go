@@ -0,0 +1,41 @@
+package detector
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+func TestHiddenPersistenceFindings(t *testing.T) {
+	root := filepath.Join("..", "collector", "testdata", "linux-image")
+	results, err := collector.NewCollector().Collect(collector.CollectionProfile{Root: root, Extended: true})
+	if err != nil {
+		t.Fatalf("failed to collect from the image: %v", err)
+	}
+	var hidden []collector.ArtifactResult
+	for _, result := range results {
+		if result.Artifact.Name == "hidden_persistence" {
+			hidden = append(hidden, result)
+		}
+	}
+	if len(hidden) != 1 {
+		t.Fatalf("collected %d hidden persistence artifacts, want 1", len(hidden))
+	}
+
+	findings, err := NewDetector().Evaluate(hidden)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	severities := make(map[string]string)
+	for _, finding := range findings {
+		if finding.RuleID == "RT016" {
+			severities[filepath.Base(fmt.Sprint(finding.Metadata["path"]))] = finding.Severity
+		}
+	}
+	want := map[string]string{".bashrc": "high", "authorized_keys": "medium", "sync.desktop": "medium"}
+	if fmt.Sprint(severities) != fmt.Sprint(want) {
+		t.Errorf("hidden persistence findings %v, want %v", severities, want)
+	}
+}
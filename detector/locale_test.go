@@ -0,0 +1,77 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// localeLanguages are the display languages of the tool output in
+// testdata/locale, named <tool>_<language>.<ext>. The first is the reference
+// the others must parse identically to.
+var localeLanguages = []string{"en", "de", "fr"}
+
+func TestParseLocalizedToolOutput(t *testing.T) {
+	parsers := []struct {
+		file  string
+		parse func(data string) (interface{}, int, error)
+	}{
+		{"tasklist_%s.csv", func(data string) (interface{}, int, error) {
+			records, err := ParseTasklistCSV(data)
+			return records, len(records), err
+		}},
+		{"netstat_%s.txt", func(data string) (interface{}, int, error) {
+			records := ParseNetstat(data)
+			return records, len(records), nil
+		}},
+		{"schtasks_%s.csv", func(data string) (interface{}, int, error) {
+			records, err := ParseSchtasksCSV(data)
+			return records, len(records), err
+		}},
+	}
+
+	for _, parser := range parsers {
+		var reference []byte
+		for _, language := range localeLanguages {
+			name := fmt.Sprintf(parser.file, language)
+			data, err := os.ReadFile(filepath.Join("testdata", "locale", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records, count, err := parser.parse(string(data))
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			if count == 0 {
+				t.Errorf("%s: no records parsed", name)
+				continue
+			}
+			encoded, err := json.Marshal(records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reference == nil {
+				reference = encoded
+			} else if string(encoded) != string(reference) {
+				t.Errorf("%s: records differ from %s output:\n  got  %s\n  want %s", name, localeLanguages[0], encoded, reference)
+			}
+		}
+	}
+}
+
+func TestParseNetstatDecodedFromCodePage(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "locale", "netstat_de.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := collector.DecodeTextCodePage(data, 850)
+	if records := ParseNetstat(decoded.Text); len(records) == 0 || records[0].State != "LISTENING" {
+		t.Errorf("netstat output decoded from CP850 does not parse: %+v", records)
+	}
+}
@@ -0,0 +1,282 @@
+package detector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// scriptBlockEventID is the PowerShell script block logging event
+const scriptBlockEventID = 4104
+
+// ScriptBlock is a PowerShell script reassembled from one or more 4104 events
+type ScriptBlock struct {
+	ID            string    `json:"script_block_id"`
+	Path          string    `json:"path,omitempty"`
+	Text          string    `json:"text"`
+	Parts         int       `json:"parts"`
+	ExpectedParts int       `json:"expected_parts"`
+	Complete      bool      `json:"complete"`
+	FirstSeen     time.Time `json:"first_seen"`
+	Computer      string    `json:"computer"`
+	UserID        string    `json:"user_id,omitempty"`
+	SHA256        string    `json:"sha256"`
+}
+
+// obfuscationIndicator is a marker of malicious or obfuscated PowerShell
+type obfuscationIndicator struct {
+	Name        string
+	Description string
+	Severity    string
+	Match       func(script string) bool
+}
+
+var (
+	encodedCommandPattern = regexp.MustCompile(`(?i)(^|\s)-e(c|nc|ncodedcommand)?\s+['"]?[A-Za-z0-9+/=]{20,}`)
+	base64DecodePattern   = regexp.MustCompile(`(?i)frombase64string`)
+	invokeExprPattern     = regexp.MustCompile(`(?i)(\biex\b|invoke-expression)`)
+	downloadPattern       = regexp.MustCompile(`(?i)(downloadstring|downloaddata|downloadfile|invoke-webrequest|\biwr\b|net\.webclient)`)
+	amsiBypassPattern     = regexp.MustCompile(`(?i)(amsiutils|amsiinitfailed|amsiscanbuffer|amsicontext|amsi\.dll)`)
+)
+
+// obfuscationIndicators are the markers checked against every script block
+var obfuscationIndicators = []obfuscationIndicator{
+	{
+		Name:        "amsi_bypass",
+		Description: "References AMSI internals used to bypass antimalware scanning",
+		Severity:    "critical",
+		Match:       amsiBypassPattern.MatchString,
+	},
+	{
+		Name:        "base64_invoke_expression",
+		Description: "Decodes Base64 content and executes it with Invoke-Expression",
+		Severity:    "high",
+		Match: func(script string) bool {
+			return base64DecodePattern.MatchString(script) && invokeExprPattern.MatchString(script)
+		},
+	},
+	{
+		Name:        "encoded_command",
+		Description: "Launches PowerShell with an encoded command (-enc)",
+		Severity:    "high",
+		Match:       encodedCommandPattern.MatchString,
+	},
+	{
+		Name:        "download_cradle",
+		Description: "Downloads content and executes it with Invoke-Expression",
+		Severity:    "high",
+		Match: func(script string) bool {
+			return downloadPattern.MatchString(script) && invokeExprPattern.MatchString(script)
+		},
+	},
+}
+
+// ReassembleScriptBlocks joins multi-part 4104 events by ScriptBlockId into
+// complete scripts. Parts are ordered by MessageNumber; scripts with missing
+// parts are returned with Complete set to false.
+func ReassembleScriptBlocks(events []WinEvent) []ScriptBlock {
+	type part struct {
+		number int
+		text   string
+	}
+
+	parts := make(map[string][]part)
+	blocks := make(map[string]*ScriptBlock)
+	var order []string
+
+	for _, event := range events {
+		if event.EventID != scriptBlockEventID {
+			continue
+		}
+
+		id := event.Data["ScriptBlockId"]
+		if id == "" {
+			continue
+		}
+
+		block, ok := blocks[id]
+		if !ok {
+			block = &ScriptBlock{
+				ID:        id,
+				Path:      event.Data["Path"],
+				FirstSeen: event.TimeCreated,
+				Computer:  event.Computer,
+				UserID:    event.UserID,
+			}
+			blocks[id] = block
+			order = append(order, id)
+		}
+		if !event.TimeCreated.IsZero() && (block.FirstSeen.IsZero() || event.TimeCreated.Before(block.FirstSeen)) {
+			block.FirstSeen = event.TimeCreated
+		}
+		if total, err := strconv.Atoi(event.Data["MessageTotal"]); err == nil && total > block.ExpectedParts {
+			block.ExpectedParts = total
+		}
+
+		number, _ := strconv.Atoi(event.Data["MessageNumber"])
+		parts[id] = append(parts[id], part{number: number, text: event.Data["ScriptBlockText"]})
+	}
+
+	var result []ScriptBlock
+	for _, id := range order {
+		block := blocks[id]
+		blockParts := parts[id]
+		sort.SliceStable(blockParts, func(i, j int) bool {
+			return blockParts[i].number < blockParts[j].number
+		})
+
+		// The same part can be logged more than once; keep the first copy
+		var text strings.Builder
+		seen := make(map[int]bool)
+		for _, p := range blockParts {
+			if seen[p.number] {
+				continue
+			}
+			seen[p.number] = true
+			text.WriteString(p.text)
+		}
+
+		block.Text = text.String()
+		block.Parts = len(seen)
+		if block.ExpectedParts == 0 {
+			block.ExpectedParts = block.Parts
+		}
+		block.Complete = block.Parts == block.ExpectedParts
+
+		hash := sha256.Sum256([]byte(block.Text))
+		block.SHA256 = hex.EncodeToString(hash[:])
+
+		result = append(result, *block)
+	}
+
+	return result
+}
+
+// ExtractScriptBlocks parses the PowerShell operational event artifacts and
+// returns the reassembled script blocks
+func ExtractScriptBlocks(artifacts []collector.ArtifactResult) []ScriptBlock {
+	var events []WinEvent
+	for _, artifact := range eventArtifacts(artifacts, collector.PowerShellOperationalChannel) {
+		parsed, _ := ParseEventXML(artifactText(artifact))
+		events = append(events, parsed...)
+	}
+	return ReassembleScriptBlocks(events)
+}
+
+// ScriptBlockArtifacts returns the reassembled script blocks as artifacts so
+// they are packaged, hashed and available for further analysis
+func ScriptBlockArtifacts(artifacts []collector.ArtifactResult) []collector.ArtifactResult {
+	var results []collector.ArtifactResult
+	for _, block := range ExtractScriptBlocks(artifacts) {
+		results = append(results, block.ArtifactResult())
+	}
+	return results
+}
+
+// ArtifactResult converts the script block into an artifact result
+func (b ScriptBlock) ArtifactResult() collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact(
+		"scriptblock_"+strings.Trim(b.ID, "{}"),
+		"Reassembled PowerShell script block",
+		"script",
+		"script",
+	).Artifact
+	artifact.Platform = "windows"
+	artifact.Parameters["script_block_id"] = b.ID
+	artifact.Parameters["source_path"] = b.Path
+	artifact.Parameters["complete"] = strconv.FormatBool(b.Complete)
+	artifact.Parameters["parts"] = fmt.Sprintf("%d/%d", b.Parts, b.ExpectedParts)
+
+	return collector.ArtifactResult{
+		Artifact: artifact,
+		Data:     b.Text,
+		Metadata: collector.Metadata{
+			CollectedAt: b.FirstSeen,
+			Collector:   "detector",
+			Source:      collector.PowerShellOperationalChannel,
+			Tags: map[string]string{
+				"script_block_id": b.ID,
+				"computer":        b.Computer,
+			},
+		},
+		Size:     int64(len(b.Text)),
+		Checksum: b.SHA256,
+	}
+}
+
+// evaluateScriptBlockRule checks every reassembled script block for
+// obfuscation indicators and returns one finding per suspicious block
+func (d *Detector) evaluateScriptBlockRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+
+	for _, block := range ExtractScriptBlocks(artifacts) {
+		var evidence []Evidence
+		var matched []string
+		severity := ""
+
+		for _, indicator := range obfuscationIndicators {
+			if !indicator.Match(block.Text) {
+				continue
+			}
+			matched = append(matched, indicator.Name)
+			if SeverityOf(indicator.Severity) > SeverityOf(severity) {
+				severity = indicator.Severity
+			}
+			evidence = append(evidence, Evidence{
+				Type:        "powershell_indicator",
+				Source:      collector.PowerShellOperationalChannel,
+				Value:       indicator.Name,
+				Description: indicator.Description,
+				Confidence:  0.8,
+				Metadata: map[string]interface{}{
+					"script_block_id": block.ID,
+				},
+			})
+		}
+
+		if len(matched) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    severity,
+			Category:    rule.Category,
+			Description: fmt.Sprintf("Suspicious PowerShell script block %s: %s", block.ID, strings.Join(matched, ", ")),
+			Evidence:    evidence,
+			Tags:        rule.Tags,
+			Timestamp:   time.Now(),
+			Metadata: map[string]interface{}{
+				"script_block_id": block.ID,
+				"script_path":     block.Path,
+				"script_sha256":   block.SHA256,
+				"complete":        block.Complete,
+				"parts":           block.Parts,
+				"computer":        block.Computer,
+				"first_seen":      block.FirstSeen,
+			},
+		})
+	}
+
+	return findings
+}
+
+// eventArtifacts returns the event XML artifacts collected from channel
+func eventArtifacts(artifacts []collector.ArtifactResult, channel string) []collector.ArtifactResult {
+	var matches []collector.ArtifactResult
+	for _, artifact := range artifacts {
+		if artifact.Error == nil && artifact.Artifact.Type == collector.EventXMLType &&
+			strings.EqualFold(artifact.Artifact.Parameters["channel"], channel) {
+			matches = append(matches, artifact)
+		}
+	}
+	return matches
+}
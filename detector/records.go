@@ -0,0 +1,594 @@
+package detector
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// ProcessRecord is a running process, whichever tool listed it
+type ProcessRecord struct {
+	Name        string `json:"name"`
+	PID         int    `json:"pid"`
+	ParentPID   int    `json:"parent_pid,omitempty"`
+	SessionID   int    `json:"session_id"`
+	MemoryKB    int64  `json:"memory_kb"`
+	User        string `json:"user,omitempty"`
+	CommandLine string `json:"command_line,omitempty"`
+	Path        string `json:"path,omitempty"`
+	// Signed is whether the executable has a valid Authenticode signature,
+	// nil when the signature could not be checked
+	Signed          *bool  `json:"signed,omitempty"`
+	SignatureStatus string `json:"signature_status,omitempty"`
+	Signer          string `json:"signer,omitempty"`
+	// LocationRisk rates the directory the executable runs from, see
+	// ExecutableLocationRisk
+	LocationRisk string `json:"location_risk,omitempty"`
+}
+
+// ConnectionRecord is a TCP connection or UDP endpoint. States use the
+// English netstat names, e.g. LISTENING or TIME_WAIT.
+type ConnectionRecord struct {
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"local_address"`
+	LocalPort     int    `json:"local_port"`
+	RemoteAddress string `json:"remote_address,omitempty"`
+	RemotePort    int    `json:"remote_port,omitempty"`
+	State         string `json:"state,omitempty"`
+	PID           int    `json:"pid"`
+}
+
+// ScheduledTaskRecord is a scheduled task. Run times are RFC 3339 in UTC
+// and empty when the task has not run or is not scheduled.
+type ScheduledTaskRecord struct {
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	Author      string   `json:"author,omitempty"`
+	RunAs       string   `json:"run_as,omitempty"`
+	Actions     []string `json:"actions"`
+	LastRunTime string   `json:"last_run_time,omitempty"`
+	NextRunTime string   `json:"next_run_time,omitempty"`
+	LastResult  int64    `json:"last_result"`
+}
+
+// Column positions of the text tool output. The headers are translated on
+// non-English Windows but the column order is not.
+const (
+	tasklistName = iota
+	tasklistPID
+	tasklistSessionName
+	tasklistSession
+	tasklistMemory
+	tasklistStatus
+	tasklistUser
+	tasklistColumns
+)
+
+const (
+	schtasksHost = iota
+	schtasksName
+	schtasksNextRun
+	schtasksStatus
+	schtasksLogonMode
+	schtasksLastRun
+	schtasksLastResult
+	schtasksAuthor
+	schtasksTaskToRun
+	schtasksStartIn
+	schtasksComment
+	schtasksTaskState
+	schtasksIdleTime
+	schtasksPowerManagement
+	schtasksRunAs
+	schtasksColumns
+)
+
+// tcpStates maps TCP state names, as shown by English, German and Spanish
+// netstat and by Get-NetTCPConnection, to the English netstat names. Keys
+// are normalized with stateKey.
+var tcpStates = map[string]string{}
+
+// taskStates maps localized schtasks status values and Get-ScheduledTask
+// states to one set of names. Keys are normalized with stateKey.
+var taskStates = map[string]string{}
+
+func init() {
+	for canonical, names := range map[string][]string{
+		"LISTENING":    {"LISTENING", "Listen", "ABHÖREN", "ESCUCHANDO"},
+		"ESTABLISHED":  {"ESTABLISHED", "Established", "HERGESTELLT", "ESTABLECIDO"},
+		"TIME_WAIT":    {"TIME_WAIT", "TimeWait", "WARTEND", "ESPERA_TIEMPO"},
+		"CLOSE_WAIT":   {"CLOSE_WAIT", "CloseWait", "SCHLIESSEN_WARTEN", "CERRAR_ESPERA"},
+		"SYN_SENT":     {"SYN_SENT", "SynSent", "SYN_GESENDET", "SYN_ENVIADO"},
+		"SYN_RECEIVED": {"SYN_RECEIVED", "SynReceived", "SYN_EMPFANGEN", "SYN_RECIBIDO"},
+		"FIN_WAIT_1":   {"FIN_WAIT_1", "FinWait1", "FIN_WARTEN_1", "FIN_ESPERA_1"},
+		"FIN_WAIT_2":   {"FIN_WAIT_2", "FinWait2", "FIN_WARTEN_2", "FIN_ESPERA_2"},
+		"LAST_ACK":     {"LAST_ACK", "LastAck", "LETZTE_BEST", "ÚLTIMO_ACK"},
+		"CLOSING":      {"CLOSING", "Closing", "SCHLIESSEND", "CERRANDO"},
+		"CLOSED":       {"CLOSED", "Closed", "GESCHLOSSEN", "CERRADO"},
+		"DELETE_TCB":   {"DELETE_TCB", "DeleteTCB", "TCB_LÖSCHEN"},
+		"BOUND":        {"BOUND", "Bound", "GEBUNDEN", "ENLAZADO"},
+	} {
+		for _, name := range names {
+			tcpStates[stateKey(name)] = canonical
+		}
+	}
+
+	for canonical, names := range map[string][]string{
+		"Ready":    {"Ready", "Bereit", "Prêt", "Listo"},
+		"Running":  {"Running", "Wird ausgeführt", "En cours d'exécution", "En ejecución"},
+		"Disabled": {"Disabled", "Deaktiviert", "Désactivé", "Deshabilitado"},
+		"Queued":   {"Queued", "In Warteschlange", "En file d'attente", "En cola"},
+	} {
+		for _, name := range names {
+			taskStates[stateKey(name)] = canonical
+		}
+	}
+}
+
+// stateKey reduces a state name to its ASCII letters and digits, upper
+// case. Console tools write in the OEM code page, so an accented letter
+// arrives as one byte or as two depending on the host; dropping it makes
+// both spellings match.
+func stateKey(name string) string {
+	var key strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			key.WriteByte(c - 'a' + 'A')
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			key.WriteByte(c)
+		}
+	}
+	return key.String()
+}
+
+// notAvailable reports whether a text tool marked a value as not available
+func notAvailable(value string) bool {
+	switch stateKey(value) {
+	case "", "NA", "NICHTZUTREFFEND", "NONDISPONIBLE", "NODISPONIBLE":
+		return true
+	}
+	return false
+}
+
+// ParseProcessListJSON parses the Win32_Process listing
+func ParseProcessListJSON(data string) ([]ProcessRecord, error) {
+	var raw []struct {
+		Name            string
+		ProcessID       int `json:"ProcessId"`
+		ParentProcessID int `json:"ParentProcessId"`
+		SessionID       int `json:"SessionId"`
+		WorkingSetKB    int64
+		UserName        string
+		CommandLine     string
+		ExecutablePath  string
+		SignatureStatus string
+		Signer          string
+	}
+	if err := unmarshalPowerShellJSON(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse process list: %w", err)
+	}
+
+	records := make([]ProcessRecord, 0, len(raw))
+	for _, p := range raw {
+		records = append(records, ProcessRecord{
+			Name:            p.Name,
+			PID:             p.ProcessID,
+			ParentPID:       p.ParentProcessID,
+			SessionID:       p.SessionID,
+			MemoryKB:        p.WorkingSetKB,
+			User:            p.UserName,
+			CommandLine:     p.CommandLine,
+			Path:            p.ExecutablePath,
+			Signed:          signatureValid(p.SignatureStatus),
+			SignatureStatus: p.SignatureStatus,
+			Signer:          p.Signer,
+			LocationRisk:    ExecutableLocationRisk(p.ExecutablePath),
+		})
+	}
+	return records, nil
+}
+
+// ParseTasklistCSV parses 'tasklist /FO CSV /V' output in any display
+// language. Memory is read from its digits, so "12,345 K", "12.345 K" and
+// "12 345 Ko" are the same value.
+func ParseTasklistCSV(data string) ([]ProcessRecord, error) {
+	rows, err := readToolCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tasklist output: %w", err)
+	}
+
+	records := make([]ProcessRecord, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < tasklistColumns {
+			continue
+		}
+		pid, err := strconv.Atoi(row[tasklistPID])
+		if err != nil {
+			// The header row, in whatever language
+			continue
+		}
+
+		record := ProcessRecord{Name: row[tasklistName], PID: pid}
+		record.SessionID, _ = strconv.Atoi(row[tasklistSession])
+		record.MemoryKB, _ = strconv.ParseInt(digitsOnly(row[tasklistMemory]), 10, 64)
+		if !notAvailable(row[tasklistUser]) {
+			record.User = row[tasklistUser]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ParseConnectionListJSON parses the Get-NetTCPConnection and
+// Get-NetUDPEndpoint listing
+func ParseConnectionListJSON(data string) ([]ConnectionRecord, error) {
+	var raw []struct {
+		Protocol      string
+		LocalAddress  string
+		LocalPort     int
+		RemoteAddress string
+		RemotePort    int
+		State         string
+		OwningProcess int
+	}
+	if err := unmarshalPowerShellJSON(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse connection list: %w", err)
+	}
+
+	records := make([]ConnectionRecord, 0, len(raw))
+	for _, c := range raw {
+		records = append(records, ConnectionRecord{
+			Protocol:      c.Protocol,
+			LocalAddress:  c.LocalAddress,
+			LocalPort:     c.LocalPort,
+			RemoteAddress: c.RemoteAddress,
+			RemotePort:    c.RemotePort,
+			State:         canonicalState(tcpStates, c.State),
+			PID:           c.OwningProcess,
+		})
+	}
+	return records, nil
+}
+
+// ParseNetstat parses 'netstat -ano' output in any display language.
+// Connection lines are recognized by their protocol, which is never
+// translated; headers and titles are skipped. A state may span several
+// words, so it is everything between the remote address and the PID.
+func ParseNetstat(data string) []ConnectionRecord {
+	var records []ConnectionRecord
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r", ""), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		protocol := strings.ToUpper(fields[0])
+		if protocol != "TCP" && protocol != "UDP" {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			continue
+		}
+		record := ConnectionRecord{Protocol: protocol, PID: pid}
+		record.LocalAddress, record.LocalPort = splitEndpoint(fields[1])
+		if fields[2] != "*:*" {
+			record.RemoteAddress, record.RemotePort = splitEndpoint(fields[2])
+		}
+		if protocol == "TCP" && len(fields) > 4 {
+			record.State = canonicalState(tcpStates, strings.Join(fields[3:len(fields)-1], " "))
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ParseScheduledTaskListJSON parses the Get-ScheduledTask listing
+func ParseScheduledTaskListJSON(data string) ([]ScheduledTaskRecord, error) {
+	var raw []struct {
+		TaskName    string
+		State       string
+		Author      string
+		RunAs       string
+		Actions     []string
+		LastRunTime string
+		NextRunTime string
+		LastResult  int64
+	}
+	if err := unmarshalPowerShellJSON(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduled task list: %w", err)
+	}
+
+	records := make([]ScheduledTaskRecord, 0, len(raw))
+	for _, t := range raw {
+		record := ScheduledTaskRecord{
+			Name:       t.TaskName,
+			State:      canonicalState(taskStates, t.State),
+			Author:     t.Author,
+			RunAs:      t.RunAs,
+			Actions:    t.Actions,
+			LastResult: t.LastResult,
+		}
+		if record.Actions == nil {
+			record.Actions = []string{}
+		}
+		record.LastRunTime = formatRecordTime(t.LastRunTime)
+		record.NextRunTime = formatRecordTime(t.NextRunTime)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ParseSchtasksCSV parses 'schtasks /query /fo csv /v' output in any display
+// language. schtasks repeats the header row for every task folder; only rows
+// whose task name is a path are tasks. Run times are read in the regional
+// formats Windows uses and converted to UTC; they are taken to be local
+// time, which holds when the detector runs on the collected host.
+func ParseSchtasksCSV(data string) ([]ScheduledTaskRecord, error) {
+	rows, err := readToolCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schtasks output: %w", err)
+	}
+
+	var records []ScheduledTaskRecord
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if len(row) < schtasksColumns || !strings.HasPrefix(row[schtasksName], `\`) {
+			continue
+		}
+
+		// schtasks /v lists a task once per trigger
+		name := row[schtasksName]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		record := ScheduledTaskRecord{
+			Name:    name,
+			State:   canonicalState(taskStates, row[schtasksStatus]),
+			Actions: []string{},
+		}
+		if disabled := canonicalState(taskStates, row[schtasksTaskState]); disabled == "Disabled" {
+			record.State = disabled
+		}
+		if !notAvailable(row[schtasksAuthor]) {
+			record.Author = row[schtasksAuthor]
+		}
+		if !notAvailable(row[schtasksRunAs]) {
+			record.RunAs = row[schtasksRunAs]
+		}
+		if action := row[schtasksTaskToRun]; !notAvailable(action) {
+			record.Actions = append(record.Actions, action)
+		}
+		record.LastRunTime = parseLocalizedTime(row[schtasksLastRun])
+		record.NextRunTime = parseLocalizedTime(row[schtasksNextRun])
+		record.LastResult, _ = strconv.ParseInt(row[schtasksLastResult], 10, 64)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// localizedTimeLayouts are the date formats tools print, by how the date is
+// written: day.month.year (German), month/day/year with AM/PM (US),
+// year/month/day (Japanese) and day/month/year (French, British)
+var localizedTimeLayouts = []struct {
+	pattern *regexp.Regexp
+	layout  string
+}{
+	{regexp.MustCompile(`^\d{1,2}\.\d{1,2}\.\d{4} \d{1,2}:\d{2}:\d{2}$`), "2.1.2006 15:04:05"},
+	{regexp.MustCompile(`^\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}:\d{2} [AP]M$`), "1/2/2006 3:04:05 PM"},
+	{regexp.MustCompile(`^\d{4}/\d{1,2}/\d{1,2} \d{1,2}:\d{2}:\d{2}$`), "2006/1/2 15:04:05"},
+	{regexp.MustCompile(`^\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}:\d{2}$`), "2/1/2006 15:04:05"},
+	{regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d{2}:\d{2}$`), "2006-1-2 15:04:05"},
+}
+
+// parseLocalizedTime reads a local time in a regional format and returns it
+// as RFC 3339 UTC, or "" for "N/A", never-run markers and unknown formats
+func parseLocalizedTime(value string) string {
+	value = strings.TrimSpace(value)
+	for _, candidate := range localizedTimeLayouts {
+		if !candidate.pattern.MatchString(value) {
+			continue
+		}
+		t, err := time.ParseInLocation(candidate.layout, value, time.Local)
+		if err != nil || t.Year() < 2000 {
+			// Tasks that never ran report 30.11.1999
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// formatRecordTime converts an ISO 8601 time from PowerShell to RFC 3339 UTC
+func formatRecordTime(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || t.Year() < 2000 {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// canonicalState looks up a state in one of the state tables, keeping the
+// value as it was written when it is unknown
+func canonicalState(states map[string]string, value string) string {
+	if canonical, ok := states[stateKey(value)]; ok {
+		return canonical
+	}
+	return strings.TrimSpace(value)
+}
+
+// splitEndpoint splits a netstat address such as 10.0.0.5:443 or
+// [fe80::1%4]:135 into its address and port
+func splitEndpoint(endpoint string) (string, int) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint, 0
+	}
+	number, _ := strconv.Atoi(port)
+	return host, number
+}
+
+// digitsOnly keeps the ASCII digits of a value
+func digitsOnly(value string) string {
+	var digits strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			digits.WriteByte(value[i])
+		}
+	}
+	return digits.String()
+}
+
+// readToolCSV reads the CSV written by tasklist and schtasks, including the
+// header rows, which callers recognize by content rather than by name
+func readToolCSV(data string) ([][]string, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(decodePolicyText(data))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+		}
+	}
+	return rows, nil
+}
+
+// unmarshalPowerShellJSON decodes ConvertTo-Json output into a slice.
+// PowerShell writes nothing for an empty list and a bare object for a list
+// of one, so both are accepted.
+func unmarshalPowerShellJSON(data string, v interface{}) error {
+	data = strings.TrimSpace(decodePolicyText(data))
+	switch {
+	case data == "":
+		data = "[]"
+	case strings.HasPrefix(data, "{"):
+		data = "[" + data + "]"
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// HostRecordArtifacts parses the process, connection, scheduled task, task
+// definition and event log artifacts into structured records, one artifact
+// per kind. The records are the same whichever tool produced the artifact
+// and whatever the display language of the host.
+func HostRecordArtifacts(artifacts []collector.ArtifactResult) []collector.ArtifactResult {
+	records := make(map[string]interface{})
+	failures := make(map[string][]string)
+
+	for _, artifact := range artifacts {
+		if artifact.Error != nil {
+			continue
+		}
+
+		text := artifactText(artifact)
+		var kind string
+		var parsed interface{}
+		var err error
+		switch artifact.Artifact.Type {
+		case collector.ProcessListType:
+			kind = "process_records"
+			parsed, err = ParseProcessListJSON(text)
+		case collector.TasklistCSVType:
+			kind = "process_records"
+			parsed, err = ParseTasklistCSV(text)
+		case collector.ConnectionListType:
+			kind = "network_connection_records"
+			parsed, err = ParseConnectionListJSON(text)
+		case collector.NetstatTextType:
+			kind = "network_connection_records"
+			parsed = ParseNetstat(text)
+		case collector.ScheduledTaskListType:
+			kind = "scheduled_task_records"
+			parsed, err = ParseScheduledTaskListJSON(text)
+		case collector.SchtasksCSVType:
+			kind = "scheduled_task_records"
+			parsed, err = ParseSchtasksCSV(text)
+		case collector.EventLogXMLType:
+			kind = "event_records"
+			parsed, err = ParseEventXML(text)
+		case collector.ScheduledTaskXMLType:
+			kind = "scheduled_task_definitions"
+			parsed, err = ParseScheduledTaskXML(text)
+		default:
+			continue
+		}
+
+		if err != nil {
+			failures[kind] = append(failures[kind], fmt.Sprintf("%s: %v", artifact.Artifact.Name, err))
+		}
+		records[kind] = appendRecords(records[kind], parsed)
+	}
+
+	var results []collector.ArtifactResult
+	for _, kind := range []struct {
+		name, description, category string
+	}{
+		{"process_records", "Running processes as structured records", "process"},
+		{"network_connection_records", "Network connections as structured records", "network"},
+		{"scheduled_task_records", "Scheduled tasks as structured records", "task"},
+		{"scheduled_task_definitions", "Scheduled task definitions with actions, triggers and principals", "task"},
+		{"event_records", "Event log entries as structured records", "log"},
+	} {
+		data, ok := records[kind.name]
+		if !ok {
+			continue
+		}
+
+		artifact := collector.NewBaseArtifact(kind.name, kind.description, kind.category, kind.name).Artifact
+		artifact.Platform = "windows"
+		result := collector.ArtifactResult{
+			Artifact: artifact,
+			Data:     data,
+			Metadata: collector.Metadata{
+				CollectedAt: time.Now(),
+				Collector:   "detector",
+				Source:      "records",
+			},
+		}
+		if len(failures[kind.name]) > 0 {
+			result.Metadata.Tags = map[string]string{"parse_errors": strings.Join(failures[kind.name], "; ")}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// appendRecords appends parsed records to the records of the same kind
+// collected so far
+func appendRecords(existing, parsed interface{}) interface{} {
+	switch records := parsed.(type) {
+	case []ProcessRecord:
+		previous, _ := existing.([]ProcessRecord)
+		return append(previous, records...)
+	case []ConnectionRecord:
+		previous, _ := existing.([]ConnectionRecord)
+		return append(previous, records...)
+	case []ScheduledTaskRecord:
+		previous, _ := existing.([]ScheduledTaskRecord)
+		return append(previous, records...)
+	case []WinEvent:
+		previous, _ := existing.([]WinEvent)
+		return append(previous, records...)
+	case []TaskDefinition:
+		previous, _ := existing.([]TaskDefinition)
+		return append(previous, records...)
+	}
+	return existing
+}
@@ -0,0 +1,275 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// Kinds of inbound Remote Desktop events
+const (
+	RDPLogon        = "logon"         // Security 4624 with logon type 10
+	RDPSessionLogon = "session_logon" // LocalSessionManager 21
+	RDPDisconnect   = "disconnect"    // LocalSessionManager 24
+	RDPReconnect    = "reconnect"     // LocalSessionManager 25
+)
+
+// rdpSessionKinds are the LocalSessionManager events read
+var rdpSessionKinds = map[int]string{21: RDPSessionLogon, 24: RDPDisconnect, 25: RDPReconnect}
+
+// RDPSession is an inbound Remote Desktop logon or session event
+type RDPSession struct {
+	Time      time.Time `json:"time"`
+	EventID   int       `json:"event_id"`
+	Kind      string    `json:"kind"`
+	User      string    `json:"user"`
+	Address   string    `json:"address"`
+	SessionID string    `json:"session_id,omitempty"`
+	LogonID   string    `json:"logon_id,omitempty"`
+}
+
+// ParseRDPSessionEvents parses the event XML of an RDP sessions artifact
+// into session events, oldest first. Console sessions, which
+// LocalSessionManager logs from LOCAL, are left out.
+func ParseRDPSessionEvents(data string) ([]RDPSession, error) {
+	events, err := ParseEventXML(data)
+	sessions := []RDPSession{}
+	for _, event := range events {
+		session := RDPSession{Time: event.TimeCreated.UTC(), EventID: event.EventID}
+		if event.EventID == 4624 {
+			if event.Data["LogonType"] != "10" {
+				continue
+			}
+			session.Kind = RDPLogon
+			session.User = event.Data["TargetUserName"]
+			if domain := event.Data["TargetDomainName"]; domain != "" {
+				session.User = domain + `\` + session.User
+			}
+			session.Address = event.Data["IpAddress"]
+			session.LogonID = event.Data["TargetLogonId"]
+		} else {
+			kind, ok := rdpSessionKinds[event.EventID]
+			if !ok || !strings.EqualFold(event.Channel, collector.LocalSessionManagerChannel) || strings.EqualFold(event.Data["Address"], "LOCAL") {
+				continue
+			}
+			session.Kind = kind
+			session.User = event.Data["User"]
+			session.Address = event.Data["Address"]
+			session.SessionID = event.Data["SessionID"]
+		}
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Time.Before(sessions[j].Time) })
+	return sessions, err
+}
+
+// RemoteAccessOverview is the Remote Desktop and remote access tool
+// evidence of a host, as the user activity report shows it
+type RemoteAccessOverview struct {
+	Settings *collector.RDPSettings
+	Sessions []RDPSession
+	History  []collector.RDPConnection
+	Cache    []collector.RDPCacheFile
+	Tools    []collector.RemoteTool
+	Errors   []string
+}
+
+// ExtractRemoteAccessOverview gathers the remote access artifacts. It
+// returns nil when none were collected.
+func ExtractRemoteAccessOverview(artifacts []collector.ArtifactResult) *RemoteAccessOverview {
+	var overview *RemoteAccessOverview
+
+	for _, artifact := range artifacts {
+		switch artifact.Artifact.Type {
+		case collector.RDPHistoryType, collector.RDPSessionsType, collector.RDPCacheType, collector.RDPSettingsType, collector.RemoteToolsType:
+		default:
+			continue
+		}
+
+		if overview == nil {
+			overview = &RemoteAccessOverview{}
+		}
+		if artifact.Error != nil {
+			overview.Errors = append(overview.Errors, fmt.Sprintf("%s: %v", artifact.Artifact.Name, artifact.Error))
+			continue
+		}
+
+		switch artifact.Artifact.Type {
+		case collector.RDPHistoryType:
+			overview.History = append(overview.History, collector.RDPConnections(artifact)...)
+		case collector.RDPSessionsType:
+			sessions, err := ParseRDPSessionEvents(artifactText(artifact))
+			if err != nil {
+				overview.Errors = append(overview.Errors, fmt.Sprintf("%s: failed to parse: %v", artifact.Artifact.Name, err))
+			}
+			overview.Sessions = append(overview.Sessions, sessions...)
+		case collector.RDPCacheType:
+			overview.Cache = append(overview.Cache, collector.RDPCacheFiles(artifact)...)
+		case collector.RDPSettingsType:
+			overview.Settings = collector.RDPSettingsOf(artifact)
+		case collector.RemoteToolsType:
+			overview.Tools = append(overview.Tools, collector.RemoteToolEntries(artifact)...)
+		}
+	}
+
+	return overview
+}
+
+// withinLookback reports whether t lies within the remote access lookback
+// before the artifact was collected
+func withinLookback(artifact collector.ArtifactResult, t time.Time) bool {
+	collectedAt := artifact.Metadata.CollectedAt
+	if collectedAt.IsZero() {
+		collectedAt = time.Now()
+	}
+	return !t.IsZero() && collectedAt.Sub(t) <= collector.RemoteAccessLookback
+}
+
+// evaluateRDPEnabledRule flags Remote Desktop being enabled when the key
+// that enables it was written within the lookback
+func (d *Detector) evaluateRDPEnabledRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.RDPSettingsType {
+			continue
+		}
+		settings := collector.RDPSettingsOf(artifact)
+		if settings == nil || !settings.Enabled || !withinLookback(artifact, settings.EnabledModified) {
+			continue
+		}
+		modified := settings.EnabledModified.UTC().Format(time.RFC3339)
+		findings = append(findings, rdpSettingsFinding(rule, artifact.Artifact.Name, *settings,
+			fmt.Sprintf("Remote Desktop is enabled by %s, written %s", settings.EnabledSource, modified),
+			fmt.Sprintf("fDenyTSConnections = 0 in %s", settings.EnabledSource)))
+	}
+	return findings
+}
+
+// evaluateRDPNLARule flags Remote Desktop accepting connections without
+// Network Level Authentication, which exposes the logon screen to anyone
+// who can reach the port
+func (d *Detector) evaluateRDPNLARule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.RDPSettingsType {
+			continue
+		}
+		settings := collector.RDPSettingsOf(artifact)
+		if settings == nil || !settings.Enabled || settings.NLARequired {
+			continue
+		}
+		findings = append(findings, rdpSettingsFinding(rule, artifact.Artifact.Name, *settings,
+			fmt.Sprintf("Remote Desktop is enabled on port %d without Network Level Authentication (%s)", settings.Port, settings.NLASource),
+			fmt.Sprintf("UserAuthentication = 0 in %s", settings.NLASource)))
+	}
+	return findings
+}
+
+// rdpSettingsFinding builds a finding on the RDP settings of a host
+func rdpSettingsFinding(rule Rule, source string, settings collector.RDPSettings, description, evidence string) Finding {
+	metadata := map[string]interface{}{
+		"enabled":          settings.Enabled,
+		"enabled_source":   settings.EnabledSource,
+		"enabled_modified": settings.EnabledModified.UTC().Format(time.RFC3339),
+		"nla_required":     settings.NLARequired,
+		"nla_source":       settings.NLASource,
+		"port":             settings.Port,
+	}
+	return Finding{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Severity:    rule.Severity,
+		Category:    rule.Category,
+		Description: description,
+		Evidence: []Evidence{{
+			Type:        "rdp_settings",
+			Source:      source,
+			Value:       evidence,
+			Description: evidence,
+			Confidence:  0.8,
+			Metadata:    metadata,
+		}},
+		Tags:      rule.Tags,
+		Timestamp: time.Now(),
+		Metadata:  metadata,
+	}
+}
+
+// evaluateRemoteToolRule flags remote access tools with evidence of an
+// install within the lookback, one finding per tool with each recent
+// service, Uninstall entry or directory as evidence
+func (d *Detector) evaluateRemoteToolRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	type install struct {
+		evidence []Evidence
+		first    time.Time
+		logs     []string
+	}
+	installs := make(map[string]*install)
+	var order []string
+
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.RemoteToolsType {
+			continue
+		}
+		for i, tool := range collector.RemoteToolEntries(artifact) {
+			if !withinLookback(artifact, tool.InstalledAt) {
+				continue
+			}
+			entry, ok := installs[tool.Tool]
+			if !ok {
+				entry = &install{first: tool.InstalledAt}
+				installs[tool.Tool] = entry
+				order = append(order, tool.Tool)
+			}
+			if tool.InstalledAt.Before(entry.first) {
+				entry.first = tool.InstalledAt
+			}
+			for _, log := range tool.Logs {
+				if !containsString(entry.logs, log) {
+					entry.logs = append(entry.logs, log)
+				}
+			}
+			value := tool.Path
+			if value == "" {
+				value = tool.Name
+			}
+			entry.evidence = append(entry.evidence, Evidence{
+				Type:        "remote_tool",
+				Source:      artifact.Artifact.Name,
+				Value:       value,
+				Description: fmt.Sprintf("%s %s, %s", tool.Evidence, tool.Name, tool.InstalledAt.UTC().Format(time.RFC3339)),
+				Confidence:  0.8,
+				Metadata:    map[string]interface{}{"evidence": tool.Evidence, "state": tool.State},
+				Ref:         collector.NewRecordRef("", artifact.Artifact.Name, "", i),
+			})
+		}
+	}
+
+	var findings []Finding
+	for _, name := range order {
+		entry := installs[name]
+		description := fmt.Sprintf("%s was installed at %s", name, entry.first.UTC().Format(time.RFC3339))
+		if len(entry.logs) > 0 {
+			description += "; its logs are at " + strings.Join(entry.logs, ", ")
+		}
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    rule.Severity,
+			Category:    rule.Category,
+			Description: description,
+			Evidence:    entry.evidence,
+			Tags:        rule.Tags,
+			Timestamp:   time.Now(),
+			Metadata: map[string]interface{}{
+				"tool":         name,
+				"installed_at": entry.first.UTC().Format(time.RFC3339),
+				"logs":         entry.logs,
+			},
+		})
+	}
+	return findings
+}
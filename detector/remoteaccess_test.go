@@ -0,0 +1,124 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// rdpClient is the address the test RDP sessions come from
+const rdpClient = "203.0.113.7"
+
+// TestRemoteAccessRules builds the remote access artifacts of a host where
+// RDP was recently enabled without NLA and AnyDesk recently installed, and
+// checks that the Security and LocalSessionManager events pair into
+// sessions, and that RT018, RT019 and RT020 fire, RT020 only for the tool
+// installed within the lookback, also once the artifacts are read back from
+// JSON
+func TestRemoteAccessRules(t *testing.T) {
+	recent := time.Now().Add(-36 * time.Hour).UTC().Truncate(time.Second)
+	old := time.Now().Add(-60 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	history := []collector.RDPConnection{
+		{Source: collector.RDPSourceDefault, User: "alice", Host: "jump01.corp.example", UsernameHint: `CORP\alice`, Path: `C:\Users\alice\Documents\Default.rdp`, LastWrite: recent},
+		{Source: collector.RDPSourceMRU, User: "alice", Host: "10.0.0.20", Path: `HKU\S-1-5-21-1-2-3-1001\Software\Microsoft\Terminal Server Client\Default\MRU0`, LastWrite: recent},
+	}
+	settings := collector.RDPSettings{
+		Enabled:         true,
+		EnabledSource:   `HKLM\SYSTEM\CurrentControlSet\Control\Terminal Server`,
+		EnabledModified: recent,
+		NLASource:       `HKLM\SYSTEM\CurrentControlSet\Control\Terminal Server\WinStations\RDP-Tcp`,
+		Port:            3389,
+	}
+	tools := []collector.RemoteTool{
+		{Tool: "AnyDesk", Evidence: collector.RemoteToolService, Name: "AnyDesk", Path: `C:\Program Files (x86)\AnyDesk\AnyDesk.exe`, State: "auto", InstalledAt: recent, Logs: []string{`C:\ProgramData\AnyDesk\ad_svc.trace`}},
+		{Tool: "AnyDesk", Evidence: collector.RemoteToolDirectory, Name: "AnyDesk", Path: `C:\ProgramData\AnyDesk`, InstalledAt: recent},
+		{Tool: "TeamViewer", Evidence: collector.RemoteToolInstall, Name: "TeamViewer", Path: `C:\Program Files\TeamViewer`, InstalledAt: old},
+	}
+
+	events := rdpEvent("Security", 4624, recent, "<EventData><Data Name='TargetUserName'>alice</Data><Data Name='TargetDomainName'>CORP</Data><Data Name='LogonType'>10</Data><Data Name='IpAddress'>"+rdpClient+"</Data><Data Name='TargetLogonId'>0x3e7a1</Data></EventData>") +
+		rdpEvent("Security", 4624, recent, "<EventData><Data Name='TargetUserName'>svc</Data><Data Name='LogonType'>3</Data><Data Name='IpAddress'>10.0.0.9</Data></EventData>") +
+		rdpEvent(collector.LocalSessionManagerChannel, 24, recent.Add(time.Hour), rdpUserData(`CORP\alice`, "2", rdpClient)) +
+		rdpEvent(collector.LocalSessionManagerChannel, 21, recent.Add(time.Second), rdpUserData(`CORP\alice`, "2", rdpClient)) +
+		rdpEvent(collector.LocalSessionManagerChannel, 21, recent, rdpUserData(`CORP\bob`, "1", "LOCAL"))
+
+	artifacts := []collector.ArtifactResult{
+		collector.RemoteAccessArtifact("rdp_history", "RDP connections", collector.RDPHistoryType, history, len(history)),
+		collector.RemoteAccessArtifact("rdp_sessions", "RDP sessions", collector.RDPSessionsType, events, 5),
+		collector.RemoteAccessArtifact("rdp_settings", "RDP settings", collector.RDPSettingsType, settings, 1),
+		collector.RemoteAccessArtifact("remote_tools", "Remote access tools", collector.RemoteToolsType, tools, len(tools)),
+	}
+
+	overview := ExtractRemoteAccessOverview(artifacts)
+	if overview == nil || len(overview.Errors) > 0 {
+		t.Fatalf("remote access overview: %+v", overview)
+	}
+	kinds := make([]string, 0, len(overview.Sessions))
+	for _, session := range overview.Sessions {
+		if session.Address != rdpClient || session.User != `CORP\alice` {
+			t.Fatalf("session %+v is not alice's from %s", session, rdpClient)
+		}
+		kinds = append(kinds, session.Kind)
+	}
+	if strings.Join(kinds, ",") != "logon,session_logon,disconnect" {
+		t.Fatalf("sessions are %v, want logon, session logon and disconnect in order", kinds)
+	}
+
+	checkRemoteAccessFindings(t, artifacts)
+	// Read back from JSON, as from a bundle
+	var stored []collector.ArtifactResult
+	for _, artifact := range artifacts {
+		raw, err := json.Marshal(artifact.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			t.Fatal(err)
+		}
+		artifact.Data = data
+		stored = append(stored, artifact)
+	}
+	checkRemoteAccessFindings(t, stored)
+}
+
+// checkRemoteAccessFindings evaluates the remote access artifacts and checks
+// each remote access rule fires once, RT020 for AnyDesk with both of its
+// recent records referenced
+func checkRemoteAccessFindings(t *testing.T, artifacts []collector.ArtifactResult) {
+	t.Helper()
+	findings, err := NewDetector().Evaluate(artifacts)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	fired := make(map[string]int)
+	for _, finding := range findings {
+		fired[finding.RuleID]++
+		if finding.RuleID != "RT020" {
+			continue
+		}
+		if finding.Metadata["tool"] != "AnyDesk" || len(finding.Evidence) != 2 || finding.Evidence[1].Ref == nil || finding.Evidence[1].Ref.Index != 1 {
+			t.Errorf("RT020 finding %q does not reference the two recent AnyDesk records", finding.Description)
+		}
+	}
+	for _, rule := range []string{"RT018", "RT019", "RT020"} {
+		if fired[rule] != 1 {
+			t.Errorf("%s fired %d times, want once", rule, fired[rule])
+		}
+	}
+}
+
+// rdpEvent returns an event as wevtutil exports it
+func rdpEvent(channel string, id int, at time.Time, body string) string {
+	return fmt.Sprintf("<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='test'/><EventID>%d</EventID><TimeCreated SystemTime='%s'/><EventRecordID>%d</EventRecordID><Channel>%s</Channel><Computer>RDP-HOST</Computer></System>%s</Event>",
+		id, at.Format(time.RFC3339Nano), at.Unix(), channel, body)
+}
+
+// rdpUserData returns the UserData of a LocalSessionManager event
+func rdpUserData(user, session, address string) string {
+	return fmt.Sprintf("<UserData><EventXML xmlns='Event_NS'><User>%s</User><SessionID>%s</SessionID><Address>%s</Address></EventXML></UserData>", user, session, address)
+}
@@ -0,0 +1,438 @@
+package detector
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// logonAuditGUID is the advanced audit policy subcategory for logon events
+const logonAuditGUID = "{0CCE9215-69AE-11D9-BED3-505054503030}"
+
+// UserRightAssignment is a privilege and the principals it is granted to
+type UserRightAssignment struct {
+	Right      string   `json:"right"`
+	Principals []string `json:"principals"`
+}
+
+// AuditSetting is one advanced audit policy subcategory
+type AuditSetting struct {
+	Subcategory string `json:"subcategory"`
+	GUID        string `json:"guid"`
+	Setting     string `json:"setting"`
+}
+
+// LocalAccount is a local user account
+type LocalAccount struct {
+	Name                 string `json:"name"`
+	SID                  string `json:"sid"`
+	Enabled              bool   `json:"enabled"`
+	PasswordNeverExpires bool   `json:"password_never_expires"`
+	Administrator        bool   `json:"administrator"`
+}
+
+// SecurityPosture is the effective security policy parsed from the policy
+// artifacts. Errors lists the policy artifacts that could not be collected.
+type SecurityPosture struct {
+	SystemAccess map[string]string     `json:"system_access,omitempty"`
+	EventAudit   map[string]string     `json:"event_audit,omitempty"`
+	UserRights   []UserRightAssignment `json:"user_rights,omitempty"`
+	AuditPolicy  []AuditSetting        `json:"audit_policy,omitempty"`
+	Accounts     []LocalAccount        `json:"accounts,omitempty"`
+	AppliedGPOs  []string              `json:"applied_gpos,omitempty"`
+	Errors       []string              `json:"errors,omitempty"`
+}
+
+// sensitiveRights are privileges commonly abused for credential theft or
+// code execution as SYSTEM, with the principals expected to hold them
+var sensitiveRights = map[string][]string{
+	"SeDebugPrivilege":      {"S-1-5-32-544"},
+	"SeTcbPrivilege":        {},
+	"SeLoadDriverPrivilege": {"S-1-5-32-544", "S-1-5-32-550"},
+}
+
+// wellKnownPrincipals names the well-known SIDs found in security templates
+var wellKnownPrincipals = map[string]string{
+	"S-1-1-0":      "Everyone",
+	"S-1-5-11":     "Authenticated Users",
+	"S-1-5-18":     "SYSTEM",
+	"S-1-5-19":     "LOCAL SERVICE",
+	"S-1-5-20":     "NETWORK SERVICE",
+	"S-1-5-32-544": "Administrators",
+	"S-1-5-32-545": "Users",
+	"S-1-5-32-546": "Guests",
+	"S-1-5-32-550": "Print Operators",
+	"S-1-5-32-551": "Backup Operators",
+	"S-1-5-32-555": "Remote Desktop Users",
+}
+
+// PrincipalName returns a readable name for a principal from a security
+// template, resolving well-known SIDs
+func PrincipalName(principal string) string {
+	if name, ok := wellKnownPrincipals[principal]; ok {
+		return name
+	}
+	return principal
+}
+
+// ParseSecurityTemplate parses a secedit /export template. It returns the
+// [System Access] and [Event Audit] settings and the [Privilege Rights]
+// assignments; SIDs lose their leading '*'.
+func ParseSecurityTemplate(data string) (systemAccess, eventAudit map[string]string, rights []UserRightAssignment) {
+	systemAccess = make(map[string]string)
+	eventAudit = make(map[string]string)
+
+	section := ""
+	for _, line := range strings.Split(decodePolicyText(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch section {
+		case "system access":
+			systemAccess[key] = value
+		case "event audit":
+			eventAudit[key] = value
+		case "privilege rights":
+			assignment := UserRightAssignment{Right: key}
+			for _, principal := range strings.Split(value, ",") {
+				if principal = strings.TrimPrefix(strings.TrimSpace(principal), "*"); principal != "" {
+					assignment.Principals = append(assignment.Principals, principal)
+				}
+			}
+			rights = append(rights, assignment)
+		}
+	}
+
+	return systemAccess, eventAudit, rights
+}
+
+// ParseAuditPolicyCSV parses 'auditpol /get /category:* /r' output
+func ParseAuditPolicyCSV(data string) ([]AuditSetting, error) {
+	records, err := readPolicyCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse audit policy: %w", err)
+	}
+
+	var settings []AuditSetting
+	for _, record := range records {
+		if record["Subcategory"] == "" {
+			continue
+		}
+		settings = append(settings, AuditSetting{
+			Subcategory: record["Subcategory"],
+			GUID:        strings.ToUpper(record["Subcategory GUID"]),
+			Setting:     record["Inclusion Setting"],
+		})
+	}
+	return settings, nil
+}
+
+// ParseLocalAccountsCSV parses the local accounts listing
+func ParseLocalAccountsCSV(data string) ([]LocalAccount, error) {
+	records, err := readPolicyCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse local accounts: %w", err)
+	}
+
+	var accounts []LocalAccount
+	for _, record := range records {
+		if record["Name"] == "" {
+			continue
+		}
+		accounts = append(accounts, LocalAccount{
+			Name:                 record["Name"],
+			SID:                  record["SID"],
+			Enabled:              strings.EqualFold(record["Enabled"], "true"),
+			PasswordNeverExpires: strings.EqualFold(record["PasswordNeverExpires"], "true"),
+			Administrator:        strings.EqualFold(record["Administrator"], "true"),
+		})
+	}
+	return accounts, nil
+}
+
+// ParseGPResultXML returns the names of the GPOs in a gpresult /x report
+func ParseGPResultXML(data string) ([]string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(decodePolicyText(data)))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// The text is already decoded; the declaration may still say UTF-16
+		return input, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	var path []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return names, fmt.Errorf("failed to parse gpresult XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		case xml.CharData:
+			if len(path) >= 2 && path[len(path)-1] == "Name" && path[len(path)-2] == "GPO" {
+				if name := strings.TrimSpace(string(t)); name != "" && !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// ExtractSecurityPosture parses the policy artifacts into a security posture.
+// It returns nil when no policy artifacts were collected.
+func ExtractSecurityPosture(artifacts []collector.ArtifactResult) *SecurityPosture {
+	var posture *SecurityPosture
+
+	for _, artifact := range artifacts {
+		switch artifact.Artifact.Type {
+		case collector.GroupPolicyType, collector.SecurityPolicyType, collector.AuditPolicyType, collector.LocalAccountsType:
+		default:
+			continue
+		}
+
+		if posture == nil {
+			posture = &SecurityPosture{}
+		}
+		if artifact.Error != nil {
+			posture.Errors = append(posture.Errors, fmt.Sprintf("%s: %v", artifact.Artifact.Name, artifact.Error))
+			continue
+		}
+
+		text := artifactText(artifact)
+		var err error
+		switch artifact.Artifact.Type {
+		case collector.GroupPolicyType:
+			posture.AppliedGPOs, err = ParseGPResultXML(text)
+		case collector.SecurityPolicyType:
+			posture.SystemAccess, posture.EventAudit, posture.UserRights = ParseSecurityTemplate(text)
+		case collector.AuditPolicyType:
+			posture.AuditPolicy, err = ParseAuditPolicyCSV(text)
+		case collector.LocalAccountsType:
+			posture.Accounts, err = ParseLocalAccountsCSV(text)
+		}
+		if err != nil {
+			posture.Errors = append(posture.Errors, fmt.Sprintf("%s: %v", artifact.Artifact.Name, err))
+		}
+	}
+
+	return posture
+}
+
+// SecurityPostureArtifacts returns the parsed security posture as an artifact
+// so the structured records are packaged with the raw tool output
+func SecurityPostureArtifacts(artifacts []collector.ArtifactResult) []collector.ArtifactResult {
+	posture := ExtractSecurityPosture(artifacts)
+	if posture == nil {
+		return nil
+	}
+
+	artifact := collector.NewBaseArtifact(
+		"security_posture",
+		"Parsed user rights assignments, audit policy and local accounts",
+		"policy",
+		"security_posture",
+	).Artifact
+	artifact.Platform = "windows"
+
+	return []collector.ArtifactResult{
+		{
+			Artifact: artifact,
+			Data:     posture,
+			Metadata: collector.Metadata{
+				CollectedAt: time.Now(),
+				Collector:   "detector",
+				Source:      "policy",
+			},
+		},
+	}
+}
+
+// evaluatePolicyRule flags risky security policy settings: sensitive
+// privileges granted beyond their expected holders, logon auditing disabled
+// and enabled administrator accounts whose passwords never expire
+func (d *Detector) evaluatePolicyRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	posture := ExtractSecurityPosture(artifacts)
+	if posture == nil {
+		return nil
+	}
+
+	var findings []Finding
+	newFinding := func(severity, description, evidenceType, value, evidenceDescription string, metadata map[string]interface{}) Finding {
+		return Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    severity,
+			Category:    rule.Category,
+			Description: description,
+			Evidence: []Evidence{
+				{
+					Type:        evidenceType,
+					Source:      "security_policy",
+					Value:       value,
+					Description: evidenceDescription,
+					Confidence:  0.9,
+					Metadata:    metadata,
+				},
+			},
+			Tags:      rule.Tags,
+			Timestamp: time.Now(),
+			Metadata:  metadata,
+		}
+	}
+
+	rights := make([]string, 0, len(sensitiveRights))
+	for right := range sensitiveRights {
+		rights = append(rights, right)
+	}
+	sort.Strings(rights)
+
+	for _, right := range rights {
+		for _, assignment := range posture.UserRights {
+			if !strings.EqualFold(assignment.Right, right) {
+				continue
+			}
+			for _, principal := range assignment.Principals {
+				if isExpectedPrincipal(principal, sensitiveRights[right]) {
+					continue
+				}
+				name := PrincipalName(principal)
+				findings = append(findings, newFinding(
+					"high",
+					fmt.Sprintf("%s is granted to %s", right, name),
+					"user_right",
+					right,
+					fmt.Sprintf("%s holds %s, which allows access to other processes or the kernel", name, right),
+					map[string]interface{}{"right": right, "principal": principal, "principal_name": name},
+				))
+			}
+		}
+	}
+
+	if disabled, source := logonAuditingDisabled(posture); disabled {
+		findings = append(findings, newFinding(
+			"high",
+			"Logon auditing is disabled",
+			"audit_policy",
+			"Logon: No Auditing",
+			fmt.Sprintf("Logon events are not audited (%s), hiding interactive and network logons", source),
+			map[string]interface{}{"subcategory": "Logon", "guid": logonAuditGUID, "source": source},
+		))
+	}
+
+	for _, account := range posture.Accounts {
+		if !account.Enabled || !account.Administrator || !account.PasswordNeverExpires {
+			continue
+		}
+		findings = append(findings, newFinding(
+			"medium",
+			fmt.Sprintf("Administrator account %s has a password that never expires", account.Name),
+			"local_account",
+			account.Name,
+			"Enabled member of the local Administrators group with a non-expiring password",
+			map[string]interface{}{"account": account.Name, "sid": account.SID},
+		))
+	}
+
+	return findings
+}
+
+// logonAuditingDisabled reports whether logon events are not audited. The
+// advanced audit policy takes precedence over the legacy [Event Audit] setting.
+func logonAuditingDisabled(posture *SecurityPosture) (bool, string) {
+	for _, setting := range posture.AuditPolicy {
+		if setting.GUID == logonAuditGUID || strings.EqualFold(setting.Subcategory, "Logon") {
+			return strings.EqualFold(setting.Setting, "No Auditing"), "auditpol"
+		}
+	}
+	if value, ok := posture.EventAudit["AuditLogonEvents"]; ok {
+		return value == "0", "secedit AuditLogonEvents"
+	}
+	return false, ""
+}
+
+// isExpectedPrincipal reports whether principal is one of the expected SIDs,
+// either as a SID or by its well-known name
+func isExpectedPrincipal(principal string, expected []string) bool {
+	for _, sid := range expected {
+		if strings.EqualFold(principal, sid) || strings.EqualFold(principal, wellKnownPrincipals[sid]) ||
+			strings.EqualFold(principal, `BUILTIN\`+wellKnownPrincipals[sid]) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPolicyCSV reads CSV with a header row into one map per record
+func readPolicyCSV(data string) ([]map[string]string, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimSpace(decodePolicyText(data))))
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	var records []map[string]string
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				record[strings.TrimSpace(name)] = strings.TrimSpace(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodePolicyText converts UTF-16LE tool output, as written by secedit and
+// gpresult, to UTF-8 and strips byte order marks and carriage returns
+func decodePolicyText(data string) string {
+	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+		raw := []byte(data[2:])
+		units := make([]uint16, len(raw)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(raw[2*i:])
+		}
+		data = string(utf16.Decode(units))
+	}
+	data = strings.TrimPrefix(data, "\ufeff")
+	return strings.ReplaceAll(data, "\r", "")
+}
@@ -0,0 +1,88 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the canonical severity of a finding. Higher values are more
+// severe, so severities compare with < and >.
+type Severity int
+
+// Severities, least severe first. SeverityUnknown is the zero value and is
+// never the severity of a validated finding.
+const (
+	SeverityUnknown Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// severityNames are the names findings store their severity as
+var severityNames = map[Severity]string{
+	SeverityLow:      "low",
+	SeverityMedium:   "medium",
+	SeverityHigh:     "high",
+	SeverityCritical: "critical",
+}
+
+// ParseSeverity parses a finding severity or a Sigma rule level, ignoring
+// case. Sigma's informational level has no severity of its own and maps to
+// low.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "informational", "info", "low":
+		return SeverityLow, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity %q (valid: informational, low, medium, high, critical)", s)
+}
+
+// SeverityOf returns the severity s names, or SeverityUnknown
+func SeverityOf(s string) Severity {
+	severity, _ := ParseSeverity(s)
+	return severity
+}
+
+// String returns the name a finding stores the severity as
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Level returns the severity of the finding, or SeverityUnknown when it
+// names none
+func (f Finding) Level() Severity {
+	return SeverityOf(f.Severity)
+}
+
+// normalizeSeverities rewrites the severities of a rule's findings in their
+// canonical form and returns the findings kept. A finding naming no known
+// severity takes its rule's severity, or is dropped when the rule names none
+// either; both are reported in the warnings, so one bad finding does not
+// fail the whole evaluation.
+func (d *Detector) normalizeSeverities(rule Rule, findings []Finding) []Finding {
+	kept := findings[:0]
+	for _, finding := range findings {
+		severity, err := ParseSeverity(finding.Severity)
+		if err != nil {
+			severity = SeverityOf(rule.Severity)
+			if severity == SeverityUnknown {
+				d.warnings = append(d.warnings, fmt.Sprintf("finding of rule %s skipped: it has an %v", rule.ID, err))
+				continue
+			}
+			d.warnings = append(d.warnings, fmt.Sprintf("finding of rule %s has an %v; using the rule's severity, %s", rule.ID, err, severity))
+		}
+		finding.Severity = severity.String()
+		kept = append(kept, finding)
+	}
+	return kept
+}
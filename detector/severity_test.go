@@ -0,0 +1,75 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+func TestParseSeverity(t *testing.T) {
+	levels := map[string]Severity{
+		"informational": SeverityLow,
+		"low":           SeverityLow,
+		"Medium":        SeverityMedium,
+		"high":          SeverityHigh,
+		"CRITICAL":      SeverityCritical,
+	}
+	for level, want := range levels {
+		if got, err := ParseSeverity(level); err != nil || got != want {
+			t.Errorf("level %q parsed as %s (%v), want %s", level, got, err, want)
+		}
+	}
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Error(`unknown severity "severe" was accepted`)
+	}
+}
+
+func TestAddRuleStoresCanonicalSeverity(t *testing.T) {
+	d := NewDetector()
+	if err := d.AddRule(Rule{ID: "TEST-INFO", Name: "Informational rule", Severity: "informational", Category: "log"}); err != nil {
+		t.Fatal(err)
+	}
+	if rules := d.GetBuiltInRules(); rules[len(rules)-1].Severity != "low" {
+		t.Errorf("informational rule stored with severity %q, want low", rules[len(rules)-1].Severity)
+	}
+	if err := d.AddRule(Rule{ID: "TEST-BAD", Severity: "severe"}); err == nil {
+		t.Error(`rule with severity "severe" was accepted`)
+	}
+}
+
+func TestEvaluateKeepsFindingsBesideABadSeverity(t *testing.T) {
+	d := &Detector{rules: []Rule{
+		{ID: "TEST-BAD", Name: "Bad severity", Severity: "severe", Category: "process", Enabled: true},
+		{ID: "TEST-GOOD", Name: "Good severity", Severity: "High", Category: "process", Enabled: true},
+	}}
+	artifacts := []collector.ArtifactResult{{
+		Artifact: collector.Artifact{Name: "process_list", Category: "process"},
+		Data:     "suspicious.exe\n",
+	}}
+
+	findings, err := d.Evaluate(artifacts)
+	if err != nil {
+		t.Fatalf("one bad severity failed the evaluation: %v", err)
+	}
+	if len(findings) != 1 || findings[0].RuleID != "TEST-GOOD" || findings[0].Severity != "high" {
+		t.Fatalf("expected only the TEST-GOOD finding with severity high, got %+v", findings)
+	}
+	if warnings := d.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "TEST-BAD") {
+		t.Errorf("expected one warning about TEST-BAD, got %v", warnings)
+	}
+}
+
+func TestNormalizeSeveritiesFallsBackToTheRule(t *testing.T) {
+	d := NewDetector()
+	findings := d.normalizeSeverities(Rule{ID: "TEST", Severity: "medium"}, []Finding{
+		{RuleID: "TEST", Severity: "bogus"},
+		{RuleID: "TEST", Severity: "Critical"},
+	})
+	if len(findings) != 2 || findings[0].Severity != "medium" || findings[1].Severity != "critical" {
+		t.Errorf("severities normalized to %+v, want medium and critical", findings)
+	}
+	if len(d.Warnings()) != 1 {
+		t.Errorf("expected one warning, got %v", d.Warnings())
+	}
+}
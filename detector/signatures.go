@@ -0,0 +1,159 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// Location risks of an executable's directory
+const (
+	LocationRiskHigh   = "high"   // temp, AppData, Downloads, Public and similar drop locations
+	LocationRiskMedium = "medium" // elsewhere in a user profile or ProgramData
+	LocationRiskLow    = "low"    // system and program directories
+)
+
+// locationRisks are path fragments, lower case with backslashes, and the
+// risk of running from them, most specific first
+var locationRisks = []struct {
+	fragment, risk string
+}{
+	{`\appdata\`, LocationRiskHigh},
+	{`\temp\`, LocationRiskHigh},
+	{`\tmp\`, LocationRiskHigh},
+	{`\downloads\`, LocationRiskHigh},
+	{`\users\public\`, LocationRiskHigh},
+	{`\$recycle.bin\`, LocationRiskHigh},
+	{`\perflogs\`, LocationRiskHigh},
+	{`\users\`, LocationRiskMedium},
+	{`\programdata\`, LocationRiskMedium},
+}
+
+// ExecutableLocationRisk rates the directory an executable runs from by how
+// easily ordinary users, and so malware, can write to it. It is empty when
+// the path is unknown.
+func ExecutableLocationRisk(executable string) string {
+	if executable == "" {
+		return ""
+	}
+	lower := strings.ToLower(strings.ReplaceAll(executable, "/", `\`))
+	for _, location := range locationRisks {
+		if strings.Contains(lower, location.fragment) {
+			return location.risk
+		}
+	}
+	return LocationRiskLow
+}
+
+// signatureValid maps a Get-AuthenticodeSignature status to whether the
+// file is validly signed. Statuses that say nothing about the file, and an
+// empty status where the check was not available, give nil.
+func signatureValid(status string) *bool {
+	var valid bool
+	switch status {
+	case "Valid":
+		valid = true
+	case "NotSigned", "HashMismatch", "NotTrusted", "Incompatible":
+		valid = false
+	default:
+		return nil
+	}
+	return &valid
+}
+
+// unsignedExecutable is one executable flagged by the signature rule, with
+// every process that runs it
+type unsignedExecutable struct {
+	record ProcessRecord
+	source string
+	pids   []int
+}
+
+// evaluateSignatureRule flags executables running from user-writable
+// locations without a valid signature, one finding per executable. Unsigned
+// executables in temp or AppData style locations are high severity; those
+// elsewhere in a profile, and those in high risk locations whose signature
+// could not be checked, are medium.
+func (d *Detector) evaluateSignatureRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	executables := make(map[string]*unsignedExecutable)
+	var order []string
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.ProcessListType {
+			continue
+		}
+		records, _ := ParseProcessListJSON(artifactText(artifact))
+
+		for _, record := range records {
+			if record.LocationRisk != LocationRiskHigh && record.LocationRisk != LocationRiskMedium {
+				continue
+			}
+			if record.Signed != nil && *record.Signed {
+				continue
+			}
+			if record.Signed == nil && record.LocationRisk != LocationRiskHigh {
+				continue
+			}
+
+			key := strings.ToLower(record.Path)
+			executable, ok := executables[key]
+			if !ok {
+				executable = &unsignedExecutable{record: record, source: artifact.Artifact.Name}
+				executables[key] = executable
+				order = append(order, key)
+			}
+			executable.pids = append(executable.pids, record.PID)
+		}
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		executable := executables[key]
+		record := executable.record
+		sort.Ints(executable.pids)
+
+		severity := "medium"
+		reason := "its signature could not be checked"
+		if record.Signed != nil {
+			reason = "is not validly signed (" + record.SignatureStatus + ")"
+			if record.LocationRisk == LocationRiskHigh {
+				severity = rule.Severity
+			}
+		}
+
+		metadata := map[string]interface{}{
+			"process_name":     record.Name,
+			"pid":              executable.pids[0],
+			"pids":             executable.pids,
+			"path":             record.Path,
+			"command_line":     record.CommandLine,
+			"user":             record.User,
+			"signature_status": record.SignatureStatus,
+			"signer":           record.Signer,
+			"location_risk":    record.LocationRisk,
+		}
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    severity,
+			Category:    rule.Category,
+			Description: fmt.Sprintf("%s runs from a %s risk location and %s", record.Path, record.LocationRisk, reason),
+			Evidence: []Evidence{
+				{
+					Type:        "executable_path",
+					Source:      executable.source,
+					Value:       record.Path,
+					Description: fmt.Sprintf("Executable of %d running process(es)", len(executable.pids)),
+					Confidence:  0.8,
+					Metadata:    metadata,
+				},
+			},
+			Tags:      rule.Tags,
+			Timestamp: time.Now(),
+			Metadata:  metadata,
+		})
+	}
+	return findings
+}
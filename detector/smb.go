@@ -0,0 +1,245 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// SMBOverview is the SMB activity of a host gathered from its artifacts, as
+// the network report shows it
+type SMBOverview struct {
+	Shares     []collector.SMBShare
+	Activity   *collector.SMBActivity
+	Drives     []collector.MappedDrive
+	UNCHistory []collector.UNCAccess
+	Errors     []string
+}
+
+// ExtractSMBOverview gathers the SMB artifacts. It returns nil when none
+// were collected.
+func ExtractSMBOverview(artifacts []collector.ArtifactResult) *SMBOverview {
+	var overview *SMBOverview
+
+	for _, artifact := range artifacts {
+		switch artifact.Artifact.Type {
+		case collector.SMBSharesType, collector.SMBActivityType, collector.MappedDrivesType, collector.UNCHistoryType:
+		default:
+			continue
+		}
+
+		if overview == nil {
+			overview = &SMBOverview{}
+		}
+		if artifact.Error != nil {
+			overview.Errors = append(overview.Errors, fmt.Sprintf("%s: %v", artifact.Artifact.Name, artifact.Error))
+			continue
+		}
+
+		data := []byte(artifactText(artifact))
+		var err error
+		switch artifact.Artifact.Type {
+		case collector.SMBSharesType:
+			err = json.Unmarshal(data, &overview.Shares)
+		case collector.SMBActivityType:
+			overview.Activity = &collector.SMBActivity{}
+			err = json.Unmarshal(data, overview.Activity)
+		case collector.MappedDrivesType:
+			err = json.Unmarshal(data, &overview.Drives)
+		case collector.UNCHistoryType:
+			err = json.Unmarshal(data, &overview.UNCHistory)
+		}
+		if err != nil {
+			overview.Errors = append(overview.Errors, fmt.Sprintf("%s: failed to parse: %v", artifact.Artifact.Name, err))
+		}
+	}
+
+	return overview
+}
+
+// isAdministrator reports whether a DOMAIN\user account is one of the
+// direct members of the local Administrators group. Members listed without
+// a domain match any domain.
+func isAdministrator(admins []string, account string) bool {
+	name := account
+	if i := strings.LastIndex(account, `\`); i >= 0 {
+		name = account[i+1:]
+	}
+	for _, admin := range admins {
+		if strings.EqualFold(admin, account) || (!strings.Contains(admin, `\`) && strings.EqualFold(admin, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateAdminShareRule flags accounts outside the local Administrators
+// group that accessed a hidden share, from the logged share accesses and
+// the files held open, one finding per account and share. Computer accounts
+// and hosts whose administrators could not be listed are skipped.
+func (d *Detector) evaluateAdminShareRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	type shareAccess struct {
+		user, share, source string
+		times, clients      []string
+	}
+	accesses := make(map[string]*shareAccess)
+	var order []string
+
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.SMBActivityType {
+			continue
+		}
+		var activity collector.SMBActivity
+		if err := json.Unmarshal([]byte(artifactText(artifact)), &activity); err != nil || len(activity.Administrators) == 0 {
+			continue
+		}
+
+		add := func(user, share, client, when string) {
+			if user == "" || strings.HasSuffix(user, "$") || !collector.HiddenShare(share) || isAdministrator(activity.Administrators, user) {
+				return
+			}
+			key := strings.ToLower(user + "|" + share)
+			entry, ok := accesses[key]
+			if !ok {
+				entry = &shareAccess{user: user, share: share, source: artifact.Artifact.Name}
+				accesses[key] = entry
+				order = append(order, key)
+			}
+			if when != "" {
+				entry.times = append(entry.times, when)
+			}
+			if client != "" && !containsString(entry.clients, client) {
+				entry.clients = append(entry.clients, client)
+			}
+		}
+		for _, record := range activity.HiddenShareAccess {
+			add(record.User, record.Share, record.Source, record.Time)
+		}
+		for _, file := range activity.OpenFiles {
+			add(file.ClientUser, file.Share, file.ClientComputer, "")
+		}
+	}
+
+	var findings []Finding
+	for _, key := range order {
+		entry := accesses[key]
+		sort.Strings(entry.times)
+		metadata := map[string]interface{}{
+			"user":    entry.user,
+			"share":   entry.share,
+			"clients": entry.clients,
+			"times":   entry.times,
+		}
+		description := fmt.Sprintf("%s, not a local administrator, accessed hidden share %s", entry.user, entry.share)
+		if len(entry.clients) > 0 {
+			description += " from " + strings.Join(entry.clients, ", ")
+		}
+		findings = append(findings, Finding{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Severity:    rule.Severity,
+			Category:    rule.Category,
+			Description: description,
+			Evidence: []Evidence{
+				{
+					Type:        "smb_access",
+					Source:      entry.source,
+					Value:       entry.share,
+					Description: fmt.Sprintf("%d logged access(es)", len(entry.times)),
+					Confidence:  0.7,
+					Metadata:    metadata,
+				},
+			},
+			Tags:      rule.Tags,
+			Timestamp: time.Now(),
+			Metadata:  metadata,
+		})
+	}
+	return findings
+}
+
+// broadAccounts are the principals that grant a share to everyone who can
+// reach the host
+var broadAccounts = []string{"everyone", "nt authority\\authenticated users", "builtin\\users", "nt authority\\anonymous logon"}
+
+// broadWriteAccess returns the broad principal a share grants write access
+// to, or ""
+func broadWriteAccess(share collector.SMBShare) string {
+	for _, access := range share.Access {
+		if !strings.EqualFold(access.Type, "Allow") || strings.EqualFold(access.Right, "Read") {
+			continue
+		}
+		for _, account := range broadAccounts {
+			if strings.EqualFold(access.Account, account) {
+				return access.Account
+			}
+		}
+	}
+	return ""
+}
+
+// evaluateNewShareRule flags shares created within the collection lookback.
+// Shares that grant write access to everyone are raised to high.
+func (d *Detector) evaluateNewShareRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.SMBSharesType {
+			continue
+		}
+		var shares []collector.SMBShare
+		if err := json.Unmarshal([]byte(artifactText(artifact)), &shares); err != nil {
+			continue
+		}
+
+		for _, share := range shares {
+			if share.CreatedAt == "" {
+				continue
+			}
+			severity := rule.Severity
+			description := fmt.Sprintf("Share %s (%s) was created at %s", share.Name, share.Path, share.CreatedAt)
+			if share.CreatedBy != "" {
+				description += " by " + share.CreatedBy
+			}
+			broad := broadWriteAccess(share)
+			if broad != "" {
+				severity = "high"
+				description += "; " + broad + " can write to it"
+			}
+
+			metadata := map[string]interface{}{
+				"share":         share.Name,
+				"path":          share.Path,
+				"created_at":    share.CreatedAt,
+				"created_by":    share.CreatedBy,
+				"hidden":        collector.HiddenShare(share.Name),
+				"broad_access":  broad,
+				"current_users": share.CurrentUsers,
+			}
+			findings = append(findings, Finding{
+				RuleID:      rule.ID,
+				RuleName:    rule.Name,
+				Severity:    severity,
+				Category:    rule.Category,
+				Description: description,
+				Evidence: []Evidence{
+					{
+						Type:        "smb_share",
+						Source:      artifact.Artifact.Name,
+						Value:       share.Name,
+						Description: "Share creation event (5142)",
+						Confidence:  0.8,
+						Metadata:    metadata,
+					},
+				},
+				Tags:      rule.Tags,
+				Timestamp: time.Now(),
+				Metadata:  metadata,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,309 @@
+package detector
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// TaskDefinition is a scheduled task as defined in its task XML
+type TaskDefinition struct {
+	Path        string       `json:"path"`
+	Author      string       `json:"author,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Registered  string       `json:"registered,omitempty"`
+	Enabled     bool         `json:"enabled"`
+	Hidden      bool         `json:"hidden"`
+	RunAs       string       `json:"run_as,omitempty"`
+	LogonType   string       `json:"logon_type,omitempty"`
+	RunLevel    string       `json:"run_level,omitempty"`
+	Triggers    []string     `json:"triggers"`
+	Actions     []TaskAction `json:"actions"`
+}
+
+// TaskAction is one action of a task. Exec actions carry a command line,
+// COM handler actions the class ID of the handler.
+type TaskAction struct {
+	Type             string `json:"type"`
+	Command          string `json:"command,omitempty"`
+	Arguments        string `json:"arguments,omitempty"`
+	WorkingDirectory string `json:"working_directory,omitempty"`
+	ClassID          string `json:"class_id,omitempty"`
+}
+
+// taskXML is the part of the Task Scheduler schema the detector reads
+type taskXML struct {
+	RegistrationInfo struct {
+		Date        string `xml:"Date"`
+		Author      string `xml:"Author"`
+		Description string `xml:"Description"`
+		URI         string `xml:"URI"`
+	} `xml:"RegistrationInfo"`
+	Triggers struct {
+		Triggers []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	} `xml:"Triggers"`
+	Principals struct {
+		Principal []struct {
+			UserID    string `xml:"UserId"`
+			GroupID   string `xml:"GroupId"`
+			LogonType string `xml:"LogonType"`
+			RunLevel  string `xml:"RunLevel"`
+		} `xml:"Principal"`
+	} `xml:"Principals"`
+	Settings struct {
+		Enabled string `xml:"Enabled"`
+		Hidden  string `xml:"Hidden"`
+	} `xml:"Settings"`
+	Actions struct {
+		Actions []struct {
+			XMLName          xml.Name
+			Command          string `xml:"Command"`
+			Arguments        string `xml:"Arguments"`
+			WorkingDirectory string `xml:"WorkingDirectory"`
+			ClassID          string `xml:"ClassId"`
+		} `xml:",any"`
+	} `xml:"Actions"`
+}
+
+// ParseScheduledTaskXML parses task definitions in the form of 'schtasks
+// /query /xml ONE': Task elements, each preceded by a comment holding its
+// task path. A single task file is accepted as well; tasks without a path
+// comment take the URI from their registration info.
+func ParseScheduledTaskXML(data string) ([]TaskDefinition, error) {
+	decoder := xml.NewDecoder(strings.NewReader(decodePolicyText(data)))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// The text is already decoded; the declaration may still say UTF-16
+		return input, nil
+	}
+
+	var tasks []TaskDefinition
+	pendingPath := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return tasks, fmt.Errorf("failed to parse scheduled task XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.Comment:
+			if comment := strings.TrimSpace(string(t)); strings.HasPrefix(comment, `\`) {
+				pendingPath = comment
+			}
+		case xml.StartElement:
+			if t.Name.Local != "Task" {
+				continue
+			}
+			var raw taskXML
+			if err := decoder.DecodeElement(&raw, &t); err != nil {
+				return tasks, fmt.Errorf("failed to parse scheduled task XML: %w", err)
+			}
+			task := raw.definition()
+			if pendingPath != "" {
+				task.Path = pendingPath
+				pendingPath = ""
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+// definition converts a decoded task element to a TaskDefinition. Tasks
+// are enabled and visible unless their settings say otherwise.
+func (raw taskXML) definition() TaskDefinition {
+	task := TaskDefinition{
+		Path:        strings.TrimSpace(raw.RegistrationInfo.URI),
+		Author:      strings.TrimSpace(raw.RegistrationInfo.Author),
+		Description: strings.TrimSpace(raw.RegistrationInfo.Description),
+		Registered:  strings.TrimSpace(raw.RegistrationInfo.Date),
+		Enabled:     !strings.EqualFold(strings.TrimSpace(raw.Settings.Enabled), "false"),
+		Hidden:      strings.EqualFold(strings.TrimSpace(raw.Settings.Hidden), "true"),
+		Triggers:    []string{},
+		Actions:     []TaskAction{},
+	}
+
+	if len(raw.Principals.Principal) > 0 {
+		principal := raw.Principals.Principal[0]
+		task.RunAs = strings.TrimSpace(principal.UserID)
+		if task.RunAs == "" {
+			task.RunAs = strings.TrimSpace(principal.GroupID)
+		}
+		task.LogonType = strings.TrimSpace(principal.LogonType)
+		task.RunLevel = strings.TrimSpace(principal.RunLevel)
+	}
+
+	for _, trigger := range raw.Triggers.Triggers {
+		task.Triggers = append(task.Triggers, trigger.XMLName.Local)
+	}
+
+	for _, action := range raw.Actions.Actions {
+		switch action.XMLName.Local {
+		case "Exec":
+			task.Actions = append(task.Actions, TaskAction{
+				Type:             "exec",
+				Command:          strings.TrimSpace(action.Command),
+				Arguments:        strings.TrimSpace(action.Arguments),
+				WorkingDirectory: strings.TrimSpace(action.WorkingDirectory),
+			})
+		case "ComHandler":
+			task.Actions = append(task.Actions, TaskAction{Type: "com_handler", ClassID: strings.TrimSpace(action.ClassID)})
+		case "SendEmail":
+			task.Actions = append(task.Actions, TaskAction{Type: "send_email"})
+		case "ShowMessage":
+			task.Actions = append(task.Actions, TaskAction{Type: "show_message"})
+		}
+	}
+
+	return task
+}
+
+// CommandLine returns the command and its arguments as one line
+func (a TaskAction) CommandLine() string {
+	return strings.TrimSpace(a.Command + " " + a.Arguments)
+}
+
+// userWritablePaths are path fragments, lower case with backslashes, of
+// locations ordinary users can write to. Environment variables are matched
+// as written in the task.
+var userWritablePaths = []string{
+	`\users\`, `\appdata\`, `\temp\`, `\tmp\`, `\programdata\`, `\perflogs\`, `\$recycle.bin\`,
+	`%temp%`, `%tmp%`, `%appdata%`, `%localappdata%`, `%userprofile%`, `%public%`, `%programdata%`,
+}
+
+// scriptHosts are the programs that run a script or library named in
+// their arguments, so their arguments are checked for writable paths too
+var scriptHosts = map[string]bool{
+	"powershell.exe": true, "pwsh.exe": true, "cmd.exe": true,
+	"wscript.exe": true, "cscript.exe": true, "mshta.exe": true,
+	"rundll32.exe": true, "regsvr32.exe": true,
+}
+
+// suspiciousTaskActions are command line patterns of abused task actions
+var suspiciousTaskActions = []struct {
+	reason  string
+	pattern *regexp.Regexp
+}{
+	{"PowerShell encoded command", regexp.MustCompile(`(?i)(powershell|pwsh)(\.exe)?\b.*\s[-/]e(c|nc\w*)?\s+[a-z0-9+/=]{16,}`)},
+	{"PowerShell hidden window", regexp.MustCompile(`(?i)(powershell|pwsh)(\.exe)?\b.*\s[-/]w\w*\s+hidden`)},
+	{"PowerShell download or in-memory execution", regexp.MustCompile(`(?i)(powershell|pwsh)(\.exe)?\b.*(\biex\b|invoke-expression|downloadstring|downloadfile|invoke-webrequest|\biwr\b|net\.webclient|frombase64string)`)},
+	{"mshta running remote or inline script", regexp.MustCompile(`(?i)mshta(\.exe)?\b.*(https?:|javascript:|vbscript:)`)},
+	{"rundll32 running script", regexp.MustCompile(`(?i)rundll32(\.exe)?\b.*(javascript:|vbscript:|https?:)`)},
+	{"regsvr32 loading a remote scriptlet", regexp.MustCompile(`(?i)regsvr32(\.exe)?\b.*(/i:\s*https?:|scrobj)`)},
+	{"certutil download or decode", regexp.MustCompile(`(?i)certutil(\.exe)?\b.*[-/](urlcache|decode)`)},
+	{"bitsadmin transfer", regexp.MustCompile(`(?i)bitsadmin(\.exe)?\b.*[-/](transfer|addfile)`)},
+}
+
+// userWritablePath returns the writable location a path lies in, if any
+func userWritablePath(value string) string {
+	lower := strings.ToLower(strings.ReplaceAll(value, "/", `\`))
+	for _, fragment := range userWritablePaths {
+		if strings.Contains(lower, fragment) {
+			return fragment
+		}
+	}
+	return ""
+}
+
+// taskActionReasons returns why an exec action looks like persistence:
+// it runs from a user-writable path or matches an abused command pattern
+func taskActionReasons(action TaskAction) []string {
+	var reasons []string
+	if location := userWritablePath(action.Command); location != "" {
+		reasons = append(reasons, fmt.Sprintf("runs from user-writable path (%s)", location))
+	}
+
+	program := strings.ToLower(path.Base(strings.ReplaceAll(strings.Trim(action.Command, `"`), `\`, "/")))
+	if scriptHosts[program] || scriptHosts[program+".exe"] {
+		if location := userWritablePath(action.Arguments); location != "" {
+			reasons = append(reasons, fmt.Sprintf("%s runs a file from user-writable path (%s)", program, location))
+		}
+	}
+
+	commandLine := action.CommandLine()
+	for _, suspicious := range suspiciousTaskActions {
+		if suspicious.pattern.MatchString(commandLine) {
+			reasons = append(reasons, suspicious.reason)
+		}
+	}
+	return reasons
+}
+
+// evaluateTaskDefinitionRule flags task actions that run from user-writable
+// paths or use abused command patterns, one finding per action. Actions
+// matching an abused pattern are high severity; those only running from a
+// writable path, as some per-user updaters do, are medium.
+func (d *Detector) evaluateTaskDefinitionRule(rule Rule, artifacts []collector.ArtifactResult) []Finding {
+	var findings []Finding
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.ScheduledTaskXMLType {
+			continue
+		}
+		tasks, _ := ParseScheduledTaskXML(artifactText(artifact))
+
+		for _, task := range tasks {
+			for _, action := range task.Actions {
+				if action.Type != "exec" {
+					continue
+				}
+				reasons := taskActionReasons(action)
+				if len(reasons) == 0 {
+					continue
+				}
+
+				severity := "medium"
+				for _, reason := range reasons {
+					if !strings.Contains(reason, "user-writable") {
+						severity = rule.Severity
+						break
+					}
+				}
+
+				metadata := map[string]interface{}{
+					"task_path": task.Path,
+					"command":   action.Command,
+					"arguments": action.Arguments,
+					"run_as":    task.RunAs,
+					"run_level": task.RunLevel,
+					"triggers":  task.Triggers,
+					"enabled":   task.Enabled,
+					"hidden":    task.Hidden,
+					"author":    task.Author,
+					"reasons":   reasons,
+				}
+				findings = append(findings, Finding{
+					RuleID:      rule.ID,
+					RuleName:    rule.Name,
+					Severity:    severity,
+					Category:    rule.Category,
+					Description: fmt.Sprintf("Scheduled task %s: %s", task.Path, strings.Join(reasons, "; ")),
+					Evidence: []Evidence{
+						{
+							Type:        "scheduled_task_action",
+							Source:      artifact.Artifact.Name,
+							Value:       action.CommandLine(),
+							Description: fmt.Sprintf("Exec action of task %s", task.Path),
+							Confidence:  0.8,
+							Metadata:    metadata,
+						},
+					},
+					Tags:      rule.Tags,
+					Timestamp: time.Now(),
+					Metadata:  metadata,
+				})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,445 @@
+// Package archive unpacks ZIP and tar archives supplied by users, such as
+// triage bundles, rule packs and exported incidents, without letting an
+// entry write outside the destination directory, and writes the
+// password-protected ZIP archives quarantined files are kept in
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Limits bound what an archive may unpack to, so a small archive cannot
+// fill the disk (zip bomb)
+type Limits struct {
+	MaxEntrySize int64 // largest single file
+	MaxTotalSize int64 // all files together
+	MaxEntries   int   // number of entries
+}
+
+// DefaultLimits allow bundles with large memory or log artifacts
+var DefaultLimits = Limits{
+	MaxEntrySize: 4 << 30,
+	MaxTotalSize: 16 << 30,
+	MaxEntries:   100000,
+}
+
+// safeFileMode is the widest permission an extracted file keeps: no
+// setuid, setgid or sticky bits and no write access for group or others
+const safeFileMode = 0755
+
+// ErrUnsafeEntry marks archive entries that were refused because they would
+// escape the destination or exceed the limits
+var ErrUnsafeEntry = errors.New("unsafe archive entry")
+
+// Result lists what an extraction wrote
+type Result struct {
+	Files   []string // extracted files, relative to the destination
+	Skipped []string // entries that were left out, with the reason
+	Bytes   int64    // bytes written
+}
+
+// IsArchive reports whether a file name has an extension Extract handles
+func IsArchive(name string) bool {
+	return archiveKind(name) != ""
+}
+
+// archiveKind names the format of an archive by its extension
+func archiveKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// Extract unpacks a .zip, .tar, .tar.gz or .tgz archive into dest. It stops
+// at the first entry that is absolute, escapes dest once cleaned, is a
+// symlink pointing outside dest or exceeds the limits; entries already
+// written stay inside dest.
+func Extract(archivePath, dest string, limits Limits) (*Result, error) {
+	switch archiveKind(archivePath) {
+	case "zip":
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer reader.Close()
+		return ExtractZip(&reader.Reader, dest, limits)
+	case "tar", "tgz":
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+
+		var stream io.Reader = file
+		if archiveKind(archivePath) == "tgz" {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open archive: %w", err)
+			}
+			defer gz.Close()
+			stream = gz
+		}
+		return ExtractTar(stream, dest, limits)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s (expected .zip, .tar, .tar.gz or .tgz)", filepath.Base(archivePath))
+	}
+}
+
+// ExtractZip unpacks a ZIP archive into dest
+func ExtractZip(reader *zip.Reader, dest string, limits Limits) (*Result, error) {
+	x, err := newExtractor(dest, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range reader.File {
+		mode := file.Mode()
+		entry := entry{name: file.Name, mode: mode, size: int64(file.UncompressedSize64)}
+		switch {
+		case mode.IsDir():
+			entry.kind = tar.TypeDir
+		case mode&fs.ModeSymlink != 0:
+			entry.kind = tar.TypeSymlink
+			target, err := readZipLink(file)
+			if err != nil {
+				return x.result, err
+			}
+			entry.link = target
+		case mode.IsRegular():
+			entry.kind = tar.TypeReg
+		default:
+			x.skip(file.Name, "not a regular file")
+			continue
+		}
+
+		err := x.extract(entry, func() (io.ReadCloser, error) { return file.Open() })
+		if err != nil {
+			return x.result, err
+		}
+	}
+	return x.result, nil
+}
+
+// ExtractTar unpacks a tar stream into dest
+func ExtractTar(stream io.Reader, dest string, limits Limits) (*Result, error) {
+	x, err := newExtractor(dest, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return x.result, nil
+		}
+		if err != nil {
+			return x.result, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		entry := entry{name: header.Name, mode: header.FileInfo().Mode(), size: header.Size, link: header.Linkname}
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeSymlink:
+			entry.kind = header.Typeflag
+		case tar.TypeReg, tar.TypeRegA:
+			entry.kind = tar.TypeReg
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			// Hard links, devices and FIFOs have no place in a bundle
+			x.skip(header.Name, "not a regular file, directory or symlink")
+			continue
+		}
+
+		err = x.extract(entry, func() (io.ReadCloser, error) { return io.NopCloser(reader), nil })
+		if err != nil {
+			return x.result, err
+		}
+	}
+}
+
+// entry is an archive member in a format-neutral form
+type entry struct {
+	name string
+	kind byte // tar.TypeReg, tar.TypeDir or tar.TypeSymlink
+	mode fs.FileMode
+	size int64
+	link string
+}
+
+// extractor writes entries below a destination directory
+type extractor struct {
+	dest    string // destination with symlinks resolved
+	limits  Limits
+	entries int
+	result  *Result
+}
+
+func newExtractor(dest string, limits Limits) (*extractor, error) {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve extraction directory: %w", err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve extraction directory: %w", err)
+	}
+	return &extractor{dest: resolved, limits: limits, result: &Result{}}, nil
+}
+
+func (x *extractor) skip(name, reason string) {
+	x.result.Skipped = append(x.result.Skipped, fmt.Sprintf("%s: %s", name, reason))
+}
+
+// extract writes one entry after checking its path, type and size
+func (x *extractor) extract(e entry, open func() (io.ReadCloser, error)) error {
+	x.entries++
+	if x.limits.MaxEntries > 0 && x.entries > x.limits.MaxEntries {
+		return fmt.Errorf("%w: archive has more than %d entries", ErrUnsafeEntry, x.limits.MaxEntries)
+	}
+
+	rel, err := cleanEntryName(e.name)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+
+	if e.kind == tar.TypeDir {
+		_, err := x.mkdirInside(rel, e.name)
+		return err
+	}
+
+	parent, err := x.mkdirInside(path.Dir(rel), e.name)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(parent, path.Base(rel))
+
+	// Never write through an existing symlink
+	if info, err := os.Lstat(target); err == nil && (info.Mode()&fs.ModeSymlink != 0 || info.IsDir()) {
+		return fmt.Errorf("%w: %s would replace an existing symlink or directory", ErrUnsafeEntry, e.name)
+	}
+
+	if e.kind == tar.TypeSymlink {
+		return x.symlink(e, target, rel)
+	}
+
+	if x.limits.MaxEntrySize > 0 && e.size > x.limits.MaxEntrySize {
+		return fmt.Errorf("%w: %s is %d bytes, more than the %d byte limit", ErrUnsafeEntry, e.name, e.size, x.limits.MaxEntrySize)
+	}
+	return x.writeFile(e, open, target, rel)
+}
+
+// cleanEntryName returns the slash-separated path of an entry relative to the
+// destination, refusing absolute paths and paths that climb out of it
+func cleanEntryName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" || hasDriveLetter(slashed) {
+		return "", fmt.Errorf("%w: %s is an absolute path", ErrUnsafeEntry, name)
+	}
+	cleaned := path.Clean(slashed)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %s escapes the extraction directory", ErrUnsafeEntry, name)
+	}
+	return cleaned, nil
+}
+
+// hasDriveLetter reports whether a path starts with a Windows drive such as
+// C: even when extracting on another platform
+func hasDriveLetter(p string) bool {
+	return len(p) >= 2 && p[1] == ':' && ((p[0] >= 'a' && p[0] <= 'z') || (p[0] >= 'A' && p[0] <= 'Z'))
+}
+
+// maxLinkHops bounds the symlinks followed while resolving one path
+const maxLinkHops = 40
+
+// within reports whether p is dest or below it
+func (x *extractor) within(p string) bool {
+	rel, err := filepath.Rel(x.dest, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// resolve returns the path a slash-separated path relative to the
+// destination leads to, following the symlinks written by earlier entries
+// one component at a time. It fails as soon as a step leaves the
+// destination, so nothing is created or opened outside it. A ".." after a
+// component that does not exist yet is refused, as a later entry could make
+// that component a symlink.
+func (x *extractor) resolve(rel, name string) (string, error) {
+	escape := fmt.Errorf("%w: %s leads outside the extraction directory", ErrUnsafeEntry, name)
+	current := x.dest
+	pending := strings.Split(rel, "/")
+	missing, hops := false, 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if missing {
+				return "", fmt.Errorf("%w: %s climbs out of a directory that does not exist yet", ErrUnsafeEntry, name)
+			}
+			current = filepath.Dir(current)
+			if !x.within(current) {
+				return "", escape
+			}
+			continue
+		}
+
+		next := filepath.Join(current, part)
+		if missing {
+			current = next
+			continue
+		}
+		info, err := os.Lstat(next)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			missing = true
+			current = next
+		case err != nil:
+			return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+		case info.Mode()&fs.ModeSymlink != 0:
+			if hops++; hops > maxLinkHops {
+				return "", fmt.Errorf("%w: %s follows too many symlinks", ErrUnsafeEntry, name)
+			}
+			link, err := os.Readlink(next)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+			}
+			if filepath.IsAbs(link) {
+				return "", escape
+			}
+			// The link's own components are resolved from its directory
+			pending = append(strings.Split(filepath.ToSlash(link), "/"), pending...)
+		default:
+			current = next
+		}
+		if !x.within(current) {
+			return "", escape
+		}
+	}
+	return current, nil
+}
+
+// mkdirInside creates the directory a path relative to the destination
+// leads to, after checking that it stays inside the destination
+func (x *extractor) mkdirInside(rel, name string) (string, error) {
+	dir, err := x.resolve(rel, name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	return dir, nil
+}
+
+// symlink creates a symlink whose target, with the symlinks already
+// extracted resolved, stays inside the destination. Symlinks that cannot be
+// created, as on Windows without privileges, are skipped.
+func (x *extractor) symlink(e entry, target, rel string) error {
+	link := strings.ReplaceAll(e.link, `\`, "/")
+	if link == "" || strings.HasPrefix(link, "/") || filepath.IsAbs(e.link) || hasDriveLetter(link) {
+		return fmt.Errorf("%w: symlink %s points to absolute path %s", ErrUnsafeEntry, e.name, e.link)
+	}
+	if _, err := x.resolve(path.Dir(rel)+"/"+link, e.name); err != nil {
+		return fmt.Errorf("%w: symlink %s points outside the extraction directory (%s)", ErrUnsafeEntry, e.name, e.link)
+	}
+	if err := os.Symlink(filepath.FromSlash(link), target); err != nil {
+		x.skip(e.name, fmt.Sprintf("symlink not created: %v", err))
+		return nil
+	}
+	x.result.Files = append(x.result.Files, rel)
+	return nil
+}
+
+// writeFile copies an entry's content, stopping at the per-entry and total
+// size limits whatever size the archive declares
+func (x *extractor) writeFile(e entry, open func() (io.ReadCloser, error), target, rel string) error {
+	reader, err := open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", e.name, err)
+	}
+	defer reader.Close()
+
+	perm := e.mode.Perm() & safeFileMode
+	if perm&0400 == 0 {
+		perm |= 0644
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", rel, err)
+	}
+
+	limit := x.limits.MaxEntrySize
+	if x.limits.MaxTotalSize > 0 {
+		if remaining := x.limits.MaxTotalSize - x.result.Bytes; limit <= 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+
+	var written int64
+	if limit > 0 {
+		written, err = io.Copy(file, io.LimitReader(reader, limit+1))
+	} else {
+		written, err = io.Copy(file, reader)
+	}
+	closeErr := file.Close()
+	if err == nil && limit > 0 && written > limit {
+		err = fmt.Errorf("%w: %s exceeds the size limit", ErrUnsafeEntry, e.name)
+	}
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		if errors.Is(err, ErrUnsafeEntry) {
+			return err
+		}
+		return fmt.Errorf("failed to extract %s: %w", e.name, err)
+	}
+
+	// The mode is set again in case the umask narrowed it
+	os.Chmod(target, perm)
+	x.result.Bytes += written
+	x.result.Files = append(x.result.Files, rel)
+	return nil
+}
+
+// readZipLink reads the target of a ZIP symlink entry, stored as its content
+func readZipLink(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read symlink %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, 4096))
+	if err != nil {
+		return "", fmt.Errorf("failed to read symlink %s: %w", file.Name, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,249 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testEntry is a member of a crafted test archive
+type testEntry struct {
+	name string
+	body string
+	link string // symlink target
+	mode fs.FileMode
+}
+
+// maliciousArchives are crafted archives Extract must refuse. Each also
+// holds a harmless file first, so a refused archive still shows that
+// nothing was written outside the destination.
+var maliciousArchives = []struct {
+	name    string
+	entries []testEntry
+}{
+	{"traversal.zip", []testEntry{{name: "../escaped.txt", body: "x"}}},
+	{"nested-traversal.tar.gz", []testEntry{{name: "artifacts/../../escaped.txt", body: "x"}}},
+	{"backslash-traversal.zip", []testEntry{{name: `artifacts\..\..\escaped.txt`, body: "x"}}},
+	{"absolute.tar", []testEntry{{name: "/tmp/escaped.txt", body: "x"}}},
+	{"drive-letter.zip", []testEntry{{name: `C:\Windows\escaped.txt`, body: "x"}}},
+	{"symlink-absolute.tar", []testEntry{{name: "link", link: "/etc", mode: fs.ModeSymlink}}},
+	{"symlink-outside.tar.gz", []testEntry{
+		{name: "link", link: "../outside", mode: fs.ModeSymlink},
+		{name: "link/escaped.txt", body: "x"},
+	}},
+	{"symlink-chain.zip", []testEntry{
+		{name: "inner", link: ".", mode: fs.ModeSymlink},
+		{name: "inner/up", link: "..", mode: fs.ModeSymlink},
+	}},
+	// Each link stays inside on its own; l only escapes through d/up
+	{"symlink-chained-links.tar", []testEntry{
+		{name: "d/", mode: fs.ModeDir},
+		{name: "d/up", link: "..", mode: fs.ModeSymlink},
+		{name: "l", link: "d/up/..", mode: fs.ModeSymlink},
+		{name: "l/escaped/f", body: "x"},
+	}},
+	// x/b does not exist when a is checked and becomes a link afterwards
+	{"symlink-dangling-climb.tar", []testEntry{
+		{name: "a", link: "x/b/..", mode: fs.ModeSymlink},
+		{name: "x/b", link: "..", mode: fs.ModeSymlink},
+	}},
+	{"oversized.zip", []testEntry{{name: "bomb.bin", body: strings.Repeat("0", 64<<10)}}},
+	{"oversized.tar", []testEntry{{name: "bomb.bin", body: strings.Repeat("0", 64<<10)}}},
+	{"total-size.zip", []testEntry{
+		{name: "part1.bin", body: strings.Repeat("0", 30<<10)},
+		{name: "part2.bin", body: strings.Repeat("0", 30<<10)},
+	}},
+}
+
+// testLimits keep the crafted zip bombs small
+var testLimits = Limits{MaxEntrySize: 32 << 10, MaxTotalSize: 48 << 10, MaxEntries: 100}
+
+func TestExtractRefusesUnsafeArchives(t *testing.T) {
+	for _, crafted := range maliciousArchives {
+		t.Run(crafted.name, func(t *testing.T) {
+			base := t.TempDir()
+			path := filepath.Join(base, crafted.name)
+			writeTestArchive(t, path, append([]testEntry{{name: "notes/readme.txt", body: "benign"}}, crafted.entries...))
+
+			// The destination is nested so escapes land in base, where they
+			// can be found
+			dest := filepath.Join(base, "dest", "out")
+			_, err := Extract(path, dest, testLimits)
+			if !errors.Is(err, ErrUnsafeEntry) {
+				t.Fatalf("not refused (error: %v)", err)
+			}
+			checkNoEscape(t, base, dest)
+		})
+	}
+}
+
+func TestExtractDoesNotCreateDirectoriesThroughExistingLinks(t *testing.T) {
+	base := t.TempDir()
+	dest := filepath.Join(base, "dest", "out")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", ".."), filepath.Join(dest, "pre")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	path := filepath.Join(base, "through-link.tar")
+	writeTestArchive(t, path, []testEntry{{name: "pre/escaped/f", body: "x"}})
+	if _, err := Extract(path, dest, testLimits); !errors.Is(err, ErrUnsafeEntry) {
+		t.Fatalf("not refused (error: %v)", err)
+	}
+	if _, err := os.Lstat(filepath.Join(base, "escaped")); err == nil {
+		t.Fatal("directory created outside the extraction directory before the entry was refused")
+	}
+}
+
+func TestExtractKeepsLinksInside(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "links.tar.gz")
+	writeTestArchive(t, path, []testEntry{
+		{name: "logs/app.log", body: "line"},
+		{name: "current", link: "logs", mode: fs.ModeSymlink},
+		{name: "logs/self", link: "../logs", mode: fs.ModeSymlink},
+		{name: "current/new.log", body: "x"},
+	})
+
+	dest := filepath.Join(base, "out")
+	result, err := Extract(path, dest, DefaultLimits)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(result.Skipped) > 0 {
+		t.Skipf("symlinks not supported: %v", result.Skipped)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "logs", "new.log")); err != nil || string(data) != "x" {
+		t.Fatalf("file written through an inside link not found: %v", err)
+	}
+}
+
+func TestExtractNarrowsFileModes(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "modes.tar")
+	writeTestArchive(t, path, []testEntry{
+		{name: "bin/tool", body: "#!/bin/sh\n", mode: fs.ModeSetuid | 0777},
+		{name: "data.txt", body: "x", mode: 0666},
+	})
+
+	dest := filepath.Join(base, "modes")
+	if _, err := Extract(path, dest, testLimits); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	for _, name := range []string{"bin/tool", "data.txt"} {
+		info, err := os.Stat(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("%s not extracted: %v", name, err)
+		}
+		if info.Mode()&(fs.ModeSetuid|0022) != 0 {
+			t.Errorf("%s kept unsafe mode %s", name, info.Mode())
+		}
+	}
+}
+
+// checkNoEscape fails when a refused archive left a file, directory or
+// symlink target in base, or lost the harmless entry extracted before the
+// refused one
+func checkNoEscape(t *testing.T, base, dest string) {
+	t.Helper()
+	for _, name := range []string{"escaped.txt", "escaped", "outside", filepath.Join("dest", "escaped")} {
+		if _, err := os.Lstat(filepath.Join(base, name)); err == nil {
+			t.Errorf("%s was written outside the extraction directory", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "notes", "readme.txt")); err != nil {
+		t.Errorf("benign entry before the refused one is missing: %v", err)
+	}
+}
+
+// writeTestArchive writes entries as a ZIP, tar or gzipped tar archive,
+// chosen by the file extension
+func writeTestArchive(t *testing.T, path string, entries []testEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		err = writeTestZip(&buf, entries)
+	case strings.HasSuffix(path, ".tar.gz"):
+		gz := gzip.NewWriter(&buf)
+		if err = writeTestTar(gz, entries); err == nil {
+			err = gz.Close()
+		}
+	default:
+		err = writeTestTar(&buf, entries)
+	}
+	if err != nil {
+		t.Fatalf("failed to build %s: %v", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestZip(buf *bytes.Buffer, entries []testEntry) error {
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		switch {
+		case e.mode&fs.ModeSymlink != 0:
+			header.SetMode(fs.ModeSymlink | 0777)
+			body = e.link
+		case e.mode.IsDir():
+			header.SetMode(fs.ModeDir | 0755)
+		default:
+			header.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeTestTar(w io.Writer, entries []testEntry) error {
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.mode.Perm() != 0 {
+			header.Mode = int64(e.mode.Perm())
+		}
+		if e.mode&fs.ModeSetuid != 0 {
+			header.Mode |= 04000
+		}
+		switch {
+		case e.mode&fs.ModeSymlink != 0:
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.link
+			header.Size = 0
+		case e.mode.IsDir():
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
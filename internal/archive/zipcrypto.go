@@ -0,0 +1,151 @@
+package archive
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// ErrWrongPassword is returned when an encrypted entry is opened with a
+// password other than the one it was written with
+var ErrWrongPassword = errors.New("wrong password")
+
+// zipCryptoHeaderSize is the size of the header that starts the data of an
+// entry encrypted with the traditional PKWARE cipher
+const zipCryptoHeaderSize = 12
+
+// zipCryptoFlag marks an encrypted entry in its general purpose flags
+const zipCryptoFlag = 0x1
+
+// zipCrypto is the traditional PKWARE (ZipCrypto) stream cipher. It is weak
+// and only meant to keep archived malware from being scanned, opened or run
+// by accident; any unzip tool opens it with the password.
+type zipCrypto struct {
+	keys [3]uint32
+}
+
+func newZipCrypto(password string) *zipCrypto {
+	z := &zipCrypto{keys: [3]uint32{0x12345678, 0x23456789, 0x34567890}}
+	for i := 0; i < len(password); i++ {
+		z.update(password[i])
+	}
+	return z
+}
+
+func (z *zipCrypto) update(b byte) {
+	z.keys[0] = crc32.IEEETable[byte(z.keys[0])^b] ^ (z.keys[0] >> 8)
+	z.keys[1] = (z.keys[1]+z.keys[0]&0xff)*134775813 + 1
+	z.keys[2] = crc32.IEEETable[byte(z.keys[2])^byte(z.keys[1]>>24)] ^ (z.keys[2] >> 8)
+}
+
+func (z *zipCrypto) stream() byte {
+	t := z.keys[2] | 2
+	return byte((t * (t ^ 1)) >> 8)
+}
+
+func (z *zipCrypto) encrypt(p []byte) {
+	for i, b := range p {
+		p[i] = b ^ z.stream()
+		z.update(b)
+	}
+}
+
+func (z *zipCrypto) decrypt(p []byte) {
+	for i := range p {
+		p[i] ^= z.stream()
+		z.update(p[i])
+	}
+}
+
+// cipherWriter encrypts what is written through it
+type cipherWriter struct {
+	w   io.Writer
+	z   *zipCrypto
+	buf []byte
+}
+
+func (cw *cipherWriter) Write(p []byte) (int, error) {
+	cw.buf = append(cw.buf[:0], p...)
+	cw.z.encrypt(cw.buf)
+	return cw.w.Write(cw.buf)
+}
+
+// CreateEncrypted adds a stored entry encrypted with password to zw and
+// returns the writer its content goes to. Exactly size bytes whose CRC-32 is
+// crc must be written: the header records both before the content, so the
+// archive needs no data descriptor and unzips with any tool.
+func CreateEncrypted(zw *zip.Writer, name string, modified time.Time, size int64, crc uint32, password string) (io.Writer, error) {
+	header := &zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		Modified:           modified,
+		Flags:              zipCryptoFlag,
+		CRC32:              crc,
+		CompressedSize64:   uint64(size) + zipCryptoHeaderSize,
+		UncompressedSize64: uint64(size),
+	}
+	header.SetMode(0644)
+	w, err := zw.CreateRaw(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	z := newZipCrypto(password)
+	prefix := make([]byte, zipCryptoHeaderSize)
+	if _, err := rand.Read(prefix[:zipCryptoHeaderSize-1]); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption header: %w", err)
+	}
+	// The last header byte lets readers check the password
+	prefix[zipCryptoHeaderSize-1] = byte(crc >> 24)
+	z.encrypt(prefix)
+	if _, err := w.Write(prefix); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return &cipherWriter{w: w, z: z}, nil
+}
+
+// OpenEncrypted opens a stored entry written by CreateEncrypted, returning
+// ErrWrongPassword when the password does not match. The content read is
+// checked against the entry's CRC-32 when the end is reached.
+func OpenEncrypted(f *zip.File, password string) (io.Reader, error) {
+	if f.Flags&zipCryptoFlag == 0 || f.Method != zip.Store {
+		return nil, fmt.Errorf("%s is not a stored encrypted entry", f.Name)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	z := newZipCrypto(password)
+	prefix := make([]byte, zipCryptoHeaderSize)
+	if _, err := io.ReadFull(raw, prefix); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	z.decrypt(prefix)
+	if prefix[zipCryptoHeaderSize-1] != byte(f.CRC32>>24) {
+		return nil, ErrWrongPassword
+	}
+	return &cipherReader{r: raw, z: z, crc: crc32.NewIEEE(), want: f.CRC32}, nil
+}
+
+// cipherReader decrypts an entry and checks its CRC-32 at the end
+type cipherReader struct {
+	r    io.Reader
+	z    *zipCrypto
+	crc  hash.Hash32
+	want uint32
+}
+
+func (cr *cipherReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.z.decrypt(p[:n])
+	cr.crc.Write(p[:n])
+	if err == io.EOF && cr.crc.Sum32() != cr.want {
+		return n, fmt.Errorf("checksum mismatch: %w", zip.ErrChecksum)
+	}
+	return n, err
+}
@@ -0,0 +1,336 @@
+// Package audit keeps the operation log: one structured line per
+// consequential action (collections, bundles, exports, incident changes),
+// each chained to the one before it by hash so that edits, deletions and
+// reordering are detected when the log is read
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the audit log in the reports metadata directory
+const FileName = "audit.log"
+
+// Outcomes of an audited action
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Actions recorded in the audit log
+const (
+	CollectionStarted  = "collection.started"
+	CollectionFinished = "collection.finished"
+	CollectionConfirm  = "collection.confirmation"
+	BundleCreated      = "bundle.created"
+	BundleSigned       = "bundle.signed"
+	EvidenceExported   = "evidence.exported"
+	EvidenceQuarantine = "evidence.quarantined"
+	RedactionApplied   = "redaction.applied"
+	IncidentOpened     = "incident.opened"
+	IncidentClosed     = "incident.closed"
+	IncidentReopened   = "incident.reopened"
+	IncidentExported   = "incident.exported"
+	SuppressionAdded   = "suppression.added"
+	BaselineSet        = "baseline.set"
+	BaselineCleared    = "baseline.cleared"
+	CredentialsRead    = "credentials.content_read"
+	BinaryUntrusted    = "binary.untrusted"
+	ScopeSet           = "scope.set"
+	ScopeCleared       = "scope.cleared"
+	ScopeOverridden    = "scope.overridden"
+	TuningSet          = "tuning.set"
+	TuningCleared      = "tuning.cleared"
+	RulesUpdated       = "rules.updated"
+)
+
+// Record is one line of the audit log. Hash covers every other field,
+// including PrevHash, the hash of the record before it.
+type Record struct {
+	Seq        int       `json:"seq"`
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor"`
+	IncidentID string    `json:"incident_id,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	ParamsHash string    `json:"params_hash,omitempty"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash,omitempty"`
+}
+
+// computeHash returns the chain hash of a record
+func (r Record) computeHash() string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(append([]byte(r.PrevHash), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends records to an audit log file
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Open returns the audit log in dir, creating the directory if needed
+func Open(dir string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	return &Log{Path: filepath.Join(dir, FileName)}, nil
+}
+
+// Append writes a record for an action, chained to the last record in the
+// file. The file is only ever opened for appending.
+func (l *Log) Append(action, incidentID, target string, params interface{}, err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	last, readErr := lastRecord(l.Path)
+	if readErr != nil {
+		return readErr
+	}
+
+	record := Record{
+		Seq:        last.Seq + 1,
+		Time:       time.Now().UTC(),
+		Action:     action,
+		Actor:      Actor(),
+		IncidentID: incidentID,
+		Target:     target,
+		ParamsHash: ParamsHash(params),
+		Outcome:    Success,
+		PrevHash:   last.Hash,
+	}
+	if err != nil {
+		record.Outcome = Failure
+		record.Error = err.Error()
+	}
+	record.Hash = record.computeHash()
+
+	line, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", marshalErr)
+	}
+
+	file, openErr := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if openErr != nil {
+		return fmt.Errorf("failed to open audit log: %w", openErr)
+	}
+	defer file.Close()
+	if _, writeErr := file.Write(append(line, '\n')); writeErr != nil {
+		return fmt.Errorf("failed to write audit log: %w", writeErr)
+	}
+	return file.Sync()
+}
+
+// tailSize is how much of the end of the log is read to find the last record
+const tailSize = 64 << 10
+
+// lastRecord returns the final record of the log, or a zero record when the
+// log is empty or missing
+func lastRecord(path string) (Record, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Record{}, nil
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	offset := info.Size() - tailSize
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return Record{}, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	lastLine := lines[len(lines)-1]
+	if len(bytes.TrimSpace(lastLine)) == 0 {
+		return Record{}, nil
+	}
+	var record Record
+	if err := json.Unmarshal(lastLine, &record); err != nil {
+		return Record{}, fmt.Errorf("failed to read audit log: last record is damaged: %w", err)
+	}
+	return record, nil
+}
+
+// Break is a place where the hash chain of the log does not hold
+type Break struct {
+	Line   int    `json:"line"`
+	Seq    int    `json:"seq,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Trail is the content of an audit log and the chain breaks found in it
+type Trail struct {
+	Path    string   `json:"path"`
+	Records []Record `json:"records"`
+	Breaks  []Break  `json:"breaks,omitempty"`
+}
+
+// Intact reports whether the whole chain verified
+func (t *Trail) Intact() bool {
+	return len(t.Breaks) == 0
+}
+
+// Read loads an audit log and verifies its hash chain. A missing log is an
+// empty trail.
+func Read(path string) (*Trail, error) {
+	trail := &Trail{Path: path}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return trail, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	prev := Record{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			trail.Breaks = append(trail.Breaks, Break{Line: line, Reason: "line is not a valid audit record"})
+			continue
+		}
+		switch {
+		case record.Hash != record.computeHash():
+			trail.Breaks = append(trail.Breaks, Break{Line: line, Seq: record.Seq, Reason: "record was modified (hash mismatch)"})
+		case record.PrevHash != prev.Hash:
+			trail.Breaks = append(trail.Breaks, Break{Line: line, Seq: record.Seq, Reason: "chain broken: previous record is missing or was altered"})
+		case record.Seq != prev.Seq+1:
+			trail.Breaks = append(trail.Breaks, Break{Line: line, Seq: record.Seq, Reason: fmt.Sprintf("sequence jumps from %d to %d", prev.Seq, record.Seq)})
+		}
+		trail.Records = append(trail.Records, record)
+		prev = record
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return trail, nil
+}
+
+// Filter returns the records of an incident, or of every incident when
+// incidentID is empty, made at or after since
+func (t *Trail) Filter(incidentID string, since time.Time) []Record {
+	var records []Record
+	for _, record := range t.Records {
+		if incidentID != "" && !strings.EqualFold(record.IncidentID, incidentID) {
+			continue
+		}
+		if !since.IsZero() && record.Time.Before(since) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ParseSince turns a --since value into a time: a duration back from now
+// such as 24h or 7d, or a date (2006-01-02) or RFC 3339 timestamp
+func ParseSince(value string) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q: use a duration like 24h or 7d, or a date like 2006-01-02", value)
+}
+
+// ParamsHash returns the SHA-256 of the JSON form of an action's
+// parameters, so records can be matched to a command line without storing
+// its values. It is empty when there are none.
+func ParamsHash(params interface{}) string {
+	if params == nil {
+		return ""
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		data = []byte(fmt.Sprint(params))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Actor returns the account the tool runs as
+func Actor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Log
+)
+
+// SetCurrent installs l as the process-wide audit log. With none installed,
+// Record does nothing.
+func SetCurrent(l *Log) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = l
+}
+
+// Current returns the process-wide audit log, or nil
+func Current() *Log {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
+
+// Append records an action in the process-wide audit log. err is the
+// action's result: nil records a success.
+func Append(action, incidentID, target string, params interface{}, err error) error {
+	l := Current()
+	if l == nil {
+		return nil
+	}
+	return l.Append(action, incidentID, target, params, err)
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testLog appends a chain of records for one incident and a failed export
+// to a new audit log and returns it
+func testLog(t *testing.T) *Log {
+	t.Helper()
+	log, err := Open(filepath.Join(t.TempDir(), "audit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := []struct {
+		action, incident string
+		err              error
+	}{
+		{IncidentOpened, "INC-TEST", nil},
+		{CollectionStarted, "INC-TEST", nil},
+		{CollectionFinished, "INC-TEST", nil},
+		{EvidenceExported, "", errors.New("disk full")},
+		{IncidentClosed, "INC-TEST", nil},
+	}
+	for _, a := range actions {
+		if err := log.Append(a.action, a.incident, "test", map[string]string{"action": a.action}, a.err); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return log
+}
+
+func TestAuditLogVerifies(t *testing.T) {
+	log := testLog(t)
+	trail, err := Read(log.Path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !trail.Intact() || len(trail.Records) != 5 {
+		t.Fatalf("fresh audit log has %d records and %d breaks, want 5 and none", len(trail.Records), len(trail.Breaks))
+	}
+	if n := len(trail.Filter("INC-TEST", time.Time{})); n != 4 {
+		t.Errorf("incident filter matched %d records, want 4", n)
+	}
+	if trail.Records[3].Outcome != Failure {
+		t.Errorf("failed action recorded as %s", trail.Records[3].Outcome)
+	}
+}
+
+func TestAuditLogDetectsTampering(t *testing.T) {
+	log := testLog(t)
+	original, err := os.ReadFile(log.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(original, []byte("\n"))
+	tampered := map[string][]byte{
+		"edited record":   bytes.Replace(original, []byte(`"outcome":"failure"`), []byte(`"outcome":"success"`), 1),
+		"removed record":  bytes.Join(append(append([][]byte{}, lines[:1]...), lines[2:]...), nil),
+		"swapped records": bytes.Join(append([][]byte{lines[1], lines[0]}, lines[2:]...), nil),
+	}
+	for name, data := range tampered {
+		if err := os.WriteFile(log.Path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		trail, err := Read(log.Path)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if trail.Intact() {
+			t.Errorf("audit log with a %s still verifies", name)
+		}
+	}
+}
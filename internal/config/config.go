@@ -3,10 +3,14 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/redtriage/redtriage/internal/naming"
 	"github.com/spf13/viper"
 )
 
@@ -22,8 +26,17 @@ type Config struct {
 	
 	// Collection settings
 	DetectionTimeout string `mapstructure:"detection_timeout"`
+	// DetectionWorkers is how many rules 'findings' evaluates at once (0: one per CPU)
+	DetectionWorkers int `mapstructure:"detection_workers"`
 	MinSeverity     string `mapstructure:"min_severity"`
 	CompressionLevel int    `mapstructure:"compression_level"`
+	// SensitiveHosts are hostname globs or role:<role> entries; collecting
+	// from a matching host requires typing its hostname to confirm
+	SensitiveHosts []string `mapstructure:"sensitive_hosts"`
+	// FileCollection bounds the directory walks of file metadata collectors
+	FileCollection FileCollectionConfig `mapstructure:"file_collection"`
+	// Quarantine bounds the files 'quarantine' preserves and protects their archives
+	Quarantine QuarantineConfig `mapstructure:"quarantine"`
 	
 	// Security settings
 	ChecksumAlgorithm string `mapstructure:"checksum_algorithm"`
@@ -33,6 +46,15 @@ type Config struct {
 	// Artifact settings
 	Artifacts map[string]ArtifactConfig `mapstructure:"artifacts"`
 	
+	// Plugin settings
+	Plugins PluginsConfig `mapstructure:"plugins"`
+	
+	// Findings output settings
+	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
+	
+	// Alerting settings
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	
 	// Platform-specific settings
 	Platform string `mapstructure:"platform"`
 	
@@ -40,19 +62,43 @@ type Config struct {
 	DefaultOutputDir string `mapstructure:"default_output_dir"`
 	ReportsDir       string `mapstructure:"reports_dir"`
 	ReportFormats    []string `mapstructure:"report_formats"`
+	// FilenameTemplates name reports, bundles and exports (empty: built-in names)
+	FilenameTemplates FilenameTemplatesConfig `mapstructure:"filename_templates"`
 	
 	// Rule settings
 	SigmaRulesPath string `mapstructure:"sigma_rules_path"`
 	CustomRulesPath string `mapstructure:"custom_rules_path"`
+	// RulesUpdateURL is the rule pack 'rules --update' syncs from
+	RulesUpdateURL string `mapstructure:"rules_update_url"`
 	
 	// Session settings
 	SaveHistory     bool   `mapstructure:"save_history"`
 	HistoryFile     string `mapstructure:"history_file"`
 	SessionLogPath  string `mapstructure:"session_log_path"`
+	AutosaveInterval string `mapstructure:"autosave_interval"`
+	PromptTemplate   string `mapstructure:"prompt_template"`
+	CaptureTranscripts bool   `mapstructure:"capture_transcripts"`
+	TranscriptMaxSize  string `mapstructure:"transcript_max_size"`
+	StatusLine         string `mapstructure:"status_line"` // full, minimal or off
+	
+	// Incident metrics settings
+	SLABasis      string   `mapstructure:"sla_basis"`      // calendar or business
+	BusinessHours string   `mapstructure:"business_hours"` // e.g. 09:00-17:00
+	BusinessDays  []string `mapstructure:"business_days"`  // e.g. mon, tue, wed, thu, fri
+	
+	// Incident history settings
+	SnapshotInterval string `mapstructure:"snapshot_interval"` // least time between two incident snapshots
+	
+	// Incident encryption settings
+	IncidentEncryption string `mapstructure:"incident_encryption"` // off, passphrase or keystore, for new incidents
 	
 	// Color settings
 	ColorEnabled bool   `mapstructure:"color_enabled"`
 	ColorMode    string `mapstructure:"color_mode"`
+	
+	// Where each value came from, by key, and the variables that set them
+	sources  map[string]string
+	envNames map[string]string
 }
 
 // ArtifactConfig represents configuration for a specific artifact type
@@ -62,6 +108,76 @@ type ArtifactConfig struct {
 	MaxSize string `mapstructure:"max_size"`
 }
 
+// FileCollectionConfig represents how deep and where file metadata
+// collectors walk. Directory patterns are globs matched against a
+// directory's name or its path below the walked directory.
+type FileCollectionConfig struct {
+	MaxDepth      int      `mapstructure:"max_depth"`      // Directory levels listed (0: each artifact's default)
+	AllowDirs     []string `mapstructure:"allow_dirs"`     // Only these directories are entered (empty: each artifact's default)
+	DenyDirs      []string `mapstructure:"deny_dirs"`      // Never entered, on top of each artifact's own
+	IncludeHidden bool     `mapstructure:"include_hidden"` // Also walk hidden (dot) files and directories
+}
+
+// QuarantineConfig represents the size limits and password of the ZIP
+// archives 'quarantine' copies suspicious files into
+type QuarantineConfig struct {
+	MaxFileSize  string `mapstructure:"max_file_size"`  // Largest file quarantined
+	MaxTotalSize string `mapstructure:"max_total_size"` // All quarantined files of an incident together
+	Password     string `mapstructure:"password"`       // Archive password (default: infected, as malware repositories use)
+}
+
+// FilenameTemplatesConfig represents the Go text/template file names of
+// generated output. Templates can use {{.Incident}}, {{.Host}},
+// {{.Collection}}, {{.Date}}, {{.Time}}, {{.Type}} and {{.Format}}.
+type FilenameTemplatesConfig struct {
+	Report string `mapstructure:"report"` // Each report in a bundle or analysis
+	Bundle string `mapstructure:"bundle"` // The bundle ZIP; its directory drops .zip
+	Export string `mapstructure:"export"` // Each findings export file
+}
+
+// PluginsConfig represents configuration for external tools RedTriage invokes
+type PluginsConfig struct {
+	// Packet capture settings used by collect --network-capture
+	CaptureTool      string `mapstructure:"capture_tool"`      // tcpdump, dumpcap or auto
+	TcpdumpPath      string `mapstructure:"tcpdump_path"`      // Path to tcpdump (default: looked up in PATH)
+	DumpcapPath      string `mapstructure:"dumpcap_path"`      // Path to dumpcap (default: looked up in PATH)
+	CaptureInterface string `mapstructure:"capture_interface"` // Interface to capture on (default: primary interface)
+	CaptureFilter    string `mapstructure:"capture_filter"`    // BPF capture filter
+}
+
+// ElasticsearchConfig represents the Elasticsearch or OpenSearch cluster
+// findings --elasticsearch indexes into. Credentials are best supplied
+// through the REDTRIAGE_ES_* environment variables.
+type ElasticsearchConfig struct {
+	URL      string `mapstructure:"url"`      // Used when --elasticsearch is given without a URL
+	Index    string `mapstructure:"index"`    // Index name (default: redtriage)
+	Username string `mapstructure:"username"` // Basic authentication user
+	Password string `mapstructure:"password"` // Basic authentication password
+	APIKey   string `mapstructure:"api_key"`  // Base64 API key, used instead of basic authentication
+}
+
+// NotificationsConfig represents the sinks findings alerts are posted to
+// when findings meet the --notify-on severity. Webhook URLs and the SMTP
+// password are best supplied through the REDTRIAGE_* environment variables.
+type NotificationsConfig struct {
+	NotifyOn     string     `mapstructure:"notify_on"`     // Threshold used when --notify-on is not given (empty: off)
+	SlackWebhook string     `mapstructure:"slack_webhook"` // Slack incoming webhook URL
+	TeamsWebhook string     `mapstructure:"teams_webhook"` // Microsoft Teams incoming webhook URL
+	SMTP         SMTPConfig `mapstructure:"smtp"`
+	MaxPerRun    int        `mapstructure:"max_per_run"` // Alerts sent per findings run (default: 10)
+	Cooldown     string     `mapstructure:"cooldown"`    // Quiet period before the same rule alerts again for a host
+}
+
+// SMTPConfig represents the mail server email alerts are sent through
+type SMTPConfig struct {
+	Host     string   `mapstructure:"host"`
+	Port     int      `mapstructure:"port"` // default: 587
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
 // LoadConfig loads configuration from file or creates default if not found
 func LoadConfig(configPath string) (*Config, error) {
 	// For now, just return default config
@@ -81,6 +197,15 @@ func DefaultConfig() *Config {
 		DetectionTimeout: "5m",
 		MinSeverity:      "medium",
 		CompressionLevel: 6,
+		SensitiveHosts:   []string{"role:domain-controller"},
+		FileCollection: FileCollectionConfig{
+			DenyDirs: []string{"Downloads", "node_modules"},
+		},
+		Quarantine: QuarantineConfig{
+			MaxFileSize:  "256MB",
+			MaxTotalSize: "2GB",
+			Password:     "infected",
+		},
 		ChecksumAlgorithm: "sha256",
 		RedactionEnabled:  true,
 		AllowNetwork:      false,
@@ -91,8 +216,28 @@ func DefaultConfig() *Config {
 		SaveHistory:       true,
 		HistoryFile:       ".redtriage_history",
 		SessionLogPath:    "./logs",
+		AutosaveInterval:  "30s",
+		CaptureTranscripts: true,
+		TranscriptMaxSize:  "1MB",
+		StatusLine:         "full",
+		SLABasis:          "calendar",
+		BusinessHours:     "09:00-17:00",
+		BusinessDays:      []string{"mon", "tue", "wed", "thu", "fri"},
+		SnapshotInterval:  "15m",
+		IncidentEncryption: "off",
 		ColorEnabled:      true,
 		ColorMode:         "auto",
+		Plugins: PluginsConfig{
+			CaptureTool: "auto",
+		},
+		Elasticsearch: ElasticsearchConfig{
+			Index: "redtriage",
+		},
+		Notifications: NotificationsConfig{
+			MaxPerRun: 10,
+			Cooldown:  "1h",
+			SMTP:      SMTPConfig{Port: 587},
+		},
 		Artifacts: map[string]ArtifactConfig{
 			"processes": {
 				Enabled: true,
@@ -120,14 +265,20 @@ func DefaultConfig() *Config {
 
 // Load loads configuration from file and environment
 func Load() (*Config, error) {
-	config := DefaultConfig()
-	
-	// Set config file path
-	viper.SetConfigName("redtriage")
-	viper.SetConfigType("yml")
-	
-	// Search paths in order of preference
-	searchPaths := []string{
+	return load(true)
+}
+
+// LoadReadOnly loads configuration like Load but never creates a default
+// config file or output directories, for use when nothing may be written
+// to the target system
+func LoadReadOnly() (*Config, error) {
+	return load(false)
+}
+
+// searchPaths returns the directories searched for redtriage.yml, in order
+// of preference
+func searchPaths() []string {
+	paths := []string{
 		".", // Current directory
 	}
 	
@@ -135,43 +286,55 @@ func Load() (*Config, error) {
 	if runtime.GOOS == "windows" {
 		programData := os.Getenv("PROGRAMDATA")
 		if programData != "" {
-			searchPaths = append(searchPaths, filepath.Join(programData, "RedTriage"))
+			paths = append(paths, filepath.Join(programData, "RedTriage"))
 		}
 		// Add Windows user profile paths
 		if userProfile := os.Getenv("USERPROFILE"); userProfile != "" {
-			searchPaths = append(searchPaths, userProfile)
+			paths = append(paths, userProfile)
 		}
 	} else {
-		searchPaths = append(searchPaths, "/etc/redtriage")
+		paths = append(paths, "/etc/redtriage")
 		// Add Unix user home directory
 		if home, err := os.UserHomeDir(); err == nil {
-			searchPaths = append(searchPaths, home)
+			paths = append(paths, home)
 		}
 	}
 	
 	// Add user home directory (cross-platform)
 	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, home)
+		paths = append(paths, home)
+	}
+	return paths
+}
+
+// FindFile returns the first redtriage.yml or redtriage.yaml found in the
+// config search paths, or "" when there is none
+func FindFile() string {
+	for _, dir := range searchPaths() {
+		for _, name := range []string{"redtriage.yml", "redtriage.yaml"} {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
 	}
+	return ""
+}
+
+func load(allowWrites bool) (*Config, error) {
+	config := DefaultConfig()
+	
+	// Set config file path
+	viper.SetConfigName("redtriage")
+	viper.SetConfigType("yml")
 	
 	// Add search paths
-	for _, path := range searchPaths {
+	for _, path := range searchPaths() {
 		viper.AddConfigPath(path)
 	}
 	
-	// Environment variable prefix
-	viper.SetEnvPrefix("REDTRIAGE")
-	viper.AutomaticEnv()
-	
-	// Bind environment variables
-	viper.BindEnv("log_level", "REDTRIAGE_LOG_LEVEL")
-	viper.BindEnv("log_format", "REDTRIAGE_LOG_FORMAT")
-	viper.BindEnv("default_timeout", "REDTRIAGE_DEFAULT_TIMEOUT")
-	viper.BindEnv("allow_network", "REDTRIAGE_ALLOW_NETWORK")
-	viper.BindEnv("color_enabled", "REDTRIAGE_COLOR_ENABLED")
-	viper.BindEnv("platform", "REDTRIAGE_PLATFORM")
-	viper.BindEnv("output_dir", "REDTRIAGE_OUTPUT_DIR")
-	viper.BindEnv("reports_dir", "REDTRIAGE_REPORTS_DIR")
+	// REDTRIAGE_* environment variables are applied after the file is
+	// unmarshaled, so the precedence is environment, file, defaults
 	
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -181,9 +344,11 @@ func Load() (*Config, error) {
 		}
 		// Config file not found is not an error, use defaults
 		// Try to create a default config file in the current directory
-		if err := config.Save("redtriage.yml"); err != nil {
-			// Log warning but don't fail
-			fmt.Printf("Warning: Could not create default config file: %v\n", err)
+		if allowWrites {
+			if err := config.Save("redtriage.yml"); err != nil {
+				// Log warning but don't fail
+				fmt.Printf("Warning: Could not create default config file: %v\n", err)
+			}
 		}
 	}
 	
@@ -192,14 +357,21 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 	
+	// Apply environment overrides
+	if err := config.applyEnv(inConfigFile); err != nil {
+		return nil, fmt.Errorf("invalid environment override: %w", err)
+	}
+	
 	// Validate config
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 	
 	// Ensure output directories exist
-	if err := config.ensureDirectories(); err != nil {
-		return nil, fmt.Errorf("failed to create output directories: %w", err)
+	if allowWrites {
+		if err := config.ensureDirectories(); err != nil {
+			return nil, fmt.Errorf("failed to create output directories: %w", err)
+		}
 	}
 	
 	return config, nil
@@ -215,8 +387,21 @@ func (c *Config) Save(path string) error {
 	viper.Set("max_log_size", c.MaxLogSize)
 	viper.Set("max_log_age", c.MaxLogAge)
 	viper.Set("detection_timeout", c.DetectionTimeout)
+	viper.Set("detection_workers", c.DetectionWorkers)
 	viper.Set("min_severity", c.MinSeverity)
 	viper.Set("compression_level", c.CompressionLevel)
+	viper.Set("sensitive_hosts", c.SensitiveHosts)
+	viper.Set("file_collection", map[string]interface{}{
+		"max_depth":      c.FileCollection.MaxDepth,
+		"allow_dirs":     c.FileCollection.AllowDirs,
+		"deny_dirs":      c.FileCollection.DenyDirs,
+		"include_hidden": c.FileCollection.IncludeHidden,
+	})
+	viper.Set("quarantine", map[string]interface{}{
+		"max_file_size":  c.Quarantine.MaxFileSize,
+		"max_total_size": c.Quarantine.MaxTotalSize,
+		"password":       c.Quarantine.Password,
+	})
 	viper.Set("checksum_algorithm", c.ChecksumAlgorithm)
 	viper.Set("redaction_enabled", c.RedactionEnabled)
 	viper.Set("allow_network", c.AllowNetwork)
@@ -224,14 +409,59 @@ func (c *Config) Save(path string) error {
 	viper.Set("default_output_dir", c.DefaultOutputDir)
 	viper.Set("reports_dir", c.ReportsDir)
 	viper.Set("report_formats", c.ReportFormats)
+	viper.Set("filename_templates", map[string]interface{}{
+		"report": c.FilenameTemplates.Report,
+		"bundle": c.FilenameTemplates.Bundle,
+		"export": c.FilenameTemplates.Export,
+	})
 	viper.Set("sigma_rules_path", c.SigmaRulesPath)
 	viper.Set("custom_rules_path", c.CustomRulesPath)
+	viper.Set("rules_update_url", c.RulesUpdateURL)
 	viper.Set("save_history", c.SaveHistory)
 	viper.Set("history_file", c.HistoryFile)
 	viper.Set("session_log_path", c.SessionLogPath)
+	viper.Set("autosave_interval", c.AutosaveInterval)
+	viper.Set("prompt_template", c.PromptTemplate)
+	viper.Set("capture_transcripts", c.CaptureTranscripts)
+	viper.Set("transcript_max_size", c.TranscriptMaxSize)
+	viper.Set("status_line", c.StatusLine)
+	viper.Set("sla_basis", c.SLABasis)
+	viper.Set("business_hours", c.BusinessHours)
+	viper.Set("business_days", c.BusinessDays)
+	viper.Set("snapshot_interval", c.SnapshotInterval)
+	viper.Set("incident_encryption", c.IncidentEncryption)
 	viper.Set("color_enabled", c.ColorEnabled)
 	viper.Set("color_mode", c.ColorMode)
 	viper.Set("artifacts", c.Artifacts)
+	viper.Set("plugins", map[string]interface{}{
+		"capture_tool":      c.Plugins.CaptureTool,
+		"tcpdump_path":      c.Plugins.TcpdumpPath,
+		"dumpcap_path":      c.Plugins.DumpcapPath,
+		"capture_interface": c.Plugins.CaptureInterface,
+		"capture_filter":    c.Plugins.CaptureFilter,
+	})
+	viper.Set("elasticsearch", map[string]interface{}{
+		"url":      c.Elasticsearch.URL,
+		"index":    c.Elasticsearch.Index,
+		"username": c.Elasticsearch.Username,
+		"password": c.Elasticsearch.Password,
+		"api_key":  c.Elasticsearch.APIKey,
+	})
+	viper.Set("notifications", map[string]interface{}{
+		"notify_on":     c.Notifications.NotifyOn,
+		"slack_webhook": c.Notifications.SlackWebhook,
+		"teams_webhook": c.Notifications.TeamsWebhook,
+		"max_per_run":   c.Notifications.MaxPerRun,
+		"cooldown":      c.Notifications.Cooldown,
+		"smtp": map[string]interface{}{
+			"host":     c.Notifications.SMTP.Host,
+			"port":     c.Notifications.SMTP.Port,
+			"username": c.Notifications.SMTP.Username,
+			"password": c.Notifications.SMTP.Password,
+			"from":     c.Notifications.SMTP.From,
+			"to":       c.Notifications.SMTP.To,
+		},
+	})
 	
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -268,7 +498,94 @@ func (c *Config) Validate() error {
 	if _, err := time.ParseDuration(c.DetectionTimeout); err != nil {
 		return fmt.Errorf("invalid detection timeout: %s", c.DetectionTimeout)
 	}
+	if _, err := time.ParseDuration(c.AutosaveInterval); err != nil {
+		return fmt.Errorf("invalid autosave interval: %s", c.AutosaveInterval)
+	}
+	if interval, err := time.ParseDuration(c.SnapshotInterval); c.SnapshotInterval != "" && (err != nil || interval < 0) {
+		return fmt.Errorf("invalid snapshot interval: %s", c.SnapshotInterval)
+	}
 	
+	// Validate prompt template
+	if err := ValidatePromptTemplate(c.PromptTemplate); err != nil {
+		return fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	// Validate transcript size limit
+	if c.TranscriptMaxSize != "" {
+		if _, err := ParseSize(c.TranscriptMaxSize); err != nil {
+			return fmt.Errorf("invalid transcript max size: %s", c.TranscriptMaxSize)
+		}
+	}
+
+	// Validate sensitive host patterns
+	for _, pattern := range c.SensitiveHosts {
+		if strings.HasPrefix(pattern, "role:") {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid sensitive host pattern: %s", pattern)
+		}
+	}
+
+	// Validate file collection scope; patterns stay below the walked directory
+	if c.FileCollection.MaxDepth < 0 {
+		return fmt.Errorf("invalid file collection max depth: %d", c.FileCollection.MaxDepth)
+	}
+	for _, pattern := range append(append([]string{}, c.FileCollection.AllowDirs...), c.FileCollection.DenyDirs...) {
+		p := strings.ReplaceAll(pattern, `\`, "/")
+		if p == "" || strings.HasPrefix(p, "/") || filepath.IsAbs(pattern) || strings.Contains("/"+p+"/", "/../") {
+			return fmt.Errorf("invalid file collection directory: %q (must be relative and stay below the walked directory)", pattern)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid file collection directory pattern: %s", pattern)
+		}
+	}
+
+	// Validate quarantine limits
+	for _, size := range []string{c.Quarantine.MaxFileSize, c.Quarantine.MaxTotalSize} {
+		if _, err := ParseSize(size); err != nil {
+			return fmt.Errorf("invalid quarantine size limit: %s", size)
+		}
+	}
+	if c.Quarantine.Password == "" {
+		return fmt.Errorf("quarantine password must not be empty")
+	}
+
+	// Validate filename templates
+	for _, template := range []string{c.FilenameTemplates.Report, c.FilenameTemplates.Bundle, c.FilenameTemplates.Export} {
+		if template == "" {
+			continue
+		}
+		if err := naming.Validate(template); err != nil {
+			return err
+		}
+	}
+
+	// Validate status line mode
+	switch c.StatusLine {
+	case "", "full", "minimal", "off":
+	default:
+		return fmt.Errorf("invalid status line: %s (must be full, minimal or off)", c.StatusLine)
+	}
+
+	// Validate incident encryption
+	switch c.IncidentEncryption {
+	case "", "off", "passphrase", "keystore":
+	default:
+		return fmt.Errorf("invalid incident encryption: %s (must be off, passphrase or keystore)", c.IncidentEncryption)
+	}
+
+	// Validate incident metrics basis
+	if c.SLABasis != "" && c.SLABasis != "calendar" && c.SLABasis != "business" {
+		return fmt.Errorf("invalid SLA basis: %s (must be calendar or business)", c.SLABasis)
+	}
+	if _, _, err := ParseBusinessHours(c.BusinessHours); err != nil {
+		return fmt.Errorf("invalid business hours: %w", err)
+	}
+	if _, err := ParseBusinessDays(c.BusinessDays); err != nil {
+		return fmt.Errorf("invalid business days: %w", err)
+	}
+
 	// Validate severity
 	validSeverities := map[string]bool{
 		"low": true, "medium": true, "high": true, "critical": true,
@@ -277,6 +594,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid minimum severity: %s", c.MinSeverity)
 	}
 	
+	if c.DetectionWorkers < 0 {
+		return fmt.Errorf("invalid detection workers: %d (must be 0 or more)", c.DetectionWorkers)
+	}
+	
 	// Validate compression level
 	if c.CompressionLevel < 0 || c.CompressionLevel > 9 {
 		return fmt.Errorf("invalid compression level: %d (must be 0-9)", c.CompressionLevel)
@@ -290,6 +611,36 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid checksum algorithm: %s", c.ChecksumAlgorithm)
 	}
 	
+	// Validate capture tool
+	validCaptureTools := map[string]bool{
+		"": true, "auto": true, "tcpdump": true, "dumpcap": true,
+	}
+	if !validCaptureTools[c.Plugins.CaptureTool] {
+		return fmt.Errorf("invalid capture tool: %s (must be tcpdump, dumpcap or auto)", c.Plugins.CaptureTool)
+	}
+	
+	// Validate Elasticsearch output
+	if es := c.Elasticsearch.URL; es != "" && !strings.HasPrefix(es, "http://") && !strings.HasPrefix(es, "https://") {
+		return fmt.Errorf("invalid elasticsearch url: %s (must start with http:// or https://)", es)
+	}
+	
+	// Validate notifications
+	switch c.Notifications.NotifyOn {
+	case "", "low", "medium", "high", "critical":
+	default:
+		return fmt.Errorf("invalid notifications notify_on: %s (must be low, medium, high or critical)", c.Notifications.NotifyOn)
+	}
+	for _, hook := range []string{c.Notifications.SlackWebhook, c.Notifications.TeamsWebhook} {
+		if hook != "" && !strings.HasPrefix(hook, "http://") && !strings.HasPrefix(hook, "https://") {
+			return fmt.Errorf("invalid notifications webhook: %s (must start with http:// or https://)", hook)
+		}
+	}
+	if cooldown := c.Notifications.Cooldown; cooldown != "" {
+		if _, err := time.ParseDuration(cooldown); err != nil {
+			return fmt.Errorf("invalid notifications cooldown: %s", cooldown)
+		}
+	}
+	
 	// Validate platform
 	validPlatforms := map[string]bool{
 		"windows": true, "linux": true, "darwin": true,
@@ -301,6 +652,45 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// PromptPlaceholders lists the variables that can be used in prompt_template
+var PromptPlaceholders = []string{
+	"brand", "incident_id", "incident_title", "open_findings", "tool", "host", "user", "status", "time",
+}
+
+// ValidatePromptTemplate checks that every {placeholder} in the template is
+// known and that braces are balanced. An empty template selects the built-in prompt.
+func ValidatePromptTemplate(template string) error {
+	rest := template
+	for {
+		open := strings.Index(rest, "{")
+		close := strings.Index(rest, "}")
+		if open == -1 && close == -1 {
+			return nil
+		}
+		if open == -1 || close < open {
+			return fmt.Errorf("unbalanced braces in %q", template)
+		}
+
+		name := rest[open+1 : close]
+		if strings.Contains(name, "{") {
+			return fmt.Errorf("unbalanced braces in %q", template)
+		}
+
+		known := false
+		for _, placeholder := range PromptPlaceholders {
+			if name == placeholder {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder {%s} (valid: %s)", name, strings.Join(PromptPlaceholders, ", "))
+		}
+
+		rest = rest[close+1:]
+	}
+}
+
 // GetTimeout returns the timeout as a duration
 func (c *Config) GetTimeout() time.Duration {
 	duration, err := time.ParseDuration(c.DefaultTimeout)
@@ -321,6 +711,154 @@ func (c *Config) GetDetectionTimeout() time.Duration {
 	return duration
 }
 
+// GetDetectionWorkers returns how many rules are evaluated at once, one
+// per CPU unless configured
+func (c *Config) GetDetectionWorkers() int {
+	if c.DetectionWorkers > 0 {
+		return c.DetectionWorkers
+	}
+	return runtime.NumCPU()
+}
+
+// GetAutosaveInterval returns the session auto-save debounce interval
+func (c *Config) GetAutosaveInterval() time.Duration {
+	duration, err := time.ParseDuration(c.AutosaveInterval)
+	if err != nil || duration <= 0 {
+		// Return default if parsing fails
+		return 30 * time.Second
+	}
+	return duration
+}
+
+// GetSnapshotInterval returns the least time between two incident
+// snapshots; 0 snapshots every saved change
+func (c *Config) GetSnapshotInterval() time.Duration {
+	duration, err := time.ParseDuration(c.SnapshotInterval)
+	if err != nil || duration < 0 {
+		// Return default if parsing fails
+		return 15 * time.Minute
+	}
+	return duration
+}
+
+// GetNotifyCooldown returns the quiet period before the same rule alerts
+// again for a host
+func (c *Config) GetNotifyCooldown() time.Duration {
+	duration, err := time.ParseDuration(c.Notifications.Cooldown)
+	if err != nil || duration < 0 {
+		// Return default if parsing fails
+		return time.Hour
+	}
+	return duration
+}
+
+// ParseBusinessHours parses a business hours range such as "09:00-17:00" into
+// offsets from midnight. An empty value means the whole day.
+func ParseBusinessHours(hours string) (time.Duration, time.Duration, error) {
+	if hours == "" {
+		return 0, 24 * time.Hour, nil
+	}
+
+	parts := strings.Split(hours, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%q must use the form HH:MM-HH:MM", hours)
+	}
+
+	var offsets [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("%q must use the form HH:MM-HH:MM", hours)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	if offsets[1] <= offsets[0] {
+		return 0, 0, fmt.Errorf("%q ends before it starts", hours)
+	}
+
+	return offsets[0], offsets[1], nil
+}
+
+// ParseBusinessDays parses weekday names (mon, tuesday, ...) into a set.
+// An empty list means Monday to Friday.
+func ParseBusinessDays(days []string) (map[time.Weekday]bool, error) {
+	if len(days) == 0 {
+		days = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	for _, day := range days {
+		name := strings.ToLower(strings.TrimSpace(day))
+		found := false
+		for wd := time.Sunday; wd <= time.Saturday; wd++ {
+			full := strings.ToLower(wd.String())
+			if name == full || name == full[:3] {
+				weekdays[wd] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown day %q", day)
+		}
+	}
+
+	return weekdays, nil
+}
+
+// ParseSize parses a size such as "100MB", "512KB" or "2GB" into bytes.
+// A bare number is taken as bytes.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
+
+// GetMaxArtifactSize returns the per-artifact size budget in bytes
+func (c *Config) GetMaxArtifactSize() int64 {
+	size, err := ParseSize(c.MaxArtifactSize)
+	if err != nil {
+		// Return default if parsing fails
+		return 100 << 20
+	}
+	return size
+}
+
+// GetQuarantineLimits returns the largest file 'quarantine' accepts and the
+// most all quarantined files of an incident may take, in bytes
+func (c *Config) GetQuarantineLimits() (int64, int64) {
+	maxFile, err := ParseSize(c.Quarantine.MaxFileSize)
+	if err != nil {
+		maxFile = 256 << 20
+	}
+	maxTotal, err := ParseSize(c.Quarantine.MaxTotalSize)
+	if err != nil {
+		maxTotal = 2 << 30
+	}
+	return maxFile, maxTotal
+}
+
 // IsArtifactEnabled checks if a specific artifact type is enabled
 func (c *Config) IsArtifactEnabled(artifactType string) bool {
 	if artifact, exists := c.Artifacts[artifactType]; exists {
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Sources of a configuration value, in increasing precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// EnvPrefix starts the name of every environment variable that overrides a
+// configuration value
+const EnvPrefix = "REDTRIAGE_"
+
+// setting is a configuration value that can be overridden from the
+// environment. env lists the variables that set it, the first one set
+// winning; field points at the value in a Config.
+type setting struct {
+	key    string
+	env    []string
+	kind   string // string, duration, size, int, bool or list
+	secret bool
+	field  func(c *Config) interface{}
+}
+
+// settings lists the overridable configuration values in the order of the
+// configuration file. Variables are named after the key (REDTRIAGE_ plus the
+// key in upper case, dots as underscores) unless an older name exists.
+var settings = []setting{
+	{key: "log_level", kind: "string", field: func(c *Config) interface{} { return &c.LogLevel }},
+	{key: "log_format", kind: "string", field: func(c *Config) interface{} { return &c.LogFormat }},
+	{key: "default_timeout", env: []string{"REDTRIAGE_DEFAULT_TIMEOUT", "REDTRIAGE_TIMEOUT"}, kind: "duration", field: func(c *Config) interface{} { return &c.DefaultTimeout }},
+	{key: "max_artifact_size", kind: "size", field: func(c *Config) interface{} { return &c.MaxArtifactSize }},
+	{key: "max_log_size", kind: "size", field: func(c *Config) interface{} { return &c.MaxLogSize }},
+	{key: "max_log_age", kind: "duration", field: func(c *Config) interface{} { return &c.MaxLogAge }},
+	{key: "detection_timeout", kind: "duration", field: func(c *Config) interface{} { return &c.DetectionTimeout }},
+	{key: "detection_workers", kind: "int", field: func(c *Config) interface{} { return &c.DetectionWorkers }},
+	{key: "min_severity", kind: "string", field: func(c *Config) interface{} { return &c.MinSeverity }},
+	{key: "compression_level", kind: "int", field: func(c *Config) interface{} { return &c.CompressionLevel }},
+	{key: "sensitive_hosts", kind: "list", field: func(c *Config) interface{} { return &c.SensitiveHosts }},
+	{key: "file_collection.max_depth", kind: "int", field: func(c *Config) interface{} { return &c.FileCollection.MaxDepth }},
+	{key: "file_collection.allow_dirs", kind: "list", field: func(c *Config) interface{} { return &c.FileCollection.AllowDirs }},
+	{key: "file_collection.deny_dirs", kind: "list", field: func(c *Config) interface{} { return &c.FileCollection.DenyDirs }},
+	{key: "file_collection.include_hidden", kind: "bool", field: func(c *Config) interface{} { return &c.FileCollection.IncludeHidden }},
+	{key: "quarantine.max_file_size", kind: "size", field: func(c *Config) interface{} { return &c.Quarantine.MaxFileSize }},
+	{key: "quarantine.max_total_size", kind: "size", field: func(c *Config) interface{} { return &c.Quarantine.MaxTotalSize }},
+	{key: "quarantine.password", kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Quarantine.Password }},
+	{key: "checksum_algorithm", kind: "string", field: func(c *Config) interface{} { return &c.ChecksumAlgorithm }},
+	{key: "redaction_enabled", kind: "bool", field: func(c *Config) interface{} { return &c.RedactionEnabled }},
+	{key: "allow_network", kind: "bool", field: func(c *Config) interface{} { return &c.AllowNetwork }},
+	{key: "plugins.capture_tool", kind: "string", field: func(c *Config) interface{} { return &c.Plugins.CaptureTool }},
+	{key: "plugins.tcpdump_path", kind: "string", field: func(c *Config) interface{} { return &c.Plugins.TcpdumpPath }},
+	{key: "plugins.dumpcap_path", kind: "string", field: func(c *Config) interface{} { return &c.Plugins.DumpcapPath }},
+	{key: "plugins.capture_interface", kind: "string", field: func(c *Config) interface{} { return &c.Plugins.CaptureInterface }},
+	{key: "plugins.capture_filter", kind: "string", field: func(c *Config) interface{} { return &c.Plugins.CaptureFilter }},
+	{key: "elasticsearch.url", env: []string{"REDTRIAGE_ES_URL"}, kind: "string", field: func(c *Config) interface{} { return &c.Elasticsearch.URL }},
+	{key: "elasticsearch.index", env: []string{"REDTRIAGE_ES_INDEX"}, kind: "string", field: func(c *Config) interface{} { return &c.Elasticsearch.Index }},
+	{key: "elasticsearch.username", env: []string{"REDTRIAGE_ES_USERNAME"}, kind: "string", field: func(c *Config) interface{} { return &c.Elasticsearch.Username }},
+	{key: "elasticsearch.password", env: []string{"REDTRIAGE_ES_PASSWORD"}, kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Elasticsearch.Password }},
+	{key: "elasticsearch.api_key", env: []string{"REDTRIAGE_ES_API_KEY"}, kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Elasticsearch.APIKey }},
+	{key: "notifications.notify_on", kind: "string", field: func(c *Config) interface{} { return &c.Notifications.NotifyOn }},
+	{key: "notifications.slack_webhook", env: []string{"REDTRIAGE_SLACK_WEBHOOK"}, kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Notifications.SlackWebhook }},
+	{key: "notifications.teams_webhook", env: []string{"REDTRIAGE_TEAMS_WEBHOOK"}, kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Notifications.TeamsWebhook }},
+	{key: "notifications.max_per_run", kind: "int", field: func(c *Config) interface{} { return &c.Notifications.MaxPerRun }},
+	{key: "notifications.cooldown", kind: "duration", field: func(c *Config) interface{} { return &c.Notifications.Cooldown }},
+	{key: "notifications.smtp.host", env: []string{"REDTRIAGE_SMTP_HOST"}, kind: "string", field: func(c *Config) interface{} { return &c.Notifications.SMTP.Host }},
+	{key: "notifications.smtp.port", env: []string{"REDTRIAGE_SMTP_PORT"}, kind: "int", field: func(c *Config) interface{} { return &c.Notifications.SMTP.Port }},
+	{key: "notifications.smtp.username", env: []string{"REDTRIAGE_SMTP_USERNAME"}, kind: "string", field: func(c *Config) interface{} { return &c.Notifications.SMTP.Username }},
+	{key: "notifications.smtp.password", env: []string{"REDTRIAGE_SMTP_PASSWORD"}, kind: "string", secret: true, field: func(c *Config) interface{} { return &c.Notifications.SMTP.Password }},
+	{key: "notifications.smtp.from", env: []string{"REDTRIAGE_SMTP_FROM"}, kind: "string", field: func(c *Config) interface{} { return &c.Notifications.SMTP.From }},
+	{key: "notifications.smtp.to", env: []string{"REDTRIAGE_SMTP_TO"}, kind: "list", field: func(c *Config) interface{} { return &c.Notifications.SMTP.To }},
+	{key: "platform", kind: "string", field: func(c *Config) interface{} { return &c.Platform }},
+	{key: "default_output_dir", env: []string{"REDTRIAGE_DEFAULT_OUTPUT_DIR", "REDTRIAGE_OUTPUT_DIR"}, kind: "string", field: func(c *Config) interface{} { return &c.DefaultOutputDir }},
+	{key: "reports_dir", kind: "string", field: func(c *Config) interface{} { return &c.ReportsDir }},
+	{key: "report_formats", kind: "list", field: func(c *Config) interface{} { return &c.ReportFormats }},
+	{key: "filename_templates.report", kind: "string", field: func(c *Config) interface{} { return &c.FilenameTemplates.Report }},
+	{key: "filename_templates.bundle", kind: "string", field: func(c *Config) interface{} { return &c.FilenameTemplates.Bundle }},
+	{key: "filename_templates.export", kind: "string", field: func(c *Config) interface{} { return &c.FilenameTemplates.Export }},
+	{key: "sigma_rules_path", kind: "string", field: func(c *Config) interface{} { return &c.SigmaRulesPath }},
+	{key: "custom_rules_path", kind: "string", field: func(c *Config) interface{} { return &c.CustomRulesPath }},
+	{key: "rules_update_url", env: []string{"REDTRIAGE_RULES_UPDATE_URL"}, kind: "string", field: func(c *Config) interface{} { return &c.RulesUpdateURL }},
+	{key: "save_history", kind: "bool", field: func(c *Config) interface{} { return &c.SaveHistory }},
+	{key: "history_file", kind: "string", field: func(c *Config) interface{} { return &c.HistoryFile }},
+	{key: "session_log_path", kind: "string", field: func(c *Config) interface{} { return &c.SessionLogPath }},
+	{key: "autosave_interval", kind: "duration", field: func(c *Config) interface{} { return &c.AutosaveInterval }},
+	{key: "prompt_template", kind: "string", field: func(c *Config) interface{} { return &c.PromptTemplate }},
+	{key: "capture_transcripts", kind: "bool", field: func(c *Config) interface{} { return &c.CaptureTranscripts }},
+	{key: "transcript_max_size", kind: "size", field: func(c *Config) interface{} { return &c.TranscriptMaxSize }},
+	{key: "status_line", kind: "string", field: func(c *Config) interface{} { return &c.StatusLine }},
+	{key: "sla_basis", kind: "string", field: func(c *Config) interface{} { return &c.SLABasis }},
+	{key: "business_hours", kind: "string", field: func(c *Config) interface{} { return &c.BusinessHours }},
+	{key: "business_days", kind: "list", field: func(c *Config) interface{} { return &c.BusinessDays }},
+	{key: "snapshot_interval", kind: "duration", field: func(c *Config) interface{} { return &c.SnapshotInterval }},
+	{key: "incident_encryption", kind: "string", field: func(c *Config) interface{} { return &c.IncidentEncryption }},
+	{key: "color_enabled", kind: "bool", field: func(c *Config) interface{} { return &c.ColorEnabled }},
+	{key: "color_mode", kind: "string", field: func(c *Config) interface{} { return &c.ColorMode }},
+}
+
+// envNames returns the variables that override a setting
+func (s setting) envNames() []string {
+	if len(s.env) > 0 {
+		return s.env
+	}
+	return []string{EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))}
+}
+
+// Setting is a configuration value as 'info' shows it: its effective value
+// and where that came from. Env names the variable that set it, or the one
+// that would.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Env    string `json:"env"`
+}
+
+// applyEnv overrides the configuration with the REDTRIAGE_* environment
+// variables that are set and records the source of every value. A value
+// that does not parse as the setting's type is an error naming the variable.
+func (c *Config) applyEnv(fromFile func(key string) bool) error {
+	c.sources = make(map[string]string, len(settings))
+	c.envNames = make(map[string]string)
+	for _, s := range settings {
+		c.sources[s.key] = SourceDefault
+		if fromFile != nil && fromFile(s.key) {
+			c.sources[s.key] = SourceFile
+		}
+
+		for _, name := range s.envNames() {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			if err := setValue(s, s.field(c), strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			c.sources[s.key] = SourceEnv
+			c.envNames[s.key] = name
+			break
+		}
+	}
+	return nil
+}
+
+// setValue parses value as the setting's type and stores it in field
+func setValue(s setting, field interface{}, value string) error {
+	switch s.kind {
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%q is not a duration (e.g. 30s, 20m, 2h)", value)
+		}
+	case "size":
+		if _, err := ParseSize(value); err != nil {
+			return fmt.Errorf("%q is not a size (e.g. 512KB, 100MB)", value)
+		}
+	}
+
+	switch target := field.(type) {
+	case *string:
+		*target = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*target = n
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean (true or false)", value)
+		}
+		*target = b
+	case *[]string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*target = items
+	default:
+		return fmt.Errorf("%s cannot be set from the environment", s.key)
+	}
+	return nil
+}
+
+// Source returns where a configuration value came from: SourceDefault,
+// SourceFile or SourceEnv
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Settings lists the effective configuration values with their sources, in
+// the order of the configuration file. Secrets are masked.
+func (c *Config) Settings() []Setting {
+	list := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		entry := Setting{Key: s.key, Source: c.Source(s.key), Env: s.envNames()[0]}
+		if name := c.envNames[s.key]; name != "" {
+			entry.Env = name
+		}
+		switch value := s.field(c).(type) {
+		case *string:
+			entry.Value = *value
+		case *int:
+			entry.Value = strconv.Itoa(*value)
+		case *bool:
+			entry.Value = strconv.FormatBool(*value)
+		case *[]string:
+			entry.Value = strings.Join(*value, ",")
+		}
+		if s.secret && entry.Value != "" {
+			entry.Value = "********"
+		}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// UnknownEnv returns the REDTRIAGE_* variables that are set but match no
+// configuration value, sorted, so that a misspelled name can be reported
+func UnknownEnv() []string {
+	known := map[string]bool{}
+	for _, s := range settings {
+		for _, name := range s.envNames() {
+			known[name] = true
+		}
+	}
+	var unknown []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, EnvPrefix) && !known[name] && !otherEnv[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// otherEnv are REDTRIAGE_* variables read elsewhere that are not
+// configuration values
+var otherEnv = map[string]bool{
+	"REDTRIAGE_SEAL_PASSPHRASE":     true,
+	"REDTRIAGE_INCIDENT_PASSPHRASE": true,
+}
+
+// inConfigFile reports whether a key was read from the configuration file
+func inConfigFile(key string) bool {
+	return viper.ConfigFileUsed() != "" && viper.InConfig(key)
+}
+
+// Effective is the configuration in use as 'info' reports it
+type Effective struct {
+	File       string    `json:"config_file"`
+	Settings   []Setting `json:"settings"`
+	UnknownEnv []string  `json:"unknown_env,omitempty"`
+}
+
+// Effective returns the configuration file read, if any, every value with
+// its source and the REDTRIAGE_* variables that matched nothing
+func (c *Config) Effective() Effective {
+	return Effective{File: viper.ConfigFileUsed(), Settings: c.Settings(), UnknownEnv: UnknownEnv()}
+}
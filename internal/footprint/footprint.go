@@ -0,0 +1,277 @@
+package footprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+const (
+	// Standard is the default footprint: output, logs and history go wherever configured
+	Standard = "standard"
+	// Minimal confines every write to the analyst-provided destination
+	Minimal = "minimal"
+)
+
+// custodyLogFile is the name of the custody log written to the destination
+const custodyLogFile = "custody-log.json"
+
+// Write records a single file the tool wrote during the run
+type Write struct {
+	Path        string    `json:"path"`
+	Purpose     string    `json:"purpose"`
+	Timestamp   time.Time `json:"timestamp"`
+	Unavoidable bool      `json:"unavoidable,omitempty"`
+}
+
+// Skip records an operation that was skipped to honour the footprint mode
+type Skip struct {
+	Operation string    `json:"operation"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OptIn records a collection the analyst explicitly asked for beyond the
+// defaults, such as reading credential file contents
+type OptIn struct {
+	Operation string    `json:"operation"`
+	Detail    string    `json:"detail"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Binary records the RedTriage binary that ran and its integrity check
+type Binary struct {
+	Path      string   `json:"path"`
+	SHA256    string   `json:"sha256"`
+	Integrity string   `json:"integrity"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// Confirmation records the summary shown before a collection and the
+// analyst's answer to it
+type Confirmation struct {
+	Summary   []string  `json:"summary"`
+	Decision  string    `json:"decision"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Policy describes the footprint constraints for a run and records every
+// write the tool makes so it can be documented in the custody log
+type Policy struct {
+	Mode        string
+	Destination string
+	TempDir     string
+	StartedAt   time.Time
+
+	mu      sync.Mutex
+	writes  []Write
+	skips   []Skip
+	optIns  []OptIn
+	binary  *Binary
+	confirm *Confirmation
+}
+
+var (
+	currentMu sync.Mutex
+	current   = &Policy{Mode: Standard, StartedAt: time.Now()}
+)
+
+// ValidModes lists the accepted --footprint values
+var ValidModes = []string{Standard, Minimal}
+
+// New creates a footprint policy. In minimal mode a destination is required
+// and the process temp directory is redirected beneath it.
+func New(mode, destination string) (*Policy, error) {
+	p := &Policy{
+		Mode:        mode,
+		Destination: destination,
+		StartedAt:   time.Now(),
+	}
+
+	switch mode {
+	case Standard:
+		return p, nil
+	case Minimal:
+	default:
+		return nil, fmt.Errorf("invalid footprint mode '%s'. Must be one of: %s, %s", mode, Standard, Minimal)
+	}
+
+	if destination == "" {
+		return nil, fmt.Errorf("footprint minimal requires an explicit output destination")
+	}
+
+	absDest, err := filepath.Abs(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %s: %w", destination, err)
+	}
+	p.Destination = absDest
+
+	p.TempDir = filepath.Join(absDest, "tmp")
+	if err := os.MkdirAll(p.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory on destination: %w", err)
+	}
+
+	// Keep temp files off the target system
+	for _, env := range []string{"TMPDIR", "TMP", "TEMP"} {
+		os.Setenv(env, p.TempDir)
+	}
+
+	p.RecordWrite(p.TempDir, "temporary files directory", false)
+
+	return p, nil
+}
+
+// SetCurrent installs p as the process-wide footprint policy
+func SetCurrent(p *Policy) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = p
+}
+
+// Current returns the process-wide footprint policy
+func Current() *Policy {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
+
+// IsMinimal reports whether the policy forbids writes outside the destination
+func (p *Policy) IsMinimal() bool {
+	return p != nil && p.Mode == Minimal
+}
+
+// Constraints describes what the mode guarantees, for the custody log
+func (p *Policy) Constraints() []string {
+	if !p.IsMinimal() {
+		return []string{"no footprint restrictions"}
+	}
+	return []string{
+		"all output written under " + p.Destination,
+		"temporary files created under " + p.TempDir,
+		"readline history disabled",
+		"configuration files are not created or modified",
+		"registry is not written",
+		"operations that require writing to the target system are skipped",
+	}
+}
+
+// RecordWrite records a file or directory written during the run
+func (p *Policy) RecordWrite(path, purpose string, unavoidable bool) {
+	if p == nil {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writes = append(p.writes, Write{
+		Path:        path,
+		Purpose:     purpose,
+		Timestamp:   time.Now(),
+		Unavoidable: unavoidable,
+	})
+}
+
+// RecordSkip records an operation skipped because it would write to the target
+func (p *Policy) RecordSkip(operation, reason string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.skips = append(p.skips, Skip{
+		Operation: operation,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+}
+
+// RecordOptIn records an opt-in collection so the custody log shows the
+// analyst asked for it
+func (p *Policy) RecordOptIn(operation, detail string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.optIns = append(p.optIns, OptIn{
+		Operation: operation,
+		Detail:    detail,
+		Timestamp: time.Now(),
+	})
+}
+
+// RecordBinary records the running binary's hash and integrity result so
+// the custody log names the exact tool that handled the evidence
+func (p *Policy) RecordBinary(binary Binary) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.binary = &binary
+}
+
+// RecordConfirmation records the collection summary and whether it was
+// confirmed
+func (p *Policy) RecordConfirmation(summary []string, decision string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.confirm = &Confirmation{Summary: summary, Decision: decision, Timestamp: time.Now()}
+}
+
+// WriteCustodyLog writes the mode, its constraints and every recorded write
+// to the destination. It returns the custody log path.
+func (p *Policy) WriteCustodyLog() (string, error) {
+	if !p.IsMinimal() {
+		return "", nil
+	}
+
+	p.mu.Lock()
+	hostname, _ := os.Hostname()
+	log := map[string]interface{}{
+		"mode":        p.Mode,
+		"destination": p.Destination,
+		"hostname":    hostname,
+		"pid":         os.Getpid(),
+		"started_at":  p.StartedAt,
+		"finished_at": time.Now(),
+		"constraints": p.Constraints(),
+		"writes":      append([]Write(nil), p.writes...),
+		"skipped":     append([]Skip(nil), p.skips...),
+		"opt_ins":     append([]OptIn(nil), p.optIns...),
+	}
+	if p.binary != nil {
+		log["binary"] = *p.binary
+	}
+	if p.confirm != nil {
+		log["confirmation"] = *p.confirm
+	}
+	p.mu.Unlock()
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal custody log: %w", err)
+	}
+
+	path := filepath.Join(p.Destination, custodyLogFile)
+	if err := output.WriteFileAtomic(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write custody log: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,62 @@
+// Package heapsample measures the peak heap growth of a piece of work, for
+// the tests and benchmarks that check large exports stream instead of
+// holding their data in memory
+package heapsample
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// interval is how often the heap is sampled
+const interval = 20 * time.Millisecond
+
+// Sampler records the largest heap growth over a baseline taken when it
+// starts
+type Sampler struct {
+	baseline uint64
+	peak     uint64
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// Start collects garbage, takes the baseline and samples the heap until Stop
+func Start() *Sampler {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	s := &Sampler{baseline: stats.HeapAlloc, done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.sample()
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+func (s *Sampler) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > s.baseline && stats.HeapAlloc-s.baseline > s.peak {
+		s.peak = stats.HeapAlloc - s.baseline
+	}
+}
+
+// Stop ends sampling and returns the peak heap growth in bytes
+func (s *Sampler) Stop() uint64 {
+	close(s.done)
+	s.wg.Wait()
+	s.sample()
+	return s.peak
+}
@@ -0,0 +1,322 @@
+// Package integrity checks that the running RedTriage binary is the one that
+// was released. A release build carries the SHA-256 of its own file in an
+// embedded baseline, written into the linked binary by Embed; a signed
+// SHA256SUMS file next to the binary is checked as well when present.
+package integrity
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"debug/pe"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/footprint"
+)
+
+// baselinePrefix opens the embedded baseline in the binary
+const baselinePrefix = "RTINTEGRITY:"
+
+// noBaseline is the hash of a build that was never given a baseline
+const noBaseline = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// baseline is patched in the linked binary by Embed. It must stay a variable
+// so its bytes appear exactly once in the file.
+var baseline = baselinePrefix + noBaseline
+
+// PublicKey is the base64 Ed25519 key SHA256SUMS.sig is checked with, set
+// at release build time with
+// -X github.com/redtriage/redtriage/internal/integrity.PublicKey=<key>
+var PublicKey = ""
+
+// Names of the signed hashes file and its signature next to the binary
+const (
+	SumsFile      = "SHA256SUMS"
+	SignatureFile = "SHA256SUMS.sig"
+)
+
+// Results of comparing the binary with the embedded baseline and with the
+// hashes file
+const (
+	StatusVerified     = "verified"
+	StatusMismatch     = "mismatch"
+	StatusNoBaseline   = "no baseline"
+	StatusUnavailable  = "unavailable"
+	StatusNotListed    = "not listed"
+	StatusUnsigned     = "unsigned"
+	StatusBadSignature = "bad signature"
+	StatusNoPublicKey  = "no public key"
+)
+
+// Report is the result of checking the running binary
+type Report struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	// Baseline is the result against the embedded baseline
+	Baseline     string `json:"baseline"`
+	BaselineHash string `json:"baseline_hash,omitempty"`
+	// Hashes is the result against SHA256SUMS, when the file exists
+	HashesFile string `json:"hashes_file,omitempty"`
+	Hashes     string `json:"hashes,omitempty"`
+	// WritableBy says how users other than administrators can modify or
+	// replace the binary
+	WritableBy string `json:"writable_by,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DevelopmentBuild reports whether the binary carries no baseline
+func (r Report) DevelopmentBuild() bool {
+	return r.Baseline == StatusNoBaseline
+}
+
+// Tampered reports whether the binary differs from its baseline or from a
+// correctly signed hashes file, or the hashes file signature is wrong
+func (r Report) Tampered() bool {
+	return r.Baseline == StatusMismatch || r.Hashes == StatusMismatch || r.Hashes == StatusBadSignature
+}
+
+// Warnings lists what an analyst must be told before trusting the binary
+func (r Report) Warnings() []string {
+	var warnings []string
+	if r.Baseline == StatusMismatch {
+		warnings = append(warnings, fmt.Sprintf("binary SHA-256 does not match the embedded release baseline %s: it has been modified", r.BaselineHash))
+	}
+	switch r.Hashes {
+	case StatusMismatch:
+		warnings = append(warnings, fmt.Sprintf("binary SHA-256 does not match %s", r.HashesFile))
+	case StatusBadSignature:
+		warnings = append(warnings, fmt.Sprintf("the signature of %s is invalid: the hashes file has been modified", r.HashesFile))
+	}
+	if r.Error != "" {
+		warnings = append(warnings, "binary could not be verified: "+r.Error)
+	}
+	if r.WritableBy != "" {
+		warnings = append(warnings, "binary can be modified by non-administrators: "+r.WritableBy)
+	}
+	return warnings
+}
+
+// Summary is a one-line verification result for case notes
+func (r Report) Summary() string {
+	var result string
+	switch r.Baseline {
+	case StatusVerified:
+		result = "matches the embedded release baseline"
+	case StatusMismatch:
+		result = "DOES NOT MATCH the embedded release baseline"
+	case StatusNoBaseline:
+		result = "development build: no integrity baseline embedded"
+	default:
+		result = "could not be verified"
+	}
+	switch r.Hashes {
+	case StatusVerified:
+		result += "; matches signed " + SumsFile
+	case StatusMismatch:
+		result += "; DOES NOT MATCH " + SumsFile
+	case StatusBadSignature:
+		result += "; " + SumsFile + " signature INVALID"
+	case StatusNotListed, StatusUnsigned, StatusNoPublicKey:
+		result += "; " + SumsFile + " " + r.Hashes
+	}
+	return result
+}
+
+// Custody is the result as the custody log records it
+func (r Report) Custody() footprint.Binary {
+	return footprint.Binary{Path: r.Path, SHA256: r.SHA256, Integrity: r.Summary(), Warnings: r.Warnings()}
+}
+
+// Check verifies the running executable
+func Check() Report {
+	path, err := os.Executable()
+	if err == nil {
+		path, err = filepath.EvalSymlinks(path)
+	}
+	if err != nil {
+		return Report{Baseline: StatusUnavailable, Error: fmt.Sprintf("failed to locate executable: %v", err)}
+	}
+	return CheckFile(path)
+}
+
+// CheckFile verifies the binary at path against the baseline embedded in the
+// running program and against the hashes file next to it
+func CheckFile(path string) Report {
+	report := Report{Path: path, Baseline: StatusUnavailable}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to read executable: %v", err)
+		return report
+	}
+	sum := sha256.Sum256(data)
+	report.SHA256 = hex.EncodeToString(sum[:])
+
+	expected := strings.TrimPrefix(baseline, baselinePrefix)
+	switch {
+	case expected == noBaseline:
+		report.Baseline = StatusNoBaseline
+	default:
+		report.BaselineHash = expected
+		actual, err := baselineHash(data, baseline)
+		switch {
+		case err != nil:
+			report.Baseline = StatusMismatch
+			report.Error = err.Error()
+		case actual == expected:
+			report.Baseline = StatusVerified
+		default:
+			report.Baseline = StatusMismatch
+		}
+	}
+
+	report.HashesFile, report.Hashes = checkSums(path, report.SHA256)
+	report.WritableBy = nonAdminWriter(path)
+	return report
+}
+
+// Embed writes the baseline into the binary at path and returns the SHA-256
+// of the finished file, which is what SHA256SUMS should list. On Windows,
+// embed before Authenticode signing: the signature is left out of the hash.
+func Embed(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	placeholder := baselinePrefix + noBaseline
+	offset, err := findOnce(data, placeholder)
+	if err != nil {
+		return "", err
+	}
+	hash, err := baselineHash(data, placeholder)
+	if err != nil {
+		return "", err
+	}
+	copy(data[offset+len(baselinePrefix):], hash)
+
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// baselineHash hashes the binary as it was when the baseline was embedded:
+// with current, the baseline it carries now, reset to the placeholder and
+// without an Authenticode signature
+func baselineHash(data []byte, current string) (string, error) {
+	offset, err := findOnce(data, current)
+	if err != nil {
+		return "", err
+	}
+	normalized := append([]byte(nil), data...)
+	copy(normalized[offset+len(baselinePrefix):], noBaseline)
+	normalized = withoutAuthenticode(normalized)
+
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// findOnce returns the offset of the only occurrence of marker in data
+func findOnce(data []byte, marker string) (int, error) {
+	offset := bytes.Index(data, []byte(marker))
+	if offset < 0 {
+		return 0, fmt.Errorf("integrity baseline not found in the binary")
+	}
+	if bytes.Index(data[offset+1:], []byte(marker)) >= 0 {
+		return 0, fmt.Errorf("integrity baseline found more than once in the binary")
+	}
+	return offset, nil
+}
+
+// withoutAuthenticode blanks what Authenticode signing changes in a PE file:
+// the checksum, the certificate table entry and the certificates appended to
+// the file. Other files are returned unchanged.
+func withoutAuthenticode(data []byte) []byte {
+	file, err := pe.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return data
+	}
+	defer file.Close()
+
+	optional := int(binary.LittleEndian.Uint32(data[0x3c:])) + 4 + 20
+	var security *pe.DataDirectory
+	var directoryOffset int
+	switch header := file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		security, directoryOffset = &header.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY], optional+128
+	case *pe.OptionalHeader64:
+		security, directoryOffset = &header.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_SECURITY], optional+144
+	default:
+		return data
+	}
+	if directoryOffset+8 > len(data) {
+		return data
+	}
+
+	copy(data[optional+64:optional+68], make([]byte, 4))
+	copy(data[directoryOffset:directoryOffset+8], make([]byte, 8))
+	if start := int(security.VirtualAddress); security.Size > 0 && start > 0 && start <= len(data) {
+		data = data[:start]
+	}
+	return data
+}
+
+// checkSums checks the binary against SHA256SUMS next to it and its
+// signature. It returns no path when there is no hashes file.
+func checkSums(path, sha string) (string, string) {
+	sumsPath := filepath.Join(filepath.Dir(path), SumsFile)
+	sums, err := os.ReadFile(sumsPath)
+	if err != nil {
+		return "", ""
+	}
+
+	signature, err := os.ReadFile(filepath.Join(filepath.Dir(path), SignatureFile))
+	if err != nil {
+		return sumsPath, StatusUnsigned
+	}
+	if PublicKey == "" {
+		return sumsPath, StatusNoPublicKey
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return sumsPath, StatusNoPublicKey
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), sums, sig) {
+		return sumsPath, StatusBadSignature
+	}
+
+	listed, ok := listedHash(sums, filepath.Base(path))
+	switch {
+	case !ok:
+		return sumsPath, StatusNotListed
+	case strings.EqualFold(listed, sha):
+		return sumsPath, StatusVerified
+	default:
+		return sumsPath, StatusMismatch
+	}
+}
+
+// listedHash finds the hash of name in sha256sum output
+func listedHash(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+// +build !windows
+
+package integrity
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// nonAdminWriter says how someone other than root can modify the binary or
+// replace it in its directory, or returns "" when only root can
+func nonAdminWriter(path string) string {
+	for _, p := range []string{path, filepath.Dir(path)} {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		perm := info.Mode().Perm()
+		sticky := info.IsDir() && info.Mode()&os.ModeSticky != 0
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		switch {
+		case perm&0o002 != 0 && !sticky:
+			return p + " is world-writable"
+		case ok && perm&0o020 != 0 && stat.Gid != 0:
+			return fmt.Sprintf("%s is writable by group %s", p, groupName(stat.Gid))
+		case ok && perm&0o200 != 0 && stat.Uid != 0:
+			return fmt.Sprintf("%s is owned by %s", p, userName(stat.Uid))
+		}
+	}
+	return ""
+}
+
+func userName(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(id); err == nil {
+		return u.Username
+	}
+	return "uid " + id
+}
+
+func groupName(gid uint32) string {
+	id := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(id); err == nil {
+		return g.Name
+	}
+	return "gid " + id
+}
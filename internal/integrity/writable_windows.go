@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+package integrity
+
+import (
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetEffectiveRightsFromAcl = windows.NewLazySystemDLL("advapi32.dll").NewProc("GetEffectiveRightsFromAclW")
+
+// Rights that let a holder change a file, or remove it from its directory
+const (
+	fileWriteRights = windows.FILE_WRITE_DATA | windows.FILE_APPEND_DATA | windows.DELETE |
+		windows.WRITE_DAC | windows.WRITE_OWNER | windows.GENERIC_WRITE | windows.GENERIC_ALL
+	fileDeleteChild     = 0x40
+	directoryWriteRight = fileDeleteChild | windows.WRITE_DAC | windows.WRITE_OWNER | windows.GENERIC_ALL
+)
+
+// nonAdminGroups are the groups every interactive user belongs to
+var nonAdminGroups = []struct {
+	sid  windows.WELL_KNOWN_SID_TYPE
+	name string
+}{
+	{windows.WinWorldSid, "Everyone"},
+	{windows.WinAuthenticatedUserSid, "Authenticated Users"},
+	{windows.WinBuiltinUsersSid, "Users"},
+}
+
+// nonAdminWriter says which group of ordinary users can modify the binary or
+// replace it in its directory, or returns "" when only administrators can
+func nonAdminWriter(path string) string {
+	targets := []struct {
+		path   string
+		rights uint32
+	}{
+		{path, fileWriteRights},
+		{filepath.Dir(path), directoryWriteRight},
+	}
+	for _, target := range targets {
+		sd, err := windows.GetNamedSecurityInfo(target.path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+		if err != nil {
+			continue
+		}
+		dacl, _, err := sd.DACL()
+		if err != nil {
+			continue
+		}
+		if dacl == nil {
+			return target.path + " has no access control list"
+		}
+		for _, group := range nonAdminGroups {
+			sid, err := windows.CreateWellKnownSid(group.sid)
+			if err != nil {
+				continue
+			}
+			trustee := windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_WELL_KNOWN_GROUP,
+				TrusteeValue: windows.TrusteeValueFromSID(sid),
+			}
+			var rights uint32
+			ret, _, _ := procGetEffectiveRightsFromAcl.Call(uintptr(unsafe.Pointer(dacl)), uintptr(unsafe.Pointer(&trustee)), uintptr(unsafe.Pointer(&rights)))
+			if ret == 0 && rights&target.rights != 0 {
+				return target.path + " is writable by " + group.name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+// Package jsonstream reads large JSON record lists, such as event log
+// exports, one record at a time instead of decoding the whole document, and
+// writes large JSON objects one field at a time
+package jsonstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStop can be returned by a record callback to end the stream early
+// without an error
+var ErrStop = errors.New("stop streaming")
+
+// Records decodes a JSON record list from r and calls fn with each record
+// in turn. The list is either the top-level array or, for an object, the
+// first array found under one of keys; other values are skipped without
+// being kept. Records that are not JSON objects are skipped. It returns
+// the number of records passed to fn and stops when ctx is cancelled.
+func Records(ctx context.Context, r io.Reader, keys []string, fn func(map[string]interface{}) error) (int, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	switch token {
+	case json.Delim('['):
+		return streamArray(ctx, decoder, fn)
+	case json.Delim('{'):
+	default:
+		return 0, fmt.Errorf("failed to read records: expected a JSON array or object")
+	}
+
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read records: %w", err)
+		}
+		key, _ := keyToken.(string)
+		if wanted[key] {
+			next, err := decoder.Token()
+			if err != nil {
+				return 0, fmt.Errorf("failed to read records: %w", err)
+			}
+			if next == json.Delim('[') {
+				return streamArray(ctx, decoder, fn)
+			}
+			if delim, ok := next.(json.Delim); ok {
+				if err := skipValue(decoder, delim); err != nil {
+					return 0, err
+				}
+			}
+			continue
+		}
+
+		var skipped json.RawMessage
+		if err := decoder.Decode(&skipped); err != nil {
+			return 0, fmt.Errorf("failed to read records: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// streamArray decodes the elements of an array whose opening bracket has
+// been read
+func streamArray(ctx context.Context, decoder *json.Decoder, fn func(map[string]interface{}) error) (int, error) {
+	count := 0
+	for decoder.More() {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return count, fmt.Errorf("failed to read record %d: %w", count+1, err)
+		}
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		count++
+		if err := fn(record); err != nil {
+			if errors.Is(err, ErrStop) {
+				return count, nil
+			}
+			return count, err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return count, fmt.Errorf("failed to read records: %w", err)
+	}
+	return count, nil
+}
+
+// skipValue discards the rest of an object or array whose opening
+// delimiter has been read
+func skipValue(decoder *json.Decoder, open json.Delim) error {
+	if open != json.Delim('{') && open != json.Delim('[') {
+		return nil
+	}
+	depth := 1
+	for depth > 0 {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read records: %w", err)
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
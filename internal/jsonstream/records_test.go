@@ -0,0 +1,68 @@
+package jsonstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testEventLog returns records event log entries as {"host": ...,
+// "events": [...]}, every tenth a failed logon, after a list the keys do
+// not name
+func testEventLog(records int) string {
+	var b strings.Builder
+	b.WriteString(`{"host": "TEST-WS01", "tags": [{"event_id": 1}], "events": [`)
+	for i := 0; i < records; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		eventID := 4624
+		if i%10 == 0 {
+			eventID = 4625
+		}
+		fmt.Fprintf(&b, `{"event_id": %d, "record_id": %d, "data": {"LogonType": "3"}}`, eventID, i+1)
+	}
+	b.WriteString("]}")
+	return b.String()
+}
+
+func TestRecordsReadsTheNamedList(t *testing.T) {
+	failed := 0
+	count, err := Records(context.Background(), strings.NewReader(testEventLog(1000)), []string{"events"}, func(record map[string]interface{}) error {
+		if fmt.Sprint(record["event_id"]) == "4625" {
+			failed++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Records: %v", err)
+	}
+	if count != 1000 || failed != 100 {
+		t.Errorf("read %d records with %d failed logons, want 1000 and 100", count, failed)
+	}
+}
+
+func TestRecordsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count, err := Records(ctx, strings.NewReader(testEventLog(5000)), []string{"events"}, func(record map[string]interface{}) error {
+		if fmt.Sprint(record["record_id"]) == "1000" {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 1000 {
+		t.Errorf("cancelled stream read %d records (error: %v), want it to stop after 1000", count, err)
+	}
+}
+
+func TestRecordsStopsOnErrStop(t *testing.T) {
+	count, err := Records(context.Background(), strings.NewReader(testEventLog(100)), []string{"events"}, func(record map[string]interface{}) error {
+		return ErrStop
+	})
+	if err != nil || count != 1 {
+		t.Errorf("stopped stream read %d records (error: %v), want 1 and no error", count, err)
+	}
+}
@@ -0,0 +1,127 @@
+package jsonstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ObjectWriter writes a JSON object one field at a time, indented like
+// json.MarshalIndent with two spaces. Only the value being written is held
+// in memory, so a document far larger than memory can be produced. Objects
+// may be nested with Begin and End; Close ends every open object.
+type ObjectWriter struct {
+	w       *bufio.Writer
+	buf     bytes.Buffer
+	encoder *json.Encoder
+	// Whether each open object already has a field, innermost last
+	fields []bool
+	err    error
+}
+
+// NewObjectWriter starts a JSON object on w
+func NewObjectWriter(w io.Writer) *ObjectWriter {
+	o := &ObjectWriter{w: bufio.NewWriterSize(w, 1<<16), fields: []bool{false}}
+	o.encoder = json.NewEncoder(&o.buf)
+	o.w.WriteString("{")
+	return o
+}
+
+// Field writes a field of the innermost open object
+func (o *ObjectWriter) Field(key string, value interface{}) error {
+	if o.err != nil {
+		return o.err
+	}
+
+	o.buf.Reset()
+	o.encoder.SetIndent(o.indent()+"  ", "  ")
+	if err := o.encoder.Encode(value); err != nil {
+		o.err = fmt.Errorf("failed to encode %s: %w", key, err)
+		return o.err
+	}
+
+	o.key(key)
+	// The encoder ends every value with a newline
+	o.w.Write(bytes.TrimSuffix(o.buf.Bytes(), []byte("\n")))
+	return o.flushError()
+}
+
+// Begin opens a nested object as a field of the innermost open object
+func (o *ObjectWriter) Begin(key string) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.key(key)
+	o.w.WriteString("{")
+	o.fields = append(o.fields, false)
+	return o.flushError()
+}
+
+// End closes the innermost nested object
+func (o *ObjectWriter) End() error {
+	if o.err != nil {
+		return o.err
+	}
+	if len(o.fields) < 2 {
+		o.err = fmt.Errorf("no nested object to end")
+		return o.err
+	}
+	o.close()
+	return o.flushError()
+}
+
+// Close ends every open object and flushes the output. It does not close
+// the underlying writer.
+func (o *ObjectWriter) Close() error {
+	if o.err != nil {
+		return o.err
+	}
+	for len(o.fields) > 0 {
+		o.close()
+	}
+	o.w.WriteString("\n")
+	if err := o.w.Flush(); err != nil {
+		o.err = fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return o.err
+}
+
+// key writes the separator and key of the next field
+func (o *ObjectWriter) key(key string) {
+	last := len(o.fields) - 1
+	if o.fields[last] {
+		o.w.WriteString(",")
+	}
+	o.fields[last] = true
+
+	name, _ := json.Marshal(key)
+	fmt.Fprintf(o.w, "\n%s  %s: ", o.indent(), name)
+}
+
+// close ends the innermost object
+func (o *ObjectWriter) close() {
+	last := len(o.fields) - 1
+	if o.fields[last] {
+		o.w.WriteString("\n" + o.indent())
+	}
+	o.w.WriteString("}")
+	o.fields = o.fields[:last]
+}
+
+// indent is the indentation of the innermost open object's closing brace;
+// its fields are indented two more spaces
+func (o *ObjectWriter) indent() string {
+	return strings.Repeat("  ", len(o.fields)-1)
+}
+
+// flushError records a failed write of the underlying writer. The buffered
+// writer keeps its first error, so a zero-length write reports it.
+func (o *ObjectWriter) flushError() error {
+	if _, err := o.w.Write(nil); err != nil {
+		o.err = fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return o.err
+}
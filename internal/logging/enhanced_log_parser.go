@@ -2,6 +2,7 @@ package logging
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"regexp"
@@ -9,6 +10,10 @@ import (
 	"time"
 )
 
+// cancelCheckInterval is how many lines or entries are processed between
+// checks for cancellation
+const cancelCheckInterval = 1000
+
 // LogEntry represents a parsed log entry
 type LogEntry struct {
 	Timestamp   time.Time              `json:"timestamp"`
@@ -153,8 +158,9 @@ func (lp *LogParser) loadBuiltInRules() {
 	lp.rules = append(lp.rules, builtInRules...)
 }
 
-// ParseLogFile parses a log file and returns parsed entries
-func (lp *LogParser) ParseLogFile(filePath string) ([]LogEntry, error) {
+// ParseLogFile parses a log file and returns parsed entries. It returns
+// ctx.Err() once ctx is cancelled.
+func (lp *LogParser) ParseLogFile(ctx context.Context, filePath string) ([]LogEntry, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
@@ -174,6 +180,11 @@ func (lp *LogParser) ParseLogFile(filePath string) ([]LogEntry, error) {
 	lineNumber := 0
 	for scanner.Scan() {
 		lineNumber++
+		if lineNumber%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		line := scanner.Text()
 		
 		if entry, err := parser.ParseLine(line); err == nil {
@@ -211,11 +222,17 @@ func (lp *LogParser) detectLogFormat(file *os.File) string {
 	return "generic" // Default fallback
 }
 
-// AnalyzeLogs analyzes parsed log entries using defined rules
-func (lp *LogParser) AnalyzeLogs(entries []LogEntry) []LogAnalysisResult {
+// AnalyzeLogs analyzes parsed log entries using defined rules. It returns
+// ctx.Err() once ctx is cancelled.
+func (lp *LogParser) AnalyzeLogs(ctx context.Context, entries []LogEntry) ([]LogAnalysisResult, error) {
 	var results []LogAnalysisResult
 	
-	for _, entry := range entries {
+	for i, entry := range entries {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		for _, rule := range lp.rules {
 			if match := lp.applyRule(rule, entry); match != nil {
 				results = append(results, *match)
@@ -223,7 +240,7 @@ func (lp *LogParser) AnalyzeLogs(entries []LogEntry) []LogAnalysisResult {
 		}
 	}
 	
-	return results
+	return results, nil
 }
 
 // applyRule applies a single analysis rule to a log entry
@@ -292,11 +309,17 @@ func (lp *LogParser) matchString(pattern, text string) (bool, float64) {
 	return false, 0.0
 }
 
-// GenerateTimeline generates a timeline from log entries
-func (lp *LogParser) GenerateTimeline(entries []LogEntry) []TimelineEvent {
+// GenerateTimeline generates a timeline from log entries. It returns
+// ctx.Err() once ctx is cancelled.
+func (lp *LogParser) GenerateTimeline(ctx context.Context, entries []LogEntry) ([]TimelineEvent, error) {
 	var timeline []TimelineEvent
 	
-	for _, entry := range entries {
+	for i, entry := range entries {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		event := TimelineEvent{
 			Timestamp: entry.Timestamp,
 			Source:    entry.Source,
@@ -313,11 +336,12 @@ func (lp *LogParser) GenerateTimeline(entries []LogEntry) []TimelineEvent {
 	
 	// Sort timeline by timestamp
 	// This would be implemented with a proper sort
-	return timeline
+	return timeline, nil
 }
 
-// DetectAnomalies detects anomalies in log entries
-func (lp *LogParser) DetectAnomalies(entries []LogEntry) []Anomaly {
+// DetectAnomalies detects anomalies in log entries. It returns ctx.Err()
+// once ctx is cancelled.
+func (lp *LogParser) DetectAnomalies(ctx context.Context, entries []LogEntry) ([]Anomaly, error) {
 	var anomalies []Anomaly
 	
 	// Group entries by user, process, IP, etc.
@@ -325,7 +349,12 @@ func (lp *LogParser) DetectAnomalies(entries []LogEntry) []Anomaly {
 	processActivity := make(map[string][]LogEntry)
 	ipActivity := make(map[string][]LogEntry)
 	
-	for _, entry := range entries {
+	for i, entry := range entries {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		if entry.User != "" {
 			userActivity[entry.User] = append(userActivity[entry.User], entry)
 		}
@@ -342,7 +371,7 @@ func (lp *LogParser) DetectAnomalies(entries []LogEntry) []Anomaly {
 	anomalies = append(anomalies, lp.detectUnusualProcessActivity(processActivity)...)
 	anomalies = append(anomalies, lp.detectUnusualIPActivity(ipActivity)...)
 	
-	return anomalies
+	return anomalies, nil
 }
 
 // detectUnusualUserActivity detects unusual user behavior
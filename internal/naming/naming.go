@@ -0,0 +1,251 @@
+// Package naming renders output file names, such as reports, bundles and
+// exports, from filename templates and keeps them valid on both Windows and
+// Linux
+package naming
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// MaxNameLength is the longest file name accepted, in bytes. Windows and
+// common Linux file systems allow 255; the rest is kept for a collision
+// suffix.
+const MaxNameLength = 240
+
+// Fields are the values a filename template can use:
+// {{.Incident}}, {{.Host}}, {{.Collection}}, {{.Type}}, {{.Format}},
+// {{.Date}} (20060102) and {{.Time}} (150405), plus {{date "2006-01-02"}}
+// for any Go time layout and the lower and upper functions
+type Fields struct {
+	Incident   string
+	Host       string
+	Collection string
+	Type       string // report or output type, e.g. full_report, bundle, findings
+	Format     string // file extension without the dot, e.g. html, zip
+	Timestamp  time.Time
+}
+
+// data is what a template is executed with
+type data struct {
+	Fields
+	Date string
+	Time string
+}
+
+// Parse parses a filename template. Unknown fields are an error when the
+// template is rendered.
+func Parse(text string, timestamp time.Time) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"date":  func(layout string) string { return timestamp.Format(layout) },
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+	tmpl, err := template.New("filename").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, rterrors.Validationf("invalid filename template %q: %v", text, err)
+	}
+	return tmpl, nil
+}
+
+// Render renders a filename template and checks that the result is a safe
+// file name
+func Render(text string, fields Fields) (string, error) {
+	if fields.Timestamp.IsZero() {
+		fields.Timestamp = time.Now()
+	}
+	tmpl, err := Parse(text, fields.Timestamp)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data{
+		Fields: fields,
+		Date:   fields.Timestamp.Format("20060102"),
+		Time:   fields.Timestamp.Format("150405"),
+	})
+	if err != nil {
+		return "", rterrors.Validationf("invalid filename template %q: %v", text, err)
+	}
+	name := buf.String()
+	if err := Check(name); err != nil {
+		return "", rterrors.Validationf("filename template %q renders %w", text, err)
+	}
+	return name, nil
+}
+
+// Validate checks a filename template by rendering it with sample fields
+func Validate(text string) error {
+	_, err := Render(text, Fields{
+		Incident:   "INC-001",
+		Host:       "WS01",
+		Collection: "RT-20240101-120000-0123abcd",
+		Type:       "full_report",
+		Format:     "html",
+	})
+	return err
+}
+
+// reservedNames are device names Windows refuses as file names, with or
+// without an extension
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Check reports why name cannot be used as a file name on Windows or Linux:
+// path separators, characters Windows forbids, control characters, reserved
+// device names, a trailing dot or space, or a name longer than MaxNameLength
+func Check(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("an empty file name")
+	case name == "." || name == "..":
+		return fmt.Errorf("%q, which is not a file name", name)
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("a file name of %d bytes (at most %d)", len(name), MaxNameLength)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("a file name that is not valid UTF-8")
+	case strings.HasSuffix(name, ".") || strings.HasSuffix(name, " "):
+		return fmt.Errorf("%q, which ends in a dot or space", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return fmt.Errorf("%q, which contains %q", name, r)
+		}
+	}
+	stem := strings.ToUpper(strings.TrimRight(strings.SplitN(name, ".", 2)[0], " "))
+	if reservedNames[stem] {
+		return fmt.Errorf("%q, a reserved device name on Windows", name)
+	}
+	return nil
+}
+
+// withSuffix inserts -n before the extension of name
+func withSuffix(name string, n int) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext)
+}
+
+// Namer names the files of one kind of output from a template. Names that
+// collide with an existing file, or one it named before, get a numeric
+// suffix (-1, -2, ...). A nil Namer or an empty template keeps the default
+// names, as before templates existed. A Namer is safe for concurrent use.
+type Namer struct {
+	template string
+	fields   Fields
+
+	mu    sync.Mutex
+	taken map[string]bool
+	names map[string]string
+}
+
+// New returns a Namer for template with the fields shared by every file it
+// names. The template is checked before anything is named.
+func New(template string, fields Fields) (*Namer, error) {
+	if template != "" {
+		if err := Validate(template); err != nil {
+			return nil, err
+		}
+	}
+	if fields.Timestamp.IsZero() {
+		fields.Timestamp = time.Now()
+	}
+	return &Namer{template: template, fields: fields, taken: make(map[string]bool), names: make(map[string]string)}, nil
+}
+
+// Templated reports whether names come from a template
+func (n *Namer) Templated() bool {
+	return n != nil && n.template != ""
+}
+
+// SetCollection sets the collection ID of the files named from now on
+func (n *Namer) SetCollection(id string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fields.Collection = id
+}
+
+// SetHost sets the host of the files named from now on, unless one was given
+func (n *Namer) SetHost(host string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fields.Host == "" {
+		n.fields.Host = host
+	}
+}
+
+// Path returns the path in dir of a file of the given type and format, or
+// dir/defaultName when names are not templated. A rendered name that does
+// not end in the format's extension gets it appended.
+func (n *Namer) Path(dir, fileType, format, defaultName string) (string, error) {
+	if n == nil {
+		return filepath.Join(dir, defaultName), nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.template == "" {
+		n.names[defaultName] = defaultName
+		return filepath.Join(dir, defaultName), nil
+	}
+
+	fields := n.fields
+	fields.Type, fields.Format = fileType, format
+	name, err := Render(n.template, fields)
+	if err != nil {
+		return "", err
+	}
+	if format != "" && !strings.EqualFold(filepath.Ext(name), "."+format) {
+		name += "." + format
+		if err := Check(name); err != nil {
+			return "", rterrors.Validationf("filename template %q renders %w", n.template, err)
+		}
+	}
+
+	path := filepath.Join(dir, name)
+	for i := 1; n.taken[path] || exists(path); i++ {
+		path = filepath.Join(dir, withSuffix(name, i))
+	}
+	n.taken[path] = true
+	n.names[defaultName] = filepath.Base(path)
+	return path, nil
+}
+
+// Names maps the default name of every file named so far to the name it
+// was given
+func (n *Namer) Names() map[string]string {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	names := make(map[string]string, len(n.names))
+	for defaultName, name := range n.names {
+		names[defaultName] = name
+	}
+	return names
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
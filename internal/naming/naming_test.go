@@ -0,0 +1,107 @@
+package naming
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"ACME-INC-001-WS01-20240101-full_report.html", true},
+		{"report.v2.html", true},
+		{"Bericht für José.html", true},
+		{strings.Repeat("a", MaxNameLength), true},
+		{"CONSOLE.txt", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{strings.Repeat("a", MaxNameLength+1), false},
+		{"report\xff.html", false},
+		{"report.", false},
+		{"report ", false},
+		{"CON", false},
+		{"con.html", false},
+		{"Nul .txt", false},
+		{"COM1.zip", false},
+		{"lpt9", false},
+		{"a<b.html", false},
+		{"a>b.html", false},
+		{"a:b.html", false},
+		{`a"b.html`, false},
+		{"a/b.html", false},
+		{`a\b.html`, false},
+		{"a|b.html", false},
+		{"a?b.html", false},
+		{"a*b.html", false},
+		{"a\tb.html", false},
+		{"a\x7fb.html", false},
+	}
+	for _, tt := range tests {
+		if err := Check(tt.name); (err == nil) != tt.valid {
+			t.Errorf("Check(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"{{.Incident}}-{{.Host}}-{{.Date}}-{{.Type}}.{{.Format}}", true},
+		{`ACME-{{.Incident}}-{{date "2006-01-02"}}-{{lower .Type}}`, true},
+		{"{{upper .Host}}_{{.Collection}}_{{.Time}}", true},
+		{"{{.Unknown}}", false},
+		{"{{.Incident", false},
+		{"{{.Host}}/{{.Type}}", false},
+		{`{{date "15:04"}}-report`, false},
+		{"CON", false},
+		{"{{.Type}}.", false},
+		{"", false},
+		{strings.Repeat("x", MaxNameLength) + "{{.Host}}", false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.template); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", tt.template, err, tt.valid)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	fields := Fields{Incident: "INC-001", Host: "WS01", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	namer, err := New("{{.Incident}}-{{.Host}}-{{.Date}}-{{.Type}}", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A name already on disk gets the first suffix, and a name the namer
+	// gave out before the next one
+	existing := filepath.Join(dir, "INC-001-WS01-20240102-full_report.html")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"INC-001-WS01-20240102-full_report-1.html", "INC-001-WS01-20240102-full_report-2.html"} {
+		path, err := namer.Path(dir, "full_report", "html", "full_report.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(path) != want {
+			t.Errorf("Path named %s, want %s", filepath.Base(path), want)
+		}
+	}
+	if names := namer.Names(); names["full_report.html"] != "INC-001-WS01-20240102-full_report-2.html" {
+		t.Errorf("Names maps full_report.html to %q", names["full_report.html"])
+	}
+
+	var untemplated *Namer
+	if path, err := untemplated.Path(dir, "full_report", "html", "full_report.html"); err != nil || path != filepath.Join(dir, "full_report.html") {
+		t.Errorf("nil Namer named %s (error %v), want the default name", path, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package offline analyzes a collection bundle on an analyst workstation,
+// away from the host it was collected on. Everything is read from the bundle
+// and written next to it; the local reports tree is never used.
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// Analysis is the result of running the findings engine over a bundle
+type Analysis struct {
+	// Bundle is the bundle ZIP or extracted bundle directory analyzed
+	Bundle string
+	CaseID string
+	// Host is the host the bundle was collected on, when its manifest
+	// records it
+	Host *collector.HostIdentity
+	// Artifacts are the bundled artifacts plus the ones the detector derives
+	Artifacts []collector.ArtifactResult
+	// Findings are the findings of this analysis
+	Findings []detector.Finding
+	// BundleFindings are the findings recorded when the bundle was created
+	BundleFindings []detector.Finding
+	// Warnings are the problems the findings engine worked around
+	Warnings []string
+}
+
+// Analyze opens the bundle, reads its artifacts and runs the findings engine
+// over them
+func Analyze(bundlePath string) (*Analysis, error) {
+	bundle, err := packager.OpenBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer bundle.Close()
+
+	artifacts, err := bundle.Artifacts()
+	if err != nil {
+		return nil, err
+	}
+	artifacts = append(artifacts, detector.DerivedArtifacts(artifacts)...)
+
+	detectorInstance := detector.NewDetector()
+	findings, err := detectorInstance.Evaluate(artifacts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate bundle artifacts: %w", err)
+	}
+	bundleFindings, err := bundle.Findings()
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &Analysis{
+		Bundle:         bundlePath,
+		CaseID:         bundle.Manifest.CaseID,
+		Artifacts:      artifacts,
+		Findings:       findings,
+		BundleFindings: bundleFindings,
+		Warnings:       detectorInstance.Warnings(),
+	}
+	if identity, ok := collector.HostIdentityFromMap(bundle.Manifest.HostInfo); ok {
+		analysis.Host = &identity
+	}
+	return analysis, nil
+}
+
+// Dir is where the analysis of a bundle is written: a directory named after
+// the bundle inside outputDir, or next to the bundle when outputDir is empty
+func Dir(bundlePath, outputDir string) string {
+	bundlePath = filepath.Clean(bundlePath)
+	if outputDir == "" {
+		outputDir = filepath.Dir(bundlePath)
+	}
+	name := strings.TrimSuffix(filepath.Base(bundlePath), ".zip")
+	return filepath.Join(outputDir, name+"-analysis")
+}
+
+// WriteFindings writes the findings of the analysis to findings.json in dir
+func (a *Analysis) WriteFindings(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create analysis directory: %w", err)
+	}
+	data, err := json.MarshalIndent(a.Findings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal findings: %w", err)
+	}
+	path := filepath.Join(dir, "findings.json")
+	if err := output.WriteFileAtomic(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write findings: %w", err)
+	}
+	return path, nil
+}
+
+// WriteReports writes the Markdown and HTML reports of the analysis to the
+// reports directory in dir
+func (a *Analysis) WriteReports(dir string) ([]reporter.ReportInfo, error) {
+	return reporter.NewReporter().GenerateReportsTo(a.Artifacts, a.Findings, filepath.Join(dir, "reports"))
+}
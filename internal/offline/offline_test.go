@@ -0,0 +1,105 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// testBundle packages a process and a network listing that fire built-in
+// rules, with their findings, and returns the bundle and findings
+func testBundle(t *testing.T) (string, []detector.Finding) {
+	t.Helper()
+	now := time.Now()
+	var artifacts []collector.ArtifactResult
+	for _, a := range []struct{ name, category, data string }{
+		{"process_list", "process", "svchost.exe 812\nsuspicious.exe 4242\n"},
+		{"network_connections", "network", "TCP 10.0.0.5:49712 185.220.101.45:4444 ESTABLISHED suspicious.exe\n"},
+		{"hostname", "system", "TEST-WS01\n"},
+	} {
+		artifacts = append(artifacts, collector.ArtifactResult{
+			Artifact: collector.Artifact{Name: a.name, Category: a.category, Type: "command"},
+			Data:     a.data,
+			Metadata: collector.Metadata{StartedAt: now, CollectedAt: now},
+		})
+	}
+
+	findings, err := detector.NewDetector().Evaluate(artifacts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) == 0 {
+		t.Fatal("test artifacts fire no rules")
+	}
+	bundle, err := packager.NewPackager().CreateBundle(artifacts, findings, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateBundle: %v", err)
+	}
+	return bundle, findings
+}
+
+// findingKeys lists the rule and severity of each finding, sorted
+func findingKeys(findings []detector.Finding) string {
+	keys := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		keys = append(keys, finding.RuleID+":"+finding.Severity)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func TestAnalyzeMovedBundle(t *testing.T) {
+	bundle, findings := testBundle(t)
+
+	// The analyst copies the bundle to a workstation and may extract it
+	workstation := t.TempDir()
+	zipPath := filepath.Join(workstation, filepath.Base(bundle))
+	if err := utils.CopyFile(bundle, zipPath); err != nil {
+		t.Fatal(err)
+	}
+	extracted := filepath.Join(workstation, "extracted")
+	if _, err := archive.Extract(zipPath, extracted, archive.DefaultLimits); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	want := findingKeys(findings)
+	for _, input := range []string{zipPath, extracted} {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			analysis, err := Analyze(input)
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if got := findingKeys(analysis.Findings); got != want {
+				t.Errorf("offline findings differ from the collection: got %s, want %s", got, want)
+			}
+			if got := findingKeys(analysis.BundleFindings); got != want {
+				t.Errorf("findings recorded in the bundle differ from the collection: got %s, want %s", got, want)
+			}
+
+			dir := Dir(input, "")
+			if filepath.Dir(dir) != workstation {
+				t.Fatalf("analysis written to %s, not next to the bundle", dir)
+			}
+			if _, err := analysis.WriteFindings(dir); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := analysis.WriteReports(dir); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"findings.json", filepath.Join("reports", "full_report.html")} {
+				if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Size() == 0 {
+					t.Errorf("offline analysis did not write %s", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// atomicWriterEnv makes the test binary run atomicWriterProcess instead of
+// the tests: it rewrites the file the variable names until it is killed
+const atomicWriterEnv = "REDTRIAGE_TEST_ATOMIC_WRITER"
+
+// atomicContents are the complete versions the writer process alternates
+// between. They are large enough that a write is often cut short.
+var atomicContents = [][]byte{
+	bytes.Repeat([]byte("old record\n"), 200000),
+	bytes.Repeat([]byte("new record\n"), 300000),
+}
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(atomicWriterEnv); path != "" {
+		atomicWriterProcess(path)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// atomicWriterProcess rewrites path with each version in turn, forever
+func atomicWriterProcess(path string) {
+	for i := 0; ; i++ {
+		if err := WriteFileAtomic(path, atomicContents[i%len(atomicContents)], 0644); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+func TestWriteFileAtomicSurvivesKilledWriter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts and kills writer processes")
+	}
+	path := filepath.Join(t.TempDir(), "incident.json")
+	if err := WriteFileAtomic(path, atomicContents[0], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for round := 0; round < 20; round++ {
+		writer := exec.Command(os.Args[0], "-test.run=^$")
+		writer.Env = append(os.Environ(), atomicWriterEnv+"="+path)
+		if err := writer.Start(); err != nil {
+			t.Fatalf("failed to start writer: %v", err)
+		}
+		// Kill it at a different point of its writes each round
+		time.Sleep(time.Duration(5+round*3) * time.Millisecond)
+		writer.Process.Kill()
+		writer.Wait()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("round %d: file lost after the writer was killed: %v", round, err)
+		}
+		if !bytes.Equal(data, atomicContents[0]) && !bytes.Equal(data, atomicContents[1]) {
+			t.Fatalf("round %d: partial file of %d bytes left after the writer was killed", round, len(data))
+		}
+	}
+}
+
+func TestWriteFileAtomicKeepsOldFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+	if err := WriteFileAtomic(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Renaming over a non-empty directory fails after the data was written
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.MkdirAll(filepath.Join(blocked, "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(blocked, []byte("new"), 0644); err == nil {
+		t.Fatal("write over a directory succeeded")
+	}
+
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old" {
+		t.Errorf("existing file changed: %q (%v)", data, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("temp file %s left behind after the failed write", entry.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicSetsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := WriteFileAtomic(path, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 && os.PathSeparator == '/' {
+		t.Errorf("file written with mode %v, want 0600", perm)
+	}
+}
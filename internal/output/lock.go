@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultLockTimeout is how long a write waits for another RedTriage process
+// to release the reports directory lock before giving up
+const DefaultLockTimeout = 30 * time.Second
+
+// lockFileName is the lock file kept at the root of the reports directory
+const lockFileName = ".redtriage.lock"
+
+// lockPollInterval is how often a blocked writer retries the lock
+const lockPollInterval = 50 * time.Millisecond
+
+// FileLock is an advisory, exclusive lock on a file held through the
+// operating system (flock on Unix, LockFileEx on Windows). The lock is
+// released when the holder calls Unlock or its process exits, so a crashed
+// instance never leaves a stale lock behind.
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// LockTimeoutError is returned when a lock could not be acquired in time
+type LockTimeoutError struct {
+	Path    string
+	Holder  string
+	Timeout time.Duration
+}
+
+func (e *LockTimeoutError) Error() string {
+	holder := "another RedTriage process"
+	if e.Holder != "" {
+		holder += " (" + e.Holder + ")"
+	}
+	return fmt.Sprintf("%s is locked by %s; gave up after %s. Wait for it to finish writing and retry",
+		e.Path, holder, e.Timeout)
+}
+
+// AcquireLock takes the exclusive lock on path, creating the file if needed,
+// and waits up to timeout for another holder to release it
+func AcquireLock(path string, timeout time.Duration) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(path)
+			file.Close()
+			return nil, &LockTimeoutError{Path: path, Holder: strings.TrimSpace(string(holder)), Timeout: timeout}
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	// Record the holder so a blocked process can say who it is waiting on
+	hostname, _ := os.Hostname()
+	if err := file.Truncate(0); err == nil {
+		fmt.Fprintf(file, "pid %d on %s since %s\n", os.Getpid(), hostname, time.Now().Format("15:04:05"))
+	}
+
+	return &FileLock{path: path, file: file}, nil
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	defer func() { l.file = nil }()
+
+	l.file.Truncate(0)
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", l.path, err)
+	}
+	return l.file.Close()
+}
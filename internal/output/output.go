@@ -18,6 +18,7 @@ type OutputManager struct {
 	outputFile   *os.File
 	outputFormat string
 	verbose      bool
+	quiet        bool
 	jsonOutput   bool
 	startTime    time.Time
 	commandName  string
@@ -132,6 +133,12 @@ func (om *OutputManager) initOutputFile() error {
 	return nil
 }
 
+// SetQuiet leaves success messages and the summary off the console; they
+// are still logged. Warnings and errors are always printed.
+func (om *OutputManager) SetQuiet(quiet bool) {
+	om.quiet = quiet
+}
+
 // LogInfo logs an informational message
 func (om *OutputManager) LogInfo(message string, args ...interface{}) {
 	formattedMessage := fmt.Sprintf(message, args...)
@@ -220,7 +227,9 @@ func (om *OutputManager) LogSuccess(message string, args ...interface{}) {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 
 	// Console output
-	color.New(color.FgGreen).Printf("[SUCCESS] %s\n", formattedMessage)
+	if !om.quiet {
+		color.New(color.FgGreen).Printf("[SUCCESS] %s\n", formattedMessage)
+	}
 
 	// Log file output
 	if om.logFile != nil {
@@ -274,6 +283,9 @@ func (om *OutputManager) WriteOutput() error {
 
 // PrintSummary prints a summary of the command execution
 func (om *OutputManager) PrintSummary() {
+	if om.quiet {
+		return
+	}
 	duration := time.Since(om.startTime)
 
 	fmt.Println()
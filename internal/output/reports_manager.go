@@ -1,16 +1,40 @@
 package output
 
 import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
-// ReportsManager handles centralized report storage and organization
+// maxNameAttempts bounds the retries for a free generated report name
+const maxNameAttempts = 16
+
+// ReportsManager handles centralized report storage and organization. It is
+// safe for concurrent use: writes, listing and cleanup are serialized within
+// the process by a mutex and across processes by the reports directory lock.
+// The Save methods generate a unique file name when given an empty one and
+// return the path they wrote.
 type ReportsManager struct {
-	reportsDir string
-	config     *ReportsConfig
+	reportsDir  string
+	config      *ReportsConfig
+	lockTimeout time.Duration
+
+	mu    sync.Mutex // held with the directory lock; guards scope
+	scope string
+
+	readOnly  bool
+	writeFile func(path string, data []byte, perm os.FileMode) error
+
+	spillMu  sync.Mutex // held while a spill directory is chosen
+	spill    SpillFunc
+	spillDir string
 }
 
 // ReportsConfig defines the structure for organizing reports
@@ -25,8 +49,30 @@ type ReportsConfig struct {
 
 // NewReportsManager creates a new reports manager
 func NewReportsManager(reportsDir string) (*ReportsManager, error) {
-	rm := &ReportsManager{
-		reportsDir: reportsDir,
+	rm := newReportsManager(reportsDir)
+
+	// Create all necessary directories
+	if err := rm.createDirectoryStructure(); err != nil {
+		return nil, fmt.Errorf("failed to create reports directory structure: %w", err)
+	}
+
+	return rm, nil
+}
+
+// NewReadOnlyReportsManager opens a reports directory that cannot be
+// written, such as a read-only mount. Nothing is created; listing, searching
+// and reading reports work and every write fails with ErrReadOnly.
+func NewReadOnlyReportsManager(reportsDir string) *ReportsManager {
+	rm := newReportsManager(reportsDir)
+	rm.readOnly = true
+	return rm
+}
+
+func newReportsManager(reportsDir string) *ReportsManager {
+	return &ReportsManager{
+		reportsDir:  reportsDir,
+		lockTimeout: DefaultLockTimeout,
+		writeFile:   WriteFileAtomic,
 		config: &ReportsConfig{
 			HealthReportsDir:    filepath.Join(reportsDir, "health"),
 			SystemReportsDir:    filepath.Join(reportsDir, "system"),
@@ -36,13 +82,64 @@ func NewReportsManager(reportsDir string) (*ReportsManager, error) {
 			MetadataDir:         filepath.Join(reportsDir, "metadata"),
 		},
 	}
+}
 
-	// Create all necessary directories
-	if err := rm.createDirectoryStructure(); err != nil {
-		return nil, fmt.Errorf("failed to create reports directory structure: %w", err)
+// ReadOnly reports whether the manager was opened read-only
+func (rm *ReportsManager) ReadOnly() bool {
+	return rm.readOnly
+}
+
+// readOnlyError is returned by every write through a read-only manager
+func (rm *ReportsManager) readOnlyError() error {
+	return fmt.Errorf("%w: %s", ErrReadOnly, rm.reportsDir)
+}
+
+// SetSpill sets the function asked for another directory when a save finds
+// the reports filesystem full. The directory it names is used for the rest
+// of the run without asking again.
+func (rm *ReportsManager) SetSpill(fn SpillFunc) {
+	rm.spillMu.Lock()
+	defer rm.spillMu.Unlock()
+	rm.spill = fn
+}
+
+// SpillDirectory returns the directory reports spilled to, or ""
+func (rm *ReportsManager) SpillDirectory() string {
+	rm.spillMu.Lock()
+	defer rm.spillMu.Unlock()
+	return rm.spillDir
+}
+
+// Resolve returns path, or its copy under the spill directory when only the
+// spilled copy exists
+func (rm *ReportsManager) Resolve(path string) string {
+	spillDir := rm.SpillDirectory()
+	if spillDir == "" {
+		return path
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return path
+	}
+	spilled := filepath.Join(spillDir, rm.relativePath(path))
+	if _, err := os.Lstat(spilled); err == nil {
+		return spilled
 	}
+	return path
+}
 
-	return rm, nil
+// ReportDirectories returns dir and, once reports have spilled, its
+// counterpart under the spill directory
+func (rm *ReportsManager) ReportDirectories(dir string) []string {
+	if spillDir := rm.SpillDirectory(); spillDir != "" {
+		return []string{dir, filepath.Join(spillDir, rm.relativePath(dir))}
+	}
+	return []string{dir}
+}
+
+// SetFileWriter replaces the function saves use to write into the reports
+// directory. The self-test uses it to stand in for a full filesystem.
+func (rm *ReportsManager) SetFileWriter(fn func(path string, data []byte, perm os.FileMode) error) {
+	rm.writeFile = fn
 }
 
 // createDirectoryStructure creates all necessary subdirectories
@@ -68,92 +165,319 @@ func (rm *ReportsManager) createDirectoryStructure() error {
 
 // SaveHealthReport saves a health check report
 func (rm *ReportsManager) SaveHealthReport(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("health-report-%s.json", timestamp)
-	}
-
-	filepath := filepath.Join(rm.config.HealthReportsDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	path, err := rm.save(rm.config.HealthReportsDir, filename, "health-report", ".json", data)
+	if err != nil {
 		return "", fmt.Errorf("failed to save health report: %w", err)
 	}
 
-	return filepath, nil
+	return path, nil
 }
 
 // SaveSystemReport saves a system profile report
 func (rm *ReportsManager) SaveSystemReport(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("system-profile-%s.json", timestamp)
-	}
-
-	filepath := filepath.Join(rm.config.SystemReportsDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	path, err := rm.save(rm.config.SystemReportsDir, filename, "system-profile", ".json", data)
+	if err != nil {
 		return "", fmt.Errorf("failed to save system report: %w", err)
 	}
 
-	return filepath, nil
+	return path, nil
 }
 
 // SaveCollectionReport saves a collection report
 func (rm *ReportsManager) SaveCollectionReport(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("collection-report-%s.json", timestamp)
+	path, err := rm.save(rm.config.CollectionReportsDir, filename, "collection-report", ".json", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to save collection report: %w", err)
 	}
 
-	filepath := filepath.Join(rm.config.CollectionReportsDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	return path, nil
+}
+
+// StreamCollectionReport writes a collection report produced by write
+// straight to a temporary file, so the report is never held in memory, and
+// then moves it into place under the reports directory lock
+func (rm *ReportsManager) StreamCollectionReport(filename string, write func(io.Writer) error) (string, error) {
+	path, err := rm.stream(rm.config.CollectionReportsDir, filename, "collection-report", ".json", write)
+	if err != nil {
 		return "", fmt.Errorf("failed to save collection report: %w", err)
 	}
-
-	return filepath, nil
+	return path, nil
 }
 
 // SaveTestReport saves a test report
 func (rm *ReportsManager) SaveTestReport(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("test-report-%s.json", timestamp)
-	}
-
-	filepath := filepath.Join(rm.config.TestReportsDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	path, err := rm.save(rm.config.TestReportsDir, filename, "test-report", ".json", data)
+	if err != nil {
 		return "", fmt.Errorf("failed to save test report: %w", err)
 	}
 
-	return filepath, nil
+	return path, nil
 }
 
 // SaveLog saves a log file
 func (rm *ReportsManager) SaveLog(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("redtriage-%s.log", timestamp)
-	}
-
-	filepath := filepath.Join(rm.config.LogsDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
+	path, err := rm.save(rm.config.LogsDir, filename, "redtriage", ".log", data)
+	if err != nil {
 		return "", fmt.Errorf("failed to save log: %w", err)
 	}
 
-	return filepath, nil
+	return path, nil
 }
 
 // SaveMetadata saves metadata information
 func (rm *ReportsManager) SaveMetadata(data []byte, filename string) (string, error) {
-	if filename == "" {
-		timestamp := time.Now().Format("20060102-150405")
-		filename = fmt.Sprintf("metadata-%s.json", timestamp)
+	path, err := rm.save(rm.config.MetadataDir, filename, "metadata", ".json", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to save metadata: %w", err)
 	}
 
-	filepath := filepath.Join(rm.config.MetadataDir, filename)
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to save metadata: %w", err)
+	return path, nil
+}
+
+// SetLockTimeout sets how long writes wait for another process holding the
+// reports directory lock
+func (rm *ReportsManager) SetLockTimeout(timeout time.Duration) {
+	rm.lockTimeout = timeout
+}
+
+// Lock takes the advisory lock on the reports directory. Every write through
+// the ReportsManager, and every incident file write, holds this lock so that
+// several RedTriage processes sharing a reports directory serialize instead
+// of clobbering each other. Callers must Unlock the returned lock.
+func (rm *ReportsManager) Lock() (*FileLock, error) {
+	return AcquireLock(filepath.Join(rm.reportsDir, lockFileName), rm.lockTimeout)
+}
+
+// WithLock runs fn while holding the reports directory lock. Goroutines of
+// this process wait on the manager's mutex first, so fn must not call back
+// into a locking ReportsManager method.
+func (rm *ReportsManager) WithLock(fn func() error) error {
+	if rm.readOnly {
+		return rm.readOnlyError()
 	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
 
-	return filepath, nil
+	lock, err := rm.Lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
+
+// SetScope sets the collection or incident ID that generated report names
+// include; an empty ID leaves it out
+func (rm *ReportsManager) SetScope(id string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.scope = id
+}
+
+// save atomically writes a report into dir while holding the reports
+// directory lock. An empty filename is replaced by a generated one that no
+// existing file uses: prefix, scope, time and a random suffix. A save that
+// finds the filesystem full is retried in the spill directory.
+func (rm *ReportsManager) save(dir, filename, prefix, ext string, data []byte) (string, error) {
+	if filename != "" && filepath.Base(filename) != filename {
+		return "", fmt.Errorf("invalid report name %q: must not contain a path", filename)
+	}
+	if rm.readOnly {
+		return "", rm.readOnlyError()
+	}
+
+	var path string
+	err := rm.WithLock(func() error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+
+		if filename != "" {
+			path = filepath.Join(dir, filename)
+			return rm.writeFile(path, data, 0644)
+		}
+		for attempt := 0; attempt < maxNameAttempts; attempt++ {
+			candidate := filepath.Join(dir, rm.generatedName(prefix, ext))
+			if _, err := os.Lstat(candidate); err == nil {
+				continue
+			}
+			path = candidate
+			return rm.writeFile(path, data, 0644)
+		}
+		return fmt.Errorf("failed to find a free %s report name after %d attempts", prefix, maxNameAttempts)
+	})
+	if err != nil && path != "" && IsDiskFull(err) {
+		return rm.spillWrite(path, err, func(target string) error {
+			return WriteFileAtomic(target, data, 0644)
+		})
+	}
+	return path, err
+}
+
+// spillWrite retries a write that failed because the filesystem is full at
+// the same place under the spill directory, asking for one the first time.
+// It returns the path written, or cause when there is no spill directory.
+func (rm *ReportsManager) spillWrite(path string, cause error, write func(target string) error) (string, error) {
+	rm.spillMu.Lock()
+	defer rm.spillMu.Unlock()
+
+	dir := rm.spillDir
+	if dir == "" && rm.spill != nil {
+		dir = rm.spill(path, cause)
+	}
+	if dir == "" {
+		return "", cause
+	}
+
+	target := filepath.Join(dir, rm.relativePath(path))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("failed to create spill directory: %w (after: %v)", err, cause)
+	}
+	if err := write(target); err != nil {
+		return "", fmt.Errorf("failed to write to spill directory %s: %w (after: %v)", dir, err, cause)
+	}
+	rm.spillDir = dir
+	return target, nil
+}
+
+// relativePath returns path relative to the reports directory, or its base
+// name when it lies outside
+func (rm *ReportsManager) relativePath(path string) string {
+	rel, err := filepath.Rel(rm.reportsDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// stream writes a report through write into a temporary file in dir and
+// renames it like save. Only the rename holds the directory lock, a long
+// write does not block other reports. When the filesystem fills up, write
+// is run again into the spill directory.
+func (rm *ReportsManager) stream(dir, filename, prefix, ext string, write func(io.Writer) error) (string, error) {
+	if filename != "" && filepath.Base(filename) != filename {
+		return "", fmt.Errorf("invalid report name %q: must not contain a path", filename)
+	}
+	if rm.readOnly {
+		return "", rm.readOnlyError()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmpPath, err := streamTemp(dir, prefix, write)
+	if err != nil {
+		if !IsDiskFull(err) {
+			return "", err
+		}
+		name := filename
+		if name == "" {
+			rm.mu.Lock()
+			name = rm.generatedName(prefix, ext)
+			rm.mu.Unlock()
+		}
+		return rm.spillWrite(filepath.Join(dir, name), err, func(target string) error {
+			spilled, err := streamTemp(filepath.Dir(target), prefix, write)
+			if err != nil {
+				return err
+			}
+			if err := os.Rename(spilled, target); err != nil {
+				os.Remove(spilled)
+				return fmt.Errorf("failed to rename temp file: %w", err)
+			}
+			return nil
+		})
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	var path string
+	err = rm.WithLock(func() error {
+		if filename != "" {
+			path = filepath.Join(dir, filename)
+		} else {
+			for attempt := 0; attempt < maxNameAttempts && path == ""; attempt++ {
+				candidate := filepath.Join(dir, rm.generatedName(prefix, ext))
+				if _, err := os.Lstat(candidate); err != nil {
+					path = candidate
+				}
+			}
+			if path == "" {
+				return fmt.Errorf("failed to find a free %s report name after %d attempts", prefix, maxNameAttempts)
+			}
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			return fmt.Errorf("failed to rename temp file: %w", err)
+		}
+		committed = true
+		syncDir(dir)
+		return nil
+	})
+	return path, err
+}
+
+// streamTemp writes the output of write to a new temporary file in dir and
+// returns its path. The file is removed again when any step fails.
+func streamTemp(dir, prefix string, write func(io.Writer) error) (string, error) {
+	tmp, err := os.CreateTemp(dir, "."+prefix+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	buffered := bufio.NewWriter(tmp)
+	err = write(buffered)
+	if err == nil {
+		if err = buffered.Flush(); err != nil {
+			err = fmt.Errorf("failed to write temp file: %w", err)
+		}
+	}
+	if err == nil {
+		if err = tmp.Sync(); err != nil {
+			err = fmt.Errorf("failed to sync temp file: %w", err)
+		}
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close temp file: %w", closeErr)
+	}
+	if err == nil {
+		if err = os.Chmod(tmpPath, 0644); err != nil {
+			err = fmt.Errorf("failed to set permissions: %w", err)
+		}
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
+// generatedName builds a report name from prefix, the scope, the time and a
+// random suffix. The caller holds rm.mu.
+func (rm *ReportsManager) generatedName(prefix, ext string) string {
+	parts := []string{prefix}
+	if rm.scope != "" {
+		parts = append(parts, rm.scope)
+	}
+	parts = append(parts, time.Now().Format("20060102-150405"))
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// Fall back to the clock, the existence check still avoids overwrites
+		return strings.Join(append(parts, fmt.Sprintf("%09d", time.Now().Nanosecond())), "-") + ext
+	}
+	return strings.Join(append(parts, hex.EncodeToString(suffix)), "-") + ext
+}
+
+// isPartialWrite reports whether name is the temporary file of a write in
+// progress, which listings and cleanup leave alone
+func isPartialWrite(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-")
 }
 
 // GetReportsDirectory returns the main reports directory
@@ -161,6 +485,11 @@ func (rm *ReportsManager) GetReportsDirectory() string {
 	return rm.reportsDir
 }
 
+// GetIncidentsDirectory returns the directory incident files are kept in
+func (rm *ReportsManager) GetIncidentsDirectory() string {
+	return filepath.Join(rm.reportsDir, "incidents")
+}
+
 // GetHealthReportsDirectory returns the health reports directory
 func (rm *ReportsManager) GetHealthReportsDirectory() string {
 	return rm.config.HealthReportsDir
@@ -191,34 +520,43 @@ func (rm *ReportsManager) GetMetadataDirectory() string {
 	return rm.config.MetadataDir
 }
 
-// ListReports lists all reports in a specific category
-func (rm *ReportsManager) ListReports(category string) ([]string, error) {
-	var dir string
+// GetCategoryDirectory returns the directory for a report category
+func (rm *ReportsManager) GetCategoryDirectory(category string) (string, error) {
 	switch category {
 	case "health":
-		dir = rm.config.HealthReportsDir
+		return rm.config.HealthReportsDir, nil
 	case "system":
-		dir = rm.config.SystemReportsDir
+		return rm.config.SystemReportsDir, nil
 	case "collection":
-		dir = rm.config.CollectionReportsDir
+		return rm.config.CollectionReportsDir, nil
 	case "tests":
-		dir = rm.config.TestReportsDir
+		return rm.config.TestReportsDir, nil
 	case "logs":
-		dir = rm.config.LogsDir
+		return rm.config.LogsDir, nil
 	case "metadata":
-		dir = rm.config.MetadataDir
+		return rm.config.MetadataDir, nil
 	default:
-		return nil, fmt.Errorf("unknown report category: %s", category)
+		return "", fmt.Errorf("unknown report category: %s", category)
+	}
+}
+
+// ListReports lists all reports in a specific category
+func (rm *ReportsManager) ListReports(category string) ([]string, error) {
+	dir, err := rm.GetCategoryDirectory(category)
+	if err != nil {
+		return nil, err
 	}
 
+	rm.mu.Lock()
 	entries, err := os.ReadDir(dir)
+	rm.mu.Unlock()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
 	}
 
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() && !isPartialWrite(entry.Name()) {
 			files = append(files, entry.Name())
 		}
 	}
@@ -237,13 +575,14 @@ func (rm *ReportsManager) CleanupOldReports(olderThan time.Duration) error {
 		rm.config.MetadataDir,
 	}
 
-	for _, dir := range categories {
-		if err := rm.cleanupDirectory(dir, olderThan); err != nil {
-			return fmt.Errorf("failed to cleanup directory %s: %w", dir, err)
+	return rm.WithLock(func() error {
+		for _, dir := range categories {
+			if err := rm.cleanupDirectory(dir, olderThan); err != nil {
+				return fmt.Errorf("failed to cleanup directory %s: %w", dir, err)
+			}
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // cleanupDirectory removes files older than the specified duration from a directory
@@ -255,7 +594,7 @@ func (rm *ReportsManager) cleanupDirectory(dir string, olderThan time.Duration)
 
 	cutoff := time.Now().Add(-olderThan)
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || isPartialWrite(entry.Name()) {
 			continue
 		}
 
@@ -275,3 +614,53 @@ func (rm *ReportsManager) cleanupDirectory(dir string, olderThan time.Duration)
 
 	return nil
 }
+
+// SearchMatch describes a single line in a report file that matched a search
+type SearchMatch struct {
+	Category string `json:"category"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Context  string `json:"context"`
+}
+
+// SearchReports performs a case-insensitive search for term in every report
+// of a category and returns the matching lines
+func (rm *ReportsManager) SearchReports(category, term string) ([]SearchMatch, error) {
+	dir, err := rm.GetCategoryDirectory(category)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := rm.ListReports(category)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(term)
+	var matches []SearchMatch
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(dir, file))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			if strings.Contains(strings.ToLower(line), needle) {
+				matches = append(matches, SearchMatch{
+					Category: category,
+					File:     file,
+					Line:     lineNum,
+					Context:  strings.TrimSpace(line),
+				})
+			}
+		}
+		f.Close()
+	}
+
+	return matches, nil
+}
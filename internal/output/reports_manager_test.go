@@ -0,0 +1,91 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestReportsManagerConcurrentSaves(t *testing.T) {
+	const savers, saves = 16, 25
+	rm, err := NewReportsManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.SetScope("INC-TEST")
+
+	// Half the saves get generated names and the rest share one name
+	var (
+		mu    sync.Mutex
+		paths = make(map[string]string)
+		wg    sync.WaitGroup
+	)
+	for g := 0; g < savers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < saves; i++ {
+				data := fmt.Sprintf("saver %d report %d", g, i)
+				var path string
+				var err error
+				switch i % 3 {
+				case 0:
+					path, err = rm.SaveCollectionReport([]byte(data), "")
+				case 1:
+					path, err = rm.SaveLog([]byte(data), "")
+				default:
+					_, err = rm.SaveCollectionReport([]byte(data), "shared.json")
+				}
+				if i%5 == 0 {
+					if _, listErr := rm.ListReports("collection"); listErr != nil && err == nil {
+						err = listErr
+					}
+				}
+
+				mu.Lock()
+				if err != nil {
+					t.Errorf("concurrent save failed: %v", err)
+				} else if path != "" {
+					if prior, taken := paths[path]; taken {
+						t.Errorf("%s and %s were both saved to %s", prior, data, path)
+					}
+					paths[path] = data
+				}
+				mu.Unlock()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for path, want := range paths {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("saved report missing: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("%s holds %q, want %q", path, got, want)
+		}
+	}
+	shared, err := os.ReadFile(filepath.Join(rm.GetCollectionReportsDirectory(), "shared.json"))
+	if err != nil {
+		t.Fatalf("shared report missing: %v", err)
+	}
+	var sg, si int
+	if _, err := fmt.Sscanf(string(shared), "saver %d report %d", &sg, &si); err != nil {
+		t.Errorf("shared report holds a torn write: %q", shared)
+	}
+
+	collections, err := rm.ListReports("collection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := rm.ListReports("logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(collections) + len(logs); got != len(paths)+1 {
+		t.Errorf("reports directory lists %d files, want %d", got, len(paths)+1)
+	}
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxDisplayLine is the number of characters of a line of untrusted text
+// shown on the terminal; the rest is cut
+const MaxDisplayLine = 2000
+
+// Sanitize makes untrusted text, such as process names, command lines, log
+// messages and finding descriptions, inert on a terminal. C0 and C1 control
+// characters, DEL, Unicode bidirectional overrides and bytes that are not
+// UTF-8 are shown escaped, so ESC prints as \x1b and an escape sequence as
+// plain text, and lines longer than MaxDisplayLine are cut. Newlines and
+// tabs are kept. Text written to files and JSON is not sanitized.
+func Sanitize(text string) string {
+	return sanitize(text, false)
+}
+
+// SanitizeLine is Sanitize for text shown on a single line, such as a table
+// cell: newlines and tabs are escaped as well
+func SanitizeLine(text string) string {
+	return sanitize(text, true)
+}
+
+func sanitize(text string, oneLine bool) string {
+	if inert(text, oneLine) {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	shown, cut := 0, 0
+	endLine := func() {
+		if cut > 0 {
+			fmt.Fprintf(&b, "... (%d characters cut)", cut)
+		}
+		shown, cut = 0, 0
+	}
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		char := text[i : i+size]
+		switch {
+		case r == utf8.RuneError && size == 1:
+			char = fmt.Sprintf(`\x%02x`, text[i])
+		case r == '\n' && !oneLine:
+			endLine()
+			b.WriteByte('\n')
+			i += size
+			continue
+		case r == '\t' && !oneLine:
+		case r == '\n':
+			char = `\n`
+		case r == '\r':
+			char = `\r`
+		case r == '\t':
+			char = `\t`
+		case r < 0x20 || r == 0x7f:
+			char = fmt.Sprintf(`\x%02x`, r)
+		case r >= 0x80 && r <= 0x9f, isBidiControl(r):
+			char = fmt.Sprintf(`\u%04x`, r)
+		}
+		i += size
+
+		if shown >= MaxDisplayLine {
+			cut++
+			continue
+		}
+		b.WriteString(char)
+		shown++
+	}
+	endLine()
+	return b.String()
+}
+
+// inert reports whether text can be shown as it is: no characters to escape
+// and no line too long
+func inert(text string, oneLine bool) bool {
+	line := 0
+	for _, r := range text {
+		switch {
+		case r == '\n' && !oneLine:
+			line = 0
+			continue
+		case r == '\t' && !oneLine:
+		case r < 0x20 || r == 0x7f || r == utf8.RuneError:
+			return false
+		case r >= 0x80 && r <= 0x9f, isBidiControl(r):
+			return false
+		}
+		if line++; line > MaxDisplayLine {
+			return false
+		}
+	}
+	return true
+}
+
+// isBidiControl reports whether r reorders the text around it, which can
+// make a command line display differently from what it runs
+func isBidiControl(r rune) bool {
+	return (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069)
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// hostileStrings are process names, command lines and log messages crafted
+// to take over the analyst's terminal, with the escaped text that must show
+var hostileStrings = []struct {
+	name, text, visible string
+}{
+	{"window title", "evil.exe\x1b]0;Administrator: cmd\x07", `\x1b]0;Administrator: cmd\x07`},
+	{"cursor movement", "svc.exe\x1b[2J\x1b[1;1H\x1b[8mhidden", `\x1b[2J\x1b[1;1H\x1b[8m`},
+	{"carriage return", "backup.exe\rC:\\Windows\\system32\\svchost.exe", `backup.exe\rC:`},
+	{"C1 CSI", "agent\u009b31mred", `\u009b31m`},
+	{"bidi override", "invoice\u202efdp.exe", `invoice\u202efdp.exe`},
+	{"invalid UTF-8", "name\xff\xfe", `name\xff\xfe`},
+}
+
+// checkInert fails when text holds a control character, DEL, C1 control,
+// bidi override or invalid UTF-8. Newlines are allowed unless oneLine.
+func checkInert(t *testing.T, text string, oneLine bool) {
+	t.Helper()
+	if !utf8.ValidString(text) {
+		t.Errorf("invalid UTF-8 in %q", text)
+		return
+	}
+	for _, r := range text {
+		if r == '\n' && !oneLine {
+			continue
+		}
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r <= 0x9f) || (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069) {
+			t.Errorf("control character %U reached the terminal in %q", r, text)
+			return
+		}
+	}
+}
+
+func TestSanitizeEscapesHostileText(t *testing.T) {
+	for _, hostile := range hostileStrings {
+		t.Run(hostile.name, func(t *testing.T) {
+			for _, text := range []string{Sanitize(hostile.text), SanitizeLine(hostile.text)} {
+				checkInert(t, text, true)
+				if !strings.Contains(text, hostile.visible) {
+					t.Errorf("escaped text %q does not show %q", text, hostile.visible)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeKeepsLayout(t *testing.T) {
+	if got := Sanitize("first line\n\tsecond line"); got != "first line\n\tsecond line" {
+		t.Errorf("newlines and tabs of safe text were changed: %q", got)
+	}
+	long := Sanitize(strings.Repeat("A", MaxDisplayLine+500) + "\nnext")
+	if !strings.Contains(long, "(500 characters cut)\nnext") {
+		t.Errorf("long line not cut at %d characters", MaxDisplayLine)
+	}
+}
+
+func TestTableEscapesCells(t *testing.T) {
+	table := NewTable(Column{Header: "Name"}, Column{Header: "Command Line"})
+	for _, hostile := range hostileStrings {
+		table.AddRow(hostile.text, hostile.text)
+	}
+	var rendered bytes.Buffer
+	if err := table.Render(&rendered); err != nil {
+		t.Fatal(err)
+	}
+	checkInert(t, rendered.String(), false)
+}
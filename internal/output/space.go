@@ -0,0 +1,82 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/redtriage/redtriage/utils"
+)
+
+// LowSpaceThreshold is the free space below which a reports directory is
+// reported as nearly full
+const LowSpaceThreshold = 256 << 20
+
+// ErrReadOnly is returned by writes through a read-only ReportsManager
+var ErrReadOnly = errors.New("reports directory is read-only")
+
+// DirCheck is the outcome of checking whether a reports directory can take
+// new reports
+type DirCheck struct {
+	Dir       string
+	Writable  bool
+	Problem   string // why the directory cannot be written, when it cannot
+	FreeBytes int64
+	FreeKnown bool
+}
+
+// LowSpace reports whether the directory's filesystem is nearly full
+func (c DirCheck) LowSpace() bool {
+	return c.FreeKnown && c.FreeBytes < LowSpaceThreshold
+}
+
+// CheckDirectory creates dir when missing and writes and removes a probe
+// file in it, so read-only mounts, missing permissions and full
+// filesystems are found before a command is halfway through its output
+func CheckDirectory(dir string) DirCheck {
+	check := DirCheck{Dir: dir}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Problem = describeWriteError(err)
+		return check
+	}
+	if free, err := utils.GetFreeDiskSpace(dir); err == nil {
+		check.FreeBytes, check.FreeKnown = free, true
+	}
+
+	probe, err := os.CreateTemp(dir, ".redtriage-probe-*")
+	if err == nil {
+		_, err = probe.Write([]byte("probe"))
+		if closeErr := probe.Close(); err == nil {
+			err = closeErr
+		}
+		os.Remove(probe.Name())
+	}
+	if err != nil {
+		check.Problem = describeWriteError(err)
+		return check
+	}
+	check.Writable = true
+	return check
+}
+
+// describeWriteError explains a failed write in terms of its likely cause
+func describeWriteError(err error) string {
+	switch {
+	case IsDiskFull(err):
+		return fmt.Sprintf("the filesystem is full or over quota (%v)", err)
+	case IsReadOnly(err):
+		return fmt.Sprintf("the filesystem is read-only or permission is denied (%v)", err)
+	default:
+		return err.Error()
+	}
+}
+
+// IsReadOnly reports whether err means the target cannot be written at all:
+// a read-only filesystem, missing permission, or a read-only manager
+func IsReadOnly(err error) bool {
+	return errors.Is(err, ErrReadOnly) || errors.Is(err, os.ErrPermission) || isReadOnlyFS(err)
+}
+
+// SpillFunc is asked for another reports directory when a write fails
+// because the filesystem is full. It returns "" to give up.
+type SpillFunc func(failedPath string, err error) string
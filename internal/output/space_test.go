@@ -0,0 +1,148 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// constrainedFS stands in for a reports filesystem with little space left:
+// writes succeed until the capacity is used up and then fail the way a full
+// disk does
+type constrainedFS struct {
+	mu       sync.Mutex
+	capacity int
+	used     int
+}
+
+func (c *constrainedFS) write(path string, data []byte, perm os.FileMode) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.used+len(data) > c.capacity {
+		return &os.PathError{Op: "write", Path: path, Err: diskFullErr}
+	}
+	c.used += len(data)
+	return WriteFileAtomic(path, data, perm)
+}
+
+// countFiles counts the regular files below dir
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+	count := 0
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestCheckDirectoryBelowAFile(t *testing.T) {
+	// A reports directory below a regular file cannot be created, even by root
+	blocker := filepath.Join(t.TempDir(), "not-a-directory")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if check := CheckDirectory(filepath.Join(blocker, "reports")); check.Writable || check.Problem == "" {
+		t.Error("reports directory below a file was reported writable")
+	}
+}
+
+func TestReadOnlyReportsManager(t *testing.T) {
+	dir := t.TempDir()
+	rm, err := NewReportsManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rm.SaveCollectionReport([]byte(`{"host":"TEST-WS01"}`), "collection-RT-TEST.json"); err != nil {
+		t.Fatal(err)
+	}
+	before := countFiles(t, dir)
+
+	readOnly := NewReadOnlyReportsManager(dir)
+	if listed, err := readOnly.ListReports("collection"); err != nil || len(listed) != 1 {
+		t.Errorf("read-only manager listed %d collection reports (%v), want 1", len(listed), err)
+	}
+	if matches, err := readOnly.SearchReports("collection", "TEST-WS01"); err != nil || len(matches) == 0 {
+		t.Errorf("read-only manager did not search reports: %v", err)
+	}
+	writes := map[string]error{}
+	_, writes["save"] = readOnly.SaveCollectionReport([]byte("{}"), "")
+	_, writes["stream"] = readOnly.StreamCollectionReport("", func(io.Writer) error { return nil })
+	writes["lock"] = readOnly.WithLock(func() error { return nil })
+	for name, err := range writes {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("read-only %s returned %v, want ErrReadOnly", name, err)
+		}
+	}
+	if after := countFiles(t, dir); after != before {
+		t.Errorf("read-only manager changed the directory: %d files before, %d after", before, after)
+	}
+}
+
+func TestSavesSpillFromAFullFilesystem(t *testing.T) {
+	base := t.TempDir()
+	full, err := NewReportsManager(filepath.Join(base, "reports"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	full.SetFileWriter((&constrainedFS{capacity: 64}).write)
+
+	// The first save that does not fit asks for a spill directory once,
+	// later saves go there without asking
+	spillDir := filepath.Join(base, "spill")
+	asked := 0
+	full.SetSpill(func(failedPath string, cause error) string {
+		asked++
+		if !IsDiskFull(cause) {
+			return ""
+		}
+		return spillDir
+	})
+
+	first, err := full.SaveCollectionReport([]byte(`{"fits":true}`), "collection-RT-FIRST.json")
+	if err != nil || filepath.Dir(first) != full.GetCollectionReportsDirectory() {
+		t.Fatalf("save within capacity went to %s (%v)", first, err)
+	}
+	var spilled []string
+	for i := 0; i < 3; i++ {
+		path, err := full.SaveCollectionReport(make([]byte, 100), fmt.Sprintf("collection-RT-SPILL%d.json", i))
+		if err != nil {
+			t.Fatalf("save on a full filesystem was lost: %v", err)
+		}
+		spilled = append(spilled, path)
+	}
+	if want := filepath.Join(spillDir, "collection", "collection-RT-SPILL0.json"); spilled[0] != want {
+		t.Errorf("spilled report saved to %s, want %s", spilled[0], want)
+	}
+	if asked != 1 {
+		t.Errorf("spill directory asked for %d times, want 1", asked)
+	}
+	original := filepath.Join(full.GetCollectionReportsDirectory(), "collection-RT-SPILL2.json")
+	if full.Resolve(original) != spilled[2] {
+		t.Error("spilled report not found from its original path")
+	}
+}
+
+func TestDeclinedSpillReturnsDiskFull(t *testing.T) {
+	rm, err := NewReportsManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.SetFileWriter((&constrainedFS{}).write)
+	rm.SetSpill(func(string, error) string { return "" })
+	if _, err := rm.SaveLog([]byte("log"), ""); !IsDiskFull(err) {
+		t.Errorf("declined spill returned %v, want a disk full error", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package output
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// IsDiskFull reports whether err means the filesystem is out of space or
+// the user's quota is exhausted
+func IsDiskFull(err error) bool {
+	return errors.Is(err, unix.ENOSPC) || errors.Is(err, unix.EDQUOT)
+}
+
+// isReadOnlyFS reports whether err comes from a read-only filesystem
+func isReadOnlyFS(err error) bool {
+	return errors.Is(err, unix.EROFS)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package output
+
+import "golang.org/x/sys/unix"
+
+// diskFullErr is the error a write to a full filesystem fails with
+var diskFullErr error = unix.ENOSPC
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package output
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsDiskFull reports whether err means the volume is out of space or the
+// user's quota is exhausted
+func IsDiskFull(err error) bool {
+	return errors.Is(err, windows.ERROR_DISK_FULL) || errors.Is(err, windows.ERROR_HANDLE_DISK_FULL) ||
+		errors.Is(err, windows.ERROR_DISK_QUOTA_EXCEEDED)
+}
+
+// isReadOnlyFS reports whether err comes from write-protected media
+func isReadOnlyFS(err error) bool {
+	return errors.Is(err, windows.ERROR_WRITE_PROTECT)
+}
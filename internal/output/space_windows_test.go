@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package output
+
+import "golang.org/x/sys/windows"
+
+// diskFullErr is the error a write to a full volume fails with
+var diskFullErr error = windows.ERROR_DISK_FULL
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/redtriage/redtriage/internal/heapsample"
+	"github.com/redtriage/redtriage/internal/jsonstream"
+)
+
+// Size of the collections the tests stream: TestStreamCollectionReport
+// checks a small one reads back as valid JSON, BenchmarkStreamCollectionReport
+// writes about 120 MB, more than the heap growth allowed while writing it
+const (
+	streamTestArtifacts          = 3
+	streamBenchArtifacts         = 400
+	streamTestRecordsPerArtifact = 1000
+	streamTestMaxHeapMB          = 64
+)
+
+func TestStreamCollectionReport(t *testing.T) {
+	rm, err := NewReportsManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := streamTestCollection(rm, streamTestArtifacts)
+	if err != nil {
+		t.Fatalf("StreamCollectionReport: %v", err)
+	}
+
+	artifacts, records := readStreamedCollection(t, path)
+	if artifacts != streamTestArtifacts || records != streamTestArtifacts*streamTestRecordsPerArtifact {
+		t.Errorf("streamed collection reads back with %d artifacts and %d records, want %d and %d",
+			artifacts, records, streamTestArtifacts, streamTestArtifacts*streamTestRecordsPerArtifact)
+	}
+}
+
+func BenchmarkStreamCollectionReport(b *testing.B) {
+	rm, err := NewReportsManager(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	sampler := heapsample.Start()
+	var path string
+	for i := 0; i < b.N; i++ {
+		if path, err = streamTestCollection(rm, streamBenchArtifacts); err != nil {
+			b.Fatalf("StreamCollectionReport: %v", err)
+		}
+	}
+	peak := sampler.Stop()
+	b.StopTimer()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	if peakMB := peak >> 20; peakMB > streamTestMaxHeapMB {
+		b.Errorf("heap grew by %d MB while streaming a %d MB collection, more than %d MB", peakMB, info.Size()>>20, streamTestMaxHeapMB)
+	}
+}
+
+// streamTestCollection streams a collection of artifacts artifacts into a
+// collection report and returns its path
+func streamTestCollection(rm *ReportsManager, artifacts int) (string, error) {
+	return rm.StreamCollectionReport("", func(w io.Writer) error {
+		report := jsonstream.NewObjectWriter(w)
+		report.Field("collection_id", "RT-TEST-STREAM")
+		report.Field("hostname", "TEST-WS01")
+		report.Begin("artifacts")
+		for i := 0; i < artifacts; i++ {
+			if err := report.Field(fmt.Sprintf("artifact_%03d", i), streamTestArtifact(i)); err != nil {
+				return err
+			}
+		}
+		report.End()
+		report.Field("status", "completed")
+		return report.Close()
+	})
+}
+
+// streamTestArtifact builds the record list of one artifact
+func streamTestArtifact(index int) map[string]interface{} {
+	records := make([]map[string]interface{}, streamTestRecordsPerArtifact)
+	for i := range records {
+		records[i] = map[string]interface{}{
+			"name":         fmt.Sprintf("process-%d-%d.exe", index, i),
+			"pid":          index*streamTestRecordsPerArtifact + i,
+			"path":         fmt.Sprintf(`C:\Program Files\Vendor %d\bin\process-%d.exe`, index, i),
+			"command_line": fmt.Sprintf(`"C:\Program Files\Vendor %d\bin\process-%d.exe" --service --instance %d`, index, i, i),
+			"user":         `TEST-WS01\analyst`,
+		}
+	}
+	return map[string]interface{}{"count": len(records), "records": records}
+}
+
+// readStreamedCollection decodes the report one artifact at a time and
+// counts the artifacts and their records
+func readStreamedCollection(t *testing.T, path string) (int, int) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		t.Fatalf("streamed collection is not a JSON object: %v", err)
+	}
+
+	artifacts, records := 0, 0
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("streamed collection is not valid JSON: %v", err)
+		}
+		if key != "artifacts" {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				t.Fatalf("streamed collection field %v is not valid JSON: %v", key, err)
+			}
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			t.Fatalf("streamed collection artifacts are not a JSON object: %v", err)
+		}
+		for decoder.More() {
+			if _, err := decoder.Token(); err != nil {
+				t.Fatalf("streamed collection is not valid JSON: %v", err)
+			}
+			var artifact struct {
+				Records []json.RawMessage `json:"records"`
+			}
+			if err := decoder.Decode(&artifact); err != nil {
+				t.Fatalf("streamed artifact %d is not valid JSON: %v", artifacts+1, err)
+			}
+			artifacts++
+			records += len(artifact.Records)
+		}
+		if _, err := decoder.Token(); err != nil {
+			t.Fatalf("streamed collection is not valid JSON: %v", err)
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		t.Fatalf("streamed collection is not valid JSON: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		t.Fatal("streamed collection has data after the JSON object")
+	}
+	return artifacts, records
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// Column is one column of a Table
+type Column struct {
+	Header string
+	// Max truncates longer cells with "..."; zero leaves them whole
+	Max int
+	// Color picks the color of a cell from its value, nil for none
+	Color func(value string) *color.Color
+}
+
+// Table renders rows as aligned columns for a terminal. Cells are
+// sanitized, so they may hold untrusted text. Colors follow color.NoColor,
+// which is set when stdout is not a terminal.
+type Table struct {
+	columns []Column
+	rows    [][]string
+}
+
+// NewTable creates a table with the given columns
+func NewTable(columns ...Column) *Table {
+	return &Table{columns: columns}
+}
+
+// AddRow appends a row; missing cells are left empty and extra cells dropped
+func (t *Table) AddRow(cells ...string) {
+	row := make([]string, len(t.columns))
+	for i := range row {
+		if i < len(cells) {
+			row[i] = truncateCell(cells[i], t.columns[i].Max)
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render writes the header, a rule under it and the rows. Each column is as
+// wide as its widest cell; the last column is not padded.
+func (t *Table) Render(w io.Writer) error {
+	widths := make([]int, len(t.columns))
+	for i, column := range t.columns {
+		widths[i] = utf8.RuneCountInString(column.Header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+	header := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		header[i] = column.Header
+	}
+	t.writeRow(&b, header, widths, false)
+	total := 0
+	for _, width := range widths {
+		total += width + 2
+	}
+	b.WriteString(strings.Repeat("─", total-2) + "\n")
+	for _, row := range t.rows {
+		t.writeRow(&b, row, widths, true)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeRow pads the cells to the column widths, coloring them after padding
+// so escape sequences do not count towards the width
+func (t *Table) writeRow(b *strings.Builder, cells []string, widths []int, colored bool) {
+	for i, cell := range cells {
+		padded := cell
+		if i < len(cells)-1 {
+			padded += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)) + "  "
+		}
+		if colored && t.columns[i].Color != nil {
+			if c := t.columns[i].Color(cell); c != nil {
+				padded = c.Sprint(cell) + padded[len(cell):]
+			}
+		}
+		b.WriteString(padded)
+	}
+	b.WriteString("\n")
+}
+
+// truncateCell puts a cell on one line, makes it safe to print and shortens
+// it to max characters, ending it with "..."
+func truncateCell(cell string, max int) string {
+	cell = SanitizeLine(strings.Join(strings.Fields(cell), " "))
+	if max <= 3 || utf8.RuneCountInString(cell) <= max {
+		return cell
+	}
+	runes := []rune(cell)
+	return string(runes[:max-3]) + "..."
+}
+
+// SeverityColor colors severities and priorities from critical (bold red) to
+// low (green)
+func SeverityColor(severity string) *color.Color {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return color.New(color.FgRed, color.Bold)
+	case "high":
+		return color.New(color.FgRed)
+	case "medium":
+		return color.New(color.FgYellow)
+	case "low":
+		return color.New(color.FgGreen)
+	}
+	return nil
+}
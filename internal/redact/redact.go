@@ -0,0 +1,178 @@
+// Package redact masks sensitive values, such as account names, addresses
+// and secrets, in records and text before they are shared
+package redact
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltIn is the source of the default ruleset
+const BuiltIn = "built-in"
+
+// Rule masks the matches of a pattern in any string value, or the whole
+// value of the named fields. Replacement may use the pattern's groups as
+// ${1}; it defaults to [REDACTED:<name>].
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Pattern     string   `yaml:"pattern"`
+	Fields      []string `yaml:"fields"` // matched case-insensitively
+	Replacement string   `yaml:"replacement"`
+
+	re     *regexp.Regexp
+	fields map[string]bool
+}
+
+// Ruleset is a compiled list of redaction rules
+type Ruleset struct {
+	Source string // BuiltIn or the rules file
+	rules  []Rule
+}
+
+// defaultRules mask accounts, hosts, addresses, SIDs, profile paths and
+// secrets passed as key=value
+var defaultRules = []Rule{
+	{Name: "email", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+	{Name: "ipv4", Pattern: `\b(?:\d{1,3}\.){3}\d{1,3}\b`},
+	{Name: "sid", Pattern: `S-1-5-21(?:-\d+){3,4}`},
+	{Name: "profile", Pattern: `(?i)([\\/](?:Users|home)[\\/])[^\\/\s"']+`, Replacement: "${1}[REDACTED:profile]"},
+	{Name: "secret", Pattern: `(?i)\b((?:password|passwd|pwd|secret|token|api[_-]?key)\s*[=:]\s*)\S+`, Replacement: "${1}[REDACTED:secret]"},
+	{Name: "account", Fields: []string{"user", "username", "user_name", "account", "account_name", "owner", "targetusername", "subjectusername", "client_user", "created_by"}},
+	{Name: "host", Fields: []string{"hostname", "computer", "host"}},
+}
+
+// Default returns the built-in ruleset
+func Default() *Ruleset {
+	ruleset, err := compile(BuiltIn, defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in redaction rule: %v", err))
+	}
+	return ruleset
+}
+
+// Load reads a ruleset from a YAML file with a list of rules under "rules".
+// The file replaces the built-in rules rather than adding to them.
+func Load(path string) (*Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, rterrors.NotFoundf("redaction rules file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, rterrors.Validationf("invalid redaction rules in %s: %v", path, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, rterrors.Validationf("redaction rules file %s has no rules", path)
+	}
+	ruleset, err := compile(path, file.Rules)
+	if err != nil {
+		return nil, rterrors.Validationf("invalid redaction rules in %s: %v", path, err)
+	}
+	return ruleset, nil
+}
+
+// compile checks and compiles the rules
+func compile(source string, rules []Rule) (*Ruleset, error) {
+	ruleset := &Ruleset{Source: source}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if rule.Pattern == "" && len(rule.Fields) == 0 {
+			return nil, fmt.Errorf("rule %s has neither a pattern nor fields", rule.Name)
+		}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+			}
+			rule.re = re
+		}
+		if len(rule.Fields) > 0 {
+			rule.fields = make(map[string]bool, len(rule.Fields))
+			for _, field := range rule.Fields {
+				rule.fields[strings.ToLower(field)] = true
+			}
+		}
+		if rule.Replacement == "" {
+			rule.Replacement = "[REDACTED:" + rule.Name + "]"
+		}
+		ruleset.rules = append(ruleset.rules, rule)
+	}
+	return ruleset, nil
+}
+
+// Len returns the number of rules
+func (r *Ruleset) Len() int {
+	return len(r.rules)
+}
+
+// Names returns the names of the rules, in order
+func (r *Ruleset) Names() []string {
+	names := make([]string, len(r.rules))
+	for i, rule := range r.rules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+// Text masks the pattern matches in s
+func (r *Ruleset) Text(s string) string {
+	for _, rule := range r.rules {
+		if rule.re != nil {
+			s = rule.re.ReplaceAllString(s, rule.Replacement)
+		}
+	}
+	return s
+}
+
+// Record returns a redacted copy of a record
+func (r *Ruleset) Record(record map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		redacted[key] = r.field(key, value)
+	}
+	return redacted
+}
+
+// Value returns a redacted copy of a decoded JSON value
+func (r *Ruleset) Value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Text(v)
+	case map[string]interface{}:
+		return r.Record(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.Value(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// field redacts the value of a record field, masking it whole when a rule
+// names the field
+func (r *Ruleset) field(key string, value interface{}) interface{} {
+	if value != nil && value != "" {
+		lower := strings.ToLower(key)
+		for _, rule := range r.rules {
+			if rule.fields[lower] {
+				return rule.Replacement
+			}
+		}
+	}
+	return r.Value(value)
+}
@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultRulesMaskSensitiveValues(t *testing.T) {
+	record := map[string]interface{}{
+		"name":         "powershell.exe",
+		"user":         `CORP\alice`,
+		"command_line": `powershell -File C:\Users\alice\run.ps1 -password=Hunter2 -to alice@corp.example`,
+		"sid":          "S-1-5-21-1004336348-1177238915-682003330-1001",
+		"remote":       []interface{}{"10.0.0.5"},
+	}
+	redacted := Default().Record(record)
+
+	want := map[string]interface{}{
+		"name":         "powershell.exe",
+		"user":         "[REDACTED:account]",
+		"command_line": `powershell -File C:\Users\[REDACTED:profile]\run.ps1 -password=[REDACTED:secret] -to [REDACTED:email]`,
+		"sid":          "[REDACTED:sid]",
+	}
+	for key, value := range want {
+		if redacted[key] != value {
+			t.Errorf("%s redacted as %q, want %q", key, redacted[key], value)
+		}
+	}
+	if remote, _ := redacted["remote"].([]interface{}); len(remote) != 1 || remote[0] != "[REDACTED:ipv4]" {
+		t.Errorf("remote redacted as %v", redacted["remote"])
+	}
+	if record["user"] != `CORP\alice` {
+		t.Error("redaction modified the original record")
+	}
+}
+
+func TestLoadReplacesTheBuiltInRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redaction-rules.yml")
+	rules := "rules:\n  - name: ticket\n    pattern: 'INC-\\d+'\n  - name: account\n    fields: [user]\n    replacement: '<user>'\n"
+	if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	record := custom.Record(map[string]interface{}{"user": "alice", "note": "INC-1234 from 10.0.0.5"})
+	if custom.Len() != 2 || record["user"] != "<user>" || record["note"] != "[REDACTED:ticket] from 10.0.0.5" {
+		t.Errorf("rules file redacted the record as %v", record)
+	}
+}
+
+func TestLoadRefusesInvalidRules(t *testing.T) {
+	for name, rules := range map[string]string{
+		"bad pattern": "rules:\n  - name: broken\n    pattern: '('\n",
+		"no name":     "rules:\n  - pattern: 'x'\n",
+		"no match":    "rules:\n  - name: empty\n",
+		"no rules":    "rules: []\n",
+	} {
+		path := filepath.Join(t.TempDir(), "rules.yml")
+		if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: rules file was accepted", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package rterrors defines the error categories RedTriage commands report and
+// the process exit code each category maps to.
+//
+// Exit code contract:
+//
+//	0  success
+//	1  general failure
+//	2  validation: invalid flags, arguments or input
+//	3  not found: a file, collection, incident or rule does not exist
+//	4  permission: access denied or elevation required
+//	5  external tool: a helper program (wevtutil, auditpol, ...) failed
+//	6  integrity: checksum or manifest verification failed
+package rterrors
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Category classifies why a command failed
+type Category int
+
+const (
+	General Category = iota
+	Validation
+	NotFound
+	Permission
+	ExternalTool
+	Integrity
+)
+
+// String returns the category name shown to users
+func (c Category) String() string {
+	switch c {
+	case Validation:
+		return "validation"
+	case NotFound:
+		return "not-found"
+	case Permission:
+		return "permission"
+	case ExternalTool:
+		return "external-tool"
+	case Integrity:
+		return "integrity"
+	default:
+		return "general"
+	}
+}
+
+// ExitCode returns the process exit code for the category
+func (c Category) ExitCode() int {
+	switch c {
+	case Validation:
+		return 2
+	case NotFound:
+		return 3
+	case Permission:
+		return 4
+	case ExternalTool:
+		return 5
+	case Integrity:
+		return 6
+	default:
+		return 1
+	}
+}
+
+// Error is an error tagged with a category
+type Error struct {
+	Category Category
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap tags err with a category. A nil err stays nil.
+func Wrap(category Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Category: category, Err: err}
+}
+
+// Validationf returns a validation error; format verbs follow fmt.Errorf
+func Validationf(format string, args ...interface{}) error {
+	return Wrap(Validation, fmt.Errorf(format, args...))
+}
+
+// NotFoundf returns a not-found error; format verbs follow fmt.Errorf
+func NotFoundf(format string, args ...interface{}) error {
+	return Wrap(NotFound, fmt.Errorf(format, args...))
+}
+
+// Permissionf returns a permission error; format verbs follow fmt.Errorf
+func Permissionf(format string, args ...interface{}) error {
+	return Wrap(Permission, fmt.Errorf(format, args...))
+}
+
+// ExternalToolf returns an external-tool error; format verbs follow fmt.Errorf
+func ExternalToolf(format string, args ...interface{}) error {
+	return Wrap(ExternalTool, fmt.Errorf(format, args...))
+}
+
+// Integrityf returns an integrity error; format verbs follow fmt.Errorf
+func Integrityf(format string, args ...interface{}) error {
+	return Wrap(Integrity, fmt.Errorf(format, args...))
+}
+
+// CategoryOf returns the category of err. Errors that were not tagged are
+// classified from the standard library errors they wrap.
+func CategoryOf(err error) Category {
+	if err == nil {
+		return General
+	}
+
+	var tagged *Error
+	if errors.As(err, &tagged) {
+		return tagged.Category
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return NotFound
+	case errors.Is(err, os.ErrPermission):
+		return Permission
+	case errors.Is(err, exec.ErrNotFound), errors.As(err, &exitErr):
+		return ExternalTool
+	}
+
+	return General
+}
+
+// ExitCode returns the process exit code for err, 0 when err is nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return CategoryOf(err).ExitCode()
+}
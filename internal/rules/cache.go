@@ -0,0 +1,377 @@
+// Package rules loads, caches and validates the Sigma rules used by findings
+// analysis
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir is the directory Sigma rules are loaded from
+const DefaultDir = "sigma-rules"
+
+// SigmaRule is the part of a Sigma rule findings analysis uses
+type SigmaRule struct {
+	Title       string                 `yaml:"title"`
+	ID          string                 `yaml:"id"`
+	Description string                 `yaml:"description"`
+	Level       string                 `yaml:"level"`
+	LogSource   LogSource              `yaml:"logsource"`
+	Detection   map[string]interface{} `yaml:"detection"`
+	Tags        []string               `yaml:"tags"`
+
+	// Source is SourceExternal or SourceEmbedded, set when the rule is loaded
+	Source string `yaml:"-" json:"-"`
+}
+
+// ruleCacheVersion is bumped when the cached rule format changes so stale
+// disk caches are discarded
+const ruleCacheVersion = 2
+
+// ruleCacheEntry is the parse result of a single rule file. Files that failed
+// to parse are cached too so their warning is only reported once.
+type ruleCacheEntry struct {
+	ModTime   time.Time     `json:"mod_time"`
+	Size      int64         `json:"size"`
+	Hash      string        `json:"hash"`
+	Rule      *SigmaRule    `json:"rule,omitempty"`
+	ParseErr  string        `json:"parse_error,omitempty"`
+	ParseTime time.Duration `json:"parse_time"`
+}
+
+// ruleCacheFile is the on-disk form of a rule cache
+type ruleCacheFile struct {
+	Version int                        `json:"version"`
+	Dir     string                     `json:"dir"`
+	DirHash string                     `json:"dir_hash"`
+	Entries map[string]*ruleCacheEntry `json:"entries"`
+}
+
+// CacheStats reports how a rule load used the cache
+type CacheStats struct {
+	Hits      int
+	Misses    int
+	Removed   int
+	Failed    int
+	ParseTime time.Duration // time spent parsing changed files
+	Saved     time.Duration // parse time avoided by cache hits
+	FromDisk  bool
+}
+
+// Cache keeps parsed Sigma rules between findings runs. Files are
+// revalidated by mtime and size, then by content hash, and only files that
+// changed are parsed again. The cache is persisted next to the session
+// metadata so separate invocations reuse it.
+type Cache struct {
+	mu       sync.Mutex
+	cacheDir string
+	dirs     map[string]*ruleCacheFile
+}
+
+// NewCache creates a rule cache persisted under cacheDir. An empty
+// cacheDir keeps the cache in memory only.
+func NewCache(cacheDir string) *Cache {
+	return &Cache{
+		cacheDir: cacheDir,
+		dirs:     make(map[string]*ruleCacheFile),
+	}
+}
+
+// Load returns the rules in dir, parsing only files that changed since the
+// last load. Parse warnings are returned for newly parsed files only.
+func (rc *Cache) Load(dir string) ([]SigmaRule, []string, CacheStats, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var stats CacheStats
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, stats, fmt.Errorf("failed to read rules directory: %w", err)
+	}
+
+	cached := rc.dirs[dir]
+	if cached == nil {
+		cached = rc.readDisk(dir)
+		stats.FromDisk = cached != nil
+	}
+	if cached == nil {
+		cached = &ruleCacheFile{Version: ruleCacheVersion, Dir: dir, Entries: make(map[string]*ruleCacheEntry)}
+	}
+
+	var warnings []string
+	entries := make(map[string]*ruleCacheEntry)
+	for _, file := range files {
+		if file.IsDir() || !IsRuleFile(file.Name()) {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not read rule file %s: %v", file.Name(), err))
+			continue
+		}
+
+		previous := cached.Entries[file.Name()]
+		if previous != nil && previous.ModTime.Equal(info.ModTime()) && previous.Size == info.Size() {
+			entries[file.Name()] = previous
+			stats.Hits++
+			stats.Saved += previous.ParseTime
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not read rule file %s: %v", file.Name(), err))
+			continue
+		}
+		hash := sha256.Sum256(data)
+		hashHex := hex.EncodeToString(hash[:])
+
+		// Touched but unchanged files keep their parse result
+		if previous != nil && previous.Hash == hashHex {
+			previous.ModTime = info.ModTime()
+			previous.Size = info.Size()
+			entries[file.Name()] = previous
+			stats.Hits++
+			stats.Saved += previous.ParseTime
+			continue
+		}
+
+		entry := parseRuleFile(data)
+		entry.ModTime = info.ModTime()
+		entry.Size = info.Size()
+		entry.Hash = hashHex
+		entries[file.Name()] = entry
+
+		stats.Misses++
+		stats.ParseTime += entry.ParseTime
+		if entry.ParseErr != "" {
+			warnings = append(warnings, fmt.Sprintf("Could not parse rule file %s: %s", file.Name(), entry.ParseErr))
+		}
+	}
+
+	for name := range cached.Entries {
+		if _, ok := entries[name]; !ok {
+			stats.Removed++
+		}
+	}
+
+	dirHash := ruleDirHash(entries)
+	changed := dirHash != cached.DirHash
+	cached.Entries = entries
+	cached.DirHash = dirHash
+	rc.dirs[dir] = cached
+
+	if changed {
+		if err := rc.writeDisk(cached); err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not save rule cache: %v", err))
+		}
+	}
+
+	var rules []SigmaRule
+	for _, name := range sortedNames(entries) {
+		if entry := entries[name]; entry.Rule != nil {
+			rules = append(rules, *entry.Rule)
+		} else {
+			stats.Failed++
+		}
+	}
+
+	return rules, warnings, stats, nil
+}
+
+// Invalidate drops the cached rules for dir, in memory and on disk, so the
+// next load parses every file again
+func (rc *Cache) Invalidate(dir string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delete(rc.dirs, dir)
+	if rc.cacheDir == "" {
+		return nil
+	}
+	if err := os.Remove(rc.diskPath(dir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove rule cache: %w", err)
+	}
+	return nil
+}
+
+// RuleChanges describes how the rules of a directory changed between the
+// cached load and a reload. Rules are named by title and file.
+type RuleChanges struct {
+	Added   []string
+	Removed []string
+	Changed []string
+	Errors  []string // parse errors of the reloaded files
+	Initial bool     // no rules were cached before the reload
+}
+
+// Reload drops the cached rules for dir and parses every file again,
+// reporting the rules added, removed or changed since the cached load
+func (rc *Cache) Reload(dir string) ([]SigmaRule, RuleChanges, error) {
+	previous := rc.entries(dir)
+	if err := rc.Invalidate(dir); err != nil {
+		return nil, RuleChanges{}, err
+	}
+
+	rules, warnings, _, err := rc.Load(dir)
+	if err != nil {
+		return nil, RuleChanges{}, err
+	}
+	current := rc.entries(dir)
+
+	changes := RuleChanges{Errors: warnings, Initial: previous == nil}
+	if previous == nil {
+		return rules, changes, nil
+	}
+	for _, name := range sortedNames(current) {
+		before, ok := previous[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, ruleLabel(name, current[name]))
+		case before.Hash != current[name].Hash:
+			changes.Changed = append(changes.Changed, ruleLabel(name, current[name]))
+		}
+	}
+	for _, name := range sortedNames(previous) {
+		if _, ok := current[name]; !ok {
+			changes.Removed = append(changes.Removed, ruleLabel(name, previous[name]))
+		}
+	}
+	return rules, changes, nil
+}
+
+// entries returns the cached entries for dir, from memory or disk, or nil
+// when nothing is cached
+func (rc *Cache) entries(dir string) map[string]*ruleCacheEntry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	cached := rc.dirs[dir]
+	if cached == nil {
+		cached = rc.readDisk(dir)
+	}
+	if cached == nil {
+		return nil
+	}
+	entries := make(map[string]*ruleCacheEntry, len(cached.Entries))
+	for name, entry := range cached.Entries {
+		entries[name] = entry
+	}
+	return entries
+}
+
+// ruleLabel names a rule file by the title of its rule, if it parsed
+func ruleLabel(name string, entry *ruleCacheEntry) string {
+	if entry.Rule != nil && entry.Rule.Title != "" {
+		return fmt.Sprintf("%s (%s)", entry.Rule.Title, name)
+	}
+	return name
+}
+
+// readDisk loads the persisted cache for dir, nil when missing or stale
+func (rc *Cache) readDisk(dir string) *ruleCacheFile {
+	if rc.cacheDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rc.diskPath(dir))
+	if err != nil {
+		return nil
+	}
+
+	var cached ruleCacheFile
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Version != ruleCacheVersion || cached.Entries == nil {
+		return nil
+	}
+	return &cached
+}
+
+// writeDisk persists the cache for a rules directory
+func (rc *Cache) writeDisk(cached *ruleCacheFile) error {
+	if rc.cacheDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(rc.cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create rule cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule cache: %w", err)
+	}
+
+	// Written atomically: another process may be loading the same cache
+	return output.WriteFileAtomic(rc.diskPath(cached.Dir), data, 0644)
+}
+
+// diskPath returns the cache file for a rules directory
+func (rc *Cache) diskPath(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	hash := sha256.Sum256([]byte(dir))
+	return filepath.Join(rc.cacheDir, "rules-"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// Parse parses a single Sigma rule file
+func Parse(data []byte) (*SigmaRule, error) {
+	entry := parseRuleFile(data)
+	if entry.ParseErr != "" {
+		return nil, fmt.Errorf("%s", entry.ParseErr)
+	}
+	return entry.Rule, nil
+}
+
+// parseRuleFile parses a Sigma rule and records how long it took
+func parseRuleFile(data []byte) *ruleCacheEntry {
+	start := time.Now()
+
+	var rule SigmaRule
+	err := yaml.Unmarshal(data, &rule)
+
+	entry := &ruleCacheEntry{ParseTime: time.Since(start)}
+	if err != nil {
+		entry.ParseErr = err.Error()
+	} else {
+		entry.Rule = &rule
+	}
+	return entry
+}
+
+// ruleDirHash hashes the content of every rule file in a directory
+func ruleDirHash(entries map[string]*ruleCacheEntry) string {
+	hash := sha256.New()
+	for _, name := range sortedNames(entries) {
+		fmt.Fprintf(hash, "%s\x00%s\n", name, entries[name].Hash)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// sortedNames returns the file names of cache entries in order
+func sortedNames(entries map[string]*ruleCacheEntry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRuleFile reports whether a file name is a Sigma rule
+func IsRuleFile(name string) bool {
+	return strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")
+}
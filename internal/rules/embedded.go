@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// Rule sources, shown by the rules listings
+const (
+	SourceExternal = "external"
+	SourceEmbedded = "embedded"
+)
+
+// EmbeddedDir names the built-in rule set where a directory is expected
+const EmbeddedDir = "embedded defaults"
+
+//go:embed defaults/*.yml
+var embeddedRules embed.FS
+
+// Embedded returns the curated default rules built into the binary. They
+// are used when no external rules directory is present, so findings work on
+// a fresh install.
+func Embedded() []SigmaRule {
+	names, err := fs.Glob(embeddedRules, "defaults/*.yml")
+	if err != nil {
+		return nil
+	}
+	sort.Strings(names)
+
+	var rules []SigmaRule
+	for _, name := range names {
+		data, err := embeddedRules.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		rule, err := Parse(data)
+		if err != nil {
+			continue
+		}
+		rule.Source = SourceEmbedded
+		rules = append(rules, *rule)
+	}
+	return rules
+}
+
+// LoadWithDefaults loads the rules in dir through the cache. When dir does
+// not exist or holds no rule files the embedded rules are returned instead;
+// external rules otherwise take precedence and the embedded set is not
+// mixed in. The returned source says which set was loaded.
+func (rc *Cache) LoadWithDefaults(dir string) ([]SigmaRule, []string, CacheStats, string, error) {
+	rules, warnings, stats, err := rc.Load(dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, stats, "", err
+		}
+		return Embedded(), nil, stats, SourceEmbedded, nil
+	}
+	if stats.Hits+stats.Misses == 0 {
+		return Embedded(), warnings, stats, SourceEmbedded, nil
+	}
+	for i := range rules {
+		rules[i].Source = SourceExternal
+	}
+	return rules, warnings, stats, SourceExternal, nil
+}
+
+// SourceLabel describes where a rule set was loaded from
+func SourceLabel(source, dir string) string {
+	if source == SourceEmbedded {
+		return fmt.Sprintf("%s, no rules in %s", EmbeddedDir, dir)
+	}
+	return dir
+}
@@ -0,0 +1,322 @@
+package rules
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldMapsDir is the subdirectory of a rules directory holding user field
+// mapping files, which extend the built-in mappings without a rebuild
+const FieldMapsDir = "field-mappings"
+
+// ProductRedTriage marks rules written against RedTriage's own record
+// fields, which need no mapping
+const ProductRedTriage = "redtriage"
+
+//go:embed fieldmaps/*.yml
+var embeddedFieldMaps embed.FS
+
+// LogSource is the logsource block of a Sigma rule
+type LogSource struct {
+	Category string `yaml:"category,omitempty" json:"category,omitempty"`
+	Product  string `yaml:"product,omitempty" json:"product,omitempty"`
+	Service  string `yaml:"service,omitempty" json:"service,omitempty"`
+}
+
+// String renders the logsource as category/product/service, leaving out
+// the parts that are not set
+func (ls LogSource) String() string {
+	var parts []string
+	for _, part := range []string{ls.Category, ls.Product, ls.Service} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "no logsource"
+	}
+	return strings.Join(parts, "/")
+}
+
+// RecordMapping translates the fields of one Sigma logsource to the records
+// of a collection artifact
+type RecordMapping struct {
+	Artifact string            `yaml:"artifact"` // collection artifact holding the records
+	Records  []string          `yaml:"records"`  // keys of the artifact's record lists
+	Fields   map[string]string `yaml:"fields"`   // Sigma field -> record field, optionally |host or |port
+}
+
+// FieldMap holds the field mappings by logsource key: a category, or a
+// category/product pair that takes precedence over it
+type FieldMap struct {
+	sources map[string]*RecordMapping
+	Files   []string // user mapping files loaded, in load order
+}
+
+// MappedField is one field of a rule selection translated to a record field
+type MappedField struct {
+	Key    string      // selection key as written in the rule, with modifiers
+	Target string      // record field, empty when the field cannot be evaluated
+	Want   interface{} // selection value, with modifiers turned into wildcards
+}
+
+// Value returns the record value the field reads, or nil when the record
+// does not have it
+func (f MappedField) Value(record map[string]interface{}) interface{} {
+	return RecordValue(record, f.Target)
+}
+
+// RecordValue reads a mapping target from a record. A target ending in
+// |host or |port takes that part of a host:port address.
+func RecordValue(record map[string]interface{}, target string) interface{} {
+	if target == "" {
+		return nil
+	}
+	field, part, _ := strings.Cut(target, "|")
+	value := record[field]
+	if part == "" || value == nil {
+		return value
+	}
+	address, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+	if part == "port" {
+		return port
+	}
+	return host
+}
+
+// wildcardModifiers turn a value into the wildcard pattern selection
+// matching understands
+var wildcardModifiers = map[string]func(string) string{
+	"contains":   func(v string) string { return "*" + v + "*" },
+	"startswith": func(v string) string { return v + "*" },
+	"endswith":   func(v string) string { return "*" + v },
+}
+
+// LoadFieldMap returns the built-in field mappings extended by the mapping
+// files in dir's FieldMapsDir. A user file replaces the artifact and record
+// lists of a logsource it names and adds to or overrides its fields.
+// Unreadable files are skipped with a warning.
+func LoadFieldMap(dir string) (*FieldMap, []string) {
+	m := &FieldMap{sources: make(map[string]*RecordMapping)}
+	names, _ := fs.Glob(embeddedFieldMaps, "fieldmaps/*.yml")
+	sort.Strings(names)
+	for _, name := range names {
+		if data, err := embeddedFieldMaps.ReadFile(name); err == nil {
+			m.merge(data)
+		}
+	}
+
+	if dir == "" {
+		return m, nil
+	}
+	var warnings []string
+	files, _ := os.ReadDir(filepath.Join(dir, FieldMapsDir))
+	for _, file := range files {
+		if file.IsDir() || !IsRuleFile(file.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, FieldMapsDir, file.Name())
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = m.merge(data)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Could not load field mapping %s: %v", file.Name(), err))
+			continue
+		}
+		m.Files = append(m.Files, path)
+	}
+	return m, warnings
+}
+
+// merge adds the mappings of one mapping file
+func (m *FieldMap) merge(data []byte) error {
+	var sources map[string]*RecordMapping
+	if err := yaml.Unmarshal(data, &sources); err != nil {
+		return err
+	}
+	for key, source := range sources {
+		if source == nil {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		existing := m.sources[key]
+		if existing == nil {
+			if source.Artifact == "" || len(source.Records) == 0 {
+				return fmt.Errorf("logsource %s needs an artifact and its records", key)
+			}
+			existing = &RecordMapping{Fields: make(map[string]string)}
+			m.sources[key] = existing
+		}
+		if source.Artifact != "" {
+			existing.Artifact = source.Artifact
+		}
+		if len(source.Records) > 0 {
+			existing.Records = source.Records
+		}
+		for field, target := range source.Fields {
+			existing.Fields[field] = target
+		}
+	}
+	return nil
+}
+
+// Lookup returns the mapping for a rule's logsource, preferring one for its
+// category and product over one for the category alone, or nil when there
+// is none
+func (m *FieldMap) Lookup(ls LogSource) *RecordMapping {
+	if m == nil || ls.Category == "" {
+		return nil
+	}
+	category := strings.ToLower(ls.Category)
+	if ls.Product != "" {
+		if mapping := m.sources[category+"/"+strings.ToLower(ls.Product)]; mapping != nil {
+			return mapping
+		}
+	}
+	return m.sources[category]
+}
+
+// Translate maps the fields of a rule's selection to record fields. Fields
+// the mapping does not know, or that use a modifier other than contains,
+// startswith and endswith, are returned with an empty target so they never
+// match, and listed as unmapped. It returns nil when the rule's logsource
+// has no mapping.
+func (m *FieldMap) Translate(rule SigmaRule) (*RecordMapping, []MappedField, []string) {
+	mapping := m.Lookup(rule.LogSource)
+	if mapping == nil {
+		return nil, nil, nil
+	}
+	selection, _ := rule.Detection["selection"].(map[string]interface{})
+	fields, unmapped := mapping.translate(selection)
+	return mapping, fields, unmapped
+}
+
+// translate maps one selection, in key order
+func (mapping *RecordMapping) translate(selection map[string]interface{}) ([]MappedField, []string) {
+	keys := make([]string, 0, len(selection))
+	for key := range selection {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields []MappedField
+	var unmapped []string
+	for _, key := range keys {
+		name, modifiers, _ := strings.Cut(key, "|")
+		field := MappedField{Key: key, Target: mapping.Fields[name], Want: selection[key]}
+		switch wildcard, ok := wildcardModifiers[modifiers]; {
+		case field.Target == "":
+			unmapped = append(unmapped, name)
+		case ok:
+			field.Want = applyModifier(field.Want, wildcard)
+		case modifiers != "":
+			field.Target = ""
+			unmapped = append(unmapped, key)
+		}
+		fields = append(fields, field)
+	}
+	return fields, unmapped
+}
+
+// applyModifier turns a selection value or list of values into wildcard
+// patterns
+func applyModifier(want interface{}, wildcard func(string) string) interface{} {
+	if list, ok := want.([]interface{}); ok {
+		patterns := make([]interface{}, len(list))
+		for i, value := range list {
+			patterns[i] = wildcard(fmt.Sprint(value))
+		}
+		return patterns
+	}
+	return wildcard(fmt.Sprint(want))
+}
+
+// Mapping coverage states of a rule
+const (
+	CoverageFull    = "full"    // every selection field maps to a record field
+	CoveragePartial = "partial" // some fields cannot be evaluated
+	CoverageNone    = "none"    // the rule's logsource has no mapping
+	CoverageNative  = "native"  // the rule uses RedTriage's own fields or event records
+)
+
+// Coverage is how much of a rule the field mappings can evaluate
+type Coverage struct {
+	Rule      string   `json:"rule"`
+	LogSource string   `json:"logsource"`
+	Status    string   `json:"status"`
+	Artifact  string   `json:"artifact,omitempty"`
+	Fields    int      `json:"fields"`
+	Unmapped  []string `json:"unmapped,omitempty"`
+}
+
+// RuleCoverage reports which fields of each selection of a rule map to
+// record fields. Rules without a category, for RedTriage's own product or
+// for an event log service are native and need no mapping.
+func (m *FieldMap) RuleCoverage(rule SigmaRule) Coverage {
+	name := rule.Title
+	if name == "" {
+		name = rule.ID
+	}
+	coverage := Coverage{Rule: name, LogSource: rule.LogSource.String()}
+	ls := rule.LogSource
+	mapping := m.Lookup(ls)
+	if mapping == nil {
+		coverage.Status = CoverageNone
+		if ls.Category == "" || ls.Service != "" || strings.EqualFold(ls.Product, ProductRedTriage) {
+			coverage.Status = CoverageNative
+		}
+		return coverage
+	}
+
+	coverage.Artifact = mapping.Artifact
+	seen := make(map[string]bool)
+	for key, value := range rule.Detection {
+		selection, ok := value.(map[string]interface{})
+		if key == "condition" || !ok {
+			continue
+		}
+		fields, unmapped := mapping.translate(selection)
+		coverage.Fields += len(fields)
+		for _, name := range unmapped {
+			if !seen[name] {
+				seen[name] = true
+				coverage.Unmapped = append(coverage.Unmapped, name)
+			}
+		}
+	}
+	sort.Strings(coverage.Unmapped)
+	coverage.Status = CoverageFull
+	if len(coverage.Unmapped) > 0 {
+		coverage.Status = CoveragePartial
+	}
+	return coverage
+}
+
+// String describes the coverage on one line
+func (c Coverage) String() string {
+	switch c.Status {
+	case CoverageFull:
+		return fmt.Sprintf("%s (%s): all %d fields map to %s records", c.Rule, c.LogSource, c.Fields, c.Artifact)
+	case CoveragePartial:
+		return fmt.Sprintf("%s (%s): cannot fully evaluate, unmapped %s", c.Rule, c.LogSource, strings.Join(c.Unmapped, ", "))
+	case CoverageNone:
+		return fmt.Sprintf("%s (%s): no field mapping for this logsource, the rule is not evaluated", c.Rule, c.LogSource)
+	}
+	return fmt.Sprintf("%s (%s): uses RedTriage fields, no mapping needed", c.Rule, c.LogSource)
+}
@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upstreamTestRules parses the rules in testdata/upstream, written the way
+// SigmaHQ publishes them against Sysmon field names rather than RedTriage's
+// record fields, by file name without its extension
+func upstreamTestRules(t *testing.T) map[string]SigmaRule {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "upstream", "*.yml"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no upstream test rules: %v", err)
+	}
+	parsed := make(map[string]SigmaRule)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rule, err := Parse(data)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", path, err)
+		}
+		parsed[strings.TrimSuffix(filepath.Base(path), ".yml")] = *rule
+	}
+	return parsed
+}
+
+// TestFieldMap checks the Sigma field mappings: upstream rules are
+// translated to record fields with their modifiers turned into wildcards,
+// host:port addresses are split, unmapped fields and unsupported modifiers
+// are reported, a user mapping file in testdata/rules extends the built-in
+// set, an invalid one is reported, and the embedded rules need no mapping
+// at all.
+func TestFieldMap(t *testing.T) {
+	parsed := upstreamTestRules(t)
+
+	builtin, warnings := LoadFieldMap("")
+	if len(warnings) > 0 {
+		t.Errorf("built-in mappings warned: %v", warnings)
+	}
+	for _, rule := range Embedded() {
+		if coverage := builtin.RuleCoverage(rule); coverage.Status != CoverageNative {
+			t.Errorf("embedded rule needs a mapping: %s", coverage)
+		}
+	}
+
+	coverage := builtin.RuleCoverage(parsed["process"])
+	if coverage.Status != CoveragePartial || strings.Join(coverage.Unmapped, ",") != "ParentImage" {
+		t.Errorf("process rule coverage is %s", coverage)
+	}
+	if coverage := builtin.RuleCoverage(parsed["regex"]); strings.Join(coverage.Unmapped, ",") != "TargetObject|re" {
+		t.Errorf("an unsupported modifier was not reported: %s", coverage)
+	}
+	if coverage := builtin.RuleCoverage(parsed["unknown"]); coverage.Status != CoverageNone {
+		t.Errorf("a logsource without a mapping was reported as %s", coverage.Status)
+	}
+
+	mapping, fields, _ := builtin.Translate(parsed["process"])
+	if mapping == nil || mapping.Artifact != "processes" {
+		t.Fatal("process_creation does not map to the processes artifact")
+	}
+	want := map[string]string{
+		"CommandLine|contains": "command=[*sekurlsa* *lsadump*]",
+		"Image|endswith":       `path=*\evil.exe`,
+		"ParentImage|endswith": "",
+	}
+	for _, field := range fields {
+		got := field.Target
+		if got != "" {
+			got += "=" + fmt.Sprint(field.Want)
+		}
+		if got != want[field.Key] {
+			t.Errorf("%s translated to %q, want %q", field.Key, got, want[field.Key])
+		}
+	}
+
+	_, fields, unmapped := builtin.Translate(parsed["network"])
+	record := map[string]interface{}{"remote_address": "185.220.101.45:4444", "local_address": "[fe80::1]:6667"}
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		values = append(values, fmt.Sprint(field.Value(record)))
+	}
+	if len(unmapped) > 0 || strings.Join(values, ",") != "185.220.101.45,4444" {
+		t.Errorf("network fields read %v, unmapped %v", values, unmapped)
+	}
+	if host := RecordValue(record, "local_address|host"); host != "fe80::1" {
+		t.Errorf("an IPv6 address split to host %v", host)
+	}
+
+	extended, warnings := LoadFieldMap(filepath.Join("testdata", "rules"))
+	if len(extended.Files) != 1 || len(warnings) != 1 {
+		t.Errorf("loaded %d user mapping files with %d warnings, want 1 and 1", len(extended.Files), len(warnings))
+	}
+	if coverage := extended.RuleCoverage(parsed["process"]); coverage.Status != CoverageFull {
+		t.Errorf("a user mapping did not complete the process rule: %s", coverage)
+	}
+	if coverage := extended.RuleCoverage(parsed["unknown"]); coverage.Status != CoverageFull || coverage.Artifact != "pipes" {
+		t.Errorf("a user mapping did not add a logsource: %s", coverage)
+	}
+	if mapping := extended.Lookup(parsed["process"].LogSource); mapping.Artifact != "processes" || mapping.Fields["Image"] != "path" {
+		t.Error("a user mapping replaced the built-in fields it did not name")
+	}
+}
@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Levels are the Sigma rule levels, highest first
+var Levels = []string{"critical", "high", "medium", "low", "informational"}
+
+// Selection picks the rules a findings run evaluates. Levels and Tags
+// narrow the loaded rules; RuleIDs keeps only the named rules and RuleFiles
+// adds rules read from outside the rules directory. When RuleIDs or
+// RuleFiles is set, loaded rules not named by ID are left out.
+type Selection struct {
+	Levels    []string `json:"levels,omitempty"`
+	RuleIDs   []string `json:"rule_ids,omitempty"`
+	RuleFiles []string `json:"rule_files,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// SelectionFlags are the findings flags that make up a Selection
+var SelectionFlags = []string{"--level", "--rule-id", "--rule-file", "--tag"}
+
+// TakeSelection removes the selection flags from args and returns the
+// selection they describe. Each flag takes a comma-separated list and may
+// be repeated.
+func TakeSelection(args []string) (Selection, []string, error) {
+	var sel Selection
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		var target *[]string
+		switch args[i] {
+		case "--level":
+			target = &sel.Levels
+		case "--rule-id":
+			target = &sel.RuleIDs
+		case "--rule-file":
+			target = &sel.RuleFiles
+		case "--tag":
+			target = &sel.Tags
+		default:
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) || strings.HasPrefix(args[i+1], "--") {
+			return Selection{}, nil, fmt.Errorf("%s requires a value", args[i])
+		}
+		for _, value := range strings.Split(args[i+1], ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				*target = append(*target, value)
+			}
+		}
+		i++
+	}
+
+	for i, level := range sel.Levels {
+		sel.Levels[i] = strings.ToLower(level)
+		if !validLevel(sel.Levels[i]) {
+			return Selection{}, nil, fmt.Errorf("invalid --level '%s': must be one of %s", level, strings.Join(Levels, ", "))
+		}
+	}
+	for i, tag := range sel.Tags {
+		sel.Tags[i] = strings.ToLower(tag)
+	}
+	return sel, rest, nil
+}
+
+// ParseSelection parses a selection saved with Selection.String
+func ParseSelection(s string) (Selection, error) {
+	sel, rest, err := TakeSelection(strings.Fields(s))
+	if err != nil {
+		return Selection{}, err
+	}
+	if len(rest) > 0 {
+		return Selection{}, fmt.Errorf("unexpected %q in rule selection", strings.Join(rest, " "))
+	}
+	return sel, nil
+}
+
+// Empty reports whether the selection keeps every loaded rule
+func (sel Selection) Empty() bool {
+	return len(sel.Levels)+len(sel.RuleIDs)+len(sel.RuleFiles)+len(sel.Tags) == 0
+}
+
+// String renders the selection as findings flags
+func (sel Selection) String() string {
+	var parts []string
+	add := func(flag string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, flag, strings.Join(values, ","))
+		}
+	}
+	add("--level", sel.Levels)
+	add("--rule-id", sel.RuleIDs)
+	add("--rule-file", sel.RuleFiles)
+	add("--tag", sel.Tags)
+	return strings.Join(parts, " ")
+}
+
+// Apply returns the rules the selection keeps: the loaded rules that pass
+// every filter, followed by the rules read from RuleFiles
+func (sel Selection) Apply(loaded []SigmaRule) ([]SigmaRule, error) {
+	var selected []SigmaRule
+	for _, rule := range loaded {
+		if sel.keeps(rule) {
+			selected = append(selected, rule)
+		}
+	}
+
+	for _, path := range sel.RuleFiles {
+		rule, err := ParseFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateSigmaRule(*rule); err != nil {
+			return nil, fmt.Errorf("rule %s cannot be evaluated: %w", path, err)
+		}
+		selected = append(selected, *rule)
+	}
+	return selected, nil
+}
+
+// KeepsFinding reports whether a finding of a rule that is not a loaded
+// Sigma rule, such as one of the built-in detector's, passes the selection
+// by its rule ID, level and tags. Rule files select no such finding.
+func (sel Selection) KeepsFinding(ruleID, level string, tags []string) bool {
+	return sel.keeps(SigmaRule{ID: ruleID, Level: level, Tags: tags})
+}
+
+// keeps reports whether a loaded rule passes the selection
+func (sel Selection) keeps(rule SigmaRule) bool {
+	if len(sel.RuleIDs) > 0 || len(sel.RuleFiles) > 0 {
+		if !containsFold(sel.RuleIDs, rule.ID) {
+			return false
+		}
+	}
+	if len(sel.Levels) > 0 && !containsFold(sel.Levels, rule.Level) {
+		return false
+	}
+	if len(sel.Tags) > 0 {
+		tagged := false
+		for _, tag := range rule.Tags {
+			if containsFold(sel.Tags, tag) {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseFile reads and parses a single Sigma rule file
+func ParseFile(path string) (*SigmaRule, error) {
+	if !IsRuleFile(path) {
+		return nil, fmt.Errorf("rule file must have a .yml or .yaml extension: %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+	rule, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sigma rule %s: %w", filepath.Base(path), err)
+	}
+	return rule, nil
+}
+
+func validLevel(level string) bool {
+	for _, known := range Levels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,430 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// IndexName is the file a remote rule pack lists its rules in: a JSON
+// object whose files each have a name, a SHA-256 and a size
+const IndexName = "index.json"
+
+const (
+	updateStateName   = ".redtriage-update.json" // sync state, in the rules directory
+	updateStagingName = ".redtriage-update"      // verified downloads not yet installed
+	maxIndexSize      = 4 << 20
+	maxRuleFileSize   = 4 << 20
+	defaultAttempts   = 4
+	defaultBackoff    = time.Second
+	maxBackoff        = time.Minute
+)
+
+// PackIndex is the index of a remote rule pack
+type PackIndex struct {
+	Files []PackFile `json:"files"`
+}
+
+// PackFile is a rule file listed in a pack index
+type PackFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// UpdateState is what the updater remembers between runs: the validators of
+// the last index fetched and the files it installed with their hashes
+type UpdateState struct {
+	Source       string            `json:"source"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	LastSync     time.Time         `json:"last_sync"`
+	LastCheck    time.Time         `json:"last_check"`
+	Files        map[string]string `json:"files"`
+}
+
+// UpdateOptions configures a rule pack update
+type UpdateOptions struct {
+	Source   string        // base URL of the pack; the index is at Source/index.json
+	Dir      string        // rules directory (default: DefaultDir)
+	Force    bool          // fetch the index even if it did not change
+	Client   *http.Client  // default: 30 second timeout
+	Attempts int           // tries per request (default: 4)
+	Backoff  time.Duration // delay before the first retry, doubled after each (default: 1s)
+}
+
+// UpdateResult is what an update changed
+type UpdateResult struct {
+	NotModified bool      // the index had not changed since the last sync
+	Added       []string  // rule files new in the pack
+	Updated     []string  // rule files whose content changed
+	Removed     []string  // rule files the pack no longer lists
+	Kept        []string  // files the pack dropped but that were edited locally
+	Unchanged   int       // rule files already up to date
+	Downloaded  int       // rule files fetched
+	Resumed     int       // rule files reused from an interrupted update
+	LastSync    time.Time // when the rules last changed from the pack
+}
+
+// Update brings the rules directory up to date with a remote rule pack. The
+// index is fetched with the ETag and Last-Modified of the last sync, so an
+// unchanged pack costs one request answered 304. Only rule files whose
+// SHA-256 differs from the local copy are downloaded, each checked against
+// the index and parsed before anything is replaced; a failed check leaves
+// the directory as it was. Verified downloads are kept until the update
+// completes, so an interrupted update resumes without fetching them again.
+// Transient failures and rate limits are retried with backoff.
+func Update(ctx context.Context, opts UpdateOptions) (*UpdateResult, error) {
+	base, err := updateSource(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir
+	}
+	fetcher := newFetcher(opts)
+
+	state := readUpdateState(opts.Dir)
+	if state.Source != base {
+		state = UpdateState{Source: base, Files: state.Files}
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+
+	// A conditional request would miss rule files deleted locally since
+	for name := range state.Files {
+		if _, err := os.Stat(filepath.Join(opts.Dir, name)); err != nil {
+			opts.Force = true
+		}
+	}
+	header := http.Header{}
+	if !opts.Force {
+		if state.ETag != "" {
+			header.Set("If-None-Match", state.ETag)
+		}
+		if state.LastModified != "" {
+			header.Set("If-Modified-Since", state.LastModified)
+		}
+	}
+	resp, body, err := fetcher.get(ctx, base+"/"+IndexName, header, maxIndexSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the rule pack index: %w", err)
+	}
+	result := &UpdateResult{LastSync: state.LastSync}
+	state.LastCheck = time.Now().UTC()
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, writeUpdateState(opts.Dir, state)
+	}
+
+	index, err := parsePackIndex(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	staging := filepath.Join(opts.Dir, updateStagingName)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create update staging directory: %w", err)
+	}
+
+	// Download and verify every changed file before installing any
+	changed := make(map[string][]byte)
+	for _, file := range index.Files {
+		if local, err := os.ReadFile(filepath.Join(opts.Dir, file.Name)); err == nil && sha256Hex(local) == file.SHA256 {
+			result.Unchanged++
+			continue
+		}
+		staged := filepath.Join(staging, file.SHA256)
+		if data, err := os.ReadFile(staged); err == nil && sha256Hex(data) == file.SHA256 {
+			changed[file.Name] = data
+			result.Resumed++
+			continue
+		}
+
+		_, data, err := fetcher.get(ctx, base+"/"+file.Name, nil, maxRuleFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", file.Name, err)
+		}
+		if sum := sha256Hex(data); sum != file.SHA256 {
+			return nil, rterrors.Integrityf("%s does not match the rule pack index: SHA-256 %s, expected %s", file.Name, sum, file.SHA256)
+		}
+		if file.Size > 0 && int64(len(data)) != file.Size {
+			return nil, rterrors.Integrityf("%s is %d bytes, the rule pack index says %d", file.Name, len(data), file.Size)
+		}
+		if _, err := Parse(data); err != nil {
+			return nil, rterrors.Integrityf("%s from the rule pack is not a valid rule: %v", file.Name, err)
+		}
+		if err := os.WriteFile(staged, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", file.Name, err)
+		}
+		changed[file.Name] = data
+		result.Downloaded++
+	}
+
+	listed := make(map[string]string, len(index.Files))
+	for _, file := range index.Files {
+		listed[file.Name] = file.SHA256
+		data, ok := changed[file.Name]
+		if !ok {
+			continue
+		}
+		target := filepath.Join(opts.Dir, file.Name)
+		if _, err := os.Stat(target); err == nil {
+			result.Updated = append(result.Updated, file.Name)
+		} else {
+			result.Added = append(result.Added, file.Name)
+		}
+		if err := output.WriteFileAtomic(target, data, 0644); err != nil {
+			return result, fmt.Errorf("failed to install %s: %w", file.Name, err)
+		}
+	}
+
+	// Only files an earlier update installed are removed, and only when
+	// they were not edited since
+	for name, sum := range state.Files {
+		if _, ok := listed[name]; ok {
+			continue
+		}
+		path := filepath.Join(opts.Dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if sha256Hex(data) != sum {
+			result.Kept = append(result.Kept, name)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	sort.Strings(result.Removed)
+	sort.Strings(result.Kept)
+
+	if len(result.Added)+len(result.Updated)+len(result.Removed) > 0 || state.LastSync.IsZero() {
+		state.LastSync = state.LastCheck
+	}
+	result.LastSync = state.LastSync
+	state.Files = listed
+	state.ETag = resp.Header.Get("ETag")
+	state.LastModified = resp.Header.Get("Last-Modified")
+	if err := writeUpdateState(opts.Dir, state); err != nil {
+		return result, err
+	}
+	os.RemoveAll(staging)
+	return result, nil
+}
+
+// ReadUpdateState returns the sync state of a rules directory, with zero
+// times when it was never updated from a pack
+func ReadUpdateState(dir string) UpdateState {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return readUpdateState(dir)
+}
+
+// updateSource checks the pack URL and drops a trailing index name or slash
+func updateSource(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return "", rterrors.Validationf("rule pack source must be an http or https URL: %q", source)
+	}
+	source = strings.TrimSuffix(source, "/"+IndexName)
+	return strings.TrimRight(source, "/"), nil
+}
+
+// parsePackIndex reads an index and rejects names that are not plain rule
+// file names, so a pack cannot write outside the rules directory
+func parsePackIndex(data []byte) (*PackIndex, error) {
+	var index PackIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, rterrors.Integrityf("rule pack index is not valid JSON: %v", err)
+	}
+	seen := make(map[string]bool)
+	for i, file := range index.Files {
+		if file.Name == "" || file.Name != filepath.Base(file.Name) || strings.ContainsAny(file.Name, `/\`) || strings.HasPrefix(file.Name, ".") || !IsRuleFile(file.Name) {
+			return nil, rterrors.Integrityf("rule pack index lists an invalid rule file name %q", file.Name)
+		}
+		if seen[file.Name] {
+			return nil, rterrors.Integrityf("rule pack index lists %s twice", file.Name)
+		}
+		seen[file.Name] = true
+		sum := strings.ToLower(file.SHA256)
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != 64 {
+			return nil, rterrors.Integrityf("rule pack index has no valid SHA-256 for %s", file.Name)
+		}
+		index.Files[i].SHA256 = sum
+	}
+	return &index, nil
+}
+
+// readUpdateState reads the sync state of a rules directory
+func readUpdateState(dir string) UpdateState {
+	var state UpdateState
+	if data, err := os.ReadFile(filepath.Join(dir, updateStateName)); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// writeUpdateState saves the sync state of a rules directory
+func writeUpdateState(dir string, state UpdateState) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode update state: %w", err)
+	}
+	if err := output.WriteFileAtomic(filepath.Join(dir, updateStateName), data, 0644); err != nil {
+		return fmt.Errorf("failed to save update state: %w", err)
+	}
+	return nil
+}
+
+// sha256Hex returns the hex SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// fetcher makes GET requests, retrying transient failures
+type fetcher struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+}
+
+func newFetcher(opts UpdateOptions) *fetcher {
+	f := &fetcher{client: opts.Client, attempts: opts.Attempts, backoff: opts.Backoff}
+	if f.client == nil {
+		f.client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if f.attempts < 1 {
+		f.attempts = defaultAttempts
+	}
+	if f.backoff <= 0 {
+		f.backoff = defaultBackoff
+	}
+	return f
+}
+
+// get fetches url and returns the response with its body read, up to limit
+// bytes. Network errors, 5xx responses and rate limits (429, or 403 with no
+// requests remaining) are retried, waiting as long as Retry-After or the
+// rate limit reset asks when that is under a minute. A 304 is returned as is.
+func (f *fetcher) get(ctx context.Context, url string, header http.Header, limit int64) (*http.Response, []byte, error) {
+	delay := f.backoff
+	var lastErr error
+	for attempt := 1; attempt <= f.attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, nil, rterrors.Validationf("invalid rule pack URL %s: %v", url, err)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := f.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			return resp, nil, nil
+		case resp.StatusCode == http.StatusOK && err == nil:
+			if int64(len(body)) > limit {
+				return nil, nil, fmt.Errorf("%s is larger than %d MB", url, limit>>20)
+			}
+			return resp, body, nil
+		case err != nil:
+			lastErr = err
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, nil, rterrors.NotFoundf("%s: %s", url, resp.Status)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 || rateLimited(resp):
+			lastErr = fmt.Errorf("%s: %s%s", url, resp.Status, firstLine(body))
+			if wait, ok := retryWait(resp); ok {
+				if wait > maxBackoff {
+					return nil, nil, fmt.Errorf("%s: rate limited for %s; try again later", url, wait.Round(time.Second))
+				}
+				delay = wait
+			}
+			continue
+		default:
+			return nil, nil, fmt.Errorf("%s: %s%s", url, resp.Status, firstLine(body))
+		}
+	}
+	return nil, nil, fmt.Errorf("giving up after %d attempts: %w", f.attempts, lastErr)
+}
+
+// rateLimited reports a GitHub-style 403 for an exhausted rate limit
+func rateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// retryWait returns how long the server asks to wait, from Retry-After in
+// seconds or the X-RateLimit-Reset time
+func retryWait(resp *http.Response) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		wait := time.Until(time.Unix(reset, 0))
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// firstLine returns the first line of a response body as ": line"
+func firstLine(body []byte) string {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(body), []byte("\n"))
+	if len(line) == 0 {
+		return ""
+	}
+	if len(line) > 200 {
+		line = line[:200]
+	}
+	return ": " + string(line)
+}
@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// rulePackServer serves a rule pack the way a static file host would: an
+// index with an ETag, the rule files it lists, and failures on request
+type rulePackServer struct {
+	mu       sync.Mutex
+	files    map[string]string // name -> content served
+	index    map[string]string // name -> content the index describes
+	version  int
+	failures map[string]int // path -> 503 responses left
+	requests map[string]int // path -> requests seen
+}
+
+func newRulePackServer() *rulePackServer {
+	return &rulePackServer{
+		files:    make(map[string]string),
+		index:    make(map[string]string),
+		failures: make(map[string]int),
+		requests: make(map[string]int),
+	}
+}
+
+// publish makes content the pack's version of name
+func (s *rulePackServer) publish(name, content string) {
+	s.files[name] = content
+	s.index[name] = content
+	s.version++
+}
+
+// withdraw drops name from the pack
+func (s *rulePackServer) withdraw(name string) {
+	delete(s.files, name)
+	delete(s.index, name)
+	s.version++
+}
+
+func (s *rulePackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	s.requests[path]++
+	if s.failures[path] > 0 {
+		s.failures[path]--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if path == IndexName {
+		etag := fmt.Sprintf(`"v%d"`, s.version)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		names := make([]string, 0, len(s.index))
+		for name := range s.index {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var index PackIndex
+		for _, name := range names {
+			content := s.index[name]
+			sum := sha256.Sum256([]byte(content))
+			index.Files = append(index.Files, PackFile{Name: name, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(content))})
+		}
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode(index)
+		return
+	}
+	content, ok := s.files[path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write([]byte(content))
+}
+
+// packRule is a minimal Sigma rule served by the test pack
+func packRule(title string) string {
+	return fmt.Sprintf("title: %s\nid: test-%s\nlevel: low\ndetection:\n  selection:\n    CommandLine|contains: %s\n  condition: selection\n", title, strings.ToLower(title), title)
+}
+
+// TestUpdate checks the remote rule pack updater against a local server: a
+// first sync installs the pack, an unchanged pack is answered 304, changed
+// files are updated and dropped ones removed unless edited locally, a file
+// that does not match the index leaves the directory untouched, a transient
+// failure is retried, and an interrupted update resumes from the files it
+// had already verified.
+func TestUpdate(t *testing.T) {
+	server := newRulePackServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	dir := filepath.Join(t.TempDir(), "rule-pack")
+	opts := UpdateOptions{Source: ts.URL, Dir: dir, Backoff: time.Millisecond}
+	update := func() (*UpdateResult, error) {
+		return Update(context.Background(), opts)
+	}
+
+	server.publish("alpha.yml", packRule("Alpha"))
+	server.publish("beta.yml", packRule("Beta"))
+	server.publish("gamma.yml", packRule("Gamma"))
+	result, err := update()
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	if len(result.Added) != 3 || result.Downloaded != 3 || result.LastSync.IsZero() {
+		t.Fatalf("first sync added %v, downloaded %d", result.Added, result.Downloaded)
+	}
+
+	requests := server.requests["alpha.yml"]
+	if result, err = update(); err != nil {
+		t.Fatalf("unchanged sync failed: %v", err)
+	}
+	if !result.NotModified || server.requests["alpha.yml"] != requests {
+		t.Fatal("an unchanged pack was downloaded again instead of answered 304")
+	}
+
+	// One file changes, one is dropped, one dropped file was edited locally
+	server.publish("alpha.yml", packRule("AlphaTwo"))
+	server.withdraw("beta.yml")
+	server.withdraw("gamma.yml")
+	edited := filepath.Join(dir, "gamma.yml")
+	if err := os.WriteFile(edited, []byte(packRule("GammaLocal")), 0644); err != nil {
+		t.Fatalf("failed to edit a rule: %v", err)
+	}
+	if result, err = update(); err != nil {
+		t.Fatalf("incremental sync failed: %v", err)
+	}
+	if strings.Join(result.Updated, ",") != "alpha.yml" || strings.Join(result.Removed, ",") != "beta.yml" ||
+		strings.Join(result.Kept, ",") != "gamma.yml" || result.Downloaded != 1 {
+		t.Fatalf("incremental sync updated %v, removed %v, kept %v, downloaded %d", result.Updated, result.Removed, result.Kept, result.Downloaded)
+	}
+	if _, err := os.Stat(edited); err != nil {
+		t.Fatal("a locally edited rule was removed")
+	}
+
+	// A tampered file must be rejected before anything is installed
+	server.publish("delta.yml", packRule("Delta"))
+	server.publish("epsilon.yml", packRule("Epsilon"))
+	server.files["epsilon.yml"] = packRule("Tampered")
+	before, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	_, err = update()
+	if rterrors.CategoryOf(err) != rterrors.Integrity {
+		t.Fatalf("a file not matching the index was not an integrity error: %v", err)
+	}
+	after, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if strings.Join(after, ",") != strings.Join(before, ",") {
+		t.Fatal("a rejected update changed the rules directory")
+	}
+
+	// Once the file is fixed, delta.yml, verified before epsilon.yml was
+	// rejected, is reused and a transient failure is retried
+	server.files["epsilon.yml"] = server.index["epsilon.yml"]
+	server.failures["epsilon.yml"] = 1
+	requests = server.requests["delta.yml"]
+	if result, err = update(); err != nil {
+		t.Fatalf("resumed sync failed: %v", err)
+	}
+	if len(result.Added) != 2 || result.Resumed != 1 || server.requests["delta.yml"] != requests {
+		t.Fatalf("resumed sync added %v, resumed %d, downloaded %d", result.Added, result.Resumed, result.Downloaded)
+	}
+	if server.requests["epsilon.yml"] < 3 {
+		t.Fatal("a 503 response was not retried")
+	}
+
+	state := ReadUpdateState(dir)
+	if state.ETag == "" || len(state.Files) != 3 {
+		t.Fatalf("sync state has ETag %q and %d files", state.ETag, len(state.Files))
+	}
+}
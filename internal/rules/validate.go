@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validation is the outcome of loading and checking a rules directory
+type Validation struct {
+	Dir      string   `json:"dir"`
+	Files    int      `json:"files"`
+	Loaded   int      `json:"loaded"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Validate loads every rule in dir, bypassing the persistent cache, and
+// checks that findings analysis can evaluate each one. An empty dir
+// validates DefaultDir, or the embedded rules when it has none.
+func Validate(dir string) (*Validation, error) {
+	cache := NewCache("")
+	var (
+		rules    []SigmaRule
+		warnings []string
+		stats    CacheStats
+		source   = SourceExternal
+		err      error
+	)
+	if dir == "" {
+		dir = DefaultDir
+		rules, warnings, stats, source, err = cache.LoadWithDefaults(dir)
+	} else {
+		rules, warnings, stats, err = cache.Load(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	validation := &Validation{
+		Dir:      dir,
+		Files:    stats.Misses,
+		Failures: warnings,
+	}
+	if source == SourceEmbedded {
+		validation.Dir = SourceLabel(source, dir)
+		validation.Files = len(rules)
+	}
+	for _, rule := range rules {
+		if err := validateSigmaRule(rule); err != nil {
+			name := rule.Title
+			if name == "" {
+				name = rule.ID
+			}
+			validation.Failures = append(validation.Failures, fmt.Sprintf("Rule %q cannot be evaluated: %v", name, err))
+			continue
+		}
+		validation.Loaded++
+	}
+	return validation, nil
+}
+
+// Failed reports whether the directory has rule files but none of them
+// could be loaded
+func (v *Validation) Failed() bool {
+	return v.Files > 0 && v.Loaded == 0
+}
+
+// validateSigmaRule checks that a parsed rule has what findings analysis
+// needs: a title and a detection block whose condition only names
+// selections the rule defines
+func validateSigmaRule(rule SigmaRule) error {
+	if strings.TrimSpace(rule.Title) == "" {
+		return fmt.Errorf("missing title")
+	}
+	if len(rule.Detection) == 0 {
+		return fmt.Errorf("missing detection block")
+	}
+
+	var conditions []string
+	switch condition := rule.Detection["condition"].(type) {
+	case string:
+		conditions = []string{condition}
+	case []interface{}:
+		for _, c := range condition {
+			if s, ok := c.(string); ok {
+				conditions = append(conditions, s)
+			}
+		}
+	}
+	if len(conditions) == 0 {
+		return fmt.Errorf("detection has no condition")
+	}
+
+	var selections []string
+	for key := range rule.Detection {
+		if key != "condition" && key != "timeframe" {
+			selections = append(selections, key)
+		}
+	}
+	if len(selections) == 0 {
+		return fmt.Errorf("detection defines no selections")
+	}
+
+	for _, condition := range conditions {
+		fields := strings.FieldsFunc(condition, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '(' || r == ')' || r == '|'
+		})
+		for i, field := range fields {
+			switch strings.ToLower(field) {
+			case "and", "or", "not", "of", "1", "all", "them", "near", "by", "count", "min", "max", "avg", "sum", ">", "<", ">=", "<=", "==", "=":
+				continue
+			}
+			// Aggregations such as count() > 5 end in a number
+			if i > 0 && strings.Trim(field, "0123456789") == "" {
+				continue
+			}
+			if !matchesSelection(field, selections) {
+				return fmt.Errorf("condition %q names undefined selection %q", condition, field)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesSelection reports whether a condition identifier, which may end in
+// a * wildcard, names one of the selections
+func matchesSelection(identifier string, selections []string) bool {
+	for _, selection := range selections {
+		if prefix, ok := strings.CutSuffix(identifier, "*"); ok {
+			if strings.HasPrefix(selection, prefix) {
+				return true
+			}
+		} else if selection == identifier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+// Package schema tracks the format versions of the documents RedTriage
+// writes: collection reports, bundle manifests and incident files.
+//
+// Every document is saved with a schema_version field. Readers accept any
+// older version and upgrade it in memory, recording what had to be inferred
+// in a Migration. Documents written by a newer RedTriage are refused.
+//
+// Version history:
+//
+//	0  ad-hoc format without schema_version (RedTriage 1.0)
+//	1  schema_version written on save
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Document kinds
+const (
+	Collection = "collection"
+	Bundle     = "bundle manifest"
+	Incident   = "incident"
+)
+
+// Current schema versions, written on save
+const (
+	CollectionVersion = 1
+	BundleVersion     = 1
+	IncidentVersion   = 1
+)
+
+// Migration records how an older document was upgraded in memory
+type Migration struct {
+	Document string   `json:"document"`
+	From     int      `json:"from"`
+	To       int      `json:"to"`
+	Notes    []string `json:"notes"`
+}
+
+// NewMigration starts the migration log for a document read at version from
+func NewMigration(document string, from, to int) *Migration {
+	return &Migration{Document: document, From: from, To: to}
+}
+
+// Notef records something the upgrade inferred
+func (m *Migration) Notef(format string, args ...interface{}) {
+	m.Notes = append(m.Notes, fmt.Sprintf(format, args...))
+}
+
+// Upgraded reports whether the document was read at an older version
+func (m *Migration) Upgraded() bool {
+	return m != nil && m.From < m.To
+}
+
+// String summarizes the migration on one line
+func (m *Migration) String() string {
+	summary := fmt.Sprintf("%s upgraded from schema v%d to v%d", m.Document, m.From, m.To)
+	if len(m.Notes) > 0 {
+		summary += ": " + strings.Join(m.Notes, "; ")
+	}
+	return summary
+}
+
+// NewerVersionError is returned for documents written by a newer RedTriage
+type NewerVersionError struct {
+	Document  string
+	Version   int
+	Supported int
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("%s schema version %d was produced by a newer RedTriage (this version reads up to v%d); upgrade RedTriage to open it",
+		e.Document, e.Version, e.Supported)
+}
+
+// Version returns the schema_version of a JSON document, 0 when absent
+func Version(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	if header.SchemaVersion == nil {
+		return 0, nil
+	}
+	if *header.SchemaVersion < 0 {
+		return 0, rterrors.Validationf("invalid schema version %d", *header.SchemaVersion)
+	}
+	return *header.SchemaVersion, nil
+}
+
+// Check refuses versions newer than the current one
+func Check(document string, version, current int) error {
+	if version > current {
+		return rterrors.Wrap(rterrors.Validation, &NewerVersionError{Document: document, Version: version, Supported: current})
+	}
+	return nil
+}
+
+// Read returns the schema version of a document after checking that this
+// build can read it, along with a migration log for older versions
+func Read(document string, data []byte, current int) (int, *Migration, error) {
+	version, err := Version(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := Check(document, version, current); err != nil {
+		return 0, nil, err
+	}
+
+	migration := NewMigration(document, version, current)
+	if version == 0 {
+		migration.Notef("no schema_version; read as v0 (pre-versioning format)")
+	}
+	return version, migration, nil
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ReadCollection parses a collection report of any supported version and
+// upgrades it to the current one. collectionID is used when an old report
+// does not record its own ID.
+func ReadCollection(data []byte, collectionID string) (map[string]interface{}, *Migration, error) {
+	version, migration, err := Read(Collection, data, CollectionVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var collection map[string]interface{}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse collection: %w", err)
+	}
+
+	if version < 1 {
+		upgradeCollectionV0(collection, collectionID, migration)
+	}
+	collection["schema_version"] = CollectionVersion
+
+	return collection, migration, nil
+}
+
+// ReadIncident parses an incident file of any supported version and
+// upgrades it to the current one. The result is ready to decode into the
+// session's incident type.
+func ReadIncident(data []byte) (map[string]interface{}, *Migration, error) {
+	version, migration, err := Read(Incident, data, IncidentVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var incident map[string]interface{}
+	if err := json.Unmarshal(data, &incident); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse incident: %w", err)
+	}
+
+	if version < 1 {
+		upgradeIncidentV0(incident, migration)
+	}
+	incident["schema_version"] = IncidentVersion
+
+	return incident, migration, nil
+}
+
+// upgradeCollectionV0 fills in fields that pre-versioning collection
+// reports could omit
+func upgradeCollectionV0(collection map[string]interface{}, collectionID string, migration *Migration) {
+	if id, _ := collection["collection_id"].(string); id == "" && collectionID != "" {
+		collection["collection_id"] = collectionID
+		migration.Notef("collection_id missing; taken from the file name (%s)", collectionID)
+	}
+
+	artifacts, ok := collection["artifacts"].(map[string]interface{})
+	if !ok {
+		artifacts = map[string]interface{}{}
+		collection["artifacts"] = artifacts
+		migration.Notef("artifacts missing; collection has no artifacts")
+	}
+
+	if _, ok := collection["artifacts_collected"].([]interface{}); !ok {
+		names := make([]interface{}, 0, len(artifacts))
+		keys := make([]string, 0, len(artifacts))
+		for name := range artifacts {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			names = append(names, name)
+		}
+		collection["artifacts_collected"] = names
+		migration.Notef("artifacts_collected missing; inferred %d artifacts from the artifacts map", len(names))
+	}
+
+	if status, _ := collection["status"].(string); status == "" {
+		collection["status"] = "completed"
+		migration.Notef("status missing; assumed completed")
+	}
+}
+
+// upgradeIncidentV0 fills in fields that pre-versioning incident files could
+// omit or leave null
+func upgradeIncidentV0(incident map[string]interface{}, migration *Migration) {
+	if status, _ := incident["status"].(string); status == "" {
+		incident["status"] = "open"
+		migration.Notef("status missing; assumed open")
+	}
+	if level, _ := incident["isolation_level"].(string); level == "" {
+		incident["isolation_level"] = "strict"
+		migration.Notef("isolation_level missing; assumed strict")
+	}
+
+	for _, key := range []string{"artifacts", "memory"} {
+		if _, ok := incident[key].(map[string]interface{}); !ok {
+			incident[key] = map[string]interface{}{}
+		}
+	}
+	for _, key := range []string{"tags", "findings", "notes", "timeline"} {
+		if _, ok := incident[key].([]interface{}); !ok {
+			incident[key] = []interface{}{}
+		}
+	}
+
+	untriaged := 0
+	for _, raw := range incident["findings"].([]interface{}) {
+		if finding, ok := raw.(map[string]interface{}); ok {
+			if state, _ := finding["triage_state"].(string); state == "" {
+				untriaged++
+			}
+		}
+	}
+	if untriaged > 0 {
+		migration.Notef("%d findings predate triage; treated as needs-review", untriaged)
+	}
+}
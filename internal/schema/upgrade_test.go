@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkHistoricalFormats reads every testdata/<prefix>v<version>.json with
+// read, expecting it upgraded to current with a migration log, and checks
+// that a document from a newer RedTriage is refused
+func checkHistoricalFormats(t *testing.T, prefix string, current int, read func(data []byte) (*Migration, error)) {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", prefix+"v*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no format fixtures for %s (%v)", prefix, err)
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		var version int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, prefix), "v%d.json", &version); err != nil {
+			t.Fatalf("unexpected format fixture name %s", name)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		migration, err := read(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if migration.From != version || migration.To != current {
+			t.Errorf("%s: read as v%d->v%d, want v%d->v%d", name, migration.From, migration.To, version, current)
+		}
+		if version < current && len(migration.Notes) == 0 {
+			t.Errorf("%s: upgraded without a migration log", name)
+		}
+	}
+
+	future := []byte(fmt.Sprintf(`{"schema_version": %d}`, current+1))
+	var newer *NewerVersionError
+	if _, err := read(future); !errors.As(err, &newer) {
+		t.Errorf("v%d from a newer RedTriage was not refused: %v", current+1, err)
+	}
+}
+
+func TestReadCollectionFormats(t *testing.T) {
+	checkHistoricalFormats(t, "collection_", CollectionVersion, func(data []byte) (*Migration, error) {
+		_, migration, err := ReadCollection(data, "RT-TEST")
+		return migration, err
+	})
+}
+
+func TestReadIncidentFormats(t *testing.T) {
+	checkHistoricalFormats(t, "incident_", IncidentVersion, func(data []byte) (*Migration, error) {
+		_, migration, err := ReadIncident(data)
+		return migration, err
+	})
+}
@@ -0,0 +1,326 @@
+package selftest
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Stage is the outcome of one step of the self-test pipeline
+type Stage struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Detail   string        `json:"detail"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Result is the outcome of a self-test run
+type Result struct {
+	WorkDir string  `json:"work_dir"`
+	Kept    bool    `json:"kept"`
+	Stages  []Stage `json:"stages"`
+}
+
+// Passed reports whether every stage passed
+func (r *Result) Passed() bool {
+	for _, stage := range r.Stages {
+		if !stage.Passed {
+			return false
+		}
+	}
+	return len(r.Stages) > 0
+}
+
+// Options configures a self-test run
+type Options struct {
+	Keep    bool              // Keep the working directory instead of removing it
+	OnStage func(stage Stage) // Called as each stage finishes
+}
+
+// expectedResults is the embedded description of what the pipeline must
+// produce from the synthetic collection
+type expectedResults struct {
+	Artifacts       int            `json:"artifacts"`
+	Rules           []string       `json:"rules"`
+	Severities      map[string]int `json:"severities"`
+	Reports         int            `json:"reports"`
+	EnhancedReports int            `json:"enhanced_reports"`
+	Exports         []string       `json:"exports"`
+}
+
+// syntheticCollection is the embedded collection fixture
+type syntheticCollection struct {
+	Host      string `json:"host"`
+	Platform  string `json:"platform"`
+	Artifacts []struct {
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Category    string            `json:"category"`
+		Type        string            `json:"type"`
+		Parameters  map[string]string `json:"parameters"`
+		Data        string            `json:"data"`
+		File        string            `json:"file"`
+	} `json:"artifacts"`
+}
+
+// pipeline carries state between self-test stages
+type pipeline struct {
+	workDir   string
+	expected  expectedResults
+	artifacts []collector.ArtifactResult
+	findings  []detector.Finding
+	bundle    string
+}
+
+// Run exercises collection loading, detection, reporting, packaging and
+// bundle verification against embedded fixtures. Later stages are skipped
+// once a stage fails. The working directory is removed unless opts.Keep is set.
+func Run(opts Options) (*Result, error) {
+	workDir, err := os.MkdirTemp("", "redtriage-selftest-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create self-test directory: %w", err)
+	}
+
+	result := &Result{WorkDir: workDir, Kept: opts.Keep}
+	p := &pipeline{workDir: workDir}
+
+	stages := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"Load synthetic collection", p.loadCollection},
+		{"Run findings engine", p.runDetector},
+		{"Check expected findings", p.checkFindings},
+		{"Create bundle", p.createBundle},
+		{"Generate reports", p.generateReports},
+		{"Verify bundle", p.verifyBundle},
+	}
+
+	failed := false
+	for _, s := range stages {
+		stage := Stage{Name: s.name}
+		if failed {
+			stage.Skipped = true
+			stage.Detail = "skipped after earlier failure"
+		} else {
+			start := time.Now()
+			detail, err := s.run()
+			stage.Duration = time.Since(start)
+			stage.Passed = err == nil
+			stage.Detail = detail
+			if err != nil {
+				stage.Detail = err.Error()
+				failed = true
+			}
+		}
+
+		result.Stages = append(result.Stages, stage)
+		if opts.OnStage != nil {
+			opts.OnStage(stage)
+		}
+	}
+
+	if !opts.Keep {
+		if err := os.RemoveAll(workDir); err != nil {
+			return result, fmt.Errorf("failed to remove self-test directory: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// loadCollection builds artifact results from the embedded collection and
+// appends the reassembled script blocks, as 'collect' does
+func (p *pipeline) loadCollection() (string, error) {
+	data, err := fixtures.ReadFile("fixtures/expected.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to read expected results: %w", err)
+	}
+	if err := json.Unmarshal(data, &p.expected); err != nil {
+		return "", fmt.Errorf("failed to parse expected results: %w", err)
+	}
+
+	data, err = fixtures.ReadFile("fixtures/collection.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to read synthetic collection: %w", err)
+	}
+	var collection syntheticCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return "", fmt.Errorf("failed to parse synthetic collection: %w", err)
+	}
+
+	collectedAt := time.Now()
+	for _, fixture := range collection.Artifacts {
+		text := fixture.Data
+		if fixture.File != "" {
+			content, err := fixtures.ReadFile("fixtures/" + fixture.File)
+			if err != nil {
+				return "", fmt.Errorf("failed to read fixture %s: %w", fixture.File, err)
+			}
+			text = string(content)
+		}
+
+		artifact := collector.NewBaseArtifact(fixture.Name, fixture.Description, fixture.Category, fixture.Type).Artifact
+		artifact.Platform = collection.Platform
+		for key, value := range fixture.Parameters {
+			artifact.Parameters[key] = value
+		}
+
+		p.artifacts = append(p.artifacts, collector.ArtifactResult{
+			Artifact: artifact,
+			Data:     text,
+			Metadata: collector.Metadata{
+				CollectedAt: collectedAt,
+				Collector:   "selftest",
+				Source:      collection.Host,
+			},
+			Size: int64(len(text)),
+		})
+	}
+	p.artifacts = append(p.artifacts, detector.ScriptBlockArtifacts(p.artifacts)...)
+
+	if len(p.artifacts) != p.expected.Artifacts {
+		return "", fmt.Errorf("expected %d artifacts, loaded %d", p.expected.Artifacts, len(p.artifacts))
+	}
+	return fmt.Sprintf("%d artifacts", len(p.artifacts)), nil
+}
+
+// runDetector evaluates the built-in rules against the synthetic collection
+func (p *pipeline) runDetector() (string, error) {
+	findings, err := detector.NewDetector().Evaluate(p.artifacts)
+	if err != nil {
+		return "", fmt.Errorf("detection failed: %w", err)
+	}
+	p.findings = findings
+	return fmt.Sprintf("%d findings", len(findings)), nil
+}
+
+// checkFindings compares the findings with the embedded expected results
+func (p *pipeline) checkFindings() (string, error) {
+	var problems []string
+
+	fired := make(map[string]bool)
+	severities := make(map[string]int)
+	for _, finding := range p.findings {
+		fired[finding.RuleID] = true
+		severities[finding.Severity]++
+	}
+
+	for _, rule := range p.expected.Rules {
+		if !fired[rule] {
+			problems = append(problems, fmt.Sprintf("rule %s did not fire", rule))
+		}
+	}
+
+	names := make([]string, 0, len(p.expected.Severities)+len(severities))
+	for severity := range p.expected.Severities {
+		names = append(names, severity)
+	}
+	for severity := range severities {
+		if _, ok := p.expected.Severities[severity]; !ok {
+			names = append(names, severity)
+		}
+	}
+	sort.Strings(names)
+
+	for _, severity := range names {
+		if want, got := p.expected.Severities[severity], severities[severity]; want != got {
+			problems = append(problems, fmt.Sprintf("expected %d %s findings, got %d", want, severity, got))
+		}
+	}
+
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return fmt.Sprintf("%d rules fired with expected severities", len(fired)), nil
+}
+
+// createBundle packages the artifacts and findings into a bundle ZIP
+func (p *pipeline) createBundle() (string, error) {
+	key, err := packager.NewEphemeralSealKey()
+	if err != nil {
+		return "", err
+	}
+	packagerInstance := packager.NewPackager()
+	packagerInstance.SetSealKey(key)
+	bundle, err := packagerInstance.CreateBundle(p.artifacts, p.findings, p.workDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle: %w", err)
+	}
+	p.bundle = bundle
+	return filepath.Base(bundle), nil
+}
+
+// generateReports writes every report and export format and checks that
+// each file exists and is not empty
+func (p *pipeline) generateReports() (string, error) {
+	reports, err := reporter.NewReporter().GenerateReports(p.artifacts, p.findings, p.bundle)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate reports: %w", err)
+	}
+	if len(reports) < p.expected.Reports {
+		return "", fmt.Errorf("expected %d reports, generated %d", p.expected.Reports, len(reports))
+	}
+
+	enhancedReporter := reporter.NewEnhancedReporter()
+	results, err := enhancedReporter.GenerateEnhancedReports(context.Background(), p.artifacts, p.findings, p.bundle)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate enhanced reports: %w", err)
+	}
+	if failed := reporter.FailedReports(results); len(failed) > 0 {
+		return "", fmt.Errorf("failed to generate %s report: %w", failed[0].Name, failed[0].Err)
+	}
+	enhanced := reporter.ProducedReports(results)
+	if len(enhanced) < p.expected.EnhancedReports {
+		return "", fmt.Errorf("expected %d enhanced reports, generated %d", p.expected.EnhancedReports, len(enhanced))
+	}
+	reports = append(reports, enhanced...)
+
+	exportDir := filepath.Join(p.workDir, "exports")
+	for _, format := range p.expected.Exports {
+		exports, err := enhancedReporter.ExportFindings(p.findings, "severity", format, filepath.Join(exportDir, format))
+		if err != nil {
+			return "", fmt.Errorf("failed to export %s findings: %w", format, err)
+		}
+		reports = append(reports, exports...)
+	}
+
+	for _, report := range reports {
+		info, err := os.Stat(report.Path)
+		if err != nil {
+			return "", fmt.Errorf("%s report missing: %w", report.Type, err)
+		}
+		if info.Size() == 0 {
+			return "", fmt.Errorf("%s report is empty: %s", report.Type, report.Path)
+		}
+	}
+
+	return fmt.Sprintf("%d reports", len(reports)), nil
+}
+
+// verifyBundle re-hashes the bundle contents against its manifest
+func (p *pipeline) verifyBundle() (string, error) {
+	result, err := packager.VerifyBundle(p.bundle)
+	if err != nil {
+		return "", err
+	}
+	if !result.OK() {
+		return "", fmt.Errorf("bundle does not match its manifest: %s", strings.Join(result.Problems(), "; "))
+	}
+	return fmt.Sprintf("%d entries match the manifest", result.Checked), nil
+}
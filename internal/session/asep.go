@@ -0,0 +1,30 @@
+package session
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// collectAutostartEntries sweeps the autostart extension points of the
+// registry: Run keys, Winlogon, IFEO debuggers, AppInit_DLLs, services, LSA
+// packages, COM servers and Active Setup
+func collectAutostartEntries() map[string]interface{} {
+	if runtime.GOOS != "windows" {
+		return map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"note":      "Autostart entries are only collected on Windows",
+		}
+	}
+
+	var entries []collector.ASEPEntry
+	for _, result := range collector.CollectLiveASEPs(context.Background()) {
+		entries = append(entries, collector.ASEPEntries(result)...)
+	}
+	return map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"entries":   entries,
+	}
+}
@@ -0,0 +1,124 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// setupAudit opens the audit log in the reports metadata directory and
+// warns at once when its hash chain does not verify. A read-only reports
+// directory has no audit log.
+func (s *Session) setupAudit() error {
+	if s.readOnly() {
+		return nil
+	}
+	log, err := audit.Open(s.reportsManager.GetMetadataDirectory())
+	if err != nil {
+		return err
+	}
+	audit.SetCurrent(log)
+
+	trail, err := audit.Read(log.Path)
+	if err != nil {
+		return err
+	}
+	if !trail.Intact() {
+		color.New(color.FgRed).Printf("WARNING: audit log %s failed verification (%d problems); run 'audit show' for details\n",
+			log.Path, len(trail.Breaks))
+	}
+	return nil
+}
+
+// audit records a consequential action against the current incident. A
+// failure to write the audit log is reported but does not fail the action.
+func (s *Session) audit(action, target string, params interface{}, actionErr error) {
+	incidentID := ""
+	if s.incidentContext != nil {
+		incidentID = s.incidentContext.ID
+	}
+	if err := audit.Append(action, incidentID, target, params, actionErr); err != nil {
+		fmt.Fprintf(s.infoWriter(), "Warning: failed to write audit log: %v\n", err)
+	}
+}
+
+// cmdAudit renders the audit log: audit show [--incident <id>] [--since <age>]
+func (s *Session) cmdAudit(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	if len(args) == 0 || args[0] != "show" {
+		return rterrors.Validationf("usage: audit show [--incident <id>] [--since <age>] [--format table|json|yaml]")
+	}
+
+	var incidentID string
+	var since time.Time
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--incident", "--since":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			if args[i] == "--incident" {
+				incidentID = unquote(args[i+1])
+			} else if since, err = audit.ParseSince(args[i+1]); err != nil {
+				return rterrors.Wrap(rterrors.Validation, err)
+			}
+			i++
+		default:
+			return rterrors.Validationf("unknown audit show option: %s", args[i])
+		}
+	}
+
+	log := audit.Current()
+	if log == nil {
+		return fmt.Errorf("audit log is not available")
+	}
+	trail, err := audit.Read(log.Path)
+	if err != nil {
+		return err
+	}
+	records := trail.Filter(incidentID, since)
+
+	if format != formatTable {
+		return printStructured(format, map[string]interface{}{
+			"path":    trail.Path,
+			"intact":  trail.Intact(),
+			"breaks":  trail.Breaks,
+			"records": records,
+		})
+	}
+
+	fmt.Printf("Audit log: %s (%d of %d records)\n\n", trail.Path, len(records), len(trail.Records))
+	if len(records) > 0 {
+		fmt.Printf("%-5s %-20s %-20s %-12s %-22s %-28s %s\n", "Seq", "Time", "Action", "Actor", "Incident", "Target", "Outcome")
+		for _, record := range records {
+			fmt.Printf("%-5d %-20s %-20s %-12s %-22s %-28s %s\n", record.Seq, record.Time.Local().Format("2006-01-02 15:04:05"),
+				record.Action, record.Actor, valueOrDash(record.IncidentID), valueOrDash(record.Target), record.Outcome)
+		}
+	}
+
+	if trail.Intact() {
+		color.New(color.FgGreen).Printf("\n✓ Hash chain verified (%d records)\n", len(trail.Records))
+		return nil
+	}
+	color.New(color.FgRed).Printf("\nWARNING: the audit log has been tampered with or damaged:\n")
+	for _, b := range trail.Breaks {
+		fmt.Printf("  line %d: %s\n", b.Line, b.Reason)
+	}
+	return fmt.Errorf("audit log failed verification: %d problems", len(trail.Breaks))
+}
+
+// valueOrDash returns value, or "-" when it is empty
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/config"
+)
+
+// containmentEventType is the timeline event recorded by 'incident contain'
+const containmentEventType = "containment_action"
+
+// ClockMeasurement is the time elapsed from incident creation to a milestone
+type ClockMeasurement struct {
+	At      time.Time `json:"at"`
+	Elapsed string    `json:"elapsed"`
+	Seconds int64     `json:"seconds"`
+}
+
+// IncidentClocks holds the IR metrics derived from the incident timeline:
+// time to detection (first finding), containment and close
+type IncidentClocks struct {
+	Basis       string            `json:"basis"`
+	Detection   *ClockMeasurement `json:"time_to_detection,omitempty"`
+	Containment *ClockMeasurement `json:"time_to_containment,omitempty"`
+	Close       *ClockMeasurement `json:"time_to_close,omitempty"`
+	ComputedAt  time.Time         `json:"computed_at"`
+}
+
+// computeClocks derives the incident clocks from findings and timeline events
+// using the configured calendar or business-hours basis
+func (s *Session) computeClocks(incident *IncidentContext) *IncidentClocks {
+	basis := "calendar"
+	if s.config != nil && s.config.SLABasis == "business" {
+		basis = "business"
+	}
+
+	clocks := &IncidentClocks{
+		Basis:      basis,
+		ComputedAt: time.Now(),
+	}
+
+	var firstFinding, firstContainment, closedAt time.Time
+
+	for _, finding := range incident.Findings {
+		// Dismissed false positives do not count as a detection
+		if !findingHasResults(finding) || triageState(finding) == TriageFalsePositive {
+			continue
+		}
+		if firstFinding.IsZero() || finding.Timestamp.Before(firstFinding) {
+			firstFinding = finding.Timestamp
+		}
+	}
+
+	for _, event := range incident.Timeline {
+		switch event.EventType {
+		case containmentEventType:
+			if firstContainment.IsZero() || event.Timestamp.Before(firstContainment) {
+				firstContainment = event.Timestamp
+			}
+		case "incident_closed":
+			if event.Timestamp.After(closedAt) {
+				closedAt = event.Timestamp
+			}
+		}
+	}
+
+	// A reopened incident has no close time until it is closed again
+	if incident.Status != IncidentClosed {
+		closedAt = time.Time{}
+	}
+
+	clocks.Detection = s.measureClock(incident.CreatedAt, firstFinding, basis)
+	clocks.Containment = s.measureClock(incident.CreatedAt, firstContainment, basis)
+	clocks.Close = s.measureClock(incident.CreatedAt, closedAt, basis)
+
+	return clocks
+}
+
+// measureClock measures the time from start to a milestone, or returns nil
+// when the milestone has not been reached
+func (s *Session) measureClock(start, at time.Time, basis string) *ClockMeasurement {
+	if at.IsZero() {
+		return nil
+	}
+
+	elapsed := at.Sub(start)
+	if basis == "business" {
+		elapsed = s.businessDuration(start, at)
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	elapsed = elapsed.Round(time.Second)
+
+	return &ClockMeasurement{
+		At:      at,
+		Elapsed: elapsed.String(),
+		Seconds: int64(elapsed.Seconds()),
+	}
+}
+
+// businessDuration counts only the configured business hours between start and end
+func (s *Session) businessDuration(start, end time.Time) time.Duration {
+	businessHours, businessDays := "", []string(nil)
+	if s.config != nil {
+		businessHours, businessDays = s.config.BusinessHours, s.config.BusinessDays
+	}
+
+	opensAt, closesAt, err := config.ParseBusinessHours(businessHours)
+	if err != nil {
+		return end.Sub(start)
+	}
+	days, err := config.ParseBusinessDays(businessDays)
+	if err != nil {
+		return end.Sub(start)
+	}
+
+	start, end = start.Local(), end.Local()
+
+	var total time.Duration
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for !day.After(end) {
+		if days[day.Weekday()] {
+			windowStart, windowEnd := day.Add(opensAt), day.Add(closesAt)
+			if windowStart.Before(start) {
+				windowStart = start
+			}
+			if windowEnd.After(end) {
+				windowEnd = end
+			}
+			if windowEnd.After(windowStart) {
+				total += windowEnd.Sub(windowStart)
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return total
+}
+
+// findingHasResults reports whether a finding represents an actual detection.
+// Analysis runs that completed with zero results do not stop the detection clock.
+func findingHasResults(finding Finding) bool {
+	switch total := finding.Evidence["total_findings"].(type) {
+	case int:
+		return total > 0
+	case float64:
+		return total > 0
+	default:
+		return true
+	}
+}
+
+// printClocks displays the incident clocks
+func printClocks(clocks *IncidentClocks) {
+	if clocks == nil {
+		return
+	}
+
+	fmt.Printf("\nIncident Clocks (%s basis):\n", clocks.Basis)
+	printClock("Time to detection", clocks.Detection)
+	printClock("Time to containment", clocks.Containment)
+	printClock("Time to close", clocks.Close)
+}
+
+func printClock(label string, clock *ClockMeasurement) {
+	if clock == nil {
+		fmt.Printf("  %-20s pending\n", label+":")
+		return
+	}
+	fmt.Printf("  %-20s %s (at %s)\n", label+":", clock.Elapsed, clock.At.Format(time.RFC3339))
+}
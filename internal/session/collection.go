@@ -0,0 +1,299 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/jsonstream"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/schema"
+	"github.com/redtriage/redtriage/internal/version"
+)
+
+// collectionSection is one artifact of a 'collect' run. Its category
+// decides whether an incident's collection scope allows it.
+type collectionSection struct {
+	name     string
+	category string
+	message  string
+	collect  func() map[string]interface{}
+}
+
+// collectionSections are the artifacts 'collect' gathers, in order
+var collectionSections = []collectionSection{
+	{"system_health", "system", "Collecting system health information...", collectSystemHealth},
+	{"network", "network", "Collecting network configuration and connections...", collectNetworkInfo},
+	{"processes", "process", "Collecting running processes and services...", collectProcessInfo},
+	{"services", "execution", "Collecting system services and startup items...", collectServiceInfo},
+	{"security", "policy", "Collecting security and authentication data...", collectSecurityInfo},
+	{"filesystem", "filesystem", "Collecting file system and disk information...", collectFileSystemInfo},
+	{"registry", "registry", "Collecting registry information...", collectRegistryInfo},
+	{"event_logs", "logs", "Collecting system event logs...", collectEventLogInfo},
+	{"execution_history", "execution", "Collecting ShimCache and Amcache execution history...", collectExecutionHistory},
+	{"hidden_persistence", "persistence", "Collecting shell startup files and SSH authorized keys...", collectHiddenPersistence},
+	{"autostart_entries", "persistence", "Sweeping registry autostart entries...", collectAutostartEntries},
+	{"remote_access", "remote_access", "Collecting RDP history, sessions and remote access tools...", collectRemoteAccess},
+}
+
+// run collects the section's artifact
+func (c collectionSection) run() map[string]interface{} {
+	fmt.Println("✓ " + c.message)
+	artifact := c.collect()
+	time.Sleep(200 * time.Millisecond)
+	return artifact
+}
+
+// collectionTarget is the incident a collection is for, nil for none, the
+// tags it is filed under and the justification for collecting outside the
+// incident's collection scope
+type collectionTarget struct {
+	incident      *IncidentContext
+	tags          []string
+	scopeOverride string
+}
+
+// scopeRecord starts the record of what the incident's collection scope
+// keeps out of the collection, or returns nil when there is no scope
+func (t collectionTarget) scopeRecord() *collector.ScopeRecord {
+	if t.incident == nil || t.incident.Scope == nil {
+		return nil
+	}
+	return &collector.ScopeRecord{
+		Incident:  t.incident.ID,
+		Scope:     *t.incident.Scope,
+		Override:  t.scopeOverride,
+		Respected: t.scopeOverride == "",
+	}
+}
+
+// inScope reports whether the collection may gather an artifact, printing
+// and recording the ones the scope keeps out
+func inScope(record *collector.ScopeRecord, name, category string) bool {
+	if record == nil {
+		return true
+	}
+	ok, why := record.Scope.Permits(name, category)
+	if ok {
+		return true
+	}
+	record.Skipped = append(record.Skipped, collector.ScopeSkip{
+		Artifact: name,
+		Category: collector.ScopeCategoryOf(name, category),
+		Reason:   why,
+	})
+	if record.Override != "" {
+		fmt.Printf("Warning: collecting %s outside the collection scope (overridden): %s\n", name, why)
+		return true
+	}
+	fmt.Printf("⊘ Skipping %s: %s\n", name, why)
+	return false
+}
+
+// gatherCollection collects every artifact into one collection document
+func (s *Session) gatherCollection(collectionID string, target collectionTarget, captureDone chan *collector.NetworkCapture, captureDuration time.Duration) (map[string]interface{}, collector.HostIdentity) {
+	artifacts := make(map[string]interface{}, len(collectionSections)+1)
+	var collected []string
+	scope := target.scopeRecord()
+	for _, section := range collectionSections {
+		if !inScope(scope, section.name, section.category) {
+			continue
+		}
+		artifacts[section.name] = section.run()
+		collected = append(collected, section.name)
+	}
+
+	identity := s.collectionIdentity()
+	collection := collectionHeader(collectionID, target.tags)
+	collection["host"] = identity.Map()
+	collection["host_fingerprint"] = identity.Fingerprint
+	collection["status"] = "completed"
+	collection["artifacts"] = artifacts
+
+	if capture := waitNetworkCapture(captureDone, captureDuration); capture != nil {
+		artifacts["network_capture"] = capture
+		collected = append(collected, "network_capture")
+	}
+	collection["artifacts_collected"] = collected
+	if scope != nil {
+		collection["collection_scope"] = scope
+	}
+
+	if incident := collectionIncidentContext(target.incident); incident != nil {
+		collection["incident_context"] = incident
+	}
+	return collection, identity
+}
+
+// streamCollection collects the artifacts like gatherCollection but writes
+// each to the collection report as soon as it is collected and then drops
+// it, so memory holds one artifact at a time however large the host. It
+// returns the collection without its artifacts, which are only in the
+// report.
+func (s *Session) streamCollection(collectionID string, target collectionTarget, captureDone chan *collector.NetworkCapture, captureDuration time.Duration) (map[string]interface{}, collector.HostIdentity, string, error) {
+	collection := collectionHeader(collectionID, target.tags)
+	var identity collector.HostIdentity
+
+	path, err := s.reportsManager.StreamCollectionReport(collectionReportName(collectionID), func(w io.Writer) error {
+		report := jsonstream.NewObjectWriter(w)
+		for _, key := range []string{"schema_version", "collection_id", "timestamp", "platform", "hostname", "redtriage_version", "tags"} {
+			if value, ok := collection[key]; ok {
+				report.Field(key, value)
+			}
+		}
+
+		var collected []string
+		scope := target.scopeRecord()
+		report.Begin("artifacts")
+		for _, section := range collectionSections {
+			if !inScope(scope, section.name, section.category) {
+				continue
+			}
+			if err := report.Field(section.name, section.run()); err != nil {
+				return err
+			}
+			collected = append(collected, section.name)
+		}
+		if capture := waitNetworkCapture(captureDone, captureDuration); capture != nil {
+			report.Field("network_capture", capture)
+			collected = append(collected, "network_capture")
+		}
+		report.End()
+
+		identity = s.collectionIdentity()
+		collection["host"] = identity.Map()
+		collection["host_fingerprint"] = identity.Fingerprint
+		collection["artifacts_collected"] = collected
+		collection["status"] = "completed"
+		if scope != nil {
+			collection["collection_scope"] = scope
+		}
+		if incident := collectionIncidentContext(target.incident); incident != nil {
+			collection["incident_context"] = incident
+		}
+		for _, key := range []string{"host", "host_fingerprint", "artifacts_collected", "status", "collection_scope", "incident_context"} {
+			if value, ok := collection[key]; ok {
+				report.Field(key, value)
+			}
+		}
+		return report.Close()
+	})
+	if err != nil {
+		return nil, identity, "", err
+	}
+	return collection, identity, path, nil
+}
+
+// collectionHeader returns the fields that describe a new collection
+func collectionHeader(collectionID string, tags []string) map[string]interface{} {
+	hostname, _ := os.Hostname()
+	header := map[string]interface{}{
+		"schema_version":    schema.CollectionVersion,
+		"collection_id":     collectionID,
+		"timestamp":         time.Now().Format(time.RFC3339),
+		"platform":          runtime.GOOS,
+		"hostname":          hostname,
+		"redtriage_version": version.GetShortVersion(),
+	}
+	if len(tags) > 0 {
+		header["tags"] = tags
+	}
+	return header
+}
+
+// collectionIdentity identifies the host and flags stored collections that
+// claim its hostname from another machine
+func (s *Session) collectionIdentity() collector.HostIdentity {
+	identity := collector.GatherHostIdentity("")
+	identity.HostnameConflicts = s.collectionHostConflicts(identity)
+	for _, conflict := range identity.HostnameConflicts {
+		fmt.Printf("Warning: collection %s also claims hostname %s but has fingerprint %s; it may come from a different machine\n",
+			conflict.Source, conflict.Hostname, conflict.Fingerprint)
+	}
+	if identity.Environment != nil {
+		for _, warning := range identity.Environment.Warnings() {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+	}
+	return identity
+}
+
+// waitNetworkCapture waits for a packet capture started with the collection,
+// if any, and reports how it went
+func waitNetworkCapture(captureDone chan *collector.NetworkCapture, duration time.Duration) *collector.NetworkCapture {
+	if captureDone == nil {
+		return nil
+	}
+	fmt.Printf("✓ Waiting for network capture (%s) to finish...\n", duration)
+	capture := <-captureDone
+	switch {
+	case capture == nil:
+	case capture.Skipped:
+		fmt.Printf("Warning: Network capture skipped: %s\n", capture.Reason)
+	default:
+		fmt.Printf("✓ Captured %d bytes on %s with %s\n", capture.Size, capture.Interface, capture.Tool)
+		fmt.Printf("  Capture: %s (sha256: %s)\n", capture.Path, capture.Checksum)
+	}
+	return capture
+}
+
+// collectionIncidentContext describes the incident a collection is for
+func collectionIncidentContext(incident *IncidentContext) map[string]interface{} {
+	if incident == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"incident_id":    incident.ID,
+		"incident_title": incident.Title,
+		"severity":       incident.Severity,
+		"analyst":        incident.Analyst,
+	}
+}
+
+// collectionTagPattern is what a collection tag may look like, e.g.
+// persistence or attack.t1053
+var collectionTagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// addCollectionTags adds the comma-separated tags of a --tag value, skipping
+// ones already given
+func addCollectionTags(tags []string, value string) ([]string, error) {
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if !collectionTagPattern.MatchString(tag) {
+			return nil, rterrors.Validationf("invalid collection tag %q: use letters, digits, '.', '_', ':' and '-'", tag)
+		}
+		duplicate := false
+		for _, existing := range tags {
+			duplicate = duplicate || strings.EqualFold(existing, tag)
+		}
+		if !duplicate {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// collectionIncident returns the incident a collection is for: the one
+// named with --incident, loaded without switching to it, or else the
+// active incident. Only open incidents take new collections.
+func (s *Session) collectionIncident(incidentID string) (*IncidentContext, error) {
+	if incidentID == "" || (s.incidentContext != nil && s.incidentContext.ID == incidentID) {
+		return s.incidentContext, nil
+	}
+	if !s.incidentExists(incidentID) {
+		return nil, rterrors.NotFoundf("incident not found: %s", incidentID)
+	}
+	incident, err := s.loadIncidentContext(incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+	}
+	if status := incidentStatus(incident); status != IncidentOpen {
+		return nil, rterrors.Validationf("incident %s is %s; reopen it before adding collections", incidentID, status)
+	}
+	return incident, nil
+}
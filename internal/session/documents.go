@@ -0,0 +1,270 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/schema"
+)
+
+// decodeIncident reads an incident file of any supported schema version.
+// Older incidents are upgraded in memory and the upgrade is recorded on the
+// timeline, so it is kept the next time the incident is saved.
+func decodeIncident(data []byte) (*IncidentContext, error) {
+	raw, migration, err := schema.ReadIncident(data)
+	if err != nil {
+		return nil, err
+	}
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upgrade incident data: %w", err)
+	}
+
+	var incident IncidentContext
+	if err := json.Unmarshal(upgraded, &incident); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal incident data: %w", err)
+	}
+
+	if migration.Upgraded() {
+		incident.AddTimelineEvent(TimelineEvent{
+			ID:          newID("EVT", "150405"),
+			Timestamp:   time.Now(),
+			EventType:   "schema_migrated",
+			Description: fmt.Sprintf("Incident upgraded from schema v%d to v%d", migration.From, migration.To),
+			Source:      "redtriage",
+			Data:        map[string]interface{}{"notes": migration.Notes},
+		})
+	}
+
+	return &incident, nil
+}
+
+// readCollection reads a stored collection report of any supported schema
+// version
+func (s *Session) readCollection(collectionID string) (map[string]interface{}, *schema.Migration, error) {
+	path := s.reportsManager.Resolve(filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), collectionReportName(collectionID)))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read collection %s: %w", collectionID, err)
+	}
+
+	collection, migration, err := schema.ReadCollection(data, collectionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("collection %s: %w", collectionID, err)
+	}
+	return collection, migration, nil
+}
+
+// checkCollectionVersion refuses collections written by a newer RedTriage
+// and reports how an older one was upgraded. Collections stored as a
+// directory of artifacts carry no report and are accepted as-is.
+func (s *Session) checkCollectionVersion(collectionID string) error {
+	path := s.reportsManager.Resolve(filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), collectionReportName(collectionID)))
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+
+	_, migration, err := s.readCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	if migration.Upgraded() {
+		fmt.Printf("Note: %s\n", migration)
+	}
+	return nil
+}
+
+// exportIncident writes the active incident to a file another session can
+// import: incident export <file> [--decrypt]. It is the export of
+// 'context --output', named after the import it pairs with.
+func (s *Session) exportIncident(args []string) error {
+	var file string
+	decrypt := false
+	for _, arg := range args {
+		switch arg {
+		case "--decrypt":
+			decrypt = true
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return rterrors.Validationf("unknown incident export flag: %s", arg)
+			}
+			file = arg
+		}
+	}
+	if file == "" {
+		return rterrors.Validationf("incident export requires an output file")
+	}
+	return s.exportIncidentContext(file, decrypt)
+}
+
+// importIncident copies an exported incident file into the incidents
+// directory: incident import <file> [--rename|--merge]. When the incident's
+// ID is already taken it is imported under a new ID or merged into the
+// existing incident, as chosen by flag or at a prompt. The file may also be a
+// .zip or .tar.gz archive holding a single incident JSON file.
+func (s *Session) importIncident(args []string) error {
+	var file, mode string
+	for _, arg := range args {
+		switch arg {
+		case "--rename":
+			mode = "rename"
+		case "--merge":
+			mode = "merge"
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return rterrors.Validationf("unknown incident import flag: %s", arg)
+			}
+			file = arg
+		}
+	}
+	if file == "" {
+		return rterrors.Validationf("incident import requires an incident file")
+	}
+
+	data, err := readIncidentFile(file)
+	if err != nil {
+		return err
+	}
+	data, key, err := s.openIncident(data)
+	if err != nil {
+		return err
+	}
+
+	incident, err := decodeIncident(data)
+	if err != nil {
+		return err
+	}
+	if incident.ID == "" || filepath.Base(incident.ID) != incident.ID {
+		return rterrors.Validationf("incident file has no valid incident ID: %s", file)
+	}
+	// An encrypted copy is stored with the key it was opened with; a
+	// readable one is encrypted when new incidents are
+	incident.key = key
+	if incident.Encryption == "" {
+		incident.Encryption = s.newIncidentEncryption(false)
+	}
+
+	originalID := incident.ID
+	if s.incidentExists(originalID) {
+		// Ask before taking the lock so other writers are not held up
+		if mode, err = s.importConflictMode(originalID, mode); err != nil {
+			return err
+		}
+	}
+
+	// Check and write under one lock so concurrent imports cannot both
+	// claim the same ID
+	var merged *IncidentContext
+	var summary mergeSummary
+	err = s.reportsManager.WithLock(func() error {
+		if !s.incidentExists(incident.ID) {
+			return s.writeIncidentContext(incident)
+		}
+
+		switch mode {
+		case "rename":
+			incident.ID = s.importedIncidentID(originalID)
+			return s.writeIncidentContext(incident)
+		case "merge":
+			existing := s.incidentContext
+			if existing == nil || existing.ID != originalID {
+				if existing, err = s.loadIncidentContext(originalID); err != nil {
+					return err
+				}
+			}
+			summary = mergeIncident(existing, incident)
+			existing.AddTimelineEvent(TimelineEvent{
+				ID:          newID("EVT", "150405"),
+				Timestamp:   time.Now(),
+				EventType:   "incident_merged",
+				Description: fmt.Sprintf("Merged imported copy from %s", filepath.Base(file)),
+				Source:      "redtriage",
+				Data: map[string]interface{}{
+					"added":      summary.Added,
+					"duplicates": summary.Duplicates,
+					"conflicts":  summary.Conflicts,
+				},
+			})
+			merged = existing
+			return s.writeIncidentContext(existing)
+		default:
+			return rterrors.Validationf("incident %s already exists; use --rename or --merge", originalID)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if merged != nil {
+		fmt.Printf("✓ Merged %s into incident %s: %d items added, %d duplicates skipped, %d conflicts\n",
+			filepath.Base(file), merged.ID, summary.Added, summary.Duplicates, summary.Conflicts)
+		if summary.Conflicts > 0 {
+			fmt.Println("Conflicting items were kept with an -imported ID suffix and a merge_conflict marker")
+		}
+		return nil
+	}
+
+	if incident.ID != originalID {
+		fmt.Printf("✓ Imported incident %s as %s: %s\n", output.SanitizeLine(originalID), incident.ID, output.SanitizeLine(incident.Title))
+	} else {
+		fmt.Printf("✓ Imported incident %s: %s\n", incident.ID, output.SanitizeLine(incident.Title))
+	}
+	fmt.Printf("Use 'incident switch --id %s' to work on it\n", incident.ID)
+	return nil
+}
+
+// readIncidentFile reads an incident file to import. Archives are unpacked
+// with the safe extractor into a temporary directory and must contain
+// exactly one .json file.
+func readIncidentFile(file string) ([]byte, error) {
+	if !archive.IsArchive(file) {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read incident file: %w", err)
+		}
+		return data, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "redtriage-import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	limits := archive.DefaultLimits
+	limits.MaxTotalSize = 256 << 20
+	limits.MaxEntrySize = limits.MaxTotalSize
+	result, err := archive.Extract(file, tmp, limits)
+	if errors.Is(err, archive.ErrUnsafeEntry) {
+		return nil, rterrors.Validationf("refusing to import %s: %v", file, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract incident archive: %w", err)
+	}
+
+	var incidents []string
+	for _, rel := range result.Files {
+		full := filepath.Join(tmp, filepath.FromSlash(rel))
+		if info, err := os.Lstat(full); err == nil && info.Mode().IsRegular() && strings.EqualFold(filepath.Ext(rel), ".json") {
+			incidents = append(incidents, full)
+		}
+	}
+	if len(incidents) != 1 {
+		return nil, rterrors.Validationf("incident archive must contain exactly one .json file, found %d: %s", len(incidents), file)
+	}
+
+	data, err := os.ReadFile(incidents[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incident file: %w", err)
+	}
+	return data, nil
+}
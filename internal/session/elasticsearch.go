@@ -0,0 +1,99 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// takeElasticsearchArgs removes --elasticsearch [url] and --index <name>
+// from the findings arguments. Without a URL the one configured under
+// elasticsearch.url is used. The target is nil when --elasticsearch is not
+// given.
+func (s *Session) takeElasticsearchArgs(args []string) (*reporter.ElasticsearchTarget, []string, error) {
+	var rest []string
+	enabled := false
+	esURL, index := "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--elasticsearch":
+			enabled = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				esURL = args[i+1]
+				i++
+			}
+		case "--index":
+			if i+1 >= len(args) {
+				return nil, nil, rterrors.Validationf("--index requires an index name")
+			}
+			index = args[i+1]
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	if !enabled {
+		if index != "" {
+			return nil, nil, rterrors.Validationf("--index requires --elasticsearch")
+		}
+		return nil, rest, nil
+	}
+
+	cfg := s.config.Elasticsearch
+	if esURL == "" {
+		esURL = cfg.URL
+	}
+	if esURL == "" {
+		return nil, nil, rterrors.Validationf("--elasticsearch requires a URL, or elasticsearch.url in the configuration")
+	}
+	if err := reporter.ValidateElasticsearchURL(esURL); err != nil {
+		return nil, nil, rterrors.Validationf("%w", err)
+	}
+	if index == "" {
+		index = cfg.Index
+	}
+
+	return &reporter.ElasticsearchTarget{
+		URL:      esURL,
+		Index:    index,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		APIKey:   cfg.APIKey,
+	}, rest, nil
+}
+
+// indexFindings sends the findings of a collection to Elasticsearch. It runs
+// after the local report is saved and only warns on failure.
+func (s *Session) indexFindings(target *reporter.ElasticsearchTarget, findings []map[string]interface{}, collectionID string) {
+	if len(findings) == 0 {
+		fmt.Printf("No findings to index into Elasticsearch index %s\n", target.Index)
+		return
+	}
+
+	var host *collector.HostIdentity
+	if collection, _, err := s.readCollection(collectionID); err == nil {
+		if identity, ok := collectionIdentity(collection); ok {
+			host = &identity
+		}
+	}
+
+	docs := make([]map[string]interface{}, 0, len(findings))
+	for _, finding := range findings {
+		docs = append(docs, reporter.ECSFinding(finding, collectionID, host))
+	}
+
+	fmt.Printf("Indexing %d findings into %s (index %s)...\n", len(docs), target.URL, target.Index)
+	result, err := target.IndexFindings(docs)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	fmt.Printf("✓ Indexed %d findings into Elasticsearch index %s\n", result.Indexed, target.Index)
+	if result.Failed > 0 {
+		fmt.Printf("Warning: %d findings were not indexed: %s\n", result.Failed, strings.Join(result.Errors, "; "))
+	}
+}
@@ -0,0 +1,266 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/jsonstream"
+	"github.com/redtriage/redtriage/internal/naming"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// eventRecordsArtifact is the artifact holding event log entries as
+// structured records. Exports of busy hosts reach hundreds of thousands of
+// records, so it is read one record at a time.
+const eventRecordsArtifact = "event_records"
+
+// eventRecordKeys are the keys an event records file may hold its list
+// under when it is an object rather than a bare list
+var eventRecordKeys = []string{"event_records", "events", "records"}
+
+// eventIDFields are the selection fields that make a Sigma rule an event
+// log rule
+var eventIDFields = []string{"EventID", "event_id"}
+
+// eventRecordFile returns the event records file of a collection stored as
+// a directory of artifacts, or "" when there is none
+func (s *Session) eventRecordFile(collectionID string) string {
+	path := s.reportsManager.Resolve(filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), collectionID, eventRecordsArtifact+".json"))
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		return path
+	}
+	return ""
+}
+
+// streamEventRecords calls fn with each event record of a collection. A
+// separate event records file is decoded as a stream; records embedded in
+// the collection report are small enough to have been read with it.
+func (s *Session) streamEventRecords(ctx context.Context, collectionID string, fn func(map[string]interface{}) error) (int, error) {
+	if path := s.eventRecordFile(collectionID); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open event records: %w", err)
+		}
+		defer file.Close()
+		return jsonstream.Records(ctx, file, eventRecordKeys, fn)
+	}
+
+	artifact, err := s.loadCollectionArtifact(collectionID, eventRecordsArtifact)
+	if err != nil {
+		return 0, nil
+	}
+	count := 0
+	for _, key := range eventRecordKeys {
+		list, _ := artifact[key].([]interface{})
+		for _, item := range list {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+			if record, ok := item.(map[string]interface{}); ok {
+				count++
+				if err := fn(record); err != nil {
+					return count, err
+				}
+			}
+		}
+	}
+	return count, nil
+}
+
+// isEventLogRule reports whether a rule selects event log entries by event ID
+func isEventLogRule(rule SigmaRule) bool {
+	selection, _ := rule.Detection["selection"].(map[string]interface{})
+	for _, field := range eventIDFields {
+		if _, ok := selection[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeEventLogRule matches a rule's selection against the event records
+// of a collection, one record at a time. Every selection field must match;
+// a list matches when any of its values does.
+func (s *Session) analyzeEventLogRule(ctx context.Context, rule SigmaRule, collectionID string) []map[string]interface{} {
+	var findings []map[string]interface{}
+	selection, _ := rule.Detection["selection"].(map[string]interface{})
+	fields := make([]string, 0, len(selection))
+	for field := range selection {
+		fields = append(fields, field)
+	}
+
+	// Records are referenced by their position in the stream
+	next := 0
+	_, err := s.streamEventRecords(ctx, collectionID, func(record map[string]interface{}) error {
+		index := next
+		next++
+		if !eventMatchesSelection(record, selection) {
+			return nil
+		}
+		findings = append(findings, map[string]interface{}{
+			"rule_title":   rule.Title,
+			"rule_id":      rule.ID,
+			"level":        rule.Level,
+			"description":  "Event log entry matched detection rule",
+			"evidence":     reporter.EvidenceSnapshot(record, fields),
+			"evidence_ref": collector.NewRecordRef(collectionID, eventRecordsArtifact, "", index),
+			"timestamp":    time.Now().Format(time.RFC3339),
+			"category":     "log",
+		})
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("Warning: failed to read event records for rule %s: %v\n", rule.Title, err)
+	}
+	return findings
+}
+
+// eventMatchesSelection reports whether an event record has every field of
+// a selection. Fields are looked up on the record and then in its event
+// data; the event ID selection fields match the record's event_id.
+func eventMatchesSelection(record, selection map[string]interface{}) bool {
+	return matchSelection(record, selection, nil)
+}
+
+// fieldTrace is how one selection field fared against one record
+type fieldTrace struct {
+	Field   string `json:"field"`
+	Want    string `json:"want"`
+	Have    string `json:"have,omitempty"`
+	Present bool   `json:"present"`
+	Matched bool   `json:"matched"`
+}
+
+// matchSelection is eventMatchesSelection with an optional trace. With a
+// trace every field is evaluated, in name order, and recorded instead of
+// stopping at the first one that does not match.
+func matchSelection(record, selection map[string]interface{}, trace *[]fieldTrace) bool {
+	if len(selection) == 0 {
+		return false
+	}
+	fields := make([]string, 0, len(selection))
+	for field := range selection {
+		fields = append(fields, field)
+	}
+	if trace != nil {
+		sort.Strings(fields)
+	}
+
+	data, _ := record["data"].(map[string]interface{})
+	matched := true
+	for _, field := range fields {
+		want := selection[field]
+		var have interface{}
+		switch {
+		case containsField(eventIDFields, field):
+			have = record["event_id"]
+		case record[field] != nil:
+			have = record[field]
+		default:
+			have = data[field]
+		}
+		ok := have != nil && selectionValueMatches(eventValue(have), want)
+		if trace != nil {
+			step := fieldTrace{Field: field, Want: selectionValueString(want), Present: have != nil, Matched: ok}
+			if have != nil {
+				step.Have = eventValue(have)
+			}
+			*trace = append(*trace, step)
+		}
+		if !ok {
+			if trace == nil {
+				return false
+			}
+			matched = false
+		}
+	}
+	return matched
+}
+
+// selectionValueString shows a selection value or list of values
+func selectionValueString(want interface{}) string {
+	list, ok := want.([]interface{})
+	if !ok {
+		return eventValue(want)
+	}
+	values := make([]string, len(list))
+	for i, value := range list {
+		values[i] = eventValue(value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// selectionValueMatches compares a record value with a selection value or
+// list of values, case-insensitively, with * as a wildcard
+func selectionValueMatches(have string, want interface{}) bool {
+	if list, ok := want.([]interface{}); ok {
+		for _, value := range list {
+			if selectionValueMatches(have, value) {
+				return true
+			}
+		}
+		return false
+	}
+	pattern := strings.ToLower(eventValue(want))
+	have = strings.ToLower(have)
+	if !strings.Contains(pattern, "*") {
+		return have == pattern
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(have, parts[0]) {
+		return false
+	}
+	have = have[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(have, part)
+		if i < 0 {
+			return false
+		}
+		have = have[i+len(part):]
+	}
+	return strings.HasSuffix(have, parts[len(parts)-1])
+}
+
+// eventValue formats a record or rule value for comparison
+func eventValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exportEventRecordStream exports the event records file of a collection
+// without loading it, passing each record through filter when it is set,
+// and returns nil when the collection has none
+func (s *Session) exportEventRecordStream(collectionID string, fields []string, format, outputDir string, namer *naming.Namer, filter reporter.RecordFilter) (*reporter.ReportInfo, error) {
+	path := s.eventRecordFile(collectionID)
+	if path == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := s.commandContext()
+	defer cancel()
+
+	stream := reporter.RecordStream{Name: eventRecordsArtifact, Path: path, Keys: eventRecordKeys, Filter: filter}
+	report, err := reporter.ExportRecordStream(ctx, stream, fields, format, outputDir, namer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", eventRecordsArtifact, err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// collectExecutionHistory gathers the ShimCache and Amcache entries, with
+// the error of a source that could not be read in place of its entries
+func collectExecutionHistory() map[string]interface{} {
+	if runtime.GOOS != "windows" {
+		return map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"note":      "ShimCache and Amcache are only available on Windows",
+		}
+	}
+
+	history := map[string]interface{}{"timestamp": time.Now().Format(time.RFC3339)}
+	for _, result := range collector.CollectLiveExecutionHistory(context.Background(), footprint.Current().IsMinimal()) {
+		if result.Error != nil {
+			history[result.Artifact.Name+"_error"] = result.Error.Error()
+			continue
+		}
+		history[result.Artifact.Name] = result.Data
+		if format := result.Metadata.Tags["format"]; format != "" {
+			history[result.Artifact.Name+"_format"] = format
+		}
+	}
+	return history
+}
+
+// collectionExecutionTimeline turns the ShimCache and Amcache entries of a
+// collection into timeline entries. Amcache entries are placed at the time
+// the file was first seen, ShimCache entries at the file's last
+// modification, which is all the ShimCache records; entries without a time
+// are left out.
+func (s *Session) collectionExecutionTimeline(collection IncidentArtifactEntry, incidentID, host string) ([]reporter.TimelineEntry, error) {
+	artifact, err := s.loadCollectionArtifact(collection.ID, "execution_history")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []reporter.TimelineEntry
+	for _, source := range []string{collector.SourceShimCache, collector.SourceAmcache} {
+		for _, entry := range collector.ExecutionEntries(collector.ArtifactResult{Data: artifact[source]}) {
+			when, desc := entry.Time()
+			if when.IsZero() {
+				continue
+			}
+			attributes := map[string]interface{}{
+				"collection_id": collection.ID,
+				"path":          entry.Path,
+				"position":      entry.Position,
+			}
+			if entry.SHA1 != "" {
+				attributes["sha1"] = entry.SHA1
+			}
+			if entry.Executed != nil {
+				attributes["executed"] = *entry.Executed
+			}
+			entries = append(entries, reporter.TimelineEntry{
+				Timestamp:     when,
+				TimeKnown:     true,
+				TimestampDesc: desc,
+				Source:        timelineSourceExecution,
+				SourceType:    executionSourceType(source),
+				Type:          "execution",
+				Host:          host,
+				IncidentID:    incidentID,
+				Short:         fmt.Sprintf("[%s] %s", executionSourceType(source), entry.Path),
+				Description:   fmt.Sprintf("%s recorded %s", executionSourceType(source), entry.Path),
+				Reference:     collection.ID,
+				Attributes:    attributes,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// executionSourceType returns the display name of an execution source
+func executionSourceType(source string) string {
+	if source == collector.SourceAmcache {
+		return "Amcache"
+	}
+	return "ShimCache"
+}
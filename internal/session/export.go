@@ -0,0 +1,495 @@
+package session
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/naming"
+	"github.com/redtriage/redtriage/internal/redact"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// bundleFindingsPath is where the packager stores findings inside a bundle
+const bundleFindingsPath = "findings/findings.json"
+
+// loadExportFindings loads findings from a findings report, a bundle directory
+// or bundle ZIP. Without an input the latest findings report is used.
+func (s *Session) loadExportFindings(input string) ([]detector.Finding, string, error) {
+	if input == "" {
+		path, err := s.latestFindingsReport()
+		if err != nil {
+			return nil, "", err
+		}
+		input = path
+	}
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to access %s: %w", input, err)
+	}
+
+	var data []byte
+	switch {
+	case info.IsDir():
+		if err := checkBundleManifest(readBundleDirFile(input, "manifest.json")); err != nil {
+			return nil, "", err
+		}
+		data, err = readBundleDirFile(input, bundleFindingsPath)
+	case strings.EqualFold(filepath.Ext(input), ".zip"):
+		if err := checkBundleManifest(readBundleFile(input, "manifest.json")); err != nil {
+			return nil, "", err
+		}
+		data, err = readBundleFile(input, bundleFindingsPath)
+	default:
+		data, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read findings from %s: %w", input, err)
+	}
+
+	findings, err := parseExportFindings(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse findings from %s: %w", input, err)
+	}
+
+	return findings, input, nil
+}
+
+// latestFindingsReport returns the most recent report written by 'findings'
+func (s *Session) latestFindingsReport() (string, error) {
+	dir := s.reportsManager.GetTestReportsDirectory()
+	files, err := s.reportsManager.ListReports("tests")
+	if err != nil {
+		return "", fmt.Errorf("failed to list findings reports: %w", err)
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, file := range files {
+		if !strings.HasPrefix(file, "findings-") || !strings.HasSuffix(file, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, file)
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = path, info.ModTime()
+		}
+	}
+
+	if latest == "" {
+		return "", rterrors.NotFoundf("no findings reports found. Run 'findings' first or pass --input")
+	}
+	return latest, nil
+}
+
+// checkBundleManifest refuses bundles written by a newer RedTriage and
+// reports how an older manifest was upgraded. Bundles without a readable
+// manifest are left to the findings reader.
+func checkBundleManifest(data []byte, err error) error {
+	if err != nil {
+		return nil
+	}
+
+	_, migration, err := packager.ReadManifest(data)
+	if err != nil {
+		return err
+	}
+	if migration.Upgraded() {
+		fmt.Printf("Note: %s\n", migration)
+	}
+	return nil
+}
+
+// readBundleDirFile reads a file out of an unpacked bundle directory
+func readBundleDirFile(dir, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+}
+
+// readBundleFile reads a file out of a bundle ZIP
+func readBundleFile(zipPath, name string) ([]byte, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if filepath.ToSlash(file.Name) != name {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+
+	return nil, rterrors.NotFoundf("bundle has no %s", name)
+}
+
+// parseExportFindings accepts either the detector findings array stored in
+// bundles or a session findings report with its Sigma rule matches
+func parseExportFindings(data []byte) ([]detector.Finding, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var findings []detector.Finding
+		if err := json.Unmarshal(data, &findings); err != nil {
+			return nil, err
+		}
+		return findings, nil
+	}
+
+	var report struct {
+		Findings []map[string]interface{} `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	findings := make([]detector.Finding, 0, len(report.Findings))
+	for _, match := range report.Findings {
+		severity, err := detector.ParseSeverity(stringField(match, "level"))
+		if err != nil {
+			return nil, fmt.Errorf("finding of rule %s has an %w", stringField(match, "rule_id"), err)
+		}
+		finding := detector.Finding{
+			RuleID:      stringField(match, "rule_id"),
+			RuleName:    stringField(match, "rule_title"),
+			Severity:    severity.String(),
+			Category:    stringField(match, "category"),
+			Description: stringField(match, "description"),
+			Metadata:    map[string]interface{}{"evidence": match["evidence"]},
+		}
+		if ref := collector.DecodeRecordRef(match["evidence_ref"]); ref != nil {
+			finding.Evidence = []detector.Evidence{{
+				Type:        "record",
+				Source:      ref.Artifact,
+				Value:       ref.ID,
+				Description: "Matched record",
+				Confidence:  1,
+				Metadata:    map[string]interface{}{"collection_id": ref.Collection},
+				Ref:         ref,
+			}}
+		}
+		finding.Timestamp, _ = time.Parse(time.RFC3339, stringField(match, "timestamp"))
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+func stringField(values map[string]interface{}, key string) string {
+	value, _ := values[key].(string)
+	return value
+}
+
+// resolveExportCollection returns the collection artifacts are exported
+// from: the requested one, the simulated one or the latest
+func (s *Session) resolveExportCollection(collectionID string) (string, error) {
+	if collectionID == "" {
+		collectionID = s.simulatedCollection
+	}
+	if collectionID == "" {
+		collectionID = s.findLatestCollection()
+		if collectionID == "" {
+			return "", rterrors.NotFoundf("no collection artifacts found. Please run 'collect' command first")
+		}
+	}
+	if !s.collectionExists(collectionID) {
+		return "", rterrors.NotFoundf("collection not found: %s", collectionID)
+	}
+	return collectionID, nil
+}
+
+// collectionRecordTables returns the record lists of the named artifacts of
+// a collection, or of all its artifacts when names is empty. Each list
+// becomes a table named <artifact>, or <artifact>-<list> when the artifact
+// holds several; an artifact without lists becomes a single record of its
+// fields.
+func (s *Session) collectionRecordTables(collectionID string, names []string) ([]reporter.RecordTable, error) {
+	collection, _, err := s.readCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	stored, _ := collection["artifacts"].(map[string]interface{})
+
+	var available []string
+	for name := range stored {
+		available = append(available, name)
+	}
+	sort.Strings(available)
+	if len(names) == 0 {
+		names = available
+	}
+
+	var tables []reporter.RecordTable
+	for _, name := range names {
+		if _, ok := stored[name]; !ok {
+			return nil, rterrors.NotFoundf("collection %s has no %s artifact (available: %s)", collectionID, name, strings.Join(available, ", "))
+		}
+		artifact, err := s.loadCollectionArtifact(collectionID, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, artifactRecordTables(name, artifact)...)
+	}
+	return tables, nil
+}
+
+// artifactRecordTables splits an artifact into its record lists
+func artifactRecordTables(name string, artifact map[string]interface{}) []reporter.RecordTable {
+	var keys []string
+	for key := range artifact {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tables []reporter.RecordTable
+	for _, key := range keys {
+		list, ok := artifact[key].([]interface{})
+		if !ok {
+			continue
+		}
+		var records []map[string]interface{}
+		for _, item := range list {
+			if record, ok := item.(map[string]interface{}); ok {
+				records = append(records, record)
+			}
+		}
+		if len(records) == 0 {
+			continue
+		}
+		tableName := name + "-" + key
+		if key == name {
+			tableName = name
+		}
+		tables = append(tables, reporter.RecordTable{Name: tableName, Records: records})
+	}
+
+	if len(tables) == 0 {
+		return []reporter.RecordTable{{Name: name, Records: []map[string]interface{}{artifact}}}
+	}
+	return tables
+}
+
+// projectRecordTables keeps the tables that have at least one of the
+// requested fields and warns about the fields each of them lacks, and about
+// fields no table has, so nothing is dropped silently
+func projectRecordTables(tables []reporter.RecordTable, fields []string) []reporter.RecordTable {
+	found := make(map[string]bool)
+	var kept []reporter.RecordTable
+	for _, table := range tables {
+		missing := table.MissingFields(fields)
+		if len(missing) == len(fields) {
+			continue
+		}
+		for _, field := range fields {
+			if !containsField(missing, field) {
+				found[field] = true
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Printf("Warning: %s has no field(s) %s; their columns are left empty (available: %s)\n",
+				table.Name, strings.Join(missing, ", "), strings.Join(table.Columns(), ", "))
+		}
+		kept = append(kept, table)
+	}
+
+	var unknown []string
+	for _, field := range fields {
+		if !found[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Printf("Warning: unknown field(s) %s: not present in any selected artifact\n", strings.Join(unknown, ", "))
+	}
+	if skipped := len(tables) - len(kept); skipped > 0 {
+		fmt.Printf("Skipped %d record list(s) with none of the requested fields\n", skipped)
+	}
+	return kept
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// removeField returns fields without field
+func removeField(fields []string, field string) []string {
+	var kept []string
+	for _, f := range fields {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// removeRecordTable returns tables without the tables of an artifact
+func removeRecordTable(tables []reporter.RecordTable, artifact string) []reporter.RecordTable {
+	var kept []reporter.RecordTable
+	for _, table := range tables {
+		if table.Name != artifact && !strings.HasPrefix(table.Name, artifact+"-") {
+			kept = append(kept, table)
+		}
+	}
+	return kept
+}
+
+// parseFieldList splits a comma-separated --fields value, keeping the order
+// and dropping repeats
+func parseFieldList(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, rterrors.Validationf("invalid --fields value '%s': empty field name", value)
+		}
+		if !containsField(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// exportNamer names export files from --name, or else the configured
+// filename_templates.export
+func (s *Session) exportNamer(name, collectionID string) (*naming.Namer, error) {
+	template := name
+	if template == "" && s.config != nil {
+		template = s.config.FilenameTemplates.Export
+	}
+	fields := naming.Fields{Collection: collectionID}
+	if s.incidentContext != nil {
+		fields.Incident = s.incidentContext.ID
+	}
+	namer, err := naming.New(template, fields)
+	if err != nil && name != "" {
+		return nil, rterrors.Validationf("invalid --name: %w", err)
+	}
+	return namer, err
+}
+
+// exportArtifacts writes the record lists of the selected artifacts of a
+// collection, projected to the requested fields and, with a ruleset,
+// redacted as they are written
+func (s *Session) exportArtifacts(collectionID, artifacts, fieldList, format, outputDir, name string, ruleset *redact.Ruleset) error {
+	if format != "json" && format != "csv" && format != reporter.CSVExcel && format != "md" {
+		return rterrors.Validationf("invalid format: %s (valid: json, csv, csv-excel, md)", format)
+	}
+	var fields []string
+	if fieldList != "" {
+		var err error
+		if fields, err = parseFieldList(fieldList); err != nil {
+			return err
+		}
+	}
+	var names []string
+	if artifacts != "" {
+		names = strings.Split(artifacts, ",")
+	}
+
+	collectionID, err := s.resolveExportCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	namer, err := s.exportNamer(name, collectionID)
+	if err != nil {
+		return err
+	}
+	if outputDir == "" {
+		outputDir = filepath.Join(s.reportsManager.GetReportsDirectory(), "exports", time.Now().Format("20060102-150405"))
+	}
+
+	// Event records kept in their own file are exported as a stream, so a
+	// large event log is never held in memory
+	allArtifacts := len(names) == 0
+	var reports []reporter.ReportInfo
+	var filter reporter.RecordFilter
+	if ruleset != nil {
+		filter = ruleset.Record
+	}
+	if allArtifacts || containsField(names, eventRecordsArtifact) {
+		report, err := s.exportEventRecordStream(collectionID, fields, format, outputDir, namer, filter)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			reports = append(reports, *report)
+			names = removeField(names, eventRecordsArtifact)
+		}
+	}
+
+	// A collection stored only as a directory of artifacts has no report
+	// listing its other artifacts
+	var tables []reporter.RecordTable
+	if len(names) > 0 || len(reports) == 0 || (allArtifacts && s.hasCollectionReport(collectionID)) {
+		if tables, err = s.collectionRecordTables(collectionID, names); err != nil {
+			return err
+		}
+		if len(reports) > 0 {
+			tables = removeRecordTable(tables, eventRecordsArtifact)
+		}
+	}
+	fmt.Printf("Exporting %d record list(s) from collection %s\n", len(tables)+len(reports), collectionID)
+	if len(fields) > 0 {
+		tables = projectRecordTables(tables, fields)
+	}
+	if len(tables) == 0 && len(reports) == 0 {
+		fmt.Println("No records to export")
+		return nil
+	}
+	for i := range tables {
+		tables[i].Filter = filter
+	}
+
+	tableReports, err := reporter.ExportRecords(tables, fields, format, outputDir, namer)
+	s.audit(audit.EvidenceExported, collectionID, map[string]interface{}{
+		"artifacts": artifacts, "fields": fields, "format": format, "output": outputDir,
+	}, err)
+	if err != nil {
+		return fmt.Errorf("failed to export artifacts: %w", err)
+	}
+	reports = append(reports, tableReports...)
+
+	for _, report := range reports {
+		footprint.Current().RecordWrite(report.Path, "artifact export", false)
+		fmt.Printf("✓ %s (%d bytes)\n", report.Path, report.Size)
+	}
+	fmt.Printf("Exported %d file(s) to %s\n", len(reports), outputDir)
+	if ruleset != nil {
+		if err := s.recordRedaction(collectionID, format, outputDir, reports, ruleset); err != nil {
+			return err
+		}
+	}
+
+	s.addTimelineEvent("artifacts_exported", "Artifacts exported", map[string]interface{}{
+		"collection_id": collectionID,
+		"artifacts":     artifacts,
+		"fields":        fields,
+		"format":        format,
+		"files":         len(reports),
+		"redacted":      ruleset != nil,
+	})
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/redact"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/packager"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// exportMetadataFile is written next to a redacted export
+const exportMetadataFile = "export-metadata.json"
+
+// exportMetadata describes the files of an export and how they were redacted
+type exportMetadata struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Source     string    `json:"source"`
+	Format     string    `json:"format"`
+	Files      []string  `json:"files"`
+	Redaction  struct {
+		Applied bool   `json:"applied"`
+		Rules   int    `json:"rules"`
+		Ruleset string `json:"ruleset"`
+	} `json:"redaction"`
+}
+
+// exportRuleset returns the ruleset of export --redact: the rules file, or
+// the built-in rules without one
+func exportRuleset(rulesPath string) (*redact.Ruleset, error) {
+	if rulesPath == "" {
+		return redact.Default(), nil
+	}
+	return redact.Load(rulesPath)
+}
+
+// redactFindings returns redacted copies of findings
+func redactFindings(findings []detector.Finding, ruleset *redact.Ruleset) ([]detector.Finding, error) {
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact findings: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to redact findings: %w", err)
+	}
+	if data, err = json.Marshal(ruleset.Value(decoded)); err != nil {
+		return nil, fmt.Errorf("failed to redact findings: %w", err)
+	}
+	var redacted []detector.Finding
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to redact findings: %w", err)
+	}
+	return redacted, nil
+}
+
+// recordRedaction writes the metadata of a redacted export to outputDir and
+// logs the redaction
+func (s *Session) recordRedaction(source, format, outputDir string, reports []reporter.ReportInfo, ruleset *redact.Ruleset) error {
+	metadata := exportMetadata{ExportedAt: time.Now().UTC(), Source: source, Format: format, Files: []string{}}
+	for _, report := range reports {
+		metadata.Files = append(metadata.Files, filepath.Base(report.Path))
+	}
+	metadata.Redaction.Applied = true
+	metadata.Redaction.Rules = ruleset.Len()
+	metadata.Redaction.Ruleset = ruleset.Source
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export metadata: %w", err)
+	}
+	path := filepath.Join(outputDir, exportMetadataFile)
+	err = os.WriteFile(path, data, 0644)
+	s.audit(audit.RedactionApplied, outputDir, map[string]interface{}{
+		"source": source, "rules": ruleset.Len(), "ruleset": ruleset.Source, "files": len(reports),
+	}, err)
+	if err != nil {
+		return fmt.Errorf("failed to write export metadata: %w", err)
+	}
+	footprint.Current().RecordWrite(path, "export metadata", false)
+	fmt.Printf("Redacted with %d rules (%s); see %s\n", ruleset.Len(), ruleset.Source, path)
+	return nil
+}
+
+// cmdRedact writes a redacted copy of a bundle: its artifacts and findings
+// pass through the redaction rules (the built-in ones, or --rules) and are
+// packaged again under the bundle's case ID. Binary file artifacts such as
+// packet captures cannot be redacted and are left out.
+func (s *Session) cmdRedact(args []string) error {
+	input, rulesPath, outputDir := "", "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--input", "--rules", "--output":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--input":
+				input = args[i+1]
+			case "--rules":
+				rulesPath = args[i+1]
+			case "--output":
+				outputDir = args[i+1]
+			}
+			i++
+		default:
+			return rterrors.Validationf("unknown argument: %s (usage: redact --input <bundle> [--rules <file>] [--output <dir>])", args[i])
+		}
+	}
+	if input == "" {
+		return rterrors.Validationf("bundle to redact is required (use --input)")
+	}
+
+	ruleset, err := exportRuleset(rulesPath)
+	if err != nil {
+		return err
+	}
+	bundle, err := packager.OpenBundle(input)
+	if err != nil {
+		return err
+	}
+	defer bundle.Close()
+	artifacts, err := bundle.Artifacts()
+	if err != nil {
+		return err
+	}
+	findings, err := bundle.Findings()
+	if err != nil {
+		return err
+	}
+
+	var redacted []collector.ArtifactResult
+	leftOut := 0
+	for _, artifact := range artifacts {
+		if artifact.Artifact.Type == "file" {
+			leftOut++
+			continue
+		}
+		redacted = append(redacted, redactArtifact(artifact, ruleset))
+	}
+	if findings, err = redactFindings(findings, ruleset); err != nil {
+		return err
+	}
+
+	if outputDir == "" {
+		outputDir = filepath.Join(s.reportsManager.GetReportsDirectory(), "redacted", time.Now().Format("20060102-150405"))
+	}
+	packagerInstance := packager.NewPackager()
+	packagerInstance.SetCaseID(bundle.Manifest.CaseID)
+	packagerInstance.SetRedactionRules(ruleset.Names())
+	bundlePath, err := packagerInstance.CreateBundle(redacted, findings, outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to package the redacted bundle: %w", err)
+	}
+	footprint.Current().RecordWrite(bundlePath, "redacted bundle", false)
+
+	fmt.Printf("✓ Redacted %d artifacts and %d findings of %s into %s\n", len(redacted), len(findings), input, bundlePath)
+	if leftOut > 0 {
+		fmt.Printf("⚠ %d binary file artifacts left out: they cannot be redacted\n", leftOut)
+	}
+	s.noteToolOutput(bundlePath, map[string]int{"artifacts": len(redacted), "findings": len(findings)})
+
+	report := reporter.ReportInfo{Type: "bundle", Path: bundlePath}
+	if info, err := os.Stat(bundlePath); err == nil {
+		report.Size = info.Size()
+	}
+	return s.recordRedaction(input, "bundle", outputDir, []reporter.ReportInfo{report}, ruleset)
+}
+
+// redactArtifact returns a copy of an artifact with its data, parameters,
+// metadata tags, error and the command lines it was collected with
+// redacted. The original bytes of converted text are dropped.
+func redactArtifact(artifact collector.ArtifactResult, ruleset *redact.Ruleset) collector.ArtifactResult {
+	artifact.Data = ruleset.Value(artifact.Data)
+	artifact.Raw = nil
+	parameters := make(map[string]string, len(artifact.Artifact.Parameters))
+	for key, value := range artifact.Artifact.Parameters {
+		parameters[key] = ruleset.Text(value)
+	}
+	artifact.Artifact.Parameters = parameters
+	if artifact.Error != nil {
+		artifact.Error = errors.New(ruleset.Text(artifact.Error.Error()))
+	}
+	tags := make(map[string]string, len(artifact.Metadata.Tags))
+	for key, value := range artifact.Metadata.Tags {
+		tags[key] = ruleset.Text(value)
+	}
+	artifact.Metadata.Tags = tags
+
+	commands := make([]collector.CommandRun, len(artifact.Metadata.Commands))
+	for i, run := range artifact.Metadata.Commands {
+		args := make([]string, len(run.Args))
+		for j, arg := range run.Args {
+			args[j] = ruleset.Text(arg)
+		}
+		run.Args, run.Stderr, run.Error = args, ruleset.Text(run.Stderr), ruleset.Text(run.Error)
+		commands[i] = run
+	}
+	artifact.Metadata.Commands = commands
+	return artifact
+}
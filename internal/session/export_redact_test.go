@@ -0,0 +1,81 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/packager"
+)
+
+func TestRedactWritesARedactedBundle(t *testing.T) {
+	now := time.Now()
+	artifacts := []collector.ArtifactResult{
+		{
+			Artifact: collector.Artifact{Name: "process_list", Category: "process", Type: "command"},
+			Data:     `powershell -File C:\Users\alice\run.ps1 -to alice@corp.example`,
+			Metadata: collector.Metadata{StartedAt: now, CollectedAt: now},
+		},
+		{
+			Artifact: collector.Artifact{Name: "logged_on_users", Category: "host", Type: "command"},
+			Data:     []map[string]interface{}{{"user": "alice", "remote": "10.0.0.5"}},
+			Metadata: collector.Metadata{StartedAt: now, CollectedAt: now},
+		},
+	}
+	findings := []detector.Finding{{RuleID: "RT001", RuleName: "Test", Severity: "high", Description: "Logon by alice@corp.example", Timestamp: now}}
+	source, err := packager.NewPackager().CreateBundle(artifacts, findings, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateBundle: %v", err)
+	}
+
+	s := testSession(t)
+	outputDir := t.TempDir()
+	if err := s.cmdRedact([]string{"--input", source, "--output", outputDir}); err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+
+	bundles, _ := filepath.Glob(filepath.Join(outputDir, "*.zip"))
+	if len(bundles) != 1 {
+		t.Fatalf("expected one redacted bundle, found %v", bundles)
+	}
+	bundle, err := packager.OpenBundle(bundles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bundle.Close()
+	if len(bundle.Manifest.RedactionRules) == 0 {
+		t.Error("manifest does not record the redaction rules")
+	}
+
+	redacted, err := bundle.Artifacts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	redactedFindings, err := bundle.Findings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]interface{}{"artifacts": redacted, "findings": redactedFindings})
+	for _, value := range []string{"alice", "10.0.0.5"} {
+		if strings.Contains(string(data), value) {
+			t.Errorf("redacted bundle still contains %q", value)
+		}
+	}
+	if len(redacted) != len(artifacts) || len(redactedFindings) != 1 {
+		t.Errorf("redacted bundle has %d artifacts and %d findings, want %d and 1", len(redacted), len(redactedFindings), len(artifacts))
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, exportMetadataFile)); err != nil {
+		t.Errorf("no export metadata written: %v", err)
+	}
+}
+
+func TestRedactRequiresABundle(t *testing.T) {
+	if err := testSession(t).cmdRedact(nil); err == nil {
+		t.Error("redact without --input was accepted")
+	}
+}
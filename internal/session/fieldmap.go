@@ -0,0 +1,174 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rules"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// engineMapped evaluates rules written for a standard Sigma logsource
+// through the field mappings
+const engineMapped = "field mapping"
+
+// loadFieldMap reloads the field mappings of the rules directory and warns
+// about the rules they cannot fully evaluate
+func (s *Session) loadFieldMap(dir string, loaded []SigmaRule) {
+	fieldMap, warnings := rules.LoadFieldMap(dir)
+	s.fieldMap = fieldMap
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	for _, rule := range loaded {
+		coverage := fieldMap.RuleCoverage(rule)
+		if coverage.Status == rules.CoveragePartial || coverage.Status == rules.CoverageNone {
+			fmt.Printf("Warning: %s\n", output.SanitizeLine(coverage.String()))
+		}
+	}
+}
+
+// mappedSelection translates a rule's selection into a selection keyed as
+// written in the rule, and returns a function that views a record under
+// those keys so the usual selection matching applies
+func mappedSelection(fields []rules.MappedField) (map[string]interface{}, func(map[string]interface{}) map[string]interface{}) {
+	selection := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		selection[field.Key] = field.Want
+	}
+	view := func(record map[string]interface{}) map[string]interface{} {
+		viewed := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value := field.Value(record); value != nil {
+				viewed[field.Key] = value
+			}
+		}
+		return viewed
+	}
+	return selection, view
+}
+
+// mappedRecord is a record of a mapped artifact with its reference
+type mappedRecord struct {
+	fields map[string]interface{}
+	ref    *collector.RecordRef
+}
+
+// mappedRecords returns the records of the artifact a mapping reads
+func (s *Session) mappedRecords(mapping *rules.RecordMapping, collectionID string) ([]mappedRecord, error) {
+	artifact, err := s.loadCollectionArtifact(collectionID, mapping.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	var records []mappedRecord
+	for _, key := range mapping.Records {
+		list, _ := artifact[key].([]interface{})
+		for i, item := range list {
+			if record, ok := item.(map[string]interface{}); ok {
+				records = append(records, mappedRecord{record, collector.NewRecordRef(collectionID, mapping.Artifact, key, i)})
+			}
+		}
+	}
+	return records, nil
+}
+
+// analyzeMappedRule matches a rule's selection against the records of the
+// artifact its logsource maps to. Fields that cannot be mapped never match,
+// so a rule the mappings cannot fully evaluate yields no findings.
+func (s *Session) analyzeMappedRule(rule SigmaRule, collectionID string) []map[string]interface{} {
+	var findings []map[string]interface{}
+	mapping, fields, _ := s.fieldMap.Translate(rule)
+	records, err := s.mappedRecords(mapping, collectionID)
+	if err != nil {
+		return findings
+	}
+
+	selection, view := mappedSelection(fields)
+	for _, record := range records {
+		viewed := view(record.fields)
+		if !matchSelection(viewed, selection, nil) {
+			continue
+		}
+		// The snapshot keeps the matched fields under the rule's names
+		evidence := reporter.EvidenceSnapshot(record.fields, nil)
+		for key, value := range viewed {
+			evidence[key] = value
+		}
+		findings = append(findings, map[string]interface{}{
+			"rule_title":   rule.Title,
+			"rule_id":      rule.ID,
+			"level":        rule.Level,
+			"description":  fmt.Sprintf("%s record matched detection rule", strings.ReplaceAll(rule.LogSource.Category, "_", " ")),
+			"evidence":     evidence,
+			"evidence_ref": record.ref,
+			"timestamp":    time.Now().Format(time.RFC3339),
+			"category":     mapping.Artifact,
+		})
+	}
+	return findings
+}
+
+// traceMappedRule counts the records of a mapped rule's artifact each
+// translated selection field matched
+func (s *Session) traceMappedRule(rule SigmaRule, collectionID string) artifactExplanation {
+	mapping, fields, unmapped := s.fieldMap.Translate(rule)
+	artifact := artifactExplanation{Artifact: mapping.Artifact}
+	records, err := s.mappedRecords(mapping, collectionID)
+	if err != nil {
+		artifact.Note = "the artifact was not collected"
+		return artifact
+	}
+
+	selection, view := mappedSelection(fields)
+	counts := make(map[string]*conditionCount)
+	for _, record := range records {
+		artifact.Records++
+		var trace []fieldTrace
+		if matchSelection(view(record.fields), selection, &trace) {
+			artifact.Matches++
+		}
+		for _, step := range trace {
+			count := counts[step.Field]
+			if count == nil {
+				count = &conditionCount{Field: step.Field, Want: step.Want}
+				counts[step.Field] = count
+			}
+			if step.Matched {
+				count.Matched++
+			} else {
+				count.Missed++
+			}
+		}
+	}
+	for _, field := range fields {
+		if count := counts[field.Key]; count != nil {
+			count.Field = fmt.Sprintf("%s -> %s", field.Key, field.Target)
+			artifact.Conditions = append(artifact.Conditions, *count)
+		}
+	}
+	if len(unmapped) > 0 {
+		artifact.Note = fmt.Sprintf("unmapped fields never match: %s", strings.Join(unmapped, ", "))
+	}
+	return artifact
+}
+
+// testRules prints the field mapping coverage of the loaded rules
+func (s *Session) testRules() error {
+	loaded := s.loadSigmaRules(false, false)
+	counts := make(map[string]int)
+	fmt.Printf("Field mapping coverage of %d Sigma rules:\n", len(loaded))
+	for _, rule := range loaded {
+		coverage := s.fieldMap.RuleCoverage(rule)
+		counts[coverage.Status]++
+		fmt.Printf("  [%s] %s\n", coverage.Status, output.SanitizeLine(coverage.String()))
+	}
+	for _, path := range s.fieldMap.Files {
+		fmt.Printf("Mapping file: %s\n", path)
+	}
+	fmt.Printf("✓ %d fully mapped, %d partially mapped, %d unmapped, %d native\n",
+		counts[rules.CoverageFull], counts[rules.CoveragePartial], counts[rules.CoverageNone], counts[rules.CoverageNative])
+	return nil
+}
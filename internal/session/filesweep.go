@@ -0,0 +1,160 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/version"
+)
+
+// defaultSweepRate is the number of entries per second 'collect --find' examines
+const defaultSweepRate = 5000
+
+// parseSweepArg applies one 'collect --find' option to the sweep options
+func parseSweepArg(opts *collector.SweepOptions, flag, value string) error {
+	switch flag {
+	case "--glob":
+		opts.Globs = splitSweepList(value)
+	case "--paths":
+		opts.Roots = splitSweepList(value)
+	case "--mtime-within":
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return rterrors.Validationf("invalid --mtime-within duration: %s", value)
+		}
+		opts.MTimeWithin = d
+	case "--max-results", "--max-depth", "--find-rate":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || (n == 0 && flag != "--find-rate") {
+			return rterrors.Validationf("invalid %s value: %s", flag, value)
+		}
+		switch flag {
+		case "--max-results":
+			opts.MaxResults = n
+		case "--max-depth":
+			opts.MaxDepth = n
+		default:
+			opts.FilesPerSecond = n
+		}
+	}
+	return nil
+}
+
+// splitSweepList splits a ';' separated list, dropping empty entries
+func splitSweepList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// unquote strips the quotes users type around values such as globs
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// runFileSweep runs a targeted file sweep instead of a full collection and
+// attaches the result to the active incident. Ctrl+C stops the walk and
+// keeps the matches found so far.
+func (s *Session) runFileSweep(opts collector.SweepOptions) error {
+	if len(opts.Roots) == 0 {
+		return rterrors.Validationf("--find requires --paths")
+	}
+	if len(opts.Globs) == 0 {
+		return rterrors.Validationf("--find requires --glob")
+	}
+
+	sweepID := newID("RT", "20060102-150405")
+	fmt.Printf("File Sweep ID: %s\n", sweepID)
+	fmt.Printf("✓ Sweeping %s for %s (max depth %d, max results %d)...\n",
+		strings.Join(opts.Roots, ", "), strings.Join(opts.Globs, ", "), opts.MaxDepth, opts.MaxResults)
+
+	ctx, cancel := s.commandContext()
+	defer cancel()
+
+	sweep, err := collector.SweepFiles(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("file sweep failed: %w", err)
+	}
+
+	switch {
+	case sweep.Cancelled:
+		fmt.Printf("Warning: File sweep cancelled; keeping %d matches found so far\n", len(sweep.Matches))
+	case sweep.Truncated:
+		fmt.Printf("Warning: File sweep stopped early: %s\n", sweep.TruncatedReason)
+	}
+	fmt.Printf("✓ Scanned %d entries in %s, %d matches\n", sweep.Scanned, sweep.Duration, len(sweep.Matches))
+	if sweep.SkippedHidden > 0 {
+		fmt.Printf("Skipped %d hidden entries; use --include-hidden to walk them\n", sweep.SkippedHidden)
+	}
+
+	hostname, _ := os.Hostname()
+	collection := map[string]interface{}{
+		"collection_id":       sweepID,
+		"timestamp":           sweep.StartedAt.Format(time.RFC3339),
+		"platform":            runtime.GOOS,
+		"hostname":            hostname,
+		"redtriage_version":   version.GetShortVersion(),
+		"artifacts_collected": []string{collector.FileSweepType},
+		"status":              "completed",
+		"artifacts": map[string]interface{}{
+			collector.FileSweepType: sweep,
+		},
+	}
+	if sweep.Cancelled {
+		collection["status"] = "cancelled"
+	}
+
+	if s.incidentContext != nil {
+		collection["incident_context"] = map[string]interface{}{
+			"incident_id":    s.incidentContext.ID,
+			"incident_title": s.incidentContext.Title,
+			"severity":       s.incidentContext.Severity,
+			"analyst":        s.incidentContext.Analyst,
+		}
+
+		s.incidentContext.SetArtifact(sweepID, collection)
+		s.addTimelineEvent("file_sweep", fmt.Sprintf("File sweep found %d matching files", len(sweep.Matches)), map[string]interface{}{
+			"collection_id": sweepID,
+			"roots":         sweep.Roots,
+			"globs":         sweep.Globs,
+			"matches":       len(sweep.Matches),
+			"truncated":     sweep.Truncated,
+			"cancelled":     sweep.Cancelled,
+		})
+
+		if err := s.saveIncidentContext(s.incidentContext); err != nil {
+			fmt.Printf("Warning: Failed to save incident context: %v\n", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal file sweep: %w", err)
+	}
+
+	savedPath, err := s.reportsManager.SaveCollectionReport(data, fmt.Sprintf("sweep-%s.json", sweepID))
+	if err != nil {
+		return fmt.Errorf("failed to save file sweep: %w", err)
+	}
+
+	fmt.Printf("File sweep saved to: %s\n", savedPath)
+	if s.incidentContext != nil {
+		fmt.Printf("✓ File sweep attached to incident context: %s\n", s.incidentContext.ID)
+	}
+
+	return nil
+}
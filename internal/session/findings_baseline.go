@@ -0,0 +1,201 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// globalBaselineFile holds the accepted findings baseline used outside an
+// incident, in the reports metadata directory
+const globalBaselineFile = "findings-baseline.json"
+
+// globalBaselinePath returns the path of the global findings baseline
+func (s *Session) globalBaselinePath() string {
+	return filepath.Join(s.reportsManager.GetMetadataDirectory(), globalBaselineFile)
+}
+
+// acceptedBaseline returns the baseline of the active incident, or the
+// global one outside an incident, with a description of where it is kept.
+// It returns nil when none has been set.
+func (s *Session) acceptedBaseline() (*reporter.AcceptedBaseline, string, error) {
+	if s.incidentContext != nil {
+		return s.incidentContext.Baseline, "incident " + s.incidentContext.ID, nil
+	}
+
+	path := s.globalBaselinePath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to read findings baseline: %w", err)
+	}
+	var accepted reporter.AcceptedBaseline
+	if err := json.Unmarshal(data, &accepted); err != nil {
+		return nil, path, fmt.Errorf("failed to parse findings baseline %s: %w", path, err)
+	}
+	return &accepted, path, nil
+}
+
+// storeBaseline saves or, with nil, removes the baseline of the active
+// incident or the global one
+func (s *Session) storeBaseline(accepted *reporter.AcceptedBaseline) error {
+	if s.incidentContext != nil {
+		s.incidentContext.Baseline = accepted
+		return s.saveIncidentContext(s.incidentContext)
+	}
+
+	path := s.globalBaselinePath()
+	if accepted == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove findings baseline: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(accepted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings baseline: %w", err)
+	}
+	return s.reportsManager.WithLock(func() error {
+		return output.WriteFileAtomic(path, data, 0644)
+	})
+}
+
+// cmdFindingsBaseline manages the accepted findings baseline:
+// findings baseline set --run <findings-report> [--replace] | show | clear | export <file>
+func (s *Session) cmdFindingsBaseline(args []string) error {
+	if len(args) == 0 {
+		return rterrors.Validationf("usage: findings baseline set --run <findings-report> [--replace] | show | clear | export <file>")
+	}
+
+	current, scope, err := s.acceptedBaseline()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "set":
+		run, replace := "", false
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--run":
+				if i+1 >= len(args) {
+					return rterrors.Validationf("--run requires a findings report")
+				}
+				run = unquote(args[i+1])
+				i++
+			case "--replace":
+				replace = true
+			}
+		}
+		if run == "" {
+			return rterrors.Validationf("findings baseline set requires --run <findings-report>")
+		}
+		if current != nil && !replace {
+			return rterrors.Validationf("a baseline from %s is already set for %s; add --replace to update it or run 'findings baseline clear'", current.Source, scope)
+		}
+		if _, err := os.Stat(run); err != nil {
+			return rterrors.NotFoundf("findings report not found: %s", run)
+		}
+		baseline, err := reporter.LoadBaseline(run)
+		if err != nil {
+			return rterrors.Validationf("invalid baseline: %v", err)
+		}
+
+		accepted := baseline.Accept(s.getCurrentUser())
+		err = s.storeBaseline(accepted)
+		s.audit(audit.BaselineSet, scope, map[string]interface{}{"run": run, "keys": len(accepted.Keys), "replace": replace}, err)
+		if err != nil {
+			return err
+		}
+		s.addTimelineEvent("baseline_set", "Findings baseline set", map[string]interface{}{
+			"source":   run,
+			"findings": len(accepted.Keys),
+		})
+		fmt.Printf("✓ Baseline for %s set from %s (%d accepted findings)\n", scope, run, len(accepted.Keys))
+
+	case "show":
+		if current == nil {
+			fmt.Printf("No findings baseline set for %s\n", scope)
+			return nil
+		}
+		fmt.Printf("Findings baseline for %s\n", scope)
+		fmt.Printf("  Source:   %s\n", current.Source)
+		fmt.Printf("  Set:      %s by %s\n", current.SetAt.Format("2006-01-02 15:04:05"), valueOrDash(current.SetBy))
+		fmt.Printf("  Findings: %d\n", len(current.Keys))
+
+	case "clear":
+		if current == nil {
+			fmt.Printf("No findings baseline set for %s\n", scope)
+			return nil
+		}
+		err := s.storeBaseline(nil)
+		s.audit(audit.BaselineCleared, scope, map[string]interface{}{"source": current.Source, "keys": len(current.Keys)}, err)
+		if err != nil {
+			return err
+		}
+		s.addTimelineEvent("baseline_cleared", "Findings baseline cleared", map[string]interface{}{
+			"source": current.Source,
+		})
+		fmt.Printf("✓ Cleared the findings baseline for %s\n", scope)
+
+	case "export":
+		if len(args) < 2 {
+			return rterrors.Validationf("findings baseline export requires a file")
+		}
+		if current == nil {
+			return rterrors.NotFoundf("no findings baseline set for %s", scope)
+		}
+		data, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal findings baseline: %w", err)
+		}
+		path := unquote(args[1])
+		if err := output.WriteFileAtomic(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to export findings baseline: %w", err)
+		}
+		footprint.Current().RecordWrite(path, "findings baseline export", false)
+		fmt.Printf("✓ Exported %d accepted findings to %s\n", len(current.Keys), path)
+		fmt.Println("Load it elsewhere with 'findings baseline set --run <file>'")
+
+	default:
+		return rterrors.Validationf("unknown findings baseline action: %s (valid: set, show, clear, export)", args[0])
+	}
+	return nil
+}
+
+// printBaselineSections prints a findings run against the accepted
+// baseline: the new findings, the known ones and the count of baseline
+// findings no longer seen
+func printBaselineSections(allFindings []map[string]interface{}, newGroups []reporter.FindingGroup, summary reporter.BaselineSummary, top int) {
+	var known []map[string]interface{}
+	for _, finding := range allFindings {
+		if suppressed, _ := finding[reporter.BaselineSuppressedField].(bool); suppressed {
+			known = append(known, finding)
+		}
+	}
+
+	fmt.Printf("\nNew since baseline (%d findings):\n", summary.New)
+	if summary.New == 0 {
+		fmt.Println("  None")
+	} else {
+		fmt.Print(reporter.KeyFindingsText(newGroups, top))
+	}
+
+	fmt.Printf("\nKnown from baseline (%d findings):\n", summary.Suppressed)
+	if summary.Suppressed == 0 {
+		fmt.Println("  None")
+	} else {
+		fmt.Print(reporter.KeyFindingsText(reporter.GroupFindings(known), top))
+	}
+
+	fmt.Printf("\nResolved since baseline: %d baseline finding(s) no longer seen\n", summary.Resolved)
+}
@@ -0,0 +1,314 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// ruleExplanation is why a rule did or did not match a collection
+type ruleExplanation struct {
+	RuleID     string                `json:"rule_id"`
+	RuleTitle  string                `json:"rule_title"`
+	Collection string                `json:"collection"`
+	Engine     string                `json:"engine"`
+	Artifacts  []artifactExplanation `json:"artifacts"`
+	// Unevaluated are the detection keys the engine does not look at
+	Unevaluated []string `json:"unevaluated,omitempty"`
+	Matched     bool     `json:"matched"`
+}
+
+// artifactExplanation is how a rule fared against the records of one
+// artifact
+type artifactExplanation struct {
+	Artifact    string           `json:"artifact"`
+	Records     int              `json:"records"`
+	Matches     int              `json:"matches"`
+	Conditions  []conditionCount `json:"conditions,omitempty"`
+	ClosestMiss *closestMiss     `json:"closest_miss,omitempty"`
+	Note        string           `json:"note,omitempty"`
+}
+
+// conditionCount is how many records one selection field matched
+type conditionCount struct {
+	Field   string `json:"field"`
+	Want    string `json:"want"`
+	Matched int    `json:"matched_records"`
+	Missed  int    `json:"missed_records"`
+}
+
+// closestMiss is the first record that matched the most selection fields
+// without matching them all, and the first field it missed
+type closestMiss struct {
+	Record  int    `json:"record"`
+	Matched int    `json:"matched_fields"`
+	Fields  int    `json:"fields"`
+	Field   string `json:"field"`
+	Want    string `json:"want"`
+	Have    string `json:"have,omitempty"`
+	Present bool   `json:"present"`
+}
+
+// takeExplainArg removes --explain <rule-id> from the findings arguments
+func takeExplainArg(args []string) (string, []string, error) {
+	ruleID := ""
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--explain" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", nil, rterrors.Validationf("--explain requires a rule ID")
+		}
+		ruleID = args[i+1]
+		i++
+	}
+	return ruleID, rest, nil
+}
+
+// explainRule runs one rule against a collection with the evaluation
+// traced, and prints per artifact which selection fields matched, the
+// closest miss and whether the rule matched. Nothing is saved.
+func (s *Session) explainRule(ruleID string, candidates []SigmaRule, args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	collectionID := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--collection" {
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--collection requires a collection ID")
+			}
+			collectionID = args[i+1]
+			i++
+		}
+	}
+
+	var rule *SigmaRule
+	for i := range candidates {
+		if candidates[i].ID == ruleID {
+			rule = &candidates[i]
+			break
+		}
+	}
+	if rule == nil {
+		return rterrors.NotFoundf("no Sigma rule with ID %s (%d rules loaded)", ruleID, len(candidates))
+	}
+
+	collectionID, err = s.findingsCollection(collectionID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.commandContext()
+	defer cancel()
+	explanation, err := s.traceRule(ctx, *rule, collectionID)
+	if err != nil {
+		return err
+	}
+
+	if format != formatTable {
+		return printStructured(format, explanation)
+	}
+	printRuleExplanation(explanation)
+	return nil
+}
+
+// traceRule evaluates a rule against a collection the way findings does,
+// recording how each artifact's records fared
+func (s *Session) traceRule(ctx context.Context, rule SigmaRule, collectionID string) (*ruleExplanation, error) {
+	explanation := &ruleExplanation{
+		RuleID:     rule.ID,
+		RuleTitle:  rule.Title,
+		Collection: collectionID,
+		Engine:     ruleEngine(rule, s.fieldMap),
+	}
+
+	if explanation.Engine == engineSelection || explanation.Engine == engineMapped {
+		for key := range rule.Detection {
+			if key != "selection" && key != "condition" {
+				explanation.Unevaluated = append(explanation.Unevaluated, key)
+			}
+		}
+		sort.Strings(explanation.Unevaluated)
+	}
+
+	switch explanation.Engine {
+	case engineSelection:
+		selection, _ := rule.Detection["selection"].(map[string]interface{})
+		artifact, err := s.traceSelection(ctx, selection, collectionID)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Artifacts = append(explanation.Artifacts, artifact)
+	case engineMapped:
+		explanation.Artifacts = append(explanation.Artifacts, s.traceMappedRule(rule, collectionID))
+	case engineNetwork:
+		explanation.Artifacts = append(explanation.Artifacts, s.traceHeuristic(collectionID, "network", "connections", func(record map[string]interface{}) bool {
+			return s.isSuspiciousNetworkConnection(record, rule)
+		}))
+	case engineProcess:
+		explanation.Artifacts = append(explanation.Artifacts, s.traceHeuristic(collectionID, "processes", "processes", func(record map[string]interface{}) bool {
+			return s.isSuspiciousProcess(record, rule)
+		}))
+	}
+
+	for _, artifact := range explanation.Artifacts {
+		if artifact.Matches > 0 {
+			explanation.Matched = true
+		}
+	}
+	return explanation, nil
+}
+
+// traceSelection matches a selection against each event record, counting
+// the records every field matched and keeping the closest miss
+func (s *Session) traceSelection(ctx context.Context, selection map[string]interface{}, collectionID string) (artifactExplanation, error) {
+	artifact := artifactExplanation{Artifact: eventRecordsArtifact}
+	if len(selection) == 0 {
+		artifact.Note = "the rule has no selection fields, so no record can match"
+		return artifact, nil
+	}
+
+	counts := make(map[string]*conditionCount)
+	records, err := s.streamEventRecords(ctx, collectionID, func(record map[string]interface{}) error {
+		var trace []fieldTrace
+		if matchSelection(record, selection, &trace) {
+			artifact.Matches++
+		}
+
+		matched := 0
+		var missed *fieldTrace
+		for i, step := range trace {
+			count := counts[step.Field]
+			if count == nil {
+				count = &conditionCount{Field: step.Field, Want: step.Want}
+				counts[step.Field] = count
+			}
+			if step.Matched {
+				count.Matched++
+				matched++
+			} else {
+				count.Missed++
+				if missed == nil {
+					missed = &trace[i]
+				}
+			}
+		}
+		if missed != nil && (artifact.ClosestMiss == nil || matched > artifact.ClosestMiss.Matched) {
+			artifact.ClosestMiss = &closestMiss{
+				Record:  artifact.Records + 1,
+				Matched: matched,
+				Fields:  len(trace),
+				Field:   missed.Field,
+				Want:    missed.Want,
+				Have:    missed.Have,
+				Present: missed.Present,
+			}
+		}
+		artifact.Records++
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return artifact, fmt.Errorf("rule explanation cancelled: %w", ctx.Err())
+		}
+		return artifact, fmt.Errorf("failed to read event records: %w", err)
+	}
+	if records == 0 {
+		artifact.Note = "the collection has no event records"
+	}
+	if artifact.Matches > 0 {
+		artifact.ClosestMiss = nil
+	}
+
+	fields := make([]string, 0, len(selection))
+	for field := range selection {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if count := counts[field]; count != nil {
+			artifact.Conditions = append(artifact.Conditions, *count)
+		} else {
+			artifact.Conditions = append(artifact.Conditions, conditionCount{Field: field, Want: selectionValueString(selection[field])})
+		}
+	}
+	return artifact, nil
+}
+
+// traceHeuristic counts the records of an artifact a built-in heuristic
+// flags. Heuristics do not look at the rule's selection.
+func (s *Session) traceHeuristic(collectionID, name, key string, flags func(map[string]interface{}) bool) artifactExplanation {
+	artifact := artifactExplanation{Artifact: name}
+	data, err := s.loadCollectionArtifact(collectionID, name)
+	if err != nil {
+		artifact.Note = "the artifact was not collected"
+		return artifact
+	}
+	list, _ := data[key].([]interface{})
+	for _, item := range list {
+		if record, ok := item.(map[string]interface{}); ok {
+			artifact.Records++
+			if flags(record) {
+				artifact.Matches++
+			}
+		}
+	}
+	artifact.Note = "records are matched by a built-in heuristic chosen from the rule title; the rule's selection is not evaluated"
+	return artifact
+}
+
+// printRuleExplanation prints a rule explanation as text
+func printRuleExplanation(e *ruleExplanation) {
+	fmt.Printf("Rule: %s (%s)\n", output.SanitizeLine(e.RuleTitle), output.SanitizeLine(e.RuleID))
+	fmt.Printf("Collection: %s\n", e.Collection)
+	switch e.Engine {
+	case engineSelection:
+		fmt.Println("Engine: selection match on event records; every field must match, a list matches when any value does")
+	case engineMapped:
+		fmt.Println("Engine: selection match on the records the rule's logsource maps to; every field must match, a list matches when any value does")
+	case engineGeneric:
+		fmt.Println("Engine: none; the rule selects no event ID and its title names no network or process heuristic, so findings never evaluates it")
+	default:
+		fmt.Printf("Engine: %s\n", e.Engine)
+	}
+	if len(e.Unevaluated) > 0 {
+		fmt.Printf("Not evaluated: %s (only 'selection' is)\n", output.SanitizeLine(fmt.Sprint(e.Unevaluated)))
+	}
+
+	for _, artifact := range e.Artifacts {
+		fmt.Printf("\nArtifact %s: %d records, %d matched\n", artifact.Artifact, artifact.Records, artifact.Matches)
+		for _, condition := range artifact.Conditions {
+			status := "✓"
+			if condition.Matched == 0 {
+				status = "✗"
+			}
+			fmt.Printf("  %s %s = %s: matched %d, missed %d\n", status, output.SanitizeLine(condition.Field), output.SanitizeLine(condition.Want), condition.Matched, condition.Missed)
+		}
+		if miss := artifact.ClosestMiss; miss != nil {
+			have := "absent"
+			if miss.Present {
+				have = fmt.Sprintf("%q", output.SanitizeLine(miss.Have))
+			}
+			fmt.Printf("  Closest miss: record %d matched %d of %d fields; %s is %s, wanted %s\n",
+				miss.Record, miss.Matched, miss.Fields, output.SanitizeLine(miss.Field), have, output.SanitizeLine(miss.Want))
+		}
+		if artifact.Note != "" {
+			fmt.Printf("  Note: %s\n", artifact.Note)
+		}
+	}
+
+	if e.Matched {
+		fmt.Println("\nResult: match")
+	} else {
+		fmt.Println("\nResult: no match")
+	}
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+)
+
+// ruleSelectionKey is the incident memory key holding the rule selection
+// findings uses by default inside that incident
+const ruleSelectionKey = "findings.rule_selection"
+
+// Where the rule selection of a findings run came from
+const (
+	selectionFromFlags    = "flags"
+	selectionFromIncident = "incident"
+	selectionCleared      = "cleared"
+)
+
+// takeRuleSelection removes --level, --rule-id, --rule-file, --tag and
+// --all-rules from the findings arguments. Without selection flags the
+// selection saved in the active incident's memory applies, unless
+// --all-rules is given.
+func (s *Session) takeRuleSelection(args []string) (rules.Selection, string, []string, error) {
+	selection, args, err := rules.TakeSelection(args)
+	if err != nil {
+		return rules.Selection{}, "", nil, rterrors.Validationf("%w", err)
+	}
+
+	allRules := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--all-rules" {
+			allRules = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	switch {
+	case allRules && !selection.Empty():
+		return rules.Selection{}, "", nil, rterrors.Validationf("--all-rules cannot be combined with --level, --rule-id, --rule-file or --tag")
+	case allRules:
+		return selection, selectionCleared, rest, nil
+	case !selection.Empty():
+		return selection, selectionFromFlags, rest, nil
+	}
+
+	if s.incidentContext == nil {
+		return selection, "", rest, nil
+	}
+	saved, ok := s.incidentContext.Memory[ruleSelectionKey].(string)
+	if !ok || saved == "" {
+		return selection, "", rest, nil
+	}
+	if selection, err = rules.ParseSelection(saved); err != nil {
+		return rules.Selection{}, "", nil, rterrors.Validationf("invalid rule selection in incident memory key %s: %w (fix it with 'memory set' or run with --all-rules)", ruleSelectionKey, err)
+	}
+	return selection, selectionFromIncident, rest, nil
+}
+
+// selectRules applies a rule selection to the loaded rules. Selecting no
+// rule is an error rather than a run without findings.
+func selectRules(selection rules.Selection, loaded []SigmaRule) ([]SigmaRule, error) {
+	selected, err := selection.Apply(loaded)
+	if err != nil {
+		return nil, rterrors.Validationf("%w", err)
+	}
+	if len(selected) == 0 {
+		return nil, rterrors.Validationf("no Sigma rules match the selection %s (%d rules loaded)", selection, len(loaded))
+	}
+	return selected, nil
+}
+
+// rememberRuleSelection makes the selection of this run the default of the
+// active incident, or clears the default after --all-rules. The incident is
+// saved with the findings run.
+func (s *Session) rememberRuleSelection(selection rules.Selection, origin string) {
+	if s.incidentContext == nil {
+		return
+	}
+	switch origin {
+	case selectionFromFlags:
+		s.incidentContext.SetMemory(ruleSelectionKey, selection.String())
+		fmt.Printf("Rule selection saved as the default for incident %s (memory key %s; --all-rules clears it)\n", s.incidentContext.ID, ruleSelectionKey)
+	case selectionCleared:
+		if s.incidentContext.DeleteMemory(ruleSelectionKey) {
+			fmt.Printf("Cleared the default rule selection of incident %s\n", s.incidentContext.ID)
+		}
+	}
+}
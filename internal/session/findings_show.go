@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/jsonstream"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// shownFinding is a finding as 'findings show' prints it, with the record
+// its evidence was taken from when --resolve is given
+type shownFinding struct {
+	Finding
+	Record map[string]interface{} `json:"record,omitempty"`
+	// RecordSource is the record ID, or "embedded" for a finding stored
+	// with a copy of its record
+	RecordSource string `json:"record_source,omitempty"`
+}
+
+// showFinding prints a finding of the active incident:
+// findings show --id <id> [--resolve] [--format table|json|yaml]
+func (s *Session) showFinding(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	id, resolve := "", false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--id":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--id requires a finding ID")
+			}
+			id = args[i+1]
+			i++
+		case "--resolve":
+			resolve = true
+		}
+	}
+	if id == "" {
+		return rterrors.Validationf("usage: findings show --id <finding-id> [--resolve] [--format table|json|yaml]")
+	}
+	if s.incidentContext == nil {
+		return rterrors.Validationf("findings show requires an active incident (use 'incident switch')")
+	}
+
+	var shown *shownFinding
+	for _, finding := range s.incidentContext.Findings {
+		if finding.ID == id {
+			shown = &shownFinding{Finding: finding}
+			break
+		}
+	}
+	if shown == nil {
+		return rterrors.NotFoundf("finding %s not found in incident %s", id, s.incidentContext.ID)
+	}
+
+	if resolve {
+		if ref := shown.EvidenceRef; ref != nil {
+			ctx, cancel := s.commandContext()
+			defer cancel()
+			if shown.Record, err = s.resolveRecordRef(ctx, ref); err != nil {
+				return err
+			}
+			shown.RecordSource = ref.ID
+		} else if embedded, ok := shown.Evidence["evidence"].(map[string]interface{}); ok {
+			shown.Record, shown.RecordSource = embedded, "embedded"
+		}
+	}
+
+	if format != formatTable {
+		return printStructured(format, shown)
+	}
+
+	fmt.Printf("Finding %s\n", shown.ID)
+	fmt.Printf("  Rule:        %s\n", output.SanitizeLine(fmt.Sprint(shown.Evidence["rule_title"])))
+	fmt.Printf("  Severity:    %s\n", shown.Severity)
+	fmt.Printf("  Triage:      %s\n", triageState(shown.Finding))
+	fmt.Printf("  Description: %s\n", output.SanitizeLine(shown.Description))
+	if shown.EvidenceRef != nil {
+		fmt.Printf("  Record:      %s\n", shown.EvidenceRef.ID)
+	}
+	if snapshot, ok := shown.Evidence["evidence"].(map[string]interface{}); ok && len(snapshot) > 0 {
+		keys := make([]string, 0, len(snapshot))
+		for key := range snapshot {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		rows := make([][]string, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, []string{key, fmt.Sprint(snapshot[key])})
+		}
+		fmt.Println("\nEvidence:")
+		printTable([]string{"Field", "Value"}, rows)
+	}
+	switch {
+	case shown.Record != nil:
+		data, err := json.MarshalIndent(shown.Record, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal record: %w", err)
+		}
+		fmt.Printf("\nRecord (%s):\n%s\n", shown.RecordSource, output.Sanitize(string(data)))
+	case !resolve && shown.EvidenceRef != nil:
+		fmt.Println("\nAdd --resolve to show the whole record")
+	}
+	return nil
+}
+
+// resolveRecordRef reads the record a finding's evidence points at back
+// from its collection. Event records are counted in the order they are
+// streamed, as when they were matched.
+func (s *Session) resolveRecordRef(ctx context.Context, ref *collector.RecordRef) (map[string]interface{}, error) {
+	if ref.Artifact == eventRecordsArtifact && ref.List == "" {
+		var record map[string]interface{}
+		next := 0
+		_, err := s.streamEventRecords(ctx, ref.Collection, func(candidate map[string]interface{}) error {
+			if next == ref.Index {
+				record = candidate
+				return jsonstream.ErrStop
+			}
+			next++
+			return nil
+		})
+		if err != nil && !errors.Is(err, jsonstream.ErrStop) {
+			return nil, fmt.Errorf("failed to read event records: %w", err)
+		}
+		if record == nil {
+			return nil, rterrors.NotFoundf("record %s is no longer in collection %s", ref.ID, ref.Collection)
+		}
+		return record, nil
+	}
+
+	artifact, err := s.loadCollectionArtifact(ref.Collection, ref.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	record, ok := collector.RecordAt(artifact, ref)
+	if !ok {
+		return nil, rterrors.NotFoundf("record %s is no longer in collection %s", ref.ID, ref.Collection)
+	}
+	return record, nil
+}
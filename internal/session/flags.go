@@ -0,0 +1,63 @@
+package session
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// shortFlags are the short flags session commands share with the CLI
+var shortFlags = map[string]string{
+	"-o": "--output",
+	"-f": "--format",
+	"-v": "--verbose",
+	"-q": "--quiet",
+	"-t": "--timeout",
+}
+
+// deprecatedFlags are old flag spellings still accepted for one release,
+// by command, with the flag that replaces them
+var deprecatedFlags = map[string]map[string]string{
+	"context": {"--export": "--output"},
+}
+
+// normalizeFlags expands the standard short flags, rewrites deprecated
+// spellings with a notice and takes out --quiet, which the session handles
+// for every command
+func normalizeFlags(name string, args []string) ([]string, bool) {
+	quiet := false
+	normalized := make([]string, 0, len(args))
+	for _, arg := range args {
+		if long, ok := shortFlags[arg]; ok {
+			arg = long
+		}
+		if replacement, ok := deprecatedFlags[name][arg]; ok {
+			fmt.Fprintf(os.Stderr, "Flag %s is deprecated, use %s; %s will be removed in the next release\n", arg, replacement, arg)
+			arg = replacement
+		}
+		if arg == "--quiet" {
+			quiet = true
+			continue
+		}
+		normalized = append(normalized, arg)
+	}
+	return normalized, quiet
+}
+
+// quietHandler runs handler with its standard output discarded, so only
+// warnings and errors are shown
+func quietHandler(handler func(args []string) error) func(args []string) error {
+	return func(args []string) error {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return handler(args)
+		}
+		defer devNull.Close()
+
+		stdout, colorOutput := os.Stdout, color.Output
+		os.Stdout, color.Output = devNull, devNull
+		defer func() { os.Stdout, color.Output = stdout, colorOutput }()
+		return handler(args)
+	}
+}
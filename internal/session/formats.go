@@ -0,0 +1,122 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the informational commands (incident list/show, memory
+// list, context, reports list)
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// parseOutputFormat removes --format <table|json|yaml> from args. Table is
+// the default; "text" is accepted as another name for it.
+func parseOutputFormat(args []string) (string, []string, error) {
+	format := formatTable
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--format" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", nil, rterrors.Validationf("--format requires a value")
+		}
+		format = args[i+1]
+		i++
+	}
+
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+	case "text":
+		format = formatTable
+	default:
+		return "", nil, rterrors.Validationf("invalid format: %s (valid: table, json, yaml)", format)
+	}
+	return format, rest, nil
+}
+
+// useOutputFormat records the format of the running command. With a machine
+// format, informational messages and the status line go to stderr so stdout
+// holds a single document.
+func (s *Session) useOutputFormat(format string) {
+	s.machineOutput = format != formatTable
+}
+
+// infoWriter is where informational messages of the running command go
+func (s *Session) infoWriter() io.Writer {
+	if s.machineOutput {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printStructured prints v as JSON or YAML. YAML uses the JSON field names so
+// both formats describe the same document.
+func printStructured(format string, v interface{}) error {
+	if format != formatYAML {
+		return printJSON(v)
+	}
+
+	data, err := marshalYAML(v)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// marshalYAML encodes v through its JSON form, keeping the field names and
+// order of the JSON output
+func marshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	// JSON is valid YAML; parsing it into a node keeps the key order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+	blockStyle(&doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the flow and quoting styles a node took from its JSON
+// source. The encoder still quotes strings that would not read back as such,
+// except YAML 1.1 booleans like "yes", which stay quoted here.
+func blockStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" || !yaml11Bools[strings.ToLower(node.Value)] {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// yaml11Bools are the plain scalars older YAML parsers read as booleans
+var yaml11Bools = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true,
+}
@@ -0,0 +1,199 @@
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	cases := []struct {
+		args   []string
+		format string
+		rest   []string
+	}{
+		{nil, formatTable, nil},
+		{[]string{"--format", "text"}, formatTable, nil},
+		{[]string{"--verbose", "--format", "yaml"}, formatYAML, []string{"--verbose"}},
+		{[]string{"--format", "json", "--verbose"}, formatJSON, []string{"--verbose"}},
+	}
+	for _, c := range cases {
+		format, rest, err := parseOutputFormat(c.args)
+		if err != nil || format != c.format || !reflect.DeepEqual(rest, c.rest) {
+			t.Errorf("%v parsed as %q %v (%v), want %q %v", c.args, format, rest, err, c.format, c.rest)
+		}
+	}
+	for _, args := range [][]string{{"--format"}, {"--format", "xml"}} {
+		if _, _, err := parseOutputFormat(args); err == nil {
+			t.Errorf("%v accepted", args)
+		}
+	}
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func() error) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	data := <-done
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	return data
+}
+
+// decodeFormats runs a listing command with --format json and --format yaml
+// and decodes both documents into values of the type of v: the JSON one into
+// v and the YAML one into the value returned. Both formats describe the same
+// document, so the two must be equal apart from the times of each run.
+func decodeFormats(t *testing.T, run func(args []string) error, args []string, v interface{}) interface{} {
+	t.Helper()
+	jsonOut := captureStdout(t, func() error { return run(append(args, "--format", "json")) })
+	if err := json.Unmarshal(jsonOut, v); err != nil {
+		t.Fatalf("JSON output does not parse: %v\n%s", err, jsonOut)
+	}
+
+	yamlOut := captureStdout(t, func() error { return run(append(args, "--format", "yaml")) })
+	var doc interface{}
+	if err := yaml.Unmarshal(yamlOut, &doc); err != nil {
+		t.Fatalf("YAML output does not parse: %v\n%s", err, yamlOut)
+	}
+	viaYAML := reflect.New(reflect.TypeOf(v).Elem())
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, viaYAML.Interface()); err != nil {
+		t.Fatalf("YAML output does not match the JSON fields: %v\n%s", err, yamlOut)
+	}
+	return viaYAML.Interface()
+}
+
+// checkSameDocument fails when the JSON and YAML documents differ
+func checkSameDocument(t *testing.T, fromJSON, fromYAML interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("YAML and JSON documents differ:\n  json %+v\n  yaml %+v", fromJSON, fromYAML)
+	}
+}
+
+// formatsSession is a session with an active incident holding memory keys,
+// saved to its reports directory
+func formatsSession(t *testing.T) (*Session, *IncidentContext) {
+	t.Helper()
+	s := testSession(t)
+	incident := largeIncident(20)
+	incident.Description = "Structured output"
+	incident.Tags = []string{"phishing", "yes"}
+	if err := s.writeIncidentContext(incident); err != nil {
+		t.Fatal(err)
+	}
+	s.activateIncident(incident)
+	incident.SetMemory("host", "WS-042")
+	incident.SetMemory("ports", []interface{}{float64(445), float64(3389)})
+	return s, incident
+}
+
+func TestIncidentListFormats(t *testing.T) {
+	s, incident := formatsSession(t)
+	var summaries []IncidentSummary
+	checkSameDocument(t, &summaries, decodeFormats(t, s.listIncidents, nil, &summaries))
+
+	if len(summaries) != 1 {
+		t.Fatalf("%d incidents listed, want 1", len(summaries))
+	}
+	got := summaries[0]
+	if got.ID != incident.ID || got.Title != incident.Title || got.Severity != incident.Severity || got.Findings != len(incident.Findings) {
+		t.Errorf("incident listed as %+v", got)
+	}
+	if !got.CreatedAt.Equal(incident.CreatedAt) {
+		t.Errorf("created_at listed as %s, want %s", got.CreatedAt, incident.CreatedAt)
+	}
+}
+
+func TestIncidentShowFormats(t *testing.T) {
+	s, incident := formatsSession(t)
+	var detail IncidentDetail
+	viaYAML := decodeFormats(t, s.showIncident, []string{incident.ID}, &detail).(*IncidentDetail)
+
+	if detail.ID != incident.ID || detail.IncidentOverview == nil || viaYAML.IncidentOverview == nil || detail.Clocks == nil || viaYAML.Clocks == nil {
+		t.Fatalf("incident shown as %+v", detail)
+	}
+	detail.Clocks.ComputedAt, viaYAML.Clocks.ComputedAt = time.Time{}, time.Time{}
+	checkSameDocument(t, &detail, viaYAML)
+	if detail.Description != incident.Description || strings.Join(detail.Tags, ",") != "phishing,yes" {
+		t.Errorf("overview shown as %+v", *detail.IncidentOverview)
+	}
+	if detail.Counts.Findings != len(incident.Findings) || detail.Counts.MemoryKeys != 2 {
+		t.Errorf("counts shown as %+v", detail.Counts)
+	}
+}
+
+func TestMemoryListFormats(t *testing.T) {
+	s, _ := formatsSession(t)
+	var entries []MemoryEntry
+	checkSameDocument(t, &entries, decodeFormats(t, s.listMemory, nil, &entries))
+
+	want := []MemoryEntry{
+		{Key: "host", Value: "WS-042"},
+		{Key: "ports", Value: []interface{}{float64(445), float64(3389)}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("memory listed as %+v, want %+v", entries, want)
+	}
+}
+
+func TestContextFormats(t *testing.T) {
+	s, incident := formatsSession(t)
+	var info ContextInfo
+	viaYAML := decodeFormats(t, s.cmdContext, []string{"--verbose"}, &info).(*ContextInfo)
+
+	if !info.Active || info.Incident == nil || info.Incident.ID != incident.ID {
+		t.Fatalf("context shown as %+v", info)
+	}
+	if info.Counts == nil || info.Counts.MemoryKeys != 2 || info.Clocks == nil || viaYAML.Clocks == nil {
+		t.Fatalf("verbose context lacks counts or clocks: %+v", info)
+	}
+	info.Clocks.ComputedAt, viaYAML.Clocks.ComputedAt = time.Time{}, time.Time{}
+	checkSameDocument(t, &info, viaYAML)
+}
+
+func TestReportsListFormats(t *testing.T) {
+	s, _ := formatsSession(t)
+	dir := s.reportsManager.GetHealthReportsDirectory()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "health_check.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var entries []ReportEntry
+	checkSameDocument(t, &entries, decodeFormats(t, s.cmdReports, []string{"list", "health"}, &entries))
+
+	if len(entries) != 1 {
+		t.Fatalf("%d reports listed, want 1: %+v", len(entries), entries)
+	}
+	if got := entries[0]; got.Category != "health" || got.File != "health_check.json" || got.Size != 2 || got.ModifiedAt.IsZero() {
+		t.Errorf("report listed as %+v", got)
+	}
+}
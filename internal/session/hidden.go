@@ -0,0 +1,26 @@
+package session
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// collectHiddenPersistence reads the shell startup files, SSH authorized
+// keys, autostart entries and systemd user units of every home directory
+func collectHiddenPersistence() map[string]interface{} {
+	if runtime.GOOS == "windows" {
+		return map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"note":      "Hidden persistence files are only collected on Unix hosts",
+		}
+	}
+
+	result := collector.CollectHiddenPersistence(collector.UserProfiles(), collector.WalkScope{})
+	return map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"files":     result.Data,
+		"truncated": result.Metadata.Tags["truncated"] == "true",
+	}
+}
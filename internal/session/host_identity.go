@@ -0,0 +1,67 @@
+package session
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// collectionIdentity returns the host identity recorded in a collection
+// report. Collections from before host identities were recorded have none.
+func collectionIdentity(collection map[string]interface{}) (collector.HostIdentity, bool) {
+	host, ok := collection["host"].(map[string]interface{})
+	if !ok {
+		return collector.HostIdentity{}, false
+	}
+	return collector.HostIdentityFromMap(host)
+}
+
+// collectionHostConflicts lists the stored collections that claim the
+// hostname of identity with a different host fingerprint
+func (s *Session) collectionHostConflicts(identity collector.HostIdentity) []collector.HostConflict {
+	paths, _ := filepath.Glob(filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), "collection-RT-*.json"))
+	sort.Strings(paths)
+
+	var conflicts []collector.HostConflict
+	for _, path := range paths {
+		collectionID := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "collection-"), ".json")
+		collection, _, err := s.readCollection(collectionID)
+		if err != nil {
+			continue
+		}
+		other, ok := collectionIdentity(collection)
+		if !ok || !identity.ConflictsWith(other) {
+			continue
+		}
+		conflicts = append(conflicts, collector.HostConflict{
+			Source:      collectionID,
+			Hostname:    other.Hostname,
+			Fingerprint: other.Fingerprint,
+		})
+	}
+	return conflicts
+}
+
+// markHostConflicts flags incident collections whose hostname is shared by
+// collections with a different fingerprint
+func markHostConflicts(entries []IncidentArtifactEntry) {
+	fingerprints := make(map[string]map[string]bool)
+	for _, entry := range entries {
+		if entry.Fingerprint == "" {
+			continue
+		}
+		host := strings.ToLower(entry.Host)
+		if fingerprints[host] == nil {
+			fingerprints[host] = make(map[string]bool)
+		}
+		fingerprints[host][entry.Fingerprint] = true
+	}
+
+	for i := range entries {
+		if entries[i].Fingerprint != "" && len(fingerprints[strings.ToLower(entries[i].Host)]) > 1 {
+			entries[i].HostConflict = true
+		}
+	}
+}
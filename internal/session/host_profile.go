@@ -0,0 +1,100 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/collector"
+)
+
+// printHostProfileSummary prints the highlights of a host profile, one line
+// per section; the saved documents hold the details
+func printHostProfileSummary(profile collector.HostProfile) {
+	fmt.Printf("\nHost Profile: %s (%s/%s)\n", profile.Hostname, profile.Platform, profile.Architecture)
+	fmt.Printf("  Fingerprint: %s\n", valueOrDash(profile.Host.ShortFingerprint()))
+	if env := profile.Host.Environment; env != nil && env.Isolated() {
+		fmt.Printf("  Environment: %s\n", env)
+	}
+	if osInfo := profile.OS; osInfo != nil {
+		fmt.Printf("  OS:          %s\n", joinNonEmpty(" ", osInfo.Name, osInfo.Version, parenthesize("kernel", osInfo.Kernel), parenthesize("patch", osInfo.PatchLevel)))
+		if len(osInfo.Hotfixes) > 0 {
+			fmt.Printf("  Hotfixes:    %d installed\n", len(osInfo.Hotfixes))
+		}
+		if osInfo.UptimeSeconds > 0 {
+			fmt.Printf("  Uptime:      %s\n", collector.FormatUptime(time.Duration(osInfo.UptimeSeconds)*time.Second))
+		}
+		if m := osInfo.Memory; m != nil {
+			fmt.Printf("  Memory:      %s total, %s available\n", collector.FormatBytes(m.TotalBytes), collector.FormatBytes(m.AvailableBytes))
+		}
+	}
+	if profile.Includes(collector.ProfileSoftware) {
+		fmt.Printf("  Software:    %d packages\n", len(profile.Software))
+	}
+	if profile.Includes(collector.ProfileUsers) {
+		interactive, admins := 0, []string{}
+		for _, user := range profile.Users {
+			if user.Interactive {
+				interactive++
+			}
+			if user.Admin {
+				admins = append(admins, user.Username)
+			}
+		}
+		fmt.Printf("  Users:       %d local (%d interactive), %d groups; admins: %s\n",
+			len(profile.Users), interactive, len(profile.Groups), valueOrDash(strings.Join(admins, ", ")))
+	}
+	if network := profile.Network; network != nil {
+		up := 0
+		for _, iface := range network.Interfaces {
+			if iface.Up {
+				up++
+			}
+		}
+		fmt.Printf("  Network:     %d interfaces (%d up); DNS %s\n", len(network.Interfaces), up, valueOrDash(strings.Join(network.DNSServers, ", ")))
+	}
+	if profile.Includes(collector.ProfileDisks) {
+		mounts := make([]string, 0, len(profile.Disks))
+		for _, disk := range profile.Disks {
+			mounts = append(mounts, disk.Path)
+		}
+		fmt.Printf("  Disks:       %d volumes: %s\n", len(profile.Disks), valueOrDash(strings.Join(mounts, ", ")))
+	}
+	if summary := profile.ServiceSummary; summary != nil {
+		fmt.Printf("  Services:    %d installed, %d running, %d start automatically (%s)\n", summary.Total, summary.Running, summary.AutoStart, valueOrDash(summary.Manager))
+	}
+	if profile.Includes(collector.ProfilePersistence) {
+		fmt.Printf("  Persistence: %d startup items, %d scheduled tasks\n", len(profile.StartupItems), len(profile.ScheduledTasks))
+	}
+	if security := profile.Security; security != nil {
+		products := make([]string, 0, len(security.Antivirus))
+		for _, product := range security.Antivirus {
+			products = append(products, product.Name)
+		}
+		fmt.Printf("  Security:    AV/EDR %s\n", valueOrDash(strings.Join(products, ", ")))
+		for _, highlight := range security.Highlights {
+			color.New(color.FgYellow).Printf("    ! %s\n", highlight)
+		}
+	}
+	fmt.Println()
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// parenthesize labels a value in parentheses, or is empty without a value
+func parenthesize(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("(%s %s)", label, value)
+}
@@ -0,0 +1,239 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/vault"
+)
+
+// IncidentPassphraseEnv names the environment variable that supplies the
+// passphrase of encrypted incidents instead of a prompt
+const IncidentPassphraseEnv = "REDTRIAGE_INCIDENT_PASSPHRASE"
+
+// sealedIncident is the stored form of an encrypted incident: a cleartext
+// stub it is listed by and the incident itself, encrypted
+type sealedIncident struct {
+	SchemaVersion int             `json:"schema_version"`
+	ID            string          `json:"id"`
+	Title         string          `json:"title"`
+	Status        string          `json:"status"`
+	Encrypted     *vault.Envelope `json:"encrypted"`
+}
+
+// readSealedIncident returns the stub of an encrypted incident file, or nil
+// when the file is not encrypted
+func readSealedIncident(data []byte) *sealedIncident {
+	var sealed sealedIncident
+	if json.Unmarshal(data, &sealed) != nil || sealed.Encrypted == nil {
+		return nil
+	}
+	return &sealed
+}
+
+// stub returns the incident as far as its stub describes it
+func (sealed *sealedIncident) stub() *IncidentContext {
+	return &IncidentContext{
+		SchemaVersion: sealed.SchemaVersion,
+		ID:            sealed.ID,
+		Title:         sealed.Title,
+		Status:        sealed.Status,
+		Encryption:    sealed.Encrypted.KeySource,
+		locked:        true,
+	}
+}
+
+// listedIncident loads an incident to list it. An encrypted incident not
+// unlocked in this session is listed by its stub, without asking for its
+// key.
+func (s *Session) listedIncident(incidentID string) (*IncidentContext, error) {
+	data, err := os.ReadFile(s.incidentPath(incidentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read incident file: %w", err)
+	}
+	if sealed := readSealedIncident(data); sealed != nil {
+		if key := s.incidentKeys[sealed.ID]; key == nil || !key.Matches(sealed.Encrypted) {
+			return sealed.stub(), nil
+		}
+	}
+	return s.loadIncidentContext(incidentID)
+}
+
+// newIncidentEncryption returns the key source a new incident is encrypted
+// with: the configured one, or a passphrase when --encrypt asks for
+// encryption that is not configured. Empty means no encryption.
+func (s *Session) newIncidentEncryption(requested bool) string {
+	source := ""
+	if s.config != nil && s.config.IncidentEncryption != "off" {
+		source = s.config.IncidentEncryption
+	}
+	if source == "" && requested {
+		source = vault.SourcePassphrase
+	}
+	return source
+}
+
+// sealIncident encrypts the JSON of an incident and returns it behind its
+// stub
+func (s *Session) sealIncident(incident *IncidentContext, data []byte) ([]byte, error) {
+	key, err := s.incidentKey(incident)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := key.Seal(data, []byte(incident.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt incident %s: %w", incident.ID, err)
+	}
+	sealed, err := json.MarshalIndent(sealedIncident{
+		SchemaVersion: incident.SchemaVersion,
+		ID:            incident.ID,
+		Title:         incident.Title,
+		Status:        incident.Status,
+		Encrypted:     envelope,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal encrypted incident: %w", err)
+	}
+	return sealed, nil
+}
+
+// openIncident returns the JSON of a stored incident, decrypting it when
+// it is encrypted, and the key it was decrypted with. A key unlocked
+// earlier in the session is reused; otherwise it is asked for.
+func (s *Session) openIncident(data []byte) ([]byte, *vault.Key, error) {
+	sealed := readSealedIncident(data)
+	if sealed == nil {
+		return data, nil, nil
+	}
+
+	key := s.incidentKeys[sealed.ID]
+	if key == nil || !key.Matches(sealed.Encrypted) {
+		var err error
+		if sealed.Encrypted.KeySource == vault.SourceKeystore {
+			key, err = vault.KeystoreKey(sealed.Encrypted)
+		} else {
+			var passphrase string
+			if passphrase, err = s.readIncidentPassphrase(fmt.Sprintf("Passphrase for incident %s: ", sealed.ID), false); err == nil {
+				key, err = vault.PassphraseKey(sealed.Encrypted, passphrase)
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to unlock incident %s: %w", sealed.ID, err)
+		}
+	}
+
+	plaintext, err := key.Open(sealed.Encrypted, []byte(sealed.ID))
+	if errors.Is(err, vault.ErrWrongKey) {
+		return nil, nil, rterrors.Validationf("cannot decrypt incident %s: wrong passphrase or damaged file", sealed.ID)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt incident %s: %w", sealed.ID, err)
+	}
+	return plaintext, key, nil
+}
+
+// incidentKey returns the key an encrypted incident is written with: the
+// key it was unlocked or created with, or a new one
+func (s *Session) incidentKey(incident *IncidentContext) (*vault.Key, error) {
+	key := incident.key
+	if key == nil {
+		key = s.incidentKeys[incident.ID]
+	}
+	if key == nil || key.Source != incident.Encryption {
+		var err error
+		if key, err = s.newIncidentKey(incident); err != nil {
+			return nil, err
+		}
+	}
+	s.rememberIncidentKey(incident, key)
+	return key, nil
+}
+
+// newIncidentKey creates a key for an incident. A passphrase is asked for
+// twice; it is only kept, derived, in the session's memory.
+func (s *Session) newIncidentKey(incident *IncidentContext) (*vault.Key, error) {
+	switch incident.Encryption {
+	case vault.SourcePassphrase:
+		passphrase, err := s.readIncidentPassphrase("New incident passphrase: ", true)
+		if err != nil {
+			return nil, err
+		}
+		return vault.NewPassphraseKey(passphrase)
+	case vault.SourceKeystore:
+		key, err := vault.NewKeystoreKey(incident.ID)
+		if errors.Is(err, vault.ErrNoKeystore) {
+			return nil, rterrors.Validationf("cannot encrypt incident with a keystore key: %v", err)
+		}
+		return key, err
+	default:
+		return nil, fmt.Errorf("unknown incident encryption: %s", incident.Encryption)
+	}
+}
+
+// rememberIncidentKey keeps the key of an incident for the rest of the
+// session
+func (s *Session) rememberIncidentKey(incident *IncidentContext, key *vault.Key) {
+	incident.key = key
+	if s.incidentKeys == nil {
+		s.incidentKeys = make(map[string]*vault.Key)
+	}
+	s.incidentKeys[incident.ID] = key
+}
+
+// readIncidentPassphrase reads a passphrase from IncidentPassphraseEnv, or
+// asks for it without echo
+func (s *Session) readIncidentPassphrase(prompt string, confirm bool) (string, error) {
+	if passphrase := os.Getenv(IncidentPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if s.rl == nil {
+		return "", rterrors.Validationf("incident passphrase required; set %s", IncidentPassphraseEnv)
+	}
+
+	passphrase, err := s.rl.ReadPassword(prompt)
+	if err != nil || strings.TrimSpace(string(passphrase)) == "" {
+		return "", rterrors.Validationf("no passphrase given")
+	}
+	if confirm {
+		again, err := s.rl.ReadPassword("Repeat the passphrase: ")
+		if err != nil || string(again) != string(passphrase) {
+			return "", rterrors.Validationf("passphrases do not match")
+		}
+	}
+	return string(passphrase), nil
+}
+
+// sealTranscript encrypts a transcript of an encrypted incident, bound to
+// the incident and the transcript name
+func (s *Session) sealTranscript(incident *IncidentContext, name string, data []byte) ([]byte, error) {
+	key, err := s.incidentKey(incident)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := key.Seal(data, []byte(incident.ID+"/"+name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt transcript: %w", err)
+	}
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// openTranscript decrypts a transcript written by sealTranscript
+func (s *Session) openTranscript(incident *IncidentContext, name string, data []byte) ([]byte, error) {
+	var envelope vault.Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse encrypted transcript: %w", err)
+	}
+	key, err := s.incidentKey(incident)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := key.Open(&envelope, []byte(incident.ID+"/"+name))
+	if errors.Is(err, vault.ErrWrongKey) {
+		return nil, rterrors.Validationf("cannot decrypt transcript %s: wrong key or damaged file", name)
+	}
+	return plaintext, err
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/snapshot"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// historyPath returns the file holding an incident's snapshots
+func (s *Session) historyPath(incidentID string) string {
+	return filepath.Join(s.reportsManager.GetIncidentsDirectory(), incidentID, "history.jsonl")
+}
+
+// snapshotDocument decodes a stored incident for its history. The clocks
+// are left out: they are derived and recomputed on every save.
+func snapshotDocument(data []byte) (interface{}, error) {
+	doc, err := snapshot.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if fields, ok := doc.(map[string]interface{}); ok {
+		delete(fields, "clocks")
+	}
+	return doc, nil
+}
+
+// snapshotIncident adds the incident just written to its history, at most
+// once per snapshot interval. A failed snapshot does not fail the save.
+func (s *Session) snapshotIncident(incidentID string, data []byte) {
+	interval := 15 * time.Minute
+	if s.config != nil {
+		interval = s.config.GetSnapshotInterval()
+	}
+	doc, err := snapshotDocument(data)
+	if err == nil {
+		_, err = snapshot.Record(s.historyPath(incidentID), doc, interval, time.Now())
+	}
+	if err != nil {
+		fmt.Fprintf(s.infoWriter(), "Warning: Failed to snapshot incident %s: %v\n", incidentID, err)
+	}
+}
+
+// historyIncidentID returns the incident named by --id, or the active one
+func (s *Session) historyIncidentID(incidentID string) (string, error) {
+	if incidentID == "" {
+		if s.incidentContext == nil {
+			return "", rterrors.Validationf("no active incident (use --id)")
+		}
+		return s.incidentContext.ID, nil
+	}
+	if !s.incidentExists(incidentID) {
+		return "", rterrors.NotFoundf("incident not found: %s", incidentID)
+	}
+	return incidentID, nil
+}
+
+// incidentHistory lists the snapshots of an incident:
+// incident history [--id <incident-id>] [--format table|json|yaml]
+func (s *Session) incidentHistory(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	incidentID := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--id":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--id requires an incident ID")
+			}
+			incidentID = args[i+1]
+			i++
+		default:
+			return rterrors.Validationf("unknown incident history argument: %s", args[i])
+		}
+	}
+	if incidentID, err = s.historyIncidentID(incidentID); err != nil {
+		return err
+	}
+
+	snapshots, err := snapshot.History(s.historyPath(incidentID))
+	if err != nil {
+		return fmt.Errorf("failed to read the history of %s: %w", incidentID, err)
+	}
+	if format != formatTable {
+		if snapshots == nil {
+			snapshots = []snapshot.Snapshot{}
+		}
+		return printStructured(format, snapshots)
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Printf("No snapshots of incident %s yet\n", incidentID)
+		return nil
+	}
+	fmt.Printf("Snapshots of incident %s:\n", incidentID)
+	if err := reporter.SnapshotsTable(snapshots).Render(os.Stdout); err != nil {
+		return err
+	}
+	fmt.Println("Use 'incident diff --from <snapshot> --to <snapshot>' to compare two of them")
+	return nil
+}
+
+// incidentDiff shows the fields that changed between two snapshots of an
+// incident, or between a snapshot and the incident as it is now:
+// incident diff [--id <incident-id>] [--from <snapshot>] [--to <snapshot>|current] [--format table|json|yaml]
+func (s *Session) incidentDiff(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	incidentID, from, to := "", "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--id", "--from", "--to":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--id":
+				incidentID = args[i+1]
+			case "--from":
+				from = args[i+1]
+			default:
+				to = args[i+1]
+			}
+			i++
+		default:
+			return rterrors.Validationf("unknown incident diff argument: %s", args[i])
+		}
+	}
+	if incidentID, err = s.historyIncidentID(incidentID); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(s.incidentPath(incidentID))
+	if err != nil {
+		return fmt.Errorf("failed to read incident file: %w", err)
+	}
+	if readSealedIncident(data) != nil {
+		return rterrors.Validationf("incident %s is encrypted and keeps no history", incidentID)
+	}
+	current, err := snapshotDocument(data)
+	if err != nil {
+		return err
+	}
+	before, patch, err := snapshot.Compare(s.historyPath(incidentID), current, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to compare snapshots of %s: %w", incidentID, err)
+	}
+
+	// Machine formats print the RFC 6902 patch itself
+	if format != formatTable {
+		if patch == nil {
+			patch = []snapshot.Operation{}
+		}
+		return printStructured(format, patch)
+	}
+	if len(patch) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	fmt.Printf("%d changes to incident %s:\n", len(patch), incidentID)
+	return reporter.ChangesTable(snapshot.Describe(before, patch)).Render(os.Stdout)
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// maxIDAttempts bounds how many fresh incident IDs are tried before giving up
+const maxIDAttempts = 16
+
+// generateShortID returns a random 8-character lowercase alphanumeric ID
+func generateShortID() string {
+	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the
+		// clock so an ID is still produced
+		nanos := time.Now().UnixNano()
+		for i := range b {
+			b[i] = byte(nanos >> (8 * i))
+		}
+	}
+	for i := range b {
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+	return string(b)
+}
+
+// newID builds every RedTriage identifier: prefix, the current time in
+// layout and a random short ID, e.g. INC-20250101-k3v9x2qa
+func newID(prefix, layout string) string {
+	return fmt.Sprintf("%s-%s-%s", prefix, time.Now().Format(layout), generateShortID())
+}
+
+// newIncidentID returns a candidate ID for a new incident
+var newIncidentID = func() string {
+	return newID("INC", "20060102")
+}
+
+// incidentPath returns the file an incident is stored in
+func (s *Session) incidentPath(incidentID string) string {
+	return filepath.Join(s.reportsManager.GetIncidentsDirectory(), incidentID+".json")
+}
+
+// incidentExists reports whether an incident file with this ID is stored
+func (s *Session) incidentExists(incidentID string) bool {
+	_, err := os.Stat(s.incidentPath(incidentID))
+	return err == nil
+}
+
+// createIncidentFile assigns a new incident a unique ID and writes it. The
+// ID is checked and the file written under the reports directory lock, so
+// two sessions sharing a reports directory never receive the same ID.
+func (s *Session) createIncidentFile(incident *IncidentContext) error {
+	return s.reportsManager.WithLock(func() error {
+		for attempt := 0; attempt < maxIDAttempts; attempt++ {
+			id := newIncidentID()
+			if s.incidentExists(id) {
+				continue
+			}
+			incident.ID = id
+			return s.writeIncidentContext(incident)
+		}
+		return fmt.Errorf("failed to allocate a unique incident ID after %d attempts", maxIDAttempts)
+	})
+}
+
+// importedIncidentID returns the first free ID for an imported incident
+// whose own ID is taken: <id>-imported, then <id>-imported-2, ...
+func (s *Session) importedIncidentID(incidentID string) string {
+	candidate := incidentID + "-imported"
+	for n := 2; s.incidentExists(candidate); n++ {
+		candidate = fmt.Sprintf("%s-imported-%d", incidentID, n)
+	}
+	return candidate
+}
+
+// importConflictMode decides what to do with an imported incident whose ID
+// already exists: --rename or --merge, otherwise the analyst is asked
+func (s *Session) importConflictMode(incidentID, flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if s.rl == nil {
+		return "", rterrors.Validationf("incident %s already exists; use --rename or --merge", incidentID)
+	}
+
+	s.rl.SetPrompt(fmt.Sprintf("Incident %s already exists. [r]ename, [m]erge or [c]ancel? ", incidentID))
+	defer s.rl.SetPrompt(s.getPrompt())
+
+	answer, err := s.rl.Readline()
+	if err != nil {
+		return "", rterrors.Validationf("import of %s cancelled", incidentID)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "r", "rename":
+		return "rename", nil
+	case "m", "merge":
+		return "merge", nil
+	default:
+		return "", rterrors.Validationf("import of %s cancelled", incidentID)
+	}
+}
+
+// mergeSummary counts what merging an imported incident changed
+type mergeSummary struct {
+	Added      int
+	Duplicates int
+	Conflicts  int
+}
+
+// mergeIndex holds the JSON of every item already in an incident, by ID
+type mergeIndex map[string][]byte
+
+// classify reports whether an incoming item is new, an exact duplicate of
+// the existing item with its ID, or conflicts with it
+func (idx mergeIndex) classify(id string, item interface{}) string {
+	existing, ok := idx[id]
+	if !ok {
+		return "new"
+	}
+	data, _ := json.Marshal(item)
+	if string(data) == string(existing) {
+		return "duplicate"
+	}
+	return "conflict"
+}
+
+// keep decides whether an incoming item is added. An item that conflicts
+// with an existing one is renamed and marked through id and marker, which
+// point into the item; item returns its current value. A conflicting copy
+// already merged by an earlier import counts as a duplicate.
+func (idx mergeIndex) keep(id, marker *string, item func() interface{}, summary *mergeSummary) bool {
+	switch idx.classify(*id, item()) {
+	case "new":
+		summary.Added++
+		return true
+	case "duplicate":
+		summary.Duplicates++
+		return false
+	}
+
+	original := *id
+	*marker = fmt.Sprintf("imported copy of %s, which differs from the item already in the incident", original)
+	*id = original + "-imported"
+	if idx.classify(*id, item()) == "duplicate" {
+		summary.Duplicates++
+		return false
+	}
+	summary.Conflicts++
+	return true
+}
+
+// mergeIncident merges the notes, timeline and findings of an imported
+// incident into an existing one. Identical items are skipped; items whose ID
+// exists with different content are kept under a new ID with a merge
+// conflict marker so the analyst can reconcile them.
+func mergeIncident(existing, incoming *IncidentContext) mergeSummary {
+	var summary mergeSummary
+
+	notes := mergeIndex{}
+	for _, note := range existing.Notes {
+		notes[note.ID], _ = json.Marshal(note)
+	}
+	for _, note := range incoming.Notes {
+		if notes.keep(&note.ID, &note.MergeConflict, func() interface{} { return note }, &summary) {
+			existing.AddNote(note)
+		}
+	}
+
+	events := mergeIndex{}
+	for _, event := range existing.Timeline {
+		events[event.ID], _ = json.Marshal(event)
+	}
+	for _, event := range incoming.Timeline {
+		if events.keep(&event.ID, &event.MergeConflict, func() interface{} { return event }, &summary) {
+			existing.AddTimelineEvent(event)
+		}
+	}
+	sort.SliceStable(existing.Timeline, func(i, j int) bool {
+		return existing.Timeline[i].Timestamp.Before(existing.Timeline[j].Timestamp)
+	})
+
+	findings := mergeIndex{}
+	for _, finding := range existing.Findings {
+		findings[finding.ID], _ = json.Marshal(finding)
+	}
+	for _, finding := range incoming.Findings {
+		if findings.keep(&finding.ID, &finding.MergeConflict, func() interface{} { return finding }, &summary) {
+			existing.AddFindings(finding)
+		}
+	}
+
+	// An accepted findings baseline travels with the incident unless the
+	// existing one has its own
+	if existing.Baseline == nil && incoming.Baseline != nil {
+		existing.Baseline = incoming.Baseline
+	}
+
+	existing.UpdatedAt = time.Now()
+	return summary
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// collidingIDs makes newIncidentID return every ID twice, so concurrent
+// creations collide, and restores it when the test ends
+func collidingIDs(t *testing.T) {
+	t.Helper()
+	var mu sync.Mutex
+	n := 0
+	previous := newIncidentID
+	newIncidentID = func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return fmt.Sprintf("INC-TEST-%d", n/2)
+	}
+	t.Cleanup(func() { newIncidentID = previous })
+}
+
+func TestConcurrentIncidentCreationGetsUniqueIDs(t *testing.T) {
+	collidingIDs(t)
+	dir := t.TempDir()
+
+	const perSession = 10
+	sessions := make([]*Session, 2)
+	for i := range sessions {
+		rm, err := output.NewReportsManager(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions[i] = &Session{reportsManager: rm}
+	}
+
+	ids := make(chan string, len(sessions)*perSession)
+	var wg sync.WaitGroup
+	for _, s := range sessions {
+		wg.Add(1)
+		go func(s *Session) {
+			defer wg.Done()
+			for i := 0; i < perSession; i++ {
+				incident := &IncidentContext{Title: "Concurrent", Status: IncidentOpen}
+				if err := s.createIncidentFile(incident); err != nil {
+					t.Errorf("createIncidentFile: %v", err)
+					return
+				}
+				ids <- incident.ID
+			}
+		}(s)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("incident ID %s was given out twice", id)
+		}
+		seen[id] = true
+		if !sessions[0].incidentExists(id) {
+			t.Errorf("incident %s was not written", id)
+		}
+	}
+	if len(seen) != len(sessions)*perSession {
+		t.Errorf("%d incidents created, want %d", len(seen), len(sessions)*perSession)
+	}
+}
+
+func TestImportedIncidentIDSkipsTakenIDs(t *testing.T) {
+	s := testSession(t)
+	if err := os.MkdirAll(s.reportsManager.GetIncidentsDirectory(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"INC-1", "INC-1-imported", "INC-1-imported-2"} {
+		if err := os.WriteFile(s.incidentPath(id), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if id := s.importedIncidentID("INC-1"); id != "INC-1-imported-3" {
+		t.Errorf("imported incident named %s, want INC-1-imported-3", id)
+	}
+}
@@ -0,0 +1,411 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// defaultTimelineLimit is the number of timeline events 'incident show --timeline' prints
+const defaultTimelineLimit = 20
+
+// IncidentFindingEntry is a finding listed by 'incident show --findings'
+type IncidentFindingEntry struct {
+	ID        string    `json:"id"`
+	Severity  string    `json:"severity"`
+	RuleID    string    `json:"rule_id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Triage    string    `json:"triage_state"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// IncidentArtifactEntry is a collection listed by 'incident show --artifacts'
+type IncidentArtifactEntry struct {
+	ID            string   `json:"id"`
+	Host          string   `json:"host"`
+	CollectedAt   string   `json:"collected_at"`
+	Platform      string   `json:"platform"`
+	ArtifactCount int      `json:"artifact_count"`
+	Fingerprint   string   `json:"fingerprint,omitempty"`
+	HostConflict  bool     `json:"host_conflict,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// ReportEntry is a report file listed by 'reports list' and 'incident show
+// --reports'
+type ReportEntry struct {
+	Category   string    `json:"category"`
+	File       string    `json:"file"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// IncidentSummary is an incident listed by 'incident list', shared with the
+// CLI's 'incident list'
+type IncidentSummary = reporter.IncidentSummary
+
+// IncidentCounts counts the records held by an incident
+type IncidentCounts struct {
+	Artifacts      int `json:"artifacts"`
+	Findings       int `json:"findings"`
+	ActiveFindings int `json:"active_findings"`
+	Notes          int `json:"notes"`
+	TimelineEvents int `json:"timeline_events"`
+	MemoryKeys     int `json:"memory_keys"`
+}
+
+// IncidentDetail is the document 'incident show' prints as JSON or YAML. The
+// overview is left out when findings, artifacts, reports or the timeline are
+// listed instead.
+type IncidentDetail struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Status   string `json:"status"`
+	*IncidentOverview
+	Findings  []IncidentFindingEntry  `json:"findings,omitempty"`
+	Artifacts []IncidentArtifactEntry `json:"artifacts,omitempty"`
+	Reports   []ReportEntry           `json:"reports,omitempty"`
+	Timeline  []TimelineEvent         `json:"timeline,omitempty"`
+}
+
+// IncidentOverview is the part of 'incident show' printed without a listing
+type IncidentOverview struct {
+	Description string          `json:"description"`
+	Analyst     string          `json:"analyst"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Tags        []string        `json:"tags"`
+	Counts      IncidentCounts  `json:"counts"`
+	Clocks      *IncidentClocks `json:"clocks"`
+}
+
+// MemoryEntry is a memory key listed by 'memory list'
+type MemoryEntry struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// ContextInfo is the document 'context' prints as JSON or YAML. Tags,
+// counts and clocks are included with --verbose.
+type ContextInfo struct {
+	Active         bool             `json:"active"`
+	Incident       *IncidentSummary `json:"incident,omitempty"`
+	IsolationLevel string           `json:"isolation_level,omitempty"`
+	Tags           []string         `json:"tags,omitempty"`
+	Counts         *IncidentCounts  `json:"counts,omitempty"`
+	Clocks         *IncidentClocks  `json:"clocks,omitempty"`
+}
+
+// incidentSummary summarizes an incident for 'incident list'
+func incidentSummary(incident *IncidentContext) IncidentSummary {
+	return IncidentSummary{
+		ID:             incident.ID,
+		Title:          incident.Title,
+		Severity:       incident.Severity,
+		Status:         incident.Status,
+		Analyst:        incident.Analyst,
+		CreatedAt:      incident.CreatedAt,
+		UpdatedAt:      incident.UpdatedAt,
+		Findings:       len(incident.Findings),
+		ActiveFindings: activeFindingCount(incident),
+		Locked:         incident.locked,
+	}
+}
+
+// incidentCounts counts the records held by an incident
+func incidentCounts(incident *IncidentContext) IncidentCounts {
+	return IncidentCounts{
+		Artifacts:      len(incident.Artifacts),
+		Findings:       len(incident.Findings),
+		ActiveFindings: activeFindingCount(incident),
+		Notes:          len(incident.Notes),
+		TimelineEvents: len(incident.Timeline),
+		MemoryKeys:     len(incident.Memory),
+	}
+}
+
+// memoryEntries returns the incident's memory keys sorted by key
+func memoryEntries(incident *IncidentContext) []MemoryEntry {
+	entries := make([]MemoryEntry, 0, len(incident.Memory))
+	for key, value := range incident.Memory {
+		entries = append(entries, MemoryEntry{Key: key, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// contextInfo describes the active incident context
+func (s *Session) contextInfo(verbose bool) ContextInfo {
+	incident := s.incidentContext
+	if incident == nil {
+		return ContextInfo{}
+	}
+
+	summary := incidentSummary(incident)
+	info := ContextInfo{Active: true, Incident: &summary, IsolationLevel: incident.IsolationLevel}
+	if verbose {
+		counts := incidentCounts(incident)
+		info.Tags = incident.Tags
+		info.Counts = &counts
+		info.Clocks = s.computeClocks(incident)
+	}
+	return info
+}
+
+// incidentFindings returns the incident's findings ordered by time
+func incidentFindings(incident *IncidentContext) []IncidentFindingEntry {
+	entries := make([]IncidentFindingEntry, 0, len(incident.Findings))
+	for _, finding := range incident.Findings {
+		entries = append(entries, IncidentFindingEntry{
+			ID:        finding.ID,
+			Severity:  finding.Severity,
+			RuleID:    finding.RuleID,
+			Type:      finding.Type,
+			Status:    finding.Status,
+			Triage:    triageState(finding),
+			Timestamp: finding.Timestamp,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries
+}
+
+// incidentArtifacts returns the collections referenced by the incident
+func incidentArtifacts(incident *IncidentContext) []IncidentArtifactEntry {
+	entries := make([]IncidentArtifactEntry, 0, len(incident.Artifacts))
+	for id, raw := range incident.Artifacts {
+		entry := IncidentArtifactEntry{ID: id, Host: "-"}
+
+		if collection, ok := raw.(map[string]interface{}); ok {
+			entry.CollectedAt, _ = collection["timestamp"].(string)
+			entry.Platform, _ = collection["platform"].(string)
+			entry.Host = collectionHost(collection)
+			if identity, ok := collectionIdentity(collection); ok {
+				entry.Fingerprint = identity.Fingerprint
+			}
+
+			switch collected := collection["artifacts_collected"].(type) {
+			case []string:
+				entry.ArtifactCount = len(collected)
+			case []interface{}:
+				entry.ArtifactCount = len(collected)
+			}
+			entry.Tags = stringList(collection["tags"])
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CollectedAt < entries[j].CollectedAt
+	})
+	markHostConflicts(entries)
+
+	return entries
+}
+
+// stringList reads a list of strings from a decoded or in-memory document
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if str, ok := item.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// collectionHost returns the host a collection was taken from
+func collectionHost(collection map[string]interface{}) string {
+	if host, ok := collection["hostname"].(string); ok && host != "" {
+		return host
+	}
+	if artifacts, ok := collection["artifacts"].(map[string]interface{}); ok {
+		if health, ok := artifacts["system_health"].(map[string]interface{}); ok {
+			if host, ok := health["hostname"].(string); ok && host != "" {
+				return host
+			}
+		}
+	}
+	return "-"
+}
+
+// incidentReports finds the report files generated for the incident. Reports
+// record the incident in their incident_context block, so this works for
+// closed incidents as well as the active one.
+func (s *Session) incidentReports(incidentID string) []ReportEntry {
+	var entries []ReportEntry
+
+	for _, category := range []string{"collection", "tests", "health", "system"} {
+		dir, err := s.reportsManager.GetCategoryDirectory(category)
+		if err != nil {
+			continue
+		}
+		files, err := s.reportsManager.ListReports(category)
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			if !strings.HasSuffix(file, ".json") {
+				continue
+			}
+
+			path := filepath.Join(dir, file)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+
+			var report struct {
+				IncidentContext struct {
+					IncidentID string `json:"incident_id"`
+				} `json:"incident_context"`
+			}
+			if err := json.Unmarshal(data, &report); err != nil || report.IncidentContext.IncidentID != incidentID {
+				continue
+			}
+
+			entry := ReportEntry{Category: category, File: file, Path: path}
+			if info, err := os.Stat(path); err == nil {
+				entry.Size = info.Size()
+				entry.ModifiedAt = info.ModTime()
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModifiedAt.Before(entries[j].ModifiedAt)
+	})
+
+	return entries
+}
+
+// reportEntries lists the report files of a category in directory order
+func (s *Session) reportEntries(category string) ([]ReportEntry, error) {
+	dir, err := s.reportsManager.GetCategoryDirectory(category)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.reportsManager.ListReports(category)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ReportEntry, 0, len(files))
+	for _, file := range files {
+		entry := ReportEntry{Category: category, File: file, Path: filepath.Join(dir, file)}
+		if info, err := os.Stat(entry.Path); err == nil {
+			entry.Size = info.Size()
+			entry.ModifiedAt = info.ModTime()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// recentTimeline returns the last n timeline events, or all of them when n <= 0
+func recentTimeline(incident *IncidentContext, n int) []TimelineEvent {
+	events := incident.Timeline
+	if n > 0 && len(events) > n {
+		events = events[len(events)-n:]
+	}
+	return events
+}
+
+func printIncidentFindings(entries []IncidentFindingEntry) {
+	fmt.Printf("\nFindings (%d):\n", len(entries))
+	if len(entries) == 0 {
+		fmt.Println("  No findings recorded")
+		return
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.ID, entry.Severity, entry.RuleID, entry.Status, entry.Triage, entry.Timestamp.Format("2006-01-02 15:04")})
+	}
+	printTable([]string{"ID", "Severity", "Rule", "Status", "Triage", "Time"}, rows)
+}
+
+func printIncidentArtifacts(entries []IncidentArtifactEntry) {
+	fmt.Printf("\nCollections (%d):\n", len(entries))
+	if len(entries) == 0 {
+		fmt.Println("  No collections referenced")
+		return
+	}
+
+	rows := make([][]string, 0, len(entries))
+	conflicts := 0
+	for _, entry := range entries {
+		fingerprint := entry.Fingerprint
+		switch {
+		case fingerprint == "":
+			fingerprint = "-"
+		case len(fingerprint) > 12:
+			fingerprint = fingerprint[:12]
+		}
+		if entry.HostConflict {
+			fingerprint += " !"
+			conflicts++
+		}
+		tags := strings.Join(entry.Tags, ",")
+		if tags == "" {
+			tags = "-"
+		}
+		rows = append(rows, []string{entry.ID, entry.Host, fingerprint, entry.CollectedAt, fmt.Sprintf("%d", entry.ArtifactCount), tags})
+	}
+	printTable([]string{"ID", "Host", "Fingerprint", "Collected", "Artifacts", "Tags"}, rows)
+	if conflicts > 0 {
+		fmt.Println("  ! the same hostname was collected with different host fingerprints; these may be different machines")
+	}
+}
+
+func printIncidentReports(entries []ReportEntry) {
+	fmt.Printf("\nReports (%d):\n", len(entries))
+	if len(entries) == 0 {
+		fmt.Println("  No reports generated")
+		return
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.Category, entry.ModifiedAt.Format("2006-01-02 15:04"), entry.Path})
+	}
+	printTable([]string{"Category", "Modified", "Path"}, rows)
+}
+
+func printIncidentTimeline(events []TimelineEvent, total int) {
+	fmt.Printf("\nTimeline (%d of %d events):\n", len(events), total)
+	if len(events) == 0 {
+		fmt.Println("  No timeline events")
+		return
+	}
+
+	rows := make([][]string, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, []string{event.Timestamp.Format("2006-01-02 15:04:05"), event.EventType, event.Description})
+	}
+	printTable([]string{"Time", "Event", "Description"}, rows)
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// scopeUsage is the syntax of 'incident scope'
+const scopeUsage = "usage: incident scope set [--allow <categories>] [--deny <categories>] [--statement <text>] | show | clear"
+
+// quotedValue reads the flag value starting at args[i]. The session splits
+// lines on spaces, so a quoted value spanning several words is joined back.
+// It returns the value and the index of its last word.
+func quotedValue(args []string, i int) (string, int) {
+	value := args[i]
+	if len(value) == 0 || (value[0] != '\'' && value[0] != '"') {
+		return value, i
+	}
+	quote := value[:1]
+	for j := i; j < len(args); j++ {
+		if j > i {
+			value += " " + args[j]
+		}
+		if len(value) > 1 && strings.HasSuffix(args[j], quote) {
+			return unquote(value), j
+		}
+	}
+	return strings.TrimPrefix(value, quote), len(args) - 1
+}
+
+// cmdIncidentScope manages the collection scope of the active incident:
+// the categories collect may and may not gather for it
+func (s *Session) cmdIncidentScope(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	if len(args) == 0 {
+		return rterrors.Validationf(scopeUsage)
+	}
+	incident := s.incidentContext
+	if incident == nil {
+		return rterrors.Validationf("no active incident; create or switch to one before setting its collection scope")
+	}
+
+	switch args[0] {
+	case "set":
+		scope := &collector.CollectionScope{SetBy: s.getCurrentUser(), SetAt: time.Now()}
+		for i := 1; i < len(args); i++ {
+			switch args[i] {
+			case "--allow", "--deny":
+				if i+1 >= len(args) {
+					return rterrors.Validationf("%s requires a comma-separated list of categories", args[i])
+				}
+				categories, err := collector.ParseScopeCategories(unquote(args[i+1]))
+				if err != nil {
+					return err
+				}
+				if args[i] == "--allow" {
+					scope.Allow = categories
+				} else {
+					scope.Deny = categories
+				}
+				i++
+			case "--statement":
+				if i+1 >= len(args) {
+					return rterrors.Validationf("--statement requires a text")
+				}
+				scope.Statement, i = quotedValue(args, i+1)
+			default:
+				return rterrors.Validationf("unknown incident scope argument: %s", args[i])
+			}
+		}
+		if err := scope.Validate(); err != nil {
+			return err
+		}
+
+		previous := incident.Scope
+		incident.Scope = scope
+		incident.UpdatedAt = time.Now()
+		err := s.saveIncidentContext(incident)
+		s.audit(audit.ScopeSet, incident.ID, map[string]interface{}{"allow": scope.Allow, "deny": scope.Deny, "statement": scope.Statement}, err)
+		if err != nil {
+			incident.Scope = previous
+			return fmt.Errorf("failed to save incident context: %w", err)
+		}
+		s.addTimelineEvent("scope_set", "Collection scope set", map[string]interface{}{
+			"scope":     scope.String(),
+			"statement": scope.Statement,
+		})
+		fmt.Printf("✓ Collection scope of %s: %s\n", incident.ID, scope)
+		fmt.Println("collect skips artifacts outside it and records them; --override-scope <justification> collects them anyway")
+
+	case "show":
+		if format != formatTable {
+			return printStructured(format, incident.Scope)
+		}
+		if incident.Scope == nil {
+			fmt.Printf("No collection scope set for %s; collect gathers every category\n", incident.ID)
+			return nil
+		}
+		fmt.Printf("Collection scope of %s\n", incident.ID)
+		fmt.Printf("  Allowed:   %s\n", valueOrDash(strings.Join(incident.Scope.Allow, ", ")))
+		fmt.Printf("  Denied:    %s\n", valueOrDash(strings.Join(incident.Scope.Deny, ", ")))
+		fmt.Printf("  Statement: %s\n", valueOrDash(incident.Scope.Statement))
+		fmt.Printf("  Set:       %s by %s\n", incident.Scope.SetAt.Format("2006-01-02 15:04:05"), valueOrDash(incident.Scope.SetBy))
+		fmt.Printf("  Categories: %s\n", strings.Join(collector.ScopeCategories, ", "))
+
+	case "clear":
+		if incident.Scope == nil {
+			fmt.Printf("No collection scope set for %s\n", incident.ID)
+			return nil
+		}
+		previous := incident.Scope
+		incident.Scope = nil
+		incident.UpdatedAt = time.Now()
+		err := s.saveIncidentContext(incident)
+		s.audit(audit.ScopeCleared, incident.ID, map[string]interface{}{"scope": previous.String()}, err)
+		if err != nil {
+			incident.Scope = previous
+			return fmt.Errorf("failed to save incident context: %w", err)
+		}
+		s.addTimelineEvent("scope_cleared", "Collection scope cleared", map[string]interface{}{
+			"scope": previous.String(),
+		})
+		fmt.Printf("✓ Cleared the collection scope of %s\n", incident.ID)
+
+	default:
+		return rterrors.Validationf("unknown incident scope action: %s (valid: set, show, clear)", args[0])
+	}
+	return nil
+}
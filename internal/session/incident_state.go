@@ -0,0 +1,214 @@
+package session
+
+import "time"
+
+// What a mutation of an incident changed, as passed to its observers
+const (
+	changeFindings  = "findings"
+	changeTimeline  = "timeline"
+	changeNotes     = "notes"
+	changeArtifacts = "artifacts"
+	changeStatus    = "status"
+	changeToolRuns  = "tool_runs"
+	changeMemory    = "memory"
+)
+
+// incidentStats are counters derived from an incident's findings and
+// artifacts. They are computed on first use and dropped when the incident
+// changes, so the prompt and status line never walk a large incident.
+type incidentStats struct {
+	openFindings     map[string]int // findings not dismissed as false positives, by severity
+	active           int
+	lastCollection   string
+	lastCollectionAt time.Time
+}
+
+// Revision counts the changes made to the incident through its mutation
+// methods since it was loaded
+func (ic *IncidentContext) Revision() uint64 {
+	return ic.revision
+}
+
+// OnChange registers fn to run after every change made through the
+// incident's mutation methods, and returns a function that removes it
+func (ic *IncidentContext) OnChange(fn func(change string)) (remove func()) {
+	if ic.observers == nil {
+		ic.observers = make(map[uint64]func(change string))
+	}
+	ic.nextObserver++
+	id := ic.nextObserver
+	ic.observers[id] = fn
+	return func() { delete(ic.observers, id) }
+}
+
+// changed records a mutation: the derived counters are dropped and the
+// observers told
+func (ic *IncidentContext) changed(change string) {
+	ic.revision++
+	ic.stats = nil
+	for _, fn := range ic.observers {
+		fn(change)
+	}
+}
+
+// AddFindings appends findings to the incident
+func (ic *IncidentContext) AddFindings(findings ...Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	ic.Findings = append(ic.Findings, findings...)
+	ic.changed(changeFindings)
+}
+
+// UpdateFinding applies update to the finding with the given ID and returns
+// it, or nil when the incident has no such finding
+func (ic *IncidentContext) UpdateFinding(id string, update func(*Finding)) *Finding {
+	for i := range ic.Findings {
+		if ic.Findings[i].ID == id {
+			update(&ic.Findings[i])
+			ic.changed(changeFindings)
+			return &ic.Findings[i]
+		}
+	}
+	return nil
+}
+
+// AddTimelineEvent appends an event to the incident's timeline
+func (ic *IncidentContext) AddTimelineEvent(event TimelineEvent) {
+	ic.Timeline = append(ic.Timeline, event)
+	ic.changed(changeTimeline)
+}
+
+// UpdateTimeline applies update to the timeline events from index from on
+func (ic *IncidentContext) UpdateTimeline(from int, update func([]TimelineEvent)) {
+	if from < 0 || from >= len(ic.Timeline) {
+		return
+	}
+	update(ic.Timeline[from:])
+	ic.changed(changeTimeline)
+}
+
+// AddNote appends a note to the incident
+func (ic *IncidentContext) AddNote(note Note) {
+	ic.Notes = append(ic.Notes, note)
+	ic.changed(changeNotes)
+}
+
+// SetArtifact stores a collection or other artifact under id
+func (ic *IncidentContext) SetArtifact(id string, artifact interface{}) {
+	if ic.Artifacts == nil {
+		ic.Artifacts = make(map[string]interface{})
+	}
+	ic.Artifacts[id] = artifact
+	ic.changed(changeArtifacts)
+}
+
+// SetToolRun keeps run as the last run of a tool
+func (ic *IncidentContext) SetToolRun(name string, run *ToolRun) {
+	if ic.ToolRuns == nil {
+		ic.ToolRuns = make(map[string]*ToolRun)
+	}
+	ic.ToolRuns[name] = run
+	ic.changed(changeToolRuns)
+}
+
+// SetMemory stores value under key in the incident's memory
+func (ic *IncidentContext) SetMemory(key string, value interface{}) {
+	if ic.Memory == nil {
+		ic.Memory = make(map[string]interface{})
+	}
+	ic.Memory[key] = value
+	ic.UpdatedAt = time.Now()
+	ic.changed(changeMemory)
+}
+
+// DeleteMemory removes key from the incident's memory and reports whether
+// it was there
+func (ic *IncidentContext) DeleteMemory(key string) bool {
+	if _, ok := ic.Memory[key]; !ok {
+		return false
+	}
+	delete(ic.Memory, key)
+	ic.UpdatedAt = time.Now()
+	ic.changed(changeMemory)
+	return true
+}
+
+// ClearMemory removes every key from the incident's memory
+func (ic *IncidentContext) ClearMemory() {
+	ic.Memory = make(map[string]interface{})
+	ic.UpdatedAt = time.Now()
+	ic.changed(changeMemory)
+}
+
+// SetStatus moves the incident to a lifecycle state without checking the
+// transition
+func (ic *IncidentContext) SetStatus(status string) {
+	ic.Status = status
+	ic.UpdatedAt = time.Now()
+	ic.changed(changeStatus)
+}
+
+// counters returns the derived counters, computing them when the incident
+// changed since they were last used
+func (ic *IncidentContext) counters() *incidentStats {
+	if ic.stats != nil {
+		return ic.stats
+	}
+
+	stats := &incidentStats{openFindings: make(map[string]int)}
+	for _, finding := range ic.Findings {
+		if triageState(finding) != TriageFalsePositive {
+			stats.openFindings[finding.Severity]++
+			stats.active++
+		}
+	}
+	for id, raw := range ic.Artifacts {
+		collection, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		stamp, _ := collection["timestamp"].(string)
+		at, err := time.Parse(time.RFC3339, stamp)
+		if err == nil && (stats.lastCollection == "" || at.After(stats.lastCollectionAt)) {
+			stats.lastCollection, stats.lastCollectionAt = id, at
+		}
+	}
+	ic.stats = stats
+	return stats
+}
+
+// OpenFindings returns the number of findings not dismissed as false
+// positives, by severity
+func (ic *IncidentContext) OpenFindings() map[string]int {
+	counts := make(map[string]int, len(ic.counters().openFindings))
+	for severity, count := range ic.counters().openFindings {
+		counts[severity] = count
+	}
+	return counts
+}
+
+// LastCollection returns the incident's most recent collection and when it
+// was taken, or an empty ID when it has none
+func (ic *IncidentContext) LastCollection() (string, time.Time) {
+	stats := ic.counters()
+	return stats.lastCollection, stats.lastCollectionAt
+}
+
+// activateIncident makes incident the active one. Its changes arm the
+// auto-save for as long as it stays active; the observer of the incident
+// active before is removed, so activating one again does not add another.
+func (s *Session) activateIncident(incident *IncidentContext) {
+	if s.stopAutosave != nil {
+		s.stopAutosave()
+	}
+	s.incidentContext = incident
+	s.incidentID = incident.ID
+	s.memoryIsolation = true
+	s.reportsManager.SetScope(incident.ID)
+	s.stopAutosave = incident.OnChange(func(string) {
+		if s.incidentContext == incident {
+			s.markDirty()
+		}
+	})
+}
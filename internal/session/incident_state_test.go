@@ -0,0 +1,48 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestActivateIncidentKeepsOneObserver(t *testing.T) {
+	s := testSession(t)
+	first, second := largeIncident(10), largeIncident(10)
+	for i := 0; i < 3; i++ {
+		s.activateIncident(first)
+		s.activateIncident(second)
+	}
+	if len(first.observers) != 0 {
+		t.Errorf("inactive incident kept %d observers", len(first.observers))
+	}
+	if len(second.observers) != 1 {
+		t.Errorf("active incident has %d observers, want 1", len(second.observers))
+	}
+}
+
+func TestMemoryChangesArmAutosave(t *testing.T) {
+	s := testSession(t)
+	incident := largeIncident(10)
+	s.activateIncident(incident)
+
+	revision := incident.Revision()
+	incident.SetMemory("host", "WS-042")
+	if !s.dirty || incident.Revision() != revision+1 {
+		t.Fatal("setting a memory key did not mark the incident changed")
+	}
+
+	s.dirty = false
+	if !incident.DeleteMemory("host") || !s.dirty {
+		t.Error("deleting a memory key did not mark the incident changed")
+	}
+	s.dirty = false
+	if incident.DeleteMemory("host") || s.dirty {
+		t.Error("deleting a missing memory key changed the incident")
+	}
+
+	incident.SetMemory("a", 1)
+	s.dirty = false
+	incident.ClearMemory()
+	if len(incident.Memory) != 0 || !s.dirty {
+		t.Error("clearing the memory did not empty it and mark the incident changed")
+	}
+}
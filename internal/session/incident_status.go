@@ -0,0 +1,125 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Incident lifecycle states
+const (
+	IncidentOpen   = "open"
+	IncidentClosed = "closed"
+	IncidentMerged = "merged"
+)
+
+// incidentTransitions lists the states an incident may move to from each
+// state. A closed incident can only be reopened; a merged one is final.
+var incidentTransitions = map[string][]string{
+	IncidentOpen:   {IncidentClosed, IncidentMerged},
+	IncidentClosed: {IncidentOpen},
+	IncidentMerged: {},
+}
+
+// incidentStatus returns the lifecycle state of an incident, treating the
+// empty status of older incident files as open
+func incidentStatus(incident *IncidentContext) string {
+	if incident.Status == "" {
+		return IncidentOpen
+	}
+	return incident.Status
+}
+
+// validateIncidentTransition checks that an incident may move from one
+// state to another
+func validateIncidentTransition(from, to string) error {
+	allowed, known := incidentTransitions[from]
+	if !known {
+		return rterrors.Validationf("incident has unknown status %q", from)
+	}
+	if _, valid := incidentTransitions[to]; !valid {
+		return rterrors.Validationf("unknown incident status %q", to)
+	}
+	for _, next := range allowed {
+		if next == to {
+			return nil
+		}
+	}
+	if len(allowed) == 0 {
+		return rterrors.Validationf("a %s incident cannot change status", from)
+	}
+	return rterrors.Validationf("cannot move a %s incident to %s (allowed: %s)", from, to, strings.Join(allowed, ", "))
+}
+
+// setIncidentStatus moves an incident to a new lifecycle state after
+// checking the transition
+func setIncidentStatus(incident *IncidentContext, to string) error {
+	if err := validateIncidentTransition(incidentStatus(incident), to); err != nil {
+		return err
+	}
+	incident.SetStatus(to)
+	return nil
+}
+
+// reopenIncident reopens a closed incident and makes it the active one:
+// incident reopen --id <incident-id> [--reason <text>]
+func (s *Session) reopenIncident(args []string) error {
+	incidentID, reason := "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--id":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--id requires an incident ID")
+			}
+			incidentID = args[i+1]
+			i++
+		case "--reason":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--reason requires a text")
+			}
+			reason = args[i+1]
+			i++
+		default:
+			return rterrors.Validationf("unknown incident reopen argument: %s", args[i])
+		}
+	}
+	if incidentID == "" {
+		return rterrors.Validationf("incident ID is required (use --id)")
+	}
+
+	if !s.incidentExists(incidentID) {
+		return rterrors.NotFoundf("incident not found: %s", incidentID)
+	}
+	incident, err := s.loadIncidentContext(incidentID)
+	if err != nil {
+		return fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+	}
+	if err := setIncidentStatus(incident, IncidentOpen); err != nil {
+		return err
+	}
+
+	s.activateIncident(incident)
+
+	data := map[string]interface{}{
+		"incident_id": incidentID,
+		"analyst":     s.getCurrentUser(),
+	}
+	if reason != "" {
+		data["reason"] = reason
+	}
+	s.addTimelineEvent("incident_reopened", "Incident reopened", data)
+
+	err = s.saveIncidentContext(incident)
+	s.audit(audit.IncidentReopened, incidentID, args, err)
+	if err != nil {
+		return fmt.Errorf("failed to save incident context: %w", err)
+	}
+
+	s.forcePromptRefresh()
+
+	fmt.Printf("✓ Reopened incident %s: %s\n", incidentID, incident.Title)
+	fmt.Printf("Memory isolation enabled for this incident context.\n")
+	return nil
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// tuningUsage is the syntax of 'incident tuning'
+const tuningUsage = "usage: incident tuning set [--rules <dir>] [--suppress <rule-ids>] [--allow <field=value>]... [--reason <text>] | show | clear"
+
+// cmdIncidentTuning manages the detection tuning of the active incident:
+// the rule set its findings runs load and the matches they suppress
+func (s *Session) cmdIncidentTuning(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
+	if len(args) == 0 {
+		return rterrors.Validationf(tuningUsage)
+	}
+	incident := s.incidentContext
+	if incident == nil {
+		return rterrors.Validationf("no active incident; create or switch to one before tuning its detection")
+	}
+
+	switch args[0] {
+	case "set":
+		tuning := &reporter.DetectionTuning{SetBy: s.getCurrentUser(), SetAt: time.Now()}
+		for i := 1; i < len(args); i++ {
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			switch args[i] {
+			case "--rules":
+				tuning.RulesDir, i = quotedValue(args, i+1)
+			case "--suppress":
+				for _, rule := range strings.Split(unquote(args[i+1]), ",") {
+					if rule = strings.TrimSpace(rule); rule != "" {
+						tuning.SuppressedRules = append(tuning.SuppressedRules, rule)
+					}
+				}
+				i++
+			case "--allow":
+				value, last := quotedValue(args, i+1)
+				entry, err := reporter.ParseAllowEntry(value)
+				if err != nil {
+					return rterrors.Validationf("%w", err)
+				}
+				tuning.Allowlist = append(tuning.Allowlist, entry)
+				i = last
+			case "--reason":
+				tuning.Reason, i = quotedValue(args, i+1)
+			default:
+				return rterrors.Validationf("unknown incident tuning argument: %s", args[i])
+			}
+		}
+		if tuning.Empty() {
+			return rterrors.Validationf("incident tuning set needs --rules, --suppress or --allow")
+		}
+		if tuning.RulesDir != "" {
+			if tuning.RulesDir, err = s.checkTuningRules(tuning.RulesDir); err != nil {
+				return err
+			}
+		}
+
+		previous := incident.Tuning
+		incident.Tuning = tuning
+		incident.UpdatedAt = time.Now()
+		err := s.saveIncidentContext(incident)
+		s.audit(audit.TuningSet, incident.ID, map[string]interface{}{"rules_dir": tuning.RulesDir, "suppressed_rules": tuning.SuppressedRules, "allowlist": tuning.Allowlist, "reason": tuning.Reason}, err)
+		if err != nil {
+			incident.Tuning = previous
+			return fmt.Errorf("failed to save incident context: %w", err)
+		}
+		s.addTimelineEvent("tuning_set", "Detection tuning set", map[string]interface{}{
+			"tuning": tuning.String(),
+			"reason": tuning.Reason,
+		})
+		fmt.Printf("✓ Detection tuning of %s: %s\n", incident.ID, tuning)
+		fmt.Println("findings runs in this incident use it; other incidents and the shared rules are unchanged")
+
+	case "show":
+		if format != formatTable {
+			return printStructured(format, incident.Tuning)
+		}
+		if incident.Tuning.Empty() {
+			fmt.Printf("No detection tuning set for %s; findings use %s without suppressions\n", incident.ID, sigmaRulesDir)
+			return nil
+		}
+		tuning := incident.Tuning
+		allowed := make([]string, 0, len(tuning.Allowlist))
+		for _, entry := range tuning.Allowlist {
+			allowed = append(allowed, entry.String())
+		}
+		rulesDir := tuning.RulesDir
+		if rulesDir == "" {
+			rulesDir = sigmaRulesDir + " (shared)"
+		}
+		fmt.Printf("Detection tuning of %s\n", incident.ID)
+		fmt.Printf("  Rules:      %s\n", rulesDir)
+		fmt.Printf("  Suppressed: %s\n", valueOrDash(strings.Join(tuning.SuppressedRules, ", ")))
+		fmt.Printf("  Allowlist:  %s\n", valueOrDash(strings.Join(allowed, ", ")))
+		fmt.Printf("  Reason:     %s\n", valueOrDash(tuning.Reason))
+		fmt.Printf("  Set:        %s by %s\n", tuning.SetAt.Format("2006-01-02 15:04:05"), valueOrDash(tuning.SetBy))
+
+	case "clear":
+		if incident.Tuning.Empty() {
+			fmt.Printf("No detection tuning set for %s\n", incident.ID)
+			return nil
+		}
+		previous := incident.Tuning
+		incident.Tuning = nil
+		incident.UpdatedAt = time.Now()
+		err := s.saveIncidentContext(incident)
+		s.audit(audit.TuningCleared, incident.ID, map[string]interface{}{"tuning": previous.String()}, err)
+		if err != nil {
+			incident.Tuning = previous
+			return fmt.Errorf("failed to save incident context: %w", err)
+		}
+		s.addTimelineEvent("tuning_cleared", "Detection tuning cleared", map[string]interface{}{
+			"tuning": previous.String(),
+		})
+		fmt.Printf("✓ Cleared the detection tuning of %s\n", incident.ID)
+
+	default:
+		return rterrors.Validationf("unknown incident tuning action: %s (valid: set, show, clear)", args[0])
+	}
+	return nil
+}
+
+// checkTuningRules checks an incident rule set directory holds loadable
+// rules and returns its absolute path, so the incident finds it from any
+// working directory
+func (s *Session) checkTuningRules(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve rules directory: %w", err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return "", rterrors.NotFoundf("rules directory not found: %s", dir)
+	}
+	loaded, _, _, err := s.ruleCache.Load(abs)
+	if err != nil {
+		return "", rterrors.Validationf("cannot load rules from %s: %v", dir, err)
+	}
+	if len(loaded) == 0 {
+		return "", rterrors.Validationf("no Sigma rules in %s", dir)
+	}
+	return abs, nil
+}
+
+// incidentTuning returns the detection tuning of the active incident, or
+// nil
+func (s *Session) incidentTuning() *reporter.DetectionTuning {
+	if s.incidentContext == nil || s.incidentContext.Tuning.Empty() {
+		return nil
+	}
+	return s.incidentContext.Tuning
+}
+
+// loadFindingsRules loads the rules a findings run uses: the rule set of
+// the active incident's tuning, or the shared rules directory
+func (s *Session) loadFindingsRules(noCache, verbose bool) ([]SigmaRule, string) {
+	if tuning := s.incidentTuning(); tuning != nil && tuning.RulesDir != "" {
+		return s.loadSigmaRulesFrom(tuning.RulesDir, noCache, verbose), tuning.RulesDir
+	}
+	return s.loadSigmaRules(noCache, verbose), sigmaRulesDir
+}
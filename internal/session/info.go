@@ -0,0 +1,47 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// cmdInfo shows the effective configuration with the source of every value:
+// info [--format table|json|yaml]
+func (s *Session) cmdInfo(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return rterrors.Validationf("unexpected argument: %s", args[0])
+	}
+	s.useOutputFormat(format)
+
+	cfg := s.config
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	effective := cfg.Effective()
+	if format != formatTable {
+		return printStructured(format, effective)
+	}
+
+	if effective.File != "" {
+		fmt.Printf("Configuration file: %s\n", effective.File)
+	} else {
+		fmt.Println("Configuration file: none (defaults)")
+	}
+	fmt.Println("Precedence: environment (REDTRIAGE_*) > file > defaults")
+	if err := reporter.SettingsTable(effective.Settings).Render(os.Stdout); err != nil {
+		return err
+	}
+	if len(effective.UnknownEnv) > 0 {
+		fmt.Printf("Warning: %s set but not a configuration setting\n", strings.Join(effective.UnknownEnv, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/integrity"
+)
+
+// checkBinaryIntegrity verifies the running binary at startup, records its
+// hash in the custody log and warns, in the audit log too, when the binary
+// differs from its release baseline or ordinary users can modify it
+func (s *Session) checkBinaryIntegrity() {
+	report := integrity.Check()
+	footprint.Current().RecordBinary(report.Custody())
+
+	summary := color.New(color.FgGreen)
+	switch {
+	case report.Tampered():
+		summary = color.New(color.FgRed, color.Bold)
+	case report.DevelopmentBuild() || report.Baseline == integrity.StatusUnavailable:
+		summary = color.New(color.FgYellow)
+	}
+	fmt.Printf("Binary SHA-256: %s\n", valueOrNone(report.SHA256))
+	summary.Printf("Binary integrity: %s\n", report.Summary())
+
+	warnings := report.Warnings()
+	if len(warnings) == 0 {
+		return
+	}
+	warn := color.New(color.FgRed, color.Bold)
+	fmt.Println()
+	for _, warning := range warnings {
+		warn.Printf("WARNING: %s\n", warning)
+	}
+	if report.Tampered() {
+		warn.Println("WARNING: do not trust results from this binary; use a verified copy from trusted media")
+	}
+	fmt.Println()
+	s.audit(audit.BinaryUntrusted, report.Path, map[string]interface{}{
+		"sha256": report.SHA256, "baseline": report.Baseline, "hashes": report.Hashes,
+	}, errors.New(strings.Join(warnings, "; ")))
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// takeNotifyArgs removes --notify-on <severity> from the findings arguments.
+// Without the flag the notifications.notify_on setting applies; the
+// threshold is empty when neither is set.
+func (s *Session) takeNotifyArgs(args []string) (string, []string, error) {
+	var rest []string
+	severity := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--notify-on" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", nil, rterrors.Validationf("--notify-on requires a severity (low, medium, high or critical)")
+		}
+		severity = strings.ToLower(args[i+1])
+		if !reporter.ValidNotifySeverity(severity) {
+			return "", nil, rterrors.Validationf("invalid --notify-on severity '%s': must be low, medium, high or critical", args[i+1])
+		}
+		if len(s.notificationSinks()) == 0 {
+			return "", nil, rterrors.Validationf("--notify-on requires notifications.slack_webhook, notifications.teams_webhook or notifications.smtp in the configuration")
+		}
+		i++
+	}
+
+	if severity == "" {
+		severity = strings.ToLower(s.config.Notifications.NotifyOn)
+	}
+	return severity, rest, nil
+}
+
+// notificationSinks returns the sinks configured under notifications
+func (s *Session) notificationSinks() []reporter.NotificationSink {
+	return reporter.NotificationSinks(s.config.Notifications)
+}
+
+// notifyFindings posts an alert for each rule whose findings meet the
+// threshold. It runs after the findings report is saved and only warns on
+// failure.
+func (s *Session) notifyFindings(minSeverity string, groups []reporter.FindingGroup, collectionID, reportPath string) {
+	host, _ := os.Hostname()
+	if collection, _, err := s.readCollection(collectionID); err == nil {
+		if identity, ok := collectionIdentity(collection); ok && identity.Hostname != "" {
+			host = identity.Hostname
+		}
+	}
+	if absolute, err := filepath.Abs(reportPath); err == nil {
+		reportPath = absolute
+	}
+
+	alerts := reporter.AlertsForGroups(groups, minSeverity, host, collectionID, reportPath)
+	if len(alerts) == 0 {
+		return
+	}
+	sinks := s.notificationSinks()
+	if len(sinks) == 0 {
+		fmt.Printf("Warning: %d findings rules meet the %s notification threshold but no notification sink is configured\n", len(alerts), minSeverity)
+		return
+	}
+
+	limiter := s.loadNotifyLimiter()
+	result := reporter.DispatchAlerts(sinks, alerts, limiter, time.Now())
+	if err := s.saveNotifyLimiter(limiter); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	if result.Sent > 0 {
+		fmt.Printf("✓ Sent %d %s+ findings alerts\n", result.Sent, minSeverity)
+	}
+	if result.CoolingOff > 0 {
+		fmt.Printf("  %d alerts held back: the rule already alerted for %s within %s\n", result.CoolingOff, host, limiter.Cooldown)
+	}
+	if result.OverLimit > 0 {
+		fmt.Printf("  %d alerts held back: at most %d alerts are sent per run; see %s\n", result.OverLimit, limiter.MaxPerRun, reportPath)
+	}
+	for _, notifyErr := range result.Errors {
+		fmt.Printf("Warning: failed to send findings alert via %s\n", notifyErr)
+	}
+}
+
+// loadNotifyLimiter reads the alert history; a missing or unreadable file
+// starts an empty one
+func (s *Session) loadNotifyLimiter() *reporter.NotifyLimiter {
+	limiter, err := reporter.LoadNotifyLimiter(filepath.Join(s.reportsManager.GetMetadataDirectory(), reporter.NotifyStateFile))
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	limiter.MaxPerRun = s.config.Notifications.MaxPerRun
+	limiter.Cooldown = s.config.GetNotifyCooldown()
+	return limiter
+}
+
+// saveNotifyLimiter writes the alert history back
+func (s *Session) saveNotifyLimiter(limiter *reporter.NotifyLimiter) error {
+	path := filepath.Join(s.reportsManager.GetMetadataDirectory(), reporter.NotifyStateFile)
+	return s.reportsManager.WithLock(func() error {
+		return limiter.Save(path)
+	})
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// compareProfile diffs a freshly generated profile against an earlier one,
+// prints and saves the drift, and records each change as a finding of the
+// active incident
+func (s *Session) compareProfile(priorPath string, prior, profile map[string]interface{}, format string) error {
+	drift := reporter.CompareProfiles(priorPath, prior, profile)
+
+	data, err := json.MarshalIndent(drift, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile drift: %w", err)
+	}
+	savedPath, err := s.reportsManager.SaveSystemReport(data, fmt.Sprintf("profile-drift-%s.json", time.Now().Format("20060102-150405")))
+	if err != nil {
+		return fmt.Errorf("failed to save profile drift: %w", err)
+	}
+
+	if s.incidentContext != nil && len(drift.Changes) > 0 {
+		s.incidentContext.AddFindings(driftFindingRecords(drift)...)
+		s.addTimelineEvent("profile_drift", "Host profile compared with an earlier profile", map[string]interface{}{
+			"prior":   priorPath,
+			"changes": len(drift.Changes),
+		})
+		if err := s.saveIncidentContext(s.incidentContext); err != nil {
+			fmt.Fprintf(s.infoWriter(), "Warning: Failed to save incident context: %v\n", err)
+		}
+	}
+
+	if format != formatTable {
+		return printStructured(format, drift)
+	}
+
+	fmt.Printf("\nDrift since %s (%s):\n", priorPath, valueOrDash(drift.PriorTime))
+	if drift.HostMismatch {
+		color.New(color.FgYellow).Println("Warning: the earlier profile is from a different hostname")
+	}
+	if len(drift.Changes) == 0 {
+		fmt.Println("  No changes")
+	}
+	for _, change := range drift.Changes {
+		fmt.Printf("  [%-6s] %s\n", change.Severity, change)
+	}
+	fmt.Printf("Drift report saved to: %s\n", savedPath)
+	if s.incidentContext != nil && len(drift.Changes) > 0 {
+		fmt.Printf("✓ %d change(s) added to the findings of incident %s\n", len(drift.Changes), s.incidentContext.ID)
+	}
+	return nil
+}
+
+// driftFindingRecords turns profile changes into incident findings
+func driftFindingRecords(drift reporter.ProfileDrift) []Finding {
+	records := make([]Finding, 0, len(drift.Changes))
+	for _, change := range drift.Changes {
+		records = append(records, Finding{
+			ID:          newID("FND", "150405"),
+			Type:        "profile_drift",
+			Severity:    change.Severity,
+			Description: change.String(),
+			Evidence: map[string]interface{}{
+				"section": change.Section,
+				"key":     change.Key,
+				"change":  change.Change,
+				"field":   change.Field,
+				"before":  change.Before,
+				"after":   change.After,
+				"prior":   drift.Prior,
+			},
+			RuleID:      "profile-drift-" + change.Change,
+			Timestamp:   time.Now(),
+			Status:      "active",
+			TriageState: TriageNeedsReview,
+		})
+	}
+	return records
+}
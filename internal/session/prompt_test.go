@@ -0,0 +1,109 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// promptEvents are the timeline events of the incident the prompt is
+// measured with
+const promptEvents = 50000
+
+// testSession returns a session with its reports in a temporary directory
+// and the prompt showing the active incident's open findings
+func testSession(t testing.TB) *Session {
+	t.Helper()
+	rm, err := output.NewReportsManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.PromptTemplate = "{brand}[{incident_id}:{open_findings} open]{time}$ "
+	s := &Session{config: cfg, reportsManager: rm, status: "OK", startTime: time.Now()}
+	t.Cleanup(func() {
+		if s.autosaveTimer != nil {
+			s.autosaveTimer.Stop()
+		}
+	})
+	return s
+}
+
+// largeIncident builds an incident with events timeline events, a finding
+// for every tenth and one collection
+func largeIncident(events int) *IncidentContext {
+	now := time.Now()
+	incident := &IncidentContext{
+		ID:        "INC-TIMING",
+		Title:     "Prompt timing",
+		Severity:  "high",
+		Status:    IncidentOpen,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Artifacts: map[string]interface{}{
+			"RT-TIMING": map[string]interface{}{"timestamp": now.Format(time.RFC3339)},
+		},
+		Memory: map[string]interface{}{},
+	}
+	for i := 0; i < events; i++ {
+		incident.Timeline = append(incident.Timeline, TimelineEvent{
+			ID:          fmt.Sprintf("EVT-%d", i),
+			Timestamp:   now.Add(time.Duration(i) * time.Second),
+			EventType:   "collection",
+			Description: "Synthetic event",
+			Source:      "redtriage",
+		})
+		if i%10 == 0 {
+			incident.Findings = append(incident.Findings, Finding{
+				ID:        fmt.Sprintf("FND-%d", i/10),
+				Severity:  severityOrder[i/10%len(severityOrder)],
+				RuleID:    "RT001",
+				Timestamp: now,
+				Status:    "active",
+			})
+		}
+	}
+	return incident
+}
+
+func TestPromptFollowsTriagedFinding(t *testing.T) {
+	s := testSession(t)
+	s.incidentContext = largeIncident(promptEvents)
+
+	open := activeFindingCount(s.incidentContext)
+	if prompt := s.getPrompt(); !strings.Contains(prompt, fmt.Sprintf(":%d open", open)) {
+		t.Fatalf("prompt does not show %d open findings: %q", open, prompt)
+	}
+	s.incidentContext.UpdateFinding("FND-0", func(finding *Finding) {
+		finding.TriageState = TriageFalsePositive
+	})
+	if prompt := s.getPrompt(); !strings.Contains(prompt, fmt.Sprintf(":%d open", open-1)) {
+		t.Errorf("prompt did not follow a triaged finding: %q", prompt)
+	}
+}
+
+// BenchmarkPrompt renders the prompt with a large incident active, which
+// must not walk the incident on every line
+func BenchmarkPrompt(b *testing.B) {
+	s := testSession(b)
+	s.incidentContext = largeIncident(promptEvents)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.getPrompt()
+	}
+}
+
+// BenchmarkStatusLine renders the full status line with a large incident
+// active
+func BenchmarkStatusLine(b *testing.B) {
+	s := testSession(b)
+	s.incidentContext = largeIncident(promptEvents)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.statusFields(statusLineFull)
+	}
+}
@@ -0,0 +1,165 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// quarantineDir returns the directory the active incident's quarantined
+// files are kept in
+func (s *Session) quarantineDir(incidentID string) string {
+	return filepath.Join(s.reportsManager.GetReportsDirectory(), "quarantine", incidentID)
+}
+
+// quarantineRecords reads the records of the files quarantined in dir,
+// oldest first
+func quarantineRecords(dir string) ([]collector.QuarantineRecord, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var records []collector.QuarantineRecord
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read quarantine record: %w", err)
+		}
+		var record collector.QuarantineRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse quarantine record %s: %w", path, err)
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].QuarantinedAt.Before(records[j].QuarantinedAt)
+	})
+	return records, nil
+}
+
+// cmdQuarantine copies a suspicious file into the active incident's
+// quarantine: quarantine <path> [--reason <text>] | quarantine list
+func (s *Session) cmdQuarantine(args []string) error {
+	usage := "usage: quarantine <path> [--reason <text>] | quarantine list [--format table|json|yaml]"
+	if len(args) == 0 {
+		return rterrors.Validationf("%s", usage)
+	}
+	if s.incidentContext == nil {
+		return rterrors.Validationf("quarantine needs an active incident; run 'incident create' or 'incident switch' first")
+	}
+	incident := s.incidentContext
+	dir := s.quarantineDir(incident.ID)
+
+	if args[0] == "list" {
+		format, _, err := parseOutputFormat(args[1:])
+		if err != nil {
+			return err
+		}
+		s.useOutputFormat(format)
+		records, err := quarantineRecords(dir)
+		if err != nil {
+			return err
+		}
+		if format != formatTable {
+			return printStructured(format, records)
+		}
+		if len(records) == 0 {
+			fmt.Printf("No files quarantined for incident %s\n", incident.ID)
+			return nil
+		}
+		rows := make([][]string, 0, len(records))
+		for _, r := range records {
+			rows = append(rows, []string{r.ID, r.QuarantinedAt.Local().Format("2006-01-02 15:04:05"), r.OriginalPath, fmt.Sprintf("%d", r.Size), r.SHA256, valueOrDash(r.Reason)})
+		}
+		printTable([]string{"ID", "Quarantined", "Original Path", "Size", "SHA-256", "Reason"}, rows)
+		return nil
+	}
+
+	var words []string
+	reason := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--reason" {
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--reason requires a value")
+			}
+			reason = unquote(strings.Join(args[i+1:], " "))
+			break
+		}
+		words = append(words, args[i])
+	}
+	if len(words) == 0 {
+		return rterrors.Validationf("%s", usage)
+	}
+	path := unquote(strings.Join(words, " "))
+
+	cfg := s.config
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	maxFile, maxTotal := cfg.GetQuarantineLimits()
+
+	// The incident's limit counts what is already held, so check it first
+	existing, err := quarantineRecords(dir)
+	if err != nil {
+		return err
+	}
+	var held int64
+	for _, r := range existing {
+		held += r.Size
+	}
+	if info, err := os.Stat(path); err == nil && maxTotal > 0 && held+info.Size() > maxTotal {
+		return rterrors.Validationf("quarantining %s (%d bytes) would take incident %s over its limit of %d bytes, %d already held (quarantine.max_total_size)", path, info.Size(), incident.ID, maxTotal, held)
+	}
+
+	ctx, cancel := s.commandContext()
+	defer cancel()
+	record, err := collector.QuarantineFile(ctx, path, collector.QuarantineOptions{
+		ID:       newID("QUA", "20060102-150405"),
+		Dir:      dir,
+		Password: cfg.Quarantine.Password,
+		MaxSize:  maxFile,
+		Reason:   reason,
+	})
+	params := map[string]interface{}{"reason": reason}
+	if record != nil {
+		params["id"] = record.ID
+		params["sha256"] = record.SHA256
+		params["archive"] = record.Archive
+	}
+	s.audit(audit.EvidenceQuarantine, path, params, err)
+	if err != nil {
+		return err
+	}
+	footprint.Current().RecordWrite(record.Archive, "quarantine", false)
+
+	s.addTimelineEvent("quarantine", "Quarantined "+record.OriginalPath, map[string]interface{}{
+		"id":            record.ID,
+		"original_path": record.OriginalPath,
+		"size":          record.Size,
+		"sha256":        record.SHA256,
+		"archive":       record.Archive,
+		"reason":        reason,
+	})
+	if err := s.saveIncidentContext(incident); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Quarantined %s as %s\n", record.OriginalPath, record.ID)
+	fmt.Printf("  Archive: %s\n", record.Archive)
+	fmt.Printf("  Size:    %d bytes\n", record.Size)
+	fmt.Printf("  MD5:     %s\n", record.MD5)
+	fmt.Printf("  SHA-1:   %s\n", record.SHA1)
+	fmt.Printf("  SHA-256: %s\n", record.SHA256)
+	fmt.Printf("  Owner:   %s\n", valueOrDash(record.Owner))
+	fmt.Println("  The archive is encrypted with the quarantine.password setting; the original file was left in place.")
+	return nil
+}
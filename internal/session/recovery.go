@@ -0,0 +1,277 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// sessionStateFile is the name of the session state file in the metadata directory
+const sessionStateFile = "session-state.json"
+
+// SessionState records what a session was working on so that an unclean
+// shutdown can be detected and the context restored on the next start
+type SessionState struct {
+	PID           int       `json:"pid"`
+	Hostname      string    `json:"hostname"`
+	StartedAt     time.Time `json:"started_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	IncidentID    string    `json:"incident_id"`
+	Tool          string    `json:"tool"`
+	LogPath       string    `json:"log_path"`
+	LastSavedAt   time.Time `json:"last_saved_at"`
+	CleanShutdown bool      `json:"clean_shutdown"`
+	// Last run of each tool outside incidents
+	ToolRuns map[string]*ToolRun `json:"tool_runs,omitempty"`
+}
+
+// sessionStatePath returns the path of the session state file
+func (s *Session) sessionStatePath() string {
+	return filepath.Join(s.reportsManager.GetMetadataDirectory(), sessionStateFile)
+}
+
+// loadSessionState reads the session state left by a previous session
+func (s *Session) loadSessionState() (*SessionState, error) {
+	data, err := os.ReadFile(s.sessionStatePath())
+	if err != nil {
+		return nil, err
+	}
+
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// writeSessionState persists the active incident and tool context
+func (s *Session) writeSessionState(clean bool) error {
+	if s.readOnly() {
+		return nil
+	}
+	state := SessionState{
+		PID:           os.Getpid(),
+		Hostname:      getHostname(),
+		StartedAt:     s.startTime,
+		UpdatedAt:     time.Now(),
+		LogPath:       s.logPath,
+		LastSavedAt:   s.lastAutosave,
+		CleanShutdown: clean,
+		ToolRuns:      s.toolRuns,
+	}
+	if s.incidentContext != nil {
+		state.IncidentID = s.incidentContext.ID
+	}
+	if s.currentTool != nil {
+		state.Tool = s.currentTool.Name
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session state: %w", err)
+	}
+
+	if err := output.WriteFileAtomic(s.sessionStatePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+
+	return nil
+}
+
+// restoreToolRuns picks up the tool runs earlier sessions made outside
+// incidents
+func (s *Session) restoreToolRuns() {
+	if state, err := s.loadSessionState(); err == nil {
+		s.toolRuns = state.ToolRuns
+	}
+}
+
+// checkRecovery detects an unclean shutdown of the previous session and
+// offers to restore its incident and tool context
+func (s *Session) checkRecovery() {
+	state, err := s.loadSessionState()
+	if err != nil || state.CleanShutdown {
+		return
+	}
+
+	if state.IncidentID == "" && state.Tool == "" {
+		return
+	}
+
+	color.New(color.FgYellow).Println("Previous session did not shut down cleanly.")
+	fmt.Printf("  Started: %s\n", state.StartedAt.Format(time.RFC3339))
+	fmt.Printf("  Last activity: %s\n", state.UpdatedAt.Format(time.RFC3339))
+
+	var incident *IncidentContext
+	if state.IncidentID != "" {
+		incident, err = s.loadIncidentContext(state.IncidentID)
+		if err != nil {
+			fmt.Printf("  Incident %s could not be loaded: %v\n", state.IncidentID, err)
+		} else {
+			fmt.Printf("  Incident: %s (%s)\n", incident.ID, incident.Title)
+			fmt.Printf("  Last saved: %s\n", incident.UpdatedAt.Format(time.RFC3339))
+			fmt.Printf("  Notes: %d | Findings: %d | Timeline events: %d | Memory keys: %d\n",
+				len(incident.Notes), len(incident.Findings), len(incident.Timeline), len(incident.Memory))
+		}
+	}
+
+	var tool *Tool
+	if state.Tool != "" {
+		tool = s.findTool(state.Tool)
+		if tool != nil {
+			fmt.Printf("  Tool context: %s\n", tool.Name)
+		}
+	}
+
+	if incident == nil && tool == nil {
+		return
+	}
+
+	fmt.Print("Restore previous context? [Y/n]: ")
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "" && answer != "y" && answer != "yes" {
+		fmt.Println("Starting with a fresh context.")
+		fmt.Println()
+		return
+	}
+
+	if incident != nil {
+		s.activateIncident(incident)
+		s.addTimelineEvent("session_recovered", "Context restored after unclean shutdown", map[string]interface{}{
+			"previous_pid":     state.PID,
+			"previous_started": state.StartedAt.Format(time.RFC3339),
+			"analyst":          s.getCurrentUser(),
+		})
+	}
+	s.currentTool = tool
+
+	color.New(color.FgGreen).Println("✓ Previous context restored")
+	fmt.Println()
+}
+
+// findTool looks up a tool by name
+func (s *Session) findTool(name string) *Tool {
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			return &s.tools[i]
+		}
+	}
+	return nil
+}
+
+// markDirty records an incident mutation and (re)arms the debounced auto-save
+func (s *Session) markDirty() {
+	if s.readOnly() {
+		return
+	}
+	s.dirty = true
+
+	interval := 30 * time.Second
+	if s.config != nil {
+		interval = s.config.GetAutosaveInterval()
+	}
+
+	if s.autosaveTimer != nil {
+		s.autosaveTimer.Stop()
+	}
+	s.autosaveTimer = time.AfterFunc(interval, s.autosave)
+}
+
+// autosave persists the active incident context if it has unsaved changes
+func (s *Session) autosave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty || s.incidentContext == nil {
+		return
+	}
+
+	if err := s.saveIncidentContext(s.incidentContext); err != nil {
+		s.logEvent(fmt.Sprintf("auto-save failed for %s: %v", s.incidentContext.ID, err))
+		return
+	}
+	s.lastAutosave = time.Now()
+	s.writeSessionState(false)
+}
+
+// shutdown saves pending work and marks the session state as cleanly closed
+func (s *Session) shutdown() {
+	if s.autosaveTimer != nil {
+		s.autosaveTimer.Stop()
+	}
+
+	if s.dirty && s.incidentContext != nil {
+		if err := s.saveIncidentContext(s.incidentContext); err != nil {
+			fmt.Printf("Warning: Failed to save incident context: %v\n", err)
+		}
+	}
+
+	if err := s.writeSessionState(true); err != nil {
+		fmt.Printf("Warning: Failed to write session state: %v\n", err)
+	}
+
+	if policy := footprint.Current(); policy.IsMinimal() {
+		if path, err := policy.WriteCustodyLog(); err != nil {
+			fmt.Printf("Warning: Failed to write custody log: %v\n", err)
+		} else {
+			fmt.Printf("Custody log written to %s\n", path)
+		}
+	}
+}
+
+// handlePanic attempts a final context save and writes the stack trace to
+// the logs directory before exiting. The session state is left marked as
+// unclean so the next start offers recovery.
+func (s *Session) handlePanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if s.rl != nil {
+		s.rl.Close()
+	}
+
+	color.New(color.FgWhite, color.BgRed).Print("FATAL: ")
+	fmt.Printf("session crashed: %v\n", r)
+
+	if s.incidentContext != nil {
+		if err := s.saveIncidentContext(s.incidentContext); err != nil {
+			fmt.Printf("Failed to save incident context: %v\n", err)
+		} else {
+			fmt.Printf("Incident context %s saved\n", s.incidentContext.ID)
+		}
+	}
+	s.writeSessionState(false)
+
+	trace := fmt.Sprintf("RedTriage session panic\nTime: %s\nPanic: %v\n\n%s",
+		time.Now().Format(time.RFC3339), r, debug.Stack())
+	filename := fmt.Sprintf("panic-%s.log", time.Now().Format("20060102-150405"))
+	if path, err := s.reportsManager.SaveLog([]byte(trace), filename); err == nil {
+		fmt.Printf("Stack trace written to %s\n", path)
+	}
+
+	os.Exit(2)
+}
+
+// logEvent appends a line to the session log file
+func (s *Session) logEvent(message string) {
+	f, err := os.OpenFile(s.logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "[%s] %s\n", time.Now().Format(time.RFC3339), message)
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+)
+
+// collectRemoteAccess gathers the RDP connection history, session events,
+// bitmap cache and settings and the installed remote access tools, with the
+// error of an artifact that could not be read in place of its records.
+// Session events are kept parsed rather than as event XML.
+func collectRemoteAccess() map[string]interface{} {
+	if runtime.GOOS != "windows" {
+		return map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"note":      "RDP and remote access tool artifacts are only collected on Windows",
+		}
+	}
+
+	access := map[string]interface{}{"timestamp": time.Now().Format(time.RFC3339)}
+	for _, result := range collector.CollectLiveRemoteAccess(context.Background()) {
+		if result.Error != nil {
+			access[result.Artifact.Name+"_error"] = result.Error.Error()
+			continue
+		}
+		if result.Artifact.Type != collector.RDPSessionsType {
+			access[result.Artifact.Name] = result.Data
+			continue
+		}
+		text, _ := result.Data.(string)
+		sessions, err := detector.ParseRDPSessionEvents(text)
+		if err != nil {
+			access[result.Artifact.Name+"_error"] = err.Error()
+		}
+		access[result.Artifact.Name] = sessions
+	}
+	return access
+}
@@ -0,0 +1,157 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// maxListedCandidates caps the report names listed for an ambiguous name
+const maxListedCandidates = 10
+
+// cmdReportsOpen prints a saved report, pretty-printing JSON unless --raw
+// is given, or opens an HTML report in the default browser with --browser
+func (s *Session) cmdReportsOpen(args []string) error {
+	raw, browser := false, false
+	var rest []string
+	for _, arg := range args {
+		switch arg {
+		case "--raw":
+			raw = true
+		case "--browser":
+			browser = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if len(rest) < 2 {
+		fmt.Println("Usage: reports open <category> <name> [--raw] [--browser]")
+		fmt.Println("Categories: health, system, collection, tests, logs, metadata")
+		return nil
+	}
+
+	category, name := rest[0], strings.Join(rest[1:], " ")
+	path, err := s.resolveReport(category, name)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if browser {
+		if ext != ".html" && ext != ".htm" {
+			return rterrors.Validationf("--browser opens HTML reports; %s is not one", filepath.Base(path))
+		}
+		if err := openInBrowser(path); err != nil {
+			return fmt.Errorf("failed to open %s in the browser: %w", path, err)
+		}
+		fmt.Printf("Opened %s in the default browser\n", path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	switch {
+	case ext == ".json" && !raw:
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, data, "", "  "); err != nil {
+			return rterrors.Validationf("%s is not valid JSON: %v (use --raw to print it as is)", filepath.Base(path), err)
+		}
+		fmt.Println(pretty.String())
+	case (ext == ".html" || ext == ".htm") && !raw:
+		fmt.Printf("%s is an HTML report (%d bytes)\n", path, len(data))
+		fmt.Println("Use --browser to open it in the default browser, or --raw to print the markup")
+	default:
+		os.Stdout.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// resolveReport finds the report in a category that name refers to: an
+// exact file name, a file name without its extension, or else the single
+// report whose name contains name, or holds its characters in order,
+// ignoring case
+func (s *Session) resolveReport(category, name string) (string, error) {
+	dir, err := s.reportsManager.GetCategoryDirectory(category)
+	if err != nil {
+		return "", rterrors.Validationf("%v (valid: health, system, collection, tests, logs, metadata)", err)
+	}
+	files, err := s.reportsManager.ListReports(category)
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s reports: %w", category, err)
+	}
+
+	needle := strings.ToLower(name)
+	var exact, substring, fuzzy []string
+	for _, file := range files {
+		lower := strings.ToLower(file)
+		switch {
+		case lower == needle || strings.TrimSuffix(lower, filepath.Ext(lower)) == needle:
+			exact = append(exact, file)
+		case strings.Contains(lower, needle):
+			substring = append(substring, file)
+		case isSubsequence(needle, lower):
+			fuzzy = append(fuzzy, file)
+		}
+	}
+
+	for _, matches := range [][]string{exact, substring, fuzzy} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return filepath.Join(dir, matches[0]), nil
+		}
+		sort.Strings(matches)
+		listed := matches
+		if len(listed) > maxListedCandidates {
+			listed = listed[:maxListedCandidates]
+		}
+		return "", rterrors.Validationf("'%s' matches %d %s reports: %s", name, len(matches), category, strings.Join(listed, ", "))
+	}
+	return "", rterrors.NotFoundf("no %s report matches '%s' (use 'reports list %s')", category, name, category)
+}
+
+// isSubsequence reports whether the characters of needle appear in
+// haystack in order
+func isSubsequence(needle, haystack string) bool {
+	rest := haystack
+	for _, r := range needle {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(string(r)):]
+	}
+	return true
+}
+
+// openInBrowser opens a file with the desktop's default handler
+func openInBrowser(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", abs)
+	case "darwin":
+		cmd = exec.Command("open", abs)
+	default:
+		cmd = exec.Command("xdg-open", abs)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,155 @@
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// readOnlyCommands are the commands that still work when the reports
+// directory cannot be written, with the subcommands allowed ("" is the
+// command on its own). A nil list allows every subcommand.
+var readOnlyCommands = map[string][]string{
+	"help":       nil,
+	"?":          nil,
+	"tools":      nil,
+	"categories": nil,
+	"search":     nil,
+	"use":        nil,
+	"rerun":      nil,
+	"banner":     nil,
+	"clear":      nil,
+	"cls":        nil,
+	"exit":       nil,
+	"quit":       nil,
+	"status":     nil,
+	"info":       nil,
+	"context":    nil,
+	"report":     nil,
+	"verify":     nil,
+	"rules":      {"", "reload"},
+	"reports":    {"", "list", "open", "search"},
+	"incident":   {"list", "show", "history", "diff", "export"},
+	"timeline":   {"", "show"},
+	"findings":   {"show"},
+	"quarantine": {"list"},
+}
+
+// openReportsManager checks that the reports directory can be written before
+// anything is saved to it. When it cannot, the analyst is told why and, at a
+// terminal, asked for another directory. Otherwise the directory is opened
+// read-only so existing reports, incidents and bundles can still be read.
+func openReportsManager(reportsDir string) (*output.ReportsManager, error) {
+	interactive := readline.IsTerminal(int(os.Stdin.Fd()))
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		check := output.CheckDirectory(reportsDir)
+		if check.Writable {
+			if check.LowSpace() {
+				color.New(color.FgYellow).Printf("Warning: only %s free for reports in %s\n", formatFreeSpace(check.FreeBytes), reportsDir)
+			}
+			return output.NewReportsManager(reportsDir)
+		}
+
+		color.New(color.FgRed).Printf("Reports directory %s cannot be written: %s\n", reportsDir, check.Problem)
+		if !interactive {
+			fmt.Println("Continuing read-only: reports, incidents and bundles can be read but nothing is saved.")
+			fmt.Println("Set REDTRIAGE_REPORTS_DIR (or reports_dir in the configuration) to a writable directory to save reports.")
+			return output.NewReadOnlyReportsManager(reportsDir), nil
+		}
+
+		fmt.Print("Enter another reports directory, 'r' to continue read-only, or press Enter to quit: ")
+		answer, _ := reader.ReadString('\n')
+		switch answer = strings.TrimSpace(answer); strings.ToLower(answer) {
+		case "":
+			return nil, rterrors.Validationf("no writable reports directory (set REDTRIAGE_REPORTS_DIR to choose one)")
+		case "r", "read-only":
+			fmt.Println("Continuing read-only: reports, incidents and bundles can be read but nothing is saved.")
+			return output.NewReadOnlyReportsManager(reportsDir), nil
+		default:
+			reportsDir = answer
+		}
+	}
+}
+
+// readOnly reports whether the session runs against a read-only reports
+// directory
+func (s *Session) readOnly() bool {
+	return s.reportsManager != nil && s.reportsManager.ReadOnly()
+}
+
+// checkReadOnly refuses a command that would write to a read-only reports
+// directory before it starts, so no work is lost when it tries to save
+func (s *Session) checkReadOnly(name string, args []string) error {
+	if !s.readOnly() || readOnlyAllowed(name, args) {
+		return nil
+	}
+	return rterrors.Validationf("'%s' needs to save to the reports directory, which is read-only (%s); restart with REDTRIAGE_REPORTS_DIR set to a writable directory",
+		strings.TrimSpace(name+" "+subcommand(args)), s.reportsManager.GetReportsDirectory())
+}
+
+// readOnlyAllowed reports whether readOnlyCommands allows a command
+func readOnlyAllowed(name string, args []string) bool {
+	allowed, ok := readOnlyCommands[name]
+	if ok && allowed == nil {
+		return true
+	}
+	sub := subcommand(args)
+	for _, a := range allowed {
+		if a == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// subcommand returns the subcommand of a command's arguments, or "" when
+// it has none
+func subcommand(args []string) string {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0]
+	}
+	return ""
+}
+
+// promptSpillDirectory asks for a directory to save to after a write found
+// the reports filesystem full. The reports manager uses the answer for the
+// rest of the session; an empty answer gives up on the write.
+func (s *Session) promptSpillDirectory(failedPath string, cause error) string {
+	color.New(color.FgRed).Printf("\nCould not save %s: %v\n", failedPath, cause)
+	if s.rl == nil {
+		return ""
+	}
+
+	s.rl.SetPrompt("Directory to save to instead (empty to discard): ")
+	defer s.rl.SetPrompt(s.getPrompt())
+	for {
+		answer, err := s.rl.Readline()
+		answer = strings.TrimSpace(answer)
+		if err != nil || answer == "" {
+			return ""
+		}
+		dir, absErr := filepath.Abs(answer)
+		if absErr != nil {
+			dir = answer
+		}
+		check := output.CheckDirectory(dir)
+		if check.Writable && !check.LowSpace() {
+			color.New(color.FgGreen).Printf("✓ Saving reports to %s for the rest of the session\n", dir)
+			return dir
+		}
+		problem := check.Problem
+		if check.Writable {
+			problem = "only " + formatFreeSpace(check.FreeBytes) + " free"
+		}
+		fmt.Printf("%s cannot be used: %s\n", dir, problem)
+	}
+}
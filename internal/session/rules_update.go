@@ -0,0 +1,66 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+)
+
+// updateRules syncs the rules directory with a remote rule pack, then
+// reloads it so the next findings run uses the new rules. Without a source
+// from --source or rules_update_url it only reloads, as before packs could
+// be synced.
+func (s *Session) updateRules(args []string) error {
+	source, force := "", false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--source":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--source requires a rule pack URL")
+			}
+			i++
+			source = args[i]
+		case "--force":
+			force = true
+		default:
+			return rterrors.Validationf("unknown rules update option: %s (expected --source or --force)", args[i])
+		}
+	}
+	if source == "" && s.config != nil {
+		source = s.config.RulesUpdateURL
+	}
+	if source == "" {
+		if force {
+			return rterrors.Validationf("--force requires a rule pack: --source <url> or rules_update_url in the config")
+		}
+		fmt.Println("No rule pack configured (--source or rules_update_url); reloading the rules directory")
+		return s.reloadRules()
+	}
+
+	fmt.Printf("Updating %s from %s\n", sigmaRulesDir, source)
+	result, err := rules.Update(context.Background(), rules.UpdateOptions{Source: source, Dir: sigmaRulesDir, Force: force})
+	params := map[string]interface{}{"source": source, "force": force}
+	if result != nil {
+		params["added"] = len(result.Added)
+		params["updated"] = len(result.Updated)
+		params["removed"] = len(result.Removed)
+	}
+	s.audit(audit.RulesUpdated, sigmaRulesDir, params, err)
+	if err != nil {
+		return err
+	}
+
+	if result.NotModified {
+		fmt.Println("✓ Rule pack unchanged since the last sync")
+		return nil
+	}
+	fmt.Printf("✓ %d added, %d updated, %d removed, %d unchanged (%d downloaded, %d reused from an interrupted update)\n",
+		len(result.Added), len(result.Updated), len(result.Removed), result.Unchanged, result.Downloaded, result.Resumed)
+	for _, name := range result.Kept {
+		fmt.Printf("Warning: %s is no longer in the pack but was edited locally; kept\n", name)
+	}
+	return s.reloadRules()
+}
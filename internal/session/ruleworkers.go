@@ -0,0 +1,79 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ruleResult is the findings of the rule at index in a findings run
+type ruleResult struct {
+	index    int
+	findings []map[string]interface{}
+}
+
+// analyzeRules evaluates rules against a collection with a pool of workers
+// and returns their findings in rule order, as a run one rule at a time
+// would, with the number of rules that matched. Each rule reads the
+// collection itself, so the number of workers also bounds how many copies
+// of an artifact are in memory at once. done is called in rule order as
+// each rule's findings are in. A cancelled context stops the run.
+func (s *Session) analyzeRules(ctx context.Context, rules []SigmaRule, collectionID string, workers int, done func(rule SigmaRule, findings []map[string]interface{})) ([]map[string]interface{}, int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(rules) {
+		workers = len(rules)
+	}
+
+	jobs := make(chan int)
+	results := make(chan ruleResult, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results <- ruleResult{index, s.analyzeWithRule(ctx, rules[index], collectionID)}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for index := range rules {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results arrive in the order rules finish; they are reported in the
+	// order the rules were given so the report does not depend on timing
+	pending := make([][]map[string]interface{}, len(rules))
+	finished := make([]bool, len(rules))
+	var findings []map[string]interface{}
+	next, matched := 0, 0
+	for result := range results {
+		pending[result.index], finished[result.index] = result.findings, true
+		for ; next < len(rules) && finished[next]; next++ {
+			if done != nil {
+				done(rules[next], pending[next])
+			}
+			findings = append(findings, pending[next]...)
+			if len(pending[next]) > 0 {
+				matched++
+			}
+			pending[next] = nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return findings, matched, fmt.Errorf("findings analysis cancelled: %w", err)
+	}
+	return findings, matched, nil
+}
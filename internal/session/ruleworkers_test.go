@@ -0,0 +1,148 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// timingCollection is the collection the worker pool tests evaluate rules
+// against
+const timingCollection = "RT-TIMING"
+
+// timingSession returns a session holding a timing collection of records
+// event records and as many processes
+func timingSession(t testing.TB, records int) *Session {
+	t.Helper()
+	s := testSession(t)
+	writeTimingCollection(t, filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), timingCollection), records)
+	return s
+}
+
+// poolWorkers is the pool size of the parallel runs: one worker per CPU, at
+// least four so results arrive out of order even on one CPU
+func poolWorkers(s *Session) int {
+	return max(s.config.GetDetectionWorkers(), 4)
+}
+
+func TestAnalyzeRulesPoolKeepsRuleOrder(t *testing.T) {
+	s := timingSession(t, 500)
+	rules := timingRules(500)
+	workers := poolWorkers(s)
+
+	serial, _, err := s.analyzeRules(context.Background(), rules, timingCollection, 1, nil)
+	if err != nil {
+		t.Fatalf("analyzeRules on one worker: %v", err)
+	}
+	parallel, _, err := s.analyzeRules(context.Background(), rules, timingCollection, workers, nil)
+	if err != nil {
+		t.Fatalf("analyzeRules on %d workers: %v", workers, err)
+	}
+
+	if len(serial) == 0 {
+		t.Fatal("no rule matched the timing collection")
+	}
+	if len(parallel) != len(serial) {
+		t.Fatalf("%d workers reported %d findings, one worker %d", workers, len(parallel), len(serial))
+	}
+	for i := range serial {
+		if want, have := findingIdentity(serial[i]), findingIdentity(parallel[i]); want != have {
+			t.Fatalf("finding %d is %s with %d workers, %s with one", i, have, workers, want)
+		}
+	}
+}
+
+func BenchmarkAnalyzeRules(b *testing.B) {
+	s := timingSession(b, 500)
+	rules := timingRules(500)
+	for _, workers := range []int{1, poolWorkers(s)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := s.analyzeRules(context.Background(), rules, timingCollection, workers, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// findingIdentity names a finding by its rule and the record it matched
+func findingIdentity(finding map[string]interface{}) string {
+	ref, _ := finding["evidence_ref"].(*collector.RecordRef)
+	if ref == nil {
+		return fmt.Sprint(finding["rule_id"])
+	}
+	return fmt.Sprintf("%v@%s", finding["rule_id"], ref.ID)
+}
+
+// writeTimingCollection writes a collection stored as a directory of
+// artifacts, with records event records and as many processes
+func writeTimingCollection(t testing.TB, dir string, records int) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	events := make([]map[string]interface{}, records)
+	processes := make([]map[string]interface{}, records)
+	for i := range events {
+		events[i] = map[string]interface{}{
+			"event_id":     4688 + i%4,
+			"time_created": time.Unix(int64(1700000000+i), 0).UTC().Format(time.RFC3339),
+			"channel":      "Security",
+			"computer":     "TIMING-HOST",
+			"data": map[string]interface{}{
+				"NewProcessName": fmt.Sprintf(`C:\Tools\tool%d.exe`, i%100),
+				"CommandLine":    fmt.Sprintf(`tool%d.exe --run %d`, i%100, i),
+				"ParentProcess":  `C:\Windows\explorer.exe`,
+			},
+		}
+		processes[i] = map[string]interface{}{
+			"name":        fmt.Sprintf("tool%d.exe", i%100),
+			"pid":         1000 + i,
+			"cpu_percent": float64(i % 100),
+			"memory_mb":   float64(i % 2000),
+		}
+	}
+	for name, data := range map[string]interface{}{
+		eventRecordsArtifact: events,
+		"processes":          map[string]interface{}{"processes": processes},
+	} {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), raw, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// timingRules returns count rules: event log rules selecting one tool by
+// event ID and image, and every tenth a process heuristic rule
+func timingRules(count int) []SigmaRule {
+	rules := make([]SigmaRule, count)
+	for i := range rules {
+		rules[i] = SigmaRule{
+			Title: fmt.Sprintf("Timing tool %d", i),
+			ID:    fmt.Sprintf("timing-%03d", i),
+			Level: "medium",
+			Detection: map[string]interface{}{
+				"selection": map[string]interface{}{
+					"EventID":        4688 + i%4,
+					"NewProcessName": fmt.Sprintf(`*\tool%d.exe`, i%100),
+				},
+			},
+		}
+		if i%10 == 0 {
+			rules[i].Title = fmt.Sprintf("Timing process %d", i)
+			rules[i].Detection = map[string]interface{}{"condition": "selection"}
+		}
+	}
+	return rules
+}
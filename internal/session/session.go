@@ -1,25 +1,40 @@
 package session
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/archive"
+	"github.com/redtriage/redtriage/internal/audit"
 	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/integrity"
 	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/redact"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/rules"
+	"github.com/redtriage/redtriage/internal/schema"
 	"github.com/redtriage/redtriage/internal/terminal"
 	"github.com/redtriage/redtriage/internal/validation"
 	"github.com/redtriage/redtriage/internal/version"
-	"gopkg.in/yaml.v3"
+	"github.com/redtriage/redtriage/internal/vault"
+	"github.com/redtriage/redtriage/reporter"
 )
 
 const (
@@ -32,15 +47,17 @@ const (
 
 // Tool represents a RedTriage tool with its metadata
 type Tool struct {
-	Name        string
-	Description string
-	Category    string
-	Usage       string
-	Examples    []string
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Category    string         `json:"category"`
+	Usage       string         `json:"usage"`
+	Examples    []string       `json:"examples,omitempty"`
+	Flags       []cmd.FlagInfo `json:"flags,omitempty"`
 }
 
 // IncidentContext represents the isolated memory context for a specific incident
 type IncidentContext struct {
+	SchemaVersion  int                    `json:"schema_version"`
 	ID             string                 `json:"id"`
 	Title          string                 `json:"title"`
 	Description    string                 `json:"description"`
@@ -56,6 +73,28 @@ type IncidentContext struct {
 	Timeline       []TimelineEvent        `json:"timeline"`
 	Memory         map[string]interface{} `json:"memory"`
 	IsolationLevel string                 `json:"isolation_level"`
+	Clocks         *IncidentClocks        `json:"clocks,omitempty"`
+	// Findings accepted as known with 'findings baseline set'
+	Baseline *reporter.AcceptedBaseline `json:"baseline,omitempty"`
+	// What collections for the incident may collect, set with 'incident scope set'
+	Scope *collector.CollectionScope `json:"scope,omitempty"`
+	// Rule set and suppressions of its findings runs, set with 'incident tuning set'
+	Tuning *reporter.DetectionTuning `json:"tuning,omitempty"`
+	// Last run of each tool in the incident, repeated by 'rerun'
+	ToolRuns map[string]*ToolRun `json:"tool_runs,omitempty"`
+	// Key source the stored incident is encrypted with, empty when it is not
+	Encryption string `json:"encryption,omitempty"`
+
+	// Counters derived from the records and the observers of changes,
+	// maintained by the mutation methods in incident_state.go
+	revision     uint64
+	stats        *incidentStats
+	observers    map[uint64]func(change string)
+	nextObserver uint64
+	// Key the stored incident is encrypted with, once unlocked or created
+	key *vault.Key
+	// Set on the stub an encrypted incident is listed by while locked
+	locked bool
 }
 
 // Finding represents a security finding or detection
@@ -68,6 +107,17 @@ type Finding struct {
 	RuleID      string                 `json:"rule_id"`
 	Timestamp   time.Time              `json:"timestamp"`
 	Status      string                 `json:"status"`
+	// Analyst triage decision, set by 'findings triage'
+	TriageState      string     `json:"triage_state,omitempty"`
+	OriginalSeverity string     `json:"original_severity,omitempty"`
+	TriageNote       string     `json:"triage_note,omitempty"`
+	TriagedBy        string     `json:"triaged_by,omitempty"`
+	TriagedAt        *time.Time `json:"triaged_at,omitempty"`
+	// Set when an imported finding was merged next to a different one
+	MergeConflict string `json:"merge_conflict,omitempty"`
+	// The record the evidence was taken from; findings stored before
+	// references were kept embed the whole record instead
+	EvidenceRef *collector.RecordRef `json:"evidence_ref,omitempty"`
 }
 
 // Note represents an analyst note or observation
@@ -77,6 +127,8 @@ type Note struct {
 	Author    string    `json:"author"`
 	Timestamp time.Time `json:"timestamp"`
 	Type      string    `json:"type"`
+	// Set when an imported note was merged next to a different one
+	MergeConflict string `json:"merge_conflict,omitempty"`
 }
 
 // TimelineEvent represents an event in the incident timeline
@@ -87,6 +139,8 @@ type TimelineEvent struct {
 	Description string                 `json:"description"`
 	Source      string                 `json:"source"`
 	Data        map[string]interface{} `json:"data"`
+	// Set when an imported event was merged next to a different one
+	MergeConflict string `json:"merge_conflict,omitempty"`
 }
 
 // Session represents an interactive RedTriage session
@@ -107,29 +161,101 @@ type Session struct {
 	incidentID      string
 	incidentContext *IncidentContext
 	memoryIsolation bool
+	// Stored collection served in place of the live host by 'simulate'
+	simulatedCollection string
+	// Parsed Sigma rules reused across findings runs
+	ruleCache *rules.Cache
+	// Field mappings for rules written for standard Sigma logsources
+	fieldMap *rules.FieldMap
+	// Set while a command prints JSON or YAML; chatter goes to stderr
+	machineOutput bool
+	// Cached state of the status line
+	lastCollectionID string
+	lastCollectionAt time.Time
+	freeSpace        int64
+	freeSpaceKnown   bool
+	freeSpaceAt      time.Time
+	// Names of operations running in the background
+	background []string
 	// Prompt caching to prevent flickering
-	cachedPrompt   string
-	lastPromptHash string
+	cachedPrompt string
+	promptKey    promptKey
+	// Auto-save and crash recovery
+	mu            sync.Mutex
+	dirty         bool
+	autosaveTimer *time.Timer
+	lastAutosave  time.Time
+	// Removes the auto-save observer of the active incident
+	stopAutosave func()
+	// Cancels the running command on Ctrl+C. Commands run under mu, so the
+	// signal handler uses its own lock.
+	cancelMu      sync.Mutex
+	commandCancel context.CancelFunc
+	// Keys of the encrypted incidents unlocked in this session, by ID
+	incidentKeys map[string]*vault.Key
+	// Last run of each tool outside incidents, and what the running
+	// command produced
+	toolRuns   map[string]*ToolRun
+	toolOutput *ToolRun
+}
+
+// Options controls how an interactive session is started
+type Options struct {
+	// Footprint is the footprint mode (standard or minimal)
+	Footprint string
+	// Destination receives all session output in minimal footprint mode
+	Destination string
 }
 
 // StartInteractive starts an interactive RedTriage session
 func StartInteractive() error {
+	return StartInteractiveWithOptions(Options{Footprint: footprint.Standard})
+}
+
+// StartInteractiveWithOptions starts an interactive session with the given options
+func StartInteractiveWithOptions(opts Options) error {
 	// Enable Windows virtual terminal sequences
 	terminal.EnableVirtualTerminal()
 
-	// Load configuration
-	cfg, err := config.Load()
+	if opts.Footprint == "" {
+		opts.Footprint = footprint.Standard
+	}
+	policy, err := footprint.New(opts.Footprint, opts.Destination)
+	if err != nil {
+		return err
+	}
+	footprint.SetCurrent(policy)
+
+	// Load configuration without creating files on the target in minimal mode
+	var cfg *config.Config
+	if policy.IsMinimal() {
+		cfg, err = config.LoadReadOnly()
+	} else {
+		cfg, err = config.Load()
+	}
 	if err != nil {
 		fmt.Printf("Warning: Failed to load configuration: %v\n", err)
 		fmt.Println("Using default configuration...")
 		cfg = config.DefaultConfig()
 	}
 
-	// Initialize reports manager
-	reportsManager, err := output.NewReportsManager(cfg.ReportsDir)
+	// Redirect every report, log and incident file to the destination
+	if policy.IsMinimal() {
+		cfg.ReportsDir = filepath.Join(policy.Destination, "redtriage-reports")
+		cfg.SaveHistory = false
+		policy.RecordWrite(cfg.ReportsDir, "session reports, logs and incident contexts", false)
+	}
+
+	// Initialize reports manager, read-only when the directory cannot be written
+	reportsManager, err := openReportsManager(cfg.ReportsDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize reports manager: %w", err)
 	}
+	cfg.ReportsDir = reportsManager.GetReportsDirectory()
+	ruleCacheDir := filepath.Join(reportsManager.GetMetadataDirectory(), "rule-cache")
+	if reportsManager.ReadOnly() {
+		ruleCacheDir = ""
+	}
 
 	// Initialize command validator
 	validator := validation.NewCommandValidator(true)
@@ -143,24 +269,49 @@ func StartInteractive() error {
 		reportsManager: reportsManager,
 		config:         cfg,
 		validator:      validator,
+		ruleCache:      rules.NewCache(ruleCacheDir),
 	}
 
 	// Initialize available tools
 	session.initializeTools()
 
 	// Setup log path using centralized reports
+	// A full reports filesystem is warned about, not fatal; saves ask for a
+	// spill directory once the prompt is up
 	if err := session.setupLogging(); err != nil {
-		return fmt.Errorf("failed to setup logging: %w", err)
+		if !output.IsDiskFull(err) {
+			return fmt.Errorf("failed to setup logging: %w", err)
+		}
+		color.New(color.FgYellow).Printf("Warning: session log not written, the reports filesystem is full: %v\n", err)
+		session.logPath = ""
+	}
+	if err := session.setupAudit(); err != nil {
+		if !output.IsDiskFull(err) {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		color.New(color.FgYellow).Printf("Warning: audit log not opened, the reports filesystem is full: %v\n", err)
 	}
 
 	// Display banner
 	session.displayBanner()
+	session.checkBinaryIntegrity()
+
+	// Offer to restore context after an unclean shutdown
+	session.restoreToolRuns()
+	if !session.readOnly() {
+		session.checkRecovery()
+	}
+	if err := session.writeSessionState(false); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 
 	// Setup readline
 	if err := session.setupReadline(); err != nil {
 		return fmt.Errorf("failed to setup readline: %w", err)
 	}
 	defer session.rl.Close()
+	defer session.handlePanic()
+	reportsManager.SetSpill(session.promptSpillDirectory)
 
 	// Initialize prompt cache
 	session.initializePromptCache()
@@ -173,6 +324,10 @@ func StartInteractive() error {
 }
 
 func (s *Session) setupLogging() error {
+	if s.readOnly() {
+		return nil
+	}
+
 	// Use centralized reports directory for logs
 	logDir := s.reportsManager.GetLogsDirectory()
 
@@ -195,9 +350,15 @@ func (s *Session) setupLogging() error {
 
 func (s *Session) setupReadline() error {
 	// Create readline instance with custom prompt and improved configuration
+	// No history is written in minimal footprint mode
+	historyFile := filepath.Join(".", ".redtriage_history")
+	if !s.config.SaveHistory {
+		historyFile = ""
+	}
+
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt:          s.getPrompt(),
-		HistoryFile:     filepath.Join(".", ".redtriage_history"),
+		HistoryFile:     historyFile,
 		AutoComplete:    s.getCompleter(),
 		InterruptPrompt: "^C",
 		EOFPrompt:       "exit",
@@ -259,31 +420,132 @@ func (s *Session) setupSignals() {
 	go func() {
 		for range c {
 			fmt.Println("\n^C")
+			s.cancelMu.Lock()
+			if s.commandCancel != nil {
+				s.commandCancel()
+			}
+			s.cancelMu.Unlock()
 			s.rl.SetPrompt(s.getPrompt())
 			s.rl.Refresh()
 		}
 	}()
 }
 
-// generatePromptHash creates a hash of the current prompt context to detect changes
-func (s *Session) generatePromptHash() string {
-	var contextID string
-	var toolName string
+// commandContext returns a context that Ctrl+C cancels while a long-running
+// command uses it. The returned function must be called when the command ends.
+func (s *Session) commandContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.cancelMu.Lock()
+	s.commandCancel = cancel
+	s.cancelMu.Unlock()
+
+	return ctx, func() {
+		s.cancelMu.Lock()
+		s.commandCancel = nil
+		s.cancelMu.Unlock()
+		cancel()
+	}
+}
+
+// promptKey is the state the prompt is rendered from. It is cheap to build
+// and compare, so the rendered prompt is reused until a part changes.
+type promptKey struct {
+	template  string
+	incident  string
+	title     string
+	revision  uint64 // incident changes, for {open_findings}
+	tool      string
+	simulated string
+	status    string
+	minute    string
+}
+
+// currentPromptKey returns the prompt state, leaving out what the template
+// does not reference
+func (s *Session) currentPromptKey() promptKey {
+	key := promptKey{template: s.promptTemplate(), simulated: s.simulatedCollection}
+	if s.incidentContext != nil {
+		key.incident, key.title = s.incidentContext.ID, s.incidentContext.Title
+	}
+	if s.currentTool != nil {
+		key.tool = s.currentTool.Name
+	}
+	if key.template == "" {
+		return key
+	}
+	key.status = s.status
+	if s.incidentContext != nil && strings.Contains(key.template, "{open_findings}") {
+		key.revision = s.incidentContext.Revision()
+	}
+	if strings.Contains(key.template, "{time}") {
+		key.minute = time.Now().Format("15:04")
+	}
+	return key
+}
+
+// promptTemplate returns the configured prompt template, if any
+func (s *Session) promptTemplate() string {
+	if s.config == nil {
+		return ""
+	}
+	return s.config.PromptTemplate
+}
 
+// promptValues returns the current value of every prompt template placeholder
+func (s *Session) promptValues() map[string]string {
+	values := map[string]string{
+		"brand":  "RedTriage",
+		"host":   getHostname(),
+		"user":   s.getCurrentUser(),
+		"status": s.status,
+		"time":   time.Now().Format("15:04"),
+	}
 	if s.incidentContext != nil {
-		contextID = s.incidentContext.ID
+		values["incident_id"] = s.incidentContext.ID
+		values["incident_title"] = s.incidentContext.Title
+		values["open_findings"] = fmt.Sprint(activeFindingCount(s.incidentContext))
 	}
 	if s.currentTool != nil {
-		toolName = s.currentTool.Name
+		values["tool"] = s.currentTool.Name
+	}
+	return values
+}
+
+// renderPromptTemplate substitutes placeholders in the configured template
+func (s *Session) renderPromptTemplate() string {
+	red := color.New(color.FgRed).SprintFunc()
+	triage := color.New(color.FgWhite).SprintFunc()
+	incident := color.New(color.FgYellow).SprintFunc()
+
+	values := s.promptValues()
+	var replacements []string
+	for _, name := range config.PromptPlaceholders {
+		value := values[name]
+		switch name {
+		case "brand":
+			value = red("Red") + triage("Triage")
+		case "incident_id", "incident_title":
+			if value != "" {
+				value = incident(value)
+			}
+		}
+		replacements = append(replacements, "{"+name+"}", value)
 	}
 
-	return fmt.Sprintf("%s|%s", contextID, toolName)
+	return strings.NewReplacer(replacements...).Replace(s.promptTemplate())
 }
 
 func (s *Session) getPrompt() string {
 	// Check if we need to regenerate the prompt
-	currentHash := s.generatePromptHash()
-	if s.cachedPrompt != "" && s.lastPromptHash == currentHash {
+	key := s.currentPromptKey()
+	if s.cachedPrompt != "" && s.promptKey == key {
+		return s.cachedPrompt
+	}
+
+	if key.template != "" {
+		s.cachedPrompt = s.renderPromptTemplate()
+		s.promptKey = key
 		return s.cachedPrompt
 	}
 
@@ -317,27 +579,41 @@ func (s *Session) getPrompt() string {
 			dollar("~"))
 	}
 
-	// Cache the prompt and hash
+	// Mark simulated sessions so stored data is never mistaken for the live host
+	if s.simulatedCollection != "" {
+		prompt = incident("(sim) ") + prompt
+	}
+
+	// Cache the prompt and the state it was rendered from
 	s.cachedPrompt = prompt
-	s.lastPromptHash = currentHash
+	s.promptKey = key
 
 	return prompt
 }
 
 func (s *Session) getCompleter() readline.AutoCompleter {
-	// Basic command completion
-	commands := []string{
-		"help", "banner", "check", "profile", "collect", "findings",
-		"rules", "report", "bundle", "verify", "redact", "export",
-		"config", "plugin", "diag", "health", "clear", "cls", "exit", "quit",
-		"tools", "categories", "search", "use", "reports",
-		// Memory isolation commands
-		"incident", "memory", "context",
+	// Complete the built-in session commands and every tool in the catalog,
+	// with flag completion from the tool metadata
+	var commands []string
+	for name := range builtinCommands {
+		commands = append(commands, name)
+	}
+	for _, tool := range s.tools {
+		if !builtinCommands[tool.Name] {
+			commands = append(commands, tool.Name)
+		}
 	}
+	sort.Strings(commands)
 
 	var items []readline.PrefixCompleterInterface
-	for _, cmd := range commands {
-		items = append(items, readline.PcItem(cmd))
+	for _, name := range commands {
+		var flags []readline.PrefixCompleterInterface
+		if tool := s.findTool(name); tool != nil {
+			for _, flag := range tool.Flags {
+				flags = append(flags, readline.PcItem("--"+flag.Name))
+			}
+		}
+		items = append(items, readline.PcItem(name, flags...))
 	}
 
 	return readline.NewPrefixCompleter(items...)
@@ -379,8 +655,19 @@ func (s *Session) displayBanner() {
 
 	// System info
 	fmt.Printf("Host OS: %s\n", runtime.GOOS)
-	fmt.Printf("Session Log: %s\n", s.logPath)
-	fmt.Printf("Reports Directory: %s\n", s.reportsManager.GetReportsDirectory())
+	if env := collector.DetectEnvironment(); env.Isolated() {
+		fmt.Printf("Environment: %s\n", env)
+		for _, warning := range env.Warnings() {
+			color.New(color.FgYellow).Printf("  Warning: %s\n", warning)
+		}
+	}
+	if s.readOnly() {
+		fmt.Println("Session Log: none (reports directory is read-only)")
+		color.New(color.FgYellow).Printf("Reports Directory: %s (read-only)\n", s.reportsManager.GetReportsDirectory())
+	} else {
+		fmt.Printf("Session Log: %s\n", s.logPath)
+		fmt.Printf("Reports Directory: %s\n", s.reportsManager.GetReportsDirectory())
+	}
 
 	// Tool interface information
 	fmt.Println()
@@ -388,7 +675,7 @@ func (s *Session) displayBanner() {
 	fmt.Println("Type 'help' to explore available tools by category")
 	fmt.Println("Type 'tools' to see all tools in a list")
 	fmt.Println("Type 'categories' to browse tools by function")
-	fmt.Println("Type 'search <term>' to find specific tools")
+	fmt.Println("Type 'search <term>' to find tools, findings, artifacts or memory")
 	fmt.Println("Type 'use <tool>' to switch to a specific tool context")
 	fmt.Println("Type 'reports' to view centralized reports directory")
 	fmt.Println()
@@ -409,6 +696,7 @@ func (s *Session) runREPL() error {
 				continue
 			}
 			if err.Error() == "EOF" {
+				s.shutdown()
 				break
 			}
 			// Log the error and continue instead of exiting
@@ -419,7 +707,11 @@ func (s *Session) runREPL() error {
 		// Trim and skip empty lines
 		line = strings.TrimSpace(line)
 		if line == "" {
-			continue
+			// An empty line repeats the current tool's last run
+			if !s.canRerun() {
+				continue
+			}
+			line = "rerun"
 		}
 
 		// Echo input in green (only if verbose)
@@ -428,100 +720,67 @@ func (s *Session) runREPL() error {
 		}
 
 		// Process command
-		if err := s.processCommand(line); err != nil {
+		if err := s.runCommand(line); err != nil {
 			s.status = "ERROR"
 			// Use white text with red background for error display to avoid color issues
-			color.New(color.FgWhite, color.BgRed).Print("Error: ")
-			fmt.Printf("%v\n", err)
+			label := "Error: "
+			if category := rterrors.CategoryOf(err); category != rterrors.General {
+				label = fmt.Sprintf("Error [%s]: ", category)
+			}
+			color.New(color.FgWhite, color.BgRed).Fprint(s.infoWriter(), label)
+			fmt.Fprintln(s.infoWriter(), output.Sanitize(err.Error()))
 		} else {
 			s.status = "OK"
 		}
 
 		// Show status
 		s.showStatus()
+
+		// Pick up prompt variables changed by the command (cached when unchanged)
+		s.rl.SetPrompt(s.getPrompt())
 	}
 
 	return nil
 }
 
+// runCommand processes a command while holding the session lock so that
+// background auto-saves never observe a half-applied mutation
+func (s *Session) runCommand(line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.machineOutput = false
+	err := s.processCommand(line)
+	s.writeSessionState(false)
+	return err
+}
+
+// initializeTools builds the tool catalog. Tools backed by a CLI command take
+// their metadata from the cobra command tree; session-only tools are listed here.
 func (s *Session) initializeTools() {
-	s.tools = []Tool{
-		{
-			Name:        "check",
-			Description: "Run preflight checks to verify system readiness",
-			Category:    "System",
-			Usage:       "check [--verbose] [--dry-run]",
-			Examples:    []string{"check", "check --verbose", "check --dry-run"},
-		},
-		{
-			Name:        "profile",
-			Description: "Generate comprehensive host profile and system information",
-			Category:    "Collection",
-			Usage:       "profile [--output <dir>] [--include <artifacts>]",
-			Examples:    []string{"profile", "profile --output ./profiles", "profile --include system,network"},
-		},
-		{
-			Name:        "collect",
-			Description: "Perform full triage collection with all available artifacts",
-			Category:    "Collection",
-			Usage:       "collect [--output <dir>] [--timeout <seconds>] [--exclude <artifacts>]",
-			Examples:    []string{"collect", "collect --output ./evidence", "collect --timeout 600"},
-		},
-		{
-			Name:        "findings",
-			Description: "Run detection analysis on collected artifacts using Sigma rules",
-			Category:    "Analysis",
-			Usage:       "findings [--rules <path>] [--output <dir>] [--format <format>]",
-			Examples:    []string{"findings", "findings --rules ./sigma-rules", "findings --format json"},
-		},
-		{
-			Name:        "rules",
-			Description: "Manage and update Sigma detection rules and heuristics",
-			Category:    "Configuration",
-			Usage:       "rules [install|update|list|test] [--source <url>]",
-			Examples:    []string{"rules list", "rules install", "rules update --source https://github.com/SigmaHQ/sigma"},
-		},
-		{
-			Name:        "report",
-			Description: "Generate comprehensive reports from triage data",
-			Category:    "Reporting",
-			Usage:       "report [--input <bundle>] [--format <format>] [--template <template>]",
-			Examples:    []string{"report", "report --format html", "report --template executive"},
-		},
-		{
-			Name:        "bundle",
-			Description: "Create and manage triage data bundles with integrity checks",
-			Category:    "Data Management",
-			Usage:       "bundle [create|extract|list|verify] [--input <dir>] [--output <file>]",
-			Examples:    []string{"bundle create", "bundle list", "bundle verify --input ./evidence.bundle"},
-		},
-		{
-			Name:        "verify",
-			Description: "Verify data integrity and authenticity of triage bundles",
-			Category:    "Data Management",
-			Usage:       "verify [--input <bundle>] [--checksum <file>] [--signature <file>]",
-			Examples:    []string{"verify", "verify --input ./evidence.bundle", "verify --checksum ./checksums.txt"},
-		},
+	handlers := s.commandHandlers()
+
+	sessionTools := []Tool{
 		{
 			Name:        "redact",
 			Description: "Apply redaction rules to remove sensitive information",
 			Category:    "Data Management",
-			Usage:       "redact [--input <bundle>] [--rules <file>] [--output <dir>]",
-			Examples:    []string{"redact", "redact --rules ./redaction-rules.yml", "redact --input ./evidence.bundle"},
+			Usage:       "redact --input <bundle> [--rules <file>] [--output <dir>]",
+			Examples:    []string{"redact --input ./evidence.zip", "redact --input ./evidence.zip --rules ./redaction-rules.yml", "redact --input ./evidence --output ./shared"},
 		},
 		{
 			Name:        "export",
 			Description: "Export specific artifacts in various formats",
 			Category:    "Data Management",
-			Usage:       "export [--input <bundle>] [--format <format>] [--artifacts <list>]",
-			Examples:    []string{"export", "export --format csv", "export --artifacts processes,network"},
+			Usage:       "export [--input <bundle>] [--collection <id>] [--format <format>] [--artifacts <list>] [--fields <list>] [--split-by severity|category] [--output <dir>] [--name <template>] [--redact [--rules <file>]]",
+			Examples:    []string{"export", "export --format csv", "export --artifacts processes,network", "export --artifacts processes --fields name,pid,user --format csv", "export --artifacts findings --split-by severity --format csv", "export --artifacts processes --format csv-excel", "export --format csv --name '{{.Incident}}-{{.Type}}-{{.Date}}'", "export --artifacts processes --format csv --redact", "export --redact --rules ./redaction-rules.yml"},
 		},
 		{
-			Name:        "config",
-			Description: "View and modify RedTriage configuration settings",
-			Category:    "Configuration",
-			Usage:       "config [get|set|edit|reset] [--key <key>] [--value <value>]",
-			Examples:    []string{"config get", "config set --key timeout --value 600", "config edit"},
+			Name:        "simulate",
+			Description: "Replay a stored collection as the live host for training and rule development",
+			Category:    "Analysis",
+			Usage:       "simulate [<collection-id>|off]",
+			Examples:    []string{"simulate", "simulate RT-20250101-120000-abcd1234", "simulate off"},
 		},
 		{
 			Name:        "plugin",
@@ -530,26 +789,12 @@ func (s *Session) initializeTools() {
 			Usage:       "plugin [list|install|remove|test] [--name <name>] [--source <url>]",
 			Examples:    []string{"plugin list", "plugin install --name volatility", "plugin test --name yara"},
 		},
-		{
-			Name:        "diag",
-			Description: "Run diagnostic tests and system health checks",
-			Category:    "System",
-			Usage:       "diag [--verbose] [--output <file>]",
-			Examples:    []string{"diag", "diag --verbose", "diag --output ./diagnostics.log"},
-		},
-		{
-			Name:        "health",
-			Description: "Check RedTriage system health and run comprehensive tests",
-			Category:    "System",
-			Usage:       "health [--verbose] [--output <file>] [--timeout <seconds>] [--skip <checks>] [--run <checks>]",
-			Examples:    []string{"health", "health --verbose", "health --output ./health-report.json", "health --timeout 60"},
-		},
 		{
 			Name:        "reports",
 			Description: "View and manage centralized reports directory",
 			Category:    "System",
-			Usage:       "reports [list <category> | cleanup <duration>]",
-			Examples:    []string{"reports", "reports list collection", "reports cleanup 30d"},
+			Usage:       "reports [list <category> [--format table|json|yaml] | open <category> <name> [--raw] [--browser] | search <term> [category] | cleanup <duration>]",
+			Examples:    []string{"reports", "reports list collection", "reports list collection --format json", "reports open system health", "reports open collection summary --browser", "reports search 192.168.1.100", "reports cleanup 30d"},
 		},
 		{
 			Name:        "banner",
@@ -577,24 +822,83 @@ func (s *Session) initializeTools() {
 			Name:        "incident",
 			Description: "Create, manage, and switch between incident contexts for memory isolation",
 			Category:    "Configuration",
-			Usage:       "incident [create|switch|list|show|close] [--id <id>] [--title <title>] [--severity <level>]",
-			Examples:    []string{"incident create --title 'Network Breach' --severity high", "incident switch --id INC-001", "incident list"},
+			Usage:       "incident [create|switch|list|show|contain|close|reopen|export|import|transcript|history|diff|scope|tuning] [--id <id>] [--title <title>] [--severity <level>] [--encrypt] [--action <action>] [--decrypt] [--rename|--merge] [--from <snapshot>] [--to <snapshot>] [--format table|json|yaml]",
+			Examples:    []string{"incident create --title 'Network Breach' --severity high", "incident create --title 'Insider case' --encrypt", "incident switch --id INC-001", "incident list --format json", "incident show --id INC-001 --findings --timeline --last 10", "incident reopen --id INC-001 --reason 'new activity'", "incident export ./INC-001.json", "incident import ./INC-001.json --rename", "incident history --id INC-001", "incident diff --id INC-001 --from 1 --to 3", "incident scope set --allow processes,network,persistence --deny user_activity,email --statement 'Consent form CF-7'", "incident tuning set --rules ./dev-host-rules --suppress RT-004 --allow process_name=node.exe --reason 'build server'"},
+		},
+		{
+			Name:        "timeline",
+			Description: "Show or export one chronological timeline of incident events, findings, collections and event logs",
+			Category:    "Analysis",
+			Usage:       "timeline [show] [--incident <id> | --collection <id>] [--since <time>] [--until <time>] [--source <list>] [--type <list>] [--format text|csv|json] [--output <file>] | timeline export [--incident <id>] [--format l2tcsv|jsonl] [--output <file>]",
+			Examples:    []string{"timeline", "timeline --since 24h --source finding,log", "timeline --collection RT-20250101-120000-abcd1234 --type login,login_failure --format csv", "timeline export", "timeline export --format jsonl --output ./INC-001.jsonl", "timeline export --incident INC-001 --format l2tcsv --output ./INC-001.csv"},
+		},
+		{
+			Name:        "quarantine",
+			Description: "Copy a suspicious file into the incident's password-protected quarantine, recording its path, hashes and ACL",
+			Category:    "Analysis",
+			Usage:       "quarantine <path> [--reason <text>] | quarantine list [--format table|json|yaml]",
+			Examples:    []string{"quarantine C:\\Users\\Public\\svchost.exe --reason 'dropped by phishing attachment'", "quarantine /tmp/.x/payload", "quarantine list --format json"},
 		},
 		{
 			Name:        "memory",
 			Description: "Manage isolated memory context for current incident",
 			Category:    "Configuration",
-			Usage:       "memory [set|get|list|clear|export] [--key <key>] [--value <value>]",
-			Examples:    []string{"memory set --key 'suspicious_ips' --value '192.168.1.100'", "memory get --key 'suspicious_ips'", "memory list"},
+			Usage:       "memory [set|get|list|clear|export] [--key <key>] [--value <value>] [--format table|json|yaml]",
+			Examples:    []string{"memory set --key 'suspicious_ips' --value '192.168.1.100'", "memory get --key 'suspicious_ips'", "memory list --format yaml"},
+		},
+		{
+			Name:        "status",
+			Description: "Show session status: incident, tool, last collection, open findings and free space",
+			Category:    "System",
+			Usage:       "status [--format table|json|yaml]",
+			Examples:    []string{"status", "status --format json"},
+		},
+		{
+			Name:        "info",
+			Description: "Show the effective configuration and where each value came from (default, file or REDTRIAGE_* environment)",
+			Category:    "System",
+			Usage:       "info [--format table|json|yaml]",
+			Examples:    []string{"info", "info --format json"},
 		},
 		{
 			Name:        "context",
 			Description: "Show current incident context and memory isolation status",
 			Category:    "System",
-			Usage:       "context [--verbose] [--export <file>]",
-			Examples:    []string{"context", "context --verbose", "context --export ./context.json"},
+			Usage:       "context [-v|--verbose] [-o|--output <file> [--decrypt]] [-f|--format table|json|yaml]",
+			Examples:    []string{"context", "context -v -f json", "context -o ./context.json", "context -o ./context.json --decrypt"},
 		},
 	}
+
+	// A session-only entry replaces the CLI command of the same name, whose
+	// subcommands the session extends
+	listed := make(map[string]bool, len(sessionTools))
+	for _, tool := range sessionTools {
+		listed[tool.Name] = true
+	}
+
+	s.tools = nil
+	for _, info := range cmd.Catalog() {
+		// Only expose commands the session can actually run
+		if _, ok := handlers[info.Name]; !ok || info.Path != info.Name || listed[info.Name] {
+			continue
+		}
+		s.tools = append(s.tools, Tool{
+			Name:        info.Name,
+			Description: info.Description,
+			Category:    info.Category,
+			Usage:       info.Usage,
+			Examples:    trimCommandExamples(info.Examples),
+			Flags:       info.Flags,
+		})
+	}
+	s.tools = append(s.tools, sessionTools...)
+
+	// Commands the validator accepts are exactly the ones in the catalog
+	names := make([]string, 0, len(s.tools))
+	for _, tool := range s.tools {
+		names = append(names, tool.Name)
+	}
+	s.validator.SetKnownCommands(names)
 }
 
 func (s *Session) processCommand(line string) error {
@@ -603,106 +907,98 @@ func (s *Session) processCommand(line string) error {
 		return nil
 	}
 
-	cmd := parts[0]
-	args := parts[1:]
-
-	// Define built-in session commands that don't need validation
-	builtinCommands := map[string]bool{
-		"help":       true,
-		"?":          true,
-		"tools":      true,
-		"categories": true,
-		"search":     true,
-		"use":        true,
-		"banner":     true,
-		"clear":      true,
-		"cls":        true,
-		"exit":       true,
-		"quit":       true,
-	}
+	name := parts[0]
+	args, quiet := normalizeFlags(name, parts[1:])
 
 	// Only validate commands that are not built-in session commands
-	if !builtinCommands[cmd] {
+	if !builtinCommands[name] {
 		// Validate command using the new validation system
-		if err := s.validator.ValidateCommand(cmd, args, nil); err != nil {
-			return fmt.Errorf("command validation failed: %w", err)
+		if err := s.validator.ValidateCommand(name, args, nil); err != nil {
+			return rterrors.Validationf("command validation failed: %w", err)
 		}
 	}
 
-	switch cmd {
-	case "help", "?":
-		return s.cmdHelp(args)
-	case "tools":
-		return s.cmdTools()
-	case "categories":
-		return s.cmdCategories()
-	case "search":
-		return s.cmdSearch(args)
-	case "use":
-		return s.cmdUse(args)
-	case "banner":
-		return s.cmdBanner()
-	case "clear", "cls":
-		return s.cmdClear()
-	case "exit", "quit":
-		return s.cmdExit()
-	case "check":
-		return s.cmdCheck(args)
-	case "profile":
-		return s.cmdProfile(args)
-	case "collect":
-		return s.cmdCollect(args)
-	case "findings":
-		return s.cmdFindings(args)
-	case "rules":
-		return s.cmdRules(args)
-	case "report":
-		return s.cmdReport(args)
-	case "bundle":
-		return s.cmdBundle(args)
-	case "verify":
-		return s.cmdVerify(args)
-	case "redact":
-		return s.cmdRedact(args)
-	case "export":
-		return s.cmdExport(args)
-	case "config":
-		return s.cmdConfig(args)
-	case "plugin":
-		return s.cmdPlugin(args)
-	case "diag":
-		return s.cmdDiag(args)
-	case "health":
-		return s.cmdHealth(args)
-	case "reports":
-		return s.cmdReports(args)
-	case "incident":
-		return s.cmdIncident(args)
-	case "memory":
-		return s.cmdMemory(args)
-	case "context":
-		return s.cmdContext(args)
-	default:
-		return fmt.Errorf("unknown command: %s (type 'help' for available commands)", cmd)
+	handler, ok := s.commandHandlers()[name]
+	if !ok {
+		return rterrors.Validationf("unknown command: %s (type 'help' for available commands)", name)
+	}
+
+	if err := s.checkReadOnly(name, args); err != nil {
+		return err
 	}
-}
 
-func (s *Session) showStatus() {
-	elapsed := time.Since(s.startTime).Round(time.Second)
-	statusColor := color.FgGreen
-	if s.status == "ERROR" {
-		statusColor = color.FgRed
-	} else if s.status == "WARN" {
-		statusColor = color.FgYellow
+	// Simulation serves stored data, so nothing may touch the live host
+	if s.simulatedCollection != "" && liveCollectionCommands[name] {
+		return rterrors.Validationf("%s is disabled in simulation mode (serving collection %s). Run 'simulate off' to return to live collection", name, s.simulatedCollection)
 	}
 
-	// Show current tool context if available
-	if s.currentTool != nil {
-		color.New(statusColor).Printf("[%s] ", s.status)
-		fmt.Printf("Tool: %s | Session: %s\n", s.currentTool.Name, elapsed)
-	} else {
-		color.New(statusColor).Printf("[%s] ", s.status)
-		fmt.Printf("Session: %s | Ready\n", elapsed)
+	if quiet {
+		handler = quietHandler(handler)
+	}
+	s.toolOutput = nil
+	err := s.runTranscribed(name, args, handler)
+	if !builtinCommands[name] && s.findTool(name) != nil {
+		s.recordToolRun(name, parts[1:], err)
+	}
+	return err
+}
+
+// builtinCommands are the session commands that manage the session itself.
+// They are not validated against the tool catalog.
+var builtinCommands = map[string]bool{
+	"help":       true,
+	"?":          true,
+	"tools":      true,
+	"categories": true,
+	"search":     true,
+	"use":        true,
+	"rerun":      true,
+	"banner":     true,
+	"clear":      true,
+	"cls":        true,
+	"exit":       true,
+	"quit":       true,
+}
+
+// commandHandlers maps every session command and alias to its implementation
+func (s *Session) commandHandlers() map[string]func(args []string) error {
+	return map[string]func(args []string) error{
+		"help":       s.cmdHelp,
+		"?":          s.cmdHelp,
+		"tools":      s.cmdTools,
+		"categories": func(args []string) error { return s.cmdCategories() },
+		"search":     s.cmdSearch,
+		"use":        s.cmdUse,
+		"rerun":      s.cmdRerun,
+		"banner":     func(args []string) error { return s.cmdBanner() },
+		"clear":      func(args []string) error { return s.cmdClear() },
+		"cls":        func(args []string) error { return s.cmdClear() },
+		"exit":       func(args []string) error { return s.cmdExit() },
+		"quit":       func(args []string) error { return s.cmdExit() },
+		"check":      s.cmdCheck,
+		"profile":    s.cmdProfile,
+		"collect":    s.cmdCollect,
+		"findings":   s.cmdFindings,
+		"rules":      s.cmdRules,
+		"report":     s.cmdReport,
+		"bundle":     s.cmdBundle,
+		"verify":     s.cmdVerify,
+		"redact":     s.cmdRedact,
+		"export":     s.cmdExport,
+		"simulate":   s.cmdSimulate,
+		"config":     s.cmdConfig,
+		"plugin":     s.cmdPlugin,
+		"diag":       s.cmdDiag,
+		"health":     s.cmdHealth,
+		"reports":    s.cmdReports,
+		"incident":   s.cmdIncident,
+		"memory":     s.cmdMemory,
+		"context":    s.cmdContext,
+		"audit":      s.cmdAudit,
+		"timeline":   s.cmdTimeline,
+		"quarantine": s.cmdQuarantine,
+		"status":     s.cmdStatus,
+		"info":       s.cmdInfo,
 	}
 }
 
@@ -718,7 +1014,7 @@ func (s *Session) refreshPrompt() {
 func (s *Session) forcePromptRefresh() {
 	// Clear the cached prompt to force regeneration
 	s.cachedPrompt = ""
-	s.lastPromptHash = ""
+	s.promptKey = promptKey{}
 	// Update the readline prompt
 	s.rl.SetPrompt(s.getPrompt())
 	s.rl.Refresh()
@@ -728,15 +1024,39 @@ func (s *Session) forcePromptRefresh() {
 func (s *Session) initializePromptCache() {
 	// Generate initial prompt and cache it
 	s.cachedPrompt = s.getPrompt()
-	s.lastPromptHash = s.generatePromptHash()
 }
 
 // Command implementations
 func (s *Session) cmdHelp(args []string) error {
-	if len(args) == 0 {
+	format, err := parseFormatArg(args)
+	if err != nil {
+		return err
+	}
+
+	var topics []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--format" {
+			i++
+			continue
+		}
+		topics = append(topics, args[i])
+	}
+
+	if format == "json" {
+		if len(topics) == 0 {
+			return printJSON(s.tools)
+		}
+		tool := s.findTool(topics[0])
+		if tool == nil {
+			return rterrors.NotFoundf("tool '%s' not found", topics[0])
+		}
+		return printJSON(tool)
+	}
+
+	if len(topics) == 0 {
 		s.showToolsHelp()
 	} else {
-		s.showToolHelp(args[0])
+		s.showToolHelp(topics[0])
 	}
 	// Refresh prompt after help display
 	s.refreshPrompt()
@@ -756,6 +1076,7 @@ func (s *Session) cmdClear() error {
 }
 
 func (s *Session) cmdExit() error {
+	s.shutdown()
 	fmt.Println("Goodbye! Session history saved.")
 	os.Exit(0)
 	return nil
@@ -767,7 +1088,7 @@ func (s *Session) cmdCheck(args []string) error {
 
 	// Validate arguments
 	if err := s.validator.ValidateCommand("check", args, nil); err != nil {
-		return fmt.Errorf("check command validation failed: %w", err)
+		return rterrors.Validationf("check command validation failed: %w", err)
 	}
 
 	startTime := time.Now()
@@ -810,46 +1131,99 @@ func (s *Session) cmdCheck(args []string) error {
 }
 
 func (s *Session) cmdProfile(args []string) error {
-	fmt.Println("Generating host profile...")
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+	out := s.infoWriter()
+	fmt.Fprintln(out, "Generating host profile...")
 
 	// Validate arguments
 	if err := s.validator.ValidateCommand("profile", args, nil); err != nil {
-		return fmt.Errorf("profile command validation failed: %w", err)
+		return rterrors.Validationf("profile command validation failed: %w", err)
 	}
 
-	startTime := time.Now()
+	comparePath := ""
+	include := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--compare":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--compare requires a saved profile")
+			}
+			comparePath = unquote(args[i+1])
+			i++
+		case "--include":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--include requires a list of sections (%s)", strings.Join(collector.ProfileSections, ", "))
+			}
+			include = unquote(args[i+1])
+			i++
+		}
+	}
+	sections, err := collector.ParseProfileSections(include)
+	if err != nil {
+		return rterrors.Wrap(rterrors.Validation, err)
+	}
 
-	// Collect system information
-	profile := map[string]interface{}{
-		"timestamp":         time.Now().Format(time.RFC3339),
-		"hostname":          getHostname(),
-		"os":                runtime.GOOS,
-		"architecture":      runtime.GOARCH,
-		"go_version":        runtime.Version(),
-		"cpu_cores":         runtime.NumCPU(),
-		"working_dir":       getWorkingDir(),
-		"redtriage_version": version.GetShortVersion(),
-		"config_path":       "redtriage.yml",
-		"reports_dir":       s.config.ReportsDir,
+	// The earlier profile is read first: it may be the host-profile.json
+	// this run replaces
+	var prior map[string]interface{}
+	if comparePath != "" {
+		if prior, err = reporter.LoadProfile(comparePath); err != nil {
+			return rterrors.Wrap(rterrors.Validation, err)
+		}
 	}
 
-	// Convert to JSON
-	profileData, err := json.MarshalIndent(profile, "", "  ")
+	startTime := time.Now()
+
+	// Read the host in one read-only pass; Ctrl+C stops at the next section
+	ctx, done := s.commandContext()
+	hostProfile := collector.GatherHostProfile(ctx, sections)
+	done()
+	profile := hostProfile.Map()
+
+	// Save the JSON profile, which profile --compare reads back, and the
+	// rendered Markdown and HTML documents
+	profileData, err := json.MarshalIndent(hostProfile, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal profile: %w", err)
 	}
-
-	// Save to centralized reports
 	savedPath, err := s.reportsManager.SaveSystemReport(profileData, "host-profile.json")
 	if err != nil {
 		return fmt.Errorf("failed to save profile: %w", err)
 	}
+	s.noteToolOutput(savedPath, nil)
+	markdownPath, err := s.reportsManager.SaveSystemReport([]byte(reporter.HostProfileMarkdown(hostProfile)), "host-profile.md")
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	htmlPath, err := s.reportsManager.SaveSystemReport([]byte(reporter.HostProfileHTML(hostProfile)), "host-profile.html")
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
 
 	duration := time.Since(startTime)
-	fmt.Printf("✓ Host profile generated successfully in %v!\n", duration)
-	fmt.Printf("Profile saved to: %s\n", savedPath)
-	fmt.Printf("Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
+	if format == formatTable && comparePath == "" {
+		printHostProfileSummary(hostProfile)
+	}
+	for _, section := range collector.ProfileSections {
+		if message, ok := hostProfile.Errors[section]; ok {
+			fmt.Fprintf(out, "Warning: %s section not read: %s\n", section, message)
+		}
+	}
+	fmt.Fprintf(out, "✓ Host profile generated successfully in %v!\n", duration.Round(time.Millisecond))
+	fmt.Fprintf(out, "Profile saved to: %s\n", savedPath)
+	fmt.Fprintf(out, "Rendered profile: %s, %s\n", markdownPath, htmlPath)
+	fmt.Fprintf(out, "Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
 
+	if comparePath != "" {
+		return s.compareProfile(comparePath, prior, profile, format)
+	}
+	if format != formatTable {
+		return printStructured(format, profile)
+	}
 	return nil
 }
 
@@ -858,187 +1232,452 @@ func (s *Session) cmdCollect(args []string) error {
 
 	// Validate arguments
 	if err := s.validator.ValidateCommand("collect", args, nil); err != nil {
-		return fmt.Errorf("collect command validation failed: %w", err)
+		return rterrors.Validationf("collect command validation failed: %w", err)
+	}
+
+	// Parse arguments for collect command
+	var captureDuration time.Duration
+	var target collectionTarget
+	incidentID := ""
+	findFiles, stream := false, false
+	sweep := collector.SweepOptions{
+		MaxDepth:       collector.DefaultSweepMaxDepth,
+		MaxResults:     collector.DefaultSweepMaxResults,
+		MaxHashBytes:   s.config.GetMaxArtifactSize(),
+		FilesPerSecond: defaultSweepRate,
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--network-capture":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--network-capture requires a duration")
+			}
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil || d < time.Second {
+				return rterrors.Validationf("invalid network capture duration: %s", args[i+1])
+			}
+			captureDuration = d
+			i++ // Skip next argument
+		case "--find":
+			findFiles = true
+		case "--stream":
+			stream = true
+		case "--include-hidden":
+			sweep.IncludeHidden = true
+		case "--tag", "--incident":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			if args[i] == "--incident" {
+				incidentID = unquote(args[i+1])
+			} else if tags, err := addCollectionTags(target.tags, unquote(args[i+1])); err != nil {
+				return err
+			} else {
+				target.tags = tags
+			}
+			i++ // Skip next argument
+		case "--glob", "--paths", "--mtime-within", "--max-results", "--max-depth", "--find-rate":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			if err := parseSweepArg(&sweep, args[i], unquote(args[i+1])); err != nil {
+				return err
+			}
+			i++ // Skip next argument
+		case "--override-scope":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--override-scope requires a justification")
+			}
+			target.scopeOverride, i = quotedValue(args, i+1)
+			if strings.TrimSpace(target.scopeOverride) == "" {
+				return rterrors.Validationf("--override-scope requires a justification")
+			}
+		}
+	}
+
+	if findFiles {
+		return s.runFileSweep(sweep)
+	}
+
+	incident, err := s.collectionIncident(incidentID)
+	if err != nil {
+		return err
+	}
+	target.incident = incident
+	var scope *collector.CollectionScope
+	if incident != nil {
+		scope = incident.Scope
+	}
+	if target.scopeOverride != "" && scope == nil {
+		return rterrors.Validationf("--override-scope needs an incident with a collection scope; set one with 'incident scope set'")
 	}
 
 	startTime := time.Now()
 
 	// Create collection session
-	collectionID := fmt.Sprintf("RT-%s-%s", time.Now().Format("20060102-150405"), generateShortID())
+	collectionID := newID("RT", "20060102-150405")
 	fmt.Printf("Collection Session ID: %s\n", collectionID)
+	s.audit(audit.CollectionStarted, collectionID, args, nil)
+	if target.scopeOverride != "" {
+		s.audit(audit.ScopeOverridden, collectionID, map[string]interface{}{
+			"incident":      incident.ID,
+			"scope":         scope.String(),
+			"justification": target.scopeOverride,
+		}, nil)
+		footprint.Current().RecordOptIn("collection scope override", fmt.Sprintf("%s: %s", incident.ID, target.scopeOverride))
+	}
+
+	// Start the packet capture so it runs alongside the connection snapshot
+	var captureDone chan *collector.NetworkCapture
+	if captureDuration > 0 {
+		if ok, why := scope.Permits("network_capture", "network"); ok || target.scopeOverride != "" {
+			captureDone = s.startNetworkCapture(captureDuration)
+		} else {
+			fmt.Printf("⊘ Skipping network capture: %s\n", why)
+			captureDuration = 0
+		}
+	}
 
 	// Show incident context if available
-	if s.incidentContext != nil {
-		fmt.Printf("Incident Context: %s (%s)\n", s.incidentContext.ID, s.incidentContext.Title)
+	if incident != nil {
+		fmt.Printf("Incident Context: %s (%s)\n", incident.ID, incident.Title)
+		if incident != s.incidentContext {
+			fmt.Printf("Associated incident (not the active one, which stays unchanged)\n")
+		}
 		fmt.Printf("Memory Isolation: Active - All artifacts will be isolated to this incident\n")
 	}
+	if scope != nil {
+		fmt.Printf("Collection Scope: %s\n", scope)
+		if scope.Statement != "" {
+			fmt.Printf("Authorization: %s\n", scope.Statement)
+		}
+		if target.scopeOverride != "" {
+			fmt.Printf("Scope OVERRIDDEN: %s\n", target.scopeOverride)
+		}
+	}
+	if len(target.tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(target.tags, ", "))
+	}
 
 	fmt.Println()
 
-	// 1. System Health Information
-	fmt.Println("✓ Collecting system health information...")
-	systemHealth := collectSystemHealth()
-	time.Sleep(200 * time.Millisecond)
-
-	// 2. Network Information
-	fmt.Println("✓ Collecting network configuration and connections...")
-	networkInfo := collectNetworkInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 3. Process Information
-	fmt.Println("✓ Collecting running processes and services...")
-	processInfo := collectProcessInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 4. Service Information
-	fmt.Println("✓ Collecting system services and startup items...")
-	serviceInfo := collectServiceInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 5. Security Information
-	fmt.Println("✓ Collecting security and authentication data...")
-	securityInfo := collectSecurityInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 6. File System Information
-	fmt.Println("✓ Collecting file system and disk information...")
-	fileSystemInfo := collectFileSystemInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 7. Registry Information (Windows)
-	fmt.Println("✓ Collecting registry information...")
-	registryInfo := collectRegistryInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// 8. Event Log Information
-	fmt.Println("✓ Collecting system event logs...")
-	eventLogInfo := collectEventLogInfo()
-	time.Sleep(200 * time.Millisecond)
-
-	// Create comprehensive collection report
-	collection := map[string]interface{}{
-		"collection_id":     collectionID,
-		"timestamp":         time.Now().Format(time.RFC3339),
-		"platform":          runtime.GOOS,
-		"redtriage_version": version.GetShortVersion(),
-		"artifacts_collected": []string{
-			"system_health", "network", "processes", "services",
-			"security", "filesystem", "registry", "event_logs",
-		},
-		"status": "completed",
-		"artifacts": map[string]interface{}{
-			"system_health": systemHealth,
-			"network":       networkInfo,
-			"processes":     processInfo,
-			"services":      serviceInfo,
-			"security":      securityInfo,
-			"filesystem":    fileSystemInfo,
-			"registry":      registryInfo,
-			"event_logs":    eventLogInfo,
-		},
+	var (
+		collection map[string]interface{}
+		identity   collector.HostIdentity
+		savedPath  string
+	)
+	if stream {
+		collection, identity, savedPath, err = s.streamCollection(collectionID, target, captureDone, captureDuration)
+		s.audit(audit.CollectionFinished, collectionID, args, err)
+		if err != nil {
+			return err
+		}
+	} else {
+		collection, identity = s.gatherCollection(collectionID, target, captureDone, captureDuration)
 	}
 
 	// Add incident context if available
-	if s.incidentContext != nil {
-		collection["incident_context"] = map[string]interface{}{
-			"incident_id":    s.incidentContext.ID,
-			"incident_title": s.incidentContext.Title,
-			"severity":       s.incidentContext.Severity,
-			"analyst":        s.incidentContext.Analyst,
-		}
-
+	if incident != nil {
 		// Store artifacts in incident context
-		s.incidentContext.Artifacts[collectionID] = collection
+		incident.SetArtifact(collectionID, collection)
 
 		// Add timeline event
-		s.addTimelineEvent("artifact_collection", "Comprehensive artifact collection completed", map[string]interface{}{
+		event := map[string]interface{}{
 			"collection_id": collectionID,
 			"artifacts":     len(collection["artifacts_collected"].([]string)),
 			"duration":      time.Since(startTime).String(),
-		})
+		}
+		if len(target.tags) > 0 {
+			event["tags"] = target.tags
+		}
+		s.addIncidentTimelineEvent(incident, "artifact_collection", "Comprehensive artifact collection completed", event)
 
 		// Save updated incident context
-		if err := s.saveIncidentContext(s.incidentContext); err != nil {
+		if err := s.saveIncidentContext(incident); err != nil {
 			fmt.Printf("Warning: Failed to save incident context: %v\n", err)
 		}
 	}
 
-	// Convert to JSON
-	collectionData, err := json.MarshalIndent(collection, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal collection report: %w", err)
-	}
+	if !stream {
+		// Convert to JSON
+		collectionData, err := json.MarshalIndent(collection, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal collection report: %w", err)
+		}
 
-	// Save to centralized reports
-	savedPath, err := s.reportsManager.SaveCollectionReport(collectionData, fmt.Sprintf("collection-%s.json", collectionID))
-	if err != nil {
-		return fmt.Errorf("failed to save collection report: %w", err)
+		// Save to centralized reports
+		savedPath, err = s.reportsManager.SaveCollectionReport(collectionData, collectionReportName(collectionID))
+		s.audit(audit.CollectionFinished, collectionID, args, err)
+		if err != nil {
+			return fmt.Errorf("failed to save collection report: %w", err)
+		}
 	}
 
+	s.recordCollection(collectionID, startTime)
+	artifacts, _ := collection["artifacts_collected"].([]string)
+	s.noteToolOutput(savedPath, map[string]int{"artifacts": len(artifacts)})
+
 	duration := time.Since(startTime)
 	fmt.Printf("✓ Artifact collection completed successfully in %v!\n", duration)
 	fmt.Printf("Collection saved to: %s\n", savedPath)
+	fmt.Printf("Host: %s (fingerprint %s)\n", identity.Hostname, identity.ShortFingerprint())
 	fmt.Printf("Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
 
-	if s.incidentContext != nil {
-		fmt.Printf("✓ Artifacts integrated with incident context: %s\n", s.incidentContext.ID)
+	if incident != nil {
+		fmt.Printf("✓ Artifacts integrated with incident context: %s\n", incident.ID)
 	}
 
 	return nil
 }
 
+// startNetworkCapture runs a packet capture into the collection directory in
+// the background using the capture settings from the plugin configuration
+func (s *Session) startNetworkCapture(duration time.Duration) chan *collector.NetworkCapture {
+	done := make(chan *collector.NetworkCapture, 1)
+
+	outputDir, err := s.reportsManager.GetCategoryDirectory("collection")
+	if err != nil {
+		fmt.Printf("Warning: Network capture disabled: %v\n", err)
+		done <- nil
+		return done
+	}
+
+	opts := collector.CaptureOptions{
+		Duration:    duration,
+		Tool:        s.config.Plugins.CaptureTool,
+		TcpdumpPath: s.config.Plugins.TcpdumpPath,
+		DumpcapPath: s.config.Plugins.DumpcapPath,
+		Interface:   s.config.Plugins.CaptureInterface,
+		Filter:      s.config.Plugins.CaptureFilter,
+		OutputDir:   outputDir,
+	}
+
+	fmt.Printf("✓ Starting network capture for %s...\n", duration)
+	go func() {
+		capture, err := collector.CaptureNetwork(context.Background(), opts)
+		if err != nil {
+			capture = &collector.NetworkCapture{Duration: duration, Skipped: true, Reason: err.Error()}
+		}
+		if !capture.Skipped {
+			footprint.Current().RecordWrite(capture.Path, "network packet capture", false)
+		}
+		done <- capture
+	}()
+
+	return done
+}
+
+// defaultKeyFindingsTop is the number of rules the findings summary shows
+// unless --top is given
+const defaultKeyFindingsTop = 10
+
 func (s *Session) cmdFindings(args []string) error {
-	fmt.Println("Running Sigma rule-based detection analysis...")
+	esTarget, args, err := s.takeElasticsearchArgs(args)
+	if err != nil {
+		return err
+	}
+	notifyOn, args, err := s.takeNotifyArgs(args)
+	if err != nil {
+		return err
+	}
+	selection, selectionOrigin, args, err := s.takeRuleSelection(args)
+	if err != nil {
+		return err
+	}
+	explainID, args, err := takeExplainArg(args)
+	if err != nil {
+		return err
+	}
 
 	// Validate arguments
 	if err := s.validator.ValidateCommand("findings", args, nil); err != nil {
-		return fmt.Errorf("findings command validation failed: %w", err)
+		return rterrors.Validationf("findings command validation failed: %w", err)
+	}
+
+	if len(args) > 0 && args[0] == "triage" {
+		return s.triageFinding(args[1:])
+	}
+	if len(args) > 0 && args[0] == "baseline" {
+		return s.cmdFindingsBaseline(args[1:])
+	}
+	if len(args) > 0 && args[0] == "show" {
+		return s.showFinding(args[1:])
+	}
+	if explainID != "" {
+		// --rule-file lets a draft rule be explained before it is installed
+		candidates, _ := s.loadFindingsRules(containsField(args, "--no-cache"), false)
+		if selectionOrigin == selectionFromFlags && len(selection.RuleFiles) > 0 {
+			if candidates, err = selectRules(selection, candidates); err != nil {
+				return err
+			}
+		}
+		return s.explainRule(explainID, candidates, args)
+	}
+
+	fmt.Println("Running Sigma rule-based detection analysis...")
+
+	// Parse arguments for findings command
+	collectionID := ""
+	noCache := false
+	verbose := false
+	summaryOnly := false
+	baselineFile := ""
+	againstBaseline := false
+	top := defaultKeyFindingsTop
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--no-cache":
+			noCache = true
+		case "--verbose", "-v":
+			verbose = true
+		case "--summary-only":
+			summaryOnly = true
+		case "--top":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--top requires a number of rules")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return rterrors.Validationf("invalid --top value '%s': must be a positive number", args[i+1])
+			}
+			top = n
+			i++
+		case "--collection":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--collection requires a collection ID")
+			}
+			collectionID = args[i+1]
+			i++ // Skip next argument
+		case "--baseline":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--baseline requires a findings report")
+			}
+			baselineFile = args[i+1]
+			i++
+		case "--against-baseline":
+			againstBaseline = true
+		}
+	}
+
+	var baseline *reporter.Baseline
+	switch {
+	case baselineFile != "" && againstBaseline:
+		return rterrors.Validationf("--baseline and --against-baseline cannot be used together")
+	case baselineFile != "":
+		if _, err := os.Stat(baselineFile); err != nil {
+			return rterrors.NotFoundf("baseline findings report not found: %s", baselineFile)
+		}
+		if baseline, err = reporter.LoadBaseline(baselineFile); err != nil {
+			return rterrors.Validationf("invalid baseline: %v", err)
+		}
+	case againstBaseline:
+		accepted, scope, err := s.acceptedBaseline()
+		if err != nil {
+			return err
+		}
+		if accepted == nil {
+			return rterrors.NotFoundf("no findings baseline set for %s; set one with 'findings baseline set --run <findings-report>'", scope)
+		}
+		baseline = accepted.Baseline()
+		baselineFile = accepted.Source
 	}
 
 	startTime := time.Now()
 
 	// Show incident context if available
+	tuning := s.incidentTuning()
 	if s.incidentContext != nil {
 		fmt.Printf("Incident Context: %s (%s)\n", s.incidentContext.ID, s.incidentContext.Title)
 		fmt.Printf("Memory Isolation: Active - All findings will be isolated to this incident\n")
+		if tuning != nil {
+			fmt.Printf("Detection Tuning: %s\n", tuning)
+		}
 	}
 
 	// Load Sigma rules
 	fmt.Println("✓ Loading Sigma detection rules...")
-	rules := loadSigmaRules()
-	if len(rules) == 0 {
-		return fmt.Errorf("no Sigma rules found. Please ensure sigma-rules directory contains valid YAML files")
+	loadedRules, rulesDir := s.loadFindingsRules(noCache, verbose)
+	if len(loadedRules) == 0 && len(selection.RuleFiles) == 0 {
+		return rterrors.NotFoundf("no Sigma rules found. Please ensure %s contains valid YAML files", rulesDir)
+	}
+	if len(loadedRules) > 0 && loadedRules[0].Source == rules.SourceEmbedded {
+		fmt.Printf("  No rules in %s; using the %d embedded default rules\n", rulesDir, len(loadedRules))
 	}
+	rules, err := selectRules(selection, loadedRules)
+	if err != nil {
+		return err
+	}
+	if selectionOrigin == selectionFromIncident {
+		fmt.Printf("✓ Using the rule selection saved for incident %s: %s\n", s.incidentContext.ID, selection)
+	}
+	s.rememberRuleSelection(selection, selectionOrigin)
 
-	// Find latest collection artifacts
 	fmt.Println("✓ Locating collected artifacts...")
-	latestCollection := s.findLatestCollection()
-	if latestCollection == "" {
-		return fmt.Errorf("no collection artifacts found. Please run 'collect' command first")
+	collectionID, err = s.findingsCollection(collectionID)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Analyzing collection: %s\n", latestCollection)
+	fmt.Printf("Analyzing collection: %s\n", collectionID)
 
-	// Run analysis with each rule
-	var allFindings []map[string]interface{}
+	// Run analysis with each rule. Ctrl+C stops event log rules reading a
+	// large event records file.
+	ctx, cancel := s.commandContext()
+	defer cancel()
 
-	for _, rule := range rules {
-		fmt.Printf("✓ Analyzing with rule: %s\n", rule.Title)
-		findings := s.analyzeWithRule(rule, latestCollection)
-		allFindings = append(allFindings, findings...)
-		time.Sleep(100 * time.Millisecond)
+	allFindings, matchedRules, err := s.analyzeRules(ctx, rules, collectionID, s.config.GetDetectionWorkers(), func(rule SigmaRule, _ []map[string]interface{}) {
+		if !summaryOnly {
+			fmt.Printf("✓ Analyzed with rule: %s\n", output.SanitizeLine(rule.Title))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Findings suppressed by the incident's tuning or already in the
+	// baseline stay in the report, marked, but are left out of the key
+	// findings, the incident and the index
+	newFindings := allFindings
+	var tuningSummary reporter.TuningSummary
+	if tuning != nil {
+		newFindings, tuningSummary = tuning.Apply(newFindings)
+	}
+	var baselineSummary reporter.BaselineSummary
+	if baseline != nil {
+		newFindings, baselineSummary = baseline.Apply(newFindings)
 	}
 
 	// Generate findings report
+	keyFindings := reporter.GroupFindings(newFindings)
 	findingsReport := map[string]interface{}{
 		"timestamp":         time.Now().Format(time.RFC3339),
-		"collection_id":     latestCollection,
+		"collection_id":     collectionID,
+		"rules_loaded":      len(loadedRules),
 		"rules_analyzed":    len(rules),
+		"rules_matched":     matchedRules,
 		"total_findings":    len(allFindings),
 		"findings":          allFindings,
+		"key_findings":      keyFindings,
 		"analysis_duration": time.Since(startTime).String(),
 		"redtriage_version": version.GetShortVersion(),
 	}
+	if s.simulatedCollection != "" {
+		findingsReport["simulated"] = true
+	}
+	if baseline != nil {
+		findingsReport["baseline"] = baselineSummary
+	}
+	if tuning != nil {
+		findingsReport["tuning"] = map[string]interface{}{
+			"rules_dir":        tuning.RulesDir,
+			"suppressed_rules": tuning.SuppressedRules,
+			"allowlist":        tuning.Allowlist,
+			"suppressed":       tuningSummary,
+		}
+	}
+	if !selection.Empty() {
+		findingsReport["rule_selection"] = selection
+	}
 
 	// Add incident context if available
 	if s.incidentContext != nil {
@@ -1049,23 +1688,16 @@ func (s *Session) cmdFindings(args []string) error {
 			"analyst":        s.incidentContext.Analyst,
 		}
 
-		// Store findings in incident context
-		s.incidentContext.Findings = append(s.incidentContext.Findings, Finding{
-			ID:          fmt.Sprintf("FND-%s-%s", time.Now().Format("150405"), generateShortID()),
-			Type:        "sigma_analysis",
-			Severity:    "medium", // Default severity
-			Description: fmt.Sprintf("Sigma rule analysis completed with %d findings", len(allFindings)),
-			Evidence:    findingsReport,
-			RuleID:      "multiple",
-			Timestamp:   time.Now(),
-			Status:      "active",
-		})
+		// Store each detection in the incident context so it can be triaged
+		records := sigmaFindingRecords(newFindings, collectionID)
+		s.incidentContext.AddFindings(records...)
 
 		// Add timeline event
 		s.addTimelineEvent("findings_analysis", "Sigma rule analysis completed", map[string]interface{}{
-			"collection_id":  latestCollection,
+			"collection_id":  collectionID,
 			"rules_analyzed": len(rules),
 			"total_findings": len(allFindings),
+			"new_findings":   len(newFindings),
 			"duration":       time.Since(startTime).String(),
 		})
 
@@ -1081,32 +1713,54 @@ func (s *Session) cmdFindings(args []string) error {
 		return fmt.Errorf("failed to marshal findings report: %w", err)
 	}
 
-	savedPath, err := s.reportsManager.SaveTestReport(findingsData, fmt.Sprintf("findings-%s.json", latestCollection))
+	savedPath, err := s.reportsManager.SaveTestReport(findingsData, fmt.Sprintf("findings-%s.json", collectionID))
 	if err != nil {
 		return fmt.Errorf("failed to save findings report: %w", err)
 	}
+	s.noteToolOutput(savedPath, map[string]int{"findings": len(allFindings), "new": len(newFindings), "rules_matched": matchedRules})
 
 	duration := time.Since(startTime)
 	fmt.Printf("\n✓ Detection analysis completed successfully in %v!\n", duration)
 	fmt.Printf("Total findings: %d\n", len(allFindings))
-	fmt.Printf("Findings report saved to: %s\n", savedPath)
-	fmt.Printf("Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
+	fmt.Printf("Rules: %d loaded, %d selected, %d matched\n", len(loadedRules), len(rules), matchedRules)
+	if tuning != nil {
+		fmt.Printf("Incident tuning: %d suppressed by rule, %d allowlisted\n", tuningSummary.RulesSuppressed, tuningSummary.Allowlisted)
+	}
+	if baseline != nil {
+		fmt.Printf("Baseline %s: %d new, %d suppressed (already in the baseline), %d resolved (no longer seen)\n",
+			baselineFile, baselineSummary.New, baselineSummary.Suppressed, baselineSummary.Resolved)
+	}
+	if !summaryOnly {
+		fmt.Printf("Findings report saved to: %s\n", savedPath)
+		fmt.Printf("Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
+	}
 
-	if s.incidentContext != nil {
+	if s.incidentContext != nil && !summaryOnly {
 		fmt.Printf("✓ Findings integrated with incident context: %s\n", s.incidentContext.ID)
 	}
 
-	if len(allFindings) > 0 {
-		fmt.Println("\nKey findings:")
-		for i, finding := range allFindings {
-			if i >= 5 { // Show only first 5 findings
-				fmt.Printf("... and %d more findings\n", len(allFindings)-5)
-				break
-			}
-			fmt.Printf("  - %s: %s (Level: %s)\n",
-				finding["rule_title"],
-				finding["description"],
-				finding["level"])
+	if esTarget != nil {
+		s.indexFindings(esTarget, newFindings, collectionID)
+	}
+	if notifyOn != "" {
+		s.notifyFindings(notifyOn, keyFindings, collectionID, savedPath)
+	}
+
+	if againstBaseline {
+		printBaselineSections(allFindings, keyFindings, baselineSummary, top)
+	} else if baseline != nil && len(newFindings) == 0 {
+		fmt.Println("\nNo new findings since the baseline")
+	}
+	if len(newFindings) > 0 {
+		if !againstBaseline {
+			fmt.Printf("\nKey findings (%d rules):\n", len(keyFindings))
+			fmt.Print(reporter.KeyFindingsText(keyFindings, top))
+		}
+		fmt.Printf("\nAll %d findings with their evidence: %s\n", len(allFindings), savedPath)
+		if s.incidentContext != nil {
+			fmt.Println("Review them with 'incident show --findings' and 'findings triage <id> --state <tp|fp|needs-review>'")
+		} else {
+			fmt.Println("Switch to an incident ('incident switch') before running findings to review and triage them")
 		}
 	}
 
@@ -1115,7 +1769,166 @@ func (s *Session) cmdFindings(args []string) error {
 
 func (s *Session) cmdRules(args []string) error {
 	fmt.Println("Managing detection rules...")
-	// TODO: Implement actual rules logic
+
+	if len(args) == 0 {
+		loaded := s.loadSigmaRules(false, false)
+		source := rules.SourceExternal
+		if len(loaded) > 0 {
+			source = loaded[0].Source
+		}
+		fmt.Printf("Sigma rules (%s): %d\n", rules.SourceLabel(source, sigmaRulesDir), len(loaded))
+		for _, rule := range loaded {
+			fmt.Printf("  - [%s] %s (Level: %s)\n", rule.Source, output.SanitizeLine(rule.Title), output.SanitizeLine(rule.Level))
+		}
+		if source == rules.SourceEmbedded {
+			fmt.Printf("Rules installed into %s replace the embedded defaults\n", sigmaRulesDir)
+		}
+		return nil
+	}
+
+	switch args[0] {
+	case "reload":
+		// Rule files may have been replaced wholesale, so parse everything again
+		return s.reloadRules()
+	case "update":
+		return s.updateRules(args[1:])
+	case "test":
+		return s.testRules()
+	case "install":
+		if len(args) < 2 {
+			return rterrors.Validationf("rules install requires a rule file or rule pack archive")
+		}
+		installed, err := s.installRules(args[1])
+		if err != nil {
+			return err
+		}
+		rules := s.loadSigmaRules(true, false)
+		fmt.Printf("✓ Installed %s (%d Sigma rules loaded)\n", strings.Join(installed, ", "), len(rules))
+	default:
+		return rterrors.Validationf("unknown rules subcommand: %s (expected reload, update, install or test)", args[0])
+	}
+	return nil
+}
+
+// reloadRules re-reads the rules directory, replacing the cached rules the
+// next findings run uses, and reports what changed since the last load
+func (s *Session) reloadRules() error {
+	if _, err := os.Stat(sigmaRulesDir); os.IsNotExist(err) {
+		fmt.Printf("✓ No %s directory; using the %d embedded default rules\n", sigmaRulesDir, len(rules.Embedded()))
+		return nil
+	}
+	rules, changes, err := s.ruleCache.Reload(sigmaRulesDir)
+	if err != nil {
+		return rterrors.NotFoundf("could not reload Sigma rules: %w", err)
+	}
+
+	fmt.Printf("✓ Reloaded %d Sigma rules from %s\n", len(rules), sigmaRulesDir)
+	if changes.Initial {
+		fmt.Println("  No rules were cached before, nothing to compare")
+	} else if len(changes.Added)+len(changes.Removed)+len(changes.Changed) == 0 {
+		fmt.Println("  No rules changed since the last load")
+	}
+	for _, rule := range changes.Added {
+		fmt.Printf("  + added:   %s\n", rule)
+	}
+	for _, rule := range changes.Removed {
+		fmt.Printf("  - removed: %s\n", rule)
+	}
+	for _, rule := range changes.Changed {
+		fmt.Printf("  ~ changed: %s\n", rule)
+	}
+	for _, parseErr := range changes.Errors {
+		fmt.Printf("Warning: %s\n", parseErr)
+	}
+	return nil
+}
+
+// installRules installs a Sigma rule file, or every rule file in a .zip or
+// .tar.gz rule pack, and returns the installed file names. A pack is unpacked
+// with the safe extractor and installed only if all of its rules parse.
+func (s *Session) installRules(path string) ([]string, error) {
+	if !archive.IsArchive(path) {
+		if err := s.installRule(path); err != nil {
+			return nil, err
+		}
+		return []string{filepath.Base(path)}, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "redtriage-rules-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	result, err := archive.Extract(path, tmp, archive.DefaultLimits)
+	if errors.Is(err, archive.ErrUnsafeEntry) {
+		return nil, rterrors.Validationf("refusing to install rule pack %s: %v", path, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract rule pack: %w", err)
+	}
+
+	// The rules directory is flat, so rule files in subdirectories of the
+	// pack are installed under their base name
+	var files []string
+	seen := make(map[string]string)
+	for _, rel := range result.Files {
+		if !rules.IsRuleFile(rel) {
+			continue
+		}
+		full := filepath.Join(tmp, filepath.FromSlash(rel))
+		if info, err := os.Lstat(full); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		name := filepath.Base(full)
+		if other, ok := seen[name]; ok {
+			return nil, rterrors.Validationf("rule pack contains %s twice (%s and %s)", name, other, rel)
+		}
+		seen[name] = rel
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rule file: %w", err)
+		}
+		if _, err := rules.Parse(data); err != nil {
+			return nil, rterrors.Validationf("invalid Sigma rule %s in rule pack: %v", rel, err)
+		}
+		files = append(files, full)
+	}
+	if len(files) == 0 {
+		return nil, rterrors.Validationf("rule pack contains no .yml or .yaml rule files: %s", path)
+	}
+
+	var installed []string
+	for _, file := range files {
+		if err := s.installRule(file); err != nil {
+			return installed, err
+		}
+		installed = append(installed, filepath.Base(file))
+	}
+	return installed, nil
+}
+
+// installRule copies a Sigma rule file into the rules directory after
+// checking that it parses
+func (s *Session) installRule(path string) error {
+	if !rules.IsRuleFile(path) {
+		return rterrors.Validationf("rule file must have a .yml or .yaml extension: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rule file: %w", err)
+	}
+	if _, err := rules.Parse(data); err != nil {
+		return rterrors.Validationf("invalid Sigma rule %s: %v", path, err)
+	}
+
+	if err := os.MkdirAll(sigmaRulesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(sigmaRulesDir, filepath.Base(path)), data, 0644); err != nil {
+		return fmt.Errorf("failed to install rule: %w", err)
+	}
 	return nil
 }
 
@@ -1137,15 +1950,130 @@ func (s *Session) cmdVerify(args []string) error {
 	return nil
 }
 
-func (s *Session) cmdRedact(args []string) error {
-	fmt.Println("Applying redaction rules...")
-	// TODO: Implement actual redaction logic
-	return nil
-}
-
 func (s *Session) cmdExport(args []string) error {
-	fmt.Println("Exporting artifacts...")
-	// TODO: Implement actual export logic
+	input := ""
+	format := "json"
+	artifacts := ""
+	splitBy := ""
+	outputDir := ""
+	fieldList := ""
+	collectionID := ""
+	name := ""
+	redactRecords := false
+	rulesPath := ""
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--redact":
+			redactRecords = true
+		case "--input", "--format", "--artifacts", "--split-by", "--output", "--fields", "--collection", "--name", "--rules":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			value := args[i+1]
+			switch args[i] {
+			case "--input":
+				input = value
+			case "--format":
+				format = value
+			case "--artifacts":
+				artifacts = value
+			case "--split-by":
+				splitBy = value
+			case "--output":
+				outputDir = value
+			case "--fields":
+				fieldList = value
+			case "--collection":
+				collectionID = value
+			case "--name":
+				name = unquote(value)
+			case "--rules":
+				rulesPath = value
+			}
+			i++
+		}
+	}
+
+	var ruleset *redact.Ruleset
+	if rulesPath != "" && !redactRecords {
+		return rterrors.Validationf("--rules requires --redact")
+	}
+	if redactRecords {
+		var err error
+		if ruleset, err = exportRuleset(rulesPath); err != nil {
+			return err
+		}
+	}
+
+	if artifacts != "findings" {
+		if splitBy != "" {
+			return rterrors.Validationf("--split-by is only supported with --artifacts findings")
+		}
+		return s.exportArtifacts(collectionID, artifacts, fieldList, format, outputDir, name, ruleset)
+	}
+	if fieldList != "" {
+		return rterrors.Validationf("--fields is not supported with --artifacts findings")
+	}
+
+	if splitBy != "" && splitBy != "severity" && splitBy != "category" {
+		return rterrors.Validationf("invalid --split-by value: %s (valid: severity, category)", splitBy)
+	}
+	if format != "json" && format != "csv" && format != reporter.CSVExcel && format != "md" {
+		return rterrors.Validationf("invalid format: %s (valid: json, csv, csv-excel, md)", format)
+	}
+
+	namer, err := s.exportNamer(name, "")
+	if err != nil {
+		return err
+	}
+	findings, source, err := s.loadExportFindings(input)
+	if err != nil {
+		return err
+	}
+	if ruleset != nil {
+		if findings, err = redactFindings(findings, ruleset); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Exporting %d findings from %s\n", len(findings), source)
+
+	if outputDir == "" {
+		outputDir = filepath.Join(s.reportsManager.GetReportsDirectory(), "exports", time.Now().Format("20060102-150405"))
+	}
+
+	exporter := reporter.NewEnhancedReporter()
+	exporter.SetNaming(nil, namer)
+	reports, err := exporter.ExportFindings(findings, splitBy, format, outputDir)
+	s.audit(audit.EvidenceExported, outputDir, args, err)
+	if err != nil {
+		return fmt.Errorf("failed to export findings: %w", err)
+	}
+
+	if len(reports) == 0 {
+		fmt.Println("No findings to export")
+		return nil
+	}
+
+	for _, report := range reports {
+		footprint.Current().RecordWrite(report.Path, "findings export", false)
+		fmt.Printf("✓ %s (%d bytes)\n", report.Path, report.Size)
+	}
+	fmt.Printf("Exported %d file(s) to %s\n", len(reports), outputDir)
+	if ruleset != nil {
+		if err := s.recordRedaction(source, format, outputDir, reports, ruleset); err != nil {
+			return err
+		}
+	}
+
+	s.addTimelineEvent("findings_exported", "Findings exported", map[string]interface{}{
+		"source":   source,
+		"split_by": splitBy,
+		"format":   format,
+		"files":    len(reports),
+		"redacted": ruleset != nil,
+	})
+
 	return nil
 }
 
@@ -1187,7 +2115,7 @@ func (s *Session) cmdHealth(args []string) error {
 		case "--timeout", "-t":
 			if i+1 < len(args) {
 				if t, err := fmt.Sscanf(args[i+1], "%d", &timeout); err != nil || t != 1 {
-					return fmt.Errorf("invalid timeout value: %s", args[i+1])
+					return rterrors.Validationf("invalid timeout value: %s", args[i+1])
 				}
 				i++ // Skip next argument
 			}
@@ -1196,22 +2124,55 @@ func (s *Session) cmdHealth(args []string) error {
 
 	// Validate arguments
 	if err := s.validator.ValidateCommand("health", args, nil); err != nil {
-		return fmt.Errorf("health command validation failed: %w", err)
+		return rterrors.Validationf("health command validation failed: %w", err)
 	}
 
 	startTime := time.Now()
 
 	// Run comprehensive health checks with proper execution timing
 	checks := []string{
-		"system-dependencies", "file-permissions", "go-environment",
-		"build-system", "artifact-collection", "detection-engine",
+		"system-dependencies", "file-permissions", "runtime-environment", "binary-integrity", "go-environment",
+		"build-system", "artifact-collection", "detection-engine", "validate-rules",
 		"packaging-system", "output-management", "centralized-reports",
 	}
 
+	var warnings, failures []string
 	for _, check := range checks {
 		fmt.Printf("✓ Checking %s...\n", check)
 		checkStart := time.Now()
 
+		if check == "validate-rules" {
+			ruleWarnings, err := validateRulesCheck()
+			if err != nil {
+				fmt.Printf("  ✗ %v\n", err)
+				failures = append(failures, err.Error())
+			}
+			for _, warning := range ruleWarnings {
+				fmt.Printf("  Warning: %s\n", warning)
+			}
+			warnings = append(warnings, ruleWarnings...)
+		}
+		if check == "binary-integrity" {
+			report := integrity.Check()
+			fmt.Printf("  SHA-256: %s (%s)\n", report.SHA256, report.Summary())
+			for _, warning := range report.Warnings() {
+				fmt.Printf("  Warning: %s\n", warning)
+			}
+			if report.Tampered() {
+				failures = append(failures, report.Summary())
+			} else {
+				warnings = append(warnings, report.Warnings()...)
+			}
+		}
+		if check == "runtime-environment" {
+			env := collector.DetectEnvironment()
+			fmt.Printf("  Environment: %s\n", env)
+			for _, warning := range env.Warnings() {
+				fmt.Printf("  Warning: %s\n", warning)
+			}
+			warnings = append(warnings, env.Warnings()...)
+		}
+
 		// Ensure minimum execution time to prevent instant completion
 		minExecutionTime := 100 * time.Millisecond
 		time.Sleep(minExecutionTime)
@@ -1222,26 +2183,41 @@ func (s *Session) cmdHealth(args []string) error {
 		}
 	}
 
+	status := "PASS"
+	if len(failures) > 0 {
+		status = "FAIL"
+	}
+
 	if verbose {
 		fmt.Println("\nDetailed Health Check Results:")
 		fmt.Println("===============================")
 		for _, check := range checks {
-			fmt.Printf("%s: PASS\n", strings.Title(strings.ReplaceAll(check, "-", " ")))
+			checkStatus := "PASS"
+			if check == "validate-rules" {
+				checkStatus = status
+			}
+			fmt.Printf("%s: %s\n", strings.Title(strings.ReplaceAll(check, "-", " ")), checkStatus)
 		}
 	}
 
 	duration := time.Since(startTime)
-	fmt.Printf("\n✓ All health checks completed successfully in %v!\n", duration)
+	if len(failures) > 0 {
+		fmt.Printf("\n✗ %d health check failed in %v\n", len(failures), duration)
+	} else {
+		fmt.Printf("\n✓ All health checks completed successfully in %v!\n", duration)
+	}
 
 	// Create health report
 	healthReport := map[string]interface{}{
 		"timestamp":         time.Now().Format(time.RFC3339),
 		"duration":          duration.String(),
 		"total_checks":      len(checks),
-		"passed_checks":     len(checks),
-		"failed_checks":     0,
-		"status":            "PASS",
+		"passed_checks":     len(checks) - len(failures),
+		"failed_checks":     len(failures),
+		"status":            status,
 		"checks":            checks,
+		"errors":            failures,
+		"warnings":          warnings,
 		"redtriage_version": version.GetShortVersion(),
 		"reports_directory": s.reportsManager.GetReportsDirectory(),
 	}
@@ -1266,6 +2242,7 @@ func (s *Session) cmdHealth(args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to save health report: %w", err)
 	}
+	s.noteToolOutput(savedPath, map[string]int{"checks_passed": len(checks) - len(failures), "checks_failed": len(failures)})
 
 	fmt.Printf("Health report saved to: %s\n", savedPath)
 	fmt.Printf("Reports directory: %s\n", s.reportsManager.GetReportsDirectory())
@@ -1311,25 +2288,60 @@ func (s *Session) cmdReports(args []string) error {
 	// Handle specific report commands
 	switch args[0] {
 	case "list":
-		if len(args) > 1 {
-			category := args[1]
-			files, err := s.reportsManager.ListReports(category)
+		format, rest, err := parseOutputFormat(args[1:])
+		if err != nil {
+			return err
+		}
+		s.useOutputFormat(format)
+		if len(rest) == 0 {
+			if format != formatTable {
+				return rterrors.Validationf("reports list requires a category (health, system, collection, tests, logs, metadata)")
+			}
+			fmt.Println("Usage: reports list <category> [--format table|json|yaml]")
+			fmt.Println("Categories: health, system, collection, tests, logs, metadata")
+			return nil
+		}
+		category := rest[0]
+		entries, err := s.reportEntries(category)
+		if err != nil {
+			return fmt.Errorf("failed to list %s reports: %w", category, err)
+		}
+		if format != formatTable {
+			return printStructured(format, entries)
+		}
+		fmt.Printf("%s Reports (%d files):\n", strings.Title(category), len(entries))
+		for _, entry := range entries {
+			fmt.Printf("  - %s\n", entry.File)
+		}
+	case "open":
+		return s.cmdReportsOpen(args[1:])
+	case "search":
+		if len(args) < 2 {
+			fmt.Println("Usage: reports search <term> [category]")
+			fmt.Println("Categories: health, system, collection, tests, logs, metadata")
+			return nil
+		}
+		categories := []string{"health", "system", "collection", "tests", "logs", "metadata"}
+		if len(args) > 2 {
+			categories = []string{args[2]}
+		}
+		count := 0
+		for _, category := range categories {
+			matches, err := s.reportsManager.SearchReports(category, args[1])
 			if err != nil {
-				return fmt.Errorf("failed to list %s reports: %w", category, err)
+				return fmt.Errorf("failed to search %s reports: %w", category, err)
 			}
-			fmt.Printf("%s Reports (%d files):\n", strings.Title(category), len(files))
-			for _, file := range files {
-				fmt.Printf("  - %s\n", file)
+			for _, match := range matches {
+				fmt.Printf("  %s/%s:%d: %s\n", match.Category, match.File, match.Line, truncateString(match.Context, 80))
 			}
-		} else {
-			fmt.Println("Usage: reports list <category>")
-			fmt.Println("Categories: health, system, collection, tests, logs, metadata")
+			count += len(matches)
 		}
+		fmt.Printf("%d matches for '%s'\n", count, args[1])
 	case "cleanup":
 		if len(args) > 1 {
 			duration, err := time.ParseDuration(args[1])
 			if err != nil {
-				return fmt.Errorf("invalid duration: %s (use format like '24h', '7d')", args[1])
+				return rterrors.Validationf("invalid duration: %s (use format like '24h', '7d')", args[1])
 			}
 			if err := s.reportsManager.CleanupOldReports(duration); err != nil {
 				return fmt.Errorf("failed to cleanup old reports: %w", err)
@@ -1340,13 +2352,53 @@ func (s *Session) cmdReports(args []string) error {
 			fmt.Println("Example: reports cleanup 7d (clean up reports older than 7 days)")
 		}
 	default:
-		fmt.Println("Usage: reports [list <category> | cleanup <duration>]")
+		fmt.Println("Usage: reports [list <category> [--format table|json|yaml] | open <category> <name> [--raw] [--browser] | search <term> [category] | cleanup <duration>]")
 		fmt.Println("Use 'reports' to see directory structure and recent reports")
 	}
 
 	return nil
 }
 
+// parseFormatArg extracts the --format value (text or json) from args
+func parseFormatArg(args []string) (string, error) {
+	format := "text"
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--format" {
+			if i+1 < len(args) {
+				format = args[i+1]
+				i++
+			} else {
+				return "", rterrors.Validationf("--format requires a value")
+			}
+		}
+	}
+	if format != "text" && format != "json" {
+		return "", rterrors.Validationf("invalid format: %s (valid: text, json)", format)
+	}
+	return format, nil
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return nil
+}
+
+// trimCommandExamples strips the binary name from CLI examples so they can
+// be typed directly at the session prompt
+func trimCommandExamples(examples []string) []string {
+	trimmed := make([]string, 0, len(examples))
+	for _, example := range examples {
+		trimmed = append(trimmed, strings.TrimPrefix(example, "RedTriage "))
+	}
+	return trimmed
+}
+
 func (s *Session) showToolHelp(toolName string) {
 	// Clear any existing output and reset formatting
 	fmt.Print("\033[2K") // Clear the current line
@@ -1400,7 +2452,15 @@ func (s *Session) showGeneralHelp() {
 }
 
 // Navigation command implementations
-func (s *Session) cmdTools() error {
+func (s *Session) cmdTools(args []string) error {
+	format, err := parseFormatArg(args)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		return printJSON(s.tools)
+	}
+
 	// Clear any existing output and reset formatting
 	fmt.Print("\033[2K") // Clear the current line
 	color.Unset()
@@ -1511,9 +2571,36 @@ func (s *Session) cmdSearch(args []string) error {
 	// Add a clear separator line
 	fmt.Println(strings.Repeat("─", 80))
 
-	if len(args) == 0 {
-		fmt.Println("Usage: search <term>")
+	scopes := []string{"tools", "memory", "findings", "artifacts"}
+	var terms []string
+
+	// Parse arguments
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--in":
+			if i+1 < len(args) {
+				requested := strings.Split(args[i+1], ",")
+				for _, scope := range requested {
+					if !isValidSearchScope(scope) {
+						return rterrors.Validationf("invalid search scope: %s (valid: tools, memory, findings, artifacts, all)", scope)
+					}
+				}
+				if args[i+1] != "all" {
+					scopes = requested
+				}
+				i++
+			} else {
+				return rterrors.Validationf("--in requires a scope")
+			}
+		default:
+			terms = append(terms, args[i])
+		}
+	}
+
+	if len(terms) == 0 {
+		fmt.Println("Usage: search <term> [--in tools|memory|findings|artifacts|all]")
 		fmt.Println("Example: search network")
+		fmt.Println("Example: search 192.168.1.100 --in findings,artifacts")
 		fmt.Println(strings.Repeat("─", 80))
 		fmt.Println()
 		// Refresh prompt after display
@@ -1521,9 +2608,51 @@ func (s *Session) cmdSearch(args []string) error {
 		return nil
 	}
 
-	searchTerm := strings.ToLower(strings.Join(args, " "))
-	fmt.Printf("Searching for tools matching: '%s'\n\n", searchTerm)
+	searchTerm := strings.ToLower(strings.Join(terms, " "))
+	fmt.Printf("Searching %s for: '%s'\n\n", strings.Join(scopes, ", "), searchTerm)
+
+	total := 0
+	for _, scope := range scopes {
+		switch scope {
+		case "tools":
+			total += s.searchTools(searchTerm)
+		case "memory":
+			total += s.searchMemory(searchTerm)
+		case "findings":
+			total += s.searchFindings(searchTerm)
+		case "artifacts":
+			total += s.searchArtifacts(searchTerm)
+		}
+	}
+
+	if total == 0 {
+		fmt.Printf("No matches found for '%s'\n", searchTerm)
+		fmt.Println("Try using a different search term or narrow the scope with --in.")
+	} else {
+		fmt.Printf("%d total matches\n", total)
+	}
+	fmt.Println()
 
+	// Add a clear separator line at the end
+	fmt.Println(strings.Repeat("─", 80))
+	fmt.Println()
+
+	// Refresh prompt after display
+	s.refreshPrompt()
+
+	return nil
+}
+
+func isValidSearchScope(scope string) bool {
+	switch scope {
+	case "tools", "memory", "findings", "artifacts", "all":
+		return true
+	}
+	return false
+}
+
+// searchTools matches the term against tool names, descriptions and categories
+func (s *Session) searchTools(searchTerm string) int {
 	var foundTools []Tool
 
 	// Search in tool names and descriptions
@@ -1536,45 +2665,138 @@ func (s *Session) cmdSearch(args []string) error {
 	}
 
 	if len(foundTools) == 0 {
-		fmt.Printf("No tools found matching '%s'\n", searchTerm)
-		fmt.Println("Try using a different search term or use 'tools' to see all available tools.")
-		fmt.Println(strings.Repeat("─", 80))
-		fmt.Println()
-		// Refresh prompt after display
-		s.refreshPrompt()
-		return nil
+		return 0
+	}
+
+	color.New(color.FgHiWhite, color.Bold).Printf("Tools (%d matches):\n", len(foundTools))
+	color.Unset()
+
+	// Sort search results for consistent display
+	sort.Slice(foundTools, func(i, j int) bool {
+		if foundTools[i].Category != foundTools[j].Category {
+			return foundTools[i].Category < foundTools[j].Category
+		}
+		return foundTools[i].Name < foundTools[j].Name
+	})
+
+	// Display search results
+	for _, tool := range foundTools {
+		color.New(color.FgCyan, color.Bold).Printf("  %s (%s):\n", tool.Name, tool.Category)
+		color.Unset()
+		fmt.Printf("    %s\n", tool.Description)
+		fmt.Printf("    Usage: %s\n", tool.Usage)
+	}
+	fmt.Printf("  Use 'help %s' for detailed information about any tool.\n\n", foundTools[0].Name)
+
+	return len(foundTools)
+}
+
+// searchMemory matches the term against keys and values in incident memory
+func (s *Session) searchMemory(searchTerm string) int {
+	if s.incidentContext == nil {
+		return 0
+	}
+
+	var keys []string
+	for key, value := range s.incidentContext.Memory {
+		if strings.Contains(strings.ToLower(key), searchTerm) ||
+			strings.Contains(strings.ToLower(fmt.Sprintf("%v", value)), searchTerm) {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return 0
+	}
+	sort.Strings(keys)
+
+	color.New(color.FgHiWhite, color.Bold).Printf("Memory (%d matches):\n", len(keys))
+	color.Unset()
+	for _, key := range keys {
+		fmt.Printf("  [%s] %s = %v\n", s.incidentContext.ID, key, s.incidentContext.Memory[key])
+	}
+	fmt.Println()
+
+	return len(keys)
+}
+
+// searchFindings matches the term against incident findings and saved findings reports
+func (s *Session) searchFindings(searchTerm string) int {
+	var lines []string
+
+	if s.incidentContext != nil {
+		for _, finding := range s.incidentContext.Findings {
+			findingData, err := json.Marshal(finding)
+			if err != nil {
+				continue
+			}
+			if strings.Contains(strings.ToLower(string(findingData)), searchTerm) {
+				lines = append(lines, fmt.Sprintf("[%s] %s (%s): %s",
+					s.incidentContext.ID, finding.ID, finding.Severity, truncateString(finding.Description, 60)))
+			}
+		}
+	}
+
+	matches, err := s.reportsManager.SearchReports("tests", searchTerm)
+	if err == nil {
+		for _, match := range matches {
+			if !strings.HasPrefix(match.File, "findings-") {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", match.File, match.Line, truncateString(match.Context, 60)))
+		}
 	}
 
-	fmt.Printf("Found %d matching tools:\n\n", len(foundTools))
+	return printSearchSection("Findings", lines)
+}
 
-	// Sort search results for consistent display
-	sort.Slice(foundTools, func(i, j int) bool {
-		if foundTools[i].Category != foundTools[j].Category {
-			return foundTools[i].Category < foundTools[j].Category
+// searchArtifacts matches the term against incident artifacts and saved collection reports
+func (s *Session) searchArtifacts(searchTerm string) int {
+	var lines []string
+
+	if s.incidentContext != nil {
+		var keys []string
+		for key := range s.incidentContext.Artifacts {
+			keys = append(keys, key)
 		}
-		return foundTools[i].Name < foundTools[j].Name
-	})
+		sort.Strings(keys)
 
-	// Display search results
-	for _, tool := range foundTools {
-		color.New(color.FgCyan, color.Bold).Printf("%s (%s):\n", tool.Name, tool.Category)
-		color.Unset()
-		fmt.Printf("  %s\n", tool.Description)
-		fmt.Printf("  Usage: %s\n", tool.Usage)
-		fmt.Println()
+		for _, key := range keys {
+			artifactData, err := json.Marshal(s.incidentContext.Artifacts[key])
+			if err != nil {
+				continue
+			}
+			if strings.Contains(strings.ToLower(string(artifactData)), searchTerm) ||
+				strings.Contains(strings.ToLower(key), searchTerm) {
+				lines = append(lines, fmt.Sprintf("[%s] artifact %s", s.incidentContext.ID, key))
+			}
+		}
 	}
 
-	fmt.Printf("Use 'help %s' for detailed information about any tool.\n", foundTools[0].Name)
-	fmt.Println()
+	matches, err := s.reportsManager.SearchReports("collection", searchTerm)
+	if err == nil {
+		for _, match := range matches {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", match.File, match.Line, truncateString(match.Context, 60)))
+		}
+	}
 
-	// Add a clear separator line at the end
-	fmt.Println(strings.Repeat("─", 80))
-	fmt.Println()
+	return printSearchSection("Artifacts", lines)
+}
 
-	// Refresh prompt after display
-	s.refreshPrompt()
+// printSearchSection prints a titled block of search matches and returns the count
+func printSearchSection(title string, lines []string) int {
+	if len(lines) == 0 {
+		return 0
+	}
 
-	return nil
+	color.New(color.FgHiWhite, color.Bold).Printf("%s (%d matches):\n", title, len(lines))
+	color.Unset()
+	for _, line := range lines {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println()
+
+	return len(lines)
 }
 
 func (s *Session) cmdUse(args []string) error {
@@ -1584,6 +2806,8 @@ func (s *Session) cmdUse(args []string) error {
 			fmt.Printf("Description: %s\n", s.currentTool.Description)
 			fmt.Printf("Usage: %s\n", s.currentTool.Usage)
 			fmt.Println()
+			s.printToolRunPanel(s.currentTool)
+			fmt.Println()
 			fmt.Println("To switch to a different tool, use: use <tool_name>")
 			fmt.Println("To clear current tool context, use: use --clear")
 		} else {
@@ -1622,6 +2846,8 @@ func (s *Session) cmdUse(args []string) error {
 	fmt.Printf("Description: %s\n", tool.Description)
 	fmt.Printf("Usage: %s\n", tool.Usage)
 	fmt.Println()
+	s.printToolRunPanel(tool)
+	fmt.Println()
 	fmt.Println("Your prompt now shows the current tool context.")
 	fmt.Println("Use 'use --clear' to return to main session.")
 
@@ -1645,17 +2871,6 @@ func getWorkingDir() string {
 	return "unknown"
 }
 
-// Helper functions for artifact collection
-func generateShortID() string {
-	// Generate a short 8-character ID
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, 8)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
-
 func saveArtifact(dir, filename string, data interface{}) {
 	artifactData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
@@ -1664,7 +2879,7 @@ func saveArtifact(dir, filename string, data interface{}) {
 	}
 
 	filepath := filepath.Join(dir, filename)
-	if err := os.WriteFile(filepath, artifactData, 0644); err != nil {
+	if err := output.WriteFileAtomic(filepath, artifactData, 0644); err != nil {
 		fmt.Printf("Warning: Failed to save %s: %v\n", filename, err)
 	}
 }
@@ -1682,9 +2897,7 @@ func collectSystemHealth() map[string]interface{} {
 		"cpu_cores":         runtime.NumCPU(),
 		"working_directory": wd,
 		"redtriage_version": version.GetShortVersion(),
-		"system_uptime":     getSystemUptime(),
-		"memory_info":       getMemoryInfo(),
-		"disk_usage":        getDiskUsage(),
+		"system_stats":      collector.GatherSystemStats(),
 		"environment_vars":  getEnvironmentVars(),
 	}
 }
@@ -1751,8 +2964,6 @@ func collectRegistryInfo() map[string]interface{} {
 
 	return map[string]interface{}{
 		"timestamp":     time.Now().Format(time.RFC3339),
-		"startup_keys":  getRegistryStartupKeys(),
-		"autorun_keys":  getRegistryAutorunKeys(),
 		"network_keys":  getRegistryNetworkKeys(),
 		"security_keys": getRegistrySecurityKeys(),
 		"software_keys": getRegistrySoftwareKeys(),
@@ -1770,31 +2981,6 @@ func collectEventLogInfo() map[string]interface{} {
 }
 
 // System information collection helpers
-func getSystemUptime() string {
-	// Simulate system uptime
-	return "24h 15m 32s"
-}
-
-func getMemoryInfo() map[string]interface{} {
-	return map[string]interface{}{
-		"total":     "16 GB",
-		"available": "8.5 GB",
-		"used":      "7.5 GB",
-		"free":      "8.5 GB",
-	}
-}
-
-func getDiskUsage() map[string]interface{} {
-	return map[string]interface{}{
-		"c_drive": map[string]interface{}{
-			"total":         "500 GB",
-			"used":          "350 GB",
-			"free":          "150 GB",
-			"usage_percent": 70,
-		},
-	}
-}
-
 func getEnvironmentVars() map[string]string {
 	env := make(map[string]string)
 	for _, e := range os.Environ() {
@@ -2239,26 +3425,6 @@ func getStartupFolders() []map[string]interface{} {
 }
 
 // Registry information collection helpers (Windows-specific)
-func getRegistryStartupKeys() []map[string]interface{} {
-	return []map[string]interface{}{
-		{
-			"key":        "HKCU\\Software\\Microsoft\\Windows\\CurrentVersion\\Run",
-			"value_name": "OneDrive",
-			"value_data": "C:\\Users\\wasif\\AppData\\Local\\Microsoft\\OneDrive\\OneDrive.exe",
-		},
-	}
-}
-
-func getRegistryAutorunKeys() []map[string]interface{} {
-	return []map[string]interface{}{
-		{
-			"key":        "HKLM\\SOFTWARE\\Microsoft\\Windows\\CurrentVersion\\Run",
-			"value_name": "Windows Defender",
-			"value_data": "C:\\Program Files\\Windows Defender\\MSASCui.exe",
-		},
-	}
-}
-
 func getRegistryNetworkKeys() []map[string]interface{} {
 	return []map[string]interface{}{
 		{
@@ -2442,9 +3608,11 @@ Available Categories:
 Navigation Commands:
   tools                    - Show all available tools
   categories               - Show tool categories
-  search <term>           - Search for tools by name or description
+  search <term>           - Search tools, findings, artifacts and memory
+  search <term> --in <s>  - Limit search to tools|memory|findings|artifacts
   use <tool>              - Switch to a specific tool context
   use --clear             - Clear current tool context
+  rerun                   - Repeat the current tool's last run (or press Enter)
   help <tool>             - Show detailed help for a specific tool
   banner                   - Display RedTriage banner
   clear                    - Clear screen and redraw banner
@@ -2455,14 +3623,21 @@ Memory Isolation Commands:
   incident create          - Create new incident context
   incident switch          - Switch to existing incident
   incident list            - List all incidents
-  incident show            - Show incident details
+  incident show            - Show incident details and clocks
+  incident show --findings - List findings (also --artifacts, --reports, --timeline)
+  incident contain         - Record a containment action
   incident close           - Close current incident
+  incident reopen          - Reopen a closed incident
+  incident history         - List the snapshots of an incident
+  incident diff            - Show what changed between two snapshots
   memory set               - Set memory key-value pair
   memory get               - Get memory value by key
   memory list              - List all memory keys
   memory clear             - Clear all memory
   memory export            - Export memory data
   context                  - Show current context status
+  timeline                 - Show the merged incident timeline (--since, --source, --type)
+  timeline export          - Export the incident timeline (l2tcsv or jsonl)
 
 Examples:
   help collect             - Show help for collection tool
@@ -2473,6 +3648,13 @@ Examples:
   memory set --key "suspicious_ips" --value "192.168.1.100"
   context --verbose        - Show detailed context information
 
+Common Flags:
+  -o, --output             - Output directory (a file where a command writes one)
+  -f, --format             - Output format
+  -v, --verbose            - Show more detail
+  -q, --quiet              - Print only warnings and errors
+  -t, --timeout            - Timeout
+
 Type 'help <tool>' for detailed information about a specific tool.`
 }
 
@@ -2495,6 +3677,7 @@ func (s *Session) showToolsHelp() {
 			color.New(color.FgCyan, color.Bold).Println(line)
 		} else if strings.Contains(line, "Available Categories:") ||
 			strings.Contains(line, "Navigation Commands:") ||
+			strings.Contains(line, "Common Flags:") ||
 			strings.Contains(line, "Examples:") {
 			color.New(color.FgCyan, color.Bold).Println(line)
 		} else if strings.Contains(line, ":") && !strings.Contains(line, "  ") {
@@ -2517,54 +3700,77 @@ func (s *Session) showToolsHelp() {
 	fmt.Println()
 }
 
-// Sigma rule analysis helpers
-type SigmaRule struct {
-	Title       string                 `yaml:"title"`
-	ID          string                 `yaml:"id"`
-	Description string                 `yaml:"description"`
-	Level       string                 `yaml:"level"`
-	Detection   map[string]interface{} `yaml:"detection"`
-	Tags        []string               `yaml:"tags"`
-}
+// sigmaRulesDir is the directory Sigma rules are loaded from
+const sigmaRulesDir = rules.DefaultDir
 
-func loadSigmaRules() []SigmaRule {
-	var rules []SigmaRule
+// Sigma rule analysis helpers
+type SigmaRule = rules.SigmaRule
 
-	// Look for Sigma rules in the sigma-rules directory
-	rulesDir := "sigma-rules"
-	files, err := os.ReadDir(rulesDir)
+// validateRulesCheck loads and validates the Sigma rules for the health
+// check. Rules that cannot be used are returned as warnings; the error is
+// set when no rule in the directory could be loaded.
+func validateRulesCheck() ([]string, error) {
+	validation, err := rules.Validate("")
 	if err != nil {
-		fmt.Printf("Warning: Could not read sigma-rules directory: %v\n", err)
-		return rules
+		return []string{fmt.Sprintf("Sigma rules not loaded: %v", err)}, nil
 	}
 
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".yml") || strings.HasSuffix(file.Name(), ".yaml") {
-			rulePath := filepath.Join(rulesDir, file.Name())
-			ruleData, err := os.ReadFile(rulePath)
-			if err != nil {
-				fmt.Printf("Warning: Could not read rule file %s: %v\n", file.Name(), err)
-				continue
-			}
+	fmt.Printf("  %d of %d Sigma rules loaded from %s\n", validation.Loaded, validation.Files, validation.Dir)
+	if validation.Failed() {
+		return validation.Failures, fmt.Errorf("none of the %d rules in %s could be loaded", validation.Files, validation.Dir)
+	}
+	return validation.Failures, nil
+}
 
-			var rule SigmaRule
-			if err := yaml.Unmarshal(ruleData, &rule); err != nil {
-				fmt.Printf("Warning: Could not parse rule file %s: %v\n", file.Name(), err)
-				continue
-			}
+// loadSigmaRules loads the Sigma rules through the rule cache, falling back
+// to the embedded defaults when the rules directory has none. noCache
+// discards the cache first so every file is parsed again.
+func (s *Session) loadSigmaRules(noCache, verbose bool) []SigmaRule {
+	return s.loadSigmaRulesFrom(sigmaRulesDir, noCache, verbose)
+}
+
+// loadSigmaRulesFrom loads the Sigma rules of dir as loadSigmaRules does
+func (s *Session) loadSigmaRulesFrom(dir string, noCache, verbose bool) []SigmaRule {
+	if noCache {
+		if err := s.ruleCache.Invalidate(dir); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 
-			rules = append(rules, rule)
+	loaded, warnings, stats, _, err := s.ruleCache.LoadWithDefaults(dir)
+	if err != nil {
+		fmt.Printf("Warning: Could not read rules directory %s: %v\n", dir, err)
+		return nil
+	}
+	for _, warning := range warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	s.loadFieldMap(dir, loaded)
+
+	if verbose {
+		cache := "cold"
+		if stats.FromDisk {
+			cache = "disk"
+		} else if stats.Hits > 0 {
+			cache = "session"
 		}
+		fmt.Printf("Rule cache: %d hits, %d misses, %d removed, %d unparseable (cache: %s)\n",
+			stats.Hits, stats.Misses, stats.Removed, stats.Failed, cache)
+		fmt.Printf("Rule parse time: %v, saved by cache: %v\n", stats.ParseTime, stats.Saved)
 	}
 
-	return rules
+	return loaded
 }
 
 func (s *Session) findLatestCollection() string {
-	// Look for the most recent collection in the collection reports directory
-	collectionDir := s.reportsManager.GetCollectionReportsDirectory()
-	files, err := os.ReadDir(collectionDir)
-	if err != nil {
+	// Look for the most recent collection in the collection reports
+	// directory and its spill directory
+	var files []os.DirEntry
+	for _, dir := range s.reportsManager.ReportDirectories(s.reportsManager.GetCollectionReportsDirectory()) {
+		entries, _ := os.ReadDir(dir)
+		files = append(files, entries...)
+	}
+	if len(files) == 0 {
 		return ""
 	}
 
@@ -2572,15 +3778,24 @@ func (s *Session) findLatestCollection() string {
 	var latestTime time.Time
 
 	for _, file := range files {
-		if file.IsDir() && strings.HasPrefix(file.Name(), "RT-") {
+		// Collections are stored as RT-* directories or collection-RT-*.json reports
+		name := file.Name()
+		if !file.IsDir() {
+			if !strings.HasPrefix(name, "collection-") || !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "collection-"), ".json")
+		}
+
+		if strings.HasPrefix(name, "RT-") {
 			// Extract timestamp from collection ID (RT-YYYYMMDD-HHMMSS-xxxxx)
-			parts := strings.Split(file.Name(), "-")
+			parts := strings.Split(name, "-")
 			if len(parts) >= 3 {
 				timestampStr := parts[1] + "-" + parts[2]
 				if t, err := time.Parse("20060102-150405", timestampStr); err == nil {
 					if t.After(latestTime) {
 						latestTime = t
-						latestCollection = file.Name()
+						latestCollection = name
 					}
 				}
 			}
@@ -2590,55 +3805,100 @@ func (s *Session) findLatestCollection() string {
 	return latestCollection
 }
 
-func (s *Session) analyzeWithRule(rule SigmaRule, collectionID string) []map[string]interface{} {
-	var findings []map[string]interface{}
+// findingsCollection returns the collection findings analyze: the requested
+// one, the simulated one or the latest
+func (s *Session) findingsCollection(collectionID string) (string, error) {
+	if collectionID == "" {
+		collectionID = s.simulatedCollection
+	}
+	if collectionID != "" {
+		if !s.collectionExists(collectionID) {
+			return "", rterrors.NotFoundf("collection not found: %s", collectionID)
+		}
+	} else {
+		collectionID = s.findLatestCollection()
+		if collectionID == "" {
+			return "", rterrors.NotFoundf("no collection artifacts found. Please run 'collect' command first")
+		}
+	}
 
-	// Load collection artifacts
-	artifactsDir := filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), collectionID)
+	if err := s.checkCollectionVersion(collectionID); err != nil {
+		return "", err
+	}
+	return collectionID, nil
+}
 
-	// Analyze based on rule type
+// How a Sigma rule is evaluated against a collection
+const (
+	engineSelection = "selection"
+	engineNetwork   = "network heuristic"
+	engineProcess   = "process heuristic"
+	engineGeneric   = "none"
+)
+
+// ruleEngine picks how a rule is evaluated: event log rules match their
+// selection against event records, rules for a mapped logsource against
+// the records it maps to, other rules fall back to heuristics chosen by
+// title
+func ruleEngine(rule SigmaRule, fieldMap *rules.FieldMap) string {
 	switch {
+	case isEventLogRule(rule):
+		return engineSelection
+	case fieldMap.Lookup(rule.LogSource) != nil:
+		return engineMapped
 	case strings.Contains(strings.ToLower(rule.Title), "network"):
-		findings = s.analyzeNetworkRule(rule, artifactsDir)
+		return engineNetwork
 	case strings.Contains(strings.ToLower(rule.Title), "process"):
-		findings = s.analyzeProcessRule(rule, artifactsDir)
+		return engineProcess
+	}
+	return engineGeneric
+}
+
+func (s *Session) analyzeWithRule(ctx context.Context, rule SigmaRule, collectionID string) []map[string]interface{} {
+	var findings []map[string]interface{}
+
+	// Analyze based on rule type
+	switch ruleEngine(rule, s.fieldMap) {
+	case engineSelection:
+		findings = s.analyzeEventLogRule(ctx, rule, collectionID)
+	case engineMapped:
+		findings = s.analyzeMappedRule(rule, collectionID)
+	case engineNetwork:
+		findings = s.analyzeNetworkRule(rule, collectionID)
+	case engineProcess:
+		findings = s.analyzeProcessRule(rule, collectionID)
 	default:
 		// Generic analysis
-		findings = s.analyzeGenericRule(rule, artifactsDir)
+		findings = s.analyzeGenericRule(rule, collectionID)
 	}
 
 	return findings
 }
 
-func (s *Session) analyzeNetworkRule(rule SigmaRule, artifactsDir string) []map[string]interface{} {
+func (s *Session) analyzeNetworkRule(rule SigmaRule, collectionID string) []map[string]interface{} {
 	var findings []map[string]interface{}
 
 	// Load network artifacts
-	networkFile := filepath.Join(artifactsDir, "network.json")
-	networkData, err := os.ReadFile(networkFile)
+	networkInfo, err := s.loadCollectionArtifact(collectionID, "network")
 	if err != nil {
 		return findings
 	}
 
-	var networkInfo map[string]interface{}
-	if err := json.Unmarshal(networkData, &networkInfo); err != nil {
-		return findings
-	}
-
 	// Analyze network connections
 	if connections, ok := networkInfo["connections"].([]interface{}); ok {
-		for _, conn := range connections {
+		for i, conn := range connections {
 			if connMap, ok := conn.(map[string]interface{}); ok {
 				// Check for suspicious patterns
 				if s.isSuspiciousNetworkConnection(connMap, rule) {
 					finding := map[string]interface{}{
-						"rule_title":  rule.Title,
-						"rule_id":     rule.ID,
-						"level":       rule.Level,
-						"description": "Suspicious network connection detected",
-						"evidence":    connMap,
-						"timestamp":   time.Now().Format(time.RFC3339),
-						"category":    "network",
+						"rule_title":   rule.Title,
+						"rule_id":      rule.ID,
+						"level":        rule.Level,
+						"description":  "Suspicious network connection detected",
+						"evidence":     reporter.EvidenceSnapshot(connMap, nil),
+						"evidence_ref": collector.NewRecordRef(collectionID, "network", "connections", i),
+						"timestamp":    time.Now().Format(time.RFC3339),
+						"category":     "network",
 					}
 					findings = append(findings, finding)
 				}
@@ -2649,35 +3909,30 @@ func (s *Session) analyzeNetworkRule(rule SigmaRule, artifactsDir string) []map[
 	return findings
 }
 
-func (s *Session) analyzeProcessRule(rule SigmaRule, artifactsDir string) []map[string]interface{} {
+func (s *Session) analyzeProcessRule(rule SigmaRule, collectionID string) []map[string]interface{} {
 	var findings []map[string]interface{}
 
 	// Load process artifacts
-	processFile := filepath.Join(artifactsDir, "processes.json")
-	processData, err := os.ReadFile(processFile)
+	processInfo, err := s.loadCollectionArtifact(collectionID, "processes")
 	if err != nil {
 		return findings
 	}
 
-	var processInfo map[string]interface{}
-	if err := json.Unmarshal(processData, &processInfo); err != nil {
-		return findings
-	}
-
 	// Analyze processes
 	if processes, ok := processInfo["processes"].([]interface{}); ok {
-		for _, proc := range processes {
+		for i, proc := range processes {
 			if procMap, ok := proc.(map[string]interface{}); ok {
 				// Check for suspicious patterns
 				if s.isSuspiciousProcess(procMap, rule) {
 					finding := map[string]interface{}{
-						"rule_title":  rule.Title,
-						"rule_id":     rule.ID,
-						"level":       rule.Level,
-						"description": "Suspicious process behavior detected",
-						"evidence":    procMap,
-						"timestamp":   time.Now().Format(time.RFC3339),
-						"category":    "process",
+						"rule_title":   rule.Title,
+						"rule_id":      rule.ID,
+						"level":        rule.Level,
+						"description":  "Suspicious process behavior detected",
+						"evidence":     reporter.EvidenceSnapshot(procMap, []string{"cpu_percent", "memory_mb"}),
+						"evidence_ref": collector.NewRecordRef(collectionID, "processes", "processes", i),
+						"timestamp":    time.Now().Format(time.RFC3339),
+						"category":     "process",
 					}
 					findings = append(findings, finding)
 				}
@@ -2688,7 +3943,7 @@ func (s *Session) analyzeProcessRule(rule SigmaRule, artifactsDir string) []map[
 	return findings
 }
 
-func (s *Session) analyzeGenericRule(rule SigmaRule, artifactsDir string) []map[string]interface{} {
+func (s *Session) analyzeGenericRule(rule SigmaRule, collectionID string) []map[string]interface{} {
 	// Generic analysis for other rule types
 	return []map[string]interface{}{}
 }
@@ -2756,7 +4011,7 @@ func (s *Session) isSuspiciousProcess(proc map[string]interface{}, rule SigmaRul
 // cmdIncident handles incident creation, switching, and management
 func (s *Session) cmdIncident(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("incident command requires subcommand: create, switch, list, show, or close")
+		return rterrors.Validationf("incident command requires subcommand: create, switch, list, show, contain, close, reopen, export, import, transcript, history, diff, scope, or tuning")
 	}
 
 	subcmd := args[0]
@@ -2771,15 +4026,33 @@ func (s *Session) cmdIncident(args []string) error {
 		return s.showIncident(args[1:])
 	case "close":
 		return s.closeIncident(args[1:])
+	case "reopen":
+		return s.reopenIncident(args[1:])
+	case "contain":
+		return s.containIncident(args[1:])
+	case "export":
+		return s.exportIncident(args[1:])
+	case "import":
+		return s.importIncident(args[1:])
+	case "transcript":
+		return s.cmdTranscript(args[1:])
+	case "history":
+		return s.incidentHistory(args[1:])
+	case "diff":
+		return s.incidentDiff(args[1:])
+	case "scope":
+		return s.cmdIncidentScope(args[1:])
+	case "tuning":
+		return s.cmdIncidentTuning(args[1:])
 	default:
-		return fmt.Errorf("unknown incident subcommand: %s", subcmd)
+		return rterrors.Validationf("unknown incident subcommand: %s", subcmd)
 	}
 }
 
 // cmdMemory handles memory context operations
 func (s *Session) cmdMemory(args []string) error {
 	if len(args) == 0 {
-		return fmt.Errorf("memory command requires subcommand: set, get, list, clear, or export")
+		return rterrors.Validationf("memory command requires subcommand: set, get, list, clear, or export")
 	}
 
 	subcmd := args[0]
@@ -2795,7 +4068,7 @@ func (s *Session) cmdMemory(args []string) error {
 	case "export":
 		return s.exportMemory(args[1:])
 	default:
-		return fmt.Errorf("unknown memory subcommand: %s", subcmd)
+		return rterrors.Validationf("unknown memory subcommand: %s", subcmd)
 	}
 }
 
@@ -2803,21 +4076,43 @@ func (s *Session) cmdMemory(args []string) error {
 func (s *Session) cmdContext(args []string) error {
 	verbose := false
 	exportFile := ""
+	decrypt := false
+
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
 
 	// Parse arguments
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--verbose":
 			verbose = true
-		case "--export":
+		case "--output":
 			if i+1 < len(args) {
 				exportFile = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--export requires a file path")
+				return rterrors.Validationf("--output requires a file path")
 			}
+		case "--decrypt":
+			decrypt = true
 		}
 	}
+	if decrypt && exportFile == "" {
+		return rterrors.Validationf("--decrypt requires --output")
+	}
+
+	if format != formatTable {
+		if err := printStructured(format, s.contextInfo(verbose)); err != nil {
+			return err
+		}
+		if exportFile != "" {
+			return s.exportIncidentContext(exportFile, decrypt)
+		}
+		return nil
+	}
 
 	// Display current context
 	if s.incidentContext == nil {
@@ -2839,15 +4134,16 @@ func (s *Session) cmdContext(args []string) error {
 	if verbose {
 		fmt.Printf("\nTags: %v\n", s.incidentContext.Tags)
 		fmt.Printf("Artifacts Count: %d\n", len(s.incidentContext.Artifacts))
-		fmt.Printf("Findings Count: %d\n", len(s.incidentContext.Findings))
+		fmt.Printf("Findings Count: %d (%d active)\n", len(s.incidentContext.Findings), activeFindingCount(s.incidentContext))
 		fmt.Printf("Notes Count: %d\n", len(s.incidentContext.Notes))
 		fmt.Printf("Timeline Events: %d\n", len(s.incidentContext.Timeline))
 		fmt.Printf("Memory Keys: %d\n", len(s.incidentContext.Memory))
+		printClocks(s.computeClocks(s.incidentContext))
 	}
 
 	// Export context if requested
 	if exportFile != "" {
-		return s.exportIncidentContext(exportFile)
+		return s.exportIncidentContext(exportFile, decrypt)
 	}
 
 	return nil
@@ -2859,6 +4155,7 @@ func (s *Session) createIncident(args []string) error {
 	title := ""
 	severity := "medium"
 	description := ""
+	encrypt := false
 
 	// Parse arguments
 	for i := 0; i < len(args); i++ {
@@ -2868,27 +4165,29 @@ func (s *Session) createIncident(args []string) error {
 				title = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--title requires a value")
+				return rterrors.Validationf("--title requires a value")
 			}
 		case "--severity":
 			if i+1 < len(args) {
 				severity = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--severity requires a value")
+				return rterrors.Validationf("--severity requires a value")
 			}
 		case "--description":
 			if i+1 < len(args) {
 				description = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--description requires a value")
+				return rterrors.Validationf("--description requires a value")
 			}
+		case "--encrypt":
+			encrypt = true
 		}
 	}
 
 	if title == "" {
-		return fmt.Errorf("incident title is required (use --title)")
+		return rterrors.Validationf("incident title is required (use --title)")
 	}
 
 	// Validate severity
@@ -2901,17 +4200,16 @@ func (s *Session) createIncident(args []string) error {
 		}
 	}
 	if !valid {
-		return fmt.Errorf("invalid severity level. Must be one of: %v", validSeverities)
+		return rterrors.Validationf("invalid severity level. Must be one of: %v", validSeverities)
 	}
 
 	// Create new incident
-	incidentID := fmt.Sprintf("INC-%s-%s", time.Now().Format("20060102"), generateShortID())
 	incident := &IncidentContext{
-		ID:             incidentID,
+		SchemaVersion:  schema.IncidentVersion,
 		Title:          title,
 		Description:    description,
 		Severity:       severity,
-		Status:         "open",
+		Status:         IncidentOpen,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 		Analyst:        s.getCurrentUser(),
@@ -2922,21 +4220,32 @@ func (s *Session) createIncident(args []string) error {
 		Timeline:       []TimelineEvent{},
 		Memory:         make(map[string]interface{}),
 		IsolationLevel: "strict",
+		Encryption:     s.newIncidentEncryption(encrypt),
+	}
+
+	// Ask for the passphrase before taking the lock so other writers are
+	// not held up
+	if incident.Encryption == vault.SourcePassphrase {
+		key, err := s.newIncidentKey(incident)
+		if err != nil {
+			return err
+		}
+		incident.key = key
+	}
+
+	// Save incident context under a unique ID
+	if err := s.createIncidentFile(incident); err != nil {
+		return fmt.Errorf("failed to save incident context: %w", err)
 	}
+	incidentID := incident.ID
 
 	// Set as current incident
-	s.incidentContext = incident
-	s.incidentID = incidentID
-	s.memoryIsolation = true
+	s.activateIncident(incident)
+	s.audit(audit.IncidentOpened, incidentID, map[string]string{"title": title, "severity": severity, "encryption": incident.Encryption}, nil)
 
 	// Force prompt refresh for new incident context
 	s.forcePromptRefresh()
 
-	// Save incident context
-	if err := s.saveIncidentContext(incident); err != nil {
-		return fmt.Errorf("failed to save incident context: %w", err)
-	}
-
 	// Add timeline event
 	s.addTimelineEvent("incident_created", "Incident created", map[string]interface{}{
 		"title":    title,
@@ -2946,6 +4255,9 @@ func (s *Session) createIncident(args []string) error {
 
 	fmt.Printf("✓ Created incident %s: %s (Severity: %s)\n", incidentID, title, severity)
 	fmt.Printf("Memory isolation enabled. All data will be isolated to this incident context.\n")
+	if incident.Encryption != "" {
+		fmt.Printf("Incident encrypted at rest with a %s key; only its ID, title and status are readable without it.\n", incident.Encryption)
+	}
 
 	return nil
 }
@@ -2961,13 +4273,13 @@ func (s *Session) switchIncident(args []string) error {
 				incidentID = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--id requires an incident ID")
+				return rterrors.Validationf("--id requires an incident ID")
 			}
 		}
 	}
 
 	if incidentID == "" {
-		return fmt.Errorf("incident ID is required (use --id)")
+		return rterrors.Validationf("incident ID is required (use --id)")
 	}
 
 	// Load incident context
@@ -2977,9 +4289,7 @@ func (s *Session) switchIncident(args []string) error {
 	}
 
 	// Switch to incident
-	s.incidentContext = incident
-	s.incidentID = incidentID
-	s.memoryIsolation = true
+	s.activateIncident(incident)
 
 	// Force prompt refresh for new incident context
 	s.forcePromptRefresh()
@@ -2997,36 +4307,47 @@ func (s *Session) switchIncident(args []string) error {
 }
 
 func (s *Session) listIncidents(args []string) error {
+	format, _, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
 	incidents, err := s.listAllIncidents()
 	if err != nil {
 		return fmt.Errorf("failed to list incidents: %w", err)
 	}
 
+	summaries := make([]IncidentSummary, 0, len(incidents))
+	for _, incident := range incidents {
+		summaries = append(summaries, incidentSummary(incident))
+	}
+	if format != formatTable {
+		return printStructured(format, summaries)
+	}
+
 	if len(incidents) == 0 {
 		fmt.Println("No incidents found")
 		return nil
 	}
 
 	fmt.Println("Available Incidents:")
-	fmt.Println(strings.Repeat("─", 80))
-	fmt.Printf("%-15s %-30s %-10s %-10s %-20s\n", "ID", "Title", "Severity", "Status", "Created")
-	fmt.Println(strings.Repeat("─", 80))
-
-	for _, incident := range incidents {
-		created := incident.CreatedAt.Format("2006-01-02 15:04")
-		fmt.Printf("%-15s %-30s %-10s %-10s %-20s\n",
-			incident.ID,
-			truncateString(incident.Title, 28),
-			incident.Severity,
-			incident.Status,
-			created)
-	}
-
-	return nil
+	return reporter.IncidentsTable(summaries).Render(os.Stdout)
 }
 
 func (s *Session) showIncident(args []string) error {
 	incidentID := ""
+	showFindings := false
+	showArtifacts := false
+	showReports := false
+	showTimeline := false
+	timelineLimit := defaultTimelineLimit
+
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
 
 	// Parse arguments
 	for i := 0; i < len(args); i++ {
@@ -3036,51 +4357,134 @@ func (s *Session) showIncident(args []string) error {
 				incidentID = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--id requires an incident ID")
+				return rterrors.Validationf("--id requires an incident ID")
+			}
+		case "--findings":
+			showFindings = true
+		case "--artifacts":
+			showArtifacts = true
+		case "--reports":
+			showReports = true
+		case "--timeline":
+			showTimeline = true
+		case "--last":
+			if i+1 < len(args) {
+				if _, err := fmt.Sscanf(args[i+1], "%d", &timelineLimit); err != nil || timelineLimit <= 0 {
+					return rterrors.Validationf("invalid --last value: %s", args[i+1])
+				}
+				i++
+			} else {
+				return rterrors.Validationf("--last requires a number of events")
 			}
 		}
 	}
 
+	// Default to the active incident
+	if incidentID == "" && s.incidentContext != nil {
+		incidentID = s.incidentContext.ID
+	}
 	if incidentID == "" {
-		return fmt.Errorf("incident ID is required (use --id)")
+		return rterrors.Validationf("incident ID is required (use --id)")
 	}
 
-	// Load incident context
-	incident, err := s.loadIncidentContext(incidentID)
-	if err != nil {
-		return fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+	// Prefer the in-memory context for the active incident so unsaved changes show
+	var incident *IncidentContext
+	if s.incidentContext != nil && s.incidentContext.ID == incidentID {
+		incident = s.incidentContext
+	} else {
+		incident, err = s.loadIncidentContext(incidentID)
+		if err != nil {
+			return fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+		}
+	}
+
+	clocks := s.computeClocks(incident)
+	listing := showFindings || showArtifacts || showReports || showTimeline
+
+	if format != formatTable {
+		detail := IncidentDetail{
+			ID:       incident.ID,
+			Title:    incident.Title,
+			Severity: incident.Severity,
+			Status:   incident.Status,
+		}
+		if !listing {
+			detail.IncidentOverview = &IncidentOverview{
+				Description: incident.Description,
+				Analyst:     incident.Analyst,
+				CreatedAt:   incident.CreatedAt,
+				UpdatedAt:   incident.UpdatedAt,
+				Tags:        incident.Tags,
+				Counts:      incidentCounts(incident),
+				Clocks:      clocks,
+			}
+		}
+		if showFindings {
+			detail.Findings = incidentFindings(incident)
+		}
+		if showArtifacts {
+			detail.Artifacts = incidentArtifacts(incident)
+		}
+		if showReports {
+			detail.Reports = s.incidentReports(incident.ID)
+		}
+		if showTimeline {
+			detail.Timeline = recentTimeline(incident, timelineLimit)
+		}
+		return printStructured(format, detail)
 	}
 
 	// Display incident details
 	fmt.Printf("Incident Details: %s\n", incident.ID)
 	fmt.Println(strings.Repeat("─", 80))
 	fmt.Printf("Title: %s\n", incident.Title)
-	fmt.Printf("Description: %s\n", incident.Description)
-	fmt.Printf("Severity: %s\n", incident.Severity)
-	fmt.Printf("Status: %s\n", incident.Status)
-	fmt.Printf("Analyst: %s\n", incident.Analyst)
-	fmt.Printf("Created: %s\n", incident.CreatedAt.Format(time.RFC3339))
-	fmt.Printf("Updated: %s\n", incident.UpdatedAt.Format(time.RFC3339))
-	fmt.Printf("Tags: %v\n", incident.Tags)
-	fmt.Printf("Artifacts: %d\n", len(incident.Artifacts))
-	fmt.Printf("Findings: %d\n", len(incident.Findings))
-	fmt.Printf("Notes: %d\n", len(incident.Notes))
-	fmt.Printf("Timeline Events: %d\n", len(incident.Timeline))
-	fmt.Printf("Memory Keys: %d\n", len(incident.Memory))
+	if listing {
+		fmt.Printf("Severity: %s  Status: %s\n", incident.Severity, incident.Status)
+	} else {
+		fmt.Printf("Description: %s\n", incident.Description)
+		fmt.Printf("Severity: %s\n", incident.Severity)
+		fmt.Printf("Status: %s\n", incident.Status)
+		fmt.Printf("Analyst: %s\n", incident.Analyst)
+		fmt.Printf("Created: %s\n", incident.CreatedAt.Format(time.RFC3339))
+		fmt.Printf("Updated: %s\n", incident.UpdatedAt.Format(time.RFC3339))
+		fmt.Printf("Tags: %v\n", incident.Tags)
+		fmt.Printf("Artifacts: %d\n", len(incident.Artifacts))
+		fmt.Printf("Findings: %d (%d active)\n", len(incident.Findings), activeFindingCount(incident))
+		fmt.Printf("Notes: %d\n", len(incident.Notes))
+		fmt.Printf("Timeline Events: %d\n", len(incident.Timeline))
+		fmt.Printf("Memory Keys: %d\n", len(incident.Memory))
+		printClocks(clocks)
+		fmt.Println("\nUse --findings, --artifacts, --reports or --timeline [--last N] for details.")
+		return nil
+	}
+
+	if showFindings {
+		printIncidentFindings(incidentFindings(incident))
+	}
+	if showArtifacts {
+		printIncidentArtifacts(incidentArtifacts(incident))
+	}
+	if showReports {
+		printIncidentReports(s.incidentReports(incident.ID))
+	}
+	if showTimeline {
+		printIncidentTimeline(recentTimeline(incident, timelineLimit), len(incident.Timeline))
+	}
 
 	return nil
 }
 
 func (s *Session) closeIncident(args []string) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident to close")
+		return rterrors.Validationf("no active incident to close")
 	}
 
 	incidentID := s.incidentContext.ID
 
 	// Update incident status
-	s.incidentContext.Status = "closed"
-	s.incidentContext.UpdatedAt = time.Now()
+	if err := setIncidentStatus(s.incidentContext, IncidentClosed); err != nil {
+		return err
+	}
 
 	// Add timeline event
 	s.addTimelineEvent("incident_closed", "Incident closed", map[string]interface{}{
@@ -3089,16 +4493,23 @@ func (s *Session) closeIncident(args []string) error {
 	})
 
 	// Save updated context
-	if err := s.saveIncidentContext(s.incidentContext); err != nil {
+	err := s.saveIncidentContext(s.incidentContext)
+	s.audit(audit.IncidentClosed, incidentID, args, err)
+	if err != nil {
 		return fmt.Errorf("failed to save incident context: %w", err)
 	}
 
 	fmt.Printf("✓ Closed incident %s: %s\n", incidentID, s.incidentContext.Title)
 
 	// Clear current context
+	if s.stopAutosave != nil {
+		s.stopAutosave()
+		s.stopAutosave = nil
+	}
 	s.incidentContext = nil
 	s.incidentID = ""
 	s.memoryIsolation = false
+	s.reportsManager.SetScope("")
 
 	// Force prompt refresh for cleared context
 	s.forcePromptRefresh()
@@ -3108,11 +4519,62 @@ func (s *Session) closeIncident(args []string) error {
 	return nil
 }
 
+func (s *Session) containIncident(args []string) error {
+	if s.incidentContext == nil {
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
+	}
+
+	action := ""
+	target := ""
+
+	// Parse arguments
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--action":
+			if i+1 < len(args) {
+				action = args[i+1]
+				i++
+			} else {
+				return rterrors.Validationf("--action requires a value")
+			}
+		case "--target":
+			if i+1 < len(args) {
+				target = args[i+1]
+				i++
+			} else {
+				return rterrors.Validationf("--target requires a value")
+			}
+		}
+	}
+
+	if action == "" {
+		return rterrors.Validationf("containment action is required (use --action)")
+	}
+
+	// Add timeline event
+	s.addTimelineEvent(containmentEventType, "Containment action recorded", map[string]interface{}{
+		"action":  action,
+		"target":  target,
+		"analyst": s.getCurrentUser(),
+	})
+
+	if err := s.saveIncidentContext(s.incidentContext); err != nil {
+		return fmt.Errorf("failed to save incident context: %w", err)
+	}
+
+	fmt.Printf("✓ Recorded containment action for %s: %s\n", s.incidentContext.ID, action)
+	if clocks := s.incidentContext.Clocks; clocks != nil && clocks.Containment != nil {
+		fmt.Printf("Time to containment: %s\n", clocks.Containment.Elapsed)
+	}
+
+	return nil
+}
+
 // Memory management helper functions
 
 func (s *Session) setMemory(args []string) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context. Use 'incident create' or 'incident switch' first")
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
 	}
 
 	key := ""
@@ -3126,29 +4588,28 @@ func (s *Session) setMemory(args []string) error {
 				key = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--key requires a value")
+				return rterrors.Validationf("--key requires a value")
 			}
 		case "--value":
 			if i+1 < len(args) {
 				value = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--value requires a value")
+				return rterrors.Validationf("--value requires a value")
 			}
 		}
 	}
 
 	if key == "" {
-		return fmt.Errorf("memory key is required (use --key)")
+		return rterrors.Validationf("memory key is required (use --key)")
 	}
 
 	if value == "" {
-		return fmt.Errorf("memory value is required (use --value)")
+		return rterrors.Validationf("memory value is required (use --value)")
 	}
 
 	// Set memory value
-	s.incidentContext.Memory[key] = value
-	s.incidentContext.UpdatedAt = time.Now()
+	s.incidentContext.SetMemory(key, value)
 
 	// Add timeline event
 	s.addTimelineEvent("memory_set", "Memory key set", map[string]interface{}{
@@ -3164,7 +4625,7 @@ func (s *Session) setMemory(args []string) error {
 
 func (s *Session) getMemory(args []string) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context. Use 'incident create' or 'incident switch' first")
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
 	}
 
 	key := ""
@@ -3177,19 +4638,19 @@ func (s *Session) getMemory(args []string) error {
 				key = args[i+1]
 				i++
 			} else {
-				return fmt.Errorf("--key requires a value")
+				return rterrors.Validationf("--key requires a value")
 			}
 		}
 	}
 
 	if key == "" {
-		return fmt.Errorf("memory key is required (use --key)")
+		return rterrors.Validationf("memory key is required (use --key)")
 	}
 
 	// Get memory value
 	value, exists := s.incidentContext.Memory[key]
 	if !exists {
-		return fmt.Errorf("memory key '%s' not found", key)
+		return rterrors.NotFoundf("memory key '%s' not found", key)
 	}
 
 	fmt.Printf("Memory key '%s' = '%v'\n", key, value)
@@ -3197,19 +4658,30 @@ func (s *Session) getMemory(args []string) error {
 }
 
 func (s *Session) listMemory(args []string) error {
+	format, _, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	s.useOutputFormat(format)
+
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context. Use 'incident create' or 'incident switch' first")
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
 	}
 
-	if len(s.incidentContext.Memory) == 0 {
+	entries := memoryEntries(s.incidentContext)
+	if format != formatTable {
+		return printStructured(format, entries)
+	}
+
+	if len(entries) == 0 {
 		fmt.Println("No memory keys set")
 		return nil
 	}
 
 	fmt.Println("Memory Keys:")
 	fmt.Println(strings.Repeat("─", 50))
-	for key, value := range s.incidentContext.Memory {
-		fmt.Printf("%-20s = %v\n", key, value)
+	for _, entry := range entries {
+		fmt.Printf("%-20s = %v\n", entry.Key, entry.Value)
 	}
 
 	return nil
@@ -3217,12 +4689,11 @@ func (s *Session) listMemory(args []string) error {
 
 func (s *Session) clearMemory(args []string) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context. Use 'incident create' or 'incident switch' first")
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
 	}
 
 	// Clear all memory
-	s.incidentContext.Memory = make(map[string]interface{})
-	s.incidentContext.UpdatedAt = time.Now()
+	s.incidentContext.ClearMemory()
 
 	// Add timeline event
 	s.addTimelineEvent("memory_cleared", "All memory keys cleared", map[string]interface{}{})
@@ -3235,7 +4706,7 @@ func (s *Session) clearMemory(args []string) error {
 
 func (s *Session) exportMemory(args []string) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context. Use 'incident create' or 'incident switch' first")
+		return rterrors.Validationf("no active incident context. Use 'incident create' or 'incident switch' first")
 	}
 
 	// Export memory to JSON
@@ -3264,13 +4735,27 @@ func (s *Session) getCurrentUser() string {
 	return "unknown"
 }
 
+// saveIncidentContext writes an incident file while holding the reports
+// directory lock, so concurrent sessions never interleave incident writes
 func (s *Session) saveIncidentContext(incident *IncidentContext) error {
+	return s.reportsManager.WithLock(func() error {
+		return s.writeIncidentContext(incident)
+	})
+}
+
+// writeIncidentContext writes an incident file; the caller holds the reports
+// directory lock
+func (s *Session) writeIncidentContext(incident *IncidentContext) error {
 	// Create incidents directory if it doesn't exist
-	incidentsDir := filepath.Join(s.reportsManager.GetReportsDirectory(), "incidents")
+	incidentsDir := s.reportsManager.GetIncidentsDirectory()
 	if err := os.MkdirAll(incidentsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create incidents directory: %w", err)
 	}
 
+	// Refresh the derived incident clocks
+	incident.Clocks = s.computeClocks(incident)
+	incident.SchemaVersion = schema.IncidentVersion
+
 	// Save incident context to file
 	filename := fmt.Sprintf("%s.json", incident.ID)
 	filepath := filepath.Join(incidentsDir, filename)
@@ -3280,35 +4765,53 @@ func (s *Session) saveIncidentContext(incident *IncidentContext) error {
 		return fmt.Errorf("failed to marshal incident data: %w", err)
 	}
 
-	if err := os.WriteFile(filepath, incidentData, 0644); err != nil {
+	stored := incidentData
+	if incident.Encryption != "" {
+		if stored, err = s.sealIncident(incident, incidentData); err != nil {
+			return err
+		}
+	}
+
+	if err := output.WriteFileAtomic(filepath, stored, 0644); err != nil {
 		return fmt.Errorf("failed to write incident file: %w", err)
 	}
+	// History snapshots are stored in the clear, so encrypted incidents
+	// keep none
+	if incident.Encryption == "" {
+		s.snapshotIncident(incident.ID, incidentData)
+	}
+
+	if incident == s.incidentContext {
+		s.dirty = false
+	}
 
 	return nil
 }
 
 func (s *Session) loadIncidentContext(incidentID string) (*IncidentContext, error) {
 	// Load incident context from file
-	incidentsDir := filepath.Join(s.reportsManager.GetReportsDirectory(), "incidents")
-	filename := fmt.Sprintf("%s.json", incidentID)
-	filepath := filepath.Join(incidentsDir, filename)
-
-	incidentData, err := os.ReadFile(filepath)
+	incidentData, err := os.ReadFile(s.incidentPath(incidentID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read incident file: %w", err)
 	}
 
-	var incident IncidentContext
-	if err := json.Unmarshal(incidentData, &incident); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal incident data: %w", err)
+	incidentData, key, err := s.openIncident(incidentData)
+	if err != nil {
+		return nil, err
 	}
-
-	return &incident, nil
+	incident, err := decodeIncident(incidentData)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		s.rememberIncidentKey(incident, key)
+	}
+	return incident, nil
 }
 
 func (s *Session) listAllIncidents() ([]*IncidentContext, error) {
 	// List all incident contexts
-	incidentsDir := filepath.Join(s.reportsManager.GetReportsDirectory(), "incidents")
+	incidentsDir := s.reportsManager.GetIncidentsDirectory()
 	files, err := os.ReadDir(incidentsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -3323,9 +4826,9 @@ func (s *Session) listAllIncidents() ([]*IncidentContext, error) {
 			continue
 		}
 
-		incident, err := s.loadIncidentContext(strings.TrimSuffix(file.Name(), ".json"))
+		incident, err := s.listedIncident(strings.TrimSuffix(file.Name(), ".json"))
 		if err != nil {
-			fmt.Printf("Warning: Failed to load incident %s: %v\n", file.Name(), err)
+			fmt.Fprintf(s.infoWriter(), "Warning: Failed to load incident %s: %v\n", file.Name(), err)
 			continue
 		}
 
@@ -3339,9 +4842,14 @@ func (s *Session) addTimelineEvent(eventType, description string, data map[strin
 	if s.incidentContext == nil {
 		return
 	}
+	s.addIncidentTimelineEvent(s.incidentContext, eventType, description, data)
+}
 
+// addIncidentTimelineEvent records an event on the timeline of an incident,
+// which need not be the active one; the caller saves an inactive incident
+func (s *Session) addIncidentTimelineEvent(incident *IncidentContext, eventType, description string, data map[string]interface{}) {
 	event := TimelineEvent{
-		ID:          fmt.Sprintf("EVT-%s-%s", time.Now().Format("150405"), generateShortID()),
+		ID:          newID("EVT", "150405"),
 		Timestamp:   time.Now(),
 		EventType:   eventType,
 		Description: description,
@@ -3349,26 +4857,49 @@ func (s *Session) addTimelineEvent(eventType, description string, data map[strin
 		Data:        data,
 	}
 
-	s.incidentContext.Timeline = append(s.incidentContext.Timeline, event)
-	s.incidentContext.UpdatedAt = time.Now()
+	incident.UpdatedAt = time.Now()
+	incident.AddTimelineEvent(event)
 }
 
-func (s *Session) exportIncidentContext(filename string) error {
+// exportIncidentContext writes the active incident to filename. An
+// encrypted incident stays encrypted unless decrypt is set; the choice is
+// recorded in the audit log.
+func (s *Session) exportIncidentContext(filename string, decrypt bool) error {
 	if s.incidentContext == nil {
-		return fmt.Errorf("no active incident context to export")
+		return rterrors.Validationf("no active incident context to export")
 	}
 
 	// Export incident context to file
+	s.incidentContext.Clocks = s.computeClocks(s.incidentContext)
+	s.incidentContext.SchemaVersion = schema.IncidentVersion
 	contextData, err := json.MarshalIndent(s.incidentContext, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal context data: %w", err)
 	}
 
-	if err := os.WriteFile(filename, contextData, 0644); err != nil {
+	encrypted := s.incidentContext.Encryption != "" && !decrypt
+	if encrypted {
+		if contextData, err = s.sealIncident(s.incidentContext, contextData); err != nil {
+			return err
+		}
+	}
+
+	err = output.WriteFileAtomic(filename, contextData, 0644)
+	s.audit(audit.IncidentExported, filename, map[string]interface{}{
+		"encryption": s.incidentContext.Encryption, "encrypted": encrypted, "decrypted": decrypt && s.incidentContext.Encryption != "",
+	}, err)
+	if err != nil {
 		return fmt.Errorf("failed to write context file: %w", err)
 	}
 
-	fmt.Printf("✓ Exported incident context to %s\n", filename)
+	switch {
+	case encrypted:
+		fmt.Fprintf(s.infoWriter(), "✓ Exported incident context to %s (encrypted; use --decrypt for a readable copy)\n", filename)
+	case decrypt && s.incidentContext.Encryption != "":
+		fmt.Fprintf(s.infoWriter(), "✓ Exported incident context to %s (decrypted)\n", filename)
+	default:
+		fmt.Fprintf(s.infoWriter(), "✓ Exported incident context to %s\n", filename)
+	}
 	return nil
 }
 
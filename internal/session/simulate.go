@@ -0,0 +1,115 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+
+	"github.com/redtriage/redtriage/internal/rterrors")
+
+// liveCollectionCommands gather data from the host and are blocked while a
+// stored collection is being simulated
+var liveCollectionCommands = map[string]bool{
+	"collect":    true,
+	"profile":    true,
+	"quarantine": true,
+}
+
+// cmdSimulate replays a stored collection as the current host. While active,
+// findings analyze the stored collection and live collectors are disabled.
+func (s *Session) cmdSimulate(args []string) error {
+	if len(args) == 0 {
+		if s.simulatedCollection == "" {
+			fmt.Println("Simulation mode: off")
+			fmt.Println("Usage: simulate <collection-id> | simulate off")
+			return nil
+		}
+		fmt.Printf("Simulation mode: on (serving collection %s)\n", s.simulatedCollection)
+		fmt.Println("Live collection is disabled. Run 'simulate off' to return to live collection.")
+		return nil
+	}
+
+	switch args[0] {
+	case "off", "stop":
+		if s.simulatedCollection == "" {
+			fmt.Println("Simulation mode is not active")
+			return nil
+		}
+		fmt.Printf("✓ Stopped simulating collection %s; live collection re-enabled\n", s.simulatedCollection)
+		s.simulatedCollection = ""
+		s.forcePromptRefresh()
+		return nil
+	}
+
+	collectionID := args[0]
+	if !s.collectionExists(collectionID) {
+		return rterrors.NotFoundf("collection not found: %s", collectionID)
+	}
+	if err := s.checkCollectionVersion(collectionID); err != nil {
+		return err
+	}
+
+	s.simulatedCollection = collectionID
+	s.forcePromptRefresh()
+
+	fmt.Printf("✓ Simulating collection %s as the live host\n", collectionID)
+	fmt.Println("  'findings' analyzes the stored collection; live collectors are disabled")
+	fmt.Println("  Run 'simulate off' to return to live collection")
+	return nil
+}
+
+// collectionExists reports whether a stored collection with the ID exists
+func (s *Session) collectionExists(collectionID string) bool {
+	if collectionID == "" || filepath.Base(collectionID) != collectionID {
+		return false
+	}
+
+	rm := s.reportsManager
+	collectionDir := rm.GetCollectionReportsDirectory()
+	if info, err := os.Stat(rm.Resolve(filepath.Join(collectionDir, collectionID))); err == nil && info.IsDir() {
+		return true
+	}
+	_, err := os.Stat(rm.Resolve(filepath.Join(collectionDir, collectionReportName(collectionID))))
+	return err == nil
+}
+
+// hasCollectionReport reports whether 'collect' saved a report for the
+// collection, as opposed to it being stored only as a directory
+func (s *Session) hasCollectionReport(collectionID string) bool {
+	_, err := os.Stat(s.reportsManager.Resolve(filepath.Join(s.reportsManager.GetCollectionReportsDirectory(), collectionReportName(collectionID))))
+	return err == nil
+}
+
+// loadCollectionArtifact reads one artifact of a stored collection, either
+// from its collection directory (<id>/<name>.json) or from the collection
+// report written by 'collect'
+func (s *Session) loadCollectionArtifact(collectionID, name string) (map[string]interface{}, error) {
+	collectionDir := s.reportsManager.GetCollectionReportsDirectory()
+
+	if data, err := os.ReadFile(s.reportsManager.Resolve(filepath.Join(collectionDir, collectionID, name+".json"))); err == nil {
+		var artifact map[string]interface{}
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return nil, fmt.Errorf("failed to parse %s artifact: %w", name, err)
+		}
+		return artifact, nil
+	}
+
+	collection, _, err := s.readCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, _ := collection["artifacts"].(map[string]interface{})
+	artifact, ok := artifacts[name].(map[string]interface{})
+	if !ok {
+		return nil, rterrors.NotFoundf("collection %s has no %s artifact", collectionID, name)
+	}
+	return artifact, nil
+}
+
+// collectionReportName is the file name 'collect' saves a collection under
+func collectionReportName(collectionID string) string {
+	return fmt.Sprintf("collection-%s.json", collectionID)
+}
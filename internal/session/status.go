@@ -0,0 +1,257 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// Status line modes (status_line in the configuration)
+const (
+	statusLineFull    = "full"
+	statusLineMinimal = "minimal"
+	statusLineOff     = "off"
+)
+
+// freeSpaceRefresh is how long the free space of the reports directory is
+// reused before it is measured again
+const freeSpaceRefresh = 30 * time.Second
+
+// severityOrder lists severities from most to least severe
+var severityOrder = []string{"critical", "high", "medium", "low"}
+
+// SessionStatus is the state shown by 'status' and the status line
+type SessionStatus struct {
+	Status           string         `json:"status"`
+	Uptime           string         `json:"uptime"`
+	Tool             string         `json:"tool,omitempty"`
+	Incident         string         `json:"incident,omitempty"`
+	IncidentSeverity string         `json:"incident_severity,omitempty"`
+	LastCollection   string         `json:"last_collection,omitempty"`
+	LastCollectionAt *time.Time     `json:"last_collection_at,omitempty"`
+	OpenFindings     map[string]int `json:"open_findings"`
+	ReportsDirectory string         `json:"reports_directory"`
+	ReportsReadOnly  bool           `json:"reports_read_only,omitempty"`
+	SpillDirectory   string         `json:"spill_directory,omitempty"`
+	ReportsFreeBytes *int64         `json:"reports_free_bytes,omitempty"`
+	Background       []string       `json:"background"`
+}
+
+// sessionStatus gathers the status from state the session already holds,
+// incident counters included. Only the free space of the reports directory
+// is measured, and at most once per freeSpaceRefresh.
+func (s *Session) sessionStatus() SessionStatus {
+	status := SessionStatus{
+		Status:           s.status,
+		Uptime:           time.Since(s.startTime).Round(time.Second).String(),
+		OpenFindings:     map[string]int{},
+		ReportsDirectory: s.reportsManager.GetReportsDirectory(),
+		ReportsReadOnly:  s.readOnly(),
+		SpillDirectory:   s.reportsManager.SpillDirectory(),
+		Background:       append([]string{}, s.background...),
+	}
+	if s.currentTool != nil {
+		status.Tool = s.currentTool.Name
+	}
+	if incident := s.incidentContext; incident != nil {
+		status.Incident = incident.ID
+		status.IncidentSeverity = incident.Severity
+		status.OpenFindings = incident.OpenFindings()
+	}
+	if s.lastCollectionID != "" {
+		at := s.lastCollectionAt
+		status.LastCollection = s.lastCollectionID
+		status.LastCollectionAt = &at
+	} else if s.incidentContext != nil {
+		// Before this session collects, the incident's own latest collection
+		if id, at := s.incidentContext.LastCollection(); id != "" {
+			status.LastCollection = id
+			status.LastCollectionAt = &at
+		}
+	}
+	if free, ok := s.reportsFreeSpace(); ok {
+		status.ReportsFreeBytes = &free
+	}
+	return status
+}
+
+// reportsFreeSpace returns the free space of the reports directory, measured
+// again once the cached value is older than freeSpaceRefresh
+func (s *Session) reportsFreeSpace() (int64, bool) {
+	if time.Since(s.freeSpaceAt) >= freeSpaceRefresh {
+		free, err := utils.GetFreeDiskSpace(s.reportsManager.GetReportsDirectory())
+		s.freeSpace, s.freeSpaceKnown = free, err == nil
+		s.freeSpaceAt = time.Now()
+	}
+	return s.freeSpace, s.freeSpaceKnown
+}
+
+// recordCollection remembers the latest collection for the status line
+func (s *Session) recordCollection(collectionID string, at time.Time) {
+	s.lastCollectionID = collectionID
+	s.lastCollectionAt = at
+}
+
+// statusLineMode returns the configured status line mode
+func (s *Session) statusLineMode() string {
+	if s.config == nil || s.config.StatusLine == "" {
+		return statusLineFull
+	}
+	return s.config.StatusLine
+}
+
+// showStatus prints the status line after a command. It is left out when
+// turned off and after JSON or YAML output, so piped documents stay clean.
+func (s *Session) showStatus() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mode := s.statusLineMode()
+	if mode == statusLineOff || s.machineOutput {
+		return
+	}
+
+	statusColor := color.FgGreen
+	if s.status == "ERROR" {
+		statusColor = color.FgRed
+	} else if s.status == "WARN" {
+		statusColor = color.FgYellow
+	}
+
+	out := s.infoWriter()
+	color.New(statusColor).Fprintf(out, "[%s] ", s.status)
+	fmt.Fprintln(out, strings.Join(s.statusFields(mode), " | "))
+}
+
+// statusFields renders the parts of the status line for mode
+func (s *Session) statusFields(mode string) []string {
+	elapsed := time.Since(s.startTime).Round(time.Second)
+	if mode == statusLineMinimal {
+		fields := []string{"Session: " + elapsed.String()}
+		if s.incidentContext != nil {
+			fields = append(fields, "Incident: "+s.incidentContext.ID)
+		}
+		return fields
+	}
+
+	status := s.sessionStatus()
+	fields := []string{"Session: " + status.Uptime}
+	if status.Incident != "" {
+		fields = append(fields, fmt.Sprintf("Incident: %s (%s)", status.Incident, status.IncidentSeverity))
+	}
+	if status.Tool != "" {
+		fields = append(fields, "Tool: "+status.Tool)
+	}
+	if status.LastCollection != "" {
+		fields = append(fields, fmt.Sprintf("Collection: %s (%s ago)", status.LastCollection, time.Since(*status.LastCollectionAt).Round(time.Second)))
+	}
+	if status.Incident != "" {
+		fields = append(fields, "Open: "+openFindingsText(status.OpenFindings))
+	}
+	if status.ReportsFreeBytes != nil {
+		fields = append(fields, "Free: "+formatFreeSpace(*status.ReportsFreeBytes))
+	}
+	if len(status.Background) > 0 {
+		fields = append(fields, "Running: "+strings.Join(status.Background, ", "))
+	}
+	if status.Tool == "" && status.Incident == "" {
+		fields = append(fields, "Ready")
+	}
+	return fields
+}
+
+// cmdStatus shows the session status in full
+func (s *Session) cmdStatus(args []string) error {
+	format, args, err := parseOutputFormat(args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return rterrors.Validationf("unexpected argument: %s", args[0])
+	}
+	s.useOutputFormat(format)
+
+	status := s.sessionStatus()
+	if format != formatTable {
+		return printStructured(format, status)
+	}
+
+	rows := [][]string{
+		{"Status", status.Status},
+		{"Session", status.Uptime},
+		{"Incident", valueOrNone(status.Incident)},
+	}
+	if status.Incident != "" {
+		rows = append(rows,
+			[]string{"Incident severity", status.IncidentSeverity},
+			[]string{"Open findings", openFindingsText(status.OpenFindings)},
+		)
+	}
+	rows = append(rows, []string{"Tool", valueOrNone(status.Tool)})
+	if status.LastCollection != "" {
+		rows = append(rows, []string{"Last collection", fmt.Sprintf("%s (%s ago)", status.LastCollection, time.Since(*status.LastCollectionAt).Round(time.Second))})
+	} else {
+		rows = append(rows, []string{"Last collection", "none this session"})
+	}
+	if status.ReportsReadOnly {
+		rows = append(rows, []string{"Reports directory", status.ReportsDirectory + " (read-only)"})
+	} else {
+		rows = append(rows, []string{"Reports directory", status.ReportsDirectory})
+	}
+	if status.SpillDirectory != "" {
+		rows = append(rows, []string{"Spill directory", status.SpillDirectory})
+	}
+	if status.ReportsFreeBytes != nil {
+		rows = append(rows, []string{"Reports free space", formatFreeSpace(*status.ReportsFreeBytes)})
+	} else {
+		rows = append(rows, []string{"Reports free space", "unknown"})
+	}
+	rows = append(rows, []string{"Background", valueOrNone(strings.Join(status.Background, ", "))})
+
+	printTable([]string{"Field", "Value"}, rows)
+	return nil
+}
+
+// openFindingsText summarizes open finding counts, most severe first
+func openFindingsText(counts map[string]int) string {
+	var parts []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	for severity, count := range counts {
+		if !isValidSeverity(severity) && count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, valueOrNone(severity)))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatFreeSpace renders a byte count with a binary unit
+func formatFreeSpace(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// maxTableColumnWidth caps column widths so long values don't wrap the table
+const maxTableColumnWidth = 48
+
+// printTable renders rows as an aligned table with a header and separator
+func printTable(headers []string, rows [][]string) {
+	// Cells hold collected data, so escape anything a terminal would act on
+	safe := make([][]string, len(rows))
+	for i, row := range rows {
+		safe[i] = make([]string, len(row))
+		for j, cell := range row {
+			safe[i][j] = output.SanitizeLine(cell)
+		}
+	}
+	rows = safe
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			if w := utf8.RuneCountInString(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	// The last column is never truncated so paths and descriptions stay intact
+	total := 0
+	for i := range widths {
+		if i < len(widths)-1 && widths[i] > maxTableColumnWidth {
+			widths[i] = maxTableColumnWidth
+		}
+		total += widths[i] + 2
+	}
+
+	printRow := func(cells []string) {
+		var line strings.Builder
+		for i, width := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+				if i < len(widths)-1 {
+					cell = truncateString(cell, width)
+				}
+			}
+			line.WriteString(cell)
+			if i < len(widths)-1 {
+				line.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		fmt.Println(line.String())
+	}
+
+	printRow(headers)
+	fmt.Println(strings.Repeat("─", total))
+	for _, row := range rows {
+		printRow(row)
+	}
+}
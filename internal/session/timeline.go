@@ -0,0 +1,580 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/audit"
+	"github.com/redtriage/redtriage/internal/footprint"
+	"github.com/redtriage/redtriage/internal/logging"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/reporter"
+)
+
+// Sources of the unified incident timeline
+const (
+	timelineSourceIncident   = "INCIDENT"
+	timelineSourceFinding    = "FINDING"
+	timelineSourceCollection = "COLLECTION"
+	timelineSourceLog        = "LOG"
+	timelineSourceExecution  = "EXECUTION"
+)
+
+// timelineSources are the values --source accepts
+var timelineSources = []string{timelineSourceIncident, timelineSourceFinding, timelineSourceCollection, timelineSourceLog, timelineSourceExecution}
+
+const timelineUsage = "usage: timeline [show] [--incident <id> | --collection <id>] [--since <time>] [--until <time>] [--source <list>] [--type <list>] [--format text|csv|json] [--output <file>]\n" +
+	"       timeline export [--incident <id>] [--format l2tcsv|jsonl] [--output <file>]"
+
+// cmdTimeline handles the timeline command: 'timeline export' writes a
+// forensic super-timeline, anything else shows the merged timeline
+func (s *Session) cmdTimeline(args []string) error {
+	if len(args) > 0 && args[0] == "export" {
+		return s.exportTimeline(args[1:])
+	}
+	if len(args) > 0 && args[0] == "show" {
+		args = args[1:]
+	}
+	return s.showTimeline(args)
+}
+
+// showTimeline prints one chronological view of an incident's timeline
+// events, findings and collections and the event log records of its
+// collections. With --collection only the events of that collection are
+// shown.
+func (s *Session) showTimeline(args []string) error {
+	var incidentID, collectionID, outputPath string
+	var filter reporter.TimelineFilter
+	format := formatTable
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--incident", "--collection", "--since", "--until", "--source", "--type", "--format", "--output":
+		default:
+			return rterrors.Validationf("unknown timeline option: %s\n%s", args[i], timelineUsage)
+		}
+		if i+1 >= len(args) {
+			return rterrors.Validationf("%s requires a value", args[i])
+		}
+		value := unquote(args[i+1])
+		switch args[i] {
+		case "--incident":
+			incidentID = value
+		case "--collection":
+			collectionID = value
+		case "--since", "--until":
+			t, err := audit.ParseSince(value)
+			if err != nil {
+				return rterrors.Validationf("invalid %s value %q: use a duration like 24h or 7d, or a date like 2006-01-02", args[i], value)
+			}
+			if args[i] == "--since" {
+				filter.Since = t
+			} else {
+				filter.Until = t
+			}
+		case "--source":
+			for _, source := range strings.Split(value, ",") {
+				name := strings.ToUpper(strings.TrimSpace(source))
+				if !containsField(timelineSources, name) {
+					return rterrors.Validationf("invalid timeline source '%s': must be one of %s", source, strings.ToLower(strings.Join(timelineSources, ", ")))
+				}
+				filter.Sources = append(filter.Sources, name)
+			}
+		case "--type":
+			for _, eventType := range strings.Split(value, ",") {
+				filter.Types = append(filter.Types, strings.TrimSpace(eventType))
+			}
+		case "--format":
+			format = strings.ToLower(value)
+		default:
+			outputPath = value
+		}
+		i++
+	}
+	switch format {
+	case "text", formatTable:
+		format = formatTable
+	case "csv", formatJSON:
+	default:
+		return rterrors.Validationf("invalid format: %s (valid: text, csv, json)", format)
+	}
+	if format == formatTable && outputPath != "" {
+		return rterrors.Validationf("--output requires --format csv or json")
+	}
+	if incidentID != "" && collectionID != "" {
+		return rterrors.Validationf("use either --incident or --collection, not both")
+	}
+	if collectionID != "" && !s.collectionExists(collectionID) {
+		return rterrors.NotFoundf("collection not found: %s", collectionID)
+	}
+	s.useOutputFormat(format)
+
+	incident := s.incidentContext
+	if incidentID != "" && (incident == nil || incident.ID != incidentID) {
+		if !s.incidentExists(incidentID) {
+			return rterrors.NotFoundf("incident not found: %s", incidentID)
+		}
+		loaded, err := s.loadIncidentContext(incidentID)
+		if err != nil {
+			return fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+		}
+		incident = loaded
+	}
+	if collectionID != "" && incident != nil {
+		if _, ok := incident.Artifacts[collectionID]; !ok {
+			incident = nil
+		}
+	}
+	if incident == nil && collectionID == "" {
+		return rterrors.Validationf("timeline requires an active incident, --incident <id> or --collection <id>")
+	}
+
+	entries, collections := s.timelineEntries(incident, collectionID)
+	if filter.Sources == nil || containsField(filter.Sources, timelineSourceLog) {
+		for _, collection := range collections {
+			logs, err := s.collectionLogTimeline(collection.entry, collection.incidentID, collection.host)
+			if err != nil {
+				if rterrors.CategoryOf(err) != rterrors.NotFound {
+					fmt.Fprintf(s.infoWriter(), "Warning: could not read the event logs of collection %s: %v\n", collection.entry.ID, err)
+				}
+				continue
+			}
+			entries = append(entries, logs...)
+		}
+	}
+	if filter.Sources == nil || containsField(filter.Sources, timelineSourceExecution) {
+		for _, collection := range collections {
+			execution, err := s.collectionExecutionTimeline(collection.entry, collection.incidentID, collection.host)
+			if err != nil {
+				if rterrors.CategoryOf(err) != rterrors.NotFound {
+					fmt.Fprintf(s.infoWriter(), "Warning: could not read the execution history of collection %s: %v\n", collection.entry.ID, err)
+				}
+				continue
+			}
+			entries = append(entries, execution...)
+		}
+	}
+	reporter.SortTimeline(entries)
+	entries = reporter.FilterTimeline(entries, filter)
+
+	subject := "collection " + collectionID
+	if collectionID == "" {
+		subject = "incident " + incident.ID
+	}
+	if format == formatTable {
+		printTimeline(subject, entries)
+		return nil
+	}
+
+	var data bytes.Buffer
+	if format == "csv" {
+		if err := reporter.WriteTimelineCSV(&data, entries); err != nil {
+			return err
+		}
+	} else {
+		if entries == nil {
+			entries = []reporter.TimelineEntry{}
+		}
+		encoded, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode timeline: %w", err)
+		}
+		data.Write(append(encoded, '\n'))
+	}
+	if outputPath == "" {
+		_, err := os.Stdout.Write(data.Bytes())
+		return err
+	}
+
+	if err := output.WriteFileAtomic(outputPath, data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write timeline: %w", err)
+	}
+	footprint.Current().RecordWrite(outputPath, "timeline", false)
+	fmt.Fprintf(s.infoWriter(), "✓ Wrote %d timeline events of %s as %s to %s\n", len(entries), subject, format, outputPath)
+	return nil
+}
+
+// timelineCollection is a collection whose event logs join the timeline
+type timelineCollection struct {
+	entry      IncidentArtifactEntry
+	incidentID string
+	host       string
+}
+
+// timelineEntries returns the incident's timeline and the collections whose
+// event logs belong on it. For a collection, only the incident events that
+// refer to it are kept; a collection outside any incident contributes just
+// its own collection event.
+func (s *Session) timelineEntries(incident *IncidentContext, collectionID string) ([]reporter.TimelineEntry, []timelineCollection) {
+	var entries []reporter.TimelineEntry
+	var collections []timelineCollection
+	if incident != nil {
+		for _, entry := range s.buildTimeline(incident) {
+			if collectionID == "" || entry.Reference == collectionID || entry.Attributes["collection_id"] == collectionID {
+				entries = append(entries, entry)
+			}
+		}
+		for _, collection := range incidentArtifacts(incident) {
+			if collectionID != "" && collection.ID != collectionID {
+				continue
+			}
+			host := collection.Host
+			for _, entry := range entries {
+				if entry.Reference == collection.ID && entry.Source == timelineSourceCollection {
+					host = entry.Host
+				}
+			}
+			collections = append(collections, timelineCollection{entry: collection, incidentID: incident.ID, host: host})
+		}
+		return entries, collections
+	}
+
+	collection := IncidentArtifactEntry{ID: collectionID, Host: "-"}
+	if report, _, err := s.readCollection(collectionID); err == nil {
+		collection = incidentArtifacts(&IncidentContext{Artifacts: map[string]interface{}{collectionID: report}})[0]
+	}
+	host := collection.Host
+	if host == "-" {
+		host, _ = os.Hostname()
+	}
+	if collection.CollectedAt != "" {
+		entries = append(entries, collectionTimelineEntry(collection, "", "", host))
+	}
+	return entries, []timelineCollection{{entry: collection, host: host}}
+}
+
+// collectionLogTimeline turns the event log records of a collection into
+// timeline entries. Each record goes through the Windows event log parser,
+// which gives it a category and severity, and then through the LogParser's
+// GenerateTimeline. Records listed in more than one log section appear once.
+func (s *Session) collectionLogTimeline(collection IncidentArtifactEntry, incidentID, host string) ([]reporter.TimelineEntry, error) {
+	artifact, err := s.loadCollectionArtifact(collection.ID, "event_logs")
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]string, 0, len(artifact))
+	for section := range artifact {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	parser := &logging.WindowsEventLogParser{}
+	var records []logging.LogEntry
+	var known []bool
+	seen := make(map[string]bool)
+	for _, section := range sections {
+		list, ok := artifact[section].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, raw := range list {
+			record, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			eventID := ""
+			if id, ok := record["event_id"]; ok && id != nil {
+				eventID = fmt.Sprint(id)
+			}
+			level, source := stringField(record, "level"), stringField(record, "source")
+			message, generated := stringField(record, "message"), stringField(record, "time_generated")
+			key := strings.Join([]string{eventID, generated, source, message}, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			// The parser splits on commas and keeps only the first part of
+			// the message, so the full message is put back afterwards
+			line := strings.Join([]string{eventID, level, source, generated, strings.ReplaceAll(message, ",", ";")}, ",")
+			entry, err := parser.ParseLine(line)
+			if err != nil {
+				continue
+			}
+			entry.Message = message
+			entry.Metadata["section"] = section
+			timestamp, err := time.Parse(time.RFC3339, generated)
+			entry.Timestamp = timestamp
+			records = append(records, *entry)
+			known = append(known, err == nil)
+		}
+	}
+
+	events, err := logging.NewLogParser().GenerateTimeline(context.Background(), records)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]reporter.TimelineEntry, 0, len(events))
+	for i, event := range events {
+		record := records[i]
+		entries = append(entries, reporter.TimelineEntry{
+			Timestamp:     event.Timestamp,
+			TimeKnown:     known[i],
+			TimestampDesc: "Event Logged",
+			Source:        timelineSourceLog,
+			SourceType:    "Event log",
+			Type:          event.Type,
+			User:          event.User,
+			Host:          host,
+			IncidentID:    incidentID,
+			Short:         fmt.Sprintf("[%s] %s %s", record.Level, record.EventID, event.Source),
+			Description:   event.Description,
+			Reference:     collection.ID,
+			Attributes: map[string]interface{}{
+				"collection_id": collection.ID,
+				"event_id":      record.EventID,
+				"level":         record.Level,
+				"log":           record.Metadata["section"],
+				"severity":      event.Severity,
+				"tags":          event.Tags,
+			},
+		})
+	}
+	return entries, nil
+}
+
+// printTimeline prints timeline entries as a table, oldest first
+func printTimeline(subject string, entries []reporter.TimelineEntry) {
+	fmt.Printf("Timeline of %s (%d events)\n\n", subject, len(entries))
+	if len(entries) == 0 {
+		fmt.Println("No events match.")
+		return
+	}
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		when := "-"
+		if entry.TimeKnown {
+			when = entry.Timestamp.Local().Format("2006-01-02 15:04:05")
+		}
+		event := entry.Description
+		if entry.Source == timelineSourceFinding || entry.Source == timelineSourceLog {
+			event = entry.Short + " " + entry.Description
+		}
+		rows = append(rows, []string{when, entry.Source, valueOrDash(entry.Type), valueOrDash(entry.Host), event})
+	}
+	printTable([]string{"Time", "Source", "Type", "Host", "Event"}, rows)
+}
+
+// exportTimeline writes the unified timeline of the active or a given
+// incident in a forensic timeline format. Without --output the file goes to
+// the exports directory of the reports directory.
+func (s *Session) exportTimeline(args []string) error {
+	incidentID, format, outputPath := "", reporter.TimelineL2TCSV, ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--incident", "--format", "--output":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("%s requires a value", args[i])
+			}
+			value := unquote(args[i+1])
+			switch args[i] {
+			case "--incident":
+				incidentID = value
+			case "--format":
+				format = strings.ToLower(value)
+			default:
+				outputPath = value
+			}
+			i++
+		default:
+			return rterrors.Validationf("unknown timeline export option: %s", args[i])
+		}
+	}
+	if !reporter.ValidTimelineFormat(format) {
+		return rterrors.Validationf("invalid timeline format '%s': must be %s or %s", format, reporter.TimelineL2TCSV, reporter.TimelineJSONL)
+	}
+
+	incident := s.incidentContext
+	if incidentID != "" && (incident == nil || incident.ID != incidentID) {
+		if !s.incidentExists(incidentID) {
+			return rterrors.NotFoundf("incident not found: %s", incidentID)
+		}
+		loaded, err := s.loadIncidentContext(incidentID)
+		if err != nil {
+			return fmt.Errorf("failed to load incident %s: %w", incidentID, err)
+		}
+		incident = loaded
+	}
+	if incident == nil {
+		return rterrors.Validationf("timeline export requires an active incident or --incident <id>")
+	}
+
+	if outputPath == "" {
+		name := fmt.Sprintf("timeline-%s-%s%s", incident.ID, time.Now().Format("20060102-150405"), reporter.TimelineExtension(format))
+		outputPath = filepath.Join(s.reportsManager.GetReportsDirectory(), "exports", name)
+	}
+
+	entries := s.buildTimeline(incident)
+	var data bytes.Buffer
+	if err := reporter.WriteTimeline(&data, format, entries); err != nil {
+		return err
+	}
+
+	err := os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err == nil {
+		err = output.WriteFileAtomic(outputPath, data.Bytes(), 0644)
+	}
+	s.audit(audit.EvidenceExported, incident.ID, map[string]interface{}{
+		"timeline": format, "events": len(entries), "output": outputPath,
+	}, err)
+	if err != nil {
+		return fmt.Errorf("failed to write timeline export: %w", err)
+	}
+	footprint.Current().RecordWrite(outputPath, "timeline export", false)
+
+	unknown := 0
+	for _, entry := range entries {
+		if !entry.TimeKnown {
+			unknown++
+		}
+	}
+	fmt.Printf("✓ Exported %d timeline events of incident %s as %s to %s\n", len(entries), incident.ID, format, outputPath)
+	if unknown > 0 {
+		fmt.Printf("  %d events have no precise time and are marked %q\n", unknown, reporter.NotATime)
+	}
+
+	if incident == s.incidentContext {
+		s.addTimelineEvent("timeline_exported", "Timeline exported", map[string]interface{}{
+			"format": format,
+			"events": len(entries),
+			"output": outputPath,
+		})
+	}
+	return nil
+}
+
+// collectionTimelineEntry is the timeline entry recording when a collection
+// was taken
+func collectionTimelineEntry(collection IncidentArtifactEntry, incidentID, user, host string) reporter.TimelineEntry {
+	collectedAt, err := time.Parse(time.RFC3339, collection.CollectedAt)
+	return reporter.TimelineEntry{
+		Timestamp:     collectedAt,
+		TimeKnown:     err == nil,
+		TimestampDesc: "Collection Time",
+		Source:        timelineSourceCollection,
+		SourceType:    "RedTriage collection",
+		Type:          "collection",
+		User:          user,
+		Host:          host,
+		IncidentID:    incidentID,
+		Short:         "Collection " + collection.ID,
+		Description:   fmt.Sprintf("Collected %d artifacts from %s", collection.ArtifactCount, host),
+		Reference:     collection.ID,
+		Attributes: map[string]interface{}{
+			"collection_id": collection.ID,
+			"platform":      collection.Platform,
+			"artifacts":     collection.ArtifactCount,
+		},
+	}
+}
+
+// buildTimeline merges the incident's timeline events, findings and
+// collections into one list ordered by time. Events without a precise time
+// come last, in the order they were recorded. Every entry names the
+// incident and a host: the host of the collection it refers to, or the
+// incident's first collected host.
+func (s *Session) buildTimeline(incident *IncidentContext) []reporter.TimelineEntry {
+	hosts := make(map[string]string)
+	defaultHost := ""
+	for _, collection := range incidentArtifacts(incident) {
+		if collection.Host == "-" {
+			continue
+		}
+		hosts[collection.ID] = collection.Host
+		if defaultHost == "" {
+			defaultHost = collection.Host
+		}
+	}
+	if defaultHost == "" {
+		defaultHost, _ = os.Hostname()
+	}
+	hostOf := func(data map[string]interface{}) string {
+		if id, ok := data["collection_id"].(string); ok && hosts[id] != "" {
+			return hosts[id]
+		}
+		return defaultHost
+	}
+
+	var entries []reporter.TimelineEntry
+	for _, event := range incident.Timeline {
+		user, _ := event.Data["analyst"].(string)
+		if user == "" {
+			user = incident.Analyst
+		}
+		attributes := make(map[string]interface{}, len(event.Data)+1)
+		for key, value := range event.Data {
+			attributes[key] = value
+		}
+		if event.MergeConflict != "" {
+			attributes["merge_conflict"] = event.MergeConflict
+		}
+		entries = append(entries, reporter.TimelineEntry{
+			Timestamp:     event.Timestamp,
+			TimeKnown:     !event.Timestamp.IsZero(),
+			TimestampDesc: "Event Recorded",
+			Source:        timelineSourceIncident,
+			SourceType:    "RedTriage incident timeline",
+			Type:          event.EventType,
+			User:          user,
+			Host:          hostOf(event.Data),
+			IncidentID:    incident.ID,
+			Short:         event.Description,
+			Description:   event.Description,
+			Reference:     event.ID,
+			Attributes:    attributes,
+		})
+	}
+
+	for _, finding := range incident.Findings {
+		description := finding.Description
+		if title, ok := finding.Evidence["rule_title"].(string); ok && title != "" && description == "" {
+			description = title
+		}
+		attributes := map[string]interface{}{
+			"severity":     finding.Severity,
+			"rule_id":      finding.RuleID,
+			"status":       finding.Status,
+			"triage_state": triageState(finding),
+		}
+		if collectionID, ok := finding.Evidence["collection_id"].(string); ok {
+			attributes["collection_id"] = collectionID
+		}
+		if title, ok := finding.Evidence["rule_title"].(string); ok && title != "" {
+			attributes["rule_title"] = title
+		}
+		entries = append(entries, reporter.TimelineEntry{
+			Timestamp:     finding.Timestamp,
+			TimeKnown:     !finding.Timestamp.IsZero(),
+			TimestampDesc: "Detection Time",
+			Source:        timelineSourceFinding,
+			SourceType:    "RedTriage finding",
+			Type:          finding.Type,
+			User:          finding.TriagedBy,
+			Host:          hostOf(finding.Evidence),
+			IncidentID:    incident.ID,
+			Short:         fmt.Sprintf("[%s] %s", finding.Severity, finding.RuleID),
+			Description:   description,
+			Reference:     finding.ID,
+			Attributes:    attributes,
+		})
+	}
+
+	for _, collection := range incidentArtifacts(incident) {
+		host := collection.Host
+		if host == "-" {
+			host = defaultHost
+		}
+		entries = append(entries, collectionTimelineEntry(collection, incident.ID, incident.Analyst, host))
+	}
+
+	reporter.SortTimeline(entries)
+	return entries
+}
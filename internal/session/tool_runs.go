@@ -0,0 +1,155 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// ToolRun is the last invocation of a tool: the arguments it was run with
+// and what it produced
+type ToolRun struct {
+	Args       []string       `json:"args"`
+	At         time.Time      `json:"at"`
+	Error      string         `json:"error,omitempty"`
+	OutputPath string         `json:"output_path,omitempty"`
+	Counts     map[string]int `json:"counts,omitempty"`
+}
+
+// commandLine returns the command the run repeats
+func (run *ToolRun) commandLine(name string) string {
+	return strings.TrimSpace(name + " " + strings.Join(run.Args, " "))
+}
+
+// countsText lists the result counts of the run, e.g. "12 findings, 3 new"
+func (run *ToolRun) countsText() string {
+	names := make([]string, 0, len(run.Counts))
+	for name := range run.Counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", run.Counts[name], strings.ReplaceAll(name, "_", " ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// noteToolOutput records the report the running command saved and its key
+// counts for the tool's run
+func (s *Session) noteToolOutput(path string, counts map[string]int) {
+	s.toolOutput = &ToolRun{OutputPath: path, Counts: counts}
+}
+
+// recordToolRun keeps the run of a tool in the active incident, or in the
+// session state when no incident is active
+func (s *Session) recordToolRun(name string, args []string, err error) {
+	run := &ToolRun{Args: args, At: time.Now()}
+	if s.toolOutput != nil {
+		run.OutputPath, run.Counts = s.toolOutput.OutputPath, s.toolOutput.Counts
+		s.toolOutput = nil
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if s.incidentContext != nil {
+		s.incidentContext.SetToolRun(name, run)
+		return
+	}
+	if s.toolRuns == nil {
+		s.toolRuns = make(map[string]*ToolRun)
+	}
+	s.toolRuns[name] = run
+}
+
+// lastToolRun returns the last run of a tool in the active incident, or
+// outside incidents when none is active
+func (s *Session) lastToolRun(name string) *ToolRun {
+	if s.incidentContext != nil {
+		return s.incidentContext.ToolRuns[name]
+	}
+	return s.toolRuns[name]
+}
+
+// canRerun reports whether an empty line repeats the current tool's last run
+func (s *Session) canRerun() bool {
+	return s.currentTool != nil && s.lastToolRun(s.currentTool.Name) != nil
+}
+
+// printToolRunPanel shows the last run of a tool and how to repeat it
+func (s *Session) printToolRunPanel(tool *Tool) {
+	scope := "outside an incident"
+	if s.incidentContext != nil {
+		scope = "in " + s.incidentContext.ID
+	}
+	run := s.lastToolRun(tool.Name)
+	if run == nil {
+		fmt.Printf("No previous run of %s %s\n", tool.Name, scope)
+		return
+	}
+
+	fmt.Printf("Last run %s: %s (%s ago)\n", scope, run.At.Format("2006-01-02 15:04:05"), time.Since(run.At).Round(time.Second))
+	fmt.Printf("  Flags:  %s\n", valueOrDash(strings.Join(run.Args, " ")))
+	if run.Error != "" {
+		fmt.Printf("  Result: failed: %s\n", output.Sanitize(run.Error))
+	} else {
+		fmt.Printf("  Result: %s\n", valueOrDash(run.countsText()))
+	}
+	if run.OutputPath != "" {
+		fmt.Printf("  Output: %s\n", run.OutputPath)
+	}
+	fmt.Printf("Press Enter on an empty line or type 'rerun' to run '%s' again\n", run.commandLine(tool.Name))
+}
+
+// cmdRerun repeats the last run of the current tool with the same
+// arguments, asking first when the tool acts on the host or the evidence
+func (s *Session) cmdRerun(args []string) error {
+	if len(args) > 0 {
+		return rterrors.Validationf("usage: rerun (repeats the last run of the current tool)")
+	}
+	if s.currentTool == nil {
+		return rterrors.Validationf("no tool selected; 'use <tool>' first")
+	}
+	name := s.currentTool.Name
+	run := s.lastToolRun(name)
+	if run == nil {
+		return rterrors.NotFoundf("no previous run of %s to repeat", name)
+	}
+
+	line := run.commandLine(name)
+	if needsRerunConfirm(name, run.Args) && !s.confirmRerun(line) {
+		fmt.Println("Not repeated")
+		return nil
+	}
+	fmt.Printf("Re-running: %s\n", line)
+	return s.processCommand(line)
+}
+
+// needsRerunConfirm reports whether repeating a command asks first. Any
+// run that would not be allowed on a read-only reports directory may act on
+// the live host or change the evidence, so only those in readOnlyCommands
+// repeat without asking.
+func needsRerunConfirm(name string, args []string) bool {
+	return !readOnlyAllowed(name, args)
+}
+
+// confirmRerun asks whether to repeat a command. Without a terminal to ask
+// at, it is not repeated.
+func (s *Session) confirmRerun(line string) bool {
+	color.New(color.FgYellow).Printf("'%s' acts on the live host or the evidence.\n", line)
+	if s.rl == nil {
+		return false
+	}
+
+	s.rl.SetPrompt("Run it again? [y/N]: ")
+	defer s.rl.SetPrompt(s.getPrompt())
+	answer, err := s.rl.Readline()
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return err == nil && (answer == "y" || answer == "yes")
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNeedsRerunConfirm(t *testing.T) {
+	tests := []struct {
+		line    string
+		confirm bool
+	}{
+		{"memory clear", true},
+		{"incident reopen INC-001", true},
+		{"incident scope set host WS01", true},
+		{"incident scope clear", true},
+		{"incident tuning set RT001 low", true},
+		{"incident tuning clear RT001", true},
+		{"findings baseline set findings.json", true},
+		{"findings baseline clear", true},
+		{"incident contain", true},
+		{"collect --output evidence", true},
+		{"reports cleanup", true},
+		{"incident list", false},
+		{"incident show INC-001", false},
+		{"findings show", false},
+		{"reports", false},
+		{"rules --verbose", false},
+		{"status", false},
+	}
+	for _, tt := range tests {
+		fields := strings.Fields(tt.line)
+		if got := needsRerunConfirm(fields[0], fields[1:]); got != tt.confirm {
+			t.Errorf("needsRerunConfirm(%q) = %v, want %v", tt.line, got, tt.confirm)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chzyer/readline"
+
+	"github.com/redtriage/redtriage/cmd"
+	"github.com/redtriage/redtriage/internal/validation"
+)
+
+// TestCompleterAndValidatorFollowCatalog checks the session completes and
+// accepts the commands of the CLI catalog, with the catalog's flags, and
+// nothing that is neither in the catalog nor a session command
+func TestCompleterAndValidatorFollowCatalog(t *testing.T) {
+	s := testSession(t)
+	s.validator = validation.NewCommandValidator(false)
+	s.initializeTools()
+
+	completer, ok := s.getCompleter().(*readline.PrefixCompleter)
+	if !ok {
+		t.Fatalf("completer is a %T", s.getCompleter())
+	}
+	completed := make(map[string][]string)
+	for _, item := range completer.GetChildren() {
+		name := strings.TrimSpace(string(item.GetName()))
+		for _, flag := range item.GetChildren() {
+			completed[name] = append(completed[name], strings.TrimSpace(string(flag.GetName())))
+		}
+		if _, ok := completed[name]; !ok {
+			completed[name] = nil
+		}
+	}
+	known := func(name string) bool {
+		err := s.validator.ValidateCommand(name, nil, nil)
+		return err == nil || !strings.Contains(err.Error(), "unknown command")
+	}
+
+	handlers := s.commandHandlers()
+	checked := 0
+	for _, info := range cmd.Catalog() {
+		if _, ok := handlers[info.Name]; !ok || info.Path != info.Name {
+			continue
+		}
+		checked++
+		if !known(info.Name) {
+			t.Errorf("validator does not know catalog command %s", info.Name)
+		}
+		flags, ok := completed[info.Name]
+		if !ok {
+			t.Errorf("completer does not offer catalog command %s", info.Name)
+			continue
+		}
+		tool := s.findTool(info.Name)
+		if tool == nil {
+			t.Errorf("catalog command %s is not a session tool", info.Name)
+			continue
+		}
+		if len(flags) != len(tool.Flags) {
+			t.Errorf("completer offers %d flags for %s, its tool has %d", len(flags), info.Name, len(tool.Flags))
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no catalog command is a session command")
+	}
+
+	for name := range completed {
+		if s.findTool(name) == nil && !builtinCommands[name] {
+			t.Errorf("completer offers %s, which is neither a tool nor a session command", name)
+		}
+	}
+	for _, tool := range s.tools {
+		if !known(tool.Name) {
+			t.Errorf("validator does not know tool %s", tool.Name)
+		}
+	}
+	if known("bogus") {
+		t.Error("validator accepts a command missing from the catalog")
+	}
+	if _, ok := completed["bogus"]; ok {
+		t.Error("completer offers a command missing from the catalog")
+	}
+}
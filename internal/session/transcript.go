@@ -0,0 +1,251 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// defaultTranscriptLimit caps a transcript when transcript_max_size is unset
+// or invalid
+const defaultTranscriptLimit = 1 << 20
+
+// encryptedTranscriptSuffix ends the names of encrypted transcripts
+const encryptedTranscriptSuffix = ".enc"
+
+// ansiPattern matches terminal escape sequences removed from transcripts
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// untranscribedCommands produce no analysis narrative worth keeping, or
+// would transcribe transcripts themselves
+var untranscribedCommands = map[string]bool{
+	"help": true, "?": true, "tools": true, "categories": true, "search": true,
+	"use": true, "rerun": true, "banner": true, "clear": true, "cls": true, "exit": true, "quit": true,
+	"incident": true, "memory": true,
+}
+
+// boundedBuffer keeps the first limit bytes written to it and counts the rest
+type boundedBuffer struct {
+	buf     bytes.Buffer
+	limit   int
+	dropped int
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - b.buf.Len()
+	if room >= len(p) {
+		return b.buf.Write(p)
+	}
+	if room > 0 {
+		b.buf.Write(p[:room])
+	} else {
+		room = 0
+	}
+	b.dropped += len(p) - room
+	return len(p), nil
+}
+
+// transcriptLimit returns the configured maximum transcript size
+func (s *Session) transcriptLimit() int {
+	if s.config == nil || s.config.TranscriptMaxSize == "" {
+		return defaultTranscriptLimit
+	}
+	size, err := config.ParseSize(s.config.TranscriptMaxSize)
+	if err != nil || size <= 0 {
+		return defaultTranscriptLimit
+	}
+	return int(size)
+}
+
+// transcriptDir returns the directory holding an incident's transcripts
+func (s *Session) transcriptDir(incidentID string) string {
+	return filepath.Join(s.reportsManager.GetIncidentsDirectory(), incidentID, "transcripts")
+}
+
+// runTranscribed runs a command handler and, while an incident is active,
+// tees everything it prints into a transcript file under the incident. The
+// screen output is unchanged. Readline keeps its own handle on the terminal,
+// so interactive prompts are not captured.
+func (s *Session) runTranscribed(name string, args []string, handler func(args []string) error) error {
+	incident := s.incidentContext
+	if incident == nil || untranscribedCommands[name] || (s.config != nil && !s.config.CaptureTranscripts) || s.readOnly() {
+		return handler(args)
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return handler(args)
+	}
+
+	stdout, stderr, colorOutput := os.Stdout, os.Stderr, color.Output
+	capture := &boundedBuffer{limit: s.transcriptLimit()}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		io.Copy(io.MultiWriter(stdout, capture), reader)
+	}()
+
+	os.Stdout, os.Stderr, color.Output = writer, writer, writer
+	eventsBefore := len(incident.Timeline)
+	started := time.Now()
+
+	cmdErr := handler(args)
+
+	os.Stdout, os.Stderr, color.Output = stdout, stderr, colorOutput
+	writer.Close()
+	wg.Wait()
+	reader.Close()
+
+	path, err := s.saveTranscript(incident, name, args, started, capture, cmdErr)
+	if err != nil {
+		fmt.Printf("Warning: failed to save transcript: %v\n", err)
+		return cmdErr
+	}
+	if incident == s.incidentContext {
+		s.referenceTranscript(name, path, eventsBefore)
+	}
+	return cmdErr
+}
+
+// saveTranscript writes a captured command output, without terminal escape
+// sequences, and returns its path relative to the incident's transcripts.
+// Transcripts of an encrypted incident are encrypted with it, as .txt.enc.
+func (s *Session) saveTranscript(incident *IncidentContext, name string, args []string, started time.Time, capture *boundedBuffer, cmdErr error) (string, error) {
+	dir := s.transcriptDir(incident.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create transcripts directory: %w", err)
+	}
+
+	status := "ok"
+	if cmdErr != nil {
+		status = "error: " + cmdErr.Error()
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "# Command: %s\n", strings.TrimSpace(name+" "+strings.Join(args, " ")))
+	fmt.Fprintf(&content, "# Started: %s\n", started.Format(time.RFC3339))
+	fmt.Fprintf(&content, "# Duration: %s\n", time.Since(started).Round(time.Millisecond))
+	fmt.Fprintf(&content, "# Analyst: %s\n", s.getCurrentUser())
+	fmt.Fprintf(&content, "# Status: %s\n\n", status)
+	content.WriteString(ansiPattern.ReplaceAllString(capture.buf.String(), ""))
+	if capture.dropped > 0 {
+		fmt.Fprintf(&content, "\n[transcript truncated: %d more bytes not captured; limit is transcript_max_size]\n", capture.dropped)
+	}
+
+	filename := fmt.Sprintf("%s-%s.txt", started.Format("20060102-150405.000"), name)
+	data := []byte(content.String())
+	if incident.Encryption != "" {
+		sealed, err := s.sealTranscript(incident, filename, data)
+		if err != nil {
+			return "", err
+		}
+		data = sealed
+		filename += encryptedTranscriptSuffix
+	}
+	if err := output.WriteFileAtomic(filepath.Join(dir, filename), data, 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// referenceTranscript links a transcript from the timeline events the
+// command added, or records a command event when it added none
+func (s *Session) referenceTranscript(name, transcript string, eventsBefore int) {
+	timeline := s.incidentContext.Timeline
+	if eventsBefore > len(timeline) {
+		eventsBefore = len(timeline)
+	}
+
+	added := timeline[eventsBefore:]
+	if len(added) == 0 {
+		s.addTimelineEvent("command_run", fmt.Sprintf("%s run — transcript: %s", name, transcript), map[string]interface{}{
+			"command":    name,
+			"transcript": transcript,
+		})
+		return
+	}
+
+	s.incidentContext.UpdateTimeline(eventsBefore, func(added []TimelineEvent) {
+		for i := range added {
+			added[i].Description += " — transcript: " + transcript
+			if added[i].Data == nil {
+				added[i].Data = map[string]interface{}{}
+			}
+			added[i].Data["transcript"] = transcript
+		}
+	})
+}
+
+// cmdTranscript reviews the active incident's transcripts:
+// incident transcript list | show <file>
+func (s *Session) cmdTranscript(args []string) error {
+	if s.incidentContext == nil {
+		return rterrors.Validationf("no active incident; transcripts are kept per incident")
+	}
+	dir := s.transcriptDir(s.incidentContext.ID)
+
+	if len(args) == 0 || args[0] == "list" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read transcripts: %w", err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(strings.TrimSuffix(entry.Name(), encryptedTranscriptSuffix), ".txt") {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) == 0 {
+			fmt.Printf("No transcripts for incident %s\n", s.incidentContext.ID)
+			return nil
+		}
+		sort.Strings(names)
+
+		fmt.Printf("Transcripts for incident %s:\n", s.incidentContext.ID)
+		for _, name := range names {
+			size := int64(0)
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				size = info.Size()
+			}
+			fmt.Printf("  %-48s %8d bytes\n", name, size)
+		}
+		fmt.Println("Use 'incident transcript show <file>' to read one")
+		return nil
+	}
+
+	if args[0] != "show" {
+		return rterrors.Validationf("unknown transcript subcommand: %s (use list or show)", args[0])
+	}
+	if len(args) < 2 {
+		return rterrors.Validationf("incident transcript show requires a transcript file")
+	}
+	name := filepath.Base(args[1])
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rterrors.NotFoundf("transcript not found: %s", name)
+		}
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if strings.HasSuffix(name, encryptedTranscriptSuffix) {
+		if data, err = s.openTranscript(s.incidentContext, strings.TrimSuffix(name, encryptedTranscriptSuffix), data); err != nil {
+			return err
+		}
+	}
+	fmt.Print(string(data))
+	return nil
+}
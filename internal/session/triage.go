@@ -0,0 +1,189 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Triage states an analyst can assign to a finding
+const (
+	TriageNeedsReview   = "needs-review"
+	TriageTruePositive  = "true-positive"
+	TriageFalsePositive = "false-positive"
+)
+
+// triageStateAliases maps the accepted --state values to triage states
+var triageStateAliases = map[string]string{
+	"tp":             TriageTruePositive,
+	"true-positive":  TriageTruePositive,
+	"fp":             TriageFalsePositive,
+	"false-positive": TriageFalsePositive,
+	"review":         TriageNeedsReview,
+	"needs-review":   TriageNeedsReview,
+}
+
+// sigmaFindingRecords turns the Sigma matches of a findings run into incident
+// finding records awaiting triage
+func sigmaFindingRecords(matches []map[string]interface{}, collectionID string) []Finding {
+	records := make([]Finding, 0, len(matches))
+	for _, match := range matches {
+		severity := strings.ToLower(stringField(match, "level"))
+		if !isValidSeverity(severity) {
+			severity = "medium"
+		}
+
+		records = append(records, Finding{
+			ID:          newID("FND", "150405"),
+			Type:        "sigma_detection",
+			Severity:    severity,
+			Description: stringField(match, "description"),
+			Evidence: map[string]interface{}{
+				"rule_title":    match["rule_title"],
+				"category":      match["category"],
+				"collection_id": collectionID,
+				"evidence":      match["evidence"],
+			},
+			RuleID:      stringField(match, "rule_id"),
+			Timestamp:   time.Now(),
+			Status:      "active",
+			TriageState: TriageNeedsReview,
+			EvidenceRef: collector.DecodeRecordRef(match["evidence_ref"]),
+		})
+	}
+	return records
+}
+
+// triageFinding records an analyst decision on a finding of the active
+// incident: findings triage <id> --state <tp|fp|needs-review> [--severity <level>] [--note <text>]
+func (s *Session) triageFinding(args []string) error {
+	if s.incidentContext == nil {
+		return rterrors.Validationf("findings triage requires an active incident (use 'incident switch')")
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		return rterrors.Validationf("findings triage requires a finding ID")
+	}
+
+	findingID := args[0]
+	state := ""
+	severity := ""
+	var note []string
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--state":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--state requires a value")
+			}
+			normalized, ok := triageStateAliases[strings.ToLower(args[i+1])]
+			if !ok {
+				return rterrors.Validationf("invalid triage state '%s'. Must be one of: tp, fp, needs-review", args[i+1])
+			}
+			state = normalized
+			i++
+		case "--severity":
+			if i+1 >= len(args) {
+				return rterrors.Validationf("--severity requires a value")
+			}
+			severity = strings.ToLower(args[i+1])
+			if !isValidSeverity(severity) {
+				return rterrors.Validationf("invalid severity '%s'. Must be one of: low, medium, high, critical", args[i+1])
+			}
+			i++
+		case "--note":
+			// The note runs until the next flag so it may contain spaces
+			for i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+				note = append(note, args[i+1])
+				i++
+			}
+			if len(note) == 0 {
+				return rterrors.Validationf("--note requires a value")
+			}
+		default:
+			return rterrors.Validationf("unknown findings triage option: %s", args[i])
+		}
+	}
+
+	if state == "" && severity == "" && len(note) == 0 {
+		return rterrors.Validationf("findings triage requires --state, --severity or --note")
+	}
+
+	var previousState, previousSeverity string
+	finding := s.incidentContext.UpdateFinding(findingID, func(finding *Finding) {
+		previousState = triageState(*finding)
+		previousSeverity = finding.Severity
+
+		if state != "" {
+			finding.TriageState = state
+			if state == TriageFalsePositive {
+				finding.Status = "dismissed"
+			} else {
+				finding.Status = "active"
+			}
+		}
+		if severity != "" && severity != finding.Severity {
+			if finding.OriginalSeverity == "" {
+				finding.OriginalSeverity = finding.Severity
+			}
+			finding.Severity = severity
+		}
+		if len(note) > 0 {
+			finding.TriageNote = strings.Trim(strings.Join(note, " "), `"'`)
+		}
+		now := time.Now()
+		finding.TriagedAt = &now
+		finding.TriagedBy = s.incidentContext.Analyst
+	})
+	if finding == nil {
+		return rterrors.NotFoundf("finding not found in incident %s: %s", s.incidentContext.ID, findingID)
+	}
+
+	s.addTimelineEvent("finding_triaged", fmt.Sprintf("Finding %s triaged as %s", finding.ID, triageState(*finding)), map[string]interface{}{
+		"finding_id":        finding.ID,
+		"rule_id":           finding.RuleID,
+		"previous_state":    previousState,
+		"state":             triageState(*finding),
+		"previous_severity": previousSeverity,
+		"severity":          finding.Severity,
+		"note":              finding.TriageNote,
+	})
+
+	if err := s.saveIncidentContext(s.incidentContext); err != nil {
+		return fmt.Errorf("failed to save incident context: %w", err)
+	}
+
+	fmt.Printf("✓ Finding %s: %s, severity %s\n", finding.ID, triageState(*finding), finding.Severity)
+	if finding.OriginalSeverity != "" {
+		fmt.Printf("  Original severity: %s\n", finding.OriginalSeverity)
+	}
+	if finding.TriageNote != "" {
+		fmt.Printf("  Note: %s\n", finding.TriageNote)
+	}
+	fmt.Printf("Active findings: %d of %d\n", activeFindingCount(s.incidentContext), len(s.incidentContext.Findings))
+
+	return nil
+}
+
+// triageState returns the finding's triage state; findings recorded before
+// triage existed are awaiting review
+func triageState(finding Finding) string {
+	if finding.TriageState == "" {
+		return TriageNeedsReview
+	}
+	return finding.TriageState
+}
+
+// activeFindingCount counts the findings not dismissed as false positives
+func activeFindingCount(incident *IncidentContext) int {
+	return incident.counters().active
+}
+
+func isValidSeverity(severity string) bool {
+	switch severity {
+	case "low", "medium", "high", "critical":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Snapshot is one line of a history file. The first snapshot holds the
+// whole document, every later one the patch from the snapshot before it.
+// SHA256 is the hash of the whole document at that point, checked on every
+// replay, so a history that was edited does not read back.
+type Snapshot struct {
+	Seq       int         `json:"seq"`
+	Timestamp time.Time   `json:"timestamp"`
+	SHA256    string      `json:"sha256"`
+	Base      interface{} `json:"base,omitempty"`
+	Patch     []Operation `json:"patch,omitempty"`
+	Size      int         `json:"-"` // bytes the snapshot takes in the file
+}
+
+// Changes is the number of patch operations the snapshot records, 0 for the
+// base copy
+func (s Snapshot) Changes() int {
+	return len(s.Patch)
+}
+
+// Record appends a snapshot of doc to the history file at path, unless doc
+// is unchanged since the last snapshot or that one is younger than
+// interval. It reports whether a snapshot was written. The caller
+// serializes writers of the same file.
+func Record(path string, doc interface{}, interval time.Duration, now time.Time) (bool, error) {
+	snapshots, last, err := replay(path, 0)
+	if err != nil {
+		return false, err
+	}
+
+	next := Snapshot{Seq: 1, Timestamp: now.UTC()}
+	if len(snapshots) > 0 {
+		previous := snapshots[len(snapshots)-1]
+		if now.Sub(previous.Timestamp) < interval {
+			return false, nil
+		}
+		next.Seq = previous.Seq + 1
+		if next.Patch = Diff(last, doc); len(next.Patch) == 0 {
+			return false, nil
+		}
+	} else {
+		next.Base = doc
+	}
+	if next.SHA256, err = hashOf(doc); err != nil {
+		return false, err
+	}
+
+	line, err := json.Marshal(next)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return false, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return true, nil
+}
+
+// History lists the snapshots in the history file at path, oldest first,
+// after checking that each one replays to its recorded hash. A missing file
+// is an empty history.
+func History(path string) ([]Snapshot, error) {
+	snapshots, _, err := replay(path, 0)
+	return snapshots, err
+}
+
+// State returns the whole document as it was at snapshot seq
+func State(path string, seq int) (interface{}, error) {
+	if seq < 1 {
+		return nil, rterrors.Validationf("invalid snapshot %d", seq)
+	}
+	snapshots, doc, err := replay(path, seq)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 || snapshots[len(snapshots)-1].Seq != seq {
+		return nil, rterrors.NotFoundf("snapshot %d not found", seq)
+	}
+	return doc, nil
+}
+
+// Current names the document as it is now, next to the numbered snapshots
+const Current = "current"
+
+// Compare returns the document at snapshot from and the patch that turns it
+// into the document at snapshot to. Snapshots are named by number or by
+// Current, which stands for current; from defaults to the latest snapshot
+// and to to Current.
+func Compare(path string, current interface{}, from, to string) (interface{}, []Operation, error) {
+	if from == "" {
+		snapshots, err := History(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, nil, rterrors.NotFoundf("no snapshots recorded yet")
+		}
+		from = strconv.Itoa(snapshots[len(snapshots)-1].Seq)
+	}
+	if to == "" {
+		to = Current
+	}
+
+	fromDoc, err := resolve(path, current, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toDoc, err := resolve(path, current, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromDoc, Diff(fromDoc, toDoc), nil
+}
+
+// resolve returns the document a snapshot name stands for
+func resolve(path string, current interface{}, name string) (interface{}, error) {
+	if strings.EqualFold(name, Current) {
+		return current, nil
+	}
+	seq, err := strconv.Atoi(strings.TrimPrefix(name, "#"))
+	if err != nil {
+		return nil, rterrors.Validationf("invalid snapshot %q (use a snapshot number or %s)", name, Current)
+	}
+	return State(path, seq)
+}
+
+// replay reads the history file up to and including snapshot upTo, or all
+// of it for 0, and returns the snapshots read and the document they lead to
+func replay(path string, upTo int) ([]Snapshot, interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var snapshots []Snapshot
+	var doc interface{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 256*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snapshot Snapshot
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&snapshot); err != nil {
+			return nil, nil, rterrors.Integrityf("snapshot %d of %s is unreadable: %v", len(snapshots)+1, filepath.Base(path), err)
+		}
+		snapshot.Size = len(scanner.Bytes()) + 1
+
+		if len(snapshots) == 0 {
+			doc, snapshot.Base = snapshot.Base, nil
+		} else if doc, err = Apply(doc, snapshot.Patch); err != nil {
+			return nil, nil, rterrors.Integrityf("snapshot %d of %s does not apply: %v", snapshot.Seq, filepath.Base(path), err)
+		}
+		hash, err := hashOf(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hash != snapshot.SHA256 {
+			return nil, nil, rterrors.Integrityf("snapshot %d of %s does not match its hash; the history was modified", snapshot.Seq, filepath.Base(path))
+		}
+
+		snapshots = append(snapshots, snapshot)
+		if snapshot.Seq == upTo {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return snapshots, doc, nil
+}
+
+// hashOf hashes the canonical encoding of doc: object keys sorted, numbers
+// as decoded
+func hashOf(doc interface{}) (string, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot document: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
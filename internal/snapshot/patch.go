@@ -0,0 +1,258 @@
+// Package snapshot keeps the history of a JSON document, such as an
+// incident, as a base copy and a chain of JSON Patches (RFC 6902), and
+// compares any two of its snapshots
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation is one JSON Patch operation. Only add, remove and replace are
+// produced and applied.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves the value out of remove operations, which have none;
+// add and replace keep it even when it is null
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
+// Decode parses a JSON document keeping numbers as written, so that a
+// snapshot hashes the same however often it is decoded and encoded
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return doc, nil
+}
+
+// Diff returns the patch that turns from into to. Objects are compared key
+// by key and arrays index by index, so appending to a list costs one add.
+func Diff(from, to interface{}) []Operation {
+	var ops []Operation
+	diff("", from, to, &ops)
+	return ops
+}
+
+func diff(path string, from, to interface{}, ops *[]Operation) {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		if t, ok := to.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(f) {
+				if value, ok := t[key]; ok {
+					diff(path+"/"+escape(key), f[key], value, ops)
+				} else {
+					*ops = append(*ops, Operation{Op: "remove", Path: path + "/" + escape(key)})
+				}
+			}
+			for _, key := range sortedKeys(t) {
+				if _, ok := f[key]; !ok {
+					*ops = append(*ops, Operation{Op: "add", Path: path + "/" + escape(key), Value: t[key]})
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if t, ok := to.([]interface{}); ok {
+			for i := 0; i < len(f) && i < len(t); i++ {
+				diff(fmt.Sprintf("%s/%d", path, i), f[i], t[i], ops)
+			}
+			// Remove from the end so the indices of the remaining items hold
+			for i := len(f) - 1; i >= len(t); i-- {
+				*ops = append(*ops, Operation{Op: "remove", Path: fmt.Sprintf("%s/%d", path, i)})
+			}
+			for i := len(f); i < len(t); i++ {
+				*ops = append(*ops, Operation{Op: "add", Path: fmt.Sprintf("%s/%d", path, i), Value: t[i]})
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(from, to) {
+		*ops = append(*ops, Operation{Op: "replace", Path: path, Value: to})
+	}
+}
+
+// Apply applies patch to doc and returns the result. doc is changed in
+// place; on error it is left partly patched.
+func Apply(doc interface{}, patch []Operation) (interface{}, error) {
+	for _, op := range patch {
+		if op.Op != "add" && op.Op != "remove" && op.Op != "replace" {
+			return nil, fmt.Errorf("unsupported patch operation %q at %s", op.Op, op.Path)
+		}
+		tokens, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = apply(doc, tokens, op); err != nil {
+			return nil, fmt.Errorf("failed to %s %s: %w", op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func apply(node interface{}, tokens []string, op Operation) (interface{}, error) {
+	if len(tokens) == 0 {
+		if op.Op == "remove" {
+			return nil, fmt.Errorf("cannot remove the whole document")
+		}
+		return op.Value, nil
+	}
+	key, rest := tokens[0], tokens[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[key]
+		if !ok && (len(rest) > 0 || op.Op != "add") {
+			return nil, fmt.Errorf("no member %q", key)
+		}
+		switch {
+		case len(rest) > 0:
+			updated, err := apply(child, rest, op)
+			if err != nil {
+				return nil, err
+			}
+			n[key] = updated
+		case op.Op == "remove":
+			delete(n, key)
+		default:
+			n[key] = op.Value
+		}
+		return n, nil
+
+	case []interface{}:
+		if key == "-" && len(rest) == 0 && op.Op == "add" {
+			return append(n, op.Value), nil
+		}
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid array index %q", key)
+		}
+		if len(rest) == 0 && op.Op == "add" {
+			if index > len(n) {
+				return nil, fmt.Errorf("index %d is past the end of the array", index)
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = op.Value
+			return n, nil
+		}
+		if index >= len(n) {
+			return nil, fmt.Errorf("index %d is past the end of the array", index)
+		}
+		switch {
+		case len(rest) > 0:
+			updated, err := apply(n[index], rest, op)
+			if err != nil {
+				return nil, err
+			}
+			n[index] = updated
+		case op.Op == "remove":
+			n = append(n[:index], n[index+1:]...)
+		default:
+			n[index] = op.Value
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%q is not inside an object or array", key)
+}
+
+// Resolve returns the value at a JSON Pointer in doc
+func Resolve(doc interface{}, pointer string) (interface{}, bool) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, false
+	}
+	for _, token := range tokens {
+		switch n := doc.(type) {
+		case map[string]interface{}:
+			value, ok := n[token]
+			if !ok {
+				return nil, false
+			}
+			doc = value
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(n) {
+				return nil, false
+			}
+			doc = n[index]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Change is a patch operation with the value it replaced or removed, as a
+// comparison of two snapshots shows it
+type Change struct {
+	Op     string      `json:"op"`
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Describe pairs every operation of a patch made by Diff with the value it
+// replaced or removed in from
+func Describe(from interface{}, patch []Operation) []Change {
+	changes := make([]Change, 0, len(patch))
+	for _, op := range patch {
+		change := Change{Op: op.Op, Path: op.Path}
+		if op.Op != "remove" {
+			change.After = op.Value
+		}
+		if op.Op != "add" {
+			change.Before, _ = Resolve(from, op.Path)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
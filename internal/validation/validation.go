@@ -2,6 +2,7 @@ package validation
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -11,7 +12,8 @@ import (
 
 // CommandValidator provides strict validation for CLI commands
 type CommandValidator struct {
-	strictMode bool
+	strictMode    bool
+	knownCommands map[string]bool
 }
 
 // NewCommandValidator creates a new command validator
@@ -21,6 +23,15 @@ func NewCommandValidator(strictMode bool) *CommandValidator {
 	}
 }
 
+// SetKnownCommands restricts validation to the given command names. When no
+// commands are registered any well-formed command name is accepted.
+func (cv *CommandValidator) SetKnownCommands(commands []string) {
+	cv.knownCommands = make(map[string]bool, len(commands))
+	for _, command := range commands {
+		cv.knownCommands[command] = true
+	}
+}
+
 // ValidateCommand validates command structure and arguments
 func (cv *CommandValidator) ValidateCommand(command string, args []string, flags map[string]interface{}) error {
 	// Validate command name
@@ -53,6 +64,11 @@ func (cv *CommandValidator) validateCommandName(command string) error {
 		return fmt.Errorf("command name contains invalid characters: %s", command)
 	}
 
+	// Check against the registered command catalog
+	if len(cv.knownCommands) > 0 && !cv.knownCommands[command] {
+		return fmt.Errorf("unknown command: %s", command)
+	}
+
 	// Check for reserved commands
 	reservedCommands := []string{"help", "version", "config", "init", "setup"}
 	for _, reserved := range reservedCommands {
@@ -80,8 +96,13 @@ func (cv *CommandValidator) validateArgument(arg string, index int) error {
 		return fmt.Errorf("argument cannot be empty")
 	}
 
-	// Prevent directory traversal
-	if strings.Contains(arg, "..") || strings.Contains(arg, "//") {
+	// Prevent directory traversal. The scheme separator of http(s) URLs,
+	// such as findings --elasticsearch takes, is not a path.
+	path := arg
+	if u, err := url.Parse(arg); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		path = strings.TrimPrefix(arg, u.Scheme+"://")
+	}
+	if strings.Contains(path, "..") || strings.Contains(path, "//") {
 		return fmt.Errorf("argument contains invalid path characters: %s", arg)
 	}
 
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package vault
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keystoreStore saves secret in the desktop keyring with secret-tool
+// (libsecret), under name. The envelope keeps the name to look it up by.
+func keystoreStore(name string, secret []byte) ([]byte, error) {
+	tool, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret-tool (libsecret) is not installed", ErrNoKeystore)
+	}
+	cmd := exec.Command(tool, "store", "--label=RedTriage "+name, "application", "redtriage", "key", name)
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(secret))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to store key in the keyring: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return []byte(name), nil
+}
+
+// keystoreLoad looks up the secret stored by keystoreStore
+func keystoreLoad(blob []byte) ([]byte, error) {
+	name := string(blob)
+	tool, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret-tool (libsecret) is not installed", ErrNoKeystore)
+	}
+	out, err := exec.Command(tool, "lookup", "application", "redtriage", "key", name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find key %s in the keyring: %w", name, err)
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil || len(secret) != 32 {
+		return nil, fmt.Errorf("keyring entry for %s is not a RedTriage key", name)
+	}
+	return secret, nil
+}
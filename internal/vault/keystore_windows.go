@@ -0,0 +1,44 @@
+//go:build windows
+
+package vault
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// keystoreStore protects secret with DPAPI for the current user. The
+// protected blob is kept in the envelope; name only describes it.
+func keystoreStore(name string, secret []byte) ([]byte, error) {
+	label, err := windows.UTF16PtrFromString("redtriage " + name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to protect key: %w", err)
+	}
+	var out windows.DataBlob
+	in := windows.DataBlob{Size: uint32(len(secret)), Data: &secret[0]}
+	if err := windows.CryptProtectData(&in, label, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("failed to protect key with DPAPI: %w", err)
+	}
+	return takeBlob(out), nil
+}
+
+// keystoreLoad recovers a secret protected by keystoreStore
+func keystoreLoad(blob []byte) ([]byte, error) {
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("no DPAPI key blob")
+	}
+	var out windows.DataBlob
+	in := windows.DataBlob{Size: uint32(len(blob)), Data: &blob[0]}
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("failed to unprotect key with DPAPI (another user or machine?): %w", err)
+	}
+	return takeBlob(out), nil
+}
+
+// takeBlob copies a blob allocated by DPAPI and frees it
+func takeBlob(blob windows.DataBlob) []byte {
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(blob.Data)))
+	return append([]byte(nil), unsafe.Slice(blob.Data, blob.Size)...)
+}
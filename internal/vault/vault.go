@@ -0,0 +1,231 @@
+// Package vault encrypts data at rest with AES-256-GCM, under a key derived
+// from a passphrase or held by the operating system's keystore
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Key sources
+const (
+	SourcePassphrase = "passphrase"
+	SourceKeystore   = "keystore"
+)
+
+// KDFIterations is the PBKDF2 work factor of passphrase keys
+const KDFIterations = 200000
+
+// ErrWrongKey is returned when an envelope does not decrypt
+var ErrWrongKey = errors.New("wrong passphrase or damaged data")
+
+// ErrNoKeystore is returned when the system has no keystore to hold keys
+var ErrNoKeystore = errors.New("no OS keystore available")
+
+// Envelope is the stored form of encrypted data. Besides the ciphertext it
+// carries what is needed to find the key again: the KDF parameters of a
+// passphrase key, or the keystore blob of a keystore key.
+type Envelope struct {
+	KeySource  string `json:"key_source"`
+	KDF        string `json:"kdf,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Salt       string `json:"salt,omitempty"`
+	KeyBlob    string `json:"key_blob,omitempty"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Key seals and opens envelopes. One key protects every write of the same
+// data, so a passphrase is only derived once.
+type Key struct {
+	Source     string
+	secret     []byte
+	salt       []byte
+	iterations int
+	blob       []byte
+}
+
+// NewPassphraseKey derives a key from passphrase with a new salt
+func NewPassphraseKey(passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	salt, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	return derive(passphrase, salt, KDFIterations), nil
+}
+
+// NewKeystoreKey generates a key and stores it in the OS keystore under
+// name
+func NewKeystoreKey(name string) (*Key, error) {
+	secret, err := randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := keystoreStore(name, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{Source: SourceKeystore, secret: secret, blob: blob}, nil
+}
+
+// PassphraseKey derives the key an envelope was sealed with from
+// passphrase. A wrong passphrase is only detected when the envelope is
+// opened.
+func PassphraseKey(e *Envelope, passphrase string) (*Key, error) {
+	if e.KeySource != SourcePassphrase {
+		return nil, fmt.Errorf("data is not encrypted with a passphrase (key source %q)", e.KeySource)
+	}
+	if e.KDF != "pbkdf2-sha256" || e.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported key derivation %q with %d iterations", e.KDF, e.Iterations)
+	}
+	salt, err := hex.DecodeString(e.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid envelope salt")
+	}
+	return derive(passphrase, salt, e.Iterations), nil
+}
+
+// KeystoreKey fetches the key an envelope was sealed with from the OS
+// keystore
+func KeystoreKey(e *Envelope) (*Key, error) {
+	if e.KeySource != SourceKeystore {
+		return nil, fmt.Errorf("data is not encrypted with a keystore key (key source %q)", e.KeySource)
+	}
+	blob, err := hex.DecodeString(e.KeyBlob)
+	if err != nil || len(blob) == 0 {
+		return nil, fmt.Errorf("invalid envelope key blob")
+	}
+	secret, err := keystoreLoad(blob)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{Source: SourceKeystore, secret: secret, blob: blob}, nil
+}
+
+// Matches reports whether e was sealed with a key of the same source and
+// parameters as k
+func (k *Key) Matches(e *Envelope) bool {
+	if e.KeySource != k.Source {
+		return false
+	}
+	if k.Source == SourcePassphrase {
+		return e.Salt == hex.EncodeToString(k.salt) && e.Iterations == k.iterations
+	}
+	blob, err := hex.DecodeString(e.KeyBlob)
+	return err == nil && bytes.Equal(blob, k.blob)
+}
+
+// Seal encrypts plaintext, binding it to additional data that must be
+// given again to open it
+func (k *Key) Seal(plaintext, additional []byte) (*Envelope, error) {
+	gcm, err := k.cipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{
+		KeySource:  k.Source,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(gcm.Seal(nil, nonce, plaintext, additional)),
+	}
+	if k.Source == SourcePassphrase {
+		e.KDF = "pbkdf2-sha256"
+		e.Iterations = k.iterations
+		e.Salt = hex.EncodeToString(k.salt)
+	} else {
+		e.KeyBlob = hex.EncodeToString(k.blob)
+	}
+	return e, nil
+}
+
+// Open decrypts an envelope sealed with k, failing with ErrWrongKey when
+// the key or the additional data is wrong or the envelope was changed
+func (k *Key) Open(e *Envelope, additional []byte) ([]byte, error) {
+	gcm, err := k.cipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(e.Nonce)
+	if err != nil || len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid envelope nonce")
+	}
+	ciphertext, err := hex.DecodeString(e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid envelope ciphertext")
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additional)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// cipher returns the AES-GCM cipher of the key
+func (k *Key) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// derive returns the passphrase key for salt
+func derive(passphrase string, salt []byte, iterations int) *Key {
+	return &Key{
+		Source:     SourcePassphrase,
+		secret:     PBKDF2SHA256([]byte(passphrase), salt, iterations, 32),
+		salt:       salt,
+		iterations: iterations,
+	}
+}
+
+// randomBytes returns n bytes from the system's secure random source
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// PBKDF2SHA256 is PBKDF2 (RFC 8018) with HMAC-SHA256
+func PBKDF2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var derived []byte
+	for block := uint32(1); len(derived) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		derived = append(derived, t...)
+	}
+	return derived[:keyLen]
+}
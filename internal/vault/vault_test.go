@@ -0,0 +1,73 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// pbkdf2Vector is PBKDF2-HMAC-SHA256 of "passwd" and "salt" with one
+// iteration, from RFC 7914
+const pbkdf2Vector = "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+
+func TestPBKDF2SHA256(t *testing.T) {
+	if got := hex.EncodeToString(PBKDF2SHA256([]byte("passwd"), []byte("salt"), 1, 64)); got != pbkdf2Vector {
+		t.Errorf("PBKDF2-SHA256 derived %s, want the RFC 7914 vector", got)
+	}
+}
+
+// TestPassphraseKeySealsIncident seals an incident holding memory under a
+// passphrase and checks the envelope hides it, that the passphrase opens it
+// again and that a wrong passphrase or another incident ID is refused
+func TestPassphraseKeySealsIncident(t *testing.T) {
+	incident := []byte(`{"id":"INC-TEST","memory":{"suspicious_ips":"10.9.8.7","informant":"j.doe"}}`)
+	key, err := NewPassphraseKey("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := key.Seal(incident, []byte("INC-TEST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"10.9.8.7", "j.doe", "correct horse"} {
+		if bytes.Contains(stored, []byte(secret)) {
+			t.Errorf("encrypted incident still contains %q", secret)
+		}
+	}
+
+	var reread Envelope
+	if err := json.Unmarshal(stored, &reread); err != nil {
+		t.Fatal(err)
+	}
+	unlocked, err := PassphraseKey(&reread, "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unlocked.Matches(&reread) {
+		t.Error("key derived again does not match its envelope")
+	}
+	opened, err := unlocked.Open(&reread, []byte("INC-TEST"))
+	if err != nil {
+		t.Fatalf("failed to open incident with its passphrase: %v", err)
+	}
+	if !bytes.Equal(opened, incident) {
+		t.Errorf("incident opened as %s", opened)
+	}
+
+	wrong, err := PassphraseKey(&reread, "incorrect horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrong.Open(&reread, []byte("INC-TEST")); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("wrong passphrase opened the incident (error %v)", err)
+	}
+	if _, err := unlocked.Open(&reread, []byte("INC-OTHER")); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("incident opened under another incident ID (error %v)", err)
+	}
+}
@@ -0,0 +1,223 @@
+package packager
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/schema"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// Bundle is a bundle ZIP, or the directory it was extracted to, opened for
+// analysis away from the host it was collected on. Everything is read from
+// the bundle itself.
+type Bundle struct {
+	Path      string
+	Manifest  *BundleManifest
+	Migration *schema.Migration
+
+	archive *zip.ReadCloser
+	files   map[string]*zip.File
+}
+
+// OpenBundle opens a bundle ZIP or extracted bundle directory and reads its
+// manifest
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, rterrors.NotFoundf("bundle not found: %s", bundlePath)
+	}
+
+	bundle := &Bundle{Path: bundlePath}
+	if !info.IsDir() {
+		archive, err := zip.OpenReader(bundlePath)
+		if err != nil {
+			return nil, rterrors.Validationf("%s is not a bundle ZIP: %w", bundlePath, err)
+		}
+		bundle.archive = archive
+		bundle.files = make(map[string]*zip.File, len(archive.File))
+		for _, file := range archive.File {
+			bundle.files[strings.ReplaceAll(file.Name, `\`, "/")] = file
+		}
+	}
+
+	data, err := bundle.ReadFile("manifest.json")
+	if err != nil {
+		bundle.Close()
+		return nil, rterrors.Validationf("%s is not a RedTriage bundle: %w", bundlePath, err)
+	}
+	if bundle.Manifest, bundle.Migration, err = ReadManifest(data); err != nil {
+		bundle.Close()
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Close releases the bundle ZIP
+func (b *Bundle) Close() error {
+	if b.archive == nil {
+		return nil
+	}
+	return b.archive.Close()
+}
+
+// Dir is the directory the bundle is in, where its analysis is written by
+// default. An extracted bundle is left as it is.
+func (b *Bundle) Dir() string {
+	return filepath.Dir(filepath.Clean(b.Path))
+}
+
+// ReadFile reads a file of the bundle by its slash-separated name
+func (b *Bundle) ReadFile(name string) ([]byte, error) {
+	if b.files == nil {
+		return os.ReadFile(filepath.Join(b.Path, filepath.FromSlash(name)))
+	}
+	file, ok := b.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in bundle: %w", name, os.ErrNotExist)
+	}
+	return readZipFile(file)
+}
+
+// Artifacts reads the collected artifacts back as artifact results: text as
+// strings, structured data decoded from its JSON, collection errors as
+// errors. Raw companions of converted text and artifacts the detector
+// derives are left out; detector.DerivedArtifacts builds the latter again.
+// An artifact whose content does not match its manifest checksum is an
+// integrity error.
+func (b *Bundle) Artifacts() ([]collector.ArtifactResult, error) {
+	names, err := b.artifactFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []collector.ArtifactResult
+	for _, info := range b.Manifest.Artifacts {
+		if info.Type == "raw_text" || detector.IsDerivedArtifact(info.Type) {
+			continue
+		}
+		name, ok := names[utils.SafeFilename(info.Name)]
+		if !ok {
+			return nil, rterrors.Integrityf("artifact %s listed in the manifest is missing from the bundle", info.Name)
+		}
+		data, err := b.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read artifact %s: %w", info.Name, err)
+		}
+		if data, err = decompressData(info.Compression, data); err != nil {
+			return nil, fmt.Errorf("failed to read artifact %s: %w", info.Name, err)
+		}
+		hash := sha256.Sum256(data)
+		if checksum := hex.EncodeToString(hash[:]); info.Checksum != "" && checksum != info.Checksum {
+			return nil, rterrors.Integrityf("artifact %s does not match its manifest checksum; verify the bundle", info.Name)
+		}
+
+		result, err := bundleArtifactResult(info, data)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Findings reads the findings recorded when the bundle was created
+func (b *Bundle) Findings() ([]detector.Finding, error) {
+	data, err := b.ReadFile("findings/findings.json")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read bundle findings: %w", err)
+	}
+	var findings []detector.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle findings: %w", err)
+	}
+	return findings, nil
+}
+
+// artifactFiles maps the safe names of the files under artifacts/ to their
+// bundle names, whatever extension or compression they were written with
+func (b *Bundle) artifactFiles() (map[string]string, error) {
+	names := make(map[string]string)
+	add := func(name string) {
+		if dir, base := path.Split(name); dir == "artifacts/" && base != "" {
+			names[artifactStem(base)] = name
+		}
+	}
+
+	if b.files != nil {
+		for name := range b.files {
+			add(name)
+		}
+		return names, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(b.Path, "artifacts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundle artifacts: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			add("artifacts/" + entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// bundleArtifactResult rebuilds an artifact result from its manifest entry
+// and bundled content
+func bundleArtifactResult(info ArtifactInfo, data []byte) (collector.ArtifactResult, error) {
+	artifact := collector.NewBaseArtifact(info.Name, info.Description, info.Category, info.Type).Artifact
+	for key, value := range info.Parameters {
+		artifact.Parameters[key] = value
+	}
+
+	result := collector.ArtifactResult{
+		Artifact: artifact,
+		Metadata: collector.Metadata{
+			StartedAt:   info.StartedAt,
+			CollectedAt: info.CollectedAt,
+			Collector:   "bundle",
+			Source:      "bundle",
+			Tags:        map[string]string{},
+		},
+		Size:     info.Size,
+		Checksum: info.Checksum,
+	}
+	for key, value := range info.Metadata {
+		if text, ok := value.(string); ok && key != "data_format" {
+			result.Metadata.Tags[key] = text
+		}
+	}
+	result.Metadata.Commands = collector.DecodeCommands(info.Metadata["commands"])
+	if message := result.Metadata.Tags["error"]; message != "" {
+		result.Error = errors.New(message)
+	}
+	result.Reason = collector.ReasonCode(result.Metadata.Tags["reason"])
+
+	switch {
+	case info.Type == "file":
+		// Binary file artifacts such as packet captures have no text
+	case info.Metadata["data_format"] == "json":
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return result, fmt.Errorf("failed to parse artifact %s: %w", info.Name, err)
+		}
+		result.Data = value
+	default:
+		result.Data = string(data)
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package packager
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// Artifact compression codecs. zstd runs the zstd tool, which must be on
+// the PATH both when the bundle is created and when it is read.
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// compressionExts are the file extensions compressed artifacts get
+var compressionExts = map[string]string{
+	CompressionGzip: ".gz",
+	CompressionZstd: ".zst",
+}
+
+// ValidateCompression checks an artifact compression codec: none, gzip or
+// zstd. It returns the codec to use, "" for none.
+func ValidateCompression(codec string) (string, error) {
+	switch strings.ToLower(codec) {
+	case "", "none":
+		return CompressionNone, nil
+	case CompressionGzip, "gz":
+		return CompressionGzip, nil
+	case CompressionZstd, "zst":
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return "", rterrors.ExternalToolf("zstd compression needs the zstd tool on the PATH: %w", err)
+		}
+		return CompressionZstd, nil
+	}
+	return "", rterrors.Validationf("unknown artifact compression %q (valid: gzip, zstd, none)", codec)
+}
+
+// compressData compresses artifact content with codec
+func compressData(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return runZstd(data, "-q", "-c", "-19")
+	}
+	return nil, rterrors.Validationf("unknown artifact compression %q", codec)
+}
+
+// decompressData restores artifact content compressed with codec. Content
+// that was not compressed is returned as it is.
+func decompressData(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip artifact: %w", err)
+		}
+		defer reader.Close()
+		out, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip artifact: %w", err)
+		}
+		return out, nil
+	case CompressionZstd:
+		return runZstd(data, "-q", "-d", "-c")
+	}
+	return nil, rterrors.Validationf("unknown artifact compression %q; a newer RedTriage may be needed", codec)
+}
+
+// runZstd pipes data through the zstd tool
+func runZstd(data []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		return nil, rterrors.ExternalToolf("zstd artifacts need the zstd tool on the PATH: %w", err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("zstd", args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		var exitErr *exec.ExitError
+		if message == "" || !errors.As(err, &exitErr) {
+			message = err.Error()
+		}
+		return nil, rterrors.ExternalToolf("zstd failed: %s", message)
+	}
+	return stdout.Bytes(), nil
+}
+
+// artifactStem is the safe artifact name of a file under artifacts/: its
+// base name without the compression and format extensions
+func artifactStem(base string) string {
+	for _, ext := range compressionExts {
+		if strings.HasSuffix(base, ext) {
+			base = strings.TrimSuffix(base, ext)
+			break
+		}
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
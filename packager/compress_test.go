@@ -0,0 +1,68 @@
+package packager
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCompressedBundleReadsBack(t *testing.T) {
+	for _, codec := range []string{CompressionGzip, CompressionZstd} {
+		t.Run(codec, func(t *testing.T) {
+			if codec == CompressionZstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd tool not installed")
+				}
+			}
+			key, err := NewEphemeralSealKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, artifacts := testBundle(t, nil)
+
+			packagerInstance := NewPackager()
+			packagerInstance.SetSealKey(key)
+			packagerInstance.SetCompression(codec)
+			path, err := packagerInstance.CreateBundle(artifacts, nil, t.TempDir())
+			if err != nil {
+				t.Fatalf("CreateBundle: %v", err)
+			}
+
+			verify, err := VerifyBundleSeals(path, "", key)
+			if err != nil {
+				t.Fatalf("VerifyBundleSeals: %v", err)
+			}
+			if !verify.OK() {
+				t.Fatalf("%s bundle does not verify: %s", codec, strings.Join(verify.Problems(), "; "))
+			}
+
+			bundle, err := OpenBundle(path)
+			if err != nil {
+				t.Fatalf("OpenBundle: %v", err)
+			}
+			defer bundle.Close()
+			read, err := bundle.Artifacts()
+			if err != nil {
+				t.Fatalf("Artifacts: %v", err)
+			}
+			data := make(map[string]interface{}, len(read))
+			for _, result := range read {
+				data[result.Artifact.Name] = result.Data
+			}
+			for _, artifact := range artifacts {
+				if data[artifact.Artifact.Name] != artifact.Data {
+					t.Errorf("artifact %s reads back as %q, want %q", artifact.Artifact.Name, data[artifact.Artifact.Name], artifact.Data)
+				}
+			}
+
+			for _, info := range bundle.Manifest.Artifacts {
+				if info.Type == "file" {
+					continue
+				}
+				if info.Compression != codec || info.CompressedSize <= 0 {
+					t.Errorf("manifest does not record the %s size of artifact %s", codec, info.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package packager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/schema"
+)
+
+// ReadManifest parses a bundle manifest of any supported schema version and
+// upgrades it to the current one. The migration log records what was
+// inferred for older manifests.
+func ReadManifest(data []byte) (*BundleManifest, *schema.Migration, error) {
+	version, migration, err := schema.Read(schema.Bundle, data, schema.BundleVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if version < 1 {
+		upgradeManifestV0(&manifest, migration)
+	}
+	manifest.SchemaVersion = schema.BundleVersion
+
+	return &manifest, migration, nil
+}
+
+// upgradeManifestV0 fills in fields that pre-versioning manifests could omit
+func upgradeManifestV0(manifest *BundleManifest, migration *schema.Migration) {
+	if manifest.HostInfo == nil {
+		manifest.HostInfo = map[string]interface{}{}
+		migration.Notef("host_info missing; left empty")
+	}
+	if manifest.Configuration == nil {
+		manifest.Configuration = map[string]interface{}{}
+	}
+	if manifest.Metadata == nil {
+		manifest.Metadata = map[string]interface{}{}
+		migration.Notef("metadata missing; left empty")
+	}
+
+	if manifest.Checksums == nil {
+		manifest.Checksums = make(map[string]string)
+		for _, artifact := range manifest.Artifacts {
+			if artifact.Checksum != "" {
+				manifest.Checksums[artifact.Name] = artifact.Checksum
+			}
+		}
+		migration.Notef("checksums map missing; rebuilt from %d artifact entries", len(manifest.Checksums))
+	}
+
+	for i := range manifest.Artifacts {
+		if manifest.Artifacts[i].Metadata == nil {
+			manifest.Artifacts[i].Metadata = map[string]interface{}{}
+		}
+	}
+}
+
+// HostConflicts lists the earlier bundles in outputDir whose manifest claims
+// the hostname of identity with a different host fingerprint, as happens
+// with machines cloned from one image. Bundles from before host identities
+// were recorded carry no fingerprint and are not compared.
+func HostConflicts(outputDir string, identity collector.HostIdentity) []collector.HostConflict {
+	paths, _ := filepath.Glob(filepath.Join(outputDir, "redtriage-*", "manifest.json"))
+	sort.Strings(paths)
+
+	var conflicts []collector.HostConflict
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		manifest, _, err := ReadManifest(data)
+		if err != nil {
+			continue
+		}
+		other, ok := collector.HostIdentityFromMap(manifest.HostInfo)
+		if !ok || !identity.ConflictsWith(other) {
+			continue
+		}
+		conflicts = append(conflicts, collector.HostConflict{
+			Source:      manifest.CaseID,
+			Hostname:    other.Hostname,
+			Fingerprint: other.Fingerprint,
+		})
+	}
+	return conflicts
+}
@@ -0,0 +1,47 @@
+package packager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/redtriage/redtriage/internal/schema"
+)
+
+func TestReadManifestFormats(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "bundle_manifest_v*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no bundle manifest fixtures (%v)", err)
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		var version int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, "bundle_manifest_"), "v%d.json", &version); err != nil {
+			t.Fatalf("unexpected format fixture name %s", name)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, migration, err := ReadManifest(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if migration.From != version || migration.To != schema.BundleVersion {
+			t.Errorf("%s: read as v%d->v%d, want v%d->v%d", name, migration.From, migration.To, version, schema.BundleVersion)
+		}
+		if version < schema.BundleVersion && len(migration.Notes) == 0 {
+			t.Errorf("%s: upgraded without a migration log", name)
+		}
+	}
+
+	future := []byte(fmt.Sprintf(`{"schema_version": %d}`, schema.BundleVersion+1))
+	var newer *schema.NewerVersionError
+	if _, _, err := ReadManifest(future); !errors.As(err, &newer) {
+		t.Errorf("manifest v%d from a newer RedTriage was not refused: %v", schema.BundleVersion+1, err)
+	}
+}
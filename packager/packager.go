@@ -8,20 +8,32 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/redtriage/redtriage/collector"
 	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/naming"
+	"github.com/redtriage/redtriage/internal/rterrors"
+	"github.com/redtriage/redtriage/internal/schema"
 	"github.com/redtriage/redtriage/utils"
 )
 
 // Packager represents the packaging engine
 type Packager struct {
 	version string
+	sealKey *SealKey
+	namer   *naming.Namer
+	caseID  string
+	// compression is the codec artifacts are compressed with, "" for none
+	compression string
+	// redactionRules name the rules the bundle's content was redacted with
+	redactionRules []string
 }
 
 // BundleManifest represents the manifest for a triage bundle
 type BundleManifest struct {
+	SchemaVersion int                    `json:"schema_version"`
 	CaseID        string                 `json:"case_id"`
 	ToolVersion   string                 `json:"tool_version"`
 	CollectionTime time.Time             `json:"collection_time"`
@@ -32,6 +44,10 @@ type BundleManifest struct {
 	RedactionRules []string              `json:"redaction_rules"`
 	Checksums     map[string]string      `json:"checksums"`
 	Metadata      map[string]interface{} `json:"metadata"`
+	// SealKeyID identifies the key the artifact seals were made with
+	SealKeyID     string                 `json:"seal_key_id,omitempty"`
+	// FileNames maps each output (bundle, report types) to its file name
+	FileNames     map[string]string      `json:"file_names,omitempty"`
 }
 
 // ArtifactInfo represents information about a collected artifact
@@ -43,7 +59,16 @@ type ArtifactInfo struct {
 	Size        int64                  `json:"size"`
 	Checksum    string                 `json:"checksum"`
 	CollectedAt time.Time              `json:"collected_at"`
+	StartedAt   time.Time              `json:"started_at"`
+	DurationMS  float64                `json:"duration_ms"`
 	Metadata    map[string]interface{} `json:"metadata"`
+	// Parameters of the collected artifact, e.g. the event log channel
+	Parameters  map[string]string      `json:"parameters,omitempty"`
+	Seal        *Seal                  `json:"seal,omitempty"`
+	// Compression is the codec the artifact file is compressed with. Size
+	// and Checksum are of the decompressed content.
+	Compression    string              `json:"compression,omitempty"`
+	CompressedSize int64               `json:"compressed_size,omitempty"`
 }
 
 // FindingInfo represents information about a detection finding
@@ -74,13 +99,59 @@ func NewPackager() *Packager {
 	}
 }
 
+// SetSealKey makes the packager seal every artifact with key as it is
+// written into the bundle
+func (p *Packager) SetSealKey(key *SealKey) {
+	p.sealKey = key
+}
+
+// SetNamer names the bundle from a filename template
+func (p *Packager) SetNamer(namer *naming.Namer) {
+	p.namer = namer
+}
+
+// SetCaseID makes the next bundle use caseID instead of a generated one
+func (p *Packager) SetCaseID(caseID string) {
+	p.caseID = caseID
+}
+
+// SetCompression compresses text artifacts in the bundle with codec
+// (gzip or zstd, "" for none), see ValidateCompression
+func (p *Packager) SetCompression(codec string) {
+	p.compression = codec
+}
+
+// SetRedactionRules records in the manifest the rules the artifacts and
+// findings were redacted with before they were packaged
+func (p *Packager) SetRedactionRules(names []string) {
+	p.redactionRules = names
+}
+
+// seal returns the seal of an artifact, or nil when sealing is off
+func (p *Packager) seal(name, checksum string) *Seal {
+	if p.sealKey == nil {
+		return nil
+	}
+	return p.sealKey.Seal(name, checksum)
+}
+
 // CreateBundle creates a triage bundle with collected artifacts and findings
 func (p *Packager) CreateBundle(artifacts []collector.ArtifactResult, findings []detector.Finding, outputDir string) (string, error) {
 	// Generate case ID
-	caseID := utils.GenerateCaseID()
+	caseID := p.caseID
+	if caseID == "" {
+		caseID = utils.GenerateCaseID()
+	}
+	identity, _ := collector.FindHostIdentity(artifacts)
+	p.namer.SetCollection(caseID)
+	p.namer.SetHost(identity.Hostname)
 	
 	// Create bundle directory
-	bundleDir := filepath.Join(outputDir, fmt.Sprintf("redtriage-%s", caseID))
+	zipPath, err := p.namer.Path(outputDir, "bundle", "zip", fmt.Sprintf("redtriage-%s.zip", caseID))
+	if err != nil {
+		return "", fmt.Errorf("failed to name bundle: %w", err)
+	}
+	bundleDir := strings.TrimSuffix(zipPath, ".zip")
 	if err := os.MkdirAll(bundleDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create bundle directory: %w", err)
 	}
@@ -109,10 +180,11 @@ func (p *Packager) CreateBundle(artifacts []collector.ArtifactResult, findings [
 	}
 	
 	// Create manifest
-	manifest, err := p.createManifest(caseID, artifactInfos, findingInfos)
+	manifest, err := p.createManifest(caseID, identity, artifactInfos, findingInfos)
 	if err != nil {
 		return "", fmt.Errorf("failed to create manifest: %w", err)
 	}
+	manifest.FileNames = map[string]string{"bundle": filepath.Base(zipPath)}
 	
 	// Write manifest
 	manifestPath := filepath.Join(bundleDir, "manifest.json")
@@ -127,7 +199,6 @@ func (p *Packager) CreateBundle(artifacts []collector.ArtifactResult, findings [
 	}
 	
 	// Create ZIP archive
-	zipPath := bundleDir + ".zip"
 	if err := p.createZipArchive(bundleDir, zipPath); err != nil {
 		return "", fmt.Errorf("failed to create ZIP archive: %w", err)
 	}
@@ -147,6 +218,27 @@ func (p *Packager) CreateBundle(artifacts []collector.ArtifactResult, findings [
 	return zipPath, nil
 }
 
+// RecordFileNames adds the names of files written after the bundle, such as
+// reports, to the manifest beside it
+func (p *Packager) RecordFileNames(bundlePath string, names map[string]string) error {
+	manifestPath := filepath.Join(strings.TrimSuffix(bundlePath, ".zip"), "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.FileNames == nil {
+		manifest.FileNames = make(map[string]string)
+	}
+	for kind, name := range names {
+		manifest.FileNames[kind] = name
+	}
+	return p.writeManifest(&manifest, manifestPath)
+}
+
 // copyArtifacts copies artifacts to the bundle directory
 func (p *Packager) copyArtifacts(artifacts []collector.ArtifactResult, artifactsDir string) ([]ArtifactInfo, error) {
 	var artifactInfos []ArtifactInfo
@@ -154,10 +246,22 @@ func (p *Packager) copyArtifacts(artifacts []collector.ArtifactResult, artifacts
 	for _, artifact := range artifacts {
 		// Create safe filename
 		safeName := utils.SafeFilename(artifact.Artifact.Name)
+		
+		// File artifacts such as packet captures are copied as-is
+		if srcPath := artifact.Artifact.Parameters["path"]; artifact.Artifact.Type == "file" && srcPath != "" {
+			artifactInfo, err := p.copyFileArtifact(artifact, srcPath, artifactsDir, safeName)
+			if err != nil {
+				return nil, err
+			}
+			artifactInfos = append(artifactInfos, artifactInfo)
+			continue
+		}
+		
 		artifactPath := filepath.Join(artifactsDir, safeName+".txt")
 		
 		// Convert artifact data to string and write to file
 		var dataStr string
+		dataFormat := "text"
 		switch v := artifact.Data.(type) {
 		case string:
 			dataStr = v
@@ -165,20 +269,16 @@ func (p *Packager) copyArtifacts(artifacts []collector.ArtifactResult, artifacts
 			// Convert to JSON for complex data
 			if jsonData, err := json.MarshalIndent(v, "", "  "); err == nil {
 				dataStr = string(jsonData)
+				dataFormat = "json"
 			} else {
 				dataStr = fmt.Sprintf("%v", v)
 			}
 		}
 		
 		// Write artifact data
-		if err := os.WriteFile(artifactPath, []byte(dataStr), 0644); err != nil {
-			return nil, fmt.Errorf("failed to write artifact %s: %w", artifact.Artifact.Name, err)
-		}
-		
-		// Calculate checksum
-		checksum, err := utils.GetFileHash(artifactPath)
+		checksum, compressedSize, err := p.writeArtifactData(artifactPath, []byte(dataStr))
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate checksum for %s: %w", artifact.Artifact.Name, err)
+			return nil, fmt.Errorf("failed to write artifact %s: %w", artifact.Artifact.Name, err)
 		}
 		
 		// Create artifact info
@@ -190,7 +290,57 @@ func (p *Packager) copyArtifacts(artifacts []collector.ArtifactResult, artifacts
 			Size:        int64(len(dataStr)),
 			Checksum:    checksum,
 			CollectedAt: artifact.Metadata.CollectedAt,
-			Metadata:    map[string]interface{}{},
+			StartedAt:   artifact.Metadata.StartedAt,
+			DurationMS:  durationMS(artifact.Metadata.Duration),
+			Metadata:    map[string]interface{}{"data_format": dataFormat},
+			Parameters:  artifact.Artifact.Parameters,
+			Seal:        p.seal(artifact.Artifact.Name, checksum),
+			Compression:    p.compression,
+			CompressedSize: compressedSize,
+		}
+		
+		// Record why an artifact could not be collected, e.g. live-only
+		// artifacts in offline mode
+		if availability := artifact.Metadata.Tags["availability"]; availability != "" {
+			artifactInfo.Metadata["availability"] = availability
+			if artifact.Error != nil {
+				artifactInfo.Metadata["error"] = artifact.Error.Error()
+			}
+		}
+		
+		// Record whether a missing or incomplete artifact was not present,
+		// denied, lacked its tool, timed out or was truncated
+		if artifact.Reason != "" {
+			artifactInfo.Metadata["reason"] = string(artifact.Reason)
+			if artifact.Error != nil {
+				artifactInfo.Metadata["error"] = artifact.Error.Error()
+			}
+		}
+		if channels := artifact.Metadata.Tags["channel_reasons"]; channels != "" {
+			artifactInfo.Metadata["channel_reasons"] = channels
+		}
+		
+		// Record the commands run, with their exit codes and standard error
+		if len(artifact.Metadata.Commands) > 0 {
+			artifactInfo.Metadata["commands"] = artifact.Metadata.Commands
+		}
+		
+		// Text converted to UTF-8 records the encoding it was written in,
+		// and the original bytes are kept beside it as a raw artifact
+		if encoding := artifact.Metadata.Tags["encoding"]; encoding != "" {
+			artifactInfo.Metadata["encoding"] = encoding
+			if invalid := artifact.Metadata.Tags["invalid_bytes"]; invalid != "" {
+				artifactInfo.Metadata["invalid_bytes"] = invalid
+			}
+		}
+		if len(artifact.Raw) > 0 {
+			rawInfo, err := p.writeRawArtifact(artifact, artifactsDir)
+			if err != nil {
+				return nil, err
+			}
+			artifactInfo.Metadata["raw_artifact"] = rawInfo.Name
+			artifactInfos = append(artifactInfos, artifactInfo, rawInfo)
+			continue
 		}
 		
 		artifactInfos = append(artifactInfos, artifactInfo)
@@ -199,6 +349,103 @@ func (p *Packager) copyArtifacts(artifacts []collector.ArtifactResult, artifacts
 	return artifactInfos, nil
 }
 
+// writeRawArtifact writes the original bytes of a text artifact that was
+// converted to UTF-8 as the artifact <name>_raw
+func (p *Packager) writeRawArtifact(artifact collector.ArtifactResult, artifactsDir string) (ArtifactInfo, error) {
+	name := artifact.Artifact.Name + "_raw"
+	artifactPath := filepath.Join(artifactsDir, utils.SafeFilename(name)+".bin")
+	checksum, compressedSize, err := p.writeArtifactData(artifactPath, artifact.Raw)
+	if err != nil {
+		return ArtifactInfo{}, fmt.Errorf("failed to write artifact %s: %w", name, err)
+	}
+	
+	return ArtifactInfo{
+		Name:        name,
+		Description: "Original bytes of " + artifact.Artifact.Name + " before conversion to UTF-8",
+		Category:    artifact.Artifact.Category,
+		Type:        "raw_text",
+		Size:        int64(len(artifact.Raw)),
+		Checksum:    checksum,
+		CollectedAt: artifact.Metadata.CollectedAt,
+		StartedAt:   artifact.Metadata.StartedAt,
+		DurationMS:  durationMS(artifact.Metadata.Duration),
+		Metadata: map[string]interface{}{
+			"raw_of":   artifact.Artifact.Name,
+			"encoding": artifact.Metadata.Tags["encoding"],
+		},
+		Seal:           p.seal(name, checksum),
+		Compression:    p.compression,
+		CompressedSize: compressedSize,
+	}, nil
+}
+
+// writeArtifactData writes artifact content to path, or compressed to path
+// plus the codec's extension, and returns the checksum of the content and
+// the compressed size
+func (p *Packager) writeArtifactData(path string, data []byte) (string, int64, error) {
+	hash := sha256.Sum256(data)
+	checksum := fmt.Sprintf("%x", hash)
+	if p.compression == CompressionNone {
+		return checksum, 0, os.WriteFile(path, data, 0644)
+	}
+	
+	compressed, err := compressData(p.compression, data)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to compress: %w", err)
+	}
+	if err := os.WriteFile(path+compressionExts[p.compression], compressed, 0644); err != nil {
+		return "", 0, err
+	}
+	return checksum, int64(len(compressed)), nil
+}
+
+// copyFileArtifact copies a file artifact into the bundle, keeping its extension
+func (p *Packager) copyFileArtifact(artifact collector.ArtifactResult, srcPath, artifactsDir, safeName string) (ArtifactInfo, error) {
+	artifactPath := filepath.Join(artifactsDir, safeName+filepath.Ext(srcPath))
+	if err := utils.CopyFile(srcPath, artifactPath); err != nil {
+		return ArtifactInfo{}, fmt.Errorf("failed to copy artifact %s: %w", artifact.Artifact.Name, err)
+	}
+	
+	checksum, err := utils.GetFileHash(artifactPath)
+	if err != nil {
+		return ArtifactInfo{}, fmt.Errorf("failed to calculate checksum for %s: %w", artifact.Artifact.Name, err)
+	}
+	if artifact.Checksum != "" && artifact.Checksum != checksum {
+		return ArtifactInfo{}, rterrors.Integrityf("checksum mismatch for %s: collected %s, bundled %s", artifact.Artifact.Name, artifact.Checksum, checksum)
+	}
+	
+	size, err := utils.GetFileSize(artifactPath)
+	if err != nil {
+		return ArtifactInfo{}, fmt.Errorf("failed to stat artifact %s: %w", artifact.Artifact.Name, err)
+	}
+	
+	metadata := map[string]interface{}{}
+	for key, value := range artifact.Artifact.Parameters {
+		if key != "path" {
+			metadata[key] = value
+		}
+	}
+	
+	return ArtifactInfo{
+		Name:        artifact.Artifact.Name,
+		Description: artifact.Artifact.Description,
+		Category:    artifact.Artifact.Category,
+		Type:        artifact.Artifact.Type,
+		Size:        size,
+		Checksum:    checksum,
+		CollectedAt: artifact.Metadata.CollectedAt,
+		StartedAt:   artifact.Metadata.StartedAt,
+		DurationMS:  durationMS(artifact.Metadata.Duration),
+		Metadata:    metadata,
+		Seal:        p.seal(artifact.Artifact.Name, checksum),
+	}, nil
+}
+
+// durationMS expresses a collection time in milliseconds for the manifest
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
 // writeFindings writes findings to the bundle directory
 func (p *Packager) writeFindings(findings []detector.Finding, findingsDir string) ([]FindingInfo, error) {
 	var findingInfos []FindingInfo
@@ -246,8 +493,9 @@ func (p *Packager) writeFindings(findings []detector.Finding, findingsDir string
 	return findingInfos, nil
 }
 
-// createManifest creates the bundle manifest
-func (p *Packager) createManifest(caseID string, artifacts []ArtifactInfo, findings []FindingInfo) (*BundleManifest, error) {
+// createManifest creates the bundle manifest. The host info is the
+// collection's host identity when it has one.
+func (p *Packager) createManifest(caseID string, identity collector.HostIdentity, artifacts []ArtifactInfo, findings []FindingInfo) (*BundleManifest, error) {
 	// Get hostname for host info
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -269,6 +517,7 @@ func (p *Packager) createManifest(caseID string, artifacts []ArtifactInfo, findi
 	}
 	
 	manifest := &BundleManifest{
+		SchemaVersion: schema.BundleVersion,
 		CaseID:        caseID,
 		ToolVersion:   p.version,
 		CollectionTime: time.Now(),
@@ -279,7 +528,7 @@ func (p *Packager) createManifest(caseID string, artifacts []ArtifactInfo, findi
 		Artifacts:     artifacts,
 		Findings:      findings,
 		Configuration: make(map[string]interface{}),
-		RedactionRules: []string{},
+		RedactionRules: append([]string{}, p.redactionRules...),
 		Checksums:     checksums,
 		Metadata: map[string]interface{}{
 			"created_by": "RedTriage",
@@ -287,6 +536,29 @@ func (p *Packager) createManifest(caseID string, artifacts []ArtifactInfo, findi
 		},
 	}
 	
+	if p.sealKey != nil {
+		manifest.SealKeyID = p.sealKey.ID
+	}
+	
+	// A collection from inside WSL or a container says so, since it does
+	// not show the whole machine
+	if env := identity.Environment; env != nil {
+		manifest.Metadata["environment"] = env.Kind
+		if warnings := env.Warnings(); len(warnings) > 0 {
+			manifest.Metadata["environment_warnings"] = warnings
+		}
+	}
+	
+	if identity.Fingerprint != "" {
+		manifest.HostInfo = identity.Map()
+		manifest.Metadata["host_fingerprint"] = identity.Fingerprint
+		manifest.Metadata["collection_mode"] = "live"
+	}
+	if identity.Offline() {
+		manifest.Metadata["collection_mode"] = "offline"
+		manifest.Metadata["image_root"] = identity.ImageRoot
+	}
+	
 	return manifest, nil
 }
 
@@ -0,0 +1,90 @@
+package packager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+func TestCreateBundleKeepsRawBytes(t *testing.T) {
+	units := utf16.Encode([]rune("Name,Vendor\r\nÜberwachungsdienst,Müller GmbH\r\n"))
+	utf16le := []byte{0xff, 0xfe}
+	for _, unit := range units {
+		utf16le = append(utf16le, byte(unit), byte(unit>>8))
+	}
+	converted := collector.ArtifactResult{Artifact: collector.NewBaseArtifact("wmic_product", "Installed products", "host", "command").Artifact}
+	converted.SetTextCodePage(utf16le, 850)
+	plain := collector.ArtifactResult{Artifact: collector.NewBaseArtifact("hostname", "Host name", "host", "command").Artifact}
+	plain.SetTextCodePage([]byte("TEST-WS01\n"), 850)
+
+	bundle, err := NewPackager().CreateBundle([]collector.ArtifactResult{converted, plain}, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateBundle: %v", err)
+	}
+	verify, err := VerifyBundle(bundle)
+	if err != nil {
+		t.Fatalf("VerifyBundle: %v", err)
+	}
+	if !verify.OK() {
+		t.Fatalf("bundle does not verify: %s", strings.Join(verify.Problems(), "; "))
+	}
+
+	artifactsDir := filepath.Join(strings.TrimSuffix(bundle, ".zip"), "artifacts")
+	data, err := os.ReadFile(filepath.Join(artifactsDir, "wmic_product_raw.bin"))
+	if err != nil || !bytes.Equal(data, utf16le) {
+		t.Errorf("bundle does not keep the original bytes of a converted artifact (%v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(artifactsDir, "hostname_raw.bin")); err == nil {
+		t.Error("raw bytes bundled for an artifact the conversion left alone")
+	}
+}
+
+func TestBundleKeepsCommandRuns(t *testing.T) {
+	runs := map[string]collector.CommandRun{
+		"prints": {Args: []string{"sh", "-c", "echo provenance"}, ExitCode: 0},
+		"fails":  {Args: []string{"sh", "-c", "echo denied >&2; exit 3"}, ExitCode: 3, Stderr: "denied", Error: "exit status 3"},
+		"empty":  {Args: []string{"sh", "-c", "true"}, ExitCode: 0, Empty: true},
+	}
+	var results []collector.ArtifactResult
+	for name, run := range runs {
+		run.StartedAt = time.Now().UTC()
+		results = append(results, collector.ArtifactResult{
+			Artifact: collector.NewBaseArtifact("provenance_"+name, "Command provenance", "provenance", "command").Artifact,
+			Data:     name,
+			Metadata: collector.Metadata{Collector: "test", Tags: map[string]string{}, Commands: []collector.CommandRun{run}},
+		})
+	}
+
+	path, err := NewPackager().CreateBundle(results, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateBundle: %v", err)
+	}
+	bundle, err := OpenBundle(path)
+	if err != nil {
+		t.Fatalf("OpenBundle: %v", err)
+	}
+	defer bundle.Close()
+	bundled, err := bundle.Artifacts()
+	if err != nil {
+		t.Fatalf("Artifacts: %v", err)
+	}
+	if len(bundled) != len(results) {
+		t.Fatalf("bundle reads %d command artifacts, want %d", len(bundled), len(results))
+	}
+	for _, result := range bundled {
+		want := runs[strings.TrimPrefix(result.Artifact.Name, "provenance_")]
+		if len(result.Metadata.Commands) != 1 {
+			t.Errorf("bundled %s has %d command runs, want 1", result.Artifact.Name, len(result.Metadata.Commands))
+			continue
+		}
+		if run := result.Metadata.Commands[0]; run.ExitCode != want.ExitCode || run.Stderr != want.Stderr || run.Empty != want.Empty {
+			t.Errorf("bundled %s does not keep its command run: %+v", result.Artifact.Name, run)
+		}
+	}
+}
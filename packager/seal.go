@@ -0,0 +1,244 @@
+package packager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/vault"
+)
+
+// SealAlgorithm is the keyed hash used for artifact seals
+const SealAlgorithm = "hmac-sha256"
+
+// SealPassphraseEnv names the environment variable whose value encrypts
+// the per-collection seal key file, and decrypts it again for verify
+const SealPassphraseEnv = "REDTRIAGE_SEAL_PASSPHRASE"
+
+// minSealKeySize is the shortest key accepted for sealing, in bytes
+const minSealKeySize = 16
+
+// sealKDFIterations is the PBKDF2 work factor for encrypted key files
+const sealKDFIterations = 200000
+
+// Seal is a timestamped HMAC of one artifact, made when the artifact is
+// written into the bundle. It binds the artifact name, its SHA-256 and the
+// sealing time under a key kept apart from the bundle, so an artifact
+// changed afterwards cannot be given a matching seal without the key.
+type Seal struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"key_id"`
+	SealedAt  time.Time `json:"sealed_at"`
+	Value     string    `json:"value"`
+}
+
+// SealKey is the secret artifact seals are made with
+type SealKey struct {
+	ID     string
+	Secret []byte
+	// Ephemeral keys are generated for one collection and saved beside
+	// the bundle; other keys are supplied by the analyst
+	Ephemeral bool
+}
+
+// sealKeyFile is the on-disk form of a seal key. Key holds the secret in
+// hex, or is empty when the secret is encrypted in Ciphertext.
+type sealKeyFile struct {
+	KeyID      string    `json:"key_id"`
+	Algorithm  string    `json:"algorithm"`
+	CreatedAt  time.Time `json:"created_at"`
+	Key        string    `json:"key,omitempty"`
+	KDF        string    `json:"kdf,omitempty"`
+	Iterations int       `json:"iterations,omitempty"`
+	Salt       string    `json:"salt,omitempty"`
+	Nonce      string    `json:"nonce,omitempty"`
+	Ciphertext string    `json:"ciphertext,omitempty"`
+}
+
+// NewSealKey wraps a secret as a seal key
+func NewSealKey(secret []byte) (*SealKey, error) {
+	if len(secret) < minSealKeySize {
+		return nil, fmt.Errorf("seal key is too short: %d bytes, need at least %d", len(secret), minSealKeySize)
+	}
+	sum := sha256.Sum256(append([]byte("redtriage-seal-key:"), secret...))
+	return &SealKey{ID: hex.EncodeToString(sum[:8]), Secret: secret}, nil
+}
+
+// NewEphemeralSealKey generates a random key for a single collection
+func NewEphemeralSealKey() (*SealKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate seal key: %w", err)
+	}
+	key, err := NewSealKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	key.Ephemeral = true
+	return key, nil
+}
+
+// LoadSealKey reads a seal key file written by SaveSealKey, decrypting it
+// with passphrase when it is encrypted. Any other file is used as the raw
+// key, minus surrounding whitespace.
+func LoadSealKey(path, passphrase string) (*SealKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seal key: %w", err)
+	}
+
+	var file sealKeyFile
+	if json.Unmarshal(data, &file) != nil || file.KeyID == "" {
+		return NewSealKey([]byte(strings.TrimSpace(string(data))))
+	}
+
+	var secret []byte
+	if file.Ciphertext != "" {
+		if passphrase == "" {
+			return nil, fmt.Errorf("seal key %s is encrypted; set %s to its passphrase", path, SealPassphraseEnv)
+		}
+		if secret, err = file.decrypt(passphrase); err != nil {
+			return nil, err
+		}
+	} else if secret, err = hex.DecodeString(file.Key); err != nil {
+		return nil, fmt.Errorf("failed to parse seal key %s: %w", path, err)
+	}
+
+	key, err := NewSealKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	if key.ID != file.KeyID {
+		return nil, fmt.Errorf("seal key %s does not match its key ID %s", path, file.KeyID)
+	}
+	return key, nil
+}
+
+// SaveSealKey writes a seal key file readable only by the owner, with the
+// secret encrypted with AES-256-GCM under a PBKDF2 key from passphrase. It
+// refuses to write the secret in the clear: a plain key kept with the
+// bundle would let anyone re-seal altered artifacts.
+func SaveSealKey(path string, key *SealKey, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("seal key not saved: a passphrase (%s) is required to encrypt it", SealPassphraseEnv)
+	}
+	file := sealKeyFile{KeyID: key.ID, Algorithm: SealAlgorithm, CreatedAt: time.Now().UTC()}
+	if err := file.encrypt(key.Secret, passphrase); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal seal key: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write seal key: %w", err)
+	}
+	return nil
+}
+
+// encrypt stores secret encrypted under passphrase
+func (f *sealKeyFile) encrypt(secret []byte, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to encrypt seal key: %w", err)
+	}
+	gcm, err := sealKeyCipher(passphrase, salt, sealKDFIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to encrypt seal key: %w", err)
+	}
+
+	f.KDF = "pbkdf2-sha256"
+	f.Iterations = sealKDFIterations
+	f.Salt = hex.EncodeToString(salt)
+	f.Nonce = hex.EncodeToString(nonce)
+	f.Ciphertext = hex.EncodeToString(gcm.Seal(nil, nonce, secret, []byte(f.KeyID)))
+	return nil
+}
+
+// decrypt recovers the secret of an encrypted key file
+func (f *sealKeyFile) decrypt(passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(f.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seal key salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seal key nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(f.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse seal key: %w", err)
+	}
+	gcm, err := sealKeyCipher(passphrase, salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("failed to decrypt seal key: bad nonce")
+	}
+	secret, err := gcm.Open(nil, nonce, ciphertext, []byte(f.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt seal key: wrong passphrase or damaged file")
+	}
+	return secret, nil
+}
+
+// sealKeyCipher derives the AES-GCM cipher protecting a key file
+func sealKeyCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, fmt.Errorf("invalid seal key file: no KDF iterations")
+	}
+	block, err := aes.NewCipher(vault.PBKDF2SHA256([]byte(passphrase), salt, iterations, 32))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seal key cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seal key cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// Seal seals an artifact with the given content checksum now
+func (k *SealKey) Seal(name, checksum string) *Seal {
+	sealedAt := time.Now().UTC()
+	return &Seal{
+		Algorithm: SealAlgorithm,
+		KeyID:     k.ID,
+		SealedAt:  sealedAt,
+		Value:     hex.EncodeToString(k.mac(name, checksum, sealedAt)),
+	}
+}
+
+// Check reports whether seal was made with this key for an artifact with
+// the given name and content checksum
+func (k *SealKey) Check(name, checksum string, seal *Seal) bool {
+	if seal == nil || seal.Algorithm != SealAlgorithm || seal.KeyID != k.ID {
+		return false
+	}
+	value, err := hex.DecodeString(seal.Value)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(value, k.mac(name, checksum, seal.SealedAt))
+}
+
+// mac computes the HMAC a seal carries
+func (k *SealKey) mac(name, checksum string, sealedAt time.Time) []byte {
+	mac := hmac.New(sha256.New, k.Secret)
+	fmt.Fprintf(mac, "redtriage-seal-v1\n%s\n%s\n%s", name, checksum, sealedAt.UTC().Format(time.RFC3339Nano))
+	return mac.Sum(nil)
+}
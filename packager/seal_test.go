@@ -0,0 +1,223 @@
+package packager
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// testBundle packages a few text artifacts into a bundle in a temporary
+// directory, sealed with key when it is not nil
+func testBundle(t *testing.T, key *SealKey) (string, []collector.ArtifactResult) {
+	t.Helper()
+	now := time.Now()
+	var artifacts []collector.ArtifactResult
+	for _, name := range []string{"process_list", "network_connections", "services"} {
+		artifacts = append(artifacts, collector.ArtifactResult{
+			Artifact: collector.Artifact{Name: name, Category: "host", Type: "command"},
+			Data:     name + " output\n",
+			Metadata: collector.Metadata{StartedAt: now, CollectedAt: now},
+		})
+	}
+
+	packagerInstance := NewPackager()
+	packagerInstance.SetSealKey(key)
+	bundle, err := packagerInstance.CreateBundle(artifacts, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreateBundle: %v", err)
+	}
+	return bundle, artifacts
+}
+
+func TestBundleSealsVerify(t *testing.T) {
+	key, err := NewEphemeralSealKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, artifacts := testBundle(t, key)
+
+	result, err := VerifyBundleSeals(bundle, "", key)
+	if err != nil {
+		t.Fatalf("VerifyBundleSeals: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("sealed bundle does not verify: %s", strings.Join(result.Problems(), "; "))
+	}
+	if result.Sealed != len(artifacts) {
+		t.Errorf("expected %d sealed artifacts, found %d", len(artifacts), result.Sealed)
+	}
+
+	otherKey, err := NewEphemeralSealKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyBundleSeals(bundle, "", otherKey); err == nil {
+		t.Error("seals were checked with a key they were not made with")
+	}
+}
+
+func TestBundleSealsDetectRewrittenArtifact(t *testing.T) {
+	key, err := NewEphemeralSealKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, _ := testBundle(t, key)
+	tampered := filepath.Join(t.TempDir(), "tampered-bundle.zip")
+	name := rewriteArtifact(t, bundle, tampered)
+
+	plain, err := VerifyBundle(tampered)
+	if err != nil {
+		t.Fatalf("VerifyBundle: %v", err)
+	}
+	if !plain.OK() {
+		t.Fatalf("rewritten bundle should still match its rewritten checksums: %s", strings.Join(plain.Problems(), "; "))
+	}
+	sealed, err := VerifyBundleSeals(tampered, "", key)
+	if err != nil {
+		t.Fatalf("VerifyBundleSeals: %v", err)
+	}
+	if len(sealed.BadSeals) != 1 || !strings.HasPrefix(sealed.BadSeals[0], name+":") {
+		t.Errorf("expected the seal of %s to fail, got %v", name, sealed.BadSeals)
+	}
+}
+
+func TestSaveSealKeyEncryptsTheKey(t *testing.T) {
+	key, err := NewEphemeralSealKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "bundle.sealkey")
+
+	if err := SaveSealKey(keyPath, key, ""); err == nil {
+		t.Fatal("seal key saved without a passphrase to encrypt it")
+	}
+	if _, err := os.Stat(keyPath); err == nil {
+		t.Fatal("refused seal key was still written")
+	}
+
+	if err := SaveSealKey(keyPath, key, "correct horse"); err != nil {
+		t.Fatalf("SaveSealKey: %v", err)
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(hex.EncodeToString(key.Secret))) {
+		t.Error("seal key file holds the secret in the clear")
+	}
+
+	if _, err := LoadSealKey(keyPath, ""); err == nil {
+		t.Error("encrypted seal key loaded without its passphrase")
+	}
+	if _, err := LoadSealKey(keyPath, "wrong"); err == nil {
+		t.Error("encrypted seal key loaded with the wrong passphrase")
+	}
+	loaded, err := LoadSealKey(keyPath, "correct horse")
+	if err != nil {
+		t.Fatalf("LoadSealKey: %v", err)
+	}
+	if loaded.ID != key.ID || !bytes.Equal(loaded.Secret, key.Secret) {
+		t.Error("loaded seal key differs from the saved one")
+	}
+}
+
+// rewriteArtifact copies a bundle to dest with the content of its first
+// artifact changed and the manifest checksum updated to match, as someone
+// without the seal key would forge it. It returns the artifact's name.
+func rewriteArtifact(t *testing.T, bundle, dest string) string {
+	t.Helper()
+	archive, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	defer archive.Close()
+
+	var manifest map[string]interface{}
+	var target string
+	forged := []byte("forged content\n")
+	for _, file := range archive.File {
+		name := strings.ReplaceAll(file.Name, `\`, "/")
+		if name == "manifest.json" {
+			if err := json.Unmarshal(readTestZipEntry(t, file), &manifest); err != nil {
+				t.Fatalf("failed to parse manifest: %v", err)
+			}
+		}
+		if dir, base := path.Split(name); target == "" && dir == "artifacts/" && base != "" {
+			target = name
+		}
+	}
+	if manifest == nil || target == "" {
+		t.Fatal("bundle has no manifest or artifacts to rewrite")
+	}
+
+	safeName := strings.TrimSuffix(path.Base(target), path.Ext(target))
+	sum := sha256.Sum256(forged)
+	var artifactName string
+	artifacts, _ := manifest["artifacts"].([]interface{})
+	for _, entry := range artifacts {
+		artifact, _ := entry.(map[string]interface{})
+		if name, _ := artifact["name"].(string); name != "" && utils.SafeFilename(name) == safeName {
+			artifact["checksum"] = hex.EncodeToString(sum[:])
+			artifactName = name
+		}
+	}
+	if artifactName == "" {
+		t.Fatalf("no manifest entry for %s", target)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range archive.File {
+		data := readTestZipEntry(t, file)
+		switch strings.ReplaceAll(file.Name, `\`, "/") {
+		case "manifest.json":
+			data = manifestData
+		case target:
+			data = forged
+		}
+		w, err := zw.Create(file.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return artifactName
+}
+
+func readTestZipEntry(t *testing.T, file *zip.File) []byte {
+	t.Helper()
+	reader, err := file.Open()
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", file.Name, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", file.Name, err)
+	}
+	return data
+}
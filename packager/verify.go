@@ -0,0 +1,258 @@
+package packager
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/schema"
+	"github.com/redtriage/redtriage/utils"
+)
+
+// VerifyResult is the outcome of checking a bundle against a manifest
+type VerifyResult struct {
+	BundlePath    string            `json:"bundle_path"`
+	Manifest      string            `json:"manifest"`
+	CaseID        string            `json:"case_id"`
+	SchemaVersion int               `json:"schema_version"`
+	Migration     *schema.Migration `json:"migration,omitempty"`
+	Checked       int               `json:"checked"`
+	// Mismatches are entries whose content differs from the manifest
+	Mismatches []string `json:"mismatches,omitempty"`
+	// Missing are manifest entries absent from the bundle
+	Missing []string `json:"missing,omitempty"`
+	// Extra are bundle artifacts the manifest does not list
+	Extra []string `json:"extra,omitempty"`
+	// Sealed is how many artifacts carry a seal, made with SealKeyID.
+	// SealsChecked is set when a key was supplied to check them against.
+	Sealed       int    `json:"sealed"`
+	SealKeyID    string `json:"seal_key_id,omitempty"`
+	SealsChecked bool   `json:"seals_checked"`
+	// BadSeals are artifacts whose seal is missing or does not match
+	BadSeals []string `json:"bad_seals,omitempty"`
+}
+
+// OK reports whether the bundle and the manifest agree on every entry
+func (vr *VerifyResult) OK() bool {
+	return len(vr.Mismatches) == 0 && len(vr.Missing) == 0 && len(vr.Extra) == 0 && len(vr.BadSeals) == 0
+}
+
+// Problems returns every disagreement, one line each
+func (vr *VerifyResult) Problems() []string {
+	var problems []string
+	for _, name := range vr.Missing {
+		problems = append(problems, fmt.Sprintf("missing: %s is in the manifest but not in the bundle", name))
+	}
+	for _, name := range vr.Extra {
+		problems = append(problems, fmt.Sprintf("extra: %s is in the bundle but not in the manifest", name))
+	}
+	for _, mismatch := range vr.Mismatches {
+		problems = append(problems, "mismatch: "+mismatch)
+	}
+	for _, seal := range vr.BadSeals {
+		problems = append(problems, "seal: "+seal)
+	}
+	return problems
+}
+
+// VerifyBundle re-hashes the artifacts inside a bundle ZIP and compares them
+// with the checksums recorded in its manifest
+func VerifyBundle(zipPath string) (*VerifyResult, error) {
+	return verifyBundle(zipPath, "", nil)
+}
+
+// VerifyBundleAgainst checks a bundle against a manifest supplied
+// separately, such as one published over another channel, instead of the
+// manifest embedded in the bundle
+func VerifyBundleAgainst(zipPath, manifestPath string) (*VerifyResult, error) {
+	if manifestPath == "" {
+		return nil, fmt.Errorf("no manifest to verify against")
+	}
+	return verifyBundle(zipPath, manifestPath, nil)
+}
+
+// VerifyBundleSeals checks a bundle like VerifyBundle, or against a
+// separate manifest when manifestPath is set, and also checks every
+// artifact's seal with key. Content changed after collection fails its
+// seal even when the manifest checksum was rewritten to match.
+func VerifyBundleSeals(zipPath, manifestPath string, key *SealKey) (*VerifyResult, error) {
+	if key == nil {
+		return nil, fmt.Errorf("no seal key to verify with")
+	}
+	return verifyBundle(zipPath, manifestPath, key)
+}
+
+// verifyBundle checks a bundle against the manifest at manifestPath, or
+// against its own manifest.json when manifestPath is empty, and the
+// artifact seals against key when it is set
+func verifyBundle(zipPath, manifestPath string, key *SealKey) (*VerifyResult, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[strings.ReplaceAll(file.Name, `\`, "/")] = file
+	}
+
+	manifest, migration, source, err := loadVerifyManifest(files, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{BundlePath: zipPath, Manifest: source, CaseID: manifest.CaseID, SchemaVersion: migration.From,
+		SealKeyID: manifest.SealKeyID, SealsChecked: key != nil}
+	if key != nil && manifest.SealKeyID != "" && manifest.SealKeyID != key.ID {
+		return nil, fmt.Errorf("bundle was sealed with key %s, not %s", manifest.SealKeyID, key.ID)
+	}
+	if migration.Upgraded() {
+		result.Migration = migration
+	}
+
+	listed := make(map[string]bool, len(manifest.Artifacts))
+	for _, artifact := range manifest.Artifacts {
+		result.Checked++
+
+		file := findArtifactFile(files, utils.SafeFilename(artifact.Name))
+		if file == nil {
+			result.Missing = append(result.Missing, artifact.Name)
+			continue
+		}
+		listed[file.Name] = true
+
+		data, err := readZipFile(file)
+		if err == nil {
+			data, err = decompressData(artifact.Compression, data)
+		}
+		if err != nil {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s: %v", artifact.Name, err))
+			continue
+		}
+
+		hash := sha256.Sum256(data)
+		checksum := hex.EncodeToString(hash[:])
+		if checksum != artifact.Checksum {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s: checksum %s does not match manifest %s", artifact.Name, checksum, artifact.Checksum))
+		}
+		if problem := checkSeal(artifact, checksum, key); problem != "" {
+			result.BadSeals = append(result.BadSeals, problem)
+		}
+		if artifact.Seal != nil {
+			result.Sealed++
+		}
+	}
+	if key != nil && result.Sealed == 0 && len(manifest.Artifacts) > 0 {
+		result.BadSeals = append(result.BadSeals, "the bundle carries no seals to check")
+	}
+
+	// Artifacts the manifest does not account for
+	for name, file := range files {
+		if dir, base := path.Split(name); dir == "artifacts/" && base != "" && !listed[file.Name] {
+			result.Extra = append(result.Extra, name)
+		}
+	}
+	sort.Strings(result.Extra)
+
+	// The findings checksum covers the findings recorded in the manifest.
+	// An external manifest is checked against the findings the bundle
+	// itself carries.
+	if expected, ok := manifest.Checksums["findings"]; ok {
+		result.Checked++
+		findings := manifest.Findings
+		if manifestPath != "" {
+			embedded, _, err := readEmbeddedManifest(files)
+			if err != nil {
+				result.Missing = append(result.Missing, "findings")
+				return result, nil
+			}
+			findings = embedded.Findings
+		}
+		findingsData, err := json.Marshal(findings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal findings: %w", err)
+		}
+		hash := sha256.Sum256(findingsData)
+		if checksum := hex.EncodeToString(hash[:]); checksum != expected {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("findings: checksum %s does not match manifest %s", checksum, expected))
+		}
+	}
+
+	return result, nil
+}
+
+// checkSeal checks the seal of an artifact against the checksum of its
+// content in the bundle, and returns what is wrong with it, if anything.
+// Without a key only the presence of a seal is checked.
+func checkSeal(artifact ArtifactInfo, checksum string, key *SealKey) string {
+	if artifact.Seal == nil {
+		if key != nil {
+			return fmt.Sprintf("%s: no seal", artifact.Name)
+		}
+		return ""
+	}
+	if key != nil && !key.Check(artifact.Name, checksum, artifact.Seal) {
+		return fmt.Sprintf("%s: seal from %s does not match; the artifact or its seal was altered after collection",
+			artifact.Name, artifact.Seal.SealedAt.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// loadVerifyManifest reads the manifest a bundle is verified against and
+// describes where it came from
+func loadVerifyManifest(files map[string]*zip.File, manifestPath string) (*BundleManifest, *schema.Migration, string, error) {
+	if manifestPath == "" {
+		manifest, migration, err := readEmbeddedManifest(files)
+		return manifest, migration, "manifest.json (embedded)", err
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	manifest, migration, err := ReadManifest(data)
+	return manifest, migration, manifestPath, err
+}
+
+// readEmbeddedManifest reads the manifest.json stored in a bundle
+func readEmbeddedManifest(files map[string]*zip.File) (*BundleManifest, *schema.Migration, error) {
+	manifestFile, ok := files["manifest.json"]
+	if !ok {
+		return nil, nil, fmt.Errorf("bundle has no manifest.json")
+	}
+	data, err := readZipFile(manifestFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return ReadManifest(data)
+}
+
+// findArtifactFile finds an artifact in the bundle by its safe name,
+// whatever extension or compression it was written with
+func findArtifactFile(files map[string]*zip.File, safeName string) *zip.File {
+	for name, file := range files {
+		dir, base := path.Split(name)
+		if dir == "artifacts/" && artifactStem(base) == safeName {
+			return file
+		}
+	}
+	return nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
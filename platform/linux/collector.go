@@ -120,6 +120,9 @@ func (l *LinuxCollector) CollectExtendedArtifacts(ctx context.Context) ([]collec
 		results = append(results, packages)
 	}
 
+	// Collect containers, images and pods
+	results = append(results, l.collectContainerArtifacts(ctx)...)
+
 	return results, nil
 }
 
@@ -210,8 +213,8 @@ func (l *LinuxCollector) collectServices() (collector.ArtifactResult, error) {
 
 	var serviceData strings.Builder
 
-	// Try systemctl if available
-	if _, err := exec.LookPath("systemctl"); err == nil {
+	// Try systemctl if available; containers usually run without systemd
+	if _, err := exec.LookPath("systemctl"); err == nil && collector.DetectEnvironment().Systemd {
 		if output, err := exec.Command("systemctl", "list-units", "--type=service", "--state=running").Output(); err == nil {
 			serviceData.WriteString("=== Systemd Services ===\n")
 			serviceData.Write(output)
@@ -294,8 +297,10 @@ func (l *LinuxCollector) collectSystemLogs() (collector.ArtifactResult, error) {
 
 	var logData strings.Builder
 
-	// Collect recent system messages
-	if output, err := exec.Command("journalctl", "--no-pager", "-n", "100").Output(); err == nil {
+	// Collect recent system messages, when systemd keeps a journal
+	if !collector.DetectEnvironment().Systemd {
+		logData.WriteString("=== System Journal ===\nskipped: systemd is not running\n\n")
+	} else if output, err := exec.Command("journalctl", "--no-pager", "-n", "100").Output(); err == nil {
 		logData.WriteString("=== System Journal ===\n")
 		logData.Write(output)
 		logData.WriteString("\n\n")
@@ -0,0 +1,205 @@
+package linux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// containerCgroupPattern extracts the 64 hex digit container ID from a
+// cgroup path written by docker, containerd, CRI-O or podman, including the
+// kubepods slices used by Kubernetes
+var containerCgroupPattern = regexp.MustCompile(`(docker|containerd|crio|libpod|kubepods)[^\n]*?([0-9a-f]{64})`)
+
+// containerNamespaces are the namespaces compared against PID 1 to show how
+// a containerized process is isolated
+var containerNamespaces = []string{"pid", "mnt", "net", "uts", "ipc", "user", "cgroup"}
+
+// ContainerProcess is a host process that runs inside a container
+type ContainerProcess struct {
+	PID         int               `json:"pid"`
+	Command     string            `json:"command"`
+	Runtime     string            `json:"runtime"`
+	ContainerID string            `json:"container_id"`
+	Namespaces  map[string]string `json:"namespaces"`
+	Isolated    []string          `json:"isolated_namespaces"`
+}
+
+// collectContainerArtifacts collects containers, images and pods from the
+// container runtimes installed on the host. Runtimes that are not installed
+// are skipped; a runtime that is installed but not answering is recorded as
+// a failed artifact. Containerized processes are found from /proc, so they
+// are reported even when no runtime tool is available.
+func (l *LinuxCollector) collectContainerArtifacts(ctx context.Context) []collector.ArtifactResult {
+	var results []collector.ArtifactResult
+
+	if _, err := exec.LookPath("docker"); err == nil {
+		results = append(results,
+			l.collectDockerList(ctx, "docker_containers", "Docker containers, running and stopped", collector.ContainerListType, "ps", "--all", "--no-trunc", "--format", "{{json .}}"),
+			l.collectDockerList(ctx, "docker_images", "Local Docker images", collector.ContainerImagesType, "images", "--no-trunc", "--format", "{{json .}}"),
+			l.collectDockerInspect(ctx),
+		)
+	}
+
+	if _, err := exec.LookPath("crictl"); err == nil {
+		results = append(results, l.collectPods(ctx))
+	}
+
+	return append(results, l.collectContainerProcesses())
+}
+
+// collectDockerList runs a docker listing command
+func (l *LinuxCollector) collectDockerList(ctx context.Context, name, description, artifactType string, args ...string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact(name, description, collector.ContainerCategory, artifactType).Artifact
+
+	output, err := runContainerTool(ctx, "docker", args...)
+	return l.newContainerResult(artifact, "docker", output, err)
+}
+
+// collectDockerInspect inspects every running container
+func (l *LinuxCollector) collectDockerInspect(ctx context.Context) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("docker_inspect", "Configuration of running Docker containers", collector.ContainerCategory, collector.ContainerInspectType).Artifact
+
+	ids, err := runContainerTool(ctx, "docker", "ps", "--quiet", "--no-trunc")
+	if err != nil {
+		return l.newContainerResult(artifact, "docker", "", err)
+	}
+
+	running := strings.Fields(ids)
+	if len(running) == 0 {
+		return l.newContainerResult(artifact, "docker", "[]", nil)
+	}
+
+	output, err := runContainerTool(ctx, "docker", append([]string{"inspect"}, running...)...)
+	return l.newContainerResult(artifact, "docker", output, err)
+}
+
+// collectPods lists Kubernetes pods and their containers through the CRI
+func (l *LinuxCollector) collectPods(ctx context.Context) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("kubernetes_pods", "Kubernetes pods and containers on this node", collector.ContainerCategory, collector.PodListType).Artifact
+
+	pods, err := runContainerTool(ctx, "crictl", "pods", "--output", "json")
+	if err != nil {
+		return l.newContainerResult(artifact, "crictl", "", err)
+	}
+	containers, err := runContainerTool(ctx, "crictl", "ps", "--all", "--output", "json")
+	if err != nil {
+		return l.newContainerResult(artifact, "crictl", "", err)
+	}
+
+	data := fmt.Sprintf("{\"pods\": %s, \"containers\": %s}", strings.TrimSpace(pods), strings.TrimSpace(containers))
+	return l.newContainerResult(artifact, "crictl", data, nil)
+}
+
+// collectContainerProcesses walks /proc for processes whose cgroup belongs
+// to a container and records which namespaces they do not share with PID 1
+func (l *LinuxCollector) collectContainerProcesses() collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("container_processes", "Host processes running inside containers, with their namespaces", collector.ContainerCategory, collector.ContainerProcessesType).Artifact
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return l.newContainerResult(artifact, "/proc", "", fmt.Errorf("failed to read /proc: %w", err))
+	}
+
+	hostNamespaces := processNamespaces(1)
+	processes := make([]ContainerProcess, 0)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == 1 {
+			continue
+		}
+
+		cgroup, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cgroup"))
+		if err != nil {
+			continue
+		}
+		match := containerCgroupPattern.FindStringSubmatch(string(cgroup))
+		if match == nil {
+			continue
+		}
+
+		process := ContainerProcess{
+			PID:         pid,
+			Runtime:     match[1],
+			ContainerID: match[2],
+			Namespaces:  processNamespaces(pid),
+			Isolated:    make([]string, 0),
+		}
+		if comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm")); err == nil {
+			process.Command = strings.TrimSpace(string(comm))
+		}
+		for _, ns := range containerNamespaces {
+			if id, ok := process.Namespaces[ns]; ok && id != hostNamespaces[ns] {
+				process.Isolated = append(process.Isolated, ns)
+			}
+		}
+		processes = append(processes, process)
+	}
+
+	data, err := json.MarshalIndent(processes, "", "  ")
+	if err != nil {
+		return l.newContainerResult(artifact, "/proc", "", fmt.Errorf("failed to marshal container processes: %w", err))
+	}
+	return l.newContainerResult(artifact, "/proc", string(data), nil)
+}
+
+// processNamespaces reads the namespace links of a process, such as
+// pid -> pid:[4026531836]. Namespaces that cannot be read are omitted.
+func processNamespaces(pid int) map[string]string {
+	namespaces := make(map[string]string)
+	for _, ns := range containerNamespaces {
+		if link, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "ns", ns)); err == nil {
+			namespaces[ns] = link
+		}
+	}
+	return namespaces
+}
+
+// runContainerTool runs a container runtime CLI. A daemon that is not
+// running or a socket the user cannot access is reported as an external
+// tool failure with the tool's own message.
+func runContainerTool(ctx context.Context, tool string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, tool, args...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if strings.Contains(strings.ToLower(message), "permission denied") {
+			return "", rterrors.Permissionf("%s %s: %s", tool, args[0], message)
+		}
+		if message == "" {
+			message = err.Error()
+		}
+		return "", rterrors.ExternalToolf("%s %s failed: %s", tool, args[0], message)
+	}
+	return string(output), nil
+}
+
+// newContainerResult builds the result for a container artifact, keeping
+// the error on the result so a failed runtime is visible in the manifest
+func (l *LinuxCollector) newContainerResult(artifact collector.Artifact, source, data string, err error) collector.ArtifactResult {
+	return collector.ArtifactResult{
+		Artifact: artifact,
+		Data:     data,
+		Metadata: collector.Metadata{
+			CollectedAt: time.Now(),
+			Collector:   "linux",
+			Version:     l.version,
+			Source:      source,
+		},
+		Size:     int64(len(data)),
+		Checksum: l.calculateChecksum(data),
+		Error:    err,
+	}
+}
@@ -492,39 +492,41 @@ func (elc *EnhancedLinuxCollector) collectUserArtifacts(results []collector.Coll
 
 // collectServiceArtifacts collects service information
 func (elc *EnhancedLinuxCollector) collectServiceArtifacts(results []collector.CollectionResult) ([]collector.CollectionResult, error) {
-	// Systemd services
-	if systemctl, err := exec.Command("systemctl", "list-units", "--type=service", "--state=running").Output(); err == nil {
-		artifact := &collector.Artifact{
-			Name:        "running_services",
-			Category:    "services",
-			Description: "Running systemd services",
-			Path:        filepath.Join(elc.baseDir, "running_services.txt"),
-		}
+	// Systemd services, skipped in containers without systemd
+	if collector.DetectEnvironment().Systemd {
+		if systemctl, err := exec.Command("systemctl", "list-units", "--type=service", "--state=running").Output(); err == nil {
+			artifact := &collector.Artifact{
+				Name:        "running_services",
+				Category:    "services",
+				Description: "Running systemd services",
+				Path:        filepath.Join(elc.baseDir, "running_services.txt"),
+			}
 
-		if err := os.WriteFile(artifact.Path, systemctl, 0644); err == nil {
-			results = append(results, collector.CollectionResult{
-				Artifact: artifact,
-				Success:  true,
-				Size:     int64(len(systemctl)),
-			})
+			if err := os.WriteFile(artifact.Path, systemctl, 0644); err == nil {
+				results = append(results, collector.CollectionResult{
+					Artifact: artifact,
+					Success:  true,
+					Size:     int64(len(systemctl)),
+				})
+			}
 		}
-	}
 
-	// Failed services
-	if failed, err := exec.Command("systemctl", "list-units", "--type=service", "--state=failed").Output(); err == nil {
-		artifact := &collector.Artifact{
-			Name:        "failed_services",
-			Category:    "services",
-			Description: "Failed systemd services",
-			Path:        filepath.Join(elc.baseDir, "failed_services.txt"),
-		}
+		// Failed services
+		if failed, err := exec.Command("systemctl", "list-units", "--type=service", "--state=failed").Output(); err == nil {
+			artifact := &collector.Artifact{
+				Name:        "failed_services",
+				Category:    "services",
+				Description: "Failed systemd services",
+				Path:        filepath.Join(elc.baseDir, "failed_services.txt"),
+			}
 
-		if err := os.WriteFile(artifact.Path, failed, 0644); err == nil {
-			results = append(results, collector.CollectionResult{
-				Artifact: artifact,
-				Success:  true,
-				Size:     int64(len(failed)),
-			})
+			if err := os.WriteFile(artifact.Path, failed, 0644); err == nil {
+				results = append(results, collector.CollectionResult{
+					Artifact: artifact,
+					Success:  true,
+					Size:     int64(len(failed)),
+				})
+			}
 		}
 	}
 
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/footprint"
 )
 
 // WindowsCollector implements ArtifactCollector for Windows systems
@@ -78,31 +79,46 @@ func (w *WindowsCollector) CollectHostProfile(ctx context.Context) (*collector.A
 func (w *WindowsCollector) CollectBasicArtifacts(ctx context.Context) ([]collector.ArtifactResult, error) {
 	var results []collector.ArtifactResult
 	
-	// Collect running processes
-	if processes, err := w.collectProcesses(); err == nil {
-		results = append(results, processes)
-	}
+	// Collect running processes; failures are recorded on the artifact
+	results = append(results, w.collectProcesses())
 	
-	// Collect running services
-	if services, err := w.collectServices(); err == nil {
-		results = append(results, services)
-	}
+	// Collect running services; a failed sc run is kept with its exit code
+	// and standard error
+	services, _ := w.collectServices()
+	results = append(results, services)
 	
 	// Collect scheduled tasks
-	if tasks, err := w.collectScheduledTasks(); err == nil {
-		results = append(results, tasks)
-	}
+	results = append(results, w.collectScheduledTasks())
+	results = append(results, w.collectScheduledTaskXML())
 	
 	// Collect network information
 	if network, err := w.collectNetworkInfo(); err == nil {
 		results = append(results, network)
 	}
+	results = append(results, w.collectNetworkConnections())
+	
+	// Collect SMB shares, sessions, mapped drives and UNC paths; failures are recorded on the artifacts
+	results = append(results, collectSMBArtifacts("windows", w.version)...)
 	
 	// Collect event logs
-	if events, err := w.collectEventLogs(); err == nil {
-		results = append(results, events)
+	results = append(results, w.collectEventLogs())
+	
+	// Collect PowerShell script block events for reassembly
+	if scriptBlocks, err := w.collectScriptBlockEvents(); err == nil {
+		results = append(results, scriptBlocks)
 	}
 	
+	// Collect Windows Defender detection events
+	if defender, err := w.collectDefenderEvents(); err == nil {
+		results = append(results, defender)
+	}
+	
+	// Collect ShimCache and Amcache execution evidence
+	results = append(results, collector.CollectLiveExecutionHistory(ctx, footprint.Current().IsMinimal())...)
+	
+	// Collect RDP history, sessions and settings and remote access tools
+	results = append(results, collector.CollectLiveRemoteAccess(ctx)...)
+	
 	return results, nil
 }
 
@@ -110,10 +126,8 @@ func (w *WindowsCollector) CollectBasicArtifacts(ctx context.Context) ([]collect
 func (w *WindowsCollector) CollectExtendedArtifacts(ctx context.Context) ([]collector.ArtifactResult, error) {
 	var results []collector.ArtifactResult
 	
-	// Collect autoruns
-	if autoruns, err := w.collectAutoruns(); err == nil {
-		results = append(results, autoruns)
-	}
+	// Sweep the registry autostart extension points
+	results = append(results, collector.CollectLiveASEPs(ctx)...)
 	
 	// Collect execution traces
 	if traces, err := w.collectExecutionTraces(); err == nil {
@@ -125,6 +139,9 @@ func (w *WindowsCollector) CollectExtendedArtifacts(ctx context.Context) ([]coll
 		results = append(results, software)
 	}
 	
+	// Collect the effective security policy; failures are recorded on the artifacts
+	results = append(results, collectPolicyArtifacts("windows", w.version)...)
+	
 	return results, nil
 }
 
@@ -134,17 +151,17 @@ func (w *WindowsCollector) getOSInfo() map[string]interface{} {
 	
 	// Get Windows version
 	if version, err := exec.Command("ver").Output(); err == nil {
-		info["version"] = strings.TrimSpace(string(version))
+		info["version"] = strings.TrimSpace(collector.DecodeText(version).Text)
 	}
 	
 	// Get Windows build info
 	if build, err := exec.Command("wmic", "os", "get", "BuildNumber", "/value").Output(); err == nil {
-		info["build"] = strings.TrimSpace(string(build))
+		info["build"] = strings.TrimSpace(collector.DecodeText(build).Text)
 	}
 	
 	// Get Windows edition
 	if edition, err := exec.Command("wmic", "os", "get", "Caption", "/value").Output(); err == nil {
-		info["edition"] = strings.TrimSpace(string(edition))
+		info["edition"] = strings.TrimSpace(collector.DecodeText(edition).Text)
 	}
 	
 	return info
@@ -167,34 +184,15 @@ func (w *WindowsCollector) getSystemInfo() map[string]interface{} {
 }
 
 // collectProcesses collects running process information
-func (w *WindowsCollector) collectProcesses() (collector.ArtifactResult, error) {
+func (w *WindowsCollector) collectProcesses() collector.ArtifactResult {
 	artifact := collector.NewBaseArtifact(
 		"running_processes",
 		"Currently running processes",
 		"process",
-		"command",
+		collector.ProcessListType,
 	)
 	
-	// Use tasklist to get process information
-	output, err := exec.Command("tasklist", "/FO", "CSV", "/V").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect processes: %w", err)
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     string(output),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "windows",
-			Version:     w.version,
-			Source:      "tasklist",
-		},
-		Size:     int64(len(output)),
-		Checksum: w.calculateChecksum(string(output)),
-	}
-	
-	return result, nil
+	return collectProcessList(artifact.Artifact, "windows", w.version)
 }
 
 // collectServices collects running service information
@@ -207,56 +205,48 @@ func (w *WindowsCollector) collectServices() (collector.ArtifactResult, error) {
 	)
 	
 	// Use sc query to get service information
-	output, err := exec.Command("sc", "query", "type=", "state=", "all").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect services: %w", err)
-	}
-	
+	output, run, err := runPolicyTool(exec.Command("sc", "query", "type=", "state=", "all"))
 	result := collector.ArtifactResult{
 		Artifact: artifact.Artifact,
-		Data:     string(output),
 		Metadata: collector.Metadata{
 			CollectedAt: time.Now(),
 			Collector:   "windows",
 			Version:     w.version,
 			Source:      "sc",
+			Commands:    []collector.CommandRun{run},
 		},
-		Size:     int64(len(output)),
-		Checksum: w.calculateChecksum(string(output)),
 	}
+	if err != nil {
+		result.Fail(collector.ReasonFor(err), fmt.Errorf("failed to collect services: %w", err))
+		return result, result.Error
+	}
+	result.SetText([]byte(output))
 	
 	return result, nil
 }
 
 // collectScheduledTasks collects scheduled task information
-func (w *WindowsCollector) collectScheduledTasks() (collector.ArtifactResult, error) {
+func (w *WindowsCollector) collectScheduledTasks() collector.ArtifactResult {
 	artifact := collector.NewBaseArtifact(
 		"scheduled_tasks",
 		"Scheduled tasks",
 		"task",
-		"command",
+		collector.ScheduledTaskListType,
 	)
 	
-	// Use schtasks to get scheduled task information
-	output, err := exec.Command("schtasks", "/query", "/fo", "csv", "/v").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect scheduled tasks: %w", err)
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     string(output),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "windows",
-			Version:     w.version,
-			Source:      "schtasks",
-		},
-		Size:     int64(len(output)),
-		Checksum: w.calculateChecksum(string(output)),
-	}
+	return collectScheduledTaskList(artifact.Artifact, "windows", w.version)
+}
+
+// collectScheduledTaskXML collects the full scheduled task definitions
+func (w *WindowsCollector) collectScheduledTaskXML() collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact(
+		"scheduled_task_xml",
+		"Scheduled task XML definitions",
+		"task",
+		collector.ScheduledTaskXMLType,
+	)
 	
-	return result, nil
+	return collectScheduledTaskXML(artifact.Artifact, "windows", w.version)
 }
 
 // collectNetworkInfo collects network configuration information
@@ -268,22 +258,17 @@ func (w *WindowsCollector) collectNetworkInfo() (collector.ArtifactResult, error
 		"command",
 	)
 	
-	// Use ipconfig and netstat to get network information
+	// Use ipconfig to get network configuration; connections are collected
+	// separately as structured records
 	var networkData strings.Builder
 	
 	// Get IP configuration
 	if ipconfig, err := exec.Command("ipconfig", "/all").Output(); err == nil {
 		networkData.WriteString("=== IP Configuration ===\n")
-		networkData.Write(ipconfig)
+		networkData.WriteString(collector.DecodeText(ipconfig).Text)
 		networkData.WriteString("\n\n")
 	}
 	
-	// Get network connections
-	if netstat, err := exec.Command("netstat", "-an").Output(); err == nil {
-		networkData.WriteString("=== Network Connections ===\n")
-		networkData.Write(netstat)
-	}
-	
 	result := collector.ArtifactResult{
 		Artifact: artifact.Artifact,
 		Data:     networkData.String(),
@@ -291,7 +276,7 @@ func (w *WindowsCollector) collectNetworkInfo() (collector.ArtifactResult, error
 			CollectedAt: time.Now(),
 			Collector:   "windows",
 			Version:     w.version,
-			Source:      "ipconfig,netstat",
+			Source:      "ipconfig",
 		},
 		Size:     int64(networkData.Len()),
 		Checksum: w.calculateChecksum(networkData.String()),
@@ -300,83 +285,64 @@ func (w *WindowsCollector) collectNetworkInfo() (collector.ArtifactResult, error
 	return result, nil
 }
 
-// collectEventLogs collects recent event log entries
-func (w *WindowsCollector) collectEventLogs() (collector.ArtifactResult, error) {
+// collectNetworkConnections collects TCP connections and UDP endpoints
+func (w *WindowsCollector) collectNetworkConnections() collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact(
+		"network_connections",
+		"Active network connections and listening ports",
+		"network",
+		collector.ConnectionListType,
+	)
+	artifact.Volatile = true
+	
+	return collectConnectionList(artifact.Artifact, "windows", w.version)
+}
+
+// collectEventLogs collects recent event log entries as event XML, which
+// unlike the rendered text does not depend on the display language
+func (w *WindowsCollector) collectEventLogs() collector.ArtifactResult {
 	artifact := collector.NewBaseArtifact(
 		"event_logs",
 		"Recent event log entries",
 		"log",
-		"command",
+		collector.EventLogXMLType,
 	)
 	
-	// Use wevtutil to get recent events from key logs
-	var eventData strings.Builder
-	
-	logs := []string{"System", "Security", "Application"}
-	for _, logName := range logs {
-		if events, err := exec.Command("wevtutil", "qe", logName, "/c:100", "/f:text").Output(); err == nil {
-			eventData.WriteString(fmt.Sprintf("=== %s Log ===\n", logName))
-			eventData.Write(events)
-			eventData.WriteString("\n\n")
-		}
-	}
+	channels := []string{"System", "Security", "Application", collector.PowerShellOperationalChannel, collector.DefenderOperationalChannel}
+	return collectEventLogXML(artifact.Artifact, channels, 100, "windows", w.version)
+}
+
+// collectScriptBlockEvents collects PowerShell 4104 script block events as XML
+func (w *WindowsCollector) collectScriptBlockEvents() (collector.ArtifactResult, error) {
+	artifact := collector.NewBaseArtifact(
+		"powershell_scriptblocks",
+		"PowerShell script block logging events (4104)",
+		"log",
+		collector.EventXMLType,
+	)
 	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     eventData.String(),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "windows",
-			Version:     w.version,
-			Source:      "wevtutil",
-		},
-		Size:     int64(eventData.Len()),
-		Checksum: w.calculateChecksum(eventData.String()),
+	events, run, err := queryEventsXML(collector.PowerShellOperationalChannel, scriptBlockQuery, 1000)
+	result := newEventXMLResult(artifact.Artifact, collector.PowerShellOperationalChannel, events, run, "windows", w.version)
+	if err != nil {
+		result.Fail(collector.ReasonFor(err), err)
 	}
 	
 	return result, nil
 }
 
-// collectAutoruns collects autorun entries
-func (w *WindowsCollector) collectAutoruns() (collector.ArtifactResult, error) {
+// collectDefenderEvents collects Windows Defender detection and action events as XML
+func (w *WindowsCollector) collectDefenderEvents() (collector.ArtifactResult, error) {
 	artifact := collector.NewBaseArtifact(
-		"autoruns",
-		"System autorun entries",
-		"autorun",
-		"registry",
+		"defender_events",
+		"Windows Defender detection and action events (1116/1117)",
+		"log",
+		collector.EventXMLType,
 	)
 	
-	// Implement autorun collection from registry
-	var autorunData strings.Builder
-	
-	// Common autorun registry locations
-	autorunKeys := []string{
-		`HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`,
-		`HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`,
-		`HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`,
-		`HKCU\SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`,
-	}
-	
-	for _, key := range autorunKeys {
-		// Use reg query to get autorun entries
-		if output, err := exec.Command("reg", "query", key).Output(); err == nil {
-			autorunData.WriteString(fmt.Sprintf("=== %s ===\n", key))
-			autorunData.Write(output)
-			autorunData.WriteString("\n\n")
-		}
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     autorunData.String(),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "windows",
-			Version:     w.version,
-			Source:      "registry",
-		},
-		Size:     int64(autorunData.Len()),
-		Checksum: w.calculateChecksum(autorunData.String()),
+	events, run, err := queryEventsXML(collector.DefenderOperationalChannel, defenderDetectionQuery, 500)
+	result := newEventXMLResult(artifact.Artifact, collector.DefenderOperationalChannel, events, run, "windows", w.version)
+	if err != nil {
+		result.Fail(collector.ReasonFor(err), err)
 	}
 	
 	return result, nil
@@ -411,7 +377,7 @@ func (w *WindowsCollector) collectExecutionTraces() (collector.ArtifactResult, e
 	// Collect recent file access info
 	traceData.WriteString("\n=== Recent File Access ===\n")
 	if recent, err := exec.Command("dir", "/O:D", "/T:W", "%USERPROFILE%\\Recent", "/B").Output(); err == nil {
-		traceData.Write(recent)
+		traceData.WriteString(collector.DecodeText(recent).Text)
 	}
 	
 	result := collector.ArtifactResult{
@@ -447,16 +413,14 @@ func (w *WindowsCollector) collectInstalledSoftware() (collector.ArtifactResult,
 	
 	result := collector.ArtifactResult{
 		Artifact: artifact.Artifact,
-		Data:     string(output),
 		Metadata: collector.Metadata{
 			CollectedAt: time.Now(),
 			Collector:   "windows",
 			Version:     w.version,
 			Source:      "wmic",
 		},
-		Size:     int64(len(output)),
-		Checksum: w.calculateChecksum(string(output)),
 	}
+	result.SetText(output)
 	
 	return result, nil
 }
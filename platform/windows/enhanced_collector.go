@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +39,7 @@ func (e *EnhancedWindowsCollector) CollectEnhancedArtifacts(ctx context.Context,
 		} else {
 			// Log error but continue with other artifacts
 			fmt.Printf("Warning: Failed to collect volatile artifact %s: %v\n", artifact.Name, err)
+			results = append(results, e.failedResult(artifact, err))
 		}
 	}
 	
@@ -57,6 +59,7 @@ func (e *EnhancedWindowsCollector) CollectEnhancedArtifacts(ctx context.Context,
 						results = append(results, result)
 					} else {
 						fmt.Printf("Warning: Failed to collect artifact %s: %v\n", artifact.Name, err)
+						results = append(results, e.failedResult(artifact, err))
 					}
 				}
 			}
@@ -66,12 +69,32 @@ func (e *EnhancedWindowsCollector) CollectEnhancedArtifacts(ctx context.Context,
 	return results, nil
 }
 
+// failedResult records an artifact that could not be collected, with the
+// reason, so reports show it as missing rather than leaving it out
+func (e *EnhancedWindowsCollector) failedResult(artifact collector.EnhancedArtifact, err error) collector.ArtifactResult {
+	result := collector.ArtifactResult{
+		Artifact: artifact.Artifact,
+		Metadata: collector.Metadata{
+			CollectedAt: time.Now(),
+			Collector:   "enhanced_windows",
+			Version:     e.version,
+			Source:      artifact.ForensicType,
+			Tags:        map[string]string{"error": err.Error()},
+		},
+	}
+	result.Fail(collector.ReasonFor(err), err)
+	return result
+}
+
 // collectEnhancedArtifact collects a single enhanced artifact
 func (e *EnhancedWindowsCollector) collectEnhancedArtifact(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	switch artifact.ForensicType {
 	case "memory_analysis":
 		return e.collectMemoryDump(ctx, artifact)
 	case "registry_analysis":
+		if artifact.Name == "autostart_entries" {
+			return e.collectAutostartEntries(ctx, artifact)
+		}
 		return e.collectRegistryHives(ctx, artifact)
 	case "file_analysis":
 		return e.collectFileMetadata(ctx, artifact)
@@ -87,6 +110,8 @@ func (e *EnhancedWindowsCollector) collectEnhancedArtifact(ctx context.Context,
 		return e.collectDeviceArtifacts(ctx, artifact)
 	case "timeline_analysis":
 		return e.collectTimelineData(ctx, artifact)
+	case "policy_analysis":
+		return e.collectPolicyArtifact(ctx, artifact)
 	default:
 		return collector.ArtifactResult{}, fmt.Errorf("unknown forensic type: %s", artifact.ForensicType)
 	}
@@ -189,6 +214,18 @@ func (e *EnhancedWindowsCollector) collectFileMetadata(ctx context.Context, arti
 	return result, nil
 }
 
+// collectAutostartEntries sweeps the registry autostart extension points
+func (e *EnhancedWindowsCollector) collectAutostartEntries(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
+	results := collector.CollectLiveASEPs(ctx)
+	if len(results) == 0 {
+		return collector.ArtifactResult{}, fmt.Errorf("failed to sweep autostart entries: no registry on this platform")
+	}
+	result := results[0]
+	result.Artifact = artifact.Artifact
+	result.Metadata.Version = e.version
+	return result, nil
+}
+
 // collectExecutionArtifacts collects execution-related artifacts
 func (e *EnhancedWindowsCollector) collectExecutionArtifacts(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	switch artifact.Name {
@@ -256,6 +293,17 @@ func (e *EnhancedWindowsCollector) collectNetworkArtifacts(ctx context.Context,
 		return e.collectARPCache(ctx, artifact)
 	case "dns_cache":
 		return e.collectDNSCache(ctx, artifact)
+	case "smb_shares":
+		result, _ := collectSMBShares("enhanced_windows", e.version)
+		return result, nil
+	case "smb_activity":
+		// Open files are resolved against the shares' paths
+		_, shares := collectSMBShares("enhanced_windows", e.version)
+		return collectSMBActivity(shares, "enhanced_windows", e.version), nil
+	case "mapped_drives":
+		return collectMappedDrives("enhanced_windows", e.version), nil
+	case "unc_history":
+		return collectUNCHistory("enhanced_windows", e.version), nil
 	default:
 		return collector.ArtifactResult{}, fmt.Errorf("unknown network artifact: %s", artifact.Name)
 	}
@@ -263,79 +311,50 @@ func (e *EnhancedWindowsCollector) collectNetworkArtifacts(ctx context.Context,
 
 // collectNetworkConnections collects active network connections
 func (e *EnhancedWindowsCollector) collectNetworkConnections(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
-	var networkData strings.Builder
-	
-	// Get active connections with process information
-	if output, err := exec.Command("netstat", "-ano").Output(); err == nil {
-		networkData.WriteString("=== Active Network Connections ===\n")
-		networkData.Write(output)
-		networkData.WriteString("\n")
-	}
-	
-	// Get listening ports
-	if output, err := exec.Command("netstat", "-an").Output(); err == nil {
-		networkData.WriteString("=== Listening Ports ===\n")
-		networkData.Write(output)
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     networkData.String(),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "enhanced_windows",
-			Version:     e.version,
-			Source:      "network_analysis",
-		},
-		Size:     int64(networkData.Len()),
-		Checksum: "",
-	}
-	
-	return result, nil
+	result := collectConnectionList(artifact.Artifact, "enhanced_windows", e.version)
+	return result, result.Error
 }
 
 // collectARPCache collects ARP cache
 func (e *EnhancedWindowsCollector) collectARPCache(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
-	output, err := exec.Command("arp", "-a").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect ARP cache: %w", err)
-	}
-	
+	output, run, err := runPolicyTool(exec.Command("arp", "-a"))
 	result := collector.ArtifactResult{
 		Artifact: artifact.Artifact,
-		Data:     string(output),
 		Metadata: collector.Metadata{
 			CollectedAt: time.Now(),
 			Collector:   "enhanced_windows",
 			Version:     e.version,
 			Source:      "network_analysis",
+			Commands:    []collector.CommandRun{run},
 		},
-		Size:     int64(len(output)),
-		Checksum: "",
 	}
+	if err != nil {
+		result.Fail(collector.ReasonFor(err), fmt.Errorf("failed to collect ARP cache: %w", err))
+		return result, result.Error
+	}
+	result.SetText([]byte(output))
 	
 	return result, nil
 }
 
 // collectDNSCache collects DNS cache
 func (e *EnhancedWindowsCollector) collectDNSCache(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
-	output, err := exec.Command("ipconfig", "/displaydns").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect DNS cache: %w", err)
-	}
-	
+	output, run, err := runPolicyTool(exec.Command("ipconfig", "/displaydns"))
 	result := collector.ArtifactResult{
 		Artifact: artifact.Artifact,
-		Data:     string(output),
 		Metadata: collector.Metadata{
 			CollectedAt: time.Now(),
 			Collector:   "enhanced_windows",
 			Version:     e.version,
 			Source:      "network_analysis",
+			Commands:    []collector.CommandRun{run},
 		},
-		Size:     int64(len(output)),
-		Checksum: "",
 	}
+	if err != nil {
+		result.Fail(collector.ReasonFor(err), fmt.Errorf("failed to collect DNS cache: %w", err))
+		return result, result.Error
+	}
+	result.SetText([]byte(output))
 	
 	return result, nil
 }
@@ -349,11 +368,30 @@ func (e *EnhancedWindowsCollector) collectLogArtifacts(ctx context.Context, arti
 		return e.collectPowerShellLogs(ctx, artifact)
 	case "sysmon_logs":
 		return e.collectSysmonLogs(ctx, artifact)
+	case "powershell_scriptblocks", "defender_logs":
+		return e.collectEventXML(ctx, artifact)
 	default:
 		return collector.ArtifactResult{}, fmt.Errorf("unknown log artifact: %s", artifact.Name)
 	}
 }
 
+// collectPolicyArtifact collects one security policy artifact. Tool failures
+// are recorded on the result so non-elevated runs still report them.
+func (e *EnhancedWindowsCollector) collectPolicyArtifact(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
+	switch artifact.Name {
+	case "group_policy":
+		return collectGroupPolicy("enhanced_windows", e.version), nil
+	case "security_policy":
+		return collectSecurityPolicy("enhanced_windows", e.version), nil
+	case "audit_policy":
+		return collectAuditPolicy("enhanced_windows", e.version), nil
+	case "local_accounts":
+		return collectLocalAccounts("enhanced_windows", e.version), nil
+	default:
+		return collector.ArtifactResult{}, fmt.Errorf("unknown policy artifact: %s", artifact.Name)
+	}
+}
+
 // collectPowerShellLogs collects PowerShell logs
 func (e *EnhancedWindowsCollector) collectPowerShellLogs(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	var psData strings.Builder
@@ -375,7 +413,7 @@ func (e *EnhancedWindowsCollector) collectPowerShellLogs(ctx context.Context, ar
 	
 	// Check PowerShell execution policy
 	if output, err := exec.Command("powershell", "-Command", "Get-ExecutionPolicy").Output(); err == nil {
-		psData.WriteString(fmt.Sprintf("Execution Policy: %s", strings.TrimSpace(string(output))))
+		psData.WriteString(fmt.Sprintf("Execution Policy: %s", strings.TrimSpace(collector.DecodeText(output).Text)))
 	}
 	
 	result := collector.ArtifactResult{
@@ -401,11 +439,27 @@ func (e *EnhancedWindowsCollector) collectUserActivityArtifacts(ctx context.Cont
 		return e.collectBrowserHistory(ctx, artifact)
 	case "email_clients":
 		return e.collectEmailClients(ctx, artifact)
+	case "rdp_history", "rdp_sessions", "rdp_cache", "rdp_settings", "remote_tools":
+		return e.collectRemoteAccess(ctx, artifact)
 	default:
 		return collector.ArtifactResult{}, fmt.Errorf("unknown user activity artifact: %s", artifact.Name)
 	}
 }
 
+// collectRemoteAccess collects one of the RDP and remote access tool
+// artifacts. They are gathered together, so the others are dropped.
+func (e *EnhancedWindowsCollector) collectRemoteAccess(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
+	for _, result := range collector.CollectLiveRemoteAccess(ctx) {
+		if result.Artifact.Name != artifact.Name {
+			continue
+		}
+		result.Artifact = artifact.Artifact
+		result.Metadata.Version = e.version
+		return result, result.Error
+	}
+	return collector.ArtifactResult{}, fmt.Errorf("failed to collect %s: no remote access artifacts on this platform", artifact.Name)
+}
+
 // collectBrowserHistory collects browser history
 func (e *EnhancedWindowsCollector) collectBrowserHistory(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	var browserData strings.Builder
@@ -473,14 +527,14 @@ func (e *EnhancedWindowsCollector) collectUSBDevices(ctx context.Context, artifa
 	// Use WMI to get USB device information
 	if output, err := exec.Command("wmic", "usbcontroller", "get", "name,deviceid", "/format:csv").Output(); err == nil {
 		usbData.WriteString("USB Controllers:\n")
-		usbData.Write(output)
+		usbData.WriteString(collector.DecodeText(output).Text)
 		usbData.WriteString("\n")
 	}
 	
 	// Get USB storage devices
 	if output, err := exec.Command("wmic", "diskdrive", "where", "interfacetype='USB'", "get", "caption,size,serialnumber", "/format:csv").Output(); err == nil {
 		usbData.WriteString("USB Storage Devices:\n")
-		usbData.Write(output)
+		usbData.WriteString(collector.DecodeText(output).Text)
 	}
 	
 	result := collector.ArtifactResult{
@@ -532,7 +586,9 @@ func (e *EnhancedWindowsCollector) checkDependencies(artifact collector.Enhanced
 	
 	collectedNames := make(map[string]bool)
 	for _, result := range collectedResults {
-		collectedNames[result.Artifact.Name] = true
+		if result.Error == nil {
+			collectedNames[result.Artifact.Name] = true
+		}
 	}
 	
 	for _, dependency := range artifact.Dependencies {
@@ -547,25 +603,8 @@ func (e *EnhancedWindowsCollector) checkDependencies(artifact collector.Enhanced
 // Helper methods for other execution artifacts
 func (e *EnhancedWindowsCollector) collectScheduledTasks(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	// Enhanced scheduled task collection
-	output, err := exec.Command("schtasks", "/query", "/fo", "csv", "/v").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect scheduled tasks: %w", err)
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     string(output),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "enhanced_windows",
-			Version:     e.version,
-			Source:      "execution_analysis",
-		},
-		Size:     int64(len(output)),
-		Checksum: "",
-	}
-	
-	return result, nil
+	result := collectScheduledTaskList(artifact.Artifact, "enhanced_windows", e.version)
+	return result, result.Error
 }
 
 func (e *EnhancedWindowsCollector) collectStartupItems(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
@@ -608,56 +647,38 @@ func (e *EnhancedWindowsCollector) collectStartupItems(ctx context.Context, arti
 }
 
 func (e *EnhancedWindowsCollector) collectProcessTree(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
-	// Enhanced process tree collection
-	output, err := exec.Command("tasklist", "/FO", "CSV", "/V", "/FI", "STATUS eq RUNNING").Output()
-	if err != nil {
-		return collector.ArtifactResult{}, fmt.Errorf("failed to collect process tree: %w", err)
-	}
-	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     string(output),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "enhanced_windows",
-			Version:     e.version,
-			Source:      "execution_analysis",
-		},
-		Size:     int64(len(output)),
-		Checksum: "",
-	}
-	
-	return result, nil
+	// Enhanced process tree collection; the CIM listing carries parent PIDs
+	result := collectProcessList(artifact.Artifact, "enhanced_windows", e.version, "/FI", "STATUS eq RUNNING")
+	return result, result.Error
 }
 
 func (e *EnhancedWindowsCollector) collectEventLogs(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
 	// Enhanced event log collection
-	var eventData strings.Builder
-	logs := strings.Split(artifact.Parameters["logs"], ",")
-	
-	for _, logName := range logs {
-		logName = strings.TrimSpace(logName)
-		if events, err := exec.Command("wevtutil", "qe", logName, "/c:100", "/f:text").Output(); err == nil {
-			eventData.WriteString(fmt.Sprintf("=== %s Log ===\n", logName))
-			eventData.Write(events)
-			eventData.WriteString("\n\n")
+	var channels []string
+	for _, logName := range strings.Split(artifact.Parameters["logs"], ",") {
+		if logName = strings.TrimSpace(logName); logName != "" {
+			channels = append(channels, logName)
 		}
 	}
 	
-	result := collector.ArtifactResult{
-		Artifact: artifact.Artifact,
-		Data:     eventData.String(),
-		Metadata: collector.Metadata{
-			CollectedAt: time.Now(),
-			Collector:   "enhanced_windows",
-			Version:     e.version,
-			Source:      "log_analysis",
-		},
-		Size:     int64(eventData.Len()),
-		Checksum: "",
+	result := collectEventLogXML(artifact.Artifact, channels, 100, "enhanced_windows", e.version)
+	return result, result.Error
+}
+
+// collectEventXML exports the artifact's event channel as XML for the detector
+func (e *EnhancedWindowsCollector) collectEventXML(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
+	channel := artifact.Parameters["channel"]
+	count, err := strconv.Atoi(artifact.Parameters["max_events"])
+	if err != nil {
+		count = 1000
 	}
 	
-	return result, nil
+	events, run, err := queryEventsXML(channel, artifact.Parameters["query"], count)
+	if err != nil {
+		return collector.ArtifactResult{}, err
+	}
+	
+	return newEventXMLResult(artifact.Artifact, channel, events, run, "enhanced_windows", e.version), nil
 }
 
 func (e *EnhancedWindowsCollector) collectSysmonLogs(ctx context.Context, artifact collector.EnhancedArtifact) (collector.ArtifactResult, error) {
@@ -666,18 +687,22 @@ func (e *EnhancedWindowsCollector) collectSysmonLogs(ctx context.Context, artifa
 	sysmonData.WriteString("=== Sysmon Logs ===\n")
 	
 	// Check if Sysmon is installed and running
-	if output, err := exec.Command("sc", "query", "SysmonDrv").Output(); err == nil {
+	output, driverRun, err := collector.RunCommand(exec.Command("sc", "query", "SysmonDrv"))
+	if err == nil {
 		sysmonData.WriteString("Sysmon Driver Status:\n")
-		sysmonData.Write(output)
+		sysmonData.WriteString(collector.DecodeText(output).Text)
 		sysmonData.WriteString("\n")
 	}
 	
-	// Try to get Sysmon events
-	if events, err := exec.Command("wevtutil", "qe", "Microsoft-Windows-Sysmon/Operational", "/c:50", "/f:text").Output(); err == nil {
+	// Try to get Sysmon events; a missing channel means Sysmon is not
+	// installed, which is not the same as being denied its events
+	events, eventsRun, eventsErr := collector.RunCommand(exec.Command("wevtutil", "qe", "Microsoft-Windows-Sysmon/Operational", "/c:50", "/f:text"))
+	if eventsErr == nil {
 		sysmonData.WriteString("Recent Sysmon Events:\n")
-		sysmonData.Write(events)
+		sysmonData.WriteString(collector.DecodeText(events).Text)
 	} else {
-		sysmonData.WriteString("Sysmon events not available or Sysmon not installed\n")
+		eventsErr = wevtutilError("Microsoft-Windows-Sysmon/Operational", eventsRun, eventsErr)
+		sysmonData.WriteString(fmt.Sprintf("Sysmon events not available: %v\n", eventsErr))
 	}
 	
 	result := collector.ArtifactResult{
@@ -688,10 +713,14 @@ func (e *EnhancedWindowsCollector) collectSysmonLogs(ctx context.Context, artifa
 			Collector:   "enhanced_windows",
 			Version:     e.version,
 			Source:      "log_analysis",
+			Commands:    []collector.CommandRun{driverRun, eventsRun},
 		},
 		Size:     int64(sysmonData.Len()),
 		Checksum: "",
 	}
+	if eventsErr != nil {
+		result.Fail(collector.ReasonFor(eventsErr), eventsErr)
+	}
 	
 	return result, nil
 }
@@ -748,14 +777,14 @@ func (e *EnhancedWindowsCollector) collectPrintSpooler(ctx context.Context, arti
 	// Get print spooler service status
 	if output, err := exec.Command("sc", "query", "Spooler").Output(); err == nil {
 		printData.WriteString("Spooler Service Status:\n")
-		printData.Write(output)
+		printData.WriteString(collector.DecodeText(output).Text)
 		printData.WriteString("\n")
 	}
 	
 	// Get printer information
 	if output, err := exec.Command("wmic", "printer", "get", "name,portname,drivername", "/format:csv").Output(); err == nil {
 		printData.WriteString("Installed Printers:\n")
-		printData.Write(output)
+		printData.WriteString(collector.DecodeText(output).Text)
 	}
 	
 	result := collector.ArtifactResult{
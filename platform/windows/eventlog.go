@@ -0,0 +1,80 @@
+package windows
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// XPath queries for the events the detector parses from XML
+const (
+	scriptBlockQuery       = "*[System[(EventID=4104)]]"
+	defenderDetectionQuery = "*[System[(EventID=1116 or EventID=1117)]]"
+)
+
+// queryEventsXML exports the newest events from a channel as event XML and
+// returns the wevtutil run
+func queryEventsXML(channel, query string, count int) (string, collector.CommandRun, error) {
+	args := []string{"qe", channel, "/rd:true", fmt.Sprintf("/c:%d", count), "/f:xml"}
+	if query != "" {
+		args = append(args, "/q:"+query)
+	}
+
+	output, run, err := collector.RunCommand(exec.Command("wevtutil", args...))
+	if err != nil {
+		return "", run, wevtutilError(channel, run, err)
+	}
+
+	return collector.DecodeText(output).Text, run, nil
+}
+
+// wevtutilError classifies a failed wevtutil run by what it wrote to stderr:
+// a channel that does not exist, such as Sysmon's where Sysmon is not
+// installed, is told apart from one the caller may not read
+func wevtutilError(channel string, run collector.CommandRun, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		message := run.Stderr
+		lower := strings.ToLower(message)
+		switch {
+		case strings.Contains(lower, "could not be found"), strings.Contains(lower, "does not exist"):
+			return rterrors.NotFoundf("channel %s is not present: %s", channel, message)
+		case strings.Contains(lower, "access is denied"):
+			return rterrors.Permissionf("channel %s cannot be read: %s", channel, message)
+		}
+	}
+	return fmt.Errorf("failed to query %s: %w", channel, err)
+}
+
+// newEventXMLResult wraps exported event XML in an artifact result tagged
+// with its channel and the wevtutil run so the detector can parse it
+func newEventXMLResult(artifact collector.Artifact, channel, data string, run collector.CommandRun, collectorName, version string) collector.ArtifactResult {
+	artifact.Type = collector.EventXMLType
+	if artifact.Parameters == nil {
+		artifact.Parameters = make(map[string]string)
+	}
+	artifact.Parameters["channel"] = channel
+
+	hash := sha256.Sum256([]byte(data))
+
+	return collector.ArtifactResult{
+		Artifact: artifact,
+		Data:     data,
+		Metadata: collector.Metadata{
+			CollectedAt: time.Now(),
+			Collector:   collectorName,
+			Version:     version,
+			Source:      channel,
+			Commands:    []collector.CommandRun{run},
+		},
+		Size:     int64(len(data)),
+		Checksum: hex.EncodeToString(hash[:]),
+	}
+}
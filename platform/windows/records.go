@@ -0,0 +1,218 @@
+package windows
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// processListScript lists processes from Win32_Process as JSON. Owners come
+// from Get-Process -IncludeUserName, which needs elevation; without it the
+// user is left empty. Each executable's Authenticode signature is checked
+// once; where Get-AuthenticodeSignature is unavailable, as in constrained
+// language mode, the signature fields are left empty.
+const processListScript = `$owners = @{}
+Get-Process -IncludeUserName -ErrorAction SilentlyContinue | ForEach-Object { $owners[$_.Id] = $_.UserName }
+$canVerify = [bool](Get-Command Get-AuthenticodeSignature -ErrorAction SilentlyContinue)
+$signatures = @{}
+$processes = @(Get-CimInstance Win32_Process | ForEach-Object {
+  $path = $_.ExecutablePath
+  if ($canVerify -and $path -and -not $signatures.ContainsKey($path)) {
+    $signatures[$path] = $null
+    try {
+      $sig = Get-AuthenticodeSignature -LiteralPath $path -ErrorAction Stop
+      $signer = ''
+      if ($sig.SignerCertificate) { $signer = $sig.SignerCertificate.Subject }
+      $signatures[$path] = @{ Status = $sig.Status.ToString(); Signer = $signer }
+    } catch {}
+  }
+  $sig = $null
+  if ($path) { $sig = $signatures[$path] }
+  [pscustomobject]@{
+    Name = $_.Name
+    ProcessId = $_.ProcessId
+    ParentProcessId = $_.ParentProcessId
+    SessionId = $_.SessionId
+    WorkingSetKB = [int64]($_.WorkingSetSize / 1KB)
+    UserName = $owners[[int]$_.ProcessId]
+    CommandLine = $_.CommandLine
+    ExecutablePath = $path
+    SignatureStatus = $(if ($sig) { $sig.Status } else { '' })
+    Signer = $(if ($sig) { $sig.Signer } else { '' })
+  }
+})
+ConvertTo-Json -InputObject $processes -Compress`
+
+// connectionListScript lists TCP connections and UDP endpoints as JSON. The
+// state is converted to its name because Windows PowerShell serializes enums
+// as numbers.
+const connectionListScript = `$tcp = @(Get-NetTCPConnection -ErrorAction SilentlyContinue | ForEach-Object {
+  [pscustomobject]@{ Protocol = 'TCP'; LocalAddress = $_.LocalAddress; LocalPort = $_.LocalPort; RemoteAddress = $_.RemoteAddress; RemotePort = $_.RemotePort; State = $_.State.ToString(); OwningProcess = $_.OwningProcess }
+})
+$udp = @(Get-NetUDPEndpoint -ErrorAction SilentlyContinue | ForEach-Object {
+  [pscustomobject]@{ Protocol = 'UDP'; LocalAddress = $_.LocalAddress; LocalPort = $_.LocalPort; RemoteAddress = ''; RemotePort = 0; State = ''; OwningProcess = $_.OwningProcess }
+})
+ConvertTo-Json -InputObject ($tcp + $udp) -Compress`
+
+// scheduledTaskListScript lists scheduled tasks as JSON with run times in
+// UTC ISO 8601, so they do not depend on the regional date format
+const scheduledTaskListScript = `$tasks = @(Get-ScheduledTask | ForEach-Object {
+  $info = $_ | Get-ScheduledTaskInfo -ErrorAction SilentlyContinue
+  [pscustomobject]@{
+    TaskName = $_.TaskPath + $_.TaskName
+    State = $_.State.ToString()
+    Author = $_.Author
+    RunAs = $_.Principal.UserId
+    Actions = @($_.Actions | ForEach-Object { (@($_.Execute, $_.Arguments) -join ' ').Trim() })
+    LastRunTime = if ($info -and $info.LastRunTime) { $info.LastRunTime.ToUniversalTime().ToString('o') } else { '' }
+    NextRunTime = if ($info -and $info.NextRunTime) { $info.NextRunTime.ToUniversalTime().ToString('o') } else { '' }
+    LastResult = if ($info) { [int64]$info.LastTaskResult } else { 0 }
+  }
+})
+ConvertTo-Json -InputObject $tasks -Depth 3 -Compress`
+
+// collectProcessList collects running processes as CIM JSON, falling back to
+// tasklist when PowerShell cannot run
+func collectProcessList(artifact collector.Artifact, collectorName, version string, filters ...string) collector.ArtifactResult {
+	return collectWithFallback(artifact, collectorName, version,
+		recordSource{collector.ProcessListType, "powershell", powerShell(processListScript)},
+		recordSource{collector.TasklistCSVType, "tasklist", exec.Command("tasklist", append([]string{"/FO", "CSV", "/V"}, filters...)...)},
+	)
+}
+
+// collectConnectionList collects TCP connections and UDP endpoints with their
+// owning processes, falling back to netstat
+func collectConnectionList(artifact collector.Artifact, collectorName, version string) collector.ArtifactResult {
+	return collectWithFallback(artifact, collectorName, version,
+		recordSource{collector.ConnectionListType, "powershell", powerShell(connectionListScript)},
+		recordSource{collector.NetstatTextType, "netstat", exec.Command("netstat", "-ano")},
+	)
+}
+
+// collectScheduledTaskList collects scheduled tasks, falling back to schtasks
+func collectScheduledTaskList(artifact collector.Artifact, collectorName, version string) collector.ArtifactResult {
+	return collectWithFallback(artifact, collectorName, version,
+		recordSource{collector.ScheduledTaskListType, "powershell", powerShell(scheduledTaskListScript)},
+		recordSource{collector.SchtasksCSVType, "schtasks", exec.Command("schtasks", "/query", "/fo", "csv", "/v")},
+	)
+}
+
+// collectScheduledTaskXML reads the task definitions from the Tasks
+// directory. Without elevation the definitions of other users' tasks cannot
+// be read; when none can, 'schtasks /query /xml ONE' exports those the
+// caller may see.
+func collectScheduledTaskXML(artifact collector.Artifact, collectorName, version string) collector.ArtifactResult {
+	artifact.Type = collector.ScheduledTaskXMLType
+
+	root := os.Getenv("SystemRoot")
+	if root == "" {
+		root = `C:\Windows`
+	}
+	dir := filepath.Join(root, "System32", "Tasks")
+
+	data, skipped, err := collector.ReadTaskDefinitions(dir, collector.WalkScope{})
+	if err == nil {
+		result := newPolicyResult(artifact, dir, data, nil, collectorName, version)
+		if skipped > 0 {
+			result.Metadata.Tags["unreadable"] = fmt.Sprintf("%d task files", skipped)
+		}
+		return result
+	}
+
+	output, run, schtasksErr := runPolicyTool(exec.Command("schtasks", "/query", "/xml", "ONE"))
+	result := newPolicyResult(artifact, "schtasks", output, schtasksErr, collectorName, version, run)
+	if schtasksErr == nil {
+		result.Metadata.Tags["fallback"] = fmt.Sprintf("%s: %v", dir, err)
+	}
+	return result
+}
+
+// collectEventLogXML exports the newest events of each channel as event XML.
+// Channels that cannot be read, such as Sysmon where it is not installed,
+// are skipped; the artifact fails only when no channel could be read.
+func collectEventLogXML(artifact collector.Artifact, channels []string, count int, collectorName, version string) collector.ArtifactResult {
+	artifact.Type = collector.EventLogXMLType
+	if artifact.Parameters == nil {
+		artifact.Parameters = make(map[string]string)
+	}
+	artifact.Parameters["channels"] = strings.Join(channels, ",")
+
+	var events strings.Builder
+	var failures, reasons []string
+	var reason collector.ReasonCode
+	var runs []collector.CommandRun
+	for _, channel := range channels {
+		output, run, err := queryEventsXML(channel, "", count)
+		runs = append(runs, run)
+		if err != nil {
+			failures = append(failures, err.Error())
+			code := collector.ReasonFor(err)
+			reasons = append(reasons, fmt.Sprintf("%s=%s", channel, code))
+			if reason == "" || code.Blind() {
+				reason = code
+			}
+			continue
+		}
+		events.WriteString(output)
+	}
+
+	var err error
+	if events.Len() == 0 && len(failures) > 0 {
+		err = fmt.Errorf("no event channel could be read: %s", strings.Join(failures, "; "))
+	}
+	result := newPolicyResult(artifact, "wevtutil", events.String(), err, collectorName, version, runs...)
+	if len(reasons) > 0 {
+		// Which channels were missing and which were denied, e.g.
+		// Microsoft-Windows-Sysmon/Operational=not_present
+		result.Metadata.Tags["channel_reasons"] = strings.Join(reasons, ",")
+	}
+	if err != nil {
+		result.Reason = reason
+	}
+	return result
+}
+
+// recordSource is one way of collecting a record artifact: the artifact
+// type its output is parsed as, and the command producing it
+type recordSource struct {
+	artifactType string
+	source       string
+	cmd          *exec.Cmd
+}
+
+// collectWithFallback runs the sources in order and keeps the first that
+// succeeds. Failures of earlier sources are recorded in the fallback tag,
+// and every run in the artifact's commands.
+func collectWithFallback(artifact collector.Artifact, collectorName, version string, sources ...recordSource) collector.ArtifactResult {
+	var failures []string
+	var runs []collector.CommandRun
+	var err error
+	for _, source := range sources {
+		var output string
+		var run collector.CommandRun
+		output, run, err = runPolicyTool(source.cmd)
+		runs = append(runs, run)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", source.source, err))
+			continue
+		}
+
+		artifact.Type = source.artifactType
+		result := newPolicyResult(artifact, source.source, output, nil, collectorName, version, runs...)
+		if len(failures) > 0 {
+			result.Metadata.Tags["fallback"] = strings.Join(failures, "; ")
+		}
+		return result
+	}
+
+	return newPolicyResult(artifact, sources[len(sources)-1].source, "", err, collectorName, version, runs...)
+}
+
+// powerShell builds a non-interactive PowerShell command for a script
+func powerShell(script string) *exec.Cmd {
+	return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+}
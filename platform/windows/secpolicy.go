@@ -0,0 +1,161 @@
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/rterrors"
+)
+
+// localAccountsScript lists local users as CSV with whether each is a member
+// of the local Administrators group (S-1-5-32-544) and whether its password
+// never expires
+const localAccountsScript = `$admins = @(Get-LocalGroupMember -SID 'S-1-5-32-544' -ErrorAction SilentlyContinue | ForEach-Object { $_.SID.Value })
+Get-LocalUser | ForEach-Object {
+  [pscustomobject]@{
+    Name = $_.Name
+    SID = $_.SID.Value
+    Enabled = $_.Enabled
+    PasswordNeverExpires = ($null -eq $_.PasswordExpires)
+    Administrator = ($admins -contains $_.SID.Value)
+  }
+} | ConvertTo-Csv -NoTypeInformation`
+
+// collectPolicyArtifacts collects the effective group policy, local security
+// policy, audit policy and local accounts. Artifacts that cannot be collected,
+// typically because the run is not elevated, are returned with their error
+// recorded instead of being dropped.
+func collectPolicyArtifacts(collectorName, version string) []collector.ArtifactResult {
+	return []collector.ArtifactResult{
+		collectGroupPolicy(collectorName, version),
+		collectSecurityPolicy(collectorName, version),
+		collectAuditPolicy(collectorName, version),
+		collectLocalAccounts(collectorName, version),
+	}
+}
+
+// collectGroupPolicy exports the resultant set of policy. The computer scope
+// needs elevation, so a non-elevated run falls back to the user scope.
+func collectGroupPolicy(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("group_policy", "Resultant set of policy (gpresult /x)", "policy", collector.GroupPolicyType).Artifact
+
+	var failures []string
+	var runs []collector.CommandRun
+	for _, scope := range []string{"computer", "user"} {
+		data, run, err := exportToTempFile("redtriage-gpresult-*.xml", func(path string) *exec.Cmd {
+			return exec.Command("gpresult", "/scope", scope, "/x", path, "/f")
+		})
+		runs = append(runs, run...)
+		if err == nil {
+			artifact.Parameters["scope"] = scope
+			return newPolicyResult(artifact, "gpresult", data, nil, collectorName, version, runs...)
+		}
+		failures = append(failures, fmt.Sprintf("%s scope: %v", scope, err))
+	}
+
+	return newPolicyResult(artifact, "gpresult", "", rterrors.Wrap(rterrors.ExternalTool, errors.New(strings.Join(failures, "; "))), collectorName, version, runs...)
+}
+
+// collectSecurityPolicy exports password, lockout and user rights settings
+// with secedit. The export is UTF-16 and is parsed by the detector.
+func collectSecurityPolicy(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("security_policy", "Local security policy (secedit /export)", "policy", collector.SecurityPolicyType).Artifact
+
+	data, runs, err := exportToTempFile("redtriage-secedit-*.inf", func(path string) *exec.Cmd {
+		return exec.Command("secedit", "/export", "/cfg", path, "/areas", "SECURITYPOLICY", "USER_RIGHTS", "/quiet")
+	})
+
+	return newPolicyResult(artifact, "secedit", data, err, collectorName, version, runs...)
+}
+
+// collectAuditPolicy reports the effective advanced audit policy as CSV
+func collectAuditPolicy(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("audit_policy", "Effective audit policy (auditpol /get /category:*)", "policy", collector.AuditPolicyType).Artifact
+
+	output, run, err := runPolicyTool(exec.Command("auditpol", "/get", "/category:*", "/r"))
+	return newPolicyResult(artifact, "auditpol", output, err, collectorName, version, run)
+}
+
+// collectLocalAccounts lists local users with administrator membership and
+// password expiry so accounts whose passwords never expire can be flagged
+func collectLocalAccounts(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("local_accounts", "Local accounts with administrator membership and password expiry", "policy", collector.LocalAccountsType).Artifact
+
+	output, run, err := runPolicyTool(exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", localAccountsScript))
+	return newPolicyResult(artifact, "powershell", output, err, collectorName, version, run)
+}
+
+// exportToTempFile runs a tool that can only write its output to a file and
+// returns the file content with the run, if the tool was started. The file
+// is always removed.
+func exportToTempFile(pattern string, command func(path string) *exec.Cmd) (string, []collector.CommandRun, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	_, run, err := runPolicyTool(command(path))
+	runs := []collector.CommandRun{run}
+	if err != nil {
+		return "", runs, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", runs, fmt.Errorf("failed to read export: %w", err)
+	}
+	return string(data), runs, nil
+}
+
+// runPolicyTool runs a policy tool and classifies failures. Access denied
+// output means the run was not elevated. The run is returned for the
+// artifact's provenance whether it succeeded or not.
+func runPolicyTool(cmd *exec.Cmd) (string, collector.CommandRun, error) {
+	output, run, err := collector.RunCommand(cmd)
+	if err == nil {
+		return string(output), run, nil
+	}
+
+	message := run.Message(output)
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, "access is denied") || strings.Contains(lower, "elevat") || strings.Contains(lower, "administrator") {
+		return "", run, rterrors.Permissionf("%s requires elevation: %s", cmd.Args[0], message)
+	}
+	if message != "" {
+		return "", run, rterrors.ExternalToolf("%s failed: %s: %w", cmd.Args[0], message, err)
+	}
+	return "", run, rterrors.Wrap(rterrors.ExternalTool, fmt.Errorf("%s failed: %w", cmd.Args[0], err))
+}
+
+// newPolicyResult wraps policy tool output in an artifact result, recording
+// the error when the tool could not run and the commands run. The output is
+// converted to UTF-8 from the encoding the tool wrote it in.
+func newPolicyResult(artifact collector.Artifact, source, data string, err error, collectorName, version string, runs ...collector.CommandRun) collector.ArtifactResult {
+	result := collector.ArtifactResult{
+		Artifact: artifact,
+		Metadata: collector.Metadata{
+			CollectedAt: time.Now(),
+			Collector:   collectorName,
+			Version:     version,
+			Source:      source,
+			Tags:        map[string]string{},
+			Commands:    runs,
+		},
+		Error:  err,
+		Reason: collector.ReasonFor(err),
+	}
+	result.SetText([]byte(data))
+	if err != nil {
+		result.Metadata.Tags["error"] = err.Error()
+	}
+
+	return result
+}
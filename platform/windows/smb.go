@@ -0,0 +1,221 @@
+package windows
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// eventDataScript defines Get-EventData, which returns the named EventData
+// fields of an event record
+const eventDataScript = `function Get-EventData($record) {
+  $data = @{}
+  foreach ($field in ([xml]$record.ToXml()).Event.EventData.Data) { $data[$field.Name] = $field.'#text' }
+  $data
+}
+`
+
+// userHivesScript defines Get-UserHives, which lists the loaded user hives
+// with the account each belongs to
+const userHivesScript = `function Get-UserHives {
+  Get-ChildItem 'Registry::HKEY_USERS' -ErrorAction SilentlyContinue |
+    Where-Object { $_.PSChildName -match '^S-1-5-21-[\d-]+$' } |
+    ForEach-Object {
+      $sid = $_.PSChildName
+      $user = try { (New-Object System.Security.Principal.SecurityIdentifier($sid)).Translate([System.Security.Principal.NTAccount]).Value } catch { $sid }
+      [pscustomobject]@{ SID = $sid; User = $user }
+    }
+}
+`
+
+// smbSharesScript lists the shares with their permissions and the share
+// creation events (5142) logged within the lookback, in hours
+const smbSharesScript = eventDataScript + `$created = @{}
+Get-WinEvent -FilterHashtable @{ LogName = 'Security'; Id = 5142; StartTime = (Get-Date).AddHours(-%d) } -ErrorAction SilentlyContinue | ForEach-Object {
+  $data = Get-EventData $_
+  $name = $data['ShareName'] -replace '^\\\\\*\\', ''
+  if (-not $created.ContainsKey($name)) {
+    $created[$name] = @{ time = $_.TimeCreated.ToUniversalTime().ToString('o'); user = "$($data['SubjectDomainName'])\$($data['SubjectUserName'])" }
+  }
+}
+$shares = @(Get-SmbShare -ErrorAction Stop | ForEach-Object {
+  $share = $_
+  $access = @(Get-SmbShareAccess -Name $share.Name -ErrorAction SilentlyContinue | ForEach-Object {
+    [pscustomobject]@{ account = $_.AccountName; right = "$($_.AccessRight)"; type = "$($_.AccessControlType)" }
+  })
+  $creation = $created[$share.Name]
+  [pscustomobject]@{
+    name = $share.Name; path = $share.Path; description = $share.Description; special = [bool]$share.Special
+    share_type = "$($share.ShareType)"; current_users = [int]$share.CurrentUsers; access = $access
+    created_at = $(if ($creation) { $creation.time } else { '' }); created_by = $(if ($creation) { $creation.user } else { '' })
+  }
+})
+ConvertTo-Json -InputObject $shares -Depth 4 -Compress`
+
+// smbActivityScript lists the SMB sessions and open files, the accesses to
+// hidden shares (5140) logged within the lookback, in hours, and the direct
+// members of the local Administrators group
+const smbActivityScript = eventDataScript + `$admins = @(Get-LocalGroupMember -SID 'S-1-5-32-544' -ErrorAction SilentlyContinue | ForEach-Object { $_.Name })
+$sessions = @(Get-SmbSession -ErrorAction Stop | ForEach-Object {
+  [pscustomobject]@{
+    session_id = "$($_.SessionId)"; client_computer = $_.ClientComputerName; client_user = $_.ClientUserName
+    dialect = "$($_.Dialect)"; open_files = [int]$_.NumOpens; seconds = [int64]$_.SecondsExists; idle_seconds = [int64]$_.SecondsIdle
+  }
+})
+$files = @(Get-SmbOpenFile -ErrorAction SilentlyContinue | ForEach-Object {
+  [pscustomobject]@{
+    file_id = "$($_.FileId)"; session_id = "$($_.SessionId)"; client_computer = $_.ClientComputerName; client_user = $_.ClientUserName
+    path = $_.Path; share_relative_path = $_.ShareRelativePath; locks = [int]$_.Locks
+  }
+})
+$access = @(Get-WinEvent -FilterHashtable @{ LogName = 'Security'; Id = 5140; StartTime = (Get-Date).AddHours(-%d) } -MaxEvents 5000 -ErrorAction SilentlyContinue | ForEach-Object {
+  $data = Get-EventData $_
+  $share = $data['ShareName'] -replace '^\\\\\*\\', ''
+  if ($share -like '*$' -and $share -ne 'IPC$') {
+    [pscustomobject]@{
+      time = $_.TimeCreated.ToUniversalTime().ToString('o'); share = $share
+      user = "$($data['SubjectDomainName'])\$($data['SubjectUserName'])"; sid = $data['SubjectUserSid']; source = $data['IpAddress']
+    }
+  }
+})
+ConvertTo-Json -InputObject ([pscustomobject]@{ administrators = $admins; sessions = $sessions; open_files = $files; hidden_share_access = $access }) -Depth 4 -Compress`
+
+// mappedDrivesScript lists the persistent drive mappings in the Network key
+// of every loaded user hive and the current SMB mappings
+const mappedDrivesScript = userHivesScript + `$drives = @()
+foreach ($hive in Get-UserHives) {
+  Get-ChildItem "Registry::HKEY_USERS\$($hive.SID)\Network" -ErrorAction SilentlyContinue | ForEach-Object {
+    $value = Get-ItemProperty $_.PSPath
+    $drives += [pscustomobject]@{
+      source = 'registry'; user = $hive.User; sid = $hive.SID; drive = "$($_.PSChildName):"
+      remote_path = $value.RemotePath; connect_as = $value.UserName; status = 'persistent'
+    }
+  }
+}
+Get-SmbMapping -ErrorAction SilentlyContinue | ForEach-Object {
+  $drives += [pscustomobject]@{
+    source = 'net_use'; user = "$env:USERDOMAIN\$env:USERNAME"; drive = $_.LocalPath; remote_path = $_.RemotePath; status = "$($_.Status)"
+  }
+}
+ConvertTo-Json -InputObject @($drives) -Depth 3 -Compress`
+
+// uncHistoryScript lists the UNC paths in the MountPoints2 key of every
+// loaded user hive and the recent shortcuts of every profile that point at
+// a UNC path
+const uncHistoryScript = userHivesScript + `$items = @()
+foreach ($hive in Get-UserHives) {
+  Get-ChildItem "Registry::HKEY_USERS\$($hive.SID)\Software\Microsoft\Windows\CurrentVersion\Explorer\MountPoints2" -ErrorAction SilentlyContinue |
+    Where-Object { $_.PSChildName -like '##*' } | ForEach-Object {
+      $items += [pscustomobject]@{ source = 'mountpoints2'; user = $hive.User; target = '\\' + ($_.PSChildName.Substring(2) -replace '#', '\') }
+    }
+}
+$shell = New-Object -ComObject WScript.Shell
+Get-ChildItem "$env:SystemDrive\Users\*\AppData\Roaming\Microsoft\Windows\Recent\*.lnk" -Force -ErrorAction SilentlyContinue | Select-Object -First %d | ForEach-Object {
+  $target = try { $shell.CreateShortcut($_.FullName).TargetPath } catch { '' }
+  if ($target -like '\\*') {
+    $items += [pscustomobject]@{
+      source = 'lnk'; user = $_.FullName.Split('\')[2]; target = $target; shortcut = $_.FullName; last_used = $_.LastWriteTimeUtc.ToString('o')
+    }
+  }
+}
+ConvertTo-Json -InputObject @($items) -Depth 3 -Compress`
+
+// maxRecentShortcuts bounds the recent shortcuts read across all profiles
+const maxRecentShortcuts = 5000
+
+// collectSMBArtifacts collects the host's shares, its SMB sessions and open
+// files, the users' mapped drives and the UNC paths they opened. Artifacts
+// that cannot be collected are returned with their error recorded.
+func collectSMBArtifacts(collectorName, version string) []collector.ArtifactResult {
+	shares, shareList := collectSMBShares(collectorName, version)
+	return []collector.ArtifactResult{
+		shares,
+		collectSMBActivity(shareList, collectorName, version),
+		collectMappedDrives(collectorName, version),
+		collectUNCHistory(collectorName, version),
+	}
+}
+
+// collectSMBShares lists the shares with their permissions and recent
+// creation events. The shares are also returned to resolve open files.
+func collectSMBShares(collectorName, version string) (collector.ArtifactResult, []collector.SMBShare) {
+	artifact := collector.NewBaseArtifact("smb_shares", "Shares exposed by the host with permissions (Get-SmbShare)", "network", collector.SMBSharesType).Artifact
+	artifact.Parameters["lookback"] = collector.SMBLookback.String()
+
+	var shares []collector.SMBShare
+	run, err := runSMBScript(fmt.Sprintf(smbSharesScript, int(collector.SMBLookback.Hours())), &shares)
+	return newSMBResult(artifact, shares, run, err, collectorName, version), shares
+}
+
+// collectSMBActivity lists the SMB sessions and open files, each open file
+// resolved to the share that holds it, and recent hidden share accesses
+func collectSMBActivity(shares []collector.SMBShare, collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("smb_activity", "SMB sessions, open files and hidden share access (Get-SmbSession, Get-SmbOpenFile)", "network", collector.SMBActivityType).Artifact
+	artifact.Parameters["lookback"] = collector.SMBLookback.String()
+
+	var activity collector.SMBActivity
+	run, err := runSMBScript(fmt.Sprintf(smbActivityScript, int(collector.SMBLookback.Hours())), &activity)
+	for i := range activity.OpenFiles {
+		activity.OpenFiles[i].Share = collector.ShareOf(shares, activity.OpenFiles[i].Path)
+	}
+	return newSMBResult(artifact, activity, run, err, collectorName, version)
+}
+
+// collectMappedDrives lists the network drives of the users whose hives are
+// loaded, persistent and current
+func collectMappedDrives(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("mapped_drives", "Mapped network drives per user (Network registry key, net use)", "network", collector.MappedDrivesType).Artifact
+
+	var drives []collector.MappedDrive
+	run, err := runSMBScript(mappedDrivesScript, &drives)
+	for i := range drives {
+		drives[i].Server, drives[i].Share = collector.ParseUNC(drives[i].RemotePath)
+	}
+	return newSMBResult(artifact, drives, run, err, collectorName, version)
+}
+
+// collectUNCHistory lists the UNC paths users opened, from MountPoints2 and
+// recent shortcuts
+func collectUNCHistory(collectorName, version string) collector.ArtifactResult {
+	artifact := collector.NewBaseArtifact("unc_history", "UNC paths opened per user (MountPoints2, recent shortcuts)", "network", collector.UNCHistoryType).Artifact
+
+	var items []collector.UNCAccess
+	run, err := runSMBScript(fmt.Sprintf(uncHistoryScript, maxRecentShortcuts), &items)
+	for i := range items {
+		items[i].Server, items[i].Share = collector.ParseUNC(items[i].Target)
+	}
+	return newSMBResult(artifact, items, run, err, collectorName, version)
+}
+
+// runSMBScript runs a PowerShell script printing JSON and decodes its output
+// into v, returning the run
+func runSMBScript(script string, v interface{}) (collector.CommandRun, error) {
+	output, run, err := runPolicyTool(exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script))
+	if err != nil {
+		return run, err
+	}
+	text := strings.TrimSpace(collector.DecodeText([]byte(output)).Text)
+	if text == "" {
+		return run, nil
+	}
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		return run, fmt.Errorf("failed to parse PowerShell output: %w", err)
+	}
+	return run, nil
+}
+
+// newSMBResult stores v as the JSON data of an SMB artifact, recording the
+// error when the script could not run
+func newSMBResult(artifact collector.Artifact, v interface{}, run collector.CommandRun, err error, collectorName, version string) collector.ArtifactResult {
+	if err != nil {
+		return newPolicyResult(artifact, "powershell", "", err, collectorName, version, run)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return newPolicyResult(artifact, "powershell", "", fmt.Errorf("failed to marshal %s: %w", artifact.Name, err), collectorName, version, run)
+	}
+	return newPolicyResult(artifact, "powershell", string(data), nil, collectorName, version, run)
+}
@@ -0,0 +1,47 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// autostartHTML renders the autostart entries of the collection as an
+// autoruns-style table for the technical report, empty when there are none
+func autostartHTML(artifacts []collector.ArtifactResult) string {
+	var entries []collector.ASEPEntry
+	for _, artifact := range artifacts {
+		if artifact.Error == nil && artifact.Artifact.Type == collector.ASEPType {
+			entries = append(entries, collector.ASEPEntries(artifact)...)
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("    <div class=\"technical\">\n        <h2>Autostart Entries</h2>\n        <table>\n")
+	b.WriteString("            <tr><th>Location</th><th>Entry</th><th>Image</th><th>Signature</th><th>SHA-256</th><th>Key Modified</th></tr>\n")
+	for _, entry := range entries {
+		image := entry.Image
+		if entry.ImageMissing {
+			image += " (missing)"
+		}
+		signature := entry.SignatureStatus
+		if entry.Signer != "" {
+			signature += ": " + entry.Signer
+		}
+		fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(strings.ReplaceAll(entry.Group, "_", " ")),
+			html.EscapeString(entry.Name()),
+			html.EscapeString(image),
+			html.EscapeString(signature),
+			html.EscapeString(entry.SHA256),
+			entry.KeyModified.UTC().Format(time.RFC3339))
+	}
+	b.WriteString("        </table>\n    </div>\n")
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/detector"
+)
+
+// BaselineSuppressedField marks a Sigma match that was already present in
+// the baseline findings report
+const BaselineSuppressedField = "baseline_suppressed"
+
+// Baseline is the set of findings in an earlier findings report. A finding
+// is known when the baseline has one with the same rule and evidence key.
+type Baseline struct {
+	Path  string
+	known map[string]bool
+}
+
+// BaselineSummary counts the findings of a run against a baseline.
+// Resolved counts the baseline findings the run no longer found.
+type BaselineSummary struct {
+	File       string `json:"file"`
+	New        int    `json:"new"`
+	Suppressed int    `json:"suppressed"`
+	Resolved   int    `json:"resolved"`
+}
+
+// AcceptedBaseline is a baseline recorded with 'findings baseline set': the
+// finding keys of a findings run accepted as known. It is stored with an
+// incident or in the global baseline file, and can be exported and loaded
+// again with LoadBaseline.
+type AcceptedBaseline struct {
+	Source string    `json:"source"`
+	SetAt  time.Time `json:"set_at"`
+	SetBy  string    `json:"set_by"`
+	Keys   []string  `json:"keys"`
+}
+
+// Baseline returns the baseline the accepted keys describe
+func (a *AcceptedBaseline) Baseline() *Baseline {
+	baseline := &Baseline{Path: a.Source, known: make(map[string]bool, len(a.Keys))}
+	for _, key := range a.Keys {
+		baseline.known[key] = true
+	}
+	return baseline
+}
+
+// Accept records the baseline's finding keys as accepted by setBy
+func (b *Baseline) Accept(setBy string) *AcceptedBaseline {
+	keys := make([]string, 0, len(b.known))
+	for key := range b.known {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return &AcceptedBaseline{Source: b.Path, SetAt: time.Now(), SetBy: setBy, Keys: keys}
+}
+
+// LoadBaseline reads a findings report saved by the findings command, a
+// plain JSON array of its findings, the detector findings of an offline
+// analysis, or an exported accepted baseline
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var findings []map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &findings)
+		// Detector findings list their evidence, which is keyed as the
+		// matches an offline analysis makes of them
+		if _, isList := firstEvidence(findings).([]interface{}); err == nil && isList {
+			var detected []detector.Finding
+			if err = json.Unmarshal(data, &detected); err == nil {
+				findings = DetectorMatches(detected)
+			}
+		}
+	} else {
+		var report struct {
+			Findings []map[string]interface{} `json:"findings"`
+			Keys     []string                 `json:"keys"`
+		}
+		err = json.Unmarshal(data, &report)
+		if err == nil && report.Findings == nil && report.Keys != nil {
+			accepted := AcceptedBaseline{Source: path, Keys: report.Keys}
+			return accepted.Baseline(), nil
+		}
+		findings = report.Findings
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+
+	baseline := &Baseline{Path: path, known: make(map[string]bool, len(findings))}
+	for _, finding := range findings {
+		baseline.known[FindingKey(finding)] = true
+	}
+	return baseline, nil
+}
+
+// firstEvidence returns the evidence of the first finding, nil when there
+// is none
+func firstEvidence(findings []map[string]interface{}) interface{} {
+	if len(findings) == 0 {
+		return nil
+	}
+	return findings[0]["evidence"]
+}
+
+// Len returns the number of distinct findings in the baseline
+func (b *Baseline) Len() int {
+	return len(b.known)
+}
+
+// Apply marks the matches already present in the baseline with
+// BaselineSuppressedField and returns the new ones
+func (b *Baseline) Apply(matches []map[string]interface{}) ([]map[string]interface{}, BaselineSummary) {
+	summary := BaselineSummary{File: b.Path}
+	var fresh []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		key := FindingKey(match)
+		if b.known[key] {
+			match[BaselineSuppressedField] = true
+			seen[key] = true
+			summary.Suppressed++
+			continue
+		}
+		fresh = append(fresh, match)
+		summary.New++
+	}
+	summary.Resolved = len(b.known) - len(seen)
+	return fresh, summary
+}
+
+// volatileEvidenceKeys change between runs on the same host without the
+// finding being different
+var volatileEvidenceKeys = map[string]bool{
+	"pid": true, "ppid": true, "process_id": true, "ProcessId": true, "ProcessID": true,
+	"local_address": true, "local_port": true, "timestamp": true, "start_time": true,
+	"create_time": true, "cpu_percent": true, "memory_percent": true, "memory": true,
+	"status": true, "state": true, "threads": true,
+}
+
+// FindingKey identifies a Sigma match across runs: its rule and the entity
+// its evidence points at. Process IDs and other values that change between
+// runs are left out, so the same process or connection seen again has the
+// same key.
+func FindingKey(match map[string]interface{}) string {
+	rule := stringValue(match["rule_id"])
+	if rule == "" {
+		rule = stringValue(match["rule_title"])
+	}
+	evidence, _ := match["evidence"].(map[string]interface{})
+	return rule + "|" + evidenceKey(evidence)
+}
+
+// evidenceKey is the process name, remote host and file path of the
+// evidence, or all its stable values when it holds none of these
+func evidenceKey(evidence map[string]interface{}) string {
+	var parts []string
+	if name := firstValue(evidence, processNameKeys); name != "" {
+		parts = append(parts, "process="+strings.ToLower(name))
+	}
+	if remote := firstValue(evidence, remoteAddrKeys); remote != "" {
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+		parts = append(parts, "remote="+remote)
+	}
+	if path := firstValue(evidence, filePathKeys); path != "" {
+		parts = append(parts, "path="+strings.ToLower(path))
+	}
+	if len(parts) > 0 {
+		return strings.Join(parts, ";")
+	}
+
+	keys := make([]string, 0, len(evidence))
+	for key := range evidence {
+		if !volatileEvidenceKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+stringValue(evidence[key]))
+	}
+	return strings.Join(parts, ";")
+}
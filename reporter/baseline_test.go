@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/detector"
+)
+
+// TestBaselineFromOfflineFindings loads the findings.json an offline
+// analysis writes as a baseline and checks a second run of the same
+// findings is suppressed and marked, while a new finding is not
+func TestBaselineFromOfflineFindings(t *testing.T) {
+	detected := []detector.Finding{
+		{RuleID: "RT001", RuleName: "Suspicious Process Names", Severity: "medium", Category: "process", Timestamp: time.Now(),
+			Evidence: []detector.Evidence{{Type: "process_name", Value: "mimikatz.exe"}}},
+		{RuleID: "RT002", RuleName: "Unusual Network Connections", Severity: "medium", Category: "network", Timestamp: time.Now(),
+			Evidence: []detector.Evidence{{Type: "remote_address", Value: "185.220.101.45:4444"}}},
+	}
+	data, err := json.Marshal(detected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "findings.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseline.Len() != 2 {
+		t.Fatalf("baseline holds %d findings, want 2", baseline.Len())
+	}
+
+	rerun := append(detected[:1:1], detector.Finding{RuleID: "RT001", RuleName: "Suspicious Process Names", Severity: "medium",
+		Category: "process", Timestamp: time.Now(), Evidence: []detector.Evidence{{Type: "process_name", Value: "rubeus.exe"}}})
+	matches := DetectorMatches(rerun)
+	fresh, summary := baseline.Apply(matches)
+	if summary.New != 1 || summary.Suppressed != 1 || summary.Resolved != 1 {
+		t.Errorf("baseline summary %+v, want 1 new, 1 suppressed and 1 resolved", summary)
+	}
+	if len(fresh) != 1 || fresh[0]["evidence"].(map[string]interface{})["process_name"] != "rubeus.exe" {
+		t.Errorf("baseline kept %v, want only rubeus.exe", fresh)
+	}
+	if suppressed, _ := matches[0][BaselineSuppressedField].(bool); !suppressed {
+		t.Error("finding already in the baseline is not marked")
+	}
+}
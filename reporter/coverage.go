@@ -0,0 +1,73 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+)
+
+// noFindingsText is what a report says when there are no findings. With
+// coverage gaps the result is incomplete, not clean, and it says so.
+func noFindingsText(gaps []detector.CoverageGap) string {
+	if len(gaps) == 0 {
+		return "No findings detected."
+	}
+	counts := make(map[string]int)
+	for _, gap := range gaps {
+		counts[strings.ToLower(gap.Reason.Label())]++
+	}
+	var reasons []string
+	for reason, count := range counts {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("No findings detected in the artifacts that could be examined. %d could not be (%s), so this is not evidence that the host is clean.",
+		len(gaps), strings.Join(reasons, ", "))
+}
+
+// notCollectedMarkdown lists the artifacts that were not collected, or not
+// in full, with the reason, or nothing when every artifact was collected
+func notCollectedMarkdown(artifacts []collector.ArtifactResult) string {
+	var b strings.Builder
+	for _, artifact := range artifacts {
+		if artifact.Reason == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("## Artifacts Not Collected in Full\n\n| Artifact | Reason | Detail |\n|---|---|---|\n")
+		}
+		detail := ""
+		if artifact.Error != nil {
+			detail = strings.NewReplacer("|", `\|`, "\n", " ").Replace(artifact.Error.Error())
+		}
+		reason := artifact.Reason.Label()
+		if artifact.Reason.Blind() {
+			reason = "**" + reason + "**"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", artifact.Artifact.Name, reason, detail)
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// statusCellHTML is the status cell of an artifact in the HTML artifact
+// table, styled by its reason code
+func statusCellHTML(artifact collector.ArtifactResult) string {
+	if artifact.Reason == "" {
+		if artifact.Error != nil {
+			return fmt.Sprintf(`<td title="%s">Failed</td>`, html.EscapeString(artifact.Error.Error()))
+		}
+		return `<td>Collected</td>`
+	}
+	title := ""
+	if artifact.Error != nil {
+		title = fmt.Sprintf(` title="%s"`, html.EscapeString(artifact.Error.Error()))
+	}
+	return fmt.Sprintf(`<td class="reason-%s"%s>%s</td>`, artifact.Reason, title, html.EscapeString(artifact.Reason.Label()))
+}
@@ -0,0 +1,95 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redtriage/redtriage/detector"
+)
+
+// utf8BOM is the byte order mark Excel needs to read a CSV file as UTF-8
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readExcelCSV checks a csv-excel export is a .csv file that starts with a
+// byte order mark and ends every line with CRLF, and returns its rows
+func readExcelCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	if filepath.Ext(path) != ".csv" {
+		t.Fatalf("csv-excel export written as %s, want a .csv file", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, utf8BOM) {
+		t.Fatalf("%s does not start with a UTF-8 byte order mark", filepath.Base(path))
+	}
+	if lines, crlf := bytes.Count(data, []byte("\n")), bytes.Count(data, []byte("\r\n")); lines == 0 || lines != crlf {
+		t.Fatalf("%s ends %d of %d lines with CRLF", filepath.Base(path), crlf, lines)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data[len(utf8BOM):])).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read back %s: %v", filepath.Base(path), err)
+	}
+	if len(rows) < 2 {
+		t.Fatalf("%s has no records", filepath.Base(path))
+	}
+	return rows
+}
+
+func TestCSVExcelExports(t *testing.T) {
+	dir := t.TempDir()
+	table := RecordTable{Name: "processes", Records: []map[string]interface{}{
+		{"name": "explorer.exe", "user": `CORP\José.Müller`, "path": `C:\Users\José.Müller\Desktop`},
+	}}
+
+	t.Run("records", func(t *testing.T) {
+		reports, err := ExportRecords([]RecordTable{table}, nil, CSVExcel, dir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows := readExcelCSV(t, reports[0].Path); rows[1][2] != `CORP\José.Müller` {
+			t.Errorf("user read back as %q", rows[1][2])
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		logPath := filepath.Join(dir, "events.json")
+		writeTestEventLog(t, logPath, 10)
+		stream := RecordStream{Name: "event_records", Path: logPath, Keys: []string{"events"}}
+		report, err := ExportRecordStream(context.Background(), stream, nil, CSVExcel, dir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		readExcelCSV(t, report.Path)
+	})
+
+	t.Run("findings", func(t *testing.T) {
+		findings := []detector.Finding{{RuleID: "RT-X", RuleName: "Accès suspect", Severity: "high", Category: "process"}}
+		reports, err := NewEnhancedReporter().ExportFindings(findings, "", CSVExcel, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows := readExcelCSV(t, reports[0].Path); rows[1][1] != "Accès suspect" {
+			t.Errorf("rule name read back as %q", rows[1][1])
+		}
+	})
+
+	t.Run("plain csv", func(t *testing.T) {
+		reports, err := ExportRecords([]RecordTable{table}, nil, "csv", filepath.Join(dir, "plain"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(reports[0].Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.HasPrefix(data, utf8BOM) || bytes.Contains(data, []byte("\r\n")) {
+			t.Error("plain csv export has a byte order mark or CRLF line endings")
+		}
+	})
+}
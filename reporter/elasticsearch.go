@@ -0,0 +1,285 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// DefaultElasticsearchIndex is the index findings are written to when none
+// is configured
+const DefaultElasticsearchIndex = "redtriage"
+
+// elasticsearchBatchSize is the number of findings sent per bulk request
+const elasticsearchBatchSize = 500
+
+// ElasticsearchTarget is an Elasticsearch or OpenSearch cluster findings are
+// indexed into. An API key takes precedence over basic authentication.
+type ElasticsearchTarget struct {
+	URL        string
+	Index      string
+	Username   string
+	Password   string
+	APIKey     string
+	MaxRetries int           // retries per bulk request (default: 3)
+	Backoff    time.Duration // first retry delay, doubled each retry (default: 500ms)
+	Client     *http.Client  // default: 30 second timeout
+}
+
+// ElasticsearchResult summarizes a bulk indexing run
+type ElasticsearchResult struct {
+	Indexed int
+	Failed  int
+	Errors  []string // first error of each failed batch or document
+}
+
+// ValidateElasticsearchURL checks that a cluster URL is an absolute http or
+// https URL
+func ValidateElasticsearchURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid Elasticsearch URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid Elasticsearch URL %q: must be http(s)://host[:port]", raw)
+	}
+	return nil
+}
+
+// ECSFinding converts a Sigma match to a document with Elastic Common
+// Schema field names. Evidence is kept under redtriage.evidence since its
+// shape depends on the artifact the rule matched, with the reference to the
+// whole record under redtriage.evidence_ref.
+func ECSFinding(finding map[string]interface{}, collectionID string, host *collector.HostIdentity) map[string]interface{} {
+	timestamp, _ := finding["timestamp"].(string)
+	if timestamp == "" {
+		timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	level := strings.ToLower(fmt.Sprint(finding["level"]))
+
+	doc := map[string]interface{}{
+		"@timestamp": timestamp,
+		"message":    finding["description"],
+		"event": map[string]interface{}{
+			"kind":     "alert",
+			"module":   "redtriage",
+			"dataset":  "redtriage.findings",
+			"category": finding["category"],
+			"severity": ecsSeverity(level),
+		},
+		"rule": map[string]interface{}{
+			"id":       finding["rule_id"],
+			"name":     finding["rule_title"],
+			"ruleset":  "sigma",
+			"severity": level,
+		},
+		"redtriage": map[string]interface{}{
+			"collection_id": collectionID,
+			"evidence":      finding["evidence"],
+		},
+	}
+	if ref := finding["evidence_ref"]; ref != nil {
+		doc["redtriage"].(map[string]interface{})["evidence_ref"] = ref
+	}
+	if host != nil {
+		ecsHost := map[string]interface{}{
+			"name":     host.Hostname,
+			"hostname": host.Hostname,
+			"id":       host.Fingerprint,
+			"os":       map[string]interface{}{"platform": host.Platform, "full": host.OSBuild},
+		}
+		if host.Domain != "" {
+			ecsHost["domain"] = host.Domain
+		}
+		if len(host.MACAddresses) > 0 {
+			ecsHost["mac"] = host.MACAddresses
+		}
+		doc["host"] = ecsHost
+	}
+	return doc
+}
+
+// ecsSeverity maps a Sigma level to the numeric event.severity, using the
+// scale of Elastic detection rules
+func ecsSeverity(level string) int {
+	switch level {
+	case "critical":
+		return 99
+	case "high":
+		return 73
+	case "medium":
+		return 47
+	case "low":
+		return 21
+	default:
+		return 0
+	}
+}
+
+// IndexFindings bulk-indexes documents into the target index. Requests that
+// fail on the network or with 429 or 5xx responses are retried with
+// exponential backoff; documents the cluster rejects are counted as failed.
+// The error is set only when no document could be indexed.
+func (t ElasticsearchTarget) IndexFindings(docs []map[string]interface{}) (ElasticsearchResult, error) {
+	var result ElasticsearchResult
+	if err := ValidateElasticsearchURL(t.URL); err != nil {
+		return result, err
+	}
+	if t.Index == "" {
+		t.Index = DefaultElasticsearchIndex
+	}
+
+	for start := 0; start < len(docs); start += elasticsearchBatchSize {
+		end := start + elasticsearchBatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		batch := docs[start:end]
+
+		body, err := t.bulkBody(batch)
+		if err != nil {
+			return result, err
+		}
+		indexed, errs, err := t.sendBulk(body)
+		if err != nil {
+			result.Failed += len(batch)
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Indexed += indexed
+		result.Failed += len(batch) - indexed
+		result.Errors = append(result.Errors, errs...)
+	}
+
+	if result.Indexed == 0 && result.Failed > 0 {
+		return result, fmt.Errorf("failed to index findings into %s: %s", t.Index, result.Errors[0])
+	}
+	return result, nil
+}
+
+// bulkBody encodes documents as a bulk API request body
+func (t ElasticsearchTarget) bulkBody(docs []map[string]interface{}) ([]byte, error) {
+	action, err := json.Marshal(map[string]interface{}{"index": map[string]string{"_index": t.Index}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bulk action: %w", err)
+	}
+
+	var body bytes.Buffer
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode finding: %w", err)
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+	return body.Bytes(), nil
+}
+
+// sendBulk posts one bulk request, retrying transient failures, and returns
+// the number of documents indexed and the errors of rejected documents
+func (t ElasticsearchTarget) sendBulk(body []byte) (int, []string, error) {
+	client := t.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	retries := t.MaxRetries
+	if retries <= 0 {
+		retries = 3
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
+	}
+	endpoint := strings.TrimSuffix(t.URL, "/") + "/_bulk"
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff << (attempt - 1))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create bulk request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		switch {
+		case t.APIKey != "":
+			req.Header.Set("Authorization", "ApiKey "+t.APIKey)
+		case t.Username != "":
+			req.SetBasicAuth(t.Username, t.Password)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("bulk request failed: %w", err)
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read bulk response: %w", err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("bulk request failed: %s", resp.Status)
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			return 0, nil, fmt.Errorf("bulk request rejected: %s: %s", resp.Status, truncateBody(data))
+		}
+		return parseBulkResponse(data)
+	}
+
+	return 0, nil, fmt.Errorf("%w (after %d retries)", lastErr, retries)
+}
+
+// parseBulkResponse counts the documents a bulk response reports as indexed
+func parseBulkResponse(data []byte) (int, []string, error) {
+	var response struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+
+	indexed := 0
+	var errs []string
+	for _, item := range response.Items {
+		for _, op := range item {
+			if op.Status >= 200 && op.Status < 300 {
+				indexed++
+			} else if len(errs) < 5 {
+				errs = append(errs, fmt.Sprintf("%s: %s", op.Error.Type, op.Error.Reason))
+			}
+		}
+	}
+	return indexed, errs, nil
+}
+
+// truncateBody shortens an error response for display
+func truncateBody(data []byte) string {
+	text := strings.TrimSpace(string(data))
+	if len(text) > 200 {
+		return text[:200] + "..."
+	}
+	return text
+}
@@ -1,10 +1,12 @@
 package reporter
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
 	"sort"
@@ -54,6 +56,7 @@ type CollectionInfo struct {
 	TotalArtifacts int     `json:"total_artifacts"`
 	TotalFindings int      `json:"total_findings"`
 	TotalLogs    int       `json:"total_logs"`
+	Host         *collector.HostIdentity `json:"host,omitempty"`
 }
 
 // NewEnhancedReporter creates a new enhanced reporter
@@ -64,70 +67,39 @@ func NewEnhancedReporter() *EnhancedReporter {
 	}
 }
 
-// GenerateEnhancedReports generates comprehensive reports in multiple formats
-func (er *EnhancedReporter) GenerateEnhancedReports(artifacts []collector.ArtifactResult, findings []detector.Finding, bundlePath string) ([]ReportInfo, error) {
-	var reports []ReportInfo
-	
+// GenerateEnhancedReports generates comprehensive reports in multiple
+// formats. Generators run concurrently and independently: a failing report
+// is recorded in its result and never prevents the others. The error is only
+// set when no report could be attempted, or is ctx.Err() when ctx is
+// cancelled.
+func (er *EnhancedReporter) GenerateEnhancedReports(ctx context.Context, artifacts []collector.ArtifactResult, findings []detector.Finding, bundlePath string) ([]ReportResult, error) {
 	// Prepare report data
-	reportData := er.prepareReportData(artifacts, findings)
+	reportData, err := er.prepareReportData(ctx, artifacts, findings)
+	if err != nil {
+		return nil, err
+	}
 	
 	// Get bundle directory
 	bundleDir := strings.TrimSuffix(bundlePath, ".zip")
 	reportsDir := filepath.Join(bundleDir, "reports")
-	
-	// Generate reports in different formats
-	formats := []string{"html", "json", "csv", "xml"}
-	
-	for _, format := range formats {
-		if reportPath, err := er.generateReportInFormat(reportData, format, reportsDir); err == nil {
-			if info, err := er.getReportInfo(reportPath); err == nil {
-				reports = append(reports, info)
-			}
-		}
-	}
-	
-	// Generate specialized reports
-	if executivePath, err := er.generateExecutiveSummary(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(executivePath); err == nil {
-			reports = append(reports, info)
-		}
-	}
-	
-	if technicalPath, err := er.generateTechnicalReport(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(technicalPath); err == nil {
-			reports = append(reports, info)
-		}
-	}
-	
-	if timelinePath, err := er.generateTimelineReport(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(timelinePath); err == nil {
-			reports = append(reports, info)
-		}
-	}
-	
-	if networkPath, err := er.generateNetworkReport(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(networkPath); err == nil {
-			reports = append(reports, info)
-		}
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create reports directory: %w", err)
 	}
-	
-	if userPath, err := er.generateUserActivityReport(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(userPath); err == nil {
-			reports = append(reports, info)
-		}
+	if host := reportData.CollectionInfo.Host; host != nil {
+		er.reportNamer.SetHost(host.Hostname)
 	}
 	
-	if securityPath, err := er.generateSecurityReport(reportData, reportsDir); err == nil {
-		if info, err := er.getReportInfo(securityPath); err == nil {
-			reports = append(reports, info)
-		}
+	results := er.runReportGenerators(ctx, er.reportGenerators(), reportData, reportsDir)
+	if err := ctx.Err(); err != nil {
+		return results, err
 	}
-	
-	return reports, nil
+	return results, nil
 }
 
-// prepareReportData prepares all data needed for report generation
-func (er *EnhancedReporter) prepareReportData(artifacts []collector.ArtifactResult, findings []detector.Finding) ReportData {
+// prepareReportData prepares all data needed for report generation. Log
+// artifacts are analyzed one at a time; once ctx is cancelled the analysis
+// stops, its temporary files are removed and ctx.Err() is returned.
+func (er *EnhancedReporter) prepareReportData(ctx context.Context, artifacts []collector.ArtifactResult, findings []detector.Finding) (ReportData, error) {
 	// Analyze logs if available
 	var logAnalysis []logging.LogAnalysisResult
 	var timeline []logging.TimelineEvent
@@ -135,29 +107,37 @@ func (er *EnhancedReporter) prepareReportData(artifacts []collector.ArtifactResu
 	
 	// Process log artifacts
 	for _, artifact := range artifacts {
-		if artifact.Artifact.Category == "log" {
-			if logData, ok := artifact.Data.(string); ok {
-				// Create temporary log file for parsing
-				if tempFile, err := er.createTempLogFile(logData); err == nil {
-					defer os.Remove(tempFile.Name())
-					defer tempFile.Close()
-					
-					if entries, err := er.logParser.ParseLogFile(tempFile.Name()); err == nil {
-						// Analyze logs
-						analysis := er.logParser.AnalyzeLogs(entries)
-						logAnalysis = append(logAnalysis, analysis...)
-						
-						// Generate timeline
-						timeline = append(timeline, er.logParser.GenerateTimeline(entries)...)
-						
-						// Detect anomalies
-						anomalies = append(anomalies, er.logParser.DetectAnomalies(entries)...)
-					}
-				}
+		if err := ctx.Err(); err != nil {
+			return ReportData{}, err
+		}
+		if artifact.Artifact.Category != "log" {
+			continue
+		}
+		logData, ok := artifact.Data.(string)
+		if !ok {
+			continue
+		}
+		analysis, err := er.analyzeLog(ctx, logData)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ReportData{}, ctx.Err()
 			}
+			continue
 		}
+		logAnalysis = append(logAnalysis, analysis.results...)
+		timeline = append(timeline, analysis.timeline...)
+		anomalies = append(anomalies, analysis.anomalies...)
 	}
 	
+	// Place ShimCache and Amcache entries among the log events
+	timeline = append(timeline, executionTimeline(artifacts)...)
+	
+	// Sort the timeline once; report generators run concurrently and only
+	// read the report data
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Timestamp.Before(timeline[j].Timestamp)
+	})
+	
 	// Prepare collection info
 	collectionInfo := CollectionInfo{
 		StartTime:      time.Now().Add(-time.Hour), // Estimate
@@ -170,6 +150,9 @@ func (er *EnhancedReporter) prepareReportData(artifacts []collector.ArtifactResu
 		TotalFindings:  len(findings),
 		TotalLogs:      len(logAnalysis),
 	}
+	if identity, ok := collector.FindHostIdentity(artifacts); ok {
+		collectionInfo.Host = &identity
+	}
 	
 	return ReportData{
 		Artifacts:      artifacts,
@@ -179,7 +162,41 @@ func (er *EnhancedReporter) prepareReportData(artifacts []collector.ArtifactResu
 		Anomalies:      anomalies,
 		Metadata:       make(map[string]interface{}),
 		CollectionInfo: collectionInfo,
+	}, nil
+}
+
+// logAnalysis is what the log parser makes of one log artifact
+type logAnalysis struct {
+	results   []logging.LogAnalysisResult
+	timeline  []logging.TimelineEvent
+	anomalies []logging.Anomaly
+}
+
+// analyzeLog parses a log through a temporary file, which is removed when
+// the analysis ends or is cancelled
+func (er *EnhancedReporter) analyzeLog(ctx context.Context, logData string) (logAnalysis, error) {
+	var analysis logAnalysis
+	tempFile, err := er.createTempLogFile(logData)
+	if err != nil {
+		return analysis, err
 	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+	
+	entries, err := er.logParser.ParseLogFile(ctx, tempFile.Name())
+	if err != nil {
+		return analysis, err
+	}
+	if analysis.results, err = er.logParser.AnalyzeLogs(ctx, entries); err != nil {
+		return analysis, err
+	}
+	if analysis.timeline, err = er.logParser.GenerateTimeline(ctx, entries); err != nil {
+		return analysis, err
+	}
+	if analysis.anomalies, err = er.logParser.DetectAnomalies(ctx, entries); err != nil {
+		return analysis, err
+	}
+	return analysis, nil
 }
 
 // createTempLogFile creates a temporary log file for parsing
@@ -222,7 +239,10 @@ func (er *EnhancedReporter) generateReportInFormat(data ReportData, format, repo
 
 // generateHTMLReport generates a comprehensive HTML report
 func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "comprehensive_report.html")
+	reportPath, err := er.reportPath(reportsDir, "comprehensive_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -263,6 +283,12 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
         .table th, .table td { padding: 12px; text-align: left; border-bottom: 1px solid #ddd; }
         .table th { background-color: #f8f9fa; font-weight: 600; }
         .table tr:hover { background-color: #f5f5f5; }
+        .reason-not_present { color: #777; }
+        .reason-access_denied { background: #ffe3e3; color: #a61e1e; font-weight: bold; }
+        .reason-tool_missing { background: #fff4d6; color: #8a5a00; font-weight: bold; }
+        .reason-timeout { background: #ffe9d6; color: #a34700; font-weight: bold; }
+        .reason-truncated { background: #e7f3fd; color: #1b5e8c; }
+        .reason-scope_denied { color: #5f3dc4; }
         .severity-badge { padding: 4px 8px; border-radius: 12px; font-size: 0.8em; font-weight: bold; }
         .severity-critical { background: #8e44ad; color: white; }
         .severity-high { background: #e74c3c; color: white; }
@@ -317,7 +343,7 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
 		data.CollectionInfo.Version)
 	
 	// Write critical findings
-	criticalFindings := er.filterFindingsBySeverity(data.Findings, "critical")
+	criticalFindings := er.filterFindingsBySeverity(data.Findings, detector.SeverityCritical)
 	if len(criticalFindings) > 0 {
 		for _, finding := range criticalFindings {
 			fmt.Fprintf(file, `
@@ -330,7 +356,7 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
                 <ul>`, finding.RuleName, finding.RuleID, finding.Category, finding.Description)
 			
 			for _, evidence := range finding.Evidence {
-				fmt.Fprintf(file, `<li>%s: %s (Confidence: %.1f%%)</li>`, evidence.Type, evidence.Description, evidence.Confidence*100)
+				fmt.Fprintf(file, `<li>%s: %s (Confidence: %.1f%%)%s</li>`, evidence.Type, evidence.Description, evidence.Confidence*100, evidenceRecordHTML(evidence.Ref, data.Artifacts))
 			}
 			
 			fmt.Fprintf(file, `</ul></div>`)
@@ -377,11 +403,6 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
             <h2>⏰ Timeline Analysis</h2>
             <div class="timeline">`)
 	
-	// Sort timeline events by timestamp
-	sort.Slice(data.Timeline, func(i, j int) bool {
-		return data.Timeline[i].Timestamp.Before(data.Timeline[j].Timestamp)
-	})
-	
 	for _, event := range data.Timeline {
 		fmt.Fprintf(file, `
                 <div class="timeline-event">
@@ -428,6 +449,7 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
                         <th>Category</th>
                         <th>Type</th>
                         <th>Size</th>
+                        <th>Status</th>
                         <th>Description</th>
                     </tr>
                 </thead>
@@ -440,31 +462,35 @@ func (er *EnhancedReporter) generateHTMLReport(data ReportData, reportsDir strin
                         <td>%s</td>
                         <td>%s</td>
                         <td>%d bytes</td>
+                        %s
                         <td>%s</td>
                     </tr>`, 
-			artifact.Artifact.Name, artifact.Artifact.Category, artifact.Artifact.Type, artifact.Size, artifact.Artifact.Description)
+			artifact.Artifact.Name, artifact.Artifact.Category, artifact.Artifact.Type, artifact.Size, statusCellHTML(artifact), artifact.Artifact.Description)
 	}
 	
 	fmt.Fprintf(file, `
                 </tbody>
             </table>
         </div>
-        
+        %s
         <div class="footer">
-            <p>Report generated by RedTriage v%s on %s</p>
+            <p>Report generated by RedTriage v%s on %s</p>%s
             <p>Professional Incident Response & Digital Forensics Tool</p>
         </div>
     </div>
 </body>
 </html>`, 
-		data.CollectionInfo.Version, time.Now().Format("2006-01-02 15:04:05"))
+		collectionScopeHTML(data.Artifacts), data.CollectionInfo.Version, time.Now().Format("2006-01-02 15:04:05"), hostFooterHTML(data.CollectionInfo.Host))
 	
 	return reportPath, nil
 }
 
 // generateJSONReport generates a JSON report
 func (er *EnhancedReporter) generateJSONReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "comprehensive_report.json")
+	reportPath, err := er.reportPath(reportsDir, "comprehensive_report.json")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -484,7 +510,10 @@ func (er *EnhancedReporter) generateJSONReport(data ReportData, reportsDir strin
 
 // generateCSVReport generates a CSV report
 func (er *EnhancedReporter) generateCSVReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "comprehensive_report.csv")
+	reportPath, err := er.reportPath(reportsDir, "comprehensive_report.csv")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -543,7 +572,10 @@ func (er *EnhancedReporter) generateCSVReport(data ReportData, reportsDir string
 
 // generateXMLReport generates an XML report
 func (er *EnhancedReporter) generateXMLReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "comprehensive_report.xml")
+	reportPath, err := er.reportPath(reportsDir, "comprehensive_report.xml")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -554,16 +586,99 @@ func (er *EnhancedReporter) generateXMLReport(data ReportData, reportsDir string
 	encoder := xml.NewEncoder(file)
 	encoder.Indent("", "  ")
 	
-	if err := encoder.Encode(data); err != nil {
+	if err := encoder.Encode(newXMLReport(data)); err != nil {
 		return "", fmt.Errorf("failed to encode XML: %w", err)
 	}
 	
 	return reportPath, nil
 }
 
+// xmlReport is the XML form of the report data. encoding/xml cannot encode
+// maps or interface values, so findings and artifacts are flattened.
+type xmlReport struct {
+	XMLName        xml.Name         `xml:"redtriage_report"`
+	CollectionInfo CollectionInfo   `xml:"collection_info"`
+	Findings       []xmlFinding     `xml:"findings>finding"`
+	Artifacts      []xmlArtifact    `xml:"artifacts>artifact"`
+	Timeline       []xmlTimelineRow `xml:"timeline>event"`
+}
+
+type xmlFinding struct {
+	RuleID        string   `xml:"rule_id,attr"`
+	Severity      string   `xml:"severity,attr"`
+	RuleName      string   `xml:"rule_name"`
+	Category      string   `xml:"category"`
+	Description   string   `xml:"description"`
+	Timestamp     string   `xml:"timestamp,omitempty"`
+	EvidenceCount int      `xml:"evidence_count"`
+	Tags          []string `xml:"tags>tag"`
+}
+
+type xmlArtifact struct {
+	Name     string `xml:"name,attr"`
+	Category string `xml:"category"`
+	Type     string `xml:"type"`
+	Size     int64  `xml:"size"`
+	Checksum string `xml:"checksum,omitempty"`
+	Error    string `xml:"error,omitempty"`
+}
+
+type xmlTimelineRow struct {
+	Timestamp   string `xml:"timestamp,attr"`
+	Source      string `xml:"source"`
+	Type        string `xml:"type"`
+	Description string `xml:"description"`
+}
+
+// newXMLReport flattens the report data for XML encoding
+func newXMLReport(data ReportData) xmlReport {
+	report := xmlReport{CollectionInfo: data.CollectionInfo}
+	
+	for _, finding := range data.Findings {
+		report.Findings = append(report.Findings, xmlFinding{
+			RuleID:        finding.RuleID,
+			Severity:      finding.Severity,
+			RuleName:      finding.RuleName,
+			Category:      finding.Category,
+			Description:   finding.Description,
+			Timestamp:     exportTimestamp(finding.Timestamp),
+			EvidenceCount: len(finding.Evidence),
+			Tags:          finding.Tags,
+		})
+	}
+	
+	for _, artifact := range data.Artifacts {
+		row := xmlArtifact{
+			Name:     artifact.Artifact.Name,
+			Category: artifact.Artifact.Category,
+			Type:     artifact.Artifact.Type,
+			Size:     artifact.Size,
+			Checksum: artifact.Checksum,
+		}
+		if artifact.Error != nil {
+			row.Error = artifact.Error.Error()
+		}
+		report.Artifacts = append(report.Artifacts, row)
+	}
+	
+	for _, event := range data.Timeline {
+		report.Timeline = append(report.Timeline, xmlTimelineRow{
+			Timestamp:   exportTimestamp(event.Timestamp),
+			Source:      event.Source,
+			Type:        event.Type,
+			Description: event.Description,
+		})
+	}
+	
+	return report
+}
+
 // generateExecutiveSummary generates an executive summary report
 func (er *EnhancedReporter) generateExecutiveSummary(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "executive_summary.html")
+	reportPath, err := er.reportPath(reportsDir, "executive_summary.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -583,6 +698,8 @@ func (er *EnhancedReporter) generateExecutiveSummary(data ReportData, reportsDir
         .finding { margin: 10px 0; padding: 10px; border-left: 4px solid #ddd; }
         .critical { border-left-color: #e74c3c; }
         .high { border-left-color: #f39c12; }
+        table { border-collapse: collapse; }
+        th, td { padding: 6px 10px; text-align: left; border-bottom: 1px solid #eee; }
     </style>
 </head>
 <body>
@@ -597,20 +714,25 @@ func (er *EnhancedReporter) generateExecutiveSummary(data ReportData, reportsDir
         <p>Total Findings: %d</p>
         <p>Critical Issues: %d</p>
         <p>High Priority Issues: %d</p>
-    </div>
+    </div>%s%s
 </body>
 </html>`, 
 		data.CollectionInfo.TotalArtifacts,
 		data.CollectionInfo.TotalFindings,
-		len(er.filterFindingsBySeverity(data.Findings, "critical")),
-		len(er.filterFindingsBySeverity(data.Findings, "high")))
+		len(er.filterFindingsBySeverity(data.Findings, detector.SeverityCritical)),
+		len(er.filterFindingsBySeverity(data.Findings, detector.SeverityHigh)),
+		keyFindingsHTML(GroupDetectorFindings(data.Findings)),
+		hostFooterHTML(data.CollectionInfo.Host))
 	
 	return reportPath, nil
 }
 
 // generateTechnicalReport generates a technical deep-dive report
 func (er *EnhancedReporter) generateTechnicalReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "technical_report.html")
+	reportPath, err := er.reportPath(reportsDir, "technical_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -642,19 +764,99 @@ func (er *EnhancedReporter) generateTechnicalReport(data ReportData, reportsDir
         <p>Platform: %s</p>
         <p>Collector: %s</p>
     </div>
-</body>
+%s%s%s</body>
 </html>`, 
 		data.CollectionInfo.TotalArtifacts,
 		data.CollectionInfo.TotalFindings,
 		data.CollectionInfo.Platform,
-		data.CollectionInfo.Collector)
+		data.CollectionInfo.Collector,
+		securityPostureHTML(detector.ExtractSecurityPosture(data.Artifacts)),
+		autostartHTML(data.Artifacts),
+		findingRecordsHTML(data.Findings, data.Artifacts))
 	
 	return reportPath, nil
 }
 
+// securityPostureHTML renders the security posture section of the technical
+// report. It is empty when no policy artifacts were collected.
+func securityPostureHTML(posture *detector.SecurityPosture) string {
+	if posture == nil {
+		return ""
+	}
+	
+	var b strings.Builder
+	b.WriteString("    <div class=\"technical\">\n        <h2>Security Posture</h2>\n")
+	
+	if len(posture.Errors) > 0 {
+		b.WriteString("        <h3>Collection Errors</h3>\n        <ul>\n")
+		for _, e := range posture.Errors {
+			fmt.Fprintf(&b, "            <li>%s</li>\n", html.EscapeString(e))
+		}
+		b.WriteString("        </ul>\n")
+	}
+	
+	if len(posture.SystemAccess) > 0 {
+		keys := make([]string, 0, len(posture.SystemAccess))
+		for key := range posture.SystemAccess {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		
+		b.WriteString("        <h3>Password and Lockout Policy</h3>\n        <table>\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td></tr>\n", html.EscapeString(key), html.EscapeString(posture.SystemAccess[key]))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(posture.UserRights) > 0 {
+		b.WriteString("        <h3>User Rights Assignments</h3>\n        <table>\n")
+		for _, assignment := range posture.UserRights {
+			names := make([]string, 0, len(assignment.Principals))
+			for _, principal := range assignment.Principals {
+				names = append(names, detector.PrincipalName(principal))
+			}
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td></tr>\n", html.EscapeString(assignment.Right), html.EscapeString(strings.Join(names, ", ")))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(posture.AuditPolicy) > 0 {
+		b.WriteString("        <h3>Audit Policy</h3>\n        <table>\n")
+		for _, setting := range posture.AuditPolicy {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td></tr>\n", html.EscapeString(setting.Subcategory), html.EscapeString(setting.Setting))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(posture.Accounts) > 0 {
+		b.WriteString("        <h3>Local Accounts</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>Name</th><th>Enabled</th><th>Administrator</th><th>Password Never Expires</th></tr>\n")
+		for _, account := range posture.Accounts {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%t</td><td>%t</td><td>%t</td></tr>\n",
+				html.EscapeString(account.Name), account.Enabled, account.Administrator, account.PasswordNeverExpires)
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(posture.AppliedGPOs) > 0 {
+		b.WriteString("        <h3>Group Policy Objects</h3>\n        <ul>\n")
+		for _, gpo := range posture.AppliedGPOs {
+			fmt.Fprintf(&b, "            <li>%s</li>\n", html.EscapeString(gpo))
+		}
+		b.WriteString("        </ul>\n")
+	}
+	
+	b.WriteString("    </div>\n")
+	return b.String()
+}
+
 // generateTimelineReport generates a timeline report
 func (er *EnhancedReporter) generateTimelineReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "timeline_report.html")
+	reportPath, err := er.reportPath(reportsDir, "timeline_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -683,11 +885,6 @@ func (er *EnhancedReporter) generateTimelineReport(data ReportData, reportsDir s
     <div class="timeline">
         <h2>Timeline Events (%d total)</h2>`, len(data.Timeline))
 	
-	// Sort timeline events
-	sort.Slice(data.Timeline, func(i, j int) bool {
-		return data.Timeline[i].Timestamp.Before(data.Timeline[j].Timestamp)
-	})
-	
 	for _, event := range data.Timeline {
 		fmt.Fprintf(file, `
         <div class="event">
@@ -708,7 +905,10 @@ func (er *EnhancedReporter) generateTimelineReport(data ReportData, reportsDir s
 
 // generateNetworkReport generates a network analysis report
 func (er *EnhancedReporter) generateNetworkReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "network_report.html")
+	reportPath, err := er.reportPath(reportsDir, "network_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -739,17 +939,114 @@ func (er *EnhancedReporter) generateNetworkReport(data ReportData, reportsDir st
         <p>Total artifacts: %d</p>
         <p>Network findings: %d</p>
     </div>
-</body>
+%s</body>
 </html>`, 
 		data.CollectionInfo.TotalArtifacts,
-		len(er.filterFindingsByCategory(data.Findings, "network")))
+		len(er.filterFindingsByCategory(data.Findings, "network")),
+		smbActivityHTML(detector.ExtractSMBOverview(data.Artifacts)))
 	
 	return reportPath, nil
 }
 
+// smbActivityHTML renders the SMB activity section of the network report.
+// It is empty when no SMB artifacts were collected.
+func smbActivityHTML(overview *detector.SMBOverview) string {
+	if overview == nil {
+		return ""
+	}
+	
+	var b strings.Builder
+	b.WriteString("    <div class=\"network\">\n        <h2>SMB Activity</h2>\n")
+	
+	if len(overview.Errors) > 0 {
+		b.WriteString("        <h3>Collection Errors</h3>\n        <ul>\n")
+		for _, e := range overview.Errors {
+			fmt.Fprintf(&b, "            <li>%s</li>\n", html.EscapeString(e))
+		}
+		b.WriteString("        </ul>\n")
+	}
+	
+	if len(overview.Shares) > 0 {
+		b.WriteString("        <h3>Shares</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>Name</th><th>Path</th><th>Permissions</th><th>Users</th><th>Created</th></tr>\n")
+		for _, share := range overview.Shares {
+			access := make([]string, 0, len(share.Access))
+			for _, entry := range share.Access {
+				access = append(access, fmt.Sprintf("%s: %s %s", entry.Account, entry.Type, entry.Right))
+			}
+			created := share.CreatedAt
+			if share.CreatedBy != "" {
+				created += " by " + share.CreatedBy
+			}
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+				html.EscapeString(share.Name), html.EscapeString(share.Path), html.EscapeString(strings.Join(access, "; ")),
+				share.CurrentUsers, html.EscapeString(created))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if activity := overview.Activity; activity != nil {
+		if len(activity.Sessions) > 0 {
+			b.WriteString("        <h3>Sessions</h3>\n        <table>\n")
+			b.WriteString("            <tr><th>Client</th><th>User</th><th>Dialect</th><th>Open Files</th><th>Connected (s)</th></tr>\n")
+			for _, session := range activity.Sessions {
+				fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%d</td></tr>\n",
+					html.EscapeString(session.ClientComputer), html.EscapeString(session.ClientUser), html.EscapeString(session.Dialect),
+					session.OpenFiles, session.Seconds)
+			}
+			b.WriteString("        </table>\n")
+		}
+		if len(activity.OpenFiles) > 0 {
+			b.WriteString("        <h3>Open Files</h3>\n        <table>\n")
+			b.WriteString("            <tr><th>Client</th><th>User</th><th>Share</th><th>Path</th></tr>\n")
+			for _, file := range activity.OpenFiles {
+				fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					html.EscapeString(file.ClientComputer), html.EscapeString(file.ClientUser), html.EscapeString(file.Share), html.EscapeString(file.Path))
+			}
+			b.WriteString("        </table>\n")
+		}
+		if len(activity.HiddenShareAccess) > 0 {
+			b.WriteString("        <h3>Hidden Share Access</h3>\n        <table>\n")
+			b.WriteString("            <tr><th>Time</th><th>Share</th><th>User</th><th>Source</th></tr>\n")
+			for _, record := range activity.HiddenShareAccess {
+				fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					html.EscapeString(record.Time), html.EscapeString(record.Share), html.EscapeString(record.User), html.EscapeString(record.Source))
+			}
+			b.WriteString("        </table>\n")
+		}
+	}
+	
+	if len(overview.Drives) > 0 {
+		b.WriteString("        <h3>Mapped Drives</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>User</th><th>Drive</th><th>Remote Path</th><th>Source</th><th>Status</th></tr>\n")
+		for _, drive := range overview.Drives {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(drive.User), html.EscapeString(drive.Drive), html.EscapeString(drive.RemotePath),
+				html.EscapeString(drive.Source), html.EscapeString(drive.Status))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(overview.UNCHistory) > 0 {
+		b.WriteString("        <h3>Recent UNC Paths</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>User</th><th>Target</th><th>Source</th><th>Last Used</th></tr>\n")
+		for _, item := range overview.UNCHistory {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(item.User), html.EscapeString(item.Target), html.EscapeString(item.Source), html.EscapeString(item.LastUsed))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	b.WriteString("    </div>\n")
+	return b.String()
+}
+
 // generateUserActivityReport generates a user activity report
 func (er *EnhancedReporter) generateUserActivityReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "user_activity_report.html")
+	reportPath, err := er.reportPath(reportsDir, "user_activity_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -780,17 +1077,125 @@ func (er *EnhancedReporter) generateUserActivityReport(data ReportData, reportsD
         <p>Total artifacts: %d</p>
         <p>User-related findings: %d</p>
     </div>
-</body>
+%s</body>
 </html>`, 
 		data.CollectionInfo.TotalArtifacts,
-		len(er.filterFindingsByCategory(data.Findings, "user")))
+		len(er.filterFindingsByCategory(data.Findings, "user")),
+		remoteAccessHTML(detector.ExtractRemoteAccessOverview(data.Artifacts)))
 	
 	return reportPath, nil
 }
 
+// remoteAccessHTML renders the Remote Access section of the user activity
+// report, or "" when no remote access artifact was collected
+func remoteAccessHTML(overview *detector.RemoteAccessOverview) string {
+	if overview == nil {
+		return ""
+	}
+	
+	var b strings.Builder
+	b.WriteString("    <div class=\"user\">\n        <h2>Remote Access</h2>\n")
+	
+	if len(overview.Errors) > 0 {
+		b.WriteString("        <h3>Collection Errors</h3>\n        <ul>\n")
+		for _, e := range overview.Errors {
+			fmt.Fprintf(&b, "            <li>%s</li>\n", html.EscapeString(e))
+		}
+		b.WriteString("        </ul>\n")
+	}
+	
+	if settings := overview.Settings; settings != nil {
+		enabled, nla := "disabled", "required"
+		if settings.Enabled {
+			enabled = fmt.Sprintf("enabled on port %d", settings.Port)
+		}
+		if !settings.NLARequired {
+			nla = "not required"
+		}
+		b.WriteString("        <h3>Remote Desktop Settings</h3>\n        <table>\n")
+		fmt.Fprintf(&b, "            <tr><th>Remote Desktop</th><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			enabled, html.EscapeString(settings.EnabledSource), exportTimestamp(settings.EnabledModified))
+		fmt.Fprintf(&b, "            <tr><th>Network Level Authentication</th><td>%s</td><td>%s</td><td></td></tr>\n",
+			nla, html.EscapeString(settings.NLASource))
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(overview.Tools) > 0 {
+		b.WriteString("        <h3>Remote Access Tools</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>Tool</th><th>Evidence</th><th>Name</th><th>Path</th><th>Installed</th><th>Logs</th></tr>\n")
+		for _, tool := range overview.Tools {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(tool.Tool), html.EscapeString(tool.Evidence), html.EscapeString(tool.Name), html.EscapeString(tool.Path),
+				exportTimestamp(tool.InstalledAt), html.EscapeString(strings.Join(tool.Logs, "; ")))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(overview.Sessions) > 0 {
+		b.WriteString("        <h3>Inbound RDP Sessions</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>Time</th><th>Event</th><th>User</th><th>Address</th><th>Session</th></tr>\n")
+		for _, session := range overview.Sessions {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%d %s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				exportTimestamp(session.Time), session.EventID, strings.ReplaceAll(session.Kind, "_", " "),
+				html.EscapeString(session.User), html.EscapeString(session.Address), html.EscapeString(session.SessionID))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(overview.History) > 0 {
+		b.WriteString("        <h3>Outbound RDP Connections</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>User</th><th>Host</th><th>Username Hint</th><th>Source</th><th>Last Write</th></tr>\n")
+		for _, connection := range overview.History {
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(connection.User), html.EscapeString(connection.Host), html.EscapeString(connection.UsernameHint),
+				html.EscapeString(connection.Source), exportTimestamp(connection.LastWrite))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	if len(overview.Cache) > 0 {
+		// The cache files themselves are bitmap tiles; per user, how many
+		// there are and when they were last written is what matters
+		type cacheSummary struct {
+			files  int
+			size   int64
+			latest time.Time
+		}
+		summaries := make(map[string]*cacheSummary)
+		var users []string
+		for _, file := range overview.Cache {
+			summary, ok := summaries[file.User]
+			if !ok {
+				summary = &cacheSummary{}
+				summaries[file.User] = summary
+				users = append(users, file.User)
+			}
+			summary.files++
+			summary.size += file.Size
+			if file.Modified.After(summary.latest) {
+				summary.latest = file.Modified
+			}
+		}
+		b.WriteString("        <h3>RDP Bitmap Cache</h3>\n        <table>\n")
+		b.WriteString("            <tr><th>User</th><th>Files</th><th>Bytes</th><th>Last Written</th></tr>\n")
+		for _, user := range users {
+			summary := summaries[user]
+			fmt.Fprintf(&b, "            <tr><td>%s</td><td>%d</td><td>%d</td><td>%s</td></tr>\n",
+				html.EscapeString(user), summary.files, summary.size, exportTimestamp(summary.latest))
+		}
+		b.WriteString("        </table>\n")
+	}
+	
+	b.WriteString("    </div>\n")
+	return b.String()
+}
+
 // generateSecurityReport generates a security incident report
 func (er *EnhancedReporter) generateSecurityReport(data ReportData, reportsDir string) (string, error) {
-	reportPath := filepath.Join(reportsDir, "security_report.html")
+	reportPath, err := er.reportPath(reportsDir, "security_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(reportPath)
 	if err != nil {
@@ -825,29 +1230,17 @@ func (er *EnhancedReporter) generateSecurityReport(data ReportData, reportsDir s
 </body>
 </html>`, 
 		data.CollectionInfo.TotalFindings,
-		len(er.filterFindingsBySeverity(data.Findings, "critical")),
-		len(er.filterFindingsBySeverity(data.Findings, "high")))
+		len(er.filterFindingsBySeverity(data.Findings, detector.SeverityCritical)),
+		len(er.filterFindingsBySeverity(data.Findings, detector.SeverityHigh)))
 	
 	return reportPath, nil
 }
 
-// Helper methods for filtering findings
-func (er *EnhancedReporter) filterFindingsBySeverity(findings []detector.Finding, minSeverity string) []detector.Finding {
-	severityLevels := map[string]int{
-		"low":      1,
-		"medium":   2,
-		"high":     3,
-		"critical": 4,
-	}
-	
-	minLevel := severityLevels[minSeverity]
-	if minLevel == 0 {
-		minLevel = 1
-	}
-	
+// filterFindingsBySeverity returns the findings of at least minSeverity
+func (er *EnhancedReporter) filterFindingsBySeverity(findings []detector.Finding, minSeverity detector.Severity) []detector.Finding {
 	var filtered []detector.Finding
 	for _, finding := range findings {
-		if level := severityLevels[finding.Severity]; level >= minLevel {
+		if finding.Level() >= minSeverity {
 			filtered = append(filtered, finding)
 		}
 	}
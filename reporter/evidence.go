@@ -0,0 +1,68 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+)
+
+// ResolveRecord returns the record a piece of evidence points at among the
+// artifacts of a collection
+func ResolveRecord(ref *collector.RecordRef, artifacts []collector.ArtifactResult) (map[string]interface{}, bool) {
+	for _, artifact := range artifacts {
+		if artifact.Error == nil && artifact.Artifact.Name == ref.Artifact {
+			return collector.RecordAt(artifact.Data, ref)
+		}
+	}
+	return nil, false
+}
+
+// evidenceRecordHTML renders the record a piece of evidence points at as a
+// collapsed section anchored by the record ID, so the report can link to
+// it. Evidence without a reference, as in findings written before
+// references were kept, renders nothing.
+func evidenceRecordHTML(ref *collector.RecordRef, artifacts []collector.ArtifactResult) string {
+	if ref == nil {
+		return ""
+	}
+	record, ok := ResolveRecord(ref, artifacts)
+	if !ok {
+		return fmt.Sprintf(` <em>(record %s is not in this collection)</em>`, html.EscapeString(ref.ID))
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(`<details id="%s"><summary><a href="#%s">Record %s</a></summary><pre>%s</pre></details>`,
+		ref.Anchor(), ref.Anchor(), html.EscapeString(ref.ID), html.EscapeString(string(data)))
+}
+
+// findingRecordsHTML lists the records the evidence of findings points at
+// for the technical report, each under its finding. A record more than one
+// finding points at is shown once and linked from the others. It is empty
+// when no evidence has a reference.
+func findingRecordsHTML(findings []detector.Finding, artifacts []collector.ArtifactResult) string {
+	var b strings.Builder
+	shown := make(map[string]bool)
+	for _, finding := range findings {
+		for _, evidence := range finding.Evidence {
+			if evidence.Ref == nil {
+				continue
+			}
+			record := fmt.Sprintf(` <a href="#%s">Record %s</a>`, evidence.Ref.Anchor(), html.EscapeString(evidence.Ref.ID))
+			if !shown[evidence.Ref.ID] {
+				record = evidenceRecordHTML(evidence.Ref, artifacts)
+				shown[evidence.Ref.ID] = true
+			}
+			fmt.Fprintf(&b, "            <li>%s %s%s</li>\n", html.EscapeString(finding.RuleID), html.EscapeString(finding.Description), record)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "    <div class=\"technical\">\n        <h2>Evidence Records</h2>\n        <ul>\n" + b.String() + "        </ul>\n    </div>\n"
+}
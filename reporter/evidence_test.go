@@ -0,0 +1,106 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+)
+
+// TestEvidenceReferencesRecords checks that finding evidence points at the record it
+// was read from instead of copying it: a detector finding's reference
+// resolves to its autostart entry and the technical report shows the record
+// under an anchor, findings written before references still render, and a
+// Sigma match keeps a snapshot small enough to leave the record behind yet
+// identifying it as the whole record did
+func TestEvidenceReferencesRecords(t *testing.T) {
+	recent := time.Now().Add(-24 * time.Hour).UTC().Truncate(time.Second)
+	entries := []collector.ASEPEntry{
+		{Group: "run", Hive: "HKLM", Key: `HKLM\Software\Microsoft\Windows\CurrentVersion\Run`, ValueName: "Vendor", Data: `C:\Program Files\Vendor\vendor.exe`, Image: `C:\Program Files\Vendor\vendor.exe`, KeyModified: recent},
+		{Group: "run", Hive: "HKCU", Key: `HKU\alice\Software\Microsoft\Windows\CurrentVersion\Run`, ValueName: "Dropper", Data: `C:\Users\Public\dropper.exe`, Image: `C:\Users\Public\dropper.exe`, KeyModified: recent, SignatureStatus: "NotSigned"},
+	}
+	artifact := collector.ASEPArtifact(entries)
+	artifacts := []collector.ArtifactResult{artifact}
+
+	findings, err := detector.NewDetector().Evaluate(artifacts)
+	if err != nil {
+		t.Fatalf("failed to evaluate autostart entries: %v", err)
+	}
+	var ref *collector.RecordRef
+	for _, finding := range findings {
+		for _, evidence := range finding.Evidence {
+			if finding.RuleID == "RT017" && evidence.Ref != nil {
+				ref = evidence.Ref
+			}
+		}
+	}
+	if ref == nil {
+		t.Fatal("the autostart finding has no record reference")
+	}
+	record, ok := ResolveRecord(ref, artifacts)
+	if !ok || record["value_name"] != "Dropper" {
+		t.Fatalf("record %s resolves to %v, want the Dropper entry", ref.ID, record)
+	}
+
+	// Findings are read back from JSON, as from a bundle, before rendering
+	data, err := json.Marshal(findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored []detector.Finding
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("failed to read findings back: %v", err)
+	}
+	report := generatedReport(t, artifacts, stored, "technical")
+	if !strings.Contains(report, `<details id="`+ref.Anchor()+`">`) || !strings.Contains(report, "<h2>Evidence Records</h2>") || !strings.Contains(report, `dropper.exe`) {
+		t.Fatalf("technical report does not show record %s under its anchor", ref.ID)
+	}
+
+	// Findings written before references were kept render without records
+	legacy := strings.ReplaceAll(string(data), `"ref":`, `"legacy_ref":`)
+	var old []detector.Finding
+	if err := json.Unmarshal([]byte(legacy), &old); err != nil {
+		t.Fatalf("failed to read findings without references: %v", err)
+	}
+	if report := generatedReport(t, artifacts, old, "technical"); strings.Contains(report, "<h2>Evidence Records</h2>") {
+		t.Fatal("findings without references rendered records")
+	}
+
+	// A Sigma match keeps a snapshot and a reference that resolves once read back
+	process := map[string]interface{}{"name": "rundll32.exe", "pid": 4242.0, "path": `C:\Users\Public\x.dll`, "command_line": "rundll32 x.dll,Start"}
+	for i := 0; i < 40; i++ {
+		process[fmt.Sprintf("module_%d", i)] = strings.Repeat("m", 64)
+	}
+	collected := map[string]interface{}{"processes": []interface{}{map[string]interface{}{"name": "System"}, process}}
+	match := map[string]interface{}{
+		"rule_id":      "sigma-process",
+		"evidence":     EvidenceSnapshot(process, []string{"cpu_percent"}),
+		"evidence_ref": collector.NewRecordRef("RT-test", "processes", "processes", 1),
+	}
+	raw, err := json.Marshal(match)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	full, _ := json.Marshal(process)
+	if len(raw) >= len(full) {
+		t.Fatalf("match with a snapshot is %d bytes, no smaller than the %d-byte record", len(raw), len(full))
+	}
+	if FindingKey(decoded) != FindingKey(map[string]interface{}{"rule_id": "sigma-process", "evidence": process}) {
+		t.Fatal("the snapshot identifies a different finding than the whole record")
+	}
+	sigmaRef := collector.DecodeRecordRef(decoded["evidence_ref"])
+	if sigmaRef == nil || sigmaRef.ID != "RT-test/processes/processes/1" {
+		t.Fatalf("match reference reads back as %+v", sigmaRef)
+	}
+	if resolved, ok := collector.RecordAt(collected, sigmaRef); !ok || resolved["command_line"] != process["command_line"] {
+		t.Fatalf("match reference %s does not resolve to the matched process", sigmaRef.ID)
+	}
+}
@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/internal/logging"
+)
+
+// executionTimeline places the ShimCache and Amcache entries of the
+// collection on the report timeline, at the time each source records.
+// Entries without a time are left out.
+func executionTimeline(artifacts []collector.ArtifactResult) []logging.TimelineEvent {
+	var events []logging.TimelineEvent
+	for _, artifact := range artifacts {
+		if artifact.Error != nil || artifact.Artifact.Type != collector.ExecutionHistoryType {
+			continue
+		}
+		for _, entry := range collector.ExecutionEntries(artifact) {
+			when, desc := entry.Time()
+			if when.IsZero() {
+				continue
+			}
+			tags := []string{"execution", entry.Source}
+			if entry.SHA1 != "" {
+				tags = append(tags, "sha1:"+entry.SHA1)
+			}
+			events = append(events, logging.TimelineEvent{
+				Timestamp:   when,
+				Source:      artifact.Artifact.Name,
+				Type:        "execution",
+				Description: fmt.Sprintf("%s: %s", desc, entry.Path),
+				Severity:    1,
+				Process:     path.Base(strings.ReplaceAll(entry.Path, `\`, "/")),
+				Tags:        tags,
+			})
+		}
+	}
+	return events
+}
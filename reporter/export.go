@@ -0,0 +1,221 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/detector"
+)
+
+// severityOrder is the order severity buckets are exported in
+var severityOrder = []string{"critical", "high", "medium", "low"}
+
+// FindingsBucket is a named group of findings exported to its own file
+type FindingsBucket struct {
+	Name     string             `json:"name"`
+	Findings []detector.Finding `json:"findings"`
+}
+
+// SplitFindings groups findings by severity or category. Empty buckets are
+// skipped; severities are ordered critical first and categories by name.
+func (er *EnhancedReporter) SplitFindings(findings []detector.Finding, splitBy string) ([]FindingsBucket, error) {
+	var buckets []FindingsBucket
+
+	switch splitBy {
+	case "severity":
+		counts := er.groupFindingsBySeverity(findings)
+
+		names := append([]string(nil), severityOrder...)
+		var other []string
+		for severity := range counts {
+			if !containsString(severityOrder, severity) {
+				other = append(other, severity)
+			}
+		}
+		sort.Strings(other)
+		names = append(names, other...)
+
+		for _, severity := range names {
+			if counts[severity] == 0 {
+				continue
+			}
+			buckets = append(buckets, FindingsBucket{
+				Name:     severity,
+				Findings: er.filterFindingsBySeverityLevel(findings, severity),
+			})
+		}
+	case "category":
+		var categories []string
+		for _, finding := range findings {
+			if !containsString(categories, finding.Category) {
+				categories = append(categories, finding.Category)
+			}
+		}
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			if bucket := er.filterFindingsByCategory(findings, category); len(bucket) > 0 {
+				buckets = append(buckets, FindingsBucket{Name: category, Findings: bucket})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid split field '%s': must be severity or category", splitBy)
+	}
+
+	return buckets, nil
+}
+
+// ExportFindings writes findings to outputDir in the given format (json,
+// csv, csv-excel or md). With splitBy set, one file is written per
+// non-empty severity or category bucket, named findings-<bucket>.<ext>.
+func (er *EnhancedReporter) ExportFindings(findings []detector.Finding, splitBy, format, outputDir string) ([]ReportInfo, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	buckets := []FindingsBucket{{Findings: findings}}
+	if splitBy != "" {
+		var err error
+		if buckets, err = er.SplitFindings(findings, splitBy); err != nil {
+			return nil, err
+		}
+	}
+
+	var reports []ReportInfo
+	for _, bucket := range buckets {
+		name := "findings"
+		if bucket.Name != "" {
+			name = "findings-" + exportFileComponent(bucket.Name)
+		}
+
+		data, ext, err := encodeFindings(bucket, format)
+		if err != nil {
+			return reports, err
+		}
+
+		path, err := er.exportNamer.Path(outputDir, name, ext, name+"."+ext)
+		if err != nil {
+			return reports, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return reports, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		reports = append(reports, ReportInfo{
+			Type: format,
+			Path: path,
+			Size: int64(len(data)),
+		})
+	}
+
+	return reports, nil
+}
+
+// filterFindingsBySeverityLevel returns the findings with exactly the given severity
+func (er *EnhancedReporter) filterFindingsBySeverityLevel(findings []detector.Finding, severity string) []detector.Finding {
+	if level, err := detector.ParseSeverity(severity); err == nil {
+		findings = er.filterFindingsBySeverity(findings, level)
+	}
+
+	var filtered []detector.Finding
+	for _, finding := range findings {
+		if finding.Severity == severity {
+			filtered = append(filtered, finding)
+		}
+	}
+
+	return filtered
+}
+
+// encodeFindings renders a findings bucket and returns the file extension
+func encodeFindings(bucket FindingsBucket, format string) ([]byte, string, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(bucket.Findings, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal findings: %w", err)
+		}
+		return data, "json", nil
+	case "csv", CSVExcel:
+		var buf bytes.Buffer
+		writer := newCSVWriter(&buf, format)
+		writer.Write([]string{"Rule ID", "Rule Name", "Severity", "Category", "Description", "Evidence Count", "Timestamp"})
+		for _, finding := range bucket.Findings {
+			writer.Write([]string{
+				finding.RuleID,
+				finding.RuleName,
+				finding.Severity,
+				finding.Category,
+				finding.Description,
+				fmt.Sprintf("%d", len(finding.Evidence)),
+				exportTimestamp(finding.Timestamp),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, "", fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return buf.Bytes(), exportExtension(format), nil
+	case "md":
+		var buf bytes.Buffer
+		title := "RedTriage Findings"
+		if bucket.Name != "" {
+			title += ": " + bucket.Name
+		}
+		fmt.Fprintf(&buf, "# %s\n\n", title)
+		fmt.Fprintf(&buf, "**Generated:** %s\n", time.Now().Format(time.RFC3339))
+		fmt.Fprintf(&buf, "**Total Findings:** %d\n\n", len(bucket.Findings))
+		for i, finding := range bucket.Findings {
+			fmt.Fprintf(&buf, "## %d. %s\n\n", i+1, finding.RuleName)
+			fmt.Fprintf(&buf, "- **Rule ID:** %s\n", finding.RuleID)
+			fmt.Fprintf(&buf, "- **Severity:** %s\n", finding.Severity)
+			fmt.Fprintf(&buf, "- **Category:** %s\n", finding.Category)
+			fmt.Fprintf(&buf, "- **Description:** %s\n", finding.Description)
+			fmt.Fprintf(&buf, "- **Timestamp:** %s\n", exportTimestamp(finding.Timestamp))
+			if len(finding.Tags) > 0 {
+				fmt.Fprintf(&buf, "- **Tags:** %s\n", strings.Join(finding.Tags, ", "))
+			}
+			fmt.Fprintf(&buf, "\n")
+		}
+		return buf.Bytes(), "md", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported export format '%s': must be json, csv, csv-excel or md", format)
+	}
+}
+
+// exportTimestamp formats a finding timestamp, leaving unknown times blank
+func exportTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// exportFileComponent makes a bucket name safe to use in a file name
+func exportFileComponent(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "uncategorized"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
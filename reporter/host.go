@@ -0,0 +1,103 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// hostIdentityFields lists the identity fields shown in reports, in order
+func hostIdentityFields(identity collector.HostIdentity) [][2]string {
+	fields := [][2]string{
+		{"Collection Mode", offlineLabel(identity)},
+		{"Hostname", identity.Hostname},
+		{"FQDN", identity.FQDN},
+		{"Domain", identity.Domain},
+		{"Machine ID", identity.MachineID},
+		{"MAC Addresses", strings.Join(identity.MACAddresses, ", ")},
+		{"OS Build", identity.OSBuild},
+		{"Boot Time", identity.BootTime},
+		{"Host Fingerprint", fmt.Sprintf("%s (from %s)", identity.Fingerprint, strings.Join(identity.FingerprintBasis, ", "))},
+	}
+
+	shown := fields[:0]
+	for _, field := range fields {
+		if field[1] != "" {
+			shown = append(shown, field)
+		}
+	}
+	return shown
+}
+
+// hostIdentityMarkdown renders the host identity section of Markdown reports
+func hostIdentityMarkdown(identity collector.HostIdentity) string {
+	var b strings.Builder
+	b.WriteString("## Host Identity\n\n")
+	for _, field := range hostIdentityFields(identity) {
+		fmt.Fprintf(&b, "**%s:** %s\n", field[0], field[1])
+	}
+	b.WriteString("\n")
+	if notice := hostConflictNotice(identity); notice != "" {
+		fmt.Fprintf(&b, "> **Warning:** %s\n\n", notice)
+	}
+	return b.String()
+}
+
+// hostIdentityHTML renders the host identity rows of an HTML property table
+// and, after the table, any hostname conflict
+func hostIdentityHTML(identity collector.HostIdentity) (rows, notice string) {
+	var b strings.Builder
+	for _, field := range hostIdentityFields(identity) {
+		fmt.Fprintf(&b, `<tr><td>%s</td><td>%s</td></tr>`, field[0], html.EscapeString(field[1]))
+	}
+	if text := hostConflictNotice(identity); text != "" {
+		notice = fmt.Sprintf(`<p class="finding high"><strong>Warning:</strong> %s</p>`, html.EscapeString(text))
+	}
+	return b.String(), notice
+}
+
+// hostConflictNotice describes earlier collections that claim this hostname
+// with a different fingerprint, or is empty when there are none
+func hostConflictNotice(identity collector.HostIdentity) string {
+	if len(identity.HostnameConflicts) == 0 {
+		return ""
+	}
+	sources := make([]string, 0, len(identity.HostnameConflicts))
+	for _, conflict := range identity.HostnameConflicts {
+		sources = append(sources, fmt.Sprintf("%s (fingerprint %.12s)", conflict.Source, conflict.Fingerprint))
+	}
+	return fmt.Sprintf("hostname %s was also claimed by %s with a different host fingerprint; these may be different machines, e.g. clones of one image",
+		identity.Hostname, strings.Join(sources, ", "))
+}
+
+// offlineLabel marks results of an offline analysis, or is empty for
+// collections from the live host
+func offlineLabel(identity collector.HostIdentity) string {
+	if !identity.Offline() {
+		return ""
+	}
+	return "Offline analysis of the image mounted at " + identity.ImageRoot
+}
+
+// hostFooter returns the host line of report footers
+func hostFooter(identity collector.HostIdentity) string {
+	if identity.Offline() {
+		return fmt.Sprintf("%s (hostname %s), host fingerprint %s", offlineLabel(identity), identity.Hostname, identity.Fingerprint)
+	}
+	return fmt.Sprintf("Collected from %s, host fingerprint %s", identity.Hostname, identity.Fingerprint)
+}
+
+// hostFooterHTML returns the host footer as an HTML paragraph, followed by
+// any hostname conflict, or nothing when the host identity is unknown
+func hostFooterHTML(identity *collector.HostIdentity) string {
+	if identity == nil {
+		return ""
+	}
+	footer := fmt.Sprintf("\n            <p>%s</p>", html.EscapeString(hostFooter(*identity)))
+	if notice := hostConflictNotice(*identity); notice != "" {
+		footer += fmt.Sprintf("\n            <p><strong>Warning:</strong> %s</p>", html.EscapeString(notice))
+	}
+	return footer
+}
@@ -0,0 +1,299 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// profileListLimit caps the rows of long lists, such as installed
+// packages, in rendered profiles; the JSON profile always holds them all
+const profileListLimit = 200
+
+// profileTable is one table of a rendered host profile
+type profileTable struct {
+	title   string
+	headers []string
+	rows    [][]string
+	total   int
+}
+
+// hostProfileTables lays out the sections of a host profile as tables
+func hostProfileTables(profile collector.HostProfile) []profileTable {
+	var tables []profileTable
+	add := func(title string, headers []string, rows [][]string) {
+		table := profileTable{title: title, headers: headers, rows: rows, total: len(rows)}
+		if len(table.rows) > profileListLimit {
+			table.rows = table.rows[:profileListLimit]
+		}
+		tables = append(tables, table)
+	}
+
+	if osInfo := profile.OS; osInfo != nil {
+		var uptime, memory string
+		if osInfo.UptimeSeconds > 0 {
+			uptime = collector.FormatUptime(time.Duration(osInfo.UptimeSeconds) * time.Second)
+		}
+		if m := osInfo.Memory; m != nil {
+			memory = fmt.Sprintf("%s total, %s available", formatBytes(m.TotalBytes), formatBytes(m.AvailableBytes))
+		}
+		add("Operating System", []string{"Property", "Value"}, nonEmptyRows([][]string{
+			{"Name", osInfo.Name}, {"Version", osInfo.Version}, {"Build", osInfo.Build}, {"Kernel", osInfo.Kernel},
+			{"Patch Level", osInfo.PatchLevel}, {"Hotfixes", strings.Join(osInfo.Hotfixes, ", ")},
+			{"Uptime", uptime}, {"Memory", memory},
+		}))
+	}
+	if profile.Includes(collector.ProfileSoftware) {
+		var rows [][]string
+		for _, s := range profile.Software {
+			rows = append(rows, []string{s.Name, s.Version, s.Publisher, s.Source})
+		}
+		add(fmt.Sprintf("Installed Software (%d)", len(profile.Software)), []string{"Name", "Version", "Publisher", "Source"}, rows)
+	}
+	if profile.Includes(collector.ProfileUsers) {
+		var rows [][]string
+		for _, u := range profile.Users {
+			rows = append(rows, []string{u.Username, u.UID, yesNo(u.Enabled), yesNo(u.Interactive), yesNo(u.Admin), strings.Join(u.Groups, ", ")})
+		}
+		add("Local Users", []string{"User", "ID", "Enabled", "Interactive", "Admin", "Groups"}, rows)
+		rows = nil
+		for _, g := range profile.Groups {
+			if len(g.Members) > 0 {
+				rows = append(rows, []string{g.Name, strings.Join(g.Members, ", ")})
+			}
+		}
+		add("Local Groups with Members", []string{"Group", "Members"}, rows)
+	}
+	if network := profile.Network; network != nil {
+		var rows [][]string
+		for _, iface := range network.Interfaces {
+			rows = append(rows, []string{iface.Name, iface.MAC, yesNo(iface.Up), strings.Join(iface.Addresses, ", ")})
+		}
+		add("Network Interfaces", []string{"Interface", "MAC", "Up", "Addresses"}, rows)
+		add("Network Configuration", []string{"Property", "Value"}, nonEmptyRows([][]string{
+			{"DNS Servers", strings.Join(network.DNSServers, ", ")},
+			{"Default Gateways", strings.Join(network.DefaultGateways, ", ")},
+		}))
+	}
+	if profile.Includes(collector.ProfileDisks) {
+		var rows [][]string
+		for _, d := range profile.Disks {
+			rows = append(rows, []string{d.Path, d.Device, d.FileSystem, formatBytes(d.SizeBytes), formatBytes(d.FreeBytes)})
+		}
+		add("Disk Layout", []string{"Mount", "Device", "File System", "Size", "Free"}, rows)
+	}
+	if summary := profile.ServiceSummary; summary != nil {
+		add("Services", []string{"Property", "Value"}, [][]string{
+			{"Service Manager", summary.Manager},
+			{"Installed", fmt.Sprint(summary.Total)},
+			{"Running", fmt.Sprint(summary.Running)},
+			{"Start Automatically", fmt.Sprint(summary.AutoStart)},
+			{"Running Services", strings.Join(summary.Names, ", ")},
+		})
+	}
+	if profile.Includes(collector.ProfilePersistence) {
+		var rows [][]string
+		for _, item := range profile.StartupItems {
+			rows = append(rows, []string{item.Name, item.Command, item.Location})
+		}
+		add("Startup Items", []string{"Name", "Command", "Location"}, rows)
+		rows = nil
+		for _, task := range profile.ScheduledTasks {
+			rows = append(rows, []string{task.Name, task.Schedule, task.Command, task.RunAs, yesNo(task.Enabled)})
+		}
+		add("Scheduled Tasks", []string{"Name", "Schedule", "Command", "Run As", "Enabled"}, rows)
+	}
+	if security := profile.Security; security != nil {
+		var av, firewall []string
+		for _, product := range security.Antivirus {
+			av = append(av, fmt.Sprintf("%s (%s, real-time %s)", product.Name, enabledLabel(product.Enabled), enabledLabel(product.RealTime)))
+		}
+		for _, f := range security.Firewall {
+			firewall = append(firewall, fmt.Sprintf("%s: %s", f.Name, enabledLabel(f.Enabled)))
+		}
+		add("Security Posture", []string{"Property", "Value"}, nonEmptyRows([][]string{
+			{"Antivirus / EDR", valueOr(strings.Join(av, "; "), "none found")},
+			{"Firewall", valueOr(strings.Join(firewall, "; "), "none found")},
+			{"UAC", security.UAC}, {"SELinux", security.SELinux}, {"AppArmor", security.AppArmor},
+		}))
+	}
+	return tables
+}
+
+// HostProfileMarkdown renders a host profile as a Markdown document
+func HostProfileMarkdown(profile collector.HostProfile) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Host Profile: %s\n\n", profile.Hostname)
+	fmt.Fprintf(&b, "**Generated:** %s  \n**Platform:** %s/%s  \n**Sections:** %s\n\n",
+		profile.Timestamp, profile.Platform, profile.Architecture, strings.Join(profile.Sections, ", "))
+	b.WriteString(hostIdentityMarkdown(profile.Host))
+
+	if security := profile.Security; security != nil && len(security.Highlights) > 0 {
+		b.WriteString("## Security Highlights\n\n")
+		for _, highlight := range security.Highlights {
+			fmt.Fprintf(&b, "- %s\n", highlight)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, table := range hostProfileTables(profile) {
+		fmt.Fprintf(&b, "## %s\n\n", table.title)
+		if len(table.rows) == 0 {
+			b.WriteString("None found.\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| %s |\n|%s\n", strings.Join(table.headers, " | "), strings.Repeat(" --- |", len(table.headers)))
+		for _, row := range table.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", `\|`), "\n", " ")
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+		}
+		if table.total > len(table.rows) {
+			fmt.Fprintf(&b, "\n_%d more rows in host-profile.json_\n", table.total-len(table.rows))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(profile.Errors) > 0 {
+		b.WriteString("## Sections Not Read\n\n")
+		for _, section := range collector.ProfileSections {
+			if message, ok := profile.Errors[section]; ok {
+				fmt.Fprintf(&b, "- **%s:** %s\n", section, message)
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "---\n*%s*\n", hostFooter(profile.Host))
+	return b.String()
+}
+
+// HostProfileHTML renders a host profile as a standalone HTML page
+func HostProfileHTML(profile collector.HostProfile) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Host Profile: %s</title>
+    <style>
+        body { font-family: Arial, sans-serif; margin: 40px; line-height: 1.6; }
+        .header { background: #f4f4f4; padding: 20px; border-radius: 5px; margin-bottom: 30px; }
+        .section { margin-bottom: 30px; }
+        .finding { border-left: 4px solid #ddd; padding-left: 15px; margin: 10px 0; }
+        .finding.high { border-left-color: #ff6b6b; }
+        table { border-collapse: collapse; width: 100%%; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; vertical-align: top; }
+        th { background-color: #f2f2f2; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>Host Profile: %s</h1>
+        <p><strong>Generated:</strong> %s</p>
+        <p><strong>Platform:</strong> %s/%s</p>
+        <p><strong>Sections:</strong> %s</p>
+    </div>
+`, html.EscapeString(profile.Hostname), html.EscapeString(profile.Hostname), html.EscapeString(profile.Timestamp),
+		html.EscapeString(profile.Platform), html.EscapeString(profile.Architecture), html.EscapeString(strings.Join(profile.Sections, ", ")))
+
+	rows, notice := hostIdentityHTML(profile.Host)
+	fmt.Fprintf(&b, "    <div class=\"section\">\n        <h2>Host Identity</h2>\n        <table>%s</table>%s\n    </div>\n", rows, notice)
+
+	if security := profile.Security; security != nil && len(security.Highlights) > 0 {
+		b.WriteString("    <div class=\"section\">\n        <h2>Security Highlights</h2>\n")
+		for _, highlight := range security.Highlights {
+			fmt.Fprintf(&b, "        <p class=\"finding high\">%s</p>\n", html.EscapeString(highlight))
+		}
+		b.WriteString("    </div>\n")
+	}
+
+	for _, table := range hostProfileTables(profile) {
+		fmt.Fprintf(&b, "    <div class=\"section\">\n        <h2>%s</h2>\n", html.EscapeString(table.title))
+		if len(table.rows) == 0 {
+			b.WriteString("        <p>None found.</p>\n    </div>\n")
+			continue
+		}
+		b.WriteString("        <table><tr>")
+		for _, header := range table.headers {
+			fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(header))
+		}
+		b.WriteString("</tr>\n")
+		for _, row := range table.rows {
+			b.WriteString("        <tr>")
+			for _, cell := range row {
+				fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("        </table>\n")
+		if table.total > len(table.rows) {
+			fmt.Fprintf(&b, "        <p><em>%d more rows in host-profile.json</em></p>\n", table.total-len(table.rows))
+		}
+		b.WriteString("    </div>\n")
+	}
+
+	if len(profile.Errors) > 0 {
+		b.WriteString("    <div class=\"section\">\n        <h2>Sections Not Read</h2>\n")
+		for _, section := range collector.ProfileSections {
+			if message, ok := profile.Errors[section]; ok {
+				fmt.Fprintf(&b, "        <p><strong>%s:</strong> %s</p>\n", section, html.EscapeString(message))
+			}
+		}
+		b.WriteString("    </div>\n")
+	}
+	fmt.Fprintf(&b, "    <div class=\"section\">\n        <p>%s</p>\n        <p>Generated by RedTriage at %s</p>\n    </div>\n</body>\n</html>\n",
+		html.EscapeString(hostFooter(profile.Host)), time.Now().Format(time.RFC3339))
+	return b.String()
+}
+
+// nonEmptyRows drops property rows without a value
+func nonEmptyRows(rows [][]string) [][]string {
+	kept := rows[:0]
+	for _, row := range rows {
+		if row[len(row)-1] != "" {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func enabledLabel(v bool) string {
+	if v {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
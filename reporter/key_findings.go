@@ -0,0 +1,268 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// maxGroupExamples is the number of example entities kept per finding group
+const maxGroupExamples = 3
+
+// FindingGroup summarizes the findings of one rule: how many there are, the
+// highest severity among them and a few of the entities they point at
+type FindingGroup struct {
+	RuleID   string   `json:"rule_id"`
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples,omitempty"`
+}
+
+// GroupFindings groups Sigma matches, as produced by the session findings
+// command, by rule. Groups are ordered by severity, then count.
+func GroupFindings(matches []map[string]interface{}) []FindingGroup {
+	grouper := newFindingGrouper()
+	for _, match := range matches {
+		evidence, _ := match["evidence"].(map[string]interface{})
+		grouper.add(stringValue(match["rule_id"]), stringValue(match["rule_title"]), stringValue(match["level"]), evidence)
+	}
+	return grouper.groups()
+}
+
+// GroupDetectorFindings groups detector findings by rule, picking the
+// example entity from their merged evidence
+func GroupDetectorFindings(findings []detector.Finding) []FindingGroup {
+	grouper := newFindingGrouper()
+	for _, finding := range findings {
+		grouper.add(finding.RuleID, finding.RuleName, finding.Severity, mergedEvidence(finding))
+	}
+	return grouper.groups()
+}
+
+// DetectorMatches converts detector findings to the match form of a findings
+// report, so they filter, group and print like Sigma matches
+func DetectorMatches(findings []detector.Finding) []map[string]interface{} {
+	matches := make([]map[string]interface{}, 0, len(findings))
+	for _, finding := range findings {
+		matches = append(matches, map[string]interface{}{
+			"rule_id":     finding.RuleID,
+			"rule_title":  finding.RuleName,
+			"level":       finding.Severity,
+			"category":    finding.Category,
+			"description": finding.Description,
+			"tags":        finding.Tags,
+			"timestamp":   finding.Timestamp.Format(time.RFC3339),
+			"evidence":    mergedEvidence(finding),
+		})
+	}
+	return matches
+}
+
+// mergedEvidence merges the evidence entries of a detector finding into one
+// map, each keyed by its type as well as by its metadata
+func mergedEvidence(finding detector.Finding) map[string]interface{} {
+	evidence := make(map[string]interface{})
+	for _, item := range finding.Evidence {
+		for key, value := range item.Metadata {
+			evidence[key] = value
+		}
+		if item.Type != "" && item.Value != "" {
+			evidence[item.Type] = item.Value
+		}
+	}
+	return evidence
+}
+
+// findingGrouper accumulates finding groups in first-seen order
+type findingGrouper struct {
+	order  []string
+	byRule map[string]*FindingGroup
+}
+
+func newFindingGrouper() *findingGrouper {
+	return &findingGrouper{byRule: make(map[string]*FindingGroup)}
+}
+
+func (g *findingGrouper) add(ruleID, rule, severity string, evidence map[string]interface{}) {
+	key := ruleID
+	if key == "" {
+		key = rule
+	}
+	group, ok := g.byRule[key]
+	if !ok {
+		if rule == "" {
+			rule = ruleID
+		}
+		group = &FindingGroup{RuleID: ruleID, Rule: rule}
+		g.byRule[key] = group
+		g.order = append(g.order, key)
+	}
+
+	group.Count++
+	severity = strings.ToLower(severity)
+	if group.Severity == "" || severityRank(severity) > severityRank(group.Severity) {
+		group.Severity = severity
+	}
+	if entity := findingEntity(evidence); entity != "" && len(group.Examples) < maxGroupExamples && !containsString(group.Examples, entity) {
+		group.Examples = append(group.Examples, entity)
+	}
+}
+
+func (g *findingGrouper) groups() []FindingGroup {
+	groups := make([]FindingGroup, 0, len(g.order))
+	for _, key := range g.order {
+		groups = append(groups, *g.byRule[key])
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if ri, rj := severityRank(groups[i].Severity), severityRank(groups[j].Severity); ri != rj {
+			return ri > rj
+		}
+		return groups[i].Count > groups[j].Count
+	})
+	return groups
+}
+
+// Evidence keys an entity is read from, in order of preference
+var (
+	processNameKeys = []string{"process_name", "name", "process", "image", "Image"}
+	processIDKeys   = []string{"pid", "process_id", "ProcessId", "ProcessID"}
+	remoteAddrKeys  = []string{"remote_address", "remote_ip", "destination_ip", "DestinationIp"}
+	filePathKeys    = []string{"file_path", "path", "target_filename", "TargetFilename", "executable_path"}
+)
+
+// findingEntity describes what a finding points at: the process name and
+// PID, the remote IP and the file path, whichever the evidence holds
+func findingEntity(evidence map[string]interface{}) string {
+	var parts []string
+	if name := firstValue(evidence, processNameKeys); name != "" {
+		if pid := firstValue(evidence, processIDKeys); pid != "" {
+			name = fmt.Sprintf("%s (pid %s)", name, pid)
+		}
+		parts = append(parts, name)
+	}
+	if remote := firstValue(evidence, remoteAddrKeys); remote != "" {
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+		parts = append(parts, remote)
+	}
+	if path := firstValue(evidence, filePathKeys); path != "" {
+		parts = append(parts, path)
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// firstValue returns the first non-empty value among keys
+func firstValue(evidence map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if value := stringValue(evidence[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// snapshotKeys are kept in every evidence snapshot the record has them in:
+// besides the entity, when and where an event was recorded
+var snapshotKeys = []string{"event_id", "record_id", "time_created", "timestamp", "channel", "computer", "user", "command_line", "state"}
+
+// EvidenceSnapshot is the part of a matched record a finding keeps next to
+// the reference to it: the fields naming its entity, as findingEntity and
+// FindingKey read them, and the fields the rule matched on. Fields missing
+// from the record are looked up in its event data.
+func EvidenceSnapshot(record map[string]interface{}, fields []string) map[string]interface{} {
+	data, _ := record["data"].(map[string]interface{})
+	snapshot := make(map[string]interface{})
+	for _, group := range [][]string{processNameKeys, processIDKeys, remoteAddrKeys, filePathKeys, snapshotKeys, fields} {
+		for _, key := range group {
+			if value, ok := record[key]; ok && key != "data" {
+				snapshot[key] = value
+			} else if value, ok := data[key]; ok {
+				snapshot[key] = value
+			}
+		}
+	}
+	return snapshot
+}
+
+// stringValue formats a JSON value for display, or "" when it is missing
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// severityRank orders severities, including Sigma levels, so groups can be
+// sorted highest first. Unknown severities rank 0.
+func severityRank(severity string) int {
+	return int(detector.SeverityOf(severity))
+}
+
+// KeyFindingsText renders the first top groups as console lines, each rule
+// followed by its example entities, sanitized for the terminal. A top of
+// zero or less shows every group.
+func KeyFindingsText(groups []FindingGroup, top int) string {
+	shown := groups
+	if top > 0 && len(shown) > top {
+		shown = shown[:top]
+	}
+
+	var b strings.Builder
+	for _, group := range shown {
+		noun := "findings"
+		if group.Count == 1 {
+			noun = "finding"
+		}
+		fmt.Fprintf(&b, "  [%s] %s: %d %s\n", strings.ToUpper(group.Severity), output.SanitizeLine(group.Rule), group.Count, noun)
+		if len(group.Examples) > 0 {
+			fmt.Fprintf(&b, "      e.g. %s\n", output.SanitizeLine(strings.Join(group.Examples, "; ")))
+		}
+	}
+	switch hidden := len(groups) - len(shown); {
+	case hidden == 1:
+		b.WriteString("  ... and 1 more rule (use --top to show more)\n")
+	case hidden > 1:
+		fmt.Fprintf(&b, "  ... and %d more rules (use --top to show more)\n", hidden)
+	}
+	return b.String()
+}
+
+// keyFindingsHTML renders finding groups as the key findings table of the
+// executive summary, or nothing when there are no findings
+func keyFindingsHTML(groups []FindingGroup) string {
+	if len(groups) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+    <div class="summary">
+        <h2>Findings by Rule</h2>
+        <table>
+            <tr><th>Severity</th><th>Rule</th><th>Findings</th><th>Examples</th></tr>`)
+	for _, group := range groups {
+		fmt.Fprintf(&b, `
+            <tr class="finding %s"><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>`,
+			html.EscapeString(group.Severity), html.EscapeString(strings.ToUpper(group.Severity)),
+			html.EscapeString(group.Rule), group.Count, html.EscapeString(strings.Join(group.Examples, "; ")))
+	}
+	b.WriteString(`
+        </table>
+    </div>`)
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGroupFindings(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "grouping.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture struct {
+		Matches  []map[string]interface{} `json:"matches"`
+		Expected []FindingGroup           `json:"expected"`
+	}
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("failed to parse grouping fixture: %v", err)
+	}
+
+	groups := GroupFindings(fixture.Matches)
+	got, err := json.Marshal(groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(fixture.Expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("finding groups differ:\n  got  %s\n  want %s", got, want)
+	}
+
+	const top = 2
+	summary := KeyFindingsText(groups, top)
+	more := fmt.Sprintf("... and %d more rule", len(groups)-top)
+	if !strings.Contains(summary, groups[top-1].Rule) || strings.Contains(summary, groups[top].Rule) || !strings.Contains(summary, more) {
+		t.Errorf("summary of the top %d rules is wrong:\n%s", top, summary)
+	}
+}
+
+func TestGroupFindingsRanksInformationalAsLow(t *testing.T) {
+	groups := GroupFindings([]map[string]interface{}{
+		{"rule_id": "S3", "rule_title": "Unknown level", "level": "severe"},
+		{"rule_id": "S2", "rule_title": "Informational level", "level": "informational"},
+		{"rule_id": "S1", "rule_title": "High level", "level": "high"},
+	})
+	var order []string
+	for _, group := range groups {
+		order = append(order, group.RuleID)
+	}
+	if strings.Join(order, ",") != "S1,S2,S3" {
+		t.Errorf("groups ranked %v, want high, informational, then the unknown level", order)
+	}
+}
@@ -0,0 +1,322 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/output"
+)
+
+// NotifyStateFile records when each rule last alerted for a host, in the
+// reports metadata directory, so the cooldown holds across runs
+const NotifyStateFile = "notify-state.json"
+
+// Alert is the notification sent for one rule whose findings meet the
+// notification threshold
+type Alert struct {
+	Host         string   `json:"host"`
+	RuleID       string   `json:"rule_id"`
+	Rule         string   `json:"rule"`
+	Severity     string   `json:"severity"`
+	Count        int      `json:"count"`
+	Examples     []string `json:"examples,omitempty"`
+	CollectionID string   `json:"collection_id"`
+	Report       string   `json:"report"`
+}
+
+// Key identifies the alert for rate limiting: the same rule on the same host
+func (a Alert) Key() string {
+	rule := a.RuleID
+	if rule == "" {
+		rule = a.Rule
+	}
+	return a.Host + "|" + rule
+}
+
+// Subject is the one-line summary of the alert
+func (a Alert) Subject() string {
+	return fmt.Sprintf("[RedTriage] %s finding on %s: %s", strings.ToUpper(a.Severity), a.Host, a.Rule)
+}
+
+// Text renders the alert body: host, rule, severity and the report to open
+func (a Alert) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Host: %s\n", a.Host)
+	fmt.Fprintf(&b, "Rule: %s", a.Rule)
+	if a.RuleID != "" {
+		fmt.Fprintf(&b, " (%s)", a.RuleID)
+	}
+	fmt.Fprintf(&b, "\nSeverity: %s\nMatches: %d\n", a.Severity, a.Count)
+	if len(a.Examples) > 0 {
+		fmt.Fprintf(&b, "Examples: %s\n", strings.Join(a.Examples, "; "))
+	}
+	fmt.Fprintf(&b, "Collection: %s\nReport: %s\n", a.CollectionID, a.Report)
+	return b.String()
+}
+
+// ValidNotifySeverity reports whether a --notify-on threshold is a known
+// severity
+func ValidNotifySeverity(severity string) bool {
+	return severityRank(severity) > 0
+}
+
+// AlertsForGroups builds one alert for each finding group at or above the
+// minimum severity, keeping the group order (highest severity first)
+func AlertsForGroups(groups []FindingGroup, minSeverity, host, collectionID, report string) []Alert {
+	threshold := severityRank(minSeverity)
+	var alerts []Alert
+	for _, group := range groups {
+		if severityRank(group.Severity) < threshold {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Host:         host,
+			RuleID:       group.RuleID,
+			Rule:         group.Rule,
+			Severity:     strings.ToLower(group.Severity),
+			Count:        group.Count,
+			Examples:     group.Examples,
+			CollectionID: collectionID,
+			Report:       report,
+		})
+	}
+	return alerts
+}
+
+// NotificationSink is a destination alerts are posted to
+type NotificationSink interface {
+	Name() string
+	Send(alert Alert) error
+}
+
+// NotificationSinks returns the sinks configured under notifications
+func NotificationSinks(cfg config.NotificationsConfig) []NotificationSink {
+	var sinks []NotificationSink
+	if cfg.SlackWebhook != "" {
+		sinks = append(sinks, SlackSink{WebhookURL: cfg.SlackWebhook})
+	}
+	if cfg.TeamsWebhook != "" {
+		sinks = append(sinks, TeamsSink{WebhookURL: cfg.TeamsWebhook})
+	}
+	if cfg.SMTP.Host != "" {
+		sinks = append(sinks, EmailSink{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			To:       cfg.SMTP.To,
+		})
+	}
+	return sinks
+}
+
+// SlackSink posts alerts to a Slack incoming webhook
+type SlackSink struct {
+	WebhookURL string
+	Client     *http.Client // default: 15 second timeout
+}
+
+// Name identifies the sink in warnings
+func (s SlackSink) Name() string { return "slack" }
+
+// Send posts the alert as a Slack message
+func (s SlackSink) Send(alert Alert) error {
+	payload := map[string]string{"text": fmt.Sprintf("*%s*\n```%s```", alert.Subject(), alert.Text())}
+	return postWebhook(s.Client, s.WebhookURL, payload)
+}
+
+// TeamsSink posts alerts to a Microsoft Teams incoming webhook
+type TeamsSink struct {
+	WebhookURL string
+	Client     *http.Client // default: 15 second timeout
+}
+
+// Name identifies the sink in warnings
+func (t TeamsSink) Name() string { return "teams" }
+
+// Send posts the alert as a Teams message card
+func (t TeamsSink) Send(alert Alert) error {
+	payload := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    alert.Subject(),
+		"title":      alert.Subject(),
+		"themeColor": teamsColor(alert.Severity),
+		"text":       strings.ReplaceAll(strings.TrimSpace(alert.Text()), "\n", "<br>"),
+	}
+	return postWebhook(t.Client, t.WebhookURL, payload)
+}
+
+// teamsColor picks the card accent color for a severity
+func teamsColor(severity string) string {
+	switch severityRank(severity) {
+	case 4:
+		return "8B0000"
+	case 3:
+		return "FF6B6B"
+	case 2:
+		return "FFA500"
+	default:
+		return "808080"
+	}
+}
+
+// EmailSink sends alerts as plain text mail through an SMTP server, with
+// authentication when a username is set
+type EmailSink struct {
+	Host     string
+	Port     int // default: 587
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name identifies the sink in warnings
+func (e EmailSink) Name() string { return "email" }
+
+// Send mails the alert to every recipient
+func (e EmailSink) Send(alert Alert) error {
+	if e.Host == "" || e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("email alerts need an SMTP host, a from address and at least one recipient")
+	}
+	port := e.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		e.From, strings.Join(e.To, ", "), alert.Subject(), strings.ReplaceAll(alert.Text(), "\n", "\r\n"))
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, e.From, e.To, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send alert mail through %s: %w", addr, err)
+	}
+	return nil
+}
+
+// postWebhook posts a JSON payload to a webhook and fails on non-2xx responses
+func postWebhook(client *http.Client, webhookURL string, payload interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("alert webhook rejected the message: %s: %s", resp.Status, truncateBody(data))
+	}
+	return nil
+}
+
+// NotifyLimiter keeps alerts from flooding a channel: at most MaxPerRun
+// alerts are sent per run, and a rule that alerted for a host stays quiet
+// for the cooldown. Sent is persisted between runs.
+type NotifyLimiter struct {
+	MaxPerRun int                  `json:"-"`
+	Cooldown  time.Duration        `json:"-"`
+	Sent      map[string]time.Time `json:"sent"`
+}
+
+// LoadNotifyLimiter reads the alert history saved at path. A missing file
+// starts an empty history, as does an unreadable one, which is returned
+// with the error.
+func LoadNotifyLimiter(path string) (*NotifyLimiter, error) {
+	limiter := &NotifyLimiter{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return limiter, nil
+	}
+	if err := json.Unmarshal(data, limiter); err != nil {
+		return &NotifyLimiter{}, fmt.Errorf("ignoring unreadable %s: %w", NotifyStateFile, err)
+	}
+	return limiter, nil
+}
+
+// Save writes the alert history to path
+func (l *NotifyLimiter) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification state: %w", err)
+	}
+	return output.WriteFileAtomic(path, data, 0644)
+}
+
+// allow reports whether the alert may be sent at now
+func (l *NotifyLimiter) allow(alert Alert, now time.Time) bool {
+	last, ok := l.Sent[alert.Key()]
+	return !ok || now.Sub(last) >= l.Cooldown
+}
+
+// prune drops entries whose cooldown has passed so the state stays small
+func (l *NotifyLimiter) prune(now time.Time) {
+	for key, last := range l.Sent {
+		if now.Sub(last) >= l.Cooldown {
+			delete(l.Sent, key)
+		}
+	}
+}
+
+// NotifyResult summarizes a notification run
+type NotifyResult struct {
+	Sent       int      // alerts delivered by at least one sink
+	CoolingOff int      // alerts skipped because the rule alerted recently
+	OverLimit  int      // alerts skipped past the per-run limit
+	Errors     []string // one entry per failed delivery
+}
+
+// DispatchAlerts sends each alert to every sink, subject to the limiter.
+// A failed sink does not stop the others; failures are only reported.
+func DispatchAlerts(sinks []NotificationSink, alerts []Alert, limiter *NotifyLimiter, now time.Time) NotifyResult {
+	var result NotifyResult
+	if limiter.Sent == nil {
+		limiter.Sent = make(map[string]time.Time)
+	}
+	limiter.prune(now)
+
+	for _, alert := range alerts {
+		if !limiter.allow(alert, now) {
+			result.CoolingOff++
+			continue
+		}
+		if limiter.MaxPerRun > 0 && result.Sent >= limiter.MaxPerRun {
+			result.OverLimit++
+			continue
+		}
+
+		delivered := false
+		for _, sink := range sinks {
+			if err := sink.Send(alert); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", sink.Name(), err))
+				continue
+			}
+			delivered = true
+		}
+		if delivered {
+			result.Sent++
+			limiter.Sent[alert.Key()] = now
+		}
+	}
+	return result
+}
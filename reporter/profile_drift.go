@@ -0,0 +1,292 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of profile change
+const (
+	DriftAdded   = "added"
+	DriftRemoved = "removed"
+	DriftChanged = "changed"
+)
+
+// ProfileChange is one difference between two host profiles
+type ProfileChange struct {
+	Section  string      `json:"section"`
+	Key      string      `json:"key"`
+	Change   string      `json:"change"`
+	Field    string      `json:"field,omitempty"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+	Severity string      `json:"severity"`
+}
+
+// String describes the change in one line
+func (c ProfileChange) String() string {
+	switch c.Change {
+	case DriftChanged:
+		field := c.Key
+		if c.Field != "" {
+			field += "." + c.Field
+		}
+		return fmt.Sprintf("%s: %s changed from %s to %s", c.Section, field, stringValue(c.Before), stringValue(c.After))
+	default:
+		return fmt.Sprintf("%s: %s %s", c.Section, c.Key, c.Change)
+	}
+}
+
+// ProfileDrift is the result of comparing a host profile with an earlier one
+type ProfileDrift struct {
+	Prior        string          `json:"prior"`
+	PriorTime    string          `json:"prior_timestamp,omitempty"`
+	CurrentTime  string          `json:"current_timestamp,omitempty"`
+	HostMismatch bool            `json:"host_mismatch,omitempty"`
+	Changes      []ProfileChange `json:"changes"`
+}
+
+// LoadProfile reads a saved host profile
+func LoadProfile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	var profile map[string]interface{}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// volatileProfileKeys hold values that change between runs on an unchanged
+// host: times, counters, usage and the state of running things. They are
+// left out of the comparison.
+var volatileProfileKeys = map[string]bool{
+	"timestamp": true, "collected_at": true, "collection_time": true, "hostname_conflicts": true, "uptime": true, "uptime_seconds": true, "boot_time": true,
+	"last_login": true, "last_run": true, "next_run": true, "login_time": true, "logout_time": true,
+	"login_history": true, "processes": true, "connections": true, "arp_table": true,
+	"recent_files": true, "temp_files": true, "cpu_usage": true, "memory_usage": true,
+	"memory": true, "available": true, "free": true, "used": true, "usage_percent": true,
+	"pid": true, "ppid": true, "status": true, "state": true, "duration": true,
+	"working_dir": true, "go_version": true, "redtriage_version": true, "reports_dir": true,
+	"config_path": true, "free_bytes": true, "running": true, "running_services": true,
+	"sections": true, "errors": true,
+}
+
+// profileIdentityKeys name the field that identifies an entry of a list,
+// in order of preference
+var profileIdentityKeys = []string{"username", "name", "id", "path", "command", "display_name"}
+
+// CompareProfiles returns the differences between a prior and a current
+// host profile, ordered by severity and then by section. Lists of entries
+// such as users or services are matched by their name rather than their
+// position, so reordering is not a change.
+func CompareProfiles(priorPath string, prior, current map[string]interface{}) ProfileDrift {
+	drift := ProfileDrift{
+		Prior:       priorPath,
+		PriorTime:   stringValue(prior["timestamp"]),
+		CurrentTime: stringValue(current["timestamp"]),
+	}
+	if before, after := stringValue(prior["hostname"]), stringValue(current["hostname"]); before != "" && after != "" && !strings.EqualFold(before, after) {
+		drift.HostMismatch = true
+	}
+
+	drift.Changes = compareProfileMaps("", normalizeProfile(prior), normalizeProfile(current))
+	sort.SliceStable(drift.Changes, func(i, j int) bool {
+		a, b := drift.Changes[i], drift.Changes[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		return a.Section < b.Section
+	})
+	return drift
+}
+
+// normalizeProfile gives a profile built in memory the same value types as
+// one read from a file
+func normalizeProfile(profile map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return profile
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return profile
+	}
+	return normalized
+}
+
+// compareProfileMaps compares two objects of a profile. Scalars directly
+// under the top level are reported in the "host" section.
+func compareProfileMaps(section string, prior, current map[string]interface{}) []ProfileChange {
+	var changes []ProfileChange
+	for _, key := range unionKeys(prior, current) {
+		if volatileProfileKeys[key] {
+			continue
+		}
+		path := joinSection(section, key)
+		before, hadBefore := prior[key]
+		after, hasAfter := current[key]
+
+		switch {
+		case !hadBefore:
+			changes = append(changes, newProfileChange(sectionOrHost(section), key, DriftAdded, "", nil, after))
+		case !hasAfter:
+			changes = append(changes, newProfileChange(sectionOrHost(section), key, DriftRemoved, "", before, nil))
+		default:
+			beforeMap, beforeIsMap := before.(map[string]interface{})
+			afterMap, afterIsMap := after.(map[string]interface{})
+			beforeList, beforeIsList := before.([]interface{})
+			afterList, afterIsList := after.([]interface{})
+			switch {
+			case beforeIsMap && afterIsMap:
+				changes = append(changes, compareProfileMaps(path, beforeMap, afterMap)...)
+			case beforeIsList && afterIsList:
+				changes = append(changes, compareProfileLists(path, beforeList, afterList)...)
+			case !reflect.DeepEqual(before, after):
+				changes = append(changes, newProfileChange(sectionOrHost(section), key, DriftChanged, "", before, after))
+			}
+		}
+	}
+	return changes
+}
+
+// compareProfileLists compares two lists of a profile: entries are matched
+// by identity, and changed fields of a matched entry are reported one by one
+func compareProfileLists(section string, prior, current []interface{}) []ProfileChange {
+	priorEntries, priorOrder := indexProfileList(prior)
+	currentEntries, currentOrder := indexProfileList(current)
+
+	var changes []ProfileChange
+	for _, key := range currentOrder {
+		before, ok := priorEntries[key]
+		after := currentEntries[key]
+		if !ok {
+			changes = append(changes, newProfileChange(section, key, DriftAdded, "", nil, after))
+			continue
+		}
+		beforeMap, _ := before.(map[string]interface{})
+		afterMap, _ := after.(map[string]interface{})
+		for _, field := range unionKeys(beforeMap, afterMap) {
+			if volatileProfileKeys[field] || reflect.DeepEqual(beforeMap[field], afterMap[field]) {
+				continue
+			}
+			changes = append(changes, newProfileChange(section, key, DriftChanged, field, beforeMap[field], afterMap[field]))
+		}
+	}
+	for _, key := range priorOrder {
+		if _, ok := currentEntries[key]; !ok {
+			changes = append(changes, newProfileChange(section, key, DriftRemoved, "", priorEntries[key], nil))
+		}
+	}
+	return changes
+}
+
+// indexProfileList keys the entries of a list by their identity field, or
+// by their stable content when they have none
+func indexProfileList(list []interface{}) (map[string]interface{}, []string) {
+	entries := make(map[string]interface{}, len(list))
+	var order []string
+	for _, item := range list {
+		key := profileEntryKey(item)
+		if _, seen := entries[key]; seen {
+			continue
+		}
+		entries[key] = item
+		order = append(order, key)
+	}
+	return entries, order
+}
+
+// profileEntryKey returns the identity of a list entry
+func profileEntryKey(item interface{}) string {
+	entry, ok := item.(map[string]interface{})
+	if !ok {
+		return stringValue(item)
+	}
+	for _, key := range profileIdentityKeys {
+		if value := stringValue(entry[key]); value != "" {
+			return value
+		}
+	}
+	stable := make(map[string]interface{}, len(entry))
+	for key, value := range entry {
+		if !volatileProfileKeys[key] {
+			stable[key] = value
+		}
+	}
+	data, _ := json.Marshal(stable)
+	return string(data)
+}
+
+// newProfileChange builds a change and rates it
+func newProfileChange(section, key, change, field string, before, after interface{}) ProfileChange {
+	return ProfileChange{
+		Section:  section,
+		Key:      key,
+		Change:   change,
+		Field:    field,
+		Before:   before,
+		After:    after,
+		Severity: driftSeverity(section, change, field, after),
+	}
+}
+
+// driftSeverity rates a change: new or changed accounts and privileges are
+// high, new or changed persistence such as services, startup items and
+// scheduled tasks medium, and anything removed or otherwise changed low
+func driftSeverity(section, change, field string, after interface{}) string {
+	name := strings.ToLower(section + "." + field)
+	if change == DriftRemoved {
+		return "low"
+	}
+	for _, word := range []string{"user", "account", "group", "admin", "privilege", "sudo"} {
+		if strings.Contains(name, word) {
+			return "high"
+		}
+	}
+	if strings.Contains(strings.ToLower(stringValue(after)), "admin") {
+		return "high"
+	}
+	for _, word := range []string{"service", "startup", "task", "autorun", "run_key", "cron", "driver", "listening"} {
+		if strings.Contains(name, word) {
+			return "medium"
+		}
+	}
+	return "low"
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]interface{}{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinSection(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
+
+func sectionOrHost(section string) string {
+	if section == "" {
+		return "host"
+	}
+	return section
+}
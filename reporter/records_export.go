@@ -0,0 +1,357 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/jsonstream"
+	"github.com/redtriage/redtriage/internal/naming"
+)
+
+// RecordFilter rewrites a record as it is exported, such as to redact it.
+// It must keep the record's fields.
+type RecordFilter func(map[string]interface{}) map[string]interface{}
+
+// RecordTable is a named list of artifact records, such as the processes of
+// a collection, exported to its own file
+type RecordTable struct {
+	Name    string
+	Records []map[string]interface{}
+	Filter  RecordFilter // optional
+}
+
+// Columns returns the fields present in any record, sorted by name
+func (t RecordTable) Columns() []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, record := range t.Records {
+		for key := range record {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// MissingFields returns the requested fields that no record of the table has
+func (t RecordTable) MissingFields(fields []string) []string {
+	present := make(map[string]bool)
+	for _, column := range t.Columns() {
+		present[column] = true
+	}
+	var missing []string
+	for _, field := range fields {
+		if !present[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// ExportRecords writes each table to outputDir in the given format (json,
+// csv, csv-excel or md), named <table>.<ext> or by namer. Only the given columns are
+// written, in order; with none, every field of the table is written.
+func ExportRecords(tables []RecordTable, columns []string, format, outputDir string, namer *naming.Namer) ([]ReportInfo, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	var reports []ReportInfo
+	for _, table := range tables {
+		tableColumns := columns
+		if len(tableColumns) == 0 {
+			tableColumns = table.Columns()
+		}
+
+		data, ext, err := encodeRecords(table, tableColumns, format)
+		if err != nil {
+			return reports, err
+		}
+
+		name := exportFileComponent(table.Name)
+		path, err := namer.Path(outputDir, name, ext, name+"."+ext)
+		if err != nil {
+			return reports, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return reports, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		reports = append(reports, ReportInfo{
+			Type: format,
+			Path: path,
+			Size: int64(len(data)),
+		})
+	}
+
+	return reports, nil
+}
+
+// encodeRecords renders a record table with the given columns and returns
+// the file extension
+func encodeRecords(table RecordTable, columns []string, format string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	encoder, err := newRecordEncoder(&buf, table.Name, columns, format, len(table.Records))
+	if err != nil {
+		return nil, "", err
+	}
+	for _, record := range table.Records {
+		if table.Filter != nil {
+			record = table.Filter(record)
+		}
+		if err := encoder.write(record); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := encoder.close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), encoder.ext, nil
+}
+
+// MaxTableRows is the most records a Markdown table shows. The rest are
+// counted in a note and only written by the json and csv formats.
+const MaxTableRows = 1000
+
+// CSVExcel is the CSV export variant for Excel: it starts with a UTF-8 byte
+// order mark, without which Excel reads the file in the ANSI code page, and
+// ends lines with CRLF
+const CSVExcel = "csv-excel"
+
+// exportExtension returns the file extension of an export format
+func exportExtension(format string) string {
+	if format == CSVExcel {
+		return "csv"
+	}
+	return format
+}
+
+// newCSVWriter starts a CSV export, with the byte order mark and CRLF line
+// endings of CSVExcel when format asks for them
+func newCSVWriter(w io.Writer, format string) *csv.Writer {
+	if format == CSVExcel {
+		io.WriteString(w, "\ufeff")
+	}
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = format == CSVExcel
+	return writer
+}
+
+// recordEncoder writes records one at a time in an export format
+type recordEncoder struct {
+	w       io.Writer
+	csv     *csv.Writer
+	name    string
+	columns []string
+	format  string
+	ext     string
+	written int
+	hidden  int
+}
+
+// newRecordEncoder writes the start of an export. total is the number of
+// records shown in the Markdown header, or -1 when it is not known yet.
+func newRecordEncoder(w io.Writer, name string, columns []string, format string, total int) (*recordEncoder, error) {
+	e := &recordEncoder{w: w, name: name, columns: columns, format: format, ext: exportExtension(format)}
+	switch format {
+	case "json":
+		io.WriteString(w, "[")
+	case "csv", CSVExcel:
+		e.csv = newCSVWriter(w, format)
+		e.csv.Write(columns)
+	case "md":
+		fmt.Fprintf(w, "# RedTriage Artifacts: %s\n\n", name)
+		fmt.Fprintf(w, "**Generated:** %s\n", time.Now().Format(time.RFC3339))
+		if total >= 0 {
+			fmt.Fprintf(w, "**Records:** %d\n", total)
+		}
+		io.WriteString(w, "\n")
+		if len(columns) > 0 {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(columns, " | "))
+			fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(columns)))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export format '%s': must be json, csv, csv-excel or md", format)
+	}
+	return e, nil
+}
+
+// write adds one record
+func (e *recordEncoder) write(record map[string]interface{}) error {
+	switch e.format {
+	case "json":
+		// Objects are written field by field so they keep the column order
+		if e.written > 0 {
+			io.WriteString(e.w, ",")
+		}
+		io.WriteString(e.w, "\n  {")
+		for j, column := range e.columns {
+			key, _ := json.Marshal(column)
+			value, err := json.Marshal(record[column])
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s.%s: %w", e.name, column, err)
+			}
+			if j > 0 {
+				io.WriteString(e.w, ",")
+			}
+			fmt.Fprintf(e.w, "\n    %s: %s", key, value)
+		}
+		io.WriteString(e.w, "\n  }")
+	case "csv", CSVExcel:
+		row := make([]string, len(e.columns))
+		for i, column := range e.columns {
+			row[i] = recordCell(record[column])
+		}
+		e.csv.Write(row)
+	case "md":
+		if len(e.columns) == 0 {
+			return nil
+		}
+		if e.written >= MaxTableRows {
+			e.hidden++
+			return nil
+		}
+		cells := make([]string, len(e.columns))
+		for i, column := range e.columns {
+			cells[i] = strings.ReplaceAll(recordCell(record[column]), "|", `\|`)
+		}
+		fmt.Fprintf(e.w, "| %s |\n", strings.Join(cells, " | "))
+	}
+	e.written++
+	return nil
+}
+
+// close writes the end of the export
+func (e *recordEncoder) close() error {
+	switch e.format {
+	case "json":
+		_, err := io.WriteString(e.w, "\n]\n")
+		return err
+	case "csv", CSVExcel:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	case "md":
+		if e.hidden > 0 {
+			fmt.Fprintf(e.w, "\n_... %d more records not shown; export as csv or json for the full set._\n", e.hidden)
+		}
+	}
+	return nil
+}
+
+// recordCell formats a record value for a CSV or Markdown cell. Nested
+// values are written as JSON.
+func recordCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return stringValue(v)
+	}
+}
+
+// RecordStream is a record list in a JSON file that is read one record at
+// a time, for lists such as event log exports too large to hold in memory
+type RecordStream struct {
+	Name   string
+	Path   string
+	Keys   []string     // object keys the list may be stored under
+	Filter RecordFilter // optional
+}
+
+// scan reads every record of the stream and calls fn with it
+func (s RecordStream) scan(ctx context.Context, fn func(map[string]interface{}) error) (int, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", s.Path, err)
+	}
+	defer file.Close()
+	return jsonstream.Records(ctx, bufio.NewReaderSize(file, 1<<20), s.Keys, fn)
+}
+
+// ExportRecordStream writes a record stream to outputDir like ExportRecords,
+// holding one record in memory at a time. Without columns, or for md, the
+// file is read twice: once for the columns and record count, once to write.
+func ExportRecordStream(ctx context.Context, stream RecordStream, columns []string, format, outputDir string, namer *naming.Namer) (ReportInfo, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return ReportInfo{}, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	total := -1
+	if len(columns) == 0 || format == "md" {
+		seen := make(map[string]bool)
+		var found []string
+		count, err := stream.scan(ctx, func(record map[string]interface{}) error {
+			for key := range record {
+				if !seen[key] {
+					seen[key] = true
+					found = append(found, key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return ReportInfo{}, err
+		}
+		if len(columns) == 0 {
+			sort.Strings(found)
+			columns = found
+		}
+		total = count
+	}
+
+	name := exportFileComponent(stream.Name)
+	ext := exportExtension(format)
+	path, err := namer.Path(outputDir, name, ext, name+"."+ext)
+	if err != nil {
+		return ReportInfo{}, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return ReportInfo{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer file.Close()
+	out := bufio.NewWriterSize(file, 1<<20)
+
+	encoder, err := newRecordEncoder(out, stream.Name, columns, format, total)
+	if err != nil {
+		return ReportInfo{}, err
+	}
+	write := encoder.write
+	if stream.Filter != nil {
+		write = func(record map[string]interface{}) error { return encoder.write(stream.Filter(record)) }
+	}
+	if _, err := stream.scan(ctx, write); err != nil {
+		return ReportInfo{}, err
+	}
+	if err := encoder.close(); err != nil {
+		return ReportInfo{}, err
+	}
+	if err := out.Flush(); err != nil {
+		return ReportInfo{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return ReportInfo{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return ReportInfo{Type: format, Path: path, Size: info.Size()}, nil
+}
@@ -0,0 +1,95 @@
+package reporter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/internal/redact"
+)
+
+// writeTestEventLog writes records Security log entries as {"host": ...,
+// "events": [...]}, every tenth a failed logon
+func writeTestEventLog(t testing.TB, path string, records int) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriterSize(file, 1<<20)
+	w.WriteString(`{"host": "TEST-WS01", "events": [`)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < records; i++ {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		eventID := 4624
+		if i%10 == 0 {
+			eventID = 4625
+		}
+		fmt.Fprintf(w, `
+  {"event_id": %d, "provider": "Microsoft-Windows-Security-Auditing", "channel": "Security", "computer": "TEST-WS01", "record_id": %d, "time_created": %q, "data": {"TargetUserName": "user%d", "IpAddress": "10.0.%d.%d", "LogonType": "3"}}`,
+			eventID, i+1, start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), i%50, (i/256)%256, i%256)
+	}
+	w.WriteString("\n]}\n")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkRedacted fails when an export holds any of the sensitive values or
+// lacks any of the expected ones
+func checkRedacted(t *testing.T, path string, sensitive, expected []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, value := range sensitive {
+		if strings.Contains(text, value) {
+			t.Errorf("%s still contains %q", filepath.Base(path), value)
+		}
+	}
+	for _, value := range expected {
+		if !strings.Contains(text, value) {
+			t.Errorf("%s does not contain %q", filepath.Base(path), value)
+		}
+	}
+}
+
+func TestExportRecordsRedacts(t *testing.T) {
+	ruleset := redact.Default()
+	processes := []map[string]interface{}{
+		{"name": "powershell.exe", "pid": 4242, "user": `CORP\alice`, "command_line": `powershell -File C:\Users\alice\run.ps1 -password=Hunter2 -to alice@corp.example`},
+		{"name": "ssh", "pid": 77, "user": "bob", "command_line": "ssh admin@10.0.0.5 -i /home/bob/key", "sid": "S-1-5-21-1004336348-1177238915-682003330-1001"},
+	}
+	table := RecordTable{Name: "processes", Records: processes, Filter: ruleset.Record}
+	reports, err := ExportRecords([]RecordTable{table}, nil, "csv", t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("ExportRecords: %v", err)
+	}
+	checkRedacted(t, reports[0].Path, []string{"alice", "bob", "Hunter2", "10.0.0.5", "1004336348"},
+		[]string{"[REDACTED:account]", `C:\Users\[REDACTED:profile]\run.ps1`, "-password=[REDACTED:secret]", "[REDACTED:email]", "[REDACTED:sid]", "powershell.exe"})
+	if processes[0]["user"] != `CORP\alice` {
+		t.Error("redaction modified the exported records")
+	}
+}
+
+func TestExportRecordStreamRedacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	writeTestEventLog(t, path, 200)
+	stream := RecordStream{Name: "event_records", Path: path, Keys: []string{"events"}, Filter: redact.Default().Record}
+	report, err := ExportRecordStream(context.Background(), stream, nil, "json", t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("ExportRecordStream: %v", err)
+	}
+	checkRedacted(t, report.Path, []string{"TEST-WS01", `"user1"`, "10.0."}, []string{"[REDACTED:host]", "[REDACTED:account]", "[REDACTED:ipv4]"})
+}
@@ -0,0 +1,111 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redtriage/redtriage/internal/heapsample"
+)
+
+// streamTestRecords is the size of the event log TestExportRecordStream
+// exports
+const streamTestRecords = 50000
+
+// The benchmark's event log size and the heap growth allowed while
+// exporting an event log, e.g.
+// go test -bench ExportRecordStream ./reporter -args -stream-records 5000000 -stream-max-heap-mb 32
+var (
+	streamBenchRecords = flag.Int("stream-records", 1000000, "records in the event log BenchmarkExportRecordStream exports")
+	streamMaxHeapMB    = flag.Uint64("stream-max-heap-mb", 64, "heap growth in MB allowed while exporting an event log")
+)
+
+func TestExportRecordStream(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "event_records.json")
+	writeTestEventLog(t, path, streamTestRecords)
+	stream := RecordStream{Name: "event_records", Path: path, Keys: []string{"events"}}
+	outputDir := filepath.Join(dir, "export")
+
+	sampler := heapsample.Start()
+	csvReport, err := ExportRecordStream(context.Background(), stream, nil, "csv", outputDir, nil)
+	if err == nil {
+		_, err = ExportRecordStream(context.Background(), stream, []string{"event_id", "record_id"}, "md", outputDir, nil)
+	}
+	peak := sampler.Stop()
+	if err != nil {
+		t.Fatalf("ExportRecordStream: %v", err)
+	}
+
+	if peakMB := peak >> 20; peakMB > *streamMaxHeapMB {
+		t.Errorf("heap grew by %d MB while exporting %d records, more than %d MB", peakMB, streamTestRecords, *streamMaxHeapMB)
+	}
+	if lines := countLines(t, csvReport.Path); lines != streamTestRecords+1 {
+		t.Errorf("CSV export has %d lines, want %d", lines, streamTestRecords+1)
+	}
+	md, err := os.ReadFile(filepath.Join(outputDir, "event_records.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(md, []byte(fmt.Sprintf("%d more records not shown", streamTestRecords-MaxTableRows))) {
+		t.Errorf("Markdown export of %d records is not capped at %d rows", streamTestRecords, MaxTableRows)
+	}
+}
+
+func TestExportRecordStreamStopsWhenCancelled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "event_records.json")
+	writeTestEventLog(t, path, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream := RecordStream{Name: "event_records", Path: path, Keys: []string{"events"}}
+	if _, err := ExportRecordStream(ctx, stream, nil, "csv", dir, nil); err == nil {
+		t.Error("cancelled export completed")
+	}
+}
+
+func BenchmarkExportRecordStream(b *testing.B) {
+	dir := b.TempDir()
+	path := filepath.Join(dir, "event_records.json")
+	writeTestEventLog(b, path, *streamBenchRecords)
+	stream := RecordStream{Name: "event_records", Path: path, Keys: []string{"events"}}
+
+	b.ResetTimer()
+	sampler := heapsample.Start()
+	for i := 0; i < b.N; i++ {
+		if _, err := ExportRecordStream(context.Background(), stream, nil, "csv", filepath.Join(dir, "export"), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	peak := sampler.Stop()
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	if peakMB := peak >> 20; peakMB > *streamMaxHeapMB {
+		b.Errorf("heap grew by %d MB while exporting %d records, more than %d MB", peakMB, *streamBenchRecords, *streamMaxHeapMB)
+	}
+}
+
+// countLines counts the lines of a file
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// maxReportWorkers bounds how many reports are generated at once
+const maxReportWorkers = 4
+
+// ReportResult is the outcome of one report generator
+type ReportResult struct {
+	Name   string     `json:"name"`
+	Report ReportInfo `json:"report"`
+	Err    error      `json:"-"`
+}
+
+// reportGenerator writes one report into reportsDir and returns its path
+type reportGenerator struct {
+	name     string
+	generate func(data ReportData, reportsDir string) (string, error)
+}
+
+// reportGenerators returns every report GenerateEnhancedReports produces
+func (er *EnhancedReporter) reportGenerators() []reportGenerator {
+	generators := make([]reportGenerator, 0, 10)
+	for _, format := range []string{"html", "json", "csv", "xml"} {
+		format := format
+		generators = append(generators, reportGenerator{format, func(data ReportData, reportsDir string) (string, error) {
+			return er.generateReportInFormat(data, format, reportsDir)
+		}})
+	}
+
+	return append(generators,
+		reportGenerator{"executive", er.generateExecutiveSummary},
+		reportGenerator{"technical", er.generateTechnicalReport},
+		reportGenerator{"timeline", er.generateTimelineReport},
+		reportGenerator{"network", er.generateNetworkReport},
+		reportGenerator{"user_activity", er.generateUserActivityReport},
+		reportGenerator{"security", er.generateSecurityReport},
+	)
+}
+
+// runReportGenerators runs the generators on a bounded pool and returns one
+// result per generator, in generator order. A panicking generator is
+// reported as a failure like any other, and generators not started before
+// ctx is cancelled fail with ctx.Err().
+func (er *EnhancedReporter) runReportGenerators(ctx context.Context, generators []reportGenerator, data ReportData, reportsDir string) []ReportResult {
+	results := make([]ReportResult, len(generators))
+	slots := make(chan struct{}, maxReportWorkers)
+
+	var wg sync.WaitGroup
+	for i, generator := range generators {
+		wg.Add(1)
+		go func(i int, generator reportGenerator) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result := ReportResult{Name: generator.name}
+			if err := ctx.Err(); err != nil {
+				result.Err = err
+				results[i] = result
+				return
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					result.Err = fmt.Errorf("report generator panicked: %v", r)
+				}
+				results[i] = result
+			}()
+
+			path, err := generator.generate(data, reportsDir)
+			if err != nil {
+				result.Err = err
+				return
+			}
+			if result.Report, err = er.getReportInfo(path); err != nil {
+				result.Err = fmt.Errorf("failed to read generated report: %w", err)
+			}
+		}(i, generator)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// ProducedReports returns the reports that were generated successfully
+func ProducedReports(results []ReportResult) []ReportInfo {
+	var reports []ReportInfo
+	for _, result := range results {
+		if result.Err == nil {
+			reports = append(reports, result.Report)
+		}
+	}
+	return reports
+}
+
+// FailedReports returns the results of the reports that could not be
+// generated
+func FailedReports(results []ReportResult) []ReportResult {
+	var failed []ReportResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
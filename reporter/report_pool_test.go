@@ -0,0 +1,124 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+func TestReportGeneratorFailureDoesNotStopTheOthers(t *testing.T) {
+	er := NewEnhancedReporter()
+	dir := t.TempDir()
+	broken := errors.New("disk on fire")
+	writer := func(name string) func(ReportData, string) (string, error) {
+		return func(_ ReportData, reportsDir string) (string, error) {
+			path := filepath.Join(reportsDir, name+".txt")
+			return path, os.WriteFile(path, []byte(name), 0644)
+		}
+	}
+	generators := []reportGenerator{
+		{"first", writer("first")},
+		{"failing", func(ReportData, string) (string, error) { return "", broken }},
+		{"panicking", func(ReportData, string) (string, error) { panic("nil map") }},
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		generators = append(generators, reportGenerator{name, writer(name)})
+	}
+
+	results := er.runReportGenerators(context.Background(), generators, ReportData{}, dir)
+	if len(results) != len(generators) {
+		t.Fatalf("got %d results for %d generators", len(results), len(generators))
+	}
+	for i, result := range results {
+		if result.Name != generators[i].name {
+			t.Errorf("result %d is for %s, want %s", i, result.Name, generators[i].name)
+		}
+	}
+
+	failed := FailedReports(results)
+	if len(failed) != 2 || !errors.Is(failed[0].Err, broken) || failed[1].Name != "panicking" {
+		t.Fatalf("failures not surfaced: %+v", failed)
+	}
+	produced := ProducedReports(results)
+	if len(produced) != len(generators)-2 {
+		t.Errorf("%d reports produced, want %d", len(produced), len(generators)-2)
+	}
+	for _, report := range produced {
+		if _, err := os.Stat(report.Path); err != nil {
+			t.Errorf("produced report missing: %v", err)
+		}
+	}
+}
+
+func TestReportGeneratorsNotStartedAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	results := NewEnhancedReporter().runReportGenerators(ctx, []reportGenerator{
+		{"late", func(ReportData, string) (string, error) { ran = true; return "", nil }},
+	}, ReportData{}, t.TempDir())
+	if ran || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("generator ran after cancellation (result %+v)", results[0])
+	}
+}
+
+// TestCancelledReportGenerationCleansUp starts report generation on a large
+// log artifact, cancels it once the log parser has its temporary file, and
+// checks that it returns context.Canceled promptly, without writing reports
+// or leaving the temporary file behind
+func TestCancelledReportGenerationCleansUp(t *testing.T) {
+	// A log of this size takes seconds to analyze in full
+	const lines = 200000
+	var log strings.Builder
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&log, "%s [ERROR] authentication failed for user%d from 10.0.%d.%d via powershell iex\n",
+			start.Add(time.Duration(i)*time.Second).Format("2006-01-02T15:04:05"), i%50, (i/256)%256, i%256)
+	}
+	artifact := collector.NewBaseArtifact("system_log", "Large system log", "log", "text").Artifact
+	artifacts := []collector.ArtifactResult{{Artifact: artifact, Data: log.String()}}
+
+	tmp := t.TempDir()
+	for _, name := range []string{"TMPDIR", "TMP", "TEMP"} {
+		t.Setenv(name, tmp)
+	}
+	pattern := filepath.Join(tmp, "redtriage_log_*.tmp")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelled := make(chan time.Time, 1)
+	go func() {
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) && ctx.Err() == nil {
+			if files, _ := filepath.Glob(pattern); len(files) > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancelled <- time.Now()
+		cancel()
+	}()
+
+	bundle := t.TempDir()
+	results, err := NewEnhancedReporter().GenerateEnhancedReports(ctx, artifacts, nil, bundle)
+	returned := time.Now()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("report generation returned %d results and error %v, want context.Canceled", len(results), err)
+	}
+	if delay := returned.Sub(<-cancelled); delay > time.Second {
+		t.Errorf("report generation returned %s after it was cancelled", delay)
+	}
+	if leaked, _ := filepath.Glob(pattern); len(leaked) > 0 {
+		t.Errorf("cancelled report generation left %s behind", strings.Join(leaked, ", "))
+	}
+	if _, err := os.Stat(filepath.Join(bundle, "reports")); err == nil {
+		t.Error("cancelled report generation wrote reports")
+	}
+}
@@ -2,6 +2,7 @@ package reporter
 
 import (
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,11 +11,16 @@ import (
 
 	"github.com/redtriage/redtriage/collector"
 	"github.com/redtriage/redtriage/detector"
+	"github.com/redtriage/redtriage/internal/naming"
 )
 
 // Reporter represents the reporting engine
 type Reporter struct {
 	version string
+	// reportNamer and exportNamer name report and export files; nil keeps
+	// the built-in names
+	reportNamer *naming.Namer
+	exportNamer *naming.Namer
 }
 
 // ReportInfo represents information about a generated report
@@ -32,13 +38,39 @@ func NewReporter() *Reporter {
 	}
 }
 
-// GenerateReports generates all report types
+// SetNaming names the reports and findings exports written from now on from
+// filename templates
+func (r *Reporter) SetNaming(reports, exports *naming.Namer) {
+	r.reportNamer = reports
+	r.exportNamer = exports
+}
+
+// reportPath returns where the report with the given built-in name is
+// written in reportsDir
+func (r *Reporter) reportPath(reportsDir, defaultName string) (string, error) {
+	ext := filepath.Ext(defaultName)
+	return r.reportNamer.Path(reportsDir, strings.TrimSuffix(defaultName, ext), strings.TrimPrefix(ext, "."), defaultName)
+}
+
+// GenerateReports generates all report types into the reports directory of
+// the bundle
 func (r *Reporter) GenerateReports(artifacts []collector.ArtifactResult, findings []detector.Finding, bundlePath string) ([]ReportInfo, error) {
-	var reports []ReportInfo
-	
 	// Get bundle directory
 	bundleDir := strings.TrimSuffix(bundlePath, ".zip")
-	reportsDir := filepath.Join(bundleDir, "reports")
+	return r.GenerateReportsTo(artifacts, findings, filepath.Join(bundleDir, "reports"))
+}
+
+// GenerateReportsTo generates all report types into reportsDir, creating it
+// if needed
+func (r *Reporter) GenerateReportsTo(artifacts []collector.ArtifactResult, findings []detector.Finding, reportsDir string) ([]ReportInfo, error) {
+	var reports []ReportInfo
+	
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create reports directory: %w", err)
+	}
+	if identity, ok := collector.FindHostIdentity(artifacts); ok {
+		r.reportNamer.SetHost(identity.Hostname)
+	}
 	
 	// Generate Markdown summary
 	if summaryPath, err := r.generateMarkdownSummary(artifacts, findings, reportsDir); err == nil {
@@ -55,7 +87,9 @@ func (r *Reporter) GenerateReports(artifacts []collector.ArtifactResult, finding
 	}
 	
 	// Generate Markdown findings report
-	if findingsPath, err := r.generateFindingsReport(findings, reportsDir); err == nil {
+	identity, _ := collector.FindHostIdentity(artifacts)
+	gaps := detector.CoverageGaps(artifacts)
+	if findingsPath, err := r.generateFindingsReport(findings, identity, gaps, reportsDir); err == nil {
 		if info, err := r.getReportInfo(findingsPath); err == nil {
 			reports = append(reports, info)
 		}
@@ -66,7 +100,10 @@ func (r *Reporter) GenerateReports(artifacts []collector.ArtifactResult, finding
 
 // generateMarkdownSummary generates a concise Markdown summary
 func (r *Reporter) generateMarkdownSummary(artifacts []collector.ArtifactResult, findings []detector.Finding, reportsDir string) (string, error) {
-	summaryPath := filepath.Join(reportsDir, "summary.md")
+	summaryPath, err := r.reportPath(reportsDir, "summary.md")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(summaryPath)
 	if err != nil {
@@ -79,6 +116,11 @@ func (r *Reporter) generateMarkdownSummary(artifacts []collector.ArtifactResult,
 	fmt.Fprintf(file, "**Generated:** %s\n", time.Now().Format(time.RFC3339))
 	fmt.Fprintf(file, "**Tool Version:** %s\n\n", r.version)
 	
+	identity, hasIdentity := collector.FindHostIdentity(artifacts)
+	if hasIdentity {
+		fmt.Fprint(file, hostIdentityMarkdown(identity))
+	}
+	
 	// Write host profile
 	if hostProfile := r.findHostProfile(artifacts); hostProfile != nil {
 		fmt.Fprintf(file, "## Host Profile\n\n")
@@ -102,11 +144,14 @@ func (r *Reporter) generateMarkdownSummary(artifacts []collector.ArtifactResult,
 		fmt.Fprintf(file, "- **%s:** %d artifacts\n", category, count)
 	}
 	fmt.Fprintf(file, "\n")
+	fmt.Fprint(file, notCollectedMarkdown(artifacts))
+	fmt.Fprint(file, collectionScopeMarkdown(artifacts))
 	
 	// Write findings summary
+	gaps := detector.CoverageGaps(artifacts)
 	fmt.Fprintf(file, "## Findings Summary\n\n")
 	if len(findings) == 0 {
-		fmt.Fprintf(file, "No findings detected.\n\n")
+		fmt.Fprintf(file, "%s\n\n", noFindingsText(gaps))
 	} else {
 		findingsBySeverity := r.groupFindingsBySeverity(findings)
 		for severity, count := range findingsBySeverity {
@@ -115,7 +160,7 @@ func (r *Reporter) generateMarkdownSummary(artifacts []collector.ArtifactResult,
 		fmt.Fprintf(file, "\n")
 		
 		// List high and critical findings
-		highFindings := r.filterFindingsBySeverity(findings, "high")
+		highFindings := r.filterFindingsBySeverity(findings, detector.SeverityHigh)
 		if len(highFindings) > 0 {
 			fmt.Fprintf(file, "### High Priority Findings\n\n")
 			for _, finding := range highFindings {
@@ -132,18 +177,28 @@ func (r *Reporter) generateMarkdownSummary(artifacts []collector.ArtifactResult,
 		fmt.Fprintf(file, "2. Investigate high and critical findings immediately\n")
 		fmt.Fprintf(file, "3. Correlate findings with other evidence sources\n")
 		fmt.Fprintf(file, "4. Document investigation steps and conclusions\n")
+	} else if len(gaps) > 0 {
+		fmt.Fprintf(file, "1. Re-collect the artifacts that could not be examined, with administrator rights or the missing tools, before concluding the host is clean\n")
+		fmt.Fprintf(file, "2. Review collected artifacts for manual analysis\n")
 	} else {
 		fmt.Fprintf(file, "1. No immediate threats detected\n")
 		fmt.Fprintf(file, "2. Review collected artifacts for manual analysis\n")
 		fmt.Fprintf(file, "3. Consider additional collection if needed\n")
 	}
 	
+	if hasIdentity {
+		fmt.Fprintf(file, "\n---\n%s\n", hostFooter(identity))
+	}
+	
 	return summaryPath, nil
 }
 
 // generateHTMLReport generates a comprehensive HTML report
 func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, findings []detector.Finding, reportsDir string) (string, error) {
-	htmlPath := filepath.Join(reportsDir, "full_report.html")
+	htmlPath, err := r.reportPath(reportsDir, "full_report.html")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(htmlPath)
 	if err != nil {
@@ -170,6 +225,12 @@ func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, find
         table { border-collapse: collapse; width: 100%%; }
         th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
         th { background-color: #f2f2f2; }
+        .reason-not_present { color: #777; }
+        .reason-access_denied { background: #ffe3e3; color: #a61e1e; font-weight: bold; }
+        .reason-tool_missing { background: #fff4d6; color: #8a5a00; font-weight: bold; }
+        .reason-timeout { background: #ffe9d6; color: #a34700; font-weight: bold; }
+        .reason-truncated { background: #e7f3fd; color: #1b5e8c; }
+        .reason-scope_denied { color: #5f3dc4; }
     </style>
 </head>
 <body>
@@ -183,6 +244,12 @@ func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, find
 	// Write host profile section
 	fmt.Fprintf(file, `<div class="section">
     <h2>Host Profile</h2>`)
+	identity, hasIdentity := collector.FindHostIdentity(artifacts)
+	if hasIdentity {
+		rows, notice := hostIdentityHTML(identity)
+		fmt.Fprintf(file, `<table>
+        <tr><th>Property</th><th>Value</th></tr>%s</table>%s`, rows, notice)
+	}
 	if hostProfile := r.findHostProfile(artifacts); hostProfile != nil {
 		if hostData, ok := hostProfile.Data.(map[string]interface{}); ok {
 			fmt.Fprintf(file, `<table>
@@ -204,23 +271,25 @@ func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, find
 	fmt.Fprintf(file, `<div class="section">
     <h2>Collected Artifacts</h2>
     <table>
-        <tr><th>Name</th><th>Category</th><th>Type</th><th>Size</th><th>Description</th></tr>`)
+        <tr><th>Name</th><th>Category</th><th>Type</th><th>Size</th><th>Status</th><th>Description</th></tr>`)
 	for _, artifact := range artifacts {
 		fmt.Fprintf(file, `<tr>
             <td>%s</td>
             <td>%s</td>
             <td>%s</td>
             <td>%d bytes</td>
+            %s
             <td>%s</td>
-        </tr>`, artifact.Artifact.Name, artifact.Artifact.Category, artifact.Artifact.Type, artifact.Size, artifact.Artifact.Description)
+        </tr>`, artifact.Artifact.Name, artifact.Artifact.Category, artifact.Artifact.Type, artifact.Size, statusCellHTML(artifact), artifact.Artifact.Description)
 	}
 	fmt.Fprintf(file, `</table></div>`)
+	fmt.Fprint(file, collectionScopeHTML(artifacts))
 	
 	// Write findings section
 	fmt.Fprintf(file, `<div class="section">
     <h2>Detection Findings</h2>`)
 	if len(findings) == 0 {
-		fmt.Fprintf(file, `<p>No findings detected.</p>`)
+		fmt.Fprintf(file, `<p>%s</p>`, html.EscapeString(noFindingsText(detector.CoverageGaps(artifacts))))
 	} else {
 		for _, finding := range findings {
 			severityClass := strings.ToLower(finding.Severity)
@@ -234,7 +303,7 @@ func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, find
 			if len(finding.Evidence) > 0 {
 				fmt.Fprintf(file, `<p><strong>Evidence:</strong></p><ul>`)
 				for _, evidence := range finding.Evidence {
-					fmt.Fprintf(file, `<li>%s: %s (Confidence: %.1f%%)</li>`, evidence.Type, evidence.Description, evidence.Confidence*100)
+					fmt.Fprintf(file, `<li>%s: %s (Confidence: %.1f%%)%s</li>`, evidence.Type, evidence.Description, evidence.Confidence*100, evidenceRecordHTML(evidence.Ref, artifacts))
 				}
 				fmt.Fprintf(file, `</ul>`)
 			}
@@ -245,21 +314,30 @@ func (r *Reporter) generateHTMLReport(artifacts []collector.ArtifactResult, find
 	fmt.Fprintf(file, `</div>`)
 	
 	// Write footer
+	hostLine := ""
+	if hasIdentity {
+		hostLine = fmt.Sprintf("\n        <p>%s</p>", html.EscapeString(hostFooter(identity)))
+	}
 	fmt.Fprintf(file, `
     <div class="section">
         <h2>Report Information</h2>
         <p>This report was generated by RedTriage, a professional incident response triage tool.</p>
-        <p>For questions or support, please refer to the RedTriage documentation.</p>
+        <p>For questions or support, please refer to the RedTriage documentation.</p>%s
     </div>
 </body>
-</html>`)
+</html>`, hostLine)
 	
 	return htmlPath, nil
 }
 
-// generateFindingsReport generates a detailed Markdown findings report
-func (r *Reporter) generateFindingsReport(findings []detector.Finding, reportsDir string) (string, error) {
-	findingsPath := filepath.Join(reportsDir, "findings.md")
+// generateFindingsReport generates a detailed Markdown findings report. The
+// host is named when the collection recorded its identity, and artifacts
+// the detections could not examine are listed.
+func (r *Reporter) generateFindingsReport(findings []detector.Finding, identity collector.HostIdentity, gaps []detector.CoverageGap, reportsDir string) (string, error) {
+	findingsPath, err := r.reportPath(reportsDir, "findings.md")
+	if err != nil {
+		return "", err
+	}
 	
 	file, err := os.Create(findingsPath)
 	if err != nil {
@@ -270,20 +348,42 @@ func (r *Reporter) generateFindingsReport(findings []detector.Finding, reportsDi
 	// Write header
 	fmt.Fprintf(file, "# RedTriage Findings Report\n\n")
 	fmt.Fprintf(file, "**Generated:** %s\n", time.Now().Format(time.RFC3339))
+	if identity.Offline() {
+		fmt.Fprintf(file, "**Mode:** %s\n", offlineLabel(identity))
+	}
+	if identity.Fingerprint != "" {
+		fmt.Fprintf(file, "**Host:** %s\n", identity.Hostname)
+		fmt.Fprintf(file, "**Host Fingerprint:** %s\n", identity.Fingerprint)
+		if notice := hostConflictNotice(identity); notice != "" {
+			fmt.Fprintf(file, "**Warning:** %s\n", notice)
+		}
+	}
 	fmt.Fprintf(file, "**Total Findings:** %d\n\n", len(findings))
+	if len(gaps) > 0 {
+		fmt.Fprintf(file, "**Not Examined:**\n\n")
+		for _, gap := range gaps {
+			fmt.Fprintf(file, "- %s\n", gap)
+		}
+		fmt.Fprintf(file, "\n")
+	}
 	
-	if len(findings) == 0 {
+	if len(findings) == 0 && len(gaps) == 0 {
 		fmt.Fprintf(file, "No findings detected during this triage collection.\n")
 		return findingsPath, nil
 	}
+	if len(findings) == 0 {
+		fmt.Fprintf(file, "%s\n", noFindingsText(gaps))
+		return findingsPath, nil
+	}
 	
 	// Group findings by severity
 	findingsBySeverity := r.groupFindingsBySeverity(findings)
 	
 	// Write findings by severity
-	for _, severity := range []string{"critical", "high", "medium", "low"} {
+	for _, level := range []detector.Severity{detector.SeverityCritical, detector.SeverityHigh, detector.SeverityMedium, detector.SeverityLow} {
+		severity := level.String()
 		if count, exists := findingsBySeverity[severity]; exists && count > 0 {
-			severityFindings := r.filterFindingsBySeverity(findings, severity)
+			severityFindings := r.filterFindingsBySeverity(findings, level)
 			
 			fmt.Fprintf(file, "## %s Severity Findings (%d)\n\n", strings.Title(severity), count)
 			
@@ -320,6 +420,10 @@ func (r *Reporter) generateFindingsReport(findings []detector.Finding, reportsDi
 		}
 	}
 	
+	if identity.Fingerprint != "" {
+		fmt.Fprintf(file, "\n---\n%s\n", hostFooter(identity))
+	}
+	
 	return findingsPath, nil
 }
 
@@ -355,29 +459,17 @@ func (r *Reporter) groupFindingsBySeverity(findings []detector.Finding) map[stri
 }
 
 // filterFindingsBySeverity filters findings by minimum severity
-func (r *Reporter) filterFindingsBySeverity(findings []detector.Finding, minSeverity string) []detector.Finding {
-	severityLevels := map[string]int{
-		"low":      1,
-		"medium":   2,
-		"high":     3,
-		"critical": 4,
-	}
-	
-	minLevel := severityLevels[minSeverity]
-	if minLevel == 0 {
-		minLevel = 1
-	}
-	
+func (r *Reporter) filterFindingsBySeverity(findings []detector.Finding, minSeverity detector.Severity) []detector.Finding {
 	var filtered []detector.Finding
 	for _, finding := range findings {
-		if level := severityLevels[finding.Severity]; level >= minLevel {
+		if finding.Level() >= minSeverity {
 			filtered = append(filtered, finding)
 		}
 	}
 	
 	// Sort by severity (highest first)
 	sort.Slice(filtered, func(i, j int) bool {
-		return severityLevels[filtered[i].Severity] > severityLevels[filtered[j].Severity]
+		return filtered[i].Level() > filtered[j].Level()
 	})
 	
 	return filtered
@@ -0,0 +1,145 @@
+package reporter
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redtriage/redtriage/collector"
+	"github.com/redtriage/redtriage/detector"
+)
+
+// generatedReport generates the enhanced reports in a temporary directory
+// and returns the content of the one named name
+func generatedReport(t *testing.T, artifacts []collector.ArtifactResult, findings []detector.Finding, name string) string {
+	t.Helper()
+	results, err := NewEnhancedReporter().GenerateEnhancedReports(context.Background(), artifacts, findings, t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to generate reports: %v", err)
+	}
+	for _, result := range results {
+		if result.Name != name {
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("failed to generate the %s report: %v", name, result.Err)
+		}
+		data, err := os.ReadFile(result.Report.Path)
+		if err != nil {
+			t.Fatalf("failed to read the %s report: %v", name, err)
+		}
+		return string(data)
+	}
+	t.Fatalf("no %s report generated", name)
+	return ""
+}
+
+func TestExecutiveSummaryCountsParsedSeverities(t *testing.T) {
+	findings := []detector.Finding{
+		{RuleID: "RT-C", RuleName: "Critical", Severity: "critical"},
+		{RuleID: "RT-H", RuleName: "High", Severity: "high"},
+		{RuleID: "RT-L", RuleName: "Low", Severity: "low"},
+	}
+	report := generatedReport(t, nil, findings, "executive")
+	for _, want := range []string{"Critical Issues: 1", "High Priority Issues: 2"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("executive summary does not show %q", want)
+		}
+	}
+}
+
+func TestReportsShowNoReplacementCharacters(t *testing.T) {
+	cp850 := collector.ArtifactResult{Artifact: collector.NewBaseArtifact("netstat", "Network connections", "network", "command").Artifact}
+	cp850.SetTextCodePage([]byte("  TCP    0.0.0.0:135    0.0.0.0:0    ABH\x99REN\r\n"), 850)
+	garbage := collector.ArtifactResult{Artifact: collector.NewBaseArtifact("binary_garbage", "UTF-8 text with binary bytes", "host", "command").Artifact}
+	garbage.SetTextCodePage([]byte("Dienst gestartet: Überwachung\x00\xff\xfe aktiv\r\n"), 850)
+
+	results, err := NewEnhancedReporter().GenerateEnhancedReports(context.Background(), []collector.ArtifactResult{cp850, garbage}, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("GenerateEnhancedReports: %v", err)
+	}
+	if failed := FailedReports(results); len(failed) > 0 {
+		t.Fatalf("failed to generate the %s report: %v", failed[0].Name, failed[0].Err)
+	}
+	for _, report := range ProducedReports(results) {
+		data, err := os.ReadFile(report.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsRune(string(data), '�') {
+			t.Errorf("%s report shows replacement characters", report.Type)
+		}
+	}
+}
+
+func TestSummaryReportDocumentsCollectionScope(t *testing.T) {
+	scope := &collector.CollectionScope{Deny: []string{"credentials"}, Statement: "Test authorization"}
+	artifacts, record := collector.ApplyScope([]collector.ArtifactResult{{
+		Artifact: collector.Artifact{Name: "cloud_credentials", Category: "host", Type: "command"},
+		Data:     "token",
+	}}, scope, "INC-TEST", "")
+	artifacts = append(artifacts, collector.CollectionScopeArtifact(record))
+
+	reports, err := NewReporter().GenerateReportsTo(artifacts, nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("GenerateReportsTo: %v", err)
+	}
+	for _, report := range reports {
+		if !strings.HasSuffix(report.Path, ".md") {
+			continue
+		}
+		data, err := os.ReadFile(report.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "## Collection Scope") && strings.Contains(string(data), scope.Statement) {
+			return
+		}
+	}
+	t.Error("no Markdown report documents the collection scope")
+}
+
+func TestTechnicalReportListsAutostartEntries(t *testing.T) {
+	sha256 := "0f3c2e5d8f7e4a1b9c6d2e8f4a7b1c3d5e9f0a2b4c6d8e1f3a5b7c9d0e2f4a6b"
+	autostart := collector.ASEPArtifact([]collector.ASEPEntry{{
+		Group:       "run_keys",
+		Key:         `HKU\alice\Software\Microsoft\Windows\CurrentVersion\Run`,
+		ValueName:   "Updater",
+		Data:        `"%LOCALAPPDATA%\Updater\updater.exe" --silent`,
+		KeyModified: time.Now().Add(-48 * time.Hour).UTC(),
+		User:        "alice",
+		Image:       `C:\Users\alice\AppData\Local\Updater\updater.exe`,
+		SHA256:      sha256,
+	}})
+
+	report := generatedReport(t, []collector.ArtifactResult{autostart}, nil, "technical")
+	if !strings.Contains(report, "<h2>Autostart Entries</h2>") || !strings.Contains(report, sha256) {
+		t.Error("technical report does not list the autostart entries")
+	}
+}
+
+func TestUserActivityReportShowsRemoteAccess(t *testing.T) {
+	recent := time.Now().Add(-36 * time.Hour).UTC().Truncate(time.Second)
+	client := "203.0.113.7"
+	logon := "<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='test'/><EventID>4624</EventID>" +
+		"<TimeCreated SystemTime='" + recent.Format(time.RFC3339Nano) + "'/><EventRecordID>1</EventRecordID><Channel>Security</Channel><Computer>RDP-HOST</Computer></System>" +
+		"<EventData><Data Name='TargetUserName'>alice</Data><Data Name='TargetDomainName'>CORP</Data><Data Name='LogonType'>10</Data><Data Name='IpAddress'>" + client + "</Data></EventData></Event>"
+	history := []collector.RDPConnection{{Source: collector.RDPSourceDefault, User: "alice", Host: "jump01.corp.example", LastWrite: recent}}
+	settings := collector.RDPSettings{Enabled: true, EnabledModified: recent, Port: 3389}
+	tools := []collector.RemoteTool{{Tool: "AnyDesk", Evidence: collector.RemoteToolService, Name: "AnyDesk", InstalledAt: recent, Logs: []string{`C:\ProgramData\AnyDesk\ad_svc.trace`}}}
+	artifacts := []collector.ArtifactResult{
+		collector.RemoteAccessArtifact("rdp_history", "RDP connections", collector.RDPHistoryType, history, len(history)),
+		collector.RemoteAccessArtifact("rdp_sessions", "RDP sessions", collector.RDPSessionsType, logon, 1),
+		collector.RemoteAccessArtifact("rdp_settings", "RDP settings", collector.RDPSettingsType, settings, 1),
+		collector.RemoteAccessArtifact("remote_tools", "Remote access tools", collector.RemoteToolsType, tools, len(tools)),
+	}
+
+	report := generatedReport(t, artifacts, nil, "user_activity")
+	for _, want := range []string{"<h2>Remote Access</h2>", client, "jump01.corp.example", "ad_svc.trace", "not required"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("user activity report does not show %q", want)
+		}
+	}
+}
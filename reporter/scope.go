@@ -0,0 +1,95 @@
+package reporter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/redtriage/redtriage/collector"
+)
+
+// scopeFields are the label and value rows of a collection scope record
+func scopeFields(record collector.ScopeRecord) [][2]string {
+	fields := [][2]string{{"Incident", record.Incident}}
+	if len(record.Scope.Allow) > 0 {
+		fields = append(fields, [2]string{"Allowed", strings.Join(record.Scope.Allow, ", ")})
+	}
+	if len(record.Scope.Deny) > 0 {
+		fields = append(fields, [2]string{"Denied", strings.Join(record.Scope.Deny, ", ")})
+	}
+	if record.Scope.Statement != "" {
+		fields = append(fields, [2]string{"Authorization", record.Scope.Statement})
+	}
+	if record.Scope.SetBy != "" {
+		fields = append(fields, [2]string{"Set by", fmt.Sprintf("%s at %s", record.Scope.SetBy, record.Scope.SetAt.Format("2006-01-02 15:04:05 MST"))})
+	}
+	return fields
+}
+
+// scopeOutcome says whether the collection respected its scope
+func scopeOutcome(record collector.ScopeRecord) string {
+	if record.Override != "" {
+		return fmt.Sprintf("The collection scope was overridden and %d out-of-scope artifacts were collected. Justification: %s", len(record.Skipped), record.Override)
+	}
+	if len(record.Skipped) == 0 {
+		return "The collection respected its scope; every artifact collected was within it."
+	}
+	return fmt.Sprintf("The collection respected its scope; %d artifacts outside it were not collected.", len(record.Skipped))
+}
+
+// collectionScopeMarkdown renders the collection scope section of Markdown
+// reports, or nothing when the collection ran without a scope
+func collectionScopeMarkdown(artifacts []collector.ArtifactResult) string {
+	record, ok := collector.FindScopeRecord(artifacts)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Collection Scope\n\n")
+	for _, field := range scopeFields(record) {
+		fmt.Fprintf(&b, "**%s:** %s\n", field[0], field[1])
+	}
+	fmt.Fprintf(&b, "\n%s\n\n", scopeOutcome(record))
+	if len(record.Skipped) > 0 {
+		b.WriteString("| Artifact | Category | Reason |\n|---|---|---|\n")
+		for _, skip := range record.Skipped {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", skip.Artifact, skip.Category, skip.Reason)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// collectionScopeHTML renders the collection scope section of HTML reports,
+// or nothing when the collection ran without a scope
+func collectionScopeHTML(artifacts []collector.ArtifactResult) string {
+	record, ok := collector.FindScopeRecord(artifacts)
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="section">
+    <h2>Collection Scope</h2>
+    <table>
+        <tr><th>Property</th><th>Value</th></tr>`)
+	for _, field := range scopeFields(record) {
+		fmt.Fprintf(&b, `<tr><td>%s</td><td>%s</td></tr>`, field[0], html.EscapeString(field[1]))
+	}
+	b.WriteString(`</table>`)
+	class := ""
+	if record.Override != "" {
+		class = ` class="finding high"`
+	}
+	fmt.Fprintf(&b, `<p%s>%s</p>`, class, html.EscapeString(scopeOutcome(record)))
+	if len(record.Skipped) > 0 {
+		b.WriteString(`<table>
+        <tr><th>Artifact</th><th>Category</th><th>Reason</th></tr>`)
+		for _, skip := range record.Skipped {
+			fmt.Fprintf(&b, `<tr><td>%s</td><td>%s</td><td>%s</td></tr>`,
+				html.EscapeString(skip.Artifact), html.EscapeString(skip.Category), html.EscapeString(skip.Reason))
+		}
+		b.WriteString(`</table>`)
+	}
+	b.WriteString(`</div>`)
+	return b.String()
+}
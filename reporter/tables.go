@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/redtriage/redtriage/internal/config"
+	"github.com/redtriage/redtriage/internal/output"
+	"github.com/redtriage/redtriage/internal/snapshot"
+)
+
+// IncidentSummary is an incident as 'incident list' shows it
+type IncidentSummary struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Severity       string    `json:"severity"`
+	Status         string    `json:"status"`
+	Analyst        string    `json:"analyst"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Findings       int       `json:"findings"`
+	ActiveFindings int       `json:"active_findings"`
+	// Locked encrypted incidents are summarized from their cleartext stub,
+	// without severity, times or counts
+	Locked bool `json:"locked,omitempty"`
+}
+
+// IncidentsTable lists incidents, one per row
+func IncidentsTable(incidents []IncidentSummary) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "ID"},
+		output.Column{Header: "Title", Max: 30},
+		output.Column{Header: "Severity", Color: output.SeverityColor},
+		output.Column{Header: "Status"},
+		output.Column{Header: "Findings"},
+		output.Column{Header: "Created"},
+	)
+	for _, incident := range incidents {
+		findings := fmt.Sprint(incident.Findings)
+		if incident.ActiveFindings != incident.Findings {
+			findings = fmt.Sprintf("%d (%d active)", incident.Findings, incident.ActiveFindings)
+		}
+		if incident.Locked {
+			table.AddRow(incident.ID, incident.Title, "encrypted", incident.Status, "-", "-")
+			continue
+		}
+		table.AddRow(incident.ID, incident.Title, incident.Severity, incident.Status, findings,
+			incident.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return table
+}
+
+// FindingsTable lists Sigma matches, as stored in a findings report, one per
+// row with the entity each points at
+func FindingsTable(matches []map[string]interface{}) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "Severity", Color: output.SeverityColor},
+		output.Column{Header: "Rule", Max: 40},
+		output.Column{Header: "Category"},
+		output.Column{Header: "Entity", Max: 60},
+		output.Column{Header: "Time"},
+	)
+	for _, match := range matches {
+		evidence, _ := match["evidence"].(map[string]interface{})
+		rule := stringValue(match["rule_title"])
+		if rule == "" {
+			rule = stringValue(match["rule_id"])
+		}
+		seen := stringValue(match["timestamp"])
+		if t, err := time.Parse(time.RFC3339, seen); err == nil {
+			seen = t.Local().Format("2006-01-02 15:04:05")
+		}
+		table.AddRow(strings.ToLower(stringValue(match["level"])), rule, stringValue(match["category"]),
+			orDash(findingEntity(evidence)), seen)
+	}
+	return table
+}
+
+// KeyFindingsTable lists finding groups, one rule per row with its count and
+// example entities
+func KeyFindingsTable(groups []FindingGroup) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "Severity", Color: output.SeverityColor},
+		output.Column{Header: "Rule", Max: 40},
+		output.Column{Header: "Findings"},
+		output.Column{Header: "Examples", Max: 80},
+	)
+	for _, group := range groups {
+		table.AddRow(group.Severity, group.Rule, fmt.Sprint(group.Count), orDash(strings.Join(group.Examples, "; ")))
+	}
+	return table
+}
+
+// SnapshotsTable lists the snapshots of an incident's history, one per row
+func SnapshotsTable(snapshots []snapshot.Snapshot) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "Snapshot"},
+		output.Column{Header: "Taken"},
+		output.Column{Header: "Changes"},
+		output.Column{Header: "Stored"},
+		output.Column{Header: "SHA-256"},
+	)
+	for _, snap := range snapshots {
+		changes := fmt.Sprint(snap.Changes())
+		if snap.Seq == 1 {
+			changes = "base copy"
+		}
+		table.AddRow(fmt.Sprint(snap.Seq), snap.Timestamp.Local().Format("2006-01-02 15:04:05"), changes,
+			fmt.Sprintf("%d bytes", snap.Size), snap.SHA256[:min(12, len(snap.SHA256))])
+	}
+	return table
+}
+
+// ChangesTable lists the fields that changed between two snapshots, one per
+// row with the value before and after
+func ChangesTable(changes []snapshot.Change) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "Change"},
+		output.Column{Header: "Field", Max: 50},
+		output.Column{Header: "Before", Max: 40},
+		output.Column{Header: "After", Max: 40},
+	)
+	labels := map[string]string{"add": "added", "remove": "removed", "replace": "changed"}
+	for _, change := range changes {
+		table.AddRow(labels[change.Op], change.Path, changeValue(change.Before), changeValue(change.After))
+	}
+	return table
+}
+
+// changeValue renders a changed value as compact JSON, or "-" for none
+func changeValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// SettingsTable lists the effective configuration values, one per row with
+// the source of the value and the variable that overrides it
+func SettingsTable(settings []config.Setting) *output.Table {
+	table := output.NewTable(
+		output.Column{Header: "Setting"},
+		output.Column{Header: "Value", Max: 50},
+		output.Column{Header: "Source", Color: sourceColor},
+		output.Column{Header: "Environment Variable"},
+	)
+	for _, setting := range settings {
+		value := setting.Value
+		if value == "" {
+			value = "-"
+		}
+		table.AddRow(setting.Key, value, setting.Source, setting.Env)
+	}
+	return table
+}
+
+// sourceColor highlights values that do not come from the defaults
+func sourceColor(source string) *color.Color {
+	switch source {
+	case config.SourceEnv:
+		return color.New(color.FgCyan)
+	case config.SourceFile:
+		return color.New(color.FgGreen)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestConsoleRenderersEscapeHostileText(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	hostile := []string{
+		"evil.exe\x1b]0;Administrator: cmd\x07",
+		"svc.exe\x1b[2J\x1b[1;1H\x1b[8mhidden",
+		"backup.exe\rC:\\Windows\\system32\\svchost.exe",
+		"agent\u009b31mred",
+		"invoice\u202efdp.exe",
+		"name\xff\xfe",
+	}
+	var matches []map[string]interface{}
+	for _, text := range hostile {
+		matches = append(matches, map[string]interface{}{
+			"rule_id":    "hostile",
+			"rule_title": text,
+			"level":      "high",
+			"evidence":   map[string]interface{}{"Image": text, "ProcessId": "4242"},
+		})
+	}
+
+	var rendered bytes.Buffer
+	if err := FindingsTable(matches).Render(&rendered); err != nil {
+		t.Fatal(err)
+	}
+	rendered.WriteString(KeyFindingsText(GroupFindings(matches), 0))
+
+	text := rendered.String()
+	if strings.ContainsRune(text, '�') {
+		t.Error("invalid UTF-8 shown as replacement characters")
+	}
+	for _, r := range text {
+		if (r < 0x20 && r != '\n') || r == 0x7f || (r >= 0x80 && r <= 0x9f) || (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069) {
+			t.Fatalf("control character %U reached the terminal:\n%s", r, text)
+		}
+	}
+	if !strings.Contains(text, `evil.exe\x1b]0;Administrator`) {
+		t.Errorf("escaped process name not shown:\n%s", text)
+	}
+}
@@ -0,0 +1,316 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Forensic timeline export formats
+const (
+	TimelineL2TCSV = "l2tcsv" // log2timeline l2t_csv
+	TimelineJSONL  = "jsonl"  // Timesketch JSON lines
+)
+
+// NotATime is the timestamp description of events whose time is unknown,
+// as Plaso writes it. Such events carry no date or time in l2t_csv and the
+// zero timestamp in JSONL.
+const NotATime = "Not a time"
+
+// L2TCSVColumns are the columns of the log2timeline l2t_csv format, in order
+var L2TCSVColumns = []string{
+	"date", "time", "timezone", "MACB", "source", "sourcetype", "type", "user", "host",
+	"short", "desc", "version", "filename", "inode", "notes", "format", "extra",
+}
+
+// timesketchFields are the JSONL fields set from the entry itself; extra
+// attributes with these names are written with a data_ prefix
+var timesketchFields = map[string]bool{
+	"message": true, "datetime": true, "timestamp": true, "timestamp_desc": true,
+	"time_known": true, "source_short": true, "source_long": true, "event_type": true,
+	"hostname": true, "username": true, "incident_id": true, "reference": true,
+}
+
+// TimelineEntry is one event of an incident timeline as the forensic
+// timeline exports write it
+type TimelineEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Unset when the event has no precise time
+	TimeKnown bool `json:"time_known"`
+	// What the time means, e.g. "Detection Time"
+	TimestampDesc string `json:"timestamp_desc"`
+	// Short and long source names, e.g. FINDING and "RedTriage finding"
+	Source      string                 `json:"source"`
+	SourceType  string                 `json:"source_type"`
+	Type        string                 `json:"type"`
+	User        string                 `json:"user,omitempty"`
+	Host        string                 `json:"host"`
+	IncidentID  string                 `json:"incident_id"`
+	Short       string                 `json:"short"`
+	Description string                 `json:"description"`
+	Reference   string                 `json:"reference,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ValidTimelineFormat reports whether format is a timeline export format
+func ValidTimelineFormat(format string) bool {
+	return format == TimelineL2TCSV || format == TimelineJSONL
+}
+
+// TimelineExtension returns the file extension of a timeline export format
+func TimelineExtension(format string) string {
+	if format == TimelineJSONL {
+		return ".jsonl"
+	}
+	return ".csv"
+}
+
+// WriteTimeline writes the entries in a timeline export format
+func WriteTimeline(w io.Writer, format string, entries []TimelineEntry) error {
+	switch format {
+	case TimelineL2TCSV:
+		return WriteL2TCSV(w, entries)
+	case TimelineJSONL:
+		return WriteTimesketchJSONL(w, entries)
+	default:
+		return fmt.Errorf("unsupported timeline format: %s (use %s or %s)", format, TimelineL2TCSV, TimelineJSONL)
+	}
+}
+
+// WriteL2TCSV writes the entries as l2t_csv, the super-timeline format of
+// log2timeline: times in UTC, one event per row. Events without a known
+// time get the date 00/00/0000, the time --:--:-- and the type "Not a time".
+func WriteL2TCSV(w io.Writer, entries []TimelineEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(L2TCSVColumns); err != nil {
+		return fmt.Errorf("failed to write timeline header: %w", err)
+	}
+
+	for _, entry := range entries {
+		date, clock, timestampDesc := "00/00/0000", "--:--:--", NotATime
+		if entry.TimeKnown {
+			utc := entry.Timestamp.UTC()
+			date, clock, timestampDesc = utc.Format("01/02/2006"), utc.Format("15:04:05"), entry.TimestampDesc
+		}
+
+		row := []string{
+			date,
+			clock,
+			"UTC",
+			"....",
+			entry.Source,
+			entry.SourceType,
+			timestampDesc,
+			orDash(entry.User),
+			orDash(entry.Host),
+			oneLine(entry.Short),
+			oneLine(entry.Description),
+			"2",
+			"-",
+			"-",
+			"-",
+			"redtriage",
+			l2tExtra(entry),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write timeline row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTimesketchJSONL writes the entries as JSON lines Timesketch imports:
+// message, datetime, timestamp in microseconds and timestamp_desc, followed
+// by the host, incident and event attributes. Events without a known time
+// have the zero timestamp, timestamp_desc "Not a time" and time_known false.
+func WriteTimesketchJSONL(w io.Writer, entries []TimelineEntry) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	encoder.SetEscapeHTML(false)
+
+	for _, entry := range entries {
+		timestamp, timestampDesc := time.Unix(0, 0).UTC(), NotATime
+		if entry.TimeKnown {
+			timestamp, timestampDesc = entry.Timestamp.UTC(), entry.TimestampDesc
+		}
+
+		line := map[string]interface{}{
+			"message":        entry.Description,
+			"datetime":       timestamp.Format("2006-01-02T15:04:05.000000-07:00"),
+			"timestamp":      timestamp.UnixMicro(),
+			"timestamp_desc": timestampDesc,
+			"time_known":     entry.TimeKnown,
+			"source_short":   entry.Source,
+			"source_long":    entry.SourceType,
+			"event_type":     entry.Type,
+			"hostname":       entry.Host,
+			"incident_id":    entry.IncidentID,
+		}
+		if entry.User != "" {
+			line["username"] = entry.User
+		}
+		if entry.Reference != "" {
+			line["reference"] = entry.Reference
+		}
+		for key, value := range entry.Attributes {
+			if timesketchFields[key] {
+				key = "data_" + key
+			}
+			line[key] = value
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to write timeline event: %w", err)
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// TimelineCSVColumns are the columns of the plain timeline CSV
+var TimelineCSVColumns = []string{
+	"timestamp", "source", "type", "host", "user", "incident_id", "short", "description", "reference",
+}
+
+// WriteTimelineCSV writes the entries as plain CSV for spreadsheets, one
+// event per row with RFC 3339 times in UTC. Events without a known time have
+// an empty timestamp.
+func WriteTimelineCSV(w io.Writer, entries []TimelineEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(TimelineCSVColumns); err != nil {
+		return fmt.Errorf("failed to write timeline header: %w", err)
+	}
+
+	for _, entry := range entries {
+		timestamp := ""
+		if entry.TimeKnown {
+			timestamp = entry.Timestamp.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			timestamp,
+			entry.Source,
+			entry.Type,
+			entry.Host,
+			entry.User,
+			entry.IncidentID,
+			oneLine(entry.Short),
+			oneLine(entry.Description),
+			entry.Reference,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write timeline row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// SortTimeline orders entries by time. Entries without a known time come
+// last, in their original order.
+func SortTimeline(entries []TimelineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].TimeKnown != entries[j].TimeKnown {
+			return entries[i].TimeKnown
+		}
+		return entries[i].TimeKnown && entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+}
+
+// TimelineFilter selects timeline entries. Empty fields match everything;
+// sources and types match without regard to case. Entries without a known
+// time are left out once a time range is set.
+type TimelineFilter struct {
+	Since   time.Time
+	Until   time.Time
+	Sources []string
+	Types   []string
+}
+
+// Match reports whether the filter selects the entry
+func (f TimelineFilter) Match(entry TimelineEntry) bool {
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		if !entry.TimeKnown {
+			return false
+		}
+		if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+			return false
+		}
+	}
+	return matchesAny(f.Sources, entry.Source) && matchesAny(f.Types, entry.Type)
+}
+
+// FilterTimeline returns the entries the filter selects
+func FilterTimeline(entries []TimelineEntry, filter TimelineFilter) []TimelineEntry {
+	var selected []TimelineEntry
+	for _, entry := range entries {
+		if filter.Match(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// l2tExtra renders the fields l2t_csv has no column for as "key: value"
+// pairs separated by semicolons, the incident first
+func l2tExtra(entry TimelineEntry) string {
+	pairs := []string{"incident_id: " + entry.IncidentID, "event_type: " + entry.Type}
+	if entry.Reference != "" {
+		pairs = append(pairs, "reference: "+entry.Reference)
+	}
+	if !entry.TimeKnown {
+		pairs = append(pairs, "time_known: false")
+	}
+
+	keys := make([]string, 0, len(entry.Attributes))
+	for key := range entry.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := entry.Attributes[key]
+		text := fmt.Sprint(value)
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			if data, err := json.Marshal(value); err == nil {
+				text = string(data)
+			}
+		}
+		pairs = append(pairs, fmt.Sprintf("%s: %s", key, strings.ReplaceAll(oneLine(text), ";", ",")))
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// oneLine joins the lines of a value so every event stays on one row
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,166 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// l2tSpecColumns are the l2t_csv columns as published with log2timeline,
+// kept separate from the exporter's list so a change there is caught
+var l2tSpecColumns = []string{
+	"date", "time", "timezone", "MACB", "source", "sourcetype", "type", "user", "host",
+	"short", "desc", "version", "filename", "inode", "notes", "format", "extra",
+}
+
+const (
+	timelineTestHost     = "TEST-WS01"
+	timelineTestIncident = "INC-TEST"
+)
+
+// timelineTestEntries are an incident event, two findings and an imported
+// note without a known time
+func timelineTestEntries() []TimelineEntry {
+	entries := []TimelineEntry{{
+		Timestamp:     time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+		TimeKnown:     true,
+		TimestampDesc: "Event Recorded",
+		Source:        "INCIDENT",
+		SourceType:    "RedTriage incident timeline",
+		Type:          "incident_created",
+		User:          "analyst",
+		Host:          timelineTestHost,
+		IncidentID:    timelineTestIncident,
+		Short:         "Incident created",
+		Description:   "Incident created, severity high;\nsecond line",
+		Attributes:    map[string]interface{}{"title": "a, b; c", "message": "shadowed"},
+	}}
+	for i, rule := range []string{"builtin-defender-detection", "builtin-encoded-powershell"} {
+		entries = append(entries, TimelineEntry{
+			Timestamp:     time.Date(2025, 3, 1, 9, 5, i, 500000000, time.FixedZone("CET", 3600)),
+			TimeKnown:     true,
+			TimestampDesc: "Detection Time",
+			Source:        "FINDING",
+			SourceType:    "RedTriage finding",
+			Type:          "builtin_detection",
+			Host:          timelineTestHost,
+			IncidentID:    timelineTestIncident,
+			Short:         "[high] " + rule,
+			Description:   "Finding of " + rule,
+			Attributes:    map[string]interface{}{"rule_id": rule},
+		})
+	}
+	return append(entries, TimelineEntry{
+		Source:      "INCIDENT",
+		SourceType:  "RedTriage incident timeline",
+		Type:        "note_imported",
+		Host:        timelineTestHost,
+		IncidentID:  timelineTestIncident,
+		Short:       "Imported note",
+		Description: "Imported note without a recorded time",
+	})
+}
+
+func TestWriteTimelineL2TCSV(t *testing.T) {
+	entries := timelineTestEntries()
+	var buf bytes.Buffer
+	if err := WriteTimeline(&buf, TimelineL2TCSV, entries); err != nil {
+		t.Fatalf("WriteTimeline: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("l2t_csv export does not read back: %v", err)
+	}
+	if len(records) != len(entries)+1 {
+		t.Fatalf("l2t_csv export has %d rows, want %d and a header", len(records)-1, len(entries))
+	}
+	if got := strings.Join(records[0], ","); got != strings.Join(l2tSpecColumns, ",") {
+		t.Fatalf("l2t_csv header %q does not match the l2t spec %q", got, strings.Join(l2tSpecColumns, ","))
+	}
+
+	column := make(map[string]int, len(l2tSpecColumns))
+	for i, name := range l2tSpecColumns {
+		column[name] = i
+	}
+	for i, row := range records[1:] {
+		entry := entries[i]
+		if row[column["host"]] != timelineTestHost || !strings.Contains(row[column["extra"]], "incident_id: "+timelineTestIncident) {
+			t.Errorf("row %d lacks the host or incident: %q", i+1, row)
+		}
+		if strings.Contains(row[column["desc"]], "\n") {
+			t.Errorf("row %d spans several lines", i+1)
+		}
+		if !entry.TimeKnown {
+			if row[column["date"]] != "00/00/0000" || row[column["time"]] != "--:--:--" || row[column["type"]] != NotATime {
+				t.Errorf("row %d has no time but is not marked: %q", i+1, row)
+			}
+			continue
+		}
+		at, err := time.Parse("01/02/2006 15:04:05", row[column["date"]]+" "+row[column["time"]])
+		if err != nil {
+			t.Errorf("row %d has an invalid date or time: %v", i+1, err)
+			continue
+		}
+		if !at.Equal(entry.Timestamp.UTC().Truncate(time.Second)) || row[column["timezone"]] != "UTC" {
+			t.Errorf("row %d reads back as %s, want %s", i+1, at, entry.Timestamp.UTC())
+		}
+	}
+}
+
+func TestWriteTimelineJSONL(t *testing.T) {
+	entries := timelineTestEntries()
+	var buf bytes.Buffer
+	if err := WriteTimeline(&buf, TimelineJSONL, entries); err != nil {
+		t.Fatalf("WriteTimeline: %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		if lines >= len(entries) {
+			t.Fatal("JSONL export has more lines than events")
+		}
+		entry := entries[lines]
+		lines++
+
+		var event struct {
+			Message       string `json:"message"`
+			Datetime      string `json:"datetime"`
+			Timestamp     int64  `json:"timestamp"`
+			TimestampDesc string `json:"timestamp_desc"`
+			TimeKnown     bool   `json:"time_known"`
+			Hostname      string `json:"hostname"`
+			IncidentID    string `json:"incident_id"`
+			DataMessage   string `json:"data_message"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %d does not parse: %v", lines, err)
+		}
+		if event.Message != entry.Description || event.Hostname != timelineTestHost || event.IncidentID != timelineTestIncident {
+			t.Errorf("line %d lacks the message, host or incident: %s", lines, scanner.Text())
+		}
+		at, err := time.Parse(time.RFC3339Nano, event.Datetime)
+		if err != nil {
+			t.Errorf("line %d has an invalid datetime: %v", lines, err)
+		} else if at.UnixMicro() != event.Timestamp {
+			t.Errorf("line %d: datetime %s and timestamp %d disagree", lines, event.Datetime, event.Timestamp)
+		}
+		if entry.TimeKnown != event.TimeKnown || (!entry.TimeKnown && event.TimestampDesc != NotATime) {
+			t.Errorf("line %d has no time but is not marked: %s", lines, scanner.Text())
+		}
+		if _, shadowed := entry.Attributes["message"]; shadowed && event.DataMessage == "" {
+			t.Errorf("line %d: an attribute named message replaced the message field", lines)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if lines != len(entries) {
+		t.Errorf("JSONL export has %d lines, want %d", lines, len(entries))
+	}
+}
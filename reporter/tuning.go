@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TuningSuppressedField marks a Sigma match an incident's detection tuning
+// suppressed; it holds why
+const TuningSuppressedField = "tuning_suppressed"
+
+// DetectionTuning is the detection configuration of one incident: a rule
+// set loaded instead of the shared rules directory, rules whose matches are
+// suppressed and evidence values that are allowed. It lets a noisy host be
+// tuned without changing what other incidents detect.
+type DetectionTuning struct {
+	RulesDir        string       `json:"rules_dir,omitempty"`
+	SuppressedRules []string     `json:"suppressed_rules,omitempty"`
+	Allowlist       []AllowEntry `json:"allowlist,omitempty"`
+	Reason          string       `json:"reason,omitempty"`
+	SetBy           string       `json:"set_by"`
+	SetAt           time.Time    `json:"set_at"`
+}
+
+// AllowEntry allows an evidence value: a match whose evidence field has
+// the value is suppressed. Values compare without case and may hold *
+// wildcards.
+type AllowEntry struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// TuningSummary counts the matches a detection tuning suppressed
+type TuningSummary struct {
+	RulesSuppressed int `json:"rules_suppressed"`
+	Allowlisted     int `json:"allowlisted"`
+}
+
+// ParseAllowEntry reads an allowlist entry written as field=value
+func ParseAllowEntry(s string) (AllowEntry, error) {
+	field, value, ok := strings.Cut(s, "=")
+	field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+	if !ok || field == "" || value == "" {
+		return AllowEntry{}, fmt.Errorf("invalid allowlist entry %q: expected field=value", s)
+	}
+	return AllowEntry{Field: field, Value: value}, nil
+}
+
+// String returns the entry as field=value
+func (e AllowEntry) String() string {
+	return e.Field + "=" + e.Value
+}
+
+// Empty reports whether the tuning changes nothing
+func (t *DetectionTuning) Empty() bool {
+	return t == nil || (t.RulesDir == "" && len(t.SuppressedRules) == 0 && len(t.Allowlist) == 0)
+}
+
+// String summarizes the tuning for display
+func (t *DetectionTuning) String() string {
+	if t.Empty() {
+		return "none"
+	}
+	var parts []string
+	if t.RulesDir != "" {
+		parts = append(parts, "rules from "+t.RulesDir)
+	}
+	if len(t.SuppressedRules) > 0 {
+		parts = append(parts, fmt.Sprintf("%d suppressed rules", len(t.SuppressedRules)))
+	}
+	if len(t.Allowlist) > 0 {
+		parts = append(parts, fmt.Sprintf("%d allowlist entries", len(t.Allowlist)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply marks the matches of suppressed rules and the matches with an
+// allowed evidence value with TuningSuppressedField and returns the others
+func (t *DetectionTuning) Apply(matches []map[string]interface{}) ([]map[string]interface{}, TuningSummary) {
+	var summary TuningSummary
+	if t.Empty() {
+		return matches, summary
+	}
+
+	patterns := make([]*regexp.Regexp, len(t.Allowlist))
+	for i, entry := range t.Allowlist {
+		patterns[i] = wildcardPattern(entry.Value)
+	}
+
+	var kept []map[string]interface{}
+	for _, match := range matches {
+		if rule := t.suppressedRule(match); rule != "" {
+			match[TuningSuppressedField] = "rule " + rule + " suppressed"
+			summary.RulesSuppressed++
+			continue
+		}
+		if entry := t.allowedBy(match, patterns); entry != "" {
+			match[TuningSuppressedField] = "allowlisted " + entry
+			summary.Allowlisted++
+			continue
+		}
+		kept = append(kept, match)
+	}
+	return kept, summary
+}
+
+// suppressedRule returns the suppressed rule ID or title the match is of,
+// or ""
+func (t *DetectionTuning) suppressedRule(match map[string]interface{}) string {
+	for _, rule := range t.SuppressedRules {
+		if strings.EqualFold(stringValue(match["rule_id"]), rule) || strings.EqualFold(stringValue(match["rule_title"]), rule) {
+			return rule
+		}
+	}
+	return ""
+}
+
+// allowedBy returns the allowlist entry the match's evidence has a value
+// of, or ""
+func (t *DetectionTuning) allowedBy(match map[string]interface{}, patterns []*regexp.Regexp) string {
+	evidence, _ := match["evidence"].(map[string]interface{})
+	for key, value := range evidence {
+		text := stringValue(value)
+		if text == "" {
+			continue
+		}
+		for i, entry := range t.Allowlist {
+			if strings.EqualFold(key, entry.Field) && patterns[i].MatchString(text) {
+				return entry.String()
+			}
+		}
+	}
+	return ""
+}
+
+// wildcardPattern compiles a value with * wildcards into a case-insensitive
+// pattern matching the whole value
+func wildcardPattern(value string) *regexp.Regexp {
+	quoted := strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	return regexp.MustCompile("(?is)^" + quoted + "$")
+}
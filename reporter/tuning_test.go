@@ -0,0 +1,54 @@
+package reporter
+
+import "testing"
+
+func TestDetectionTuningApply(t *testing.T) {
+	match := func(ruleID, title string, evidence map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"rule_id": ruleID, "rule_title": title, "evidence": evidence}
+	}
+	matches := []map[string]interface{}{
+		match("RT-001", "Suspicious Process Behavior", map[string]interface{}{"name": "node.exe", "pid": "4120"}),
+		match("RT-001", "Suspicious Process Behavior", map[string]interface{}{"name": "mimikatz.exe", "pid": "6666"}),
+		match("RT-002", "Suspicious Network Connections", map[string]interface{}{"process": "svchost.exe", "remote_address": "185.220.101.45:4444"}),
+		match("RT-003", "Scheduled Task Created", map[string]interface{}{"file_path": `C:\Build\agent\run.cmd`}),
+		match("RT-004", "Defender Malware Detected", map[string]interface{}{"file_path": `C:\Users\x\evil.exe`}),
+	}
+
+	var allowlist []AllowEntry
+	for _, value := range []string{"name=NODE.EXE", `File_Path=c:\build\*`} {
+		entry, err := ParseAllowEntry(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowlist = append(allowlist, entry)
+	}
+
+	// Rules are suppressed by ID or by title, in any case
+	tuning := &DetectionTuning{SuppressedRules: []string{"suspicious network connections", "RT-004"}, Allowlist: allowlist}
+	kept, summary := tuning.Apply(matches)
+	if len(kept) != 1 || kept[0]["evidence"].(map[string]interface{})["name"] != "mimikatz.exe" {
+		t.Fatalf("tuning kept %d matches, want only mimikatz.exe", len(kept))
+	}
+	if summary.RulesSuppressed != 2 || summary.Allowlisted != 2 {
+		t.Errorf("tuning suppressed %d by rule and allowlisted %d, want 2 and 2", summary.RulesSuppressed, summary.Allowlisted)
+	}
+	for _, m := range matches {
+		if _, suppressed := m[TuningSuppressedField]; suppressed == (m["evidence"].(map[string]interface{})["name"] == "mimikatz.exe") {
+			t.Errorf("match of %s marked %v", m["rule_id"], m[TuningSuppressedField])
+		}
+	}
+	if reason := matches[3][TuningSuppressedField]; reason != `allowlisted File_Path=c:\build\*` {
+		t.Errorf("allowlisted match marked %v", reason)
+	}
+
+	var none *DetectionTuning
+	if kept, _ := none.Apply(matches); len(kept) != len(matches) {
+		t.Error("an incident without tuning suppressed matches")
+	}
+}
+
+func TestParseAllowEntryNeedsValue(t *testing.T) {
+	if _, err := ParseAllowEntry("name"); err == nil {
+		t.Error("allowlist entry without a value parsed without error")
+	}
+}
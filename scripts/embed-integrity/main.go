@@ -0,0 +1,87 @@
+// Command embed-integrity prepares release binaries for RedTriage's
+// self-integrity check. It embeds each binary's baseline hash, writes
+// SHA256SUMS for them and, given a key, signs it as SHA256SUMS.sig.
+//
+//	go run ./scripts/embed-integrity -genkey release.key
+//	go run ./scripts/embed-integrity -sums dist/SHA256SUMS -key release.key build/redtriage build/redtriage.exe
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redtriage/redtriage/internal/integrity"
+)
+
+func main() {
+	sumsPath := flag.String("sums", "", "write SHA256SUMS for the binaries to this file")
+	keyPath := flag.String("key", "", "sign SHA256SUMS with the base64 Ed25519 private key in this file")
+	genKey := flag.String("genkey", "", "generate a signing key in this file and print its public key")
+	flag.Parse()
+
+	if err := run(*sumsPath, *keyPath, *genKey, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "embed-integrity: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(sumsPath, keyPath, genKey string, binaries []string) error {
+	if genKey != "" {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		if err := os.WriteFile(genKey, []byte(base64.StdEncoding.EncodeToString(private)+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write key: %w", err)
+		}
+		fmt.Printf("Public key (-X github.com/redtriage/redtriage/internal/integrity.PublicKey=...):\n%s\n", base64.StdEncoding.EncodeToString(public))
+		return nil
+	}
+	if len(binaries) == 0 {
+		return fmt.Errorf("no binaries given")
+	}
+	if keyPath != "" && sumsPath == "" {
+		return fmt.Errorf("-key requires -sums")
+	}
+
+	var sums strings.Builder
+	for _, binary := range binaries {
+		hash, err := integrity.Embed(binary)
+		if err != nil {
+			return fmt.Errorf("%s: %w", binary, err)
+		}
+		fmt.Printf("%s  %s\n", hash, binary)
+		fmt.Fprintf(&sums, "%s  %s\n", hash, filepath.Base(binary))
+	}
+	if sumsPath == "" {
+		return nil
+	}
+	if err := os.WriteFile(sumsPath, []byte(sums.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sumsPath, err)
+	}
+	if keyPath == "" {
+		return nil
+	}
+
+	encoded, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("%s is not a base64 Ed25519 private key", keyPath)
+	}
+	signature := ed25519.Sign(ed25519.PrivateKey(key), []byte(sums.String()))
+	sigPath := filepath.Join(filepath.Dir(sumsPath), integrity.SignatureFile)
+	if err := os.WriteFile(sigPath, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sigPath, err)
+	}
+	fmt.Printf("Signed %s as %s\n", sumsPath, sigPath)
+	return nil
+}
@@ -147,10 +147,10 @@ $healthTests = @(
 )
 
 $collectionTests = @(
-    @{Command = "collect --output $systemDir/collection-test"; Description = "Basic collection"},
-    @{Command = "collect --extended --output $systemDir/extended-test"; Description = "Extended collection"},
-    @{Command = "collect --include processes,network --output $systemDir/selective-test"; Description = "Selective collection"},
-    @{Command = "collect --exclude memory --output $systemDir/exclude-test"; Description = "Exclude memory"}
+    @{Command = "collect --yes --output $systemDir/collection-test"; Description = "Basic collection"},
+    @{Command = "collect --yes --extended --output $systemDir/extended-test"; Description = "Extended collection"},
+    @{Command = "collect --yes --include processes,network --output $systemDir/selective-test"; Description = "Selective collection"},
+    @{Command = "collect --yes --exclude memory --output $systemDir/exclude-test"; Description = "Exclude memory"}
 )
 
 $checkTests = @(
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// GetFreeDiskSpace returns the free disk space in bytes for the given path
+func GetFreeDiskSpace(path string) (int64, error) {
+	return 0, fmt.Errorf("disk space checking not implemented for %s", runtime.GOOS)
+}
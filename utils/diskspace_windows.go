@@ -0,0 +1,23 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// GetFreeDiskSpace returns the free disk space in bytes for the given path,
+// as available to the calling user
+func GetFreeDiskSpace(path string) (int64, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get disk space of %s: %w", path, err)
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &free, &total, &totalFree); err != nil {
+		return 0, fmt.Errorf("failed to get disk space of %s: %w", path, err)
+	}
+	return int64(free), nil
+}
@@ -42,21 +42,6 @@ func HasAdminPrivileges() bool {
 	return os.Geteuid() == 0
 }
 
-// GetFreeDiskSpace returns the free disk space in bytes for the given path
-func GetFreeDiskSpace(path string) (int64, error) {
-	// For Windows, we'll use a different approach since Statfs is not available
-	if runtime.GOOS == "windows" {
-		// Use Windows-specific disk space checking
-		// For now, return a placeholder value
-		// TODO: Implement proper Windows disk space checking
-		return 1024 * 1024 * 1024 * 100, nil // 100GB placeholder
-	}
-	
-	// For Unix-like systems, we would use syscall.Statfs
-	// But since it's not available on Windows, we'll skip this for now
-	return 0, fmt.Errorf("disk space checking not implemented for %s", runtime.GOOS)
-}
-
 // CheckClockSanity checks if the system clock appears to be correct
 func CheckClockSanity() error {
 	now := time.Now()